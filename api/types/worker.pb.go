@@ -100,6 +100,9 @@ type GCPolicy struct {
 	KeepDuration         int64    `protobuf:"varint,2,opt,name=keepDuration,proto3" json:"keepDuration,omitempty"`
 	KeepBytes            int64    `protobuf:"varint,3,opt,name=keepBytes,proto3" json:"keepBytes,omitempty"`
 	Filters              []string `protobuf:"bytes,4,rep,name=filters,proto3" json:"filters,omitempty"`
+	ReservedSpace        int64    `protobuf:"varint,5,opt,name=reservedSpace,proto3" json:"reservedSpace,omitempty"`
+	MinFreeSpace         int64    `protobuf:"varint,6,opt,name=minFreeSpace,proto3" json:"minFreeSpace,omitempty"`
+	Schedule             string   `protobuf:"bytes,7,opt,name=schedule,proto3" json:"schedule,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -166,6 +169,27 @@ func (m *GCPolicy) GetFilters() []string {
 	return nil
 }
 
+func (m *GCPolicy) GetReservedSpace() int64 {
+	if m != nil {
+		return m.ReservedSpace
+	}
+	return 0
+}
+
+func (m *GCPolicy) GetMinFreeSpace() int64 {
+	if m != nil {
+		return m.MinFreeSpace
+	}
+	return 0
+}
+
+func (m *GCPolicy) GetSchedule() string {
+	if m != nil {
+		return m.Schedule
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*WorkerRecord)(nil), "moby.buildkit.v1.types.WorkerRecord")
 	proto.RegisterMapType((map[string]string)(nil), "moby.buildkit.v1.types.WorkerRecord.LabelsEntry")
@@ -175,30 +199,32 @@ func init() {
 func init() { proto.RegisterFile("worker.proto", fileDescriptor_e4ff6184b07e587a) }
 
 var fileDescriptor_e4ff6184b07e587a = []byte{
-	// 355 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x91, 0xc1, 0x4e, 0xea, 0x40,
-	0x14, 0x86, 0x6f, 0x5b, 0x2e, 0x97, 0x0e, 0xcd, 0x8d, 0x99, 0x18, 0xd3, 0x10, 0x83, 0x84, 0x15,
-	0x0b, 0x9d, 0xa2, 0x6e, 0xd4, 0xb8, 0x42, 0x8c, 0x92, 0xb8, 0x20, 0xb3, 0x71, 0xdd, 0x81, 0x01,
-	0x9b, 0x0e, 0x9c, 0xc9, 0x74, 0x8a, 0xf6, 0x39, 0x7c, 0x29, 0x96, 0x3e, 0x81, 0x31, 0x3c, 0x89,
-	0x99, 0x29, 0x08, 0x26, 0xba, 0x3b, 0xff, 0x9f, 0xff, 0xfb, 0xe7, 0x9c, 0x0c, 0x0a, 0x9e, 0x41,
-	0xa5, 0x5c, 0x11, 0xa9, 0x40, 0x03, 0x3e, 0x98, 0x01, 0x2b, 0x08, 0xcb, 0x13, 0x31, 0x4e, 0x13,
-	0x4d, 0x16, 0xa7, 0x44, 0x17, 0x92, 0x67, 0x8d, 0x93, 0x69, 0xa2, 0x9f, 0x72, 0x46, 0x46, 0x30,
-	0x8b, 0xa6, 0x30, 0x85, 0xc8, 0xc6, 0x59, 0x3e, 0xb1, 0xca, 0x0a, 0x3b, 0x95, 0x35, 0x8d, 0xe3,
-	0x9d, 0xb8, 0x69, 0x8c, 0x36, 0x8d, 0x51, 0x06, 0x62, 0xc1, 0x55, 0x24, 0x59, 0x04, 0x32, 0x2b,
-	0xd3, 0xed, 0x57, 0x17, 0x05, 0x8f, 0x76, 0x0b, 0xca, 0x47, 0xa0, 0xc6, 0xf8, 0x3f, 0x72, 0x07,
-	0xfd, 0xd0, 0x69, 0x39, 0x1d, 0x9f, 0xba, 0x83, 0x3e, 0xbe, 0x47, 0xd5, 0x87, 0x98, 0x71, 0x91,
-	0x85, 0x6e, 0xcb, 0xeb, 0xd4, 0xcf, 0xba, 0xe4, 0xe7, 0x35, 0xc9, 0x6e, 0x0b, 0x29, 0x91, 0xdb,
-	0xb9, 0x56, 0x05, 0x5d, 0xf3, 0xb8, 0x8b, 0x7c, 0x29, 0x62, 0x3d, 0x01, 0x35, 0xcb, 0x42, 0xcf,
-	0x96, 0x05, 0x44, 0x32, 0x32, 0x5c, 0x9b, 0xbd, 0xca, 0xf2, 0xfd, 0xe8, 0x0f, 0xdd, 0x86, 0xf0,
-	0x35, 0xaa, 0xdd, 0xdd, 0x0c, 0x41, 0x24, 0xa3, 0x22, 0xac, 0x58, 0xa0, 0xf5, 0xdb, 0xeb, 0x9b,
-	0x1c, 0xfd, 0x22, 0x1a, 0x97, 0xa8, 0xbe, 0xb3, 0x06, 0xde, 0x43, 0x5e, 0xca, 0x8b, 0xf5, 0x65,
-	0x66, 0xc4, 0xfb, 0xe8, 0xef, 0x22, 0x16, 0x39, 0x0f, 0x5d, 0xeb, 0x95, 0xe2, 0xca, 0xbd, 0x70,
-	0xda, 0x2f, 0xdb, 0x87, 0x0d, 0x17, 0x0b, 0x61, 0xb9, 0x1a, 0x35, 0x23, 0x6e, 0xa3, 0x20, 0xe5,
-	0x5c, 0xf6, 0x73, 0x15, 0xeb, 0x04, 0xe6, 0x16, 0xf7, 0xe8, 0x37, 0x0f, 0x1f, 0x22, 0xdf, 0xe8,
-	0x5e, 0xa1, 0xb9, 0x39, 0xd6, 0x04, 0xb6, 0x06, 0x0e, 0xd1, 0xbf, 0x49, 0x22, 0x34, 0x57, 0x99,
-	0xbd, 0xcb, 0xa7, 0x1b, 0xd9, 0x0b, 0x96, 0xab, 0xa6, 0xf3, 0xb6, 0x6a, 0x3a, 0x1f, 0xab, 0xa6,
-	0xc3, 0xaa, 0xf6, 0x93, 0xce, 0x3f, 0x03, 0x00, 0x00, 0xff, 0xff, 0xfc, 0x79, 0x52, 0x6a, 0x29,
-	0x02, 0x00, 0x00,
+	// 400 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x52, 0x4f, 0x6f, 0xd3, 0x30,
+	0x1c, 0x25, 0xe9, 0xd6, 0x35, 0x5e, 0x40, 0xc8, 0x42, 0xc8, 0x8a, 0x50, 0xa9, 0x2a, 0x0e, 0x3b,
+	0x80, 0x33, 0xe0, 0x02, 0x88, 0x53, 0x29, 0x7f, 0x26, 0x71, 0x98, 0xcc, 0x81, 0x73, 0x9c, 0xfc,
+	0xda, 0x45, 0x71, 0x6a, 0xcb, 0x76, 0x82, 0xf2, 0x39, 0xf8, 0x52, 0x3b, 0xf2, 0x09, 0x26, 0xd4,
+	0x4f, 0x82, 0xec, 0xb4, 0x6b, 0x26, 0xb1, 0xdb, 0xef, 0x3d, 0xbd, 0xf7, 0xfc, 0x9e, 0x64, 0x14,
+	0xff, 0x92, 0xba, 0x02, 0x4d, 0x95, 0x96, 0x56, 0xe2, 0xa7, 0xb5, 0xe4, 0x1d, 0xe5, 0x4d, 0x29,
+	0x8a, 0xaa, 0xb4, 0xb4, 0x7d, 0x4d, 0x6d, 0xa7, 0xc0, 0x24, 0xaf, 0xd6, 0xa5, 0xbd, 0x6a, 0x38,
+	0xcd, 0x65, 0x9d, 0xae, 0xe5, 0x5a, 0xa6, 0x5e, 0xce, 0x9b, 0x95, 0x47, 0x1e, 0xf8, 0xab, 0x8f,
+	0x49, 0x5e, 0x0e, 0xe4, 0x2e, 0x31, 0xdd, 0x27, 0xa6, 0x46, 0x8a, 0x16, 0x74, 0xaa, 0x78, 0x2a,
+	0x95, 0xe9, 0xd5, 0xf3, 0xdf, 0x21, 0x8a, 0x7f, 0xfa, 0x16, 0x0c, 0x72, 0xa9, 0x0b, 0xfc, 0x08,
+	0x85, 0x17, 0x4b, 0x12, 0xcc, 0x82, 0xb3, 0x88, 0x85, 0x17, 0x4b, 0xfc, 0x0d, 0x8d, 0xbf, 0x67,
+	0x1c, 0x84, 0x21, 0xe1, 0x6c, 0x74, 0x76, 0xfa, 0xe6, 0x9c, 0xfe, 0xbf, 0x26, 0x1d, 0xa6, 0xd0,
+	0xde, 0xf2, 0x79, 0x63, 0x75, 0xc7, 0x76, 0x7e, 0x7c, 0x8e, 0x22, 0x25, 0x32, 0xbb, 0x92, 0xba,
+	0x36, 0x64, 0xe4, 0xc3, 0x62, 0xaa, 0x38, 0xbd, 0xdc, 0x91, 0x8b, 0xa3, 0xeb, 0x9b, 0xe7, 0x0f,
+	0xd8, 0x41, 0x84, 0x3f, 0xa2, 0xc9, 0xd7, 0x4f, 0x97, 0x52, 0x94, 0x79, 0x47, 0x8e, 0xbc, 0x61,
+	0x76, 0xdf, 0xeb, 0x7b, 0x1d, 0xbb, 0x75, 0x24, 0xef, 0xd1, 0xe9, 0xa0, 0x06, 0x7e, 0x8c, 0x46,
+	0x15, 0x74, 0xbb, 0x65, 0xee, 0xc4, 0x4f, 0xd0, 0x71, 0x9b, 0x89, 0x06, 0x48, 0xe8, 0xb9, 0x1e,
+	0x7c, 0x08, 0xdf, 0x05, 0xf3, 0x9b, 0xe0, 0xf0, 0xb2, 0x33, 0x66, 0x42, 0x78, 0xe3, 0x84, 0xb9,
+	0x13, 0xcf, 0x51, 0x5c, 0x01, 0xa8, 0x65, 0xa3, 0x33, 0x5b, 0xca, 0x8d, 0xf7, 0x8f, 0xd8, 0x1d,
+	0x0e, 0x3f, 0x43, 0x91, 0xc3, 0x8b, 0xce, 0x82, 0x5b, 0xeb, 0x04, 0x07, 0x02, 0x13, 0x74, 0xb2,
+	0x2a, 0x85, 0x05, 0x6d, 0xfc, 0xb0, 0x88, 0xed, 0x21, 0x7e, 0x81, 0x1e, 0x6a, 0x30, 0xa0, 0x5b,
+	0x28, 0x7e, 0xa8, 0x2c, 0x07, 0x72, 0xec, 0xbd, 0x77, 0x49, 0xd7, 0xa0, 0x2e, 0x37, 0x5f, 0x34,
+	0x40, 0x2f, 0x1a, 0xf7, 0x0d, 0x86, 0x1c, 0x4e, 0xd0, 0xc4, 0xe4, 0x57, 0x50, 0x34, 0x02, 0xc8,
+	0x89, 0x5f, 0x78, 0x8b, 0x17, 0xf1, 0xf5, 0x76, 0x1a, 0xfc, 0xd9, 0x4e, 0x83, 0xbf, 0xdb, 0x69,
+	0xc0, 0xc7, 0xfe, 0x2f, 0xbc, 0xfd, 0x37, 0x00, 0x7a, 0x92, 0xc7, 0x04, 0x90, 0x02, 0x00, 0x00,
 }
 
 func (m *WorkerRecord) Marshal() (dAtA []byte, err error) {
@@ -306,6 +332,23 @@ func (m *GCPolicy) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.Schedule) > 0 {
+		i -= len(m.Schedule)
+		copy(dAtA[i:], m.Schedule)
+		i = encodeVarintWorker(dAtA, i, uint64(len(m.Schedule)))
+		i--
+		dAtA[i] = 0x3a
+	}
+	if m.MinFreeSpace != 0 {
+		i = encodeVarintWorker(dAtA, i, uint64(m.MinFreeSpace))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.ReservedSpace != 0 {
+		i = encodeVarintWorker(dAtA, i, uint64(m.ReservedSpace))
+		i--
+		dAtA[i] = 0x28
+	}
 	if len(m.Filters) > 0 {
 		for iNdEx := len(m.Filters) - 1; iNdEx >= 0; iNdEx-- {
 			i -= len(m.Filters[iNdEx])
@@ -406,6 +449,16 @@ func (m *GCPolicy) Size() (n int) {
 			n += 1 + l + sovWorker(uint64(l))
 		}
 	}
+	if m.ReservedSpace != 0 {
+		n += 1 + sovWorker(uint64(m.ReservedSpace))
+	}
+	if m.MinFreeSpace != 0 {
+		n += 1 + sovWorker(uint64(m.MinFreeSpace))
+	}
+	l = len(m.Schedule)
+	if l > 0 {
+		n += 1 + l + sovWorker(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -815,6 +868,76 @@ func (m *GCPolicy) Unmarshal(dAtA []byte) error {
 			}
 			m.Filters = append(m.Filters, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReservedSpace", wireType)
+			}
+			m.ReservedSpace = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowWorker
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ReservedSpace |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MinFreeSpace", wireType)
+			}
+			m.MinFreeSpace = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowWorker
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MinFreeSpace |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Schedule", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowWorker
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthWorker
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthWorker
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Schedule = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipWorker(dAtA[iNdEx:])
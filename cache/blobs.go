@@ -175,7 +175,7 @@ func computeBlobChain(ctx context.Context, sr *immutableRef, createIfNeeded bool
 				} else if !isTypeWindows(sr) {
 					enableOverlay, fallback = true, true
 					switch sr.cm.Snapshotter.Name() {
-					case "overlayfs", "stargz":
+					case "overlayfs", "stargz", "overlaybd":
 						// overlayfs-based snapshotters should support overlay diff except when running an arbitrary diff
 						// (in which case lower and upper may differ by more than one layer), so print warn log on unexpected
 						// failure.
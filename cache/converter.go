@@ -16,6 +16,7 @@ import (
 	"github.com/moby/buildkit/identity"
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/compression"
+	"github.com/moby/buildkit/util/overlaybd"
 	digest "github.com/opencontainers/go-digest"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
@@ -25,6 +26,16 @@ import (
 // be the compressionType.
 func needsConversion(ctx context.Context, cs content.Store, desc ocispecs.Descriptor, compressionType compression.Type) (bool, error) {
 	mediaType := desc.MediaType
+	if images.IsNonDistributable(mediaType) {
+		// foreign layers, e.g. the Windows base layers, are pulled from their
+		// URLs and keep the digest of their original compression
+		return false, nil
+	}
+	if overlaybd.IsLayer(desc) {
+		// overlaybd layers are mounted from their blobs by the overlaybd
+		// snapshotter, converting them would drop the blobs of the layers
+		return false, nil
+	}
 	switch compressionType {
 	case compression.Uncompressed:
 		if !images.IsLayerType(mediaType) || compression.FromMediaType(mediaType) == compression.Uncompressed {
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/containerd/containerd/images"
+	"github.com/moby/buildkit/util/compression"
+	"github.com/moby/buildkit/util/overlaybd"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNeedsConversionForeignLayers(t *testing.T) {
+	for _, mt := range []string{
+		images.MediaTypeDockerSchema2LayerForeignGzip,
+		ocispecs.MediaTypeImageLayerNonDistributableGzip,
+	} {
+		desc := ocispecs.Descriptor{
+			MediaType: mt,
+			Digest:    digest.FromString(mt),
+			URLs:      []string{"https://mcr.microsoft.com/v2/windows/nanoserver/blobs/" + digest.FromString(mt).String()},
+		}
+		for _, c := range []compression.Type{compression.Uncompressed, compression.Zstd} {
+			// the content store isn't needed for the foreign layers
+			needs, err := needsConversion(context.TODO(), nil, desc, c)
+			require.NoError(t, err)
+			require.False(t, needs, "%s to %s", mt, c)
+		}
+	}
+}
+
+func TestNeedsConversionOverlaybdLayers(t *testing.T) {
+	desc := ocispecs.Descriptor{
+		MediaType: ocispecs.MediaTypeImageLayer,
+		Digest:    digest.FromString("overlaybd"),
+		Annotations: map[string]string{
+			overlaybd.BlobDigestAnnotation: digest.FromString("overlaybd").String(),
+			overlaybd.BlobSizeAnnotation:   "4737695",
+		},
+	}
+	for _, c := range []compression.Type{compression.Gzip, compression.Zstd, compression.EStargz} {
+		// the content store isn't needed for the overlaybd layers
+		needs, err := needsConversion(context.TODO(), nil, desc, c)
+		require.NoError(t, err)
+		require.False(t, needs, "overlaybd to %s", c)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+// +build !windows
+
+package cache
+
+import (
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+func diskFree(root string) (int64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(root, &st); err != nil {
+		return 0, errors.Wrapf(err, "failed to stat filesystem of %s", root)
+	}
+	return int64(st.Bsize) * int64(st.Bavail), nil
+}
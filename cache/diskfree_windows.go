@@ -0,0 +1,10 @@
+//go:build windows
+// +build windows
+
+package cache
+
+import "github.com/pkg/errors"
+
+func diskFree(root string) (int64, error) {
+	return 0, errors.New("free disk space detection is not supported on windows")
+}
@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/containerd/containerd/filters"
+	"github.com/moby/buildkit/client"
+	"github.com/pkg/errors"
+)
+
+// ageFilterPattern matches the clauses of a filter on the age of records,
+// e.g. "type==regular,until=24h". containerd filters only compare strings,
+// so these clauses are extracted before the rest of the filter is parsed.
+var ageFilterPattern = regexp.MustCompile(`(^|,)(until|since|unused-for|used-within)=([^,]*)`)
+
+// usageFilter matches records that match any of its clauses, like the
+// filters returned by filters.ParseAll.
+type usageFilter []usageFilterClause
+
+type usageFilterClause struct {
+	filter filters.Filter
+	// records are matched if they were created before createdBefore,
+	// created after createdAfter, last used before usedBefore and last used
+	// after usedAfter. Zero times are ignored.
+	createdBefore time.Time
+	createdAfter  time.Time
+	usedBefore    time.Time
+	usedAfter     time.Time
+}
+
+// parseUsageFilters parses the filters of disk usage and prune requests. In
+// addition to the containerd filter syntax, the clauses until=<duration> and
+// since=<duration> match records created more or less than the duration
+// before now, and unused-for=<duration> and used-within=<duration> match
+// records last used more or less than the duration before now.
+func parseUsageFilters(now time.Time, ss []string) (usageFilter, error) {
+	var uf usageFilter
+	for _, s := range ss {
+		var c usageFilterClause
+		var err error
+		rest := ageFilterPattern.ReplaceAllStringFunc(s, func(m string) string {
+			sm := ageFilterPattern.FindStringSubmatch(m)
+			d, perr := time.ParseDuration(sm[3])
+			if perr != nil {
+				err = errors.Wrapf(perr, "invalid duration in filter %q", strings.TrimPrefix(m, ","))
+				return ""
+			}
+			t := now.Add(-d)
+			switch sm[2] {
+			case "until":
+				c.createdBefore = t
+			case "since":
+				c.createdAfter = t
+			case "unused-for":
+				c.usedBefore = t
+			case "used-within":
+				c.usedAfter = t
+			}
+			return ""
+		})
+		if err != nil {
+			return nil, err
+		}
+		rest = strings.TrimPrefix(rest, ",")
+		if rest == "" {
+			c.filter = filters.Always
+		} else {
+			c.filter, err = filters.Parse(rest)
+			if err != nil {
+				return nil, err
+			}
+		}
+		uf = append(uf, c)
+	}
+	return uf, nil
+}
+
+func (uf usageFilter) Match(info *client.UsageInfo) bool {
+	if len(uf) == 0 {
+		return true
+	}
+	for _, c := range uf {
+		if c.match(info) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c usageFilterClause) match(info *client.UsageInfo) bool {
+	if !c.createdBefore.IsZero() && !info.CreatedAt.Before(c.createdBefore) {
+		return false
+	}
+	if !c.createdAfter.IsZero() && !info.CreatedAt.After(c.createdAfter) {
+		return false
+	}
+	lastUsed := info.CreatedAt
+	if info.LastUsedAt != nil {
+		lastUsed = *info.LastUsedAt
+	}
+	if !c.usedBefore.IsZero() && !lastUsed.Before(c.usedBefore) {
+		return false
+	}
+	if !c.usedAfter.IsZero() && !lastUsed.After(c.usedAfter) {
+		return false
+	}
+	return c.filter.Match(adaptUsageInfo(info))
+}
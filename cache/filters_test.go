@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageFilters(t *testing.T) {
+	now := time.Now()
+	hoursAgo := func(h int) *time.Time {
+		t := now.Add(-time.Duration(h) * time.Hour)
+		return &t
+	}
+
+	old := &client.UsageInfo{
+		ID:          "old",
+		RecordType:  client.UsageRecordTypeRegular,
+		Description: "pulled from docker.io/library/alpine:latest",
+		CreatedAt:   *hoursAgo(72),
+		LastUsedAt:  hoursAgo(1),
+	}
+	recent := &client.UsageInfo{
+		ID:          "recent",
+		RecordType:  client.UsageRecordTypeCacheMount,
+		Description: "cached mount /root/.cache/go-build",
+		CreatedAt:   *hoursAgo(2),
+	}
+
+	for _, tc := range []struct {
+		filters []string
+		matches []string
+	}{
+		{nil, []string{"old", "recent"}},
+		{[]string{"type==exec.cachemount"}, []string{"recent"}},
+		{[]string{"description~=alpine"}, []string{"old"}},
+		{[]string{"until=24h"}, []string{"old"}},
+		{[]string{"since=24h"}, []string{"recent"}},
+		{[]string{"unused-for=90m"}, []string{"recent"}},
+		{[]string{"used-within=90m"}, []string{"old"}},
+		{[]string{"type==regular,until=24h"}, []string{"old"}},
+		{[]string{`type~="^(regular|exec\\.cachemount)$",description~="go-build"`}, []string{"recent"}},
+		{[]string{"until=24h,type==exec.cachemount"}, nil},
+		{[]string{"until=48h,since=96h"}, []string{"old"}},
+		{[]string{"type==exec.cachemount", "until=24h"}, []string{"old", "recent"}},
+	} {
+		f, err := parseUsageFilters(now, tc.filters)
+		require.NoError(t, err, tc.filters)
+		var matches []string
+		for _, info := range []*client.UsageInfo{old, recent} {
+			if f.Match(info) {
+				matches = append(matches, info.ID)
+			}
+		}
+		require.Equal(t, tc.matches, matches, tc.filters)
+	}
+
+	_, err := parseUsageFilters(now, []string{"until=foo"})
+	require.Error(t, err)
+	_, err = parseUsageFilters(now, []string{"type=="})
+	require.Error(t, err)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/flightcontrol"
 	"github.com/moby/buildkit/util/progress"
+	"github.com/moby/buildkit/util/tenant"
 	digest "github.com/opencontainers/go-digest"
 	imagespecidentity "github.com/opencontainers/image-spec/identity"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
@@ -91,7 +92,8 @@ type cacheManager struct {
 	Differ          diff.Comparer
 	MetadataStore   *metadata.Store
 
-	mountPool sharableMountPool
+	mountPool     sharableMountPool
+	mountPoolRoot string
 
 	muPrune sync.Mutex // make sure parallel prune is not allowed so there will not be inconsistent results
 	unlazyG flightcontrol.Group
@@ -119,6 +121,7 @@ func NewManager(opt ManagerOpt) (Manager, error) {
 		return nil, err
 	}
 	cm.mountPool = p
+	cm.mountPoolRoot = opt.MountPoolRoot
 
 	// cm.scheduleGC(5 * time.Minute)
 
@@ -276,6 +279,7 @@ func (cm *cacheManager) GetByBlob(ctx context.Context, desc ocispecs.Descriptor,
 		cacheMetadata: md,
 	}
 
+	opts = append(opts, withTenant(tenant.FromContext(ctx)))
 	if err := initializeMetadata(rec.cacheMetadata, rec.parentRefs, opts...); err != nil {
 		return nil, err
 	}
@@ -593,7 +597,7 @@ func (cm *cacheManager) New(ctx context.Context, s ImmutableRef, sess session.Gr
 		return nil, errors.Wrapf(err, "failed to add snapshot %s to lease", snapshotID)
 	}
 
-	if cm.Snapshotter.Name() == "stargz" && parent != nil {
+	if isRemoteSnapshotter(cm.Snapshotter.Name()) && parent != nil {
 		if rerr := parent.withRemoteSnapshotLabelsStargzMode(ctx, sess, func() {
 			err = cm.Snapshotter.Prepare(ctx, snapshotID, parentSnapshotID)
 		}); rerr != nil {
@@ -620,7 +624,7 @@ func (cm *cacheManager) New(ctx context.Context, s ImmutableRef, sess session.Gr
 		cacheMetadata: md,
 	}
 
-	opts = append(opts, withSnapshotID(snapshotID))
+	opts = append(opts, withSnapshotID(snapshotID), withTenant(tenant.FromContext(ctx)))
 	if err := initializeMetadata(rec.cacheMetadata, rec.parentRefs, opts...); err != nil {
 		return nil, err
 	}
@@ -1000,7 +1004,7 @@ func (cm *cacheManager) Prune(ctx context.Context, ch chan client.UsageInfo, opt
 }
 
 func (cm *cacheManager) pruneOnce(ctx context.Context, ch chan client.UsageInfo, opt client.PruneInfo) error {
-	filter, err := filters.ParseAll(opt.Filter...)
+	filter, err := parseUsageFilters(time.Now(), opt.Filter)
 	if err != nil {
 		return errors.Wrapf(err, "failed to parse prune filters %v", opt.Filter)
 	}
@@ -1015,29 +1019,78 @@ func (cm *cacheManager) pruneOnce(ctx context.Context, ch chan client.UsageInfo,
 	}
 
 	totalSize := int64(0)
-	if opt.KeepBytes != 0 {
+	if opt.KeepBytes != 0 || opt.ReservedSpace != 0 || opt.MinFreeSpace != 0 {
 		du, err := cm.DiskUsage(ctx, client.DiskUsageInfo{})
 		if err != nil {
 			return err
 		}
 		for _, ui := range du {
-			if ui.Shared {
+			if ui.Shared || opt.Tenant != "" && ui.Tenant != opt.Tenant {
 				continue
 			}
 			totalSize += ui.Size
 		}
 	}
 
+	free := int64(-1)
+	if opt.MinFreeSpace != 0 {
+		f, err := diskFree(cm.mountPoolRoot)
+		if err != nil {
+			bklog.G(ctx).Warnf("ignoring free space target of prune policy: %v", err)
+		} else {
+			free = f
+		}
+	}
+
+	keepBytes, ok := pruneKeepBytes(opt, totalSize, free)
+	if !ok {
+		return nil
+	}
+
 	return cm.prune(ctx, ch, pruneOpt{
 		filter:       filter,
 		all:          opt.All,
 		checkShared:  check,
 		keepDuration: opt.KeepDuration,
-		keepBytes:    opt.KeepBytes,
+		keepBytes:    keepBytes,
 		totalSize:    totalSize,
+		tenant:       opt.Tenant,
 	})
 }
 
+// pruneKeepBytes returns the size the build cache is pruned down to for the
+// policy. Zero means that all matching records are removed. The free disk
+// space is negative if it is unknown. ok is false if nothing needs to be
+// pruned.
+func pruneKeepBytes(opt client.PruneInfo, totalSize, free int64) (keep int64, ok bool) {
+	keep = opt.KeepBytes
+	if opt.MinFreeSpace != 0 && free < 0 && keep == 0 {
+		return 0, false
+	}
+	if opt.MinFreeSpace != 0 && free >= 0 {
+		if need := opt.MinFreeSpace - free; need > 0 {
+			target := totalSize - need
+			if target < 1 {
+				target = 1 // remove records one by one until the target is met
+			}
+			if keep == 0 || target < keep {
+				keep = target
+			}
+		} else if keep == 0 {
+			return 0, false
+		}
+	}
+	if opt.ReservedSpace != 0 {
+		if totalSize <= opt.ReservedSpace {
+			return 0, false
+		}
+		if keep < opt.ReservedSpace {
+			keep = opt.ReservedSpace
+		}
+	}
+	return keep, true
+}
+
 func (cm *cacheManager) prune(ctx context.Context, ch chan client.UsageInfo, opt pruneOpt) error {
 	var toDelete []*deleteRecord
 
@@ -1088,10 +1141,18 @@ func (cm *cacheManager) prune(ctx context.Context, ch chan client.UsageInfo, opt
 			}
 
 			c := &client.UsageInfo{
-				ID:         cr.ID(),
-				Mutable:    cr.mutable,
-				RecordType: recordType,
-				Shared:     shared,
+				ID:          cr.ID(),
+				Mutable:     cr.mutable,
+				RecordType:  recordType,
+				Shared:      shared,
+				CreatedAt:   cr.GetCreatedAt(),
+				Description: cr.GetDescription(),
+				Tenant:      cr.GetTenant(),
+			}
+
+			if opt.tenant != "" && c.Tenant != opt.tenant {
+				cr.mu.Unlock()
+				continue
 			}
 
 			usageCount, lastUsedAt := cr.getLastUsed()
@@ -1105,7 +1166,7 @@ func (cm *cacheManager) prune(ctx context.Context, ch chan client.UsageInfo, opt
 				}
 			}
 
-			if opt.filter.Match(adaptUsageInfo(c)) {
+			if opt.filter.Match(c) {
 				toDelete = append(toDelete, &deleteRecord{
 					cacheRecord: cr,
 					lastUsedAt:  c.LastUsedAt,
@@ -1289,10 +1350,11 @@ type cacheUsageInfo struct {
 	recordType  client.UsageRecordType
 	shared      bool
 	parentChain []digest.Digest
+	tenant      string
 }
 
 func (cm *cacheManager) DiskUsage(ctx context.Context, opt client.DiskUsageInfo) ([]*client.UsageInfo, error) {
-	filter, err := filters.ParseAll(opt.Filter...)
+	filter, err := parseUsageFilters(time.Now(), opt.Filter)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to parse diskusage filters %v", opt.Filter)
 	}
@@ -1322,6 +1384,7 @@ func (cm *cacheManager) DiskUsage(ctx context.Context, opt client.DiskUsageInfo)
 			doubleRef:   cr.equalImmutable != nil,
 			recordType:  cr.GetRecordType(),
 			parentChain: cr.layerDigestChain(),
+			tenant:      cr.GetTenant(),
 		}
 		if c.recordType == "" {
 			c.recordType = client.UsageRecordTypeRegular
@@ -1389,8 +1452,9 @@ func (cm *cacheManager) DiskUsage(ctx context.Context, opt client.DiskUsageInfo)
 			UsageCount:  cr.usageCount,
 			RecordType:  cr.recordType,
 			Shared:      cr.shared,
+			Tenant:      cr.tenant,
 		}
-		if filter.Match(adaptUsageInfo(c)) {
+		if filter.Match(c) {
 			du = append(du, c)
 		}
 	}
@@ -1493,6 +1557,16 @@ func setImageRefMetadata(m *cacheMetadata, opts ...RefOption) error {
 	return m.commitMetadata()
 }
 
+// withTenant records the tenant the record was created for, if any.
+func withTenant(name string) RefOption {
+	return func(m *cacheMetadata) error {
+		if name == "" {
+			return nil
+		}
+		return m.queueTenant(name)
+	}
+}
+
 func withSnapshotID(id string) RefOption {
 	return imageRefOption(func(m *cacheMetadata) error {
 		return m.queueSnapshotID(id)
@@ -1570,6 +1644,8 @@ func adaptUsageInfo(info *client.UsageInfo) filters.Adaptor {
 			return "", info.Shared
 		case "private":
 			return "", !info.Shared
+		case "tenant":
+			return info.Tenant, info.Tenant != ""
 		}
 
 		// TODO: add int/datetime/bytes support for more fields
@@ -1579,12 +1655,13 @@ func adaptUsageInfo(info *client.UsageInfo) filters.Adaptor {
 }
 
 type pruneOpt struct {
-	filter       filters.Filter
+	filter       usageFilter
 	all          bool
 	checkShared  ExternalRefChecker
 	keepDuration time.Duration
 	keepBytes    int64
 	totalSize    int64
+	tenant       string
 }
 
 type deleteRecord struct {
@@ -44,6 +44,7 @@ import (
 	"github.com/moby/buildkit/util/compression"
 	"github.com/moby/buildkit/util/contentutil"
 	"github.com/moby/buildkit/util/leaseutil"
+	"github.com/moby/buildkit/util/tenant"
 	"github.com/moby/buildkit/util/winlayers"
 	digest "github.com/opencontainers/go-digest"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
@@ -972,6 +973,58 @@ func TestPrune(t *testing.T) {
 	require.Equal(t, 0, len(dirs))
 }
 
+func TestPruneTenant(t *testing.T) {
+	t.Parallel()
+	ctx := namespaces.WithNamespace(context.Background(), "buildkit-test")
+
+	tmpdir, err := ioutil.TempDir("", "cachemanager")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	snapshotter, err := native.NewSnapshotter(filepath.Join(tmpdir, "snapshots"))
+	require.NoError(t, err)
+
+	co, cleanup, err := newCacheManager(ctx, cmOpt{
+		snapshotter:     snapshotter,
+		snapshotterName: "native",
+	})
+	require.NoError(t, err)
+
+	defer cleanup()
+	cm := co.manager
+
+	for _, name := range []string{"a", "b", ""} {
+		active, err := cm.New(tenant.WithName(ctx, name), nil, nil, CachePolicyRetain)
+		require.NoError(t, err)
+		snap, err := active.Commit(ctx)
+		require.NoError(t, err)
+		require.NoError(t, snap.Release(ctx))
+	}
+
+	du, err := cm.DiskUsage(ctx, client.DiskUsageInfo{})
+	require.NoError(t, err)
+	tenants := map[string]int{}
+	for _, r := range du {
+		tenants[r.Tenant]++
+	}
+	require.Equal(t, map[string]int{"a": 1, "b": 1, "": 1}, tenants)
+
+	// prune of a tenant only deletes the records of the tenant
+	buf := pruneResultBuffer()
+	err = cm.Prune(ctx, buf.C, client.PruneInfo{Tenant: "a"})
+	buf.close()
+	require.NoError(t, err)
+
+	require.Equal(t, 1, len(buf.all))
+	checkDiskUsage(ctx, t, cm, 0, 2)
+
+	du, err = cm.DiskUsage(ctx, client.DiskUsageInfo{})
+	require.NoError(t, err)
+	for _, r := range du {
+		require.NotEqual(t, "a", r.Tenant)
+	}
+}
+
 func TestLazyCommit(t *testing.T) {
 	t.Parallel()
 
@@ -2656,3 +2709,39 @@ func isReadOnly(mnt mount.Mount) bool {
 	}
 	return false
 }
+
+func TestPruneKeepBytes(t *testing.T) {
+	t.Parallel()
+
+	const gb = 1 << 30
+	for _, tc := range []struct {
+		name      string
+		opt       client.PruneInfo
+		totalSize int64
+		free      int64
+		keep      int64
+		ok        bool
+	}{
+		{name: "all", ok: true},
+		{name: "keepbytes", opt: client.PruneInfo{KeepBytes: 5 * gb}, totalSize: 10 * gb, free: -1, keep: 5 * gb, ok: true},
+		{name: "enough free space", opt: client.PruneInfo{MinFreeSpace: 2 * gb}, totalSize: 10 * gb, free: 3 * gb},
+		{name: "unknown free space", opt: client.PruneInfo{MinFreeSpace: 2 * gb}, totalSize: 10 * gb, free: -1},
+		{name: "free space target", opt: client.PruneInfo{MinFreeSpace: 4 * gb}, totalSize: 10 * gb, free: 1 * gb, keep: 7 * gb, ok: true},
+		{name: "stricter keepbytes", opt: client.PruneInfo{MinFreeSpace: 4 * gb, KeepBytes: 6 * gb}, totalSize: 10 * gb, free: 1 * gb, keep: 6 * gb, ok: true},
+		{name: "stricter free space", opt: client.PruneInfo{MinFreeSpace: 4 * gb, KeepBytes: 8 * gb}, totalSize: 10 * gb, free: 1 * gb, keep: 7 * gb, ok: true},
+		{name: "free space above cache size", opt: client.PruneInfo{MinFreeSpace: 20 * gb}, totalSize: 10 * gb, free: 1 * gb, keep: 1, ok: true},
+		{name: "keepbytes with enough free space", opt: client.PruneInfo{MinFreeSpace: 1 * gb, KeepBytes: 5 * gb}, totalSize: 10 * gb, free: 3 * gb, keep: 5 * gb, ok: true},
+		{name: "reserved", opt: client.PruneInfo{ReservedSpace: 4 * gb}, totalSize: 10 * gb, free: -1, keep: 4 * gb, ok: true},
+		{name: "under reserved", opt: client.PruneInfo{ReservedSpace: 4 * gb, KeepBytes: 1 * gb}, totalSize: 3 * gb, free: -1},
+		{name: "reserved floor", opt: client.PruneInfo{ReservedSpace: 4 * gb, MinFreeSpace: 8 * gb}, totalSize: 10 * gb, free: 1 * gb, keep: 4 * gb, ok: true},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			keep, ok := pruneKeepBytes(tc.opt, tc.totalSize, tc.free)
+			require.Equal(t, tc.ok, ok)
+			if ok {
+				require.Equal(t, tc.keep, keep)
+			}
+		})
+	}
+}
@@ -38,6 +38,7 @@ const keyImageRefs = "cache.imageRefs"
 const keyDeleted = "cache.deleted"
 const keyBlobSize = "cache.blobsize" // the packed blob size as specified in the oci descriptor
 const keyURLs = "cache.layer.urls"
+const keyTenant = "cache.tenant"
 
 // Indexes
 const blobchainIndex = "blobchainid:"
@@ -179,6 +180,16 @@ func (md *cacheMetadata) queueRecordType(value client.UsageRecordType) error {
 	return md.queueValue(keyRecordType, value, "")
 }
 
+// GetTenant returns the tenant the record was created for, empty if it was
+// created for no tenant.
+func (md *cacheMetadata) GetTenant() string {
+	return md.GetString(keyTenant)
+}
+
+func (md *cacheMetadata) queueTenant(name string) error {
+	return md.queueValue(keyTenant, name, "")
+}
+
 func (md *cacheMetadata) SetCreatedAt(tm time.Time) error {
 	return md.setTime(keyCreatedAt, tm, "")
 }
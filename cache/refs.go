@@ -27,6 +27,7 @@ import (
 	"github.com/moby/buildkit/util/compression"
 	"github.com/moby/buildkit/util/flightcontrol"
 	"github.com/moby/buildkit/util/leaseutil"
+	"github.com/moby/buildkit/util/overlaybd"
 	"github.com/moby/buildkit/util/progress"
 	"github.com/moby/buildkit/util/winlayers"
 	"github.com/moby/sys/mountinfo"
@@ -882,7 +883,7 @@ func filterAnnotationsForSave(a map[string]string) (b map[string]string) {
 	if a == nil {
 		return nil
 	}
-	for _, k := range append(eStargzAnnotations, containerdUncompressed) {
+	for _, k := range append(append(eStargzAnnotations, overlaybd.Annotations...), containerdUncompressed) {
 		v, ok := a[k]
 		if !ok {
 			continue
@@ -924,7 +925,7 @@ func (sr *immutableRef) Mount(ctx context.Context, readonly bool, s session.Grou
 	}
 
 	var mnt snapshot.Mountable
-	if sr.cm.Snapshotter.Name() == "stargz" {
+	if isRemoteSnapshotter(sr.cm.Snapshotter.Name()) {
 		if err := sr.withRemoteSnapshotLabelsStargzMode(ctx, s, func() {
 			mnt, rerr = sr.mount(ctx, s)
 		}); err != nil {
@@ -948,7 +949,7 @@ func (sr *immutableRef) Extract(ctx context.Context, s session.Group) (rerr erro
 		return nil
 	}
 
-	if sr.cm.Snapshotter.Name() == "stargz" {
+	if isRemoteSnapshotter(sr.cm.Snapshotter.Name()) {
 		if err := sr.withRemoteSnapshotLabelsStargzMode(ctx, s, func() {
 			if rerr = sr.prepareRemoteSnapshotsStargzMode(ctx, s); rerr != nil {
 				return
@@ -963,6 +964,19 @@ func (sr *immutableRef) Extract(ctx context.Context, s session.Group) (rerr erro
 	return sr.unlazy(ctx, sr.descHandlers, sr.progress, s, true)
 }
 
+// remoteSnapshotters are the names of the snapshotters preparing the layers
+// of images as remote snapshots, mounted from the registries without pulling
+// the layers.
+var remoteSnapshotters = map[string]struct{}{
+	"stargz":    {},
+	"overlaybd": {},
+}
+
+func isRemoteSnapshotter(name string) bool {
+	_, ok := remoteSnapshotters[name]
+	return ok
+}
+
 func (sr *immutableRef) withRemoteSnapshotLabelsStargzMode(ctx context.Context, s session.Group, f func()) error {
 	dhs := sr.descHandlers
 	for _, r := range sr.layerChain() {
@@ -1432,7 +1446,7 @@ func (sr *mutableRef) Mount(ctx context.Context, readonly bool, s session.Group)
 	}
 
 	var mnt snapshot.Mountable
-	if sr.cm.Snapshotter.Name() == "stargz" && sr.layerParent != nil {
+	if isRemoteSnapshotter(sr.cm.Snapshotter.Name()) && sr.layerParent != nil {
 		if err := sr.layerParent.withRemoteSnapshotLabelsStargzMode(ctx, s, func() {
 			mnt, rerr = sr.mount(ctx, s)
 		}); err != nil {
@@ -13,6 +13,7 @@ import (
 	"github.com/moby/buildkit/util/compression"
 	"github.com/moby/buildkit/util/contentutil"
 	"github.com/moby/buildkit/util/estargz"
+	"github.com/moby/buildkit/util/overlaybd"
 	"github.com/moby/buildkit/util/push"
 	"github.com/moby/buildkit/util/resolver"
 	"github.com/moby/buildkit/util/resolver/limited"
@@ -127,6 +128,9 @@ func (dsl *withDistributionSourceLabel) SnapshotLabels(descs []ocispecs.Descript
 	for k, v := range estargz.SnapshotLabels(dsl.ref, descs, index) {
 		labels[k] = v
 	}
+	for k, v := range overlaybd.SnapshotLabels(dsl.ref, descs, index) {
+		labels[k] = v
+	}
 	return labels
 }
 
@@ -79,6 +79,52 @@ func ReadFile(ctx context.Context, mount snapshot.Mountable, req ReadRequest) ([
 	return dt, err
 }
 
+// ReadFileStream opens a file of the mount for reading. The mount stays
+// mounted until the returned reader is closed.
+func ReadFileStream(ctx context.Context, mount snapshot.Mountable, req ReadRequest) (io.ReadCloser, error) {
+	lm := snapshot.LocalMounter(mount)
+
+	root, err := lm.Mount()
+	if err != nil {
+		return nil, err
+	}
+
+	fp, err := fs.RootPath(root, req.Filename)
+	if err != nil {
+		lm.Unmount()
+		return nil, errors.WithStack(err)
+	}
+	f, err := os.Open(fp)
+	if err != nil {
+		lm.Unmount()
+		return nil, errors.WithStack(err)
+	}
+
+	rc := &mountedFile{f: f, r: f, lm: lm}
+	if req.Range != nil {
+		rc.r = io.NewSectionReader(f, int64(req.Range.Offset), int64(req.Range.Length))
+	}
+	return rc, nil
+}
+
+type mountedFile struct {
+	f  *os.File
+	r  io.Reader
+	lm snapshot.Mounter
+}
+
+func (f *mountedFile) Read(p []byte) (int, error) {
+	return f.r.Read(p)
+}
+
+func (f *mountedFile) Close() error {
+	err := f.f.Close()
+	if err1 := f.lm.Unmount(); err == nil {
+		err = err1
+	}
+	return errors.WithStack(err)
+}
+
 type ReadDirRequest struct {
 	Path           string
 	IncludePattern string
@@ -117,6 +163,31 @@ func ReadDir(ctx context.Context, mount snapshot.Mountable, req ReadDirRequest)
 	return rd, err
 }
 
+// WalkDir calls fn for every file in the directory tree of the mount at the
+// requested path. The paths of the files are relative to the directory.
+func WalkDir(ctx context.Context, mount snapshot.Mountable, req ReadDirRequest, fn func(*fstypes.Stat) error) error {
+	var wo fsutil.WalkOpt
+	if req.IncludePattern != "" {
+		wo.IncludePatterns = append(wo.IncludePatterns, req.IncludePattern)
+	}
+	return withMount(ctx, mount, func(root string) error {
+		fp, err := fs.RootPath(root, req.Path)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		return fsutil.Walk(ctx, fp, &wo, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return errors.Wrapf(err, "walking %q", root)
+			}
+			stat, ok := info.Sys().(*fstypes.Stat)
+			if !ok {
+				return errors.Errorf("expected a *fsutil.Stat but got %T", info.Sys())
+			}
+			return fn(stat)
+		})
+	})
+}
+
 func StatFile(ctx context.Context, mount snapshot.Mountable, path string) (*fstypes.Stat, error) {
 	var st *fstypes.Stat
 	err := withMount(ctx, mount, func(root string) error {
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/moby/buildkit/util/bklog"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// Attach reconnects to a build started with SolveOpt.Detach. It sends the
+// progress of the build to statusChan while it is running and returns the
+// result of the build once it has completed.
+func (c *Client) Attach(ctx context.Context, ref string, statusChan chan *SolveStatus) (*SolveResponse, error) {
+	defer func() {
+		if statusChan != nil {
+			close(statusChan)
+		}
+	}()
+
+	statusContext, cancelStatus := context.WithCancel(context.Background())
+	defer cancelStatus()
+
+	eg, ctx := errgroup.WithContext(ctx)
+
+	var res *SolveResponse
+	eg.Go(func() error {
+		defer func() {
+			go func() {
+				<-time.After(3 * time.Second)
+				cancelStatus()
+			}()
+		}()
+		resp, err := c.controlClient().Attach(ctx, &controlapi.AttachRequest{
+			Ref: ref,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to attach")
+		}
+		res = newSolveResponse(resp)
+		return nil
+	})
+
+	eg.Go(func() error {
+		// progress is not available for builds that have already completed
+		if err := c.streamStatus(statusContext, ref, statusChan); err != nil {
+			bklog.G(ctx).Debugf("failed to get status of %s: %v", ref, err)
+		}
+		return nil
+	})
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
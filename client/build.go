@@ -113,6 +113,22 @@ func (g *gatewayClientForBuild) StatFile(ctx context.Context, in *gatewayapi.Sta
 	return g.gateway.StatFile(ctx, in, opts...)
 }
 
+func (g *gatewayClientForBuild) ReadFileStream(ctx context.Context, in *gatewayapi.ReadFileRequest, opts ...grpc.CallOption) (gatewayapi.LLBBridge_ReadFileStreamClient, error) {
+	if err := g.caps.Supports(gatewayapi.CapReadFileStream); err != nil {
+		return nil, err
+	}
+	ctx = buildid.AppendToOutgoingContext(ctx, g.buildID)
+	return g.gateway.ReadFileStream(ctx, in, opts...)
+}
+
+func (g *gatewayClientForBuild) ReadDirRecursive(ctx context.Context, in *gatewayapi.ReadDirRequest, opts ...grpc.CallOption) (gatewayapi.LLBBridge_ReadDirRecursiveClient, error) {
+	if err := g.caps.Supports(gatewayapi.CapReadDirRecursive); err != nil {
+		return nil, err
+	}
+	ctx = buildid.AppendToOutgoingContext(ctx, g.buildID)
+	return g.gateway.ReadDirRecursive(ctx, in, opts...)
+}
+
 func (g *gatewayClientForBuild) Ping(ctx context.Context, in *gatewayapi.PingRequest, opts ...grpc.CallOption) (*gatewayapi.PongResponse, error) {
 	ctx = buildid.AppendToOutgoingContext(ctx, g.buildID)
 	return g.gateway.Ping(ctx, in, opts...)
@@ -155,6 +171,14 @@ func (g *gatewayClientForBuild) ExecProcess(ctx context.Context, opts ...grpc.Ca
 	return g.gateway.ExecProcess(ctx, opts...)
 }
 
+func (g *gatewayClientForBuild) Prefetch(ctx context.Context, in *gatewayapi.PrefetchRequest, opts ...grpc.CallOption) (*gatewayapi.PrefetchResponse, error) {
+	if err := g.caps.Supports(gatewayapi.CapGatewayPrefetch); err != nil {
+		return nil, err
+	}
+	ctx = buildid.AppendToOutgoingContext(ctx, g.buildID)
+	return g.gateway.Prefetch(ctx, in, opts...)
+}
+
 func (g *gatewayClientForBuild) Warn(ctx context.Context, in *gatewayapi.WarnRequest, opts ...grpc.CallOption) (*gatewayapi.WarnResponse, error) {
 	ctx = buildid.AppendToOutgoingContext(ctx, g.buildID)
 	return g.gateway.Warn(ctx, in)
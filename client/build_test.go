@@ -34,6 +34,7 @@ import (
 	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
+	fstypes "github.com/tonistiigi/fsutil/types"
 	"golang.org/x/crypto/ssh/agent"
 )
 
@@ -60,6 +61,7 @@ func TestClientGatewayIntegration(t *testing.T) {
 		testClientGatewayContainerSignal,
 		testWarnings,
 		testClientGatewayFrontendAttrs,
+		testClientGatewayReadFileStream,
 	), integration.WithMirroredImages(integration.OfficialImages("busybox:latest")))
 
 	integration.Run(t, integration.TestFuncs(
@@ -2057,6 +2059,68 @@ func testClientGatewayFrontendAttrs(t *testing.T, sb integration.Sandbox) {
 	checkAllReleasable(t, c, sb, true)
 }
 
+func testClientGatewayReadFileStream(t *testing.T, sb integration.Sandbox) {
+	requiresLinux(t)
+	c, err := New(sb.Context(), sb.Address())
+	require.NoError(t, err)
+	defer c.Close()
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1<<17)
+
+	b := func(ctx context.Context, c client.Client) (*client.Result, error) {
+		st := llb.Scratch().
+			File(llb.Mkdir("/dir/sub", 0700, llb.WithParents(true))).
+			File(llb.Mkfile("/dir/sub/big", 0600, data)).
+			File(llb.Mkfile("/dir/small", 0600, []byte("small")))
+		def, err := st.Marshal(sb.Context())
+		if err != nil {
+			return nil, err
+		}
+		res, err := c.Solve(ctx, client.SolveRequest{
+			Definition: def.ToPB(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		ref, err := res.SingleRef()
+		if err != nil {
+			return nil, err
+		}
+
+		rc, err := ref.ReadFileStream(ctx, client.ReadRequest{Filename: "/dir/sub/big"})
+		require.NoError(t, err)
+		dt, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		require.Equal(t, data, dt)
+
+		rc, err = ref.ReadFileStream(ctx, client.ReadRequest{
+			Filename: "/dir/sub/big",
+			Range:    &client.FileRange{Offset: 16, Length: 10},
+		})
+		require.NoError(t, err)
+		dt, err = io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		require.Equal(t, "0123456789", string(dt))
+
+		var paths []string
+		err = ref.ReadDirRecursive(ctx, client.ReadDirRequest{Path: "/dir"}, func(st *fstypes.Stat) error {
+			paths = append(paths, st.Path)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"small", "sub", "sub/big"}, paths)
+
+		return res, nil
+	}
+
+	_, err = c.Build(sb.Context(), SolveOpt{}, "", b, nil)
+	require.NoError(t, err)
+
+	checkAllReleasable(t, c, sb, true)
+}
+
 type nopCloser struct {
 	io.Writer
 }
@@ -0,0 +1,181 @@
+// Package buildimage builds images from a Dockerfile with the session
+// attachables most clients need: syncing of the local build context, registry
+// credentials from the Docker config, SSH agent forwarding and secrets.
+package buildimage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containerd/console"
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/auth/authprovider"
+	"github.com/moby/buildkit/session/secrets/secretsprovider"
+	"github.com/moby/buildkit/session/sshforward/sshprovider"
+	"github.com/moby/buildkit/util/entitlements"
+	"github.com/moby/buildkit/util/progress/progresswriter"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+const defaultFrontend = "dockerfile.v0"
+
+type Opt struct {
+	// ContextDir is the directory sent as the build context.
+	ContextDir string
+	// Dockerfile is the path of the Dockerfile. Defaults to Dockerfile in
+	// ContextDir.
+	Dockerfile string
+	// Target is the stage of the Dockerfile to build.
+	Target string
+	// BuildArgs are the values of the build arguments of the Dockerfile.
+	BuildArgs map[string]string
+	// FrontendAttrs are additional options for the frontend.
+	FrontendAttrs map[string]string
+	// Frontend defaults to the builtin Dockerfile frontend.
+	Frontend string
+
+	// Tags are the names of the image. The image is pushed to the registry of
+	// the tags if Push is set.
+	Tags []string
+	Push bool
+	// Exports replace the image exporter of Tags.
+	Exports []client.ExportEntry
+
+	CacheImports []client.CacheOptionsEntry
+	CacheExports []client.CacheOptionsEntry
+
+	// Secrets are exposed to RUN --mount=type=secret, e.g.
+	// {ID: "token", Env: "TOKEN"} for a secret from the environment.
+	Secrets []secretsprovider.Source
+	// SSH are the agents or keys exposed to RUN --mount=type=ssh. Use
+	// {ID: "default"} to forward the agent of $SSH_AUTH_SOCK.
+	SSH []sshprovider.AgentConfig
+	// Session are additional attachables for the session of the build.
+	Session []session.Attachable
+
+	AllowedEntitlements []entitlements.Entitlement
+
+	// Progress is the mode of the progress output: auto, tty, plain or quiet.
+	// Defaults to auto.
+	Progress string
+	// ProgressOutput defaults to os.Stderr.
+	ProgressOutput console.File
+}
+
+// Build builds the image described by opt and renders the progress of the
+// build.
+func Build(ctx context.Context, c *client.Client, opt Opt) (*client.SolveResponse, error) {
+	solveOpt, err := SolveOpt(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	out := opt.ProgressOutput
+	if out == nil {
+		out = os.Stderr
+	}
+	mode := opt.Progress
+	if mode == "" {
+		mode = "auto"
+	}
+	// not using shared context to not disrupt display but let it finish reporting errors
+	pw, err := progresswriter.NewPrinter(context.TODO(), out, mode)
+	if err != nil {
+		return nil, err
+	}
+	solveOpt.Session = append(solveOpt.Session, authprovider.NewDockerAuthProvider(out))
+
+	var resp *client.SolveResponse
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		var err error
+		resp, err = c.Solve(ctx, nil, *solveOpt, progresswriter.ResetTime(pw).Status())
+		return err
+	})
+	eg.Go(func() error {
+		<-pw.Done()
+		return pw.Err()
+	})
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// SolveOpt returns the options for solving opt with client.Solve. Registry
+// credentials are not included as the auth provider writes to the progress
+// output.
+func SolveOpt(opt Opt) (*client.SolveOpt, error) {
+	if opt.ContextDir == "" {
+		return nil, errors.New("build context directory is required")
+	}
+	dockerfile := opt.Dockerfile
+	if dockerfile == "" {
+		dockerfile = filepath.Join(opt.ContextDir, "Dockerfile")
+	}
+
+	frontend := opt.Frontend
+	if frontend == "" {
+		frontend = defaultFrontend
+	}
+	frontendAttrs := map[string]string{
+		"filename": filepath.Base(dockerfile),
+	}
+	if opt.Target != "" {
+		frontendAttrs["target"] = opt.Target
+	}
+	for k, v := range opt.BuildArgs {
+		frontendAttrs["build-arg:"+k] = v
+	}
+	for k, v := range opt.FrontendAttrs {
+		frontendAttrs[k] = v
+	}
+
+	exports := opt.Exports
+	if len(exports) == 0 && len(opt.Tags) > 0 {
+		attrs := map[string]string{
+			"name": strings.Join(opt.Tags, ","),
+		}
+		if opt.Push {
+			attrs["push"] = "true"
+		}
+		exports = []client.ExportEntry{{
+			Type:  client.ExporterImage,
+			Attrs: attrs,
+		}}
+	}
+
+	attachable := append([]session.Attachable{}, opt.Session...)
+	if len(opt.Secrets) > 0 {
+		store, err := secretsprovider.NewStore(opt.Secrets)
+		if err != nil {
+			return nil, err
+		}
+		attachable = append(attachable, secretsprovider.NewSecretProvider(store))
+	}
+	if len(opt.SSH) > 0 {
+		sp, err := sshprovider.NewSSHAgentProvider(opt.SSH)
+		if err != nil {
+			return nil, err
+		}
+		attachable = append(attachable, sp)
+	}
+
+	return &client.SolveOpt{
+		Exports: exports,
+		LocalDirs: map[string]string{
+			"context":    opt.ContextDir,
+			"dockerfile": filepath.Dir(dockerfile),
+		},
+		Frontend:            frontend,
+		FrontendAttrs:       frontendAttrs,
+		CacheExports:        opt.CacheExports,
+		CacheImports:        opt.CacheImports,
+		Session:             attachable,
+		AllowedEntitlements: opt.AllowedEntitlements,
+	}, nil
+}
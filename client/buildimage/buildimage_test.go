@@ -0,0 +1,54 @@
+package buildimage
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/session/secrets/secretsprovider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSolveOpt(t *testing.T) {
+	_, err := SolveOpt(Opt{})
+	require.Error(t, err)
+
+	so, err := SolveOpt(Opt{
+		ContextDir: "/src",
+		Target:     "release",
+		BuildArgs:  map[string]string{"VERSION": "1.0"},
+		Tags:       []string{"example.com/foo:latest", "example.com/foo:1.0"},
+		Push:       true,
+		Secrets:    []secretsprovider.Source{{ID: "token", Env: "TOKEN"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, defaultFrontend, so.Frontend)
+	require.Equal(t, map[string]string{
+		"context":    "/src",
+		"dockerfile": "/src",
+	}, so.LocalDirs)
+	require.Equal(t, map[string]string{
+		"filename":          "Dockerfile",
+		"target":            "release",
+		"build-arg:VERSION": "1.0",
+	}, so.FrontendAttrs)
+	require.Equal(t, []client.ExportEntry{{
+		Type: client.ExporterImage,
+		Attrs: map[string]string{
+			"name": "example.com/foo:latest,example.com/foo:1.0",
+			"push": "true",
+		},
+	}}, so.Exports)
+	require.Len(t, so.Session, 1)
+
+	so, err = SolveOpt(Opt{
+		ContextDir: "/src",
+		Dockerfile: "/build/Dockerfile.release",
+		Tags:       []string{"foo"},
+		Exports:    []client.ExportEntry{{Type: client.ExporterLocal, OutputDir: "/out"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "/build", so.LocalDirs["dockerfile"])
+	require.Equal(t, "Dockerfile.release", so.FrontendAttrs["filename"])
+	require.Equal(t, client.ExporterLocal, so.Exports[0].Type)
+	require.Len(t, so.Session, 0)
+}
@@ -0,0 +1,26 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/pkg/errors"
+)
+
+// Cancel cancels the running build with the ref. During the grace period no
+// new step of the build is started, its running processes receive SIGTERM
+// and the exporters already running can finish. The build is then canceled
+// and its processes are killed. The reason is reported in the progress and
+// the error of the build.
+func (c *Client) Cancel(ctx context.Context, ref, reason string, grace time.Duration) error {
+	_, err := c.controlClient().Cancel(ctx, &controlapi.CancelRequest{
+		Ref:         ref,
+		Reason:      reason,
+		GracePeriod: int64(grace),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to cancel build")
+	}
+	return nil
+}
@@ -81,6 +81,9 @@ func New(ctx context.Context, address string, opts ...ClientOpt) (*Client, error
 		if sd, ok := o.(*withSessionDialer); ok {
 			sessionDialer = sd.dialer
 		}
+		if wt, ok := o.(*withToken); ok {
+			gopts = append(gopts, grpc.WithPerRPCCredentials(wt))
+		}
 	}
 
 	if !customTracer {
@@ -262,6 +265,26 @@ type withTracerDelegate struct {
 	TracerDelegate
 }
 
+// WithToken sends the token in the authorization metadata of the requests,
+// identifying the tenant of the client on a daemon shared by tenants.
+func WithToken(token string) ClientOpt {
+	return &withToken{token: token}
+}
+
+type withToken struct {
+	token string
+}
+
+func (t *withToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.token}, nil
+}
+
+// RequireTransportSecurity allows the token to be sent over unix sockets and
+// connection helpers.
+func (t *withToken) RequireTransportSecurity() bool {
+	return false
+}
+
 func WithSessionDialer(dialer func(context.Context, string, map[string][]string) (net.Conn, error)) ClientOpt {
 	return &withSessionDialer{dialer}
 }
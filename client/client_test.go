@@ -161,6 +161,7 @@ func TestIntegration(t *testing.T) {
 		testUncompressedLocalCacheImportExport,
 		testUncompressedRegistryCacheImportExport,
 		testStargzLazyRegistryCacheImportExport,
+		testDetachAttach,
 	)
 	tests = append(tests, diffOpTestCases()...)
 	integration.Run(t, tests, mirrors)
@@ -748,6 +749,40 @@ func testPushByDigest(t *testing.T, sb integration.Sandbox) {
 	require.Equal(t, resp.ExporterResponse[exptypes.ExporterImageDigestKey], desc.Digest.String())
 	require.Equal(t, images.MediaTypeDockerSchema2Manifest, desc.MediaType)
 	require.True(t, desc.Size > 0)
+
+	// the tag is skipped and the digest is written to the digest file
+	destDir, err := ioutil.TempDir("", "buildkit")
+	require.NoError(t, err)
+	defer os.RemoveAll(destDir)
+	digestFile := filepath.Join(destDir, "digest")
+
+	resp, err = c.Solve(sb.Context(), def, SolveOpt{
+		Exports: []ExportEntry{
+			{
+				Type: ExporterImage,
+				Attrs: map[string]string{
+					"name":                 name + ":v1",
+					"push":                 "true",
+					"push-by-digest":       "true",
+					"name-canonical":       "true",
+					ExporterAttrDigestFile: digestFile,
+				},
+			},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	_, _, err = contentutil.ProviderFromRef(name + ":v1")
+	require.Error(t, err)
+
+	dgst := resp.ExporterResponse[exptypes.ExporterImageDigestKey]
+	dt, err := ioutil.ReadFile(digestFile)
+	require.NoError(t, err)
+	require.Equal(t, dgst, string(dt))
+	require.Equal(t, name+"@"+dgst, resp.ExporterResponse[exptypes.ExporterImageNameCanonicalKey])
+
+	_, _, err = contentutil.ProviderFromRef(name + "@" + dgst)
+	require.NoError(t, err)
 }
 
 func testSecurityMode(t *testing.T, sb integration.Sandbox) {
@@ -1309,6 +1344,35 @@ func testFileOpMkdirMkfile(t *testing.T, sb integration.Sandbox) {
 	require.Equal(t, []byte("contents"), dt)
 }
 
+func testDetachAttach(t *testing.T, sb integration.Sandbox) {
+	requiresLinux(t)
+	c, err := New(sb.Context(), sb.Address())
+	require.NoError(t, err)
+	defer c.Close()
+
+	st := llb.Image("busybox:latest").Run(llb.Shlex("sleep 3"))
+
+	def, err := st.Marshal(sb.Context())
+	require.NoError(t, err)
+
+	// the build continues when the client gives up on it
+	ref := identity.NewID()
+	ctx, cancel := context.WithTimeout(sb.Context(), time.Second)
+	defer cancel()
+	_, err = c.Solve(ctx, def, SolveOpt{
+		Ref:    ref,
+		Detach: true,
+	}, nil)
+	require.Error(t, err)
+
+	_, err = c.Attach(sb.Context(), ref, nil)
+	require.NoError(t, err)
+
+	_, err = c.Attach(sb.Context(), identity.NewID(), nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no detached build")
+}
+
 func testFileOpCopyRm(t *testing.T, sb integration.Sandbox) {
 	requiresLinux(t)
 	c, err := New(sb.Context(), sb.Address())
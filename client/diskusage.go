@@ -22,6 +22,9 @@ type UsageInfo struct {
 	Description string
 	RecordType  UsageRecordType
 	Shared      bool
+	// Tenant is the tenant the record was created for on a daemon shared by
+	// tenants.
+	Tenant string
 }
 
 func (c *Client) DiskUsage(ctx context.Context, opts ...DiskUsageOption) ([]*UsageInfo, error) {
@@ -51,6 +54,7 @@ func (c *Client) DiskUsage(ctx context.Context, opts ...DiskUsageOption) ([]*Usa
 			LastUsedAt:  d.LastUsedAt,
 			RecordType:  UsageRecordType(d.RecordType),
 			Shared:      d.Shared,
+			Tenant:      d.Tenant,
 		})
 	}
 
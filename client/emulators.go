@@ -0,0 +1,36 @@
+package client
+
+import (
+	"context"
+
+	controlapi "github.com/moby/buildkit/api/services/control"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// EmulatorInfo contains information about an emulator of the daemon host
+type EmulatorInfo struct {
+	Platform ocispecs.Platform `json:"platform"`
+	// Type is "binfmt" for the emulators registered in the kernel, or
+	// "static" for the buildkit-qemu-* binaries.
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
+// ListEmulators lists the emulators available to run the processes of the
+// platforms the daemon host can't run natively.
+func (c *Client) ListEmulators(ctx context.Context) ([]*EmulatorInfo, error) {
+	resp, err := c.controlClient().ListEmulators(ctx, &controlapi.ListEmulatorsRequest{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list emulators")
+	}
+	var out []*EmulatorInfo
+	for _, e := range resp.Emulators {
+		out = append(out, &EmulatorInfo{
+			Platform: e.Platform.Spec(),
+			Type:     e.Type,
+			Path:     e.Path,
+		})
+	}
+	return out, nil
+}
@@ -1,5 +1,12 @@
 package client
 
+import (
+	"io/ioutil"
+
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	"github.com/pkg/errors"
+)
+
 const (
 	ExporterImage  = "image"
 	ExporterLocal  = "local"
@@ -7,3 +14,58 @@ const (
 	ExporterOCI    = "oci"
 	ExporterDocker = "docker"
 )
+
+// ExporterAttrDigestFile is the attribute of the image, oci and docker
+// exporters handled by the client: the digest of the exported image is
+// written to the file at its path when the build completes. It lets the
+// images pushed by digest be tagged later, e.g. after they are tested.
+const ExporterAttrDigestFile = "digest-file"
+
+// splitDigestFile returns the exports without their digest-file attribute
+// and the path of the digest file, if one of them sets it.
+func splitDigestFile(exports []ExportEntry) ([]ExportEntry, string, error) {
+	var digestFile string
+	out := make([]ExportEntry, 0, len(exports))
+	for _, ex := range exports {
+		p, ok := ex.Attrs[ExporterAttrDigestFile]
+		if !ok {
+			out = append(out, ex)
+			continue
+		}
+		switch ex.Type {
+		case ExporterImage, ExporterOCI, ExporterDocker:
+		default:
+			return nil, "", errors.Errorf("%s is not supported by %s exporter", ExporterAttrDigestFile, ex.Type)
+		}
+		if p == "" {
+			return nil, "", errors.Errorf("%s requires a path", ExporterAttrDigestFile)
+		}
+		if digestFile != "" {
+			// the responses of the exporters are merged
+			return nil, "", errors.Errorf("%s can only be set for a single exporter", ExporterAttrDigestFile)
+		}
+		digestFile = p
+		attrs := make(map[string]string, len(ex.Attrs)-1)
+		for k, v := range ex.Attrs {
+			if k != ExporterAttrDigestFile {
+				attrs[k] = v
+			}
+		}
+		ex.Attrs = attrs
+		out = append(out, ex)
+	}
+	return out, digestFile, nil
+}
+
+// writeDigestFile writes the digest of the exported image of the exporter
+// response to the file p.
+func writeDigestFile(p string, exporterResponse map[string]string) error {
+	dgst, ok := exporterResponse[exptypes.ExporterImageDigestKey]
+	if !ok {
+		return errors.Errorf("failed to write %s: the exporter didn't return the image digest", p)
+	}
+	if err := ioutil.WriteFile(p, []byte(dgst), 0644); err != nil {
+		return errors.Wrapf(err, "failed to write %s", p)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitDigestFile(t *testing.T) {
+	attrs := map[string]string{"name": "example", ExporterAttrDigestFile: "/tmp/digest"}
+	exports, p, err := splitDigestFile([]ExportEntry{
+		{Type: ExporterLocal, OutputDir: "/tmp/out"},
+		{Type: ExporterImage, Attrs: attrs},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "/tmp/digest", p)
+	require.Equal(t, []ExportEntry{
+		{Type: ExporterLocal, OutputDir: "/tmp/out"},
+		{Type: ExporterImage, Attrs: map[string]string{"name": "example"}},
+	}, exports)
+	// the attributes of the caller are not modified
+	require.Contains(t, attrs, ExporterAttrDigestFile)
+
+	_, p, err = splitDigestFile([]ExportEntry{{Type: ExporterImage, Attrs: map[string]string{"name": "example"}}})
+	require.NoError(t, err)
+	require.Equal(t, "", p)
+
+	_, _, err = splitDigestFile([]ExportEntry{{Type: ExporterLocal, Attrs: map[string]string{ExporterAttrDigestFile: "/tmp/digest"}}})
+	require.Error(t, err)
+
+	_, _, err = splitDigestFile([]ExportEntry{
+		{Type: ExporterImage, Attrs: map[string]string{ExporterAttrDigestFile: "/tmp/digest"}},
+		{Type: ExporterOCI, Attrs: map[string]string{ExporterAttrDigestFile: "/tmp/digest2"}},
+	})
+	require.Error(t, err)
+}
+
+func TestWriteDigestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "buildkit-digest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	p := filepath.Join(dir, "digest")
+
+	require.NoError(t, writeDigestFile(p, map[string]string{exptypes.ExporterImageDigestKey: "sha256:abcd"}))
+	dt, err := ioutil.ReadFile(p)
+	require.NoError(t, err)
+	require.Equal(t, "sha256:abcd", string(dt))
+
+	require.Error(t, writeDigestFile(p, map[string]string{}))
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/pkg/errors"
+)
+
+// GCRun is a garbage collection run of the build cache.
+type GCRun struct {
+	// Trigger is what started the run: build, schedule or api.
+	Trigger        string
+	StartedAt      *time.Time
+	CompletedAt    *time.Time
+	ReclaimedBytes int64
+	NumRecords     int64
+	Error          string
+}
+
+// RunGC runs the garbage collection policies of the workers and waits for
+// the run to complete.
+func (c *Client) RunGC(ctx context.Context) (*GCRun, error) {
+	resp, err := c.controlClient().RunGC(ctx, &controlapi.RunGCRequest{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run gc")
+	}
+	return fromAPIGCRun(resp), nil
+}
+
+// GCRuns returns the most recent garbage collection runs, oldest first.
+func (c *Client) GCRuns(ctx context.Context) ([]*GCRun, error) {
+	resp, err := c.controlClient().ListGCRuns(ctx, &controlapi.ListGCRunsRequest{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list gc runs")
+	}
+	runs := make([]*GCRun, 0, len(resp.Runs))
+	for _, r := range resp.Runs {
+		runs = append(runs, fromAPIGCRun(r))
+	}
+	return runs, nil
+}
+
+func fromAPIGCRun(r *controlapi.GCRun) *GCRun {
+	return &GCRun{
+		Trigger:        r.Trigger,
+		StartedAt:      r.StartedAt,
+		CompletedAt:    r.CompletedAt,
+		ReclaimedBytes: r.ReclaimedBytes,
+		NumRecords:     r.NumRecords,
+		Error:          r.Error,
+	}
+}
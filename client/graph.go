@@ -16,6 +16,44 @@ type Vertex struct {
 	Cached        bool
 	Error         string
 	ProgressGroup *pb.ProgressGroup
+	// CacheMiss is set on executed vertexes and explains why they were not
+	// loaded from the cache.
+	CacheMiss *CacheMiss
+}
+
+// CacheMissReason is the kind of a cache miss.
+type CacheMissReason string
+
+const (
+	// CacheMissIgnoreCache is a vertex executed because the cache was
+	// disabled for it.
+	CacheMissIgnoreCache CacheMissReason = "ignore-cache"
+	// CacheMissDefinition is a vertex whose definition has no match in the
+	// cache, e.g. a changed command.
+	CacheMissDefinition CacheMissReason = "definition"
+	// CacheMissInput is a vertex whose input was rebuilt.
+	CacheMissInput CacheMissReason = "input"
+	// CacheMissInputContent is a vertex whose input content has no match in
+	// the cache, e.g. changed files of a local source.
+	CacheMissInputContent CacheMissReason = "input-content"
+	// CacheMissInputs is a vertex whose inputs all have a match in the cache
+	// but never together.
+	CacheMissInputs CacheMissReason = "inputs"
+	// CacheMissNoResult is a vertex whose cache key matched but whose result
+	// is no longer in the cache, e.g. after a prune.
+	CacheMissNoResult CacheMissReason = "no-result"
+)
+
+// CacheMiss explains why a vertex was executed instead of loaded from the
+// cache.
+type CacheMiss struct {
+	Reason CacheMissReason `json:"reason"`
+	// Input is the index of the input the miss is about, -1 for none.
+	Input int `json:"input"`
+	// Digest is the checksum that had no match in the cache.
+	Digest digest.Digest `json:"digest,omitempty"`
+	// Message is the human readable explanation of the miss.
+	Message string `json:"message,omitempty"`
 }
 
 type VertexStatus struct {
@@ -56,4 +94,26 @@ type SolveStatus struct {
 type SolveResponse struct {
 	// ExporterResponse is also used for CacheExporter
 	ExporterResponse map[string]string
+	// CacheStatus is set for dry runs
+	CacheStatus []*VertexCacheStatus
+}
+
+// VertexCacheStatus reports if a vertex would be loaded from the cache.
+// Uncertain is set if the vertex is not cached but its cache key depends on
+// the contents of an input that is only known after building it.
+type VertexCacheStatus struct {
+	Digest    digest.Digest
+	Name      string
+	Cached    bool
+	Uncertain bool
+	// Local and Remote report if the result is in the local cache or in one
+	// of the imported caches.
+	Local  bool
+	Remote bool
+	// Key is the cache key of the vertex chained from its CacheMapDigests
+	// and the keys of its Inputs. Inputs matched by the checksum of their
+	// contents have an empty key.
+	Key             digest.Digest
+	CacheMapDigests []digest.Digest
+	Inputs          []digest.Digest
 }
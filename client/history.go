@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/pkg/errors"
+)
+
+// BuildHistoryRecord describes a completed build
+type BuildHistoryRecord struct {
+	Ref               string                 `json:"ref"`
+	Frontend          string                 `json:"frontend,omitempty"`
+	FrontendAttrs     map[string]string      `json:"frontendAttrs,omitempty"`
+	Exporters         []BuildHistoryExporter `json:"exporters,omitempty"`
+	ExporterResponse  map[string]string      `json:"exporterResponse,omitempty"`
+	CreatedAt         *time.Time             `json:"createdAt,omitempty"`
+	CompletedAt       *time.Time             `json:"completedAt,omitempty"`
+	Error             string                 `json:"error,omitempty"`
+	NumTotalSteps     int                    `json:"numTotalSteps"`
+	NumCachedSteps    int                    `json:"numCachedSteps"`
+	NumCompletedSteps int                    `json:"numCompletedSteps"`
+	// Logs is set if the progress of the build can be retrieved with
+	// BuildHistoryLogs.
+	Logs bool `json:"logs"`
+}
+
+type BuildHistoryExporter struct {
+	Type  string            `json:"type"`
+	Attrs map[string]string `json:"attrs,omitempty"`
+}
+
+// BuildHistory lists the records of completed builds, from the oldest to the
+// newest. If ref is set only the record of the build with ref is returned.
+func (c *Client) BuildHistory(ctx context.Context, ref string) ([]*BuildHistoryRecord, error) {
+	resp, err := c.controlClient().ListBuildHistory(ctx, &controlapi.ListBuildHistoryRequest{
+		Ref: ref,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list build history")
+	}
+
+	recs := make([]*BuildHistoryRecord, 0, len(resp.Records))
+	for _, r := range resp.Records {
+		rec := &BuildHistoryRecord{
+			Ref:               r.Ref,
+			Frontend:          r.Frontend,
+			FrontendAttrs:     r.FrontendAttrs,
+			ExporterResponse:  r.ExporterResponse,
+			CreatedAt:         r.CreatedAt,
+			CompletedAt:       r.CompletedAt,
+			Error:             r.Error,
+			NumTotalSteps:     int(r.NumTotalSteps),
+			NumCachedSteps:    int(r.NumCachedSteps),
+			NumCompletedSteps: int(r.NumCompletedSteps),
+			Logs:              r.Logs,
+		}
+		for _, e := range r.Exporters {
+			rec.Exporters = append(rec.Exporters, BuildHistoryExporter{
+				Type:  e.Type,
+				Attrs: e.Attrs,
+			})
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
+
+// BuildHistoryLogs sends the stored progress of a completed build to
+// statusChan.
+func (c *Client) BuildHistoryLogs(ctx context.Context, ref string, statusChan chan *SolveStatus) error {
+	defer func() {
+		if statusChan != nil {
+			close(statusChan)
+		}
+	}()
+
+	stream, err := c.controlClient().BuildHistoryLogs(ctx, &controlapi.BuildHistoryLogsRequest{
+		Ref: ref,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to get build logs")
+	}
+	return receiveStatus(stream, statusChan)
+}
+
+// DeleteBuildHistory removes the record of a completed build.
+func (c *Client) DeleteBuildHistory(ctx context.Context, ref string) error {
+	_, err := c.controlClient().DeleteBuildHistory(ctx, &controlapi.DeleteBuildHistoryRequest{
+		Ref: ref,
+	})
+	return errors.Wrap(err, "failed to delete build history")
+}
@@ -0,0 +1,62 @@
+package llb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScratchDiff(t *testing.T) {
+	t.Parallel()
+
+	s := Diff(Scratch(), Scratch())
+	require.Nil(t, s.Output())
+
+	upper := Image("foo")
+	s = Diff(Scratch(), upper)
+	require.Equal(t, upper.Output(), s.Output())
+
+	s = Diff(Image("bar"), upper)
+	require.NotEqual(t, upper.Output(), s.Output())
+}
+
+func TestDiffMarshal(t *testing.T) {
+	t.Parallel()
+
+	lower := Image("foo")
+	upper := lower.File(Mkfile("/bar", 0644, []byte("bar")))
+
+	def, err := Diff(lower, upper).Marshal(context.TODO())
+	require.NoError(t, err)
+
+	m, arr := parseDef(t, def.Def)
+	require.Equal(t, 4, len(arr))
+
+	dgst, idx := last(t, arr)
+	require.Equal(t, 0, idx)
+	op := m[dgst]
+	diff := op.GetDiff()
+	require.NotNil(t, diff)
+	require.Equal(t, 2, len(op.Inputs))
+	require.Nil(t, op.Platform)
+	require.Equal(t, pb.InputIndex(0), diff.Lower.Input)
+	require.Equal(t, pb.InputIndex(1), diff.Upper.Input)
+	lowerOp := m[op.Inputs[0].Digest]
+	require.Equal(t, "docker-image://docker.io/library/foo:latest", lowerOp.GetSource().Identifier)
+	upperOp := m[op.Inputs[1].Digest]
+	require.NotNil(t, upperOp.GetFile())
+	require.True(t, def.Metadata[dgst].Caps[pb.CapDiffOp])
+
+	// diff of a state and scratch deletes all the files of the state
+	def, err = Diff(lower, Scratch()).Marshal(context.TODO())
+	require.NoError(t, err)
+
+	m, arr = parseDef(t, def.Def)
+	dgst, _ = last(t, arr)
+	op = m[dgst]
+	require.Equal(t, 1, len(op.Inputs))
+	require.Equal(t, pb.InputIndex(0), op.GetDiff().Lower.Input)
+	require.Equal(t, pb.Empty, op.GetDiff().Upper.Input)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net"
 	"sort"
+	"strings"
 
 	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/system"
@@ -45,9 +46,14 @@ type mount struct {
 	tmpfs        bool
 	tmpfsOpt     TmpfsInfo
 	cacheSharing CacheMountSharingMode
+	cacheOwner   *cacheOwner
 	noOutput     bool
 }
 
+type cacheOwner struct {
+	uid, gid uint32
+}
+
 type ExecOp struct {
 	MarshalCache
 	proxyEnv    *ProxyEnv
@@ -58,6 +64,7 @@ type ExecOp struct {
 	isValidated bool
 	secrets     []SecretInfo
 	ssh         []SSHInfo
+	cacheMode   ExecCacheMode
 }
 
 func (e *ExecOp) AddMount(target string, source Output, opt ...MountOption) Output {
@@ -143,13 +150,26 @@ func (e *ExecOp) Marshal(ctx context.Context, c *Constraints) (digest.Digest, []
 	}
 
 	if len(e.ssh) > 0 {
+		agent := -1
 		for i, s := range e.ssh {
 			if s.Target == "" {
 				e.ssh[i].Target = fmt.Sprintf("/run/buildkit/ssh_agent.%d", i)
 			}
+			if agent == -1 && !strings.HasPrefix(s.ID, pb.SSHSocketIDPrefix) && s.ID != pb.SSHNestedBuildID {
+				agent = i
+			}
 		}
-		if _, ok := env.Get("SSH_AUTH_SOCK"); !ok {
-			env = env.AddOrReplace("SSH_AUTH_SOCK", e.ssh[0].Target)
+		if _, ok := env.Get("SSH_AUTH_SOCK"); !ok && agent != -1 {
+			env = env.AddOrReplace("SSH_AUTH_SOCK", e.ssh[agent].Target)
+		}
+		for _, s := range e.ssh {
+			if s.ID != pb.SSHNestedBuildID {
+				continue
+			}
+			if _, ok := env.Get("BUILDKIT_HOST"); !ok {
+				env = env.AddOrReplace("BUILDKIT_HOST", "unix://"+s.Target)
+			}
+			break
 		}
 	}
 	if c.Caps != nil {
@@ -191,13 +211,59 @@ func (e *ExecOp) Marshal(ctx context.Context, c *Constraints) (digest.Digest, []
 		return "", nil, nil, nil, err
 	}
 
+	seccomp, err := getSeccompProfile(e.base)(ctx, c)
+	if err != nil {
+		return "", nil, nil, nil, err
+	}
+
+	apparmor, err := getApparmorProfile(e.base)(ctx, c)
+	if err != nil {
+		return "", nil, nil, nil, err
+	}
+
+	tc, err := getTraceContext(e.base)(ctx, c)
+	if err != nil {
+		return "", nil, nil, nil, err
+	}
+
 	meta := &pb.Meta{
-		Args:         args,
-		Env:          env.ToArray(),
-		Cwd:          cwd,
-		User:         user,
-		Hostname:     hostname,
-		CgroupParent: cgrpParent,
+		Args:            args,
+		Env:             env.ToArray(),
+		Cwd:             cwd,
+		User:            user,
+		Hostname:        hostname,
+		CgroupParent:    cgrpParent,
+		SeccompProfile:  seccomp,
+		ApparmorProfile: apparmor,
+		TraceContext:    tc,
+	}
+	if seccomp != "" || apparmor != "" {
+		addCap(&e.constraints, pb.CapExecMetaSecurityProfile)
+	}
+	if tc {
+		addCap(&e.constraints, pb.CapExecMetaTraceContext)
+	}
+
+	dns, err := getDNS(e.base)(ctx, c)
+	if err != nil {
+		return "", nil, nil, nil, err
+	}
+	if dns != nil {
+		meta.Dns = &pb.DNSConfig{
+			Nameservers:   dns.Nameservers,
+			Options:       dns.Options,
+			SearchDomains: dns.SearchDomains,
+		}
+		addCap(&e.constraints, pb.CapExecMetaDNS)
+	}
+
+	runtime, err := getRuntime(e.base)(ctx, c)
+	if err != nil {
+		return "", nil, nil, nil, err
+	}
+	if runtime != "" {
+		meta.Runtime = runtime
+		addCap(&e.constraints, pb.CapExecMetaRuntime)
 	}
 
 	extraHosts, err := getExtraHosts(e.base)(ctx, c)
@@ -234,20 +300,34 @@ func (e *ExecOp) Marshal(ctx context.Context, c *Constraints) (digest.Digest, []
 		return "", nil, nil, nil, err
 	}
 
+	networkName, err := getNetworkName(e.base)(ctx, c)
+	if err != nil {
+		return "", nil, nil, nil, err
+	}
+
 	security, err := getSecurity(e.base)(ctx, c)
 	if err != nil {
 		return "", nil, nil, nil, err
 	}
 
 	peo := &pb.ExecOp{
-		Meta:     meta,
-		Network:  network,
-		Security: security,
+		Meta:        meta,
+		Network:     network,
+		Security:    security,
+		NetworkName: networkName,
+		CacheMode:   e.cacheMode,
+	}
+	if e.cacheMode != CacheModeDefinition {
+		addCap(&e.constraints, pb.CapExecCacheMode)
 	}
 	if network != NetModeSandbox {
 		addCap(&e.constraints, pb.CapExecMetaNetwork)
 	}
 
+	if networkName != "" {
+		addCap(&e.constraints, pb.CapExecMetaNetworkName)
+	}
+
 	if security != SecurityModeSandbox {
 		addCap(&e.constraints, pb.CapExecMetaSecurity)
 	}
@@ -272,6 +352,9 @@ func (e *ExecOp) Marshal(ctx context.Context, c *Constraints) (digest.Digest, []
 		if m.cacheID != "" {
 			addCap(&e.constraints, pb.CapExecMountCache)
 			addCap(&e.constraints, pb.CapExecMountCacheSharing)
+			if m.cacheOwner != nil {
+				addCap(&e.constraints, pb.CapExecMountCacheOwner)
+			}
 		} else if m.tmpfs {
 			addCap(&e.constraints, pb.CapExecMountTmpfs)
 			if m.tmpfsOpt.Size > 0 {
@@ -294,6 +377,14 @@ func (e *ExecOp) Marshal(ctx context.Context, c *Constraints) (digest.Digest, []
 
 	if len(e.ssh) > 0 {
 		addCap(&e.constraints, pb.CapExecMountSSH)
+		for _, s := range e.ssh {
+			if strings.HasPrefix(s.ID, pb.SSHSocketIDPrefix) {
+				addCap(&e.constraints, pb.CapExecMountSocket)
+			}
+			if s.ID == pb.SSHNestedBuildID {
+				addCap(&e.constraints, pb.CapExecMountNestedBuild)
+			}
+		}
 	}
 
 	if e.constraints.Platform == nil {
@@ -364,6 +455,10 @@ func (e *ExecOp) Marshal(ctx context.Context, c *Constraints) (digest.Digest, []
 			case CacheMountLocked:
 				pm.CacheOpt.Sharing = pb.CacheSharingOpt_LOCKED
 			}
+			if m.cacheOwner != nil {
+				pm.CacheOpt.Uid = m.cacheOwner.uid
+				pm.CacheOpt.Gid = m.cacheOwner.gid
+			}
 		}
 		if m.tmpfs {
 			pm.MountType = pb.MountType_TMPFS
@@ -498,6 +593,15 @@ func AsPersistentCacheDir(id string, sharing CacheMountSharingMode) MountOption
 	}
 }
 
+// CacheOwner makes uid and gid the owner of the root of a cache mount in the
+// container, without chowning the files of the cache on disk when the kernel
+// supports idmapped mounts.
+func CacheOwner(uid, gid int) MountOption {
+	return func(m *mount) {
+		m.cacheOwner = &cacheOwner{uid: uint32(uid), gid: uint32(gid)}
+	}
+}
+
 func Tmpfs(opts ...TmpfsOption) MountOption {
 	return func(m *mount) {
 		t := &TmpfsInfo{}
@@ -580,6 +684,27 @@ func WithCgroupParent(cp string) RunOption {
 	})
 }
 
+func WithSeccompProfile(name string) RunOption {
+	return runOptionFunc(func(ei *ExecInfo) {
+		ei.State = ei.State.WithSeccompProfile(name)
+	})
+}
+
+func WithApparmorProfile(name string) RunOption {
+	return runOptionFunc(func(ei *ExecInfo) {
+		ei.State = ei.State.WithApparmorProfile(name)
+	})
+}
+
+// WithTraceContext passes the trace context of the build to the process as
+// TRACEPARENT and TRACESTATE environment variables, so that the traces of the
+// process can be correlated with the trace of the build.
+func WithTraceContext() RunOption {
+	return runOptionFunc(func(ei *ExecInfo) {
+		ei.State = ei.State.WithTraceContext(true)
+	})
+}
+
 func With(so ...StateOption) RunOption {
 	return runOptionFunc(func(ei *ExecInfo) {
 		ei.State = ei.State.With(so...)
@@ -604,6 +729,24 @@ func AddSSHSocket(opts ...SSHOption) RunOption {
 	})
 }
 
+// AddSocket forwards the socket of the client exposed with id, e.g. a Docker or
+// a GPG agent socket, to target. The daemon only forwards the sockets allowed
+// by its configuration.
+func AddSocket(id, target string, opts ...SSHOption) RunOption {
+	opts = append(opts, SSHID(pb.SSHSocketIDPrefix+id), SSHSocketTarget(target))
+	return AddSSHSocket(opts...)
+}
+
+// AddNestedBuildSocket serves the BuildKit API of the daemon on the socket at
+// target, so that the process can run nested builds, e.g. with buildctl. The
+// nested builds share the cache of the build and can't use more entitlements
+// or resources than it. BUILDKIT_HOST points to the socket unless it is set.
+// It requires the nested.build entitlement.
+func AddNestedBuildSocket(target string, opts ...SSHOption) RunOption {
+	opts = append(opts, SSHID(pb.SSHNestedBuildID), SSHSocketTarget(target))
+	return AddSSHSocket(opts...)
+}
+
 type SSHOption interface {
 	SetSSHOption(*SSHInfo)
 }
@@ -709,6 +852,18 @@ func ReadonlyRootFS() RunOption {
 	})
 }
 
+// WithCacheMode sets how the results of the exec are matched in the cache.
+// With CacheModeContent, the operations using the results also match them by
+// their content, so that identical results of different commands share the
+// cache of the operations using them. CacheModeHermetic also leaves the
+// hostname, the extra hosts, the DNS config, the ulimits, the cgroup parent
+// and the network name of the process out of the cache key of the exec.
+func WithCacheMode(m ExecCacheMode) RunOption {
+	return runOptionFunc(func(ei *ExecInfo) {
+		ei.CacheMode = m
+	})
+}
+
 func WithProxy(ps ProxyEnv) RunOption {
 	return runOptionFunc(func(ei *ExecInfo) {
 		ei.ProxyEnv = &ps
@@ -723,6 +878,7 @@ type ExecInfo struct {
 	ProxyEnv       *ProxyEnv
 	Secrets        []SecretInfo
 	SSH            []SSHInfo
+	CacheMode      ExecCacheMode
 }
 
 type MountInfo struct {
@@ -758,6 +914,14 @@ const (
 	SecurityModeSandbox  = pb.SecurityMode_SANDBOX
 )
 
+type ExecCacheMode = pb.ExecCacheMode
+
+const (
+	CacheModeDefinition = pb.ExecCacheMode_CACHE_DEFINITION
+	CacheModeContent    = pb.ExecCacheMode_CACHE_CONTENT
+	CacheModeHermetic   = pb.ExecCacheMode_CACHE_HERMETIC
+)
+
 type UlimitName string
 
 const (
@@ -50,3 +50,115 @@ func TestValidGetMountIndex(t *testing.T) {
 	require.NoError(t, err, "failed to getIndex")
 	require.Equal(t, pb.OutputIndex(1), mountIndex, "unexpected mount index")
 }
+
+func TestExecSecurityProfiles(t *testing.T) {
+	t.Parallel()
+
+	st := Image("foo").Run(Shlex("args")).Root()
+	def, err := st.Marshal(context.TODO())
+	require.NoError(t, err)
+	m, arr := parseDef(t, def.Def)
+	dgst, _ := last(t, arr)
+	exec := m[dgst]
+	require.Equal(t, "", exec.GetExec().Meta.SeccompProfile)
+	require.Equal(t, "", exec.GetExec().Meta.ApparmorProfile)
+	require.False(t, def.Metadata[dgst].Caps[pb.CapExecMetaSecurityProfile])
+
+	st = Image("foo").Run(Shlex("args"), WithSeccompProfile(pb.SecurityProfileUnconfined), WithApparmorProfile("buildkit-custom")).Root()
+	def, err = st.Marshal(context.TODO())
+	require.NoError(t, err)
+	m, arr = parseDef(t, def.Def)
+	dgst, _ = last(t, arr)
+	exec = m[dgst]
+	require.Equal(t, pb.SecurityProfileUnconfined, exec.GetExec().Meta.SeccompProfile)
+	require.Equal(t, "buildkit-custom", exec.GetExec().Meta.ApparmorProfile)
+	require.True(t, def.Metadata[dgst].Caps[pb.CapExecMetaSecurityProfile])
+}
+
+func TestExecTraceContext(t *testing.T) {
+	t.Parallel()
+
+	st := Image("foo").Run(Shlex("args")).Root()
+	def, err := st.Marshal(context.TODO())
+	require.NoError(t, err)
+	m, arr := parseDef(t, def.Def)
+	dgst, _ := last(t, arr)
+	exec := m[dgst]
+	require.False(t, exec.GetExec().Meta.TraceContext)
+	require.False(t, def.Metadata[dgst].Caps[pb.CapExecMetaTraceContext])
+
+	st = Image("foo").Run(Shlex("args"), WithTraceContext()).Root()
+	def, err = st.Marshal(context.TODO())
+	require.NoError(t, err)
+	m, arr = parseDef(t, def.Def)
+	dgst, _ = last(t, arr)
+	exec = m[dgst]
+	require.True(t, exec.GetExec().Meta.TraceContext)
+	require.True(t, def.Metadata[dgst].Caps[pb.CapExecMetaTraceContext])
+}
+
+func TestExecDNS(t *testing.T) {
+	t.Parallel()
+
+	st := Image("foo").Run(Shlex("args")).Root()
+	def, err := st.Marshal(context.TODO())
+	require.NoError(t, err)
+	m, arr := parseDef(t, def.Def)
+	dgst, _ := last(t, arr)
+	exec := m[dgst]
+	require.Nil(t, exec.GetExec().Meta.Dns)
+	require.False(t, def.Metadata[dgst].Caps[pb.CapExecMetaDNS])
+
+	st = Image("foo").Run(Shlex("args"), DNS(DNSConfig{Nameservers: []string{"10.0.0.53"}, SearchDomains: []string{"corp.example.com"}})).Root()
+	def, err = st.Marshal(context.TODO())
+	require.NoError(t, err)
+	m, arr = parseDef(t, def.Def)
+	dgst, _ = last(t, arr)
+	exec = m[dgst]
+	require.Equal(t, &pb.DNSConfig{Nameservers: []string{"10.0.0.53"}, SearchDomains: []string{"corp.example.com"}}, exec.GetExec().Meta.Dns)
+	require.True(t, def.Metadata[dgst].Caps[pb.CapExecMetaDNS])
+}
+
+func TestExecRuntime(t *testing.T) {
+	t.Parallel()
+
+	st := Image("foo").Run(Shlex("args")).Root()
+	def, err := st.Marshal(context.TODO())
+	require.NoError(t, err)
+	m, arr := parseDef(t, def.Def)
+	dgst, _ := last(t, arr)
+	exec := m[dgst]
+	require.Equal(t, "", exec.GetExec().Meta.Runtime)
+	require.False(t, def.Metadata[dgst].Caps[pb.CapExecMetaRuntime])
+
+	st = Image("foo").Run(Shlex("args"), Runtime("gvisor")).Root()
+	def, err = st.Marshal(context.TODO())
+	require.NoError(t, err)
+	m, arr = parseDef(t, def.Def)
+	dgst, _ = last(t, arr)
+	exec = m[dgst]
+	require.Equal(t, "gvisor", exec.GetExec().Meta.Runtime)
+	require.True(t, def.Metadata[dgst].Caps[pb.CapExecMetaRuntime])
+}
+
+func TestExecCacheMode(t *testing.T) {
+	t.Parallel()
+
+	st := Image("foo").Run(Shlex("args")).Root()
+	def, err := st.Marshal(context.TODO())
+	require.NoError(t, err)
+	m, arr := parseDef(t, def.Def)
+	dgst, _ := last(t, arr)
+	exec := m[dgst]
+	require.Equal(t, CacheModeDefinition, exec.GetExec().CacheMode)
+	require.False(t, def.Metadata[dgst].Caps[pb.CapExecCacheMode])
+
+	st = Image("foo").Run(Shlex("args"), WithCacheMode(CacheModeHermetic)).Root()
+	def, err = st.Marshal(context.TODO())
+	require.NoError(t, err)
+	m, arr = parseDef(t, def.Def)
+	dgst, _ = last(t, arr)
+	exec = m[dgst]
+	require.Equal(t, CacheModeHermetic, exec.GetExec().CacheMode)
+	require.True(t, def.Metadata[dgst].Caps[pb.CapExecCacheMode])
+}
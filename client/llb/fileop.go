@@ -34,6 +34,7 @@ import (
 
 func NewFileOp(s State, action *FileAction, c Constraints) *FileOp {
 	action = action.bind(s)
+	addActionConstraints(&c, action)
 
 	f := &FileOp{
 		action:      action,
@@ -54,6 +55,7 @@ type CopyInput interface {
 
 type subAction interface {
 	toProtoAction(context.Context, string, pb.InputIndex) (pb.IsFileAction, error)
+	actionConstraints() *Constraints
 }
 
 type capAdder interface {
@@ -85,6 +87,24 @@ func (fa *FileAction) Rm(p string, opt ...RmOption) *FileAction {
 	return a
 }
 
+func (fa *FileAction) Symlink(oldpath, newpath string, opt ...SymlinkOption) *FileAction {
+	a := Symlink(oldpath, newpath, opt...)
+	a.prev = fa
+	return a
+}
+
+func (fa *FileAction) Link(oldpath, newpath string, opt ...LinkOption) *FileAction {
+	a := Link(oldpath, newpath, opt...)
+	a.prev = fa
+	return a
+}
+
+func (fa *FileAction) SetAttr(p string, opt ...SetAttrOption) *FileAction {
+	a := SetAttr(p, opt...)
+	a.prev = fa
+	return a
+}
+
 func (fa *FileAction) Copy(input CopyInput, src, dest string, opt ...CopyOption) *FileAction {
 	a := Copy(input, src, dest, opt...)
 	a.prev = fa
@@ -163,6 +183,10 @@ func (a *fileActionMkdir) toProtoAction(ctx context.Context, parent string, base
 	}, nil
 }
 
+func (a *fileActionMkdir) actionConstraints() *Constraints {
+	return &a.info.Constraints
+}
+
 type MkdirOption interface {
 	SetMkdirOption(*MkdirInfo)
 }
@@ -170,6 +194,8 @@ type MkdirOption interface {
 type ChownOption interface {
 	MkdirOption
 	MkfileOption
+	SymlinkOption
+	SetAttrOption
 	CopyOption
 }
 
@@ -188,6 +214,7 @@ func WithParents(b bool) MkdirOption {
 }
 
 type MkdirInfo struct {
+	constraintsWrapper
 	MakeParents bool
 	ChownOpt    *ChownOpt
 	CreatedTime *time.Time
@@ -252,6 +279,12 @@ func (co ChownOpt) SetMkdirOption(mi *MkdirInfo) {
 func (co ChownOpt) SetMkfileOption(mi *MkfileInfo) {
 	mi.ChownOpt = &co
 }
+func (co ChownOpt) SetSymlinkOption(mi *SymlinkInfo) {
+	mi.ChownOpt = &co
+}
+func (co ChownOpt) SetSetAttrOption(mi *SetAttrInfo) {
+	mi.ChownOpt = &co
+}
 func (co ChownOpt) SetCopyOption(mi *CopyInfo) {
 	mi.ChownOpt = &co
 }
@@ -303,6 +336,7 @@ type MkfileOption interface {
 }
 
 type MkfileInfo struct {
+	constraintsWrapper
 	ChownOpt    *ChownOpt
 	CreatedTime *time.Time
 }
@@ -332,6 +366,10 @@ func (a *fileActionMkfile) toProtoAction(ctx context.Context, parent string, bas
 	}, nil
 }
 
+func (a *fileActionMkfile) actionConstraints() *Constraints {
+	return &a.info.Constraints
+}
+
 func Rm(p string, opts ...RmOption) *FileAction {
 	var mi RmInfo
 	for _, o := range opts {
@@ -357,6 +395,7 @@ func (fn rmOptionFunc) SetRmOption(mi *RmInfo) {
 }
 
 type RmInfo struct {
+	constraintsWrapper
 	AllowNotFound bool
 	AllowWildcard bool
 }
@@ -394,6 +433,210 @@ func (a *fileActionRm) toProtoAction(ctx context.Context, parent string, base pb
 	}, nil
 }
 
+func (a *fileActionRm) actionConstraints() *Constraints {
+	return &a.info.Constraints
+}
+
+// Symlink creates a symlink at newpath pointing to oldpath.
+func Symlink(oldpath, newpath string, opts ...SymlinkOption) *FileAction {
+	var mi SymlinkInfo
+	for _, o := range opts {
+		o.SetSymlinkOption(&mi)
+	}
+
+	return &FileAction{
+		action: &fileActionSymlink{
+			oldpath: oldpath,
+			newpath: newpath,
+			info:    mi,
+		},
+	}
+}
+
+type SymlinkOption interface {
+	SetSymlinkOption(*SymlinkInfo)
+}
+
+type SymlinkInfo struct {
+	constraintsWrapper
+	ChownOpt    *ChownOpt
+	CreatedTime *time.Time
+}
+
+func (mi *SymlinkInfo) SetSymlinkOption(mi2 *SymlinkInfo) {
+	*mi2 = *mi
+}
+
+var _ SymlinkOption = &SymlinkInfo{}
+
+type fileActionSymlink struct {
+	oldpath string
+	newpath string
+	info    SymlinkInfo
+}
+
+func (a *fileActionSymlink) toProtoAction(ctx context.Context, parent string, base pb.InputIndex) (pb.IsFileAction, error) {
+	return &pb.FileAction_Symlink{
+		Symlink: &pb.FileActionSymlink{
+			// the target is relative to the symlink, it is kept as is
+			Oldpath:   a.oldpath,
+			Newpath:   normalizePath(parent, a.newpath, false),
+			Owner:     a.info.ChownOpt.marshal(base),
+			Timestamp: marshalTime(a.info.CreatedTime),
+		},
+	}, nil
+}
+
+func (a *fileActionSymlink) actionConstraints() *Constraints {
+	return &a.info.Constraints
+}
+
+func (a *fileActionSymlink) addCaps(f *FileOp) {
+	addCap(&f.constraints, pb.CapFileSymlink)
+}
+
+// Link creates a hardlink at newpath of the file at oldpath.
+func Link(oldpath, newpath string, opts ...LinkOption) *FileAction {
+	var mi LinkInfo
+	for _, o := range opts {
+		o.SetLinkOption(&mi)
+	}
+
+	return &FileAction{
+		action: &fileActionLink{
+			oldpath: oldpath,
+			newpath: newpath,
+			info:    mi,
+		},
+	}
+}
+
+type LinkOption interface {
+	SetLinkOption(*LinkInfo)
+}
+
+type LinkInfo struct {
+	constraintsWrapper
+}
+
+func (mi *LinkInfo) SetLinkOption(mi2 *LinkInfo) {
+	*mi2 = *mi
+}
+
+var _ LinkOption = &LinkInfo{}
+
+type fileActionLink struct {
+	oldpath string
+	newpath string
+	info    LinkInfo
+}
+
+func (a *fileActionLink) toProtoAction(ctx context.Context, parent string, base pb.InputIndex) (pb.IsFileAction, error) {
+	return &pb.FileAction_Link{
+		Link: &pb.FileActionLink{
+			Oldpath: normalizePath(parent, a.oldpath, false),
+			Newpath: normalizePath(parent, a.newpath, false),
+		},
+	}, nil
+}
+
+func (a *fileActionLink) actionConstraints() *Constraints {
+	return &a.info.Constraints
+}
+
+func (a *fileActionLink) addCaps(f *FileOp) {
+	addCap(&f.constraints, pb.CapFileLink)
+}
+
+// SetAttr changes the owner, the permissions or the modification time of the
+// file at p in place, without the shell of a RUN step.
+func SetAttr(p string, opts ...SetAttrOption) *FileAction {
+	var mi SetAttrInfo
+	for _, o := range opts {
+		o.SetSetAttrOption(&mi)
+	}
+
+	return &FileAction{
+		action: &fileActionSetAttr{
+			file: p,
+			info: mi,
+		},
+	}
+}
+
+type SetAttrOption interface {
+	SetSetAttrOption(*SetAttrInfo)
+}
+
+type setAttrOptionFunc func(*SetAttrInfo)
+
+func (fn setAttrOptionFunc) SetSetAttrOption(mi *SetAttrInfo) {
+	fn(mi)
+}
+
+type SetAttrInfo struct {
+	constraintsWrapper
+	// Mode are the new permission bits, kept if nil
+	Mode     *os.FileMode
+	ChownOpt *ChownOpt
+	// CreatedTime is the new modification time, kept if nil
+	CreatedTime   *time.Time
+	Recursive     bool
+	AllowWildcard bool
+	AllowNotFound bool
+}
+
+func (mi *SetAttrInfo) SetSetAttrOption(mi2 *SetAttrInfo) {
+	*mi2 = *mi
+}
+
+var _ SetAttrOption = &SetAttrInfo{}
+
+// WithMode sets the permission bits of the files of SetAttr.
+func WithMode(m os.FileMode) SetAttrOption {
+	return setAttrOptionFunc(func(mi *SetAttrInfo) {
+		mi.Mode = &m
+	})
+}
+
+// WithRecursive makes SetAttr change the files in the directories as well.
+func WithRecursive(b bool) SetAttrOption {
+	return setAttrOptionFunc(func(mi *SetAttrInfo) {
+		mi.Recursive = b
+	})
+}
+
+type fileActionSetAttr struct {
+	file string
+	info SetAttrInfo
+}
+
+func (a *fileActionSetAttr) toProtoAction(ctx context.Context, parent string, base pb.InputIndex) (pb.IsFileAction, error) {
+	mode := int32(-1)
+	if a.info.Mode != nil {
+		mode = int32(*a.info.Mode & 0777)
+	}
+	return &pb.FileAction_Setattr{
+		Setattr: &pb.FileActionSetAttr{
+			Path:          normalizePath(parent, a.file, false),
+			Mode:          mode,
+			Owner:         a.info.ChownOpt.marshal(base),
+			Timestamp:     marshalTime(a.info.CreatedTime),
+			Recursive:     a.info.Recursive,
+			AllowWildcard: a.info.AllowWildcard,
+			AllowNotFound: a.info.AllowNotFound,
+		},
+	}, nil
+}
+
+func (a *fileActionSetAttr) actionConstraints() *Constraints {
+	return &a.info.Constraints
+}
+
+func (a *fileActionSetAttr) addCaps(f *FileOp) {
+	addCap(&f.constraints, pb.CapFileSetAttr)
+}
+
 func Copy(input CopyInput, src, dest string, opts ...CopyOption) *FileAction {
 	var state *State
 	var fas *fileActionWithState
@@ -428,6 +671,7 @@ type CopyOption interface {
 }
 
 type CopyInfo struct {
+	constraintsWrapper
 	Mode                *os.FileMode
 	FollowSymlinks      bool
 	CopyDirContentsOnly bool
@@ -439,7 +683,39 @@ type CopyInfo struct {
 	AllowEmptyWildcard  bool
 	ChownOpt            *ChownOpt
 	CreatedTime         *time.Time
-}
+	// Existing selects how the files already present at the destination are
+	// handled.
+	Existing CopyExisting
+	// Symlinks selects how the symlinks of the copied files are handled.
+	// FollowSymlinks only resolves the source path itself.
+	Symlinks CopySymlinks
+}
+
+// CopyExisting defines how a copy handles the files already present at the
+// destination. Directories copied over directories are always merged.
+type CopyExisting int
+
+const (
+	// CopyExistingOverwrite replaces the existing files.
+	CopyExistingOverwrite CopyExisting = iota
+	// CopyExistingSkip keeps the existing files.
+	CopyExistingSkip
+	// CopyExistingFail fails the copy if a file would be replaced.
+	CopyExistingFail
+)
+
+// CopySymlinks defines how a copy handles the symlinks of the copied files.
+type CopySymlinks int
+
+const (
+	// CopySymlinksPreserve copies the symlinks as they are.
+	CopySymlinksPreserve CopySymlinks = iota
+	// CopySymlinksFollow copies the files the symlinks point to.
+	CopySymlinksFollow
+	// CopySymlinksForbidEscape copies the symlinks as they are and fails the
+	// copy if one of them points outside of the copied files.
+	CopySymlinksForbidEscape
+)
 
 func (mi *CopyInfo) SetCopyOption(mi2 *CopyInfo) {
 	*mi2 = *mi
@@ -474,6 +750,18 @@ func (a *fileActionCopy) toProtoAction(ctx context.Context, parent string, base
 		CreateDestPath:                   a.info.CreateDestPath,
 		Timestamp:                        marshalTime(a.info.CreatedTime),
 	}
+	switch a.info.Existing {
+	case CopyExistingSkip:
+		c.Existing = pb.CopyExistingPolicy_SKIP
+	case CopyExistingFail:
+		c.Existing = pb.CopyExistingPolicy_FAIL
+	}
+	switch a.info.Symlinks {
+	case CopySymlinksFollow:
+		c.Symlinks = pb.CopySymlinkPolicy_FOLLOW
+	case CopySymlinksForbidEscape:
+		c.Symlinks = pb.CopySymlinkPolicy_FORBID_ESCAPE
+	}
 	if a.info.Mode != nil {
 		c.Mode = int32(*a.info.Mode)
 	} else {
@@ -504,10 +792,69 @@ func (a *fileActionCopy) sourcePath(ctx context.Context) (string, error) {
 	return p, nil
 }
 
+func (a *fileActionCopy) actionConstraints() *Constraints {
+	return &a.info.Constraints
+}
+
 func (a *fileActionCopy) addCaps(f *FileOp) {
 	if len(a.info.IncludePatterns) != 0 || len(a.info.ExcludePatterns) != 0 {
 		addCap(&f.constraints, pb.CapFileCopyIncludeExcludePatterns)
 	}
+	if a.info.Existing != CopyExistingOverwrite {
+		addCap(&f.constraints, pb.CapFileCopyExisting)
+	}
+	if a.info.Symlinks != CopySymlinksPreserve {
+		addCap(&f.constraints, pb.CapFileCopySymlinks)
+	}
+}
+
+// addActionConstraints adds the custom names, descriptions, progress groups
+// and source locations set on the actions to the constraints of the op,
+// sources first. The ones set on the op take precedence and the custom names
+// of several actions are joined.
+func addActionConstraints(c *Constraints, fa *FileAction) {
+	var names []string
+	visited := map[*FileAction]struct{}{}
+	var add func(*FileAction)
+	add = func(fa *FileAction) {
+		if fa == nil || fa.action == nil {
+			return
+		}
+		if _, ok := visited[fa]; ok {
+			return
+		}
+		visited[fa] = struct{}{}
+		add(fa.prev)
+		if a, ok := fa.action.(*fileActionCopy); ok && a.fas != nil {
+			add(a.fas.FileAction)
+		}
+
+		ac := fa.action.actionConstraints()
+		for k, v := range ac.Metadata.Description {
+			if k == customNameKey {
+				names = append(names, v)
+				continue
+			}
+			if _, ok := c.Metadata.Description[k]; !ok {
+				if c.Metadata.Description == nil {
+					c.Metadata.Description = map[string]string{}
+				}
+				c.Metadata.Description[k] = v
+			}
+		}
+		if c.Metadata.ProgressGroup == nil {
+			c.Metadata.ProgressGroup = ac.Metadata.ProgressGroup
+		}
+		c.SourceLocations = append(c.SourceLocations, ac.SourceLocations...)
+	}
+	add(fa)
+
+	if _, ok := c.Metadata.Description[customNameKey]; !ok && len(names) > 0 {
+		if c.Metadata.Description == nil {
+			c.Metadata.Description = map[string]string{}
+		}
+		c.Metadata.Description[customNameKey] = strings.Join(names, ", ")
+	}
 }
 
 type CreatedTime time.Time
@@ -524,6 +871,14 @@ func (c CreatedTime) SetMkfileOption(mi *MkfileInfo) {
 	mi.CreatedTime = (*time.Time)(&c)
 }
 
+func (c CreatedTime) SetSymlinkOption(mi *SymlinkInfo) {
+	mi.CreatedTime = (*time.Time)(&c)
+}
+
+func (c CreatedTime) SetSetAttrOption(mi *SetAttrInfo) {
+	mi.CreatedTime = (*time.Time)(&c)
+}
+
 func (c CreatedTime) SetCopyOption(mi *CopyInfo) {
 	mi.CreatedTime = (*time.Time)(&c)
 }
@@ -684,6 +1039,11 @@ func (f *FileOp) Marshal(ctx context.Context, c *Constraints) (digest.Digest, []
 	}
 
 	state := newMarshalState(ctx)
+	_, err := state.add(f.action, c)
+	if err != nil {
+		return "", nil, nil, nil, err
+	}
+	// the caps of the actions are known once they are added
 	for _, st := range state.actions {
 		if adder, isCapAdder := st.action.(capAdder); isCapAdder {
 			adder.addCaps(f)
@@ -694,11 +1054,6 @@ func (f *FileOp) Marshal(ctx context.Context, c *Constraints) (digest.Digest, []
 	pop.Op = &pb.Op_File{
 		File: pfo,
 	}
-
-	_, err := state.add(f.action, c)
-	if err != nil {
-		return "", nil, nil, nil, err
-	}
 	pop.Inputs = state.inputs
 
 	for i, st := range state.actions {
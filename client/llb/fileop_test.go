@@ -209,6 +209,98 @@ func TestFileRm(t *testing.T) {
 	require.Equal(t, "/foo", rm.Path)
 }
 
+func TestFileSymlinkLinkSetAttr(t *testing.T) {
+	t.Parallel()
+
+	st := Image("foo").Dir("/etc").File(
+		Symlink("../usr/lib/os-release", "os-release", WithUIDGID(1, 2)).
+			Link("/bin/busybox", "/bin/sh").
+			SetAttr("app", WithMode(0700), WithUser("app"), WithRecursive(true)),
+	)
+	def, err := st.Marshal(context.TODO())
+	require.NoError(t, err)
+
+	m, arr := parseDef(t, def.Def)
+	require.Equal(t, 3, len(arr))
+
+	dgst, idx := last(t, arr)
+	require.Equal(t, 0, idx)
+	require.Equal(t, m[dgst], arr[1])
+
+	f := arr[1].Op.(*pb.Op_File).File
+	require.Equal(t, 3, len(f.Actions))
+
+	symlink := f.Actions[0].Action.(*pb.FileAction_Symlink).Symlink
+	require.Equal(t, "../usr/lib/os-release", symlink.Oldpath)
+	require.Equal(t, "/etc/os-release", symlink.Newpath)
+	require.Equal(t, 1, int(symlink.Owner.User.User.(*pb.UserOpt_ByID).ByID))
+	require.Equal(t, 2, int(symlink.Owner.Group.User.(*pb.UserOpt_ByID).ByID))
+	require.Equal(t, -1, int(symlink.Timestamp))
+
+	link := f.Actions[1].Action.(*pb.FileAction_Link).Link
+	require.Equal(t, "/bin/busybox", link.Oldpath)
+	require.Equal(t, "/bin/sh", link.Newpath)
+
+	setattr := f.Actions[2].Action.(*pb.FileAction_Setattr).Setattr
+	require.Equal(t, "/etc/app", setattr.Path)
+	require.Equal(t, 0700, int(setattr.Mode))
+	require.Equal(t, "app", setattr.Owner.User.User.(*pb.UserOpt_ByName).ByName.Name)
+	require.True(t, setattr.Recursive)
+	require.Equal(t, -1, int(setattr.Timestamp))
+
+	_, ok := def.Metadata[dgst].Caps[pb.CapFileSymlink]
+	require.True(t, ok)
+	_, ok = def.Metadata[dgst].Caps[pb.CapFileLink]
+	require.True(t, ok)
+	_, ok = def.Metadata[dgst].Caps[pb.CapFileSetAttr]
+	require.True(t, ok)
+
+	// permissions are kept by default
+	st = Image("foo").File(SetAttr("/app", WithUIDGID(1, 1)))
+	def, err = st.Marshal(context.TODO())
+	require.NoError(t, err)
+	_, arr = parseDef(t, def.Def)
+	setattr = arr[1].Op.(*pb.Op_File).File.Actions[0].Action.(*pb.FileAction_Setattr).Setattr
+	require.Equal(t, -1, int(setattr.Mode))
+}
+
+func TestFileCopyPolicies(t *testing.T) {
+	t.Parallel()
+
+	st := Image("foo").File(
+		Copy(Image("bar"), "/dir", "/out", &CopyInfo{
+			Existing: CopyExistingSkip,
+			Symlinks: CopySymlinksForbidEscape,
+		}),
+	)
+	def, err := st.Marshal(context.TODO())
+	require.NoError(t, err)
+
+	m, arr := parseDef(t, def.Def)
+	dgst, idx := last(t, arr)
+	require.Equal(t, 0, idx)
+	f := m[dgst].Op.(*pb.Op_File).File
+	require.Equal(t, 1, len(f.Actions))
+
+	copy := f.Actions[0].Action.(*pb.FileAction_Copy).Copy
+	require.Equal(t, pb.CopyExistingPolicy_SKIP, copy.Existing)
+	require.Equal(t, pb.CopySymlinkPolicy_FORBID_ESCAPE, copy.Symlinks)
+
+	_, ok := def.Metadata[dgst].Caps[pb.CapFileCopyExisting]
+	require.True(t, ok)
+	_, ok = def.Metadata[dgst].Caps[pb.CapFileCopySymlinks]
+	require.True(t, ok)
+
+	// the default policies don't require the caps
+	st = Image("foo").File(Copy(Image("bar"), "/dir", "/out"))
+	def, err = st.Marshal(context.TODO())
+	require.NoError(t, err)
+	_, arr = parseDef(t, def.Def)
+	dgst, _ = last(t, arr)
+	_, ok = def.Metadata[dgst].Caps[pb.CapFileCopyExisting]
+	require.False(t, ok)
+}
+
 func TestFileSimpleChains(t *testing.T) {
 	t.Parallel()
 
@@ -641,6 +733,48 @@ func TestFileCreatedTime(t *testing.T) {
 	require.Equal(t, dt3.UnixNano(), copy.Timestamp)
 }
 
+func TestFileActionConstraints(t *testing.T) {
+	t.Parallel()
+
+	sm := NewSourceMap(nil, "Dockerfile", []byte("COPY src /app"))
+	st := Image("foo").File(
+		Mkdir("/app", 0755, WithCustomName("mkdir /app")).
+			Copy(Image("bar"), "src", "/app", WithCustomName("COPY src -> /app (stage build)"),
+				ProgressGroup("build", "stage build", false),
+				sm.Location([]*pb.Range{{Start: pb.Position{Line: 1}, End: pb.Position{Line: 1}}}),
+			))
+	def, err := st.Marshal(context.TODO())
+	require.NoError(t, err)
+
+	m, arr := parseDef(t, def.Def)
+	dgst, _ := last(t, arr)
+	_, ok := m[dgst].Op.(*pb.Op_File)
+	require.True(t, ok)
+
+	md := def.Metadata[dgst]
+	require.Equal(t, "mkdir /app, COPY src -> /app (stage build)", md.Description["llb.customname"])
+	require.Equal(t, &pb.ProgressGroup{Id: "build", Name: "stage build"}, md.ProgressGroup)
+
+	require.NotNil(t, def.Source)
+	require.Equal(t, 1, len(def.Source.Locations[dgst.String()].Locations))
+	require.Equal(t, "Dockerfile", def.Source.Infos[0].Filename)
+
+	// the constraints of the op take precedence over the ones of the actions
+	st = Image("foo").File(
+		Mkdir("/app", 0755, WithCustomName("mkdir /app"), ProgressGroup("build", "stage build", false)),
+		WithCustomName("WORKDIR /app"),
+		ProgressGroup("workdir", "workdir", false),
+	)
+	def, err = st.Marshal(context.TODO())
+	require.NoError(t, err)
+
+	_, arr = parseDef(t, def.Def)
+	dgst, _ = last(t, arr)
+	md = def.Metadata[dgst]
+	require.Equal(t, "WORKDIR /app", md.Description["llb.customname"])
+	require.Equal(t, "workdir", md.ProgressGroup.Id)
+}
+
 func parseDef(t *testing.T, def [][]byte) (map[digest.Digest]pb.Op, []pb.Op) {
 	m := map[digest.Digest]pb.Op{}
 	arr := make([]pb.Op, 0, len(def))
@@ -22,10 +22,16 @@ var (
 	keyHostname     = contextKeyT("llb.exec.hostname")
 	keyUlimit       = contextKeyT("llb.exec.ulimit")
 	keyCgroupParent = contextKeyT("llb.exec.cgroup.parent")
+	keySeccomp      = contextKeyT("llb.exec.security.seccomp")
+	keyApparmor     = contextKeyT("llb.exec.security.apparmor")
+	keyTraceContext = contextKeyT("llb.exec.tracecontext")
+	keyDNS          = contextKeyT("llb.exec.dns")
+	keyRuntime      = contextKeyT("llb.exec.runtime")
 	keyUser         = contextKeyT("llb.exec.user")
 
 	keyPlatform = contextKeyT("llb.platform")
 	keyNetwork  = contextKeyT("llb.network")
+	keyNetName  = contextKeyT("llb.network.name")
 	keySecurity = contextKeyT("llb.security")
 )
 
@@ -166,6 +172,27 @@ func getHostname(s State) func(context.Context, *Constraints) (string, error) {
 	}
 }
 
+// Runtime runs the processes of the state with the OCI runtime of the worker
+// with name, e.g. "gvisor" for a worker configured with a runsc runtime.
+func Runtime(name string) StateOption {
+	return func(s State) State {
+		return s.WithValue(keyRuntime, name)
+	}
+}
+
+func getRuntime(s State) func(context.Context, *Constraints) (string, error) {
+	return func(ctx context.Context, c *Constraints) (string, error) {
+		v, err := s.getValue(keyRuntime)(ctx, c)
+		if err != nil {
+			return "", err
+		}
+		if v != nil {
+			return v.(string), nil
+		}
+		return "", nil
+	}
+}
+
 func args(args ...string) StateOption {
 	return func(s State) State {
 		return s.WithValue(keyArgs, args)
@@ -283,6 +310,93 @@ func getCgroupParent(s State) func(context.Context, *Constraints) (string, error
 	}
 }
 
+func seccompProfile(name string) StateOption {
+	return func(s State) State {
+		return s.WithValue(keySeccomp, name)
+	}
+}
+
+func getSeccompProfile(s State) func(context.Context, *Constraints) (string, error) {
+	return func(ctx context.Context, c *Constraints) (string, error) {
+		v, err := s.getValue(keySeccomp)(ctx, c)
+		if err != nil {
+			return "", err
+		}
+		if v != nil {
+			return v.(string), nil
+		}
+		return "", nil
+	}
+}
+
+func apparmorProfile(name string) StateOption {
+	return func(s State) State {
+		return s.WithValue(keyApparmor, name)
+	}
+}
+
+func getApparmorProfile(s State) func(context.Context, *Constraints) (string, error) {
+	return func(ctx context.Context, c *Constraints) (string, error) {
+		v, err := s.getValue(keyApparmor)(ctx, c)
+		if err != nil {
+			return "", err
+		}
+		if v != nil {
+			return v.(string), nil
+		}
+		return "", nil
+	}
+}
+
+func traceContext(v bool) StateOption {
+	return func(s State) State {
+		return s.WithValue(keyTraceContext, v)
+	}
+}
+
+func getTraceContext(s State) func(context.Context, *Constraints) (bool, error) {
+	return func(ctx context.Context, c *Constraints) (bool, error) {
+		v, err := s.getValue(keyTraceContext)(ctx, c)
+		if err != nil {
+			return false, err
+		}
+		if v != nil {
+			return v.(bool), nil
+		}
+		return false, nil
+	}
+}
+
+// DNSConfig is the DNS config of the processes run from a state. The fields
+// that are not set keep the values of the worker.
+type DNSConfig struct {
+	Nameservers   []string
+	Options       []string
+	SearchDomains []string
+}
+
+// DNS overrides the DNS config of the worker and of the build for the
+// processes run from the state.
+func DNS(cfg DNSConfig) StateOption {
+	return func(s State) State {
+		return s.WithValue(keyDNS, cfg)
+	}
+}
+
+func getDNS(s State) func(context.Context, *Constraints) (*DNSConfig, error) {
+	return func(ctx context.Context, c *Constraints) (*DNSConfig, error) {
+		v, err := s.getValue(keyDNS)(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			cfg := v.(DNSConfig)
+			return &cfg, nil
+		}
+		return nil, nil
+	}
+}
+
 func Network(v pb.NetMode) StateOption {
 	return func(s State) State {
 		return s.WithValue(keyNetwork, v)
@@ -302,6 +416,26 @@ func getNetwork(s State) func(context.Context, *Constraints) (pb.NetMode, error)
 	}
 }
 
+// NetworkName selects a named network configured in the worker. It is only
+// used with the sandbox network mode.
+func NetworkName(name string) StateOption {
+	return func(s State) State {
+		return s.WithValue(keyNetName, name)
+	}
+}
+func getNetworkName(s State) func(context.Context, *Constraints) (string, error) {
+	return func(ctx context.Context, c *Constraints) (string, error) {
+		v, err := s.getValue(keyNetName)(ctx, c)
+		if err != nil {
+			return "", err
+		}
+		if v != nil {
+			return v.(string), nil
+		}
+		return "", nil
+	}
+}
+
 func Security(v pb.SecurityMode) StateOption {
 	return func(s State) State {
 		return s.WithValue(keySecurity, v)
@@ -110,6 +110,9 @@ func Image(ref string, opts ...ImageOption) State {
 		if info.resolveMode == ResolveModeForcePull {
 			addCap(&info.Constraints, pb.CapSourceImageResolveMode) // only require cap for security enforced mode
 		}
+		if info.resolveMode == ResolveModeLocalStore {
+			addCap(&info.Constraints, pb.CapSourceImageLocalStore)
+		}
 	}
 
 	if info.RecordType != "" {
@@ -180,6 +183,10 @@ const (
 	ResolveModeDefault ResolveMode = iota
 	ResolveModeForcePull
 	ResolveModePreferLocal
+	// ResolveModeLocalStore resolves the image only with the image store of
+	// the worker, e.g. the containerd image store of the containerd worker,
+	// without contacting the registry.
+	ResolveModeLocalStore
 )
 
 func (r ResolveMode) SetImageOption(ii *ImageInfo) {
@@ -194,6 +201,8 @@ func (r ResolveMode) String() string {
 		return pb.AttrImageResolveModeForcePull
 	case ResolveModePreferLocal:
 		return pb.AttrImageResolveModePreferLocal
+	case ResolveModeLocalStore:
+		return pb.AttrImageResolveModeLocalStore
 	default:
 		return ""
 	}
@@ -207,6 +216,149 @@ type ImageInfo struct {
 	RecordType    string
 }
 
+// OCILayout returns the state of an image in an OCI layout store shared by the
+// client. The reference is the name of the image in the store with a tag or a
+// digest, a tag is resolved with the index of the store.
+func OCILayout(ref string, opts ...OCILayoutOption) State {
+	var info OCILayoutInfo
+	for _, opt := range opts {
+		opt.SetOCILayoutOption(&info)
+	}
+
+	attrs := map[string]string{}
+	if info.sessionID != "" {
+		attrs[pb.AttrOCILayoutSessionID] = info.sessionID
+	}
+	if info.storeID != "" {
+		attrs[pb.AttrOCILayoutStoreID] = info.storeID
+	}
+
+	addCap(&info.Constraints, pb.CapSourceOCILayout)
+
+	source := NewSource("oci-layout://"+ref, attrs, info.Constraints)
+	return NewState(source.Output())
+}
+
+type OCILayoutOption interface {
+	SetOCILayoutOption(*OCILayoutInfo)
+}
+
+type ociLayoutOptionFunc func(*OCILayoutInfo)
+
+func (fn ociLayoutOptionFunc) SetOCILayoutOption(li *OCILayoutInfo) {
+	fn(li)
+}
+
+// OCIStore sets the OCI layout store the image is loaded from. The session
+// can be empty to use the sessions of the build.
+func OCIStore(sessionID string, storeID string) OCILayoutOption {
+	return ociLayoutOptionFunc(func(oi *OCILayoutInfo) {
+		oi.sessionID = sessionID
+		oi.storeID = storeID
+	})
+}
+
+type OCILayoutInfo struct {
+	constraintsWrapper
+	sessionID string
+	storeID   string
+}
+
+// ObjectStore returns the state of a single object of an S3 (s3://bucket/key),
+// Google Cloud Storage (gs://bucket/key) or Azure Blob Storage
+// (azblob://account/container/key) bucket. The object is fetched anonymously
+// unless a secret with the credentials is set.
+func ObjectStore(url string, opts ...ObjectStoreOption) State {
+	var info ObjectStoreInfo
+	for _, opt := range opts {
+		opt.SetObjectStoreOption(&info)
+	}
+
+	attrs := map[string]string{}
+	if info.Checksum != "" {
+		attrs[pb.AttrObjectStoreChecksum] = info.Checksum.String()
+	}
+	if info.Filename != "" {
+		attrs[pb.AttrObjectStoreFilename] = info.Filename
+	}
+	if info.SecretID != "" {
+		attrs[pb.AttrObjectStoreSecret] = info.SecretID
+	}
+	if info.Region != "" {
+		attrs[pb.AttrObjectStoreRegion] = info.Region
+	}
+	if info.Endpoint != "" {
+		attrs[pb.AttrObjectStoreEndpoint] = info.Endpoint
+	}
+
+	addCap(&info.Constraints, pb.CapSourceObjectStore)
+
+	source := NewSource(url, attrs, info.Constraints)
+	return NewState(source.Output())
+}
+
+type ObjectStoreOption interface {
+	SetObjectStoreOption(*ObjectStoreInfo)
+}
+
+type objectStoreOptionFunc func(*ObjectStoreInfo)
+
+func (fn objectStoreOptionFunc) SetObjectStoreOption(oi *ObjectStoreInfo) {
+	fn(oi)
+}
+
+// ObjectStoreChecksum pins the digest of the object. The object is not
+// fetched if a snapshot with the digest is cached.
+func ObjectStoreChecksum(dgst digest.Digest) ObjectStoreOption {
+	return objectStoreOptionFunc(func(oi *ObjectStoreInfo) {
+		oi.Checksum = dgst
+	})
+}
+
+// ObjectStoreFilename sets the name of the file of the object, the base name
+// of its key by default.
+func ObjectStoreFilename(name string) ObjectStoreOption {
+	return objectStoreOptionFunc(func(oi *ObjectStoreInfo) {
+		oi.Filename = name
+	})
+}
+
+// ObjectStoreSecret sets the ID of the session secret containing the
+// credentials of the bucket. The secret of S3 buckets contains the
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and optional AWS_SESSION_TOKEN
+// variables, one per line. The secret of Google Cloud Storage buckets is an
+// OAuth 2.0 access token and the one of Azure Blob Storage containers a shared
+// access signature.
+func ObjectStoreSecret(secretID string) ObjectStoreOption {
+	return objectStoreOptionFunc(func(oi *ObjectStoreInfo) {
+		oi.SecretID = secretID
+	})
+}
+
+// ObjectStoreRegion sets the region of an S3 bucket, us-east-1 by default.
+func ObjectStoreRegion(region string) ObjectStoreOption {
+	return objectStoreOptionFunc(func(oi *ObjectStoreInfo) {
+		oi.Region = region
+	})
+}
+
+// ObjectStoreEndpoint sets the URL of a compatible storage service to fetch the
+// object from. The bucket is the first element of the path of the requests.
+func ObjectStoreEndpoint(endpoint string) ObjectStoreOption {
+	return objectStoreOptionFunc(func(oi *ObjectStoreInfo) {
+		oi.Endpoint = endpoint
+	})
+}
+
+type ObjectStoreInfo struct {
+	constraintsWrapper
+	Checksum digest.Digest
+	Filename string
+	SecretID string
+	Region   string
+	Endpoint string
+}
+
 func Git(remote, ref string, opts ...GitOption) State {
 	url := strings.Split(remote, "#")[0]
 
@@ -248,6 +400,27 @@ func Git(remote, ref string, opts ...GitOption) State {
 		attrs[pb.AttrFullRemoteURL] = url
 		addCap(&gi.Constraints, pb.CapSourceGitFullURL)
 	}
+	if gi.SkipSubmodules {
+		attrs[pb.AttrGitSkipSubmodules] = "true"
+		addCap(&gi.Constraints, pb.CapSourceGitSubmodules)
+	}
+	if gi.LFS {
+		attrs[pb.AttrGitLFS] = "true"
+		addCap(&gi.Constraints, pb.CapSourceGitLFS)
+	}
+	if gi.Depth > 0 {
+		attrs[pb.AttrGitDepth] = strconv.Itoa(gi.Depth)
+		addCap(&gi.Constraints, pb.CapSourceGitDepth)
+	}
+	if gi.Filter != "" {
+		attrs[pb.AttrGitFilter] = gi.Filter
+		addCap(&gi.Constraints, pb.CapSourceGitFilter)
+	}
+	if len(gi.SparsePaths) > 0 {
+		dt, _ := json.Marshal(gi.SparsePaths) // empty on error
+		attrs[pb.AttrGitSparsePaths] = string(dt)
+		addCap(&gi.Constraints, pb.CapSourceGitSparse)
+	}
 	if gi.AuthTokenSecret != "" {
 		attrs[pb.AttrAuthTokenSecret] = gi.AuthTokenSecret
 		if gi.addAuthCap {
@@ -303,6 +476,11 @@ type GitInfo struct {
 	addAuthCap       bool
 	KnownSSHHosts    string
 	MountSSHSock     string
+	SkipSubmodules   bool
+	LFS              bool
+	Depth            int
+	Filter           string
+	SparsePaths      []string
 }
 
 func KeepGitDir() GitOption {
@@ -311,6 +489,43 @@ func KeepGitDir() GitOption {
 	})
 }
 
+// SkipSubmodules disables checking out the submodules of the repository.
+func SkipSubmodules() GitOption {
+	return gitOptionFunc(func(gi *GitInfo) {
+		gi.SkipSubmodules = true
+	})
+}
+
+// LFS enables fetching the Git LFS objects of the checked out files.
+func LFS() GitOption {
+	return gitOptionFunc(func(gi *GitInfo) {
+		gi.LFS = true
+	})
+}
+
+// GitDepth sets the number of commits fetched from the history of the ref.
+func GitDepth(depth int) GitOption {
+	return gitOptionFunc(func(gi *GitInfo) {
+		gi.Depth = depth
+	})
+}
+
+// GitFilter fetches the repository as a partial clone with the object
+// filter, e.g. "blob:none". Filtered objects are fetched on demand for the
+// checked out files.
+func GitFilter(filter string) GitOption {
+	return gitOptionFunc(func(gi *GitInfo) {
+		gi.Filter = filter
+	})
+}
+
+// GitSparsePaths limits the checkout to the paths of the repository.
+func GitSparsePaths(paths ...string) GitOption {
+	return gitOptionFunc(func(gi *GitInfo) {
+		gi.SparsePaths = append(gi.SparsePaths, paths...)
+	})
+}
+
 func AuthTokenSecret(v string) GitOption {
 	return gitOptionFunc(func(gi *GitInfo) {
 		gi.AuthTokenSecret = v
@@ -498,6 +713,40 @@ func HTTP(url string, opts ...HTTPOption) State {
 		attrs[pb.AttrHTTPGID] = strconv.Itoa(hi.GID)
 		addCap(&hi.Constraints, pb.CapSourceHTTPUIDGID)
 	}
+	for k, v := range hi.Header {
+		attrs[pb.AttrHTTPHeaderPrefix+k] = v
+		addCap(&hi.Constraints, pb.CapSourceHTTPHeader)
+	}
+	if hi.AuthHeaderSecret != "" {
+		attrs[pb.AttrHTTPAuthHeaderSecret] = hi.AuthHeaderSecret
+		addCap(&hi.Constraints, pb.CapSourceHTTPAuth)
+	}
+	if hi.AuthTokenSecret != "" {
+		attrs[pb.AttrHTTPAuthTokenSecret] = hi.AuthTokenSecret
+		addCap(&hi.Constraints, pb.CapSourceHTTPAuth)
+	}
+	if p := hi.Proxy; p != nil && (p.HTTPProxy != "" || p.HTTPSProxy != "") {
+		if p.HTTPProxy != "" {
+			attrs[pb.AttrHTTPProxy] = p.HTTPProxy
+		}
+		if p.HTTPSProxy != "" {
+			attrs[pb.AttrHTTPSProxy] = p.HTTPSProxy
+		}
+		if p.NoProxy != "" {
+			attrs[pb.AttrHTTPNoProxy] = p.NoProxy
+		}
+		addCap(&hi.Constraints, pb.CapSourceHTTPProxy)
+	}
+	if hi.Unpack != "" {
+		attrs[pb.AttrHTTPUnpack] = hi.Unpack
+		if hi.StripComponents != 0 {
+			attrs[pb.AttrHTTPUnpackStripComponents] = strconv.Itoa(hi.StripComponents)
+		}
+		if hi.UnpackSubpath != "" {
+			attrs[pb.AttrHTTPUnpackSubpath] = hi.UnpackSubpath
+		}
+		addCap(&hi.Constraints, pb.CapSourceHTTPUnpack)
+	}
 
 	addCap(&hi.Constraints, pb.CapSourceHTTP)
 	source := NewSource(url, attrs, hi.Constraints)
@@ -511,6 +760,15 @@ type HTTPInfo struct {
 	Perm     int
 	UID      int
 	GID      int
+	Header   map[string]string
+
+	AuthHeaderSecret string
+	AuthTokenSecret  string
+	Proxy            *ProxyEnv
+
+	Unpack          string
+	StripComponents int
+	UnpackSubpath   string
 }
 
 type HTTPOption interface {
@@ -548,6 +806,70 @@ func Chown(uid, gid int) HTTPOption {
 	})
 }
 
+// HTTPHeader sets a header of the requests for the URL. Headers with
+// credentials should be set with HTTPAuthHeaderSecret instead, as the values
+// are part of the definition.
+func HTTPHeader(name, value string) HTTPOption {
+	return httpOptionFunc(func(hi *HTTPInfo) {
+		if hi.Header == nil {
+			hi.Header = map[string]string{}
+		}
+		hi.Header[name] = value
+	})
+}
+
+// HTTPAuthHeaderSecret sets the Authorization header of the requests to the
+// value of the secret. A secret named "<name>.<host>" is used if it exists.
+func HTTPAuthHeaderSecret(secretName string) HTTPOption {
+	return httpOptionFunc(func(hi *HTTPInfo) {
+		hi.AuthHeaderSecret = secretName
+	})
+}
+
+// HTTPAuthTokenSecret sends the value of the secret as a bearer token. A
+// secret named "<name>.<host>" is used if it exists.
+func HTTPAuthTokenSecret(secretName string) HTTPOption {
+	return httpOptionFunc(func(hi *HTTPInfo) {
+		hi.AuthTokenSecret = secretName
+	})
+}
+
+// HTTPProxy sets the proxies used for downloading the URL. Only the HTTP,
+// HTTPS and no proxy values are used.
+func HTTPProxy(pe ProxyEnv) HTTPOption {
+	return httpOptionFunc(func(hi *HTTPInfo) {
+		hi.Proxy = &pe
+	})
+}
+
+// HTTPUnpack extracts the downloaded archive instead of saving the file. The
+// format is one of "tar", "zip", "decompress" for the compressed files that
+// are not archives, or "auto" to detect it and save the files that are not
+// archives as they are. The compressions of tar archives are detected.
+func HTTPUnpack(format string) HTTPOption {
+	return httpOptionFunc(func(hi *HTTPInfo) {
+		hi.Unpack = format
+	})
+}
+
+// HTTPStripComponents removes the n leading path components of the files of
+// the archive extracted by HTTPUnpack. The files with fewer components are
+// not extracted.
+func HTTPStripComponents(n int) HTTPOption {
+	return httpOptionFunc(func(hi *HTTPInfo) {
+		hi.StripComponents = n
+	})
+}
+
+// HTTPUnpackSubpath only extracts the files in the path of the archive
+// extracted by HTTPUnpack, relative to it. It applies after
+// HTTPStripComponents.
+func HTTPUnpackSubpath(p string) HTTPOption {
+	return httpOptionFunc(func(hi *HTTPInfo) {
+		hi.UnpackSubpath = p
+	})
+}
+
 func platformSpecificSource(id string) bool {
 	return strings.HasPrefix(id, "docker-image://")
 }
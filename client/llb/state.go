@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"time"
 
 	"github.com/containerd/containerd/platforms"
 	"github.com/moby/buildkit/identity"
@@ -167,6 +168,12 @@ func (s State) Marshal(ctx context.Context, co ...ConstraintsOpt) (*Definition,
 		if m.ExportCache != nil {
 			md.Caps[pb.CapMetaExportCache] = true
 		}
+		if m.Timeout != 0 {
+			md.Caps[pb.CapMetaTimeout] = true
+		}
+		if m.Retry != nil {
+			md.Caps[pb.CapMetaRetry] = true
+		}
 	}
 
 	def.Metadata[dgst] = md
@@ -265,6 +272,7 @@ func (s State) Run(ro ...RunOption) ExecState {
 	}
 	exec.secrets = ei.Secrets
 	exec.ssh = ei.SSH
+	exec.cacheMode = ei.CacheMode
 
 	return ExecState{
 		State: s.WithOutput(exec.Output()),
@@ -380,6 +388,18 @@ func (s State) GetNetwork(ctx context.Context, co ...ConstraintsOpt) (pb.NetMode
 	}
 	return getNetwork(s)(ctx, c)
 }
+func (s State) NetworkName(name string) State {
+	return NetworkName(name)(s)
+}
+
+func (s State) GetNetworkName(ctx context.Context, co ...ConstraintsOpt) (string, error) {
+	c := &Constraints{}
+	for _, f := range co {
+		f.SetConstraintsOption(c)
+	}
+	return getNetworkName(s)(ctx, c)
+}
+
 func (s State) Security(n pb.SecurityMode) State {
 	return Security(n)(s)
 }
@@ -411,6 +431,54 @@ func (s State) WithCgroupParent(cp string) State {
 	return cgroupParent(cp)(s)
 }
 
+// WithSeccompProfile sets the seccomp profile of the processes run from the
+// state. Supported profiles are "default" and "unconfined".
+func (s State) WithSeccompProfile(name string) State {
+	return seccompProfile(name)(s)
+}
+
+// WithApparmorProfile sets the AppArmor profile of the processes run from the
+// state, overriding the profile of the worker. The profile needs to be loaded
+// on the host.
+func (s State) WithApparmorProfile(name string) State {
+	return apparmorProfile(name)(s)
+}
+
+// WithTraceContext sets whether the trace context of the build is passed to
+// the processes run from the state as TRACEPARENT and TRACESTATE environment
+// variables.
+func (s State) WithTraceContext(v bool) State {
+	return traceContext(v)(s)
+}
+
+// DNS overrides the DNS config of the worker and of the build for the
+// processes run from the state.
+func (s State) DNS(cfg DNSConfig) State {
+	return DNS(cfg)(s)
+}
+
+func (s State) GetDNS(ctx context.Context, co ...ConstraintsOpt) (*DNSConfig, error) {
+	c := &Constraints{}
+	for _, f := range co {
+		f.SetConstraintsOption(c)
+	}
+	return getDNS(s)(ctx, c)
+}
+
+// Runtime runs the processes of the state with the OCI runtime of the worker
+// with name.
+func (s State) Runtime(name string) State {
+	return Runtime(name)(s)
+}
+
+func (s State) GetRuntime(ctx context.Context, co ...ConstraintsOpt) (string, error) {
+	c := &Constraints{}
+	for _, f := range co {
+		f.SetConstraintsOption(c)
+	}
+	return getRuntime(s)(ctx, c)
+}
+
 func (s State) isFileOpCopyInput() {}
 
 type output struct {
@@ -454,6 +522,15 @@ type ConstraintsOpt interface {
 	HTTPOption
 	ImageOption
 	GitOption
+	OCILayoutOption
+	ObjectStoreOption
+	MkdirOption
+	MkfileOption
+	RmOption
+	SymlinkOption
+	LinkOption
+	SetAttrOption
+	CopyOption
 }
 
 type constraintsOptFunc func(m *Constraints)
@@ -482,6 +559,42 @@ func (fn constraintsOptFunc) SetGitOption(gi *GitInfo) {
 	gi.applyConstraints(fn)
 }
 
+func (fn constraintsOptFunc) SetOCILayoutOption(oi *OCILayoutInfo) {
+	oi.applyConstraints(fn)
+}
+
+func (fn constraintsOptFunc) SetObjectStoreOption(oi *ObjectStoreInfo) {
+	oi.applyConstraints(fn)
+}
+
+func (fn constraintsOptFunc) SetMkdirOption(mi *MkdirInfo) {
+	mi.applyConstraints(fn)
+}
+
+func (fn constraintsOptFunc) SetMkfileOption(mi *MkfileInfo) {
+	mi.applyConstraints(fn)
+}
+
+func (fn constraintsOptFunc) SetRmOption(mi *RmInfo) {
+	mi.applyConstraints(fn)
+}
+
+func (fn constraintsOptFunc) SetSymlinkOption(mi *SymlinkInfo) {
+	mi.applyConstraints(fn)
+}
+
+func (fn constraintsOptFunc) SetLinkOption(mi *LinkInfo) {
+	mi.applyConstraints(fn)
+}
+
+func (fn constraintsOptFunc) SetSetAttrOption(mi *SetAttrInfo) {
+	mi.applyConstraints(fn)
+}
+
+func (fn constraintsOptFunc) SetCopyOption(mi *CopyInfo) {
+	mi.applyConstraints(fn)
+}
+
 func mergeMetadata(m1, m2 pb.OpMetadata) pb.OpMetadata {
 	if m2.IgnoreCache {
 		m1.IgnoreCache = true
@@ -508,6 +621,12 @@ func mergeMetadata(m1, m2 pb.OpMetadata) pb.OpMetadata {
 	if m2.ProgressGroup != nil {
 		m1.ProgressGroup = m2.ProgressGroup
 	}
+	if m2.Timeout != 0 {
+		m1.Timeout = m2.Timeout
+	}
+	if m2.Retry != nil {
+		m1.Retry = m2.Retry
+	}
 
 	return m1
 }
@@ -527,9 +646,13 @@ func WithDescription(m map[string]string) ConstraintsOpt {
 	})
 }
 
+// customNameKey is the description key of the name of the vertex shown in
+// the progress.
+const customNameKey = "llb.customname"
+
 func WithCustomName(name string) ConstraintsOpt {
 	return WithDescription(map[string]string{
-		"llb.customname": name,
+		customNameKey: name,
 	})
 }
 
@@ -598,6 +721,23 @@ func LocalUniqueID(v string) ConstraintsOpt {
 	})
 }
 
+// WithTimeout cancels executions of the vertex that run longer than the
+// duration and fails them with a timeout error.
+func WithTimeout(d time.Duration) ConstraintsOpt {
+	return constraintsOptFunc(func(c *Constraints) {
+		c.Metadata.Timeout = int64(d)
+	})
+}
+
+// WithRetry retries failed executions of the vertex up to attempts times, at
+// most 10, larger values are reduced to 10. The first retry waits for delay, every further retry twice as long
+// as the previous one, up to 5 minutes.
+func WithRetry(attempts int, delay time.Duration) ConstraintsOpt {
+	return constraintsOptFunc(func(c *Constraints) {
+		c.Metadata.Retry = &pb.RetryPolicy{Attempts: int64(attempts), Delay: int64(delay)}
+	})
+}
+
 func ProgressGroup(id, name string, weak bool) ConstraintsOpt {
 	return constraintsOptFunc(func(c *Constraints) {
 		c.Metadata.ProgressGroup = &pb.ProgressGroup{Id: id, Name: name, Weak: weak}
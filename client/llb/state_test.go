@@ -3,6 +3,7 @@ package llb
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/moby/buildkit/solver/pb"
 	digest "github.com/opencontainers/go-digest"
@@ -167,3 +168,24 @@ func getEnvHelper(t *testing.T, s State, k string) (string, bool) {
 	require.NoError(t, err)
 	return v, ok
 }
+
+func TestStateTimeoutRetry(t *testing.T) {
+	t.Parallel()
+
+	st := Image("foo").Run(Shlex("make"), WithTimeout(10*time.Minute), WithRetry(3, time.Second)).Root()
+	def, err := st.Marshal(context.TODO())
+	require.NoError(t, err)
+
+	m, arr := parseDef(t, def.Def)
+	dgst, _ := last(t, arr)
+	exec := m[dgst]
+	require.NotNil(t, exec.GetExec())
+
+	md := def.Metadata[dgst]
+	require.Equal(t, int64(10*time.Minute), md.Timeout)
+	require.Equal(t, &pb.RetryPolicy{Attempts: 3, Delay: int64(time.Second)}, md.Retry)
+
+	caps := def.Metadata[digest.FromBytes(def.Def[len(def.Def)-1])].Caps
+	require.True(t, caps[pb.CapMetaTimeout])
+	require.True(t, caps[pb.CapMetaRetry])
+}
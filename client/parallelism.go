@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/pkg/errors"
+)
+
+// Parallelism limits the number of operations the daemon runs at a time
+// across builds. Zero values are unlimited.
+type Parallelism struct {
+	// Max limits all operations.
+	Max int
+	// Network limits image pulls, git fetches, http downloads and cache
+	// imports.
+	Network int
+	// Exec limits exec operations.
+	Exec int
+	// Speculative limits the network sources fetched speculatively while
+	// builds compute their cache keys. Zero disables speculation.
+	Speculative int
+}
+
+// Parallelism returns the current parallelism limits of the daemon.
+func (c *Client) Parallelism(ctx context.Context) (*Parallelism, error) {
+	resp, err := c.controlClient().GetParallelism(ctx, &controlapi.GetParallelismRequest{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get parallelism")
+	}
+	return fromAPIParallelism(resp), nil
+}
+
+// SetParallelism changes the parallelism limits of the daemon until it is
+// restarted. Running operations are not affected.
+func (c *Client) SetParallelism(ctx context.Context, p Parallelism) (*Parallelism, error) {
+	resp, err := c.controlClient().SetParallelism(ctx, &controlapi.Parallelism{
+		Max:         int64(p.Max),
+		Network:     int64(p.Network),
+		Exec:        int64(p.Exec),
+		Speculative: int64(p.Speculative),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to set parallelism")
+	}
+	return fromAPIParallelism(resp), nil
+}
+
+func fromAPIParallelism(p *controlapi.Parallelism) *Parallelism {
+	return &Parallelism{
+		Max:         int(p.Max),
+		Network:     int(p.Network),
+		Exec:        int(p.Exec),
+		Speculative: int(p.Speculative),
+	}
+}
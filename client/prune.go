@@ -49,6 +49,7 @@ func (c *Client) Prune(ctx context.Context, ch chan UsageInfo, opts ...PruneOpti
 				LastUsedAt:  d.LastUsedAt,
 				RecordType:  UsageRecordType(d.RecordType),
 				Shared:      d.Shared,
+				Tenant:      d.Tenant,
 			}
 		}
 	}
@@ -63,6 +64,17 @@ type PruneInfo struct {
 	All          bool          `json:"all"`
 	KeepDuration time.Duration `json:"keepDuration"`
 	KeepBytes    int64         `json:"keepBytes"`
+	// ReservedSpace is the size of the cache that is never pruned.
+	ReservedSpace int64 `json:"reservedSpace,omitempty"`
+	// MinFreeSpace prunes records until the disk of the cache has this many
+	// free bytes.
+	MinFreeSpace int64 `json:"minFreeSpace,omitempty"`
+	// Schedule is the cron-like schedule of a GC policy. Policies without a
+	// schedule run after builds.
+	Schedule string `json:"schedule,omitempty"`
+	// Tenant limits the policy to the records created for the tenant. The
+	// sizes of the policy are then the sizes of the records of the tenant.
+	Tenant string `json:"tenant,omitempty"`
 }
 
 type pruneOptionFunc func(*PruneInfo)
@@ -0,0 +1,19 @@
+package client
+
+import (
+	"context"
+
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/pkg/errors"
+)
+
+// ReloadConfig makes the daemon reload the registry configuration from its
+// configuration file, without interrupting running builds. The configured
+// registries are returned.
+func (c *Client) ReloadConfig(ctx context.Context) ([]string, error) {
+	resp, err := c.controlClient().ReloadConfig(ctx, &controlapi.ReloadConfigRequest{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to reload config")
+	}
+	return resp.Registries, nil
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/pkg/errors"
+)
+
+// RootlessInfo reports whether the daemon runs in rootless mode and the
+// features of its host used in rootless mode.
+type RootlessInfo struct {
+	Rootless bool            `json:"rootless"`
+	Checks   []RootlessCheck `json:"checks"`
+}
+
+// RootlessCheck is the detection of a feature used in rootless mode. The
+// message of a missing feature describes the limitation and how to lift it.
+type RootlessCheck struct {
+	Name    string `json:"name"`
+	OK      bool   `json:"ok"`
+	Message string `json:"message"`
+}
+
+// RootlessInfo detects the features of the daemon host used in rootless mode,
+// like overlayfs, cgroup v2 and slirp4netns.
+func (c *Client) RootlessInfo(ctx context.Context) (*RootlessInfo, error) {
+	resp, err := c.controlClient().RootlessInfo(ctx, &controlapi.RootlessInfoRequest{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get rootless info")
+	}
+	info := &RootlessInfo{Rootless: resp.Rootless}
+	for _, c := range resp.Checks {
+		info.Checks = append(info.Checks, RootlessCheck{
+			Name:    c.Name,
+			OK:      c.OK,
+			Message: c.Message,
+		})
+	}
+	return info, nil
+}
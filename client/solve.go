@@ -20,6 +20,7 @@ import (
 	"github.com/moby/buildkit/session/filesync"
 	"github.com/moby/buildkit/session/grpchijack"
 	"github.com/moby/buildkit/solver/pb"
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/entitlements"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
@@ -42,6 +43,63 @@ type SolveOpt struct {
 	AllowedEntitlements   []entitlements.Entitlement
 	SharedSession         *session.Session // TODO: refactor to better session syncing
 	SessionPreInitialized bool             // TODO: refactor to better session syncing
+	// DryRun reports the cache status of the vertexes of the definition
+	// without building it. Exports are ignored.
+	DryRun bool
+	// Ref is the ID of the build. A random ID is used if it is empty.
+	Ref string
+	// Detach keeps the build running on the daemon if the client
+	// disconnects. Its result can be retrieved with Client.Attach.
+	Detach bool
+	// ResourceLimits constrain the containers of the exec operations of the
+	// build. The defaults of the daemon are used for unset limits.
+	ResourceLimits *ResourceLimits
+	// Weight is the share of the parallel operations the build gets when
+	// builds compete for them on the daemon.
+	Weight int
+	// SourcePolicy denies or rewrites the sources of the build. The source
+	// policy of the daemon is applied after it.
+	SourcePolicy *spb.Policy
+	// OCIStores are the OCI layout stores the oci-layout sources of the
+	// build load their images from, keyed by store name. Stores created with
+	// sessioncontent.NewOCILayoutStore can resolve images by tag.
+	OCIStores map[string]content.Store
+	// DNS overrides the DNS config of the worker for the exec operations of
+	// the build that don't set their own with llb.DNS.
+	DNS *DNSConfig
+	// Userns is the user namespace mode of the exec operations of the build,
+	// "host" to run them with the uids of the host or "private" to map them
+	// to uid and gid ranges no other container uses. The default of the
+	// worker is used if it is empty.
+	Userns string
+	// Runtime is the name of the OCI runtime of the worker the exec
+	// operations of the build that don't set their own with llb.Runtime are
+	// run with, e.g. to isolate untrusted builds with gVisor or Kata
+	// Containers.
+	Runtime string
+	// BandwidthLimits limit the transfer rates of the build, so that builds
+	// sharing a network link get a fair share of it. The defaults of the
+	// daemon are used for unset limits.
+	BandwidthLimits *BandwidthLimits
+}
+
+// DNSConfig is the DNS config of the exec containers of a build. The fields
+// that are not set keep the values of the worker.
+type DNSConfig struct {
+	Nameservers   []string
+	Options       []string
+	SearchDomains []string
+}
+
+// ResourceLimits are the limits of the exec containers of a build. Zero
+// values are not set.
+type ResourceLimits struct {
+	// CPU is the number of CPUs, e.g. 1.5
+	CPU float64
+	// Memory is the memory limit in bytes
+	Memory int64
+	// Pids is the maximum number of processes
+	Pids int64
 }
 
 type ExportEntry struct {
@@ -81,13 +139,25 @@ func (c *Client) solve(ctx context.Context, def *llb.Definition, runGateway runG
 	if def != nil && runGateway != nil {
 		return nil, errors.New("invalid with def and cb")
 	}
+	if opt.DryRun && def == nil {
+		return nil, errors.New("dry run requires an LLB definition")
+	}
 
 	syncedDirs, err := prepareSyncedDirs(def, opt.LocalDirs)
 	if err != nil {
 		return nil, err
 	}
 
-	ref := identity.NewID()
+	var digestFile string
+	opt.Exports, digestFile, err = splitDigestFile(opt.Exports)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := opt.Ref
+	if ref == "" {
+		ref = identity.NewID()
+	}
 	eg, ctx := errgroup.WithContext(ctx)
 
 	statusContext, cancelStatus := context.WithCancel(context.Background())
@@ -114,14 +184,6 @@ func (c *Client) solve(ctx context.Context, def *llb.Definition, runGateway runG
 		return nil, err
 	}
 
-	var ex ExportEntry
-	if len(opt.Exports) > 1 {
-		return nil, errors.New("currently only single Exports can be specified")
-	}
-	if len(opt.Exports) == 1 {
-		ex = opt.Exports[0]
-	}
-
 	if !opt.SessionPreInitialized {
 		if len(syncedDirs) > 0 {
 			s.Allow(filesync.NewFSSyncProvider(syncedDirs))
@@ -131,34 +193,47 @@ func (c *Client) solve(ctx context.Context, def *llb.Definition, runGateway runG
 			s.Allow(a)
 		}
 
-		switch ex.Type {
-		case ExporterLocal:
-			if ex.Output != nil {
-				return nil, errors.New("output file writer is not supported by local exporter")
-			}
-			if ex.OutputDir == "" {
-				return nil, errors.New("output directory is required for local exporter")
-			}
-			s.Allow(filesync.NewFSSyncTargetDir(ex.OutputDir))
-		case ExporterOCI, ExporterDocker, ExporterTar:
-			if ex.OutputDir != "" {
-				return nil, errors.Errorf("output directory %s is not supported by %s exporter", ex.OutputDir, ex.Type)
-			}
-			if ex.Output == nil {
-				return nil, errors.Errorf("output file writer is required for %s exporter", ex.Type)
-			}
-			s.Allow(filesync.NewFSSyncTarget(ex.Output))
-		default:
-			if ex.Output != nil {
-				return nil, errors.Errorf("output file writer is not supported by %s exporter", ex.Type)
-			}
-			if ex.OutputDir != "" {
-				return nil, errors.Errorf("output directory %s is not supported by %s exporter", ex.OutputDir, ex.Type)
+		var syncTargets []filesync.FSSyncTarget
+		for exID, ex := range opt.Exports {
+			switch ex.Type {
+			case ExporterLocal:
+				if ex.Output != nil {
+					return nil, errors.New("output file writer is not supported by local exporter")
+				}
+				if ex.OutputDir == "" {
+					return nil, errors.New("output directory is required for local exporter")
+				}
+				syncTargets = append(syncTargets, filesync.WithFSSyncDir(exID, ex.OutputDir))
+			case ExporterOCI, ExporterDocker, ExporterTar:
+				if ex.OutputDir != "" {
+					return nil, errors.Errorf("output directory %s is not supported by %s exporter", ex.OutputDir, ex.Type)
+				}
+				if ex.Output == nil {
+					return nil, errors.Errorf("output file writer is required for %s exporter", ex.Type)
+				}
+				syncTargets = append(syncTargets, filesync.WithFSSync(exID, ex.Output))
+			default:
+				if ex.Output != nil {
+					return nil, errors.Errorf("output file writer is not supported by %s exporter", ex.Type)
+				}
+				if ex.OutputDir != "" {
+					return nil, errors.Errorf("output directory %s is not supported by %s exporter", ex.OutputDir, ex.Type)
+				}
 			}
 		}
+		if len(syncTargets) > 0 {
+			s.Allow(filesync.NewFSSyncTarget(syncTargets...))
+		}
 
-		if len(cacheOpt.contentStores) > 0 {
-			s.Allow(sessioncontent.NewAttachable(cacheOpt.contentStores))
+		contentStores := map[string]content.Store{}
+		for k, v := range cacheOpt.contentStores {
+			contentStores[k] = v
+		}
+		for k, v := range opt.OCIStores {
+			contentStores[sessioncontent.OCIStoreIDPrefix+k] = v
+		}
+		if len(contentStores) > 0 {
+			s.Allow(sessioncontent.NewAttachable(contentStores))
 		}
 
 		eg.Go(func() error {
@@ -205,24 +280,49 @@ func (c *Client) solve(ctx context.Context, def *llb.Definition, runGateway runG
 			frontendInputs[key] = def.ToPB()
 		}
 
+		exports := make([]*controlapi.Exporter, 0, len(opt.Exports))
+		for _, exp := range opt.Exports {
+			exports = append(exports, &controlapi.Exporter{
+				Type:  exp.Type,
+				Attrs: exp.Attrs,
+			})
+		}
+
+		// keep sending the single exporter with the deprecated fields so
+		// that older daemons not supporting multiple exporters still work
+		var exporterDeprecated string
+		var exporterAttrsDeprecated map[string]string
+		if len(opt.Exports) == 1 {
+			exporterDeprecated = opt.Exports[0].Type
+			exporterAttrsDeprecated = opt.Exports[0].Attrs
+		}
+
 		resp, err := c.controlClient().Solve(ctx, &controlapi.SolveRequest{
-			Ref:            ref,
-			Definition:     pbd,
-			Exporter:       ex.Type,
-			ExporterAttrs:  ex.Attrs,
-			Session:        s.ID(),
-			Frontend:       opt.Frontend,
-			FrontendAttrs:  opt.FrontendAttrs,
-			FrontendInputs: frontendInputs,
-			Cache:          cacheOpt.options,
-			Entitlements:   opt.AllowedEntitlements,
+			Ref:                     ref,
+			Definition:              pbd,
+			Exporters:               exports,
+			ExporterDeprecated:      exporterDeprecated,
+			ExporterAttrsDeprecated: exporterAttrsDeprecated,
+			Session:                 s.ID(),
+			Frontend:                opt.Frontend,
+			FrontendAttrs:           opt.FrontendAttrs,
+			FrontendInputs:          frontendInputs,
+			Cache:                   cacheOpt.options,
+			Entitlements:            opt.AllowedEntitlements,
+			DryRun:                  opt.DryRun,
+			Detach:                  opt.Detach,
+			Resources:               toAPIResourceLimits(opt.ResourceLimits),
+			Weight:                  int64(opt.Weight),
+			SourcePolicy:            opt.SourcePolicy,
+			DNS:                     toAPIDNSConfig(opt.DNS),
+			Userns:                  opt.Userns,
+			Runtime:                 opt.Runtime,
+			Bandwidth:               toAPIBandwidthLimits(opt.BandwidthLimits),
 		})
 		if err != nil {
 			return errors.Wrap(err, "failed to solve")
 		}
-		res = &SolveResponse{
-			ExporterResponse: resp.ExporterResponse,
-		}
+		res = newSolveResponse(resp)
 		return nil
 	})
 
@@ -249,68 +349,7 @@ func (c *Client) solve(ctx context.Context, def *llb.Definition, runGateway runG
 	}
 
 	eg.Go(func() error {
-		stream, err := c.controlClient().Status(statusContext, &controlapi.StatusRequest{
-			Ref: ref,
-		})
-		if err != nil {
-			return errors.Wrap(err, "failed to get status")
-		}
-		for {
-			resp, err := stream.Recv()
-			if err != nil {
-				if err == io.EOF {
-					return nil
-				}
-				return errors.Wrap(err, "failed to receive status")
-			}
-			s := SolveStatus{}
-			for _, v := range resp.Vertexes {
-				s.Vertexes = append(s.Vertexes, &Vertex{
-					Digest:        v.Digest,
-					Inputs:        v.Inputs,
-					Name:          v.Name,
-					Started:       v.Started,
-					Completed:     v.Completed,
-					Error:         v.Error,
-					Cached:        v.Cached,
-					ProgressGroup: v.ProgressGroup,
-				})
-			}
-			for _, v := range resp.Statuses {
-				s.Statuses = append(s.Statuses, &VertexStatus{
-					ID:        v.ID,
-					Vertex:    v.Vertex,
-					Name:      v.Name,
-					Total:     v.Total,
-					Current:   v.Current,
-					Timestamp: v.Timestamp,
-					Started:   v.Started,
-					Completed: v.Completed,
-				})
-			}
-			for _, v := range resp.Logs {
-				s.Logs = append(s.Logs, &VertexLog{
-					Vertex:    v.Vertex,
-					Stream:    int(v.Stream),
-					Data:      v.Msg,
-					Timestamp: v.Timestamp,
-				})
-			}
-			for _, v := range resp.Warnings {
-				s.Warnings = append(s.Warnings, &VertexWarning{
-					Vertex:     v.Vertex,
-					Level:      int(v.Level),
-					Short:      v.Short,
-					Detail:     v.Detail,
-					URL:        v.Url,
-					SourceInfo: v.Info,
-					Range:      v.Ranges,
-				})
-			}
-			if statusChan != nil {
-				statusChan <- &s
-			}
-		}
+		return c.streamStatus(statusContext, ref, statusChan)
 	})
 
 	if err := eg.Wait(); err != nil {
@@ -329,9 +368,109 @@ func (c *Client) solve(ctx context.Context, def *llb.Definition, runGateway runG
 			}
 		}
 	}
+	if digestFile != "" {
+		if err := writeDigestFile(digestFile, res.ExporterResponse); err != nil {
+			return nil, err
+		}
+	}
 	return res, nil
 }
 
+// streamStatus sends the progress of the build with ref to statusChan.
+func (c *Client) streamStatus(ctx context.Context, ref string, statusChan chan *SolveStatus) error {
+	stream, err := c.controlClient().Status(ctx, &controlapi.StatusRequest{
+		Ref: ref,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to get status")
+	}
+	return receiveStatus(stream, statusChan)
+}
+
+// receiveStatus converts the status responses of the stream and sends them to
+// statusChan until the stream ends.
+func receiveStatus(stream interface {
+	Recv() (*controlapi.StatusResponse, error)
+}, statusChan chan *SolveStatus) error {
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return errors.Wrap(err, "failed to receive status")
+		}
+		s := SolveStatus{}
+		for _, v := range resp.Vertexes {
+			s.Vertexes = append(s.Vertexes, &Vertex{
+				Digest:        v.Digest,
+				Inputs:        v.Inputs,
+				Name:          v.Name,
+				Started:       v.Started,
+				Completed:     v.Completed,
+				Error:         v.Error,
+				Cached:        v.Cached,
+				ProgressGroup: v.ProgressGroup,
+				CacheMiss:     cacheMissFromPB(v.CacheMiss),
+			})
+		}
+		for _, v := range resp.Statuses {
+			s.Statuses = append(s.Statuses, &VertexStatus{
+				ID:        v.ID,
+				Vertex:    v.Vertex,
+				Name:      v.Name,
+				Total:     v.Total,
+				Current:   v.Current,
+				Timestamp: v.Timestamp,
+				Started:   v.Started,
+				Completed: v.Completed,
+			})
+		}
+		for _, v := range resp.Logs {
+			s.Logs = append(s.Logs, &VertexLog{
+				Vertex:    v.Vertex,
+				Stream:    int(v.Stream),
+				Data:      v.Msg,
+				Timestamp: v.Timestamp,
+			})
+		}
+		for _, v := range resp.Warnings {
+			s.Warnings = append(s.Warnings, &VertexWarning{
+				Vertex:     v.Vertex,
+				Level:      int(v.Level),
+				Short:      v.Short,
+				Detail:     v.Detail,
+				URL:        v.Url,
+				SourceInfo: v.Info,
+				Range:      v.Ranges,
+			})
+		}
+		if statusChan != nil {
+			statusChan <- &s
+		}
+	}
+}
+
+func newSolveResponse(resp *controlapi.SolveResponse) *SolveResponse {
+	res := &SolveResponse{
+		ExporterResponse: resp.ExporterResponse,
+	}
+	for _, s := range resp.CacheStatus {
+		res.CacheStatus = append(res.CacheStatus, &VertexCacheStatus{
+			Digest:          s.Digest,
+			Name:            s.Name,
+			Cached:          s.Cached,
+			Uncertain:       s.Uncertain,
+			Local:           s.Local,
+			Remote:          s.Remote,
+			Key:             s.Key,
+			CacheMapDigests: s.CacheMapDigests,
+			Inputs:          s.Inputs,
+		})
+	}
+	return res
+}
+
 func prepareSyncedDirs(def *llb.Definition, localDirs map[string]string) ([]filesync.SyncedDir, error) {
 	for _, d := range localDirs {
 		fi, err := os.Stat(d)
@@ -504,3 +643,59 @@ func parseCacheOptions(ctx context.Context, isGateway bool, opt SolveOpt) (*cach
 	}
 	return &res, nil
 }
+
+func toAPIDNSConfig(dns *DNSConfig) *pb.DNSConfig {
+	if dns == nil {
+		return nil
+	}
+	return &pb.DNSConfig{
+		Nameservers:   dns.Nameservers,
+		Options:       dns.Options,
+		SearchDomains: dns.SearchDomains,
+	}
+}
+
+// BandwidthLimits are the transfer rates of a build in bytes per second.
+// Zero values are not set.
+type BandwidthLimits struct {
+	// Context limits the transfer of the local contexts from the client
+	Context int64
+	// Push limits the blobs pushed to registries
+	Push int64
+	// Pull limits the content pulled from registries
+	Pull int64
+}
+
+func toAPIBandwidthLimits(l *BandwidthLimits) *controlapi.BandwidthLimits {
+	if l == nil {
+		return nil
+	}
+	return &controlapi.BandwidthLimits{
+		Context: l.Context,
+		Push:    l.Push,
+		Pull:    l.Pull,
+	}
+}
+
+func toAPIResourceLimits(r *ResourceLimits) *controlapi.ResourceLimits {
+	if r == nil {
+		return nil
+	}
+	return &controlapi.ResourceLimits{
+		CPU:    r.CPU,
+		Memory: r.Memory,
+		Pids:   r.Pids,
+	}
+}
+
+func cacheMissFromPB(m *controlapi.CacheMiss) *CacheMiss {
+	if m == nil {
+		return nil
+	}
+	return &CacheMiss{
+		Reason:  CacheMissReason(m.Reason),
+		Input:   int(m.Input),
+		Digest:  m.Digest,
+		Message: m.Message,
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	bccommon "github.com/moby/buildkit/cmd/buildctl/common"
+	"github.com/moby/buildkit/util/progress/progresswriter"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+	"golang.org/x/sync/errgroup"
+)
+
+var attachCommand = cli.Command{
+	Name:      "attach",
+	Usage:     "reattach to a build started with --detach",
+	ArgsUsage: "BUILD_ID",
+	Action:    attachAction,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "progress",
+			Usage: "Set type of progress (auto, plain, tty, json). Use plain to show container output",
+			Value: "auto",
+		},
+		cli.StringFlag{
+			Name:  "metadata-file",
+			Usage: "Output build metadata (e.g., image digest) to a file as JSON",
+		},
+	},
+}
+
+func attachAction(clicontext *cli.Context) error {
+	ref := clicontext.Args().First()
+	if ref == "" {
+		return errors.New("build ID must be specified")
+	}
+
+	c, err := bccommon.ResolveClient(clicontext)
+	if err != nil {
+		return err
+	}
+
+	// not using shared context to not disrupt display but let is finish reporting errors
+	pw, err := progresswriter.NewPrinter(context.TODO(), os.Stderr, clicontext.String("progress"))
+	if err != nil {
+		return err
+	}
+
+	eg, ctx := errgroup.WithContext(bccommon.CommandContext(clicontext))
+	eg.Go(func() error {
+		resp, err := c.Attach(ctx, ref, progresswriter.ResetTime(pw).Status())
+		if err != nil {
+			return err
+		}
+		for k, v := range resp.ExporterResponse {
+			logrus.Debugf("exporter response: %s=%s", k, v)
+		}
+		if metadataFile := clicontext.String("metadata-file"); metadataFile != "" && resp.ExporterResponse != nil {
+			return writeMetadataFile(metadataFile, resp.ExporterResponse)
+		}
+		return nil
+	})
+	eg.Go(func() error {
+		<-pw.Done()
+		return pw.Err()
+	})
+	return eg.Wait()
+}
@@ -4,17 +4,20 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
+	"text/tabwriter"
 
 	"github.com/containerd/continuity"
 	"github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/client/llb"
 	"github.com/moby/buildkit/cmd/buildctl/build"
 	bccommon "github.com/moby/buildkit/cmd/buildctl/common"
+	"github.com/moby/buildkit/identity"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/auth/authprovider"
-	"github.com/moby/buildkit/session/sshforward/sshprovider"
+	"github.com/moby/buildkit/session/httpfetch/httpfetchprovider"
 	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/progress/progresswriter"
 	digest "github.com/opencontainers/go-digest"
@@ -50,7 +53,7 @@ var buildCommand = cli.Command{
 		},
 		cli.StringFlag{
 			Name:  "progress",
-			Usage: "Set type of progress (auto, plain, tty). Use plain to show container output",
+			Usage: "Set type of progress (auto, plain, tty, json). Use plain to show container output",
 			Value: "auto",
 		},
 		cli.StringFlag{
@@ -61,19 +64,27 @@ var buildCommand = cli.Command{
 			Name:  "local",
 			Usage: "Allow build access to the local directory",
 		},
+		cli.StringSliceFlag{
+			Name:  "oci-layout",
+			Usage: "Allow build access to the local OCI layout, e.g. --oci-layout name=path",
+		},
 		cli.StringFlag{
 			Name:  "frontend",
 			Usage: "Define frontend used for build",
 		},
 		cli.StringSliceFlag{
 			Name:  "opt",
-			Usage: "Define custom options for frontend, e.g. --opt target=foo --opt build-arg:foo=bar",
+			Usage: "Define custom options for frontend, e.g. --opt target=foo --opt build-arg:foo=bar. Use key=@file to read a value from a file and @file or @- to read a JSON object of options from a file or stdin",
 		},
 		cli.StringSliceFlag{
 			Name:   "frontend-opt",
 			Usage:  "Define custom options for frontend, e.g. --frontend-opt target=foo --frontend-opt build-arg:foo=bar (DEPRECATED: use --opt)",
 			Hidden: true,
 		},
+		cli.StringSliceFlag{
+			Name:  "target",
+			Usage: "Build the target of the frontend. Repeat to build several targets in parallel, {target} in --output is replaced by the name of the target, e.g. --target app --target test --output type=local,dest=out/{target}",
+		},
 		cli.BoolFlag{
 			Name:  "no-cache",
 			Usage: "Disable cache for all the vertices",
@@ -93,7 +104,7 @@ var buildCommand = cli.Command{
 		},
 		cli.StringSliceFlag{
 			Name:  "secret",
-			Usage: "Secret value exposed to the build. Format id=secretname,src=filepath",
+			Usage: "Secret value exposed to the build. Format id=secretname,src=filepath[,type=file|env|vault|aws|template][,ttl=duration]",
 		},
 		cli.StringSliceFlag{
 			Name:  "allow",
@@ -103,10 +114,86 @@ var buildCommand = cli.Command{
 			Name:  "ssh",
 			Usage: "Allow forwarding SSH agent to the builder. Format default|<id>[=<socket>|<key>[,<key>]]",
 		},
+		cli.StringSliceFlag{
+			Name:  "socket",
+			Usage: "Allow forwarding a client socket to the socket mounts of the builder. Format <id>=<socket>",
+		},
+		cli.StringSliceFlag{
+			Name:  "http-via-client",
+			Usage: "Fetch the HTTP sources of the hosts matching the pattern with the network of the client, e.g. *.corp.example.com",
+		},
 		cli.StringFlag{
 			Name:  "metadata-file",
 			Usage: "Output build metadata (e.g., image digest) to a file as JSON",
 		},
+		cli.BoolFlag{
+			Name:  "debug-on-failure",
+			Usage: "Start an interactive shell in the container of a failed RUN step. Requires --frontend and a terminal",
+		},
+		cli.StringSliceFlag{
+			Name:  "debug-shell",
+			Usage: "Command started by --debug-on-failure (default: /bin/sh)",
+		},
+		cli.BoolFlag{
+			Name:  "detach",
+			Usage: "Keep the build running on the daemon if buildctl exits. Reattach with buildctl attach BUILD_ID",
+		},
+		cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Print which steps of the LLB definition would be loaded from the cache without building it",
+		},
+		cli.StringFlag{
+			Name:  "cpus",
+			Usage: "Number of CPUs of the containers of RUN steps, e.g. 1.5",
+		},
+		cli.StringFlag{
+			Name:  "memory",
+			Usage: "Memory limit of the containers of RUN steps, e.g. 512m",
+		},
+		cli.Int64Flag{
+			Name:  "pids-limit",
+			Usage: "Maximum number of processes in the containers of RUN steps",
+		},
+		cli.StringFlag{
+			Name:  "context-bandwidth",
+			Usage: "Transfer rate per second of the local contexts, e.g. 10m",
+		},
+		cli.StringFlag{
+			Name:  "push-bandwidth",
+			Usage: "Transfer rate per second of the registry pushes, e.g. 10m",
+		},
+		cli.StringFlag{
+			Name:  "pull-bandwidth",
+			Usage: "Transfer rate per second of the registry pulls, e.g. 10m",
+		},
+		cli.StringSliceFlag{
+			Name:  "dns",
+			Usage: "Nameserver of the containers of RUN steps, overriding the one of the worker",
+		},
+		cli.StringSliceFlag{
+			Name:  "dns-search",
+			Usage: "DNS search domain of the containers of RUN steps",
+		},
+		cli.StringSliceFlag{
+			Name:  "dns-option",
+			Usage: "DNS resolver option of the containers of RUN steps, e.g. ndots:2",
+		},
+		cli.StringFlag{
+			Name:  "userns",
+			Usage: "User namespace of the containers of RUN steps (host, private). Defaults to the worker config",
+		},
+		cli.StringFlag{
+			Name:  "runtime",
+			Usage: "OCI runtime of the worker the containers of RUN steps are run with, e.g. gvisor. Defaults to the worker config",
+		},
+		cli.IntFlag{
+			Name:  "weight",
+			Usage: "Share of the parallel operations of the daemon the build gets when builds compete for them",
+		},
+		cli.StringFlag{
+			Name:  "source-policy-file",
+			Usage: "Deny or rewrite the image, git and http sources of the build with the rules of a JSON source policy file",
+		},
 	},
 }
 
@@ -161,18 +248,17 @@ func buildAction(clicontext *cli.Context) error {
 
 	attachable := []session.Attachable{authprovider.NewDockerAuthProvider(os.Stderr)}
 
-	if ssh := clicontext.StringSlice("ssh"); len(ssh) > 0 {
-		configs, err := build.ParseSSH(ssh)
-		if err != nil {
-			return err
-		}
-		sp, err := sshprovider.NewSSHAgentProvider(configs)
-		if err != nil {
-			return err
-		}
+	sp, err := build.ParseSSHAndSocket(clicontext.StringSlice("ssh"), clicontext.StringSlice("socket"))
+	if err != nil {
+		return err
+	}
+	if sp != nil {
 		attachable = append(attachable, sp)
 	}
 
+	if hosts := clicontext.StringSlice("http-via-client"); len(hosts) > 0 {
+		attachable = append(attachable, httpfetchprovider.NewProvider(hosts))
+	}
 	if secrets := clicontext.StringSlice("secret"); len(secrets) > 0 {
 		secretProvider, err := build.ParseSecret(secrets)
 		if err != nil {
@@ -186,13 +272,23 @@ func buildAction(clicontext *cli.Context) error {
 		return err
 	}
 
+	targets, err := build.ParseTargets(clicontext.StringSlice("target"))
+	if err != nil {
+		return err
+	}
+
 	var exports []client.ExportEntry
 	if legacyExporter := clicontext.String("exporter"); legacyExporter != "" {
 		logrus.Warnf("--exporter <exporter> is deprecated. Please use --output type=<exporter>[,<opt>=<optval>] instead.")
 		if len(clicontext.StringSlice("output")) > 0 {
 			return errors.New("--exporter cannot be used with --output")
 		}
+		if len(targets) > 1 {
+			return errors.New("--exporter cannot be used with several targets")
+		}
 		exports, err = build.ParseLegacyExporter(clicontext.String("exporter"), clicontext.StringSlice("exporter-opt"))
+	} else if len(targets) > 1 {
+		exports, err = build.ParseTargetOutput(clicontext.StringSlice("output"), targets)
 	} else {
 		exports, err = build.ParseOutput(clicontext.StringSlice("output"))
 	}
@@ -222,16 +318,74 @@ func buildAction(clicontext *cli.Context) error {
 		AllowedEntitlements: allowed,
 	}
 
+	for _, opt := range clicontext.StringSlice("opt") {
+		if opt != build.StdinOpt {
+			continue
+		}
+		if clicontext.String("frontend") == "" {
+			return errors.Errorf("--opt %s requires --frontend as stdin is used for the LLB definition", build.StdinOpt)
+		}
+		if clicontext.Bool("debug-on-failure") {
+			return errors.Errorf("--opt %s can't be used with --debug-on-failure as stdin is used by the debug shell", build.StdinOpt)
+		}
+	}
+
 	solveOpt.FrontendAttrs, err = build.ParseOpt(clicontext.StringSlice("opt"), clicontext.StringSlice("frontend-opt"))
 	if err != nil {
 		return errors.Wrap(err, "invalid opt")
 	}
 
+	if len(targets) > 0 {
+		if clicontext.String("frontend") == "" {
+			return errors.New("--target requires --frontend")
+		}
+		if _, ok := solveOpt.FrontendAttrs["target"]; ok {
+			return errors.New("--target cannot be used with --opt target")
+		}
+		if len(targets) == 1 {
+			solveOpt.FrontendAttrs["target"] = targets[0]
+		}
+	}
+	if len(targets) > 1 {
+		if clicontext.Bool("debug-on-failure") {
+			return errors.New("--debug-on-failure cannot be used with several targets")
+		}
+		if clicontext.String("metadata-file") != "" {
+			return errors.New("--metadata-file cannot be used with several targets")
+		}
+	}
+
 	solveOpt.LocalDirs, err = build.ParseLocal(clicontext.StringSlice("local"))
 	if err != nil {
 		return errors.Wrap(err, "invalid local")
 	}
 
+	solveOpt.OCIStores, err = build.ParseOCILayout(clicontext.StringSlice("oci-layout"))
+	if err != nil {
+		return errors.Wrap(err, "invalid oci-layout")
+	}
+
+	solveOpt.ResourceLimits, err = build.ParseResourceLimits(clicontext.String("cpus"), clicontext.String("memory"), clicontext.Int64("pids-limit"))
+	if err != nil {
+		return errors.Wrap(err, "invalid resource limits")
+	}
+	solveOpt.BandwidthLimits, err = build.ParseBandwidthLimits(clicontext.String("context-bandwidth"), clicontext.String("push-bandwidth"), clicontext.String("pull-bandwidth"))
+	if err != nil {
+		return errors.Wrap(err, "invalid bandwidth limits")
+	}
+	solveOpt.DNS, err = build.ParseDNS(clicontext.StringSlice("dns"), clicontext.StringSlice("dns-search"), clicontext.StringSlice("dns-option"))
+	if err != nil {
+		return errors.Wrap(err, "invalid dns")
+	}
+	solveOpt.Weight = clicontext.Int("weight")
+	solveOpt.Userns = clicontext.String("userns")
+	solveOpt.Runtime = clicontext.String("runtime")
+
+	solveOpt.SourcePolicy, err = build.ParseSourcePolicy(clicontext.String("source-policy-file"))
+	if err != nil {
+		return err
+	}
+
 	var def *llb.Definition
 	if clicontext.String("frontend") == "" {
 		if fi, _ := os.Stdin.Stat(); (fi.Mode() & os.ModeCharDevice) != 0 {
@@ -250,12 +404,53 @@ func buildAction(clicontext *cli.Context) error {
 		}
 	}
 
+	dryRun := clicontext.Bool("dry-run")
+	if dryRun {
+		if def == nil {
+			return errors.New("--dry-run requires an LLB definition from stdin")
+		}
+		solveOpt.DryRun = true
+	}
+
+	if clicontext.Bool("detach") {
+		solveOpt.Ref = identity.NewID()
+		solveOpt.Detach = true
+		logrus.Infof("build ID: %s", solveOpt.Ref)
+	}
+
+	debug := clicontext.Bool("debug-on-failure")
+	progress := clicontext.String("progress")
+	if debug {
+		if def != nil {
+			return errors.New("--debug-on-failure requires --frontend as stdin is used by the debug shell")
+		}
+		// the debug shell takes over the terminal
+		if progress == "auto" || progress == "tty" {
+			progress = "plain"
+		}
+	}
+
 	// not using shared context to not disrupt display but let is finish reporting errors
-	pw, err := progresswriter.NewPrinter(context.TODO(), os.Stderr, clicontext.String("progress"))
+	pw, err := progresswriter.NewPrinter(context.TODO(), os.Stderr, progress)
 	if err != nil {
 		return err
 	}
 
+	if clicontext.GlobalBool("debug") {
+		missCh := make(chan *client.SolveStatus)
+		pw = progresswriter.Tee(pw, missCh)
+		eg.Go(func() error {
+			for s := range missCh {
+				for _, v := range s.Vertexes {
+					if v.CacheMiss != nil && v.Completed == nil {
+						logrus.Debugf("cache miss for %q: %s", v.Name, v.CacheMiss.Message)
+					}
+				}
+			}
+			return nil
+		})
+	}
+
 	if traceEnc != nil {
 		traceCh := make(chan *client.SolveStatus)
 		pw = progresswriter.Tee(pw, traceCh)
@@ -283,19 +478,35 @@ func buildAction(clicontext *cli.Context) error {
 		}
 	}
 
+	var cacheStatus []*client.VertexCacheStatus
 	eg.Go(func() error {
 		defer func() {
 			for _, w := range writers {
 				close(w.Status())
 			}
 		}()
-		resp, err := c.Solve(ctx, def, solveOpt, progresswriter.ResetTime(mw.WithPrefix("", false)).Status())
+		var (
+			resp *client.SolveResponse
+			err  error
+		)
+		if len(targets) > 1 {
+			resp, err = solveTargets(ctx, c, solveOpt, targets, progresswriter.ResetTime(mw.WithPrefix("", false)).Status())
+		} else if debug {
+			shell := clicontext.StringSlice("debug-shell")
+			if len(shell) == 0 {
+				shell = []string{"/bin/sh"}
+			}
+			resp, err = debugSolve(ctx, c, solveOpt, shell, progresswriter.ResetTime(mw.WithPrefix("", false)).Status())
+		} else {
+			resp, err = c.Solve(ctx, def, solveOpt, progresswriter.ResetTime(mw.WithPrefix("", false)).Status())
+		}
 		if err != nil {
 			return err
 		}
 		for k, v := range resp.ExporterResponse {
 			logrus.Debugf("exporter response: %s=%s", k, v)
 		}
+		cacheStatus = resp.CacheStatus
 
 		metadataFile := clicontext.String("metadata-file")
 		if metadataFile != "" && resp.ExporterResponse != nil {
@@ -312,7 +523,29 @@ func buildAction(clicontext *cli.Context) error {
 		return pw.Err()
 	})
 
-	return eg.Wait()
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+	if dryRun {
+		// printed after the progress output has finished
+		printCacheStatus(cacheStatus)
+	}
+	return nil
+}
+
+func printCacheStatus(status []*client.VertexCacheStatus) {
+	tw := tabwriter.NewWriter(os.Stdout, 1, 8, 1, '\t', 0)
+	fmt.Fprintln(tw, "CACHED\tDIGEST\tNAME")
+	for _, s := range status {
+		cached := "false"
+		if s.Cached {
+			cached = "true"
+		} else if s.Uncertain {
+			cached = "unknown"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", cached, s.Digest, s.Name)
+	}
+	tw.Flush()
 }
 
 func writeMetadataFile(filename string, exporterResponse map[string]string) error {
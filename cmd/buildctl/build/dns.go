@@ -0,0 +1,26 @@
+package build
+
+import (
+	"net"
+
+	"github.com/moby/buildkit/client"
+	"github.com/pkg/errors"
+)
+
+// ParseDNS parses the DNS config of a build. The nameservers must be IP
+// addresses. nil is returned if nothing is set.
+func ParseDNS(nameservers, searchDomains, options []string) (*client.DNSConfig, error) {
+	if len(nameservers) == 0 && len(searchDomains) == 0 && len(options) == 0 {
+		return nil, nil
+	}
+	for _, ns := range nameservers {
+		if net.ParseIP(ns) == nil {
+			return nil, errors.Errorf("invalid nameserver %q", ns)
+		}
+	}
+	return &client.DNSConfig{
+		Nameservers:   nameservers,
+		SearchDomains: searchDomains,
+		Options:       options,
+	}, nil
+}
@@ -0,0 +1,25 @@
+package build
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDNS(t *testing.T) {
+	dns, err := ParseDNS(nil, nil, nil)
+	require.NoError(t, err)
+	require.Nil(t, dns)
+
+	dns, err = ParseDNS([]string{"10.0.0.53", "fd00::53"}, []string{"corp.example.com"}, []string{"ndots:2"})
+	require.NoError(t, err)
+	require.Equal(t, &client.DNSConfig{
+		Nameservers:   []string{"10.0.0.53", "fd00::53"},
+		SearchDomains: []string{"corp.example.com"},
+		Options:       []string{"ndots:2"},
+	}, dns)
+
+	_, err = ParseDNS([]string{"dns.example.com"}, nil, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,24 @@
+package build
+
+import (
+	"github.com/containerd/containerd/content"
+	sessioncontent "github.com/moby/buildkit/session/content"
+	"github.com/pkg/errors"
+)
+
+// ParseOCILayout parses --oci-layout
+func ParseOCILayout(layouts []string) (map[string]content.Store, error) {
+	dirs, err := attrMap(layouts)
+	if err != nil {
+		return nil, err
+	}
+	stores := make(map[string]content.Store, len(dirs))
+	for name, dir := range dirs {
+		cs, err := sessioncontent.NewOCILayoutStore(dir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to open OCI layout %s", dir)
+		}
+		stores[name] = cs
+	}
+	return stores, nil
+}
@@ -1,10 +1,27 @@
 package build
 
 import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
+// StdinOpt is the --opt value reading options from stdin.
+const StdinOpt = "@-"
+
+// ParseOpt parses the frontend options. An option of the form @<file> reads
+// a JSON object of options from the file, or from stdin for @-. An option
+// value of the form @<file> is replaced with the contents of the file. Values
+// starting with @@ are unescaped to a literal @ instead.
 func ParseOpt(opts, legacyFrontendOpts []string) (map[string]string, error) {
+	return parseOpt(opts, legacyFrontendOpts, os.Stdin)
+}
+
+func parseOpt(opts, legacyFrontendOpts []string, stdin io.Reader) (map[string]string, error) {
 	m := make(map[string]string)
 	if len(legacyFrontendOpts) > 0 {
 		logrus.Warn("--frontend-opt <opt>=<optval> is deprecated. Please use --opt <opt>=<optval> instead.")
@@ -16,12 +33,60 @@ func ParseOpt(opts, legacyFrontendOpts []string) (map[string]string, error) {
 			m[k] = v
 		}
 	}
-	modern, err := attrMap(opts)
+	for _, opt := range opts {
+		if strings.HasPrefix(opt, "@") {
+			bulk, err := readOptFile(strings.TrimPrefix(opt, "@"), stdin)
+			if err != nil {
+				return nil, err
+			}
+			for k, v := range bulk {
+				m[k] = v
+			}
+			continue
+		}
+		parts := strings.SplitN(opt, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("invalid value %s", opt)
+		}
+		v, err := optValue(parts[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read value of %s", parts[0])
+		}
+		m[parts[0]] = v
+	}
+	return m, nil
+}
+
+// readOptFile reads a JSON object of options from the file, or from stdin if
+// the name is "-".
+func readOptFile(name string, stdin io.Reader) (map[string]string, error) {
+	var dt []byte
+	var err error
+	if name == "-" {
+		dt, err = io.ReadAll(stdin)
+	} else {
+		dt, err = os.ReadFile(name)
+	}
 	if err != nil {
-		return nil, err
+		return nil, errors.Wrap(err, "failed to read options")
 	}
-	for k, v := range modern {
-		m[k] = v
+	var m map[string]string
+	if err := json.Unmarshal(dt, &m); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse options from %s, expected a JSON object of strings", name)
 	}
 	return m, nil
 }
+
+func optValue(v string) (string, error) {
+	if strings.HasPrefix(v, "@@") {
+		return v[1:], nil
+	}
+	if !strings.HasPrefix(v, "@") {
+		return v, nil
+	}
+	dt, err := os.ReadFile(v[1:])
+	if err != nil {
+		return "", err
+	}
+	return string(dt), nil
+}
@@ -0,0 +1,46 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOpt(t *testing.T) {
+	dir := t.TempDir()
+	valueFile := filepath.Join(dir, "value")
+	require.NoError(t, os.WriteFile(valueFile, []byte("from-file"), 0600))
+	jsonFile := filepath.Join(dir, "opts.json")
+	require.NoError(t, os.WriteFile(jsonFile, []byte(`{"build-arg:A":"json","build-arg:B":"json"}`), 0600))
+
+	m, err := parseOpt([]string{
+		"target=foo",
+		"build-arg:FILE=@" + valueFile,
+		"build-arg:AT=@@literal",
+		"@" + jsonFile,
+		"build-arg:B=override",
+		"@-",
+	}, nil, strings.NewReader(`{"build-arg:STDIN":"stdin"}`))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"target":          "foo",
+		"build-arg:FILE":  "from-file",
+		"build-arg:AT":    "@literal",
+		"build-arg:A":     "json",
+		"build-arg:B":     "override",
+		"build-arg:STDIN": "stdin",
+	}, m)
+
+	_, err = parseOpt([]string{"build-arg:FILE=@" + filepath.Join(dir, "missing")}, nil, nil)
+	require.Error(t, err)
+
+	_, err = parseOpt([]string{"@-"}, nil, strings.NewReader(`["foo"]`))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "JSON object")
+
+	_, err = parseOpt([]string{"foo"}, nil, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,67 @@
+package build
+
+import (
+	"strconv"
+
+	units "github.com/docker/go-units"
+	"github.com/moby/buildkit/client"
+	"github.com/pkg/errors"
+)
+
+// ParseResourceLimits parses the resource limits of a build. cpus is a
+// number of CPUs, e.g. "1.5", and memory a size like "512m". Empty values
+// are not set. nil is returned if no limit is set.
+func ParseResourceLimits(cpus, memory string, pids int64) (*client.ResourceLimits, error) {
+	var limits client.ResourceLimits
+	if cpus != "" {
+		v, err := strconv.ParseFloat(cpus, 64)
+		if err != nil || v <= 0 {
+			return nil, errors.Errorf("invalid cpus %q", cpus)
+		}
+		limits.CPU = v
+	}
+	if memory != "" {
+		v, err := units.RAMInBytes(memory)
+		if err != nil || v <= 0 {
+			return nil, errors.Errorf("invalid memory %q", memory)
+		}
+		limits.Memory = v
+	}
+	if pids < 0 {
+		return nil, errors.Errorf("invalid pids limit %d", pids)
+	}
+	limits.Pids = pids
+	if limits == (client.ResourceLimits{}) {
+		return nil, nil
+	}
+	return &limits, nil
+}
+
+// ParseBandwidthLimits parses the bandwidth limits of a build, sizes per
+// second like "10m". Empty values are not set. nil is returned if no limit
+// is set.
+func ParseBandwidthLimits(context, push, pull string) (*client.BandwidthLimits, error) {
+	var limits client.BandwidthLimits
+	for _, l := range []struct {
+		name  string
+		value string
+		dest  *int64
+	}{
+		{"context", context, &limits.Context},
+		{"push", push, &limits.Push},
+		{"pull", pull, &limits.Pull},
+	} {
+		if l.value == "" {
+			continue
+		}
+		v, err := units.RAMInBytes(l.value)
+		if err != nil || v <= 0 {
+			return nil, errors.Errorf("invalid %s bandwidth %q", l.name, l.value)
+		}
+		*l.dest = v
+	}
+	if limits == (client.BandwidthLimits{}) {
+		return nil, nil
+	}
+	return &limits, nil
+}
@@ -0,0 +1,43 @@
+package build
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResourceLimits(t *testing.T) {
+	limits, err := ParseResourceLimits("", "", 0)
+	require.NoError(t, err)
+	require.Nil(t, limits)
+
+	limits, err = ParseResourceLimits("1.5", "512m", 100)
+	require.NoError(t, err)
+	require.Equal(t, &client.ResourceLimits{CPU: 1.5, Memory: 512 * 1024 * 1024, Pids: 100}, limits)
+
+	_, err = ParseResourceLimits("two", "", 0)
+	require.Error(t, err)
+
+	_, err = ParseResourceLimits("", "-1", 0)
+	require.Error(t, err)
+
+	_, err = ParseResourceLimits("", "", -1)
+	require.Error(t, err)
+}
+
+func TestParseBandwidthLimits(t *testing.T) {
+	limits, err := ParseBandwidthLimits("", "", "")
+	require.NoError(t, err)
+	require.Nil(t, limits)
+
+	limits, err = ParseBandwidthLimits("1m", "", "512k")
+	require.NoError(t, err)
+	require.Equal(t, &client.BandwidthLimits{Context: 1024 * 1024, Pull: 512 * 1024}, limits)
+
+	_, err = ParseBandwidthLimits("", "fast", "")
+	require.Error(t, err)
+
+	_, err = ParseBandwidthLimits("", "", "0")
+	require.Error(t, err)
+}
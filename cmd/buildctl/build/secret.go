@@ -3,6 +3,7 @@ package build
 import (
 	"encoding/csv"
 	"strings"
+	"time"
 
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/secrets/secretsprovider"
@@ -47,7 +48,9 @@ func parseSecret(value string) (*secretsprovider.Source, error) {
 		value := parts[1]
 		switch key {
 		case "type":
-			if value != "file" && value != "env" {
+			switch value {
+			case "file", "env", "vault", "aws", "template":
+			default:
 				return nil, errors.Errorf("unsupported secret type %q", value)
 			}
 			typ = value
@@ -57,12 +60,34 @@ func parseSecret(value string) (*secretsprovider.Source, error) {
 			fs.FilePath = value
 		case "env":
 			fs.Env = value
+		case "ttl":
+			ttl, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid ttl %q", value)
+			}
+			fs.TTL = ttl
 		default:
 			return nil, errors.Errorf("unexpected key '%s' in '%s'", key, field)
 		}
 	}
-	if typ == "env" && fs.Env == "" {
-		fs.Env = fs.FilePath
+	switch typ {
+	case "env":
+		if fs.Env == "" {
+			fs.Env = fs.FilePath
+			fs.FilePath = ""
+		}
+	case "vault", "aws", "template":
+		if fs.FilePath == "" {
+			return nil, errors.Errorf("secret type %s requires a source", typ)
+		}
+		switch typ {
+		case "vault":
+			fs.Vault = fs.FilePath
+		case "aws":
+			fs.AWSSecretID = fs.FilePath
+		case "template":
+			fs.Template = fs.FilePath
+		}
 		fs.FilePath = ""
 	}
 	return &fs, nil
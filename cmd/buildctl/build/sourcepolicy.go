@@ -0,0 +1,30 @@
+package build
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/moby/buildkit/sourcepolicy"
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
+	"github.com/pkg/errors"
+)
+
+// ParseSourcePolicy reads a source policy from a JSON file, e.g.
+// {"rules": [{"action": "CONVERT", "selector": {"identifier": "docker-image://docker.io/*"}, "updates": {"identifier": "docker-image://mirror.example.com/${1}"}}]}
+func ParseSourcePolicy(filename string) (*spb.Policy, error) {
+	if filename == "" {
+		return nil, nil
+	}
+	dt, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read source policy")
+	}
+	var pol spb.Policy
+	if err := json.Unmarshal(dt, &pol); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse source policy %s", filename)
+	}
+	if _, err := sourcepolicy.NewEngine([]*spb.Policy{&pol}); err != nil {
+		return nil, err
+	}
+	return &pol, nil
+}
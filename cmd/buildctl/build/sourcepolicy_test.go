@@ -0,0 +1,35 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSourcePolicy(t *testing.T) {
+	dir := t.TempDir()
+	fn := filepath.Join(dir, "policy.json")
+	require.NoError(t, os.WriteFile(fn, []byte(`{"rules": [
+	{"action": "CONVERT", "selector": {"identifier": "docker-image://docker.io/*"}, "updates": {"identifier": "docker-image://mirror.example.com/${1}"}},
+	{"action": "deny", "selector": {"identifier": "^git://.*$", "match_type": "REGEX"}}
+]}`), 0600))
+
+	pol, err := ParseSourcePolicy(fn)
+	require.NoError(t, err)
+	require.Len(t, pol.Rules, 2)
+	require.Equal(t, spb.PolicyAction_CONVERT, pol.Rules[0].Action)
+	require.Equal(t, "docker-image://mirror.example.com/${1}", pol.Rules[0].Updates.Identifier)
+	require.Equal(t, spb.PolicyAction_DENY, pol.Rules[1].Action)
+	require.Equal(t, spb.MatchType_REGEX, pol.Rules[1].Selector.MatchType)
+
+	pol, err = ParseSourcePolicy("")
+	require.NoError(t, err)
+	require.Nil(t, pol)
+
+	require.NoError(t, os.WriteFile(fn, []byte(`{"rules": [{"action": "block"}]}`), 0600))
+	_, err = ParseSourcePolicy(fn)
+	require.Error(t, err)
+}
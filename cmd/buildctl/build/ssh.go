@@ -3,7 +3,9 @@ package build
 import (
 	"strings"
 
+	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/sshforward/sshprovider"
+	"github.com/pkg/errors"
 )
 
 // ParseSSH parses --ssh
@@ -21,3 +23,36 @@ func ParseSSH(inp []string) ([]sshprovider.AgentConfig, error) {
 	}
 	return configs, nil
 }
+
+// ParseSocket parses --socket
+func ParseSocket(inp []string) ([]sshprovider.SocketConfig, error) {
+	configs := make([]sshprovider.SocketConfig, 0, len(inp))
+	for _, v := range inp {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.Errorf("invalid socket %q, expected <id>=<path>", v)
+		}
+		configs = append(configs, sshprovider.SocketConfig{
+			ID:   parts[0],
+			Path: parts[1],
+		})
+	}
+	return configs, nil
+}
+
+// ParseSSHAndSocket parses --ssh and --socket into a single session provider.
+// It returns nil if neither is set.
+func ParseSSHAndSocket(ssh, sockets []string) (session.Attachable, error) {
+	if len(ssh) == 0 && len(sockets) == 0 {
+		return nil, nil
+	}
+	sshConfigs, err := ParseSSH(ssh)
+	if err != nil {
+		return nil, err
+	}
+	socketConfigs, err := ParseSocket(sockets)
+	if err != nil {
+		return nil, err
+	}
+	return sshprovider.NewProvider(sshConfigs, socketConfigs)
+}
@@ -0,0 +1,54 @@
+package build
+
+import (
+	"strings"
+
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	"github.com/pkg/errors"
+)
+
+// TargetPlaceholder is replaced by the name of the target in the --output
+// of a build of several targets.
+const TargetPlaceholder = "{target}"
+
+// ParseTargets parses --target. The targets must be unique.
+func ParseTargets(targets []string) ([]string, error) {
+	seen := map[string]struct{}{}
+	for _, t := range targets {
+		if t == "" {
+			return nil, errors.New("target name can't be empty")
+		}
+		if _, ok := seen[t]; ok {
+			return nil, errors.Errorf("duplicate target %s", t)
+		}
+		seen[t] = struct{}{}
+	}
+	return targets, nil
+}
+
+// ParseTargetOutput parses --output of a build of several targets. Every
+// output is exported for each target, from the named build output of the
+// target and with TargetPlaceholder replaced by the name of the target. The
+// outputs must use the placeholder so that the targets are not exported to
+// the same destination.
+func ParseTargetOutput(exports []string, targets []string) ([]client.ExportEntry, error) {
+	var entries []client.ExportEntry
+	for _, s := range exports {
+		if !strings.Contains(s, TargetPlaceholder) {
+			return nil, errors.Errorf("output %s is shared by the targets, use %s in its destination or name", s, TargetPlaceholder)
+		}
+		for _, t := range targets {
+			e, err := parseOutputCSV(strings.ReplaceAll(s, TargetPlaceholder, t))
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid output for target %s", t)
+			}
+			if _, ok := e.Attrs[exptypes.OutputAttr]; ok {
+				return nil, errors.Errorf("%s can't be set on the outputs of several targets", exptypes.OutputAttr)
+			}
+			e.Attrs[exptypes.OutputAttr] = t
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,42 @@
+package build
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/moby/buildkit/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTargets(t *testing.T) {
+	targets, err := ParseTargets([]string{"a", "b"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, targets)
+
+	_, err = ParseTargets([]string{"a", "a"})
+	require.Error(t, err)
+	_, err = ParseTargets([]string{""})
+	require.Error(t, err)
+}
+
+func TestParseTargetOutput(t *testing.T) {
+	dir := t.TempDir()
+	entries, err := ParseTargetOutput([]string{
+		"type=local,dest=" + filepath.Join(dir, "{target}"),
+		"type=image,name=docker.io/foo/bar:{target},push=true",
+	}, []string{"a", "b"})
+	require.NoError(t, err)
+	require.Equal(t, []client.ExportEntry{
+		{Type: client.ExporterLocal, Attrs: map[string]string{"output": "a"}, OutputDir: filepath.Join(dir, "a")},
+		{Type: client.ExporterLocal, Attrs: map[string]string{"output": "b"}, OutputDir: filepath.Join(dir, "b")},
+		{Type: client.ExporterImage, Attrs: map[string]string{"name": "docker.io/foo/bar:a", "push": "true", "output": "a"}},
+		{Type: client.ExporterImage, Attrs: map[string]string{"name": "docker.io/foo/bar:b", "push": "true", "output": "b"}},
+	}, entries)
+
+	_, err = ParseTargetOutput([]string{"type=local,dest=" + dir}, []string{"a", "b"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "shared by the targets")
+
+	_, err = ParseTargetOutput([]string{"type=local,dest=" + filepath.Join(dir, "{target}") + ",output=bin"}, []string{"a", "b"})
+	require.Error(t, err)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/frontend/bake"
+	gateway "github.com/moby/buildkit/frontend/gateway/client"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// buildTargets returns a build function building the targets with the
+// frontend in parallel. The result has a named build output for each target,
+// exported with the output attribute of the exporters.
+func buildTargets(frontend string, frontendAttrs map[string]string, targets []string) gateway.BuildFunc {
+	return func(ctx context.Context, c gateway.Client) (*gateway.Result, error) {
+		results := make([]*gateway.Result, len(targets))
+		eg, ctx := errgroup.WithContext(ctx)
+		for i, target := range targets {
+			i, target := i, target
+			attrs := make(map[string]string, len(frontendAttrs)+1)
+			for k, v := range frontendAttrs {
+				attrs[k] = v
+			}
+			attrs["target"] = target
+			eg.Go(func() error {
+				res, err := c.Solve(ctx, gateway.SolveRequest{
+					Frontend:    frontend,
+					FrontendOpt: attrs,
+				})
+				if err != nil {
+					return errors.Wrapf(err, "failed to build target %s", target)
+				}
+				results[i] = res
+				return nil
+			})
+		}
+		if err := eg.Wait(); err != nil {
+			return nil, err
+		}
+		bt := make([]*bake.Target, len(targets))
+		for i, target := range targets {
+			bt[i] = &bake.Target{Name: target}
+		}
+		return bake.NewResult(bt, results)
+	}
+}
+
+// solveTargets builds the targets of the frontend in a single build, sharing
+// the session, the transfers of the local sources and the cache.
+func solveTargets(ctx context.Context, c *client.Client, solveOpt client.SolveOpt, targets []string, statusChan chan *client.SolveStatus) (*client.SolveResponse, error) {
+	frontend := solveOpt.Frontend
+	frontendAttrs := solveOpt.FrontendAttrs
+	solveOpt.Frontend = ""
+	solveOpt.FrontendAttrs = nil
+	return c.Build(ctx, solveOpt, "buildctl", buildTargets(frontend, frontendAttrs, targets), statusChan)
+}
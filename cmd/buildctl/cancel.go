@@ -0,0 +1,37 @@
+package main
+
+import (
+	bccommon "github.com/moby/buildkit/cmd/buildctl/common"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+var cancelCommand = cli.Command{
+	Name:      "cancel",
+	Usage:     "cancel a running build",
+	ArgsUsage: "BUILD_ID",
+	Action:    cancelAction,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "reason",
+			Usage: "Reason of the cancellation, reported in the progress and the error of the build",
+		},
+		cli.DurationFlag{
+			Name:  "grace-period",
+			Usage: "Time the running steps get to stop after SIGTERM and the running exporters get to finish before the build is canceled",
+		},
+	},
+}
+
+func cancelAction(clicontext *cli.Context) error {
+	ref := clicontext.Args().First()
+	if ref == "" {
+		return errors.New("build ID must be specified")
+	}
+
+	c, err := bccommon.ResolveClient(clicontext)
+	if err != nil {
+		return err
+	}
+	return c.Cancel(bccommon.CommandContext(clicontext), ref, clicontext.String("reason"), clicontext.Duration("grace-period"))
+}
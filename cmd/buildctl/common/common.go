@@ -81,6 +81,9 @@ func ResolveClient(c *cli.Context) (*client.Client, error) {
 	if caCert != "" || cert != "" || key != "" {
 		opts = append(opts, client.WithCredentials(serverName, caCert, cert, key))
 	}
+	if token := c.GlobalString("token"); token != "" {
+		opts = append(opts, client.WithToken(token))
+	}
 
 	timeout := time.Duration(c.GlobalInt("timeout"))
 	ctx, cancel := context.WithTimeout(ctx, timeout*time.Second)
@@ -12,5 +12,10 @@ var debugCommand = cli.Command{
 		debug.DumpLLBCommand,
 		debug.DumpMetadataCommand,
 		debug.WorkersCommand,
+		debug.EmulatorsCommand,
+		debug.ParallelismCommand,
+		debug.CacheKeysCommand,
+		debug.ReloadConfigCommand,
+		debug.RootlessInfoCommand,
 	},
 }
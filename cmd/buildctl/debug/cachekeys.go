@@ -0,0 +1,149 @@
+package debug
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/cmd/buildctl/build"
+	bccommon "github.com/moby/buildkit/cmd/buildctl/common"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/auth/authprovider"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+var CacheKeysCommand = cli.Command{
+	Name:      "cachekeys",
+	Usage:     "show the cache keys of the vertexes of an LLB definition and whether they are cached, without building it. LLB can be also passed via stdin.",
+	ArgsUsage: "<llbfile>",
+	Action:    cacheKeys,
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "import-cache",
+			Usage: "Look up the keys in the imported build cache, e.g. --import-cache type=registry,ref=example.com/foo/bar",
+		},
+		cli.BoolFlag{
+			Name:  "verbose, v",
+			Usage: "Verbose output",
+		},
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "Format the output using the given Go template, e.g, '{{json .}}'",
+		},
+	},
+}
+
+func cacheKeys(clicontext *cli.Context) error {
+	var r io.Reader
+	if llbFile := clicontext.Args().First(); llbFile != "" && llbFile != "-" {
+		f, err := os.Open(llbFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	} else {
+		r = os.Stdin
+	}
+	def, err := llb.ReadFrom(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse input")
+	}
+
+	cacheImports, err := build.ParseImportCache(clicontext.StringSlice("import-cache"))
+	if err != nil {
+		return err
+	}
+
+	c, err := bccommon.ResolveClient(clicontext)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Solve(commandContext(clicontext), def, client.SolveOpt{
+		CacheImports: cacheImports,
+		Session:      []session.Attachable{authprovider.NewDockerAuthProvider(os.Stderr)},
+		DryRun:       true,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	if format := clicontext.String("format"); format != "" {
+		if clicontext.Bool("verbose") {
+			logrus.Debug("Ignoring --verbose")
+		}
+		tmpl, err := parseTemplate(format)
+		if err != nil {
+			return err
+		}
+		if err := tmpl.Execute(clicontext.App.Writer, resp.CacheStatus); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(clicontext.App.Writer, "\n")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 1, 8, 1, '\t', 0)
+	if clicontext.Bool("verbose") {
+		printCacheKeysVerbose(tw, resp.CacheStatus)
+	} else {
+		printCacheKeysTable(tw, resp.CacheStatus)
+	}
+	return nil
+}
+
+func printCacheKeysVerbose(tw *tabwriter.Writer, status []*client.VertexCacheStatus) {
+	for _, s := range status {
+		fmt.Fprintf(tw, "Name:\t%s\n", s.Name)
+		fmt.Fprintf(tw, "Digest:\t%s\n", s.Digest)
+		fmt.Fprintf(tw, "Key:\t%s\n", s.Key)
+		fmt.Fprintf(tw, "Cache:\t%s\n", cacheKeyStatus(s))
+		for _, dgst := range s.CacheMapDigests {
+			fmt.Fprintf(tw, "Cache Map Digest:\t%s\n", dgst)
+		}
+		for i, k := range s.Inputs {
+			key := k.String()
+			if k == "" {
+				key = "content based"
+			}
+			fmt.Fprintf(tw, "Input %d:\t%s\n", i, key)
+		}
+		fmt.Fprintf(tw, "\n")
+	}
+	tw.Flush()
+}
+
+func printCacheKeysTable(tw *tabwriter.Writer, status []*client.VertexCacheStatus) {
+	fmt.Fprintln(tw, "CACHE\tKEY\tNAME")
+	for _, s := range status {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", cacheKeyStatus(s), s.Key, s.Name)
+	}
+	tw.Flush()
+}
+
+func cacheKeyStatus(s *client.VertexCacheStatus) string {
+	if !s.Cached {
+		if s.Uncertain {
+			return "unknown"
+		}
+		return "miss"
+	}
+	var where []string
+	if s.Local {
+		where = append(where, "local")
+	}
+	if s.Remote {
+		where = append(where, "remote")
+	}
+	if len(where) == 0 {
+		return "hit"
+	}
+	return strings.Join(where, ",")
+}
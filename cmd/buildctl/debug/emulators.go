@@ -0,0 +1,53 @@
+package debug
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/containerd/containerd/platforms"
+	bccommon "github.com/moby/buildkit/cmd/buildctl/common"
+	"github.com/urfave/cli"
+)
+
+var EmulatorsCommand = cli.Command{
+	Name:   "emulators",
+	Usage:  "list the emulators of the platforms the daemon host can't run natively",
+	Action: listEmulators,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "Format the output using the given Go template, e.g, '{{json .}}'",
+		},
+	},
+}
+
+func listEmulators(clicontext *cli.Context) error {
+	c, err := bccommon.ResolveClient(clicontext)
+	if err != nil {
+		return err
+	}
+
+	emulators, err := c.ListEmulators(commandContext(clicontext))
+	if err != nil {
+		return err
+	}
+	if format := clicontext.String("format"); format != "" {
+		tmpl, err := parseTemplate(format)
+		if err != nil {
+			return err
+		}
+		if err := tmpl.Execute(clicontext.App.Writer, emulators); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(clicontext.App.Writer, "\n")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 1, 8, 1, '\t', 0)
+	fmt.Fprintln(tw, "PLATFORM\tTYPE\tPATH")
+	for _, e := range emulators {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", platforms.Format(e.Platform), e.Type, e.Path)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,83 @@
+package debug
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	bccommon "github.com/moby/buildkit/cmd/buildctl/common"
+	"github.com/urfave/cli"
+)
+
+var ParallelismCommand = cli.Command{
+	Name:   "parallelism",
+	Usage:  "show or change the number of operations the daemon runs at a time",
+	Action: parallelism,
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "max",
+			Usage: "Limit of all operations, 0 is unlimited",
+		},
+		cli.IntFlag{
+			Name:  "network",
+			Usage: "Limit of image pulls, git fetches, http downloads and cache imports, 0 is unlimited",
+		},
+		cli.IntFlag{
+			Name:  "exec",
+			Usage: "Limit of exec operations, 0 is unlimited",
+		},
+		cli.IntFlag{
+			Name:  "speculative",
+			Usage: "Limit of image pulls and git fetches started before cache lookups complete, 0 disables them",
+		},
+	},
+}
+
+func parallelism(clicontext *cli.Context) error {
+	c, err := bccommon.ResolveClient(clicontext)
+	if err != nil {
+		return err
+	}
+	ctx := commandContext(clicontext)
+
+	p, err := c.Parallelism(ctx)
+	if err != nil {
+		return err
+	}
+	if clicontext.IsSet("max") || clicontext.IsSet("network") || clicontext.IsSet("exec") || clicontext.IsSet("speculative") {
+		if clicontext.IsSet("max") {
+			p.Max = clicontext.Int("max")
+		}
+		if clicontext.IsSet("network") {
+			p.Network = clicontext.Int("network")
+		}
+		if clicontext.IsSet("exec") {
+			p.Exec = clicontext.Int("exec")
+		}
+		if clicontext.IsSet("speculative") {
+			p.Speculative = clicontext.Int("speculative")
+		}
+		p, err = c.SetParallelism(ctx, *p)
+		if err != nil {
+			return err
+		}
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 1, 8, 1, '\t', 0)
+	fmt.Fprintf(tw, "Max:\t%s\n", limitString(p.Max))
+	fmt.Fprintf(tw, "Network:\t%s\n", limitString(p.Network))
+	fmt.Fprintf(tw, "Exec:\t%s\n", limitString(p.Exec))
+	speculative := "disabled"
+	if p.Speculative > 0 {
+		speculative = fmt.Sprintf("%d", p.Speculative)
+	}
+	fmt.Fprintf(tw, "Speculative:\t%s\n", speculative)
+	return tw.Flush()
+}
+
+func limitString(n int) string {
+	if n <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d", n)
+}
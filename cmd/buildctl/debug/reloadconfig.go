@@ -0,0 +1,30 @@
+package debug
+
+import (
+	"fmt"
+
+	bccommon "github.com/moby/buildkit/cmd/buildctl/common"
+	"github.com/urfave/cli"
+)
+
+var ReloadConfigCommand = cli.Command{
+	Name:   "reload-config",
+	Usage:  "reload the registry configuration of the daemon from buildkitd.toml",
+	Action: reloadConfig,
+}
+
+func reloadConfig(clicontext *cli.Context) error {
+	c, err := bccommon.ResolveClient(clicontext)
+	if err != nil {
+		return err
+	}
+
+	registries, err := c.ReloadConfig(commandContext(clicontext))
+	if err != nil {
+		return err
+	}
+	for _, r := range registries {
+		fmt.Fprintln(clicontext.App.Writer, r)
+	}
+	return nil
+}
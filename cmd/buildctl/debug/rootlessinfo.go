@@ -0,0 +1,59 @@
+package debug
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	bccommon "github.com/moby/buildkit/cmd/buildctl/common"
+	"github.com/urfave/cli"
+)
+
+var RootlessInfoCommand = cli.Command{
+	Name:   "rootless-info",
+	Usage:  "report the features of the daemon host used in rootless mode and the limitations of the missing ones",
+	Action: rootlessInfo,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "Format the output using the given Go template, e.g, '{{json .}}'",
+		},
+	},
+}
+
+func rootlessInfo(clicontext *cli.Context) error {
+	c, err := bccommon.ResolveClient(clicontext)
+	if err != nil {
+		return err
+	}
+
+	info, err := c.RootlessInfo(commandContext(clicontext))
+	if err != nil {
+		return err
+	}
+	if format := clicontext.String("format"); format != "" {
+		tmpl, err := parseTemplate(format)
+		if err != nil {
+			return err
+		}
+		if err := tmpl.Execute(clicontext.App.Writer, info); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(clicontext.App.Writer, "\n")
+		return err
+	}
+
+	if !info.Rootless {
+		fmt.Fprintln(clicontext.App.Writer, "the daemon is not running in rootless mode")
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 1, 8, 1, '\t', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tMESSAGE")
+	for _, c := range info.Checks {
+		status := "ok"
+		if !c.OK {
+			status = "limited"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Name, status, c.Message)
+	}
+	return tw.Flush()
+}
@@ -96,6 +96,15 @@ func printWorkersVerbose(tw *tabwriter.Writer, winfo []*client.WorkerInfo) {
 			if rule.KeepBytes > 0 {
 				fmt.Fprintf(tw, "\tKeep Bytes:\t%g\n", units.Bytes(rule.KeepBytes))
 			}
+			if rule.ReservedSpace > 0 {
+				fmt.Fprintf(tw, "\tReserved Space:\t%g\n", units.Bytes(rule.ReservedSpace))
+			}
+			if rule.MinFreeSpace > 0 {
+				fmt.Fprintf(tw, "\tMin Free Space:\t%g\n", units.Bytes(rule.MinFreeSpace))
+			}
+			if rule.Schedule != "" {
+				fmt.Fprintf(tw, "\tSchedule:\t%s\n", rule.Schedule)
+			}
 		}
 		fmt.Fprintf(tw, "\n")
 	}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/containerd/console"
+	"github.com/moby/buildkit/client"
+	gateway "github.com/moby/buildkit/frontend/gateway/client"
+	"github.com/moby/buildkit/solver/errdefs"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// debugOnFailure returns a build function solving the frontend request. When an exec operation fails, an interactive shell is
+// started in a container with the mounts of the failed operation before the
+// error is returned.
+func debugOnFailure(frontend string, frontendAttrs map[string]string, shell []string) gateway.BuildFunc {
+	return func(ctx context.Context, c gateway.Client) (*gateway.Result, error) {
+		res, err := c.Solve(ctx, gateway.SolveRequest{
+			Frontend:    frontend,
+			FrontendOpt: frontendAttrs,
+			Evaluate:    true,
+		})
+		if err != nil {
+			var se *errdefs.SolveError
+			if errors.As(err, &se) {
+				if err2 := debugShell(ctx, c, se, shell); err2 != nil {
+					logrus.Errorf("failed to start debug shell: %v", err2)
+				}
+			}
+			return nil, err
+		}
+		return res, nil
+	}
+}
+
+// debugShell runs the shell in the state of the failed exec operation of the
+// solve error, attached to the current console.
+func debugShell(ctx context.Context, c gateway.Client, se *errdefs.SolveError, shell []string) error {
+	creq, exec, err := gateway.NewContainerRequestFromSolveError(se)
+	if err != nil {
+		return err
+	}
+
+	con, err := console.ConsoleFromFile(os.Stdin)
+	if err != nil {
+		return errors.Wrap(err, "debug shell requires stdin to be a terminal")
+	}
+
+	ctr, err := c.NewContainer(ctx, creq)
+	if err != nil {
+		return err
+	}
+	defer ctr.Release(context.TODO())
+
+	if err := con.SetRaw(); err != nil {
+		return err
+	}
+	defer con.Reset()
+
+	proc, err := ctr.Start(ctx, gateway.StartRequest{
+		Args:         shell,
+		Env:          exec.Meta.Env,
+		User:         exec.Meta.User,
+		Cwd:          exec.Meta.Cwd,
+		Tty:          true,
+		Stdin:        io.NopCloser(con),
+		Stdout:       nopWriteCloser{os.Stdout},
+		Stderr:       nopWriteCloser{os.Stderr},
+		SecurityMode: exec.Security,
+	})
+	if err != nil {
+		return err
+	}
+	if size, err := con.Size(); err == nil {
+		proc.Resize(ctx, gateway.WinSize{
+			Rows: uint32(size.Height),
+			Cols: uint32(size.Width),
+		})
+	}
+	return proc.Wait()
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// debugSolve runs the frontend build through the gateway with
+// debugOnFailure.
+func debugSolve(ctx context.Context, c *client.Client, solveOpt client.SolveOpt, shell []string, statusChan chan *client.SolveStatus) (*client.SolveResponse, error) {
+	frontend := solveOpt.Frontend
+	frontendAttrs := solveOpt.FrontendAttrs
+	solveOpt.Frontend = ""
+	solveOpt.FrontendAttrs = nil
+	return c.Build(ctx, solveOpt, "buildctl", debugOnFailure(frontend, frontendAttrs, shell), statusChan)
+}
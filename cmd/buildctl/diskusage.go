@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 	"text/tabwriter"
 
@@ -17,7 +18,7 @@ var diskUsageCommand = cli.Command{
 	Name:   "du",
 	Usage:  "disk usage",
 	Action: diskUsage,
-	Flags: []cli.Flag{
+	Flags: append([]cli.Flag{
 		cli.StringSliceFlag{
 			Name:  "filter, f",
 			Usage: "Filter records",
@@ -26,7 +27,11 @@ var diskUsageCommand = cli.Command{
 			Name:  "verbose, v",
 			Usage: "Verbose output",
 		},
-	},
+		cli.BoolFlag{
+			Name:  "by-type",
+			Usage: "Print sizes grouped by record type",
+		},
+	}, usageFilterFlags...),
 }
 
 func diskUsage(clicontext *cli.Context) error {
@@ -35,20 +40,28 @@ func diskUsage(clicontext *cli.Context) error {
 		return err
 	}
 
-	du, err := c.DiskUsage(bccommon.CommandContext(clicontext), client.WithFilter(clicontext.StringSlice("filter")))
+	filters, err := usageFilters(clicontext)
+	if err != nil {
+		return err
+	}
+
+	du, err := c.DiskUsage(bccommon.CommandContext(clicontext), client.WithFilter(filters))
 	if err != nil {
 		return err
 	}
 
 	tw := tabwriter.NewWriter(os.Stdout, 1, 8, 1, '\t', 0)
 
-	if clicontext.Bool("verbose") {
+	switch {
+	case clicontext.Bool("by-type"):
+		printByType(tw, du)
+	case clicontext.Bool("verbose"):
 		printVerbose(tw, du)
-	} else {
+	default:
 		printTable(tw, du)
 	}
 
-	if len(clicontext.StringSlice("filter")) == 0 {
+	if len(filters) == 0 {
 		printSummary(tw, du)
 	}
 
@@ -113,6 +126,41 @@ func printTableRow(tw *tabwriter.Writer, di *client.UsageInfo) {
 	fmt.Fprintf(tw, "%-71s\t%-11v\t%s\t\n", id, !di.InUse, size)
 }
 
+func printByType(tw *tabwriter.Writer, du []*client.UsageInfo) {
+	type typeUsage struct {
+		count       int
+		size        int64
+		reclaimable int64
+	}
+	var types []client.UsageRecordType
+	byType := map[client.UsageRecordType]*typeUsage{}
+	for _, di := range du {
+		u, ok := byType[di.RecordType]
+		if !ok {
+			u = &typeUsage{}
+			byType[di.RecordType] = u
+			types = append(types, di.RecordType)
+		}
+		u.count++
+		if di.Size > 0 {
+			u.size += di.Size
+			if !di.InUse {
+				u.reclaimable += di.Size
+			}
+		}
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return byType[types[i]].size > byType[types[j]].size
+	})
+
+	fmt.Fprintln(tw, "TYPE	COUNT	RECLAIMABLE	SIZE")
+	for _, t := range types {
+		u := byType[t]
+		fmt.Fprintf(tw, "%s\t%d\t%.2f\t%.2f\n", t, u.count, units.Bytes(u.reclaimable), units.Bytes(u.size))
+	}
+	tw.Flush()
+}
+
 func printSummary(tw *tabwriter.Writer, du []*client.UsageInfo) {
 	total := int64(0)
 	reclaimable := int64(0)
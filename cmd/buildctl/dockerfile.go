@@ -0,0 +1,211 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/moby/buildkit/client/buildimage"
+	"github.com/moby/buildkit/cmd/buildctl/build"
+	bccommon "github.com/moby/buildkit/cmd/buildctl/common"
+	"github.com/moby/buildkit/session/httpfetch/httpfetchprovider"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+var dockerfileCommand = cli.Command{
+	Name:  "dockerfile",
+	Usage: "build Dockerfiles with docker build like flags",
+	Subcommands: []cli.Command{
+		dockerfileBuildCommand,
+	},
+}
+
+var dockerfileBuildCommand = cli.Command{
+	Name:      "build",
+	Usage:     "build an image from a Dockerfile",
+	UsageText: "buildctl dockerfile build [OPTIONS] PATH",
+	Action:    dockerfileBuildAction,
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "tag, t",
+			Usage: "Name of the image, e.g. -t docker.io/user/app:latest",
+		},
+		cli.StringFlag{
+			Name:  "file, f",
+			Usage: "Path of the Dockerfile (default: PATH/Dockerfile)",
+		},
+		cli.StringFlag{
+			Name:  "target",
+			Usage: "Stage of the Dockerfile to build",
+		},
+		cli.StringSliceFlag{
+			Name:  "build-arg",
+			Usage: "Set a build argument, e.g. --build-arg FOO=bar. The value of the environment variable is used if no value is given",
+		},
+		cli.StringSliceFlag{
+			Name:  "label",
+			Usage: "Set a label of the image, e.g. --label org.opencontainers.image.version=1.0",
+		},
+		cli.StringSliceFlag{
+			Name:  "platform",
+			Usage: "Platforms to build the image for, e.g. --platform linux/amd64,linux/arm64",
+		},
+		cli.BoolFlag{
+			Name:  "push",
+			Usage: "Push the image to the registry of the tags",
+		},
+		cli.BoolFlag{
+			Name:  "no-cache",
+			Usage: "Do not use the cache for any of the steps",
+		},
+		cli.StringSliceFlag{
+			Name:  "output, o",
+			Usage: "Define exports for build result instead of the image of the tags, e.g. --output type=local,dest=out",
+		},
+		cli.StringSliceFlag{
+			Name:  "cache-from",
+			Usage: "Import build cache, e.g. --cache-from type=registry,ref=example.com/foo/bar",
+		},
+		cli.StringSliceFlag{
+			Name:  "cache-to",
+			Usage: "Export build cache, e.g. --cache-to type=registry,ref=example.com/foo/bar",
+		},
+		cli.StringSliceFlag{
+			Name:  "secret",
+			Usage: "Secret value exposed to the build. Format id=secretname,src=filepath[,type=file|env|vault|aws|template][,ttl=duration]",
+		},
+		cli.StringSliceFlag{
+			Name:  "ssh",
+			Usage: "Allow forwarding SSH agent to the builder. Format default|<id>[=<socket>|<key>[,<key>]]",
+		},
+		cli.StringSliceFlag{
+			Name:  "socket",
+			Usage: "Allow forwarding a client socket to the socket mounts of the builder. Format <id>=<socket>",
+		},
+		cli.StringSliceFlag{
+			Name:  "http-via-client",
+			Usage: "Fetch the HTTP sources of the hosts matching the pattern with the network of the client, e.g. *.corp.example.com",
+		},
+		cli.StringSliceFlag{
+			Name:  "allow",
+			Usage: "Allow extra privileged entitlement, e.g. network.host, security.insecure",
+		},
+		cli.StringFlag{
+			Name:  "progress",
+			Usage: "Set type of progress (auto, plain, tty, json). Use plain to show container output",
+			Value: "auto",
+		},
+		cli.StringFlag{
+			Name:  "metadata-file",
+			Usage: "Output build metadata (e.g., image digest) to a file as JSON",
+		},
+	},
+}
+
+func dockerfileBuildAction(clicontext *cli.Context) error {
+	if clicontext.NArg() != 1 {
+		return errors.New("exactly one build context PATH is required")
+	}
+
+	opt, err := dockerfileBuildOpt(clicontext)
+	if err != nil {
+		return err
+	}
+
+	c, err := bccommon.ResolveClient(clicontext)
+	if err != nil {
+		return err
+	}
+
+	resp, err := buildimage.Build(bccommon.CommandContext(clicontext), c, *opt)
+	if err != nil {
+		return err
+	}
+	if metadataFile := clicontext.String("metadata-file"); metadataFile != "" && resp.ExporterResponse != nil {
+		return writeMetadataFile(metadataFile, resp.ExporterResponse)
+	}
+	return nil
+}
+
+func dockerfileBuildOpt(clicontext *cli.Context) (*buildimage.Opt, error) {
+	opt := &buildimage.Opt{
+		ContextDir:    clicontext.Args().First(),
+		Dockerfile:    clicontext.String("file"),
+		Target:        clicontext.String("target"),
+		Tags:          clicontext.StringSlice("tag"),
+		Push:          clicontext.Bool("push"),
+		BuildArgs:     map[string]string{},
+		FrontendAttrs: map[string]string{},
+		Progress:      clicontext.String("progress"),
+	}
+	if opt.Push && len(opt.Tags) == 0 {
+		return nil, errors.New("--push requires at least one --tag")
+	}
+
+	for _, arg := range clicontext.StringSlice("build-arg") {
+		k, v, ok := cut(arg, "=")
+		if !ok {
+			v, ok = os.LookupEnv(k)
+			if !ok {
+				continue
+			}
+		}
+		opt.BuildArgs[k] = v
+	}
+	for _, label := range clicontext.StringSlice("label") {
+		k, v, _ := cut(label, "=")
+		opt.FrontendAttrs["label:"+k] = v
+	}
+	if platforms := clicontext.StringSlice("platform"); len(platforms) > 0 {
+		opt.FrontendAttrs["platform"] = strings.Join(platforms, ",")
+	}
+	if clicontext.Bool("no-cache") {
+		opt.FrontendAttrs["no-cache"] = ""
+	}
+
+	var err error
+	if outputs := clicontext.StringSlice("output"); len(outputs) > 0 {
+		if opt.Push {
+			return nil, errors.New("--push cannot be used with --output")
+		}
+		if opt.Exports, err = build.ParseOutput(outputs); err != nil {
+			return nil, err
+		}
+	}
+	if opt.CacheImports, err = build.ParseImportCache(clicontext.StringSlice("cache-from")); err != nil {
+		return nil, err
+	}
+	if opt.CacheExports, err = build.ParseExportCache(clicontext.StringSlice("cache-to"), nil); err != nil {
+		return nil, err
+	}
+	if opt.AllowedEntitlements, err = build.ParseAllow(clicontext.StringSlice("allow")); err != nil {
+		return nil, err
+	}
+
+	sp, err := build.ParseSSHAndSocket(clicontext.StringSlice("ssh"), clicontext.StringSlice("socket"))
+	if err != nil {
+		return nil, err
+	}
+	if sp != nil {
+		opt.Session = append(opt.Session, sp)
+	}
+	if hosts := clicontext.StringSlice("http-via-client"); len(hosts) > 0 {
+		opt.Session = append(opt.Session, httpfetchprovider.NewProvider(hosts))
+	}
+	if secrets := clicontext.StringSlice("secret"); len(secrets) > 0 {
+		sp, err := build.ParseSecret(secrets)
+		if err != nil {
+			return nil, err
+		}
+		opt.Session = append(opt.Session, sp)
+	}
+	return opt, nil
+}
+
+// cut is strings.Cut, which requires go 1.18.
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/client/buildimage"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+func TestDockerfileBuildOpt(t *testing.T) {
+	t.Setenv("FROM_ENV", "env-value")
+
+	parse := func(args ...string) (opt *buildimage.Opt, err error) {
+		cmd := dockerfileBuildCommand
+		cmd.Action = func(clicontext *cli.Context) error {
+			opt, err = dockerfileBuildOpt(clicontext)
+			return nil
+		}
+		app := cli.NewApp()
+		app.Commands = []cli.Command{cmd}
+		require.NoError(t, app.Run(append([]string{"buildctl", "build"}, args...)))
+		return opt, err
+	}
+
+	opt, err := parse(
+		"-t", "example.com/app:latest",
+		"-f", "build/Dockerfile",
+		"--target", "release",
+		"--build-arg", "FOO=bar",
+		"--build-arg", "FROM_ENV",
+		"--build-arg", "UNSET",
+		"--label", "version=1.0",
+		"--platform", "linux/amd64",
+		"--platform", "linux/arm64",
+		"--no-cache",
+		"--push",
+		".",
+	)
+	require.NoError(t, err)
+	require.Equal(t, ".", opt.ContextDir)
+	require.Equal(t, "build/Dockerfile", opt.Dockerfile)
+	require.Equal(t, "release", opt.Target)
+	require.Equal(t, []string{"example.com/app:latest"}, opt.Tags)
+	require.True(t, opt.Push)
+	require.Equal(t, map[string]string{"FOO": "bar", "FROM_ENV": "env-value"}, opt.BuildArgs)
+	require.Equal(t, map[string]string{
+		"label:version": "1.0",
+		"platform":      "linux/amd64,linux/arm64",
+		"no-cache":      "",
+	}, opt.FrontendAttrs)
+
+	opt, err = parse("-o", "type=local,dest="+t.TempDir(), ".")
+	require.NoError(t, err)
+	require.Len(t, opt.Exports, 1)
+	require.Equal(t, client.ExporterLocal, opt.Exports[0].Type)
+
+	_, err = parse("--push", ".")
+	require.Error(t, err)
+}
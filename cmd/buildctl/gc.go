@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/moby/buildkit/client"
+	bccommon "github.com/moby/buildkit/cmd/buildctl/common"
+	"github.com/tonistiigi/units"
+	"github.com/urfave/cli"
+)
+
+var gcCommand = cli.Command{
+	Name:  "gc",
+	Usage: "run and inspect garbage collection of the build cache",
+	Subcommands: []cli.Command{
+		{
+			Name:   "run",
+			Usage:  "run the garbage collection policies of the workers",
+			Action: runGC,
+		},
+		{
+			Name:   "ls",
+			Usage:  "list recent garbage collection runs",
+			Action: listGCRuns,
+		},
+	},
+}
+
+func runGC(clicontext *cli.Context) error {
+	c, err := bccommon.ResolveClient(clicontext)
+	if err != nil {
+		return err
+	}
+
+	run, err := c.RunGC(bccommon.CommandContext(clicontext))
+	if err != nil {
+		return err
+	}
+	printGCRuns(os.Stdout, []*client.GCRun{run})
+	return nil
+}
+
+func listGCRuns(clicontext *cli.Context) error {
+	c, err := bccommon.ResolveClient(clicontext)
+	if err != nil {
+		return err
+	}
+
+	runs, err := c.GCRuns(bccommon.CommandContext(clicontext))
+	if err != nil {
+		return err
+	}
+	printGCRuns(os.Stdout, runs)
+	return nil
+}
+
+func printGCRuns(w io.Writer, runs []*client.GCRun) {
+	tw := tabwriter.NewWriter(w, 1, 8, 1, '\t', 0)
+	fmt.Fprintln(tw, "TRIGGER\tSTARTED\tDURATION\tRECORDS\tRECLAIMED\tERROR")
+	for _, r := range runs {
+		started, duration := "", ""
+		if r.StartedAt != nil {
+			started = r.StartedAt.Local().Format(time.RFC3339)
+			if r.CompletedAt != nil {
+				duration = r.CompletedAt.Sub(*r.StartedAt).Round(time.Millisecond).String()
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%.2f\t%s\n", r.Trigger, started, duration, r.NumRecords, units.Bytes(r.ReclaimedBytes), r.Error)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"github.com/moby/buildkit/cmd/buildctl/history"
+	"github.com/urfave/cli"
+)
+
+var historyCommand = cli.Command{
+	Name:  "history",
+	Usage: "inspect the history of completed builds",
+	Subcommands: []cli.Command{
+		history.ListCommand,
+		history.InspectCommand,
+		history.LogsCommand,
+		history.RemoveCommand,
+	},
+}
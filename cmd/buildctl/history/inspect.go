@@ -0,0 +1,41 @@
+package history
+
+import (
+	"encoding/json"
+	"os"
+
+	bccommon "github.com/moby/buildkit/cmd/buildctl/common"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+var InspectCommand = cli.Command{
+	Name:      "inspect",
+	Usage:     "print the record of a completed build as JSON",
+	ArgsUsage: "REF",
+	Action:    inspect,
+}
+
+func inspect(clicontext *cli.Context) error {
+	ref := clicontext.Args().First()
+	if ref == "" {
+		return errors.New("build ref must be specified")
+	}
+
+	c, err := bccommon.ResolveClient(clicontext)
+	if err != nil {
+		return err
+	}
+
+	recs, err := c.BuildHistory(bccommon.CommandContext(clicontext), ref)
+	if err != nil {
+		return err
+	}
+	if len(recs) == 0 {
+		return errors.Errorf("no build history for %s", ref)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(recs[0])
+}
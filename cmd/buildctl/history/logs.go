@@ -0,0 +1,53 @@
+package history
+
+import (
+	"context"
+	"os"
+
+	bccommon "github.com/moby/buildkit/cmd/buildctl/common"
+	"github.com/moby/buildkit/util/progress/progresswriter"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+	"golang.org/x/sync/errgroup"
+)
+
+var LogsCommand = cli.Command{
+	Name:      "logs",
+	Usage:     "print the progress of a completed build",
+	ArgsUsage: "REF",
+	Action:    logs,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "progress",
+			Usage: "Set type of progress (plain, tty, json)",
+			Value: "plain",
+		},
+	},
+}
+
+func logs(clicontext *cli.Context) error {
+	ref := clicontext.Args().First()
+	if ref == "" {
+		return errors.New("build ref must be specified")
+	}
+
+	c, err := bccommon.ResolveClient(clicontext)
+	if err != nil {
+		return err
+	}
+
+	pw, err := progresswriter.NewPrinter(context.TODO(), os.Stdout, clicontext.String("progress"))
+	if err != nil {
+		return err
+	}
+
+	eg, ctx := errgroup.WithContext(bccommon.CommandContext(clicontext))
+	eg.Go(func() error {
+		return c.BuildHistoryLogs(ctx, ref, pw.Status())
+	})
+	eg.Go(func() error {
+		<-pw.Done()
+		return pw.Err()
+	})
+	return eg.Wait()
+}
@@ -0,0 +1,58 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/moby/buildkit/client"
+	bccommon "github.com/moby/buildkit/cmd/buildctl/common"
+	"github.com/urfave/cli"
+)
+
+var ListCommand = cli.Command{
+	Name:   "ls",
+	Usage:  "list completed builds",
+	Action: list,
+}
+
+func list(clicontext *cli.Context) error {
+	c, err := bccommon.ResolveClient(clicontext)
+	if err != nil {
+		return err
+	}
+
+	recs, err := c.BuildHistory(bccommon.CommandContext(clicontext), "")
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 1, 8, 1, '\t', 0)
+	fmt.Fprintln(tw, "REF\tFRONTEND\tSTATUS\tCREATED AT\tDURATION\tSTEPS\tCACHED")
+	for _, rec := range recs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%d\n", rec.Ref, rec.Frontend, status(rec), createdAt(rec), duration(rec), rec.NumTotalSteps, rec.NumCachedSteps)
+	}
+	return tw.Flush()
+}
+
+func status(rec *client.BuildHistoryRecord) string {
+	if rec.Error != "" {
+		return "error"
+	}
+	return "completed"
+}
+
+func createdAt(rec *client.BuildHistoryRecord) string {
+	if rec.CreatedAt == nil {
+		return ""
+	}
+	return rec.CreatedAt.Format(time.RFC3339)
+}
+
+func duration(rec *client.BuildHistoryRecord) string {
+	if rec.CreatedAt == nil || rec.CompletedAt == nil {
+		return ""
+	}
+	return rec.CompletedAt.Sub(*rec.CreatedAt).Round(100 * time.Millisecond).String()
+}
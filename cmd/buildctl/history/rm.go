@@ -0,0 +1,34 @@
+package history
+
+import (
+	bccommon "github.com/moby/buildkit/cmd/buildctl/common"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+var RemoveCommand = cli.Command{
+	Name:      "rm",
+	Usage:     "remove the records of completed builds",
+	ArgsUsage: "REF [REF...]",
+	Action:    remove,
+}
+
+func remove(clicontext *cli.Context) error {
+	refs := clicontext.Args()
+	if len(refs) == 0 {
+		return errors.New("build ref must be specified")
+	}
+
+	c, err := bccommon.ResolveClient(clicontext)
+	if err != nil {
+		return err
+	}
+
+	ctx := bccommon.CommandContext(clicontext)
+	for _, ref := range refs {
+		if err := c.DeleteBuildHistory(ctx, ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
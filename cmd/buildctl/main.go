@@ -80,6 +80,11 @@ func main() {
 			Usage: "directory containing CA certificate, client certificate, and client key",
 			Value: "",
 		},
+		cli.StringFlag{
+			Name:   "token",
+			Usage:  "token identifying the tenant on a daemon shared by tenants",
+			EnvVar: "BUILDKIT_TOKEN",
+		},
 		cli.IntFlag{
 			Name:  "timeout",
 			Usage: "timeout backend connection after value seconds",
@@ -90,7 +95,12 @@ func main() {
 	app.Commands = []cli.Command{
 		diskUsageCommand,
 		pruneCommand,
+		gcCommand,
 		buildCommand,
+		attachCommand,
+		cancelCommand,
+		historyCommand,
+		dockerfileCommand,
 		debugCommand,
 		dialStdioCommand,
 	}
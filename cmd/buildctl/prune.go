@@ -15,7 +15,7 @@ var pruneCommand = cli.Command{
 	Name:   "prune",
 	Usage:  "clean up build cache",
 	Action: prune,
-	Flags: []cli.Flag{
+	Flags: append([]cli.Flag{
 		cli.DurationFlag{
 			Name:  "keep-duration",
 			Usage: "Keep data newer than this limit",
@@ -36,7 +36,7 @@ var pruneCommand = cli.Command{
 			Name:  "verbose, v",
 			Usage: "Verbose output",
 		},
-	},
+	}, usageFilterFlags...),
 }
 
 func prune(clicontext *cli.Context) error {
@@ -45,6 +45,11 @@ func prune(clicontext *cli.Context) error {
 		return err
 	}
 
+	filters, err := usageFilters(clicontext)
+	if err != nil {
+		return err
+	}
+
 	ch := make(chan client.UsageInfo)
 	printed := make(chan struct{})
 
@@ -70,7 +75,7 @@ func prune(clicontext *cli.Context) error {
 	}()
 
 	opts := []client.PruneOption{
-		client.WithFilter(clicontext.StringSlice("filter")),
+		client.WithFilter(filters),
 		client.WithKeepOpt(clicontext.Duration("keep-duration"), int64(clicontext.Float64("keep-storage")*1e6)),
 	}
 
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+// usageFilterFlags are the flags of du and prune selecting records in
+// addition to --filter.
+var usageFilterFlags = []cli.Flag{
+	cli.StringSliceFlag{
+		Name:  "type",
+		Usage: "Only include records of the type (regular, internal, frontend, source.local, source.git.checkout, exec.cachemount)",
+	},
+	cli.StringFlag{
+		Name:  "description",
+		Usage: "Only include records with a description matching the regular expression",
+	},
+	cli.DurationFlag{
+		Name:  "until",
+		Usage: "Only include records created longer ago than the duration",
+	},
+	cli.DurationFlag{
+		Name:  "since",
+		Usage: "Only include records created within the duration",
+	},
+	cli.DurationFlag{
+		Name:  "unused-for",
+		Usage: "Only include records not used within the duration",
+	},
+	cli.DurationFlag{
+		Name:  "used-within",
+		Usage: "Only include records used within the duration",
+	},
+}
+
+// usageFilters returns the filters of the --filter flags, with the conditions
+// of the usageFilterFlags added to every filter.
+func usageFilters(clicontext *cli.Context) ([]string, error) {
+	var clauses []string
+	if types := clicontext.StringSlice("type"); len(types) > 0 {
+		quoted := make([]string, len(types))
+		for i, t := range types {
+			quoted[i] = regexp.QuoteMeta(t)
+		}
+		clauses = append(clauses, "type~="+strconv.Quote("^("+strings.Join(quoted, "|")+")$"))
+	}
+	if desc := clicontext.String("description"); desc != "" {
+		if _, err := regexp.Compile(desc); err != nil {
+			return nil, errors.Wrap(err, "invalid description regular expression")
+		}
+		clauses = append(clauses, "description~="+strconv.Quote(desc))
+	}
+	for _, name := range []string{"until", "since", "unused-for", "used-within"} {
+		if d := clicontext.Duration(name); d != 0 {
+			clauses = append(clauses, name+"="+d.String())
+		}
+	}
+
+	filters := clicontext.StringSlice("filter")
+	if len(clauses) == 0 {
+		return filters, nil
+	}
+	cond := strings.Join(clauses, ",")
+	if len(filters) == 0 {
+		return []string{cond}, nil
+	}
+	out := make([]string, len(filters))
+	for i, f := range filters {
+		out[i] = f + "," + cond
+	}
+	return out, nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+func TestUsageFilters(t *testing.T) {
+	parse := func(args ...string) (filters []string, err error) {
+		cmd := diskUsageCommand
+		cmd.Action = func(clicontext *cli.Context) error {
+			filters, err = usageFilters(clicontext)
+			return nil
+		}
+		app := cli.NewApp()
+		app.Commands = []cli.Command{cmd}
+		require.NoError(t, app.Run(append([]string{"buildctl", "du"}, args...)))
+		return filters, err
+	}
+
+	filters, err := parse()
+	require.NoError(t, err)
+	require.Empty(t, filters)
+
+	filters, err = parse("--filter", "id==foo", "--filter", "shared")
+	require.NoError(t, err)
+	require.Equal(t, []string{"id==foo", "shared"}, filters)
+
+	filters, err = parse("--type", "regular", "--type", "exec.cachemount", "--description", "alpine", "--until", "24h", "--unused-for", "1h")
+	require.NoError(t, err)
+	require.Equal(t, []string{`type~="^(regular|exec\\.cachemount)$",description~="alpine",until=24h0m0s,unused-for=1h0m0s`}, filters)
+
+	filters, err = parse("--filter", "id==foo", "--filter", "shared", "--since", "2h")
+	require.NoError(t, err)
+	require.Equal(t, []string{"id==foo,since=2h0m0s", "shared,since=2h0m0s"}, filters)
+
+	_, err = parse("--description", "(")
+	require.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
 	resolverconfig "github.com/moby/buildkit/util/resolver/config"
 )
 
@@ -19,40 +20,264 @@ type Config struct {
 	Workers struct {
 		OCI        OCIConfig        `toml:"oci"`
 		Containerd ContainerdConfig `toml:"containerd"`
+		// Remote are the buildkitd agents running the RUN steps of their
+		// platforms for the builds of this daemon.
+		Remote []RemoteWorkerConfig `toml:"remote"`
 	} `toml:"worker"`
 
 	Registries map[string]resolverconfig.RegistryConfig `toml:"registry"`
 
 	DNS *DNSConfig `toml:"dns"`
+
+	Resources ResourcesConfig `toml:"resources"`
+
+	// SourcePolicy rules deny or rewrite the sources of all builds. The first
+	// rule matching a source applies.
+	SourcePolicy []SourcePolicyRule `toml:"sourcePolicy"`
+
+	// ImagePolicy rules require the images matching their selector to be
+	// signed, and optionally attested, with one of their public keys. The
+	// first rule matching an image applies.
+	ImagePolicy []ImagePolicyRule `toml:"imagePolicy"`
+
+	// Frontend restricts the frontends run from untrusted images.
+	Frontend FrontendConfig `toml:"frontend"`
+
+	// AllowedSockets are the IDs of the client sockets that builds can
+	// forward to their RUN steps, e.g. "docker" or "gpg-*". No socket is
+	// forwarded by default.
+	AllowedSockets []string `toml:"allowedSockets"`
+
+	// BuildInfo configures the build info recorded in the results.
+	BuildInfo BuildInfoConfig `toml:"buildinfo"`
+
+	// Tenancy splits the daemon between tenants.
+	Tenancy TenancyConfig `toml:"tenancy"`
+
+	// Audit writes the audit records of the builds and the prunes.
+	Audit AuditConfig `toml:"audit"`
+}
+
+// AuditConfig sets where the audit records of the builds and the prunes are
+// written. Auditing is disabled if neither is set.
+type AuditConfig struct {
+	// Path is the file the records are appended to as JSON lines.
+	Path string `toml:"path"`
+	// OTLPEndpoint is the OTLP gRPC endpoint the records are sent to as
+	// spans.
+	OTLPEndpoint string `toml:"otlpEndpoint"`
+	OTLPInsecure bool   `toml:"otlpInsecure"`
+}
+
+// TenancyConfig splits the daemon between tenants identified by their client
+// certificates or tokens. Requests of no tenant are rejected when tenants
+// are configured. The builds of a tenant don't share their cache, their
+// running steps and their cache mounts with other tenants.
+type TenancyConfig struct {
+	// ShareImages shares the cache of the base images between tenants.
+	ShareImages bool           `toml:"shareImages"`
+	Tenants     []TenantConfig `toml:"tenant"`
+}
+
+type TenantConfig struct {
+	Name string `toml:"name"`
+	// CommonNames are the common names of the client certificates of the
+	// tenant, verified with the CA of the grpc TLS config.
+	CommonNames []string `toml:"commonNames"`
+	// Tokens are the bearer tokens of the tenant.
+	Tokens []string `toml:"tokens"`
+	// DiskQuota is the maximum size of the build cache records created for
+	// the tenant. Its builds run garbage collection of its records when they
+	// exceed it and fail if that does not free enough space.
+	DiskQuota int64 `toml:"diskQuota"`
+	// GCPolicy are the garbage collection policies of the records of the
+	// tenant, run with the policies of the workers.
+	GCPolicy []GCPolicy `toml:"gcpolicy"`
+}
+
+// BuildInfoConfig selects the frontend attributes recorded in the build info,
+// with patterns in which * matches any sequence of characters, e.g.
+// build-arg:SECRET_*.
+type BuildInfoConfig struct {
+	// AllowAttrs are the recorded attributes. The attributes that could
+	// change the build result are recorded if it is not set.
+	AllowAttrs []string `toml:"allowAttrs"`
+	// DenyAttrs are the attributes never recorded, even if allowed.
+	DenyAttrs []string `toml:"denyAttrs"`
+	// RedactAttrs are the attributes recorded with the digest of their value
+	// instead of the value, in addition to the build args whose names look
+	// like they hold credentials, e.g. GITHUB_TOKEN.
+	RedactAttrs []string `toml:"redactAttrs"`
+	// NoDefaultRedact disables the redaction of the build args whose names
+	// look like they hold credentials.
+	NoDefaultRedact bool `toml:"noDefaultRedact"`
+}
+
+// SourcePolicyRule matches source identifiers, e.g.
+// docker-image://docker.io/library/alpine:latest, with the selector.
+type SourcePolicyRule struct {
+	// Action is allow, deny or convert
+	Action spb.PolicyAction `toml:"action"`
+	// MatchType of the selector is wildcard (default), exact or regex
+	MatchType spb.MatchType `toml:"matchType"`
+	Selector  string        `toml:"selector"`
+	// Identifier and Attrs update converted sources. Identifier can reference
+	// the groups captured by the selector with ${1}, ${2}, ...
+	Identifier string            `toml:"identifier"`
+	Attrs      map[string]string `toml:"attrs"`
+}
+
+// ImagePolicyRule matches image references, e.g. docker.io/library/*, with the
+// wildcard selector.
+type ImagePolicyRule struct {
+	Selector string `toml:"selector"`
+	// PublicKeys are the paths of the PEM encoded public keys verifying the
+	// cosign signatures and attestations of the images.
+	PublicKeys []string `toml:"publicKeys"`
+	// Attestations are the predicate types of the in-toto attestations the
+	// images require, e.g. https://slsa.dev/provenance/v0.2.
+	Attestations []string `toml:"attestations"`
+}
+
+// FrontendConfig configures the frontends run from images, e.g. with the
+// syntax directive of Dockerfiles.
+type FrontendConfig struct {
+	// Trusted are the repositories of the frontend images run without
+	// restrictions, as path.Match patterns, e.g. docker.io/docker/dockerfile.
+	Trusted []string `toml:"trusted"`
+	// Untrusted restricts the frontends run from the other images. They run
+	// without restrictions if it is not set.
+	Untrusted *UntrustedFrontendConfig `toml:"untrusted"`
+}
+
+// UntrustedFrontendConfig sets the features allowed to untrusted frontends.
+// Untrusted frontends can't solve requests of other frontends.
+type UntrustedFrontendConfig struct {
+	// Network allows the frontend containers to access the network.
+	Network bool `toml:"network"`
+	// Secrets allows the frontends to use the secrets of the builds.
+	Secrets bool `toml:"secrets"`
+	// MaxLLBSize is the maximum size in bytes of the definitions the
+	// frontends can solve.
+	MaxLLBSize int64 `toml:"maxLLBSize"`
+}
+
+// ResourcesConfig sets the default resource limits of the exec containers of
+// builds and how operations of concurrent builds are scheduled.
+type ResourcesConfig struct {
+	CPU    float64 `toml:"cpu"`
+	Memory int64   `toml:"memory"`
+	Pids   int64   `toml:"pids"`
+	// MaxParallelism is the number of operations run in parallel across
+	// builds. Builds get a share of them in proportion to their weight.
+	MaxParallelism int `toml:"maxParallelism"`
+	// MaxNetworkParallelism limits image pulls, git fetches, http downloads
+	// and cache imports, MaxExecParallelism exec operations.
+	MaxNetworkParallelism int `toml:"maxNetworkParallelism"`
+	MaxExecParallelism    int `toml:"maxExecParallelism"`
+	// SpeculativeFetches limits the image pulls and git fetches started
+	// before the cache lookups of the vertexes using them complete. Zero
+	// disables speculative fetches.
+	SpeculativeFetches int `toml:"speculativeFetches"`
+	// ContextBandwidth, PushBandwidth and PullBandwidth are the default
+	// limits in bytes per second of the transfer of the local contexts of a
+	// build and of its registry pushes and pulls. Each build gets its own
+	// limits.
+	ContextBandwidth int64 `toml:"contextBandwidth"`
+	PushBandwidth    int64 `toml:"pushBandwidth"`
+	PullBandwidth    int64 `toml:"pullBandwidth"`
 }
 
 type GRPCConfig struct {
-	Address      []string `toml:"address"`
-	DebugAddress string   `toml:"debugAddress"`
-	UID          *int     `toml:"uid"`
-	GID          *int     `toml:"gid"`
+	Address        []string `toml:"address"`
+	DebugAddress   string   `toml:"debugAddress"`
+	MetricsAddress string   `toml:"metricsAddress"`
+	UID            *int     `toml:"uid"`
+	GID            *int     `toml:"gid"`
 
 	TLS TLSConfig `toml:"tls"`
+	// Clients are the roles of the client certificates of the TCP
+	// listeners. When set, TCP clients can only call the methods of their
+	// roles, local sockets are not restricted.
+	Clients []GRPCClientConfig `toml:"client"`
 	// MaxRecvMsgSize int    `toml:"max_recv_message_size"`
 	// MaxSendMsgSize int    `toml:"max_send_message_size"`
 }
 
+type GRPCClientConfig struct {
+	// CommonNames are the common names of the client certificates, verified
+	// with the CA of the TLS config.
+	CommonNames []string `toml:"commonNames"`
+	// Roles are build, prune or admin.
+	Roles []string `toml:"roles"`
+}
+
 type TLSConfig struct {
 	Cert string `toml:"cert"`
 	Key  string `toml:"key"`
 	CA   string `toml:"ca"`
 }
 
+// RemoteWorkerConfig is a buildkitd agent running the exec operations of its
+// platforms. The results are kept in the cache of the default worker.
+type RemoteWorkerConfig struct {
+	Address   string            `toml:"address"`
+	Platforms []string          `toml:"platforms"`
+	Labels    map[string]string `toml:"labels"`
+	// ServerName is the name verified in the certificate of the agent, the
+	// host of the address by default.
+	ServerName string    `toml:"serverName"`
+	TLS        TLSConfig `toml:"tls"`
+}
+
 type GCConfig struct {
 	GC            *bool      `toml:"gc"`
 	GCKeepStorage int64      `toml:"gckeepstorage"`
 	GCPolicy      []GCPolicy `toml:"gcpolicy"`
+	// DiskQuota is the maximum size of the build cache. Builds run garbage
+	// collection when they exceed it and fail if that does not free enough
+	// space.
+	DiskQuota int64 `toml:"diskQuota"`
 }
 
 type NetworkConfig struct {
 	Mode          string `toml:"networkMode"`
 	CNIConfigPath string `toml:"cniConfigPath"`
 	CNIBinaryPath string `toml:"cniBinaryPath"`
+	// CNINetworks maps the names of the networks that can be selected by
+	// build steps to their CNI config files.
+	CNINetworks map[string]string `toml:"cniNetworks"`
+	// NetworkPools are named CNI networks with namespaces that are set up in
+	// advance and optional egress rules.
+	NetworkPools []NetworkPoolConfig `toml:"networkPool"`
+	// Slirp4netnsBinary is the slirp4netns binary of the slirp4netns
+	// network mode, looked up in PATH if it is not a path.
+	Slirp4netnsBinary string `toml:"slirp4netnsBinary"`
+	// Bypass4netns accelerates the sockets of the build steps in the
+	// slirp4netns network mode with bypass4netns.
+	Bypass4netns bool `toml:"bypass4netns"`
+}
+
+type NetworkPoolConfig struct {
+	Name          string `toml:"name"`
+	CNIConfigPath string `toml:"cniConfigPath"`
+	// Size is the number of namespaces that are kept ready.
+	Size int `toml:"size"`
+	// Egress restricts the outgoing connections of the build steps to the
+	// destinations of the rules if set.
+	Egress []EgressRuleConfig `toml:"egress"`
+	// PublishPorts are the host ports gateway containers can publish on the
+	// network. They can't publish any port if it is not set.
+	PublishPorts []int `toml:"publishPorts"`
+	// PublishHostIP is the address of the host the ports are published on,
+	// all the addresses of the host if unset.
+	PublishHostIP string `toml:"publishHostIP"`
+}
+
+type EgressRuleConfig struct {
+	CIDR     string `toml:"cidr"`
+	Ports    []int  `toml:"ports"`
+	Protocol string `toml:"protocol"`
 }
 
 type OCIConfig struct {
@@ -83,6 +308,18 @@ type OCIConfig struct {
 
 	// MaxParallelism is the maximum number of parallel build steps that can be run at the same time.
 	MaxParallelism int `toml:"max-parallelism"`
+
+	// UsernsRemap runs the containers of the builds in user namespaces with
+	// uid and gid ranges of their own.
+	UsernsRemap UsernsRemapConfig `toml:"usernsRemap"`
+
+	// Runtimes are the alternative OCI runtimes the build steps can select,
+	// keyed by name, e.g. gvisor = "runsc". The values are the names or the
+	// paths of their binaries.
+	Runtimes map[string]string `toml:"runtimes"`
+	// DefaultRuntime is the name of the runtime of the build steps that
+	// don't select one. Binary is used if it is empty.
+	DefaultRuntime string `toml:"defaultRuntime"`
 }
 
 type ContainerdConfig struct {
@@ -102,6 +339,27 @@ type ContainerdConfig struct {
 	MaxParallelism int `toml:"max-parallelism"`
 
 	Rootless bool `toml:"rootless"`
+
+	UsernsRemap UsernsRemapConfig `toml:"usernsRemap"`
+
+	// Runtimes are the containerd runtimes the build steps can select, keyed
+	// by name, e.g. kata = "io.containerd.kata.v2".
+	Runtimes map[string]string `toml:"runtimes"`
+	// DefaultRuntime is the name of the runtime of the build steps that
+	// don't select one. The default runtime of containerd is used if it is
+	// empty.
+	DefaultRuntime string `toml:"defaultRuntime"`
+}
+
+type UsernsRemapConfig struct {
+	// User is the user of /etc/subuid and /etc/subgid the ranges of the
+	// containers are allocated from.
+	User string `toml:"user"`
+	// Size is the number of uids and gids mapped in each container.
+	Size int `toml:"size"`
+	// Default is the mode of the builds that don't set one, "host" or
+	// "private".
+	Default string `toml:"default"`
 }
 
 type GCPolicy struct {
@@ -109,6 +367,16 @@ type GCPolicy struct {
 	KeepBytes    int64    `toml:"keepBytes"`
 	KeepDuration int64    `toml:"keepDuration"`
 	Filters      []string `toml:"filters"`
+	// ReservedSpace is the size of the build cache that is never removed by
+	// the policy.
+	ReservedSpace int64 `toml:"reservedSpace"`
+	// MinFreeSpace is the free disk space the policy prunes the build cache
+	// for. MinFreeSpacePercent sets it as a percentage of the disk size.
+	MinFreeSpace        int64 `toml:"minFreeSpace"`
+	MinFreeSpacePercent int64 `toml:"minFreeSpacePercent"`
+	// Schedule runs the policy periodically with a cron expression instead
+	// of after builds.
+	Schedule string `toml:"schedule"`
 }
 
 type DNSConfig struct {
@@ -1,5 +1,7 @@
 package config
 
+import "github.com/pkg/errors"
+
 const defaultCap int64 = 2e9 // 2GB
 
 func DefaultGCPolicy(p string, keep int64) []GCPolicy {
@@ -29,3 +31,22 @@ func DefaultGCPolicy(p string, keep int64) []GCPolicy {
 		},
 	}
 }
+
+// MinFreeSpaceBytes returns the free disk space target of the policy for the
+// disk of root. The percentage is used if it sets a larger target.
+func (p GCPolicy) MinFreeSpaceBytes(root string) (int64, error) {
+	if p.MinFreeSpacePercent == 0 {
+		return p.MinFreeSpace, nil
+	}
+	if p.MinFreeSpacePercent < 0 || p.MinFreeSpacePercent > 100 {
+		return 0, errors.Errorf("invalid minFreeSpacePercent %d", p.MinFreeSpacePercent)
+	}
+	diskSize, err := getDiskSize(root)
+	if err != nil {
+		return 0, err
+	}
+	if v := diskSize * p.MinFreeSpacePercent / 100; v > p.MinFreeSpace {
+		return v, nil
+	}
+	return p.MinFreeSpace, nil
+}
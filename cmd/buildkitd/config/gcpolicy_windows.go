@@ -3,6 +3,12 @@
 
 package config
 
+import "github.com/pkg/errors"
+
 func DetectDefaultGCCap(root string) int64 {
 	return defaultCap
 }
+
+func getDiskSize(root string) (int64, error) {
+	return 0, errors.New("disk size detection is not supported on windows")
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"testing"
 
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
 	"github.com/stretchr/testify/require"
 )
 
@@ -61,6 +62,15 @@ cert="cert.pem"
 nameservers=["1.1.1.1","8.8.8.8"]
 options=["edns0"]
 searchDomains=["example.com"]
+
+[[sourcePolicy]]
+action="convert"
+selector="docker-image://docker.io/*"
+identifier="docker-image://mirror.example.com/${1}"
+[[sourcePolicy]]
+action="deny"
+matchType="regex"
+selector="^git://.*$"
 `
 
 	cfg, err := Load(bytes.NewBuffer([]byte(testConfig)))
@@ -113,6 +123,13 @@ searchDomains=["example.com"]
 	require.Equal(t, cfg.Registries["docker.io"].KeyPairs[0].Key, "key.pem")
 	require.Equal(t, cfg.Registries["docker.io"].KeyPairs[0].Certificate, "cert.pem")
 
+	require.Equal(t, 2, len(cfg.SourcePolicy))
+	require.Equal(t, spb.PolicyAction_CONVERT, cfg.SourcePolicy[0].Action)
+	require.Equal(t, spb.MatchType_WILDCARD, cfg.SourcePolicy[0].MatchType)
+	require.Equal(t, "docker-image://mirror.example.com/${1}", cfg.SourcePolicy[0].Identifier)
+	require.Equal(t, spb.PolicyAction_DENY, cfg.SourcePolicy[1].Action)
+	require.Equal(t, spb.MatchType_REGEX, cfg.SourcePolicy[1].MatchType)
+
 	require.NotNil(t, cfg.DNS)
 	require.Equal(t, cfg.DNS.Nameservers, []string{"1.1.1.1", "8.8.8.8"})
 	require.Equal(t, cfg.DNS.SearchDomains, []string{"example.com"})
@@ -18,7 +18,6 @@ import (
 	"github.com/containerd/containerd/pkg/seed"
 	"github.com/containerd/containerd/pkg/userns"
 	"github.com/containerd/containerd/platforms"
-	"github.com/containerd/containerd/remotes/docker"
 	"github.com/containerd/containerd/sys"
 	sddaemon "github.com/coreos/go-systemd/v22/daemon"
 	"github.com/docker/docker/pkg/reexec"
@@ -32,32 +31,48 @@ import (
 	"github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/cmd/buildkitd/config"
 	"github.com/moby/buildkit/control"
+	"github.com/moby/buildkit/executor"
 	"github.com/moby/buildkit/executor/oci"
 	"github.com/moby/buildkit/frontend"
+	"github.com/moby/buildkit/frontend/bake"
+	"github.com/moby/buildkit/frontend/compose"
 	dockerfile "github.com/moby/buildkit/frontend/dockerfile/builder"
 	"github.com/moby/buildkit/frontend/gateway"
 	"github.com/moby/buildkit/frontend/gateway/forwarder"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/solver/bboltcachestorage"
+	"github.com/moby/buildkit/solver/llbsolver"
+	"github.com/moby/buildkit/solver/pb"
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
 	"github.com/moby/buildkit/util/apicaps"
 	"github.com/moby/buildkit/util/appcontext"
 	"github.com/moby/buildkit/util/appdefaults"
 	"github.com/moby/buildkit/util/archutil"
+	"github.com/moby/buildkit/util/audit"
+	"github.com/moby/buildkit/util/bandwidth"
 	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/buildinfo"
 	"github.com/moby/buildkit/util/grpcerrors"
+	"github.com/moby/buildkit/util/imageverify"
+	"github.com/moby/buildkit/util/network/cniprovider"
+	"github.com/moby/buildkit/util/network/netproviders"
 	"github.com/moby/buildkit/util/profiler"
-	"github.com/moby/buildkit/util/resolver"
+	"github.com/moby/buildkit/util/rbac"
+	"github.com/moby/buildkit/util/rootless/diag"
 	"github.com/moby/buildkit/util/stack"
+	"github.com/moby/buildkit/util/tenant"
 	"github.com/moby/buildkit/util/tracing/detect"
 	_ "github.com/moby/buildkit/util/tracing/detect/jaeger"
 	_ "github.com/moby/buildkit/util/tracing/env"
 	"github.com/moby/buildkit/util/tracing/transform"
 	"github.com/moby/buildkit/version"
 	"github.com/moby/buildkit/worker"
+	"github.com/moby/buildkit/worker/remote"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
+	bolt "go.etcd.io/bbolt"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.opentelemetry.io/otel/propagation"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -170,6 +185,11 @@ func main() {
 			Usage: "debugging address (eg. 0.0.0.0:6060)",
 			Value: defaultConf.GRPC.DebugAddress,
 		},
+		cli.StringFlag{
+			Name:  "metrics-addr",
+			Usage: "address for serving Prometheus metrics (eg. 0.0.0.0:9090)",
+			Value: defaultConf.GRPC.MetricsAddress,
+		},
 		cli.StringFlag{
 			Name:  "tlscert",
 			Usage: "certificate file to use",
@@ -187,7 +207,7 @@ func main() {
 		},
 		cli.StringSliceFlag{
 			Name:  "allow-insecure-entitlement",
-			Usage: "allows insecure entitlements e.g. network.host, security.insecure",
+			Usage: "allows insecure entitlements e.g. network.host, security.insecure, nested.build",
 		},
 	)
 	app.Flags = append(app.Flags, appFlags...)
@@ -222,6 +242,12 @@ func main() {
 			}
 		}
 
+		if cfg.GRPC.MetricsAddress != "" {
+			if err := setupMetricsHandler(cfg.GRPC.MetricsAddress); err != nil {
+				return err
+			}
+		}
+
 		tp, err := detect.TracerProvider()
 		if err != nil {
 			return err
@@ -229,10 +255,30 @@ func main() {
 
 		streamTracer := otelgrpc.StreamServerInterceptor(otelgrpc.WithTracerProvider(tp), otelgrpc.WithPropagators(propagators))
 
-		unary := grpc_middleware.ChainUnaryServer(unaryInterceptor(ctx, tp), grpcerrors.UnaryServerInterceptor)
-		stream := grpc_middleware.ChainStreamServer(streamTracer, grpcerrors.StreamServerInterceptor)
+		unaryInterceptors := []grpc.UnaryServerInterceptor{unaryInterceptor(ctx, tp)}
+		streamInterceptors := []grpc.StreamServerInterceptor{streamTracer}
+		if len(cfg.GRPC.Clients) > 0 {
+			auth, err := authorizer(cfg.GRPC)
+			if err != nil {
+				return errors.Wrap(err, "invalid grpc client config")
+			}
+			unaryInterceptors = append(unaryInterceptors, auth.UnaryServerInterceptor())
+			streamInterceptors = append(streamInterceptors, auth.StreamServerInterceptor())
+		}
+		if len(cfg.Tenancy.Tenants) > 0 {
+			auth, err := tenant.NewAuthenticator(tenants(cfg.Tenancy))
+			if err != nil {
+				return errors.Wrap(err, "invalid tenancy config")
+			}
+			unaryInterceptors = append(unaryInterceptors, auth.UnaryServerInterceptor())
+			streamInterceptors = append(streamInterceptors, auth.StreamServerInterceptor())
+		}
+		unary := grpc_middleware.ChainUnaryServer(append(unaryInterceptors, grpcerrors.UnaryServerInterceptor)...)
+		stream := grpc_middleware.ChainStreamServer(append(streamInterceptors, grpcerrors.StreamServerInterceptor)...)
 
-		opts := []grpc.ServerOption{grpc.UnaryInterceptor(unary), grpc.StreamInterceptor(stream)}
+		// TLS is terminated by the listeners, the credentials pass the client
+		// certificates to the interceptors
+		opts := []grpc.ServerOption{grpc.UnaryInterceptor(unary), grpc.StreamInterceptor(stream), grpc.Creds(tenant.ListenerCredentials())}
 		server := grpc.NewServer(opts...)
 
 		// relative path does not work with nightlyone/lockfile
@@ -260,13 +306,23 @@ func main() {
 			os.RemoveAll(lockPath)
 		}()
 
-		controller, err := newController(c, &cfg)
+		auditLogger, err := newAuditLogger(ctx, cfg.Audit)
+		if err != nil {
+			return err
+		}
+		if auditLogger != nil {
+			defer auditLogger.Close()
+		}
+
+		controller, err := newController(c, &cfg, auditLogger)
 		if err != nil {
 			return err
 		}
 
 		controller.Register(server)
 
+		go reloadOnSignal(ctx, c.GlobalString("config"))
+
 		ents := c.GlobalStringSlice("allow-insecure-entitlement")
 		if len(ents) > 0 {
 			cfg.Entitlements = []string{}
@@ -449,6 +505,10 @@ func applyMainFlags(c *cli.Context, cfg *config.Config) error {
 		cfg.GRPC.DebugAddress = c.String("debugaddr")
 	}
 
+	if c.IsSet("metrics-addr") {
+		cfg.GRPC.MetricsAddress = c.String("metrics-addr")
+	}
+
 	if cfg.GRPC.UID == nil {
 		uid := os.Getuid()
 		cfg.GRPC.UID = &uid
@@ -608,7 +668,7 @@ func serverCredentials(cfg config.TLSConfig) (*tls.Config, error) {
 	return tlsConf, nil
 }
 
-func newController(c *cli.Context, cfg *config.Config) (*control.Controller, error) {
+func newController(c *cli.Context, cfg *config.Config, auditLogger audit.Logger) (*control.Controller, error) {
 	sessionManager, err := session.NewManager()
 	if err != nil {
 		return nil, err
@@ -635,15 +695,26 @@ func newController(c *cli.Context, cfg *config.Config) (*control.Controller, err
 	if err != nil {
 		return nil, err
 	}
+	if err := registerMetricsCollectors(sessionManager, wc); err != nil {
+		return nil, err
+	}
+
 	frontends := map[string]frontend.Frontend{}
 	frontends["dockerfile.v0"] = forwarder.NewGatewayForwarder(wc, dockerfile.Build)
-	frontends["gateway.v0"] = gateway.NewGatewayFrontend(wc)
+	frontends["bake.v0"] = forwarder.NewGatewayForwarder(wc, bake.Build)
+	frontends["compose.v0"] = forwarder.NewGatewayForwarder(wc, compose.Build)
+	frontends["gateway.v0"] = gateway.NewGatewayFrontend(wc, frontendRestrictions(cfg.Frontend))
 
 	cacheStorage, err := bboltcachestorage.NewStore(filepath.Join(cfg.Root, "cache.db"))
 	if err != nil {
 		return nil, err
 	}
 
+	historyDB, err := bolt.Open(filepath.Join(cfg.Root, "history.db"), 0600, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	resolverFn := resolverFunc(cfg)
 
 	w, err := wc.GetDefault()
@@ -651,6 +722,11 @@ func newController(c *cli.Context, cfg *config.Config) (*control.Controller, err
 		return nil, err
 	}
 
+	imagePol, err := imagePolicy(cfg.ImagePolicy)
+	if err != nil {
+		return nil, err
+	}
+
 	remoteCacheExporterFuncs := map[string]remotecache.ResolveCacheExporterFunc{
 		"registry": registryremotecache.ResolveCacheExporterFunc(sessionManager, resolverFn),
 		"local":    localremotecache.ResolveCacheExporterFunc(sessionManager),
@@ -672,11 +748,158 @@ func newController(c *cli.Context, cfg *config.Config) (*control.Controller, err
 		CacheKeyStorage:           cacheStorage,
 		Entitlements:              cfg.Entitlements,
 		TraceCollector:            tc,
+		HistoryDB:                 historyDB,
+		AuditLogger:               auditLogger,
+		ResourceLimits: executor.ResourceLimits{
+			CPU:    cfg.Resources.CPU,
+			Memory: cfg.Resources.Memory,
+			Pids:   cfg.Resources.Pids,
+		},
+		TenantGCPolicy:    tenantGCPolicy(cfg.Tenancy, cfg.Root),
+		ShareTenantImages: cfg.Tenancy.ShareImages,
+		BandwidthLimits: bandwidth.Limits{
+			Context: cfg.Resources.ContextBandwidth,
+			Push:    cfg.Resources.PushBandwidth,
+			Pull:    cfg.Resources.PullBandwidth,
+		},
+		Parallelism: llbsolver.Parallelism{
+			Max:         cfg.Resources.MaxParallelism,
+			Network:     cfg.Resources.MaxNetworkParallelism,
+			Exec:        cfg.Resources.MaxExecParallelism,
+			Speculative: cfg.Resources.SpeculativeFetches,
+		},
+		SourcePolicies: []*spb.Policy{sourcePolicy(cfg.SourcePolicy), imagePol},
+		AllowedSockets: cfg.AllowedSockets,
+		BuildInfoAttrs: &buildinfo.AttrsPolicy{
+			Allow:           cfg.BuildInfo.AllowAttrs,
+			Deny:            cfg.BuildInfo.DenyAttrs,
+			Redact:          cfg.BuildInfo.RedactAttrs,
+			NoDefaultRedact: cfg.BuildInfo.NoDefaultRedact,
+		},
+		ReloadConfig: func(ctx context.Context) ([]string, error) {
+			return reloadConfig(ctx, c.GlobalString("config"))
+		},
+		RootlessInfo: func(ctx context.Context) (bool, []diag.Check) {
+			return cfg.Workers.OCI.Rootless || cfg.Workers.Containerd.Rootless, diag.Detect(ctx, rootlessDiagOpt(cfg))
+		},
 	})
 }
 
-func resolverFunc(cfg *config.Config) docker.RegistryHosts {
-	return resolver.NewRegistryConfig(cfg.Registries)
+// rootlessDiagOpt returns the options of the detection of the features used
+// in rootless mode.
+func rootlessDiagOpt(cfg *config.Config) diag.Opt {
+	opt := diag.Opt{
+		Root:        cfg.Root,
+		Slirp4netns: cfg.Workers.OCI.Slirp4netnsBinary,
+	}
+	if cfg.Workers.OCI.Bypass4netns {
+		opt.Bypass4netns = "bypass4netns"
+	}
+	return opt
+}
+
+// newAuditLogger returns the logger of the audit records, nil if auditing is
+// disabled.
+func newAuditLogger(ctx context.Context, cfg config.AuditConfig) (audit.Logger, error) {
+	var loggers []audit.Logger
+	if cfg.Path != "" {
+		l, err := audit.NewFileLogger(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		loggers = append(loggers, l)
+	}
+	if cfg.OTLPEndpoint != "" {
+		l, err := audit.NewOTLPLogger(ctx, cfg.OTLPEndpoint, cfg.OTLPInsecure)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create audit OTLP exporter")
+		}
+		loggers = append(loggers, l)
+	}
+	switch len(loggers) {
+	case 0:
+		return nil, nil
+	case 1:
+		return loggers[0], nil
+	}
+	return audit.MultiLogger(loggers...), nil
+}
+
+func sourcePolicy(rules []config.SourcePolicyRule) *spb.Policy {
+	if len(rules) == 0 {
+		return nil
+	}
+	pol := &spb.Policy{Version: 1}
+	for _, r := range rules {
+		rule := &spb.Rule{
+			Action: r.Action,
+			Selector: &spb.Selector{
+				Identifier: r.Selector,
+				MatchType:  r.MatchType,
+			},
+		}
+		if r.Identifier != "" || len(r.Attrs) > 0 {
+			rule.Updates = &spb.Update{
+				Identifier: r.Identifier,
+				Attrs:      r.Attrs,
+			}
+		}
+		pol.Rules = append(pol.Rules, rule)
+	}
+	return pol
+}
+
+// frontendRestrictions returns the restrictions of the untrusted frontend
+// images, or nil if they run without restrictions.
+func frontendRestrictions(cfg config.FrontendConfig) *gateway.Restrictions {
+	if cfg.Untrusted == nil {
+		return nil
+	}
+	return &gateway.Restrictions{
+		Trusted:    cfg.Trusted,
+		Network:    cfg.Untrusted.Network,
+		Secrets:    cfg.Untrusted.Secrets,
+		MaxLLBSize: cfg.Untrusted.MaxLLBSize,
+	}
+}
+
+// imagePolicy returns the policy setting the verification attributes of the
+// images matching the rules. It is applied after the source policy, so the
+// images are verified after they are converted.
+func imagePolicy(rules []config.ImagePolicyRule) (*spb.Policy, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	pol := &spb.Policy{Version: 1}
+	for _, r := range rules {
+		var keys []byte
+		for _, p := range r.PublicKeys {
+			dt, err := ioutil.ReadFile(p)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read image policy key for %s", r.Selector)
+			}
+			keys = append(keys, dt...)
+		}
+		if _, err := imageverify.ParsePolicy(string(keys), ""); err != nil {
+			return nil, errors.Wrapf(err, "invalid image policy for %s", r.Selector)
+		}
+		attrs := map[string]string{
+			pb.AttrImageVerifyKeys: string(keys),
+		}
+		if len(r.Attestations) > 0 {
+			attrs[pb.AttrImageVerifyAttestations] = strings.Join(r.Attestations, ",")
+		}
+		pol.Rules = append(pol.Rules, &spb.Rule{
+			Action: spb.PolicyAction_CONVERT,
+			Selector: &spb.Selector{
+				Identifier: "docker-image://" + r.Selector,
+			},
+			Updates: &spb.Update{
+				Attrs: attrs,
+			},
+		})
+	}
+	return pol, nil
 }
 
 func newWorkerController(c *cli.Context, wiOpt workerInitializerOpt) (*worker.Controller, error) {
@@ -704,11 +927,37 @@ func newWorkerController(c *cli.Context, wiOpt workerInitializerOpt) (*worker.Co
 	if err != nil {
 		return nil, err
 	}
+	for _, rc := range wiOpt.config.Workers.Remote {
+		w, err := newRemoteWorker(defaultWorker, rc)
+		if err != nil {
+			return nil, err
+		}
+		logrus.Infof("found remote worker %q, platforms=%v", w.ID(), formatPlatforms(w.Platforms(false)))
+		if err := wc.Add(w); err != nil {
+			return nil, err
+		}
+	}
 	logrus.Infof("found %d workers, default=%q", nWorkers, defaultWorker.ID())
-	logrus.Warn("currently, only the default worker can be used.")
 	return wc, nil
 }
 
+func newRemoteWorker(local worker.Worker, cfg config.RemoteWorkerConfig) (worker.Worker, error) {
+	platforms, err := parsePlatforms(cfg.Platforms)
+	if err != nil {
+		return nil, err
+	}
+	var opts []client.ClientOpt
+	if cfg.TLS.CA != "" {
+		opts = append(opts, client.WithCredentials(cfg.ServerName, cfg.TLS.CA, cfg.TLS.Cert, cfg.TLS.Key))
+	}
+	return remote.NewWorker(local, remote.WorkerOpt{
+		Address:    cfg.Address,
+		Platforms:  platforms,
+		Labels:     cfg.Labels,
+		ClientOpts: opts,
+	})
+}
+
 func attrMap(sl []string) (map[string]string, error) {
 	m := map[string]string{}
 	for _, v := range sl {
@@ -750,16 +999,82 @@ func getGCPolicy(cfg config.GCConfig, root string) []client.PruneInfo {
 	}
 	out := make([]client.PruneInfo, 0, len(cfg.GCPolicy))
 	for _, rule := range cfg.GCPolicy {
+		minFree, err := rule.MinFreeSpaceBytes(root)
+		if err != nil {
+			logrus.Warnf("ignoring free space target of gc policy: %v", err)
+		}
 		out = append(out, client.PruneInfo{
-			Filter:       rule.Filters,
-			All:          rule.All,
-			KeepBytes:    rule.KeepBytes,
-			KeepDuration: time.Duration(rule.KeepDuration) * time.Second,
+			Filter:        rule.Filters,
+			All:           rule.All,
+			KeepBytes:     rule.KeepBytes,
+			KeepDuration:  time.Duration(rule.KeepDuration) * time.Second,
+			ReservedSpace: rule.ReservedSpace,
+			MinFreeSpace:  minFree,
+			Schedule:      rule.Schedule,
+		})
+	}
+	return out
+}
+
+// authorizer returns the authorizer of the roles of the grpc clients. The
+// roles need the client certificates to be verified.
+func authorizer(cfg config.GRPCConfig) (*rbac.Authorizer, error) {
+	if cfg.TLS.CA == "" {
+		return nil, errors.New("client roles require a TLS CA to verify the client certificates")
+	}
+	clients := make([]rbac.Client, 0, len(cfg.Clients))
+	for _, c := range cfg.Clients {
+		roles := make([]rbac.Role, 0, len(c.Roles))
+		for _, r := range c.Roles {
+			role, err := rbac.ParseRole(r)
+			if err != nil {
+				return nil, err
+			}
+			roles = append(roles, role)
+		}
+		clients = append(clients, rbac.Client{CommonNames: c.CommonNames, Roles: roles})
+	}
+	return rbac.NewAuthorizer(clients)
+}
+
+func tenants(cfg config.TenancyConfig) []tenant.Tenant {
+	out := make([]tenant.Tenant, 0, len(cfg.Tenants))
+	for _, t := range cfg.Tenants {
+		out = append(out, tenant.Tenant{
+			Name:        t.Name,
+			CommonNames: t.CommonNames,
+			Tokens:      t.Tokens,
 		})
 	}
 	return out
 }
 
+// tenantGCPolicy returns the gc policies of the tenants, limited to the
+// records of their tenant.
+func tenantGCPolicy(cfg config.TenancyConfig, root string) []client.PruneInfo {
+	var out []client.PruneInfo
+	for _, t := range cfg.Tenants {
+		if len(t.GCPolicy) == 0 {
+			continue
+		}
+		for _, p := range getGCPolicy(config.GCConfig{GCPolicy: t.GCPolicy}, root) {
+			p.Tenant = t.Name
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func tenantDiskQuota(cfg config.TenancyConfig) map[string]int64 {
+	quota := map[string]int64{}
+	for _, t := range cfg.Tenants {
+		if t.DiskQuota > 0 {
+			quota[t.Name] = t.DiskQuota
+		}
+	}
+	return quota
+}
+
 func getDNSConfig(cfg *config.DNSConfig) *oci.DNSConfig {
 	var dns *oci.DNSConfig
 	if cfg != nil {
@@ -772,6 +1087,47 @@ func getDNSConfig(cfg *config.DNSConfig) *oci.DNSConfig {
 	return dns
 }
 
+func networkPools(cfg []config.NetworkPoolConfig) []netproviders.NetworkPool {
+	out := make([]netproviders.NetworkPool, 0, len(cfg))
+	for _, p := range cfg {
+		pool := netproviders.NetworkPool{
+			Name:          p.Name,
+			ConfigPath:    p.CNIConfigPath,
+			Size:          p.Size,
+			PublishPorts:  p.PublishPorts,
+			PublishHostIP: p.PublishHostIP,
+		}
+		for _, r := range p.Egress {
+			pool.Egress = append(pool.Egress, cniprovider.EgressRule{
+				CIDR:     r.CIDR,
+				Ports:    r.Ports,
+				Protocol: r.Protocol,
+			})
+		}
+		out = append(out, pool)
+	}
+	return out
+}
+
+// usernsRemapper returns the remapper of the user namespaces of the builds
+// of a worker, or nil if user namespace remapping isn't configured.
+func usernsRemapper(cfg config.UsernsRemapConfig, rootless bool) (*oci.UsernsRemapper, error) {
+	if cfg.User == "" {
+		if cfg.Default != "" && cfg.Default != oci.UsernsHost {
+			return nil, errors.Errorf("usernsRemap default %q requires a user", cfg.Default)
+		}
+		return nil, nil
+	}
+	if rootless {
+		return nil, errors.New("usernsRemap can't be used with rootless")
+	}
+	ranges, err := parseIdentityMapping(cfg.User)
+	if err != nil {
+		return nil, err
+	}
+	return oci.NewUsernsRemapper(ranges, cfg.Size, cfg.Default)
+}
+
 // parseBoolOrAuto returns (nil, nil) if s is "auto"
 func parseBoolOrAuto(s string) (*bool, error) {
 	if s == "" || strings.ToLower(s) == "auto" {
@@ -136,6 +136,11 @@ func init() {
 		}(),
 		Hidden: len(defaultConf.Workers.Containerd.GCPolicy) != 0,
 	})
+	flags = append(flags, cli.Int64Flag{
+		Name:  "containerd-worker-disk-quota",
+		Usage: "Maximum size of the build cache, builds exceeding it run garbage collection and fail if it is still exceeded (MB)",
+		Value: defaultConf.Workers.Containerd.DiskQuota / 1e6,
+	})
 
 	registerWorkerInitializer(
 		workerInitializer{
@@ -202,6 +207,10 @@ func applyContainerdFlags(c *cli.Context, cfg *config.Config) error {
 		cfg.Workers.Containerd.GCKeepStorage = c.GlobalInt64("containerd-worker-gc-keepstorage") * 1e6
 	}
 
+	if c.GlobalIsSet("containerd-worker-disk-quota") {
+		cfg.Workers.Containerd.DiskQuota = c.GlobalInt64("containerd-worker-disk-quota") * 1e6
+	}
+
 	if c.GlobalIsSet("containerd-worker-net") {
 		cfg.Workers.Containerd.NetworkConfig.Mode = c.GlobalString("containerd-worker-net")
 	}
@@ -248,6 +257,8 @@ func containerdWorkerInitializer(c *cli.Context, common workerInitializerOpt) ([
 			ConfigPath: common.config.Workers.Containerd.CNIConfigPath,
 			BinaryDir:  common.config.Workers.Containerd.CNIBinaryPath,
 		},
+		CNINetworks: common.config.Workers.Containerd.CNINetworks,
+		Pools:       networkPools(common.config.Workers.Containerd.NetworkPools),
 	}
 
 	var parallelismSem *semaphore.Weighted
@@ -259,11 +270,20 @@ func containerdWorkerInitializer(c *cli.Context, common workerInitializerOpt) ([
 	if cfg.Snapshotter != "" {
 		snapshotter = cfg.Snapshotter
 	}
-	opt, err := containerd.NewWorkerOpt(common.config.Root, cfg.Address, snapshotter, cfg.Namespace, cfg.Rootless, cfg.Labels, dns, nc, common.config.Workers.Containerd.ApparmorProfile, parallelismSem, common.traceSocket, ctd.WithTimeout(60*time.Second))
+	userns, err := usernsRemapper(cfg.UsernsRemap, cfg.Rootless)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := cfg.Runtimes[cfg.DefaultRuntime]; cfg.DefaultRuntime != "" && !ok {
+		return nil, errors.Errorf("unknown default runtime %q", cfg.DefaultRuntime)
+	}
+	opt, err := containerd.NewWorkerOpt(common.config.Root, cfg.Address, snapshotter, cfg.Namespace, cfg.Rootless, cfg.Labels, dns, nc, common.config.Workers.Containerd.ApparmorProfile, parallelismSem, common.traceSocket, userns, cfg.Runtimes, cfg.DefaultRuntime, ctd.WithTimeout(60*time.Second))
 	if err != nil {
 		return nil, err
 	}
 	opt.GCPolicy = getGCPolicy(cfg.GCConfig, common.config.Root)
+	opt.DiskQuota = cfg.DiskQuota
+	opt.TenantDiskQuota = tenantDiskQuota(common.config.Tenancy)
 	opt.RegistryHosts = resolverFunc(common.config)
 
 	if platformsStr := cfg.Platforms; len(platformsStr) != 0 {
@@ -34,7 +34,9 @@ import (
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/util/network/cniprovider"
 	"github.com/moby/buildkit/util/network/netproviders"
+	"github.com/moby/buildkit/util/network/slirpprovider"
 	"github.com/moby/buildkit/util/resolver"
+	"github.com/moby/buildkit/util/rootless/diag"
 	"github.com/moby/buildkit/worker"
 	"github.com/moby/buildkit/worker/base"
 	"github.com/moby/buildkit/worker/runc"
@@ -87,7 +89,7 @@ func init() {
 		},
 		cli.StringFlag{
 			Name:  "oci-worker-net",
-			Usage: "worker network type (auto, cni or host)",
+			Usage: "worker network type (auto, cni, slirp4netns or host)",
 			Value: defaultConf.Workers.OCI.NetworkConfig.Mode,
 		},
 		cli.StringFlag{
@@ -149,6 +151,11 @@ func init() {
 		}(),
 		Hidden: len(defaultConf.Workers.OCI.GCPolicy) != 0,
 	})
+	flags = append(flags, cli.Int64Flag{
+		Name:  "oci-worker-disk-quota",
+		Usage: "Maximum size of the build cache, builds exceeding it run garbage collection and fail if it is still exceeded (MB)",
+		Value: defaultConf.Workers.OCI.DiskQuota / 1e6,
+	})
 
 	registerWorkerInitializer(
 		workerInitializer{
@@ -213,6 +220,10 @@ func applyOCIFlags(c *cli.Context, cfg *config.Config) error {
 		cfg.Workers.OCI.GCKeepStorage = c.GlobalInt64("oci-worker-gc-keepstorage") * 1e6
 	}
 
+	if c.GlobalIsSet("oci-worker-disk-quota") {
+		cfg.Workers.OCI.DiskQuota = c.GlobalInt64("oci-worker-disk-quota") * 1e6
+	}
+
 	if c.GlobalIsSet("oci-worker-net") {
 		cfg.Workers.OCI.NetworkConfig.Mode = c.GlobalString("oci-worker-net")
 	}
@@ -259,8 +270,11 @@ func ociWorkerInitializer(c *cli.Context, common workerInitializerOpt) ([]worker
 
 	if cfg.Rootless {
 		logrus.Debugf("running in rootless mode")
+		for _, c := range diag.Limitations(diag.Detect(context.TODO(), rootlessDiagOpt(common.config))) {
+			logrus.Warnf("rootless: %s: %s", c.Name, c.Message)
+		}
 		if common.config.Workers.OCI.NetworkConfig.Mode == "auto" {
-			common.config.Workers.OCI.NetworkConfig.Mode = "host"
+			common.config.Workers.OCI.NetworkConfig.Mode = rootlessNetworkMode(cfg.NetworkConfig)
 		}
 	}
 
@@ -282,6 +296,15 @@ func ociWorkerInitializer(c *cli.Context, common workerInitializerOpt) ([]worker
 			ConfigPath: common.config.Workers.OCI.CNIConfigPath,
 			BinaryDir:  common.config.Workers.OCI.CNIBinaryPath,
 		},
+		CNINetworks: common.config.Workers.OCI.CNINetworks,
+		Pools:       networkPools(common.config.Workers.OCI.NetworkPools),
+		Slirp4netns: slirpprovider.Opt{
+			Binary: common.config.Workers.OCI.Slirp4netnsBinary,
+			Root:   common.config.Root,
+		},
+	}
+	if common.config.Workers.OCI.Bypass4netns {
+		nc.Slirp4netns.Bypass4netns = "bypass4netns"
 	}
 
 	var parallelismSem *semaphore.Weighted
@@ -289,11 +312,21 @@ func ociWorkerInitializer(c *cli.Context, common workerInitializerOpt) ([]worker
 		parallelismSem = semaphore.NewWeighted(int64(cfg.MaxParallelism))
 	}
 
-	opt, err := runc.NewWorkerOpt(common.config.Root, snFactory, cfg.Rootless, processMode, cfg.Labels, idmapping, nc, dns, cfg.Binary, cfg.ApparmorProfile, parallelismSem, common.traceSocket, cfg.DefaultCgroupParent)
+	userns, err := usernsRemapper(cfg.UsernsRemap, cfg.Rootless)
+	if err != nil {
+		return nil, err
+	}
+	if userns != nil && idmapping != nil {
+		return nil, errors.New("usernsRemap can't be used with userRemapUnsupported")
+	}
+
+	opt, err := runc.NewWorkerOpt(common.config.Root, snFactory, cfg.Rootless, processMode, cfg.Labels, idmapping, nc, dns, cfg.Binary, cfg.ApparmorProfile, parallelismSem, common.traceSocket, cfg.DefaultCgroupParent, userns, cfg.Runtimes, cfg.DefaultRuntime)
 	if err != nil {
 		return nil, err
 	}
 	opt.GCPolicy = getGCPolicy(cfg.GCConfig, common.config.Root)
+	opt.DiskQuota = cfg.DiskQuota
+	opt.TenantDiskQuota = tenantDiskQuota(common.config.Tenancy)
 	opt.RegistryHosts = hosts
 
 	if platformsStr := cfg.Platforms; len(platformsStr) != 0 {
@@ -310,6 +343,20 @@ func ociWorkerInitializer(c *cli.Context, common workerInitializerOpt) ([]worker
 	return []worker.Worker{w}, nil
 }
 
+// rootlessNetworkMode returns the network mode used for "auto" in rootless
+// mode: slirp4netns if it is installed, the network of the daemon otherwise.
+func rootlessNetworkMode(cfg config.NetworkConfig) string {
+	bin := cfg.Slirp4netnsBinary
+	if bin == "" {
+		bin = "slirp4netns"
+	}
+	if _, err := exec.LookPath(bin); err != nil {
+		logrus.Warnf("%s not found, the build steps share the network namespace of the daemon: install slirp4netns to isolate their network", bin)
+		return "host"
+	}
+	return "slirp4netns"
+}
+
 func snapshotterFactory(commonRoot string, cfg config.OCIConfig, sm *session.Manager, hosts docker.RegistryHosts) (runc.SnapshotterFactory, error) {
 	var (
 		name    = cfg.Snapshotter
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/util/metrics"
+	"github.com/moby/buildkit/worker"
+	"github.com/sirupsen/logrus"
+)
+
+func setupMetricsHandler(addr string) error {
+	m := http.NewServeMux()
+	m.Handle("/metrics", metrics.Handler())
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	logrus.Debugf("metrics handler listening at %s", addr)
+	go http.Serve(l, m)
+	return nil
+}
+
+func registerMetricsCollectors(sm *session.Manager, wc *worker.Controller) error {
+	if err := metrics.Register(metrics.NewSessionsCollector(sm.Count)); err != nil {
+		return err
+	}
+	return metrics.Register(metrics.NewDiskUsageCollector(func(ctx context.Context) (map[string]int64, error) {
+		workers, err := wc.List()
+		if err != nil {
+			return nil, err
+		}
+		usage := make(map[string]int64, len(workers))
+		for _, w := range workers {
+			du, err := w.DiskUsage(ctx, client.DiskUsageInfo{})
+			if err != nil {
+				return nil, err
+			}
+			var size int64
+			for _, r := range du {
+				size += r.Size
+			}
+			usage[w.ID()] = size
+		}
+		return usage, nil
+	}))
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/containerd/containerd/remotes/docker"
+	"github.com/moby/buildkit/cmd/buildkitd/config"
+	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/resolver"
+	"github.com/pkg/errors"
+)
+
+var (
+	registryHostsOnce sync.Once
+	registryHosts     *resolver.ReloadableRegistryHosts
+)
+
+// resolverFunc returns the registry hosts of the configuration. The workers
+// and the cache backends share them, so reloadConfig updates all of them.
+func resolverFunc(cfg *config.Config) docker.RegistryHosts {
+	registryHostsOnce.Do(func() {
+		registryHosts = resolver.NewReloadableRegistryHosts(cfg.Registries)
+	})
+	return registryHosts.RegistryHosts
+}
+
+// reloadConfig reloads the registry configuration from the configuration
+// file. Other settings are only applied on restart. A missing file reloads
+// the default configuration, the same way as on startup.
+func reloadConfig(ctx context.Context, path string) ([]string, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		bklog.G(ctx).Warnf("configuration file %s not found, reloading the default configuration", path)
+	}
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	setDefaultConfig(&cfg)
+	registries, err := registryHosts.Reload(cfg.Registries)
+	if err != nil {
+		return nil, err
+	}
+	bklog.G(ctx).Infof("reloaded registry configuration from %s", path)
+	return registries, nil
+}
+
+// reloadOnSignal reloads the configuration on SIGHUP until ctx is done.
+func reloadOnSignal(ctx context.Context, path string) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	defer signal.Stop(ch)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ch:
+			if _, err := reloadConfig(ctx, path); err != nil {
+				bklog.G(ctx).Errorf("failed to reload configuration: %+v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moby/buildkit/cmd/buildkitd/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadConfig(t *testing.T) {
+	resolverFunc(&config.Config{})
+
+	path := filepath.Join(t.TempDir(), "buildkitd.toml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+[registry."docker.io"]
+  mirrors = ["mirror.example.com"]
+`), 0600))
+
+	registries, err := reloadConfig(context.TODO(), path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"docker.io"}, registries)
+
+	// a removed file reloads the defaults
+	require.NoError(t, os.Remove(path))
+	registries, err = reloadConfig(context.TODO(), path)
+	require.NoError(t, err)
+	require.Empty(t, registries)
+
+	require.NoError(t, os.WriteFile(path, []byte(`[registry`), 0600))
+	_, err = reloadConfig(context.TODO(), path)
+	require.Error(t, err)
+}
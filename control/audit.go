@@ -0,0 +1,72 @@
+package control
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/moby/buildkit/util/audit"
+	"github.com/moby/buildkit/util/bklog"
+)
+
+// auditSolve returns the usage collector of the build and the function
+// writing its audit record once it completes. The usage is nil if auditing
+// is disabled.
+func (c *Controller) auditSolve(ctx context.Context, req *controlapi.SolveRequest) (*audit.Usage, func(error)) {
+	if c.opt.AuditLogger == nil {
+		return nil, func(error) {}
+	}
+	usage := &audit.Usage{}
+	rec := audit.Record{
+		Started:  time.Now(),
+		Action:   audit.ActionSolve,
+		Client:   audit.ClientFromContext(ctx),
+		Ref:      req.Ref,
+		Frontend: req.Frontend,
+	}
+	for _, ex := range req.Exporters {
+		push, _ := strconv.ParseBool(ex.Attrs["push"])
+		rec.Exporters = append(rec.Exporters, audit.Exporter{
+			Type: ex.Type,
+			Name: ex.Attrs["name"],
+			Push: push,
+		})
+	}
+	return usage, func(err error) {
+		rec.Time = time.Now()
+		usage.Fill(&rec)
+		if err != nil {
+			rec.Error = err.Error()
+		}
+		c.logAudit(ctx, rec)
+	}
+}
+
+// auditPrune writes the audit record of a prune.
+func (c *Controller) auditPrune(ctx context.Context, req *controlapi.PruneRequest, started time.Time, pruned audit.Prune, err error) {
+	if c.opt.AuditLogger == nil {
+		return
+	}
+	pruned.Filters = req.Filter
+	pruned.All = req.All
+	pruned.KeepDuration = time.Duration(req.KeepDuration)
+	pruned.KeepBytes = req.KeepBytes
+	rec := audit.Record{
+		Time:    time.Now(),
+		Started: started,
+		Action:  audit.ActionPrune,
+		Client:  audit.ClientFromContext(ctx),
+		Prune:   &pruned,
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	c.logAudit(ctx, rec)
+}
+
+func (c *Controller) logAudit(ctx context.Context, rec audit.Record) {
+	if err := c.opt.AuditLogger.Log(rec); err != nil {
+		bklog.G(ctx).Errorf("failed to write audit record: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package control
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/moby/buildkit/util/tenant"
+	"github.com/pkg/errors"
+)
+
+// activeBuild is a build that can be canceled with the Cancel API.
+type activeBuild struct {
+	cancel func()
+	tenant string
+
+	mu       sync.Mutex
+	canceled bool
+	reason   string
+}
+
+// startBuild registers the build so it can be canceled with Cancel until
+// finishBuild is called. The build runs with the returned context.
+func (c *Controller) startBuild(ctx context.Context, ref string) (context.Context, *activeBuild, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	b := &activeBuild{cancel: cancel, tenant: tenant.FromContext(ctx)}
+
+	c.buildsMu.Lock()
+	defer c.buildsMu.Unlock()
+	if _, ok := c.builds[ref]; ok {
+		cancel()
+		return nil, nil, errors.Errorf("build %s already exists", ref)
+	}
+	c.builds[ref] = b
+	close(c.buildsStarted)
+	c.buildsStarted = make(chan struct{})
+	return ctx, b, nil
+}
+
+// finishBuild unregisters the build and returns its error, with the reason
+// of the cancellation if it was canceled.
+func (c *Controller) finishBuild(ref string, b *activeBuild, err error) error {
+	c.buildsMu.Lock()
+	delete(c.builds, ref)
+	c.buildsMu.Unlock()
+	b.cancel()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err != nil && b.canceled && b.reason != "" {
+		return errors.Wrapf(err, "build canceled: %s", b.reason)
+	}
+	return err
+}
+
+// Cancel cancels a running build. During the grace period the build is only
+// stopped: no new step is started, the running processes receive SIGTERM
+// and running exporters can finish. The build is canceled once the grace
+// period has passed.
+func (c *Controller) Cancel(ctx context.Context, req *controlapi.CancelRequest) (*controlapi.CancelResponse, error) {
+	if req.GracePeriod < 0 {
+		return nil, errors.Errorf("invalid grace period %s", time.Duration(req.GracePeriod))
+	}
+	c.buildsMu.Lock()
+	b, ok := c.builds[req.Ref]
+	c.buildsMu.Unlock()
+	if !ok || !tenantAllowed(ctx, b.tenant) {
+		return nil, errors.Errorf("no running build %s", req.Ref)
+	}
+
+	b.mu.Lock()
+	if b.canceled {
+		b.mu.Unlock()
+		return nil, errors.Errorf("build %s is already canceled", req.Ref)
+	}
+	b.canceled = true
+	b.reason = req.Reason
+	b.mu.Unlock()
+
+	// stopping the build also reports the cancellation in its progress
+	if err := c.solver.Stop(req.Ref, req.Reason); err != nil || req.GracePeriod == 0 {
+		b.cancel()
+		return &controlapi.CancelResponse{}, nil
+	}
+	time.AfterFunc(time.Duration(req.GracePeriod), b.cancel)
+	return &controlapi.CancelResponse{}, nil
+}
@@ -2,34 +2,49 @@ package control
 
 import (
 	"context"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/moby/buildkit/util/audit"
+	"github.com/moby/buildkit/util/bandwidth"
 	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/buildinfo"
 
 	controlapi "github.com/moby/buildkit/api/services/control"
 	apitypes "github.com/moby/buildkit/api/types"
 	"github.com/moby/buildkit/cache/remotecache"
 	"github.com/moby/buildkit/client"
 	controlgateway "github.com/moby/buildkit/control/gateway"
+	"github.com/moby/buildkit/executor"
+	"github.com/moby/buildkit/executor/oci"
 	"github.com/moby/buildkit/exporter"
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
 	"github.com/moby/buildkit/frontend"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/grpchijack"
 	"github.com/moby/buildkit/solver"
 	"github.com/moby/buildkit/solver/llbsolver"
 	"github.com/moby/buildkit/solver/pb"
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
+	"github.com/moby/buildkit/util/archutil"
 	"github.com/moby/buildkit/util/imageutil"
+	"github.com/moby/buildkit/util/metrics"
+	"github.com/moby/buildkit/util/rootless/diag"
+	"github.com/moby/buildkit/util/tenant"
 	"github.com/moby/buildkit/util/throttle"
 	"github.com/moby/buildkit/util/tracing/transform"
 	"github.com/moby/buildkit/worker"
 	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	tracev1 "go.opentelemetry.io/proto/otlp/collector/trace/v1"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -42,6 +57,42 @@ type Opt struct {
 	ResolveCacheImporterFuncs map[string]remotecache.ResolveCacheImporterFunc
 	Entitlements              []string
 	TraceCollector            sdktrace.SpanExporter
+	// HistoryDB stores the records of completed builds. The build history
+	// is disabled if it is nil.
+	HistoryDB *bolt.DB
+	// ResourceLimits are the default limits of the exec containers of
+	// builds that don't set their own.
+	ResourceLimits executor.ResourceLimits
+	// BandwidthLimits are the default transfer rates of builds that don't
+	// set their own.
+	BandwidthLimits bandwidth.Limits
+	// TenantGCPolicy are the gc policies of the tenants, run with the
+	// policies of the workers.
+	TenantGCPolicy []client.PruneInfo
+	// ShareTenantImages shares the cache of the image sources between
+	// tenants. The cache of the other operations is never shared.
+	ShareTenantImages bool
+	// Parallelism limits the operations run in parallel across builds,
+	// shared by build weight. It can be changed with SetParallelism.
+	Parallelism llbsolver.Parallelism
+	// SourcePolicies deny or rewrite the sources of all builds, after the
+	// source policies of the requests.
+	SourcePolicies []*spb.Policy
+	// AllowedSockets are the IDs of the client sockets builds can forward to
+	// their exec operations, as path.Match patterns.
+	AllowedSockets []string
+	// BuildInfoAttrs selects the frontend attributes recorded in the build
+	// info, buildinfo.DefaultAttrsPolicy if nil.
+	BuildInfoAttrs *buildinfo.AttrsPolicy
+	// ReloadConfig reloads the registry configuration of the daemon and
+	// returns the configured registries.
+	ReloadConfig func(context.Context) ([]string, error)
+	// AuditLogger writes the audit records of the builds and the prunes.
+	// Auditing is disabled if it is nil.
+	AuditLogger audit.Logger
+	// RootlessInfo returns whether the daemon runs in rootless mode and the
+	// detection of the features it uses.
+	RootlessInfo func(context.Context) (bool, []diag.Check)
 }
 
 type Controller struct { // TODO: ControlService
@@ -53,6 +104,14 @@ type Controller struct { // TODO: ControlService
 	gatewayForwarder *controlgateway.GatewayForwarder
 	throttledGC      func()
 	gcmu             sync.Mutex
+	gcRuns           []*controlapi.GCRun
+	gcRunsMu         sync.Mutex
+	detached         map[string]*detachedBuild
+	detachedMu       sync.Mutex
+	builds           map[string]*activeBuild
+	buildsStarted    chan struct{}
+	buildsMu         sync.Mutex
+	history          *historyStore
 	*tracev1.UnimplementedTraceServiceServer
 }
 
@@ -61,7 +120,7 @@ func NewController(opt Opt) (*Controller, error) {
 
 	gatewayForwarder := controlgateway.NewGatewayForwarder()
 
-	solver, err := llbsolver.New(opt.WorkerController, opt.Frontends, cache, opt.ResolveCacheImporterFuncs, gatewayForwarder, opt.SessionManager, opt.Entitlements)
+	solver, err := llbsolver.New(opt.WorkerController, opt.Frontends, cache, opt.ResolveCacheImporterFuncs, gatewayForwarder, opt.SessionManager, opt.Entitlements, opt.Parallelism, opt.SourcePolicies, opt.AllowedSockets, opt.BuildInfoAttrs)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create solver")
 	}
@@ -71,6 +130,16 @@ func NewController(opt Opt) (*Controller, error) {
 		solver:           solver,
 		cache:            cache,
 		gatewayForwarder: gatewayForwarder,
+		detached:         map[string]*detachedBuild{},
+		builds:           map[string]*activeBuild{},
+		buildsStarted:    make(chan struct{}),
+	}
+	c.solver.SetNestedBuildServer(newNestedServer(c))
+	if opt.HistoryDB != nil {
+		c.history, err = newHistoryStore(opt.HistoryDB)
+		if err != nil {
+			return nil, err
+		}
 	}
 	c.throttledGC = throttle.After(time.Minute, c.gc)
 
@@ -78,6 +147,10 @@ func NewController(opt Opt) (*Controller, error) {
 		time.AfterFunc(time.Second, c.throttledGC)
 	}()
 
+	if err := c.scheduleGC(context.TODO()); err != nil {
+		return nil, err
+	}
+
 	return c, nil
 }
 
@@ -103,6 +176,10 @@ func (c *Controller) DiskUsage(ctx context.Context, r *controlapi.DiskUsageReque
 		}
 
 		for _, r := range du {
+			// tenants only see their own records
+			if t := tenant.FromContext(ctx); t != "" && r.Tenant != t {
+				continue
+			}
 			resp.Record = append(resp.Record, &controlapi.UsageRecord{
 				// TODO: add worker info
 				ID:          r.ID,
@@ -116,6 +193,7 @@ func (c *Controller) DiskUsage(ctx context.Context, r *controlapi.DiskUsageReque
 				LastUsedAt:  r.LastUsedAt,
 				RecordType:  string(r.RecordType),
 				Shared:      r.Shared,
+				Tenant:      r.Tenant,
 			})
 		}
 	}
@@ -156,6 +234,7 @@ func (c *Controller) Prune(req *controlapi.PruneRequest, stream controlapi.Contr
 					All:          req.All,
 					KeepDuration: time.Duration(req.KeepDuration),
 					KeepBytes:    req.KeepBytes,
+					Tenant:       tenant.FromContext(ctx),
 				})
 			})
 		}(w)
@@ -168,9 +247,13 @@ func (c *Controller) Prune(req *controlapi.PruneRequest, stream controlapi.Contr
 		return eg.Wait()
 	})
 
+	started := time.Now()
+	var pruned audit.Prune
 	eg2.Go(func() error {
 		for r := range ch {
 			didPrune = true
+			pruned.Records++
+			pruned.Size += r.Size
 			if err := stream.Send(&controlapi.UsageRecord{
 				// TODO: add worker info
 				ID:          r.ID,
@@ -184,6 +267,7 @@ func (c *Controller) Prune(req *controlapi.PruneRequest, stream controlapi.Contr
 				LastUsedAt:  r.LastUsedAt,
 				RecordType:  string(r.RecordType),
 				Shared:      r.Shared,
+				Tenant:      r.Tenant,
 			}); err != nil {
 				return err
 			}
@@ -191,7 +275,9 @@ func (c *Controller) Prune(req *controlapi.PruneRequest, stream controlapi.Contr
 		return nil
 	})
 
-	return eg2.Wait()
+	err = eg2.Wait()
+	c.auditPrune(stream.Context(), req, started, pruned, err)
+	return err
 }
 
 func (c *Controller) Export(ctx context.Context, req *tracev1.ExportTraceServiceRequest) (*tracev1.ExportTraceServiceResponse, error) {
@@ -205,6 +291,35 @@ func (c *Controller) Export(ctx context.Context, req *tracev1.ExportTraceService
 	return &tracev1.ExportTraceServiceResponse{}, nil
 }
 
+// withSelectedOutputs returns the frontend attributes with the named build
+// outputs selected by the exporters, so that the frontend doesn't build the
+// outputs that are not exported. The attributes are returned as they are if
+// no output is selected or if an exporter exports all the outputs.
+func withSelectedOutputs(attrs map[string]string, outputs []string) map[string]string {
+	var names []string
+	seen := map[string]struct{}{}
+	for _, name := range outputs {
+		if name == exptypes.OutputAll {
+			return attrs
+		}
+		if _, ok := seen[name]; ok || name == "" {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return attrs
+	}
+	sort.Strings(names)
+	out := make(map[string]string, len(attrs)+1)
+	for k, v := range attrs {
+		out[k] = v
+	}
+	out[exptypes.FrontendOutputsAttr] = strings.Join(names, ",")
+	return out
+}
+
 func translateLegacySolveRequest(req *controlapi.SolveRequest) error {
 	// translates ExportRef and ExportAttrs to new Exports (v0.4.0)
 	if legacyExportRef := req.Cache.ExportRefDeprecated; legacyExportRef != "" {
@@ -231,12 +346,28 @@ func translateLegacySolveRequest(req *controlapi.SolveRequest) error {
 		req.Cache.Imports = append(req.Cache.Imports, im)
 	}
 	req.Cache.ImportRefsDeprecated = nil
+	// translates single exporter to multiple exporters
+	if req.ExporterDeprecated != "" && len(req.Exporters) == 0 {
+		req.Exporters = []*controlapi.Exporter{{
+			Type:  req.ExporterDeprecated,
+			Attrs: req.ExporterAttrsDeprecated,
+		}}
+		req.ExporterDeprecated = ""
+		req.ExporterAttrsDeprecated = nil
+	}
 	return nil
 }
 
-func (c *Controller) Solve(ctx context.Context, req *controlapi.SolveRequest) (*controlapi.SolveResponse, error) {
+func (c *Controller) Solve(ctx context.Context, req *controlapi.SolveRequest) (solveResp *controlapi.SolveResponse, retErr error) {
+	if req.Detach {
+		return c.solveDetached(ctx, req)
+	}
+	req.Session = tenantSessionID(ctx, req.Session)
+
 	atomic.AddInt64(&c.buildCount, 1)
 	defer atomic.AddInt64(&c.buildCount, -1)
+	metrics.ActiveBuilds.Inc()
+	defer metrics.ActiveBuilds.Dec()
 
 	// This method registers job ID in solver.Solve. Make sure there are no blocking calls before that might delay this.
 
@@ -244,32 +375,65 @@ func (c *Controller) Solve(ctx context.Context, req *controlapi.SolveRequest) (*
 		return nil, err
 	}
 
+	if err := oci.ValidateUsernsMode(req.Userns); err != nil {
+		return nil, err
+	}
+
+	if req.DryRun {
+		return c.dryRun(ctx, req)
+	}
+
+	if c.history != nil {
+		finish := c.history.record(ctx, c.solver.Status, req)
+		defer func() {
+			finish(solveResp, retErr)
+		}()
+	}
+
+	usage, finishAudit := c.auditSolve(ctx, req)
+	defer func() {
+		finishAudit(retErr)
+	}()
+
 	defer func() {
 		time.AfterFunc(time.Second, c.throttledGC)
 	}()
 
-	var expi exporter.ExporterInstance
 	// TODO: multiworker
 	// This is actually tricky, as the exporter should come from the worker that has the returned reference. We may need to delay this so that the solver loads this.
 	w, err := c.opt.WorkerController.GetDefault()
 	if err != nil {
 		return nil, err
 	}
-	if req.Exporter != "" {
-		exp, err := w.Exporter(req.Exporter, c.opt.SessionManager)
+
+	expis := make([]exporter.ExporterInstance, 0, len(req.Exporters))
+	outputs := make([]string, 0, len(req.Exporters))
+	for i, ex := range req.Exporters {
+		exp, err := w.Exporter(ex.Type, c.opt.SessionManager)
 		if err != nil {
 			return nil, err
 		}
-		expi, err = exp.Resolve(ctx, req.ExporterAttrs)
+		// the build output is selected by the solver, not the exporter
+		attrs := make(map[string]string, len(ex.Attrs))
+		for k, v := range ex.Attrs {
+			if k != exptypes.OutputAttr {
+				attrs[k] = v
+			}
+		}
+		expi, err := exp.Resolve(ctx, i, attrs)
 		if err != nil {
 			return nil, err
 		}
+		expis = append(expis, expi)
+		outputs = append(outputs, ex.Attrs[exptypes.OutputAttr])
 	}
+	frontendAttrs := withSelectedOutputs(req.FrontendAttrs, outputs)
 
 	var (
-		cacheExporter   remotecache.Exporter
-		cacheExportMode solver.CacheExportMode
-		cacheImports    []frontend.CacheOptionsEntry
+		cacheExporter    remotecache.Exporter
+		cacheExportMode  solver.CacheExportMode
+		cacheExportEntry *frontend.CacheOptionsEntry
+		cacheImports     []frontend.CacheOptionsEntry
 	)
 	if len(req.Cache.Exports) > 1 {
 		// TODO(AkihiroSuda): this should be fairly easy
@@ -286,6 +450,10 @@ func (c *Controller) Solve(ctx context.Context, req *controlapi.SolveRequest) (*
 		if err != nil {
 			return nil, err
 		}
+		cacheExportEntry = &frontend.CacheOptionsEntry{
+			Type:  e.Type,
+			Attrs: e.Attrs,
+		}
 		if exportMode, supported := parseCacheExportMode(e.Attrs["mode"]); !supported {
 			bklog.G(ctx).Debugf("skipping invalid cache export mode: %s", e.Attrs["mode"])
 		} else {
@@ -299,17 +467,34 @@ func (c *Controller) Solve(ctx context.Context, req *controlapi.SolveRequest) (*
 		})
 	}
 
+	if req.Ref != "" {
+		var b *activeBuild
+		ctx, b, err = c.startBuild(ctx, req.Ref)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			retErr = c.finishBuild(req.Ref, b, retErr)
+		}()
+	}
+
+	res := c.resources(ctx, req)
+	res.Usage = usage
+
 	resp, err := c.solver.Solve(ctx, req.Ref, req.Session, frontend.SolveRequest{
 		Frontend:       req.Frontend,
 		Definition:     req.Definition,
-		FrontendOpt:    req.FrontendAttrs,
+		FrontendOpt:    frontendAttrs,
 		FrontendInputs: req.FrontendInputs,
 		CacheImports:   cacheImports,
+		SourcePolicies: sourcePolicies(req),
 	}, llbsolver.ExporterRequest{
-		Exporter:        expi,
-		CacheExporter:   cacheExporter,
-		CacheExportMode: cacheExportMode,
-	}, req.Entitlements)
+		Exporters:        expis,
+		Outputs:          outputs,
+		CacheExporter:    cacheExporter,
+		CacheExportMode:  cacheExportMode,
+		CacheExportEntry: cacheExportEntry,
+	}, req.Entitlements, res)
 	if err != nil {
 		return nil, err
 	}
@@ -318,7 +503,101 @@ func (c *Controller) Solve(ctx context.Context, req *controlapi.SolveRequest) (*
 	}, nil
 }
 
+func sourcePolicies(req *controlapi.SolveRequest) []*spb.Policy {
+	if req.SourcePolicy == nil {
+		return nil
+	}
+	return []*spb.Policy{req.SourcePolicy}
+}
+
+// resources returns the resources of the build, with the default limits for
+// the limits the request does not set, its DNS config, its user namespace
+// mode and its OCI runtime.
+func (c *Controller) resources(ctx context.Context, req *controlapi.SolveRequest) llbsolver.Resources {
+	limits := c.opt.ResourceLimits
+	if r := req.Resources; r != nil {
+		if r.CPU != 0 {
+			limits.CPU = r.CPU
+		}
+		if r.Memory != 0 {
+			limits.Memory = r.Memory
+		}
+		if r.Pids != 0 {
+			limits.Pids = r.Pids
+		}
+	}
+	res := llbsolver.Resources{Weight: int(req.Weight)}
+	if limits != (executor.ResourceLimits{}) {
+		res.Limits = &limits
+	}
+	if dns := req.DNS; dns != nil {
+		res.DNS = &executor.DNSConfig{
+			Nameservers:   dns.Nameservers,
+			Options:       dns.Options,
+			SearchDomains: dns.SearchDomains,
+		}
+	}
+	res.Userns = req.Userns
+	res.Runtime = req.Runtime
+	res.Tenant = tenant.FromContext(ctx)
+	res.ShareTenantImages = c.opt.ShareTenantImages
+	res.Bandwidth = c.opt.BandwidthLimits
+	if b := req.Bandwidth; b != nil {
+		if b.Context != 0 {
+			res.Bandwidth.Context = b.Context
+		}
+		if b.Push != 0 {
+			res.Bandwidth.Push = b.Push
+		}
+		if b.Pull != 0 {
+			res.Bandwidth.Pull = b.Pull
+		}
+	}
+	return res
+}
+
+func (c *Controller) dryRun(ctx context.Context, req *controlapi.SolveRequest) (*controlapi.SolveResponse, error) {
+	var cacheImports []frontend.CacheOptionsEntry
+	for _, im := range req.Cache.Imports {
+		cacheImports = append(cacheImports, frontend.CacheOptionsEntry{
+			Type:  im.Type,
+			Attrs: im.Attrs,
+		})
+	}
+
+	status, err := c.solver.DryRun(ctx, req.Ref, req.Session, frontend.SolveRequest{
+		Frontend:       req.Frontend,
+		Definition:     req.Definition,
+		CacheImports:   cacheImports,
+		SourcePolicies: sourcePolicies(req),
+	}, req.Entitlements)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &controlapi.SolveResponse{
+		CacheStatus: make([]*controlapi.VertexCacheStatus, 0, len(status)),
+	}
+	for _, s := range status {
+		resp.CacheStatus = append(resp.CacheStatus, &controlapi.VertexCacheStatus{
+			Digest:          s.Digest,
+			Name:            s.Name,
+			Cached:          s.Cached,
+			Uncertain:       s.Uncertain,
+			Local:           s.Local,
+			Remote:          s.Remote,
+			Key:             s.Key,
+			CacheMapDigests: s.CacheMapDigests,
+			Inputs:          s.Inputs,
+		})
+	}
+	return resp, nil
+}
+
 func (c *Controller) Status(req *controlapi.StatusRequest, stream controlapi.Control_StatusServer) error {
+	if err := c.checkBuildTenant(stream.Context(), req.Ref); err != nil {
+		return err
+	}
 	ch := make(chan *client.SolveStatus, 8)
 
 	eg, ctx := errgroup.WithContext(stream.Context())
@@ -346,6 +625,7 @@ func (c *Controller) Status(req *controlapi.StatusRequest, stream controlapi.Con
 						Error:         v.Error,
 						Cached:        v.Cached,
 						ProgressGroup: v.ProgressGroup,
+						CacheMiss:     cacheMissToPB(v.CacheMiss),
 					})
 				}
 				for _, v := range ss.Statuses {
@@ -402,6 +682,16 @@ func (c *Controller) Status(req *controlapi.StatusRequest, stream controlapi.Con
 }
 
 func (c *Controller) Session(stream controlapi.Control_SessionServer) error {
+	// the sessions of the tenants are scoped to their tenant
+	if ctx := stream.Context(); tenant.FromContext(ctx) != "" {
+		var err error
+		stream, err = scopeSession(stream, func(id string) string {
+			return tenantSessionID(ctx, id)
+		})
+		if err != nil {
+			return err
+		}
+	}
 	bklog.G(stream.Context()).Debugf("session started")
 
 	conn, closeCh, opts := grpchijack.Hijack(stream)
@@ -418,6 +708,35 @@ func (c *Controller) Session(stream controlapi.Control_SessionServer) error {
 	return err
 }
 
+// sessionIDHeader is the metadata of the session requests holding the ID of
+// the session.
+const sessionIDHeader = "x-docker-expose-session-uuid"
+
+// scopeSession returns the session stream with the ID of the session
+// replaced by scope(ID).
+func scopeSession(stream controlapi.Control_SessionServer, scope func(string) string) (controlapi.Control_SessionServer, error) {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	md = md.Copy()
+	ids := md.Get(sessionIDHeader)
+	if len(ids) != 1 || ids[0] == "" {
+		return nil, errors.New("session requires a session ID")
+	}
+	md.Set(sessionIDHeader, scope(ids[0]))
+	return &scopedSessionStream{
+		Control_SessionServer: stream,
+		ctx:                   metadata.NewIncomingContext(stream.Context(), md),
+	}, nil
+}
+
+type scopedSessionStream struct {
+	controlapi.Control_SessionServer
+	ctx context.Context
+}
+
+func (s *scopedSessionStream) Context() context.Context {
+	return s.ctx
+}
+
 func (c *Controller) ListWorkers(ctx context.Context, r *controlapi.ListWorkersRequest) (*controlapi.ListWorkersResponse, error) {
 	resp := &controlapi.ListWorkersResponse{}
 	workers, err := c.opt.WorkerController.List(r.Filter...)
@@ -435,46 +754,70 @@ func (c *Controller) ListWorkers(ctx context.Context, r *controlapi.ListWorkersR
 	return resp, nil
 }
 
-func (c *Controller) gc() {
-	c.gcmu.Lock()
-	defer c.gcmu.Unlock()
+func (c *Controller) ListEmulators(ctx context.Context, r *controlapi.ListEmulatorsRequest) (*controlapi.ListEmulatorsResponse, error) {
+	resp := &controlapi.ListEmulatorsResponse{}
+	// the emulators can be registered or installed after the daemon started
+	for _, e := range archutil.Emulators(true) {
+		resp.Emulators = append(resp.Emulators, &controlapi.Emulator{
+			Platform: pb.PlatformFromSpec(e.Platform),
+			Type:     e.Type,
+			Path:     e.Path,
+		})
+	}
+	return resp, nil
+}
 
-	workers, err := c.opt.WorkerController.List()
+func (c *Controller) ReloadConfig(ctx context.Context, r *controlapi.ReloadConfigRequest) (*controlapi.ReloadConfigResponse, error) {
+	if c.opt.ReloadConfig == nil {
+		return nil, status.Error(codes.Unimplemented, "reloading the configuration is not supported")
+	}
+	registries, err := c.opt.ReloadConfig(ctx)
 	if err != nil {
-		return
+		return nil, err
 	}
+	return &controlapi.ReloadConfigResponse{Registries: registries}, nil
+}
 
-	eg, ctx := errgroup.WithContext(context.TODO())
+func (c *Controller) RootlessInfo(ctx context.Context, r *controlapi.RootlessInfoRequest) (*controlapi.RootlessInfoResponse, error) {
+	if c.opt.RootlessInfo == nil {
+		return nil, status.Error(codes.Unimplemented, "rootless diagnostics are not supported")
+	}
+	rootless, checks := c.opt.RootlessInfo(ctx)
+	resp := &controlapi.RootlessInfoResponse{Rootless: rootless}
+	for _, check := range checks {
+		resp.Checks = append(resp.Checks, &controlapi.RootlessCheck{
+			Name:    check.Name,
+			OK:      check.OK,
+			Message: check.Message,
+		})
+	}
+	return resp, nil
+}
 
-	var size int64
-	ch := make(chan client.UsageInfo)
-	done := make(chan struct{})
-	go func() {
-		for ui := range ch {
-			size += ui.Size
-		}
-		close(done)
-	}()
+func (c *Controller) GetParallelism(ctx context.Context, r *controlapi.GetParallelismRequest) (*controlapi.Parallelism, error) {
+	return toPBParallelism(c.solver.Parallelism()), nil
+}
 
-	for _, w := range workers {
-		func(w worker.Worker) {
-			eg.Go(func() error {
-				if policy := w.GCPolicy(); len(policy) > 0 {
-					return w.Prune(ctx, ch, policy...)
-				}
-				return nil
-			})
-		}(w)
+func (c *Controller) SetParallelism(ctx context.Context, r *controlapi.Parallelism) (*controlapi.Parallelism, error) {
+	if r.Max < 0 || r.Network < 0 || r.Exec < 0 || r.Speculative < 0 {
+		return nil, errors.Errorf("invalid parallelism %+v", r)
 	}
+	c.solver.SetParallelism(llbsolver.Parallelism{
+		Max:         int(r.Max),
+		Network:     int(r.Network),
+		Exec:        int(r.Exec),
+		Speculative: int(r.Speculative),
+	})
+	bklog.G(ctx).Infof("parallelism set to max=%d network=%d exec=%d speculative=%d", r.Max, r.Network, r.Exec, r.Speculative)
+	return toPBParallelism(c.solver.Parallelism()), nil
+}
 
-	err = eg.Wait()
-	close(ch)
-	if err != nil {
-		bklog.G(ctx).Errorf("gc error: %+v", err)
-	}
-	<-done
-	if size > 0 {
-		bklog.G(ctx).Debugf("gc cleaned up %d bytes", size)
+func toPBParallelism(p llbsolver.Parallelism) *controlapi.Parallelism {
+	return &controlapi.Parallelism{
+		Max:         int64(p.Max),
+		Network:     int64(p.Network),
+		Exec:        int64(p.Exec),
+		Speculative: int64(p.Speculative),
 	}
 }
 
@@ -492,10 +835,13 @@ func toPBGCPolicy(in []client.PruneInfo) []*apitypes.GCPolicy {
 	policy := make([]*apitypes.GCPolicy, 0, len(in))
 	for _, p := range in {
 		policy = append(policy, &apitypes.GCPolicy{
-			All:          p.All,
-			KeepBytes:    p.KeepBytes,
-			KeepDuration: int64(p.KeepDuration),
-			Filters:      p.Filter,
+			All:           p.All,
+			KeepBytes:     p.KeepBytes,
+			KeepDuration:  int64(p.KeepDuration),
+			Filters:       p.Filter,
+			ReservedSpace: p.ReservedSpace,
+			MinFreeSpace:  p.MinFreeSpace,
+			Schedule:      p.Schedule,
 		})
 	}
 	return policy
@@ -0,0 +1,28 @@
+package control
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithSelectedOutputs(t *testing.T) {
+	attrs := map[string]string{"target": "release"}
+
+	out := withSelectedOutputs(attrs, []string{"", "report", "bin", "report"})
+	require.Equal(t, "release", out["target"])
+	require.Equal(t, "bin,report", out[exptypes.FrontendOutputsAttr])
+	_, ok := attrs[exptypes.FrontendOutputsAttr]
+	require.False(t, ok)
+
+	// no selection is sent without named outputs
+	out = withSelectedOutputs(attrs, []string{""})
+	_, ok = out[exptypes.FrontendOutputsAttr]
+	require.False(t, ok)
+
+	// all the outputs are built when an exporter exports all of them
+	out = withSelectedOutputs(attrs, []string{"bin", exptypes.OutputAll})
+	_, ok = out[exptypes.FrontendOutputsAttr]
+	require.False(t, ok)
+}
@@ -0,0 +1,79 @@
+package control
+
+import (
+	"context"
+	"time"
+
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/moby/buildkit/util/tenant"
+	"github.com/moby/buildkit/util/tracing"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/peer"
+)
+
+// detachedResultTimeout is how long the result of a detached build is kept
+// after the build has completed.
+const detachedResultTimeout = time.Hour
+
+type detachedBuild struct {
+	tenant string
+	done   chan struct{}
+	resp   *controlapi.SolveResponse
+	err    error
+}
+
+// solveDetached runs the solve in the background so the build isn't canceled
+// when the client disconnects. The response is returned if the client waits
+// for it, otherwise it can be retrieved with Attach.
+func (c *Controller) solveDetached(ctx context.Context, req *controlapi.SolveRequest) (*controlapi.SolveResponse, error) {
+	if req.Ref == "" {
+		return nil, errors.New("detached build requires a ref")
+	}
+	d := &detachedBuild{tenant: tenant.FromContext(ctx), done: make(chan struct{})}
+
+	c.detachedMu.Lock()
+	if _, ok := c.detached[req.Ref]; ok {
+		c.detachedMu.Unlock()
+		return nil, errors.Errorf("build %s already exists", req.Ref)
+	}
+	c.detached[req.Ref] = d
+	c.detachedMu.Unlock()
+
+	r := *req
+	r.Detach = false
+	// the build outlives the request but keeps its tenant and its client
+	bctx := tenant.WithName(tracing.ContextWithSpanFromContext(context.Background(), ctx), tenant.FromContext(ctx))
+	if p, ok := peer.FromContext(ctx); ok {
+		bctx = peer.NewContext(bctx, p)
+	}
+	go func() {
+		d.resp, d.err = c.Solve(bctx, &r)
+		close(d.done)
+		time.AfterFunc(detachedResultTimeout, func() {
+			c.detachedMu.Lock()
+			delete(c.detached, req.Ref)
+			c.detachedMu.Unlock()
+		})
+	}()
+
+	return c.waitDetached(ctx, d)
+}
+
+func (c *Controller) Attach(ctx context.Context, req *controlapi.AttachRequest) (*controlapi.SolveResponse, error) {
+	c.detachedMu.Lock()
+	d, ok := c.detached[req.Ref]
+	c.detachedMu.Unlock()
+	if !ok || !tenantAllowed(ctx, d.tenant) {
+		return nil, errors.Errorf("no detached build %s", req.Ref)
+	}
+	return c.waitDetached(ctx, d)
+}
+
+func (c *Controller) waitDetached(ctx context.Context, d *detachedBuild) (*controlapi.SolveResponse, error) {
+	select {
+	case <-d.done:
+		return d.resp, d.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/moby/buildkit/client/buildid"
 	"github.com/moby/buildkit/frontend/gateway"
 	gwapi "github.com/moby/buildkit/frontend/gateway/pb"
+	"github.com/moby/buildkit/util/tenant"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
 )
@@ -15,12 +16,18 @@ import (
 type GatewayForwarder struct {
 	mu         sync.RWMutex
 	updateCond *sync.Cond
-	builds     map[string]gateway.LLBBridgeForwarder
+	builds     map[string]build
+}
+
+// build is a registered build with the tenant that started it.
+type build struct {
+	fwd    gateway.LLBBridgeForwarder
+	tenant string
 }
 
 func NewGatewayForwarder() *GatewayForwarder {
 	gwf := &GatewayForwarder{
-		builds: map[string]gateway.LLBBridgeForwarder{},
+		builds: map[string]build{},
 	}
 	gwf.updateCond = sync.NewCond(gwf.mu.RLocker())
 	return gwf
@@ -38,7 +45,7 @@ func (gwf *GatewayForwarder) RegisterBuild(ctx context.Context, id string, bridg
 		return errors.Errorf("build ID %s exists", id)
 	}
 
-	gwf.builds[id] = bridge
+	gwf.builds[id] = build{fwd: bridge, tenant: tenant.FromContext(ctx)}
 	gwf.updateCond.Broadcast()
 
 	return nil
@@ -76,12 +83,16 @@ func (gwf *GatewayForwarder) lookupForwarder(ctx context.Context) (gateway.LLBBr
 			return nil, errors.Errorf("no such job %s", bid)
 		default:
 		}
-		fwd, ok := gwf.builds[bid]
+		b, ok := gwf.builds[bid]
 		if !ok {
 			gwf.updateCond.Wait()
 			continue
 		}
-		return fwd, nil
+		// tenants can only forward to their own builds
+		if t := tenant.FromContext(ctx); t != "" && t != b.tenant {
+			return nil, errors.Errorf("no such job %s", bid)
+		}
+		return b.fwd, nil
 	}
 }
 
@@ -153,6 +164,22 @@ func (gwf *GatewayForwarder) StatFile(ctx context.Context, req *gwapi.StatFileRe
 	return fwd.StatFile(ctx, req)
 }
 
+func (gwf *GatewayForwarder) ReadFileStream(req *gwapi.ReadFileRequest, srv gwapi.LLBBridge_ReadFileStreamServer) error {
+	fwd, err := gwf.lookupForwarder(srv.Context())
+	if err != nil {
+		return errors.Wrap(err, "forwarding ReadFileStream")
+	}
+	return fwd.ReadFileStream(req, srv)
+}
+
+func (gwf *GatewayForwarder) ReadDirRecursive(req *gwapi.ReadDirRequest, srv gwapi.LLBBridge_ReadDirRecursiveServer) error {
+	fwd, err := gwf.lookupForwarder(srv.Context())
+	if err != nil {
+		return errors.Wrap(err, "forwarding ReadDirRecursive")
+	}
+	return fwd.ReadDirRecursive(req, srv)
+}
+
 func (gwf *GatewayForwarder) NewContainer(ctx context.Context, req *gwapi.NewContainerRequest) (*gwapi.NewContainerResponse, error) {
 	fwd, err := gwf.lookupForwarder(ctx)
 	if err != nil {
@@ -184,3 +211,11 @@ func (gwf *GatewayForwarder) Warn(ctx context.Context, req *gwapi.WarnRequest) (
 	}
 	return fwd.Warn(ctx, req)
 }
+
+func (gwf *GatewayForwarder) Prefetch(ctx context.Context, req *gwapi.PrefetchRequest) (*gwapi.PrefetchResponse, error) {
+	fwd, err := gwf.lookupForwarder(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "forwarding Prefetch")
+	}
+	return fwd.Prefetch(ctx, req)
+}
@@ -0,0 +1,36 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/buildkit/frontend/gateway"
+	"github.com/moby/buildkit/util/tenant"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+type testForwarder struct {
+	gateway.LLBBridgeForwarder
+}
+
+func TestLookupForwarderTenant(t *testing.T) {
+	gwf := NewGatewayForwarder()
+	fwd := &testForwarder{}
+	require.NoError(t, gwf.RegisterBuild(tenant.WithName(context.TODO(), "team-a"), "build1", fwd))
+
+	ctx := metadata.NewIncomingContext(context.TODO(), metadata.Pairs("buildkit-controlapi-buildid", "build1"))
+
+	f, err := gwf.lookupForwarder(tenant.WithName(ctx, "team-a"))
+	require.NoError(t, err)
+	require.Equal(t, fwd, f)
+
+	// requests without tenant can access all the builds
+	f, err = gwf.lookupForwarder(ctx)
+	require.NoError(t, err)
+	require.Equal(t, fwd, f)
+
+	_, err = gwf.lookupForwarder(tenant.WithName(ctx, "team-b"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no such job build1")
+}
@@ -0,0 +1,185 @@
+package control
+
+import (
+	"context"
+	"time"
+
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/cron"
+	"github.com/moby/buildkit/util/metrics"
+	"github.com/moby/buildkit/util/tenant"
+	"github.com/moby/buildkit/worker"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	gcTriggerBuild    = "build"
+	gcTriggerSchedule = "schedule"
+	gcTriggerAPI      = "api"
+
+	// maxGCRuns is the number of completed gc runs kept for ListGCRuns
+	maxGCRuns = 50
+)
+
+// RunGC runs the gc policies. The tenants only run their own policies, the
+// policies of the workers pruning the records of all the tenants.
+func (c *Controller) RunGC(ctx context.Context, r *controlapi.RunGCRequest) (*controlapi.GCRun, error) {
+	t := tenant.FromContext(ctx)
+	return c.runGC(ctx, gcTriggerAPI, func(p client.PruneInfo) bool {
+		return t == "" || p.Tenant == t
+	}), nil
+}
+
+func (c *Controller) ListGCRuns(ctx context.Context, r *controlapi.ListGCRunsRequest) (*controlapi.ListGCRunsResponse, error) {
+	c.gcRunsMu.Lock()
+	defer c.gcRunsMu.Unlock()
+	runs := make([]*controlapi.GCRun, len(c.gcRuns))
+	copy(runs, c.gcRuns)
+	return &controlapi.ListGCRunsResponse{Runs: runs}, nil
+}
+
+// gc runs the policies of the workers that are not scheduled. It is called
+// after builds.
+func (c *Controller) gc() {
+	c.runGC(context.TODO(), gcTriggerBuild, func(p client.PruneInfo) bool {
+		return p.Schedule == ""
+	})
+}
+
+// scheduleGC starts running the scheduled policies of the workers. Policies
+// with the same schedule run together.
+func (c *Controller) scheduleGC(ctx context.Context) error {
+	workers, err := c.opt.WorkerController.List()
+	if err != nil {
+		return err
+	}
+	schedules := map[string]cron.Schedule{}
+	for _, w := range workers {
+		for _, p := range c.gcPolicy(w) {
+			if p.Schedule == "" {
+				continue
+			}
+			if _, ok := schedules[p.Schedule]; ok {
+				continue
+			}
+			sched, err := cron.Parse(p.Schedule)
+			if err != nil {
+				bklog.G(ctx).Errorf("ignoring gc policy with invalid schedule: %v", err)
+				continue
+			}
+			schedules[p.Schedule] = sched
+		}
+	}
+	for spec, sched := range schedules {
+		go c.runScheduledGC(ctx, spec, sched)
+	}
+	return nil
+}
+
+func (c *Controller) runScheduledGC(ctx context.Context, spec string, sched cron.Schedule) {
+	for {
+		next := sched.Next(time.Now())
+		if next.IsZero() {
+			bklog.G(ctx).Warnf("gc schedule %q has no next run", spec)
+			return
+		}
+		t := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+		c.runGC(ctx, gcTriggerSchedule, func(p client.PruneInfo) bool {
+			return p.Schedule == spec
+		})
+	}
+}
+
+// runGC prunes the build cache of the workers with their policies matching
+// the filter and records the run.
+func (c *Controller) runGC(ctx context.Context, trigger string, filter func(client.PruneInfo) bool) *controlapi.GCRun {
+	c.gcmu.Lock()
+	defer c.gcmu.Unlock()
+
+	start := time.Now()
+	run := &controlapi.GCRun{
+		Trigger:   trigger,
+		StartedAt: &start,
+	}
+	defer func() {
+		completed := time.Now()
+		run.CompletedAt = &completed
+		c.addGCRun(run)
+		metrics.GCRuns.Inc()
+		metrics.GCDuration.Observe(completed.Sub(start).Seconds())
+	}()
+
+	workers, err := c.opt.WorkerController.List()
+	if err != nil {
+		run.Error = err.Error()
+		return run
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+
+	var size, records int64
+	ch := make(chan client.UsageInfo)
+	done := make(chan struct{})
+	go func() {
+		for ui := range ch {
+			size += ui.Size
+			records++
+		}
+		close(done)
+	}()
+
+	for _, w := range workers {
+		func(w worker.Worker) {
+			eg.Go(func() error {
+				var policy []client.PruneInfo
+				for _, p := range c.gcPolicy(w) {
+					if filter(p) {
+						policy = append(policy, p)
+					}
+				}
+				if len(policy) > 0 {
+					return w.Prune(ctx, ch, policy...)
+				}
+				return nil
+			})
+		}(w)
+	}
+
+	err = eg.Wait()
+	close(ch)
+	if err != nil {
+		bklog.G(ctx).Errorf("gc error: %+v", err)
+		run.Error = err.Error()
+	}
+	<-done
+	run.ReclaimedBytes = size
+	run.NumRecords = records
+	if size > 0 {
+		bklog.G(ctx).Debugf("gc cleaned up %d bytes", size)
+		metrics.GCReclaimedBytes.Add(float64(size))
+	}
+	return run
+}
+
+// gcPolicy returns the policies of the worker and of the tenants.
+func (c *Controller) gcPolicy(w worker.Worker) []client.PruneInfo {
+	policy := append([]client.PruneInfo{}, w.GCPolicy()...)
+	return append(policy, c.opt.TenantGCPolicy...)
+}
+
+func (c *Controller) addGCRun(run *controlapi.GCRun) {
+	c.gcRunsMu.Lock()
+	defer c.gcRunsMu.Unlock()
+	c.gcRuns = append(c.gcRuns, run)
+	if len(c.gcRuns) > maxGCRuns {
+		c.gcRuns = c.gcRuns[len(c.gcRuns)-maxGCRuns:]
+	}
+}
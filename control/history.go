@@ -0,0 +1,479 @@
+package control
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/tenant"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	historyBucket     = []byte("_history")
+	historyLogsBucket = []byte("_history_logs")
+	// historyIndexBucket indexes the records by creation time for pruning
+	// the oldest ones. Its sequence is the number of records.
+	historyIndexBucket = []byte("_history_index")
+)
+
+const (
+	// maxHistoryRecords is the number of builds kept in the history, older
+	// records are removed when new builds complete.
+	maxHistoryRecords = 1000
+	// maxHistoryLogSize limits the size of the progress stored for a build.
+	maxHistoryLogSize = 4 << 20
+)
+
+type historyStore struct {
+	db         *bolt.DB
+	maxRecords int
+}
+
+func newHistoryStore(db *bolt.DB) (*historyStore, error) {
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{historyBucket, historyLogsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		if tx.Bucket(historyIndexBucket) != nil {
+			return nil
+		}
+		// the records saved before the index was added are indexed once
+		idx, err := tx.CreateBucket(historyIndexBucket)
+		if err != nil {
+			return err
+		}
+		recs, err := readHistory(tx.Bucket(historyBucket), "")
+		if err != nil {
+			return err
+		}
+		for _, rec := range recs {
+			if err := idx.Put(historyIndexKey(rec), []byte(rec.Ref)); err != nil {
+				return err
+			}
+		}
+		return idx.SetSequence(uint64(len(recs)))
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to create history buckets")
+	}
+	return &historyStore{db: db, maxRecords: maxHistoryRecords}, nil
+}
+
+// historyIndexKey returns the key of the record in the index, sorting the
+// records by creation time.
+func historyIndexKey(rec *controlapi.BuildHistoryRecord) []byte {
+	var ts int64
+	if rec.CreatedAt != nil {
+		ts = rec.CreatedAt.UnixNano()
+	}
+	k := make([]byte, 8+len(rec.Ref))
+	binary.BigEndian.PutUint64(k, uint64(ts))
+	copy(k[8:], rec.Ref)
+	return k
+}
+
+func (h *historyStore) save(rec *controlapi.BuildHistoryRecord, logs []byte) error {
+	dt, err := rec.Marshal()
+	if err != nil {
+		return err
+	}
+	return h.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(historyBucket).Get([]byte(rec.Ref)) != nil {
+			if err := deleteHistory(tx, rec.Ref); err != nil {
+				return err
+			}
+		}
+		if err := tx.Bucket(historyBucket).Put([]byte(rec.Ref), dt); err != nil {
+			return err
+		}
+		if len(logs) > 0 {
+			if err := tx.Bucket(historyLogsBucket).Put([]byte(rec.Ref), logs); err != nil {
+				return err
+			}
+		}
+		idx := tx.Bucket(historyIndexBucket)
+		if err := idx.Put(historyIndexKey(rec), []byte(rec.Ref)); err != nil {
+			return err
+		}
+		if err := idx.SetSequence(idx.Sequence() + 1); err != nil {
+			return err
+		}
+		return h.prune(tx)
+	})
+}
+
+// prune removes the oldest records above maxRecords. Only the records that
+// are removed are read from the index.
+func (h *historyStore) prune(tx *bolt.Tx) error {
+	idx := tx.Bucket(historyIndexBucket)
+	n := int(idx.Sequence()) - h.maxRecords
+	if n <= 0 {
+		return nil
+	}
+	refs := make([]string, 0, n)
+	c := idx.Cursor()
+	for k, v := c.First(); k != nil && len(refs) < n; k, v = c.Next() {
+		refs = append(refs, string(v))
+	}
+	for _, ref := range refs {
+		if err := deleteHistory(tx, ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *historyStore) list(ref string) (recs []*controlapi.BuildHistoryRecord, err error) {
+	err = h.db.View(func(tx *bolt.Tx) error {
+		recs, err = readHistory(tx.Bucket(historyBucket), ref)
+		return err
+	})
+	return recs, err
+}
+
+// readHistory returns the records of the bucket from the oldest to the
+// newest.
+// get returns the record of the build, nil if there is none.
+func (h *historyStore) get(ref string) (rec *controlapi.BuildHistoryRecord, err error) {
+	err = h.db.View(func(tx *bolt.Tx) error {
+		dt := tx.Bucket(historyBucket).Get([]byte(ref))
+		if dt == nil {
+			return nil
+		}
+		rec = &controlapi.BuildHistoryRecord{}
+		return errors.Wrapf(rec.Unmarshal(dt), "invalid history record %s", ref)
+	})
+	return rec, err
+}
+
+func readHistory(b *bolt.Bucket, ref string) ([]*controlapi.BuildHistoryRecord, error) {
+	var recs []*controlapi.BuildHistoryRecord
+	if err := b.ForEach(func(k, v []byte) error {
+		if ref != "" && string(k) != ref {
+			return nil
+		}
+		var rec controlapi.BuildHistoryRecord
+		if err := rec.Unmarshal(v); err != nil {
+			return errors.Wrapf(err, "invalid history record %s", k)
+		}
+		recs = append(recs, &rec)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		if recs[i].CreatedAt == nil || recs[j].CreatedAt == nil {
+			return recs[j].CreatedAt != nil
+		}
+		return recs[i].CreatedAt.Before(*recs[j].CreatedAt)
+	})
+	return recs, nil
+}
+
+func (h *historyStore) logs(ref string) (resps []*controlapi.StatusResponse, err error) {
+	err = h.db.View(func(tx *bolt.Tx) error {
+		dt := tx.Bucket(historyLogsBucket).Get([]byte(ref))
+		if dt == nil {
+			if tx.Bucket(historyBucket).Get([]byte(ref)) == nil {
+				return errors.Errorf("no build history for %s", ref)
+			}
+			return nil
+		}
+		for len(dt) > 0 {
+			l, n := binary.Uvarint(dt)
+			if n <= 0 || uint64(len(dt)-n) < l {
+				return errors.Errorf("invalid logs for %s", ref)
+			}
+			var resp controlapi.StatusResponse
+			if err := resp.Unmarshal(dt[n : n+int(l)]); err != nil {
+				return err
+			}
+			resps = append(resps, &resp)
+			dt = dt[n+int(l):]
+		}
+		return nil
+	})
+	return resps, err
+}
+
+func (h *historyStore) delete(ref string) error {
+	return h.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(historyBucket).Get([]byte(ref)) == nil {
+			return errors.Errorf("no build history for %s", ref)
+		}
+		return deleteHistory(tx, ref)
+	})
+}
+
+func deleteHistory(tx *bolt.Tx, ref string) error {
+	b := tx.Bucket(historyBucket)
+	if dt := b.Get([]byte(ref)); dt != nil {
+		var rec controlapi.BuildHistoryRecord
+		if err := rec.Unmarshal(dt); err != nil {
+			return errors.Wrapf(err, "invalid history record %s", ref)
+		}
+		idx := tx.Bucket(historyIndexBucket)
+		if idx.Get(historyIndexKey(&rec)) != nil {
+			if err := idx.Delete(historyIndexKey(&rec)); err != nil {
+				return err
+			}
+			if seq := idx.Sequence(); seq > 0 {
+				if err := idx.SetSequence(seq - 1); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if err := b.Delete([]byte(ref)); err != nil {
+		return err
+	}
+	return tx.Bucket(historyLogsBucket).Delete([]byte(ref))
+}
+
+type statusFunc func(ctx context.Context, ref string, ch chan *client.SolveStatus) error
+
+// record starts recording the progress of the build of the request. The
+// returned function saves the record with the result of the build.
+func (h *historyStore) record(ctx context.Context, status statusFunc, req *controlapi.SolveRequest) func(*controlapi.SolveResponse, error) {
+	now := time.Now()
+	rec := &controlapi.BuildHistoryRecord{
+		Ref:           req.Ref,
+		Frontend:      req.Frontend,
+		FrontendAttrs: redactFrontendAttrs(req.FrontendAttrs),
+		Exporters:     req.Exporters,
+		CreatedAt:     &now,
+		Tenant:        tenant.FromContext(ctx),
+	}
+
+	statusCtx, cancel := context.WithCancel(context.Background())
+	ch := make(chan *client.SolveStatus, 8)
+	go status(statusCtx, req.Ref, ch)
+
+	vertexes := map[digest.Digest]*client.Vertex{}
+	var logs bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ss := range ch {
+			for _, v := range ss.Vertexes {
+				vertexes[v.Digest] = v
+			}
+			if logs.Len() > maxHistoryLogSize {
+				continue
+			}
+			dt, err := historyStatusResponse(ss).Marshal()
+			if err != nil {
+				continue
+			}
+			var l [binary.MaxVarintLen64]byte
+			logs.Write(l[:binary.PutUvarint(l[:], uint64(len(dt)))])
+			logs.Write(dt)
+		}
+	}()
+
+	return func(resp *controlapi.SolveResponse, err error) {
+		// the progress ends when the job of the build is released
+		select {
+		case <-done:
+		case <-time.After(3 * time.Second):
+			cancel()
+			<-done
+		}
+		cancel()
+
+		completed := time.Now()
+		rec.CompletedAt = &completed
+		if err != nil {
+			rec.Error = err.Error()
+		} else if resp != nil {
+			rec.ExporterResponse = resp.ExporterResponse
+		}
+		for _, v := range vertexes {
+			rec.NumTotalSteps++
+			if v.Cached {
+				rec.NumCachedSteps++
+			}
+			if v.Completed != nil {
+				rec.NumCompletedSteps++
+			}
+		}
+		rec.Logs = logs.Len() > 0
+		if err := h.save(rec, logs.Bytes()); err != nil {
+			bklog.G(ctx).Errorf("failed to save build history for %s: %v", rec.Ref, err)
+		}
+	}
+}
+
+// redactedValue replaces the sensitive values in the history records.
+const redactedValue = "<redacted>"
+
+// sensitiveBuildArg matches the names of the build arguments that are likely
+// to hold secrets.
+var sensitiveBuildArg = regexp.MustCompile(`(?i)(secret|token|passw(or)?d|credential|api_?key|access_?key|private_?key)`)
+
+// redactFrontendAttrs returns a copy of the frontend attributes for storing
+// them in the history. The build arguments named like secrets are redacted,
+// as well as the credentials of the URLs.
+func redactFrontendAttrs(attrs map[string]string) map[string]string {
+	if attrs == nil {
+		return nil
+	}
+	out := make(map[string]string, len(attrs))
+	for k, v := range attrs {
+		if name := strings.TrimPrefix(k, "build-arg:"); name != k && sensitiveBuildArg.MatchString(name) {
+			out[k] = redactedValue
+			continue
+		}
+		out[k] = redactURLCredentials(v)
+	}
+	return out
+}
+
+// redactURLCredentials masks the password of the URLs, and the user of the
+// HTTP URLs as it is usually a token.
+func redactURLCredentials(v string) string {
+	if !strings.Contains(v, "@") || !strings.Contains(v, "://") {
+		return v
+	}
+	u, err := url.Parse(v)
+	if err != nil || u.User == nil {
+		return v
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "xxxxx")
+	} else if u.Scheme == "http" || u.Scheme == "https" {
+		u.User = url.User("xxxxx")
+	} else {
+		return v
+	}
+	return u.String()
+}
+
+// historyStatusResponse converts the status for storing it in the history.
+// Progress of individual tasks is not stored.
+func historyStatusResponse(ss *client.SolveStatus) *controlapi.StatusResponse {
+	sr := &controlapi.StatusResponse{}
+	for _, v := range ss.Vertexes {
+		sr.Vertexes = append(sr.Vertexes, &controlapi.Vertex{
+			Digest:        v.Digest,
+			Inputs:        v.Inputs,
+			Name:          v.Name,
+			Started:       v.Started,
+			Completed:     v.Completed,
+			Error:         v.Error,
+			Cached:        v.Cached,
+			ProgressGroup: v.ProgressGroup,
+			CacheMiss:     cacheMissToPB(v.CacheMiss),
+		})
+	}
+	for _, v := range ss.Logs {
+		sr.Logs = append(sr.Logs, &controlapi.VertexLog{
+			Vertex:    v.Vertex,
+			Stream:    int64(v.Stream),
+			Msg:       v.Data,
+			Timestamp: v.Timestamp,
+		})
+	}
+	for _, v := range ss.Warnings {
+		sr.Warnings = append(sr.Warnings, &controlapi.VertexWarning{
+			Vertex: v.Vertex,
+			Level:  int64(v.Level),
+			Short:  v.Short,
+			Detail: v.Detail,
+			Info:   v.SourceInfo,
+			Ranges: v.Range,
+			Url:    v.URL,
+		})
+	}
+	return sr
+}
+
+func (c *Controller) ListBuildHistory(ctx context.Context, req *controlapi.ListBuildHistoryRequest) (*controlapi.ListBuildHistoryResponse, error) {
+	if c.history == nil {
+		return nil, errors.New("build history is not enabled")
+	}
+	recs, err := c.history.list(req.Ref)
+	if err != nil {
+		return nil, err
+	}
+	// tenants only see their own records
+	out := recs[:0]
+	for _, rec := range recs {
+		if tenantAllowed(ctx, rec.Tenant) {
+			out = append(out, rec)
+		}
+	}
+	return &controlapi.ListBuildHistoryResponse{Records: out}, nil
+}
+
+func (c *Controller) BuildHistoryLogs(req *controlapi.BuildHistoryLogsRequest, stream controlapi.Control_BuildHistoryLogsServer) error {
+	if c.history == nil {
+		return errors.New("build history is not enabled")
+	}
+	if err := c.checkHistoryTenant(stream.Context(), req.Ref); err != nil {
+		return err
+	}
+	resps, err := c.history.logs(req.Ref)
+	if err != nil {
+		return err
+	}
+	for _, resp := range resps {
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Controller) DeleteBuildHistory(ctx context.Context, req *controlapi.DeleteBuildHistoryRequest) (*controlapi.DeleteBuildHistoryResponse, error) {
+	if c.history == nil {
+		return nil, errors.New("build history is not enabled")
+	}
+	if err := c.checkHistoryTenant(ctx, req.Ref); err != nil {
+		return nil, err
+	}
+	if err := c.history.delete(req.Ref); err != nil {
+		return nil, err
+	}
+	return &controlapi.DeleteBuildHistoryResponse{}, nil
+}
+
+// checkHistoryTenant returns an error if the record of the build isn't a
+// record of the tenant of the request.
+func (c *Controller) checkHistoryTenant(ctx context.Context, ref string) error {
+	if tenant.FromContext(ctx) == "" {
+		return nil
+	}
+	rec, err := c.history.get(ref)
+	if err != nil {
+		return err
+	}
+	if rec == nil || !tenantAllowed(ctx, rec.Tenant) {
+		return errors.Errorf("no build history for %s", ref)
+	}
+	return nil
+}
+
+func cacheMissToPB(m *client.CacheMiss) *controlapi.CacheMiss {
+	if m == nil {
+		return nil
+	}
+	return &controlapi.CacheMiss{
+		Reason:  string(m.Reason),
+		Input:   int64(m.Input),
+		Digest:  m.Digest,
+		Message: m.Message,
+	}
+}
@@ -0,0 +1,222 @@
+package control
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/moby/buildkit/util/entitlements"
+	"github.com/moby/buildkit/util/grpcerrors"
+	"github.com/moby/buildkit/util/nestedbuild"
+	"github.com/moby/buildkit/util/tenant"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// maxNestedDepth limits how deep nested builds can run nested builds.
+const maxNestedDepth = 4
+
+// nestedStatusTimeout is how long the status requests of nested builds wait
+// for the build to be started, the clients requesting the status concurrently
+// with the solve.
+const nestedStatusTimeout = 6 * time.Second
+
+// nestedServer serves the BuildKit API to the containers of the builds
+// mounting it. The API only allows solving and following builds, and the
+// nested builds can't use more entitlements or resources than their parents.
+type nestedServer struct {
+	c *Controller
+
+	mu     sync.Mutex
+	depths map[string]int
+}
+
+func newNestedServer(c *Controller) *nestedServer {
+	return &nestedServer{c: c, depths: map[string]int{}}
+}
+
+func (s *nestedServer) Serve(ctx context.Context, l net.Listener, p nestedbuild.Parent) error {
+	// the requests of the nested builds are made for the tenant of the parent
+	unaryTenant := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(tenant.WithName(ctx, p.Tenant), req)
+	}
+	streamTenant := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		wrapped := grpc_middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = tenant.WithName(ss.Context(), p.Tenant)
+		return handler(srv, wrapped)
+	}
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(grpcerrors.UnaryServerInterceptor, unaryTenant),
+		grpc.ChainStreamInterceptor(grpcerrors.StreamServerInterceptor, streamTenant),
+	)
+	controlapi.RegisterControlServer(server, newNestedControl(s, p))
+	go func() {
+		<-ctx.Done()
+		// the connections are closed, canceling the nested builds
+		server.Stop()
+	}()
+	err := server.Serve(l)
+	if errors.Is(err, grpc.ErrServerStopped) {
+		return nil
+	}
+	return err
+}
+
+// depth returns the nesting depth of the build, 0 for the builds of clients.
+func (s *nestedServer) depth(id string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.depths[id]
+}
+
+func (s *nestedServer) setDepth(id string, depth int) func() {
+	s.mu.Lock()
+	s.depths[id] = depth
+	s.mu.Unlock()
+	return func() {
+		s.mu.Lock()
+		delete(s.depths, id)
+		s.mu.Unlock()
+	}
+}
+
+type nestedControl struct {
+	controlapi.UnimplementedControlServer
+	s      *nestedServer
+	parent nestedbuild.Parent
+
+	mu      sync.Mutex
+	refs    map[string]struct{}
+	started chan struct{}
+}
+
+func newNestedControl(s *nestedServer, p nestedbuild.Parent) *nestedControl {
+	return &nestedControl{s: s, parent: p, refs: map[string]struct{}{}, started: make(chan struct{})}
+}
+
+// addRef records a build started by the nested control.
+func (nc *nestedControl) addRef(ref string) {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	nc.refs[ref] = struct{}{}
+	close(nc.started)
+	nc.started = make(chan struct{})
+}
+
+// waitRef waits until the build is started by the nested control, returning
+// an error if it isn't before the timeout.
+func (nc *nestedControl) waitRef(ctx context.Context, ref string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		nc.mu.Lock()
+		_, ok := nc.refs[ref]
+		started := nc.started
+		nc.mu.Unlock()
+		if ok {
+			return nil
+		}
+		select {
+		case <-started:
+		case <-ctx.Done():
+			return grpcerrors.WrapCode(errors.Errorf("build %s is not a nested build of %s", ref, nc.parent.ID), codes.PermissionDenied)
+		}
+	}
+}
+
+// sessionID returns the ID of a session of the nested builds, scoped to the
+// parent build so that they can't use or replace the sessions of other builds.
+func (nc *nestedControl) sessionID(id string) string {
+	if id == "" {
+		return ""
+	}
+	return "nested-" + nc.parent.ID + "-" + id
+}
+
+func (nc *nestedControl) Solve(ctx context.Context, req *controlapi.SolveRequest) (*controlapi.SolveResponse, error) {
+	if err := nc.restrict(req); err != nil {
+		return nil, err
+	}
+	depth := nc.s.depth(nc.parent.ID) + 1
+	if depth > maxNestedDepth {
+		return nil, errors.Errorf("nested builds can't be nested more than %d levels deep", maxNestedDepth)
+	}
+	if req.Ref == "" {
+		return nil, errors.New("nested build requires a build ref")
+	}
+	if nc.s.depth(req.Ref) != 0 {
+		return nil, errors.Errorf("nested build %s already running", req.Ref)
+	}
+	defer nc.s.setDepth(req.Ref, depth)()
+	nc.addRef(req.Ref)
+	req.Session = nc.sessionID(req.Session)
+	return nc.s.c.Solve(ctx, req)
+}
+
+// restrict checks that the nested build doesn't request entitlements its
+// parent doesn't have and limits its resources to the ones of the parent.
+func (nc *nestedControl) restrict(req *controlapi.SolveRequest) error {
+	if req.Detach {
+		return errors.New("nested builds can't be detached")
+	}
+	for _, e := range req.Entitlements {
+		if !hasEntitlement(nc.parent.Entitlements, e) {
+			return errors.Errorf("nested build can't use entitlement %s not granted to its parent build", e)
+		}
+	}
+
+	if limits := nc.parent.Limits; limits != nil {
+		if req.Resources == nil {
+			req.Resources = &controlapi.ResourceLimits{}
+		}
+		r := req.Resources
+		if limits.CPU > 0 && (r.CPU == 0 || r.CPU > limits.CPU) {
+			r.CPU = limits.CPU
+		}
+		if limits.Memory > 0 && (r.Memory == 0 || r.Memory > limits.Memory) {
+			r.Memory = limits.Memory
+		}
+		if limits.Pids > 0 && (r.Pids == 0 || r.Pids > limits.Pids) {
+			r.Pids = limits.Pids
+		}
+	}
+	if w := int64(nc.parent.Weight); w > 0 && (req.Weight == 0 || req.Weight > w) {
+		req.Weight = w
+	}
+	return nil
+}
+
+func hasEntitlement(ents []entitlements.Entitlement, e entitlements.Entitlement) bool {
+	for _, ee := range ents {
+		if ee == e {
+			return true
+		}
+	}
+	return false
+}
+
+// Status returns the status of the builds started by the nested control.
+func (nc *nestedControl) Status(req *controlapi.StatusRequest, stream controlapi.Control_StatusServer) error {
+	if err := nc.waitRef(stream.Context(), req.Ref, nestedStatusTimeout); err != nil {
+		return err
+	}
+	return nc.s.c.Status(req, stream)
+}
+
+// Session serves the sessions of the nested builds, with their IDs scoped to
+// the parent build.
+func (nc *nestedControl) Session(stream controlapi.Control_SessionServer) error {
+	stream, err := scopeSession(stream, nc.sessionID)
+	if err != nil {
+		return err
+	}
+	return nc.s.c.Session(stream)
+}
+
+func (nc *nestedControl) ListWorkers(ctx context.Context, req *controlapi.ListWorkersRequest) (*controlapi.ListWorkersResponse, error) {
+	return nc.s.c.ListWorkers(ctx, req)
+}
@@ -0,0 +1,79 @@
+package control
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/moby/buildkit/executor"
+	"github.com/moby/buildkit/util/entitlements"
+	"github.com/moby/buildkit/util/nestedbuild"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNestedRestrict(t *testing.T) {
+	nc := &nestedControl{
+		s: newNestedServer(nil),
+		parent: nestedbuild.Parent{
+			ID:           "parent",
+			Entitlements: []entitlements.Entitlement{entitlements.EntitlementNetworkHost},
+			Limits:       &executor.ResourceLimits{CPU: 2, Memory: 1 << 30},
+			Weight:       2,
+		},
+	}
+
+	req := &controlapi.SolveRequest{
+		Ref:          "child",
+		Entitlements: []entitlements.Entitlement{entitlements.EntitlementNetworkHost},
+		Resources:    &controlapi.ResourceLimits{CPU: 4, Pids: 100},
+		Weight:       10,
+	}
+	require.NoError(t, nc.restrict(req))
+	require.Equal(t, &controlapi.ResourceLimits{CPU: 2, Memory: 1 << 30, Pids: 100}, req.Resources)
+	require.Equal(t, int64(2), req.Weight)
+
+	err := nc.restrict(&controlapi.SolveRequest{
+		Entitlements: []entitlements.Entitlement{entitlements.EntitlementSecurityInsecure},
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "security.insecure")
+
+	err = nc.restrict(&controlapi.SolveRequest{Detach: true})
+	require.Error(t, err)
+}
+
+func TestNestedDepth(t *testing.T) {
+	s := newNestedServer(nil)
+	release := s.setDepth("child", maxNestedDepth)
+	nc := newNestedControl(s, nestedbuild.Parent{ID: "child"})
+
+	_, err := nc.Solve(context.TODO(), &controlapi.SolveRequest{Ref: "grandchild"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "levels deep")
+
+	release()
+	require.Equal(t, 0, s.depth("child"))
+}
+
+func TestNestedStatusRefs(t *testing.T) {
+	nc := newNestedControl(newNestedServer(nil), nestedbuild.Parent{ID: "parent"})
+
+	err := nc.waitRef(context.TODO(), "other", 10*time.Millisecond)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not a nested build")
+
+	done := make(chan error, 1)
+	go func() {
+		done <- nc.waitRef(context.TODO(), "child", time.Minute)
+	}()
+	nc.addRef("child")
+	require.NoError(t, <-done)
+	require.NoError(t, nc.waitRef(context.TODO(), "child", 0))
+}
+
+func TestNestedSessionID(t *testing.T) {
+	nc := newNestedControl(newNestedServer(nil), nestedbuild.Parent{ID: "parent"})
+	require.Equal(t, "nested-parent-session", nc.sessionID("session"))
+	require.Equal(t, "", nc.sessionID(""))
+}
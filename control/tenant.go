@@ -0,0 +1,67 @@
+package control
+
+import (
+	"context"
+	"time"
+
+	"github.com/moby/buildkit/util/grpcerrors"
+	"github.com/moby/buildkit/util/tenant"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+)
+
+// buildTenantTimeout is how long the requests for a build wait for the build
+// to be started before checking its tenant, the clients following the builds
+// concurrently with the solve.
+const buildTenantTimeout = 6 * time.Second
+
+// tenantAllowed returns whether the request can access the builds and the
+// records of tenant t. The requests of no tenant can access all of them.
+func tenantAllowed(ctx context.Context, t string) bool {
+	name := tenant.FromContext(ctx)
+	return name == "" || name == t
+}
+
+// tenantSessionID returns the ID of a session of the request, scoped to its
+// tenant so that the tenants can't use or replace the sessions of the others.
+// Tenant names can't contain slashes.
+func tenantSessionID(ctx context.Context, id string) string {
+	name := tenant.FromContext(ctx)
+	if name == "" || id == "" {
+		return id
+	}
+	return name + "/" + id
+}
+
+// checkBuildTenant returns an error if the build isn't a build of the tenant
+// of the request, running or recorded in the history.
+func (c *Controller) checkBuildTenant(ctx context.Context, ref string) error {
+	if tenant.FromContext(ctx) == "" {
+		return nil
+	}
+	notFound := grpcerrors.WrapCode(errors.Errorf("no build %s", ref), codes.NotFound)
+	ctx, cancel := context.WithTimeout(ctx, buildTenantTimeout)
+	defer cancel()
+	for {
+		c.buildsMu.Lock()
+		b, ok := c.builds[ref]
+		started := c.buildsStarted
+		c.buildsMu.Unlock()
+		if ok {
+			if !tenantAllowed(ctx, b.tenant) {
+				return notFound
+			}
+			return nil
+		}
+		if c.history != nil {
+			if rec, err := c.history.get(ref); err == nil && rec != nil && tenantAllowed(ctx, rec.Tenant) {
+				return nil
+			}
+		}
+		select {
+		case <-started:
+		case <-ctx.Done():
+			return notFound
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package control
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/moby/buildkit/util/tenant"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantSessionID(t *testing.T) {
+	require.Equal(t, "session", tenantSessionID(context.TODO(), "session"))
+	ctx := tenant.WithName(context.TODO(), "a")
+	require.Equal(t, "a/session", tenantSessionID(ctx, "session"))
+	require.Equal(t, "", tenantSessionID(ctx, ""))
+}
+
+func TestTenantBuilds(t *testing.T) {
+	c := &Controller{
+		builds:        map[string]*activeBuild{},
+		buildsStarted: make(chan struct{}),
+		detached:      map[string]*detachedBuild{},
+		history:       newTestHistoryStore(t),
+	}
+	ctxA := tenant.WithName(context.TODO(), "a")
+	ctxB := tenant.WithName(context.TODO(), "b")
+
+	_, b, err := c.startBuild(ctxA, "ref1")
+	require.NoError(t, err)
+	defer c.finishBuild("ref1", b, nil)
+
+	require.NoError(t, c.checkBuildTenant(ctxA, "ref1"))
+	require.NoError(t, c.checkBuildTenant(context.TODO(), "ref1"))
+	require.Error(t, c.checkBuildTenant(ctxB, "ref1"))
+
+	_, err = c.Cancel(ctxB, &controlapi.CancelRequest{Ref: "ref1"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no running build")
+
+	// the status can be requested before the build is started
+	done := make(chan error, 1)
+	go func() {
+		done <- c.checkBuildTenant(ctxA, "ref2")
+	}()
+	time.Sleep(10 * time.Millisecond)
+	_, b2, err := c.startBuild(ctxA, "ref2")
+	require.NoError(t, err)
+	defer c.finishBuild("ref2", b2, nil)
+	require.NoError(t, <-done)
+
+	c.detached["ref3"] = &detachedBuild{tenant: "a", done: make(chan struct{})}
+	_, err = c.Attach(ctxB, &controlapi.AttachRequest{Ref: "ref3"})
+	require.Error(t, err)
+}
+
+func TestTenantHistory(t *testing.T) {
+	c := &Controller{history: newTestHistoryStore(t)}
+	ctxA := tenant.WithName(context.TODO(), "a")
+	ctxB := tenant.WithName(context.TODO(), "b")
+	require.NoError(t, c.history.save(&controlapi.BuildHistoryRecord{Ref: "ref1", Tenant: "a"}, nil))
+	require.NoError(t, c.history.save(&controlapi.BuildHistoryRecord{Ref: "ref2", Tenant: "b"}, nil))
+
+	resp, err := c.ListBuildHistory(ctxA, &controlapi.ListBuildHistoryRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Records, 1)
+	require.Equal(t, "ref1", resp.Records[0].Ref)
+
+	resp, err = c.ListBuildHistory(context.TODO(), &controlapi.ListBuildHistoryRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Records, 2)
+
+	require.NoError(t, c.checkBuildTenant(ctxA, "ref1"))
+
+	_, err = c.DeleteBuildHistory(ctxB, &controlapi.DeleteBuildHistoryRequest{Ref: "ref1"})
+	require.Error(t, err)
+	_, err = c.DeleteBuildHistory(ctxA, &controlapi.DeleteBuildHistoryRequest{Ref: "ref1"})
+	require.NoError(t, err)
+}
@@ -11,23 +11,19 @@ import (
 	"time"
 
 	"github.com/moby/buildkit/util/bklog"
-	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/trace"
 
 	"github.com/containerd/containerd"
 	"github.com/containerd/containerd/cio"
 	"github.com/containerd/containerd/mount"
 	containerdoci "github.com/containerd/containerd/oci"
-	"github.com/containerd/continuity/fs"
-	"github.com/docker/docker/pkg/idtools"
 	"github.com/moby/buildkit/executor"
 	"github.com/moby/buildkit/executor/oci"
 	gatewayapi "github.com/moby/buildkit/frontend/gateway/pb"
 	"github.com/moby/buildkit/identity"
-	"github.com/moby/buildkit/snapshot"
 	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/network"
 	rootlessspecconv "github.com/moby/buildkit/util/rootless/specconv"
+	"github.com/moby/buildkit/util/tracing"
 	"github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/pkg/errors"
 )
@@ -43,10 +39,15 @@ type containerdExecutor struct {
 	apparmorProfile  string
 	traceSocket      string
 	rootless         bool
+	userns           *oci.UsernsRemapper
+	runtimes         map[string]string
+	defaultRuntime   string
 }
 
-// New creates a new executor backed by connection to containerd API
-func New(client *containerd.Client, root, cgroup string, networkProviders map[pb.NetMode]network.Provider, dnsConfig *oci.DNSConfig, apparmorProfile string, traceSocket string, rootless bool) executor.Executor {
+// New creates a new executor backed by connection to containerd API.
+// runtimes maps the names of the runtimes the containers can select to
+// containerd runtimes, e.g. io.containerd.runsc.v1.
+func New(client *containerd.Client, root, cgroup string, networkProviders map[pb.NetMode]network.Provider, dnsConfig *oci.DNSConfig, apparmorProfile string, traceSocket string, rootless bool, userns *oci.UsernsRemapper, runtimes map[string]string, defaultRuntime string) executor.Executor {
 	// clean up old hosts/resolv.conf file. ignore errors
 	os.RemoveAll(filepath.Join(root, "hosts"))
 	os.RemoveAll(filepath.Join(root, "resolv.conf"))
@@ -61,9 +62,48 @@ func New(client *containerd.Client, root, cgroup string, networkProviders map[pb
 		apparmorProfile:  apparmorProfile,
 		traceSocket:      traceSocket,
 		rootless:         rootless,
+		userns:           userns,
+		runtimes:         runtimes,
+		defaultRuntime:   defaultRuntime,
 	}
 }
 
+// runtime returns the containerd runtime with name, or the default runtime if
+// name is empty. An empty string is returned for the runtime of containerd.
+func (w *containerdExecutor) runtime(name string) (string, error) {
+	if name == "" {
+		name = w.defaultRuntime
+	}
+	if name == "" {
+		return "", nil
+	}
+	runtime, ok := w.runtimes[name]
+	if !ok {
+		return "", errors.Errorf("unknown runtime %q", name)
+	}
+	return runtime, nil
+}
+
+// containerRootfs is the rootfs of a container prepared for its task.
+type containerRootfs struct {
+	// path is where the rootfs is mounted on the host while the container
+	// runs, if it is
+	path string
+	// mounts are the rootfs mounts of the task
+	mounts []mount.Mount
+	// opts are the spec options of the user of the container
+	opts    []containerdoci.SpecOpts
+	release func()
+}
+
+func bindRootfs(p string) []mount.Mount {
+	return []mount.Mount{{
+		Source:  p,
+		Type:    "bind",
+		Options: []string{"rbind"},
+	}}
+}
+
 func (w *containerdExecutor) Run(ctx context.Context, id string, root executor.Mount, mounts []executor.Mount, process executor.ProcessInfo, started chan<- struct{}) (err error) {
 	if id == "" {
 		id = identity.NewID()
@@ -89,12 +129,17 @@ func (w *containerdExecutor) Run(ctx context.Context, id string, root executor.M
 
 	meta := process.Meta
 
-	resolvConf, err := oci.GetResolvConf(ctx, w.root, nil, w.dnsConfig)
+	span, ctx := oci.StartExecSpan(ctx, id, meta)
+	defer func() {
+		tracing.FinishWithError(span, err)
+	}()
+
+	runtime, err := w.runtime(meta.Runtime)
 	if err != nil {
 		return err
 	}
 
-	hostsFile, clean, err := oci.GetHostsFile(ctx, w.root, meta.ExtraHosts, nil, meta.Hostname)
+	resolvConf, hostsFile, clean, err := w.prepareNetworkFiles(ctx, meta)
 	if err != nil {
 		return err
 	}
@@ -115,39 +160,17 @@ func (w *containerdExecutor) Run(ctx context.Context, id string, root executor.M
 		defer release()
 	}
 
-	lm := snapshot.LocalMounterWithMounts(rootMounts)
-	rootfsPath, err := lm.Mount()
-	if err != nil {
-		return err
-	}
-	defer lm.Unmount()
-	defer executor.MountStubsCleaner(rootfsPath, mounts)()
-
-	uid, gid, sgids, err := oci.GetUser(rootfsPath, meta.User)
+	rootfs, err := w.prepareRootfs(rootMounts, mounts, meta)
 	if err != nil {
 		return err
 	}
-
-	identity := idtools.Identity{
-		UID: int(uid),
-		GID: int(gid),
-	}
-
-	newp, err := fs.RootPath(rootfsPath, meta.Cwd)
-	if err != nil {
-		return errors.Wrapf(err, "working dir %s points to invalid target", newp)
-	}
-	if _, err := os.Stat(newp); err != nil {
-		if err := idtools.MkdirAllAndChown(newp, 0755, identity); err != nil {
-			return errors.Wrapf(err, "failed to create working directory %s", newp)
-		}
-	}
+	defer rootfs.release()
 
 	provider, ok := w.networkProviders[meta.NetMode]
 	if !ok {
 		return errors.Errorf("unknown network mode %s", meta.NetMode)
 	}
-	namespace, err := provider.New()
+	namespace, err := network.NewNamespace(provider, meta.NetworkName, meta.PublishPorts...)
 	if err != nil {
 		return err
 	}
@@ -157,17 +180,53 @@ func (w *containerdExecutor) Run(ctx context.Context, id string, root executor.M
 		bklog.G(ctx).Info("enabling HostNetworking")
 	}
 
-	opts := []containerdoci.SpecOpts{oci.WithUIDGID(uid, gid, sgids)}
+	usernsMap, releaseUserns, err := w.userns.Get(meta.Userns)
+	if err != nil {
+		return err
+	}
+	defer releaseUserns()
+
+	opts := rootfs.opts
 	if meta.ReadonlyRootFS {
 		opts = append(opts, containerdoci.WithRootFSReadonly())
 	}
 
 	processMode := oci.ProcessSandbox // FIXME(AkihiroSuda)
-	spec, cleanup, err := oci.GenerateSpec(ctx, meta, mounts, id, resolvConf, hostsFile, namespace, w.cgroupParent, processMode, nil, w.apparmorProfile, w.traceSocket, opts...)
+	spec, cleanup, err := oci.GenerateSpec(ctx, meta, mounts, id, resolvConf, hostsFile, namespace, w.cgroupParent, processMode, usernsMap, w.apparmorProfile, w.traceSocket, opts...)
 	if err != nil {
 		return err
 	}
 	defer cleanup()
+	owners, err := oci.MountOwners(mounts, nil)
+	if err != nil {
+		return err
+	}
+	var owned []string
+	if len(owners) > 0 {
+		var releaseOwners func() error
+		owned, releaseOwners, err = oci.RemapOwners(spec, filepath.Join(w.root, "owners", id), owners, usernsMap)
+		if err != nil {
+			return err
+		}
+		defer releaseOwners()
+	}
+	if usernsMap != nil {
+		// the files keep their uids on disk, the container sees them through
+		// idmapped mounts
+		idmapped, err := oci.NewIDMappedMounts(filepath.Join(w.root, "idmap", id), usernsMap)
+		if err != nil {
+			return err
+		}
+		defer idmapped.Close()
+		p, err := idmapped.Mount(rootfs.path)
+		if err != nil {
+			return err
+		}
+		rootfs.mounts = bindRootfs(p)
+		if err := idmapped.Remap(spec, append([]string{resolvConf, hostsFile, w.traceSocket}, owned...)...); err != nil {
+			return err
+		}
+	}
 	spec.Process.Terminal = meta.Tty
 	if w.rootless {
 		if err := rootlessspecconv.ToRootless(spec); err != nil {
@@ -175,9 +234,12 @@ func (w *containerdExecutor) Run(ctx context.Context, id string, root executor.M
 		}
 	}
 
-	container, err := w.client.NewContainer(ctx, id,
-		containerd.WithSpec(spec),
-	)
+	containerOpts := []containerd.NewContainerOpts{containerd.WithSpec(spec)}
+	if runtime != "" {
+		containerOpts = append(containerOpts, containerd.WithRuntime(runtime, nil))
+	}
+
+	container, err := w.client.NewContainer(ctx, id, containerOpts...)
 	if err != nil {
 		return err
 	}
@@ -194,11 +256,7 @@ func (w *containerdExecutor) Run(ctx context.Context, id string, root executor.M
 		cioOpts = append(cioOpts, cio.WithTerminal)
 	}
 
-	task, err := container.NewTask(ctx, cio.NewCreator(cioOpts...), containerd.WithRootFS([]mount.Mount{{
-		Source:  rootfsPath,
-		Type:    "bind",
-		Options: []string{"rbind"},
-	}}))
+	task, err := container.NewTask(ctx, cio.NewCreator(cioOpts...), containerd.WithRootFS(rootfs.mounts))
 	if err != nil {
 		return err
 	}
@@ -209,10 +267,10 @@ func (w *containerdExecutor) Run(ctx context.Context, id string, root executor.M
 		}
 	}()
 
-	trace.SpanFromContext(ctx).AddEvent("Container created")
+	oci.ContainerCreated(ctx)
 	err = w.runProcess(ctx, task, process.Resize, process.Signal, func() {
 		startedOnce.Do(func() {
-			trace.SpanFromContext(ctx).AddEvent("Container started")
+			oci.ContainerStarted(ctx)
 			if started != nil {
 				close(started)
 			}
@@ -400,12 +458,7 @@ func (w *containerdExecutor) runProcess(ctx context.Context, p containerd.Proces
 			if cancel != nil {
 				cancel()
 			}
-			trace.SpanFromContext(ctx).AddEvent(
-				"Container exited",
-				trace.WithAttributes(
-					attribute.Int("exit.code", int(status.ExitCode())),
-				),
-			)
+			oci.ContainerExited(ctx, status.ExitCode())
 			if status.ExitCode() != 0 {
 				exitErr := &gatewayapi.ExitError{
 					ExitCode: status.ExitCode(),
@@ -5,6 +5,7 @@ import (
 
 	"github.com/containerd/containerd"
 	gatewayapi "github.com/moby/buildkit/frontend/gateway/pb"
+	"github.com/stretchr/testify/require"
 )
 
 func TestContainerdUnknownExitStatus(t *testing.T) {
@@ -14,3 +15,24 @@ func TestContainerdUnknownExitStatus(t *testing.T) {
 		t.Fatalf("containerd.UnknownExitStatus != errdefs.UnknownExitStatus")
 	}
 }
+
+func TestContainerdRuntime(t *testing.T) {
+	w := &containerdExecutor{
+		runtimes: map[string]string{"kata": "io.containerd.kata.v2", "gvisor": "io.containerd.runsc.v1"},
+	}
+	rt, err := w.runtime("")
+	require.NoError(t, err)
+	require.Equal(t, "", rt)
+
+	rt, err = w.runtime("gvisor")
+	require.NoError(t, err)
+	require.Equal(t, "io.containerd.runsc.v1", rt)
+
+	_, err = w.runtime("firecracker")
+	require.Error(t, err)
+
+	w.defaultRuntime = "kata"
+	rt, err = w.runtime("")
+	require.NoError(t, err)
+	require.Equal(t, "io.containerd.kata.v2", rt)
+}
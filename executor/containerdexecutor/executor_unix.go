@@ -0,0 +1,80 @@
+//go:build !windows
+// +build !windows
+
+package containerdexecutor
+
+import (
+	"context"
+	"os"
+
+	"github.com/containerd/containerd/mount"
+	containerdoci "github.com/containerd/containerd/oci"
+	"github.com/containerd/continuity/fs"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/moby/buildkit/executor"
+	"github.com/moby/buildkit/executor/oci"
+	"github.com/moby/buildkit/snapshot"
+	"github.com/pkg/errors"
+)
+
+// prepareNetworkFiles returns the resolv.conf and the hosts files of the
+// container.
+func (w *containerdExecutor) prepareNetworkFiles(ctx context.Context, meta executor.Meta) (string, string, func(), error) {
+	resolvConf, err := oci.GetOverrideResolvConf(ctx, w.root, nil, w.dnsConfig, meta.DNS)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	hostsFile, clean, err := oci.GetHostsFile(ctx, w.root, meta.ExtraHosts, nil, meta.Hostname)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return resolvConf, hostsFile, clean, nil
+}
+
+// prepareRootfs mounts the rootfs on the host, creates the working directory
+// of the container and resolves its user. The task bind mounts the rootfs.
+func (w *containerdExecutor) prepareRootfs(rootMounts []mount.Mount, mounts []executor.Mount, meta executor.Meta) (_ *containerRootfs, err error) {
+	lm := snapshot.LocalMounterWithMounts(rootMounts)
+	rootfsPath, err := lm.Mount()
+	if err != nil {
+		return nil, err
+	}
+	cleanStubs := executor.MountStubsCleaner(rootfsPath, mounts)
+	release := func() {
+		cleanStubs()
+		lm.Unmount()
+	}
+	defer func() {
+		if err != nil {
+			release()
+		}
+	}()
+
+	uid, gid, sgids, err := oci.GetUser(rootfsPath, meta.User)
+	if err != nil {
+		return nil, err
+	}
+
+	identity := idtools.Identity{
+		UID: int(uid),
+		GID: int(gid),
+	}
+
+	newp, err := fs.RootPath(rootfsPath, meta.Cwd)
+	if err != nil {
+		return nil, errors.Wrapf(err, "working dir %s points to invalid target", newp)
+	}
+	if _, err := os.Stat(newp); err != nil {
+		if err := idtools.MkdirAllAndChown(newp, 0755, identity); err != nil {
+			return nil, errors.Wrapf(err, "failed to create working directory %s", newp)
+		}
+	}
+
+	return &containerRootfs{
+		path:    rootfsPath,
+		mounts:  bindRootfs(rootfsPath),
+		opts:    []containerdoci.SpecOpts{oci.WithUIDGID(uid, gid, sgids)},
+		release: release,
+	}, nil
+}
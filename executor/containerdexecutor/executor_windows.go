@@ -0,0 +1,74 @@
+//go:build windows
+// +build windows
+
+package containerdexecutor
+
+import (
+	"context"
+	"os"
+
+	"github.com/containerd/containerd/containers"
+	"github.com/containerd/containerd/mount"
+	containerdoci "github.com/containerd/containerd/oci"
+	"github.com/containerd/continuity/fs"
+	"github.com/moby/buildkit/executor"
+	"github.com/moby/buildkit/snapshot"
+	"github.com/pkg/errors"
+)
+
+// prepareNetworkFiles returns no files, the DNS config and the hosts of
+// Windows containers are set up by HCS.
+func (w *containerdExecutor) prepareNetworkFiles(ctx context.Context, meta executor.Meta) (string, string, func(), error) {
+	return "", "", nil, nil
+}
+
+// prepareRootfs creates the working directory of the container. The layers
+// of Windows snapshots are mounted by the task itself, so the rootfs is only
+// mounted on the host while the directory is created.
+func (w *containerdExecutor) prepareRootfs(rootMounts []mount.Mount, mounts []executor.Mount, meta executor.Meta) (*containerRootfs, error) {
+	if meta.ReadonlyRootFS {
+		return nil, errors.New("read-only root filesystem is not supported on Windows")
+	}
+	if err := ensureCwd(rootMounts, meta.Cwd); err != nil {
+		return nil, err
+	}
+
+	var opts []containerdoci.SpecOpts
+	if meta.User != "" {
+		opts = append(opts, withUsername(meta.User))
+	}
+	return &containerRootfs{
+		mounts:  rootMounts,
+		opts:    opts,
+		release: func() {},
+	}, nil
+}
+
+func ensureCwd(rootMounts []mount.Mount, cwd string) error {
+	lm := snapshot.LocalMounterWithMounts(rootMounts)
+	rootfsPath, err := lm.Mount()
+	if err != nil {
+		return err
+	}
+	defer lm.Unmount()
+
+	newp, err := fs.RootPath(rootfsPath, cwd)
+	if err != nil {
+		return errors.Wrapf(err, "working dir %s points to invalid target", newp)
+	}
+	if _, err := os.Stat(newp); err != nil {
+		if err := os.MkdirAll(newp, 0755); err != nil {
+			return errors.Wrapf(err, "failed to create working directory %s", newp)
+		}
+	}
+	return nil
+}
+
+// withUsername runs the process of the container as the Windows user, e.g.
+// ContainerUser. The user is resolved in the container.
+func withUsername(username string) containerdoci.SpecOpts {
+	return func(_ context.Context, _ containerdoci.Client, _ *containers.Container, s *containerdoci.Spec) error {
+		s.Process.User.Username = username
+		return nil
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"net"
 	"syscall"
 
+	"github.com/docker/docker/pkg/idtools"
 	"github.com/moby/buildkit/snapshot"
 	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/network"
 )
 
 type Meta struct {
@@ -22,7 +24,105 @@ type Meta struct {
 	Ulimit         []*pb.Ulimit
 	CgroupParent   string
 	NetMode        pb.NetMode
-	SecurityMode   pb.SecurityMode
+	NetworkName    string
+	// PublishPorts are the ports of the container published on the host
+	PublishPorts []network.PortMapping
+	SecurityMode pb.SecurityMode
+	// SeccompProfile and ApparmorProfile override the profiles of the
+	// sandbox security mode
+	SeccompProfile  string
+	ApparmorProfile string
+	// TraceContext passes the trace context of ctx to the process as
+	// TRACEPARENT and TRACESTATE environment variables
+	TraceContext bool
+	// ResourceLimits constrains the resources of the container
+	ResourceLimits *ResourceLimits
+	// DNS overrides the DNS config of the worker
+	DNS *DNSConfig
+	// Userns is the user namespace mode of the container, "host" or
+	// "private". The worker default is used if it is empty.
+	Userns string
+	// Runtime is the name of the OCI runtime of the worker the container is
+	// run with. The default runtime of the worker is used if it is empty.
+	Runtime string
+}
+
+// DNSConfig overrides the DNS config of a worker. The fields that are not set
+// keep the values of the worker.
+type DNSConfig struct {
+	Nameservers   []string
+	Options       []string
+	SearchDomains []string
+}
+
+type dnsKey struct{}
+
+// WithDNS returns a context with the DNS config for the containers of the
+// operations run with it.
+func WithDNS(ctx context.Context, dns *DNSConfig) context.Context {
+	return context.WithValue(ctx, dnsKey{}, dns)
+}
+
+// DNSFromContext returns the DNS config set with WithDNS or nil.
+func DNSFromContext(ctx context.Context) *DNSConfig {
+	dns, _ := ctx.Value(dnsKey{}).(*DNSConfig)
+	return dns
+}
+
+type usernsKey struct{}
+
+// WithUserns returns a context with the user namespace mode for the
+// containers of the operations run with it.
+func WithUserns(ctx context.Context, mode string) context.Context {
+	return context.WithValue(ctx, usernsKey{}, mode)
+}
+
+// UsernsFromContext returns the user namespace mode set with WithUserns or
+// an empty string.
+func UsernsFromContext(ctx context.Context) string {
+	mode, _ := ctx.Value(usernsKey{}).(string)
+	return mode
+}
+
+type runtimeKey struct{}
+
+// WithRuntime returns a context with the OCI runtime for the containers of
+// the operations run with it that don't set their own.
+func WithRuntime(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, runtimeKey{}, name)
+}
+
+// RuntimeFromContext returns the OCI runtime set with WithRuntime or an empty
+// string.
+func RuntimeFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(runtimeKey{}).(string)
+	return name
+}
+
+// ResourceLimits are the cgroup limits of a container. Zero values are
+// unlimited.
+type ResourceLimits struct {
+	// CPU is the number of CPUs the container can use, e.g. 1.5
+	CPU float64
+	// Memory is the memory limit in bytes
+	Memory int64
+	// Pids is the maximum number of processes
+	Pids int64
+}
+
+type resourceLimitsKey struct{}
+
+// WithResourceLimits returns a context with the resource limits for the
+// containers of the operations run with it.
+func WithResourceLimits(ctx context.Context, limits *ResourceLimits) context.Context {
+	return context.WithValue(ctx, resourceLimitsKey{}, limits)
+}
+
+// ResourceLimitsFromContext returns the resource limits set with
+// WithResourceLimits or nil.
+func ResourceLimitsFromContext(ctx context.Context) *ResourceLimits {
+	limits, _ := ctx.Value(resourceLimitsKey{}).(*ResourceLimits)
+	return limits
 }
 
 type Mountable interface {
@@ -34,6 +134,11 @@ type Mount struct {
 	Selector string
 	Dest     string
 	Readonly bool
+	// Owner owns the root of the mount in the container. The files of the
+	// mount owned by root on disk are seen as owned by Owner through an
+	// idmapped mount, or the root of the mount is chowned if idmapped mounts
+	// are not supported.
+	Owner *idtools.Identity
 }
 
 type WinSize struct {
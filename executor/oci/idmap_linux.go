@@ -0,0 +1,408 @@
+package oci
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/containerd/containerd/mount"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/docker/pkg/reexec"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+const usernsHolderCmd = "buildkit-userns-holder"
+
+// flags of open_tree and move_mount missing from x/sys/unix
+const (
+	openTreeClone       = 0x1
+	moveMountFEmptyPath = 0x4
+)
+
+func init() {
+	reexec.Register(usernsHolderCmd, func() {
+		// keep the user namespace alive until the parent has opened it
+		io.Copy(ioutil.Discard, os.Stdin)
+	})
+}
+
+// IDMappedMounts mounts the rootfs and the mounts of a container with the
+// user namespace of its mapping, so that the files owned by root on disk are
+// owned by root in the container and the files created in the container are
+// owned by root on disk. It requires a kernel supporting idmapped mounts for
+// the filesystems of the mounts, e.g. 5.19 for overlayfs.
+type IDMappedMounts struct {
+	dir      string
+	usernsFd int
+	mounts   []string
+}
+
+// NewIDMappedMounts creates the user namespace of idmap. The idmapped mounts
+// are created in dir.
+func NewIDMappedMounts(dir string, idmap *idtools.IdentityMapping) (*IDMappedMounts, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	fd, err := usernsFd(idmap)
+	if err != nil {
+		return nil, err
+	}
+	return &IDMappedMounts{dir: dir, usernsFd: fd}, nil
+}
+
+// Mount returns a path where src is mounted with the mapping.
+func (m *IDMappedMounts) Mount(src string) (string, error) {
+	fi, err := os.Stat(src)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	target := filepath.Join(m.dir, strconv.Itoa(len(m.mounts)))
+	if fi.IsDir() {
+		err = os.Mkdir(target, 0700)
+	} else {
+		err = ioutil.WriteFile(target, nil, 0600)
+	}
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	fd, err := openTree(src)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to clone mount %s", src)
+	}
+	defer unix.Close(fd)
+	attr := unix.MountAttr{
+		Attr_set:  unix.MOUNT_ATTR_IDMAP,
+		Userns_fd: uint64(m.usernsFd),
+	}
+	if err := unix.MountSetattr(fd, "", unix.AT_EMPTY_PATH|unix.AT_RECURSIVE, &attr); err != nil {
+		return "", errors.Wrapf(err, "failed to idmap mount %s", src)
+	}
+	if err := moveMount(fd, target); err != nil {
+		return "", errors.Wrapf(err, "failed to attach idmapped mount of %s", src)
+	}
+	m.mounts = append(m.mounts, target)
+	return target, nil
+}
+
+// Remap replaces the sources of the bind and overlay mounts of the spec
+// with idmapped mounts, except for the sources in skip.
+func (m *IDMappedMounts) Remap(s *specs.Spec, skip ...string) error {
+	for i, sm := range s.Mounts {
+		if contains(skip, sm.Source) {
+			continue
+		}
+		switch sm.Type {
+		case "bind":
+			p, err := m.Mount(sm.Source)
+			if err != nil {
+				return err
+			}
+			s.Mounts[i].Source = p
+		case "overlay":
+			// overlay mounts are mounted once with the mapping and bind
+			// mounted in the container
+			p, err := m.mountOverlay(sm)
+			if err != nil {
+				return err
+			}
+			mp, err := m.Mount(p)
+			if err != nil {
+				return err
+			}
+			s.Mounts[i] = bindMount(sm, mp)
+		}
+	}
+	return nil
+}
+
+// mountOverlay mounts the overlay mount sm in the directory of m.
+func (m *IDMappedMounts) mountOverlay(sm specs.Mount) (string, error) {
+	p := filepath.Join(m.dir, "overlay"+strconv.Itoa(len(m.mounts)))
+	if err := os.Mkdir(p, 0700); err != nil {
+		return "", errors.WithStack(err)
+	}
+	if err := mount.All([]mount.Mount{{Type: sm.Type, Source: sm.Source, Options: sm.Options}}, p); err != nil {
+		return "", errors.Wrapf(err, "failed to mount %s", sm.Destination)
+	}
+	m.mounts = append(m.mounts, p)
+	return p, nil
+}
+
+// bindMount returns the bind mount of src replacing sm.
+func bindMount(sm specs.Mount, src string) specs.Mount {
+	opts := []string{"rbind"}
+	for _, opt := range sm.Options {
+		if opt == "ro" {
+			opts = append(opts, opt)
+		}
+	}
+	return specs.Mount{
+		Destination: sm.Destination,
+		Type:        "bind",
+		Source:      src,
+		Options:     opts,
+	}
+}
+
+// RemapOwners makes the owners, by destination, the owners of the roots of
+// the mounts of the spec. The mounts are idmapped so that the files owned by
+// root on disk are owned by the owner in the container, and the files owned
+// by the owner on disk are owned by root. idmap is the mapping of the user
+// namespace of the container, nil if it runs with the IDs of the daemon. If
+// the kernel doesn't support idmapped mounts of a mount, its root is chowned
+// instead. The sources of the idmapped mounts are returned for Remap to skip
+// them.
+func RemapOwners(s *specs.Spec, dir string, owners map[string]idtools.Identity, idmap *idtools.IdentityMapping) (_ []string, release func() error, err error) {
+	var (
+		sources []string
+		mounts  []*IDMappedMounts
+	)
+	release = func() error {
+		var rerr error
+		for i := len(mounts) - 1; i >= 0; i-- {
+			if err := mounts[i].Close(); err != nil && rerr == nil {
+				rerr = err
+			}
+		}
+		return rerr
+	}
+	defer func() {
+		if err != nil {
+			release()
+		}
+	}()
+	for i, sm := range s.Mounts {
+		owner, ok := owners[sm.Destination]
+		if !ok || (sm.Type != "bind" && sm.Type != "overlay") {
+			continue
+		}
+		mapping, err := ownerMapping(owner, idmap)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to map the owner of %s", sm.Destination)
+		}
+		m, err := NewIDMappedMounts(filepath.Join(dir, strconv.Itoa(len(mounts))), mapping)
+		if err != nil {
+			return nil, nil, err
+		}
+		mounts = append(mounts, m)
+		src := sm.Source
+		if sm.Type == "overlay" {
+			if src, err = m.mountOverlay(sm); err != nil {
+				return nil, nil, err
+			}
+			s.Mounts[i] = bindMount(sm, src)
+		}
+		p, err := m.Mount(src)
+		if err != nil {
+			if !idmapUnsupported(err) {
+				return nil, nil, err
+			}
+			if err := os.Chown(src, owner.UID, owner.GID); err != nil {
+				return nil, nil, errors.Wrapf(err, "failed to chown %s", sm.Destination)
+			}
+			continue
+		}
+		s.Mounts[i].Source = p
+		sources = append(sources, p)
+	}
+	return sources, release, nil
+}
+
+// idmapUnsupported returns whether err is the error of a kernel that doesn't
+// support idmapped mounts, or idmapped mounts of the filesystem.
+func idmapUnsupported(err error) bool {
+	return errors.Is(err, unix.ENOSYS) || errors.Is(err, unix.EINVAL) || errors.Is(err, unix.EOPNOTSUPP)
+}
+
+// ownerMapping returns the mapping of the idmapped mounts making owner the
+// owner of the files owned by root on disk, and the reverse. The other IDs
+// are mapped by idmap, or by the user namespace of the daemon if idmap is
+// nil.
+func ownerMapping(owner idtools.Identity, idmap *idtools.IdentityMapping) (*idtools.IdentityMapping, error) {
+	var uids, gids []idtools.IDMap
+	if idmap != nil {
+		uids, gids = idmap.UIDs(), idmap.GIDs()
+	} else {
+		var err error
+		if uids, err = currentIDs("/proc/self/uid_map"); err != nil {
+			return nil, err
+		}
+		if gids, err = currentIDs("/proc/self/gid_map"); err != nil {
+			return nil, err
+		}
+	}
+	if !mapped(uids, owner.UID) {
+		return nil, errors.Errorf("uid %d is not mapped", owner.UID)
+	}
+	if !mapped(gids, owner.GID) {
+		return nil, errors.Errorf("gid %d is not mapped", owner.GID)
+	}
+	return idtools.NewIDMappingsFromMaps(swapIDs(uids, owner.UID), swapIDs(gids, owner.GID)), nil
+}
+
+// currentIDs returns the IDs of the user namespace of the daemon, mapped to
+// themselves.
+func currentIDs(p string) ([]idtools.IDMap, error) {
+	dt, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var ids []idtools.IDMap
+	for _, line := range strings.Split(string(dt), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		start, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", p)
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", p)
+		}
+		ids = append(ids, idtools.IDMap{ContainerID: start, HostID: start, Size: int(size)})
+	}
+	return ids, nil
+}
+
+func mapped(m []idtools.IDMap, id int) bool {
+	for _, r := range m {
+		if id >= r.ContainerID && id-r.ContainerID < r.Size {
+			return true
+		}
+	}
+	return false
+}
+
+// swapIDs returns the mapping m with the host IDs of the container IDs 0 and
+// id swapped.
+func swapIDs(m []idtools.IDMap, id int) []idtools.IDMap {
+	if id == 0 {
+		return m
+	}
+	var out []idtools.IDMap
+	for _, r := range m {
+		pos, end := r.ContainerID, r.ContainerID+r.Size
+		for _, p := range []int{0, id} {
+			if p < pos || p >= end {
+				continue
+			}
+			if p > pos {
+				out = append(out, idtools.IDMap{ContainerID: pos, HostID: r.HostID + pos - r.ContainerID, Size: p - pos})
+			}
+			swapped := id
+			if p == id {
+				swapped = 0
+			}
+			out = append(out, idtools.IDMap{ContainerID: swapped, HostID: r.HostID + p - r.ContainerID, Size: 1})
+			pos = p + 1
+		}
+		if pos < end {
+			out = append(out, idtools.IDMap{ContainerID: pos, HostID: r.HostID + pos - r.ContainerID, Size: end - pos})
+		}
+	}
+	return out
+}
+
+// Close unmounts the idmapped mounts and releases the user namespace.
+func (m *IDMappedMounts) Close() error {
+	var rerr error
+	for i := len(m.mounts) - 1; i >= 0; i-- {
+		if err := unix.Unmount(m.mounts[i], unix.MNT_DETACH); err != nil && rerr == nil {
+			rerr = errors.Wrapf(err, "failed to unmount %s", m.mounts[i])
+		}
+	}
+	m.mounts = nil
+	unix.Close(m.usernsFd)
+	if err := os.RemoveAll(m.dir); err != nil && rerr == nil {
+		rerr = errors.WithStack(err)
+	}
+	return rerr
+}
+
+// usernsFd returns a file descriptor of a new user namespace with the
+// mapping of idmap.
+func usernsFd(idmap *idtools.IdentityMapping) (int, error) {
+	cmd := reexec.Command(usernsHolderCmd)
+	cmd.SysProcAttr.Cloneflags = unix.CLONE_NEWUSER
+	cmd.SysProcAttr.UidMappings = sysMapping(idmap.UIDs())
+	cmd.SysProcAttr.GidMappings = sysMapping(idmap.GIDs())
+	cmd.SysProcAttr.GidMappingsEnableSetgroups = true
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return -1, errors.WithStack(err)
+	}
+	if err := cmd.Start(); err != nil {
+		return -1, errors.Wrap(err, "failed to create user namespace")
+	}
+	defer func() {
+		stdin.Close()
+		cmd.Wait()
+	}()
+	fd, err := unix.Open(fmt.Sprintf("/proc/%d/ns/user", cmd.Process.Pid), unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return -1, errors.Wrap(err, "failed to open user namespace")
+	}
+	return fd, nil
+}
+
+func openTree(path string) (int, error) {
+	p, err := unix.BytePtrFromString(path)
+	if err != nil {
+		return -1, err
+	}
+	dirfd := unix.AT_FDCWD
+	fd, _, errno := unix.Syscall(unix.SYS_OPEN_TREE, uintptr(dirfd), uintptr(unsafe.Pointer(p)), uintptr(openTreeClone|unix.O_CLOEXEC|unix.AT_RECURSIVE))
+	if errno != 0 {
+		return -1, errno
+	}
+	return int(fd), nil
+}
+
+func moveMount(fd int, target string) error {
+	empty, err := unix.BytePtrFromString("")
+	if err != nil {
+		return err
+	}
+	p, err := unix.BytePtrFromString(target)
+	if err != nil {
+		return err
+	}
+	dirfd := unix.AT_FDCWD
+	_, _, errno := unix.Syscall6(unix.SYS_MOVE_MOUNT, uintptr(fd), uintptr(unsafe.Pointer(empty)), uintptr(dirfd), uintptr(unsafe.Pointer(p)), moveMountFEmptyPath, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func sysMapping(m []idtools.IDMap) []syscall.SysProcIDMap {
+	var ids []syscall.SysProcIDMap
+	for _, item := range m {
+		ids = append(ids, syscall.SysProcIDMap{
+			ContainerID: item.ContainerID,
+			HostID:      item.HostID,
+			Size:        item.Size,
+		})
+	}
+	return ids
+}
+
+func contains(s []string, v string) bool {
+	for _, vv := range s {
+		if vv == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package oci
+
+import (
+	"testing"
+
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwapIDs(t *testing.T) {
+	m := []idtools.IDMap{{ContainerID: 0, HostID: 100000, Size: 65536}}
+
+	require.Equal(t, m, swapIDs(m, 0))
+
+	require.Equal(t, []idtools.IDMap{
+		{ContainerID: 1000, HostID: 100000, Size: 1},
+		{ContainerID: 1, HostID: 100001, Size: 999},
+		{ContainerID: 0, HostID: 101000, Size: 1},
+		{ContainerID: 1001, HostID: 101001, Size: 64535},
+	}, swapIDs(m, 1000))
+
+	require.Equal(t, []idtools.IDMap{
+		{ContainerID: 65535, HostID: 100000, Size: 1},
+		{ContainerID: 1, HostID: 100001, Size: 65534},
+		{ContainerID: 0, HostID: 165535, Size: 1},
+	}, swapIDs(m, 65535))
+
+	// ranges not starting at 0
+	m = []idtools.IDMap{
+		{ContainerID: 0, HostID: 0, Size: 1},
+		{ContainerID: 1, HostID: 100000, Size: 10},
+	}
+	require.Equal(t, []idtools.IDMap{
+		{ContainerID: 5, HostID: 0, Size: 1},
+		{ContainerID: 1, HostID: 100000, Size: 4},
+		{ContainerID: 0, HostID: 100004, Size: 1},
+		{ContainerID: 6, HostID: 100005, Size: 5},
+	}, swapIDs(m, 5))
+}
+
+func TestOwnerMappingUnmapped(t *testing.T) {
+	idmap := idtools.NewIDMappingsFromMaps(
+		[]idtools.IDMap{{ContainerID: 0, HostID: 100000, Size: 1000}},
+		[]idtools.IDMap{{ContainerID: 0, HostID: 100000, Size: 1000}},
+	)
+	_, err := ownerMapping(idtools.Identity{UID: 1000, GID: 0}, idmap)
+	require.Error(t, err)
+
+	m, err := ownerMapping(idtools.Identity{UID: 10, GID: 20}, idmap)
+	require.NoError(t, err)
+	require.Equal(t, swapIDs(idmap.UIDs(), 10), m.UIDs())
+	require.Equal(t, swapIDs(idmap.GIDs(), 20), m.GIDs())
+}
@@ -0,0 +1,34 @@
+//go:build !linux
+// +build !linux
+
+package oci
+
+import (
+	"github.com/docker/docker/pkg/idtools"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
+)
+
+// IDMappedMounts mounts the rootfs and the mounts of a container with the
+// user namespace of its mapping. It is only supported on Linux.
+type IDMappedMounts struct{}
+
+func NewIDMappedMounts(dir string, idmap *idtools.IdentityMapping) (*IDMappedMounts, error) {
+	return nil, errors.New("idmapped mounts are only supported on Linux")
+}
+
+func (m *IDMappedMounts) Mount(src string) (string, error) {
+	return "", errors.New("idmapped mounts are only supported on Linux")
+}
+
+func (m *IDMappedMounts) Remap(s *specs.Spec, skip ...string) error {
+	return errors.New("idmapped mounts are only supported on Linux")
+}
+
+func (m *IDMappedMounts) Close() error {
+	return nil
+}
+
+func RemapOwners(s *specs.Spec, dir string, owners map[string]idtools.Identity, idmap *idtools.IdentityMapping) ([]string, func() error, error) {
+	return nil, nil, errors.New("idmapped mounts are only supported on Linux")
+}
@@ -7,9 +7,35 @@ import (
 
 	"github.com/containerd/containerd/containers"
 	"github.com/containerd/containerd/oci"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/moby/buildkit/executor"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
 )
 
+// MountOwners returns the owners of the mounts, by destination, for
+// RemapOwners. idmap is the identity mapping of the worker, the owners are
+// mapped to the IDs on disk with it.
+func MountOwners(mounts []executor.Mount, idmap *idtools.IdentityMapping) (map[string]idtools.Identity, error) {
+	var owners map[string]idtools.Identity
+	for _, m := range mounts {
+		if m.Owner == nil {
+			continue
+		}
+		owner := *m.Owner
+		if idmap != nil {
+			var err error
+			if owner, err = idmap.ToHost(owner); err != nil {
+				return nil, err
+			}
+		}
+		if owners == nil {
+			owners = map[string]idtools.Identity{}
+		}
+		owners[m.Dest] = owner
+	}
+	return owners, nil
+}
+
 func withRemovedMount(destination string) oci.SpecOpts {
 	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *specs.Spec) error {
 		newMounts := []specs.Mount{}
@@ -2,6 +2,7 @@ package oci
 
 import (
 	"context"
+	"encoding/json"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -9,7 +10,9 @@ import (
 	"github.com/docker/docker/libnetwork/resolvconf"
 	"github.com/docker/docker/libnetwork/types"
 	"github.com/docker/docker/pkg/idtools"
+	"github.com/moby/buildkit/executor"
 	"github.com/moby/buildkit/util/flightcontrol"
+	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
 )
 
@@ -61,64 +64,111 @@ func GetResolvConf(ctx context.Context, stateDir string, idmap *idtools.Identity
 		if !generate {
 			return "", nil
 		}
+		return "", writeResolvConf(p, idmap, dns)
+	})
+	if err != nil {
+		return "", err
+	}
+	return p, nil
+}
 
-		var dt []byte
-		f, err := resolvconfGet()
-		if err != nil {
-			if !errors.Is(err, os.ErrNotExist) {
-				return "", err
+// GetOverrideResolvConf returns the path of the resolv.conf file of the DNS
+// config of the worker with the fields set by override replaced. The files of
+// the configs are kept in stateDir and regenerated when the resolv.conf file
+// of the host changes.
+func GetOverrideResolvConf(ctx context.Context, stateDir string, idmap *idtools.IdentityMapping, dns *DNSConfig, override *executor.DNSConfig) (string, error) {
+	if override == nil {
+		return GetResolvConf(ctx, stateDir, idmap, dns)
+	}
+	merged := DNSConfig{}
+	if dns != nil {
+		merged = *dns
+	}
+	if len(override.Nameservers) > 0 {
+		merged.Nameservers = override.Nameservers
+	}
+	if len(override.SearchDomains) > 0 {
+		merged.SearchDomains = override.SearchDomains
+	}
+	if len(override.Options) > 0 {
+		merged.Options = override.Options
+	}
+	dt, err := json.Marshal(merged)
+	if err != nil {
+		return "", err
+	}
+	p := filepath.Join(stateDir, "resolv.conf."+digest.FromBytes(dt).Encoded()[:16])
+	_, err = g.Do(ctx, p, func(ctx context.Context) (interface{}, error) {
+		fi, err := os.Stat(p)
+		if err == nil {
+			fiMain, err := os.Stat(resolvconf.Path())
+			if err != nil || !fi.ModTime().Before(fiMain.ModTime()) {
+				return nil, nil
 			}
-		} else {
-			dt = f.Content
 		}
+		return nil, writeResolvConf(p, idmap, &merged)
+	})
+	if err != nil {
+		return "", err
+	}
+	return p, nil
+}
 
-		if dns != nil {
-			var (
-				dnsNameservers   = resolvconf.GetNameservers(dt, types.IP)
-				dnsSearchDomains = resolvconf.GetSearchDomains(dt)
-				dnsOptions       = resolvconf.GetOptions(dt)
-			)
-			if len(dns.Nameservers) > 0 {
-				dnsNameservers = dns.Nameservers
-			}
-			if len(dns.SearchDomains) > 0 {
-				dnsSearchDomains = dns.SearchDomains
-			}
-			if len(dns.Options) > 0 {
-				dnsOptions = dns.Options
-			}
+// writeResolvConf writes the resolv.conf file of the host to p with the
+// fields set by dns replaced.
+func writeResolvConf(p string, idmap *idtools.IdentityMapping, dns *DNSConfig) error {
+	var dt []byte
+	f, err := resolvconfGet()
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	} else {
+		dt = f.Content
+	}
 
-			f, err = resolvconf.Build(p+".tmp", dnsNameservers, dnsSearchDomains, dnsOptions)
-			if err != nil {
-				return "", err
-			}
-			dt = f.Content
+	if dns != nil {
+		var (
+			dnsNameservers   = resolvconf.GetNameservers(dt, types.IP)
+			dnsSearchDomains = resolvconf.GetSearchDomains(dt)
+			dnsOptions       = resolvconf.GetOptions(dt)
+		)
+		if len(dns.Nameservers) > 0 {
+			dnsNameservers = dns.Nameservers
+		}
+		if len(dns.SearchDomains) > 0 {
+			dnsSearchDomains = dns.SearchDomains
+		}
+		if len(dns.Options) > 0 {
+			dnsOptions = dns.Options
 		}
 
-		f, err = resolvconf.FilterResolvDNS(dt, true)
+		f, err = resolvconf.Build(p+".tmp", dnsNameservers, dnsSearchDomains, dnsOptions)
 		if err != nil {
-			return "", err
+			return err
 		}
+		dt = f.Content
+	}
 
-		tmpPath := p + ".tmp"
-		if err := ioutil.WriteFile(tmpPath, f.Content, 0644); err != nil {
-			return "", err
-		}
+	f, err = resolvconf.FilterResolvDNS(dt, true)
+	if err != nil {
+		return err
+	}
 
-		if idmap != nil {
-			root := idmap.RootPair()
-			if err := os.Chown(tmpPath, root.UID, root.GID); err != nil {
-				return "", err
-			}
-		}
+	tmpPath := p + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, f.Content, 0644); err != nil {
+		return err
+	}
 
-		if err := os.Rename(tmpPath, p); err != nil {
-			return "", err
+	if idmap != nil {
+		root := idmap.RootPair()
+		if err := os.Chown(tmpPath, root.UID, root.GID); err != nil {
+			return err
 		}
-		return "", nil
-	})
-	if err != nil {
-		return "", err
 	}
-	return p, nil
+
+	if err := os.Rename(tmpPath, p); err != nil {
+		return err
+	}
+	return nil
 }
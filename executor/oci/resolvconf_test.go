@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/docker/docker/libnetwork/resolvconf"
+	"github.com/moby/buildkit/executor"
 	"github.com/stretchr/testify/require"
 )
 
@@ -37,3 +38,40 @@ nameserver 2001:4860:4860::8844`
 	require.NoError(t, err)
 	require.Equal(t, string(b), defaultResolvConf)
 }
+
+// TestOverrideResolvConf modifies a global variable
+// It must not run in parallel.
+func TestOverrideResolvConf(t *testing.T) {
+	oldResolvconfGet := resolvconfGet
+	defer func() {
+		resolvconfGet = oldResolvconfGet
+	}()
+	resolvconfGet = func() (*resolvconf.File, error) {
+		return &resolvconf.File{Content: []byte("nameserver 10.0.0.1\nsearch example.com\n")}, nil
+	}
+
+	dir, err := ioutil.TempDir("", "buildkit-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+
+	p1, err := GetOverrideResolvConf(ctx, dir, nil, nil, &executor.DNSConfig{Nameservers: []string{"10.1.0.53"}})
+	require.NoError(t, err)
+	b, err := ioutil.ReadFile(p1)
+	require.NoError(t, err)
+	require.Contains(t, string(b), "nameserver 10.1.0.53")
+	require.NotContains(t, string(b), "10.0.0.1")
+	require.Contains(t, string(b), "search example.com")
+
+	p2, err := GetOverrideResolvConf(ctx, dir, nil, nil, &executor.DNSConfig{SearchDomains: []string{"corp.example.com"}})
+	require.NoError(t, err)
+	require.NotEqual(t, p1, p2)
+	b, err = ioutil.ReadFile(p2)
+	require.NoError(t, err)
+	require.Contains(t, string(b), "nameserver 10.0.0.1")
+	require.Contains(t, string(b), "search corp.example.com")
+
+	p3, err := GetOverrideResolvConf(ctx, dir, nil, nil, &executor.DNSConfig{Nameservers: []string{"10.1.0.53"}})
+	require.NoError(t, err)
+	require.Equal(t, p1, p3)
+}
@@ -13,10 +13,12 @@ import (
 	cdseccomp "github.com/containerd/containerd/pkg/seccomp"
 	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/profiles/seccomp"
+	"github.com/moby/buildkit/executor"
 	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/entitlements/security"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/opencontainers/selinux/go-selinux/label"
+	"github.com/pkg/errors"
 )
 
 func generateMountOpts(resolvConf, hostsFile string) ([]oci.SpecOpts, error) {
@@ -30,7 +32,7 @@ func generateMountOpts(resolvConf, hostsFile string) ([]oci.SpecOpts, error) {
 }
 
 // generateSecurityOpts may affect mounts, so must be called after generateMountOpts
-func generateSecurityOpts(mode pb.SecurityMode, apparmorProfile string) (opts []oci.SpecOpts, _ error) {
+func generateSecurityOpts(mode pb.SecurityMode, seccompProfile, apparmorProfile string) (opts []oci.SpecOpts, _ error) {
 	switch mode {
 	case pb.SecurityMode_INSECURE:
 		return []oci.SpecOpts{
@@ -44,8 +46,14 @@ func generateSecurityOpts(mode pb.SecurityMode, apparmorProfile string) (opts []
 			},
 		}, nil
 	case pb.SecurityMode_SANDBOX:
-		if cdseccomp.IsEnabled() {
-			opts = append(opts, withDefaultProfile())
+		switch seccompProfile {
+		case "", pb.SeccompProfileDefault:
+			if cdseccomp.IsEnabled() {
+				opts = append(opts, withDefaultProfile())
+			}
+		case pb.SecurityProfileUnconfined:
+		default:
+			return nil, errors.Errorf("unsupported seccomp profile %q", seccompProfile)
 		}
 		if apparmorProfile != "" {
 			opts = append(opts, oci.WithApparmorProfile(apparmorProfile))
@@ -104,6 +112,42 @@ func generateRlimitOpts(ulimits []*pb.Ulimit) ([]oci.SpecOpts, error) {
 	}, nil
 }
 
+// cpuPeriod is the CFS period the CPU limit of containers is set for
+const cpuPeriod = 100000
+
+func generateResourceOpts(limits *executor.ResourceLimits) ([]oci.SpecOpts, error) {
+	if limits == nil {
+		return nil, nil
+	}
+	if limits.CPU < 0 || limits.Memory < 0 || limits.Pids < 0 {
+		return nil, errors.Errorf("invalid resource limits %+v", *limits)
+	}
+	return []oci.SpecOpts{
+		func(_ context.Context, _ oci.Client, _ *containers.Container, s *specs.Spec) error {
+			if s.Linux == nil {
+				s.Linux = &specs.Linux{}
+			}
+			if s.Linux.Resources == nil {
+				s.Linux.Resources = &specs.LinuxResources{}
+			}
+			r := s.Linux.Resources
+			if limits.CPU > 0 {
+				period := uint64(cpuPeriod)
+				quota := int64(limits.CPU * cpuPeriod)
+				r.CPU = &specs.LinuxCPU{Period: &period, Quota: &quota}
+			}
+			if limits.Memory > 0 {
+				memory := limits.Memory
+				r.Memory = &specs.LinuxMemory{Limit: &memory}
+			}
+			if limits.Pids > 0 {
+				r.Pids = &specs.LinuxPids{Limit: limits.Pids}
+			}
+			return nil
+		},
+	}, nil
+}
+
 // withDefaultProfile sets the default seccomp profile to the spec.
 // Note: must follow the setting of process capabilities
 func withDefaultProfile() oci.SpecOpts {
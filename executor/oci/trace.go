@@ -0,0 +1,41 @@
+package oci
+
+import (
+	"context"
+
+	"github.com/moby/buildkit/executor"
+	"github.com/moby/buildkit/util/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartExecSpan starts the span of the process run in the container. The
+// span records the events of the container and is the parent of the trace
+// context propagated into the process.
+func StartExecSpan(ctx context.Context, id string, meta executor.Meta) (trace.Span, context.Context) {
+	return tracing.StartSpan(ctx, "exec", trace.WithAttributes(
+		attribute.String("container.id", id),
+		attribute.String("container.network", meta.NetMode.String()),
+	))
+}
+
+// ContainerCreated adds the creation event of the container to the span of
+// the context.
+func ContainerCreated(ctx context.Context) {
+	trace.SpanFromContext(ctx).AddEvent("Container created")
+}
+
+// ContainerStarted adds the start event of the container to the span of the
+// context.
+func ContainerStarted(ctx context.Context) {
+	trace.SpanFromContext(ctx).AddEvent("Container started")
+}
+
+// ContainerExited adds the exit event of the container with the exit code
+// of its process to the span of the context.
+func ContainerExited(ctx context.Context, exitCode uint32) {
+	trace.SpanFromContext(ctx).AddEvent(
+		"Container exited",
+		trace.WithAttributes(attribute.Int("exit.code", int(exitCode))),
+	)
+}
@@ -0,0 +1,74 @@
+package oci
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/moby/buildkit/executor"
+	"github.com/moby/buildkit/solver/pb"
+	traceexec "github.com/moby/buildkit/util/tracing/exec"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (r *spanRecorder) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (r *spanRecorder) OnEnd(s sdktrace.ReadOnlySpan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, s)
+}
+
+func (r *spanRecorder) Shutdown(context.Context) error   { return nil }
+func (r *spanRecorder) ForceFlush(context.Context) error { return nil }
+
+func TestExecSpan(t *testing.T) {
+	rec := &spanRecorder{}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	ctx, parent := tp.Tracer("").Start(context.TODO(), "vertex")
+
+	span, ctx := StartExecSpan(ctx, "ctr1", executor.Meta{NetMode: pb.NetMode_NONE})
+	ContainerCreated(ctx)
+	ContainerStarted(ctx)
+	ContainerExited(ctx, 2)
+
+	// the process is traced as a child of the exec span
+	var traceparent string
+	for _, env := range traceexec.Environ(ctx) {
+		if strings.HasPrefix(env, "TRACEPARENT=") {
+			traceparent = strings.TrimPrefix(env, "TRACEPARENT=")
+		}
+	}
+	require.Contains(t, traceparent, span.SpanContext().SpanID().String())
+
+	span.End()
+	parent.End()
+
+	require.Len(t, rec.spans, 2)
+	s := rec.spans[0]
+	require.Equal(t, "exec", s.Name())
+	require.Equal(t, parent.SpanContext().SpanID(), s.Parent().SpanID())
+	require.Contains(t, s.Attributes(), attribute.String("container.id", "ctr1"))
+
+	events := s.Events()
+	require.Len(t, events, 3)
+	require.Equal(t, "Container created", events[0].Name)
+	require.Equal(t, "Container started", events[1].Name)
+	require.Equal(t, "Container exited", events[2].Name)
+	require.Contains(t, events[2].Attributes, attribute.Int("exit.code", 2))
+
+	// there are no exec spans without a parent span
+	span, ctx = StartExecSpan(context.TODO(), "ctr2", executor.Meta{})
+	ContainerStarted(ctx)
+	span.End()
+	require.False(t, span.SpanContext().IsValid())
+	require.Len(t, rec.spans, 2)
+}
@@ -0,0 +1,130 @@
+package oci
+
+import (
+	"sync"
+
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/pkg/errors"
+)
+
+const (
+	// UsernsHost runs the containers of a build with the uids of the host.
+	UsernsHost = "host"
+	// UsernsPrivate runs the containers of a build in a user namespace
+	// mapped to a range of uids and gids no other container uses.
+	UsernsPrivate = "private"
+)
+
+// DefaultUsernsSize is the number of uids and gids mapped for each container
+// with UsernsPrivate if no size is configured.
+const DefaultUsernsSize = 65536
+
+// UsernsRemapper allocates the uid and gid ranges of the containers run with
+// UsernsPrivate from the subordinate ranges of a user. The files of the
+// rootfs and of the mounts of the containers keep their uids on disk, so the
+// snapshots are shared with the builds using other ranges.
+type UsernsRemapper struct {
+	mu          sync.Mutex
+	uids        []int
+	gids        []int
+	size        int
+	used        []bool
+	defaultMode string
+}
+
+// NewUsernsRemapper returns a remapper allocating ranges of size uids and gids
+// from the host ids of ranges. defaultMode is the mode of the builds that
+// don't set one.
+func NewUsernsRemapper(ranges *idtools.IdentityMapping, size int, defaultMode string) (*UsernsRemapper, error) {
+	if ranges == nil || ranges.Empty() {
+		return nil, errors.New("no subordinate uid and gid ranges for user namespace remapping")
+	}
+	if size <= 0 {
+		size = DefaultUsernsSize
+	}
+	switch defaultMode {
+	case "":
+		defaultMode = UsernsHost
+	case UsernsHost, UsernsPrivate:
+	default:
+		return nil, errors.Errorf("invalid user namespace mode %q", defaultMode)
+	}
+	uids := slots(ranges.UIDs(), size)
+	gids := slots(ranges.GIDs(), size)
+	if len(gids) < len(uids) {
+		uids = uids[:len(gids)]
+	}
+	gids = gids[:len(uids)]
+	if len(uids) == 0 {
+		return nil, errors.Errorf("subordinate uid and gid ranges are smaller than %d", size)
+	}
+	return &UsernsRemapper{
+		uids:        uids,
+		gids:        gids,
+		size:        size,
+		used:        make([]bool, len(uids)),
+		defaultMode: defaultMode,
+	}, nil
+}
+
+// ValidateUsernsMode returns an error if mode isn't a user namespace mode.
+func ValidateUsernsMode(mode string) error {
+	switch mode {
+	case "", UsernsHost, UsernsPrivate:
+		return nil
+	}
+	return errors.Errorf("invalid user namespace mode %q", mode)
+}
+
+// Get returns the mapping of a container run in mode, or nil if the container
+// runs with the uids of the host. release frees the range of the mapping.
+func (r *UsernsRemapper) Get(mode string) (*idtools.IdentityMapping, func(), error) {
+	if err := ValidateUsernsMode(mode); err != nil {
+		return nil, nil, err
+	}
+	if r == nil {
+		if mode == UsernsPrivate {
+			return nil, nil, errors.New("user namespace remapping is not configured for this worker")
+		}
+		return nil, func() {}, nil
+	}
+	if mode == "" {
+		mode = r.defaultMode
+	}
+	if mode == UsernsHost {
+		return nil, func() {}, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, used := range r.used {
+		if used {
+			continue
+		}
+		r.used[i] = true
+		idmap := idtools.NewIDMappingsFromMaps(
+			[]idtools.IDMap{{ContainerID: 0, HostID: r.uids[i], Size: r.size}},
+			[]idtools.IDMap{{ContainerID: 0, HostID: r.gids[i], Size: r.size}},
+		)
+		var once sync.Once
+		return idmap, func() {
+			once.Do(func() {
+				r.mu.Lock()
+				r.used[i] = false
+				r.mu.Unlock()
+			})
+		}, nil
+	}
+	return nil, nil, errors.Errorf("all %d user namespace ranges are in use", len(r.used))
+}
+
+// slots returns the first host ids of the ranges of size that fit in m.
+func slots(m []idtools.IDMap, size int) []int {
+	var ids []int
+	for _, r := range m {
+		for start := r.HostID; start+size <= r.HostID+r.Size; start += size {
+			ids = append(ids, start)
+		}
+	}
+	return ids
+}
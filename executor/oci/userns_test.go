@@ -0,0 +1,61 @@
+package oci
+
+import (
+	"testing"
+
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsernsRemapper(t *testing.T) {
+	ranges := idtools.NewIDMappingsFromMaps(
+		[]idtools.IDMap{{ContainerID: 0, HostID: 100000, Size: 150000}},
+		[]idtools.IDMap{{ContainerID: 0, HostID: 200000, Size: 65536}, {ContainerID: 65536, HostID: 400000, Size: 65536}},
+	)
+	r, err := NewUsernsRemapper(ranges, 65536, UsernsPrivate)
+	require.NoError(t, err)
+
+	m1, release1, err := r.Get("")
+	require.NoError(t, err)
+	require.Equal(t, []idtools.IDMap{{ContainerID: 0, HostID: 100000, Size: 65536}}, m1.UIDs())
+	require.Equal(t, []idtools.IDMap{{ContainerID: 0, HostID: 200000, Size: 65536}}, m1.GIDs())
+
+	m2, release2, err := r.Get(UsernsPrivate)
+	require.NoError(t, err)
+	require.Equal(t, []idtools.IDMap{{ContainerID: 0, HostID: 165536, Size: 65536}}, m2.UIDs())
+	require.Equal(t, []idtools.IDMap{{ContainerID: 0, HostID: 400000, Size: 65536}}, m2.GIDs())
+
+	// the uid ranges only fit two containers
+	_, _, err = r.Get(UsernsPrivate)
+	require.Error(t, err)
+
+	m, release, err := r.Get(UsernsHost)
+	require.NoError(t, err)
+	require.Nil(t, m)
+	release()
+
+	release1()
+	release1()
+	m3, release3, err := r.Get(UsernsPrivate)
+	require.NoError(t, err)
+	require.Equal(t, m1.UIDs(), m3.UIDs())
+	release2()
+	release3()
+
+	_, _, err = r.Get("shared")
+	require.Error(t, err)
+
+	_, err = NewUsernsRemapper(ranges, 200000, "")
+	require.Error(t, err)
+}
+
+func TestUsernsRemapperNotConfigured(t *testing.T) {
+	var r *UsernsRemapper
+	m, release, err := r.Get("")
+	require.NoError(t, err)
+	require.Nil(t, m)
+	release()
+
+	_, _, err = r.Get(UsernsPrivate)
+	require.Error(t, err)
+}
@@ -12,8 +12,6 @@ import (
 	"time"
 
 	"github.com/moby/buildkit/util/bklog"
-	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/trace"
 
 	"github.com/containerd/containerd/mount"
 	containerdoci "github.com/containerd/containerd/oci"
@@ -28,6 +26,7 @@ import (
 	"github.com/moby/buildkit/util/network"
 	rootlessspecconv "github.com/moby/buildkit/util/rootless/specconv"
 	"github.com/moby/buildkit/util/stack"
+	"github.com/moby/buildkit/util/tracing"
 	"github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/pkg/errors"
 )
@@ -49,12 +48,25 @@ type Opt struct {
 	OOMScoreAdj     *int
 	ApparmorProfile string
 	TracingSocket   string
+	// UsernsRemapper allocates the user namespaces of the containers of the
+	// builds run with user namespace remapping
+	UsernsRemapper *oci.UsernsRemapper
+	// Runtimes are the alternative OCI runtimes the containers can be run
+	// with, e.g. runsc, keyed by name. The values are the names or the paths
+	// of their binaries.
+	Runtimes map[string]string
+	// DefaultRuntime is the name of the runtime of the containers that don't
+	// select one. The binary of CommandCandidates is used if it is empty.
+	DefaultRuntime string
 }
 
 var defaultCommandCandidates = []string{"buildkit-runc", "runc"}
 
 type runcExecutor struct {
 	runc             *runc.Runc
+	runtimes         map[string]*runc.Runc
+	defaultRuntime   string
+	containers       map[string]*runc.Runc
 	root             string
 	cgroupParent     string
 	rootless         bool
@@ -68,6 +80,7 @@ type runcExecutor struct {
 	mu               sync.Mutex
 	apparmorProfile  string
 	tracingSocket    string
+	userns           *oci.UsernsRemapper
 }
 
 func New(opt Opt, networkProviders map[pb.NetMode]network.Provider) (executor.Executor, error) {
@@ -107,19 +120,26 @@ func New(opt Opt, networkProviders map[pb.NetMode]network.Provider) (executor.Ex
 	os.RemoveAll(filepath.Join(root, "hosts"))
 	os.RemoveAll(filepath.Join(root, "resolv.conf"))
 
-	runtime := &runc.Runc{
-		Command:   cmd,
-		Log:       filepath.Join(root, "runc-log.json"),
-		LogFormat: runc.JSON,
-		Setpgid:   true,
-		// we don't execute runc with --rootless=(true|false) explicitly,
-		// so as to support non-runc runtimes
-	}
+	runtime := newRuntime(cmd, filepath.Join(root, "runc-log.json"))
 
-	updateRuncFieldsForHostOS(runtime)
+	runtimes := make(map[string]*runc.Runc, len(opt.Runtimes))
+	for name, binary := range opt.Runtimes {
+		if _, err := exec.LookPath(binary); err != nil {
+			return nil, errors.Wrapf(err, "failed to find %s binary of runtime %s", binary, name)
+		}
+		runtimes[name] = newRuntime(binary, filepath.Join(root, "runtime-"+name+"-log.json"))
+	}
+	if opt.DefaultRuntime != "" {
+		if _, ok := runtimes[opt.DefaultRuntime]; !ok {
+			return nil, errors.Errorf("unknown default runtime %q", opt.DefaultRuntime)
+		}
+	}
 
 	w := &runcExecutor{
 		runc:             runtime,
+		runtimes:         runtimes,
+		defaultRuntime:   opt.DefaultRuntime,
+		containers:       make(map[string]*runc.Runc),
 		root:             root,
 		cgroupParent:     opt.DefaultCgroupParent,
 		rootless:         opt.Rootless,
@@ -132,10 +152,41 @@ func New(opt Opt, networkProviders map[pb.NetMode]network.Provider) (executor.Ex
 		running:          make(map[string]chan error),
 		apparmorProfile:  opt.ApparmorProfile,
 		tracingSocket:    opt.TracingSocket,
+		userns:           opt.UsernsRemapper,
 	}
 	return w, nil
 }
 
+func newRuntime(cmd, log string) *runc.Runc {
+	runtime := &runc.Runc{
+		Command:   cmd,
+		Log:       log,
+		LogFormat: runc.JSON,
+		Setpgid:   true,
+		// we don't execute runc with --rootless=(true|false) explicitly,
+		// so as to support non-runc runtimes
+	}
+
+	updateRuncFieldsForHostOS(runtime)
+	return runtime
+}
+
+// runtime returns the OCI runtime with name, or the default runtime if name
+// is empty.
+func (w *runcExecutor) runtime(name string) (*runc.Runc, error) {
+	if name == "" {
+		name = w.defaultRuntime
+	}
+	if name == "" {
+		return w.runc, nil
+	}
+	rt, ok := w.runtimes[name]
+	if !ok {
+		return nil, errors.Errorf("unknown runtime %q", name)
+	}
+	return rt, nil
+}
+
 func (w *runcExecutor) Run(ctx context.Context, id string, root executor.Mount, mounts []executor.Mount, process executor.ProcessInfo, started chan<- struct{}) (err error) {
 	meta := process.Meta
 
@@ -147,6 +198,7 @@ func (w *runcExecutor) Run(ctx context.Context, id string, root executor.Mount,
 	defer func() {
 		w.mu.Lock()
 		delete(w.running, id)
+		delete(w.containers, id)
 		w.mu.Unlock()
 		done <- err
 		close(done)
@@ -157,11 +209,21 @@ func (w *runcExecutor) Run(ctx context.Context, id string, root executor.Mount,
 		}
 	}()
 
+	span, ctx := oci.StartExecSpan(ctx, id, meta)
+	defer func() {
+		tracing.FinishWithError(span, err)
+	}()
+
+	rt, err := w.runtime(meta.Runtime)
+	if err != nil {
+		return err
+	}
+
 	provider, ok := w.networkProviders[meta.NetMode]
 	if !ok {
 		return errors.Errorf("unknown network mode %s", meta.NetMode)
 	}
-	namespace, err := provider.New()
+	namespace, err := network.NewNamespace(provider, meta.NetworkName, meta.PublishPorts...)
 	if err != nil {
 		return err
 	}
@@ -171,7 +233,17 @@ func (w *runcExecutor) Run(ctx context.Context, id string, root executor.Mount,
 		bklog.G(ctx).Info("enabling HostNetworking")
 	}
 
-	resolvConf, err := oci.GetResolvConf(ctx, w.root, w.idmap, w.dns)
+	usernsMap, releaseUserns, err := w.userns.Get(meta.Userns)
+	if err != nil {
+		return err
+	}
+	defer releaseUserns()
+	idmap := w.idmap
+	if usernsMap != nil {
+		idmap = usernsMap
+	}
+
+	resolvConf, err := oci.GetOverrideResolvConf(ctx, w.root, w.idmap, w.dns, meta.DNS)
 	if err != nil {
 		return err
 	}
@@ -251,13 +323,41 @@ func (w *runcExecutor) Run(ctx context.Context, id string, root executor.Mount,
 		}
 	}
 
-	spec, cleanup, err := oci.GenerateSpec(ctx, meta, mounts, id, resolvConf, hostsFile, namespace, w.cgroupParent, w.processMode, w.idmap, w.apparmorProfile, w.tracingSocket, opts...)
+	spec, cleanup, err := oci.GenerateSpec(ctx, meta, mounts, id, resolvConf, hostsFile, namespace, w.cgroupParent, w.processMode, idmap, w.apparmorProfile, w.tracingSocket, opts...)
 	if err != nil {
 		return err
 	}
 	defer cleanup()
 
 	spec.Root.Path = rootFSPath
+	owners, err := oci.MountOwners(mounts, w.idmap)
+	if err != nil {
+		return err
+	}
+	var owned []string
+	if len(owners) > 0 {
+		var releaseOwners func() error
+		owned, releaseOwners, err = oci.RemapOwners(spec, filepath.Join(bundle, "owners"), owners, usernsMap)
+		if err != nil {
+			return err
+		}
+		defer releaseOwners()
+	}
+	if usernsMap != nil {
+		// the files keep their uids on disk, the container sees them through
+		// idmapped mounts
+		idmapped, err := oci.NewIDMappedMounts(filepath.Join(bundle, "idmap"), usernsMap)
+		if err != nil {
+			return err
+		}
+		defer idmapped.Close()
+		if spec.Root.Path, err = idmapped.Mount(rootFSPath); err != nil {
+			return err
+		}
+		if err := idmapped.Remap(spec, append([]string{resolvConf, hostsFile, w.tracingSocket}, owned...)...); err != nil {
+			return err
+		}
+	}
 	if root.Readonly {
 		spec.Root.Readonly = true
 	}
@@ -275,6 +375,9 @@ func (w *runcExecutor) Run(ctx context.Context, id string, root executor.Mount,
 	spec.Process.Terminal = meta.Tty
 	spec.Process.OOMScoreAdj = w.oomScoreAdj
 	if w.rootless {
+		if r := spec.Linux.Resources; r != nil && (r.CPU != nil || r.Memory != nil || r.Pids != nil) {
+			bklog.G(ctx).Warnf("resource limits of %s are not applied in rootless mode: run buildctl debug rootless-info for the limitations of the daemon host", id)
+		}
 		if err := rootlessspecconv.ToRootless(spec); err != nil {
 			return err
 		}
@@ -294,7 +397,7 @@ func (w *runcExecutor) Run(ctx context.Context, id string, root executor.Mount,
 			select {
 			case <-ctx.Done():
 				killCtx, timeout := context.WithTimeout(context.Background(), 7*time.Second)
-				if err := w.runc.Kill(killCtx, id, int(syscall.SIGKILL), nil); err != nil {
+				if err := rt.Kill(killCtx, id, int(syscall.SIGKILL), nil); err != nil {
 					bklog.G(ctx).Errorf("failed to kill runc %s: %+v", id, err)
 					select {
 					case <-killCtx.Done():
@@ -318,10 +421,14 @@ func (w *runcExecutor) Run(ctx context.Context, id string, root executor.Mount,
 
 	bklog.G(ctx).Debugf("> creating %s %v", id, meta.Args)
 
-	trace.SpanFromContext(ctx).AddEvent("Container created")
-	err = w.run(runCtx, id, bundle, process, func() {
+	w.mu.Lock()
+	w.containers[id] = rt
+	w.mu.Unlock()
+
+	oci.ContainerCreated(ctx)
+	err = w.run(runCtx, rt, id, bundle, process, func() {
 		startedOnce.Do(func() {
-			trace.SpanFromContext(ctx).AddEvent("Container started")
+			oci.ContainerStarted(ctx)
 			if started != nil {
 				close(started)
 			}
@@ -343,12 +450,7 @@ func exitError(ctx context.Context, err error) error {
 				ExitCode: uint32(runcExitError.Status),
 			}
 		}
-		trace.SpanFromContext(ctx).AddEvent(
-			"Container exited",
-			trace.WithAttributes(
-				attribute.Int("exit.code", int(exitErr.ExitCode)),
-			),
-		)
+		oci.ContainerExited(ctx, exitErr.ExitCode)
 		select {
 		case <-ctx.Done():
 			exitErr.Err = errors.Wrapf(ctx.Err(), exitErr.Error())
@@ -358,10 +460,7 @@ func exitError(ctx context.Context, err error) error {
 		}
 	}
 
-	trace.SpanFromContext(ctx).AddEvent(
-		"Container exited",
-		trace.WithAttributes(attribute.Int("exit.code", 0)),
-	)
+	oci.ContainerExited(ctx, 0)
 	return nil
 }
 
@@ -370,15 +469,21 @@ func (w *runcExecutor) Exec(ctx context.Context, id string, process executor.Pro
 	// is in the process of being created and check again every 100ms or until
 	// context is canceled.
 	var state *runc.Container
+	var rt *runc.Runc
 	for {
 		w.mu.Lock()
 		done, ok := w.running[id]
+		rt = w.containers[id]
 		w.mu.Unlock()
 		if !ok {
 			return errors.Errorf("container %s not found", id)
 		}
+		if rt == nil {
+			// the container is not created yet
+			rt = w.runc
+		}
 
-		state, _ = w.runc.State(ctx, id)
+		state, _ = rt.State(ctx, id)
 		if state != nil && state.Status == "running" {
 			break
 		}
@@ -428,7 +533,7 @@ func (w *runcExecutor) Exec(ctx context.Context, id string, process executor.Pro
 		spec.Process.Env = process.Meta.Env
 	}
 
-	err = w.exec(ctx, id, state.Bundle, spec.Process, process, nil)
+	err = w.exec(ctx, rt, id, state.Bundle, spec.Process, process, nil)
 	return exitError(ctx, err)
 }
 
@@ -21,9 +21,9 @@ func updateRuncFieldsForHostOS(runtime *runc.Runc) {
 	runtime.PdeathSignal = syscall.SIGKILL // this can still leak the process
 }
 
-func (w *runcExecutor) run(ctx context.Context, id, bundle string, process executor.ProcessInfo, started func()) error {
+func (w *runcExecutor) run(ctx context.Context, rt *runc.Runc, id, bundle string, process executor.ProcessInfo, started func()) error {
 	return w.callWithIO(ctx, id, bundle, process, started, func(ctx context.Context, started chan<- int, io runc.IO) error {
-		_, err := w.runc.Run(ctx, id, bundle, &runc.CreateOpts{
+		_, err := rt.Run(ctx, id, bundle, &runc.CreateOpts{
 			NoPivot: w.noPivot,
 			Started: started,
 			IO:      io,
@@ -32,9 +32,9 @@ func (w *runcExecutor) run(ctx context.Context, id, bundle string, process execu
 	})
 }
 
-func (w *runcExecutor) exec(ctx context.Context, id, bundle string, specsProcess *specs.Process, process executor.ProcessInfo, started func()) error {
+func (w *runcExecutor) exec(ctx context.Context, rt *runc.Runc, id, bundle string, specsProcess *specs.Process, process executor.ProcessInfo, started func()) error {
 	return w.callWithIO(ctx, id, bundle, process, started, func(ctx context.Context, started chan<- int, io runc.IO) error {
-		return w.runc.Exec(ctx, id, *specsProcess, &runc.ExecOpts{
+		return rt.Exec(ctx, id, *specsProcess, &runc.ExecOpts{
 			Started: started,
 			IO:      io,
 		})
@@ -0,0 +1,237 @@
+package containerimage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/images"
+	"github.com/moby/buildkit/exporter"
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	"github.com/moby/buildkit/util/buildinfo"
+	digest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	// mediaTypeEmptyJSON is the media type of the empty config used by
+	// artifact manifests.
+	mediaTypeEmptyJSON = "application/vnd.oci.empty.v1+json"
+	// annotationArtifactType is set on the descriptors of artifact manifests
+	// as the descriptor type does not have the artifactType field yet.
+	annotationArtifactType = "vnd.moby.buildkit.artifact.type"
+)
+
+var emptyJSON = []byte("{}")
+
+// artifactManifest is an OCI image manifest storing an artifact that refers
+// to another manifest through the subject field.
+type artifactManifest struct {
+	// MediaType is reserved in the OCI spec but
+	// excluded from go types.
+	MediaType    string `json:"mediaType"`
+	ArtifactType string `json:"artifactType,omitempty"`
+
+	ocispecs.Manifest
+
+	Subject *ocispecs.Descriptor `json:"subject,omitempty"`
+}
+
+// AttestationOpts selects the attestations written by CommitAttestations.
+type AttestationOpts struct {
+	// All writes the attestations of the frontend and the SBOM of the
+	// packages found in the result.
+	All bool
+	// BuildInfo writes the build info.
+	BuildInfo bool
+	// BuildInfoAttrs filters the attributes of the build info, which are
+	// removed if it is nil.
+	BuildInfoAttrs *buildinfo.AttrsPolicy
+}
+
+// CommitAttestations writes the attestations for the image manifests of
+// desc as artifact manifests referring to them. desc is the descriptor
+// returned by Commit. The returned descriptors need to be added to the image
+// layout for the referrers to be discoverable.
+func (ic *ImageWriter) CommitAttestations(ctx context.Context, inp exporter.Source, desc ocispecs.Descriptor, opts AttestationOpts) ([]ocispecs.Descriptor, error) {
+	subject := ocispecs.Descriptor{
+		MediaType: desc.MediaType,
+		Digest:    desc.Digest,
+		Size:      desc.Size,
+	}
+
+	switch desc.MediaType {
+	case images.MediaTypeDockerSchema2ManifestList, ocispecs.MediaTypeImageIndex:
+	default:
+		atts, err := collectAttestations(inp.Metadata, "", opts)
+		if err != nil {
+			return nil, err
+		}
+		return ic.commitAttestations(ctx, subject, atts)
+	}
+
+	var p exptypes.Platforms
+	if err := json.Unmarshal(inp.Metadata[exptypes.ExporterPlatformsKey], &p); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse platforms passed to exporter")
+	}
+
+	dt, err := content.ReadBlob(ctx, ic.opt.ContentStore, desc)
+	if err != nil {
+		return nil, err
+	}
+	var idx ocispecs.Index
+	if err := json.Unmarshal(dt, &idx); err != nil {
+		return nil, errors.Wrap(err, "failed to parse index")
+	}
+	if len(idx.Manifests) != len(p.Platforms) {
+		return nil, errors.Errorf("number of platforms does not match manifests %d %d", len(p.Platforms), len(idx.Manifests))
+	}
+
+	var out []ocispecs.Descriptor
+	for i, p := range p.Platforms {
+		atts, err := collectAttestations(inp.Metadata, p.ID, opts)
+		if err != nil {
+			return nil, err
+		}
+		m := idx.Manifests[i]
+		descs, err := ic.commitAttestations(ctx, ocispecs.Descriptor{
+			MediaType: m.MediaType,
+			Digest:    m.Digest,
+			Size:      m.Size,
+		}, atts)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, descs...)
+	}
+	return out, nil
+}
+
+func (ic *ImageWriter) commitAttestations(ctx context.Context, subject ocispecs.Descriptor, atts []exptypes.Attestation) ([]ocispecs.Descriptor, error) {
+	if len(atts) == 0 {
+		return nil, nil
+	}
+
+	out := make([]ocispecs.Descriptor, 0, len(atts))
+	for _, att := range atts {
+		layerDesc := ocispecs.Descriptor{
+			MediaType: att.ArtifactType,
+			Digest:    digest.FromBytes(att.Data),
+			Size:      int64(len(att.Data)),
+		}
+		if err := content.WriteBlob(ctx, ic.opt.ContentStore, layerDesc.Digest.String(), bytes.NewReader(att.Data), layerDesc); err != nil {
+			return nil, errors.Wrapf(err, "error writing attestation blob %s", layerDesc.Digest)
+		}
+
+		mfstDesc, err := ic.commitArtifact(ctx, subject, att.ArtifactType, layerDesc, att.Annotations)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, mfstDesc)
+	}
+	return out, nil
+}
+
+// commitArtifact writes an artifact manifest of artifactType with the layer
+// of layerDesc referring to subject. The layer needs to be written to the
+// content store.
+func (ic *ImageWriter) commitArtifact(ctx context.Context, subject ocispecs.Descriptor, artifactType string, layerDesc ocispecs.Descriptor, annotations map[string]string) (ocispecs.Descriptor, error) {
+	emptyDesc := ocispecs.Descriptor{
+		MediaType: mediaTypeEmptyJSON,
+		Digest:    digest.FromBytes(emptyJSON),
+		Size:      int64(len(emptyJSON)),
+	}
+	if err := content.WriteBlob(ctx, ic.opt.ContentStore, emptyDesc.Digest.String(), bytes.NewReader(emptyJSON), emptyDesc); err != nil {
+		return ocispecs.Descriptor{}, errors.Wrap(err, "error writing empty config blob")
+	}
+
+	mfst := artifactManifest{
+		MediaType:    ocispecs.MediaTypeImageManifest,
+		ArtifactType: artifactType,
+		Manifest: ocispecs.Manifest{
+			Versioned: specs.Versioned{
+				SchemaVersion: 2,
+			},
+			Config:      emptyDesc,
+			Layers:      []ocispecs.Descriptor{layerDesc},
+			Annotations: annotations,
+		},
+		Subject: &subject,
+	}
+	mfstJSON, err := json.MarshalIndent(mfst, "", "   ")
+	if err != nil {
+		return ocispecs.Descriptor{}, errors.Wrap(err, "failed to marshal artifact manifest")
+	}
+	mfstDesc := ocispecs.Descriptor{
+		MediaType: ocispecs.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(mfstJSON),
+		Size:      int64(len(mfstJSON)),
+	}
+	mfstDone := oneOffProgress(ctx, "exporting artifact manifest "+mfstDesc.Digest.String())
+	labels := map[string]string{
+		"containerd.io/gc.ref.content.0": emptyDesc.Digest.String(),
+		"containerd.io/gc.ref.content.1": layerDesc.Digest.String(),
+	}
+	if err := content.WriteBlob(ctx, ic.opt.ContentStore, mfstDesc.Digest.String(), bytes.NewReader(mfstJSON), mfstDesc, content.WithLabels(labels)); err != nil {
+		return ocispecs.Descriptor{}, mfstDone(errors.Wrapf(err, "error writing artifact manifest blob %s", mfstDesc.Digest))
+	}
+	mfstDone(nil)
+
+	mfstDesc.Annotations = map[string]string{
+		annotationArtifactType: artifactType,
+	}
+	return mfstDesc, nil
+}
+
+// collectAttestations returns the attestations selected by opts for the
+// platform ID. The attestations in the metadata and the packages found by the
+// frontend, as a SBOM attestation, are added if opts.All is set and formatted
+// buildinfo is added as an attestation if opts.BuildInfo is set.
+func collectAttestations(md map[string][]byte, id string, opts AttestationOpts) ([]exptypes.Attestation, error) {
+	attKey, biKey := exptypes.AttestationsKey(id), exptypes.ExporterBuildInfo
+	if id != "" {
+		biKey = fmt.Sprintf("%s/%s", biKey, id)
+	}
+	dtbi := md[biKey]
+
+	var atts []exptypes.Attestation
+	if opts.All {
+		if dt, ok := md[attKey]; ok {
+			if err := json.Unmarshal(dt, &atts); err != nil {
+				return nil, errors.Wrapf(err, "failed to parse attestations")
+			}
+		}
+
+		sbom, err := buildinfo.SBOM(dtbi)
+		if err != nil {
+			return nil, err
+		}
+		if sbom != nil {
+			atts = append(atts, exptypes.Attestation{
+				ArtifactType: exptypes.SBOMArtifactType,
+				Data:         sbom,
+			})
+		}
+	}
+
+	if opts.BuildInfo {
+		if len(dtbi) > 0 {
+			dt, err := buildinfo.Format(dtbi, buildinfo.FormatOpts{
+				RemoveAttrs: opts.BuildInfoAttrs == nil,
+				Attrs:       opts.BuildInfoAttrs,
+			})
+			if err != nil {
+				return nil, err
+			}
+			atts = append(atts, exptypes.Attestation{
+				ArtifactType: exptypes.BuildInfoArtifactType,
+				Data:         dt,
+			})
+		}
+	}
+	return atts, nil
+}
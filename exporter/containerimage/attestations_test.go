@@ -0,0 +1,110 @@
+package containerimage
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/content/local"
+	"github.com/moby/buildkit/exporter"
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	binfotypes "github.com/moby/buildkit/util/buildinfo/types"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectAttestations(t *testing.T) {
+	target := "release"
+	dtbi, err := json.Marshal(binfotypes.BuildInfo{
+		Frontend: "dockerfile.v0",
+		Attrs:    map[string]*string{"target": &target},
+		Packages: []binfotypes.Package{{Type: binfotypes.PackageTypeApk, Name: "musl", Version: "1.2.4-r2"}},
+	})
+	require.NoError(t, err)
+	dtatts, err := json.Marshal([]exptypes.Attestation{{ArtifactType: "application/vnd.example+json", Data: []byte("{}")}})
+	require.NoError(t, err)
+	md := map[string][]byte{
+		exptypes.ExporterBuildInfo:   dtbi,
+		exptypes.AttestationsKey(""): dtatts,
+	}
+
+	artifactTypes := func(atts []exptypes.Attestation) []string {
+		var types []string
+		for _, att := range atts {
+			types = append(types, att.ArtifactType)
+		}
+		return types
+	}
+
+	atts, err := collectAttestations(md, "", AttestationOpts{All: true, BuildInfo: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{"application/vnd.example+json", exptypes.SBOMArtifactType, exptypes.BuildInfoArtifactType}, artifactTypes(atts))
+
+	// buildinfo=attestation without the other attestations
+	atts, err = collectAttestations(md, "", AttestationOpts{BuildInfo: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{exptypes.BuildInfoArtifactType}, artifactTypes(atts))
+
+	var bi binfotypes.BuildInfo
+	require.NoError(t, json.Unmarshal(atts[0].Data, &bi))
+	require.Equal(t, "dockerfile.v0", bi.Frontend)
+	require.Nil(t, bi.Attrs)
+
+	atts, err = collectAttestations(md, "", AttestationOpts{All: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{"application/vnd.example+json", exptypes.SBOMArtifactType}, artifactTypes(atts))
+}
+
+func TestCommitAttestations(t *testing.T) {
+	ctx := context.TODO()
+	tmpdir, err := ioutil.TempDir("", "buildkit-attestations")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	store, err := local.NewStore(tmpdir)
+	require.NoError(t, err)
+	ic := &ImageWriter{opt: WriterOpt{ContentStore: store}}
+
+	dtatts, err := json.Marshal([]exptypes.Attestation{{
+		ArtifactType: "application/vnd.example+json",
+		Data:         []byte(`{"result":"ok"}`),
+		Annotations:  map[string]string{"org.example.kind": "test"},
+	}})
+	require.NoError(t, err)
+	subject := ocispecs.Descriptor{
+		MediaType: ocispecs.MediaTypeImageManifest,
+		Digest:    digest.FromString("manifest"),
+		Size:      8,
+	}
+
+	descs, err := ic.CommitAttestations(ctx, exporter.Source{
+		Metadata: map[string][]byte{exptypes.AttestationsKey(""): dtatts},
+	}, subject, AttestationOpts{All: true})
+	require.NoError(t, err)
+	require.Len(t, descs, 1)
+	require.Equal(t, ocispecs.MediaTypeImageManifest, descs[0].MediaType)
+	require.Equal(t, "application/vnd.example+json", descs[0].Annotations[annotationArtifactType])
+
+	dt, err := content.ReadBlob(ctx, store, descs[0])
+	require.NoError(t, err)
+	var mfst artifactManifest
+	require.NoError(t, json.Unmarshal(dt, &mfst))
+	require.Equal(t, "application/vnd.example+json", mfst.ArtifactType)
+	require.Equal(t, subject.Digest, mfst.Subject.Digest)
+	require.Equal(t, mediaTypeEmptyJSON, mfst.Config.MediaType)
+	require.Equal(t, "test", mfst.Annotations["org.example.kind"])
+	require.Len(t, mfst.Layers, 1)
+
+	dt, err = content.ReadBlob(ctx, store, mfst.Layers[0])
+	require.NoError(t, err)
+	require.Equal(t, `{"result":"ok"}`, string(dt))
+
+	// no artifact manifest is written without attestations
+	descs, err = ic.CommitAttestations(ctx, exporter.Source{Metadata: map[string][]byte{}}, subject, AttestationOpts{All: true})
+	require.NoError(t, err)
+	require.Empty(t, descs)
+}
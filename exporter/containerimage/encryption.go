@@ -0,0 +1,47 @@
+package containerimage
+
+import (
+	"context"
+	"strings"
+
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/secrets"
+	"github.com/moby/buildkit/util/ocicrypt"
+	"github.com/pkg/errors"
+)
+
+// ParseEncryptionRecipients returns the IDs of the session secrets holding
+// the public keys of the recipients of the layers, from the comma separated
+// value of the encryption-recipients exporter option.
+func ParseEncryptionRecipients(v string) []string {
+	var ids []string
+	for _, id := range strings.Split(v, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// EncryptionConfig loads the public keys of the recipients of the layers from
+// the session secrets ids. It returns nil if there are no recipients.
+func EncryptionConfig(ctx context.Context, sm *session.Manager, sessionID string, ids []string) (*ocicrypt.Config, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	keys := make([][]byte, 0, len(ids))
+	err := sm.Any(ctx, session.NewGroup(sessionID), func(ctx context.Context, _ string, caller session.Caller) error {
+		for _, id := range ids {
+			dt, err := secrets.GetSecret(ctx, caller, id)
+			if err != nil {
+				return errors.Wrapf(err, "failed to load encryption recipient %s", id)
+			}
+			keys = append(keys, dt)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ocicrypt.ParseRecipients(keys...)
+}
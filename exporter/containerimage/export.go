@@ -16,12 +16,14 @@ import (
 	"github.com/containerd/containerd/platforms"
 	"github.com/containerd/containerd/remotes/docker"
 	"github.com/containerd/containerd/rootfs"
+	"github.com/docker/distribution/reference"
 	"github.com/moby/buildkit/cache"
 	cacheconfig "github.com/moby/buildkit/cache/config"
 	"github.com/moby/buildkit/exporter"
 	"github.com/moby/buildkit/exporter/containerimage/exptypes"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/snapshot"
+	"github.com/moby/buildkit/util/buildinfo"
 	"github.com/moby/buildkit/util/compression"
 	"github.com/moby/buildkit/util/contentutil"
 	"github.com/moby/buildkit/util/leaseutil"
@@ -46,7 +48,17 @@ const (
 	keyCompressionLevel = "compression-level"
 	keyBuildInfo        = "buildinfo"
 	keyBuildInfoAttrs   = "buildinfo-attrs"
+	keyAttestations     = "attestations"
 	ociTypes            = "oci-mediatypes"
+	// keyEncryptionRecipients is a comma separated list of the IDs of the
+	// session secrets holding the public keys the layers are encrypted for.
+	keyEncryptionRecipients = "encryption-recipients"
+	// keySignKey is the reference of the key signing the pushed image, the ID
+	// of a session secret holding a private key or a KMS key reference.
+	keySignKey = "sign-key"
+	// keySquashLayers is the range of the layers squashed in a single layer,
+	// e.g. "from=1,to=-1".
+	keySquashLayers = "squash-layers"
 	// preferNondistLayersKey is an exporter option which can be used to mark a layer as non-distributable if the layer reference was
 	// already found to use a non-distributable media type.
 	// When this option is not set, the exporter will change the media type of the layer to a distributable one.
@@ -74,9 +86,10 @@ func New(opt Opt) (exporter.Exporter, error) {
 	return im, nil
 }
 
-func (e *imageExporter) Resolve(ctx context.Context, opt map[string]string) (exporter.ExporterInstance, error) {
+func (e *imageExporter) Resolve(ctx context.Context, id int, opt map[string]string) (exporter.ExporterInstance, error) {
 	i := &imageExporterInstance{
 		imageExporter:    e,
+		id:               id,
 		layerCompression: compression.Default,
 		buildInfo:        true,
 	}
@@ -180,25 +193,37 @@ func (e *imageExporter) Resolve(ctx context.Context, opt map[string]string) (exp
 			v := int(ii)
 			i.compressionLevel = &v
 		case keyBuildInfo:
-			if v == "" {
-				i.buildInfo = true
-				continue
-			}
-			b, err := strconv.ParseBool(v)
+			embed, attest, err := ParseBuildInfoMode(v)
 			if err != nil {
-				return nil, errors.Wrapf(err, "non-bool value specified for %s", k)
+				return nil, errors.Wrapf(err, "invalid value specified for %s", k)
 			}
-			i.buildInfo = b
+			i.buildInfo, i.buildInfoAttestation = embed, attest
 		case keyBuildInfoAttrs:
+			p, err := ParseBuildInfoAttrs(v)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid value specified for %s", k)
+			}
+			i.buildInfoAttrs = p
+		case keyAttestations:
 			if v == "" {
-				i.buildInfoAttrs = false
+				i.attestations = true
 				continue
 			}
 			b, err := strconv.ParseBool(v)
 			if err != nil {
 				return nil, errors.Wrapf(err, "non-bool value specified for %s", k)
 			}
-			i.buildInfoAttrs = b
+			i.attestations = b
+		case keyEncryptionRecipients:
+			i.encryptionRecipients = ParseEncryptionRecipients(v)
+		case keySignKey:
+			i.signKey = v
+		case keySquashLayers:
+			sq, err := ParseSquashLayers(v)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid value specified for %s", k)
+			}
+			i.squashLayers = sq
 		case preferNondistLayersKey:
 			b, err := strconv.ParseBool(v)
 			if err != nil {
@@ -216,26 +241,49 @@ func (e *imageExporter) Resolve(ctx context.Context, opt map[string]string) (exp
 		logrus.Warn("forcibly turning on oci-mediatype mode for estargz")
 		i.ociTypes = true
 	}
+	if len(i.encryptionRecipients) > 0 && !i.ociTypes {
+		logrus.Warn("forcibly turning on oci-mediatype mode for encrypted layers")
+		i.ociTypes = true
+	}
+	if len(i.encryptionRecipients) > 0 && i.unpack {
+		return nil, errors.Errorf("%s is not supported with %s", keyUnpack, keyEncryptionRecipients)
+	}
+	if i.signKey != "" && !i.push {
+		return nil, errors.Errorf("%s requires %s=true", keySignKey, keyPush)
+	}
+	if i.buildInfoAttestation && !i.push {
+		return nil, errors.Errorf("%s=attestation requires %s=true", keyBuildInfo, keyPush)
+	}
 	return i, nil
 }
 
 type imageExporterInstance struct {
 	*imageExporter
-	targetName          string
-	push                bool
-	pushByDigest        bool
-	unpack              bool
-	insecure            bool
-	ociTypes            bool
-	nameCanonical       bool
-	danglingPrefix      string
-	layerCompression    compression.Type
-	forceCompression    bool
-	compressionLevel    *int
-	buildInfo           bool
-	buildInfoAttrs      bool
-	meta                map[string][]byte
-	preferNondistLayers bool
+	id                   int
+	targetName           string
+	push                 bool
+	pushByDigest         bool
+	unpack               bool
+	insecure             bool
+	ociTypes             bool
+	nameCanonical        bool
+	danglingPrefix       string
+	layerCompression     compression.Type
+	forceCompression     bool
+	compressionLevel     *int
+	buildInfo            bool
+	buildInfoAttestation bool
+	buildInfoAttrs       *buildinfo.AttrsPolicy
+	attestations         bool
+	meta                 map[string][]byte
+	preferNondistLayers  bool
+	encryptionRecipients []string
+	signKey              string
+	squashLayers         *SquashLayers
+}
+
+func (e *imageExporterInstance) ID() int {
+	return e.id
 }
 
 func (e *imageExporterInstance) Name() string {
@@ -270,8 +318,20 @@ func (e *imageExporterInstance) Export(ctx context.Context, src exporter.Source,
 	}
 	defer done(context.TODO())
 
+	var signer Signer
+	if e.signKey != "" {
+		signer, err = loadSigner(ctx, e.opt.SessionManager, sessionID, e.signKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	refCfg := e.refCfg()
-	desc, err := e.opt.ImageWriter.Commit(ctx, src, e.ociTypes, refCfg, e.buildInfo, e.buildInfoAttrs, sessionID)
+	enc, err := EncryptionConfig(ctx, e.opt.SessionManager, sessionID, e.encryptionRecipients)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := e.opt.ImageWriter.Commit(ctx, src, e.ociTypes, refCfg, enc, e.squashLayers, e.buildInfo, e.buildInfoAttrs, sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -280,20 +340,38 @@ func (e *imageExporterInstance) Export(ctx context.Context, src exporter.Source,
 		e.opt.ImageWriter.ContentStore().Delete(context.TODO(), desc.Digest)
 	}()
 
+	var attDescs []ocispecs.Descriptor
+	if (e.attestations || e.buildInfoAttestation) && e.push {
+		attDescs, err = e.opt.ImageWriter.CommitAttestations(ctx, src, *desc, e.attestationOpts())
+		if err != nil {
+			return nil, err
+		}
+		for _, attDesc := range attDescs {
+			attDesc := attDesc
+			defer func() {
+				e.opt.ImageWriter.ContentStore().Delete(context.TODO(), attDesc.Digest)
+			}()
+		}
+	}
+
 	resp := make(map[string]string)
 
-	if n, ok := src.Metadata["image.name"]; e.targetName == "*" && ok {
-		e.targetName = string(n)
+	// the instance exports each named output of the result with the
+	// exptypes.OutputAll output, so the name is resolved for every export
+	name := e.targetName
+	if n, ok := src.Metadata["image.name"]; name == "*" && ok {
+		name = string(n)
 	}
 
 	nameCanonical := e.nameCanonical
-	if e.targetName == "" && e.danglingPrefix != "" {
-		e.targetName = e.danglingPrefix + "@" + desc.Digest.String()
+	if name == "" && e.danglingPrefix != "" {
+		name = e.danglingPrefix + "@" + desc.Digest.String()
 		nameCanonical = false
 	}
 
-	if e.targetName != "" {
-		targetNames := strings.Split(e.targetName, ",")
+	var canonicalNames []string
+	if name != "" {
+		targetNames := strings.Split(name, ",")
 		for _, targetName := range targetNames {
 			if e.opt.Images != nil {
 				tagDone := oneOffProgress(ctx, "naming to "+targetName)
@@ -326,6 +404,19 @@ func (e *imageExporterInstance) Export(ctx context.Context, src exporter.Source,
 				}
 			}
 			if e.push {
+				pushName := targetName
+				if e.pushByDigest {
+					// the tag is skipped, the image is only pushed by digest
+					// and can be tagged later
+					named, err := reference.ParseNormalizedNamed(targetName)
+					if err != nil {
+						return nil, err
+					}
+					pushName = named.Name()
+					if nameCanonical {
+						canonicalNames = append(canonicalNames, pushName+"@"+desc.Digest.String())
+					}
+				}
 				annotations := map[digest.Digest]map[string]string{}
 				mprovider := contentutil.NewMultiProvider(e.opt.ImageWriter.ContentStore())
 				if src.Ref != nil {
@@ -353,12 +444,23 @@ func (e *imageExporterInstance) Export(ctx context.Context, src exporter.Source,
 					}
 				}
 
-				if err := push.Push(ctx, e.opt.SessionManager, sessionID, mprovider, e.opt.ImageWriter.ContentStore(), desc.Digest, targetName, e.insecure, e.opt.RegistryHosts, e.pushByDigest, annotations); err != nil {
+				if err := push.Push(ctx, e.opt.SessionManager, sessionID, mprovider, e.opt.ImageWriter.ContentStore(), desc.Digest, pushName, e.insecure, e.opt.RegistryHosts, e.pushByDigest, annotations); err != nil {
+					return nil, err
+				}
+				if err := e.pushAttestations(ctx, sessionID, attDescs, targetName); err != nil {
 					return nil, err
 				}
+				if signer != nil {
+					if err := e.pushSignature(ctx, sessionID, signer, *desc, targetName); err != nil {
+						return nil, err
+					}
+				}
 			}
 		}
-		resp["image.name"] = e.targetName
+		resp["image.name"] = name
+	}
+	if len(canonicalNames) > 0 {
+		resp[exptypes.ExporterImageNameCanonicalKey] = strings.Join(canonicalNames, ",")
 	}
 
 	resp[exptypes.ExporterImageDigestKey] = desc.Digest.String()
@@ -376,6 +478,40 @@ func (e *imageExporterInstance) Export(ctx context.Context, src exporter.Source,
 	return resp, nil
 }
 
+// pushAttestations pushes the attestation manifests by digest to the
+// repository of the image. Registries supporting the referrers API index them
+// by the subject field.
+func (e *imageExporterInstance) pushAttestations(ctx context.Context, sessionID string, descs []ocispecs.Descriptor, targetName string) error {
+	if len(descs) == 0 {
+		return nil
+	}
+	named, err := reference.ParseNormalizedNamed(targetName)
+	if err != nil {
+		return err
+	}
+	for _, desc := range descs {
+		if err := push.Push(ctx, e.opt.SessionManager, sessionID, e.opt.ImageWriter.ContentStore(), e.opt.ImageWriter.ContentStore(), desc.Digest, named.Name(), e.insecure, e.opt.RegistryHosts, true, nil); err != nil {
+			return errors.Wrapf(err, "failed to push attestation %s", desc.Digest)
+		}
+	}
+	return nil
+}
+
+// pushSignature signs the manifest of desc pushed to the repository of the
+// image and pushes the signature by digest, referring to the manifest.
+func (e *imageExporterInstance) pushSignature(ctx context.Context, sessionID string, signer Signer, desc ocispecs.Descriptor, targetName string) error {
+	named, err := reference.ParseNormalizedNamed(targetName)
+	if err != nil {
+		return err
+	}
+	sigDesc, err := e.opt.ImageWriter.CommitSignature(ctx, signer, named.Name(), desc)
+	if err != nil {
+		return err
+	}
+	defer e.opt.ImageWriter.ContentStore().Delete(context.TODO(), sigDesc.Digest)
+	return e.pushAttestations(ctx, sessionID, []ocispecs.Descriptor{sigDesc}, targetName)
+}
+
 func (e *imageExporterInstance) refCfg() cacheconfig.RefConfig {
 	return cacheconfig.RefConfig{
 		Compression:            e.compression(),
@@ -383,6 +519,16 @@ func (e *imageExporterInstance) refCfg() cacheconfig.RefConfig {
 	}
 }
 
+// attestationOpts returns the attestations written by the export. The build
+// info is written with the other attestations unless it isn't recorded.
+func (e *imageExporterInstance) attestationOpts() AttestationOpts {
+	return AttestationOpts{
+		All:            e.attestations,
+		BuildInfo:      e.buildInfoAttestation || (e.attestations && e.buildInfo),
+		BuildInfoAttrs: e.buildInfoAttrs,
+	}
+}
+
 func (e *imageExporterInstance) unpackImage(ctx context.Context, img images.Image, src exporter.Source, s session.Group) (err0 error) {
 	unpackDone := oneOffProgress(ctx, "unpacking to "+img.Name)
 	defer func() {
@@ -482,3 +628,40 @@ func addAnnotations(m map[digest.Digest]map[string]string, desc ocispecs.Descrip
 		a[k] = v
 	}
 }
+
+// ParseBuildInfoMode parses the value of the buildinfo exporter option,
+// returning whether the build info is embedded in the image config and
+// whether it is written as an attestation referring to the image. The value
+// is embed (same as true), attestation, both or false.
+func ParseBuildInfoMode(v string) (embed bool, attest bool, _ error) {
+	switch v {
+	case "", "embed":
+		return true, false, nil
+	case "attestation":
+		return false, true, nil
+	case "both":
+		return true, true, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, false, errors.Errorf("invalid buildinfo mode %q, expected embed, attestation, both or a bool", v)
+	}
+	return b, false, nil
+}
+
+// ParseBuildInfoAttrs parses the value of the buildinfo-attrs exporter
+// option. A bool includes all the recorded attributes or none of them, nil
+// being returned for none. Otherwise the value is a policy filtering the
+// recorded attributes, e.g. allow:target,filename;deny:build-arg:SECRET_*.
+func ParseBuildInfoAttrs(v string) (*buildinfo.AttrsPolicy, error) {
+	if v == "" {
+		return nil, nil
+	}
+	if b, err := strconv.ParseBool(v); err == nil {
+		if !b {
+			return nil, nil
+		}
+		return &buildinfo.AttrsPolicy{}, nil
+	}
+	return buildinfo.ParseAttrsPolicy(v)
+}
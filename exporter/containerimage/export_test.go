@@ -0,0 +1,55 @@
+package containerimage
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/util/buildinfo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBuildInfoAttrs(t *testing.T) {
+	p, err := ParseBuildInfoAttrs("")
+	require.NoError(t, err)
+	require.Nil(t, p)
+
+	p, err = ParseBuildInfoAttrs("false")
+	require.NoError(t, err)
+	require.Nil(t, p)
+
+	p, err = ParseBuildInfoAttrs("true")
+	require.NoError(t, err)
+	require.Equal(t, &buildinfo.AttrsPolicy{}, p)
+
+	p, err = ParseBuildInfoAttrs("allow:target;deny:build-arg:SECRET_*")
+	require.NoError(t, err)
+	require.Equal(t, &buildinfo.AttrsPolicy{
+		Allow: []string{"target"},
+		Deny:  []string{"build-arg:SECRET_*"},
+	}, p)
+
+	_, err = ParseBuildInfoAttrs("yes please")
+	require.Error(t, err)
+}
+
+func TestParseBuildInfoMode(t *testing.T) {
+	for _, tc := range []struct {
+		value  string
+		embed  bool
+		attest bool
+	}{
+		{"", true, false},
+		{"true", true, false},
+		{"embed", true, false},
+		{"false", false, false},
+		{"attestation", false, true},
+		{"both", true, true},
+	} {
+		embed, attest, err := ParseBuildInfoMode(tc.value)
+		require.NoError(t, err, tc.value)
+		require.Equal(t, tc.embed, embed, tc.value)
+		require.Equal(t, tc.attest, attest, tc.value)
+	}
+
+	_, _, err := ParseBuildInfoMode("referrers")
+	require.Error(t, err)
+}
@@ -12,9 +12,66 @@ const (
 	ExporterImageDescriptorKey   = "containerimage.descriptor"
 	ExporterInlineCache          = "containerimage.inlinecache"
 	ExporterBuildInfo            = "containerimage.buildinfo"
+	ExporterAttestations         = "containerimage.attestations"
 	ExporterPlatformsKey         = "refs.platforms"
+	ExporterOutputsKey           = "refs.outputs"
 )
 
+// ExporterImageStagesKey is the metadata key of the JSON encoded list of the
+// StageLayers of the image, suffixed with the platform ID for multi-platform
+// results.
+const ExporterImageStagesKey = "containerimage.stages"
+
+// StageLayers are the layers of an image added by a named build stage, from
+// the From to the To layer index, 0 being the bottom layer of the image.
+type StageLayers struct {
+	Name string `json:"name"`
+	From int    `json:"from"`
+	To   int    `json:"to"`
+}
+
+// ExporterImageNameCanonicalKey is the exporter response key of the comma
+// separated canonical references, name@digest, of the images pushed by digest
+// with name-canonical=true.
+const ExporterImageNameCanonicalKey = "containerimage.name.canonical"
+
+// ExporterFrontendImage is the metadata key of the JSON encoded source of the
+// image of the gateway frontend that built the result, recorded in the build
+// info.
+const ExporterFrontendImage = "frontend.image"
+
+// OutputAttr is the exporter attribute selecting the named build output that
+// is exported instead of the build target.
+const OutputAttr = "output"
+
+// OutputAll is the value of OutputAttr exporting each named build output of
+// the result in turn.
+const OutputAll = "*"
+
+// FrontendOutputsAttr is the frontend attribute listing the named build
+// outputs selected by the exporters, separated by commas. Frontends receiving
+// it only return the selected outputs next to the build target.
+const FrontendOutputsAttr = "outputs"
+
+// OutputTargetKey is the key of the build target in the refs of a result
+// with named build outputs. The refs of the outputs are keyed
+// "output:<name>".
+const OutputTargetKey = "target"
+
+// OutputKey returns the key of the named build output in the refs of a
+// result.
+func OutputKey(name string) string {
+	return "output:" + name
+}
+
+// BuildInfoArtifactType is the artifact type of buildinfo attestations
+// attached to image manifests.
+const BuildInfoArtifactType = "application/vnd.moby.buildkit.buildinfo.v1+json"
+
+// SBOMArtifactType is the artifact type of the attestations listing the
+// packages found in the image when the frontend scanned it.
+const SBOMArtifactType = "application/vnd.moby.buildkit.sbom.v1+json"
+
 type Platforms struct {
 	Platforms []Platform
 }
@@ -23,3 +80,22 @@ type Platform struct {
 	ID       string
 	Platform ocispecs.Platform
 }
+
+// Attestation is a blob that describes an image manifest, like a SBOM or a
+// provenance statement. Attestations are stored as JSON list in the result
+// metadata under the ExporterAttestations key (suffixed with the platform
+// ID for multi-platform results).
+type Attestation struct {
+	ArtifactType string            `json:"artifactType"`
+	Data         []byte            `json:"data"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+// AttestationsKey returns the metadata key of the attestations for the
+// platform ID. An empty ID is used for single-platform results.
+func AttestationsKey(platformID string) string {
+	if platformID == "" {
+		return ExporterAttestations
+	}
+	return ExporterAttestations + "/" + platformID
+}
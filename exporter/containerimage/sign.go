@@ -0,0 +1,178 @@
+package containerimage
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"sync"
+
+	"github.com/containerd/containerd/content"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/secrets"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SignatureArtifactType is the artifact type of the manifests of the
+	// cosign signatures of the images.
+	SignatureArtifactType = "application/vnd.dev.cosign.artifact.sig.v1+json"
+	// mediaTypeSimpleSigning is the media type of the payloads signed by the
+	// cosign signatures.
+	mediaTypeSimpleSigning = "application/vnd.dev.cosign.simplesigning.v1+json"
+	// annotationSignature is the annotation of the payload layer holding the
+	// base64 encoded signature.
+	annotationSignature = "dev.cosignproject.cosign/signature"
+)
+
+// Signer signs the payloads of the signatures of the images.
+type Signer interface {
+	Sign(ctx context.Context, payload []byte) ([]byte, error)
+}
+
+// SignerProvider returns the signer of a key reference, e.g. a key of a KMS.
+type SignerProvider func(ctx context.Context, ref string) (Signer, error)
+
+var (
+	signerProvidersMu sync.RWMutex
+	signerProviders   = map[string]SignerProvider{}
+)
+
+// RegisterSignerProvider registers the provider of the signers of the key
+// references with the scheme, e.g. "awskms" for "awskms:///alias/signing".
+func RegisterSignerProvider(scheme string, p SignerProvider) {
+	signerProvidersMu.Lock()
+	defer signerProvidersMu.Unlock()
+	signerProviders[scheme] = p
+}
+
+// loadSigner returns the signer of the key reference ref of the sign-key
+// exporter option. A reference with a scheme is resolved by the provider
+// registered for the scheme, other references are the IDs of the session
+// secrets holding PEM encoded private keys.
+func loadSigner(ctx context.Context, sm *session.Manager, sessionID string, ref string) (Signer, error) {
+	if i := strings.Index(ref, "://"); i > 0 {
+		scheme := ref[:i]
+		signerProvidersMu.RLock()
+		p, ok := signerProviders[scheme]
+		signerProvidersMu.RUnlock()
+		if !ok {
+			return nil, errors.Errorf("no signer provider for %s keys", scheme)
+		}
+		return p(ctx, ref)
+	}
+
+	var dt []byte
+	err := sm.Any(ctx, session.NewGroup(sessionID), func(ctx context.Context, _ string, caller session.Caller) error {
+		var err error
+		dt, err = secrets.GetSecret(ctx, caller, ref)
+		return errors.Wrapf(err, "failed to load signing key %s", ref)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseSigningKey(dt)
+}
+
+// parseSigningKey returns the signer of a PEM encoded ECDSA, RSA or Ed25519
+// private key.
+func parseSigningKey(dt []byte) (Signer, error) {
+	b, _ := pem.Decode(dt)
+	if b == nil {
+		return nil, errors.New("no PEM encoded private key for signing")
+	}
+	var key interface{}
+	var err error
+	switch b.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(b.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(b.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(b.Bytes)
+	default:
+		return nil, errors.Errorf("unsupported PEM block %q for signing, the key needs to be unencrypted", b.Type)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse signing key")
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.Errorf("unsupported signing key type %T", key)
+	}
+	return &keySigner{key: signer}, nil
+}
+
+type keySigner struct {
+	key crypto.Signer
+}
+
+func (s *keySigner) Sign(ctx context.Context, payload []byte) ([]byte, error) {
+	if _, ok := s.key.(ed25519.PrivateKey); ok {
+		return s.key.Sign(rand.Reader, payload, crypto.Hash(0))
+	}
+	h := sha256.Sum256(payload)
+	return s.key.Sign(rand.Reader, h[:], crypto.SHA256)
+}
+
+// simpleSigningPayload is the payload of the cosign signatures.
+type simpleSigningPayload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest digest.Digest `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+	Optional map[string]interface{} `json:"optional"`
+}
+
+func signaturePayload(name string, dgst digest.Digest) ([]byte, error) {
+	var p simpleSigningPayload
+	p.Critical.Identity.DockerReference = name
+	p.Critical.Image.DockerManifestDigest = dgst
+	p.Critical.Type = "cosign container image signature"
+	dt, err := json.Marshal(p)
+	return dt, errors.WithStack(err)
+}
+
+// CommitSignature signs the manifest of desc pushed to the repository name
+// and writes the signature as an artifact manifest referring to it, in the
+// format of the cosign signatures.
+func (ic *ImageWriter) CommitSignature(ctx context.Context, signer Signer, name string, desc ocispecs.Descriptor) (ocispecs.Descriptor, error) {
+	payload, err := signaturePayload(name, desc.Digest)
+	if err != nil {
+		return ocispecs.Descriptor{}, err
+	}
+	sig, err := signer.Sign(ctx, payload)
+	if err != nil {
+		return ocispecs.Descriptor{}, errors.Wrapf(err, "failed to sign %s", desc.Digest)
+	}
+	layerDesc := ocispecs.Descriptor{
+		MediaType: mediaTypeSimpleSigning,
+		Digest:    digest.FromBytes(payload),
+		Size:      int64(len(payload)),
+	}
+	if err := content.WriteBlob(ctx, ic.opt.ContentStore, layerDesc.Digest.String(), bytes.NewReader(payload), layerDesc); err != nil {
+		return ocispecs.Descriptor{}, errors.Wrap(err, "error writing signature payload blob")
+	}
+	layerDesc.Annotations = map[string]string{
+		annotationSignature: base64.StdEncoding.EncodeToString(sig),
+	}
+	return ic.commitArtifact(ctx, ocispecs.Descriptor{
+		MediaType: desc.MediaType,
+		Digest:    desc.Digest,
+		Size:      desc.Size,
+	}, SignatureArtifactType, layerDesc, nil)
+}
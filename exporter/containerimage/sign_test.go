@@ -0,0 +1,75 @@
+package containerimage
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignaturePayload(t *testing.T) {
+	dgst := digest.FromString("manifest")
+	dt, err := signaturePayload("docker.io/library/foo", dgst)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"critical":{"identity":{"docker-reference":"docker.io/library/foo"},"image":{"docker-manifest-digest":"`+dgst.String()+`"},"type":"cosign container image signature"},"optional":null}`, string(dt))
+}
+
+func TestParseSigningKey(t *testing.T) {
+	ctx := context.TODO()
+	payload := []byte(`{"critical":{}}`)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	dt, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	require.NoError(t, err)
+	s, err := parseSigningKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: dt}))
+	require.NoError(t, err)
+	sig, err := s.Sign(ctx, payload)
+	require.NoError(t, err)
+	h := sha256.Sum256(payload)
+	require.True(t, ecdsa.VerifyASN1(&ecKey.PublicKey, h[:], sig))
+
+	pub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	dt, err = x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+	s, err = parseSigningKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: dt}))
+	require.NoError(t, err)
+	sig, err = s.Sign(ctx, payload)
+	require.NoError(t, err)
+	require.True(t, ed25519.Verify(pub, payload, sig))
+
+	_, err = parseSigningKey(pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED COSIGN PRIVATE KEY", Bytes: []byte("x")}))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unencrypted")
+}
+
+type testSigner struct{}
+
+func (testSigner) Sign(ctx context.Context, payload []byte) ([]byte, error) {
+	return []byte("signed"), nil
+}
+
+func TestLoadSignerProvider(t *testing.T) {
+	RegisterSignerProvider("testkms", func(ctx context.Context, ref string) (Signer, error) {
+		require.Equal(t, "testkms://keys/signing", ref)
+		return testSigner{}, nil
+	})
+	s, err := loadSigner(context.TODO(), nil, "", "testkms://keys/signing")
+	require.NoError(t, err)
+	sig, err := s.Sign(context.TODO(), nil)
+	require.NoError(t, err)
+	require.Equal(t, "signed", string(sig))
+
+	_, err = loadSigner(context.TODO(), nil, "", "awskms:///alias/signing")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no signer provider")
+}
@@ -0,0 +1,269 @@
+package containerimage
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/containerd/diff"
+	"github.com/containerd/containerd/diff/walking"
+	"github.com/containerd/containerd/mount"
+	"github.com/klauspost/compress/zstd"
+	"github.com/moby/buildkit/cache"
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	"github.com/moby/buildkit/identity"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/util/compression"
+	"github.com/moby/buildkit/util/contentutil"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// SquashLayers is a range of the layers of the exported image that are
+// squashed in a single layer. The indexes start at 0 for the bottom layer,
+// the negative indexes count from the top layer, -1 being the top layer.
+// FromStage and ToStage select the first layer of a stage for From and the
+// last layer of a stage for To instead.
+type SquashLayers struct {
+	From      int
+	To        int
+	FromStage string
+	ToStage   string
+}
+
+// ParseSquashLayers parses the value of the squash-layers exporter option,
+// e.g. "from=1,to=-2" or "from=build". from defaults to the bottom layer and
+// to to the top layer. The values that are not indexes are stage names.
+func ParseSquashLayers(v string) (*SquashLayers, error) {
+	s := &SquashLayers{From: 0, To: -1}
+	if strings.TrimSpace(v) == "" {
+		return s, nil
+	}
+	for _, field := range strings.Split(v, ",") {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("invalid field %q, expected from=<index> or to=<index>", field)
+		}
+		key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if value == "" {
+			return nil, errors.Errorf("empty %s value, expected a layer index or a stage name", key)
+		}
+		i, err := strconv.Atoi(value)
+		switch key {
+		case "from":
+			if err != nil {
+				s.FromStage = value
+			} else {
+				s.From, s.FromStage = i, ""
+			}
+		case "to":
+			if err != nil {
+				s.ToStage = value
+			} else {
+				s.To, s.ToStage = i, ""
+			}
+		default:
+			return nil, errors.Errorf("unknown field %q, expected from or to", key)
+		}
+	}
+	return s, nil
+}
+
+// withStages returns the range with the stage names replaced by the indexes
+// of their layers, from the JSON encoded exptypes.StageLayers of the image
+// recorded by the frontend.
+func (s *SquashLayers) withStages(dt []byte) (*SquashLayers, error) {
+	if s == nil || (s.FromStage == "" && s.ToStage == "") {
+		return s, nil
+	}
+	var stages []exptypes.StageLayers
+	if len(dt) > 0 {
+		if err := json.Unmarshal(dt, &stages); err != nil {
+			return nil, errors.Wrap(err, "failed to parse the stages of the image")
+		}
+	}
+	find := func(name string) (exptypes.StageLayers, error) {
+		for _, st := range stages {
+			if strings.EqualFold(st.Name, name) {
+				return st, nil
+			}
+		}
+		if len(dt) == 0 {
+			return exptypes.StageLayers{}, errors.Errorf("cannot squash the layers of stage %s: the frontend doesn't record the stages of the image, select the layers by index", name)
+		}
+		return exptypes.StageLayers{}, errors.Errorf("cannot squash the layers of stage %s: the stage adds no layers to the image", name)
+	}
+	out := *s
+	if s.FromStage != "" {
+		st, err := find(s.FromStage)
+		if err != nil {
+			return nil, err
+		}
+		out.From, out.FromStage = st.From, ""
+	}
+	if s.ToStage != "" {
+		st, err := find(s.ToStage)
+		if err != nil {
+			return nil, err
+		}
+		out.To, out.ToStage = st.To, ""
+	}
+	return &out, nil
+}
+
+// resolve returns the range of the layers of an image with n layers, with
+// positive indexes.
+func (s SquashLayers) resolve(n int) (from, to int, err error) {
+	from, to = s.From, s.To
+	if from < 0 {
+		from += n
+	}
+	if to < 0 {
+		to += n
+	}
+	if from < 0 || from >= n || to < 0 || to >= n {
+		return 0, 0, errors.Errorf("cannot squash layers %d to %d of an image with %d layers", s.From, s.To, n)
+	}
+	if from > to {
+		return 0, 0, errors.Errorf("cannot squash layers %d to %d: %d is above %d", s.From, s.To, from, to)
+	}
+	return from, to, nil
+}
+
+// squashLayers replaces the layers of remote in the range of squash by a
+// single layer holding the changes of the range, the diff between the
+// filesystems of the layers below and of the top layer of the range. The
+// layers of the build cache are left unchanged. The history entries of the
+// squashed layers are kept as empty layers, except the top one.
+func (ic *ImageWriter) squashLayers(ctx context.Context, ref cache.ImmutableRef, remote *solver.Remote, history []ocispecs.History, squash *SquashLayers, comp compression.Config, s session.Group) (*solver.Remote, []ocispecs.History, error) {
+	if squash == nil || ref == nil || len(remote.Descriptors) == 0 {
+		return remote, history, nil
+	}
+	from, to, err := squash.resolve(len(remote.Descriptors))
+	if err != nil {
+		return nil, nil, err
+	}
+	if from == to {
+		return remote, history, nil
+	}
+
+	layerChain := ref.LayerChain()
+	defer layerChain.Release(context.TODO())
+	if len(layerChain) != len(remote.Descriptors) {
+		return nil, nil, errors.Errorf("cannot squash layers: %d layers exported for %d layers of the result", len(remote.Descriptors), len(layerChain))
+	}
+
+	done := oneOffProgress(ctx, fmt.Sprintf("squashing layers %d to %d", from, to))
+	var lower []mount.Mount
+	if from > 0 {
+		var release func() error
+		if lower, release, err = mountRef(ctx, layerChain[from-1], s); err != nil {
+			return nil, nil, done(err)
+		}
+		if release != nil {
+			defer release()
+		}
+	}
+	upper, release, err := mountRef(ctx, layerChain[to], s)
+	if err != nil {
+		return nil, nil, done(err)
+	}
+	if release != nil {
+		defer release()
+	}
+
+	mediaType, compressor := squashCompression(comp)
+	desc, err := walking.NewWalkingDiff(ic.opt.ContentStore).Compare(ctx, lower, upper,
+		diff.WithMediaType(mediaType),
+		diff.WithReference("squash-"+identity.NewID()),
+		diff.WithCompressor(compressor),
+	)
+	if err != nil {
+		return nil, nil, done(errors.Wrap(err, "failed to squash layers"))
+	}
+	info, err := ic.opt.ContentStore.Info(ctx, desc.Digest)
+	if err != nil {
+		return nil, nil, done(err)
+	}
+	desc.Annotations = map[string]string{}
+	if diffID, ok := info.Labels["containerd.io/uncompressed"]; ok {
+		desc.Annotations["containerd.io/uncompressed"] = diffID
+	} else {
+		desc.Annotations["containerd.io/uncompressed"] = desc.Digest.String()
+	}
+	done(nil)
+
+	descs := make([]ocispecs.Descriptor, 0, len(remote.Descriptors)-(to-from))
+	descs = append(descs, remote.Descriptors[:from]...)
+	descs = append(descs, desc)
+	descs = append(descs, remote.Descriptors[to+1:]...)
+
+	mprovider := contentutil.NewMultiProvider(remote.Provider)
+	mprovider.Add(desc.Digest, ic.opt.ContentStore)
+
+	return &solver.Remote{Descriptors: descs, Provider: mprovider}, squashHistory(history, from, to), nil
+}
+
+// squashHistory marks the history entries of the layers from to to, but the
+// top one, as empty layers.
+func squashHistory(history []ocispecs.History, from, to int) []ocispecs.History {
+	out := make([]ocispecs.History, len(history))
+	copy(out, history)
+	var layerIndex int
+	for i, h := range out {
+		if h.EmptyLayer {
+			continue
+		}
+		switch {
+		case layerIndex >= from && layerIndex < to:
+			h.EmptyLayer = true
+		case layerIndex == to:
+			comment := fmt.Sprintf("squashed layers %d to %d", from, to)
+			if h.Comment != "" {
+				comment = h.Comment + ", " + comment
+			}
+			h.Comment = comment
+		}
+		out[i] = h
+		layerIndex++
+	}
+	return out
+}
+
+// squashCompression returns the media type and the compressor of a squashed
+// layer. The layers compressed with estargz are squashed in a gzip layer.
+func squashCompression(comp compression.Config) (string, func(io.Writer, string) (io.WriteCloser, error)) {
+	switch comp.Type {
+	case compression.Uncompressed:
+		return ocispecs.MediaTypeImageLayer, nil
+	case compression.Zstd:
+		return ocispecs.MediaTypeImageLayer + "+zstd", func(dest io.Writer, _ string) (io.WriteCloser, error) {
+			level := zstd.SpeedDefault
+			if comp.Level != nil {
+				level = zstd.EncoderLevelFromZstd(*comp.Level)
+			}
+			return zstd.NewWriter(dest, zstd.WithEncoderLevel(level))
+		}
+	default:
+		return ocispecs.MediaTypeImageLayerGzip, func(dest io.Writer, _ string) (io.WriteCloser, error) {
+			level := gzip.DefaultCompression
+			if comp.Level != nil {
+				level = *comp.Level
+			}
+			return gzip.NewWriterLevel(dest, level)
+		}
+	}
+}
+
+func mountRef(ctx context.Context, ref cache.ImmutableRef, s session.Group) ([]mount.Mount, func() error, error) {
+	m, err := ref.Mount(ctx, true, s)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m.Mount()
+}
@@ -0,0 +1,117 @@
+package containerimage
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSquashLayers(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		from, to int
+	}{
+		{"", 0, -1},
+		{"from=2", 2, -1},
+		{"to=3", 0, 3},
+		{"from=1,to=-2", 1, -2},
+		{" from = -3 , to = -1 ", -3, -1},
+	} {
+		sq, err := ParseSquashLayers(tc.value)
+		require.NoError(t, err, tc.value)
+		require.Equal(t, &SquashLayers{From: tc.from, To: tc.to}, sq, tc.value)
+	}
+
+	for _, v := range []string{"from", "from=", "from=1,until=2"} {
+		_, err := ParseSquashLayers(v)
+		require.Error(t, err, v)
+	}
+
+	sq, err := ParseSquashLayers("from=base,to=build")
+	require.NoError(t, err)
+	require.Equal(t, &SquashLayers{To: -1, FromStage: "base", ToStage: "build"}, sq)
+
+	sq, err = ParseSquashLayers("from=base,to=-2")
+	require.NoError(t, err)
+	require.Equal(t, &SquashLayers{To: -2, FromStage: "base"}, sq)
+}
+
+func TestSquashLayersWithStages(t *testing.T) {
+	stages, err := json.Marshal([]exptypes.StageLayers{
+		{Name: "base", From: 2, To: 3},
+		{Name: "build", From: 4, To: 6},
+	})
+	require.NoError(t, err)
+
+	sq, err := (&SquashLayers{To: -1, FromStage: "base", ToStage: "Build"}).withStages(stages)
+	require.NoError(t, err)
+	require.Equal(t, &SquashLayers{From: 2, To: 6}, sq)
+
+	sq, err = (&SquashLayers{From: 1, To: -1, ToStage: "base"}).withStages(stages)
+	require.NoError(t, err)
+	require.Equal(t, &SquashLayers{From: 1, To: 3}, sq)
+
+	// the ranges without stages are unchanged
+	sq, err = (&SquashLayers{From: 1, To: -1}).withStages(nil)
+	require.NoError(t, err)
+	require.Equal(t, &SquashLayers{From: 1, To: -1}, sq)
+
+	var none *SquashLayers
+	sq, err = none.withStages(stages)
+	require.NoError(t, err)
+	require.Nil(t, sq)
+
+	_, err = (&SquashLayers{To: -1, FromStage: "test"}).withStages(stages)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "adds no layers")
+
+	_, err = (&SquashLayers{To: -1, FromStage: "base"}).withStages(nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "doesn't record the stages")
+}
+
+func TestSquashLayersResolve(t *testing.T) {
+	from, to, err := SquashLayers{From: 1, To: -1}.resolve(5)
+	require.NoError(t, err)
+	require.Equal(t, 1, from)
+	require.Equal(t, 4, to)
+
+	from, to, err = SquashLayers{From: -2, To: 3}.resolve(5)
+	require.NoError(t, err)
+	require.Equal(t, 3, from)
+	require.Equal(t, 3, to)
+
+	_, _, err = SquashLayers{From: 0, To: 5}.resolve(5)
+	require.Error(t, err)
+	_, _, err = SquashLayers{From: -6, To: -1}.resolve(5)
+	require.Error(t, err)
+	_, _, err = SquashLayers{From: 3, To: 1}.resolve(5)
+	require.Error(t, err)
+}
+
+func TestSquashHistory(t *testing.T) {
+	history := []ocispecs.History{
+		{CreatedBy: "base"},
+		{CreatedBy: "ENV", EmptyLayer: true},
+		{CreatedBy: "RUN 1"},
+		{CreatedBy: "RUN 2"},
+		{CreatedBy: "WORKDIR", EmptyLayer: true},
+		{CreatedBy: "RUN 3", Comment: "buildkit.dockerfile.v0"},
+		{CreatedBy: "RUN 4"},
+	}
+	out := squashHistory(history, 1, 3)
+	require.Equal(t, []ocispecs.History{
+		{CreatedBy: "base"},
+		{CreatedBy: "ENV", EmptyLayer: true},
+		{CreatedBy: "RUN 1", EmptyLayer: true},
+		{CreatedBy: "RUN 2", EmptyLayer: true},
+		{CreatedBy: "WORKDIR", EmptyLayer: true},
+		{CreatedBy: "RUN 3", Comment: "buildkit.dockerfile.v0, squashed layers 1 to 3"},
+		{CreatedBy: "RUN 4"},
+	}, out)
+	// the history of the image isn't modified
+	require.False(t, history[2].EmptyLayer)
+}
@@ -23,6 +23,8 @@ import (
 	"github.com/moby/buildkit/util/buildinfo"
 	binfotypes "github.com/moby/buildkit/util/buildinfo/types"
 	"github.com/moby/buildkit/util/compression"
+	"github.com/moby/buildkit/util/contentutil"
+	"github.com/moby/buildkit/util/ocicrypt"
 	"github.com/moby/buildkit/util/progress"
 	"github.com/moby/buildkit/util/system"
 	"github.com/moby/buildkit/util/tracing"
@@ -50,7 +52,11 @@ type ImageWriter struct {
 	opt WriterOpt
 }
 
-func (ic *ImageWriter) Commit(ctx context.Context, inp exporter.Source, oci bool, refCfg cacheconfig.RefConfig, buildInfo bool, buildInfoAttrs bool, sessionID string) (*ocispecs.Descriptor, error) {
+// Commit writes the image of the result. The attributes of the build info
+// inlined in the image config are filtered with buildInfoAttrs, or removed if
+// it is nil. The layers in the range of squash, if set, are squashed in a
+// single layer.
+func (ic *ImageWriter) Commit(ctx context.Context, inp exporter.Source, oci bool, refCfg cacheconfig.RefConfig, enc *ocicrypt.Config, squash *SquashLayers, buildInfo bool, buildInfoAttrs *buildinfo.AttrsPolicy, sessionID string) (*ocispecs.Descriptor, error) {
 	platformsBytes, ok := inp.Metadata[exptypes.ExporterPlatformsKey]
 
 	if len(inp.Refs) > 0 && !ok {
@@ -58,7 +64,8 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp exporter.Source, oci bool
 	}
 
 	if len(inp.Refs) == 0 {
-		remotes, err := ic.exportLayers(ctx, refCfg, session.NewGroup(sessionID), inp.Ref)
+		s := session.NewGroup(sessionID)
+		remotes, err := ic.exportLayers(ctx, refCfg, s, inp.Ref)
 		if err != nil {
 			return nil, err
 		}
@@ -66,13 +73,21 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp exporter.Source, oci bool
 		var dtbi []byte
 		if buildInfo {
 			if dtbi, err = buildinfo.Format(inp.Metadata[exptypes.ExporterBuildInfo], buildinfo.FormatOpts{
-				RemoveAttrs: !buildInfoAttrs,
+				RemoveAttrs: buildInfoAttrs == nil,
+				Attrs:       buildInfoAttrs,
+				// the image config stays the same when the build is repeated
+				RemoveCache: true,
 			}); err != nil {
 				return nil, err
 			}
 		}
 
-		mfstDesc, configDesc, err := ic.commitDistributionManifest(ctx, inp.Ref, inp.Metadata[exptypes.ExporterImageConfigKey], &remotes[0], oci, inp.Metadata[exptypes.ExporterInlineCache], dtbi)
+		sq, err := squash.withStages(inp.Metadata[exptypes.ExporterImageStagesKey])
+		if err != nil {
+			return nil, err
+		}
+
+		mfstDesc, configDesc, err := ic.commitDistributionManifest(ctx, inp.Ref, inp.Metadata[exptypes.ExporterImageConfigKey], &remotes[0], oci, enc, sq, refCfg, s, inp.Metadata[exptypes.ExporterInlineCache], dtbi)
 		if err != nil {
 			return nil, err
 		}
@@ -100,7 +115,8 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp exporter.Source, oci bool
 		refs = append(refs, r)
 	}
 
-	remotes, err := ic.exportLayers(ctx, refCfg, session.NewGroup(sessionID), refs...)
+	s := session.NewGroup(sessionID)
+	remotes, err := ic.exportLayers(ctx, refCfg, s, refs...)
 	if err != nil {
 		return nil, err
 	}
@@ -137,13 +153,21 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp exporter.Source, oci bool
 		var dtbi []byte
 		if buildInfo {
 			if dtbi, err = buildinfo.Format(inp.Metadata[fmt.Sprintf("%s/%s", exptypes.ExporterBuildInfo, p.ID)], buildinfo.FormatOpts{
-				RemoveAttrs: !buildInfoAttrs,
+				RemoveAttrs: buildInfoAttrs == nil,
+				Attrs:       buildInfoAttrs,
+				// the image config stays the same when the build is repeated
+				RemoveCache: true,
 			}); err != nil {
 				return nil, err
 			}
 		}
 
-		desc, _, err := ic.commitDistributionManifest(ctx, r, config, &remotes[remotesMap[p.ID]], oci, inlineCache, dtbi)
+		sq, err := squash.withStages(inp.Metadata[fmt.Sprintf("%s/%s", exptypes.ExporterImageStagesKey, p.ID)])
+		if err != nil {
+			return nil, err
+		}
+
+		desc, _, err := ic.commitDistributionManifest(ctx, r, config, &remotes[remotesMap[p.ID]], oci, enc, sq, refCfg, s, inlineCache, dtbi)
 		if err != nil {
 			return nil, err
 		}
@@ -212,7 +236,44 @@ func (ic *ImageWriter) exportLayers(ctx context.Context, refCfg cacheconfig.RefC
 	return out, err
 }
 
-func (ic *ImageWriter) commitDistributionManifest(ctx context.Context, ref cache.ImmutableRef, config []byte, remote *solver.Remote, oci bool, inlineCache []byte, buildInfo []byte) (*ocispecs.Descriptor, *ocispecs.Descriptor, error) {
+// encryptLayers writes the layers of remote encrypted for the recipients of
+// enc to the content store. Foreign layers aren't encrypted as they are pulled
+// from their URLs.
+func (ic *ImageWriter) encryptLayers(ctx context.Context, remote *solver.Remote, enc *ocicrypt.Config) (*solver.Remote, error) {
+	eg, ctx := errgroup.WithContext(ctx)
+	encryptDone := oneOffProgress(ctx, "encrypting layers")
+
+	descs := make([]ocispecs.Descriptor, len(remote.Descriptors))
+	for i, desc := range remote.Descriptors {
+		i, desc := i, desc
+		if images.IsNonDistributable(desc.MediaType) || ocicrypt.IsEncrypted(desc.MediaType) {
+			descs[i] = desc
+			continue
+		}
+		eg.Go(func() error {
+			encDesc, err := enc.EncryptLayer(ctx, ic.opt.ContentStore, remote.Provider, desc)
+			if err != nil {
+				return err
+			}
+			descs[i] = encDesc
+			return nil
+		})
+	}
+	if err := encryptDone(eg.Wait()); err != nil {
+		return nil, err
+	}
+
+	mprovider := contentutil.NewMultiProvider(ic.opt.ContentStore)
+	for _, desc := range descs {
+		if ocicrypt.IsEncrypted(desc.MediaType) {
+			continue
+		}
+		mprovider.Add(desc.Digest, remote.Provider)
+	}
+	return &solver.Remote{Descriptors: descs, Provider: mprovider}, nil
+}
+
+func (ic *ImageWriter) commitDistributionManifest(ctx context.Context, ref cache.ImmutableRef, config []byte, remote *solver.Remote, oci bool, enc *ocicrypt.Config, squash *SquashLayers, refCfg cacheconfig.RefConfig, s session.Group, inlineCache []byte, buildInfo []byte) (*ocispecs.Descriptor, *ocispecs.Descriptor, error) {
 	if len(config) == 0 {
 		var err error
 		config, err = emptyImageConfig()
@@ -234,6 +295,21 @@ func (ic *ImageWriter) commitDistributionManifest(ctx context.Context, ref cache
 
 	remote, history = normalizeLayersAndHistory(ctx, remote, history, ref, oci)
 
+	if squash != nil {
+		remote, history, err = ic.squashLayers(ctx, ref, remote, history, squash, refCfg.Compression, s)
+		if err != nil {
+			return nil, nil, err
+		}
+		remote.Descriptors = compression.ConvertAllLayerMediaTypes(oci, remote.Descriptors...)
+	}
+
+	if enc != nil {
+		remote, err = ic.encryptLayers(ctx, remote, enc)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	config, err = patchImageConfig(config, remote.Descriptors, history, inlineCache, buildInfo)
 	if err != nil {
 		return nil, nil, err
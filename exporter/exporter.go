@@ -8,10 +8,14 @@ import (
 )
 
 type Exporter interface {
-	Resolve(context.Context, map[string]string) (ExporterInstance, error)
+	// Resolve returns an exporter instance for the attributes. id is the
+	// index of the exporter in the solve request and is used to select the
+	// matching client-side target when multiple exporters are used.
+	Resolve(ctx context.Context, id int, opt map[string]string) (ExporterInstance, error)
 }
 
 type ExporterInstance interface {
+	ID() int
 	Name() string
 	Config() Config
 	Export(ctx context.Context, src Source, sessionID string) (map[string]string, error)
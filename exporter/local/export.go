@@ -34,12 +34,17 @@ func New(opt Opt) (exporter.Exporter, error) {
 	return le, nil
 }
 
-func (e *localExporter) Resolve(ctx context.Context, opt map[string]string) (exporter.ExporterInstance, error) {
-	return &localExporterInstance{localExporter: e}, nil
+func (e *localExporter) Resolve(ctx context.Context, id int, opt map[string]string) (exporter.ExporterInstance, error) {
+	return &localExporterInstance{localExporter: e, id: id}, nil
 }
 
 type localExporterInstance struct {
 	*localExporter
+	id int
+}
+
+func (e *localExporterInstance) ID() int {
+	return e.id
 }
 
 func (e *localExporterInstance) Name() string {
@@ -121,7 +126,7 @@ func (e *localExporterInstance) Export(ctx context.Context, inp exporter.Source,
 			}
 
 			progress := newProgressHandler(ctx, lbl)
-			if err := filesync.CopyToCaller(ctx, fs, caller, progress); err != nil {
+			if err := filesync.CopyToCaller(ctx, fs, e.id, caller, progress); err != nil {
 				return err
 			}
 			return nil
@@ -18,6 +18,7 @@ import (
 	"github.com/moby/buildkit/exporter/containerimage/exptypes"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/filesync"
+	"github.com/moby/buildkit/util/buildinfo"
 	"github.com/moby/buildkit/util/compression"
 	"github.com/moby/buildkit/util/contentutil"
 	"github.com/moby/buildkit/util/grpcerrors"
@@ -41,6 +42,10 @@ const (
 	keyCompressionLevel = "compression-level"
 	keyBuildInfo        = "buildinfo"
 	keyBuildInfoAttrs   = "buildinfo-attrs"
+	keyAttestations     = "attestations"
+	// keyEncryptionRecipients is a comma separated list of the IDs of the
+	// session secrets holding the public keys the layers are encrypted for.
+	keyEncryptionRecipients = "encryption-recipients"
 	// preferNondistLayersKey is an exporter option which can be used to mark a layer as non-distributable if the layer reference was
 	// already found to use a non-distributable media type.
 	// When this option is not set, the exporter will change the media type of the layer to a distributable one.
@@ -63,10 +68,11 @@ func New(opt Opt) (exporter.Exporter, error) {
 	return im, nil
 }
 
-func (e *imageExporter) Resolve(ctx context.Context, opt map[string]string) (exporter.ExporterInstance, error) {
+func (e *imageExporter) Resolve(ctx context.Context, id int, opt map[string]string) (exporter.ExporterInstance, error) {
 	var ot *bool
 	i := &imageExporterInstance{
 		imageExporter:    e,
+		id:               id,
 		layerCompression: compression.Default,
 		buildInfo:        true,
 	}
@@ -118,25 +124,29 @@ func (e *imageExporter) Resolve(ctx context.Context, opt map[string]string) (exp
 			}
 			*ot = b
 		case keyBuildInfo:
-			if v == "" {
-				i.buildInfo = true
-				continue
-			}
-			b, err := strconv.ParseBool(v)
+			embed, attest, err := containerimage.ParseBuildInfoMode(v)
 			if err != nil {
-				return nil, errors.Wrapf(err, "non-bool value specified for %s", k)
+				return nil, errors.Wrapf(err, "invalid value specified for %s", k)
 			}
-			i.buildInfo = b
+			i.buildInfo, i.buildInfoAttestation = embed, attest
 		case keyBuildInfoAttrs:
+			p, err := containerimage.ParseBuildInfoAttrs(v)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid value specified for %s", k)
+			}
+			i.buildInfoAttrs = p
+		case keyAttestations:
 			if v == "" {
-				i.buildInfoAttrs = false
+				i.attestations = true
 				continue
 			}
 			b, err := strconv.ParseBool(v)
 			if err != nil {
 				return nil, errors.Wrapf(err, "non-bool value specified for %s", k)
 			}
-			i.buildInfoAttrs = b
+			i.attestations = b
+		case keyEncryptionRecipients:
+			i.encryptionRecipients = containerimage.ParseEncryptionRecipients(v)
 		case preferNondistLayersKey:
 			b, err := strconv.ParseBool(v)
 			if err != nil {
@@ -159,20 +169,38 @@ func (e *imageExporter) Resolve(ctx context.Context, opt map[string]string) (exp
 		logrus.Warn("forcibly turning on oci-mediatype mode for estargz")
 		i.ociTypes = true
 	}
+	if len(i.encryptionRecipients) > 0 && !i.ociTypes {
+		logrus.Warn("forcibly turning on oci-mediatype mode for encrypted layers")
+		i.ociTypes = true
+	}
+	if i.attestations && e.opt.Variant != VariantOCI {
+		return nil, errors.Errorf("%s is only supported by the oci exporter", keyAttestations)
+	}
+	if i.buildInfoAttestation && e.opt.Variant != VariantOCI {
+		return nil, errors.Errorf("%s=attestation is only supported by the oci exporter", keyBuildInfo)
+	}
 	return i, nil
 }
 
 type imageExporterInstance struct {
 	*imageExporter
-	meta             map[string][]byte
-	name             string
-	ociTypes         bool
-	layerCompression compression.Type
-	forceCompression bool
-	compressionLevel *int
-	buildInfo        bool
-	buildInfoAttrs   bool
-	preferNonDist    bool
+	id                   int
+	meta                 map[string][]byte
+	name                 string
+	ociTypes             bool
+	layerCompression     compression.Type
+	forceCompression     bool
+	compressionLevel     *int
+	buildInfo            bool
+	buildInfoAttestation bool
+	buildInfoAttrs       *buildinfo.AttrsPolicy
+	attestations         bool
+	preferNonDist        bool
+	encryptionRecipients []string
+}
+
+func (e *imageExporterInstance) ID() int {
+	return e.id
 }
 
 func (e *imageExporterInstance) Name() string {
@@ -218,7 +246,11 @@ func (e *imageExporterInstance) Export(ctx context.Context, src exporter.Source,
 	}
 	defer done(context.TODO())
 
-	desc, err := e.opt.ImageWriter.Commit(ctx, src, e.ociTypes, e.refCfg(), e.buildInfo, e.buildInfoAttrs, sessionID)
+	enc, err := containerimage.EncryptionConfig(ctx, e.opt.SessionManager, sessionID, e.encryptionRecipients)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := e.opt.ImageWriter.Commit(ctx, src, e.ociTypes, e.refCfg(), enc, nil, e.buildInfo, e.buildInfoAttrs, sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -245,11 +277,12 @@ func (e *imageExporterInstance) Export(ctx context.Context, src exporter.Source,
 	}
 	resp[exptypes.ExporterImageDescriptorKey] = base64.StdEncoding.EncodeToString(dtdesc)
 
-	if n, ok := src.Metadata["image.name"]; e.name == "*" && ok {
-		e.name = string(n)
+	name := e.name
+	if n, ok := src.Metadata["image.name"]; name == "*" && ok {
+		name = string(n)
 	}
 
-	names, err := normalizedNames(e.name)
+	names, err := normalizedNames(name)
 	if err != nil {
 		return nil, err
 	}
@@ -258,7 +291,28 @@ func (e *imageExporterInstance) Export(ctx context.Context, src exporter.Source,
 		resp["image.name"] = strings.Join(names, ",")
 	}
 
-	expOpts := []archiveexporter.ExportOpt{archiveexporter.WithManifest(*desc, names...)}
+	// foreign layers, e.g. the Windows base layers, can't be redistributed
+	// and are pulled from their URLs when the tarball is loaded
+	expOpts := []archiveexporter.ExportOpt{archiveexporter.WithManifest(*desc, names...), archiveexporter.WithSkipNonDistributableBlobs()}
+	if e.attestations || e.buildInfoAttestation {
+		attDescs, err := e.opt.ImageWriter.CommitAttestations(ctx, src, *desc, containerimage.AttestationOpts{
+			All:            e.attestations,
+			BuildInfo:      e.buildInfoAttestation || (e.attestations && e.buildInfo),
+			BuildInfoAttrs: e.buildInfoAttrs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, attDesc := range attDescs {
+			attDesc := attDesc
+			defer func() {
+				e.opt.ImageWriter.ContentStore().Delete(context.TODO(), attDesc.Digest)
+			}()
+			// attestation manifests are added to the index without a name
+			// and refer to the image manifests through their subject
+			expOpts = append(expOpts, archiveexporter.WithManifest(attDesc))
+		}
+	}
 	switch e.opt.Variant {
 	case VariantOCI:
 		expOpts = append(expOpts, archiveexporter.WithAllPlatforms(), archiveexporter.WithSkipDockerManifest())
@@ -275,7 +329,7 @@ func (e *imageExporterInstance) Export(ctx context.Context, src exporter.Source,
 		return nil, err
 	}
 
-	w, err := filesync.CopyFileWriter(ctx, resp, caller)
+	w, err := filesync.CopyFileWriter(ctx, resp, e.id, caller)
 	if err != nil {
 		return nil, err
 	}
@@ -41,8 +41,8 @@ func New(opt Opt) (exporter.Exporter, error) {
 	return le, nil
 }
 
-func (e *localExporter) Resolve(ctx context.Context, opt map[string]string) (exporter.ExporterInstance, error) {
-	li := &localExporterInstance{localExporter: e}
+func (e *localExporter) Resolve(ctx context.Context, id int, opt map[string]string) (exporter.ExporterInstance, error) {
+	li := &localExporterInstance{localExporter: e, id: id}
 
 	v, ok := opt[preferNondistLayersKey]
 	if ok {
@@ -58,9 +58,14 @@ func (e *localExporter) Resolve(ctx context.Context, opt map[string]string) (exp
 
 type localExporterInstance struct {
 	*localExporter
+	id            int
 	preferNonDist bool
 }
 
+func (e *localExporterInstance) ID() int {
+	return e.id
+}
+
 func (e *localExporterInstance) Name() string {
 	return "exporting to client"
 }
@@ -164,7 +169,7 @@ func (e *localExporterInstance) Export(ctx context.Context, inp exporter.Source,
 		return nil, err
 	}
 
-	w, err := filesync.CopyFileWriter(ctx, nil, caller)
+	w, err := filesync.CopyFileWriter(ctx, nil, e.id, caller)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,545 @@
+// Package bake implements the bake.v0 frontend, building the targets of a
+// bake file with the Dockerfile frontend.
+package bake
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	units "github.com/docker/go-units"
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	"github.com/moby/buildkit/frontend/dockerfile/dockerignore"
+	"github.com/moby/buildkit/frontend/gateway/client"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	localNameContext    = "context"
+	localNameDockerfile = "dockerfile"
+
+	keyFilename = "filename"
+	keyTarget   = "target"
+	keyVar      = "var:"
+
+	// keyDefinition is the metadata key of the resolved targets of the build
+	keyDefinition = "bake.definition"
+
+	dockerignoreFilename = ".dockerignore"
+)
+
+var defaultFilenames = []string{"docker-bake.hcl", "docker-bake.json"}
+
+var remoteContext = regexp.MustCompile(`^(https?://|git://|git@|github\.com/)`)
+
+// Build builds the targets of the bake file read from the dockerfile local.
+// The targets are selected with the comma separated target option, and the
+// default group is built otherwise. The contexts of the targets are
+// directories of the context local. Each target is built by the Dockerfile
+// frontend; a single target returns its result as is, several targets
+// return a result with a named build output for each target.
+func Build(ctx context.Context, c client.Client) (*client.Result, error) {
+	opts := c.BuildOpts().Opts
+
+	filenames := defaultFilenames
+	if v := opts[keyFilename]; v != "" {
+		filenames = []string{v}
+	}
+	dt, filename, err := ReadFile(ctx, c, filenames)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := Parse(dt)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", filename)
+	}
+
+	var names []string
+	if v := opts[keyTarget]; v != "" {
+		names = strings.Split(v, ",")
+	}
+	vars := map[string]string{}
+	for k, v := range opts {
+		if strings.HasPrefix(k, keyVar) {
+			vars[strings.TrimPrefix(k, keyVar)] = v
+		}
+	}
+	targets, err := cfg.Resolve(names, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := BuildTargets(ctx, c, targets, func(name string) (*Target, error) {
+		ts, err := cfg.Resolve([]string{name}, vars)
+		if err != nil {
+			return nil, err
+		}
+		if len(ts) != 1 || ts[0].Name != name {
+			return nil, errors.Errorf("target %s used as context needs to be a single target", name)
+		}
+		return ts[0], nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	res, err := NewResult(targets, results)
+	if err != nil {
+		return nil, err
+	}
+	def, err := json.Marshal(targetsByName(targets))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res.AddMeta(keyDefinition, def)
+	return res, nil
+}
+
+// Resolver returns the target of a name. It resolves the targets that are
+// only used as the contexts of the built targets.
+type Resolver func(name string) (*Target, error)
+
+// BuildTargets builds the targets with the Dockerfile frontend in parallel
+// and returns their results. The targets used as contexts of other targets
+// are built once.
+func BuildTargets(ctx context.Context, c client.Client, targets []*Target, resolve Resolver) ([]*client.Result, error) {
+	b := &builder{
+		c:       c,
+		resolve: resolve,
+		targets: map[string]*Target{},
+		results: map[string]*targetResult{},
+	}
+	for _, t := range targets {
+		b.targets[t.Name] = t
+	}
+	if err := b.checkCycles(targets); err != nil {
+		return nil, err
+	}
+
+	results := make([]*client.Result, len(targets))
+	eg, ctx := errgroup.WithContext(ctx)
+	for i, t := range targets {
+		i, t := i, t
+		eg.Go(func() error {
+			res, err := b.build(ctx, t.Name)
+			if err != nil {
+				return errors.Wrapf(err, "failed to build target %s", t.Name)
+			}
+			results[i] = res
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// NewResult returns the result of the builds of the targets. A single target
+// returns its result, several targets return a result with a named build
+// output for each target.
+func NewResult(targets []*Target, results []*client.Result) (*client.Result, error) {
+	if len(targets) == 1 {
+		return results[0], nil
+	}
+	res := client.NewResult()
+	names := make([]string, len(targets))
+	for i, t := range targets {
+		ref, md, err := targetOutput(results[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "target %s", t.Name)
+		}
+		k := exptypes.OutputKey(t.Name)
+		res.AddRef(k, ref)
+		for mk, v := range md {
+			res.AddMeta(mk+"/"+k, v)
+		}
+		names[i] = t.Name
+	}
+	dt, err := json.Marshal(names)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res.AddMeta(exptypes.ExporterOutputsKey, dt)
+	return res, nil
+}
+
+func targetsByName(targets []*Target) map[string]*Target {
+	m := make(map[string]*Target, len(targets))
+	for _, t := range targets {
+		m[t.Name] = t
+	}
+	return m
+}
+
+// targetOutput returns the reference and the metadata of the build target
+// of the result of a target. Results of several platforms can't be used as
+// build outputs.
+func targetOutput(res *client.Result) (client.Reference, map[string][]byte, error) {
+	if res.Refs == nil {
+		return res.Ref, res.Metadata, nil
+	}
+	if _, ok := res.Metadata[exptypes.ExporterOutputsKey]; !ok {
+		return nil, nil, errors.New("results of several platforms can only be built as a single target")
+	}
+	ref, ok := res.Refs[exptypes.OutputTargetKey]
+	if !ok {
+		return nil, nil, errors.New("result has no build target")
+	}
+	md := map[string][]byte{}
+	suffix := "/" + exptypes.OutputTargetKey
+	for k, v := range res.Metadata {
+		if strings.HasSuffix(k, suffix) {
+			md[strings.TrimSuffix(k, suffix)] = v
+		}
+	}
+	return ref, md, nil
+}
+
+// ReadFile reads the first of the files found in the dockerfile local and
+// returns its content and its name.
+func ReadFile(ctx context.Context, c client.Client, filenames []string) ([]byte, string, error) {
+	st := llb.Local(localNameDockerfile,
+		llb.FollowPaths(filenames),
+		llb.SessionID(c.BuildOpts().SessionID),
+		llb.SharedKeyHint(localNameDockerfile),
+		llb.WithCustomName("[internal] load build definition"),
+		llb.Differ(llb.DiffNone, false),
+	)
+	def, err := st.Marshal(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	res, err := c.Solve(ctx, client.SolveRequest{
+		Definition: def.ToPB(),
+	})
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to load build definition")
+	}
+	ref, err := res.SingleRef()
+	if err != nil {
+		return nil, "", err
+	}
+	for _, f := range filenames {
+		dt, err := ref.ReadFile(ctx, client.ReadRequest{Filename: f})
+		if err == nil {
+			return dt, f, nil
+		}
+	}
+	return nil, "", errors.Errorf("build definition not found, tried %s", strings.Join(filenames, ", "))
+}
+
+type targetResult struct {
+	done chan struct{}
+	res  *client.Result
+	err  error
+}
+
+type builder struct {
+	c       client.Client
+	resolve Resolver
+
+	mu      sync.Mutex
+	targets map[string]*Target
+	results map[string]*targetResult
+}
+
+// target returns the target of the name, resolving the targets that are
+// only used as the contexts of other targets.
+func (b *builder) target(name string) (*Target, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if t, ok := b.targets[name]; ok {
+		return t, nil
+	}
+	if b.resolve == nil {
+		return nil, errors.Errorf("target %s not found", name)
+	}
+	t, err := b.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	b.targets[name] = t
+	return t, nil
+}
+
+// targetContexts returns the names of the targets used as contexts by t.
+func targetContexts(t *Target) []string {
+	var names []string
+	for _, v := range t.Contexts {
+		if strings.HasPrefix(v, "target:") {
+			names = append(names, strings.TrimPrefix(v, "target:"))
+		}
+	}
+	return names
+}
+
+func (b *builder) checkCycles(targets []*Target) error {
+	done := map[string]struct{}{}
+	var visit func(name string, chain []string) error
+	visit = func(name string, chain []string) error {
+		for _, n := range chain {
+			if n == name {
+				return errors.Errorf("target %s uses itself as context: %s", name, strings.Join(append(chain, name), " -> "))
+			}
+		}
+		if _, ok := done[name]; ok {
+			return nil
+		}
+		t, err := b.target(name)
+		if err != nil {
+			return err
+		}
+		for _, dep := range targetContexts(t) {
+			if err := visit(dep, append(chain, name)); err != nil {
+				return err
+			}
+		}
+		done[name] = struct{}{}
+		return nil
+	}
+	for _, t := range targets {
+		if err := visit(t.Name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// build builds the target once, the other builds of the target wait for its
+// result.
+func (b *builder) build(ctx context.Context, name string) (*client.Result, error) {
+	b.mu.Lock()
+	r, ok := b.results[name]
+	if !ok {
+		r = &targetResult{done: make(chan struct{})}
+		b.results[name] = r
+	}
+	b.mu.Unlock()
+	if ok {
+		select {
+		case <-r.done:
+			return r.res, r.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	r.res, r.err = b.buildTarget(ctx, name)
+	close(r.done)
+	return r.res, r.err
+}
+
+func (b *builder) buildTarget(ctx context.Context, name string) (*client.Result, error) {
+	t, err := b.target(name)
+	if err != nil {
+		return nil, err
+	}
+	frontendOpt, inputs, err := b.frontendOpt(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	return b.c.Solve(ctx, client.SolveRequest{
+		Frontend:       "dockerfile.v0",
+		FrontendOpt:    frontendOpt,
+		FrontendInputs: inputs,
+	})
+}
+
+// frontendOpt returns the options and the inputs of the Dockerfile frontend
+// building the target.
+func (b *builder) frontendOpt(ctx context.Context, t *Target) (map[string]string, map[string]*pb.Definition, error) {
+	opts := map[string]string{}
+	inputs := map[string]*pb.Definition{}
+	sessionID := b.c.BuildOpts().SessionID
+
+	dockerfile := t.Dockerfile
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	contextPath := t.Context
+	if contextPath == "" {
+		contextPath = "."
+	}
+
+	if remoteContext.MatchString(contextPath) {
+		opts[localNameContext] = contextPath
+		opts[keyFilename] = dockerfile
+	} else {
+		contextPath = path.Clean(strings.TrimPrefix(contextPath, "/"))
+		if contextPath == ".." || strings.HasPrefix(contextPath, "../") {
+			return nil, nil, errors.Errorf("context %s is outside of the build context", t.Context)
+		}
+		excludes, err := b.readDockerignore(ctx, contextPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		localOpts := []llb.LocalOption{
+			llb.SessionID(sessionID),
+			llb.SharedKeyHint(localNameContext + ":" + contextPath),
+			llb.ExcludePatterns(excludes),
+			llb.WithCustomName("[" + t.Name + "] load build context"),
+		}
+		if contextPath != "." {
+			localOpts = append(localOpts, llb.IncludePatterns([]string{contextPath}))
+			opts["contextsubdir"] = contextPath
+		}
+		if err := addInput(ctx, inputs, localNameContext, llb.Local(localNameContext, localOpts...)); err != nil {
+			return nil, nil, err
+		}
+		if t.DockerfileInline == "" {
+			dockerfilePath := path.Join(contextPath, dockerfile)
+			st := llb.Local(localNameContext,
+				llb.SessionID(sessionID),
+				llb.FollowPaths([]string{dockerfilePath, dockerfilePath + dockerignoreFilename}),
+				llb.SharedKeyHint(localNameContext+":"+dockerfilePath),
+				llb.WithCustomName("["+t.Name+"] load build definition from "+dockerfilePath),
+				llb.Differ(llb.DiffNone, false),
+			)
+			if err := addInput(ctx, inputs, localNameDockerfile, st); err != nil {
+				return nil, nil, err
+			}
+			opts[keyFilename] = dockerfilePath
+		}
+	}
+	if t.DockerfileInline != "" {
+		st := llb.Scratch().File(llb.Mkfile("Dockerfile", 0644, []byte(t.DockerfileInline)), llb.WithCustomName("["+t.Name+"] inline Dockerfile"))
+		if err := addInput(ctx, inputs, localNameDockerfile, st); err != nil {
+			return nil, nil, err
+		}
+		opts[keyFilename] = "Dockerfile"
+	}
+
+	if t.Target != "" {
+		opts[keyTarget] = t.Target
+	}
+	for k, v := range t.Args {
+		opts["build-arg:"+k] = v
+	}
+	for k, v := range t.Labels {
+		opts["label:"+k] = v
+	}
+	if len(t.Platforms) > 0 {
+		opts["platform"] = strings.Join(t.Platforms, ",")
+	}
+	if t.NoCache {
+		opts["no-cache"] = ""
+	} else if len(t.NoCacheFilter) > 0 {
+		opts["no-cache"] = strings.Join(t.NoCacheFilter, ",")
+	}
+	if t.Pull {
+		opts["image-resolve-mode"] = pb.AttrImageResolveModeForcePull
+	}
+	if t.Network != "" {
+		opts["force-network-mode"] = t.Network
+	}
+	if t.ShmSize != "" {
+		size, err := units.RAMInBytes(t.ShmSize)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid shm-size %s", t.ShmSize)
+		}
+		// the Dockerfile frontend takes the size in kilobytes
+		opts["shm-size"] = strconv.FormatInt(size/1024, 10)
+	}
+	if len(t.Ulimits) > 0 {
+		opts["ulimit"] = strings.Join(t.Ulimits, ",")
+	}
+
+	for k, v := range t.Contexts {
+		if !strings.HasPrefix(v, "target:") {
+			opts["context:"+k] = v
+			continue
+		}
+		dep := strings.TrimPrefix(v, "target:")
+		res, err := b.build(ctx, dep)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to build target %s for context %s", dep, k)
+		}
+		ref, md, err := targetOutput(res)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "target %s for context %s", dep, k)
+		}
+		if ref == nil {
+			return nil, nil, errors.Errorf("target %s for context %s has an empty result", dep, k)
+		}
+		st, err := ref.ToState()
+		if err != nil {
+			return nil, nil, err
+		}
+		inputName := "target-" + dep
+		if err := addInput(ctx, inputs, inputName, st); err != nil {
+			return nil, nil, err
+		}
+		opts["context:"+k] = "input:" + inputName
+		if cfg, ok := md[exptypes.ExporterImageConfigKey]; ok {
+			md, err := json.Marshal(map[string][]byte{exptypes.ExporterImageConfigKey: cfg})
+			if err != nil {
+				return nil, nil, errors.WithStack(err)
+			}
+			opts["input-metadata:"+inputName] = string(md)
+		}
+	}
+	return opts, inputs, nil
+}
+
+// readDockerignore returns the exclude patterns of the .dockerignore file of
+// the context directory, relative to the root of the context local.
+func (b *builder) readDockerignore(ctx context.Context, contextPath string) ([]string, error) {
+	p := path.Join(contextPath, dockerignoreFilename)
+	st := llb.Local(localNameContext,
+		llb.SessionID(b.c.BuildOpts().SessionID),
+		llb.FollowPaths([]string{p}),
+		llb.SharedKeyHint(localNameContext+":"+p),
+		llb.WithCustomName("[internal] load "+p),
+		llb.Differ(llb.DiffNone, false),
+	)
+	def, err := st.Marshal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := b.c.Solve(ctx, client.SolveRequest{
+		Definition: def.ToPB(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ref, err := res.SingleRef()
+	if err != nil {
+		return nil, err
+	}
+	dt, err := ref.ReadFile(ctx, client.ReadRequest{Filename: p})
+	if err != nil {
+		// no .dockerignore
+		return nil, nil
+	}
+	excludes, err := dockerignore.ReadAll(bytes.NewReader(dt))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", p)
+	}
+	if contextPath == "." {
+		return excludes, nil
+	}
+	for i, e := range excludes {
+		if strings.HasPrefix(e, "!") {
+			excludes[i] = "!" + path.Join(contextPath, e[1:])
+		} else {
+			excludes[i] = path.Join(contextPath, e)
+		}
+	}
+	return excludes, nil
+}
+
+func addInput(ctx context.Context, inputs map[string]*pb.Definition, name string, st llb.State) error {
+	def, err := st.Marshal(ctx)
+	if err != nil {
+		return err
+	}
+	inputs[name] = def.ToPB()
+	return nil
+}
@@ -0,0 +1,475 @@
+package bake
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Target is a build of a Dockerfile described by a bake file.
+type Target struct {
+	Name             string            `json:"-"`
+	Context          string            `json:"context,omitempty"`
+	Dockerfile       string            `json:"dockerfile,omitempty"`
+	DockerfileInline string            `json:"dockerfile-inline,omitempty"`
+	Target           string            `json:"target,omitempty"`
+	Args             map[string]string `json:"args,omitempty"`
+	Labels           map[string]string `json:"labels,omitempty"`
+	Tags             []string          `json:"tags,omitempty"`
+	Platforms        []string          `json:"platforms,omitempty"`
+	Contexts         map[string]string `json:"contexts,omitempty"`
+	NoCache          bool              `json:"no-cache,omitempty"`
+	NoCacheFilter    []string          `json:"no-cache-filter,omitempty"`
+	Pull             bool              `json:"pull,omitempty"`
+	Network          string            `json:"network,omitempty"`
+	ShmSize          string            `json:"shm-size,omitempty"`
+	Ulimits          []string          `json:"ulimits,omitempty"`
+}
+
+// targetAttrs are the attributes of the targets, with the attributes of the
+// targets of bake files that only apply to clients and are ignored.
+var targetAttrs = map[string]bool{
+	"context": true, "dockerfile": true, "dockerfile-inline": true, "target": true,
+	"args": true, "labels": true, "tags": true, "platforms": true, "contexts": true,
+	"no-cache": true, "no-cache-filter": true, "pull": true, "network": true,
+	"shm-size": true, "ulimits": true, "inherits": true, "matrix": true, "name": true,
+	// client side
+	"description": false, "output": false, "cache-from": false, "cache-to": false,
+	"secret": false, "ssh": false, "attest": false,
+}
+
+// stringMapAttrs are the attributes of the targets holding maps of strings,
+// whose values can be written as numbers or bools.
+var stringMapAttrs = []string{"args", "labels", "contexts"}
+
+var nameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Config is a parsed bake file.
+type Config struct {
+	groups    map[string][]string
+	targets   map[string]map[string]interface{}
+	variables map[string]string
+}
+
+// Parse parses a bake file in HCL or in JSON.
+func Parse(dt []byte) (*Config, error) {
+	var blocks []*block
+	if trimmed := bytes.TrimSpace(dt); len(trimmed) > 0 && trimmed[0] == '{' {
+		var err error
+		blocks, err = parseJSON(trimmed)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		blocks, err = parseHCL(dt)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse bake file")
+		}
+	}
+
+	c := &Config{
+		groups:    map[string][]string{},
+		targets:   map[string]map[string]interface{}{},
+		variables: map[string]string{},
+	}
+	for _, b := range blocks {
+		if len(b.labels) != 1 {
+			return nil, errors.Errorf("%s block needs one name", b.typ)
+		}
+		name := b.labels[0]
+		if !nameRegexp.MatchString(name) {
+			return nil, errors.Errorf("invalid %s name %q", b.typ, name)
+		}
+		switch b.typ {
+		case "group":
+			targets, err := stringList(b.attrs["targets"])
+			if err != nil {
+				return nil, errors.Wrapf(err, "targets of group %s", name)
+			}
+			c.groups[name] = append(c.groups[name], targets...)
+		case "target":
+			for k := range b.attrs {
+				if _, ok := targetAttrs[k]; !ok {
+					return nil, errors.Errorf("unsupported attribute %s of target %s", k, name)
+				}
+			}
+			if t, ok := c.targets[name]; ok {
+				// later blocks of a target override its attributes
+				for k, v := range b.attrs {
+					t[k] = v
+				}
+			} else {
+				c.targets[name] = b.attrs
+			}
+		case "variable":
+			v := b.attrs["default"]
+			if v == nil {
+				c.variables[name] = ""
+			} else {
+				c.variables[name] = scalarString(v)
+			}
+		default:
+			return nil, errors.Errorf("unsupported block %s", b.typ)
+		}
+	}
+	return c, nil
+}
+
+// parseJSON parses the JSON form of bake files, where the blocks are the
+// values of objects keyed by their names in objects keyed by their types.
+func parseJSON(dt []byte) ([]*block, error) {
+	var m map[string]map[string]map[string]interface{}
+	if err := json.Unmarshal(dt, &m); err != nil {
+		return nil, errors.Wrap(err, "failed to parse bake file")
+	}
+	var blocks []*block
+	for _, typ := range sortedKeys(m) {
+		for _, name := range sortedKeys(m[typ]) {
+			attrs := m[typ][name]
+			if attrs == nil {
+				attrs = map[string]interface{}{}
+			}
+			blocks = append(blocks, &block{typ: typ, labels: []string{name}, attrs: attrs})
+		}
+	}
+	return blocks, nil
+}
+
+// sortedKeys returns the sorted keys of a map with string keys.
+func sortedKeys(m interface{}) []string {
+	keys := reflect.ValueOf(m).MapKeys()
+	out := make([]string, len(keys))
+	for i, k := range keys {
+		out[i] = k.String()
+	}
+	sort.Strings(out)
+	return out
+}
+
+func scalarString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return fmt.Sprintf("%v", v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func stringList(v interface{}) ([]string, error) {
+	if v == nil {
+		return nil, nil
+	}
+	l, ok := v.([]interface{})
+	if !ok {
+		return nil, errors.Errorf("expected list, got %T", v)
+	}
+	out := make([]string, 0, len(l))
+	for _, e := range l {
+		s, ok := e.(string)
+		if !ok {
+			return nil, errors.Errorf("expected string, got %T", e)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// Resolve returns the targets of the names of targets and groups, with the
+// variables overridden by vars. The targets are sorted by name.
+func (c *Config) Resolve(names []string, vars map[string]string) ([]*Target, error) {
+	variables := map[string]string{}
+	for k, v := range c.variables {
+		variables[k] = v
+	}
+	for k, v := range vars {
+		if _, ok := c.variables[k]; !ok {
+			return nil, errors.Errorf("undefined variable %s", k)
+		}
+		variables[k] = v
+	}
+
+	if len(names) == 0 {
+		switch {
+		case len(c.groups["default"]) > 0:
+			names = []string{"default"}
+		case len(c.targets) == 1:
+			names = sortedKeys(c.targets)
+		default:
+			return nil, errors.New("no targets selected and no default group")
+		}
+	}
+
+	selected := map[string]struct{}{}
+	if err := c.expandGroups(names, selected, map[string]struct{}{}); err != nil {
+		return nil, err
+	}
+
+	var targets []*Target
+	for _, name := range sortedKeys(selected) {
+		attrs, err := c.mergedAttrs(name, map[string]struct{}{})
+		if err != nil {
+			return nil, err
+		}
+		ts, err := expandMatrix(name, attrs, variables)
+		if err != nil {
+			return nil, errors.Wrapf(err, "target %s", name)
+		}
+		targets = append(targets, ts...)
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Name < targets[j].Name
+	})
+	for i := 1; i < len(targets); i++ {
+		if targets[i].Name == targets[i-1].Name {
+			return nil, errors.Errorf("duplicate target %s", targets[i].Name)
+		}
+	}
+	return targets, nil
+}
+
+func (c *Config) expandGroups(names []string, selected map[string]struct{}, visiting map[string]struct{}) error {
+	for _, name := range names {
+		if targets, ok := c.groups[name]; ok {
+			if _, ok := visiting[name]; ok {
+				return errors.Errorf("group %s includes itself", name)
+			}
+			visiting[name] = struct{}{}
+			if err := c.expandGroups(targets, selected, visiting); err != nil {
+				return err
+			}
+			delete(visiting, name)
+			continue
+		}
+		if _, ok := c.targets[name]; !ok {
+			return errors.Errorf("undefined target %s", name)
+		}
+		selected[name] = struct{}{}
+	}
+	return nil
+}
+
+// mergedAttrs returns the attributes of the target merged with those of the
+// targets it inherits from, in order. The attributes of the target take
+// precedence, and the entries of its maps are merged with the entries of the
+// inherited maps.
+func (c *Config) mergedAttrs(name string, visiting map[string]struct{}) (map[string]interface{}, error) {
+	attrs, ok := c.targets[name]
+	if !ok {
+		return nil, errors.Errorf("undefined target %s", name)
+	}
+	if _, ok := visiting[name]; ok {
+		return nil, errors.Errorf("target %s inherits from itself", name)
+	}
+	visiting[name] = struct{}{}
+	defer delete(visiting, name)
+
+	parents, err := stringList(attrs["inherits"])
+	if err != nil {
+		return nil, errors.Wrapf(err, "inherits of target %s", name)
+	}
+	merged := map[string]interface{}{}
+	for _, p := range append(parents, name) {
+		pattrs := attrs
+		if p != name {
+			if pattrs, err = c.mergedAttrs(p, visiting); err != nil {
+				return nil, err
+			}
+		}
+		for k, v := range pattrs {
+			switch k {
+			case "inherits":
+				continue
+			case "matrix", "name":
+				// a matrix only expands the target that defines it
+				if p != name {
+					continue
+				}
+			}
+			if m, ok := v.(map[string]interface{}); ok {
+				if prev, ok := merged[k].(map[string]interface{}); ok {
+					mm := make(map[string]interface{}, len(prev)+len(m))
+					for k, v := range prev {
+						mm[k] = v
+					}
+					for k, v := range m {
+						mm[k] = v
+					}
+					v = mm
+				}
+			}
+			merged[k] = v
+		}
+	}
+	return merged, nil
+}
+
+// expandMatrix returns the targets of the combinations of the values of the
+// matrix of the target, or the target itself if it has no matrix. The values
+// of the matrix are variables of the attributes of the targets.
+func expandMatrix(name string, attrs map[string]interface{}, variables map[string]string) ([]*Target, error) {
+	combinations := []map[string]string{nil}
+	if m, ok := attrs["matrix"]; ok && m != nil {
+		matrix, ok := m.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("matrix needs to be an object, got %T", m)
+		}
+		for _, k := range sortedKeys(matrix) {
+			l, ok := matrix[k].([]interface{})
+			if !ok {
+				return nil, errors.Errorf("values of matrix %s need to be a list", k)
+			}
+			var next []map[string]string
+			for _, c := range combinations {
+				for _, v := range l {
+					nc := map[string]string{k: scalarString(v)}
+					for k, v := range c {
+						nc[k] = v
+					}
+					next = append(next, nc)
+				}
+			}
+			combinations = next
+		}
+	}
+
+	var targets []*Target
+	for _, c := range combinations {
+		tname := name
+		if c != nil {
+			if n, ok := attrs["name"].(string); ok {
+				s, err := interpolate(n, variables, c)
+				if err != nil {
+					return nil, errors.Wrap(err, "name")
+				}
+				tname = s
+			} else {
+				parts := []string{name}
+				for _, k := range sortedKeys(c) {
+					parts = append(parts, c[k])
+				}
+				tname = strings.Join(parts, "-")
+			}
+			tname = sanitizeName(tname)
+		}
+		t, err := decodeTarget(tname, attrs, variables, c)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+func sanitizeName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+func decodeTarget(name string, attrs map[string]interface{}, variables, matrix map[string]string) (*Target, error) {
+	values := map[string]interface{}{}
+	for k, v := range attrs {
+		if !targetAttrs[k] || k == "inherits" || k == "matrix" || k == "name" {
+			continue
+		}
+		iv, err := interpolateValue(v, variables, matrix)
+		if err != nil {
+			return nil, errors.Wrapf(err, "attribute %s", k)
+		}
+		values[k] = iv
+	}
+	for _, k := range stringMapAttrs {
+		if m, ok := values[k].(map[string]interface{}); ok {
+			for mk, mv := range m {
+				if mv != nil {
+					m[mk] = scalarString(mv)
+				}
+			}
+		}
+	}
+	dt, err := json.Marshal(values)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var t Target
+	if err := json.Unmarshal(dt, &t); err != nil {
+		return nil, errors.Wrapf(err, "invalid target %s", name)
+	}
+	t.Name = name
+	return &t, nil
+}
+
+func interpolateValue(v interface{}, variables, matrix map[string]string) (interface{}, error) {
+	switch v := v.(type) {
+	case string:
+		return interpolate(v, variables, matrix)
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			iv, err := interpolateValue(e, variables, matrix)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = iv
+		}
+		return out, nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			iv, err := interpolateValue(e, variables, matrix)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = iv
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// interpolate replaces the ${name} sequences of s with the values of the
+// matrix or of the variables. $${ escapes a literal ${.
+func interpolate(s string, variables, matrix map[string]string) (string, error) {
+	var sb strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			sb.WriteString(s)
+			return sb.String(), nil
+		}
+		if i > 0 && s[i-1] == '$' {
+			sb.WriteString(s[:i-1])
+			sb.WriteString("${")
+			s = s[i+2:]
+			continue
+		}
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			return "", errors.Errorf("unterminated interpolation in %q", s)
+		}
+		sb.WriteString(s[:i])
+		name := strings.TrimSpace(s[i+2 : i+end])
+		v, ok := matrix[name]
+		if !ok {
+			v, ok = variables[name]
+		}
+		if !ok {
+			return "", errors.Errorf("undefined variable %s", name)
+		}
+		sb.WriteString(v)
+		s = s[i+end+1:]
+	}
+}
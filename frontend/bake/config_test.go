@@ -0,0 +1,187 @@
+package bake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHCL(t *testing.T) {
+	dt := []byte(`
+# build all the targets
+group "default" {
+  targets = ["app", "docs"]
+}
+
+variable "TAG" {
+  default = "latest"
+}
+
+target "base" {
+  dockerfile = "Dockerfile.base" // comment
+  args = {
+    GO_VERSION = "1.17"
+    DEBUG = 1
+  }
+}
+
+/* the application */
+target "app" {
+  inherits = ["base"]
+  context = "app"
+  target = "release"
+  tags = ["example/app:${TAG}"]
+  platforms = ["linux/amd64", "linux/arm64"]
+  args = {
+    DEBUG = false
+  }
+  output = ["type=registry"]
+}
+
+target "docs" {
+  dockerfile-inline = <<-EOT
+    FROM alpine
+    RUN echo "$${HOME}"
+  EOT
+  no-cache = true
+}
+`)
+	c, err := Parse(dt)
+	require.NoError(t, err)
+
+	targets, err := c.Resolve(nil, map[string]string{"TAG": "v1"})
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+
+	app := targets[0]
+	require.Equal(t, "app", app.Name)
+	require.Equal(t, "app", app.Context)
+	require.Equal(t, "Dockerfile.base", app.Dockerfile)
+	require.Equal(t, "release", app.Target)
+	require.Equal(t, []string{"example/app:v1"}, app.Tags)
+	require.Equal(t, []string{"linux/amd64", "linux/arm64"}, app.Platforms)
+	require.Equal(t, map[string]string{"GO_VERSION": "1.17", "DEBUG": "false"}, app.Args)
+
+	docs := targets[1]
+	require.Equal(t, "docs", docs.Name)
+	require.Equal(t, "FROM alpine\nRUN echo \"${HOME}\"\n", docs.DockerfileInline)
+	require.True(t, docs.NoCache)
+}
+
+func TestParseJSON(t *testing.T) {
+	dt := []byte(`{
+  "variable": {"TAG": {"default": "latest"}},
+  "group": {"default": {"targets": ["app"]}},
+  "target": {
+    "app": {
+      "context": ".",
+      "tags": ["example/app:${TAG}"],
+      "contexts": {"base": "target:base"}
+    },
+    "base": {"dockerfile": "Dockerfile.base"}
+  }
+}`)
+	c, err := Parse(dt)
+	require.NoError(t, err)
+
+	targets, err := c.Resolve(nil, nil)
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	require.Equal(t, "app", targets[0].Name)
+	require.Equal(t, []string{"example/app:latest"}, targets[0].Tags)
+	require.Equal(t, map[string]string{"base": "target:base"}, targets[0].Contexts)
+
+	targets, err = c.Resolve([]string{"base", "app"}, nil)
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+	require.Equal(t, "app", targets[0].Name)
+	require.Equal(t, "base", targets[1].Name)
+}
+
+func TestMatrix(t *testing.T) {
+	dt := []byte(`
+target "app" {
+  matrix = {
+    tgt = ["release", "debug"]
+    go = ["1.16", "1.17"]
+  }
+  target = "${tgt}"
+  args = {
+    GO_VERSION = "${go}"
+  }
+}
+
+target "named" {
+  name = "named-${item}"
+  matrix = {
+    item = ["a", "b"]
+  }
+}
+`)
+	c, err := Parse(dt)
+	require.NoError(t, err)
+
+	targets, err := c.Resolve([]string{"app"}, nil)
+	require.NoError(t, err)
+	require.Len(t, targets, 4)
+	require.Equal(t, "app-1_16-debug", targets[0].Name)
+	require.Equal(t, "debug", targets[0].Target)
+	require.Equal(t, map[string]string{"GO_VERSION": "1.16"}, targets[0].Args)
+	require.Equal(t, "app-1_17-release", targets[3].Name)
+	require.Equal(t, "release", targets[3].Target)
+
+	targets, err = c.Resolve([]string{"named"}, nil)
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+	require.Equal(t, "named-a", targets[0].Name)
+	require.Equal(t, "named-b", targets[1].Name)
+}
+
+func TestResolveErrors(t *testing.T) {
+	_, err := Parse([]byte(`target "app" { unknown = "foo" }`))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unsupported attribute unknown")
+
+	_, err = Parse([]byte(`target "app" { context = "foo" `))
+	require.Error(t, err)
+
+	c, err := Parse([]byte(`
+group "a" {
+  targets = ["b"]
+}
+group "b" {
+  targets = ["a"]
+}
+target "x" {
+  inherits = ["y"]
+}
+target "y" {
+  inherits = ["x"]
+}
+target "z" {
+  args = {
+    FOO = "${UNDEFINED}"
+  }
+}
+`))
+	require.NoError(t, err)
+
+	_, err = c.Resolve(nil, nil)
+	require.Error(t, err)
+
+	_, err = c.Resolve([]string{"a"}, nil)
+	require.Error(t, err)
+
+	_, err = c.Resolve([]string{"x"}, nil)
+	require.Error(t, err)
+
+	_, err = c.Resolve([]string{"z"}, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "undefined variable UNDEFINED")
+
+	_, err = c.Resolve([]string{"missing"}, nil)
+	require.Error(t, err)
+
+	_, err = c.Resolve([]string{"z"}, map[string]string{"UNDEFINED": "foo"})
+	require.Error(t, err)
+}
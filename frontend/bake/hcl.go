@@ -0,0 +1,416 @@
+package bake
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// block is a block of a bake file, e.g. target "app" { ... }. The values of
+// the attributes are strings, float64, bool, nil, []interface{} and
+// map[string]interface{}, as decoded from JSON. Strings are not interpolated.
+type block struct {
+	typ    string
+	labels []string
+	attrs  map[string]interface{}
+}
+
+// parseHCL parses the subset of HCL used by bake files: blocks, attributes,
+// strings, heredocs, numbers, bools, null, lists and objects. Expressions
+// other than ${name} interpolations in strings are not supported.
+func parseHCL(dt []byte) ([]*block, error) {
+	p := &hclParser{src: []rune(string(dt)), line: 1}
+	var blocks []*block
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return blocks, nil
+		}
+		b, err := p.parseBlock()
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+}
+
+type hclParser struct {
+	src  []rune
+	pos  int
+	line int
+}
+
+func (p *hclParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *hclParser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *hclParser) next() rune {
+	r := p.src[p.pos]
+	p.pos++
+	if r == '\n' {
+		p.line++
+	}
+	return r
+}
+
+func (p *hclParser) errorf(format string, args ...interface{}) error {
+	return errors.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+// skipSpace skips whitespace, newlines and comments.
+func (p *hclParser) skipSpace() {
+	for !p.eof() {
+		r := p.peek()
+		switch {
+		case unicode.IsSpace(r):
+			p.next()
+		case r == '#' || r == '/' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '/':
+			for !p.eof() && p.peek() != '\n' {
+				p.next()
+			}
+		case r == '/' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '*':
+			p.next()
+			p.next()
+			for !p.eof() && !(p.peek() == '*' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '/') {
+				p.next()
+			}
+			if !p.eof() {
+				p.next()
+				p.next()
+			}
+		default:
+			return
+		}
+	}
+}
+
+func isIdentRune(r rune, first bool) bool {
+	if unicode.IsLetter(r) || r == '_' {
+		return true
+	}
+	return !first && (unicode.IsDigit(r) || r == '-')
+}
+
+func (p *hclParser) parseIdent() (string, error) {
+	start := p.pos
+	for !p.eof() && isIdentRune(p.peek(), p.pos == start) {
+		p.next()
+	}
+	if p.pos == start {
+		if p.eof() {
+			return "", p.errorf("unexpected end of file")
+		}
+		return "", p.errorf("unexpected character %q", p.peek())
+	}
+	return string(p.src[start:p.pos]), nil
+}
+
+func (p *hclParser) expect(r rune) error {
+	p.skipSpace()
+	if p.eof() {
+		return p.errorf("expected %q, got end of file", r)
+	}
+	if c := p.peek(); c != r {
+		return p.errorf("expected %q, got %q", r, c)
+	}
+	p.next()
+	return nil
+}
+
+// parseBlock parses a block and its labels, e.g. target "app" { ... }.
+func (p *hclParser) parseBlock() (*block, error) {
+	typ, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	b := &block{typ: typ, attrs: map[string]interface{}{}}
+	for {
+		p.skipSpace()
+		switch r := p.peek(); {
+		case r == '"':
+			l, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			b.labels = append(b.labels, l)
+		case isIdentRune(r, true):
+			l, err := p.parseIdent()
+			if err != nil {
+				return nil, err
+			}
+			b.labels = append(b.labels, l)
+		case r == '{':
+			p.next()
+			return b, p.parseBody(b)
+		default:
+			return nil, p.errorf("unexpected %q in %s block", r, typ)
+		}
+	}
+}
+
+// parseBody parses the attributes of a block until its closing brace. Nested
+// blocks are not supported.
+func (p *hclParser) parseBody(b *block) error {
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return p.errorf("unclosed %s block", b.typ)
+		}
+		if p.peek() == '}' {
+			p.next()
+			return nil
+		}
+		name, err := p.parseIdent()
+		if err != nil {
+			return err
+		}
+		if err := p.expect('='); err != nil {
+			return errors.Wrapf(err, "attribute %s of %s block", name, b.typ)
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return err
+		}
+		if _, ok := b.attrs[name]; ok {
+			return p.errorf("duplicate attribute %s in %s block", name, b.typ)
+		}
+		b.attrs[name] = v
+	}
+}
+
+func (p *hclParser) parseValue() (interface{}, error) {
+	p.skipSpace()
+	if p.eof() {
+		return nil, p.errorf("expected value, got end of file")
+	}
+	switch r := p.peek(); {
+	case r == '"':
+		return p.parseString()
+	case r == '<':
+		return p.parseHeredoc()
+	case r == '[':
+		return p.parseList()
+	case r == '{':
+		return p.parseObject()
+	case r == '-' || unicode.IsDigit(r):
+		return p.parseNumber()
+	case isIdentRune(r, true):
+		id, err := p.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+		switch id {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return nil, p.errorf("unsupported expression %s, only literal values are supported", id)
+	default:
+		return nil, p.errorf("unexpected %q", r)
+	}
+}
+
+// parseString parses a quoted string. The ${...} sequences are kept for the
+// interpolation of the values.
+func (p *hclParser) parseString() (string, error) {
+	p.next()
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		r := p.next()
+		switch r {
+		case '"':
+			return sb.String(), nil
+		case '\n':
+			return "", p.errorf("unterminated string")
+		case '\\':
+			if p.eof() {
+				return "", p.errorf("unterminated string")
+			}
+			switch e := p.next(); e {
+			case 'n':
+				sb.WriteRune('\n')
+			case 't':
+				sb.WriteRune('\t')
+			case 'r':
+				sb.WriteRune('\r')
+			case '"', '\\':
+				sb.WriteRune(e)
+			default:
+				return "", p.errorf("invalid escape sequence \\%c", e)
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+}
+
+// parseHeredoc parses a <<EOT or <<-EOT heredoc. The indented form strips the
+// common leading whitespace of the lines.
+func (p *hclParser) parseHeredoc() (string, error) {
+	if p.pos+1 >= len(p.src) || p.src[p.pos+1] != '<' {
+		return "", p.errorf("unexpected %q", p.peek())
+	}
+	p.next()
+	p.next()
+	indent := false
+	if p.peek() == '-' {
+		indent = true
+		p.next()
+	}
+	marker, err := p.parseIdent()
+	if err != nil {
+		return "", err
+	}
+	for !p.eof() && p.peek() != '\n' {
+		p.next()
+	}
+	if p.eof() {
+		return "", p.errorf("unterminated heredoc %s", marker)
+	}
+	p.next()
+	var lines []string
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated heredoc %s", marker)
+		}
+		start := p.pos
+		for !p.eof() && p.peek() != '\n' {
+			p.next()
+		}
+		line := string(p.src[start:p.pos])
+		if !p.eof() {
+			p.next()
+		}
+		if strings.TrimSpace(line) == marker {
+			break
+		}
+		lines = append(lines, line)
+	}
+	if indent {
+		lines = trimIndent(lines)
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+func trimIndent(lines []string) []string {
+	min := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		n := len(l) - len(strings.TrimLeft(l, " \t"))
+		if min == -1 || n < min {
+			min = n
+		}
+	}
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		if len(l) >= min && min > 0 {
+			l = l[min:]
+		}
+		out[i] = l
+	}
+	return out
+}
+
+func (p *hclParser) parseNumber() (float64, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.next()
+	}
+	for !p.eof() && (unicode.IsDigit(p.peek()) || strings.ContainsRune(".eE+-", p.peek())) {
+		p.next()
+	}
+	v, err := strconv.ParseFloat(string(p.src[start:p.pos]), 64)
+	if err != nil {
+		return 0, p.errorf("invalid number %s", string(p.src[start:p.pos]))
+	}
+	return v, nil
+}
+
+func (p *hclParser) parseList() ([]interface{}, error) {
+	p.next()
+	l := []interface{}{}
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return nil, p.errorf("unclosed list")
+		}
+		if p.peek() == ']' {
+			p.next()
+			return l, nil
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		l = append(l, v)
+		p.skipSpace()
+		if p.peek() == ',' {
+			p.next()
+		} else if p.peek() != ']' {
+			return nil, p.errorf("expected ',' or ']' in list")
+		}
+	}
+}
+
+// parseObject parses an object with key = value or key: value items, the
+// keys being identifiers or strings.
+func (p *hclParser) parseObject() (map[string]interface{}, error) {
+	p.next()
+	m := map[string]interface{}{}
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return nil, p.errorf("unclosed object")
+		}
+		if p.peek() == '}' {
+			p.next()
+			return m, nil
+		}
+		var key string
+		var err error
+		if p.peek() == '"' {
+			key, err = p.parseString()
+		} else {
+			key, err = p.parseIdent()
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if r := p.peek(); r == '=' || r == ':' {
+			p.next()
+		} else {
+			return nil, p.errorf("expected '=' after object key %s", key)
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+		p.skipSpace()
+		if p.peek() == ',' {
+			p.next()
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package compose implements the compose.v0 frontend, building the services
+// of a compose file with the Dockerfile frontend.
+package compose
+
+import (
+	"context"
+	"strings"
+
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	"github.com/moby/buildkit/frontend/bake"
+	"github.com/moby/buildkit/frontend/gateway/client"
+	"github.com/pkg/errors"
+)
+
+const (
+	keyFilename    = "filename"
+	keyService     = "service"
+	keyProjectName = "project-name"
+	keyVar         = "var:"
+
+	// keyImageName is the metadata key of the image names of the results,
+	// used by the image exporters with name=*
+	keyImageName = "image.name"
+)
+
+var defaultFilenames = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// Build builds the services of the compose file read from the dockerfile
+// local in a single solve. The services are selected with the comma
+// separated service option, all the services with a build section are built
+// otherwise. The contexts of the services are directories of the context
+// local. A single service returns its result, several services return a
+// result with a named build output for each service. The results carry the
+// image names of the services for the image exporters with name=*.
+func Build(ctx context.Context, c client.Client) (*client.Result, error) {
+	opts := c.BuildOpts().Opts
+
+	filenames := defaultFilenames
+	if v := opts[keyFilename]; v != "" {
+		filenames = []string{v}
+	}
+	dt, filename, err := bake.ReadFile(ctx, c, filenames)
+	if err != nil {
+		return nil, err
+	}
+	vars := map[string]string{}
+	for k, v := range opts {
+		if strings.HasPrefix(k, keyVar) {
+			vars[strings.TrimPrefix(k, keyVar)] = v
+		}
+	}
+	p, err := Load(dt, vars)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load %s", filename)
+	}
+	projectName := p.Name
+	if v := opts[keyProjectName]; v != "" {
+		projectName = v
+	}
+
+	var names []string
+	if v := opts[keyService]; v != "" {
+		names = strings.Split(v, ",")
+	}
+	targets, err := p.Targets(names, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := bake.BuildTargets(ctx, c, targets, func(name string) (*bake.Target, error) {
+		return p.Target(name, vars)
+	})
+	if err != nil {
+		return nil, err
+	}
+	res, err := bake.NewResult(targets, results)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range targets {
+		name := imageName(t, projectName)
+		if name == "" {
+			continue
+		}
+		if len(targets) == 1 {
+			res.AddMeta(keyImageName, []byte(name))
+		} else {
+			res.AddMeta(keyImageName+"/"+exptypes.OutputKey(t.Name), []byte(name))
+		}
+	}
+	return res, nil
+}
+
+// imageName returns the image names of the target of a service, or the name
+// given to the images of the services without image by compose.
+func imageName(t *bake.Target, projectName string) string {
+	if len(t.Tags) > 0 {
+		return strings.Join(t.Tags, ",")
+	}
+	if projectName == "" {
+		return ""
+	}
+	return projectName + "-" + t.Name
+}
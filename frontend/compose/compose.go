@@ -0,0 +1,369 @@
+package compose
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/bake"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Project is a parsed compose file. Only the build related fields of the
+// services are decoded.
+type Project struct {
+	Name     string              `yaml:"name"`
+	Services map[string]*Service `yaml:"services"`
+}
+
+// Service is a service of a compose file.
+type Service struct {
+	Image    string       `yaml:"image"`
+	Platform string       `yaml:"platform"`
+	Build    *BuildConfig `yaml:"build"`
+}
+
+// BuildConfig is the build section of a service. The short syntax sets the
+// context.
+type BuildConfig struct {
+	Context            string             `yaml:"context"`
+	Dockerfile         string             `yaml:"dockerfile"`
+	DockerfileInline   string             `yaml:"dockerfile_inline"`
+	Args               mappingWithEquals  `yaml:"args"`
+	Labels             mappingWithEquals  `yaml:"labels"`
+	Target             string             `yaml:"target"`
+	Tags               []string           `yaml:"tags"`
+	Platforms          []string           `yaml:"platforms"`
+	AdditionalContexts mappingWithEquals  `yaml:"additional_contexts"`
+	Network            string             `yaml:"network"`
+	ShmSize            string             `yaml:"shm_size"`
+	Ulimits            map[string]*ulimit `yaml:"ulimits"`
+	NoCache            bool               `yaml:"no_cache"`
+	Pull               bool               `yaml:"pull"`
+}
+
+func (b *BuildConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		b.Context = value.Value
+		return nil
+	}
+	type build BuildConfig
+	return value.Decode((*build)(b))
+}
+
+// mappingWithEquals is a mapping written as an object or as a list of
+// KEY=VALUE items. Keys without values are nil.
+type mappingWithEquals map[string]*string
+
+func (m *mappingWithEquals) UnmarshalYAML(value *yaml.Node) error {
+	out := mappingWithEquals{}
+	switch value.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			k, v := value.Content[i], value.Content[i+1]
+			if v.Tag == "!!null" {
+				out[k.Value] = nil
+				continue
+			}
+			if v.Kind != yaml.ScalarNode {
+				return errors.Errorf("line %d: value of %s needs to be a scalar", v.Line, k.Value)
+			}
+			s := v.Value
+			out[k.Value] = &s
+		}
+	case yaml.SequenceNode:
+		for _, n := range value.Content {
+			if n.Kind != yaml.ScalarNode {
+				return errors.Errorf("line %d: items need to be KEY=VALUE strings", n.Line)
+			}
+			parts := strings.SplitN(n.Value, "=", 2)
+			if len(parts) == 1 {
+				out[parts[0]] = nil
+				continue
+			}
+			out[parts[0]] = &parts[1]
+		}
+	default:
+		return errors.Errorf("line %d: expected an object or a list", value.Line)
+	}
+	*m = out
+	return nil
+}
+
+// ulimit is a ulimit of a build, written as a single limit or with its soft
+// and hard limits.
+type ulimit struct {
+	Soft int64 `yaml:"soft"`
+	Hard int64 `yaml:"hard"`
+}
+
+func (u *ulimit) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		var v int64
+		if err := value.Decode(&v); err != nil {
+			return err
+		}
+		u.Soft, u.Hard = v, v
+		return nil
+	}
+	type limit ulimit
+	return value.Decode((*limit)(u))
+}
+
+// Load parses a compose file, interpolating the values with the variables.
+func Load(dt []byte, vars map[string]string) (*Project, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(dt, &root); err != nil {
+		return nil, errors.Wrap(err, "failed to parse compose file")
+	}
+	if err := interpolateNode(&root, vars); err != nil {
+		return nil, err
+	}
+	var p Project
+	if err := root.Decode(&p); err != nil {
+		return nil, errors.Wrap(err, "failed to parse compose file")
+	}
+	return &p, nil
+}
+
+// interpolateNode interpolates the scalar values of the node, the keys of
+// the mappings are kept.
+func interpolateNode(n *yaml.Node, vars map[string]string) error {
+	switch n.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, c := range n.Content {
+			if err := interpolateNode(c, vars); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(n.Content); i += 2 {
+			if err := interpolateNode(n.Content[i], vars); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if n.Tag != "!!str" || !strings.Contains(n.Value, "$") {
+			return nil
+		}
+		v, err := interpolate(n.Value, vars)
+		if err != nil {
+			return errors.Wrapf(err, "line %d", n.Line)
+		}
+		n.Value = v
+		if n.Style == 0 {
+			// plain values are resolved again, e.g. as numbers or bools
+			n.Tag = ""
+		}
+	}
+	return nil
+}
+
+// interpolate replaces the $VAR and ${VAR} sequences of s with the values of
+// the variables. The ${VAR:-default}, ${VAR-default}, ${VAR:+replacement},
+// ${VAR+replacement}, ${VAR:?error} and ${VAR?error} forms are supported as
+// in shells, $$ escapes a literal $. Unset variables are empty.
+func interpolate(s string, vars map[string]string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		switch c := s[i+1]; {
+		case c == '$':
+			sb.WriteByte('$')
+			i++
+		case c == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return "", errors.Errorf("unterminated variable in %q", s)
+			}
+			v, err := expand(s[i+2:i+end], vars)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(v)
+			i += end
+		case isNameByte(c, true):
+			j := i + 1
+			for j < len(s) && isNameByte(s[j], false) {
+				j++
+			}
+			sb.WriteString(vars[s[i+1:j]])
+			i = j - 1
+		default:
+			sb.WriteByte('$')
+		}
+	}
+	return sb.String(), nil
+}
+
+func isNameByte(c byte, first bool) bool {
+	if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' {
+		return true
+	}
+	return !first && c >= '0' && c <= '9'
+}
+
+// expand returns the value of the expression of a ${...} sequence.
+func expand(expr string, vars map[string]string) (string, error) {
+	i := 0
+	for i < len(expr) && isNameByte(expr[i], i == 0) {
+		i++
+	}
+	name, op := expr[:i], expr[i:]
+	if name == "" {
+		return "", errors.Errorf("invalid variable ${%s}", expr)
+	}
+	v, set := vars[name]
+	colon := strings.HasPrefix(op, ":")
+	if colon {
+		op = op[1:]
+		// the colon forms treat empty variables as unset
+		set = set && v != ""
+	}
+	if op == "" {
+		if colon {
+			return "", errors.Errorf("invalid variable ${%s}", expr)
+		}
+		return v, nil
+	}
+	arg := op[1:]
+	switch op[0] {
+	case '-':
+		if !set {
+			return arg, nil
+		}
+		return v, nil
+	case '+':
+		if set {
+			return arg, nil
+		}
+		return "", nil
+	case '?':
+		if !set {
+			if arg == "" {
+				arg = fmt.Sprintf("variable %s is required", name)
+			}
+			return "", errors.New(arg)
+		}
+		return v, nil
+	}
+	return "", errors.Errorf("invalid variable ${%s}", expr)
+}
+
+// Targets returns the targets building the services of the names, or all
+// the services with a build section. The targets are sorted by name.
+func (p *Project) Targets(names []string, vars map[string]string) ([]*bake.Target, error) {
+	if len(names) == 0 {
+		for name, s := range p.Services {
+			if s != nil && s.Build != nil {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			return nil, errors.New("no services to build")
+		}
+	}
+	sort.Strings(names)
+	targets := make([]*bake.Target, 0, len(names))
+	for i, name := range names {
+		if i > 0 && names[i-1] == name {
+			continue
+		}
+		t, err := p.Target(name, vars)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// Target returns the target building the service. The additional contexts
+// of the form service:name are the results of the builds of the services.
+func (p *Project) Target(name string, vars map[string]string) (*bake.Target, error) {
+	s, ok := p.Services[name]
+	if !ok || s == nil {
+		return nil, errors.Errorf("service %s not found", name)
+	}
+	b := s.Build
+	if b == nil {
+		return nil, errors.Errorf("service %s has no build section", name)
+	}
+	t := &bake.Target{
+		Name:             name,
+		Context:          b.Context,
+		Dockerfile:       b.Dockerfile,
+		DockerfileInline: b.DockerfileInline,
+		Target:           b.Target,
+		Args:             b.Args.values(vars),
+		Labels:           b.Labels.values(nil),
+		Tags:             s.tags(),
+		Platforms:        b.Platforms,
+		Network:          b.Network,
+		ShmSize:          b.ShmSize,
+		NoCache:          b.NoCache,
+		Pull:             b.Pull,
+	}
+	if len(t.Platforms) == 0 && s.Platform != "" {
+		t.Platforms = []string{s.Platform}
+	}
+	if contexts := b.AdditionalContexts.values(nil); len(contexts) > 0 {
+		t.Contexts = map[string]string{}
+		for k, v := range contexts {
+			if strings.HasPrefix(v, "service:") {
+				v = "target:" + strings.TrimPrefix(v, "service:")
+			}
+			t.Contexts[k] = v
+		}
+	}
+	for _, k := range sortedUlimits(b.Ulimits) {
+		u := b.Ulimits[k]
+		if u == nil {
+			continue
+		}
+		t.Ulimits = append(t.Ulimits, fmt.Sprintf("%s=%d:%d", k, u.Soft, u.Hard))
+	}
+	return t, nil
+}
+
+func sortedUlimits(m map[string]*ulimit) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// values returns the values of the mapping. Keys without values take the
+// values of the variables, and are dropped if unset.
+func (m mappingWithEquals) values(vars map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		if v != nil {
+			out[k] = *v
+		} else if vv, ok := vars[k]; ok {
+			out[k] = vv
+		}
+	}
+	return out
+}
+
+// tags returns the image names of the service.
+func (s *Service) tags() []string {
+	var tags []string
+	if s.Image != "" {
+		tags = append(tags, s.Image)
+	}
+	if s.Build != nil {
+		tags = append(tags, s.Build.Tags...)
+	}
+	return tags
+}
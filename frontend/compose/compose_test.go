@@ -0,0 +1,108 @@
+package compose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	dt := []byte(`
+name: shop
+services:
+  web:
+    image: example/web:${TAG:-latest}
+    build:
+      context: ./web
+      dockerfile: Dockerfile.prod
+      target: release
+      args:
+        - VERSION=${VERSION}
+        - HTTP_PROXY
+      labels:
+        com.example.team: web
+      platforms: ["linux/amd64", "linux/arm64"]
+      additional_contexts:
+        base: service:base
+      ulimits:
+        nofile:
+          soft: 1024
+          hard: 2048
+        nproc: 64
+      no_cache: ${NO_CACHE:-false}
+  base:
+    build: ./base
+  db:
+    image: postgres
+`)
+	p, err := Load(dt, map[string]string{"VERSION": "1.0", "HTTP_PROXY": "http://proxy", "NO_CACHE": "true"})
+	require.NoError(t, err)
+	require.Equal(t, "shop", p.Name)
+
+	targets, err := p.Targets(nil, map[string]string{"HTTP_PROXY": "http://proxy"})
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+
+	base := targets[0]
+	require.Equal(t, "base", base.Name)
+	require.Equal(t, "./base", base.Context)
+	require.Empty(t, base.Tags)
+
+	web := targets[1]
+	require.Equal(t, "web", web.Name)
+	require.Equal(t, "./web", web.Context)
+	require.Equal(t, "Dockerfile.prod", web.Dockerfile)
+	require.Equal(t, "release", web.Target)
+	require.Equal(t, map[string]string{"VERSION": "1.0", "HTTP_PROXY": "http://proxy"}, web.Args)
+	require.Equal(t, map[string]string{"com.example.team": "web"}, web.Labels)
+	require.Equal(t, []string{"linux/amd64", "linux/arm64"}, web.Platforms)
+	require.Equal(t, map[string]string{"base": "target:base"}, web.Contexts)
+	require.Equal(t, []string{"nofile=1024:2048", "nproc=64:64"}, web.Ulimits)
+	require.Equal(t, []string{"example/web:latest"}, web.Tags)
+	require.True(t, web.NoCache)
+
+	require.Equal(t, "example/web:latest", imageName(web, "shop"))
+	require.Equal(t, "shop-base", imageName(base, "shop"))
+	require.Equal(t, "", imageName(base, ""))
+
+	_, err = p.Targets([]string{"db"}, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "service db has no build section")
+
+	_, err = p.Targets([]string{"missing"}, nil)
+	require.Error(t, err)
+}
+
+func TestInterpolate(t *testing.T) {
+	vars := map[string]string{"FOO": "foo", "EMPTY": ""}
+	for _, tc := range []struct {
+		in  string
+		out string
+		err string
+	}{
+		{in: "$FOO-bar", out: "foo-bar"},
+		{in: "${FOO}bar", out: "foobar"},
+		{in: "$$FOO", out: "$FOO"},
+		{in: "${BAR}", out: ""},
+		{in: "${BAR:-default}", out: "default"},
+		{in: "${EMPTY:-default}", out: "default"},
+		{in: "${EMPTY-default}", out: ""},
+		{in: "${FOO:+set}", out: "set"},
+		{in: "${BAR+set}", out: ""},
+		{in: "${FOO:?missing}", out: "foo"},
+		{in: "${BAR:?BAR is missing}", err: "BAR is missing"},
+		{in: "${BAR?}", err: "variable BAR is required"},
+		{in: "${FOO", err: "unterminated variable"},
+		{in: "${}", err: "invalid variable"},
+		{in: "100$", out: "100$"},
+	} {
+		out, err := interpolate(tc.in, vars)
+		if tc.err != "" {
+			require.Error(t, err, tc.in)
+			require.Contains(t, err.Error(), tc.err)
+			continue
+		}
+		require.NoError(t, err, tc.in)
+		require.Equal(t, tc.out, out, tc.in)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"os"
 	"path"
 	"regexp"
 	"strconv"
@@ -24,12 +25,17 @@ import (
 	"github.com/moby/buildkit/frontend/dockerfile/parser"
 	"github.com/moby/buildkit/frontend/gateway/client"
 	gwpb "github.com/moby/buildkit/frontend/gateway/pb"
+	"github.com/moby/buildkit/frontend/sbom"
+	"github.com/moby/buildkit/frontend/subrequests/lockfile"
 	"github.com/moby/buildkit/solver/errdefs"
 	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/apicaps"
+	binfotypes "github.com/moby/buildkit/util/buildinfo/types"
+	"github.com/moby/buildkit/util/grpcerrors"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
 )
 
 const (
@@ -41,31 +47,42 @@ const (
 	buildArgPrefix = "build-arg:"
 	labelPrefix    = "label:"
 
-	keyTarget            = "target"
-	keyFilename          = "filename"
-	keyCacheFrom         = "cache-from"    // for registry only. deprecated in favor of keyCacheImports
-	keyCacheImports      = "cache-imports" // JSON representation of []CacheOptionsEntry
-	keyCgroupParent      = "cgroup-parent"
-	keyContextSubDir     = "contextsubdir"
-	keyForceNetwork      = "force-network-mode"
-	keyGlobalAddHosts    = "add-hosts"
-	keyHostname          = "hostname"
-	keyImageResolveMode  = "image-resolve-mode"
-	keyMultiPlatform     = "multi-platform"
-	keyNameContext       = "contextkey"
-	keyNameDockerfile    = "dockerfilekey"
-	keyNoCache           = "no-cache"
-	keyOverrideCopyImage = "override-copy-image" // remove after CopyOp implemented
-	keyShmSize           = "shm-size"
-	keyTargetPlatform    = "platform"
-	keyUlimit            = "ulimit"
+	keyTarget               = "target"
+	keyFilename             = "filename"
+	keyCacheFrom            = "cache-from"    // for registry only. deprecated in favor of keyCacheImports
+	keyCacheImports         = "cache-imports" // JSON representation of []CacheOptionsEntry
+	keyCgroupParent         = "cgroup-parent"
+	keyCheck                = "check"
+	keyCheckEnable          = "check-enable"
+	keyContextSubDir        = "contextsubdir"
+	keyForceNetwork         = "force-network-mode"
+	keyGlobalAddHosts       = "add-hosts"
+	keyHostname             = "hostname"
+	keyImageResolveMode     = "image-resolve-mode"
+	keyLockfile             = "lockfile"
+	keyMultiPlatform        = "multi-platform"
+	keyNameContext          = "contextkey"
+	keyNameDockerfile       = "dockerfilekey"
+	keyNoCache              = "no-cache"
+	keyOverrideCopyImage    = "override-copy-image" // remove after CopyOp implemented
+	keyPrefetch             = "prefetch"
+	keyRequestID            = "requestid"
+	keyRequirePinnedSources = "require-pinned-sources"
+	keySBOMScanStage        = "sbom-scan-stage"
+	keyShmSize              = "shm-size"
+	keyTargetPlatform       = "platform"
+	keyUlimit               = "ulimit"
 
 	// Don't forget to update frontend documentation if you add
 	// a new build-arg: frontend/dockerfile/docs/syntax.md
 	keyCacheNSArg           = "build-arg:BUILDKIT_CACHE_MOUNT_NS"
+	keyCheckArg             = "build-arg:BUILDKIT_DOCKERFILE_CHECK"
+	keyCheckEnableArg       = "build-arg:BUILDKIT_DOCKERFILE_CHECK_ENABLE"
 	keyContextKeepGitDirArg = "build-arg:BUILDKIT_CONTEXT_KEEP_GIT_DIR"
 	keyHostnameArg          = "build-arg:BUILDKIT_SANDBOX_HOSTNAME"
 	keyMultiPlatformArg     = "build-arg:BUILDKIT_MULTI_PLATFORM"
+	keyPrefetchArg          = "build-arg:BUILDKIT_PREFETCH"
+	keySBOMScanStageArg     = "build-arg:BUILDKIT_SBOM_SCAN_STAGE"
 	keySyntaxArg            = "build-arg:BUILDKIT_SYNTAX"
 )
 
@@ -89,6 +106,11 @@ func Build(ctx context.Context, c client.Client) (*client.Result, error) {
 		localNameContext = v
 	}
 
+	requirePinned, err := requirePinnedSources(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	forceLocalDockerfile := false
 	localNameDockerfile := DefaultLocalNameDockerfile
 	if v, ok := opts[keyNameDockerfile]; ok {
@@ -159,6 +181,12 @@ func Build(ctx context.Context, c client.Client) (*client.Result, error) {
 		filenames = append(filenames, path.Join(path.Dir(filename), strings.ToLower(defaultDockerfileName)))
 	}
 
+	// the lockfile is read from the context of the Dockerfile
+	lockfileName := opts[keyLockfile]
+	if lockfileName != "" {
+		filenames = append(filenames, lockfileName)
+	}
+
 	src := llb.Local(localNameDockerfile,
 		llb.FollowPaths(filenames),
 		llb.SessionID(c.BuildOpts().SessionID),
@@ -172,11 +200,19 @@ func Build(ctx context.Context, c client.Client) (*client.Result, error) {
 	var buildContext *llb.State
 	isNotLocalContext := false
 	if st, ok := detectGitContext(opts[localNameContext], opts[keyContextKeepGitDirArg]); ok {
+		if requirePinned {
+			if err := pinnedGitContext(opts[localNameContext]); err != nil {
+				return nil, err
+			}
+		}
 		if !forceLocalDockerfile {
 			src = *st
 		}
 		buildContext = st
 	} else if httpPrefix.MatchString(opts[localNameContext]) {
+		if requirePinned {
+			return nil, errors.Errorf("remote build context %s can't be pinned, required by %s", opts[localNameContext], keyRequirePinnedSources)
+		}
 		httpContext := llb.HTTP(opts[localNameContext], llb.Filename("context"), dockerfile2llb.WithInternalName("load remote build context"))
 		def, err := httpContext.Marshal(ctx, marshalOpts...)
 		if err != nil {
@@ -276,6 +312,7 @@ func Build(ctx context.Context, c client.Client) (*client.Result, error) {
 	var dtDockerfile []byte
 	var dtDockerignore []byte
 	var dtDockerignoreDefault []byte
+	var dtLockfile []byte
 	eg.Go(func() error {
 		res, err := c.Solve(ctx2, client.SolveRequest{
 			Definition: def.ToPB(),
@@ -314,19 +351,30 @@ func Build(ctx context.Context, c client.Client) (*client.Result, error) {
 		dt, err := ref.ReadFile(ctx2, client.ReadRequest{
 			Filename: filename + ".dockerignore",
 		})
-		if err == nil {
-			dtDockerignore = dt
+		if err := optionalFileErr(&gwcaps, err); err != nil {
+			return errors.Wrapf(err, "failed to read %s", filename+".dockerignore")
+		}
+		dtDockerignore = dt
+
+		if lockfileName != "" {
+			dtLockfile, err = ref.ReadFile(ctx2, client.ReadRequest{
+				Filename: lockfileName,
+			})
+			if err != nil {
+				return errors.Wrapf(err, "failed to read lockfile")
+			}
 		}
 		return nil
 	})
 	var excludes []string
+	var stageDockerignores map[string][]byte
 	if !isNotLocalContext {
 		eg.Go(func() error {
 			dockerignoreState := buildContext
 			if dockerignoreState == nil {
 				st := llb.Local(localNameContext,
 					llb.SessionID(c.BuildOpts().SessionID),
-					llb.FollowPaths([]string{dockerignoreFilename}),
+					llb.FollowPaths([]string{dockerignoreFilename, dockerignoreFilename + ".*"}),
 					llb.SharedKeyHint(localNameContext+"-"+dockerignoreFilename),
 					dockerfile2llb.WithInternalName("load "+dockerignoreFilename),
 					llb.Differ(llb.DiffNone, false),
@@ -350,8 +398,15 @@ func Build(ctx context.Context, c client.Client) (*client.Result, error) {
 			dtDockerignoreDefault, err = ref.ReadFile(ctx2, client.ReadRequest{
 				Filename: dockerignoreFilename,
 			})
-			if err != nil {
-				return nil
+			if err := optionalFileErr(&gwcaps, err); err != nil {
+				return errors.Wrapf(err, "failed to read %s", dockerignoreFilename)
+			}
+			if buildContext == nil {
+				// stage specific ignore files only apply to the local context
+				stageDockerignores, err = readStageDockerignores(ctx2, ref)
+				if err != nil {
+					return err
+				}
 			}
 			return nil
 		})
@@ -370,6 +425,16 @@ func Build(ctx context.Context, c client.Client) (*client.Result, error) {
 			return nil, errors.Wrap(err, "failed to parse dockerignore")
 		}
 	}
+	var stageExcludes map[string][]string
+	for stage, dt := range stageDockerignores {
+		if stageExcludes == nil {
+			stageExcludes = map[string][]string{}
+		}
+		stageExcludes[stage], err = dockerignore.ReadAll(bytes.NewBuffer(dt))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse dockerignore for stage %s", stage)
+		}
+	}
 
 	if _, ok := opts["cmdline"]; !ok {
 		if cmdline, ok := opts[keySyntaxArg]; ok {
@@ -392,7 +457,69 @@ func Build(ctx context.Context, c client.Client) (*client.Result, error) {
 		return nil, capsError
 	}
 
-	if res, ok, err := checkSubRequest(ctx, opts); ok {
+	var lock *lockfile.Lockfile
+	if dtLockfile != nil {
+		lock, err = lockfile.Parse(dtLockfile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts[keyRequestID] == lockfile.RequestLockfile {
+		return generateLockfile(ctx, c, targetPlatforms, marshalOpts, func(ctx context.Context, tp *ocispecs.Platform, sl *dockerfile2llb.SourceLock) error {
+			contextPaths := dockerfile2llb.NewContextPaths()
+			_, _, _, err := dockerfile2llb.Dockerfile2LLB(ctx, dtDockerfile, dockerfile2llb.ConvertOpt{
+				Target:           opts[keyTarget],
+				MetaResolver:     c,
+				BuildArgs:        filter(opts, buildArgPrefix),
+				Labels:           filter(opts, labelPrefix),
+				SessionID:        c.BuildOpts().SessionID,
+				BuildContext:     buildContext,
+				Excludes:         excludes,
+				StageExcludes:    stageExcludes,
+				TargetPlatform:   tp,
+				BuildPlatforms:   buildPlatforms,
+				ImageResolveMode: resolveMode,
+				LLBCaps:          &caps,
+				SourceMap:        sourceMap,
+				ContextByName:    contextByNameFunc(c, tp, contextPaths),
+				ContextPaths:     contextPaths,
+				SourceLock:       sl,
+			})
+			return err
+		})
+	}
+
+	// readFile reads the Dockerfiles included with INCLUDE
+	readFile := func(ctx context.Context, st llb.State, filename string) ([]byte, error) {
+		def, err := st.Marshal(ctx, marshalOpts...)
+		if err != nil {
+			return nil, err
+		}
+		res, err := c.Solve(ctx, client.SolveRequest{
+			Definition: def.ToPB(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		ref, err := res.SingleRef()
+		if err != nil {
+			return nil, err
+		}
+		return ref.ReadFile(ctx, client.ReadRequest{
+			Filename: filename,
+		})
+	}
+	// includeOpt resolves the included Dockerfiles when the stages are listed
+	// without converting the Dockerfile
+	includeOpt := dockerfile2llb.ConvertOpt{
+		SessionID:     c.BuildOpts().SessionID,
+		BuildContext:  buildContext,
+		ContextByName: contextByNameFunc(c, targetPlatforms[0], dockerfile2llb.NewContextPaths()),
+		ReadFile:      readFile,
+	}
+
+	if res, ok, err := checkSubRequest(ctx, opts, dtDockerfile, includeOpt); ok {
 		return res, err
 	}
 
@@ -421,111 +548,239 @@ func Build(ctx context.Context, c client.Client) (*client.Result, error) {
 		opts[keyHostname] = v
 	}
 
-	eg, ctx = errgroup.WithContext(ctx)
+	if v, ok := opts[keyCheckArg]; ok && len(v) > 0 {
+		opts[keyCheck] = v
+	}
 
-	for i, tp := range targetPlatforms {
-		func(i int, tp *ocispecs.Platform) {
-			eg.Go(func() (err error) {
-				defer func() {
-					var el *parser.ErrorLocation
-					if errors.As(err, &el) {
-						err = wrapSource(err, sourceMap, el.Location)
-					}
-				}()
+	if v, ok := opts[keyCheckEnableArg]; ok && len(v) > 0 {
+		opts[keyCheckEnable] = v
+	}
+	var checkEnable []string
+	if v := opts[keyCheckEnable]; v != "" {
+		checkEnable = strings.Split(v, ",")
+	}
 
-				st, img, bi, err := dockerfile2llb.Dockerfile2LLB(ctx, dtDockerfile, dockerfile2llb.ConvertOpt{
-					Target:            opts[keyTarget],
-					MetaResolver:      c,
-					BuildArgs:         filter(opts, buildArgPrefix),
-					Labels:            filter(opts, labelPrefix),
-					CacheIDNamespace:  opts[keyCacheNSArg],
-					SessionID:         c.BuildOpts().SessionID,
-					BuildContext:      buildContext,
-					Excludes:          excludes,
-					IgnoreCache:       ignoreCache,
-					TargetPlatform:    tp,
-					BuildPlatforms:    buildPlatforms,
-					ImageResolveMode:  resolveMode,
-					PrefixPlatform:    exportMap,
-					ExtraHosts:        extraHosts,
-					ShmSize:           shmSize,
-					Ulimit:            ulimit,
-					CgroupParent:      opts[keyCgroupParent],
-					ForceNetMode:      defaultNetMode,
-					OverrideCopyImage: opts[keyOverrideCopyImage],
-					LLBCaps:           &caps,
-					SourceMap:         sourceMap,
-					Hostname:          opts[keyHostname],
-					Warn: func(msg, url string, detail [][]byte, location *parser.Range) {
-						if i != 0 {
-							return
-						}
-						c.Warn(ctx, defVtx, msg, warnOpts(sourceMap, location, detail, url))
+	if v, ok := opts[keyPrefetchArg]; ok && len(v) > 0 {
+		opts[keyPrefetch] = v
+	}
+	var prefetch func(context.Context, string, ocispecs.Platform)
+	if v := opts[keyPrefetch]; v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid boolean value for %s", keyPrefetch)
+		}
+		if b && (&gwcaps).Supports(gwpb.CapGatewayPrefetch) == nil {
+			prefetch = func(ctx context.Context, ref string, p ocispecs.Platform) {
+				c.Prefetch(ctx, client.PrefetchRequest{
+					Images: []string{ref},
+					Platform: &pb.Platform{
+						OS:           p.OS,
+						Architecture: p.Architecture,
+						Variant:      p.Variant,
+						OSVersion:    p.OSVersion,
+						OSFeatures:   p.OSFeatures,
 					},
-					ContextByName: contextByNameFunc(c, tp),
 				})
+			}
+		}
+	}
 
-				if err != nil {
-					return err
-				}
+	if v, ok := opts[keySBOMScanStageArg]; ok && len(v) > 0 {
+		opts[keySBOMScanStage] = v
+	}
+	// scan records the packages of the results in their build info
+	var scan func(context.Context, client.Reference) ([]binfotypes.Package, error)
 
-				def, err := st.Marshal(ctx)
-				if err != nil {
-					return errors.Wrapf(err, "failed to marshal LLB definition")
-				}
+	// named build outputs are only returned for single platform results
+	var outputs []string
+	if !exportMap {
+		outputs, err = dockerfile2llb.OutputStages(ctx, dtDockerfile, includeOpt)
+		if err != nil {
+			return nil, err
+		}
+		// only the outputs selected by the exporters are built, older
+		// daemons don't send the selection
+		if v, ok := opts[exptypes.FrontendOutputsAttr]; ok {
+			outputs = selectOutputs(outputs, v)
+		}
+	}
+	// outputs skipped by their condition are not returned
+	builtOutputs := make([]bool, len(outputs))
 
-				config, err := json.Marshal(img)
-				if err != nil {
-					return errors.Wrapf(err, "failed to marshal image config")
-				}
+	eg, ctx = errgroup.WithContext(ctx)
 
-				var cacheImports []client.CacheOptionsEntry
-				// new API
-				if cacheImportsStr := opts[keyCacheImports]; cacheImportsStr != "" {
-					var cacheImportsUM []controlapi.CacheOptionsEntry
-					if err := json.Unmarshal([]byte(cacheImportsStr), &cacheImportsUM); err != nil {
-						return errors.Wrapf(err, "failed to unmarshal %s (%q)", keyCacheImports, cacheImportsStr)
-					}
-					for _, um := range cacheImportsUM {
-						cacheImports = append(cacheImports, client.CacheOptionsEntry{Type: um.Type, Attrs: um.Attrs})
-					}
-				}
-				// old API
-				if cacheFromStr := opts[keyCacheFrom]; cacheFromStr != "" {
-					cacheFrom := strings.Split(cacheFromStr, ",")
-					for _, s := range cacheFrom {
-						im := client.CacheOptionsEntry{
-							Type: "registry",
-							Attrs: map[string]string{
-								"ref": s,
-							},
-						}
-						// FIXME(AkihiroSuda): skip append if already exists
-						cacheImports = append(cacheImports, im)
-					}
+	// build converts the target stage for the platform and solves it. It
+	// returns the image config, the build info and the layers of the stages
+	// of the target.
+	build := func(ctx context.Context, target string, tp *ocispecs.Platform, warn bool, scanResult bool) (client.Reference, []byte, []byte, []byte, error) {
+		contextPaths := dockerfile2llb.NewContextPaths()
+		targetStages := &dockerfile2llb.TargetStages{}
+		var sourceLock *dockerfile2llb.SourceLock
+		if lock != nil {
+			sourceLock = dockerfile2llb.NewSourceLock(lock)
+		}
+		st, img, bi, err := dockerfile2llb.Dockerfile2LLB(ctx, dtDockerfile, dockerfile2llb.ConvertOpt{
+			Target:            target,
+			MetaResolver:      c,
+			BuildArgs:         filter(opts, buildArgPrefix),
+			Labels:            filter(opts, labelPrefix),
+			CacheIDNamespace:  opts[keyCacheNSArg],
+			SessionID:         c.BuildOpts().SessionID,
+			BuildContext:      buildContext,
+			Excludes:          excludes,
+			StageExcludes:     stageExcludes,
+			IgnoreCache:       ignoreCache,
+			TargetPlatform:    tp,
+			BuildPlatforms:    buildPlatforms,
+			ImageResolveMode:  resolveMode,
+			PrefixPlatform:    exportMap,
+			ExtraHosts:        extraHosts,
+			ShmSize:           shmSize,
+			Ulimit:            ulimit,
+			CgroupParent:      opts[keyCgroupParent],
+			ForceNetMode:      defaultNetMode,
+			OverrideCopyImage: opts[keyOverrideCopyImage],
+			LLBCaps:           &caps,
+			SourceMap:         sourceMap,
+			Hostname:          opts[keyHostname],
+			Check:             opts[keyCheck],
+			CheckEnable:       checkEnable,
+			Warn: func(msg, url string, detail [][]byte, location *parser.Range) {
+				if !warn {
+					return
 				}
+				c.Warn(ctx, defVtx, msg, warnOpts(sourceMap, location, detail, url))
+			},
+			ContextByName: contextByNameFunc(c, tp, contextPaths),
+			ContextPaths:  contextPaths,
+			ReadFile:      readFile,
+			Prefetch:      prefetch,
+			SourceLock:    sourceLock,
+			TargetStages:  targetStages,
+
+			RequirePinnedSources: requirePinned,
+		})
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
 
-				r, err := c.Solve(ctx, client.SolveRequest{
-					Definition:   def.ToPB(),
-					CacheImports: cacheImports,
-				})
-				if err != nil {
-					return err
+		def, err := st.Marshal(ctx)
+		if err != nil {
+			return nil, nil, nil, nil, errors.Wrapf(err, "failed to marshal LLB definition")
+		}
+
+		config, err := json.Marshal(img)
+		if err != nil {
+			return nil, nil, nil, nil, errors.Wrapf(err, "failed to marshal image config")
+		}
+
+		var cacheImports []client.CacheOptionsEntry
+		// new API
+		if cacheImportsStr := opts[keyCacheImports]; cacheImportsStr != "" {
+			var cacheImportsUM []controlapi.CacheOptionsEntry
+			if err := json.Unmarshal([]byte(cacheImportsStr), &cacheImportsUM); err != nil {
+				return nil, nil, nil, nil, errors.Wrapf(err, "failed to unmarshal %s (%q)", keyCacheImports, cacheImportsStr)
+			}
+			for _, um := range cacheImportsUM {
+				cacheImports = append(cacheImports, client.CacheOptionsEntry{Type: um.Type, Attrs: um.Attrs})
+			}
+		}
+		// old API
+		if cacheFromStr := opts[keyCacheFrom]; cacheFromStr != "" {
+			cacheFrom := strings.Split(cacheFromStr, ",")
+			for _, s := range cacheFrom {
+				im := client.CacheOptionsEntry{
+					Type: "registry",
+					Attrs: map[string]string{
+						"ref": s,
+					},
 				}
+				// FIXME(AkihiroSuda): skip append if already exists
+				cacheImports = append(cacheImports, im)
+			}
+		}
 
-				ref, err := r.SingleRef()
-				if err != nil {
-					return err
+		r, err := c.Solve(ctx, client.SolveRequest{
+			Definition:   def.ToPB(),
+			CacheImports: cacheImports,
+		})
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+
+		ref, err := r.SingleRef()
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+
+		if scan != nil && scanResult {
+			bi.Packages, err = scan(ctx, ref)
+			if err != nil {
+				return nil, nil, nil, nil, errors.Wrap(err, "failed to scan packages")
+			}
+		}
+
+		buildinfo, err := json.Marshal(bi)
+		if err != nil {
+			return nil, nil, nil, nil, errors.Wrapf(err, "failed to marshal build info")
+		}
+
+		stages, err := json.Marshal(targetStages.Stages)
+		if err != nil {
+			return nil, nil, nil, nil, errors.Wrapf(err, "failed to marshal stage layers")
+		}
+
+		return ref, config, buildinfo, stages, nil
+	}
+
+	if v := opts[keySBOMScanStage]; v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			if b {
+				scan = func(ctx context.Context, ref client.Reference) ([]binfotypes.Package, error) {
+					return sbom.Scan(ctx, ref)
 				}
+			}
+		} else {
+			scanner := &stageScanner{
+				c:           c,
+				stage:       v,
+				marshalOpts: marshalOpts,
+				build: func(ctx context.Context) (client.Reference, []byte, error) {
+					// the scanner runs on the build platform
+					ref, config, _, _, err := build(ctx, v, &buildPlatforms[0], false, false)
+					return ref, config, err
+				},
+			}
+			scan = scanner.scan
+		}
+	}
+
+	for i, tp := range targetPlatforms {
+		func(i int, tp *ocispecs.Platform) {
+			eg.Go(func() (err error) {
+				defer func() {
+					// errors in included Dockerfiles already have their source
+					var el *parser.ErrorLocation
+					if errors.As(err, &el) && len(errdefs.Sources(err)) == 0 {
+						err = wrapSource(err, sourceMap, el.Location)
+					}
+				}()
 
-				buildinfo, err := json.Marshal(bi)
+				ref, config, buildinfo, stages, err := build(ctx, opts[keyTarget], tp, i == 0, true)
 				if err != nil {
-					return errors.Wrapf(err, "failed to marshal build info")
+					return err
 				}
 
-				if !exportMap {
+				if !exportMap && len(outputs) > 0 {
+					k := exptypes.OutputTargetKey
+					res.AddMeta(fmt.Sprintf("%s/%s", exptypes.ExporterImageConfigKey, k), config)
+					res.AddMeta(fmt.Sprintf("%s/%s", exptypes.ExporterBuildInfo, k), buildinfo)
+					res.AddMeta(fmt.Sprintf("%s/%s", exptypes.ExporterImageStagesKey, k), stages)
+					res.AddRef(k, ref)
+				} else if !exportMap {
 					res.AddMeta(exptypes.ExporterImageConfigKey, config)
 					res.AddMeta(exptypes.ExporterBuildInfo, buildinfo)
+					res.AddMeta(exptypes.ExporterImageStagesKey, stages)
 					res.SetRef(ref)
 				} else {
 					p := platforms.DefaultSpec()
@@ -536,6 +791,7 @@ func Build(ctx context.Context, c client.Client) (*client.Result, error) {
 					k := platforms.Format(p)
 					res.AddMeta(fmt.Sprintf("%s/%s", exptypes.ExporterImageConfigKey, k), config)
 					res.AddMeta(fmt.Sprintf("%s/%s", exptypes.ExporterBuildInfo, k), buildinfo)
+					res.AddMeta(fmt.Sprintf("%s/%s", exptypes.ExporterImageStagesKey, k), stages)
 					res.AddRef(k, ref)
 					expPlatforms.Platforms[i] = exptypes.Platform{
 						ID:       k,
@@ -547,6 +803,36 @@ func Build(ctx context.Context, c client.Client) (*client.Result, error) {
 		}(i, tp)
 	}
 
+	for i, name := range outputs {
+		func(i int, name string) {
+			eg.Go(func() (err error) {
+				defer func() {
+					// errors in included Dockerfiles already have their source
+					var el *parser.ErrorLocation
+					if errors.As(err, &el) && len(errdefs.Sources(err)) == 0 {
+						err = wrapSource(err, sourceMap, el.Location)
+					}
+				}()
+
+				ref, config, buildinfo, stages, err := build(ctx, name, targetPlatforms[0], false, true)
+				if err != nil {
+					if errors.Is(err, dockerfile2llb.ErrStageSkipped) {
+						return nil
+					}
+					return err
+				}
+
+				k := exptypes.OutputKey(name)
+				res.AddMeta(fmt.Sprintf("%s/%s", exptypes.ExporterImageConfigKey, k), config)
+				res.AddMeta(fmt.Sprintf("%s/%s", exptypes.ExporterBuildInfo, k), buildinfo)
+				res.AddMeta(fmt.Sprintf("%s/%s", exptypes.ExporterImageStagesKey, k), stages)
+				res.AddRef(k, ref)
+				builtOutputs[i] = true
+				return nil
+			})
+		}(i, name)
+	}
+
 	if err := eg.Wait(); err != nil {
 		return nil, err
 	}
@@ -559,9 +845,92 @@ func Build(ctx context.Context, c client.Client) (*client.Result, error) {
 		res.AddMeta(exptypes.ExporterPlatformsKey, dt)
 	}
 
+	if len(outputs) > 0 {
+		names := []string{}
+		for i, name := range outputs {
+			if builtOutputs[i] {
+				names = append(names, name)
+			}
+		}
+		dt, err := json.Marshal(names)
+		if err != nil {
+			return nil, err
+		}
+		res.AddMeta(exptypes.ExporterOutputsKey, dt)
+	}
+
 	return res, nil
 }
 
+// selectOutputs returns the output stages named in the comma separated list
+// of the outputs selected by the exporters.
+func selectOutputs(outputs []string, selected string) []string {
+	names := map[string]struct{}{}
+	for _, name := range strings.Split(selected, ",") {
+		if name != "" {
+			names[name] = struct{}{}
+		}
+	}
+	var out []string
+	for _, name := range outputs {
+		if _, ok := names[name]; ok {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// readStageDockerignores reads the stage specific ignore files named
+// .dockerignore.<stage> from the root of ref.
+func readStageDockerignores(ctx context.Context, ref client.Reference) (map[string][]byte, error) {
+	stats, err := ref.ReadDir(ctx, client.ReadDirRequest{
+		Path:           "/",
+		IncludePattern: dockerignoreFilename + ".*",
+	})
+	if err != nil {
+		// older gateways can't read directories, ignore the stage files
+		if grpcerrors.Code(err) == codes.Unimplemented {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to read stage specific dockerignore files")
+	}
+	var out map[string][]byte
+	for _, st := range stats {
+		stage := strings.ToLower(strings.TrimPrefix(st.Path, dockerignoreFilename+"."))
+		if stage == "" || !os.FileMode(st.Mode).IsRegular() {
+			continue
+		}
+		dt, err := ref.ReadFile(ctx, client.ReadRequest{
+			Filename: st.Path,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if out == nil {
+			out = map[string][]byte{}
+		}
+		out[stage] = dt
+	}
+	return out, nil
+}
+
+// isNotExist returns whether err is the error of a file that doesn't exist,
+// returned by the bridge or by the gateway.
+func isNotExist(err error) bool {
+	return errors.Is(err, os.ErrNotExist) || grpcerrors.Code(err) == codes.NotFound
+}
+
+// optionalFileErr returns the error of reading an optional file, nil if the
+// file doesn't exist. The daemons without the readfile.notfound capability
+// don't tell missing files from other errors, so all their errors are
+// ignored.
+func optionalFileErr(gwcaps *apicaps.CapSet, err error) error {
+	if err == nil || gwcaps.Supports(gwpb.CapReadFileNotFound) != nil || isNotExist(err) {
+		return nil
+	}
+	return err
+}
+
 func forwardGateway(ctx context.Context, c client.Client, ref string, cmdline string) (*client.Result, error) {
 	opts := c.BuildOpts().Opts
 	if opts == nil {
@@ -682,6 +1051,8 @@ func parseResolveMode(v string) (llb.ResolveMode, error) {
 		return llb.ResolveModeForcePull, nil
 	case pb.AttrImageResolveModePreferLocal:
 		return llb.ResolveModePreferLocal, nil
+	case pb.AttrImageResolveModeLocalStore:
+		return llb.ResolveModeLocalStore, nil
 	default:
 		return 0, errors.Errorf("invalid image-resolve-mode: %s", v)
 	}
@@ -811,8 +1182,12 @@ func warnOpts(sm *llb.SourceMap, r *parser.Range, detail [][]byte, url string) c
 	return opts
 }
 
-func contextByNameFunc(c client.Client, p *ocispecs.Platform) func(context.Context, string) (*llb.State, *dockerfile2llb.Image, error) {
+func contextByNameFunc(c client.Client, p *ocispecs.Platform, cp *dockerfile2llb.ContextPaths) func(context.Context, string) (*llb.State, *dockerfile2llb.Image, error) {
 	return func(ctx context.Context, name string) (*llb.State, *dockerfile2llb.Image, error) {
+		origName := name
+		followPaths := func() []string {
+			return cp.FollowPaths(origName)
+		}
 		named, err := reference.ParseNormalizedNamed(name)
 		if err != nil {
 			return nil, nil, errors.Wrapf(err, "invalid context name %s", name)
@@ -825,7 +1200,7 @@ func contextByNameFunc(c client.Client, p *ocispecs.Platform) func(context.Conte
 		}
 		if p != nil {
 			name := name + "::" + platforms.Format(platforms.Normalize(*p))
-			st, img, err := contextByName(ctx, c, name, p)
+			st, img, err := contextByName(ctx, c, name, p, followPaths)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -833,11 +1208,14 @@ func contextByNameFunc(c client.Client, p *ocispecs.Platform) func(context.Conte
 				return st, img, nil
 			}
 		}
-		return contextByName(ctx, c, name, p)
+		return contextByName(ctx, c, name, p, followPaths)
 	}
 }
 
-func contextByName(ctx context.Context, c client.Client, name string, platform *ocispecs.Platform) (*llb.State, *dockerfile2llb.Image, error) {
+// contextByName returns the state of the named context. followPaths returns
+// the paths used from the context by the build, a local context is
+// transferred with only these paths when the build definition is marshaled.
+func contextByName(ctx context.Context, c client.Client, name string, platform *ocispecs.Platform, followPaths func() []string) (*llb.State, *dockerfile2llb.Image, error) {
 	opts := c.BuildOpts().Opts
 	v, ok := opts["context:"+name]
 	if !ok {
@@ -848,9 +1226,18 @@ func contextByName(ctx context.Context, c client.Client, name string, platform *
 	if len(vv) != 2 {
 		return nil, nil, errors.Errorf("invalid context specifier %s for %s", v, name)
 	}
+	requirePinned, err := requirePinnedSources(opts)
+	if err != nil {
+		return nil, nil, err
+	}
 	switch vv[0] {
 	case "docker-image":
 		ref := strings.TrimPrefix(vv[1], "//")
+		if requirePinned {
+			if err := pinnedImageContext(ref); err != nil {
+				return nil, nil, err
+			}
+		}
 		imgOpt := []llb.ImageOption{
 			llb.WithCustomName("[context " + name + "] " + ref),
 			llb.WithMetaResolver(c),
@@ -865,10 +1252,23 @@ func contextByName(ctx context.Context, c client.Client, name string, platform *
 		if !ok {
 			return nil, nil, errors.Errorf("invalid git context %s", v)
 		}
+		if requirePinned {
+			if err := pinnedGitContext(v); err != nil {
+				return nil, nil, err
+			}
+		}
 		return st, nil, nil
 	case "http", "https":
 		st, ok := detectGitContext(v, "1")
+		if ok && requirePinned {
+			if err := pinnedGitContext(v); err != nil {
+				return nil, nil, err
+			}
+		}
 		if !ok {
+			if requirePinned {
+				return nil, nil, errors.Errorf("remote context %s for %s can't be pinned, required by %s", v, name, keyRequirePinnedSources)
+			}
 			httpst := llb.HTTP(v, llb.WithCustomName("[context "+name+"] "+v))
 			st = &httpst
 		}
@@ -906,12 +1306,16 @@ func contextByName(ctx context.Context, c client.Client, name string, platform *
 				return nil, nil, err
 			}
 		}
-		st = llb.Local(vv[1],
-			llb.WithCustomName("[context "+name+"] load from client"),
+		localOpts := []llb.LocalOption{
+			llb.WithCustomName("[context " + name + "] load from client"),
 			llb.SessionID(c.BuildOpts().SessionID),
-			llb.SharedKeyHint("context:"+name),
+			llb.SharedKeyHint("context:" + name),
 			llb.ExcludePatterns(excludes),
-		)
+		}
+		localName := vv[1]
+		st = llb.Scratch().Async(func(context.Context, llb.State, *llb.Constraints) (llb.State, error) {
+			return llb.Local(localName, append(localOpts, llb.FollowPaths(followPaths()))...), nil
+		})
 		return &st, nil, nil
 	case "input":
 		inputs, err := c.Inputs(ctx)
@@ -0,0 +1,116 @@
+package builder
+
+import (
+	"context"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/frontend/dockerfile/dockerfile2llb"
+	"github.com/moby/buildkit/frontend/gateway/client"
+	"github.com/moby/buildkit/frontend/subrequests/lockfile"
+	binfotypes "github.com/moby/buildkit/util/buildinfo/types"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+var gitCommitPattern = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+
+// generateLockfile converts the Dockerfile for the target platforms with
+// convert and returns the lockfile of the sources it uses. The git
+// repositories and the URLs without checksum are fetched to find their pins.
+func generateLockfile(ctx context.Context, c client.Client, targetPlatforms []*ocispecs.Platform, marshalOpts []llb.ConstraintsOpt, convert func(context.Context, *ocispecs.Platform, *dockerfile2llb.SourceLock) error) (*client.Result, error) {
+	sl := dockerfile2llb.NewSourceLock(nil)
+	for _, tp := range targetPlatforms {
+		if err := convert(ctx, tp, sl); err != nil {
+			return nil, err
+		}
+	}
+
+	var l lockfile.Lockfile
+	pinned := map[lockfile.Source]int{}
+	for _, src := range sl.Sources() {
+		key := lockfile.Source{Type: src.Type, Ref: src.Ref}
+		// the same URL can be added with and without checksum
+		if i, ok := pinned[key]; ok {
+			if l.Sources[i].Pin == "" {
+				l.Sources[i].Pin = src.Pin
+			}
+			continue
+		}
+		pinned[key] = len(l.Sources)
+		l.Sources = append(l.Sources, src)
+	}
+	for i, src := range l.Sources {
+		if src.Pin != "" {
+			continue
+		}
+		pin, err := resolvePin(ctx, c, src, marshalOpts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to pin %s source %s", src.Type, src.Ref)
+		}
+		l.Sources[i].Pin = pin
+	}
+	return l.ToResult()
+}
+
+// resolvePin fetches the git repository or URL and returns its commit or its
+// checksum.
+func resolvePin(ctx context.Context, c client.Client, src lockfile.Source, marshalOpts []llb.ConstraintsOpt) (string, error) {
+	var st llb.State
+	switch src.Type {
+	case binfotypes.SourceTypeGit:
+		parts := strings.SplitN(src.Ref, "#", 2)
+		var ref string
+		if len(parts) > 1 {
+			// the git directory is not kept when checking out a subdirectory
+			ref = strings.SplitN(parts[1], ":", 2)[0]
+		}
+		st = llb.Git(parts[0], ref, llb.KeepGitDir(), dockerfile2llb.WithInternalName("resolving commit of "+src.Ref))
+	case binfotypes.SourceTypeHTTP:
+		st = llb.HTTP(src.Ref, llb.Filename("download"), dockerfile2llb.WithInternalName("resolving checksum of "+src.Ref))
+	default:
+		return "", errors.Errorf("unsupported source type %s", src.Type)
+	}
+
+	def, err := st.Marshal(ctx, marshalOpts...)
+	if err != nil {
+		return "", err
+	}
+	res, err := c.Solve(ctx, client.SolveRequest{
+		Definition: def.ToPB(),
+	})
+	if err != nil {
+		return "", err
+	}
+	ref, err := res.SingleRef()
+	if err != nil {
+		return "", err
+	}
+
+	if src.Type == binfotypes.SourceTypeGit {
+		// the repository is checked out at a detached HEAD
+		dt, err := ref.ReadFile(ctx, client.ReadRequest{Filename: ".git/HEAD"})
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read git HEAD")
+		}
+		commit := strings.TrimSpace(string(dt))
+		if !gitCommitPattern.MatchString(commit) {
+			return "", errors.Errorf("unexpected git HEAD %q", commit)
+		}
+		return commit, nil
+	}
+
+	rc, err := ref.ReadFileStream(ctx, client.ReadRequest{Filename: "download"})
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	digester := digest.Canonical.Digester()
+	if _, err := io.Copy(digester.Hash(), rc); err != nil {
+		return "", errors.Wrap(err, "failed to read download")
+	}
+	return digester.Digest().String(), nil
+}
@@ -0,0 +1,49 @@
+package builder
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/moby/buildkit/frontend/dockerfile/dockerfile2llb"
+	"github.com/pkg/errors"
+)
+
+// requirePinnedSources returns true if the build fails on the sources that
+// are not pinned. It can't be set with a build arg so that the Dockerfile
+// can't turn it off.
+func requirePinnedSources(opts map[string]string) (bool, error) {
+	v := opts[keyRequirePinnedSources]
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid boolean value for %s", keyRequirePinnedSources)
+	}
+	return b, nil
+}
+
+// pinnedGitContext checks the git context is checked out at a commit.
+func pinnedGitContext(src string) error {
+	var ref string
+	if parts := strings.SplitN(src, "#", 2); len(parts) > 1 {
+		ref = parts[1]
+	}
+	if !dockerfile2llb.IsPinnedGitRef(ref) {
+		return errors.Errorf("git context %s is not pinned to a commit, required by %s", src, keyRequirePinnedSources)
+	}
+	return nil
+}
+
+// pinnedImageContext checks the image context has a digest.
+func pinnedImageContext(src string) error {
+	ref, err := reference.ParseNormalizedNamed(src)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse image context %s", src)
+	}
+	if !dockerfile2llb.IsPinnedImage(ref) {
+		return errors.Errorf("image context %s is not pinned to a digest, required by %s", src, keyRequirePinnedSources)
+	}
+	return nil
+}
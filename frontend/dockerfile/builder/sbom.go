@@ -0,0 +1,126 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/frontend/dockerfile/dockerfile2llb"
+	"github.com/moby/buildkit/frontend/gateway/client"
+	binfotypes "github.com/moby/buildkit/util/buildinfo/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// scanSourceDir is where the scanned result is mounted in the scanner.
+	scanSourceDir = "/run/src"
+	// scanDestDir is where the scanner writes scanOutputFile.
+	scanDestDir = "/run/out"
+	// scanOutputFile is the JSON list of the packages found by the scanner.
+	scanOutputFile = "packages.json"
+)
+
+// stageScanner runs the command of a stage of the Dockerfile on the results
+// to find their packages. The result is mounted read-only at the path of
+// BUILDKIT_SCAN_SOURCE and the scanner writes the packages to packages.json
+// in the directory of BUILDKIT_SCAN_DESTINATION.
+type stageScanner struct {
+	c           client.Client
+	stage       string
+	marshalOpts []llb.ConstraintsOpt
+	// build builds the scanner stage, returning its ref and image config.
+	build func(context.Context) (client.Reference, []byte, error)
+
+	once   sync.Once
+	ref    client.Reference
+	config dockerfile2llb.ImageConfig
+	err    error
+}
+
+// load builds the scanner stage once for all the results.
+func (s *stageScanner) load(ctx context.Context) error {
+	s.once.Do(func() {
+		ref, dt, err := s.build(ctx)
+		if err != nil {
+			s.err = errors.Wrapf(err, "failed to build scanner stage %s", s.stage)
+			return
+		}
+		var img dockerfile2llb.Image
+		if err := json.Unmarshal(dt, &img); err != nil {
+			s.err = errors.Wrapf(err, "failed to parse image config of scanner stage %s", s.stage)
+			return
+		}
+		s.ref, s.config = ref, img.Config
+	})
+	return s.err
+}
+
+func (s *stageScanner) scan(ctx context.Context, ref client.Reference) ([]binfotypes.Package, error) {
+	if err := s.load(ctx); err != nil {
+		return nil, err
+	}
+	args := append(append([]string{}, s.config.Entrypoint...), s.config.Cmd...)
+	if len(args) == 0 {
+		return nil, errors.Errorf("scanner stage %s has no command", s.stage)
+	}
+
+	scanner, err := s.ref.ToState()
+	if err != nil {
+		return nil, err
+	}
+	src, err := ref.ToState()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []llb.RunOption{
+		llb.Args(args),
+		llb.AddMount(scanSourceDir, src, llb.Readonly),
+		dockerfile2llb.WithInternalName("scanning packages with stage " + s.stage),
+	}
+	for _, env := range s.config.Env {
+		parts := strings.SplitN(env, "=", 2)
+		v := ""
+		if len(parts) > 1 {
+			v = parts[1]
+		}
+		opts = append(opts, llb.AddEnv(parts[0], v))
+	}
+	opts = append(opts, llb.AddEnv("BUILDKIT_SCAN_SOURCE", scanSourceDir), llb.AddEnv("BUILDKIT_SCAN_DESTINATION", scanDestDir))
+	if s.config.WorkingDir != "" {
+		opts = append(opts, llb.Dir(s.config.WorkingDir))
+	}
+	if s.config.User != "" {
+		opts = append(opts, llb.User(s.config.User))
+	}
+	out := scanner.Run(opts...).AddMount(scanDestDir, llb.Scratch())
+
+	def, err := out.Marshal(ctx, s.marshalOpts...)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.c.Solve(ctx, client.SolveRequest{
+		Definition: def.ToPB(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	outRef, err := res.SingleRef()
+	if err != nil {
+		return nil, err
+	}
+	dt, err := outRef.ReadFile(ctx, client.ReadRequest{
+		Filename: scanOutputFile,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "scanner stage %s didn't write %s", s.stage, path.Join(scanDestDir, scanOutputFile))
+	}
+	var pkgs []binfotypes.Package
+	if err := json.Unmarshal(dt, &pkgs); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse packages of scanner stage %s", s.stage)
+	}
+	return pkgs, nil
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"encoding/json"
 
+	"github.com/moby/buildkit/frontend/dockerfile/dockerfile2llb"
 	"github.com/moby/buildkit/frontend/gateway/client"
 	"github.com/moby/buildkit/frontend/subrequests"
+	"github.com/moby/buildkit/frontend/subrequests/lockfile"
+	"github.com/moby/buildkit/frontend/subrequests/targets"
 	"github.com/moby/buildkit/solver/errdefs"
 )
 
-func checkSubRequest(ctx context.Context, opts map[string]string) (*client.Result, bool, error) {
-	req, ok := opts["requestid"]
+func checkSubRequest(ctx context.Context, opts map[string]string, dt []byte, includeOpt dockerfile2llb.ConvertOpt) (*client.Result, bool, error) {
+	req, ok := opts[keyRequestID]
 	if !ok {
 		return nil, false, nil
 	}
@@ -18,6 +21,13 @@ func checkSubRequest(ctx context.Context, opts map[string]string) (*client.Resul
 	case subrequests.RequestSubrequestsDescribe:
 		res, err := describe()
 		return res, true, err
+	case targets.RequestTargets:
+		l, err := dockerfile2llb.ListTargets(ctx, dt, includeOpt)
+		if err != nil {
+			return nil, true, err
+		}
+		res, err := l.ToResult()
+		return res, true, err
 	default:
 		return nil, true, errdefs.NewUnsupportedSubrequestError(req)
 	}
@@ -26,6 +36,8 @@ func checkSubRequest(ctx context.Context, opts map[string]string) (*client.Resul
 func describe() (*client.Result, error) {
 	all := []subrequests.Request{
 		subrequests.SubrequestsDescribeDefinition,
+		targets.SubrequestsTargetsDefinition,
+		lockfile.SubrequestsLockfileDefinition,
 	}
 	dt, err := json.MarshalIndent(all, "  ", "")
 	if err != nil {
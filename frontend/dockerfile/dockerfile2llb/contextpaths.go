@@ -0,0 +1,53 @@
+package dockerfile2llb
+
+import (
+	"path"
+	"path/filepath"
+	"sync"
+)
+
+// ContextPaths records the paths of the named contexts that are used by the
+// COPY, ADD and RUN --mount instructions of the build. The frontend uses them
+// to transfer only the needed files of a local context instead of the whole
+// directory. The paths are complete once the build definition is marshaled.
+type ContextPaths struct {
+	mu    sync.Mutex
+	paths map[string]map[string]struct{}
+}
+
+func NewContextPaths() *ContextPaths {
+	return &ContextPaths{
+		paths: map[string]map[string]struct{}{},
+	}
+}
+
+// add records that p is used from the context resolved with ContextByName for
+// name. The root path marks the whole context as used.
+func (cp *ContextPaths) add(name, p string) {
+	if cp == nil {
+		return
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	m, ok := cp.paths[name]
+	if !ok {
+		m = map[string]struct{}{}
+		cp.paths[name] = m
+	}
+	m[path.Join("/", filepath.ToSlash(p))] = struct{}{}
+}
+
+// FollowPaths returns the paths used from the context resolved for name. nil
+// is returned if the whole context is used or no use has been recorded.
+func (cp *ContextPaths) FollowPaths(name string) []string {
+	if cp == nil {
+		return nil
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	paths := normalizeContextPaths(cp.paths[name])
+	if len(paths) == 0 {
+		return nil
+	}
+	return paths
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -20,8 +21,10 @@ import (
 	"github.com/moby/buildkit/client/llb"
 	"github.com/moby/buildkit/client/llb/imagemetaresolver"
 	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/linter"
 	"github.com/moby/buildkit/frontend/dockerfile/parser"
 	"github.com/moby/buildkit/frontend/dockerfile/shell"
+	"github.com/moby/buildkit/frontend/subrequests/targets"
 	"github.com/moby/buildkit/identity"
 	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/apicaps"
@@ -29,6 +32,7 @@ import (
 	"github.com/moby/buildkit/util/suggest"
 	"github.com/moby/buildkit/util/system"
 	"github.com/moby/sys/signal"
+	digest "github.com/opencontainers/go-digest"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
@@ -42,6 +46,19 @@ const (
 	DefaultCopyImage = "docker/dockerfile-copy:v0.1.9@sha256:e8f159d3f00786604b93c675ee2783f8dc194bb565e61ca5788f6a6e9d304061"
 )
 
+const (
+	// CheckWarn reports the problems found by the linter as warnings.
+	CheckWarn = "warn"
+	// CheckError fails the build if the linter finds any problem.
+	CheckError = "error"
+	// CheckSkip disables the linter.
+	CheckSkip = "skip"
+)
+
+// ErrStageSkipped is returned when the target stage is skipped by its
+// condition.
+var ErrStageSkipped = errors.New("stage is skipped by its condition")
+
 type ConvertOpt struct {
 	Target       string
 	MetaResolver llb.ImageMetaResolver
@@ -50,6 +67,10 @@ type ConvertOpt struct {
 	SessionID    string
 	BuildContext *llb.State
 	Excludes     []string
+	// StageExcludes contains the exclude patterns of the stages that have
+	// their own ignore file, keyed by stage name. They replace Excludes for
+	// the build context of these stages.
+	StageExcludes map[string][]string
 	// IgnoreCache contains names of the stages that should not use build cache.
 	// Empty slice means ignore cache for all stages. Nil doesn't disable cache.
 	IgnoreCache []string
@@ -71,6 +92,105 @@ type ConvertOpt struct {
 	Hostname          string
 	Warn              func(short, url string, detail [][]byte, location *parser.Range)
 	ContextByName     func(context.Context, string) (*llb.State, *Image, error)
+	// ContextPaths records the paths used from the contexts returned by
+	// ContextByName. Contexts that are only sources of COPY --from and
+	// RUN --mount from get the paths used by these instructions, any other
+	// use marks the whole context as used.
+	ContextPaths *ContextPaths
+	// Check sets how the problems found by the linter are handled. See
+	// CheckWarn, CheckError and CheckSkip.
+	Check string
+	// CheckEnable are the names of the opt-in checks to run.
+	CheckEnable []string
+	// ReadFile reads a file from the solved state. It is used for reading the
+	// Dockerfiles included with INCLUDE.
+	ReadFile func(ctx context.Context, st llb.State, filename string) ([]byte, error)
+	// Prefetch starts pulling the base image for the platform in the
+	// background once its digest is resolved.
+	Prefetch func(ctx context.Context, ref string, platform ocispecs.Platform)
+	// SourceLock records the images, git repositories and URLs of the build
+	// and pins them to its lockfile.
+	SourceLock *SourceLock
+	// RequirePinnedSources fails the build on images without digest, git
+	// sources not checked out at a commit and URLs without checksum, unless
+	// they are pinned by the lockfile.
+	RequirePinnedSources bool
+	// TargetStages records the layers added by the stages of the build
+	// target.
+	TargetStages *TargetStages
+}
+
+// OutputStages returns the names of the stages declared as named build
+// outputs with "FROM ... AS output:<name>", including the stages of the
+// Dockerfiles included with the ReadFile and the contexts of opt.
+func OutputStages(ctx context.Context, dt []byte, opt ConvertOpt) ([]string, error) {
+	stages, _, _, err := parseStages(ctx, dt, opt)
+	if err != nil {
+		return nil, err
+	}
+	var outputs []string
+	for _, st := range stages {
+		if st.Output {
+			outputs = append(outputs, st.Name)
+		}
+	}
+	return outputs, nil
+}
+
+// ListTargets returns the stages of the Dockerfile that can be built as the
+// build target, with the build arguments they declare. The stages of the
+// Dockerfiles included with the ReadFile and the contexts of opt are listed
+// after the stages of the Dockerfile.
+func ListTargets(ctx context.Context, dt []byte, opt ConvertOpt) (*targets.List, error) {
+	stages, metaArgs, stageFiles, err := parseStages(ctx, dt, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	// the default target is the last stage of the main Dockerfile
+	defaultIndex := -1
+	for i := range stages {
+		if stageFiles[i] == nil {
+			defaultIndex = i
+		}
+	}
+
+	l := &targets.List{}
+	for _, cmd := range metaArgs {
+		l.Args = append(l.Args, toTargetArgs(cmd)...)
+	}
+	for i, s := range stages {
+		isDefault := i == defaultIndex
+		if s.Name == "" && !isDefault {
+			continue
+		}
+		t := targets.Target{
+			Name:        s.Name,
+			Default:     isDefault,
+			Description: s.Comment,
+			Base:        s.BaseName,
+			Platform:    s.Platform,
+		}
+		for _, cmd := range s.Commands {
+			if c, ok := cmd.(*instructions.ArgCommand); ok {
+				t.Args = append(t.Args, toTargetArgs(*c)...)
+			}
+		}
+		l.Targets = append(l.Targets, t)
+	}
+	return l, nil
+}
+
+func toTargetArgs(cmd instructions.ArgCommand) []targets.Arg {
+	args := make([]targets.Arg, 0, len(cmd.Args))
+	for _, a := range cmd.Args {
+		args = append(args, targets.Arg{
+			Name:        a.Key,
+			Value:       a.Value,
+			Description: a.Comment,
+		})
+	}
+	return args
 }
 
 func Dockerfile2LLB(ctx context.Context, dt []byte, opt ConvertOpt) (*llb.State, *Image, *binfotypes.BuildInfo, error) {
@@ -88,6 +208,10 @@ func Dockerfile2LLB(ctx context.Context, dt []byte, opt ConvertOpt) (*llb.State,
 		return nil, nil, nil, errors.Errorf("the Dockerfile cannot be empty")
 	}
 
+	if opt.Warn == nil {
+		opt.Warn = func(string, string, [][]byte, *parser.Range) {}
+	}
+
 	if opt.ContextLocalName == "" {
 		opt.ContextLocalName = defaultContextLocalName
 	}
@@ -110,11 +234,42 @@ func Dockerfile2LLB(ctx context.Context, dt []byte, opt ConvertOpt) (*llb.State,
 
 	proxyEnv := proxyEnvFromBuildArgs(opt.BuildArgs)
 
+	// Dockerfiles of the stages, nil for the stages of the main Dockerfile
+	stageFiles, err := resolveIncludes(ctx, dockerfile.AST, opt)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
 	stages, metaArgs, err := instructions.Parse(dockerfile.AST)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
+	if err := linter.ValidateRules(opt.CheckEnable); err != nil {
+		return nil, nil, nil, err
+	}
+	switch opt.Check {
+	case "", CheckWarn:
+		for _, w := range linter.Lint(stages, metaArgs, opt.CheckEnable) {
+			pw := w.ToParserWarning()
+			opt.Warn(pw.Short, pw.URL, pw.Detail, pw.Location)
+		}
+	case CheckError:
+		if warnings := linter.Lint(stages, metaArgs, opt.CheckEnable); len(warnings) > 0 {
+			// all the problems are reported, the error is located at the first
+			msgs := make([]string, 0, len(warnings))
+			for _, w := range warnings {
+				pw := w.ToParserWarning()
+				opt.Warn(pw.Short, pw.URL, pw.Detail, pw.Location)
+				msgs = append(msgs, pw.Short)
+			}
+			return nil, nil, nil, parser.WithLocation(errors.Errorf("Dockerfile checks failed: %s", strings.Join(msgs, "; ")), warnings[0].Location)
+		}
+	case CheckSkip:
+	default:
+		return nil, nil, nil, errors.Errorf("invalid check value %q", opt.Check)
+	}
+
 	shlex := shell.NewLex(dockerfile.EscapeToken)
 
 	for _, cmd := range metaArgs {
@@ -135,20 +290,35 @@ func Dockerfile2LLB(ctx context.Context, dt []byte, opt ConvertOpt) (*llb.State,
 
 	// set base state for every image
 	for i, st := range stages {
+		if st.Condition != "" {
+			enabled, err := evalStageCondition(shlex, st.Condition, metaArgsToMap(optMetaArgs))
+			if err != nil {
+				return nil, nil, nil, stageFiles[i].withLocation(err, st.Location)
+			}
+			if !enabled {
+				allDispatchStates.skipStage(st)
+				continue
+			}
+		}
+
 		name, err := shlex.ProcessWordWithMap(st.BaseName, metaArgsToMap(optMetaArgs))
 		if err != nil {
-			return nil, nil, nil, parser.WithLocation(err, st.Location)
+			return nil, nil, nil, stageFiles[i].withLocation(err, st.Location)
 		}
 		if name == "" {
-			return nil, nil, nil, parser.WithLocation(errors.Errorf("base name (%s) should not be blank", st.BaseName), st.Location)
+			return nil, nil, nil, stageFiles[i].withLocation(errors.Errorf("base name (%s) should not be blank", st.BaseName), st.Location)
 		}
 		st.BaseName = name
+		if _, ok := allDispatchStates.findStateByName(name); !ok && allDispatchStates.isSkipped(name) {
+			return nil, nil, nil, stageFiles[i].withLocation(errors.Errorf("base stage %s is skipped by its condition", name), st.Location)
+		}
 
 		ds := &dispatchState{
 			deps:           make(map[*dispatchState]struct{}),
 			ctxPaths:       make(map[string]struct{}),
 			stageName:      st.Name,
 			prefixPlatform: opt.PrefixPlatform,
+			include:        stageFiles[i],
 		}
 
 		if st.Name != "" {
@@ -157,6 +327,7 @@ func Dockerfile2LLB(ctx context.Context, dt []byte, opt ConvertOpt) (*llb.State,
 				return nil, nil, nil, err
 			}
 			if s != nil {
+				opt.ContextPaths.add(st.Name, "/")
 				ds.noinit = true
 				ds.state = *s
 				if img != nil {
@@ -176,17 +347,44 @@ func Dockerfile2LLB(ctx context.Context, dt []byte, opt ConvertOpt) (*llb.State,
 		if v := st.Platform; v != "" {
 			v, err := shlex.ProcessWordWithMap(v, metaArgsToMap(optMetaArgs))
 			if err != nil {
-				return nil, nil, nil, parser.WithLocation(errors.Wrapf(err, "failed to process arguments for platform %s", v), st.Location)
+				return nil, nil, nil, stageFiles[i].withLocation(errors.Wrapf(err, "failed to process arguments for platform %s", v), st.Location)
 			}
 
 			p, err := platforms.Parse(v)
 			if err != nil {
-				return nil, nil, nil, parser.WithLocation(errors.Wrapf(err, "failed to parse platform %s", v), st.Location)
+				return nil, nil, nil, stageFiles[i].withLocation(errors.Wrapf(err, "failed to parse platform %s", v), st.Location)
 			}
 			ds.platform = &p
 		}
 		allDispatchStates.addState(ds)
 
+		if opt.IgnoreCache != nil {
+			if len(opt.IgnoreCache) == 0 {
+				ds.ignoreCache = true
+			} else if st.Name != "" {
+				for _, n := range opt.IgnoreCache {
+					if strings.EqualFold(n, st.Name) {
+						ds.ignoreCache = true
+					}
+				}
+			}
+		}
+	}
+
+	if len(allDispatchStates.states) == 0 {
+		return nil, nil, nil, errors.New("all build stages are skipped by their conditions")
+	}
+
+	// the included stages come after the stages of the Dockerfile, the
+	// stages based on them are linked once all the stages are known
+	if err := allDispatchStates.linkIncludedBases(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	for _, ds := range allDispatchStates.states {
+		if ds.noinit {
+			continue
+		}
 		total := 0
 		if ds.stage.BaseName != emptyImageName && ds.base == nil {
 			total = 1
@@ -202,27 +400,21 @@ func Dockerfile2LLB(ctx context.Context, dt []byte, opt ConvertOpt) (*llb.State,
 			}
 		}
 		ds.cmdTotal = total
-
-		if opt.IgnoreCache != nil {
-			if len(opt.IgnoreCache) == 0 {
-				ds.ignoreCache = true
-			} else if st.Name != "" {
-				for _, n := range opt.IgnoreCache {
-					if strings.EqualFold(n, st.Name) {
-						ds.ignoreCache = true
-					}
-				}
-			}
-		}
 	}
 
 	var target *dispatchState
 	if opt.Target == "" {
 		target = allDispatchStates.lastTarget()
+		if target == nil {
+			return nil, nil, nil, errors.New("all build stages of the Dockerfile are skipped by their conditions")
+		}
 	} else {
 		var ok bool
 		target, ok = allDispatchStates.findStateByName(opt.Target)
 		if !ok {
+			if allDispatchStates.isSkipped(opt.Target) {
+				return nil, nil, nil, errors.Wrapf(ErrStageSkipped, "target stage %s", opt.Target)
+			}
 			return nil, nil, nil, errors.Errorf("target stage %s could not be found", opt.Target)
 		}
 	}
@@ -231,9 +423,11 @@ func Dockerfile2LLB(ctx context.Context, dt []byte, opt ConvertOpt) (*llb.State,
 	for _, d := range allDispatchStates.states {
 		d.commands = make([]command, len(d.stage.Commands))
 		for i, cmd := range d.stage.Commands {
-			newCmd, err := toCommand(cmd, allDispatchStates)
+			newCmd, err := toCommand(cmd, allDispatchStates, func(word string) (string, error) {
+				return shlex.ProcessWordWithMap(word, metaArgsToMap(optMetaArgs))
+			})
 			if err != nil {
-				return nil, nil, nil, err
+				return nil, nil, nil, d.withLocation(err, cmd.Location())
 			}
 			d.commands[i] = newCmd
 			for _, src := range newCmd.sources {
@@ -276,7 +470,7 @@ func Dockerfile2LLB(ctx context.Context, dt []byte, opt ConvertOpt) (*llb.State,
 				eg.Go(func() (err error) {
 					defer func() {
 						if err != nil {
-							err = parser.WithLocation(err, d.stage.Location)
+							err = d.withLocation(err, d.stage.Location)
 						}
 					}()
 					origName := d.stage.BaseName
@@ -296,6 +490,13 @@ func Dockerfile2LLB(ctx context.Context, dt []byte, opt ConvertOpt) (*llb.State,
 						return err
 					}
 					if st != nil {
+						if d.unregistered {
+							// only used as source, the paths are recorded
+							// when the commands are dispatched
+							d.contextName = d.stage.BaseName
+						} else {
+							opt.ContextPaths.add(d.stage.BaseName, "/")
+						}
 						if img != nil {
 							d.image = *img
 						} else {
@@ -306,6 +507,9 @@ func Dockerfile2LLB(ctx context.Context, dt []byte, opt ConvertOpt) (*llb.State,
 						return nil
 					}
 					if reachable {
+						if err := pinnedImage(opt, origName, ref); err != nil {
+							return err
+						}
 						prefix := "["
 						if opt.PrefixPlatform && platform != nil {
 							prefix += platforms.Format(*platform) + " "
@@ -329,6 +533,9 @@ func Dockerfile2LLB(ctx context.Context, dt []byte, opt ConvertOpt) (*llb.State,
 							p := autoDetectPlatform(img, *platform, platformOpt.buildPlatforms)
 							platform = &p
 						}
+						if err := opt.SourceLock.image(origName, dgst); err != nil {
+							return err
+						}
 						if dgst != "" {
 							ref, err = reference.WithDigest(ref, dgst)
 							if err != nil {
@@ -336,6 +543,9 @@ func Dockerfile2LLB(ctx context.Context, dt []byte, opt ConvertOpt) (*llb.State,
 							}
 						}
 						d.stage.BaseName = ref.String()
+						if opt.Prefetch != nil && dgst != "" && len(img.RootFS.DiffIDs) > 0 {
+							opt.Prefetch(ctx, d.stage.BaseName, *platform)
+						}
 						if len(img.RootFS.DiffIDs) == 0 {
 							isScratch = true
 							// schema1 images can't return diffIDs so double check :(
@@ -366,7 +576,7 @@ func Dockerfile2LLB(ctx context.Context, dt []byte, opt ConvertOpt) (*llb.State,
 							llb.Platform(*platform),
 							opt.ImageResolveMode,
 							llb.WithCustomName(prefixCommand(d, "FROM "+d.stage.BaseName, opt.PrefixPlatform, platform, nil)),
-							location(opt.SourceMap, d.stage.Location),
+							location(d.sourceMap(opt.SourceMap), d.stage.Location),
 						)
 					}
 					d.platform = platform
@@ -382,13 +592,25 @@ func Dockerfile2LLB(ctx context.Context, dt []byte, opt ConvertOpt) (*llb.State,
 
 	buildContext := &mutableOutput{}
 	ctxPaths := map[string]struct{}{}
+	stageContexts := map[string]*stageContext{}
 	buildInfo := &binfotypes.BuildInfo{}
 
-	for _, d := range allDispatchStates.states {
+	for _, d := range dispatchOrder(allDispatchStates.states) {
 		if !isReachable(target, d) {
 			continue
 		}
 
+		stageBuildContext, stageCtxPaths := buildContext, ctxPaths
+		if excludes, ok := opt.StageExcludes[d.stage.Name]; ok && opt.BuildContext == nil {
+			sc := &stageContext{
+				output:   &mutableOutput{},
+				excludes: excludes,
+				ctxPaths: map[string]struct{}{},
+			}
+			stageContexts[d.stage.Name] = sc
+			stageBuildContext, stageCtxPaths = sc.output, sc.ctxPaths
+		}
+
 		// collect build sources and dependencies
 		if d.buildSource != nil {
 			buildInfo.Sources = append(buildInfo.Sources, *d.buildSource)
@@ -398,7 +620,11 @@ func Dockerfile2LLB(ctx context.Context, dt []byte, opt ConvertOpt) (*llb.State,
 			d.state = d.base.state
 			d.platform = d.base.platform
 			d.image = clone(d.base.image)
+			d.firstLayer = d.base.layers
+		} else {
+			d.firstLayer = len(d.image.RootFS.DiffIDs)
 		}
+		historyStart := len(d.image.History)
 
 		// make sure that PATH is always set
 		if _, ok := shell.BuildEnvs(d.image.Config.Env)["PATH"]; !ok {
@@ -419,12 +645,12 @@ func Dockerfile2LLB(ctx context.Context, dt []byte, opt ConvertOpt) (*llb.State,
 		}
 		if d.image.Config.WorkingDir != "" {
 			if err = dispatchWorkdir(d, &instructions.WorkdirCommand{Path: d.image.Config.WorkingDir}, false, nil); err != nil {
-				return nil, nil, nil, parser.WithLocation(err, d.stage.Location)
+				return nil, nil, nil, d.withLocation(err, d.stage.Location)
 			}
 		}
 		if d.image.Config.User != "" {
 			if err = dispatchUser(d, &instructions.UserCommand{User: d.image.Config.User}, false); err != nil {
-				return nil, nil, nil, parser.WithLocation(err, d.stage.Location)
+				return nil, nil, nil, d.withLocation(err, d.stage.Location)
 			}
 		}
 		d.state = d.state.Network(opt.ForceNetMode)
@@ -435,7 +661,7 @@ func Dockerfile2LLB(ctx context.Context, dt []byte, opt ConvertOpt) (*llb.State,
 			buildArgValues:    opt.BuildArgs,
 			shlex:             shlex,
 			sessionID:         opt.SessionID,
-			buildContext:      llb.NewState(buildContext),
+			buildContext:      llb.NewState(stageBuildContext),
 			proxyEnv:          proxyEnv,
 			cacheIDNamespace:  opt.CacheIDNamespace,
 			buildPlatforms:    platformOpt.buildPlatforms,
@@ -446,27 +672,34 @@ func Dockerfile2LLB(ctx context.Context, dt []byte, opt ConvertOpt) (*llb.State,
 			cgroupParent:      opt.CgroupParent,
 			copyImage:         opt.OverrideCopyImage,
 			llbCaps:           opt.LLBCaps,
-			sourceMap:         opt.SourceMap,
+			sourceMap:         d.sourceMap(opt.SourceMap),
+			contextPaths:      opt.ContextPaths,
+			sourceLock:        opt.SourceLock,
+
+			requirePinnedSources: opt.RequirePinnedSources,
 		}
 		if opt.copyImage == "" {
 			opt.copyImage = DefaultCopyImage
 		}
 
 		if err = dispatchOnBuildTriggers(d, d.image.Config.OnBuild, opt); err != nil {
-			return nil, nil, nil, parser.WithLocation(err, d.stage.Location)
+			return nil, nil, nil, d.withLocation(err, d.stage.Location)
 		}
 		d.image.Config.OnBuild = nil
 
 		for _, cmd := range d.commands {
 			if err := dispatch(d, cmd, opt); err != nil {
-				return nil, nil, nil, parser.WithLocation(err, cmd.Location())
+				return nil, nil, nil, d.withLocation(err, cmd.Location())
 			}
 		}
 
+		d.layers = d.firstLayer + historyLayers(d.image.History[historyStart:])
+
 		for p := range d.ctxPaths {
-			ctxPaths[p] = struct{}{}
+			stageCtxPaths[p] = struct{}{}
 		}
 	}
+	opt.TargetStages.record(target)
 
 	// sort build sources
 	if len(buildInfo.Sources) > 0 {
@@ -482,22 +715,16 @@ func Dockerfile2LLB(ctx context.Context, dt []byte, opt ConvertOpt) (*llb.State,
 		target.image.Config.Labels[k] = v
 	}
 
-	opts := []llb.LocalOption{
-		llb.SessionID(opt.SessionID),
-		llb.ExcludePatterns(opt.Excludes),
-		llb.SharedKeyHint(opt.ContextLocalName),
-		WithInternalName("load build context"),
-	}
-	if includePatterns := normalizeContextPaths(ctxPaths); includePatterns != nil {
-		opts = append(opts, llb.FollowPaths(includePatterns))
-	}
-
-	bc := llb.Local(opt.ContextLocalName, opts...)
+	bc := localBuildContext(opt, "", opt.Excludes, ctxPaths)
 	if opt.BuildContext != nil {
 		bc = *opt.BuildContext
 	}
 	buildContext.Output = bc.Output()
 
+	for name, sc := range stageContexts {
+		sc.output.Output = localBuildContext(opt, name, sc.excludes, sc.ctxPaths).Output()
+	}
+
 	defaults := []llb.ConstraintsOpt{
 		llb.Platform(platformOpt.targetPlatform),
 	}
@@ -525,7 +752,7 @@ func metaArgsToMap(metaArgs []instructions.KeyValuePairOptional) map[string]stri
 	return m
 }
 
-func toCommand(ic instructions.Command, allDispatchStates *dispatchStates) (command, error) {
+func toCommand(ic instructions.Command, allDispatchStates *dispatchStates, expand instructions.SingleWordExpander) (command, error) {
 	cmd := command{Command: ic}
 	if c, ok := ic.(*instructions.CopyCommand); ok {
 		if c.From != "" {
@@ -550,8 +777,14 @@ func toCommand(ic instructions.Command, allDispatchStates *dispatchStates) (comm
 		}
 	}
 
-	if ok := detectRunMount(&cmd, allDispatchStates); ok {
-		return cmd, nil
+	if _, err := detectRunMount(&cmd, allDispatchStates, expand); err != nil {
+		return command{}, parser.WithLocation(err, ic.Location())
+	}
+
+	for _, src := range cmd.sources {
+		if src.unregistered && allDispatchStates.isSkipped(src.stage.BaseName) {
+			return command{}, parser.WithLocation(errors.Errorf("stage %s is skipped by its condition", src.stage.BaseName), ic.Location())
+		}
 	}
 
 	return cmd, nil
@@ -575,6 +808,10 @@ type dispatchOpt struct {
 	copyImage         string
 	llbCaps           *apicaps.CapSet
 	sourceMap         *llb.SourceMap
+	contextPaths      *ContextPaths
+	sourceLock        *SourceLock
+
+	requirePinnedSources bool
 }
 
 func dispatch(d *dispatchState, cmd command, opt dispatchOpt) error {
@@ -625,12 +862,25 @@ func dispatch(d *dispatchState, cmd command, opt dispatchOpt) error {
 			chown:        c.Chown,
 			chmod:        c.Chmod,
 			link:         c.Link,
-			location:     c.Location(),
-			opt:          opt,
+			checksum:     c.Checksum,
+			excludes:     c.ExcludePatterns,
+			git: gitConfig{
+				keepGitDir:     c.KeepGitDir,
+				skipSubmodules: !c.Submodules,
+				lfs:            c.LFS,
+			},
+			authSecret: c.AuthSecret,
+			unpack: unpackConfig{
+				format:          c.Unpack,
+				stripComponents: c.UnpackStripComponents,
+				subpath:         c.UnpackSubpath,
+			},
+			location: c.Location(),
+			opt:      opt,
 		})
 		if err == nil {
 			for _, src := range c.SourcePaths {
-				if !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") {
+				if !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") && !isGitSource(src) {
 					d.ctxPaths[path.Join("/", filepath.ToSlash(src))] = struct{}{}
 				}
 			}
@@ -670,6 +920,8 @@ func dispatch(d *dispatchState, cmd command, opt dispatchOpt) error {
 			chown:        c.Chown,
 			chmod:        c.Chmod,
 			link:         c.Link,
+			checksum:     c.Checksum,
+			excludes:     c.ExcludePatterns,
 			location:     c.Location(),
 			opt:          opt,
 		})
@@ -677,6 +929,10 @@ func dispatch(d *dispatchState, cmd command, opt dispatchOpt) error {
 			for _, src := range c.SourcePaths {
 				d.ctxPaths[path.Join("/", filepath.ToSlash(src))] = struct{}{}
 			}
+		} else if err == nil && cmd.sources[0].contextName != "" {
+			for _, src := range c.SourcePaths {
+				opt.contextPaths.add(cmd.sources[0].contextName, src)
+			}
 		}
 	default:
 	}
@@ -702,26 +958,89 @@ type dispatchState struct {
 	cmdTotal       int
 	prefixPlatform bool
 	buildSource    *binfotypes.Source
+	// contextName is the name the state of an unregistered source was
+	// resolved with by ContextByName.
+	contextName string
+	// include is the included Dockerfile the stage is defined in
+	include *includedFile
+	// firstLayer is the index of the first layer added by the stage to its
+	// image, layers the number of layers of the image once it is dispatched
+	firstLayer int
+	layers     int
+}
+
+// sourceMap returns the source map of the Dockerfile the stage is defined in.
+func (ds *dispatchState) sourceMap(sm *llb.SourceMap) *llb.SourceMap {
+	if ds.include != nil {
+		return ds.include.sourceMap
+	}
+	return sm
+}
+
+// withLocation attaches the location in the Dockerfile the stage is defined
+// in to err.
+func (ds *dispatchState) withLocation(err error, location []parser.Range) error {
+	return ds.include.withLocation(err, location)
 }
 
 type dispatchStates struct {
 	states       []*dispatchState
 	statesByName map[string]*dispatchState
+	// stages maps the index of every stage in the Dockerfile to its state.
+	// Stages skipped by their condition have a nil state.
+	stages  []*dispatchState
+	skipped map[string]struct{}
 }
 
 func newDispatchStates() *dispatchStates {
-	return &dispatchStates{statesByName: map[string]*dispatchState{}}
+	return &dispatchStates{
+		statesByName: map[string]*dispatchState{},
+		skipped:      map[string]struct{}{},
+	}
 }
 
 func (dss *dispatchStates) addState(ds *dispatchState) {
 	dss.states = append(dss.states, ds)
+	if !ds.unregistered {
+		dss.stages = append(dss.stages, ds)
+	}
 
 	if d, ok := dss.statesByName[ds.stage.BaseName]; ok {
 		ds.base = d
 	}
 	if ds.stage.Name != "" {
-		dss.statesByName[strings.ToLower(ds.stage.Name)] = ds
+		name := strings.ToLower(ds.stage.Name)
+		// the stages of the Dockerfile take precedence over the included
+		// stages with the same name
+		if d, ok := dss.statesByName[name]; !ok || d.include != nil || ds.include == nil {
+			dss.statesByName[name] = ds
+		}
+	}
+}
+
+// linkIncludedBases links the stages based on the included stages that come
+// after them.
+func (dss *dispatchStates) linkIncludedBases() error {
+	for _, ds := range dss.states {
+		if ds.noinit || ds.base != nil {
+			continue
+		}
+		d, ok := dss.statesByName[ds.stage.BaseName]
+		if !ok || d.include == nil {
+			continue
+		}
+		ds.base = d
+	}
+	for _, ds := range dss.states {
+		seen := map[*dispatchState]struct{}{}
+		for d := ds; d != nil; d = d.base {
+			if _, ok := seen[d]; ok {
+				return ds.include.withLocation(errors.Errorf("circular dependency between the base stages of %s", ds.stageName), ds.stage.Location)
+			}
+			seen[d] = struct{}{}
+		}
 	}
+	return nil
 }
 
 func (dss *dispatchStates) findStateByName(name string) (*dispatchState, bool) {
@@ -729,16 +1048,54 @@ func (dss *dispatchStates) findStateByName(name string) (*dispatchState, bool) {
 	return ds, ok
 }
 
+func (dss *dispatchStates) skipStage(st instructions.Stage) {
+	dss.stages = append(dss.stages, nil)
+	if st.Name != "" {
+		dss.skipped[strings.ToLower(st.Name)] = struct{}{}
+	}
+}
+
+func (dss *dispatchStates) isSkipped(name string) bool {
+	_, ok := dss.skipped[strings.ToLower(name)]
+	return ok
+}
+
 func (dss *dispatchStates) findStateByIndex(index int) (*dispatchState, error) {
-	if index < 0 || index >= len(dss.states) {
+	if index < 0 || index >= len(dss.stages) {
 		return nil, errors.Errorf("invalid stage index %d", index)
 	}
+	if dss.stages[index] == nil {
+		return nil, errors.Errorf("stage %d is skipped by its condition", index)
+	}
 
-	return dss.states[index], nil
+	return dss.stages[index], nil
 }
 
+// evalStageCondition expands the condition of a stage with the build
+// arguments. The condition is false if it expands to an empty string or to a
+// false boolean value, and can be negated with a "!" prefix.
+func evalStageCondition(shlex *shell.Lex, cond string, args map[string]string) (bool, error) {
+	negate := strings.HasPrefix(cond, "!")
+	v, err := shlex.ProcessWordWithMap(strings.TrimPrefix(cond, "!"), args)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to process stage condition %s", cond)
+	}
+	enabled := v != ""
+	if b, err := strconv.ParseBool(v); err == nil {
+		enabled = b
+	}
+	return enabled != negate, nil
+}
+
+// lastTarget returns the last stage of the main Dockerfile, nil if all its
+// stages are skipped.
 func (dss *dispatchStates) lastTarget() *dispatchState {
-	return dss.states[len(dss.states)-1]
+	for i := len(dss.states) - 1; i >= 0; i-- {
+		if dss.states[i].include == nil {
+			return dss.states[i]
+		}
+	}
+	return nil
 }
 
 type command struct {
@@ -759,7 +1116,9 @@ func dispatchOnBuildTriggers(d *dispatchState, triggers []string, opt dispatchOp
 		if err != nil {
 			return err
 		}
-		cmd, err := toCommand(ic, opt.allDispatchStates)
+		cmd, err := toCommand(ic, opt.allDispatchStates, func(word string) (string, error) {
+			return opt.shlex.ProcessWordWithMap(word, metaArgsToMap(opt.metaArgs))
+		})
 		if err != nil {
 			return err
 		}
@@ -875,6 +1234,37 @@ func dispatchRun(d *dispatchState, c *instructions.RunCommand, proxy *llb.ProxyE
 		opt = append(opt, networkOpt)
 	}
 
+	if instructions.GetTrace(c) {
+		if dopt.llbCaps != nil {
+			if err := dopt.llbCaps.Supports(pb.CapExecMetaTraceContext); err != nil {
+				return errors.Wrap(err, "RUN --trace is not supported")
+			}
+		}
+		opt = append(opt, llb.WithTraceContext())
+	}
+
+	if mode := instructions.GetCacheMode(c); mode != instructions.CacheModeDefinition {
+		if dopt.llbCaps != nil {
+			if err := dopt.llbCaps.Supports(pb.CapExecCacheMode); err != nil {
+				return errors.Wrap(err, "RUN --cache-mode is not supported")
+			}
+		}
+		if mode == instructions.CacheModeHermetic {
+			opt = append(opt, llb.WithCacheMode(llb.CacheModeHermetic))
+		} else {
+			opt = append(opt, llb.WithCacheMode(llb.CacheModeContent))
+		}
+	}
+
+	if runtime := instructions.GetRuntime(c); runtime != "" {
+		if dopt.llbCaps != nil {
+			if err := dopt.llbCaps.Supports(pb.CapExecMetaRuntime); err != nil {
+				return errors.Wrap(err, "RUN --runtime is not supported")
+			}
+		}
+		opt = append(opt, llb.Runtime(runtime))
+	}
+
 	if dopt.llbCaps != nil && dopt.llbCaps.Supports(pb.CapExecMetaUlimit) == nil {
 		for _, u := range dopt.ulimit {
 			opt = append(opt, llb.AddUlimit(llb.UlimitName(u.Name), u.Soft, u.Hard))
@@ -968,6 +1358,17 @@ func dispatchCopyFileOp(d *dispatchState, cfg copyConfig) error {
 		}
 	}
 
+	checksum, err := parseChecksum(cfg)
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.excludes) > 0 && cfg.opt.llbCaps != nil {
+		if err := cfg.opt.llbCaps.Supports(pb.CapFileCopyIncludeExcludePatterns); err != nil {
+			return errors.Wrap(err, "exclude patterns are not supported")
+		}
+	}
+
 	commitMessage := bytes.NewBufferString("")
 	if cfg.isAddCommand {
 		commitMessage.WriteString("ADD")
@@ -979,7 +1380,46 @@ func dispatchCopyFileOp(d *dispatchState, cfg copyConfig) error {
 
 	for _, src := range cfg.params.SourcePaths {
 		commitMessage.WriteString(" " + src)
-		if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		if cfg.unpack.format != "" && !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") {
+			return errors.Errorf("unpack options are only supported for URL sources: %s", src)
+		}
+		if cfg.isAddCommand && isGitSource(src) {
+			if checksum != "" {
+				return errors.Errorf("checksum is not supported for git sources: %s", src)
+			}
+			if cfg.authSecret != "" {
+				return errors.Errorf("auth secret is only supported for URL sources: %s", src)
+			}
+			parts := strings.SplitN(src, "#", 2)
+			var ref string
+			if len(parts) > 1 {
+				ref = parts[1]
+			}
+			ref, err := cfg.opt.sourceLock.git(src, ref)
+			if err != nil {
+				return err
+			}
+			if err := pinnedGit(cfg.opt, src, ref); err != nil {
+				return err
+			}
+			gitOpts := append([]llb.GitOption{dfCmd(cfg.params)}, cfg.git.options()...)
+			st := llb.Git(parts[0], ref, gitOpts...)
+
+			opts := append([]llb.CopyOption{&llb.CopyInfo{
+				Mode:                mode,
+				CopyDirContentsOnly: true,
+				CreateDestPath:      true,
+			}}, copyOpt...)
+
+			if a == nil {
+				a = llb.Copy(st, "/", dest, opts...)
+			} else {
+				a = a.Copy(st, "/", dest, opts...)
+			}
+		} else if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+			if cfg.git.isSet() {
+				return errors.Errorf("git options are only supported for git sources: %s", src)
+			}
 			if !cfg.isAddCommand {
 				return errors.New("source can't be a URL for COPY")
 			}
@@ -997,19 +1437,62 @@ func dispatchCopyFileOp(d *dispatchState, cfg copyConfig) error {
 				}
 			}
 
-			st := llb.HTTP(src, llb.Filename(f), dfCmd(cfg.params))
+			checksum, err := cfg.opt.sourceLock.http(src, checksum)
+			if err != nil {
+				return err
+			}
+			if err := pinnedHTTP(cfg.opt, src, checksum); err != nil {
+				return err
+			}
+			httpOpts := []llb.HTTPOption{llb.Filename(f), dfCmd(cfg.params)}
+			if checksum != "" {
+				httpOpts = append(httpOpts, llb.Checksum(checksum))
+			}
+			if cfg.authSecret != "" {
+				httpOpts = append(httpOpts, llb.HTTPAuthTokenSecret(cfg.authSecret))
+			}
+			httpOpts = append(httpOpts, httpProxyOpts(cfg.opt)...)
+			httpOpts = append(httpOpts, cfg.unpack.options()...)
+			st := llb.HTTP(src, httpOpts...)
 
 			opts := append([]llb.CopyOption{&llb.CopyInfo{
 				Mode:           mode,
 				CreateDestPath: true,
 			}}, copyOpt...)
 
+			srcPath := f
+			if cfg.unpack.format != "" {
+				// the files extracted from the archive are copied to the
+				// destination directory
+				if cfg.opt.llbCaps != nil {
+					if err := cfg.opt.llbCaps.Supports(pb.CapSourceHTTPUnpack); err != nil {
+						return errors.Wrap(err, "unpack is not supported")
+					}
+				}
+				srcPath = "/"
+				opts = append([]llb.CopyOption{&llb.CopyInfo{
+					Mode:                mode,
+					CopyDirContentsOnly: true,
+					CreateDestPath:      true,
+				}}, copyOpt...)
+			}
+
 			if a == nil {
-				a = llb.Copy(st, f, dest, opts...)
+				a = llb.Copy(st, srcPath, dest, opts...)
 			} else {
-				a = a.Copy(st, f, dest, opts...)
+				a = a.Copy(st, srcPath, dest, opts...)
 			}
 		} else {
+			if checksum != "" {
+				return errors.Errorf("checksum can only be verified for URL and here-document sources: %s", src)
+			}
+			if cfg.authSecret != "" {
+				return errors.Errorf("auth secret is only supported for URL sources: %s", src)
+			}
+			if cfg.git.isSet() {
+				return errors.Errorf("git options are only supported for git sources: %s", src)
+			}
+
 			opts := append([]llb.CopyOption{&llb.CopyInfo{
 				Mode:                mode,
 				FollowSymlinks:      true,
@@ -1018,6 +1501,7 @@ func dispatchCopyFileOp(d *dispatchState, cfg copyConfig) error {
 				CreateDestPath:      true,
 				AllowWildcard:       true,
 				AllowEmptyWildcard:  true,
+				ExcludePatterns:     cfg.excludes,
 			}}, copyOpt...)
 
 			if a == nil {
@@ -1033,6 +1517,11 @@ func dispatchCopyFileOp(d *dispatchState, cfg copyConfig) error {
 
 		data := src.Data
 		f := src.Path
+		if checksum != "" {
+			if actual := checksum.Algorithm().FromString(data); actual != checksum {
+				return errors.Errorf("digest mismatch for here-document %s: %s != %s", f, actual, checksum)
+			}
+		}
 		st := llb.Scratch().File(
 			llb.Mkfile(f, 0664, []byte(data)),
 			WithInternalName("preparing inline document"),
@@ -1101,10 +1590,88 @@ type copyConfig struct {
 	chown        string
 	chmod        string
 	link         bool
+	checksum     string
+	excludes     []string
+	git          gitConfig
+	authSecret   string
+	unpack       unpackConfig
 	location     []parser.Range
 	opt          dispatchOpt
 }
 
+// unpackConfig contains the options for the extraction of the archives of
+// URL sources of ADD.
+type unpackConfig struct {
+	format          string
+	stripComponents int
+	subpath         string
+}
+
+func (u unpackConfig) options() []llb.HTTPOption {
+	if u.format == "" {
+		return nil
+	}
+	return []llb.HTTPOption{
+		llb.HTTPUnpack(u.format),
+		llb.HTTPStripComponents(u.stripComponents),
+		llb.HTTPUnpackSubpath(u.subpath),
+	}
+}
+
+// gitConfig contains the options for git sources of ADD.
+type gitConfig struct {
+	keepGitDir     bool
+	skipSubmodules bool
+	lfs            bool
+}
+
+func (g gitConfig) isSet() bool {
+	return g.keepGitDir || g.skipSubmodules || g.lfs
+}
+
+func (g gitConfig) options() []llb.GitOption {
+	var opts []llb.GitOption
+	if g.keepGitDir {
+		opts = append(opts, llb.KeepGitDir())
+	}
+	if g.skipSubmodules {
+		opts = append(opts, llb.SkipSubmodules())
+	}
+	if g.lfs {
+		opts = append(opts, llb.LFS())
+	}
+	return opts
+}
+
+var gitURLPathWithFragmentSuffix = regexp.MustCompile(`^https?://.*\.git(?:#.+)?$`)
+
+// isGitSource returns true if the source of ADD is the URL of a git
+// repository. The ref to check out can be set in the URL fragment.
+func isGitSource(src string) bool {
+	for _, prefix := range []string{"git://", "git@", "ssh://"} {
+		if strings.HasPrefix(src, prefix) {
+			return true
+		}
+	}
+	return gitURLPathWithFragmentSuffix.MatchString(src)
+}
+
+// parseChecksum validates the digest passed with --checksum. As the digest
+// pins the contents of a single file, exactly one source is allowed.
+func parseChecksum(cfg copyConfig) (digest.Digest, error) {
+	if cfg.checksum == "" {
+		return "", nil
+	}
+	dgst, err := digest.Parse(cfg.checksum)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid checksum %q", cfg.checksum)
+	}
+	if n := len(cfg.params.SourcePaths) + len(cfg.params.SourceContents); n != 1 {
+		return "", errors.Errorf("checksum requires exactly one source, got %d", n)
+	}
+	return dgst, nil
+}
+
 func dispatchCopy(d *dispatchState, cfg copyConfig) error {
 	if useFileOp(cfg.opt.buildArgValues, cfg.opt.llbCaps) {
 		return dispatchCopyFileOp(d, cfg)
@@ -1114,6 +1681,29 @@ func dispatchCopy(d *dispatchState, cfg copyConfig) error {
 		return errors.New("inline content copy is not supported")
 	}
 
+	if len(cfg.excludes) > 0 {
+		return errors.New("exclude patterns are not supported")
+	}
+
+	if cfg.git.isSet() {
+		return errors.New("git sources are not supported")
+	}
+	if cfg.unpack.format != "" {
+		return errors.New("unpack is not supported")
+	}
+	if cfg.isAddCommand {
+		for _, src := range cfg.params.SourcePaths {
+			if isGitSource(src) {
+				return errors.New("git sources are not supported")
+			}
+		}
+	}
+
+	checksum, err := parseChecksum(cfg)
+	if err != nil {
+		return err
+	}
+
 	if cfg.chmod != "" {
 		if cfg.opt.llbCaps != nil && cfg.opt.llbCaps.Supports(pb.CapFileBase) != nil {
 			return errors.Wrap(cfg.opt.llbCaps.Supports(pb.CapFileBase), "chmod is not supported")
@@ -1170,10 +1760,31 @@ func dispatchCopy(d *dispatchState, cfg copyConfig) error {
 					f = base
 				}
 			}
+			checksum, err := cfg.opt.sourceLock.http(src, checksum)
+			if err != nil {
+				return err
+			}
+			if err := pinnedHTTP(cfg.opt, src, checksum); err != nil {
+				return err
+			}
+			httpOpts := []llb.HTTPOption{llb.Filename(f), dfCmd(cfg.params)}
+			if checksum != "" {
+				httpOpts = append(httpOpts, llb.Checksum(checksum))
+			}
+			if cfg.authSecret != "" {
+				httpOpts = append(httpOpts, llb.HTTPAuthTokenSecret(cfg.authSecret))
+			}
+			httpOpts = append(httpOpts, httpProxyOpts(cfg.opt)...)
 			target := path.Join(fmt.Sprintf("/src-%d", i), f)
 			args = append(args, target)
-			mounts = append(mounts, llb.AddMount(path.Dir(target), llb.HTTP(src, llb.Filename(f), dfCmd(cfg.params)), llb.Readonly))
+			mounts = append(mounts, llb.AddMount(path.Dir(target), llb.HTTP(src, httpOpts...), llb.Readonly))
 		} else {
+			if checksum != "" {
+				return errors.Errorf("checksum can only be verified for URL and here-document sources: %s", src)
+			}
+			if cfg.authSecret != "" {
+				return errors.Errorf("auth secret is only supported for URL sources: %s", src)
+			}
 			d, f := splitWildcards(src)
 			targetCmd := fmt.Sprintf("/src-%d", i)
 			targetMount := targetCmd
@@ -1499,6 +2110,44 @@ func isReachable(from, to *dispatchState) (ret bool) {
 	return false
 }
 
+// dispatchOrder returns the states sorted so that every stage comes after its
+// base and the stages it depends on, in the order of the Dockerfile
+// otherwise. The included stages come after the stages of the Dockerfile and
+// are moved before the stages using them.
+func dispatchOrder(states []*dispatchState) []*dispatchState {
+	index := make(map[*dispatchState]int, len(states))
+	for i, d := range states {
+		index[d] = i
+	}
+	out := make([]*dispatchState, 0, len(states))
+	visited := make(map[*dispatchState]struct{}, len(states))
+	var visit func(d *dispatchState)
+	visit = func(d *dispatchState) {
+		if _, ok := visited[d]; ok {
+			return
+		}
+		visited[d] = struct{}{}
+		if d.base != nil {
+			visit(d.base)
+		}
+		deps := make([]*dispatchState, 0, len(d.deps))
+		for dep := range d.deps {
+			deps = append(deps, dep)
+		}
+		sort.Slice(deps, func(i, j int) bool {
+			return index[deps[i]] < index[deps[j]]
+		})
+		for _, dep := range deps {
+			visit(dep)
+		}
+		out = append(out, d)
+	}
+	for _, d := range states {
+		visit(d)
+	}
+	return out
+}
+
 func hasCircularDependency(states []*dispatchState) (bool, *dispatchState) {
 	var visit func(state *dispatchState) bool
 	if states == nil {
@@ -1585,6 +2234,18 @@ func normalizeContextPaths(paths map[string]struct{}) []string {
 	return pathSlice
 }
 
+// httpProxyOpts returns the options passing the proxy build args to the
+// sources of remote URLs.
+func httpProxyOpts(dopt dispatchOpt) []llb.HTTPOption {
+	if dopt.proxyEnv == nil {
+		return nil
+	}
+	if dopt.llbCaps != nil && dopt.llbCaps.Supports(pb.CapSourceHTTPProxy) != nil {
+		return nil
+	}
+	return []llb.HTTPOption{llb.HTTPProxy(*dopt.proxyEnv)}
+}
+
 func proxyEnvFromBuildArgs(args map[string]string) *llb.ProxyEnv {
 	pe := &llb.ProxyEnv{}
 	isNil := true
@@ -1620,6 +2281,32 @@ type mutableOutput struct {
 	llb.Output
 }
 
+// stageContext is the build context of a stage with its own ignore file.
+type stageContext struct {
+	output   *mutableOutput
+	excludes []string
+	ctxPaths map[string]struct{}
+}
+
+func localBuildContext(opt ConvertOpt, stage string, excludes []string, ctxPaths map[string]struct{}) llb.State {
+	sharedKeyHint := opt.ContextLocalName
+	name := "load build context"
+	if stage != "" {
+		sharedKeyHint += "-" + stage
+		name += " for " + stage
+	}
+	opts := []llb.LocalOption{
+		llb.SessionID(opt.SessionID),
+		llb.ExcludePatterns(excludes),
+		llb.SharedKeyHint(sharedKeyHint),
+		WithInternalName(name),
+	}
+	if includePatterns := normalizeContextPaths(ctxPaths); includePatterns != nil {
+		opts = append(opts, llb.FollowPaths(includePatterns))
+	}
+	return llb.Local(opt.ContextLocalName, opts...)
+}
+
 func withShell(img Image, args []string) []string {
 	var shell []string
 	if len(img.Config.Shell) > 0 {
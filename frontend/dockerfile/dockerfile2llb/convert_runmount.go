@@ -15,7 +15,7 @@ import (
 	"github.com/pkg/errors"
 )
 
-func detectRunMount(cmd *command, allDispatchStates *dispatchStates) bool {
+func detectRunMount(cmd *command, allDispatchStates *dispatchStates, expand instructions.SingleWordExpander) (bool, error) {
 	if c, ok := cmd.Command.(*instructions.RunCommand); ok {
 		mounts := instructions.GetMounts(c)
 		sources := make([]*dispatchState, len(mounts))
@@ -27,7 +27,16 @@ func detectRunMount(cmd *command, allDispatchStates *dispatchStates) bool {
 				// mount.Type because it might be a variable)
 				from = emptyImageName
 			} else {
-				from = mount.From
+				// the sources need to be known before the stages are
+				// dispatched so only the global build args are expanded
+				v, err := expand(mount.From)
+				if err != nil {
+					return false, errors.Wrapf(err, "failed to expand mount from %s", mount.From)
+				}
+				if v == "" {
+					return false, errors.Errorf("mount from %s expands to an empty value", mount.From)
+				}
+				from = v
 			}
 			stn, ok := allDispatchStates.findStateByName(from)
 			if !ok {
@@ -40,10 +49,10 @@ func detectRunMount(cmd *command, allDispatchStates *dispatchStates) bool {
 			sources[i] = stn
 		}
 		cmd.sources = sources
-		return true
+		return true, nil
 	}
 
-	return false
+	return false, nil
 }
 
 func setCacheUIDGIDFileOp(m *instructions.Mount, st llb.State) llb.State {
@@ -62,6 +71,18 @@ func setCacheUIDGIDFileOp(m *instructions.Mount, st llb.State) llb.State {
 	return st.File(llb.Mkdir("/cache", mode, llb.WithUIDGID(uid, gid)), llb.WithCustomName("[internal] settings cache mount permissions"))
 }
 
+func cacheOwner(m *instructions.Mount) llb.MountOption {
+	uid := 0
+	gid := 0
+	if m.UID != nil {
+		uid = int(*m.UID)
+	}
+	if m.GID != nil {
+		gid = int(*m.GID)
+	}
+	return llb.CacheOwner(uid, gid)
+}
+
 func setCacheUIDGID(m *instructions.Mount, st llb.State, fileop bool) llb.State {
 	if fileop {
 		return setCacheUIDGIDFileOp(m, st)
@@ -85,11 +106,15 @@ func dispatchRunMounts(d *dispatchState, c *instructions.RunCommand, sources []*
 	mounts := instructions.GetMounts(c)
 
 	for i, mount := range mounts {
-		if mount.From == "" && mount.Type == instructions.MountTypeCache {
+		scratchCache := mount.From == "" && mount.Type == instructions.MountTypeCache
+		if scratchCache {
 			mount.From = emptyImageName
 		}
 		st := opt.buildContext
 		if mount.From != "" {
+			if mount.Type == instructions.MountTypeImage && !sources[i].unregistered {
+				return nil, errors.Errorf("image mount from %q refers to a build stage, use a bind mount instead", mount.From)
+			}
 			st = sources[i].state
 		}
 		var mountOpts []llb.MountOption
@@ -104,15 +129,23 @@ func dispatchRunMounts(d *dispatchState, c *instructions.RunCommand, sources []*
 			if err != nil {
 				return nil, err
 			}
-			out = append(out, secret)
+			out = append(out, secret...)
 			continue
 		}
 		if mount.Type == instructions.MountTypeSSH {
-			ssh, err := dispatchSSH(mount)
+			ssh, err := dispatchSSH(mount, useFileOp(opt.buildArgValues, opt.llbCaps))
 			if err != nil {
 				return nil, err
 			}
-			out = append(out, ssh)
+			out = append(out, ssh...)
+			continue
+		}
+		if mount.Type == instructions.MountTypeSocket {
+			out = append(out, dispatchSocket(mount))
+			continue
+		}
+		if mount.Type == instructions.MountTypeBuildKit {
+			out = append(out, dispatchBuildKitSocket(mount))
 			continue
 		}
 		if mount.ReadOnly {
@@ -147,7 +180,10 @@ func dispatchRunMounts(d *dispatchState, c *instructions.RunCommand, sources []*
 		if src := path.Join("/", mount.Source); src != "/" {
 			mountOpts = append(mountOpts, llb.SourcePath(src))
 		} else {
-			if mount.UID != nil || mount.GID != nil || mount.Mode != nil {
+			if scratchCache && mount.Mode == nil && (mount.UID != nil || mount.GID != nil) && opt.llbCaps != nil && opt.llbCaps.Supports(pb.CapExecMountCacheOwner) == nil {
+				// the cache is shared by the owners without chowning it
+				mountOpts = append(mountOpts, cacheOwner(mount))
+			} else if mount.UID != nil || mount.GID != nil || mount.Mode != nil {
 				st = setCacheUIDGID(mount, st, useFileOp(opt.buildArgValues, opt.llbCaps))
 				mountOpts = append(mountOpts, llb.SourcePath("/cache"))
 			}
@@ -157,6 +193,8 @@ func dispatchRunMounts(d *dispatchState, c *instructions.RunCommand, sources []*
 
 		if mount.From == "" {
 			d.ctxPaths[path.Join("/", filepath.ToSlash(mount.Source))] = struct{}{}
+		} else if name := sources[i].contextName; name != "" {
+			opt.contextPaths.add(name, mount.Source)
 		}
 	}
 	return out, nil
@@ -19,6 +19,9 @@ func dispatchRunNetwork(c *instructions.RunCommand) (llb.RunOption, error) {
 	case instructions.NetworkHost:
 		return llb.Network(pb.NetMode_HOST), nil
 	default:
+		if instructions.IsNamedNetwork(network) {
+			return llb.NetworkName(network), nil
+		}
 		return nil, errors.Errorf("unsupported network mode %q", network)
 	}
 }
@@ -8,7 +8,7 @@ import (
 	"github.com/pkg/errors"
 )
 
-func dispatchSecret(m *instructions.Mount) (llb.RunOption, error) {
+func dispatchSecret(m *instructions.Mount) ([]llb.RunOption, error) {
 	id := m.CacheID
 	if m.Source != "" {
 		id = m.Source
@@ -21,8 +21,10 @@ func dispatchSecret(m *instructions.Mount) (llb.RunOption, error) {
 		id = path.Base(m.Target)
 	}
 
+	// secrets exposed as environment variables are only mounted as files
+	// if the target is set
 	target := m.Target
-	if target == "" {
+	if target == "" && m.Env == nil {
 		target = "/run/secrets/" + path.Base(id)
 	}
 
@@ -48,5 +50,12 @@ func dispatchSecret(m *instructions.Mount) (llb.RunOption, error) {
 		opts = append(opts, llb.SecretFileOpt(uid, gid, mode))
 	}
 
-	return llb.AddSecret(target, opts...), nil
+	var out []llb.RunOption
+	if m.Env != nil {
+		out = append(out, llb.AddSecret(*m.Env, append([]llb.SecretOption{llb.SecretAsEnv(true)}, opts...)...))
+	}
+	if target != "" {
+		out = append(out, llb.AddSecret(target, opts...))
+	}
+	return out, nil
 }
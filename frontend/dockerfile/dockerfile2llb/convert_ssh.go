@@ -1,12 +1,18 @@
 package dockerfile2llb
 
 import (
+	"strings"
+
 	"github.com/moby/buildkit/client/llb"
 	"github.com/moby/buildkit/frontend/dockerfile/instructions"
 	"github.com/pkg/errors"
 )
 
-func dispatchSSH(m *instructions.Mount) (llb.RunOption, error) {
+// knownHostsPath is the global known hosts file of OpenSSH the known hosts
+// of ssh mounts are mounted to.
+const knownHostsPath = "/etc/ssh/ssh_known_hosts"
+
+func dispatchSSH(m *instructions.Mount, fileop bool) ([]llb.RunOption, error) {
 	if m.Source != "" {
 		return nil, errors.Errorf("ssh does not support source")
 	}
@@ -20,21 +26,65 @@ func dispatchSSH(m *instructions.Mount) (llb.RunOption, error) {
 		opts = append(opts, llb.SSHOptional)
 	}
 
-	if m.UID != nil || m.GID != nil || m.Mode != nil {
-		var uid, gid, mode int
-		if m.UID != nil {
-			uid = int(*m.UID)
-		}
-		if m.GID != nil {
-			gid = int(*m.GID)
-		}
-		if m.Mode != nil {
-			mode = int(*m.Mode)
-		} else {
-			mode = 0600
+	if opt := socketOpt(m); opt != nil {
+		opts = append(opts, opt)
+	}
+
+	out := []llb.RunOption{llb.AddSSHSocket(opts...)}
+
+	if m.KnownHosts != "" {
+		if !fileop {
+			return nil, errors.Errorf("knownhosts is not supported without file operations")
 		}
-		opts = append(opts, llb.SSHSocketOpt(m.Target, uid, gid, mode))
+		dt := []byte(strings.TrimSpace(m.KnownHosts) + "\n")
+		st := llb.Scratch().File(llb.Mkfile("/known_hosts", 0644, dt), WithInternalName("preparing ssh known hosts"))
+		out = append(out, llb.AddMount(knownHostsPath, st, llb.SourcePath("/known_hosts"), llb.Readonly))
 	}
 
-	return llb.AddSSHSocket(opts...), nil
+	return out, nil
+}
+
+// dispatchSocket mounts the client socket with the id of the mount at its
+// target.
+func dispatchSocket(m *instructions.Mount) llb.RunOption {
+	var opts []llb.SSHOption
+	if !m.Required {
+		opts = append(opts, llb.SSHOptional)
+	}
+	if opt := socketOpt(m); opt != nil {
+		opts = append(opts, opt)
+	}
+	return llb.AddSocket(m.CacheID, m.Target, opts...)
+}
+
+// dispatchBuildKitSocket serves the BuildKit API of the daemon to the RUN
+// step, so that it can run nested builds.
+func dispatchBuildKitSocket(m *instructions.Mount) llb.RunOption {
+	var opts []llb.SSHOption
+	if !m.Required {
+		opts = append(opts, llb.SSHOptional)
+	}
+	if opt := socketOpt(m); opt != nil {
+		opts = append(opts, opt)
+	}
+	return llb.AddNestedBuildSocket(m.Target, opts...)
+}
+
+func socketOpt(m *instructions.Mount) llb.SSHOption {
+	if m.UID == nil && m.GID == nil && m.Mode == nil {
+		return nil
+	}
+	var uid, gid, mode int
+	if m.UID != nil {
+		uid = int(*m.UID)
+	}
+	if m.GID != nil {
+		gid = int(*m.GID)
+	}
+	if m.Mode != nil {
+		mode = int(*m.Mode)
+	} else {
+		mode = 0600
+	}
+	return llb.SSHSocketOpt(m.Target, uid, gid, mode)
 }
@@ -1,14 +1,24 @@
 package dockerfile2llb
 
 import (
+	"context"
+	"encoding/json"
 	"strings"
+	"sync"
 	"testing"
 
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
 	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
 	"github.com/moby/buildkit/frontend/dockerfile/shell"
+	"github.com/moby/buildkit/solver/errdefs"
+	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/appcontext"
 	binfotypes "github.com/moby/buildkit/util/buildinfo/types"
+	digest "github.com/opencontainers/go-digest"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -219,3 +229,752 @@ ADD https://raw.githubusercontent.com/moby/buildkit/master/README.md /
 	assert.True(t, strings.HasPrefix(bi.Sources[0].Alias, "docker.io/library/busybox@"))
 	assert.NotEmpty(t, bi.Sources[0].Pin)
 }
+
+func TestDockerfileChecksum(t *testing.T) {
+	t.Parallel()
+	caps := pb.Caps.CapSet(pb.Caps.All())
+
+	df := `FROM scratch
+ADD --checksum=sha256:24454f830cdb571e2c4ad15481119c43b3cafd48dd869a9b2945d1036d1dc68d http://github.com/moby/buildkit/blob/master/README.md /
+`
+	_, _, _, err := Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{LLBCaps: &caps})
+	assert.NoError(t, err)
+
+	df = `FROM scratch
+COPY --checksum=sha256:5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03 <<EOF /foo
+hello
+EOF
+`
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{LLBCaps: &caps})
+	assert.NoError(t, err)
+
+	df = `FROM scratch
+COPY --checksum=sha256:24454f830cdb571e2c4ad15481119c43b3cafd48dd869a9b2945d1036d1dc68d <<EOF /foo
+hello
+EOF
+`
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{LLBCaps: &caps})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "digest mismatch")
+
+	df = `FROM scratch
+COPY --checksum=sha256:24454f830cdb571e2c4ad15481119c43b3cafd48dd869a9b2945d1036d1dc68d foo /
+`
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{LLBCaps: &caps})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum can only be verified")
+
+	df = `FROM scratch
+ADD --checksum=sha256:24454f830cdb571e2c4ad15481119c43b3cafd48dd869a9b2945d1036d1dc68d http://example.com/a http://example.com/b /
+`
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{LLBCaps: &caps})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exactly one source")
+
+	df = `FROM scratch
+ADD --checksum=invalid http://github.com/moby/buildkit/blob/master/README.md /
+`
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{LLBCaps: &caps})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid checksum")
+}
+
+func TestDockerfileStageCondition(t *testing.T) {
+	t.Parallel()
+	df := `ARG DEBUG
+FROM scratch AS tools IF $DEBUG
+ENV TOOLS=debug
+FROM scratch AS tools IF !$DEBUG
+ENV TOOLS=release
+FROM tools
+COPY --from=tools f1 /
+`
+	_, img, _, err := Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{})
+	require.NoError(t, err)
+	require.Contains(t, img.Config.Env, "TOOLS=release")
+
+	_, img, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		BuildArgs: map[string]string{"DEBUG": "1"},
+	})
+	require.NoError(t, err)
+	require.Contains(t, img.Config.Env, "TOOLS=debug")
+
+	df = `ARG DEBUG=false
+FROM scratch AS debug IF $DEBUG
+FROM scratch
+COPY --from=debug f1 /
+`
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "stage debug is skipped")
+
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		Target: "debug",
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "target stage debug: stage is skipped")
+
+	df = `FROM scratch IF $DEBUG
+FROM scratch
+COPY --from=0 f1 /
+`
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "stage 0 is skipped")
+}
+
+func TestDockerfileCopyExclude(t *testing.T) {
+	t.Parallel()
+	caps := pb.Caps.CapSet(pb.Caps.All())
+
+	df := `FROM scratch
+ARG EXCLUDE=testdata
+COPY --exclude=node_modules --exclude=$EXCLUDE . /app
+`
+	st, _, _, err := Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{LLBCaps: &caps})
+	require.NoError(t, err)
+
+	def, err := st.Marshal(appcontext.Context())
+	require.NoError(t, err)
+
+	var excludes []string
+	for _, dt := range def.Def {
+		var op pb.Op
+		require.NoError(t, op.Unmarshal(dt))
+		if f := op.GetFile(); f != nil {
+			for _, a := range f.Actions {
+				if cp := a.GetCopy(); cp != nil {
+					excludes = append(excludes, cp.ExcludePatterns...)
+				}
+			}
+		}
+	}
+	require.Equal(t, []string{"node_modules", "testdata"}, excludes)
+
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "exclude patterns are not supported")
+}
+
+func TestDockerfileStageExcludes(t *testing.T) {
+	t.Parallel()
+	df := `FROM scratch AS docs
+COPY docs /docs
+FROM scratch
+COPY --from=docs /docs /docs
+COPY src /src
+`
+	st, _, _, err := Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		Excludes: []string{"*.md"},
+		StageExcludes: map[string][]string{
+			"docs": {"*.go"},
+		},
+	})
+	require.NoError(t, err)
+
+	def, err := st.Marshal(appcontext.Context())
+	require.NoError(t, err)
+
+	excludes := map[string]string{}
+	for _, dt := range def.Def {
+		var op pb.Op
+		require.NoError(t, op.Unmarshal(dt))
+		if src := op.GetSource(); src != nil && strings.HasPrefix(src.Identifier, "local://") {
+			excludes[src.Attrs[pb.AttrFollowPaths]] = src.Attrs[pb.AttrExcludePatterns]
+		}
+	}
+	require.Equal(t, map[string]string{
+		`["docs"]`: `["*.go"]`,
+		`["src"]`:  `["*.md"]`,
+	}, excludes)
+}
+
+func TestDockerfileContextPaths(t *testing.T) {
+	t.Parallel()
+	df := `FROM scratch AS out
+COPY --from=assets /img /img
+RUN --mount=from=assets,source=fonts,target=/fonts ls
+FROM tools
+COPY --from=out / /
+`
+	cp := NewContextPaths()
+	st, _, _, err := Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		ContextPaths: cp,
+		ContextByName: func(ctx context.Context, name string) (*llb.State, *Image, error) {
+			localName := strings.TrimSuffix(strings.TrimPrefix(name, "docker.io/library/"), ":latest")
+			if localName != "assets" && localName != "tools" {
+				return nil, nil, nil
+			}
+			st := llb.Scratch().Async(func(context.Context, llb.State, *llb.Constraints) (llb.State, error) {
+				return llb.Local(localName, llb.FollowPaths(cp.FollowPaths(name))), nil
+			})
+			return &st, nil, nil
+		},
+	})
+	require.NoError(t, err)
+
+	def, err := st.Marshal(appcontext.Context())
+	require.NoError(t, err)
+
+	followPaths := map[string]string{}
+	for _, dt := range def.Def {
+		var op pb.Op
+		require.NoError(t, op.Unmarshal(dt))
+		if src := op.GetSource(); src != nil && strings.HasPrefix(src.Identifier, "local://") {
+			followPaths[src.Identifier] = src.Attrs[pb.AttrFollowPaths]
+		}
+	}
+	require.Equal(t, map[string]string{
+		"local://assets": `["fonts","img"]`,
+		"local://tools":  "",
+	}, followPaths)
+}
+
+func TestDockerfileMountFromArg(t *testing.T) {
+	t.Parallel()
+	df := `ARG SRC
+FROM busybox AS build
+ENV FOO=bar
+FROM scratch
+RUN --mount=from=$SRC,target=/src ls
+`
+	st, _, _, err := Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		BuildArgs:    map[string]string{"SRC": "build"},
+		MetaResolver: testMetaResolver{},
+	})
+	require.NoError(t, err)
+
+	def, err := st.Marshal(appcontext.Context())
+	require.NoError(t, err)
+	ops := map[digest.Digest]*pb.Op{}
+	var exec *pb.Op
+	for _, dt := range def.Def {
+		var op pb.Op
+		require.NoError(t, op.Unmarshal(dt))
+		ops[digest.FromBytes(dt)] = &op
+		if op.GetExec() != nil {
+			exec = &op
+		}
+	}
+	require.NotNil(t, exec)
+	var src string
+	for _, m := range exec.GetExec().Mounts {
+		if m.Dest == "/src" {
+			src = ops[exec.Inputs[m.Input].Digest].GetSource().Identifier
+		}
+	}
+	// the global build arg isn't redeclared in the stage
+	require.True(t, strings.HasPrefix(src, "docker-image://docker.io/library/busybox"), src)
+
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "mount from $SRC expands to an empty value")
+}
+
+func TestDockerfileAddGit(t *testing.T) {
+	t.Parallel()
+	caps := pb.Caps.CapSet(pb.Caps.All())
+
+	df := `FROM scratch
+ADD --keep-git-dir --submodules=false --lfs https://github.com/moby/buildkit.git#v0.10.0 /buildkit
+`
+	st, _, _, err := Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{LLBCaps: &caps})
+	require.NoError(t, err)
+
+	def, err := st.Marshal(appcontext.Context())
+	require.NoError(t, err)
+
+	var src *pb.SourceOp
+	for _, dt := range def.Def {
+		var op pb.Op
+		require.NoError(t, op.Unmarshal(dt))
+		if s := op.GetSource(); s != nil && strings.HasPrefix(s.Identifier, "git://") {
+			src = s
+		}
+	}
+	require.NotNil(t, src)
+	require.Equal(t, "git://github.com/moby/buildkit.git#v0.10.0", src.Identifier)
+	require.Equal(t, "true", src.Attrs[pb.AttrKeepGitDir])
+	require.Equal(t, "true", src.Attrs[pb.AttrGitSkipSubmodules])
+	require.Equal(t, "true", src.Attrs[pb.AttrGitLFS])
+
+	df = `FROM scratch
+ADD --lfs foo /foo
+`
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{LLBCaps: &caps})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "git options are only supported for git sources")
+}
+
+func TestDockerfileCheck(t *testing.T) {
+	t.Parallel()
+	df := `ARG UNUSED
+FROM scratch AS Build
+ENV FOO=bar
+`
+	var warnings []string
+	_, _, _, err := Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		Warn: func(short, url string, detail [][]byte, location *parser.Range) {
+			warnings = append(warnings, short)
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"UnusedMetaArg: global build argument UNUSED is not used in any FROM or RUN --mount from and not redeclared in any stage",
+		"StageNameCasing: stage name Build should be lowercase",
+	}, warnings)
+
+	// all the problems are reported
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		Check: CheckError,
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "UnusedMetaArg")
+	require.Contains(t, err.Error(), "StageNameCasing")
+
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		CheckEnable: []string{"Unknown"},
+	})
+	require.Error(t, err)
+
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		Check: CheckSkip,
+	})
+	require.NoError(t, err)
+}
+
+func TestDockerfileOutputStages(t *testing.T) {
+	t.Parallel()
+	df := `FROM scratch AS output:bin
+ENV FOO=bar
+
+FROM scratch AS output:docs IF $WITH_DOCS
+ENV FOO=docs
+
+FROM scratch
+COPY --from=bin / /
+`
+	outputs, err := OutputStages(appcontext.Context(), []byte(df), ConvertOpt{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"bin", "docs"}, outputs)
+
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		Target: "bin",
+	})
+	require.NoError(t, err)
+
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		Target: "docs",
+	})
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrStageSkipped))
+}
+
+func TestDockerfileInclude(t *testing.T) {
+	t.Parallel()
+	files := map[string]string{
+		"base.Dockerfile": `ARG BASE_ENV=base
+FROM scratch AS base
+ARG BASE_ENV
+ENV FOO=${BASE_ENV}
+`,
+		"invalid.Dockerfile": `FROM scratch AS invalid
+ADD --checksum=foo https://example.com/foo /foo
+`,
+		"a.Dockerfile": `INCLUDE b.Dockerfile
+FROM scratch AS a
+`,
+		"b.Dockerfile": `INCLUDE a.Dockerfile
+FROM scratch AS b
+`,
+	}
+	caps := pb.Caps.CapSet(pb.Caps.All())
+	opt := ConvertOpt{
+		LLBCaps: &caps,
+		ReadFile: func(ctx context.Context, st llb.State, filename string) ([]byte, error) {
+			dt, ok := files[filename]
+			if !ok {
+				return nil, errors.Errorf("%s not found", filename)
+			}
+			return []byte(dt), nil
+		},
+	}
+
+	_, img, _, err := Dockerfile2LLB(appcontext.Context(), []byte(`INCLUDE base.Dockerfile
+FROM base
+ENV BAR=bar
+`), opt)
+	require.NoError(t, err)
+	require.Contains(t, img.Config.Env, "FOO=base")
+	require.Contains(t, img.Config.Env, "BAR=bar")
+
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(`FROM scratch
+INCLUDE base.Dockerfile
+`), opt)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "INCLUDE is only allowed before the first FROM")
+
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(`INCLUDE a.Dockerfile
+FROM scratch
+`), opt)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "circular include: a.Dockerfile -> b.Dockerfile -> a.Dockerfile")
+
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(`INCLUDE invalid.Dockerfile
+FROM invalid
+`), opt)
+	require.Error(t, err)
+	srcs := errdefs.Sources(err)
+	require.Equal(t, 1, len(srcs))
+	require.Equal(t, "invalid.Dockerfile", srcs[0].Info.Filename)
+	require.Equal(t, int32(2), srcs[0].Ranges[0].Start.Line)
+
+	// included stages don't change the indexes and the default target of
+	// the Dockerfile
+	df := `INCLUDE base.Dockerfile
+FROM scratch AS first
+ENV FIRST=first
+
+FROM base
+COPY --from=0 / /
+ENV BAR=bar
+`
+	_, img, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), opt)
+	require.NoError(t, err)
+	require.Contains(t, img.Config.Env, "FOO=base")
+	require.Contains(t, img.Config.Env, "BAR=bar")
+
+	l, err := ListTargets(appcontext.Context(), []byte(df), opt)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(l.Args))
+	require.Equal(t, "BASE_ENV", l.Args[0].Name)
+	require.Equal(t, 3, len(l.Targets))
+	require.Equal(t, "first", l.Targets[0].Name)
+	require.Equal(t, "", l.Targets[1].Name)
+	require.True(t, l.Targets[1].Default)
+	require.Equal(t, "base", l.Targets[2].Name)
+	require.False(t, l.Targets[2].Default)
+
+	files["outputs.Dockerfile"] = `FROM scratch AS output:bin
+`
+	outputs, err := OutputStages(appcontext.Context(), []byte(`INCLUDE outputs.Dockerfile
+FROM scratch AS output:docs
+`), opt)
+	require.NoError(t, err)
+	require.Equal(t, []string{"docs", "bin"}, outputs)
+
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(`INCLUDE base.Dockerfile
+FROM base
+`), ConvertOpt{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "INCLUDE is not supported")
+}
+
+func TestListTargets(t *testing.T) {
+	t.Parallel()
+	df := `# VERSION the version of the tools
+ARG VERSION=1.0
+
+# build compiles the binary
+FROM golang:1.17 AS build
+# GOOS target operating system
+ARG GOOS
+RUN go build .
+
+FROM scratch
+# TAG image tag
+ARG TAG=latest
+COPY --from=build /app /app
+`
+	l, err := ListTargets(appcontext.Context(), []byte(df), ConvertOpt{})
+	require.NoError(t, err)
+
+	require.Equal(t, 1, len(l.Args))
+	require.Equal(t, "VERSION", l.Args[0].Name)
+	require.Equal(t, "1.0", *l.Args[0].Value)
+	require.Equal(t, "the version of the tools", l.Args[0].Description)
+
+	require.Equal(t, 2, len(l.Targets))
+	require.Equal(t, "build", l.Targets[0].Name)
+	require.Equal(t, "compiles the binary", l.Targets[0].Description)
+	require.Equal(t, "golang:1.17", l.Targets[0].Base)
+	require.False(t, l.Targets[0].Default)
+	require.Equal(t, 1, len(l.Targets[0].Args))
+	require.Equal(t, "GOOS", l.Targets[0].Args[0].Name)
+	require.Nil(t, l.Targets[0].Args[0].Value)
+	require.Equal(t, "target operating system", l.Targets[0].Args[0].Description)
+
+	require.Equal(t, "", l.Targets[1].Name)
+	require.True(t, l.Targets[1].Default)
+	require.Equal(t, "TAG", l.Targets[1].Args[0].Name)
+
+	res, err := l.ToResult()
+	require.NoError(t, err)
+	require.Contains(t, string(res.Metadata["result.txt"]), "build")
+	require.Contains(t, string(res.Metadata["result.txt"]), "(default)")
+}
+
+func TestDockerfileSSHKnownHosts(t *testing.T) {
+	t.Parallel()
+	caps := pb.Caps.CapSet(pb.Caps.All())
+
+	df := `FROM scratch
+ARG KNOWN_HOSTS
+RUN --mount=type=ssh,knownhosts=$KNOWN_HOSTS git clone git@github.com:moby/buildkit.git
+`
+	knownHosts := "github.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl\ngitlab.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAfuCHKVTjquxvt6CM6tdG4SLp1Btn/nOeHHE5UOzRdf"
+	st, _, _, err := Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		LLBCaps:   &caps,
+		BuildArgs: map[string]string{"KNOWN_HOSTS": knownHosts},
+	})
+	require.NoError(t, err)
+
+	def, err := st.Marshal(appcontext.Context())
+	require.NoError(t, err)
+
+	var mount *pb.Mount
+	var data []byte
+	for _, dt := range def.Def {
+		var op pb.Op
+		require.NoError(t, op.Unmarshal(dt))
+		if e := op.GetExec(); e != nil {
+			for _, m := range e.Mounts {
+				if m.Dest == knownHostsPath {
+					mount = m
+				}
+			}
+		}
+		if f := op.GetFile(); f != nil {
+			for _, a := range f.Actions {
+				if mkfile := a.GetMkfile(); mkfile != nil && mkfile.Path == "/known_hosts" {
+					data = mkfile.Data
+				}
+			}
+		}
+	}
+	require.NotNil(t, mount)
+	require.True(t, mount.Readonly)
+	require.Equal(t, "/known_hosts", mount.Selector)
+	require.Equal(t, knownHosts+"\n", string(data))
+
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(`FROM scratch
+RUN --mount=type=secret,knownhosts=foo cat /run/secrets/foo
+`), ConvertOpt{LLBCaps: &caps})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unexpected key 'knownhosts' for mount type 'secret'")
+}
+
+func TestDockerfileSecretEnv(t *testing.T) {
+	t.Parallel()
+	caps := pb.Caps.CapSet(pb.Caps.All())
+
+	df := `FROM scratch
+RUN --mount=type=secret,id=token,env=API_TOKEN,required ./deploy
+RUN --mount=type=secret,id=netrc,env=NETRC,target=/root/.netrc ./fetch
+`
+	st, _, _, err := Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{LLBCaps: &caps})
+	require.NoError(t, err)
+
+	def, err := st.Marshal(appcontext.Context())
+	require.NoError(t, err)
+
+	execs := map[string]*pb.ExecOp{}
+	for _, dt := range def.Def {
+		var op pb.Op
+		require.NoError(t, op.Unmarshal(dt))
+		if e := op.GetExec(); e != nil {
+			execs[e.Meta.Args[len(e.Meta.Args)-1]] = e
+		}
+	}
+
+	e := execs["./deploy"]
+	require.NotNil(t, e)
+	require.Equal(t, []*pb.SecretEnv{{ID: "token", Name: "API_TOKEN"}}, e.Secretenv)
+	for _, m := range e.Mounts {
+		require.NotEqual(t, pb.MountType_SECRET, m.MountType)
+	}
+
+	e = execs["./fetch"]
+	require.NotNil(t, e)
+	require.Equal(t, []*pb.SecretEnv{{ID: "netrc", Name: "NETRC", Optional: true}}, e.Secretenv)
+	var secretMounts []string
+	for _, m := range e.Mounts {
+		if m.MountType == pb.MountType_SECRET {
+			secretMounts = append(secretMounts, m.Dest)
+		}
+	}
+	require.Equal(t, []string{"/root/.netrc"}, secretMounts)
+
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(`FROM scratch
+RUN --mount=type=secret,id=token,env=1TOKEN ./deploy
+`), ConvertOpt{LLBCaps: &caps})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid environment variable name")
+}
+
+type testMetaResolver struct{}
+
+func (testMetaResolver) ResolveImageConfig(ctx context.Context, ref string, opt llb.ResolveImageConfigOpt) (digest.Digest, []byte, error) {
+	var img Image
+	if !strings.Contains(ref, "empty") {
+		img.RootFS.DiffIDs = []digest.Digest{digest.FromString(ref)}
+	}
+	dt, err := json.Marshal(img)
+	if err != nil {
+		return "", nil, err
+	}
+	return digest.FromBytes(dt), dt, nil
+}
+
+func TestDockerfilePrefetch(t *testing.T) {
+	t.Parallel()
+
+	df := `FROM busybox AS base
+FROM example.com/empty AS empty
+FROM scratch
+COPY --from=base /etc/passwd /
+COPY --from=empty / /
+`
+	var (
+		mu      sync.Mutex
+		fetched []string
+	)
+	_, _, _, err := Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		MetaResolver:   testMetaResolver{},
+		TargetPlatform: &ocispecs.Platform{OS: "linux", Architecture: "arm64"},
+		Prefetch: func(ctx context.Context, ref string, p ocispecs.Platform) {
+			mu.Lock()
+			defer mu.Unlock()
+			require.Equal(t, "arm64", p.Architecture)
+			fetched = append(fetched, ref)
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, fetched, 1)
+	require.True(t, strings.HasPrefix(fetched[0], "docker.io/library/busybox@sha256:"), fetched[0])
+}
+
+func TestDockerfileAddHTTPAuth(t *testing.T) {
+	t.Parallel()
+	caps := pb.Caps.CapSet(pb.Caps.All())
+
+	df := `FROM scratch
+ADD --auth-secret=artifacts https://example.com/foo.tar.gz /
+`
+	st, _, _, err := Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		LLBCaps:   &caps,
+		BuildArgs: map[string]string{"HTTPS_PROXY": "http://proxy.example.com", "NO_PROXY": "internal"},
+	})
+	require.NoError(t, err)
+
+	def, err := st.Marshal(appcontext.Context())
+	require.NoError(t, err)
+
+	var src *pb.SourceOp
+	for _, dt := range def.Def {
+		var op pb.Op
+		require.NoError(t, op.Unmarshal(dt))
+		if s := op.GetSource(); s != nil && strings.HasPrefix(s.Identifier, "https://") {
+			src = s
+		}
+	}
+	require.NotNil(t, src)
+	require.Equal(t, "artifacts", src.Attrs[pb.AttrHTTPAuthTokenSecret])
+	require.Equal(t, "http://proxy.example.com", src.Attrs[pb.AttrHTTPSProxy])
+	require.Equal(t, "internal", src.Attrs[pb.AttrHTTPNoProxy])
+
+	df = `FROM scratch
+ADD --auth-secret=artifacts foo /foo
+`
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{LLBCaps: &caps})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "auth secret is only supported for URL sources")
+}
+
+func TestDockerfileAddUnpack(t *testing.T) {
+	t.Parallel()
+	caps := pb.Caps.CapSet(pb.Caps.All())
+
+	df := `FROM scratch
+ADD --unpack=auto --unpack-strip-components=1 --unpack-subpath=bin https://example.com/tool.tar.zst /usr/local/bin/
+`
+	st, _, _, err := Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{LLBCaps: &caps})
+	require.NoError(t, err)
+
+	def, err := st.Marshal(appcontext.Context())
+	require.NoError(t, err)
+
+	var src *pb.SourceOp
+	var cp *pb.FileActionCopy
+	for _, dt := range def.Def {
+		var op pb.Op
+		require.NoError(t, op.Unmarshal(dt))
+		if s := op.GetSource(); s != nil && strings.HasPrefix(s.Identifier, "https://") {
+			src = s
+		}
+		if f := op.GetFile(); f != nil {
+			cp = f.Actions[0].GetCopy()
+		}
+	}
+	require.NotNil(t, src)
+	require.Equal(t, pb.AttrHTTPUnpackAuto, src.Attrs[pb.AttrHTTPUnpack])
+	require.Equal(t, "1", src.Attrs[pb.AttrHTTPUnpackStripComponents])
+	require.Equal(t, "bin", src.Attrs[pb.AttrHTTPUnpackSubpath])
+	require.NotNil(t, cp)
+	require.Equal(t, "/", cp.Src)
+	require.True(t, cp.DirCopyContents)
+
+	df = `FROM scratch
+ADD --unpack=tar foo.tar /
+`
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{LLBCaps: &caps})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unpack options are only supported for URL sources")
+
+	df = `FROM scratch
+ADD --unpack-strip-components=1 https://example.com/tool.tar.zst /
+`
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{LLBCaps: &caps})
+	require.Error(t, err)
+}
+
+func TestDockerfileTargetStages(t *testing.T) {
+	t.Parallel()
+
+	df := `FROM busybox AS base
+ENV FOO=bar
+RUN echo foo > /foo
+RUN echo bar > /bar
+
+FROM base AS config
+ENV BAR=baz
+
+FROM scratch AS other
+COPY --from=base /foo /
+
+FROM config AS build
+COPY --from=other /foo /foo2
+RUN echo baz > /baz
+WORKDIR /src
+`
+	stages := &TargetStages{}
+	_, _, _, err := Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		MetaResolver: testMetaResolver{},
+		TargetStages: stages,
+	})
+	require.NoError(t, err)
+	// the busybox layer is followed by the layers of the stages, the stages
+	// adding no layers and the stages the target isn't based on are skipped
+	require.Equal(t, []exptypes.StageLayers{
+		{Name: "base", From: 1, To: 2},
+		{Name: "build", From: 3, To: 4},
+	}, stages.Stages)
+
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		MetaResolver: testMetaResolver{},
+		Target:       "other",
+		TargetStages: stages,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []exptypes.StageLayers{{Name: "other", From: 0, To: 0}}, stages.Stages)
+}
@@ -0,0 +1,237 @@
+package dockerfile2llb
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/moby/buildkit/solver/errdefs"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/pkg/errors"
+)
+
+// includedFile is a Dockerfile included with INCLUDE.
+type includedFile struct {
+	sourceMap *llb.SourceMap
+}
+
+// withLocation attaches the location in the included Dockerfile to err. A
+// location already set on err takes precedence. Locations in the main
+// Dockerfile are attached for nil f.
+func (f *includedFile) withLocation(err error, ranges []parser.Range) error {
+	if err == nil {
+		return nil
+	}
+	if f == nil {
+		return parser.WithLocation(err, ranges)
+	}
+	var el *parser.ErrorLocation
+	if errors.As(err, &el) {
+		ranges = el.Location
+	}
+	s := errdefs.Source{
+		Info: &pb.SourceInfo{
+			Data:       f.sourceMap.Data,
+			Filename:   f.sourceMap.Filename,
+			Definition: f.sourceMap.Definition.ToPB(),
+		},
+		Ranges: make([]*pb.Range, 0, len(ranges)),
+	}
+	for _, r := range ranges {
+		s.Ranges = append(s.Ranges, &pb.Range{
+			Start: pb.Position{
+				Line:      int32(r.Start.Line),
+				Character: int32(r.Start.Character),
+			},
+			End: pb.Position{
+				Line:      int32(r.End.Line),
+				Character: int32(r.End.Character),
+			},
+		})
+	}
+	return errdefs.WithSource(err, s)
+}
+
+// includer splices the stages of the included Dockerfiles into the AST.
+type includer struct {
+	opt ConvertOpt
+	// stack contains the included Dockerfiles being resolved, for detecting
+	// cycles
+	stack []string
+	// files maps the FROM nodes of the included stages to their Dockerfile
+	files map[*parser.Node]*includedFile
+}
+
+// parseStages parses the stages and the global build arguments of the
+// Dockerfile with the stages of the included Dockerfiles. The included
+// Dockerfile of every stage is returned, nil for the stages of the main
+// Dockerfile.
+func parseStages(ctx context.Context, dt []byte, opt ConvertOpt) ([]instructions.Stage, []instructions.ArgCommand, []*includedFile, error) {
+	if opt.Warn == nil {
+		opt.Warn = func(string, string, [][]byte, *parser.Range) {}
+	}
+	if opt.ContextLocalName == "" {
+		opt.ContextLocalName = defaultContextLocalName
+	}
+	dockerfile, err := parser.Parse(bytes.NewReader(dt))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	stageFiles, err := resolveIncludes(ctx, dockerfile.AST, opt)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	stages, metaArgs, err := instructions.Parse(dockerfile.AST)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return stages, metaArgs, stageFiles, nil
+}
+
+// resolveIncludes replaces the INCLUDE instructions of the Dockerfile with
+// the instructions of the included Dockerfiles. Global build arguments of
+// the included Dockerfiles are added in place of the INCLUDE and their stages
+// are added after the stages of the Dockerfile, so that the indexes of the
+// stages of the Dockerfile don't change. The included Dockerfile of every
+// stage is returned, nil for the stages of the main Dockerfile.
+func resolveIncludes(ctx context.Context, ast *parser.Node, opt ConvertOpt) ([]*includedFile, error) {
+	in := &includer{
+		opt:   opt,
+		files: map[*parser.Node]*includedFile{},
+	}
+	meta, stages, err := in.expand(ctx, ast, nil)
+	if err != nil {
+		return nil, err
+	}
+	ast.Children = append(meta, stages...)
+
+	var files []*includedFile
+	for _, n := range stages {
+		if strings.EqualFold(n.Value, "from") {
+			files = append(files, in.files[n])
+		}
+	}
+	return files, nil
+}
+
+func (in *includer) expand(ctx context.Context, ast *parser.Node, file *includedFile) (meta, stages []*parser.Node, err error) {
+	var includedStages []*parser.Node
+	for _, n := range ast.Children {
+		cmd := strings.ToLower(n.Value)
+		if cmd == "from" {
+			if file != nil {
+				in.files[n] = file
+			}
+			stages = append(stages, n)
+			continue
+		}
+		if cmd != "include" {
+			if len(stages) == 0 {
+				meta = append(meta, n)
+			} else {
+				stages = append(stages, n)
+			}
+			continue
+		}
+		if len(stages) > 0 {
+			return nil, nil, file.withLocation(errors.New("INCLUDE is only allowed before the first FROM"), n.Location())
+		}
+
+		ic, err := instructions.ParseInstruction(n)
+		if err != nil {
+			return nil, nil, file.withLocation(err, n.Location())
+		}
+		c := ic.(*instructions.IncludeCommand)
+
+		incMeta, incStages, err := in.include(ctx, c)
+		if err != nil {
+			return nil, nil, file.withLocation(err, n.Location())
+		}
+		meta = append(meta, incMeta...)
+		includedStages = append(includedStages, incStages...)
+	}
+	return meta, append(stages, includedStages...), nil
+}
+
+func (in *includer) include(ctx context.Context, c *instructions.IncludeCommand) (meta, stages []*parser.Node, err error) {
+	if in.opt.ReadFile == nil {
+		return nil, nil, errors.New("INCLUDE is not supported by the frontend")
+	}
+
+	key := c.Path
+	if c.From != "" {
+		key = c.From + ":" + c.Path
+	}
+	for i, k := range in.stack {
+		if k == key {
+			return nil, nil, errors.Errorf("circular include: %s", strings.Join(append(in.stack[i:], key), " -> "))
+		}
+	}
+
+	st, err := in.includeSource(ctx, c)
+	if err != nil {
+		return nil, nil, err
+	}
+	dt, err := in.opt.ReadFile(ctx, st, c.Path)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read included Dockerfile %s", key)
+	}
+	def, err := st.Marshal(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	sm := llb.NewSourceMap(&st, c.Path, dt)
+	sm.Definition = def
+	file := &includedFile{sourceMap: sm}
+
+	ast, err := parser.Parse(bytes.NewReader(dt))
+	if err != nil {
+		var el *parser.ErrorLocation
+		if errors.As(err, &el) {
+			return nil, nil, file.withLocation(err, el.Location)
+		}
+		return nil, nil, errors.Wrapf(err, "failed to parse included Dockerfile %s", key)
+	}
+	if in.opt.Warn != nil {
+		for _, w := range ast.Warnings {
+			in.opt.Warn(w.Short, w.URL, w.Detail, nil)
+		}
+	}
+
+	in.stack = append(in.stack, key)
+	defer func() {
+		in.stack = in.stack[:len(in.stack)-1]
+	}()
+	return in.expand(ctx, ast.AST, file)
+}
+
+// includeSource returns the state of the context the Dockerfile is included
+// from.
+func (in *includer) includeSource(ctx context.Context, c *instructions.IncludeCommand) (llb.State, error) {
+	if c.From != "" && !strings.EqualFold(c.From, "context") {
+		if in.opt.ContextByName == nil {
+			return llb.State{}, errors.Errorf("context %s not found", c.From)
+		}
+		st, _, err := in.opt.ContextByName(ctx, c.From)
+		if err != nil {
+			return llb.State{}, err
+		}
+		if st == nil {
+			return llb.State{}, errors.Errorf("context %s not found", c.From)
+		}
+		return *st, nil
+	}
+	if in.opt.BuildContext != nil {
+		return *in.opt.BuildContext, nil
+	}
+	return llb.Local(in.opt.ContextLocalName,
+		llb.SessionID(in.opt.SessionID),
+		llb.FollowPaths([]string{c.Path}),
+		llb.SharedKeyHint(in.opt.ContextLocalName+"-include"),
+		WithInternalName("load included Dockerfile "+c.Path),
+		llb.Differ(llb.DiffNone, false),
+	), nil
+}
@@ -0,0 +1,125 @@
+package dockerfile2llb
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/moby/buildkit/frontend/subrequests/lockfile"
+	binfotypes "github.com/moby/buildkit/util/buildinfo/types"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// SourceLock records the images, git repositories and URLs the Dockerfile
+// builds from, and pins them to the sources of a lockfile if it is set. With
+// a lockfile, the build fails if a source is missing from it or if an image
+// or URL doesn't match its pin. Git repositories are checked out at their
+// locked commit.
+type SourceLock struct {
+	lockfile *lockfile.Lockfile
+
+	mu      sync.Mutex
+	sources map[lockfile.Source]struct{}
+}
+
+// NewSourceLock returns a SourceLock enforcing the pins of the lockfile, if
+// it is not nil.
+func NewSourceLock(l *lockfile.Lockfile) *SourceLock {
+	return &SourceLock{lockfile: l, sources: map[lockfile.Source]struct{}{}}
+}
+
+// Sources returns the recorded sources, sorted by type and ref. The pins of
+// git repositories and URLs without checksum are empty as they are only known
+// once the sources are fetched.
+func (sl *SourceLock) Sources() []lockfile.Source {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	l := &lockfile.Lockfile{}
+	for src := range sl.sources {
+		l.Sources = append(l.Sources, src)
+	}
+	l.Sort()
+	return l.Sources
+}
+
+// locked returns true if the sources are pinned to a lockfile.
+func (sl *SourceLock) locked() bool {
+	return sl != nil && sl.lockfile != nil
+}
+
+func (sl *SourceLock) record(typ binfotypes.SourceType, ref, pin string) {
+	sl.mu.Lock()
+	sl.sources[lockfile.Source{Type: typ, Ref: ref, Pin: pin}] = struct{}{}
+	sl.mu.Unlock()
+}
+
+func (sl *SourceLock) pin(typ binfotypes.SourceType, ref string) (string, error) {
+	pin, ok := sl.lockfile.Pin(typ, ref)
+	if !ok {
+		return "", errors.Errorf("%s source %s is not in the lockfile", typ, ref)
+	}
+	return pin, nil
+}
+
+// image records the image resolved to dgst and checks it against its pin.
+func (sl *SourceLock) image(ref string, dgst digest.Digest) error {
+	if sl == nil {
+		return nil
+	}
+	sl.record(binfotypes.SourceTypeDockerImage, ref, dgst.String())
+	if sl.lockfile == nil {
+		return nil
+	}
+	pin, err := sl.pin(binfotypes.SourceTypeDockerImage, ref)
+	if err != nil {
+		return err
+	}
+	if pin != dgst.String() {
+		return errors.Errorf("image %s resolved to %s but is locked to %s", ref, dgst, pin)
+	}
+	return nil
+}
+
+// git records the git source and returns the ref to check out, the locked
+// commit with the subdirectory of the ref if there is a lockfile.
+func (sl *SourceLock) git(src, ref string) (string, error) {
+	if sl == nil {
+		return ref, nil
+	}
+	sl.record(binfotypes.SourceTypeGit, src, "")
+	if sl.lockfile == nil {
+		return ref, nil
+	}
+	pin, err := sl.pin(binfotypes.SourceTypeGit, src)
+	if err != nil {
+		return "", err
+	}
+	if i := strings.Index(ref, ":"); i >= 0 {
+		return pin + ref[i:], nil
+	}
+	return pin, nil
+}
+
+// http records the URL and returns the checksum it is verified with, the
+// locked one if there is a lockfile.
+func (sl *SourceLock) http(src string, checksum digest.Digest) (digest.Digest, error) {
+	if sl == nil {
+		return checksum, nil
+	}
+	sl.record(binfotypes.SourceTypeHTTP, src, checksum.String())
+	if sl.lockfile == nil {
+		return checksum, nil
+	}
+	pin, err := sl.pin(binfotypes.SourceTypeHTTP, src)
+	if err != nil {
+		return "", err
+	}
+	dgst, err := digest.Parse(pin)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid lockfile checksum for %s", src)
+	}
+	if checksum != "" && checksum != dgst {
+		return "", errors.Errorf("checksum %s of %s doesn't match its locked checksum %s", checksum, src, dgst)
+	}
+	return dgst, nil
+}
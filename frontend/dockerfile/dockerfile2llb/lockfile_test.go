@@ -0,0 +1,95 @@
+package dockerfile2llb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/frontend/subrequests/lockfile"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/appcontext"
+	binfotypes "github.com/moby/buildkit/util/buildinfo/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerfileSourceLock(t *testing.T) {
+	t.Parallel()
+	caps := pb.Caps.CapSet(pb.Caps.All())
+
+	df := `FROM busybox
+ADD https://example.com/foo.tar.gz /
+ADD https://github.com/moby/buildkit.git#v0.10.0 /src
+`
+	dgst, _, err := testMetaResolver{}.ResolveImageConfig(appcontext.Context(), "docker.io/library/busybox:latest", llb.ResolveImageConfigOpt{})
+	require.NoError(t, err)
+
+	sl := NewSourceLock(nil)
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		LLBCaps:      &caps,
+		MetaResolver: testMetaResolver{},
+		SourceLock:   sl,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []lockfile.Source{
+		{Type: binfotypes.SourceTypeDockerImage, Ref: "busybox", Pin: dgst.String()},
+		{Type: binfotypes.SourceTypeGit, Ref: "https://github.com/moby/buildkit.git#v0.10.0"},
+		{Type: binfotypes.SourceTypeHTTP, Ref: "https://example.com/foo.tar.gz"},
+	}, sl.Sources())
+
+	const (
+		commit   = "ad57b5e19bee74c8ea3dc2b8d2a2ee1ec7e3d0b8"
+		checksum = "sha256:24454f830cdb571e2c4ad15481119c43b3cafd48dd869a9b2945d1036d1dc68d"
+	)
+	lock := &lockfile.Lockfile{Sources: []lockfile.Source{
+		{Type: binfotypes.SourceTypeDockerImage, Ref: "busybox", Pin: dgst.String()},
+		{Type: binfotypes.SourceTypeGit, Ref: "https://github.com/moby/buildkit.git#v0.10.0", Pin: commit},
+		{Type: binfotypes.SourceTypeHTTP, Ref: "https://example.com/foo.tar.gz", Pin: checksum},
+	}}
+	st, _, _, err := Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		LLBCaps:      &caps,
+		MetaResolver: testMetaResolver{},
+		SourceLock:   NewSourceLock(lock),
+	})
+	require.NoError(t, err)
+
+	def, err := st.Marshal(appcontext.Context())
+	require.NoError(t, err)
+	var gitSrc, httpSrc *pb.SourceOp
+	for _, dt := range def.Def {
+		var op pb.Op
+		require.NoError(t, op.Unmarshal(dt))
+		if s := op.GetSource(); s != nil {
+			switch {
+			case strings.HasPrefix(s.Identifier, "git://"):
+				gitSrc = s
+			case strings.HasPrefix(s.Identifier, "https://"):
+				httpSrc = s
+			}
+		}
+	}
+	require.NotNil(t, gitSrc)
+	require.True(t, strings.HasSuffix(gitSrc.Identifier, "#"+commit), gitSrc.Identifier)
+	require.NotNil(t, httpSrc)
+	require.Equal(t, checksum, httpSrc.Attrs[pb.AttrHTTPChecksum])
+
+	// the image resolves to another digest
+	lock.Sources[0].Pin = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		LLBCaps:      &caps,
+		MetaResolver: testMetaResolver{},
+		SourceLock:   NewSourceLock(lock),
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "is locked to")
+
+	// the URL isn't locked
+	lock.Sources = lock.Sources[:2]
+	lock.Sources[0].Pin = dgst.String()
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		LLBCaps:      &caps,
+		MetaResolver: testMetaResolver{},
+		SourceLock:   NewSourceLock(lock),
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "is not in the lockfile")
+}
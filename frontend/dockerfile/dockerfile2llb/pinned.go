@@ -0,0 +1,54 @@
+package dockerfile2llb
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+var commitSHA = regexp.MustCompile(`^(?:[0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// IsPinnedImage returns true if the image reference has a digest.
+func IsPinnedImage(ref reference.Named) bool {
+	_, ok := ref.(reference.Canonical)
+	return ok
+}
+
+// IsPinnedGitRef returns true if the ref of a git source, without its
+// subdirectory, is a full commit SHA.
+func IsPinnedGitRef(ref string) bool {
+	if i := strings.Index(ref, ":"); i >= 0 {
+		ref = ref[:i]
+	}
+	return commitSHA.MatchString(ref)
+}
+
+// pinnedImage checks the image of a stage is pinned when pinned sources are
+// required. An image locked by the lockfile is pinned.
+func pinnedImage(opt ConvertOpt, name string, ref reference.Named) error {
+	if !opt.RequirePinnedSources || opt.SourceLock.locked() || IsPinnedImage(ref) {
+		return nil
+	}
+	return errors.Errorf("image %s is not pinned to a digest, required by require-pinned-sources", name)
+}
+
+// pinnedGit checks the ref a git source is checked out at is a commit when
+// pinned sources are required.
+func pinnedGit(opt dispatchOpt, src, ref string) error {
+	if !opt.requirePinnedSources || IsPinnedGitRef(ref) {
+		return nil
+	}
+	return errors.Errorf("git source %s is not pinned to a commit, required by require-pinned-sources", src)
+}
+
+// pinnedHTTP checks a URL is verified with a checksum when pinned sources are
+// required.
+func pinnedHTTP(opt dispatchOpt, src string, checksum digest.Digest) error {
+	if !opt.requirePinnedSources || checksum != "" {
+		return nil
+	}
+	return errors.Errorf("URL %s has no checksum, required by require-pinned-sources", src)
+}
@@ -0,0 +1,111 @@
+package dockerfile2llb
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/frontend/subrequests/lockfile"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/appcontext"
+	binfotypes "github.com/moby/buildkit/util/buildinfo/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerfileRequirePinnedSources(t *testing.T) {
+	t.Parallel()
+	caps := pb.Caps.CapSet(pb.Caps.All())
+
+	const (
+		image    = "busybox@sha256:24454f830cdb571e2c4ad15481119c43b3cafd48dd869a9b2945d1036d1dc68d"
+		commit   = "ad57b5e19bee74c8ea3dc2b8d2a2ee1ec7e3d0b8"
+		checksum = "sha256:24454f830cdb571e2c4ad15481119c43b3cafd48dd869a9b2945d1036d1dc68d"
+	)
+
+	for _, tc := range []struct {
+		name string
+		df   string
+		err  string
+	}{
+		{
+			name: "pinned",
+			df: `FROM ` + image + `
+ADD --checksum=` + checksum + ` https://example.com/foo.tar.gz /
+ADD https://github.com/moby/buildkit.git#` + commit + ` /src
+ADD https://github.com/moby/buildkit.git#` + commit + `:docs /docs
+COPY --from=alpine@` + checksum + ` /etc/alpine-release /
+`,
+		},
+		{
+			name: "image",
+			df:   "FROM busybox\n",
+			err:  "image busybox is not pinned to a digest",
+		},
+		{
+			name: "copy from image",
+			df:   "FROM " + image + "\nCOPY --from=alpine:3.19 /etc/alpine-release /\n",
+			err:  "image alpine:3.19 is not pinned to a digest",
+		},
+		{
+			name: "git",
+			df:   "FROM " + image + "\nADD https://github.com/moby/buildkit.git#v0.10.0 /src\n",
+			err:  "git source https://github.com/moby/buildkit.git#v0.10.0 is not pinned to a commit",
+		},
+		{
+			name: "url",
+			df:   "FROM " + image + "\nADD https://example.com/foo.tar.gz /\n",
+			err:  "URL https://example.com/foo.tar.gz has no checksum",
+		},
+		{
+			name: "unreachable stage",
+			df:   "FROM busybox AS unused\nFROM " + image + "\n",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, _, err := Dockerfile2LLB(appcontext.Context(), []byte(tc.df), ConvertOpt{
+				LLBCaps:              &caps,
+				MetaResolver:         testMetaResolver{},
+				RequirePinnedSources: true,
+			})
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.err)
+		})
+	}
+
+	// the sources pinned by the lockfile are pinned
+	df := `FROM busybox
+ADD https://example.com/foo.tar.gz /
+ADD https://github.com/moby/buildkit.git#v0.10.0 /src
+`
+	lock := &lockfile.Lockfile{Sources: []lockfile.Source{
+		{Type: binfotypes.SourceTypeGit, Ref: "https://github.com/moby/buildkit.git#v0.10.0", Pin: commit},
+		{Type: binfotypes.SourceTypeHTTP, Ref: "https://example.com/foo.tar.gz", Pin: checksum},
+	}}
+	sl := NewSourceLock(nil)
+	_, _, _, err := Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		LLBCaps:      &caps,
+		MetaResolver: testMetaResolver{},
+		SourceLock:   sl,
+	})
+	require.NoError(t, err)
+	lock.Sources = append(lock.Sources, sl.Sources()[0])
+	_, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{
+		LLBCaps:              &caps,
+		MetaResolver:         testMetaResolver{},
+		SourceLock:           NewSourceLock(lock),
+		RequirePinnedSources: true,
+	})
+	require.NoError(t, err)
+}
+
+func TestIsPinnedGitRef(t *testing.T) {
+	require.True(t, IsPinnedGitRef("ad57b5e19bee74c8ea3dc2b8d2a2ee1ec7e3d0b8"))
+	require.True(t, IsPinnedGitRef("ad57b5e19bee74c8ea3dc2b8d2a2ee1ec7e3d0b8:docs"))
+	require.False(t, IsPinnedGitRef(""))
+	require.False(t, IsPinnedGitRef("main"))
+	require.False(t, IsPinnedGitRef("ad57b5e"))
+	require.False(t, IsPinnedGitRef("refs/tags/v0.10.0:docs"))
+}
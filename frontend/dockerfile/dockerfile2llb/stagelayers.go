@@ -0,0 +1,44 @@
+package dockerfile2llb
+
+import (
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// TargetStages records the layers of the image of the build target added by
+// the named stages it is built from. The exporters use them to select the
+// layers of a stage by name, e.g. with squash-layers=from=<stage>. The stages
+// are complete once Dockerfile2LLB returns.
+type TargetStages struct {
+	Stages []exptypes.StageLayers
+}
+
+// record records the stages of the chain of bases of target that add layers
+// to its image, from the bottom one.
+func (ts *TargetStages) record(target *dispatchState) {
+	if ts == nil {
+		return
+	}
+	ts.Stages = nil
+	for d := target; d != nil; d = d.base {
+		if d.stageName == "" || d.layers == d.firstLayer {
+			continue
+		}
+		ts.Stages = append([]exptypes.StageLayers{{
+			Name: d.stageName,
+			From: d.firstLayer,
+			To:   d.layers - 1,
+		}}, ts.Stages...)
+	}
+}
+
+// historyLayers returns the number of layers of the history entries.
+func historyLayers(history []ocispecs.History) int {
+	var n int
+	for _, h := range history {
+		if !h.EmptyLayer {
+			n++
+		}
+	}
+	return n
+}
@@ -26,6 +26,7 @@ var mountTests = integration.TestFuncs(
 	testMountFromError,
 	testMountInvalid,
 	testMountTmpfsSize,
+	testCacheMountOwner,
 )
 
 func init() {
@@ -270,6 +271,34 @@ RUN --mount=type=cache,target=/mycache,uid=1001,gid=1002,mode=0751 [ "$(stat -c
 	require.NoError(t, err)
 }
 
+func testCacheMountOwner(t *testing.T, sb integration.Sandbox) {
+	f := getFrontend(t, sb)
+
+	dockerfile := []byte(`
+FROM busybox
+RUN --mount=type=cache,target=/mycache,id=owned,uid=1001,gid=1002 [ "$(stat -c "%u %g" /mycache)" == "1001 1002" ] && touch /mycache/foo && [ "$(stat -c "%u %g" /mycache/foo)" == "0 0" ]
+RUN --mount=type=cache,target=/mycache,id=owned,uid=1003,gid=1004 [ "$(stat -c "%u %g" /mycache)" == "1003 1004" ] && [ -f /mycache/foo ]
+`)
+
+	dir, err := tmpdir(
+		fstest.CreateFile("Dockerfile", dockerfile, 0600),
+	)
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c, err := client.New(sb.Context(), sb.Address())
+	require.NoError(t, err)
+	defer c.Close()
+
+	_, err = f.Solve(sb.Context(), c, client.SolveOpt{
+		LocalDirs: map[string]string{
+			builder.DefaultLocalNameDockerfile: dir,
+			builder.DefaultLocalNameContext:    dir,
+		},
+	}, nil)
+	require.NoError(t, err)
+}
+
 func testCacheMountDefaultID(t *testing.T, sb integration.Sandbox) {
 	f := getFrontend(t, sb)
 
@@ -451,7 +480,7 @@ RUN --mount=from=$ttt,type=cache,target=/tmp ls
 		},
 	}, nil)
 	require.Error(t, err)
-	require.Contains(t, err.Error(), "'from' doesn't support variable expansion, define alias stage instead")
+	require.Contains(t, err.Error(), "mount from $ttt expands to an empty value")
 }
 
 func testMountTmpfsSize(t *testing.T, sb integration.Sandbox) {
@@ -5072,16 +5072,21 @@ COPY Dockerfile Dockerfile
 		require.True(t, len(reqs) > 0)
 
 		hasDescribe := false
+		hasTargets := false
 
 		for _, req := range reqs {
-			if req.Name == "frontend.subrequests.describe" {
+			switch req.Name {
+			case "frontend.subrequests.describe":
 				hasDescribe = true
 				require.Equal(t, subrequests.RequestType("rpc"), req.Type)
 				require.NotEqual(t, req.Version, "")
 				require.True(t, len(req.Metadata) > 0)
+			case "frontend.targets":
+				hasTargets = true
 			}
 		}
 		require.True(t, hasDescribe)
+		require.True(t, hasTargets)
 
 		_, err = c.Solve(ctx, gateway.SolveRequest{
 			FrontendOpt: map[string]string{
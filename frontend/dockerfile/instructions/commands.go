@@ -224,9 +224,24 @@ func (s *SourcesAndDest) ExpandRaw(expander SingleWordExpander) error {
 type AddCommand struct {
 	withNameAndCode
 	SourcesAndDest
-	Chown string
-	Chmod string
-	Link  bool
+	Chown           string
+	Chmod           string
+	Link            bool
+	Checksum        string
+	ExcludePatterns []string
+	// KeepGitDir, Submodules and LFS only apply to git sources.
+	KeepGitDir bool
+	Submodules bool
+	LFS        bool
+	// AuthSecret is the ID of the secret sent as a bearer token when
+	// downloading remote URLs.
+	AuthSecret string
+	// Unpack is the format of the remote archives extracted to the
+	// destination. UnpackStripComponents and UnpackSubpath select the files
+	// of the archives that are extracted.
+	Unpack                string
+	UnpackStripComponents int
+	UnpackSubpath         string
 }
 
 // Expand variables
@@ -237,6 +252,34 @@ func (c *AddCommand) Expand(expander SingleWordExpander) error {
 	}
 	c.Chown = expandedChown
 
+	expandedChecksum, err := expander(c.Checksum)
+	if err != nil {
+		return err
+	}
+	c.Checksum = expandedChecksum
+
+	expandedAuthSecret, err := expander(c.AuthSecret)
+	if err != nil {
+		return err
+	}
+	c.AuthSecret = expandedAuthSecret
+
+	expandedUnpack, err := expander(c.Unpack)
+	if err != nil {
+		return err
+	}
+	c.Unpack = expandedUnpack
+
+	expandedUnpackSubpath, err := expander(c.UnpackSubpath)
+	if err != nil {
+		return err
+	}
+	c.UnpackSubpath = expandedUnpackSubpath
+
+	if err := expandSliceInPlace(c.ExcludePatterns, expander); err != nil {
+		return err
+	}
+
 	return c.SourcesAndDest.Expand(expander)
 }
 
@@ -247,10 +290,12 @@ func (c *AddCommand) Expand(expander SingleWordExpander) error {
 type CopyCommand struct {
 	withNameAndCode
 	SourcesAndDest
-	From  string
-	Chown string
-	Chmod string
-	Link  bool
+	From            string
+	Chown           string
+	Chmod           string
+	Link            bool
+	Checksum        string
+	ExcludePatterns []string
 }
 
 // Expand variables
@@ -261,6 +306,16 @@ func (c *CopyCommand) Expand(expander SingleWordExpander) error {
 	}
 	c.Chown = expandedChown
 
+	expandedChecksum, err := expander(c.Checksum)
+	if err != nil {
+		return err
+	}
+	c.Checksum = expandedChecksum
+
+	if err := expandSliceInPlace(c.ExcludePatterns, expander); err != nil {
+		return err
+	}
+
 	return c.SourcesAndDest.Expand(expander)
 }
 
@@ -270,6 +325,18 @@ type OnbuildCommand struct {
 	Expression string
 }
 
+// IncludeCommand : INCLUDE [--from=<context>] path/to/Dockerfile
+//
+// Include the stages of another Dockerfile, read from the build context or
+// a named context. Includes are resolved by the frontend before the stages
+// are converted.
+//
+type IncludeCommand struct {
+	withNameAndCode
+	Path string
+	From string
+}
+
 // WorkdirCommand : WORKDIR /tmp
 //
 // Set the working directory for future RUN/CMD/etc statements.
@@ -477,6 +544,12 @@ type Stage struct {
 	Platform   string
 	Location   []parser.Range
 	Comment    string
+	// Condition is the unexpanded word after IF. The stage is skipped if it
+	// evaluates to false.
+	Condition string
+	// Output is set for stages declared as "output:<name>" that are returned
+	// as named build outputs in addition to the build target.
+	Output bool
 }
 
 // AddCommand to the stage
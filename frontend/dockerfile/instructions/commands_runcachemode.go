@@ -0,0 +1,50 @@
+package instructions
+
+import (
+	"github.com/pkg/errors"
+)
+
+const (
+	CacheModeDefinition = "definition"
+	CacheModeContent    = "content"
+	CacheModeHermetic   = "hermetic"
+)
+
+var cacheModeKey = "dockerfile/run/cachemode"
+
+func init() {
+	parseRunPreHooks = append(parseRunPreHooks, runCacheModePreHook)
+	parseRunPostHooks = append(parseRunPostHooks, runCacheModePostHook)
+}
+
+func runCacheModePreHook(cmd *RunCommand, req parseRequest) error {
+	st := &cacheModeState{}
+	st.flag = req.flags.AddString("cache-mode", CacheModeDefinition)
+	cmd.setExternalValue(cacheModeKey, st)
+	return nil
+}
+
+func runCacheModePostHook(cmd *RunCommand, req parseRequest) error {
+	st := cmd.getExternalValue(cacheModeKey).(*cacheModeState)
+	if st == nil {
+		return errors.Errorf("no cache mode state")
+	}
+	switch st.flag.Value {
+	case CacheModeDefinition, CacheModeContent, CacheModeHermetic:
+	default:
+		return errors.Errorf("invalid cache mode %q", st.flag.Value)
+	}
+	st.mode = st.flag.Value
+	return nil
+}
+
+// GetCacheMode returns how the result of the RUN command is matched in the
+// cache.
+func GetCacheMode(cmd *RunCommand) string {
+	return cmd.getExternalValue(cacheModeKey).(*cacheModeState).mode
+}
+
+type cacheModeState struct {
+	flag *Flag
+	mode string
+}
@@ -16,13 +16,23 @@ const MountTypeCache = "cache"
 const MountTypeTmpfs = "tmpfs"
 const MountTypeSecret = "secret"
 const MountTypeSSH = "ssh"
+const MountTypeImage = "image"
+const MountTypeSocket = "socket"
+const MountTypeBuildKit = "buildkit"
+
+// defaultBuildKitSocketTarget is the path of the BuildKit API socket of the
+// buildkit mounts without target.
+const defaultBuildKitSocketTarget = "/run/buildkit/buildkitd.sock"
 
 var allowedMountTypes = map[string]struct{}{
-	MountTypeBind:   {},
-	MountTypeCache:  {},
-	MountTypeTmpfs:  {},
-	MountTypeSecret: {},
-	MountTypeSSH:    {},
+	MountTypeBind:     {},
+	MountTypeCache:    {},
+	MountTypeTmpfs:    {},
+	MountTypeSecret:   {},
+	MountTypeSSH:      {},
+	MountTypeImage:    {},
+	MountTypeSocket:   {},
+	MountTypeBuildKit: {},
 }
 
 const MountSharingShared = "shared"
@@ -35,6 +45,8 @@ var allowedSharingTypes = map[string]struct{}{
 	MountSharingLocked:  {},
 }
 
+var envNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
 type mountsKeyT string
 
 var mountsKey = mountsKeyT("dockerfile/run/mounts")
@@ -131,6 +143,12 @@ type Mount struct {
 	Mode         *uint64
 	UID          *uint64
 	GID          *uint64
+	// Env is the name of the environment variable the secret is exposed as
+	// for secret mounts.
+	Env *string
+	// KnownHosts contains the known_hosts entries, separated by newlines,
+	// that are made available to the ssh client for ssh mounts.
+	KnownHosts string
 }
 
 func parseMount(value string, expander SingleWordExpander) (*Mount, error) {
@@ -162,7 +180,7 @@ func parseMount(value string, expander SingleWordExpander) (*Mount, error) {
 				roAuto = false
 				continue
 			case "required":
-				if m.Type == "secret" || m.Type == "ssh" {
+				if m.Type == "secret" || m.Type == "ssh" || m.Type == MountTypeSocket || m.Type == MountTypeBuildKit {
 					m.Required = true
 					continue
 				} else {
@@ -176,22 +194,19 @@ func parseMount(value string, expander SingleWordExpander) (*Mount, error) {
 		}
 
 		value := parts[1]
-		// check for potential variable
-		if expander != nil {
+		// check for potential variable. from is expanded with the global
+		// build args when the sources of the stages are detected, not with
+		// the environment of the stage.
+		if key != "from" {
+			if expander == nil {
+				// if we don't have an expander, defer evaluation to later
+				continue
+			}
 			processed, err := expander(value)
 			if err != nil {
 				return nil, err
 			}
 			value = processed
-		} else if key == "from" {
-			if matched, err := regexp.MatchString(`\$.`, value); err != nil { //nolint
-				return nil, err
-			} else if matched {
-				return nil, errors.Errorf("'%s' doesn't support variable expansion, define alias stage instead", key)
-			}
-		} else {
-			// if we don't have an expander, defer evaluation to later
-			continue
 		}
 
 		switch key {
@@ -220,7 +235,7 @@ func parseMount(value string, expander SingleWordExpander) (*Mount, error) {
 			m.ReadOnly = !rw
 			roAuto = false
 		case "required":
-			if m.Type == "secret" || m.Type == "ssh" {
+			if m.Type == "secret" || m.Type == "ssh" || m.Type == MountTypeSocket || m.Type == MountTypeBuildKit {
 				v, err := strconv.ParseBool(value)
 				if err != nil {
 					return nil, errors.Errorf("invalid value for %s: %s", key, value)
@@ -264,15 +279,28 @@ func parseMount(value string, expander SingleWordExpander) (*Mount, error) {
 				return nil, errors.Errorf("invalid value %s for gid", value)
 			}
 			m.GID = &gid
+		case "env":
+			if m.Type != MountTypeSecret {
+				return nil, errors.Errorf("unexpected key '%s' for mount type '%s'", key, m.Type)
+			}
+			if !envNamePattern.MatchString(value) {
+				return nil, errors.Errorf("invalid environment variable name %q", value)
+			}
+			m.Env = &value
+		case "knownhosts":
+			if m.Type != MountTypeSSH {
+				return nil, errors.Errorf("unexpected key '%s' for mount type '%s'", key, m.Type)
+			}
+			m.KnownHosts = value
 		default:
 			allKeys := []string{
-				"type", "from", "source", "target", "readonly", "id", "sharing", "required", "mode", "uid", "gid", "src", "dst", "ro", "rw", "readwrite",
+				"type", "from", "source", "target", "readonly", "id", "sharing", "required", "mode", "uid", "gid", "src", "dst", "ro", "rw", "readwrite", "env", "knownhosts",
 			}
 			return nil, suggest.WrapError(errors.Errorf("unexpected key '%s' in '%s'", key, field), key, allKeys, true)
 		}
 	}
 
-	fileInfoAllowed := m.Type == MountTypeSecret || m.Type == MountTypeSSH || m.Type == MountTypeCache
+	fileInfoAllowed := m.Type == MountTypeSecret || m.Type == MountTypeSSH || m.Type == MountTypeSocket || m.Type == MountTypeBuildKit || m.Type == MountTypeCache
 
 	if m.Mode != nil && !fileInfoAllowed {
 		return nil, errors.Errorf("mode not allowed for %q type mounts", m.Type)
@@ -298,6 +326,15 @@ func parseMount(value string, expander SingleWordExpander) (*Mount, error) {
 		return nil, errors.Errorf("invalid cache sharing set for %v mount", m.Type)
 	}
 
+	if m.Type == MountTypeImage {
+		if m.From == "" {
+			return nil, errors.Errorf("image mount requires from")
+		}
+		if !m.ReadOnly {
+			return nil, errors.Errorf("image mount can't be read-write")
+		}
+	}
+
 	if m.Type == MountTypeSecret {
 		if m.From != "" {
 			return nil, errors.Errorf("secret mount should not have a from")
@@ -313,5 +350,26 @@ func parseMount(value string, expander SingleWordExpander) (*Mount, error) {
 		}
 	}
 
+	if m.Type == MountTypeSocket {
+		if m.CacheID == "" {
+			return nil, errors.Errorf("socket mount requires id")
+		}
+		if m.Target == "" {
+			return nil, errors.Errorf("socket mount requires target")
+		}
+		if m.From != "" || m.Source != "" {
+			return nil, errors.Errorf("socket mount does not support from or source")
+		}
+	}
+
+	if m.Type == MountTypeBuildKit {
+		if m.From != "" || m.Source != "" || m.CacheID != "" {
+			return nil, errors.Errorf("buildkit mount does not support from, source or id")
+		}
+		if m.Target == "" {
+			m.Target = defaultBuildKitSocketTarget
+		}
+	}
+
 	return m, nil
 }
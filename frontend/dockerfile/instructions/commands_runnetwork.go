@@ -1,6 +1,8 @@
 package instructions
 
 import (
+	"regexp"
+
 	"github.com/pkg/errors"
 )
 
@@ -16,11 +18,25 @@ var allowedNetwork = map[string]struct{}{
 	NetworkHost:    {},
 }
 
+// networkNamePattern matches the names of networks configured in the worker
+// that can be selected instead of the network modes.
+var networkNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
 func isValidNetwork(value string) bool {
+	return isNetworkMode(value) || IsNamedNetwork(value)
+}
+
+func isNetworkMode(value string) bool {
 	_, ok := allowedNetwork[value]
 	return ok
 }
 
+// IsNamedNetwork returns true if the network of a RUN command is a named
+// network configured in the worker instead of one of the network modes.
+func IsNamedNetwork(value string) bool {
+	return !isNetworkMode(value) && networkNamePattern.MatchString(value)
+}
+
 var networkKey = "dockerfile/run/network"
 
 func init() {
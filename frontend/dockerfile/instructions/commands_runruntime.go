@@ -0,0 +1,48 @@
+package instructions
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// runtimeNamePattern matches the names of the OCI runtimes configured in the
+// worker.
+var runtimeNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+var runtimeKey = "dockerfile/run/runtime"
+
+func init() {
+	parseRunPreHooks = append(parseRunPreHooks, runRuntimePreHook)
+	parseRunPostHooks = append(parseRunPostHooks, runRuntimePostHook)
+}
+
+func runRuntimePreHook(cmd *RunCommand, req parseRequest) error {
+	st := &runtimeState{}
+	st.flag = req.flags.AddString("runtime", "")
+	cmd.setExternalValue(runtimeKey, st)
+	return nil
+}
+
+func runRuntimePostHook(cmd *RunCommand, req parseRequest) error {
+	st := cmd.getExternalValue(runtimeKey).(*runtimeState)
+	if st == nil {
+		return errors.Errorf("no runtime state")
+	}
+	if v := st.flag.Value; v != "" && !runtimeNamePattern.MatchString(v) {
+		return errors.Errorf("invalid runtime %q", v)
+	}
+	st.runtime = st.flag.Value
+	return nil
+}
+
+// GetRuntime returns the name of the OCI runtime of the worker the RUN
+// command is run with, or an empty string for the default runtime.
+func GetRuntime(cmd *RunCommand) string {
+	return cmd.getExternalValue(runtimeKey).(*runtimeState).runtime
+}
+
+type runtimeState struct {
+	flag    *Flag
+	runtime string
+}
@@ -0,0 +1,39 @@
+package instructions
+
+import (
+	"github.com/pkg/errors"
+)
+
+var traceKey = "dockerfile/run/trace"
+
+func init() {
+	parseRunPreHooks = append(parseRunPreHooks, runTracePreHook)
+	parseRunPostHooks = append(parseRunPostHooks, runTracePostHook)
+}
+
+func runTracePreHook(cmd *RunCommand, req parseRequest) error {
+	st := &traceState{}
+	st.flag = req.flags.AddBool("trace", false)
+	cmd.setExternalValue(traceKey, st)
+	return nil
+}
+
+func runTracePostHook(cmd *RunCommand, req parseRequest) error {
+	st := cmd.getExternalValue(traceKey).(*traceState)
+	if st == nil {
+		return errors.Errorf("no trace state")
+	}
+	st.trace = st.flag.IsTrue()
+	return nil
+}
+
+// GetTrace returns true if the trace context of the build is passed to the
+// RUN command.
+func GetTrace(cmd *RunCommand) bool {
+	return cmd.getExternalValue(traceKey).(*traceState).trace
+}
+
+type traceState struct {
+	flag  *Flag
+	trace bool
+}
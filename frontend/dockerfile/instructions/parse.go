@@ -101,6 +101,8 @@ func ParseInstruction(node *parser.Node) (v interface{}, err error) {
 		return parseArg(req)
 	case command.Shell:
 		return parseShell(req)
+	case command.Include:
+		return parseInclude(req)
 	}
 	return nil, suggest.WrapError(&UnknownInstructionError{Instruction: node.Value, Line: node.StartLine}, node.Value, allInstructionNames(), false)
 }
@@ -154,10 +156,16 @@ func Parse(ast *parser.Node) (stages []Stage, metaArgs []ArgCommand, err error)
 				metaArgs = append(metaArgs, *a)
 				continue
 			}
+			// included Dockerfiles are spliced in by the frontend
+			if _, isInclude := cmd.(*IncludeCommand); isInclude {
+				continue
+			}
 		}
 		switch c := cmd.(type) {
 		case *Stage:
 			stages = append(stages, *c)
+		case *IncludeCommand:
+			return nil, nil, parser.WithLocation(errors.New("INCLUDE is only allowed before the first FROM"), n.Location())
 		case Command:
 			stage, err := CurrentStage(stages)
 			if err != nil {
@@ -281,6 +289,15 @@ func parseAdd(req parseRequest) (*AddCommand, error) {
 	flChown := req.flags.AddString("chown", "")
 	flChmod := req.flags.AddString("chmod", "")
 	flLink := req.flags.AddBool("link", false)
+	flChecksum := req.flags.AddString("checksum", "")
+	flExcludes := req.flags.AddStrings("exclude")
+	flKeepGitDir := req.flags.AddBool("keep-git-dir", false)
+	flSubmodules := req.flags.AddBool("submodules", true)
+	flLFS := req.flags.AddBool("lfs", false)
+	flAuthSecret := req.flags.AddString("auth-secret", "")
+	flUnpack := req.flags.AddString("unpack", "")
+	flUnpackStripComponents := req.flags.AddString("unpack-strip-components", "")
+	flUnpackSubpath := req.flags.AddString("unpack-subpath", "")
 	if err := req.flags.Parse(); err != nil {
 		return nil, err
 	}
@@ -290,12 +307,33 @@ func parseAdd(req parseRequest) (*AddCommand, error) {
 		return nil, err
 	}
 
+	var stripComponents int
+	if v := flUnpackStripComponents.Value; v != "" {
+		stripComponents, err = strconv.Atoi(v)
+		if err != nil || stripComponents < 0 {
+			return nil, errors.Errorf("invalid value %q for --unpack-strip-components", v)
+		}
+	}
+	if flUnpack.Value == "" && (stripComponents != 0 || flUnpackSubpath.Value != "") {
+		return nil, errors.New("--unpack-strip-components and --unpack-subpath require --unpack")
+	}
+
 	return &AddCommand{
 		withNameAndCode: newWithNameAndCode(req),
 		SourcesAndDest:  *sourcesAndDest,
 		Chown:           flChown.Value,
 		Chmod:           flChmod.Value,
 		Link:            flLink.Value == "true",
+		Checksum:        flChecksum.Value,
+		ExcludePatterns: flExcludes.StringValues,
+		KeepGitDir:      flKeepGitDir.Value == "true",
+		Submodules:      flSubmodules.Value == "true",
+		LFS:             flLFS.Value == "true",
+		AuthSecret:      flAuthSecret.Value,
+
+		Unpack:                flUnpack.Value,
+		UnpackStripComponents: stripComponents,
+		UnpackSubpath:         flUnpackSubpath.Value,
 	}, nil
 }
 
@@ -307,6 +345,8 @@ func parseCopy(req parseRequest) (*CopyCommand, error) {
 	flFrom := req.flags.AddString("from", "")
 	flChmod := req.flags.AddString("chmod", "")
 	flLink := req.flags.AddBool("link", false)
+	flChecksum := req.flags.AddString("checksum", "")
+	flExcludes := req.flags.AddStrings("exclude")
 	if err := req.flags.Parse(); err != nil {
 		return nil, err
 	}
@@ -323,11 +363,15 @@ func parseCopy(req parseRequest) (*CopyCommand, error) {
 		Chown:           flChown.Value,
 		Chmod:           flChmod.Value,
 		Link:            flLink.Value == "true",
+		Checksum:        flChecksum.Value,
+		ExcludePatterns: flExcludes.StringValues,
 	}, nil
 }
 
 func parseFrom(req parseRequest) (*Stage, error) {
-	stageName, err := parseBuildStageName(req.args)
+	args, condition := parseStageCondition(req.args)
+	args, output := parseOutputStage(args)
+	stageName, err := parseBuildStageName(args)
 	if err != nil {
 		return nil, err
 	}
@@ -339,16 +383,37 @@ func parseFrom(req parseRequest) (*Stage, error) {
 
 	code := strings.TrimSpace(req.original)
 	return &Stage{
-		BaseName:   req.args[0],
+		BaseName:   args[0],
 		Name:       stageName,
 		SourceCode: code,
 		Commands:   []Command{},
 		Platform:   flPlatform.Value,
 		Location:   req.location,
 		Comment:    getComment(req.comments, stageName),
+		Condition:  condition,
+		Output:     output,
 	}, nil
 }
 
+// parseOutputStage strips the "output:" prefix, that marks the stage as a
+// named build output, off the stage name.
+func parseOutputStage(args []string) ([]string, bool) {
+	const prefix = "output:"
+	if len(args) == 3 && len(args[2]) > len(prefix) && strings.EqualFold(args[2][:len(prefix)], prefix) {
+		return []string{args[0], args[1], args[2][len(prefix):]}, true
+	}
+	return args, false
+}
+
+// parseStageCondition splits the trailing "IF <condition>" off the FROM
+// arguments.
+func parseStageCondition(args []string) ([]string, string) {
+	if n := len(args); n >= 3 && strings.EqualFold(args[n-2], "if") {
+		return args[:n-2], args[n-1]
+	}
+	return args, ""
+}
+
 func parseBuildStageName(args []string) (string, error) {
 	stageName := ""
 	switch {
@@ -376,7 +441,7 @@ func parseOnBuild(req parseRequest) (*OnbuildCommand, error) {
 	switch strings.ToUpper(triggerInstruction) {
 	case "ONBUILD":
 		return nil, errors.New("Chaining ONBUILD via `ONBUILD ONBUILD` isn't allowed")
-	case "MAINTAINER", "FROM":
+	case "MAINTAINER", "FROM", "INCLUDE":
 		return nil, fmt.Errorf("%s isn't allowed as an ONBUILD trigger", triggerInstruction)
 	}
 
@@ -391,6 +456,22 @@ func parseOnBuild(req parseRequest) (*OnbuildCommand, error) {
 	}, nil
 }
 
+func parseInclude(req parseRequest) (*IncludeCommand, error) {
+	if len(req.args) != 1 {
+		return nil, errExactlyOneArgument("INCLUDE")
+	}
+
+	flFrom := req.flags.AddString("from", "")
+	if err := req.flags.Parse(); err != nil {
+		return nil, err
+	}
+	return &IncludeCommand{
+		Path:            req.args[0],
+		From:            flFrom.Value,
+		withNameAndCode: newWithNameAndCode(req),
+	}, nil
+}
+
 func parseWorkdir(req parseRequest) (*WorkdirCommand, error) {
 	if len(req.args) != 1 {
 		return nil, errExactlyOneArgument("WORKDIR")
@@ -239,3 +239,198 @@ func TestRunCmdFlagsUsed(t *testing.T) {
 	require.IsType(t, c, &RunCommand{})
 	require.Equal(t, []string{"mount"}, c.(*RunCommand).FlagsUsed)
 }
+
+func TestRunImageMount(t *testing.T) {
+	expander := func(word string) (string, error) {
+		return word, nil
+	}
+
+	m, err := parseMount("type=image,from=golang:1.18,source=/usr/local/go,target=/go", expander)
+	require.NoError(t, err)
+	require.Equal(t, MountTypeImage, m.Type)
+	require.Equal(t, "golang:1.18", m.From)
+	require.Equal(t, "/usr/local/go", m.Source)
+	require.True(t, m.ReadOnly)
+
+	_, err = parseMount("type=image,target=/go", expander)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "requires from")
+
+	_, err = parseMount("type=image,from=golang:1.18,target=/go,rw", expander)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "can't be read-write")
+}
+
+func TestRunSocketMount(t *testing.T) {
+	expander := func(word string) (string, error) {
+		return word, nil
+	}
+
+	m, err := parseMount("type=socket,id=docker,target=/var/run/docker.sock,required,uid=1000", expander)
+	require.NoError(t, err)
+	require.Equal(t, MountTypeSocket, m.Type)
+	require.Equal(t, "docker", m.CacheID)
+	require.Equal(t, "/var/run/docker.sock", m.Target)
+	require.True(t, m.Required)
+	require.Equal(t, uint64(1000), *m.UID)
+
+	_, err = parseMount("type=socket,target=/var/run/docker.sock", expander)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "requires id")
+
+	_, err = parseMount("type=socket,id=docker", expander)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "requires target")
+}
+
+func TestRunBuildKitMount(t *testing.T) {
+	expander := func(word string) (string, error) {
+		return word, nil
+	}
+
+	m, err := parseMount("type=buildkit,required", expander)
+	require.NoError(t, err)
+	require.Equal(t, MountTypeBuildKit, m.Type)
+	require.Equal(t, "/run/buildkit/buildkitd.sock", m.Target)
+	require.True(t, m.Required)
+
+	m, err = parseMount("type=buildkit,target=/tmp/buildkitd.sock,uid=1000,mode=0660", expander)
+	require.NoError(t, err)
+	require.Equal(t, "/tmp/buildkitd.sock", m.Target)
+	require.Equal(t, uint64(1000), *m.UID)
+	require.Equal(t, uint64(0660), *m.Mode)
+
+	_, err = parseMount("type=buildkit,id=foo", expander)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "does not support")
+}
+
+func TestRunNetworkName(t *testing.T) {
+	for _, tc := range []struct {
+		network string
+		named   bool
+		err     bool
+	}{
+		{network: "host"},
+		{network: "none"},
+		{network: "default"},
+		{network: "test-services", named: true},
+		{network: "foo/bar", err: true},
+		{network: "-foo", err: true},
+	} {
+		require.Equal(t, tc.named, IsNamedNetwork(tc.network), tc.network)
+
+		ast, err := parser.Parse(strings.NewReader("RUN --network=" + tc.network + " echo hello"))
+		require.NoError(t, err)
+
+		c, err := ParseInstruction(ast.AST.Children[0])
+		if tc.err {
+			require.Error(t, err, tc.network)
+			continue
+		}
+		require.NoError(t, err, tc.network)
+		require.Equal(t, tc.network, GetNetwork(c.(*RunCommand)))
+	}
+}
+
+func TestRunTrace(t *testing.T) {
+	for _, tc := range []struct {
+		cmd   string
+		trace bool
+		err   bool
+	}{
+		{cmd: "RUN echo hello"},
+		{cmd: "RUN --trace echo hello", trace: true},
+		{cmd: "RUN --trace=true echo hello", trace: true},
+		{cmd: "RUN --trace=false echo hello"},
+		{cmd: "RUN --trace=foo echo hello", err: true},
+	} {
+		ast, err := parser.Parse(strings.NewReader(tc.cmd))
+		require.NoError(t, err)
+
+		c, err := ParseInstruction(ast.AST.Children[0])
+		if tc.err {
+			require.Error(t, err, tc.cmd)
+			continue
+		}
+		require.NoError(t, err, tc.cmd)
+		require.Equal(t, tc.trace, GetTrace(c.(*RunCommand)), tc.cmd)
+	}
+}
+
+func TestRunRuntime(t *testing.T) {
+	for _, tc := range []struct {
+		cmd     string
+		runtime string
+		err     bool
+	}{
+		{cmd: "RUN echo hello"},
+		{cmd: "RUN --runtime=gvisor echo hello", runtime: "gvisor"},
+		{cmd: "RUN --runtime=kata-qemu echo hello", runtime: "kata-qemu"},
+		{cmd: "RUN --runtime=../runsc echo hello", err: true},
+	} {
+		ast, err := parser.Parse(strings.NewReader(tc.cmd))
+		require.NoError(t, err)
+
+		c, err := ParseInstruction(ast.AST.Children[0])
+		if tc.err {
+			require.Error(t, err, tc.cmd)
+			continue
+		}
+		require.NoError(t, err, tc.cmd)
+		require.Equal(t, tc.runtime, GetRuntime(c.(*RunCommand)), tc.cmd)
+	}
+}
+
+func TestRunCacheMode(t *testing.T) {
+	for _, tc := range []struct {
+		cmd  string
+		mode string
+		err  bool
+	}{
+		{cmd: "RUN echo hello", mode: CacheModeDefinition},
+		{cmd: "RUN --cache-mode=content echo hello", mode: CacheModeContent},
+		{cmd: "RUN --cache-mode=hermetic echo hello", mode: CacheModeHermetic},
+		{cmd: "RUN --cache-mode=foo echo hello", err: true},
+	} {
+		ast, err := parser.Parse(strings.NewReader(tc.cmd))
+		require.NoError(t, err)
+
+		c, err := ParseInstruction(ast.AST.Children[0])
+		if tc.err {
+			require.Error(t, err, tc.cmd)
+			continue
+		}
+		require.NoError(t, err, tc.cmd)
+		require.Equal(t, tc.mode, GetCacheMode(c.(*RunCommand)), tc.cmd)
+	}
+}
+
+func TestOutputStage(t *testing.T) {
+	for _, tc := range []struct {
+		from   string
+		name   string
+		output bool
+		err    bool
+	}{
+		{from: "FROM scratch AS output:bin", name: "bin", output: true},
+		{from: "FROM scratch AS OUTPUT:Bin", name: "bin", output: true},
+		{from: "FROM scratch AS output:bin IF $WITH_BIN", name: "bin", output: true},
+		{from: "FROM scratch AS bin", name: "bin"},
+		{from: "FROM scratch AS output:", err: true},
+		{from: "FROM scratch AS output:1bin", err: true},
+	} {
+		ast, err := parser.Parse(strings.NewReader(tc.from))
+		require.NoError(t, err)
+
+		c, err := ParseInstruction(ast.AST.Children[0])
+		if tc.err {
+			require.Error(t, err, tc.from)
+			continue
+		}
+		require.NoError(t, err, tc.from)
+		st := c.(*Stage)
+		require.Equal(t, tc.name, st.Name, tc.from)
+		require.Equal(t, tc.output, st.Output, tc.from)
+	}
+}
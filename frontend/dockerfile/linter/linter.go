@@ -0,0 +1,245 @@
+package linter
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/pkg/errors"
+)
+
+// Rule is a check run on the parsed Dockerfile.
+type Rule struct {
+	Name        string
+	Description string
+	URL         string
+	// OptIn rules only run when they are enabled.
+	OptIn bool
+}
+
+var (
+	RuleUnpinnedBaseImage = Rule{
+		Name:        "UnpinnedBaseImage",
+		Description: "Base images should be pinned to a digest for reproducible builds",
+		OptIn:       true,
+	}
+	RuleUnusedMetaArg = Rule{
+		Name:        "UnusedMetaArg",
+		Description: "ARG instructions before the first FROM are only available in FROM lines and RUN --mount from unless redeclared in a stage",
+	}
+	RuleShadowedCopy = Rule{
+		Name:        "ShadowedCopy",
+		Description: "Files copied to a destination that is overwritten by a later COPY or ADD are never used",
+	}
+	RuleStageNameCasing = Rule{
+		Name:        "StageNameCasing",
+		Description: "Stage names are case insensitive and should be lowercase",
+	}
+)
+
+var allRules = []Rule{RuleUnpinnedBaseImage, RuleUnusedMetaArg, RuleShadowedCopy, RuleStageNameCasing}
+
+// ValidateRules returns an error if a name isn't the name of a rule.
+func ValidateRules(names []string) error {
+	for _, name := range names {
+		if !hasRule(name) {
+			return errors.Errorf("unknown Dockerfile check %q", name)
+		}
+	}
+	return nil
+}
+
+func hasRule(name string) bool {
+	for _, r := range allRules {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Warning is a problem found by a Rule.
+type Warning struct {
+	Rule     Rule
+	Message  string
+	Location []parser.Range
+}
+
+// ToParserWarning converts the warning to the format used for reporting the
+// warnings of the parser.
+func (w Warning) ToParserWarning() parser.Warning {
+	pw := parser.Warning{
+		Short:  fmt.Sprintf("%s: %s", w.Rule.Name, w.Message),
+		Detail: [][]byte{[]byte(w.Rule.Description)},
+		URL:    w.Rule.URL,
+	}
+	if len(w.Location) > 0 {
+		pw.Location = &w.Location[0]
+	}
+	return pw
+}
+
+// Lint runs the rules on the stages and global build arguments returned by
+// instructions.Parse. The opt-in rules only run if they are in enabled.
+func Lint(stages []instructions.Stage, metaArgs []instructions.ArgCommand, enabled []string) []Warning {
+	var warnings []Warning
+	if contains(enabled, RuleUnpinnedBaseImage.Name) {
+		warnings = append(warnings, checkUnpinnedBaseImages(stages)...)
+	}
+	warnings = append(warnings, checkUnusedMetaArgs(stages, metaArgs)...)
+	warnings = append(warnings, checkShadowedCopies(stages)...)
+	warnings = append(warnings, checkStageNameCasing(stages)...)
+	return warnings
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func checkUnpinnedBaseImages(stages []instructions.Stage) []Warning {
+	var warnings []Warning
+	names := map[string]struct{}{}
+	for _, st := range stages {
+		base := st.BaseName
+		_, isStage := names[strings.ToLower(base)]
+		if st.Name != "" {
+			names[st.Name] = struct{}{}
+		}
+		// images set with build arguments are only known after expansion
+		if isStage || base == "scratch" || strings.Contains(base, "$") {
+			continue
+		}
+		ref, err := reference.ParseNormalizedNamed(base)
+		if err != nil {
+			continue
+		}
+		if _, ok := ref.(reference.Digested); ok {
+			continue
+		}
+		warnings = append(warnings, Warning{
+			Rule:     RuleUnpinnedBaseImage,
+			Message:  fmt.Sprintf("base image %s is not pinned to a digest", base),
+			Location: st.Location,
+		})
+	}
+	return warnings
+}
+
+func checkUnusedMetaArgs(stages []instructions.Stage, metaArgs []instructions.ArgCommand) []Warning {
+	redeclared := map[string]struct{}{}
+	// the global build args are expanded in FROM and in the sources of the
+	// RUN mounts
+	var uses []string
+	for _, st := range stages {
+		uses = append(uses, st.BaseName, st.Platform, st.Condition)
+		for _, cmd := range st.Commands {
+			switch c := cmd.(type) {
+			case *instructions.ArgCommand:
+				for _, arg := range c.Args {
+					redeclared[arg.Key] = struct{}{}
+				}
+			case *instructions.RunCommand:
+				for _, m := range instructions.GetMounts(c) {
+					uses = append(uses, m.From)
+				}
+			}
+		}
+	}
+
+	var warnings []Warning
+	for _, cmd := range metaArgs {
+		for _, arg := range cmd.Args {
+			// BUILDKIT_ arguments configure the frontend
+			if strings.HasPrefix(arg.Key, "BUILDKIT_") {
+				continue
+			}
+			if _, ok := redeclared[arg.Key]; ok {
+				continue
+			}
+			used := false
+			re := regexp.MustCompile(`\$(` + regexp.QuoteMeta(arg.Key) + `\b|\{` + regexp.QuoteMeta(arg.Key) + `\b)`)
+			for _, u := range uses {
+				if re.MatchString(u) {
+					used = true
+					break
+				}
+			}
+			if !used {
+				warnings = append(warnings, Warning{
+					Rule:     RuleUnusedMetaArg,
+					Message:  fmt.Sprintf("global build argument %s is not used in any FROM or RUN --mount from and not redeclared in any stage", arg.Key),
+					Location: cmd.Location(),
+				})
+			}
+		}
+	}
+	return warnings
+}
+
+func checkShadowedCopies(stages []instructions.Stage) []Warning {
+	var warnings []Warning
+	for _, st := range stages {
+		// destinations copied since the last RUN, that could have used them
+		copies := map[string][]parser.Range{}
+		for _, cmd := range st.Commands {
+			var dest string
+			switch c := cmd.(type) {
+			case *instructions.CopyCommand:
+				dest = c.DestPath
+			case *instructions.AddCommand:
+				dest = c.DestPath
+			case *instructions.RunCommand, *instructions.WorkdirCommand, *instructions.OnbuildCommand:
+				copies = map[string][]parser.Range{}
+				continue
+			default:
+				continue
+			}
+			// only file destinations with an absolute path are comparable
+			if !path.IsAbs(dest) || strings.HasSuffix(dest, "/") || strings.Contains(dest, "$") {
+				continue
+			}
+			dest = path.Clean(dest)
+			if loc, ok := copies[dest]; ok {
+				warnings = append(warnings, Warning{
+					Rule:     RuleShadowedCopy,
+					Message:  fmt.Sprintf("%s is overwritten by a later instruction", dest),
+					Location: loc,
+				})
+			}
+			copies[dest] = cmd.Location()
+		}
+	}
+	return warnings
+}
+
+func checkStageNameCasing(stages []instructions.Stage) []Warning {
+	var warnings []Warning
+	for _, st := range stages {
+		if st.Name == "" {
+			continue
+		}
+		fields := strings.Fields(st.SourceCode)
+		for i, f := range fields {
+			if strings.EqualFold(f, "as") && i+1 < len(fields) {
+				if name := fields[i+1]; name != strings.ToLower(name) {
+					warnings = append(warnings, Warning{
+						Rule:     RuleStageNameCasing,
+						Message:  fmt.Sprintf("stage name %s should be lowercase", name),
+						Location: st.Location,
+					})
+				}
+				break
+			}
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,90 @@
+package linter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func lint(t *testing.T, df string, enabled ...string) []Warning {
+	ast, err := parser.Parse(strings.NewReader(df))
+	require.NoError(t, err)
+	stages, metaArgs, err := instructions.Parse(ast.AST)
+	require.NoError(t, err)
+	return Lint(stages, metaArgs, enabled)
+}
+
+func rules(warnings []Warning) []string {
+	var out []string
+	for _, w := range warnings {
+		out = append(out, w.Rule.Name)
+	}
+	return out
+}
+
+func TestLintClean(t *testing.T) {
+	warnings := lint(t, `ARG BASE=alpine@sha256:4edbd2beb5f78b1014028f4fbb99f3237d9561100b6881aabbf5acce2c4f9454
+FROM $BASE AS build
+COPY a /a
+RUN cat /a
+COPY b /a
+
+FROM build
+FROM scratch
+`)
+	require.Empty(t, warnings)
+}
+
+func TestLintUnpinnedBaseImage(t *testing.T) {
+	df := `FROM alpine:3.15 AS base
+FROM base
+FROM busybox@sha256:4edbd2beb5f78b1014028f4fbb99f3237d9561100b6881aabbf5acce2c4f9454
+`
+	warnings := lint(t, df, RuleUnpinnedBaseImage.Name)
+	require.Equal(t, []string{RuleUnpinnedBaseImage.Name}, rules(warnings))
+	require.Equal(t, 1, warnings[0].Location[0].Start.Line)
+
+	// the rule is opt-in
+	require.Empty(t, lint(t, df))
+}
+
+func TestValidateRules(t *testing.T) {
+	require.NoError(t, ValidateRules([]string{RuleUnpinnedBaseImage.Name}))
+	require.Error(t, ValidateRules([]string{"Unknown"}))
+}
+
+func TestLintUnusedMetaArg(t *testing.T) {
+	warnings := lint(t, `ARG UNUSED=1
+ARG REDECLARED=2
+ARG BUILDKIT_SYNTAX=foo
+ARG SRC=build
+FROM scratch AS build
+FROM scratch
+ARG REDECLARED
+RUN --mount=from=$SRC,target=/src ls
+`)
+	require.Equal(t, []string{RuleUnusedMetaArg.Name}, rules(warnings))
+	require.Contains(t, warnings[0].Message, "UNUSED")
+}
+
+func TestLintShadowedCopy(t *testing.T) {
+	warnings := lint(t, `FROM scratch
+COPY a /app/config
+COPY b /app/config
+COPY c /app/
+COPY d /app/
+`)
+	require.Equal(t, []string{RuleShadowedCopy.Name}, rules(warnings))
+	require.Equal(t, 2, warnings[0].Location[0].Start.Line)
+}
+
+func TestLintStageNameCasing(t *testing.T) {
+	warnings := lint(t, `FROM scratch AS Build
+FROM scratch as base
+`)
+	require.Equal(t, []string{RuleStageNameCasing.Name}, rules(warnings))
+	require.Contains(t, warnings[0].Message, "Build")
+}
@@ -233,6 +233,7 @@ func init() {
 		command.Expose:      parseStringsWhitespaceDelimited,
 		command.From:        parseStringsWhitespaceDelimited,
 		command.Healthcheck: parseHealthConfig,
+		command.Include:     parseStringsWhitespaceDelimited,
 		command.Label:       parseLabel,
 		command.Maintainer:  parseString,
 		command.Onbuild:     parseSubCommand,
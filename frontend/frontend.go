@@ -24,4 +24,6 @@ type SolveRequest = gw.SolveRequest
 
 type CacheOptionsEntry = gw.CacheOptionsEntry
 
+type FrontendBudget = gw.FrontendBudget
+
 type WarnOpts = gw.WarnOpts
@@ -3,10 +3,13 @@ package client
 import (
 	"context"
 	"io"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/moby/buildkit/client/llb"
 	"github.com/moby/buildkit/solver/pb"
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
 	"github.com/moby/buildkit/util/apicaps"
 	digest "github.com/opencontainers/go-digest"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
@@ -20,6 +23,17 @@ type Client interface {
 	Inputs(ctx context.Context) (map[string]llb.State, error)
 	NewContainer(ctx context.Context, req NewContainerRequest) (Container, error)
 	Warn(ctx context.Context, dgst digest.Digest, msg string, opts WarnOpts) error
+	// Prefetch starts pulling images and solving definitions that are likely
+	// needed by the build in the background. It returns without waiting for
+	// them and failures are not reported.
+	Prefetch(ctx context.Context, req PrefetchRequest) error
+}
+
+// PrefetchRequest contains the images and definitions to prefetch.
+type PrefetchRequest struct {
+	Images      []string
+	Platform    *pb.Platform
+	Definitions []*pb.Definition
 }
 
 // NewContainerRequest encapsulates the requirements for a client to define a
@@ -30,6 +44,19 @@ type NewContainerRequest struct {
 	ExtraHosts  []*pb.HostIP
 	Platform    *pb.Platform
 	Constraints *pb.WorkerConstraints
+	// PublishPorts publishes ports of the container on the host of the
+	// daemon. The network of the worker needs to support it, e.g. a network
+	// pool with the portmap plugin that allows the host ports.
+	PublishPorts []PortMapping
+}
+
+// PortMapping publishes a port of a container on the host of the daemon.
+type PortMapping struct {
+	HostPort      int32
+	ContainerPort int32
+	// Protocol is tcp or udp, tcp if unset.
+	Protocol string
+	HostIP   string
 }
 
 // Mount allows clients to specify a filesystem mount. A Reference to a
@@ -85,6 +112,13 @@ type Reference interface {
 	ReadFile(ctx context.Context, req ReadRequest) ([]byte, error)
 	StatFile(ctx context.Context, req StatRequest) (*fstypes.Stat, error)
 	ReadDir(ctx context.Context, req ReadDirRequest) ([]*fstypes.Stat, error)
+	// ReadFileStream returns a reader for the file that is read in chunks
+	// instead of being buffered in memory.
+	ReadFileStream(ctx context.Context, req ReadRequest) (io.ReadCloser, error)
+	// ReadDirRecursive calls fn for every file in the directory tree at the
+	// requested path as the entries are received. Paths of the entries are
+	// relative to the requested directory.
+	ReadDirRecursive(ctx context.Context, req ReadDirRequest, fn func(*fstypes.Stat) error) error
 }
 
 type ReadRequest struct {
@@ -114,6 +148,51 @@ type SolveRequest struct {
 	FrontendOpt    map[string]string
 	FrontendInputs map[string]*pb.Definition
 	CacheImports   []CacheOptionsEntry
+	// SourcePolicies deny or rewrite the sources of the definitions solved
+	// for the request, applied in order.
+	SourcePolicies []*spb.Policy
+	// InheritFrontendOpt adds the options of the calling frontend to the
+	// options of the frontend of the request, for the options the request
+	// doesn't set. The options selecting the calling frontend, like its
+	// source, are not inherited.
+	InheritFrontendOpt bool
+	// Budget limits the run of the frontend of the request.
+	Budget *FrontendBudget
+}
+
+// FrontendBudget limits the run of a frontend called by another frontend. The
+// resource limits can't exceed the limits of the calling frontend.
+type FrontendBudget struct {
+	// Timeout of the run of the frontend, including the solves of the
+	// results it evaluates
+	Timeout time.Duration
+	// CPU is the number of CPUs the frontend container can use, e.g. 1.5
+	CPU float64
+	// Memory is the memory limit of the frontend container in bytes
+	Memory int64
+	// Pids is the maximum number of processes of the frontend container
+	Pids int64
+}
+
+// InheritFrontendOpt returns opts with the options of the parent frontend
+// that opts doesn't set, except for the options selecting the parent
+// frontend.
+func InheritFrontendOpt(parent, opts map[string]string) map[string]string {
+	out := make(map[string]string, len(parent)+len(opts))
+	for k, v := range parent {
+		switch k {
+		case "source", "cmdline", "frontend.caps":
+			continue
+		}
+		if strings.HasPrefix(k, "gateway-") {
+			continue
+		}
+		out[k] = v
+	}
+	for k, v := range opts {
+		out[k] = v
+	}
+	return out
 }
 
 type CacheOptionsEntry struct {
@@ -134,6 +213,9 @@ type BuildOpts struct {
 	Product   string
 	LLBCaps   apicaps.CapSet
 	Caps      apicaps.CapSet
+	// MaxLLBSize is the maximum size in bytes of the definitions the frontend
+	// can solve, 0 if unlimited.
+	MaxLLBSize int64
 }
 
 type WarnOpts struct {
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/moby/buildkit/client/llb"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInheritFrontendOpt(t *testing.T) {
+	parent := map[string]string{
+		"source":        "docker/dockerfile",
+		"cmdline":       "foo",
+		"frontend.caps": "moby.buildkit.frontend.inputs",
+		"gateway-devel": "",
+		"build-arg:FOO": "bar",
+		"platform":      "linux/amd64",
+		"filename":      "Dockerfile",
+	}
+	opts := InheritFrontendOpt(parent, map[string]string{"source": "example.com/go-frontend", "filename": "go.mod"})
+	require.Equal(t, map[string]string{
+		"source":        "example.com/go-frontend",
+		"filename":      "go.mod",
+		"build-arg:FOO": "bar",
+		"platform":      "linux/amd64",
+	}, opts)
+}
+
+type testResolver struct {
+	dgst digest.Digest
+	dt   []byte
+}
+
+func (r testResolver) ResolveImageConfig(ctx context.Context, ref string, opt llb.ResolveImageConfigOpt) (digest.Digest, []byte, error) {
+	return r.dgst, r.dt, nil
+}
+
+func TestResolveImageMetadata(t *testing.T) {
+	dgst := digest.FromString("image")
+	bi := base64.StdEncoding.EncodeToString([]byte(`{"frontend":"dockerfile.v0","sources":[{"type":"docker-image","ref":"docker.io/library/alpine:3.19","pin":"sha256:abc"}]}`))
+	dt := []byte(`{"architecture":"amd64","os":"linux","config":{"Labels":{"org.opencontainers.image.source":"https://github.com/moby/buildkit"}},"moby.buildkit.buildinfo.v1":"` + bi + `"}`)
+
+	md, err := ResolveImageMetadata(context.TODO(), testResolver{dgst: dgst, dt: dt}, "example.com/base", llb.ResolveImageConfigOpt{})
+	require.NoError(t, err)
+	require.Equal(t, dgst, md.Digest)
+	require.Equal(t, "linux", md.Config.OS)
+	require.Equal(t, map[string]string{"org.opencontainers.image.source": "https://github.com/moby/buildkit"}, md.Labels())
+	require.NotNil(t, md.BuildInfo)
+	require.Equal(t, "dockerfile.v0", md.BuildInfo.Frontend)
+	require.Len(t, md.BuildInfo.Sources, 1)
+	require.Equal(t, "sha256:abc", md.BuildInfo.Sources[0].Pin)
+
+	md, err = ParseImageMetadata("example.com/base", dgst, []byte(`{"os":"linux"}`))
+	require.NoError(t, err)
+	require.Nil(t, md.BuildInfo)
+
+	_, err = ParseImageMetadata("example.com/base", dgst, []byte(`{"os":"linux","moby.buildkit.buildinfo.v1":"!"}`))
+	require.Error(t, err)
+}
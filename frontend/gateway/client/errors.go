@@ -0,0 +1,48 @@
+package client
+
+import (
+	"github.com/moby/buildkit/solver/errdefs"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/pkg/errors"
+)
+
+// NewContainerRequestFromSolveError returns the request for a container with
+// the mounts of the exec operation that failed with the solve error, in the
+// state they were in when the operation failed. The failed exec operation is
+// returned for starting processes with its environment.
+func NewContainerRequestFromSolveError(se *errdefs.SolveError) (NewContainerRequest, *pb.ExecOp, error) {
+	op := se.Solve.Op
+	if op == nil {
+		return NewContainerRequest{}, nil, errors.New("solve error has no operation")
+	}
+	opExec, ok := op.Op.(*pb.Op_Exec)
+	if !ok {
+		return NewContainerRequest{}, nil, errors.Errorf("failed operation is not an exec: %T", op.Op)
+	}
+	exec := opExec.Exec
+	if len(se.Solve.MountIDs) != len(exec.Mounts) {
+		return NewContainerRequest{}, nil, errors.Errorf("number of mounts does not match mount results %d %d", len(exec.Mounts), len(se.Solve.MountIDs))
+	}
+
+	mounts := make([]Mount, 0, len(exec.Mounts))
+	for i, m := range exec.Mounts {
+		mounts = append(mounts, Mount{
+			Selector:  m.Selector,
+			Dest:      m.Dest,
+			ResultID:  se.Solve.MountIDs[i],
+			Readonly:  m.Readonly,
+			MountType: m.MountType,
+			CacheOpt:  m.CacheOpt,
+			SecretOpt: m.SecretOpt,
+			SSHOpt:    m.SSHOpt,
+		})
+	}
+
+	return NewContainerRequest{
+		Mounts:      mounts,
+		NetMode:     exec.Network,
+		ExtraHosts:  exec.Meta.ExtraHosts,
+		Platform:    op.Platform,
+		Constraints: op.Constraints,
+	}, exec, nil
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/solver/errdefs"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewContainerRequestFromSolveError(t *testing.T) {
+	exec := &pb.ExecOp{
+		Meta: &pb.Meta{
+			Args: []string{"false"},
+			Cwd:  "/src",
+		},
+		Mounts: []*pb.Mount{
+			{Dest: "/", Input: 0, Output: 0},
+			{Dest: "/cache", Input: -1, Output: -1, MountType: pb.MountType_CACHE, CacheOpt: &pb.CacheOpt{ID: "foo"}},
+		},
+		Network: pb.NetMode_NONE,
+	}
+	se := &errdefs.SolveError{
+		Solve: errdefs.Solve{
+			Op:       &pb.Op{Op: &pb.Op_Exec{Exec: exec}},
+			MountIDs: []string{"root", "cache"},
+		},
+	}
+
+	req, op, err := NewContainerRequestFromSolveError(se)
+	require.NoError(t, err)
+	require.Equal(t, exec, op)
+	require.Equal(t, pb.NetMode_NONE, req.NetMode)
+	require.Len(t, req.Mounts, 2)
+	require.Equal(t, "root", req.Mounts[0].ResultID)
+	require.Equal(t, "/", req.Mounts[0].Dest)
+	require.Equal(t, "cache", req.Mounts[1].ResultID)
+	require.Equal(t, "foo", req.Mounts[1].CacheOpt.ID)
+
+	se.Solve.MountIDs = se.Solve.MountIDs[:1]
+	_, _, err = NewContainerRequestFromSolveError(se)
+	require.Error(t, err)
+
+	se.Solve.Op = &pb.Op{Op: &pb.Op_File{File: &pb.FileOp{}}}
+	_, _, err = NewContainerRequestFromSolveError(se)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not an exec")
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/moby/buildkit/client/llb"
+	binfotypes "github.com/moby/buildkit/util/buildinfo/types"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// ImageMetadata is the metadata of an image resolved by a frontend.
+type ImageMetadata struct {
+	// Ref is the reference of the image, as requested.
+	Ref string
+	// Digest is the digest the reference resolved to.
+	Digest digest.Digest
+	// Config is the parsed image config.
+	Config ocispecs.Image
+	// ConfigJSON is the raw image config, for the fields Config doesn't
+	// define.
+	ConfigJSON []byte
+	// BuildInfo is the build info embedded in the image config when the
+	// image was built by BuildKit, nil otherwise.
+	BuildInfo *binfotypes.BuildInfo
+}
+
+// Labels returns the labels of the image config.
+func (m *ImageMetadata) Labels() map[string]string {
+	return m.Config.Config.Labels
+}
+
+// ResolveImageMetadata resolves the config and the build info of the image.
+// It accepts a Client as well as any other image resolver.
+func ResolveImageMetadata(ctx context.Context, r llb.ImageMetaResolver, ref string, opt llb.ResolveImageConfigOpt) (*ImageMetadata, error) {
+	dgst, dt, err := r.ResolveImageConfig(ctx, ref, opt)
+	if err != nil {
+		return nil, err
+	}
+	return ParseImageMetadata(ref, dgst, dt)
+}
+
+// ParseImageMetadata returns the metadata of the image with the resolved
+// digest and config.
+func ParseImageMetadata(ref string, dgst digest.Digest, dt []byte) (*ImageMetadata, error) {
+	md := &ImageMetadata{
+		Ref:        ref,
+		Digest:     dgst,
+		ConfigJSON: dt,
+	}
+	if err := json.Unmarshal(dt, &md.Config); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse image config of %s", ref)
+	}
+	var config binfotypes.ImageConfig
+	if err := json.Unmarshal(dt, &config); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse image config of %s", ref)
+	}
+	if config.BuildInfo != "" {
+		dtbi, err := base64.StdEncoding.DecodeString(config.BuildInfo)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode build info of %s", ref)
+		}
+		var bi binfotypes.BuildInfo
+		if err := json.Unmarshal(dtbi, &bi); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse build info of %s", ref)
+		}
+		md.BuildInfo = &bi
+	}
+	return md, nil
+}
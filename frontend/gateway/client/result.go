@@ -2,8 +2,10 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"sync"
 
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
 	"github.com/pkg/errors"
 )
 
@@ -38,6 +40,36 @@ func (r *Result) AddRef(k string, ref Reference) {
 	r.mu.Unlock()
 }
 
+// AddAttestation attaches an attestation, like a SBOM or a provenance
+// statement, to the image of the platform ID. An empty ID is used for
+// single-platform results. Exporters supporting attestations write them as
+// artifact manifests referring to the image manifest.
+func (r *Result) AddAttestation(platformID string, att exptypes.Attestation) error {
+	if att.ArtifactType == "" {
+		return errors.New("attestation requires an artifact type")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Metadata == nil {
+		r.Metadata = map[string][]byte{}
+	}
+	k := exptypes.AttestationsKey(platformID)
+	var atts []exptypes.Attestation
+	if dt, ok := r.Metadata[k]; ok {
+		if err := json.Unmarshal(dt, &atts); err != nil {
+			return errors.Wrapf(err, "failed to parse attestations")
+		}
+	}
+	dt, err := json.Marshal(append(atts, att))
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal attestations")
+	}
+	r.Metadata[k] = dt
+	return nil
+}
+
 func (r *Result) SetRef(ref Reference) {
 	r.Ref = ref
 }
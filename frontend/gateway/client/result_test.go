@@ -0,0 +1,29 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultAddAttestation(t *testing.T) {
+	res := NewResult()
+	require.NoError(t, res.AddAttestation("", exptypes.Attestation{ArtifactType: "application/spdx+json", Data: []byte("sbom")}))
+	require.NoError(t, res.AddAttestation("", exptypes.Attestation{ArtifactType: "application/vnd.in-toto+json", Data: []byte("provenance")}))
+	require.NoError(t, res.AddAttestation("linux/arm64", exptypes.Attestation{ArtifactType: "application/spdx+json", Data: []byte("arm64")}))
+
+	var atts []exptypes.Attestation
+	require.NoError(t, json.Unmarshal(res.Metadata[exptypes.ExporterAttestations], &atts))
+	require.Len(t, atts, 2)
+	require.Equal(t, "sbom", string(atts[0].Data))
+	require.Equal(t, "application/vnd.in-toto+json", atts[1].ArtifactType)
+
+	atts = nil
+	require.NoError(t, json.Unmarshal(res.Metadata[exptypes.ExporterAttestations+"/linux/arm64"], &atts))
+	require.Len(t, atts, 1)
+	require.Equal(t, "arm64", string(atts[0].Data))
+
+	require.Error(t, res.AddAttestation("", exptypes.Attestation{Data: []byte("untyped")}))
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/moby/buildkit/util/bklog"
 
+	"github.com/docker/docker/pkg/idtools"
 	"github.com/moby/buildkit/cache"
 	"github.com/moby/buildkit/executor"
 	"github.com/moby/buildkit/frontend/gateway/client"
@@ -19,6 +20,7 @@ import (
 	"github.com/moby/buildkit/snapshot"
 	"github.com/moby/buildkit/solver/llbsolver/mounts"
 	opspb "github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/network"
 	"github.com/moby/buildkit/util/stack"
 	utilsystem "github.com/moby/buildkit/util/system"
 	"github.com/moby/buildkit/worker"
@@ -30,9 +32,11 @@ type NewContainerRequest struct {
 	ContainerID string
 	NetMode     opspb.NetMode
 	ExtraHosts  []executor.HostIP
-	Mounts      []Mount
-	Platform    *opspb.Platform
-	Constraints *opspb.WorkerConstraints
+	// PublishPorts are the ports of the container published on the host
+	PublishPorts []network.PortMapping
+	Mounts       []Mount
+	Platform     *opspb.Platform
+	Constraints  *opspb.WorkerConstraints
 }
 
 // Mount used for the gateway.Container is nearly identical to the client.Mount
@@ -54,14 +58,15 @@ func NewContainer(ctx context.Context, w worker.Worker, sm *session.Manager, g s
 		platform = *req.Platform
 	}
 	ctr := &gatewayContainer{
-		id:         req.ContainerID,
-		netMode:    req.NetMode,
-		extraHosts: req.ExtraHosts,
-		platform:   platform,
-		executor:   w.Executor(),
-		errGroup:   eg,
-		ctx:        ctx,
-		cancel:     cancel,
+		id:           req.ContainerID,
+		netMode:      req.NetMode,
+		extraHosts:   req.ExtraHosts,
+		publishPorts: req.PublishPorts,
+		platform:     platform,
+		executor:     w.Executor(),
+		errGroup:     eg,
+		ctx:          ctx,
+		cancel:       cancel,
 	}
 
 	var (
@@ -265,6 +270,9 @@ func PrepareMounts(ctx context.Context, mm *mounts.MountManager, cm cache.Manage
 			mws.Dest = dest
 			mws.Readonly = m.Readonly
 			mws.Selector = m.Selector
+			if m.MountType == opspb.MountType_CACHE && (m.CacheOpt.Uid != 0 || m.CacheOpt.Gid != 0) {
+				mws.Owner = &idtools.Identity{UID: int(m.CacheOpt.Uid), GID: int(m.CacheOpt.Gid)}
+			}
 			p.Mounts = append(p.Mounts, mws)
 		}
 	}
@@ -278,19 +286,20 @@ func PrepareMounts(ctx context.Context, mm *mounts.MountManager, cm cache.Manage
 }
 
 type gatewayContainer struct {
-	id         string
-	netMode    opspb.NetMode
-	extraHosts []executor.HostIP
-	platform   opspb.Platform
-	rootFS     executor.Mount
-	mounts     []executor.Mount
-	executor   executor.Executor
-	started    bool
-	errGroup   *errgroup.Group
-	mu         sync.Mutex
-	cleanup    []func() error
-	ctx        context.Context
-	cancel     func()
+	id           string
+	netMode      opspb.NetMode
+	extraHosts   []executor.HostIP
+	publishPorts []network.PortMapping
+	platform     opspb.Platform
+	rootFS       executor.Mount
+	mounts       []executor.Mount
+	executor     executor.Executor
+	started      bool
+	errGroup     *errgroup.Group
+	mu           sync.Mutex
+	cleanup      []func() error
+	ctx          context.Context
+	cancel       func()
 }
 
 func (gwCtr *gatewayContainer) Start(ctx context.Context, req client.StartRequest) (client.ContainerProcess, error) {
@@ -305,6 +314,7 @@ func (gwCtr *gatewayContainer) Start(ctx context.Context, req client.StartReques
 			Tty:          req.Tty,
 			NetMode:      gwCtr.netMode,
 			ExtraHosts:   gwCtr.extraHosts,
+			PublishPorts: gwCtr.publishPorts,
 			SecurityMode: req.SecurityMode,
 		},
 		Stdin:  req.Stdin,
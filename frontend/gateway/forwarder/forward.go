@@ -2,6 +2,7 @@ package forwarder
 
 import (
 	"context"
+	"io"
 	"sync"
 
 	cacheutil "github.com/moby/buildkit/cache/util"
@@ -18,6 +19,7 @@ import (
 	llberrdefs "github.com/moby/buildkit/solver/llbsolver/errdefs"
 	opspb "github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/apicaps"
+	"github.com/moby/buildkit/util/network"
 	"github.com/moby/buildkit/worker"
 	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
@@ -26,7 +28,10 @@ import (
 )
 
 func llbBridgeToGatewayClient(ctx context.Context, llbBridge frontend.FrontendLLBBridge, opts map[string]string, inputs map[string]*opspb.Definition, w worker.Infos, sid string, sm *session.Manager) (*bridgeClient, error) {
+	ctx, cancel := context.WithCancel(ctx)
 	return &bridgeClient{
+		ctx:               ctx,
+		cancel:            cancel,
 		opts:              opts,
 		inputs:            inputs,
 		FrontendLLBBridge: llbBridge,
@@ -40,6 +45,9 @@ func llbBridgeToGatewayClient(ctx context.Context, llbBridge frontend.FrontendLL
 
 type bridgeClient struct {
 	frontend.FrontendLLBBridge
+	// ctx is the context of the frontend, canceled on discard
+	ctx           context.Context
+	cancel        func()
 	mu            sync.Mutex
 	opts          map[string]string
 	inputs        map[string]*opspb.Definition
@@ -52,13 +60,18 @@ type bridgeClient struct {
 }
 
 func (c *bridgeClient) Solve(ctx context.Context, req client.SolveRequest) (*client.Result, error) {
+	frontendOpt := req.FrontendOpt
+	if req.InheritFrontendOpt {
+		frontendOpt = client.InheritFrontendOpt(c.opts, frontendOpt)
+	}
 	res, err := c.FrontendLLBBridge.Solve(ctx, frontend.SolveRequest{
 		Evaluate:       req.Evaluate,
 		Definition:     req.Definition,
 		Frontend:       req.Frontend,
-		FrontendOpt:    req.FrontendOpt,
+		FrontendOpt:    frontendOpt,
 		FrontendInputs: req.FrontendInputs,
 		CacheImports:   req.CacheImports,
+		Budget:         req.Budget,
 	}, c.sid)
 	if err != nil {
 		return nil, c.wrapSolveError(err)
@@ -204,6 +217,7 @@ func (c *bridgeClient) toFrontendResult(r *client.Result) (*frontend.Result, err
 }
 
 func (c *bridgeClient) discard(err error) {
+	c.cancel()
 	for id, workerRef := range c.workerRefByID {
 		workerRef.ImmutableRef.Release(context.TODO())
 		delete(c.workerRefByID, id)
@@ -221,6 +235,11 @@ func (c *bridgeClient) Warn(ctx context.Context, dgst digest.Digest, msg string,
 	return c.FrontendLLBBridge.Warn(ctx, dgst, msg, opts)
 }
 
+func (c *bridgeClient) Prefetch(ctx context.Context, req client.PrefetchRequest) error {
+	// prefetches outlive the request and are canceled with the frontend
+	return gateway.Prefetch(c.ctx, c.FrontendLLBBridge, c.sid, req)
+}
+
 func (c *bridgeClient) NewContainer(ctx context.Context, req client.NewContainerRequest) (client.Container, error) {
 	ctrReq := gateway.NewContainerRequest{
 		ContainerID: identity.NewID(),
@@ -281,6 +300,14 @@ func (c *bridgeClient) NewContainer(ctx context.Context, req client.NewContainer
 	if err != nil {
 		return nil, err
 	}
+	for _, p := range req.PublishPorts {
+		ctrReq.PublishPorts = append(ctrReq.PublishPorts, network.PortMapping{
+			HostPort:      p.HostPort,
+			ContainerPort: p.ContainerPort,
+			Protocol:      p.Protocol,
+			HostIP:        p.HostIP,
+		})
+	}
 
 	w, err := c.workers.GetDefault()
 	if err != nil {
@@ -342,6 +369,35 @@ func (r *ref) ReadDir(ctx context.Context, req client.ReadDirRequest) ([]*fstype
 	return cacheutil.ReadDir(ctx, m, newReq)
 }
 
+func (r *ref) ReadFileStream(ctx context.Context, req client.ReadRequest) (io.ReadCloser, error) {
+	m, err := r.getMountable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	newReq := cacheutil.ReadRequest{
+		Filename: req.Filename,
+	}
+	if r := req.Range; r != nil {
+		newReq.Range = &cacheutil.FileRange{
+			Offset: r.Offset,
+			Length: r.Length,
+		}
+	}
+	return cacheutil.ReadFileStream(ctx, m, newReq)
+}
+
+func (r *ref) ReadDirRecursive(ctx context.Context, req client.ReadDirRequest, fn func(*fstypes.Stat) error) error {
+	m, err := r.getMountable(ctx)
+	if err != nil {
+		return err
+	}
+	newReq := cacheutil.ReadDirRequest{
+		Path:           req.Path,
+		IncludePattern: req.IncludePattern,
+	}
+	return cacheutil.WalkDir(ctx, m, newReq, fn)
+}
+
 func (r *ref) StatFile(ctx context.Context, req client.StatRequest) (*fstypes.Stat, error) {
 	m, err := r.getMountable(ctx)
 	if err != nil {
@@ -39,6 +39,7 @@ import (
 	opspb "github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/apicaps"
 	"github.com/moby/buildkit/util/bklog"
+	binfotypes "github.com/moby/buildkit/util/buildinfo/types"
 	"github.com/moby/buildkit/util/grpcerrors"
 	"github.com/moby/buildkit/util/stack"
 	"github.com/moby/buildkit/util/tracing"
@@ -47,6 +48,7 @@ import (
 	digest "github.com/opencontainers/go-digest"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
+	fstypes "github.com/tonistiigi/fsutil/types"
 	"golang.org/x/net/http2"
 	"golang.org/x/sync/errgroup"
 	spb "google.golang.org/genproto/googleapis/rpc/status"
@@ -62,14 +64,19 @@ const (
 	keyDevel  = "gateway-devel"
 )
 
-func NewGatewayFrontend(w worker.Infos) frontend.Frontend {
+// NewGatewayFrontend returns the frontend running frontend images. The
+// restrictions apply to the untrusted images, nil runs all of them without
+// restrictions.
+func NewGatewayFrontend(w worker.Infos, r *Restrictions) frontend.Frontend {
 	return &gatewayFrontend{
-		workers: w,
+		workers:      w,
+		restrictions: r,
 	}
 }
 
 type gatewayFrontend struct {
-	workers worker.Infos
+	workers      worker.Infos
+	restrictions *Restrictions
 }
 
 func filterPrefix(opts map[string]string, pfx string) map[string]string {
@@ -95,6 +102,8 @@ func (gf *gatewayFrontend) Solve(ctx context.Context, llbBridge frontend.Fronten
 	var readonly bool // TODO: try to switch to read-only by default.
 
 	var frontendDef *opspb.Definition
+	// frontendImage is recorded in the build info of the result
+	var frontendImage *binfotypes.Source
 
 	if isDevel {
 		devRes, err := llbBridge.Solve(ctx,
@@ -153,6 +162,11 @@ func (gf *gatewayFrontend) Solve(ctx context.Context, llbBridge frontend.Fronten
 		}
 
 		if dgst != "" {
+			frontendImage = &binfotypes.Source{
+				Type: binfotypes.SourceTypeDockerImage,
+				Ref:  reference.TagNameOnly(sourceRef).String(),
+				Pin:  dgst.String(),
+			}
 			sourceRef, err = reference.WithDigest(sourceRef, dgst)
 			if err != nil {
 				return nil, err
@@ -229,6 +243,8 @@ func (gf *gatewayFrontend) Solve(ctx context.Context, llbBridge frontend.Fronten
 		Args:           args,
 		Cwd:            cwd,
 		ReadonlyRootFS: readonly,
+		// set by the budget of the calling frontend
+		ResourceLimits: executor.ResourceLimitsFromContext(ctx),
 	}
 
 	if v, ok := img.Config.Labels["moby.buildkit.frontend.network.none"]; ok {
@@ -236,6 +252,8 @@ func (gf *gatewayFrontend) Solve(ctx context.Context, llbBridge frontend.Fronten
 			meta.NetMode = opspb.NetMode_NONE
 		}
 	}
+	restrictions := gf.restrictions.forSource(source, isDevel)
+	meta.NetMode = restrictions.netMode(meta.NetMode)
 
 	curCaps := getCaps(img.Config.Labels["moby.buildkit.frontend.caps"])
 	addCapsForKnownFrontends(curCaps, mfstDigest)
@@ -250,7 +268,7 @@ func (gf *gatewayFrontend) Solve(ctx context.Context, llbBridge frontend.Fronten
 		}
 	}
 
-	lbf, ctx, err := serveLLBBridgeForwarder(ctx, llbBridge, gf.workers, inputs, sid, sm)
+	lbf, ctx, err := serveLLBBridgeForwarder(ctx, llbBridge, gf.workers, inputs, sid, sm, restrictions)
 	defer lbf.conn.Close() //nolint
 	if err != nil {
 		return nil, err
@@ -291,7 +309,19 @@ func (gf *gatewayFrontend) Solve(ctx context.Context, llbBridge frontend.Fronten
 		lbf.mu.Unlock()
 	}
 
-	return lbf.Result()
+	res, err := lbf.Result()
+	if err != nil || res == nil || frontendImage == nil {
+		return res, err
+	}
+	dt, err = json.Marshal(frontendImage)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal frontend image")
+	}
+	if res.Metadata == nil {
+		res.Metadata = map[string][]byte{}
+	}
+	res.Metadata[exptypes.ExporterFrontendImage] = dt
+	return res, nil
 }
 
 func metadataMount(def *opspb.Definition) (*executor.Mount, func(), error) {
@@ -424,9 +454,10 @@ func newBridgeForwarder(ctx context.Context, llbBridge frontend.FrontendLLBBridg
 	return lbf
 }
 
-func serveLLBBridgeForwarder(ctx context.Context, llbBridge frontend.FrontendLLBBridge, workers worker.Infos, inputs map[string]*opspb.Definition, sid string, sm *session.Manager) (*llbBridgeForwarder, context.Context, error) {
+func serveLLBBridgeForwarder(ctx context.Context, llbBridge frontend.FrontendLLBBridge, workers worker.Infos, inputs map[string]*opspb.Definition, sid string, sm *session.Manager, restrictions *Restrictions) (*llbBridgeForwarder, context.Context, error) {
 	ctx, cancel := context.WithCancel(ctx)
 	lbf := newBridgeForwarder(ctx, llbBridge, workers, inputs, sid, sm)
+	lbf.restrictions = restrictions
 	server := grpc.NewServer(grpc.UnaryInterceptor(grpcerrors.UnaryServerInterceptor), grpc.StreamInterceptor(grpcerrors.StreamServerInterceptor))
 	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
 	pb.RegisterLLBBridgeServer(server, lbf)
@@ -524,6 +555,8 @@ type llbBridgeForwarder struct {
 	*pipe
 	ctrs   map[string]gwclient.Container
 	ctrsMu sync.Mutex
+	// restrictions of the frontend, nil if it is trusted
+	restrictions *Restrictions
 }
 
 func (lbf *llbBridgeForwarder) ResolveImageConfig(ctx context.Context, req *pb.ResolveImageConfigRequest) (*pb.ResolveImageConfigResponse, error) {
@@ -623,6 +656,9 @@ func (lbf *llbBridgeForwarder) Solve(ctx context.Context, req *pb.SolveRequest)
 	if err := translateLegacySolveRequest(req); err != nil {
 		return nil, err
 	}
+	if err := lbf.restrictions.restrictSolve(req); err != nil {
+		return nil, err
+	}
 	var cacheImports []frontend.CacheOptionsEntry
 	for _, e := range req.CacheImports {
 		cacheImports = append(cacheImports, frontend.CacheOptionsEntry{
@@ -632,6 +668,19 @@ func (lbf *llbBridgeForwarder) Solve(ctx context.Context, req *pb.SolveRequest)
 	}
 
 	ctx = tracing.ContextWithSpanFromContext(ctx, lbf.callCtx)
+	var budget *frontend.FrontendBudget
+	if b := req.Budget; b != nil {
+		budget = &frontend.FrontendBudget{
+			Timeout: time.Duration(b.Timeout),
+			CPU:     b.Cpu,
+			Memory:  b.Memory,
+			Pids:    b.Pids,
+		}
+	}
+	// the frontends called by the frontend can't exceed its resource limits
+	if limits := executor.ResourceLimitsFromContext(lbf.callCtx); limits != nil {
+		ctx = executor.WithResourceLimits(ctx, limits)
+	}
 	res, err := lbf.llbBridge.Solve(ctx, frontend.SolveRequest{
 		Evaluate:       req.Evaluate,
 		Definition:     req.Definition,
@@ -639,6 +688,7 @@ func (lbf *llbBridgeForwarder) Solve(ctx context.Context, req *pb.SolveRequest)
 		FrontendOpt:    req.FrontendOpt,
 		FrontendInputs: req.FrontendInputs,
 		CacheImports:   cacheImports,
+		Budget:         budget,
 	}, lbf.sid)
 	if err != nil {
 		return nil, lbf.wrapSolveError(err)
@@ -778,6 +828,10 @@ func (lbf *llbBridgeForwarder) ReadFile(ctx context.Context, req *pb.ReadFileReq
 
 	dt, err := cacheutil.ReadFile(ctx, m, newReq)
 	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// the frontends can tell the missing files from the other errors
+			err = grpcerrors.WrapCode(err, codes.NotFound)
+		}
 		return nil, lbf.wrapSolveError(err)
 	}
 
@@ -827,6 +881,97 @@ func (lbf *llbBridgeForwarder) StatFile(ctx context.Context, req *pb.StatFileReq
 	return &pb.StatFileResponse{Stat: st}, nil
 }
 
+const (
+	// readFileStreamChunkSize is the size of the chunks sent by ReadFileStream
+	readFileStreamChunkSize = 1 << 20
+	// readDirRecursiveBatchSize is the number of entries sent in a single
+	// message by ReadDirRecursive
+	readDirRecursiveBatchSize = 256
+)
+
+func (lbf *llbBridgeForwarder) ReadFileStream(req *pb.ReadFileRequest, srv pb.LLBBridge_ReadFileStreamServer) error {
+	ctx := tracing.ContextWithSpanFromContext(srv.Context(), lbf.callCtx)
+
+	ref, err := lbf.getImmutableRef(ctx, req.Ref, req.FilePath)
+	if err != nil {
+		return err
+	}
+
+	newReq := cacheutil.ReadRequest{
+		Filename: req.FilePath,
+	}
+	if r := req.Range; r != nil {
+		newReq.Range = &cacheutil.FileRange{
+			Offset: int(r.Offset),
+			Length: int(r.Length),
+		}
+	}
+
+	m, err := ref.Mount(ctx, true, session.NewGroup(lbf.sid))
+	if err != nil {
+		return err
+	}
+
+	rc, err := cacheutil.ReadFileStream(ctx, m, newReq)
+	if err != nil {
+		return lbf.wrapSolveError(err)
+	}
+	defer rc.Close()
+
+	buf := make([]byte, readFileStreamChunkSize)
+	for {
+		n, err := io.ReadFull(rc, buf)
+		if n > 0 {
+			if err := srv.Send(&pb.ReadFileResponse{Data: buf[:n]}); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+}
+
+func (lbf *llbBridgeForwarder) ReadDirRecursive(req *pb.ReadDirRequest, srv pb.LLBBridge_ReadDirRecursiveServer) error {
+	ctx := tracing.ContextWithSpanFromContext(srv.Context(), lbf.callCtx)
+
+	ref, err := lbf.getImmutableRef(ctx, req.Ref, req.DirPath)
+	if err != nil {
+		return err
+	}
+
+	newReq := cacheutil.ReadDirRequest{
+		Path:           req.DirPath,
+		IncludePattern: req.IncludePattern,
+	}
+	m, err := ref.Mount(ctx, true, session.NewGroup(lbf.sid))
+	if err != nil {
+		return err
+	}
+
+	entries := make([]*fstypes.Stat, 0, readDirRecursiveBatchSize)
+	if err := cacheutil.WalkDir(ctx, m, newReq, func(st *fstypes.Stat) error {
+		entries = append(entries, st)
+		if len(entries) < readDirRecursiveBatchSize {
+			return nil
+		}
+		if err := srv.Send(&pb.ReadDirResponse{Entries: entries}); err != nil {
+			return err
+		}
+		entries = entries[:0]
+		return nil
+	}); err != nil {
+		return lbf.wrapSolveError(err)
+	}
+	if len(entries) > 0 {
+		return srv.Send(&pb.ReadDirResponse{Entries: entries})
+	}
+	return nil
+}
+
 func (lbf *llbBridgeForwarder) Ping(context.Context, *pb.PingRequest) (*pb.PongResponse, error) {
 	workers := lbf.workers.WorkerInfos()
 	pbWorkers := make([]*apitypes.WorkerRecord, 0, len(workers))
@@ -838,10 +983,15 @@ func (lbf *llbBridgeForwarder) Ping(context.Context, *pb.PingRequest) (*pb.PongR
 		})
 	}
 
+	var maxLLBSize int64
+	if lbf.restrictions != nil {
+		maxLLBSize = lbf.restrictions.MaxLLBSize
+	}
 	return &pb.PongResponse{
-		FrontendAPICaps: pb.Caps.All(),
+		FrontendAPICaps: lbf.restrictions.caps(),
 		Workers:         pbWorkers,
 		LLBCaps:         opspb.Caps.All(),
+		MaxLLBSize:      maxLLBSize,
 	}, nil
 }
 
@@ -902,9 +1052,15 @@ func (lbf *llbBridgeForwarder) Inputs(ctx context.Context, in *pb.InputsRequest)
 
 func (lbf *llbBridgeForwarder) NewContainer(ctx context.Context, in *pb.NewContainerRequest) (_ *pb.NewContainerResponse, err error) {
 	bklog.G(ctx).Debugf("|<--- NewContainer %s", in.ContainerID)
+	if err := lbf.restrictions.checkMounts(in.Mounts); err != nil {
+		return nil, err
+	}
+	if err := lbf.restrictions.checkPublishPorts(in.PublishPorts); err != nil {
+		return nil, err
+	}
 	ctrReq := NewContainerRequest{
 		ContainerID: in.ContainerID,
-		NetMode:     in.Network,
+		NetMode:     lbf.restrictions.netMode(in.Network),
 		Platform:    in.Platform,
 		Constraints: in.Constraints,
 	}
@@ -958,6 +1114,7 @@ func (lbf *llbBridgeForwarder) NewContainer(ctx context.Context, in *pb.NewConta
 	if err != nil {
 		return nil, stack.Enable(err)
 	}
+	ctrReq.PublishPorts = ParsePortMappings(in.PublishPorts)
 
 	ctr, err := NewContainer(context.Background(), w, lbf.sm, group, ctrReq)
 	if err != nil {
@@ -1006,6 +1163,27 @@ func (lbf *llbBridgeForwarder) Warn(ctx context.Context, in *pb.WarnRequest) (*p
 	return &pb.WarnResponse{}, nil
 }
 
+func (lbf *llbBridgeForwarder) Prefetch(ctx context.Context, in *pb.PrefetchRequest) (*pb.PrefetchResponse, error) {
+	for i, def := range in.Definitions {
+		def, err := lbf.restrictions.restrictDefinition(def)
+		if err != nil {
+			return nil, err
+		}
+		in.Definitions[i] = def
+	}
+	// prefetches outlive the request and are canceled with the frontend
+	ctx = tracing.ContextWithSpanFromContext(lbf.callCtx, ctx)
+	err := Prefetch(ctx, lbf.llbBridge, lbf.sid, gwclient.PrefetchRequest{
+		Images:      in.Images,
+		Platform:    in.Platform,
+		Definitions: in.Definitions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PrefetchResponse{}, nil
+}
+
 type processIO struct {
 	id       string
 	mu       sync.Mutex
@@ -59,15 +59,16 @@ func New(ctx context.Context, opts map[string]string, session, product string, c
 	}
 
 	return &grpcClient{
-		client:    c,
-		opts:      opts,
-		sessionID: session,
-		workers:   w,
-		product:   product,
-		caps:      pb.Caps.CapSet(resp.FrontendAPICaps),
-		llbCaps:   opspb.Caps.CapSet(resp.LLBCaps),
-		requests:  map[string]*pb.SolveRequest{},
-		execMsgs:  newMessageForwarder(ctx, c),
+		client:     c,
+		opts:       opts,
+		sessionID:  session,
+		workers:    w,
+		product:    product,
+		caps:       pb.Caps.CapSet(resp.FrontendAPICaps),
+		llbCaps:    opspb.Caps.CapSet(resp.LLBCaps),
+		maxLLBSize: resp.MaxLLBSize,
+		requests:   map[string]*pb.SolveRequest{},
+		execMsgs:   newMessageForwarder(ctx, c),
 	}, nil
 }
 
@@ -275,8 +276,11 @@ type grpcClient struct {
 	workers   []client.WorkerInfo
 	caps      apicaps.CapSet
 	llbCaps   apicaps.CapSet
-	requests  map[string]*pb.SolveRequest
-	execMsgs  *messageForwarder
+	// maxLLBSize is the maximum size of the definitions the frontend can
+	// solve, 0 if unlimited
+	maxLLBSize int64
+	requests   map[string]*pb.SolveRequest
+	execMsgs   *messageForwarder
 }
 
 func (c *grpcClient) requestForRef(ref client.Reference) (*pb.SolveRequest, error) {
@@ -313,6 +317,18 @@ func (c *grpcClient) Warn(ctx context.Context, dgst digest.Digest, msg string, o
 	return err
 }
 
+func (c *grpcClient) Prefetch(ctx context.Context, req client.PrefetchRequest) error {
+	if err := c.caps.Supports(pb.CapGatewayPrefetch); err != nil {
+		return err
+	}
+	_, err := c.client.Prefetch(ctx, &pb.PrefetchRequest{
+		Images:      req.Images,
+		Platform:    req.Platform,
+		Definitions: req.Definitions,
+	})
+	return err
+}
+
 func (c *grpcClient) Solve(ctx context.Context, creq client.SolveRequest) (res *client.Result, err error) {
 	if creq.Definition != nil {
 		for _, md := range creq.Definition.Metadata {
@@ -329,6 +345,22 @@ func (c *grpcClient) Solve(ctx context.Context, creq client.SolveRequest) (res *
 		// new API (CapImportCaches)
 		cacheImports []*pb.CacheOptionsEntry
 	)
+	if creq.InheritFrontendOpt {
+		creq.FrontendOpt = client.InheritFrontendOpt(c.opts, creq.FrontendOpt)
+	}
+	var budget *pb.FrontendBudget
+	if b := creq.Budget; b != nil {
+		if err := c.caps.Supports(pb.CapGatewaySolveFrontendBudget); err != nil {
+			return nil, err
+		}
+		budget = &pb.FrontendBudget{
+			Timeout: int64(b.Timeout),
+			Cpu:     b.CPU,
+			Memory:  b.Memory,
+			Pids:    b.Pids,
+		}
+	}
+
 	supportCapImportCaches := c.caps.Supports(pb.CapImportCaches) == nil
 	for _, im := range creq.CacheImports {
 		if !supportCapImportCaches && im.Type == "registry" {
@@ -370,6 +402,7 @@ func (c *grpcClient) Solve(ctx context.Context, creq client.SolveRequest) (res *
 		ImportCacheRefsDeprecated: legacyRegistryCacheImports,
 		// new API
 		CacheImports: cacheImports,
+		Budget:       budget,
 	}
 
 	// backwards compatibility with inline return
@@ -481,12 +514,13 @@ func (c *grpcClient) ResolveImageConfig(ctx context.Context, ref string, opt llb
 
 func (c *grpcClient) BuildOpts() client.BuildOpts {
 	return client.BuildOpts{
-		Opts:      c.opts,
-		SessionID: c.sessionID,
-		Workers:   c.workers,
-		Product:   c.product,
-		LLBCaps:   c.llbCaps,
-		Caps:      c.caps,
+		Opts:       c.opts,
+		SessionID:  c.sessionID,
+		Workers:    c.workers,
+		Product:    c.product,
+		LLBCaps:    c.llbCaps,
+		Caps:       c.caps,
+		MaxLLBSize: c.maxLLBSize,
 	}
 }
 
@@ -759,14 +793,30 @@ func (c *grpcClient) NewContainer(ctx context.Context, req client.NewContainerRe
 		})
 	}
 
+	var ports []*pb.PortMapping
+	if len(req.PublishPorts) > 0 {
+		if err := c.caps.Supports(pb.CapGatewayExecPublishPorts); err != nil {
+			return nil, err
+		}
+		for _, p := range req.PublishPorts {
+			ports = append(ports, &pb.PortMapping{
+				HostPort:      p.HostPort,
+				ContainerPort: p.ContainerPort,
+				Protocol:      p.Protocol,
+				HostIP:        p.HostIP,
+			})
+		}
+	}
+
 	bklog.G(ctx).Debugf("|---> NewContainer %s", id)
 	_, err = c.client.NewContainer(ctx, &pb.NewContainerRequest{
-		ContainerID: id,
-		Mounts:      mounts,
-		Platform:    req.Platform,
-		Constraints: req.Constraints,
-		Network:     req.NetMode,
-		ExtraHosts:  req.ExtraHosts,
+		ContainerID:  id,
+		Mounts:       mounts,
+		Platform:     req.Platform,
+		Constraints:  req.Constraints,
+		Network:      req.NetMode,
+		ExtraHosts:   req.ExtraHosts,
+		PublishPorts: ports,
 	})
 	if err != nil {
 		return nil, err
@@ -1067,6 +1117,82 @@ func (r *reference) ReadDir(ctx context.Context, req client.ReadDirRequest) ([]*
 	return resp.Entries, nil
 }
 
+func (r *reference) ReadFileStream(ctx context.Context, req client.ReadRequest) (io.ReadCloser, error) {
+	if err := r.c.caps.Supports(pb.CapReadFileStream); err != nil {
+		return nil, err
+	}
+	rfr := &pb.ReadFileRequest{FilePath: req.Filename, Ref: r.id}
+	if r := req.Range; r != nil {
+		rfr.Range = &pb.FileRange{
+			Offset: int64(r.Offset),
+			Length: int64(r.Length),
+		}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := r.c.client.ReadFileStream(ctx, rfr)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &fileStreamReader{stream: stream, cancel: cancel}, nil
+}
+
+// fileStreamReader reads the chunks of a file sent by ReadFileStream.
+type fileStreamReader struct {
+	stream pb.LLBBridge_ReadFileStreamClient
+	cancel func()
+	buf    []byte
+}
+
+func (r *fileStreamReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		resp, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = resp.Data
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *fileStreamReader) Close() error {
+	r.cancel()
+	return nil
+}
+
+func (r *reference) ReadDirRecursive(ctx context.Context, req client.ReadDirRequest, fn func(*fstypes.Stat) error) error {
+	if err := r.c.caps.Supports(pb.CapReadDirRecursive); err != nil {
+		return err
+	}
+	rdr := &pb.ReadDirRequest{
+		DirPath:        req.Path,
+		IncludePattern: req.IncludePattern,
+		Ref:            r.id,
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := r.c.client.ReadDirRecursive(ctx, rdr)
+	if err != nil {
+		return err
+	}
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, st := range resp.Entries {
+			if err := fn(st); err != nil {
+				return err
+			}
+		}
+	}
+}
+
 func (r *reference) StatFile(ctx context.Context, req client.StatRequest) (*fstypes.Stat, error) {
 	if err := r.c.caps.Supports(pb.CapStatFile); err != nil {
 		return nil, err
@@ -58,6 +58,45 @@ const (
 
 	// CapGatewayWarnings is the capability to log warnings from frontend
 	CapGatewayWarnings apicaps.CapID = "gateway.warnings"
+
+	// CapReadFileStream is the capability to read a file from a reference in
+	// chunks without buffering it in memory
+	CapReadFileStream apicaps.CapID = "readfile.stream"
+
+	// CapReadDirRecursive is the capability to walk a directory tree of a
+	// reference
+	CapReadDirRecursive apicaps.CapID = "readdir.recursive"
+
+	// CapReadFileNotFound is the capability to return NotFound errors when
+	// reading files that don't exist
+	CapReadFileNotFound apicaps.CapID = "readfile.notfound"
+
+	// CapGatewayPrefetch is the capability to start pulling images and solving
+	// definitions that are likely needed by the build in the background
+	CapGatewayPrefetch apicaps.CapID = "gateway.prefetch"
+
+	// CapGatewayExecPublishPorts is the capability to publish ports of the
+	// gateway containers on the host of the daemon
+	CapGatewayExecPublishPorts apicaps.CapID = "gateway.exec.publishports"
+
+	// CapGatewayNetwork is the capability of the frontend and of the
+	// containers it creates through the gateway to access the network. The
+	// daemon disables it for untrusted frontends.
+	CapGatewayNetwork apicaps.CapID = "gateway.network"
+
+	// CapGatewaySecrets is the capability to use secrets in the definitions
+	// solved by the frontend and in the containers it creates. The daemon
+	// disables it for untrusted frontends.
+	CapGatewaySecrets apicaps.CapID = "gateway.secrets"
+
+	// CapGatewaySolveFrontend is the capability to solve requests of other
+	// frontends. The daemon disables it for untrusted frontends, as it can't
+	// check the definitions of the other frontends.
+	CapGatewaySolveFrontend apicaps.CapID = "gateway.solve.frontend"
+
+	// CapGatewaySolveFrontendBudget is the capability to limit the time and
+	// the resources of the frontends solved by a frontend
+	CapGatewaySolveFrontendBudget apicaps.CapID = "gateway.solve.frontend.budget"
 )
 
 func init() {
@@ -200,4 +239,67 @@ func init() {
 		Enabled: true,
 		Status:  apicaps.CapStatusExperimental,
 	})
+
+	Caps.Init(apicaps.Cap{
+		ID:      CapReadFileStream,
+		Name:    "read file stream",
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
+	Caps.Init(apicaps.Cap{
+		ID:      CapReadDirRecursive,
+		Name:    "read dir recursive",
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
+	Caps.Init(apicaps.Cap{
+		ID:      CapReadFileNotFound,
+		Name:    "read file not found errors",
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
+	Caps.Init(apicaps.Cap{
+		ID:      CapGatewayPrefetch,
+		Name:    "prefetch",
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
+	Caps.Init(apicaps.Cap{
+		ID:      CapGatewayExecPublishPorts,
+		Name:    "publish ports",
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
+	Caps.Init(apicaps.Cap{
+		ID:      CapGatewayNetwork,
+		Name:    "frontend network access",
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
+	Caps.Init(apicaps.Cap{
+		ID:      CapGatewaySecrets,
+		Name:    "frontend secrets access",
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
+	Caps.Init(apicaps.Cap{
+		ID:      CapGatewaySolveFrontend,
+		Name:    "solve with other frontends",
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
+	Caps.Init(apicaps.Cap{
+		ID:      CapGatewaySolveFrontendBudget,
+		Name:    "frontend budgets",
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
 }
@@ -5,6 +5,7 @@ package moby_buildkit_v1_frontend
 
 import (
 	context "context"
+	encoding_binary "encoding/binary"
 	fmt "fmt"
 	rpc "github.com/gogo/googleapis/google/rpc"
 	_ "github.com/gogo/protobuf/gogoproto"
@@ -621,11 +622,14 @@ type SolveRequest struct {
 	// apicaps:CapImportCaches
 	CacheImports []*CacheOptionsEntry `protobuf:"bytes,12,rep,name=CacheImports,proto3" json:"CacheImports,omitempty"`
 	// apicaps:CapFrontendInputs
-	FrontendInputs       map[string]*pb.Definition `protobuf:"bytes,13,rep,name=FrontendInputs,proto3" json:"FrontendInputs,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	Evaluate             bool                      `protobuf:"varint,14,opt,name=Evaluate,proto3" json:"Evaluate,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}                  `json:"-"`
-	XXX_unrecognized     []byte                    `json:"-"`
-	XXX_sizecache        int32                     `json:"-"`
+	FrontendInputs map[string]*pb.Definition `protobuf:"bytes,13,rep,name=FrontendInputs,proto3" json:"FrontendInputs,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Evaluate       bool                      `protobuf:"varint,14,opt,name=Evaluate,proto3" json:"Evaluate,omitempty"`
+	// budget limits the run of the frontend of the request.
+	// apicaps:CapGatewaySolveFrontendBudget
+	Budget               *FrontendBudget `protobuf:"bytes,15,opt,name=budget,proto3" json:"budget,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
 }
 
 func (m *SolveRequest) Reset()         { *m = SolveRequest{} }
@@ -738,6 +742,89 @@ func (m *SolveRequest) GetEvaluate() bool {
 	return false
 }
 
+func (m *SolveRequest) GetBudget() *FrontendBudget {
+	if m != nil {
+		return m.Budget
+	}
+	return nil
+}
+
+// FrontendBudget limits the run of a frontend called by another frontend.
+type FrontendBudget struct {
+	// timeout of the run of the frontend in nanoseconds
+	Timeout int64 `protobuf:"varint,1,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	// cpu is the number of CPUs the frontend container can use
+	Cpu float64 `protobuf:"fixed64,2,opt,name=cpu,proto3" json:"cpu,omitempty"`
+	// memory is the memory limit of the frontend container in bytes
+	Memory int64 `protobuf:"varint,3,opt,name=memory,proto3" json:"memory,omitempty"`
+	// pids is the maximum number of processes of the frontend container
+	Pids                 int64    `protobuf:"varint,4,opt,name=pids,proto3" json:"pids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FrontendBudget) Reset()         { *m = FrontendBudget{} }
+func (m *FrontendBudget) String() string { return proto.CompactTextString(m) }
+func (*FrontendBudget) ProtoMessage()    {}
+func (*FrontendBudget) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f1a937782ebbded5, []int{11}
+}
+func (m *FrontendBudget) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *FrontendBudget) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_FrontendBudget.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *FrontendBudget) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FrontendBudget.Merge(m, src)
+}
+func (m *FrontendBudget) XXX_Size() int {
+	return m.Size()
+}
+func (m *FrontendBudget) XXX_DiscardUnknown() {
+	xxx_messageInfo_FrontendBudget.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FrontendBudget proto.InternalMessageInfo
+
+func (m *FrontendBudget) GetTimeout() int64 {
+	if m != nil {
+		return m.Timeout
+	}
+	return 0
+}
+
+func (m *FrontendBudget) GetCpu() float64 {
+	if m != nil {
+		return m.Cpu
+	}
+	return 0
+}
+
+func (m *FrontendBudget) GetMemory() int64 {
+	if m != nil {
+		return m.Memory
+	}
+	return 0
+}
+
+func (m *FrontendBudget) GetPids() int64 {
+	if m != nil {
+		return m.Pids
+	}
+	return 0
+}
+
 // CacheOptionsEntry corresponds to the control.CacheOptionsEntry
 type CacheOptionsEntry struct {
 	Type                 string            `protobuf:"bytes,1,opt,name=Type,proto3" json:"Type,omitempty"`
@@ -751,7 +838,7 @@ func (m *CacheOptionsEntry) Reset()         { *m = CacheOptionsEntry{} }
 func (m *CacheOptionsEntry) String() string { return proto.CompactTextString(m) }
 func (*CacheOptionsEntry) ProtoMessage()    {}
 func (*CacheOptionsEntry) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{11}
+	return fileDescriptor_f1a937782ebbded5, []int{12}
 }
 func (m *CacheOptionsEntry) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -808,7 +895,7 @@ func (m *SolveResponse) Reset()         { *m = SolveResponse{} }
 func (m *SolveResponse) String() string { return proto.CompactTextString(m) }
 func (*SolveResponse) ProtoMessage()    {}
 func (*SolveResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{12}
+	return fileDescriptor_f1a937782ebbded5, []int{13}
 }
 func (m *SolveResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -864,7 +951,7 @@ func (m *ReadFileRequest) Reset()         { *m = ReadFileRequest{} }
 func (m *ReadFileRequest) String() string { return proto.CompactTextString(m) }
 func (*ReadFileRequest) ProtoMessage()    {}
 func (*ReadFileRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{13}
+	return fileDescriptor_f1a937782ebbded5, []int{14}
 }
 func (m *ReadFileRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -926,7 +1013,7 @@ func (m *FileRange) Reset()         { *m = FileRange{} }
 func (m *FileRange) String() string { return proto.CompactTextString(m) }
 func (*FileRange) ProtoMessage()    {}
 func (*FileRange) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{14}
+	return fileDescriptor_f1a937782ebbded5, []int{15}
 }
 func (m *FileRange) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -980,7 +1067,7 @@ func (m *ReadFileResponse) Reset()         { *m = ReadFileResponse{} }
 func (m *ReadFileResponse) String() string { return proto.CompactTextString(m) }
 func (*ReadFileResponse) ProtoMessage()    {}
 func (*ReadFileResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{15}
+	return fileDescriptor_f1a937782ebbded5, []int{16}
 }
 func (m *ReadFileResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1029,7 +1116,7 @@ func (m *ReadDirRequest) Reset()         { *m = ReadDirRequest{} }
 func (m *ReadDirRequest) String() string { return proto.CompactTextString(m) }
 func (*ReadDirRequest) ProtoMessage()    {}
 func (*ReadDirRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{16}
+	return fileDescriptor_f1a937782ebbded5, []int{17}
 }
 func (m *ReadDirRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1090,7 +1177,7 @@ func (m *ReadDirResponse) Reset()         { *m = ReadDirResponse{} }
 func (m *ReadDirResponse) String() string { return proto.CompactTextString(m) }
 func (*ReadDirResponse) ProtoMessage()    {}
 func (*ReadDirResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{17}
+	return fileDescriptor_f1a937782ebbded5, []int{18}
 }
 func (m *ReadDirResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1138,7 +1225,7 @@ func (m *StatFileRequest) Reset()         { *m = StatFileRequest{} }
 func (m *StatFileRequest) String() string { return proto.CompactTextString(m) }
 func (*StatFileRequest) ProtoMessage()    {}
 func (*StatFileRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{18}
+	return fileDescriptor_f1a937782ebbded5, []int{19}
 }
 func (m *StatFileRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1192,7 +1279,7 @@ func (m *StatFileResponse) Reset()         { *m = StatFileResponse{} }
 func (m *StatFileResponse) String() string { return proto.CompactTextString(m) }
 func (*StatFileResponse) ProtoMessage()    {}
 func (*StatFileResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{19}
+	return fileDescriptor_f1a937782ebbded5, []int{20}
 }
 func (m *StatFileResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1238,7 +1325,7 @@ func (m *PingRequest) Reset()         { *m = PingRequest{} }
 func (m *PingRequest) String() string { return proto.CompactTextString(m) }
 func (*PingRequest) ProtoMessage()    {}
 func (*PingRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{20}
+	return fileDescriptor_f1a937782ebbded5, []int{21}
 }
 func (m *PingRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1268,19 +1355,22 @@ func (m *PingRequest) XXX_DiscardUnknown() {
 var xxx_messageInfo_PingRequest proto.InternalMessageInfo
 
 type PongResponse struct {
-	FrontendAPICaps      []pb1.APICap           `protobuf:"bytes,1,rep,name=FrontendAPICaps,proto3" json:"FrontendAPICaps"`
-	LLBCaps              []pb1.APICap           `protobuf:"bytes,2,rep,name=LLBCaps,proto3" json:"LLBCaps"`
-	Workers              []*types1.WorkerRecord `protobuf:"bytes,3,rep,name=Workers,proto3" json:"Workers,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
-	XXX_unrecognized     []byte                 `json:"-"`
-	XXX_sizecache        int32                  `json:"-"`
+	FrontendAPICaps []pb1.APICap           `protobuf:"bytes,1,rep,name=FrontendAPICaps,proto3" json:"FrontendAPICaps"`
+	LLBCaps         []pb1.APICap           `protobuf:"bytes,2,rep,name=LLBCaps,proto3" json:"LLBCaps"`
+	Workers         []*types1.WorkerRecord `protobuf:"bytes,3,rep,name=Workers,proto3" json:"Workers,omitempty"`
+	// maxLLBSize is the maximum size in bytes of the definitions the
+	// frontend can solve, 0 if unlimited.
+	MaxLLBSize           int64    `protobuf:"varint,4,opt,name=maxLLBSize,proto3" json:"maxLLBSize,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *PongResponse) Reset()         { *m = PongResponse{} }
 func (m *PongResponse) String() string { return proto.CompactTextString(m) }
 func (*PongResponse) ProtoMessage()    {}
 func (*PongResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{21}
+	return fileDescriptor_f1a937782ebbded5, []int{22}
 }
 func (m *PongResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1330,6 +1420,13 @@ func (m *PongResponse) GetWorkers() []*types1.WorkerRecord {
 	return nil
 }
 
+func (m *PongResponse) GetMaxLLBSize() int64 {
+	if m != nil {
+		return m.MaxLLBSize
+	}
+	return 0
+}
+
 type WarnRequest struct {
 	Digest               github_com_opencontainers_go_digest.Digest `protobuf:"bytes,1,opt,name=digest,proto3,customtype=github.com/opencontainers/go-digest.Digest" json:"digest"`
 	Level                int64                                      `protobuf:"varint,2,opt,name=level,proto3" json:"level,omitempty"`
@@ -1347,7 +1444,7 @@ func (m *WarnRequest) Reset()         { *m = WarnRequest{} }
 func (m *WarnRequest) String() string { return proto.CompactTextString(m) }
 func (*WarnRequest) ProtoMessage()    {}
 func (*WarnRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{22}
+	return fileDescriptor_f1a937782ebbded5, []int{23}
 }
 func (m *WarnRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1428,7 +1525,7 @@ func (m *WarnResponse) Reset()         { *m = WarnResponse{} }
 func (m *WarnResponse) String() string { return proto.CompactTextString(m) }
 func (*WarnResponse) ProtoMessage()    {}
 func (*WarnResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{23}
+	return fileDescriptor_f1a937782ebbded5, []int{24}
 }
 func (m *WarnResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1457,24 +1554,129 @@ func (m *WarnResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_WarnResponse proto.InternalMessageInfo
 
+type PrefetchRequest struct {
+	Images               []string         `protobuf:"bytes,1,rep,name=Images,proto3" json:"Images,omitempty"`
+	Platform             *pb.Platform     `protobuf:"bytes,2,opt,name=Platform,proto3" json:"Platform,omitempty"`
+	Definitions          []*pb.Definition `protobuf:"bytes,3,rep,name=Definitions,proto3" json:"Definitions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *PrefetchRequest) Reset()         { *m = PrefetchRequest{} }
+func (m *PrefetchRequest) String() string { return proto.CompactTextString(m) }
+func (*PrefetchRequest) ProtoMessage()    {}
+func (*PrefetchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f1a937782ebbded5, []int{25}
+}
+func (m *PrefetchRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PrefetchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PrefetchRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *PrefetchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PrefetchRequest.Merge(m, src)
+}
+func (m *PrefetchRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *PrefetchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PrefetchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PrefetchRequest proto.InternalMessageInfo
+
+func (m *PrefetchRequest) GetImages() []string {
+	if m != nil {
+		return m.Images
+	}
+	return nil
+}
+
+func (m *PrefetchRequest) GetPlatform() *pb.Platform {
+	if m != nil {
+		return m.Platform
+	}
+	return nil
+}
+
+func (m *PrefetchRequest) GetDefinitions() []*pb.Definition {
+	if m != nil {
+		return m.Definitions
+	}
+	return nil
+}
+
+type PrefetchResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PrefetchResponse) Reset()         { *m = PrefetchResponse{} }
+func (m *PrefetchResponse) String() string { return proto.CompactTextString(m) }
+func (*PrefetchResponse) ProtoMessage()    {}
+func (*PrefetchResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f1a937782ebbded5, []int{26}
+}
+func (m *PrefetchResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PrefetchResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PrefetchResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *PrefetchResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PrefetchResponse.Merge(m, src)
+}
+func (m *PrefetchResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *PrefetchResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PrefetchResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PrefetchResponse proto.InternalMessageInfo
+
 type NewContainerRequest struct {
 	ContainerID string `protobuf:"bytes,1,opt,name=ContainerID,proto3" json:"ContainerID,omitempty"`
 	// For mount input values we can use random identifiers passed with ref
-	Mounts               []*pb.Mount           `protobuf:"bytes,2,rep,name=Mounts,proto3" json:"Mounts,omitempty"`
-	Network              pb.NetMode            `protobuf:"varint,3,opt,name=Network,proto3,enum=pb.NetMode" json:"Network,omitempty"`
-	Platform             *pb.Platform          `protobuf:"bytes,4,opt,name=platform,proto3" json:"platform,omitempty"`
-	Constraints          *pb.WorkerConstraints `protobuf:"bytes,5,opt,name=constraints,proto3" json:"constraints,omitempty"`
-	ExtraHosts           []*pb.HostIP          `protobuf:"bytes,6,rep,name=extraHosts,proto3" json:"extraHosts,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
-	XXX_unrecognized     []byte                `json:"-"`
-	XXX_sizecache        int32                 `json:"-"`
+	Mounts      []*pb.Mount           `protobuf:"bytes,2,rep,name=Mounts,proto3" json:"Mounts,omitempty"`
+	Network     pb.NetMode            `protobuf:"varint,3,opt,name=Network,proto3,enum=pb.NetMode" json:"Network,omitempty"`
+	Platform    *pb.Platform          `protobuf:"bytes,4,opt,name=platform,proto3" json:"platform,omitempty"`
+	Constraints *pb.WorkerConstraints `protobuf:"bytes,5,opt,name=constraints,proto3" json:"constraints,omitempty"`
+	ExtraHosts  []*pb.HostIP          `protobuf:"bytes,6,rep,name=extraHosts,proto3" json:"extraHosts,omitempty"`
+	// publishPorts publishes ports of the container on the host of the
+	// daemon, e.g. for integration tests run by the client during the build.
+	PublishPorts         []*PortMapping `protobuf:"bytes,7,rep,name=publishPorts,proto3" json:"publishPorts,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
 }
 
 func (m *NewContainerRequest) Reset()         { *m = NewContainerRequest{} }
 func (m *NewContainerRequest) String() string { return proto.CompactTextString(m) }
 func (*NewContainerRequest) ProtoMessage()    {}
 func (*NewContainerRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{24}
+	return fileDescriptor_f1a937782ebbded5, []int{27}
 }
 func (m *NewContainerRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1545,6 +1747,85 @@ func (m *NewContainerRequest) GetExtraHosts() []*pb.HostIP {
 	return nil
 }
 
+func (m *NewContainerRequest) GetPublishPorts() []*PortMapping {
+	if m != nil {
+		return m.PublishPorts
+	}
+	return nil
+}
+
+type PortMapping struct {
+	HostPort      int32 `protobuf:"varint,1,opt,name=hostPort,proto3" json:"hostPort,omitempty"`
+	ContainerPort int32 `protobuf:"varint,2,opt,name=containerPort,proto3" json:"containerPort,omitempty"`
+	// protocol is tcp or udp, tcp if unset
+	Protocol             string   `protobuf:"bytes,3,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	HostIP               string   `protobuf:"bytes,4,opt,name=hostIP,proto3" json:"hostIP,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PortMapping) Reset()         { *m = PortMapping{} }
+func (m *PortMapping) String() string { return proto.CompactTextString(m) }
+func (*PortMapping) ProtoMessage()    {}
+func (*PortMapping) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f1a937782ebbded5, []int{28}
+}
+func (m *PortMapping) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PortMapping) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PortMapping.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *PortMapping) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PortMapping.Merge(m, src)
+}
+func (m *PortMapping) XXX_Size() int {
+	return m.Size()
+}
+func (m *PortMapping) XXX_DiscardUnknown() {
+	xxx_messageInfo_PortMapping.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PortMapping proto.InternalMessageInfo
+
+func (m *PortMapping) GetHostPort() int32 {
+	if m != nil {
+		return m.HostPort
+	}
+	return 0
+}
+
+func (m *PortMapping) GetContainerPort() int32 {
+	if m != nil {
+		return m.ContainerPort
+	}
+	return 0
+}
+
+func (m *PortMapping) GetProtocol() string {
+	if m != nil {
+		return m.Protocol
+	}
+	return ""
+}
+
+func (m *PortMapping) GetHostIP() string {
+	if m != nil {
+		return m.HostIP
+	}
+	return ""
+}
+
 type NewContainerResponse struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -1555,7 +1836,7 @@ func (m *NewContainerResponse) Reset()         { *m = NewContainerResponse{} }
 func (m *NewContainerResponse) String() string { return proto.CompactTextString(m) }
 func (*NewContainerResponse) ProtoMessage()    {}
 func (*NewContainerResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{25}
+	return fileDescriptor_f1a937782ebbded5, []int{29}
 }
 func (m *NewContainerResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1595,7 +1876,7 @@ func (m *ReleaseContainerRequest) Reset()         { *m = ReleaseContainerRequest
 func (m *ReleaseContainerRequest) String() string { return proto.CompactTextString(m) }
 func (*ReleaseContainerRequest) ProtoMessage()    {}
 func (*ReleaseContainerRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{26}
+	return fileDescriptor_f1a937782ebbded5, []int{30}
 }
 func (m *ReleaseContainerRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1641,7 +1922,7 @@ func (m *ReleaseContainerResponse) Reset()         { *m = ReleaseContainerRespon
 func (m *ReleaseContainerResponse) String() string { return proto.CompactTextString(m) }
 func (*ReleaseContainerResponse) ProtoMessage()    {}
 func (*ReleaseContainerResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{27}
+	return fileDescriptor_f1a937782ebbded5, []int{31}
 }
 func (m *ReleaseContainerResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1690,7 +1971,7 @@ func (m *ExecMessage) Reset()         { *m = ExecMessage{} }
 func (m *ExecMessage) String() string { return proto.CompactTextString(m) }
 func (*ExecMessage) ProtoMessage()    {}
 func (*ExecMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{28}
+	return fileDescriptor_f1a937782ebbded5, []int{32}
 }
 func (m *ExecMessage) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1846,7 +2127,7 @@ func (m *InitMessage) Reset()         { *m = InitMessage{} }
 func (m *InitMessage) String() string { return proto.CompactTextString(m) }
 func (*InitMessage) ProtoMessage()    {}
 func (*InitMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{29}
+	return fileDescriptor_f1a937782ebbded5, []int{33}
 }
 func (m *InitMessage) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1922,7 +2203,7 @@ func (m *ExitMessage) Reset()         { *m = ExitMessage{} }
 func (m *ExitMessage) String() string { return proto.CompactTextString(m) }
 func (*ExitMessage) ProtoMessage()    {}
 func (*ExitMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{30}
+	return fileDescriptor_f1a937782ebbded5, []int{34}
 }
 func (m *ExitMessage) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1975,7 +2256,7 @@ func (m *StartedMessage) Reset()         { *m = StartedMessage{} }
 func (m *StartedMessage) String() string { return proto.CompactTextString(m) }
 func (*StartedMessage) ProtoMessage()    {}
 func (*StartedMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{31}
+	return fileDescriptor_f1a937782ebbded5, []int{35}
 }
 func (m *StartedMessage) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2014,7 +2295,7 @@ func (m *DoneMessage) Reset()         { *m = DoneMessage{} }
 func (m *DoneMessage) String() string { return proto.CompactTextString(m) }
 func (*DoneMessage) ProtoMessage()    {}
 func (*DoneMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{32}
+	return fileDescriptor_f1a937782ebbded5, []int{36}
 }
 func (m *DoneMessage) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2056,7 +2337,7 @@ func (m *FdMessage) Reset()         { *m = FdMessage{} }
 func (m *FdMessage) String() string { return proto.CompactTextString(m) }
 func (*FdMessage) ProtoMessage()    {}
 func (*FdMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{33}
+	return fileDescriptor_f1a937782ebbded5, []int{37}
 }
 func (m *FdMessage) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2118,7 +2399,7 @@ func (m *ResizeMessage) Reset()         { *m = ResizeMessage{} }
 func (m *ResizeMessage) String() string { return proto.CompactTextString(m) }
 func (*ResizeMessage) ProtoMessage()    {}
 func (*ResizeMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{34}
+	return fileDescriptor_f1a937782ebbded5, []int{38}
 }
 func (m *ResizeMessage) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2174,7 +2455,7 @@ func (m *SignalMessage) Reset()         { *m = SignalMessage{} }
 func (m *SignalMessage) String() string { return proto.CompactTextString(m) }
 func (*SignalMessage) ProtoMessage()    {}
 func (*SignalMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f1a937782ebbded5, []int{35}
+	return fileDescriptor_f1a937782ebbded5, []int{39}
 }
 func (m *SignalMessage) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2228,6 +2509,7 @@ func init() {
 	proto.RegisterType((*SolveRequest)(nil), "moby.buildkit.v1.frontend.SolveRequest")
 	proto.RegisterMapType((map[string]*pb.Definition)(nil), "moby.buildkit.v1.frontend.SolveRequest.FrontendInputsEntry")
 	proto.RegisterMapType((map[string]string)(nil), "moby.buildkit.v1.frontend.SolveRequest.FrontendOptEntry")
+	proto.RegisterType((*FrontendBudget)(nil), "moby.buildkit.v1.frontend.FrontendBudget")
 	proto.RegisterType((*CacheOptionsEntry)(nil), "moby.buildkit.v1.frontend.CacheOptionsEntry")
 	proto.RegisterMapType((map[string]string)(nil), "moby.buildkit.v1.frontend.CacheOptionsEntry.AttrsEntry")
 	proto.RegisterType((*SolveResponse)(nil), "moby.buildkit.v1.frontend.SolveResponse")
@@ -2242,7 +2524,10 @@ func init() {
 	proto.RegisterType((*PongResponse)(nil), "moby.buildkit.v1.frontend.PongResponse")
 	proto.RegisterType((*WarnRequest)(nil), "moby.buildkit.v1.frontend.WarnRequest")
 	proto.RegisterType((*WarnResponse)(nil), "moby.buildkit.v1.frontend.WarnResponse")
+	proto.RegisterType((*PrefetchRequest)(nil), "moby.buildkit.v1.frontend.PrefetchRequest")
+	proto.RegisterType((*PrefetchResponse)(nil), "moby.buildkit.v1.frontend.PrefetchResponse")
 	proto.RegisterType((*NewContainerRequest)(nil), "moby.buildkit.v1.frontend.NewContainerRequest")
+	proto.RegisterType((*PortMapping)(nil), "moby.buildkit.v1.frontend.PortMapping")
 	proto.RegisterType((*NewContainerResponse)(nil), "moby.buildkit.v1.frontend.NewContainerResponse")
 	proto.RegisterType((*ReleaseContainerRequest)(nil), "moby.buildkit.v1.frontend.ReleaseContainerRequest")
 	proto.RegisterType((*ReleaseContainerResponse)(nil), "moby.buildkit.v1.frontend.ReleaseContainerResponse")
@@ -2259,137 +2544,153 @@ func init() {
 func init() { proto.RegisterFile("gateway.proto", fileDescriptor_f1a937782ebbded5) }
 
 var fileDescriptor_f1a937782ebbded5 = []byte{
-	// 2078 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x58, 0x4f, 0x6f, 0x1b, 0xc7,
-	0x15, 0xd7, 0x8a, 0x94, 0x48, 0x3e, 0xfe, 0xb1, 0x32, 0x4e, 0x53, 0x7a, 0x11, 0x38, 0xca, 0x36,
-	0x55, 0x69, 0x47, 0x59, 0xa6, 0x72, 0x02, 0xb9, 0x72, 0x90, 0xd4, 0xfa, 0x07, 0x29, 0x91, 0x64,
-	0x75, 0x94, 0xc2, 0x40, 0x90, 0x02, 0x5d, 0x71, 0x87, 0xf4, 0xc2, 0xab, 0x9d, 0xed, 0xec, 0xd0,
-	0xb2, 0x92, 0x4b, 0x7b, 0xeb, 0xb1, 0x40, 0x81, 0x5e, 0x0b, 0xf4, 0x13, 0xf4, 0x13, 0xf4, 0x9c,
-	0x63, 0x8f, 0x45, 0x0f, 0x41, 0xe1, 0xcf, 0x50, 0x14, 0xe8, 0x2d, 0x78, 0x33, 0xb3, 0xe4, 0x92,
-	0xa2, 0x96, 0x24, 0x7c, 0xe2, 0xcc, 0xdb, 0xf7, 0x7b, 0xf3, 0xfe, 0xcd, 0x7b, 0x6f, 0x08, 0xf5,
-	0x9e, 0x27, 0xd9, 0xa5, 0x77, 0xe5, 0xc6, 0x82, 0x4b, 0x4e, 0xee, 0x5c, 0xf0, 0xf3, 0x2b, 0xf7,
-	0xbc, 0x1f, 0x84, 0xfe, 0xf3, 0x40, 0xba, 0x2f, 0x7e, 0xee, 0x76, 0x05, 0x8f, 0x24, 0x8b, 0x7c,
-	0xfb, 0x83, 0x5e, 0x20, 0x9f, 0xf5, 0xcf, 0xdd, 0x0e, 0xbf, 0x68, 0xf7, 0x78, 0x8f, 0xb7, 0x15,
-	0xe2, 0xbc, 0xdf, 0x55, 0x3b, 0xb5, 0x51, 0x2b, 0x2d, 0xc9, 0xde, 0x18, 0x67, 0xef, 0x71, 0xde,
-	0x0b, 0x99, 0x17, 0x07, 0x89, 0x59, 0xb6, 0x45, 0xdc, 0x69, 0x27, 0xd2, 0x93, 0xfd, 0xc4, 0x60,
-	0xd6, 0x33, 0x18, 0x54, 0xa4, 0x9d, 0x2a, 0xd2, 0x4e, 0x78, 0xf8, 0x82, 0x89, 0x76, 0x7c, 0xde,
-	0xe6, 0x71, 0xca, 0xdd, 0xbe, 0x91, 0xdb, 0x8b, 0x83, 0xb6, 0xbc, 0x8a, 0x59, 0xd2, 0xbe, 0xe4,
-	0xe2, 0x39, 0x13, 0x06, 0xf0, 0xe0, 0x46, 0x40, 0x5f, 0x06, 0x21, 0xa2, 0x3a, 0x5e, 0x9c, 0xe0,
-	0x21, 0xf8, 0x6b, 0x40, 0x59, 0xb3, 0x25, 0x8f, 0x82, 0x44, 0x06, 0x41, 0x2f, 0x68, 0x77, 0x13,
-	0x85, 0xd1, 0xa7, 0xa0, 0x11, 0x9a, 0xdd, 0xf9, 0x63, 0x01, 0x96, 0x29, 0x4b, 0xfa, 0xa1, 0x24,
-	0x6b, 0x50, 0x17, 0xac, 0xbb, 0xcb, 0x62, 0xc1, 0x3a, 0x9e, 0x64, 0x7e, 0xd3, 0x5a, 0xb5, 0x5a,
-	0x95, 0x83, 0x05, 0x3a, 0x4a, 0x26, 0xbf, 0x86, 0x86, 0x60, 0xdd, 0x24, 0xc3, 0xb8, 0xb8, 0x6a,
-	0xb5, 0xaa, 0x1b, 0xef, 0xbb, 0x37, 0x06, 0xc3, 0xa5, 0xac, 0x7b, 0xec, 0xc5, 0x43, 0xc8, 0xc1,
-	0x02, 0x1d, 0x13, 0x42, 0x36, 0xa0, 0x20, 0x58, 0xb7, 0x59, 0x50, 0xb2, 0xee, 0xe6, 0xcb, 0x3a,
-	0x58, 0xa0, 0xc8, 0x4c, 0x36, 0xa1, 0x88, 0x52, 0x9a, 0x45, 0x05, 0x7a, 0x77, 0xaa, 0x02, 0x07,
-	0x0b, 0x54, 0x01, 0xc8, 0x17, 0x50, 0xbe, 0x60, 0xd2, 0xf3, 0x3d, 0xe9, 0x35, 0x61, 0xb5, 0xd0,
-	0xaa, 0x6e, 0xb4, 0x73, 0xc1, 0xe8, 0x20, 0xf7, 0xd8, 0x20, 0xf6, 0x22, 0x29, 0xae, 0xe8, 0x40,
-	0x80, 0xfd, 0x08, 0xea, 0x23, 0x9f, 0xc8, 0x0a, 0x14, 0x9e, 0xb3, 0x2b, 0xed, 0x3f, 0x8a, 0x4b,
-	0xf2, 0x26, 0x2c, 0xbd, 0xf0, 0xc2, 0x3e, 0x53, 0xae, 0xaa, 0x51, 0xbd, 0xd9, 0x5a, 0x7c, 0x68,
-	0x6d, 0x97, 0x61, 0x59, 0x28, 0xf1, 0xce, 0x5f, 0x2c, 0x58, 0x19, 0xf7, 0x13, 0x39, 0x34, 0x16,
-	0x5a, 0x4a, 0xc9, 0x8f, 0xe7, 0x70, 0x31, 0x12, 0x12, 0xad, 0xaa, 0x12, 0x61, 0x6f, 0x42, 0x65,
-	0x40, 0x9a, 0xa6, 0x62, 0x25, 0xa3, 0xa2, 0xb3, 0x09, 0x05, 0xca, 0xba, 0xa4, 0x01, 0x8b, 0x81,
-	0x49, 0x0a, 0xba, 0x18, 0xf8, 0x64, 0x15, 0x0a, 0x3e, 0xeb, 0x9a, 0xe0, 0x37, 0xdc, 0xf8, 0xdc,
-	0xdd, 0x65, 0xdd, 0x20, 0x0a, 0x64, 0xc0, 0x23, 0x8a, 0x9f, 0x9c, 0xbf, 0x59, 0x98, 0x5c, 0xa8,
-	0x16, 0xf9, 0x6c, 0xc4, 0x8e, 0xe9, 0xa9, 0x72, 0x4d, 0xfb, 0xa7, 0xf9, 0xda, 0x7f, 0x94, 0xd5,
-	0x7e, 0x6a, 0xfe, 0x64, 0xad, 0x93, 0x50, 0xa7, 0x4c, 0xf6, 0x45, 0x44, 0xd9, 0xef, 0xfa, 0x2c,
-	0x91, 0xe4, 0x17, 0x69, 0x44, 0x94, 0xfc, 0x69, 0x69, 0x85, 0x8c, 0xd4, 0x00, 0x48, 0x0b, 0x96,
-	0x98, 0x10, 0x5c, 0x18, 0x2d, 0x88, 0xab, 0x2b, 0x87, 0x2b, 0xe2, 0x8e, 0x7b, 0xa6, 0x2a, 0x07,
-	0xd5, 0x0c, 0xce, 0x0a, 0x34, 0xd2, 0x53, 0x93, 0x98, 0x47, 0x09, 0x73, 0x6e, 0x41, 0xfd, 0x30,
-	0x8a, 0xfb, 0x32, 0x31, 0x7a, 0x38, 0xff, 0xb0, 0xa0, 0x91, 0x52, 0x34, 0x0f, 0xf9, 0x1a, 0xaa,
-	0x43, 0x1f, 0xa7, 0xce, 0xdc, 0xca, 0xd1, 0x6f, 0x14, 0x9f, 0x09, 0x90, 0xf1, 0x6d, 0x56, 0x9c,
-	0x7d, 0x02, 0x2b, 0xe3, 0x0c, 0x13, 0x3c, 0xfd, 0xde, 0xa8, 0xa7, 0xc7, 0x03, 0x9f, 0xf1, 0xec,
-	0x9f, 0x2d, 0xb8, 0x43, 0x99, 0x2a, 0x85, 0x87, 0x17, 0x5e, 0x8f, 0xed, 0xf0, 0xa8, 0x1b, 0xf4,
-	0x52, 0x37, 0xaf, 0xa8, 0xac, 0x4a, 0x25, 0x63, 0x82, 0xb5, 0xa0, 0x7c, 0x1a, 0x7a, 0xb2, 0xcb,
-	0xc5, 0x85, 0x11, 0x5e, 0x43, 0xe1, 0x29, 0x8d, 0x0e, 0xbe, 0x92, 0x55, 0xa8, 0x1a, 0xc1, 0xc7,
-	0xdc, 0x67, 0xaa, 0x66, 0x54, 0x68, 0x96, 0x44, 0x9a, 0x50, 0x3a, 0xe2, 0xbd, 0x13, 0xef, 0x82,
-	0xa9, 0xe2, 0x50, 0xa1, 0xe9, 0xd6, 0xf9, 0xbd, 0x05, 0xf6, 0x24, 0xad, 0x8c, 0x8b, 0x3f, 0x87,
-	0xe5, 0xdd, 0xa0, 0xc7, 0x12, 0x1d, 0xfd, 0xca, 0xf6, 0xc6, 0x77, 0xdf, 0xbf, 0xb3, 0xf0, 0xef,
-	0xef, 0xdf, 0xb9, 0x9f, 0xa9, 0xab, 0x3c, 0x66, 0x51, 0x87, 0x47, 0xd2, 0x0b, 0x22, 0x26, 0xb0,
-	0x3d, 0x7c, 0xe0, 0x2b, 0x88, 0xab, 0x91, 0xd4, 0x48, 0x20, 0x6f, 0xc1, 0xb2, 0x96, 0x6e, 0xae,
-	0xbd, 0xd9, 0x39, 0xff, 0x5d, 0x82, 0xda, 0x19, 0x2a, 0x90, 0xfa, 0xc2, 0x05, 0x18, 0xba, 0xd0,
-	0xa4, 0xdd, 0xb8, 0x63, 0x33, 0x1c, 0xc4, 0x86, 0xf2, 0xbe, 0x09, 0xb1, 0xb9, 0xae, 0x83, 0x3d,
-	0xf9, 0x0a, 0xaa, 0xe9, 0xfa, 0x49, 0x2c, 0x9b, 0x05, 0x95, 0x23, 0x0f, 0x73, 0x72, 0x24, 0xab,
-	0x89, 0x9b, 0x81, 0x9a, 0x0c, 0xc9, 0x50, 0xc8, 0x27, 0x70, 0xe7, 0xf0, 0x22, 0xe6, 0x42, 0xee,
-	0x78, 0x9d, 0x67, 0x8c, 0x8e, 0x76, 0x81, 0xe2, 0x6a, 0xa1, 0x55, 0xa1, 0x37, 0x33, 0x90, 0x75,
-	0x78, 0xc3, 0x0b, 0x43, 0x7e, 0x69, 0x2e, 0x8d, 0x4a, 0xff, 0xe6, 0xd2, 0xaa, 0xd5, 0x2a, 0xd3,
-	0xeb, 0x1f, 0xc8, 0x87, 0x70, 0x3b, 0x43, 0x7c, 0x2c, 0x84, 0x77, 0x85, 0xf9, 0xb2, 0xac, 0xf8,
-	0x27, 0x7d, 0xc2, 0x0a, 0xb6, 0x1f, 0x44, 0x5e, 0xd8, 0x04, 0xc5, 0xa3, 0x37, 0xc4, 0x81, 0xda,
-	0xde, 0x4b, 0x54, 0x89, 0x89, 0xc7, 0x52, 0x8a, 0x66, 0x55, 0x85, 0x62, 0x84, 0x46, 0x4e, 0xa1,
-	0xa6, 0x14, 0xd6, 0xba, 0x27, 0xcd, 0x9a, 0x72, 0xda, 0x7a, 0x8e, 0xd3, 0x14, 0xfb, 0x93, 0x38,
-	0x73, 0x95, 0x46, 0x24, 0x90, 0x0e, 0x34, 0x52, 0xc7, 0xe9, 0x3b, 0xd8, 0xac, 0x2b, 0x99, 0x8f,
-	0xe6, 0x0d, 0x84, 0x46, 0xeb, 0x23, 0xc6, 0x44, 0x62, 0x1a, 0xec, 0xe1, 0x75, 0xf3, 0x24, 0x6b,
-	0x36, 0x94, 0xcd, 0x83, 0xbd, 0xfd, 0x29, 0xac, 0x8c, 0xc7, 0x72, 0x9e, 0xa2, 0x6f, 0xff, 0x0a,
-	0x6e, 0x4f, 0x50, 0xe1, 0xb5, 0xea, 0xc1, 0xdf, 0x2d, 0x78, 0xe3, 0x9a, 0xdf, 0x08, 0x81, 0xe2,
-	0x97, 0x57, 0x31, 0x33, 0x22, 0xd5, 0x9a, 0x1c, 0xc3, 0x12, 0xc6, 0x25, 0x69, 0x2e, 0x2a, 0xa7,
-	0x6d, 0xce, 0x13, 0x08, 0x57, 0x21, 0xb5, 0xc3, 0xb4, 0x14, 0xfb, 0x21, 0xc0, 0x90, 0x38, 0x57,
-	0xeb, 0xfb, 0x1a, 0xea, 0x26, 0x2a, 0xa6, 0x3c, 0xac, 0xe8, 0x29, 0xc5, 0x80, 0x71, 0x06, 0x19,
-	0xb6, 0x8b, 0xc2, 0x9c, 0xed, 0xc2, 0xf9, 0x16, 0x6e, 0x51, 0xe6, 0xf9, 0xfb, 0x41, 0xc8, 0x6e,
-	0xae, 0x8a, 0x78, 0xd7, 0x83, 0x90, 0x9d, 0x7a, 0xf2, 0xd9, 0xe0, 0xae, 0x9b, 0x3d, 0xd9, 0x82,
-	0x25, 0xea, 0x45, 0x3d, 0x66, 0x8e, 0x7e, 0x2f, 0xe7, 0x68, 0x75, 0x08, 0xf2, 0x52, 0x0d, 0x71,
-	0x1e, 0x41, 0x65, 0x40, 0xc3, 0x4a, 0xf5, 0xa4, 0xdb, 0x4d, 0x98, 0xae, 0x7a, 0x05, 0x6a, 0x76,
-	0x48, 0x3f, 0x62, 0x51, 0xcf, 0x1c, 0x5d, 0xa0, 0x66, 0xe7, 0xac, 0xe1, 0xa8, 0x92, 0x6a, 0x6e,
-	0x5c, 0x43, 0xa0, 0xb8, 0x8b, 0xf3, 0x94, 0xa5, 0x2e, 0x98, 0x5a, 0x3b, 0x3e, 0xb6, 0x39, 0xcf,
-	0xdf, 0x0d, 0xc4, 0xcd, 0x06, 0x36, 0xa1, 0xb4, 0x1b, 0x88, 0x8c, 0x7d, 0xe9, 0x96, 0xac, 0x61,
-	0x03, 0xec, 0x84, 0x7d, 0x1f, 0xad, 0x95, 0x4c, 0x44, 0xa6, 0xd2, 0x8f, 0x51, 0x9d, 0xcf, 0xb4,
-	0x1f, 0xd5, 0x29, 0x46, 0x99, 0x75, 0x28, 0xb1, 0x48, 0x8a, 0x80, 0xa5, 0x5d, 0x92, 0xb8, 0x7a,
-	0x04, 0x76, 0xd5, 0x08, 0xac, 0xba, 0x31, 0x4d, 0x59, 0x9c, 0x4d, 0xb8, 0x85, 0x84, 0xfc, 0x40,
-	0x10, 0x28, 0x66, 0x94, 0x54, 0x6b, 0x67, 0x0b, 0x56, 0x86, 0x40, 0x73, 0xf4, 0x1a, 0x14, 0x71,
-	0xc0, 0x36, 0x65, 0x7c, 0xd2, 0xb9, 0xea, 0xbb, 0x53, 0x87, 0xea, 0x69, 0x10, 0xa5, 0xfd, 0xd0,
-	0x79, 0x65, 0x41, 0xed, 0x94, 0x47, 0xc3, 0x4e, 0x74, 0x0a, 0xb7, 0xd2, 0x1b, 0xf8, 0xf8, 0xf4,
-	0x70, 0xc7, 0x8b, 0x53, 0x53, 0x56, 0xaf, 0x87, 0xd9, 0xbc, 0x05, 0x5c, 0xcd, 0xb8, 0x5d, 0xc4,
-	0xa6, 0x45, 0xc7, 0xe1, 0xe4, 0x97, 0x50, 0x3a, 0x3a, 0xda, 0x56, 0x92, 0x16, 0xe7, 0x92, 0x94,
-	0xc2, 0xc8, 0xa7, 0x50, 0x7a, 0xaa, 0x9e, 0x28, 0x89, 0x69, 0x2c, 0x13, 0x52, 0x4e, 0x1b, 0xaa,
-	0xd9, 0x28, 0xeb, 0x70, 0xe1, 0xd3, 0x14, 0xe4, 0xfc, 0xcf, 0x82, 0xea, 0x53, 0x6f, 0x38, 0x6b,
-	0x7d, 0x0e, 0xcb, 0xfe, 0x6b, 0x77, 0x5b, 0xbd, 0xc5, 0x5b, 0x1c, 0xb2, 0x17, 0x2c, 0x34, 0xa9,
-	0xaa, 0x37, 0x48, 0x4d, 0x9e, 0x71, 0xa1, 0x6f, 0x67, 0x8d, 0xea, 0x0d, 0xe6, 0xb5, 0xcf, 0xa4,
-	0x17, 0x84, 0xaa, 0x6b, 0xd5, 0xa8, 0xd9, 0x61, 0xd4, 0xfb, 0x22, 0x54, 0x4d, 0xa9, 0x42, 0x71,
-	0x49, 0x1c, 0x28, 0x06, 0x51, 0x97, 0xab, 0xbe, 0x63, 0xaa, 0xdb, 0x19, 0xef, 0x8b, 0x0e, 0x3b,
-	0x8c, 0xba, 0x9c, 0xaa, 0x6f, 0xe4, 0x5d, 0x58, 0x16, 0x78, 0x8d, 0x92, 0x66, 0x49, 0x39, 0xa5,
-	0x82, 0x5c, 0xfa, 0xb2, 0x99, 0x0f, 0x4e, 0x03, 0x6a, 0xda, 0x6e, 0x33, 0xed, 0xfd, 0x69, 0x11,
-	0x6e, 0x9f, 0xb0, 0xcb, 0x9d, 0xd4, 0xae, 0xd4, 0x21, 0xab, 0x50, 0x1d, 0xd0, 0x0e, 0x77, 0x4d,
-	0xfa, 0x65, 0x49, 0x78, 0xd8, 0x31, 0xef, 0x47, 0x32, 0x8d, 0xa1, 0x3a, 0x4c, 0x51, 0xa8, 0xf9,
-	0x40, 0x7e, 0x0a, 0xa5, 0x13, 0x26, 0xf1, 0x2d, 0xa9, 0xac, 0x6e, 0x6c, 0x54, 0x91, 0xe7, 0x84,
-	0x49, 0x1c, 0x8d, 0x68, 0xfa, 0x0d, 0xe7, 0xad, 0x38, 0x9d, 0xb7, 0x8a, 0x93, 0xe6, 0xad, 0xf4,
-	0x2b, 0xd9, 0x84, 0x6a, 0x87, 0x47, 0x89, 0x14, 0x5e, 0x80, 0x07, 0x2f, 0x29, 0xe6, 0x1f, 0x21,
-	0xb3, 0x0e, 0xec, 0xce, 0xf0, 0x23, 0xcd, 0x72, 0x92, 0xfb, 0x00, 0xec, 0xa5, 0x14, 0xde, 0x01,
-	0x4f, 0x64, 0xd2, 0x5c, 0x56, 0x0a, 0x03, 0xe2, 0x90, 0x70, 0x78, 0x4a, 0x33, 0x5f, 0x9d, 0xb7,
-	0xe0, 0xcd, 0x51, 0x8f, 0x18, 0x57, 0x3d, 0x82, 0x1f, 0x53, 0x16, 0x32, 0x2f, 0x61, 0xf3, 0x7b,
-	0xcb, 0xb1, 0xa1, 0x79, 0x1d, 0x6c, 0x04, 0xff, 0xbf, 0x00, 0xd5, 0xbd, 0x97, 0xac, 0x73, 0xcc,
-	0x92, 0xc4, 0xeb, 0x31, 0xf2, 0x36, 0x54, 0x4e, 0x05, 0xef, 0xb0, 0x24, 0x19, 0xc8, 0x1a, 0x12,
-	0xc8, 0x27, 0x50, 0x3c, 0x8c, 0x02, 0x69, 0xda, 0xdc, 0x5a, 0xee, 0xd0, 0x1d, 0x48, 0x23, 0x13,
-	0x1f, 0x9c, 0xb8, 0x25, 0x5b, 0x50, 0xc4, 0x22, 0x31, 0x4b, 0xa1, 0xf6, 0x33, 0x58, 0xc4, 0x90,
-	0x6d, 0xf5, 0x44, 0x0f, 0xbe, 0x61, 0x26, 0x4a, 0xad, 0xfc, 0x0e, 0x13, 0x7c, 0xc3, 0x86, 0x12,
-	0x0c, 0x92, 0xec, 0x41, 0xe9, 0x4c, 0x7a, 0x02, 0xe7, 0x34, 0x1d, 0xbd, 0x7b, 0x79, 0x83, 0x88,
-	0xe6, 0x1c, 0x4a, 0x49, 0xb1, 0xe8, 0x84, 0xbd, 0x97, 0x81, 0x34, 0xb7, 0x21, 0xcf, 0x09, 0xc8,
-	0x96, 0x31, 0x04, 0xb7, 0x88, 0xde, 0xe5, 0x11, 0x6b, 0x96, 0xa6, 0xa2, 0x91, 0x2d, 0x83, 0xc6,
-	0x2d, 0xba, 0xe1, 0x2c, 0xe8, 0xe1, 0x7c, 0x57, 0x9e, 0xea, 0x06, 0xcd, 0x98, 0x71, 0x83, 0x26,
-	0x6c, 0x97, 0x60, 0x49, 0x4d, 0x33, 0xce, 0x5f, 0x2d, 0xa8, 0x66, 0xe2, 0x34, 0xc3, 0xbd, 0x7b,
-	0x1b, 0x8a, 0xf8, 0xca, 0x37, 0xf1, 0x2f, 0xab, 0x5b, 0xc7, 0xa4, 0x47, 0x15, 0x15, 0x0b, 0xc7,
-	0xbe, 0xaf, 0x8b, 0x62, 0x9d, 0xe2, 0x12, 0x29, 0x5f, 0xca, 0x2b, 0x15, 0xb2, 0x32, 0xc5, 0x25,
-	0x59, 0x87, 0xf2, 0x19, 0xeb, 0xf4, 0x45, 0x20, 0xaf, 0x54, 0x10, 0x1a, 0x1b, 0x2b, 0xaa, 0x9c,
-	0x18, 0x9a, 0xba, 0x9c, 0x03, 0x0e, 0xe7, 0x0b, 0x4c, 0xce, 0xa1, 0x82, 0x04, 0x8a, 0x3b, 0xf8,
-	0xd6, 0x41, 0xcd, 0xea, 0x54, 0xad, 0xf1, 0xb9, 0xb9, 0x37, 0xed, 0xb9, 0xb9, 0x97, 0x3e, 0x37,
-	0x47, 0x83, 0x8a, 0xdd, 0x27, 0xe3, 0x64, 0xe7, 0x31, 0x54, 0x06, 0x89, 0x87, 0x2f, 0xfd, 0x7d,
-	0xdf, 0x9c, 0xb4, 0xb8, 0xef, 0xa3, 0x29, 0x7b, 0x4f, 0xf6, 0xd5, 0x29, 0x65, 0x8a, 0xcb, 0x41,
-	0xaf, 0x2f, 0x64, 0x7a, 0xfd, 0x26, 0x3e, 0xa4, 0x33, 0xd9, 0x87, 0x4c, 0x94, 0x5f, 0x26, 0xa9,
-	0xca, 0xb8, 0xd6, 0x66, 0x84, 0x89, 0x92, 0xa5, 0xcc, 0x08, 0x13, 0xe7, 0x27, 0x50, 0x1f, 0x89,
-	0x17, 0x32, 0xa9, 0x97, 0x9b, 0x19, 0x09, 0x71, 0xbd, 0xf1, 0xaf, 0x0a, 0x54, 0x8e, 0x8e, 0xb6,
-	0xb7, 0x45, 0xe0, 0xf7, 0x18, 0xf9, 0x83, 0x05, 0xe4, 0xfa, 0x23, 0x8e, 0x7c, 0x94, 0x7f, 0x33,
-	0x26, 0xbf, 0x44, 0xed, 0x8f, 0xe7, 0x44, 0x99, 0xfe, 0xfc, 0x15, 0x2c, 0xa9, 0xd9, 0x90, 0xfc,
-	0x6c, 0xc6, 0x99, 0xde, 0x6e, 0x4d, 0x67, 0x34, 0xb2, 0x3b, 0x50, 0x4e, 0xe7, 0x2b, 0x72, 0x3f,
-	0x57, 0xbd, 0x91, 0xf1, 0xd1, 0x7e, 0x7f, 0x26, 0x5e, 0x73, 0xc8, 0x6f, 0xa1, 0x64, 0xc6, 0x26,
-	0x72, 0x6f, 0x0a, 0x6e, 0x38, 0xc0, 0xd9, 0xf7, 0x67, 0x61, 0x1d, 0x9a, 0x91, 0x8e, 0x47, 0xb9,
-	0x66, 0x8c, 0x0d, 0x5f, 0xb9, 0x66, 0x5c, 0x9b, 0xb7, 0x9e, 0x42, 0x11, 0xe7, 0x28, 0x92, 0x57,
-	0x4f, 0x32, 0x83, 0x96, 0x9d, 0x17, 0xae, 0x91, 0x01, 0xec, 0x37, 0x58, 0x77, 0xd5, 0x5b, 0x34,
-	0xbf, 0xe2, 0x66, 0xfe, 0x3c, 0xb2, 0xef, 0xcd, 0xc0, 0x39, 0x14, 0x6f, 0xde, 0x71, 0xad, 0x19,
-	0xfe, 0xc1, 0x99, 0x2e, 0x7e, 0xec, 0xbf, 0x22, 0x0e, 0xb5, 0x6c, 0x3b, 0x25, 0x6e, 0x0e, 0x74,
-	0xc2, 0x24, 0x62, 0xb7, 0x67, 0xe6, 0x37, 0x07, 0x7e, 0x8b, 0x6f, 0x82, 0xd1, 0x56, 0x4b, 0x36,
-	0x72, 0xdd, 0x31, 0xb1, 0xa9, 0xdb, 0x0f, 0xe6, 0xc2, 0x98, 0xc3, 0x3d, 0xdd, 0xca, 0x4d, 0xbb,
-	0x26, 0xf9, 0x9d, 0x69, 0xd0, 0xf2, 0xed, 0x19, 0xf9, 0x5a, 0xd6, 0x87, 0x16, 0xe6, 0x19, 0x8e,
-	0x70, 0xb9, 0xb2, 0x33, 0xb3, 0x6d, 0x6e, 0x9e, 0x65, 0x67, 0xc1, 0xed, 0xda, 0x77, 0xaf, 0xee,
-	0x5a, 0xff, 0x7c, 0x75, 0xd7, 0xfa, 0xcf, 0xab, 0xbb, 0xd6, 0xf9, 0xb2, 0xfa, 0x63, 0xfe, 0xc1,
-	0x0f, 0x01, 0x00, 0x00, 0xff, 0xff, 0x92, 0x5d, 0x25, 0xb8, 0xea, 0x18, 0x00, 0x00,
+	// 2321 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x38, 0x4b, 0x8f, 0x1b, 0xc7,
+	0xd1, 0x9a, 0x25, 0x97, 0x8f, 0xe2, 0x63, 0xe9, 0xb6, 0x3f, 0x7f, 0xa3, 0x81, 0x21, 0xaf, 0x27,
+	0xca, 0x9a, 0x7a, 0x98, 0x54, 0x56, 0x36, 0x56, 0x91, 0x0c, 0x3b, 0xe2, 0x3e, 0xa0, 0x95, 0x77,
+	0x25, 0xa6, 0xd7, 0x81, 0x00, 0xc3, 0x01, 0x32, 0xcb, 0x69, 0x72, 0x07, 0x1a, 0xce, 0x4c, 0x7a,
+	0x9a, 0x92, 0xd6, 0xbe, 0xe4, 0x81, 0x00, 0xb9, 0x07, 0x48, 0x8e, 0x01, 0xf2, 0x0b, 0x72, 0xcb,
+	0x2d, 0x67, 0x1f, 0x73, 0x0e, 0x02, 0x23, 0xd0, 0x8f, 0x08, 0x90, 0x5b, 0x50, 0xdd, 0x3d, 0xe4,
+	0x90, 0xcb, 0x1d, 0x92, 0x30, 0x7c, 0x9a, 0xae, 0xea, 0xaa, 0xea, 0x7a, 0x75, 0x55, 0x4d, 0x43,
+	0x6d, 0xe0, 0x08, 0xf6, 0xd2, 0x39, 0x6f, 0x45, 0x3c, 0x14, 0x21, 0xb9, 0x3a, 0x0c, 0x4f, 0xcf,
+	0x5b, 0xa7, 0x23, 0xcf, 0x77, 0x9f, 0x7b, 0xa2, 0xf5, 0xe2, 0x47, 0xad, 0x3e, 0x0f, 0x03, 0xc1,
+	0x02, 0xd7, 0xfa, 0x60, 0xe0, 0x89, 0xb3, 0xd1, 0x69, 0xab, 0x17, 0x0e, 0xdb, 0x83, 0x70, 0x10,
+	0xb6, 0x25, 0xc7, 0xe9, 0xa8, 0x2f, 0x21, 0x09, 0xc8, 0x95, 0x92, 0x64, 0x6d, 0xcf, 0x92, 0x0f,
+	0xc2, 0x70, 0xe0, 0x33, 0x27, 0xf2, 0x62, 0xbd, 0x6c, 0xf3, 0xa8, 0xd7, 0x8e, 0x85, 0x23, 0x46,
+	0xb1, 0xe6, 0xb9, 0x9d, 0xe2, 0x41, 0x45, 0xda, 0x89, 0x22, 0xed, 0x38, 0xf4, 0x5f, 0x30, 0xde,
+	0x8e, 0x4e, 0xdb, 0x61, 0x94, 0x50, 0xb7, 0x2f, 0xa5, 0x76, 0x22, 0xaf, 0x2d, 0xce, 0x23, 0x16,
+	0xb7, 0x5f, 0x86, 0xfc, 0x39, 0xe3, 0x9a, 0xe1, 0xee, 0xa5, 0x0c, 0x23, 0xe1, 0xf9, 0xc8, 0xd5,
+	0x73, 0xa2, 0x18, 0x0f, 0xc1, 0xaf, 0x66, 0x4a, 0x9b, 0x2d, 0xc2, 0xc0, 0x8b, 0x85, 0xe7, 0x0d,
+	0xbc, 0x76, 0x3f, 0x96, 0x3c, 0xea, 0x14, 0x34, 0x42, 0x91, 0xdb, 0xbf, 0xcf, 0x41, 0x81, 0xb2,
+	0x78, 0xe4, 0x0b, 0xb2, 0x05, 0x35, 0xce, 0xfa, 0x7b, 0x2c, 0xe2, 0xac, 0xe7, 0x08, 0xe6, 0x9a,
+	0xc6, 0xa6, 0xd1, 0x2c, 0x3f, 0xba, 0x42, 0xa7, 0xd1, 0xe4, 0x67, 0x50, 0xe7, 0xac, 0x1f, 0xa7,
+	0x08, 0xd7, 0x36, 0x8d, 0x66, 0x65, 0xfb, 0x56, 0xeb, 0xd2, 0x60, 0xb4, 0x28, 0xeb, 0x1f, 0x3b,
+	0xd1, 0x84, 0xe5, 0xd1, 0x15, 0x3a, 0x23, 0x84, 0x6c, 0x43, 0x8e, 0xb3, 0xbe, 0x99, 0x93, 0xb2,
+	0xae, 0x65, 0xcb, 0x7a, 0x74, 0x85, 0x22, 0x31, 0xd9, 0x81, 0x3c, 0x4a, 0x31, 0xf3, 0x92, 0xe9,
+	0xbd, 0x85, 0x0a, 0x3c, 0xba, 0x42, 0x25, 0x03, 0xf9, 0x0c, 0x4a, 0x43, 0x26, 0x1c, 0xd7, 0x11,
+	0x8e, 0x09, 0x9b, 0xb9, 0x66, 0x65, 0xbb, 0x9d, 0xc9, 0x8c, 0x0e, 0x6a, 0x1d, 0x6b, 0x8e, 0xfd,
+	0x40, 0xf0, 0x73, 0x3a, 0x16, 0x60, 0x3d, 0x80, 0xda, 0xd4, 0x16, 0x69, 0x40, 0xee, 0x39, 0x3b,
+	0x57, 0xfe, 0xa3, 0xb8, 0x24, 0x6f, 0xc1, 0xfa, 0x0b, 0xc7, 0x1f, 0x31, 0xe9, 0xaa, 0x2a, 0x55,
+	0xc0, 0xfd, 0xb5, 0x7b, 0x46, 0xa7, 0x04, 0x05, 0x2e, 0xc5, 0xdb, 0x7f, 0x34, 0xa0, 0x31, 0xeb,
+	0x27, 0x72, 0xa8, 0x2d, 0x34, 0xa4, 0x92, 0x1f, 0xad, 0xe0, 0x62, 0x44, 0xc4, 0x4a, 0x55, 0x29,
+	0xc2, 0xda, 0x81, 0xf2, 0x18, 0xb5, 0x48, 0xc5, 0x72, 0x4a, 0x45, 0x7b, 0x07, 0x72, 0x94, 0xf5,
+	0x49, 0x1d, 0xd6, 0x3c, 0x9d, 0x14, 0x74, 0xcd, 0x73, 0xc9, 0x26, 0xe4, 0x5c, 0xd6, 0xd7, 0xc1,
+	0xaf, 0xb7, 0xa2, 0xd3, 0xd6, 0x1e, 0xeb, 0x7b, 0x81, 0x27, 0xbc, 0x30, 0xa0, 0xb8, 0x65, 0xff,
+	0xc5, 0x80, 0x82, 0x52, 0x8b, 0x7c, 0x3a, 0x65, 0xc7, 0xe2, 0x54, 0xb9, 0xa0, 0xfd, 0xb3, 0x6c,
+	0xed, 0x3f, 0x4c, 0x6b, 0xbf, 0x30, 0x7f, 0xd2, 0xd6, 0x09, 0xa8, 0x51, 0x26, 0x46, 0x3c, 0xa0,
+	0xec, 0x97, 0x23, 0x16, 0x0b, 0xf2, 0xe3, 0x24, 0x22, 0xa6, 0xb1, 0x44, 0x5a, 0x21, 0x21, 0xd5,
+	0x0c, 0xa4, 0x09, 0xeb, 0x8c, 0xf3, 0x90, 0x6b, 0x2d, 0x48, 0x4b, 0x55, 0x8e, 0x16, 0x8f, 0x7a,
+	0xad, 0x13, 0x59, 0x39, 0xa8, 0x22, 0xb0, 0x1b, 0x50, 0x4f, 0x4e, 0x8d, 0xa3, 0x30, 0x88, 0x99,
+	0xbd, 0x01, 0xb5, 0xc3, 0x20, 0x1a, 0x89, 0x58, 0xeb, 0x61, 0xff, 0xdd, 0x80, 0x7a, 0x82, 0x51,
+	0x34, 0xe4, 0x4b, 0xa8, 0x4c, 0x7c, 0x9c, 0x38, 0xf3, 0x7e, 0x86, 0x7e, 0xd3, 0xfc, 0xa9, 0x00,
+	0x69, 0xdf, 0xa6, 0xc5, 0x59, 0x4f, 0xa0, 0x31, 0x4b, 0x30, 0xc7, 0xd3, 0xd7, 0xa7, 0x3d, 0x3d,
+	0x1b, 0xf8, 0x94, 0x67, 0xff, 0x60, 0xc0, 0x55, 0xca, 0x64, 0x29, 0x3c, 0x1c, 0x3a, 0x03, 0xb6,
+	0x1b, 0x06, 0x7d, 0x6f, 0x90, 0xb8, 0xb9, 0x21, 0xb3, 0x2a, 0x91, 0x8c, 0x09, 0xd6, 0x84, 0x52,
+	0xd7, 0x77, 0x44, 0x3f, 0xe4, 0x43, 0x2d, 0xbc, 0x8a, 0xc2, 0x13, 0x1c, 0x1d, 0xef, 0x92, 0x4d,
+	0xa8, 0x68, 0xc1, 0xc7, 0xa1, 0xcb, 0x64, 0xcd, 0x28, 0xd3, 0x34, 0x8a, 0x98, 0x50, 0x3c, 0x0a,
+	0x07, 0x4f, 0x9c, 0x21, 0x93, 0xc5, 0xa1, 0x4c, 0x13, 0xd0, 0xfe, 0x95, 0x01, 0xd6, 0x3c, 0xad,
+	0xb4, 0x8b, 0x1f, 0x43, 0x61, 0xcf, 0x1b, 0xb0, 0x58, 0x45, 0xbf, 0xdc, 0xd9, 0xfe, 0xe6, 0xdb,
+	0x77, 0xaf, 0xfc, 0xf3, 0xdb, 0x77, 0x6f, 0xa6, 0xea, 0x6a, 0x18, 0xb1, 0xa0, 0x17, 0x06, 0xc2,
+	0xf1, 0x02, 0xc6, 0xb1, 0x3d, 0x7c, 0xe0, 0x4a, 0x96, 0x96, 0xe2, 0xa4, 0x5a, 0x02, 0x79, 0x1b,
+	0x0a, 0x4a, 0xba, 0xbe, 0xf6, 0x1a, 0xb2, 0xff, 0x56, 0x80, 0xea, 0x09, 0x2a, 0x90, 0xf8, 0xa2,
+	0x05, 0x30, 0x71, 0xa1, 0x69, 0xcc, 0x75, 0x6c, 0x8a, 0x82, 0x58, 0x50, 0x3a, 0xd0, 0x21, 0xd6,
+	0xd7, 0x75, 0x0c, 0x93, 0x2f, 0xa0, 0x92, 0xac, 0x9f, 0x46, 0xc2, 0xcc, 0xc9, 0x1c, 0xb9, 0x97,
+	0x91, 0x23, 0x69, 0x4d, 0x5a, 0x29, 0x56, 0x9d, 0x21, 0x29, 0x0c, 0xf9, 0x18, 0xae, 0x1e, 0x0e,
+	0xa3, 0x90, 0x8b, 0x5d, 0xa7, 0x77, 0xc6, 0xe8, 0x74, 0x17, 0xc8, 0x6f, 0xe6, 0x9a, 0x65, 0x7a,
+	0x39, 0x01, 0xb9, 0x0d, 0x6f, 0x38, 0xbe, 0x1f, 0xbe, 0xd4, 0x97, 0x46, 0xa6, 0xbf, 0xb9, 0xbe,
+	0x69, 0x34, 0x4b, 0xf4, 0xe2, 0x06, 0xb9, 0x03, 0x6f, 0xa6, 0x90, 0x0f, 0x39, 0x77, 0xce, 0x31,
+	0x5f, 0x0a, 0x92, 0x7e, 0xde, 0x16, 0x56, 0xb0, 0x03, 0x2f, 0x70, 0x7c, 0x13, 0x24, 0x8d, 0x02,
+	0x88, 0x0d, 0xd5, 0xfd, 0x57, 0xa8, 0x12, 0xe3, 0x0f, 0x85, 0xe0, 0x66, 0x45, 0x86, 0x62, 0x0a,
+	0x47, 0xba, 0x50, 0x95, 0x0a, 0x2b, 0xdd, 0x63, 0xb3, 0x2a, 0x9d, 0x76, 0x3b, 0xc3, 0x69, 0x92,
+	0xfc, 0x69, 0x94, 0xba, 0x4a, 0x53, 0x12, 0x48, 0x0f, 0xea, 0x89, 0xe3, 0xd4, 0x1d, 0x34, 0x6b,
+	0x52, 0xe6, 0x83, 0x55, 0x03, 0xa1, 0xb8, 0xd5, 0x11, 0x33, 0x22, 0x31, 0x0d, 0xf6, 0xf1, 0xba,
+	0x39, 0x82, 0x99, 0x75, 0x69, 0xf3, 0x18, 0x26, 0x0f, 0xa1, 0x70, 0x3a, 0x72, 0x07, 0x4c, 0x98,
+	0x1b, 0x32, 0x9d, 0x6e, 0x64, 0x1c, 0x9c, 0x88, 0xed, 0x48, 0x06, 0xaa, 0x19, 0xad, 0x4f, 0xa0,
+	0x31, 0x9b, 0x0e, 0xab, 0xf4, 0x0d, 0xeb, 0xa7, 0xf0, 0xe6, 0x1c, 0x2b, 0xbe, 0x53, 0x49, 0x39,
+	0x83, 0xfa, 0xb4, 0xb2, 0x78, 0xd1, 0x85, 0x37, 0x64, 0xe1, 0x48, 0x5d, 0xd8, 0x1c, 0x4d, 0x40,
+	0x3c, 0xa7, 0x17, 0x8d, 0xa4, 0x4c, 0x83, 0xe2, 0x12, 0xef, 0xe3, 0x90, 0x0d, 0x43, 0x7e, 0x2e,
+	0x2b, 0x46, 0x8e, 0x6a, 0x88, 0x10, 0xc8, 0x47, 0x9e, 0xab, 0xc6, 0x88, 0x1c, 0x95, 0x6b, 0xfb,
+	0xaf, 0x06, 0xbc, 0x71, 0x21, 0xc8, 0x48, 0xf9, 0xf9, 0x79, 0xc4, 0xb4, 0xf2, 0x72, 0x4d, 0x8e,
+	0x61, 0x1d, 0x93, 0x28, 0x36, 0xd7, 0x64, 0x84, 0x77, 0x56, 0xc9, 0x9a, 0x96, 0xe4, 0x94, 0x4b,
+	0xaa, 0xa4, 0x58, 0xf7, 0x00, 0x26, 0xc8, 0x95, 0xfa, 0xf4, 0x97, 0x50, 0xd3, 0x29, 0xa4, 0x6b,
+	0x59, 0x43, 0x8d, 0x54, 0x9a, 0x19, 0x07, 0xa6, 0x49, 0x6f, 0xcb, 0xad, 0xd8, 0xdb, 0xec, 0xaf,
+	0x61, 0x83, 0x32, 0xc7, 0x3d, 0xf0, 0x7c, 0x76, 0x79, 0x09, 0xc7, 0xc2, 0xe4, 0xf9, 0xac, 0xeb,
+	0x88, 0xb3, 0x71, 0x61, 0xd2, 0x30, 0xb9, 0x0f, 0xeb, 0xd4, 0x09, 0x06, 0x4c, 0x1f, 0x7d, 0x3d,
+	0x2b, 0x21, 0xf1, 0x10, 0xa4, 0xa5, 0x8a, 0xc5, 0x7e, 0x00, 0xe5, 0x31, 0x0e, 0xc3, 0xf8, 0xb4,
+	0xdf, 0x8f, 0x59, 0x12, 0x71, 0x0d, 0x21, 0xfe, 0x88, 0x05, 0x03, 0x7d, 0x74, 0x8e, 0x6a, 0xc8,
+	0xde, 0x82, 0xc6, 0x44, 0x73, 0xed, 0x1a, 0x02, 0xf9, 0x3d, 0x1c, 0xfe, 0x0c, 0x59, 0x0d, 0xe4,
+	0xda, 0x76, 0xb1, 0x27, 0x3b, 0xee, 0x9e, 0xc7, 0x2f, 0x37, 0xd0, 0x84, 0xe2, 0x9e, 0xc7, 0x53,
+	0xf6, 0x25, 0x20, 0xd9, 0xc2, 0x6e, 0xdd, 0xf3, 0x47, 0x2e, 0x5a, 0x2b, 0x18, 0x0f, 0x74, 0x5b,
+	0x9a, 0xc1, 0xda, 0x9f, 0xc2, 0xc6, 0xf8, 0x14, 0xad, 0xcc, 0x6d, 0x28, 0xb2, 0x40, 0x70, 0x8f,
+	0x25, 0x2d, 0x9d, 0xb4, 0xd4, 0xbc, 0xde, 0x92, 0xf3, 0xba, 0x1c, 0x1d, 0x68, 0x42, 0x62, 0xef,
+	0xc0, 0x06, 0x22, 0xb2, 0x03, 0x41, 0x20, 0x9f, 0x52, 0x52, 0xae, 0xed, 0xfb, 0xd0, 0x98, 0x30,
+	0xea, 0xa3, 0xb7, 0x20, 0x8f, 0x7f, 0x03, 0xba, 0xe7, 0xcc, 0x3b, 0x57, 0xee, 0xdb, 0x35, 0xa8,
+	0x74, 0xbd, 0x20, 0x69, 0xde, 0xf6, 0x6f, 0xd6, 0xa0, 0xda, 0x0d, 0x83, 0x49, 0xdb, 0xec, 0xc2,
+	0x46, 0x72, 0x31, 0x1f, 0x76, 0x0f, 0x77, 0x9d, 0x28, 0x31, 0x65, 0xf3, 0x62, 0x98, 0xf5, 0x8f,
+	0x4b, 0x4b, 0x11, 0x76, 0xf2, 0xd8, 0x61, 0xe9, 0x2c, 0x3b, 0xf9, 0x09, 0x14, 0x8f, 0x8e, 0x3a,
+	0x52, 0xd2, 0xda, 0x4a, 0x92, 0x12, 0x36, 0xf2, 0x09, 0x14, 0x9f, 0xc9, 0xff, 0xa9, 0x58, 0x77,
+	0xc1, 0x39, 0x29, 0xa7, 0x0c, 0x55, 0x64, 0x94, 0xf5, 0x42, 0xee, 0xd2, 0x84, 0x89, 0x5c, 0x03,
+	0x18, 0x3a, 0xaf, 0x8e, 0x8e, 0x3a, 0x27, 0xde, 0x57, 0x4c, 0x17, 0x87, 0x14, 0xc6, 0xfe, 0x8f,
+	0x01, 0x95, 0x67, 0xce, 0x64, 0x70, 0x7c, 0x0c, 0x05, 0xf7, 0x3b, 0x8f, 0x0e, 0x0a, 0xc4, 0x5b,
+	0xee, 0xb3, 0x17, 0xcc, 0xd7, 0xa9, 0xac, 0x00, 0xc4, 0xc6, 0x67, 0x21, 0x57, 0xb7, 0xb7, 0x4a,
+	0x15, 0x80, 0x79, 0xef, 0x32, 0xe1, 0x78, 0xbe, 0x6c, 0xc1, 0x55, 0xaa, 0x21, 0xcc, 0x8a, 0x11,
+	0xf7, 0x65, 0x87, 0x2d, 0x53, 0x5c, 0x12, 0x1b, 0xf2, 0x5e, 0xd0, 0x0f, 0xcd, 0xc2, 0xa4, 0xce,
+	0x9e, 0x84, 0x23, 0xde, 0x63, 0x87, 0x41, 0x3f, 0xa4, 0x72, 0x8f, 0xbc, 0x07, 0x05, 0x8e, 0xd7,
+	0x2c, 0x36, 0x8b, 0xd2, 0x69, 0x65, 0xa4, 0x52, 0x97, 0x51, 0x6f, 0xd8, 0x75, 0xa8, 0x2a, 0xbb,
+	0xf5, 0xe8, 0xfa, 0x3b, 0x03, 0x36, 0xba, 0x9c, 0xf5, 0x99, 0xe8, 0x9d, 0x25, 0xce, 0x78, 0x1b,
+	0x0a, 0x72, 0xbc, 0x52, 0x79, 0x50, 0xa6, 0x1a, 0x5a, 0x61, 0xc8, 0xbb, 0x33, 0x3d, 0xec, 0xaa,
+	0x10, 0xce, 0xf6, 0x86, 0x34, 0x89, 0x4d, 0xa0, 0x31, 0x51, 0x43, 0xeb, 0xf6, 0xaf, 0x35, 0x78,
+	0xf3, 0x09, 0x7b, 0xb9, 0x9b, 0xf8, 0x3c, 0xd1, 0x6f, 0x13, 0x2a, 0x63, 0xdc, 0xe1, 0x9e, 0xbe,
+	0x3a, 0x69, 0x14, 0x3a, 0xe2, 0x38, 0x1c, 0x05, 0x22, 0xc9, 0x3f, 0xe9, 0x08, 0x89, 0xa1, 0x7a,
+	0x83, 0xfc, 0x10, 0x8a, 0x4f, 0x98, 0xc0, 0x9f, 0x76, 0x19, 0x91, 0xfa, 0x76, 0x05, 0x69, 0x9e,
+	0x30, 0x81, 0x33, 0x28, 0x4d, 0xf6, 0xd0, 0xe6, 0x28, 0xb1, 0x39, 0x3f, 0xcf, 0xe6, 0x64, 0x97,
+	0xec, 0x40, 0xa5, 0x17, 0x06, 0xb1, 0xe0, 0x8e, 0x87, 0x07, 0xaf, 0x4b, 0xe2, 0xff, 0x43, 0x62,
+	0x95, 0x94, 0xbb, 0x93, 0x4d, 0x9a, 0xa6, 0x24, 0x37, 0x01, 0xd8, 0x2b, 0xc1, 0x9d, 0x47, 0x61,
+	0x2c, 0x62, 0xb3, 0x20, 0x15, 0x06, 0xe4, 0x43, 0xc4, 0x61, 0x97, 0xa6, 0x76, 0xc9, 0x63, 0xa8,
+	0x46, 0xa3, 0x53, 0xdf, 0x8b, 0xcf, 0xba, 0x72, 0xda, 0x51, 0x71, 0xde, 0xca, 0xa8, 0xc7, 0x48,
+	0x77, 0xec, 0x44, 0x11, 0x56, 0x80, 0x29, 0x5e, 0xfb, 0xb7, 0x06, 0x54, 0x52, 0xbb, 0xd8, 0x00,
+	0xce, 0xc2, 0x58, 0x20, 0x4a, 0xfa, 0x74, 0x9d, 0x8e, 0x61, 0x72, 0x1d, 0x6a, 0xe3, 0xd4, 0x97,
+	0x04, 0x6b, 0x92, 0x60, 0x1a, 0x89, 0x12, 0xe4, 0xd3, 0x44, 0x2f, 0xf4, 0x75, 0x05, 0x1d, 0xc3,
+	0x98, 0x54, 0x67, 0xd2, 0x1e, 0x3d, 0xd4, 0x6b, 0xc8, 0x7e, 0x1b, 0xde, 0x9a, 0x8e, 0xb1, 0x0e,
+	0xfe, 0x03, 0xf8, 0x7f, 0xca, 0x7c, 0xe6, 0xc4, 0x6c, 0xf5, 0xf8, 0xdb, 0x16, 0x98, 0x17, 0x99,
+	0xb5, 0xe0, 0xff, 0xe6, 0xa0, 0xb2, 0xff, 0x8a, 0xf5, 0x8e, 0x59, 0x1c, 0x3b, 0x03, 0x46, 0xde,
+	0x81, 0x72, 0x97, 0x87, 0x3d, 0x16, 0xc7, 0x63, 0x59, 0x13, 0x04, 0xf9, 0x18, 0xf2, 0x87, 0x81,
+	0x27, 0x74, 0xbe, 0x6f, 0x65, 0xfe, 0xaf, 0x79, 0x42, 0xcb, 0xc4, 0xb7, 0x0a, 0x04, 0xc9, 0x7d,
+	0xc8, 0x63, 0xc9, 0x5e, 0xa6, 0x6d, 0xba, 0x29, 0x5e, 0xe4, 0x21, 0x1d, 0xf9, 0xba, 0x93, 0x94,
+	0xaf, 0xca, 0x76, 0x33, 0xbb, 0xdf, 0x7b, 0x5f, 0xb1, 0x89, 0x04, 0xcd, 0x49, 0xf6, 0xa1, 0x78,
+	0x22, 0x1c, 0x8e, 0x23, 0xfe, 0xfa, 0xc2, 0x51, 0x52, 0x53, 0x4e, 0xa4, 0x24, 0xbc, 0xe8, 0x84,
+	0xfd, 0x57, 0x9e, 0x30, 0x0b, 0x0b, 0x9d, 0x80, 0x64, 0x29, 0x43, 0x10, 0x44, 0xee, 0xbd, 0x30,
+	0x60, 0x66, 0x71, 0x21, 0x37, 0x92, 0xa5, 0xb8, 0x11, 0x44, 0x37, 0x9c, 0x78, 0x03, 0xfc, 0x35,
+	0x28, 0x2d, 0x74, 0x83, 0x22, 0x4c, 0xb9, 0x41, 0x21, 0x3a, 0x45, 0x58, 0x97, 0x53, 0xac, 0xfd,
+	0x67, 0x03, 0x2a, 0xa9, 0x38, 0x2d, 0x51, 0x49, 0xde, 0x81, 0x3c, 0x3e, 0x10, 0xe9, 0xf8, 0x97,
+	0x64, 0x1d, 0x61, 0xc2, 0xa1, 0x12, 0x8b, 0x65, 0xfa, 0xc0, 0x55, 0xf5, 0xad, 0x46, 0x71, 0x89,
+	0x98, 0xcf, 0xc5, 0xb9, 0x0c, 0x59, 0x89, 0xe2, 0x92, 0xdc, 0x86, 0xd2, 0x09, 0xeb, 0x8d, 0xb8,
+	0x27, 0xce, 0x65, 0x10, 0xea, 0xdb, 0x0d, 0x59, 0xbc, 0x35, 0x4e, 0x96, 0x9b, 0x31, 0x85, 0xfd,
+	0x19, 0x26, 0xe7, 0x44, 0x41, 0x02, 0xf9, 0x5d, 0xfc, 0x4d, 0x46, 0xcd, 0x6a, 0x54, 0xae, 0xf1,
+	0xa5, 0x62, 0x7f, 0xd1, 0x4b, 0xc5, 0x7e, 0xf2, 0x52, 0x31, 0x1d, 0x54, 0x9c, 0x05, 0x52, 0x4e,
+	0xb6, 0x1f, 0x42, 0x79, 0x9c, 0x78, 0xf8, 0x48, 0x74, 0xe0, 0xea, 0x93, 0xd6, 0x0e, 0x5c, 0x34,
+	0x65, 0xff, 0xe9, 0x81, 0x3c, 0xa5, 0x44, 0x71, 0x39, 0x9e, 0xbc, 0x72, 0xa9, 0xc9, 0x6b, 0x07,
+	0x6a, 0x2a, 0xd9, 0x52, 0x2a, 0xd3, 0xf0, 0x65, 0x9c, 0xa8, 0x8c, 0x6b, 0x65, 0x86, 0x1f, 0x9b,
+	0x6b, 0x89, 0x19, 0x7e, 0x6c, 0xff, 0x00, 0x6a, 0x53, 0xf1, 0x42, 0x22, 0xf9, 0xd3, 0xaf, 0x07,
+	0x74, 0x5c, 0x6f, 0xff, 0xa9, 0x0a, 0xe5, 0xa3, 0xa3, 0x4e, 0x87, 0x7b, 0xee, 0x80, 0x91, 0x5f,
+	0x1b, 0x40, 0x2e, 0xfe, 0xff, 0x93, 0x0f, 0xb3, 0x6f, 0xc6, 0xfc, 0x47, 0x0c, 0xeb, 0xa3, 0x15,
+	0xb9, 0xf4, 0xb4, 0xf4, 0x05, 0xac, 0xcb, 0x49, 0x9d, 0xbc, 0xbf, 0xe4, 0xef, 0xa0, 0xd5, 0x5c,
+	0x4c, 0xa8, 0x65, 0xf7, 0xa0, 0x94, 0x4c, 0xbb, 0xe4, 0x66, 0xa6, 0x7a, 0x53, 0xc3, 0xbc, 0x75,
+	0x6b, 0x29, 0x5a, 0x7d, 0xc8, 0x2f, 0xa0, 0xa8, 0x87, 0x58, 0x72, 0x63, 0x01, 0xdf, 0x64, 0x9c,
+	0xb6, 0x6e, 0x2e, 0x43, 0x3a, 0x31, 0x23, 0x19, 0x56, 0x33, 0xcd, 0x98, 0x19, 0x85, 0xad, 0x5b,
+	0x4b, 0xd1, 0xea, 0x43, 0x9e, 0xab, 0x89, 0x1f, 0x71, 0x27, 0x82, 0x33, 0x67, 0xf8, 0xbd, 0x79,
+	0xec, 0x8e, 0x41, 0x9e, 0x43, 0x63, 0x6c, 0x64, 0x6f, 0xc4, 0x63, 0xef, 0x05, 0xfb, 0x9e, 0x9c,
+	0x77, 0xc7, 0x20, 0xcf, 0x20, 0x8f, 0xf3, 0x3a, 0xc9, 0xec, 0xea, 0x93, 0x81, 0xde, 0xca, 0x4a,
+	0xc4, 0xa9, 0x41, 0xff, 0xe7, 0x50, 0xd0, 0x0f, 0x34, 0xd9, 0xbd, 0x24, 0xf5, 0xa2, 0x6a, 0xdd,
+	0x58, 0x82, 0x72, 0x22, 0x5e, 0x3f, 0x6e, 0x34, 0x97, 0x78, 0xd6, 0x5c, 0x2c, 0x7e, 0xe6, 0x01,
+	0x35, 0x84, 0x6a, 0x7a, 0x50, 0x20, 0xad, 0x0c, 0xd6, 0x39, 0x53, 0xa3, 0xd5, 0x5e, 0x9a, 0x5e,
+	0x1f, 0xf8, 0x35, 0x34, 0x66, 0x87, 0x08, 0xb2, 0x9d, 0xe9, 0x8e, 0xb9, 0xe3, 0x8a, 0x75, 0x77,
+	0x25, 0x1e, 0x7d, 0xb8, 0xa3, 0x86, 0x14, 0x3d, 0x88, 0x90, 0xec, 0x9e, 0x3b, 0x1e, 0x66, 0xac,
+	0x25, 0xe9, 0x9a, 0x86, 0xca, 0x33, 0xfc, 0x15, 0xc8, 0x94, 0x9d, 0xfa, 0x47, 0xb2, 0xde, 0x5f,
+	0x48, 0x37, 0xb9, 0xff, 0xc9, 0x2c, 0x9f, 0x79, 0x29, 0x67, 0xfe, 0x3b, 0xac, 0x5b, 0x4b, 0xd1,
+	0xaa, 0x43, 0x3a, 0xd5, 0x6f, 0x5e, 0x5f, 0x33, 0xfe, 0xf1, 0xfa, 0x9a, 0xf1, 0xef, 0xd7, 0xd7,
+	0x8c, 0xd3, 0x82, 0x9c, 0x33, 0xef, 0xfe, 0x6f, 0x00, 0x39, 0x2e, 0xe0, 0x9a, 0x64, 0x1c, 0x00,
+	0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -2414,6 +2715,10 @@ type LLBBridgeClient interface {
 	ReadDir(ctx context.Context, in *ReadDirRequest, opts ...grpc.CallOption) (*ReadDirResponse, error)
 	// apicaps:CapStatFile
 	StatFile(ctx context.Context, in *StatFileRequest, opts ...grpc.CallOption) (*StatFileResponse, error)
+	// apicaps:CapReadFileStream
+	ReadFileStream(ctx context.Context, in *ReadFileRequest, opts ...grpc.CallOption) (LLBBridge_ReadFileStreamClient, error)
+	// apicaps:CapReadDirRecursive
+	ReadDirRecursive(ctx context.Context, in *ReadDirRequest, opts ...grpc.CallOption) (LLBBridge_ReadDirRecursiveClient, error)
 	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PongResponse, error)
 	Return(ctx context.Context, in *ReturnRequest, opts ...grpc.CallOption) (*ReturnResponse, error)
 	// apicaps:CapFrontendInputs
@@ -2423,6 +2728,8 @@ type LLBBridgeClient interface {
 	ExecProcess(ctx context.Context, opts ...grpc.CallOption) (LLBBridge_ExecProcessClient, error)
 	// apicaps:CapGatewayWarnings
 	Warn(ctx context.Context, in *WarnRequest, opts ...grpc.CallOption) (*WarnResponse, error)
+	// apicaps:CapGatewayPrefetch
+	Prefetch(ctx context.Context, in *PrefetchRequest, opts ...grpc.CallOption) (*PrefetchResponse, error)
 }
 
 type lLBBridgeClient struct {
@@ -2478,34 +2785,98 @@ func (c *lLBBridgeClient) StatFile(ctx context.Context, in *StatFileRequest, opt
 	return out, nil
 }
 
-func (c *lLBBridgeClient) Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PongResponse, error) {
-	out := new(PongResponse)
-	err := c.cc.Invoke(ctx, "/moby.buildkit.v1.frontend.LLBBridge/Ping", in, out, opts...)
+func (c *lLBBridgeClient) ReadFileStream(ctx context.Context, in *ReadFileRequest, opts ...grpc.CallOption) (LLBBridge_ReadFileStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_LLBBridge_serviceDesc.Streams[0], "/moby.buildkit.v1.frontend.LLBBridge/ReadFileStream", opts...)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	x := &lLBBridgeReadFileStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
 }
 
-func (c *lLBBridgeClient) Return(ctx context.Context, in *ReturnRequest, opts ...grpc.CallOption) (*ReturnResponse, error) {
-	out := new(ReturnResponse)
-	err := c.cc.Invoke(ctx, "/moby.buildkit.v1.frontend.LLBBridge/Return", in, out, opts...)
-	if err != nil {
+type LLBBridge_ReadFileStreamClient interface {
+	Recv() (*ReadFileResponse, error)
+	grpc.ClientStream
+}
+
+type lLBBridgeReadFileStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *lLBBridgeReadFileStreamClient) Recv() (*ReadFileResponse, error) {
+	m := new(ReadFileResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
 		return nil, err
 	}
-	return out, nil
+	return m, nil
 }
 
-func (c *lLBBridgeClient) Inputs(ctx context.Context, in *InputsRequest, opts ...grpc.CallOption) (*InputsResponse, error) {
-	out := new(InputsResponse)
-	err := c.cc.Invoke(ctx, "/moby.buildkit.v1.frontend.LLBBridge/Inputs", in, out, opts...)
+func (c *lLBBridgeClient) ReadDirRecursive(ctx context.Context, in *ReadDirRequest, opts ...grpc.CallOption) (LLBBridge_ReadDirRecursiveClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_LLBBridge_serviceDesc.Streams[1], "/moby.buildkit.v1.frontend.LLBBridge/ReadDirRecursive", opts...)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
-}
-
-func (c *lLBBridgeClient) NewContainer(ctx context.Context, in *NewContainerRequest, opts ...grpc.CallOption) (*NewContainerResponse, error) {
+	x := &lLBBridgeReadDirRecursiveClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LLBBridge_ReadDirRecursiveClient interface {
+	Recv() (*ReadDirResponse, error)
+	grpc.ClientStream
+}
+
+type lLBBridgeReadDirRecursiveClient struct {
+	grpc.ClientStream
+}
+
+func (x *lLBBridgeReadDirRecursiveClient) Recv() (*ReadDirResponse, error) {
+	m := new(ReadDirResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *lLBBridgeClient) Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PongResponse, error) {
+	out := new(PongResponse)
+	err := c.cc.Invoke(ctx, "/moby.buildkit.v1.frontend.LLBBridge/Ping", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lLBBridgeClient) Return(ctx context.Context, in *ReturnRequest, opts ...grpc.CallOption) (*ReturnResponse, error) {
+	out := new(ReturnResponse)
+	err := c.cc.Invoke(ctx, "/moby.buildkit.v1.frontend.LLBBridge/Return", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lLBBridgeClient) Inputs(ctx context.Context, in *InputsRequest, opts ...grpc.CallOption) (*InputsResponse, error) {
+	out := new(InputsResponse)
+	err := c.cc.Invoke(ctx, "/moby.buildkit.v1.frontend.LLBBridge/Inputs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lLBBridgeClient) NewContainer(ctx context.Context, in *NewContainerRequest, opts ...grpc.CallOption) (*NewContainerResponse, error) {
 	out := new(NewContainerResponse)
 	err := c.cc.Invoke(ctx, "/moby.buildkit.v1.frontend.LLBBridge/NewContainer", in, out, opts...)
 	if err != nil {
@@ -2524,7 +2895,7 @@ func (c *lLBBridgeClient) ReleaseContainer(ctx context.Context, in *ReleaseConta
 }
 
 func (c *lLBBridgeClient) ExecProcess(ctx context.Context, opts ...grpc.CallOption) (LLBBridge_ExecProcessClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_LLBBridge_serviceDesc.Streams[0], "/moby.buildkit.v1.frontend.LLBBridge/ExecProcess", opts...)
+	stream, err := c.cc.NewStream(ctx, &_LLBBridge_serviceDesc.Streams[2], "/moby.buildkit.v1.frontend.LLBBridge/ExecProcess", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -2563,6 +2934,15 @@ func (c *lLBBridgeClient) Warn(ctx context.Context, in *WarnRequest, opts ...grp
 	return out, nil
 }
 
+func (c *lLBBridgeClient) Prefetch(ctx context.Context, in *PrefetchRequest, opts ...grpc.CallOption) (*PrefetchResponse, error) {
+	out := new(PrefetchResponse)
+	err := c.cc.Invoke(ctx, "/moby.buildkit.v1.frontend.LLBBridge/Prefetch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // LLBBridgeServer is the server API for LLBBridge service.
 type LLBBridgeServer interface {
 	// apicaps:CapResolveImage
@@ -2575,6 +2955,10 @@ type LLBBridgeServer interface {
 	ReadDir(context.Context, *ReadDirRequest) (*ReadDirResponse, error)
 	// apicaps:CapStatFile
 	StatFile(context.Context, *StatFileRequest) (*StatFileResponse, error)
+	// apicaps:CapReadFileStream
+	ReadFileStream(*ReadFileRequest, LLBBridge_ReadFileStreamServer) error
+	// apicaps:CapReadDirRecursive
+	ReadDirRecursive(*ReadDirRequest, LLBBridge_ReadDirRecursiveServer) error
 	Ping(context.Context, *PingRequest) (*PongResponse, error)
 	Return(context.Context, *ReturnRequest) (*ReturnResponse, error)
 	// apicaps:CapFrontendInputs
@@ -2584,6 +2968,8 @@ type LLBBridgeServer interface {
 	ExecProcess(LLBBridge_ExecProcessServer) error
 	// apicaps:CapGatewayWarnings
 	Warn(context.Context, *WarnRequest) (*WarnResponse, error)
+	// apicaps:CapGatewayPrefetch
+	Prefetch(context.Context, *PrefetchRequest) (*PrefetchResponse, error)
 }
 
 // UnimplementedLLBBridgeServer can be embedded to have forward compatible implementations.
@@ -2605,6 +2991,12 @@ func (*UnimplementedLLBBridgeServer) ReadDir(ctx context.Context, req *ReadDirRe
 func (*UnimplementedLLBBridgeServer) StatFile(ctx context.Context, req *StatFileRequest) (*StatFileResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method StatFile not implemented")
 }
+func (*UnimplementedLLBBridgeServer) ReadFileStream(req *ReadFileRequest, srv LLBBridge_ReadFileStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method ReadFileStream not implemented")
+}
+func (*UnimplementedLLBBridgeServer) ReadDirRecursive(req *ReadDirRequest, srv LLBBridge_ReadDirRecursiveServer) error {
+	return status.Errorf(codes.Unimplemented, "method ReadDirRecursive not implemented")
+}
 func (*UnimplementedLLBBridgeServer) Ping(ctx context.Context, req *PingRequest) (*PongResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
 }
@@ -2626,6 +3018,9 @@ func (*UnimplementedLLBBridgeServer) ExecProcess(srv LLBBridge_ExecProcessServer
 func (*UnimplementedLLBBridgeServer) Warn(ctx context.Context, req *WarnRequest) (*WarnResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Warn not implemented")
 }
+func (*UnimplementedLLBBridgeServer) Prefetch(ctx context.Context, req *PrefetchRequest) (*PrefetchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Prefetch not implemented")
+}
 
 func RegisterLLBBridgeServer(s *grpc.Server, srv LLBBridgeServer) {
 	s.RegisterService(&_LLBBridge_serviceDesc, srv)
@@ -2721,6 +3116,48 @@ func _LLBBridge_StatFile_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _LLBBridge_ReadFileStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReadFileRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LLBBridgeServer).ReadFileStream(m, &lLBBridgeReadFileStreamServer{stream})
+}
+
+type LLBBridge_ReadFileStreamServer interface {
+	Send(*ReadFileResponse) error
+	grpc.ServerStream
+}
+
+type lLBBridgeReadFileStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *lLBBridgeReadFileStreamServer) Send(m *ReadFileResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LLBBridge_ReadDirRecursive_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReadDirRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LLBBridgeServer).ReadDirRecursive(m, &lLBBridgeReadDirRecursiveServer{stream})
+}
+
+type LLBBridge_ReadDirRecursiveServer interface {
+	Send(*ReadDirResponse) error
+	grpc.ServerStream
+}
+
+type lLBBridgeReadDirRecursiveServer struct {
+	grpc.ServerStream
+}
+
+func (x *lLBBridgeReadDirRecursiveServer) Send(m *ReadDirResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _LLBBridge_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PingRequest)
 	if err := dec(in); err != nil {
@@ -2855,6 +3292,24 @@ func _LLBBridge_Warn_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _LLBBridge_Prefetch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PrefetchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LLBBridgeServer).Prefetch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/moby.buildkit.v1.frontend.LLBBridge/Prefetch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LLBBridgeServer).Prefetch(ctx, req.(*PrefetchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _LLBBridge_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "moby.buildkit.v1.frontend.LLBBridge",
 	HandlerType: (*LLBBridgeServer)(nil),
@@ -2903,8 +3358,22 @@ var _LLBBridge_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Warn",
 			Handler:    _LLBBridge_Warn_Handler,
 		},
+		{
+			MethodName: "Prefetch",
+			Handler:    _LLBBridge_Prefetch_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ReadFileStream",
+			Handler:       _LLBBridge_ReadFileStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ReadDirRecursive",
+			Handler:       _LLBBridge_ReadDirRecursive_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "ExecProcess",
 			Handler:       _LLBBridge_ExecProcess_Handler,
@@ -3477,6 +3946,18 @@ func (m *SolveRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.Budget != nil {
+		{
+			size, err := m.Budget.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintGateway(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x7a
+	}
 	if m.Evaluate {
 		i--
 		if m.Evaluate {
@@ -3614,6 +4095,54 @@ func (m *SolveRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *FrontendBudget) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *FrontendBudget) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *FrontendBudget) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Pids != 0 {
+		i = encodeVarintGateway(dAtA, i, uint64(m.Pids))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.Memory != 0 {
+		i = encodeVarintGateway(dAtA, i, uint64(m.Memory))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.Cpu != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.Cpu))))
+		i--
+		dAtA[i] = 0x11
+	}
+	if m.Timeout != 0 {
+		i = encodeVarintGateway(dAtA, i, uint64(m.Timeout))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *CacheOptionsEntry) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -4057,6 +4586,11 @@ func (m *PongResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.MaxLLBSize != 0 {
+		i = encodeVarintGateway(dAtA, i, uint64(m.MaxLLBSize))
+		i--
+		dAtA[i] = 0x20
+	}
 	if len(m.Workers) > 0 {
 		for iNdEx := len(m.Workers) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -4217,6 +4751,95 @@ func (m *WarnResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *PrefetchRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PrefetchRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *PrefetchRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Definitions) > 0 {
+		for iNdEx := len(m.Definitions) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Definitions[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintGateway(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if m.Platform != nil {
+		{
+			size, err := m.Platform.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintGateway(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Images) > 0 {
+		for iNdEx := len(m.Images) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Images[iNdEx])
+			copy(dAtA[i:], m.Images[iNdEx])
+			i = encodeVarintGateway(dAtA, i, uint64(len(m.Images[iNdEx])))
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *PrefetchResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PrefetchResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *PrefetchResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *NewContainerRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -4241,6 +4864,20 @@ func (m *NewContainerRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.PublishPorts) > 0 {
+		for iNdEx := len(m.PublishPorts) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.PublishPorts[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintGateway(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x3a
+		}
+	}
 	if len(m.ExtraHosts) > 0 {
 		for iNdEx := len(m.ExtraHosts) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -4308,7 +4945,7 @@ func (m *NewContainerRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func (m *NewContainerResponse) Marshal() (dAtA []byte, err error) {
+func (m *PortMapping) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -4318,12 +4955,12 @@ func (m *NewContainerResponse) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *NewContainerResponse) MarshalTo(dAtA []byte) (int, error) {
+func (m *PortMapping) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *NewContainerResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *PortMapping) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
@@ -4332,10 +4969,34 @@ func (m *NewContainerResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.HostIP) > 0 {
+		i -= len(m.HostIP)
+		copy(dAtA[i:], m.HostIP)
+		i = encodeVarintGateway(dAtA, i, uint64(len(m.HostIP)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.Protocol) > 0 {
+		i -= len(m.Protocol)
+		copy(dAtA[i:], m.Protocol)
+		i = encodeVarintGateway(dAtA, i, uint64(len(m.Protocol)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.ContainerPort != 0 {
+		i = encodeVarintGateway(dAtA, i, uint64(m.ContainerPort))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.HostPort != 0 {
+		i = encodeVarintGateway(dAtA, i, uint64(m.HostPort))
+		i--
+		dAtA[i] = 0x8
+	}
 	return len(dAtA) - i, nil
 }
 
-func (m *ReleaseContainerRequest) Marshal() (dAtA []byte, err error) {
+func (m *NewContainerResponse) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -4345,12 +5006,39 @@ func (m *ReleaseContainerRequest) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *ReleaseContainerRequest) MarshalTo(dAtA []byte) (int, error) {
+func (m *NewContainerResponse) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *ReleaseContainerRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *NewContainerResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ReleaseContainerRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ReleaseContainerRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ReleaseContainerRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
@@ -4626,20 +5314,20 @@ func (m *InitMessage) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		dAtA[i] = 0x20
 	}
 	if len(m.Fds) > 0 {
-		dAtA26 := make([]byte, len(m.Fds)*10)
-		var j25 int
+		dAtA28 := make([]byte, len(m.Fds)*10)
+		var j27 int
 		for _, num := range m.Fds {
 			for num >= 1<<7 {
-				dAtA26[j25] = uint8(uint64(num)&0x7f | 0x80)
+				dAtA28[j27] = uint8(uint64(num)&0x7f | 0x80)
 				num >>= 7
-				j25++
+				j27++
 			}
-			dAtA26[j25] = uint8(num)
-			j25++
+			dAtA28[j27] = uint8(num)
+			j27++
 		}
-		i -= j25
-		copy(dAtA[i:], dAtA26[:j25])
-		i = encodeVarintGateway(dAtA, i, uint64(j25))
+		i -= j27
+		copy(dAtA[i:], dAtA28[:j27])
+		i = encodeVarintGateway(dAtA, i, uint64(j27))
 		i--
 		dAtA[i] = 0x1a
 	}
@@ -5212,6 +5900,34 @@ func (m *SolveRequest) Size() (n int) {
 	if m.Evaluate {
 		n += 2
 	}
+	if m.Budget != nil {
+		l = m.Budget.Size()
+		n += 1 + l + sovGateway(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *FrontendBudget) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Timeout != 0 {
+		n += 1 + sovGateway(uint64(m.Timeout))
+	}
+	if m.Cpu != 0 {
+		n += 9
+	}
+	if m.Memory != 0 {
+		n += 1 + sovGateway(uint64(m.Memory))
+	}
+	if m.Pids != 0 {
+		n += 1 + sovGateway(uint64(m.Pids))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -5434,6 +6150,9 @@ func (m *PongResponse) Size() (n int) {
 			n += 1 + l + sovGateway(uint64(l))
 		}
 	}
+	if m.MaxLLBSize != 0 {
+		n += 1 + sovGateway(uint64(m.MaxLLBSize))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -5495,6 +6214,46 @@ func (m *WarnResponse) Size() (n int) {
 	return n
 }
 
+func (m *PrefetchRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Images) > 0 {
+		for _, s := range m.Images {
+			l = len(s)
+			n += 1 + l + sovGateway(uint64(l))
+		}
+	}
+	if m.Platform != nil {
+		l = m.Platform.Size()
+		n += 1 + l + sovGateway(uint64(l))
+	}
+	if len(m.Definitions) > 0 {
+		for _, e := range m.Definitions {
+			l = e.Size()
+			n += 1 + l + sovGateway(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *PrefetchResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
 func (m *NewContainerRequest) Size() (n int) {
 	if m == nil {
 		return 0
@@ -5528,6 +6287,38 @@ func (m *NewContainerRequest) Size() (n int) {
 			n += 1 + l + sovGateway(uint64(l))
 		}
 	}
+	if len(m.PublishPorts) > 0 {
+		for _, e := range m.PublishPorts {
+			l = e.Size()
+			n += 1 + l + sovGateway(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *PortMapping) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.HostPort != 0 {
+		n += 1 + sovGateway(uint64(m.HostPort))
+	}
+	if m.ContainerPort != 0 {
+		n += 1 + sovGateway(uint64(m.ContainerPort))
+	}
+	l = len(m.Protocol)
+	if l > 0 {
+		n += 1 + l + sovGateway(uint64(l))
+	}
+	l = len(m.HostIP)
+	if l > 0 {
+		n += 1 + l + sovGateway(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -7846,6 +8637,161 @@ func (m *SolveRequest) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.Evaluate = bool(v != 0)
+		case 15:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Budget", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGateway
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGateway
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGateway
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Budget == nil {
+				m.Budget = &FrontendBudget{}
+			}
+			if err := m.Budget.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGateway(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthGateway
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *FrontendBudget) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGateway
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FrontendBudget: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FrontendBudget: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timeout", wireType)
+			}
+			m.Timeout = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGateway
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Timeout |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Cpu", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.Cpu = float64(math.Float64frombits(v))
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Memory", wireType)
+			}
+			m.Memory = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGateway
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Memory |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Pids", wireType)
+			}
+			m.Pids = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGateway
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Pids |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGateway(dAtA[iNdEx:])
@@ -9138,6 +10084,25 @@ func (m *PongResponse) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxLLBSize", wireType)
+			}
+			m.MaxLLBSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGateway
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxLLBSize |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGateway(dAtA[iNdEx:])
@@ -9481,7 +10446,7 @@ func (m *WarnResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *NewContainerRequest) Unmarshal(dAtA []byte) error {
+func (m *PrefetchRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -9504,15 +10469,15 @@ func (m *NewContainerRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: NewContainerRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: PrefetchRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: NewContainerRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: PrefetchRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ContainerID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Images", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -9540,7 +10505,211 @@ func (m *NewContainerRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.ContainerID = string(dAtA[iNdEx:postIndex])
+			m.Images = append(m.Images, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Platform", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGateway
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGateway
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGateway
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Platform == nil {
+				m.Platform = &pb.Platform{}
+			}
+			if err := m.Platform.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Definitions", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGateway
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGateway
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGateway
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Definitions = append(m.Definitions, &pb.Definition{})
+			if err := m.Definitions[len(m.Definitions)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGateway(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthGateway
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PrefetchResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGateway
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PrefetchResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PrefetchResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGateway(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthGateway
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *NewContainerRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGateway
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: NewContainerRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: NewContainerRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ContainerID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGateway
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGateway
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGateway
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ContainerID = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
@@ -9701,6 +10870,193 @@ func (m *NewContainerRequest) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PublishPorts", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGateway
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGateway
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGateway
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PublishPorts = append(m.PublishPorts, &PortMapping{})
+			if err := m.PublishPorts[len(m.PublishPorts)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGateway(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthGateway
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PortMapping) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGateway
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PortMapping: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PortMapping: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HostPort", wireType)
+			}
+			m.HostPort = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGateway
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.HostPort |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ContainerPort", wireType)
+			}
+			m.ContainerPort = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGateway
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ContainerPort |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Protocol", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGateway
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGateway
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGateway
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Protocol = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HostIP", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGateway
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGateway
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGateway
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.HostIP = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGateway(dAtA[iNdEx:])
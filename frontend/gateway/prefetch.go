@@ -0,0 +1,72 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/frontend"
+	gwclient "github.com/moby/buildkit/frontend/gateway/client"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/solver"
+	opspb "github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/worker"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Prefetch solves the definitions and pulls the images of the request in the
+// background, so their results are in the cache when the frontend solves the
+// build that needs them. The prefetches are canceled with ctx. Failures are
+// only logged, the solve of the build reports them if the results are needed.
+func Prefetch(ctx context.Context, llbBridge frontend.FrontendLLBBridge, sid string, req gwclient.PrefetchRequest) error {
+	defs := append([]*opspb.Definition{}, req.Definitions...)
+	for _, ref := range req.Images {
+		var opts []llb.ImageOption
+		if p := req.Platform; p != nil {
+			opts = append(opts, llb.Platform(ocispecs.Platform{
+				OS:           p.OS,
+				Architecture: p.Architecture,
+				Variant:      p.Variant,
+				OSVersion:    p.OSVersion,
+				OSFeatures:   p.OSFeatures,
+			}))
+		}
+		def, err := llb.Image(ref, opts...).Marshal(ctx)
+		if err != nil {
+			return err
+		}
+		defs = append(defs, def.ToPB())
+	}
+
+	for _, def := range defs {
+		go prefetch(ctx, llbBridge, sid, def)
+	}
+	return nil
+}
+
+func prefetch(ctx context.Context, llbBridge frontend.FrontendLLBBridge, sid string, def *opspb.Definition) {
+	res, err := llbBridge.Solve(ctx, frontend.SolveRequest{
+		Definition: def,
+		Evaluate:   true,
+	}, sid)
+	if err != nil {
+		bklog.G(ctx).Debugf("prefetch failed: %v", err)
+		return
+	}
+	res.EachRef(func(r solver.ResultProxy) error {
+		defer r.Release(context.TODO())
+		cr, err := r.Result(ctx)
+		if err != nil {
+			bklog.G(ctx).Debugf("prefetch failed: %v", err)
+			return nil
+		}
+		// image layers are pulled lazily, extract them to have them
+		// available when the build needs them
+		if workerRef, ok := cr.Sys().(*worker.WorkerRef); ok && workerRef.ImmutableRef != nil {
+			if err := workerRef.ImmutableRef.Extract(ctx, session.NewGroup(sid)); err != nil {
+				bklog.G(ctx).Debugf("prefetch failed to extract %s: %v", workerRef.ID(), err)
+			}
+		}
+		return nil
+	})
+}
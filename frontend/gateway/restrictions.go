@@ -0,0 +1,262 @@
+package gateway
+
+import (
+	"path"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	pb "github.com/moby/buildkit/frontend/gateway/pb"
+	opspb "github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/apicaps"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// Restrictions limit what the frontends run from untrusted images can do.
+// Untrusted frontends can't solve requests of other frontends or publish the
+// ports of their containers, the others features are allowed by the fields of
+// the restrictions. The daemon
+// advertises the features it disables in the capabilities of the gateway.
+type Restrictions struct {
+	// Trusted are the repositories of the frontend images run without
+	// restrictions, as path.Match patterns, e.g. docker.io/docker/dockerfile.
+	Trusted []string
+	// Network allows the frontend containers, the containers they create
+	// through the gateway and the processes of the definitions they solve to
+	// access the network. The processes run without network otherwise. The
+	// sources of the definitions are fetched by the daemon and are not
+	// restricted.
+	Network bool
+	// Secrets allows the frontends to use secrets and SSH agent sockets in
+	// the definitions they solve and in the containers they create.
+	Secrets bool
+	// MaxLLBSize is the maximum size in bytes of the definitions the
+	// frontends can solve, 0 if unlimited.
+	MaxLLBSize int64
+}
+
+// forSource returns the restrictions of the frontend image source, or nil if
+// the image is trusted. Frontends built by development gateways are never
+// trusted.
+func (r *Restrictions) forSource(source string, devel bool) *Restrictions {
+	if r == nil {
+		return nil
+	}
+	if devel {
+		return r
+	}
+	named, err := reference.ParseNormalizedNamed(source)
+	if err != nil {
+		return r
+	}
+	for _, p := range r.Trusted {
+		if ok, _ := path.Match(p, named.Name()); ok {
+			return nil
+		}
+	}
+	return r
+}
+
+// caps returns the capabilities of the gateway with the features disabled by
+// the restrictions.
+func (r *Restrictions) caps() []apicaps.PBCap {
+	caps := pb.Caps.All()
+	if r == nil {
+		return caps
+	}
+	for i, c := range caps {
+		switch apicaps.CapID(c.ID) {
+		case pb.CapGatewayNetwork:
+			if r.Network {
+				continue
+			}
+		case pb.CapGatewaySecrets:
+			if r.Secrets {
+				continue
+			}
+		case pb.CapGatewaySolveFrontend, pb.CapGatewayExecPublishPorts:
+		default:
+			continue
+		}
+		caps[i].Enabled = false
+		caps[i].DisabledReason = "restricted"
+		caps[i].DisabledReasonMsg = "disabled by the daemon for untrusted frontends"
+	}
+	return caps
+}
+
+// secretSourceAttrs are the attributes of the sources that use secrets or
+// SSH agent sockets of the client.
+var secretSourceAttrs = []string{
+	opspb.AttrAuthHeaderSecret,
+	opspb.AttrAuthTokenSecret,
+	opspb.AttrMountSSHSock,
+	opspb.AttrHTTPAuthHeaderSecret,
+	opspb.AttrHTTPAuthTokenSecret,
+	opspb.AttrObjectStoreSecret,
+}
+
+// restrictSolve returns an error if the solve request uses features disabled
+// by the restrictions, and restricts its definition.
+func (r *Restrictions) restrictSolve(req *pb.SolveRequest) error {
+	if r == nil {
+		return nil
+	}
+	if req.Frontend != "" {
+		return errors.Errorf("untrusted frontends can't solve requests of frontend %s", req.Frontend)
+	}
+	def, err := r.restrictDefinition(req.Definition)
+	if err != nil {
+		return err
+	}
+	req.Definition = def
+	return nil
+}
+
+// restrictDefinition returns an error if the definition uses features
+// disabled by the restrictions. The processes of the definition run without
+// network unless the network is allowed, so the digests of their operations
+// and of the operations depending on them change and are updated in the
+// returned definition.
+func (r *Restrictions) restrictDefinition(def *opspb.Definition) (*opspb.Definition, error) {
+	if r == nil || def == nil {
+		return def, nil
+	}
+	if r.MaxLLBSize > 0 {
+		if size := int64(def.Size()); size > r.MaxLLBSize {
+			return nil, errors.Errorf("definition of %d bytes exceeds the maximum size %d of untrusted frontends", size, r.MaxLLBSize)
+		}
+	}
+	if r.Secrets && r.Network {
+		return def, nil
+	}
+
+	var (
+		out      = make([][]byte, 0, len(def.Def))
+		digests  = map[digest.Digest]digest.Digest{}
+		modified bool
+	)
+	for _, dt := range def.Def {
+		var op opspb.Op
+		if err := op.Unmarshal(dt); err != nil {
+			return nil, errors.Wrap(err, "failed to parse definition")
+		}
+		dgst := digest.FromBytes(dt)
+
+		var mutated bool
+		for _, inp := range op.Inputs {
+			if d, ok := digests[inp.Digest]; ok {
+				inp.Digest = d
+				mutated = true
+			}
+		}
+		switch op := op.Op.(type) {
+		case *opspb.Op_Source:
+			if err := r.checkSource(op.Source); err != nil {
+				return nil, err
+			}
+		case *opspb.Op_Exec:
+			if len(op.Exec.Secretenv) > 0 && !r.Secrets {
+				return nil, errors.New("untrusted frontends can't use secrets")
+			}
+			if err := r.checkMounts(op.Exec.Mounts); err != nil {
+				return nil, err
+			}
+			if mode := r.netMode(op.Exec.Network); mode != op.Exec.Network {
+				op.Exec.Network = mode
+				mutated = true
+			}
+		}
+
+		if mutated {
+			var err error
+			dt, err = op.Marshal()
+			if err != nil {
+				return nil, err
+			}
+			digests[dgst] = digest.FromBytes(dt)
+			modified = true
+		}
+		out = append(out, dt)
+	}
+	if !modified {
+		return def, nil
+	}
+
+	ndef := &opspb.Definition{
+		Def:      out,
+		Metadata: make(map[digest.Digest]opspb.OpMetadata, len(def.Metadata)),
+		Source:   def.Source,
+	}
+	for dgst, md := range def.Metadata {
+		if d, ok := digests[dgst]; ok {
+			dgst = d
+		}
+		ndef.Metadata[dgst] = md
+	}
+	if def.Source != nil && len(def.Source.Locations) > 0 {
+		ndef.Source = &opspb.Source{
+			Infos:     def.Source.Infos,
+			Locations: make(map[string]*opspb.Locations, len(def.Source.Locations)),
+		}
+		for k, l := range def.Source.Locations {
+			if d, ok := digests[digest.Digest(k)]; ok {
+				k = d.String()
+			}
+			ndef.Source.Locations[k] = l
+		}
+	}
+	return ndef, nil
+}
+
+// checkSource returns an error if the source uses secrets disabled by the
+// restrictions. The git sources of llb.Git set the GIT_AUTH_TOKEN and
+// GIT_AUTH_HEADER secrets by default, untrusted frontends need to unset them.
+func (r *Restrictions) checkSource(src *opspb.SourceOp) error {
+	if r == nil || r.Secrets {
+		return nil
+	}
+	for _, k := range secretSourceAttrs {
+		if v, ok := src.Attrs[k]; ok && v != "" {
+			return errors.Errorf("untrusted frontends can't use secrets or SSH sockets with %s of source %s", k, src.Identifier)
+		}
+	}
+	return nil
+}
+
+// checkMounts returns an error if the mounts use secrets or SSH sockets
+// disabled by the restrictions.
+func (r *Restrictions) checkMounts(mounts []*opspb.Mount) error {
+	if r == nil || r.Secrets {
+		return nil
+	}
+	for _, m := range mounts {
+		switch m.MountType {
+		case opspb.MountType_SECRET:
+			return errors.Errorf("untrusted frontends can't mount secrets to %s", m.Dest)
+		case opspb.MountType_SSH:
+			if m.SSHOpt != nil && strings.HasPrefix(m.SSHOpt.ID, opspb.SSHSocketIDPrefix) {
+				return errors.Errorf("untrusted frontends can't forward sockets to %s", m.Dest)
+			}
+			return errors.Errorf("untrusted frontends can't mount SSH agent sockets to %s", m.Dest)
+		}
+	}
+	return nil
+}
+
+// checkPublishPorts returns an error if the container of the frontend
+// publishes ports.
+func (r *Restrictions) checkPublishPorts(ports []*pb.PortMapping) error {
+	if r == nil || len(ports) == 0 {
+		return nil
+	}
+	return errors.New("untrusted frontends can't publish ports")
+}
+
+// netMode returns the network mode of the containers of the frontend.
+func (r *Restrictions) netMode(mode opspb.NetMode) opspb.NetMode {
+	if r == nil || r.Network {
+		return mode
+	}
+	return opspb.NetMode_NONE
+}
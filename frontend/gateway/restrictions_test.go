@@ -0,0 +1,208 @@
+package gateway
+
+import (
+	"testing"
+
+	pb "github.com/moby/buildkit/frontend/gateway/pb"
+	opspb "github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/apicaps"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestrictionsForSource(t *testing.T) {
+	r := &Restrictions{Trusted: []string{"docker.io/docker/dockerfile", "registry.example.com/frontends/*"}}
+	require.Nil(t, r.forSource("docker/dockerfile:1", false))
+	require.Nil(t, r.forSource("docker.io/docker/dockerfile@sha256:dc7b4ab8d81384f7ddaa7a4ea2d6d3f1ef22a6d9e24d9ba2e4af8f8e6a8dd2f5", false))
+	require.Nil(t, r.forSource("registry.example.com/frontends/foo:latest", false))
+	require.Equal(t, r, r.forSource("docker/dockerfile:1", true))
+	require.Equal(t, r, r.forSource("example.com/docker/dockerfile:1", false))
+	require.Equal(t, r, r.forSource("registry.example.com/frontends/foo/bar", false))
+
+	var none *Restrictions
+	require.Nil(t, none.forSource("example.com/frontend", false))
+}
+
+func TestRestrictionsCaps(t *testing.T) {
+	caps := pb.Caps.CapSet((&Restrictions{Network: true}).caps())
+	require.NoError(t, caps.Supports(pb.CapGatewayNetwork))
+	require.Error(t, caps.Supports(pb.CapGatewaySecrets))
+	require.Error(t, caps.Supports(pb.CapGatewaySolveFrontend))
+	require.Error(t, caps.Supports(pb.CapGatewayExecPublishPorts))
+	require.NoError(t, caps.Supports(pb.CapSolveBase))
+
+	var none *Restrictions
+	caps = pb.Caps.CapSet(none.caps())
+	for _, id := range []apicaps.CapID{pb.CapGatewayNetwork, pb.CapGatewaySecrets, pb.CapGatewaySolveFrontend, pb.CapGatewayExecPublishPorts} {
+		require.NoError(t, caps.Supports(id))
+	}
+	// the caps of the gateway are not modified
+	caps = pb.Caps.CapSet(pb.Caps.All())
+	require.NoError(t, caps.Supports(pb.CapGatewaySecrets))
+}
+
+func TestRestrictionsRestrictSolve(t *testing.T) {
+	secretOp := &opspb.Op{
+		Op: &opspb.Op_Exec{Exec: &opspb.ExecOp{
+			Meta:    &opspb.Meta{Args: []string{"cat", "/run/secrets/foo"}},
+			Network: opspb.NetMode_NONE,
+			Mounts: []*opspb.Mount{
+				{Dest: "/"},
+				{Dest: "/run/secrets/foo", MountType: opspb.MountType_SECRET, SecretOpt: &opspb.SecretOpt{ID: "foo"}},
+			},
+		}},
+	}
+	dt, err := secretOp.Marshal()
+	require.NoError(t, err)
+	req := &pb.SolveRequest{Definition: &opspb.Definition{Def: [][]byte{dt}}}
+
+	r := &Restrictions{}
+	err = r.restrictSolve(req)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "can't mount secrets")
+
+	r.Secrets = true
+	require.NoError(t, r.restrictSolve(req))
+
+	r.MaxLLBSize = 10
+	err = r.restrictSolve(req)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "exceeds the maximum size")
+
+	err = r.restrictSolve(&pb.SolveRequest{Frontend: "dockerfile.v0"})
+	require.Error(t, err)
+
+	var none *Restrictions
+	require.NoError(t, none.restrictSolve(&pb.SolveRequest{Frontend: "dockerfile.v0"}))
+	require.Equal(t, opspb.NetMode_UNSET, none.netMode(opspb.NetMode_UNSET))
+	require.Equal(t, opspb.NetMode_NONE, (&Restrictions{}).netMode(opspb.NetMode_UNSET))
+}
+
+func TestRestrictionsNetwork(t *testing.T) {
+	src := &opspb.Op{
+		Op: &opspb.Op_Source{Source: &opspb.SourceOp{Identifier: "docker-image://docker.io/library/busybox:latest"}},
+	}
+	srcDt, err := src.Marshal()
+	require.NoError(t, err)
+	srcDgst := digest.FromBytes(srcDt)
+
+	exec := &opspb.Op{
+		Inputs: []*opspb.Input{{Digest: srcDgst}},
+		Op: &opspb.Op_Exec{Exec: &opspb.ExecOp{
+			Meta:   &opspb.Meta{Args: []string{"wget", "example.com"}},
+			Mounts: []*opspb.Mount{{Dest: "/"}},
+		}},
+	}
+	execDt, err := exec.Marshal()
+	require.NoError(t, err)
+	execDgst := digest.FromBytes(execDt)
+
+	out := &opspb.Op{Inputs: []*opspb.Input{{Digest: execDgst}}}
+	outDt, err := out.Marshal()
+	require.NoError(t, err)
+	outDgst := digest.FromBytes(outDt)
+
+	def := &opspb.Definition{
+		Def: [][]byte{srcDt, execDt, outDt},
+		Metadata: map[digest.Digest]opspb.OpMetadata{
+			execDgst: {Description: map[string]string{"llb.customname": "wget"}},
+		},
+		Source: &opspb.Source{Locations: map[string]*opspb.Locations{
+			execDgst.String(): {},
+		}},
+	}
+
+	// the processes of the definitions run without network
+	req := &pb.SolveRequest{Definition: def}
+	require.NoError(t, (&Restrictions{Secrets: true}).restrictSolve(req))
+	ndef := req.Definition
+	require.Len(t, ndef.Def, 3)
+	require.Equal(t, srcDt, ndef.Def[0])
+
+	var op opspb.Op
+	require.NoError(t, op.Unmarshal(ndef.Def[1]))
+	require.Equal(t, opspb.NetMode_NONE, op.GetExec().Network)
+	require.Equal(t, srcDgst, op.Inputs[0].Digest)
+	nexecDgst := digest.FromBytes(ndef.Def[1])
+	require.NotEqual(t, execDgst, nexecDgst)
+	require.Equal(t, "wget", ndef.Metadata[nexecDgst].Description["llb.customname"])
+	require.Contains(t, ndef.Source.Locations, nexecDgst.String())
+
+	op = opspb.Op{}
+	require.NoError(t, op.Unmarshal(ndef.Def[2]))
+	require.Equal(t, nexecDgst, op.Inputs[0].Digest)
+	require.NotEqual(t, outDgst, digest.FromBytes(ndef.Def[2]))
+
+	// the definitions of the allowed network are unchanged
+	ndef, err = (&Restrictions{Secrets: true, Network: true}).restrictDefinition(def)
+	require.NoError(t, err)
+	require.Equal(t, def, ndef)
+
+	var none *Restrictions
+	ndef, err = none.restrictDefinition(def)
+	require.NoError(t, err)
+	require.Equal(t, def, ndef)
+}
+
+func TestRestrictionsSourceSecrets(t *testing.T) {
+	for _, tc := range []struct {
+		identifier string
+		attr       string
+	}{
+		{"git://github.com/moby/buildkit", opspb.AttrAuthHeaderSecret},
+		{"git://github.com/moby/buildkit", opspb.AttrAuthTokenSecret},
+		{"git://github.com/moby/buildkit", opspb.AttrMountSSHSock},
+		{"https://example.com/foo", opspb.AttrHTTPAuthHeaderSecret},
+		{"https://example.com/foo", opspb.AttrHTTPAuthTokenSecret},
+		{"s3://bucket/foo", opspb.AttrObjectStoreSecret},
+	} {
+		tc := tc
+		t.Run(tc.attr, func(t *testing.T) {
+			op := &opspb.Op{
+				Op: &opspb.Op_Source{Source: &opspb.SourceOp{
+					Identifier: tc.identifier,
+					Attrs:      map[string]string{tc.attr: "foo"},
+				}},
+			}
+			dt, err := op.Marshal()
+			require.NoError(t, err)
+			def := &opspb.Definition{Def: [][]byte{dt}}
+
+			_, err = (&Restrictions{Network: true}).restrictDefinition(def)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.attr)
+
+			_, err = (&Restrictions{Network: true, Secrets: true}).restrictDefinition(def)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRestrictionsCheckMounts(t *testing.T) {
+	r := &Restrictions{}
+	require.NoError(t, r.checkMounts([]*opspb.Mount{{Dest: "/"}}))
+
+	err := r.checkMounts([]*opspb.Mount{{Dest: "/run/ssh", MountType: opspb.MountType_SSH, SSHOpt: &opspb.SSHOpt{ID: "default"}}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "can't mount SSH agent sockets")
+
+	err = r.checkMounts([]*opspb.Mount{{Dest: "/run/docker.sock", MountType: opspb.MountType_SSH, SSHOpt: &opspb.SSHOpt{ID: opspb.SSHSocketIDPrefix + "docker"}}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "can't forward sockets")
+
+	r.Secrets = true
+	require.NoError(t, r.checkMounts([]*opspb.Mount{{Dest: "/run/ssh", MountType: opspb.MountType_SSH}}))
+}
+
+func TestRestrictionsCheckPublishPorts(t *testing.T) {
+	ports := []*pb.PortMapping{{HostPort: 8080, ContainerPort: 80}}
+
+	var none *Restrictions
+	require.NoError(t, none.checkPublishPorts(ports))
+
+	r := &Restrictions{Network: true, Secrets: true}
+	require.NoError(t, r.checkPublishPorts(nil))
+	err := r.checkPublishPorts(ports)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "can't publish ports")
+}
@@ -4,7 +4,9 @@ import (
 	"net"
 
 	"github.com/moby/buildkit/executor"
+	gwpb "github.com/moby/buildkit/frontend/gateway/pb"
 	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/network"
 	"github.com/pkg/errors"
 )
 
@@ -22,3 +24,20 @@ func ParseExtraHosts(ips []*pb.HostIP) ([]executor.HostIP, error) {
 	}
 	return out, nil
 }
+
+// ParsePortMappings converts the ports published by the gateway containers.
+func ParsePortMappings(ports []*gwpb.PortMapping) []network.PortMapping {
+	if len(ports) == 0 {
+		return nil
+	}
+	out := make([]network.PortMapping, len(ports))
+	for i, p := range ports {
+		out[i] = network.PortMapping{
+			HostPort:      p.HostPort,
+			ContainerPort: p.ContainerPort,
+			Protocol:      p.Protocol,
+			HostIP:        p.HostIP,
+		}
+	}
+	return out
+}
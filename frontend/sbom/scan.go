@@ -0,0 +1,211 @@
+// Package sbom finds the OS and language packages installed in build
+// results, so that frontends can record them in the build info.
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/gateway/client"
+	binfotypes "github.com/moby/buildkit/util/buildinfo/types"
+	fstypes "github.com/tonistiigi/fsutil/types"
+)
+
+const (
+	dpkgStatus    = "/var/lib/dpkg/status"
+	dpkgStatusDir = "/var/lib/dpkg/status.d"
+	apkInstalled  = "/lib/apk/db/installed"
+)
+
+// pythonLibDirs are the directories holding the python* directories with
+// the site-packages and dist-packages of the python installations.
+var pythonLibDirs = []string{"/usr/lib", "/usr/local/lib"}
+
+// FS is the filesystem of a build result. client.Reference implements it.
+type FS interface {
+	ReadFile(ctx context.Context, req client.ReadRequest) ([]byte, error)
+	ReadDir(ctx context.Context, req client.ReadDirRequest) ([]*fstypes.Stat, error)
+}
+
+// Scan returns the packages installed with dpkg (apt), apk and pip in the
+// filesystem, sorted by type and name. The package databases missing in the
+// filesystem are skipped.
+func Scan(ctx context.Context, fs FS) ([]binfotypes.Package, error) {
+	var pkgs []binfotypes.Package
+
+	// a missing file and a failed read can't be told apart through the
+	// gateway, so read errors mean the database is missing
+	if dt, err := fs.ReadFile(ctx, client.ReadRequest{Filename: dpkgStatus}); err == nil {
+		pkgs = append(pkgs, parseDpkgStatus(dt)...)
+	}
+	// distroless images have a status file per package instead
+	if stats, err := fs.ReadDir(ctx, client.ReadDirRequest{Path: dpkgStatusDir}); err == nil {
+		for _, st := range stats {
+			if !os.FileMode(st.Mode).IsRegular() {
+				continue
+			}
+			dt, err := fs.ReadFile(ctx, client.ReadRequest{Filename: path.Join(dpkgStatusDir, st.Path)})
+			if err != nil {
+				continue
+			}
+			pkgs = append(pkgs, parseDpkgStatus(dt)...)
+		}
+	}
+	if dt, err := fs.ReadFile(ctx, client.ReadRequest{Filename: apkInstalled}); err == nil {
+		pkgs = append(pkgs, parseApkInstalled(dt)...)
+	}
+	pkgs = append(pkgs, scanPython(ctx, fs)...)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return dedupe(pkgs), nil
+}
+
+// parseDpkgStatus returns the installed packages of a dpkg status file.
+func parseDpkgStatus(dt []byte) []binfotypes.Package {
+	var pkgs []binfotypes.Package
+	for _, p := range paragraphs(dt, ": ") {
+		if p["Package"] == "" || !strings.HasSuffix(p["Status"], " installed") {
+			continue
+		}
+		pkgs = append(pkgs, binfotypes.Package{
+			Type:    binfotypes.PackageTypeDeb,
+			Name:    p["Package"],
+			Version: p["Version"],
+		})
+	}
+	return pkgs
+}
+
+// parseApkInstalled returns the packages of an apk installed database.
+func parseApkInstalled(dt []byte) []binfotypes.Package {
+	var pkgs []binfotypes.Package
+	for _, p := range paragraphs(dt, ":") {
+		if p["P"] == "" {
+			continue
+		}
+		pkgs = append(pkgs, binfotypes.Package{
+			Type:    binfotypes.PackageTypeApk,
+			Name:    p["P"],
+			Version: p["V"],
+		})
+	}
+	return pkgs
+}
+
+// paragraphs splits the blank line separated paragraphs of key-value lines.
+// Continuation lines starting with a space are skipped.
+func paragraphs(dt []byte, sep string) []map[string]string {
+	var out []map[string]string
+	p := map[string]string{}
+	s := bufio.NewScanner(bytes.NewReader(dt))
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for s.Scan() {
+		line := s.Text()
+		if strings.TrimSpace(line) == "" {
+			if len(p) > 0 {
+				out = append(out, p)
+				p = map[string]string{}
+			}
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		parts := strings.SplitN(line, sep, 2)
+		if len(parts) != 2 {
+			continue
+		}
+		p[parts[0]] = strings.TrimSpace(parts[1])
+	}
+	if len(p) > 0 {
+		out = append(out, p)
+	}
+	return out
+}
+
+// scanPython returns the packages installed with pip in the site-packages
+// and dist-packages directories of the python installations.
+func scanPython(ctx context.Context, fs FS) []binfotypes.Package {
+	var pkgs []binfotypes.Package
+	for _, lib := range pythonLibDirs {
+		pythons, err := fs.ReadDir(ctx, client.ReadDirRequest{Path: lib, IncludePattern: "python*"})
+		if err != nil {
+			continue
+		}
+		for _, py := range pythons {
+			if !os.FileMode(py.Mode).IsDir() {
+				continue
+			}
+			for _, dir := range []string{"site-packages", "dist-packages"} {
+				stats, err := fs.ReadDir(ctx, client.ReadDirRequest{Path: path.Join(lib, py.Path, dir)})
+				if err != nil {
+					continue
+				}
+				for _, st := range stats {
+					if p, ok := parsePythonDistInfo(st.Path); ok {
+						pkgs = append(pkgs, p)
+					}
+				}
+			}
+		}
+	}
+	return pkgs
+}
+
+// parsePythonDistInfo returns the package of a name-version.dist-info or
+// name-version[-pyX.Y].egg-info metadata directory or file.
+func parsePythonDistInfo(name string) (binfotypes.Package, bool) {
+	var base string
+	switch {
+	case strings.HasSuffix(name, ".dist-info"):
+		base = strings.TrimSuffix(name, ".dist-info")
+	case strings.HasSuffix(name, ".egg-info"):
+		base = strings.TrimSuffix(name, ".egg-info")
+	default:
+		return binfotypes.Package{}, false
+	}
+	// names and versions are escaped to not contain dashes
+	parts := strings.Split(base, "-")
+	if len(parts) < 2 || parts[0] == "" {
+		return binfotypes.Package{}, false
+	}
+	return binfotypes.Package{
+		Type:    binfotypes.PackageTypePyPI,
+		Name:    normalizePythonName(parts[0]),
+		Version: parts[1],
+	}, true
+}
+
+// normalizePythonName returns the normalized name of a python project, as
+// used in package URLs.
+func normalizePythonName(name string) string {
+	name = strings.ToLower(name)
+	return strings.NewReplacer("_", "-", ".", "-").Replace(name)
+}
+
+func dedupe(pkgs []binfotypes.Package) []binfotypes.Package {
+	sort.Slice(pkgs, func(i, j int) bool {
+		if pkgs[i].Type != pkgs[j].Type {
+			return pkgs[i].Type < pkgs[j].Type
+		}
+		if pkgs[i].Name != pkgs[j].Name {
+			return pkgs[i].Name < pkgs[j].Name
+		}
+		return pkgs[i].Version < pkgs[j].Version
+	})
+	out := pkgs[:0]
+	for _, p := range pkgs {
+		if len(out) > 0 && p == out[len(out)-1] {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
@@ -0,0 +1,116 @@
+package sbom
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/moby/buildkit/frontend/gateway/client"
+	binfotypes "github.com/moby/buildkit/util/buildinfo/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	fstypes "github.com/tonistiigi/fsutil/types"
+)
+
+// testFS is a filesystem of files and directories, directories ending
+// with a slash.
+type testFS map[string]string
+
+func (fs testFS) ReadFile(ctx context.Context, req client.ReadRequest) ([]byte, error) {
+	dt, ok := fs[req.Filename]
+	if !ok {
+		return nil, errors.Errorf("%s not found", req.Filename)
+	}
+	return []byte(dt), nil
+}
+
+func (fs testFS) ReadDir(ctx context.Context, req client.ReadDirRequest) ([]*fstypes.Stat, error) {
+	if _, ok := fs[req.Path+"/"]; !ok {
+		return nil, errors.Errorf("%s not found", req.Path)
+	}
+	var stats []*fstypes.Stat
+	for p := range fs {
+		name := strings.TrimPrefix(p, req.Path+"/")
+		if name == p || name == "" || strings.Contains(strings.TrimSuffix(name, "/"), "/") {
+			continue
+		}
+		mode := uint32(0644)
+		if strings.HasSuffix(name, "/") {
+			mode = uint32(os.ModeDir | 0755)
+			name = strings.TrimSuffix(name, "/")
+		}
+		if req.IncludePattern != "" {
+			if ok, _ := path.Match(req.IncludePattern, name); !ok {
+				continue
+			}
+		}
+		stats = append(stats, &fstypes.Stat{Path: name, Mode: mode})
+	}
+	return stats, nil
+}
+
+func TestScan(t *testing.T) {
+	fs := testFS{
+		"/var/lib/dpkg/status": `Package: libc6
+Status: install ok installed
+Version: 2.36-9
+Description: GNU C Library
+ continuation: line
+
+Package: removed
+Status: deinstall ok config-files
+Version: 1.0
+`,
+		"/var/lib/dpkg/status.d/":        "",
+		"/var/lib/dpkg/status.d/tzdata":  "Package: tzdata\nStatus: install ok installed\nVersion: 2024a-0\n",
+		"/var/lib/dpkg/status.d/subdir/": "",
+		"/lib/apk/db/installed": `C:Q1abc=
+P:musl
+V:1.2.4-r2
+
+P:busybox
+V:1.36.1-r5
+`,
+		"/usr/lib/":                       "",
+		"/usr/lib/python3/":               "",
+		"/usr/lib/python3/dist-packages/": "",
+		"/usr/lib/python3/dist-packages/six-1.16.0.egg-info": "",
+		"/usr/local/lib/":                          "",
+		"/usr/local/lib/python3.11/":               "",
+		"/usr/local/lib/python3.11/site-packages/": "",
+		"/usr/local/lib/python3.11/site-packages/Flask_Login-0.6.3.dist-info/": "",
+		"/usr/local/lib/python3.11/site-packages/requests-2.31.0.dist-info/":   "",
+		"/usr/local/lib/python3.11/site-packages/requests/":                    "",
+		"/usr/local/lib/python3.11/site-packages/pip-24.0.dist-info/":          "",
+		"/usr/local/lib/python3.12/site-packages/pip-24.0.dist-info/":          "",
+	}
+	pkgs, err := Scan(context.TODO(), fs)
+	require.NoError(t, err)
+	require.Equal(t, []binfotypes.Package{
+		{Type: binfotypes.PackageTypeApk, Name: "busybox", Version: "1.36.1-r5"},
+		{Type: binfotypes.PackageTypeApk, Name: "musl", Version: "1.2.4-r2"},
+		{Type: binfotypes.PackageTypeDeb, Name: "libc6", Version: "2.36-9"},
+		{Type: binfotypes.PackageTypeDeb, Name: "tzdata", Version: "2024a-0"},
+		{Type: binfotypes.PackageTypePyPI, Name: "flask-login", Version: "0.6.3"},
+		{Type: binfotypes.PackageTypePyPI, Name: "pip", Version: "24.0"},
+		{Type: binfotypes.PackageTypePyPI, Name: "requests", Version: "2.31.0"},
+		{Type: binfotypes.PackageTypePyPI, Name: "six", Version: "1.16.0"},
+	}, pkgs)
+
+	pkgs, err = Scan(context.TODO(), testFS{})
+	require.NoError(t, err)
+	require.Empty(t, pkgs)
+}
+
+func TestParsePythonDistInfo(t *testing.T) {
+	p, ok := parsePythonDistInfo("zope.interface-6.1-py3.11.egg-info")
+	require.True(t, ok)
+	require.Equal(t, binfotypes.Package{Type: binfotypes.PackageTypePyPI, Name: "zope-interface", Version: "6.1"}, p)
+
+	_, ok = parsePythonDistInfo("requests")
+	require.False(t, ok)
+	_, ok = parsePythonDistInfo("broken.dist-info")
+	require.False(t, ok)
+}
@@ -0,0 +1,84 @@
+package lockfile
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/moby/buildkit/frontend/gateway/client"
+	"github.com/moby/buildkit/frontend/subrequests"
+	binfotypes "github.com/moby/buildkit/util/buildinfo/types"
+	"github.com/pkg/errors"
+)
+
+const RequestLockfile = "frontend.lockfile"
+
+var SubrequestsLockfileDefinition = subrequests.Request{
+	Name:        RequestLockfile,
+	Version:     "1.0.0",
+	Type:        subrequests.TypeRPC,
+	Description: "Generate a lockfile pinning the sources of the build",
+	Opts:        []subrequests.Named{},
+	Metadata: []subrequests.Named{
+		{Name: "result.json"},
+	},
+}
+
+// Lockfile pins the sources of a build. It is the result of the lockfile
+// subrequest and can be passed back to the frontend to build from the same
+// sources.
+type Lockfile struct {
+	Sources []Source `json:"sources"`
+}
+
+// Source is a source of the build with its pin: the digest of images, the
+// commit of git repositories or the checksum of URLs.
+type Source struct {
+	Type binfotypes.SourceType `json:"type"`
+	Ref  string                `json:"ref"`
+	Pin  string                `json:"pin"`
+}
+
+// Parse parses a lockfile.
+func Parse(dt []byte) (*Lockfile, error) {
+	var l Lockfile
+	if err := json.Unmarshal(dt, &l); err != nil {
+		return nil, errors.Wrap(err, "failed to parse lockfile")
+	}
+	for _, src := range l.Sources {
+		if src.Ref == "" || src.Pin == "" {
+			return nil, errors.Errorf("invalid lockfile source %s %q without ref or pin", src.Type, src.Ref)
+		}
+	}
+	return &l, nil
+}
+
+// Pin returns the pin of the source.
+func (l *Lockfile) Pin(typ binfotypes.SourceType, ref string) (string, bool) {
+	for _, src := range l.Sources {
+		if src.Type == typ && src.Ref == ref {
+			return src.Pin, true
+		}
+	}
+	return "", false
+}
+
+// Sort sorts the sources by type and ref.
+func (l *Lockfile) Sort() {
+	sort.Slice(l.Sources, func(i, j int) bool {
+		if l.Sources[i].Type != l.Sources[j].Type {
+			return l.Sources[i].Type < l.Sources[j].Type
+		}
+		return l.Sources[i].Ref < l.Sources[j].Ref
+	})
+}
+
+func (l Lockfile) ToResult() (*client.Result, error) {
+	res := client.NewResult()
+	dt, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	res.AddMeta("result.json", dt)
+	res.AddMeta("version", []byte(SubrequestsLockfileDefinition.Version))
+	return res, nil
+}
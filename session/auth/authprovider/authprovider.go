@@ -18,8 +18,11 @@ import (
 	remoteserrors "github.com/containerd/containerd/remotes/errors"
 	"github.com/docker/cli/cli/config"
 	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/credentials"
+	"github.com/docker/cli/cli/config/types"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/auth"
+	"github.com/moby/buildkit/util/flightcontrol"
 	"github.com/moby/buildkit/util/progress/progresswriter"
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/nacl/sign"
@@ -28,13 +31,26 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-const defaultExpiration = 60
+const (
+	defaultExpiration = 60
+	// tokenRefreshTimeout bounds the background refreshes of the cached
+	// tokens.
+	tokenRefreshTimeout = 30 * time.Second
+)
 
+// NewDockerAuthProvider returns the provider of the credentials of the Docker
+// config file. The credential helpers of the comma-separated
+// BUILDKIT_CREDENTIAL_HELPERS environment variable are consulted in order
+// after the ones of the config file for the registries they have no
+// credentials for, e.g. BUILDKIT_CREDENTIAL_HELPERS=ecr-login,gcloud.
 func NewDockerAuthProvider(stderr io.Writer) session.Attachable {
 	return &authProvider{
 		config:      config.LoadDefaultConfigFile(stderr),
 		seeds:       &tokenSeeds{dir: config.Dir()},
 		loggerCache: map[string]struct{}{},
+		helpers:     splitHelpers(os.Getenv("BUILDKIT_CREDENTIAL_HELPERS")),
+		tokens:      newTokenCache(),
+		creds:       map[string]cachedCredentials{},
 	}
 }
 
@@ -43,6 +59,10 @@ type authProvider struct {
 	seeds       *tokenSeeds
 	logger      progresswriter.Logger
 	loggerCache map[string]struct{}
+	helpers     []string
+	tokens      *tokenCache
+	fetches     flightcontrol.Group
+	creds       map[string]cachedCredentials
 
 	// The need for this mutex is not well understood.
 	// Without it, the docker cli on OS X hangs when
@@ -61,12 +81,51 @@ func (ap *authProvider) Register(server *grpc.Server) {
 	auth.RegisterAuthServer(server, ap)
 }
 
-func (ap *authProvider) FetchToken(ctx context.Context, req *auth.FetchTokenRequest) (rr *auth.FetchTokenResponse, err error) {
+// FetchToken returns the token of the request from the cache of the provider
+// or fetches it. The concurrent requests for the same token share a single
+// fetch, and the cached tokens are refreshed in the background when they get
+// close to their expiration.
+func (ap *authProvider) FetchToken(ctx context.Context, req *auth.FetchTokenRequest) (*auth.FetchTokenResponse, error) {
 	creds, err := ap.credentials(req.Host)
 	if err != nil {
 		return nil, err
 	}
 
+	key := tokenCacheKey(req, creds)
+	if resp, refresh := ap.tokens.get(key); resp != nil {
+		if refresh {
+			go ap.refreshToken(key, req, creds)
+		}
+		return resp, nil
+	}
+
+	v, err := ap.fetches.Do(ctx, key, func(ctx context.Context) (interface{}, error) {
+		resp, err := ap.fetchToken(ctx, req, creds)
+		if err != nil {
+			return nil, err
+		}
+		ap.tokens.set(key, resp)
+		return resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := *v.(*auth.FetchTokenResponse)
+	return &resp, nil
+}
+
+func (ap *authProvider) refreshToken(key string, req *auth.FetchTokenRequest, creds *auth.CredentialsResponse) {
+	ctx, cancel := context.WithTimeout(context.Background(), tokenRefreshTimeout)
+	defer cancel()
+	resp, err := ap.fetchToken(ctx, req, creds)
+	if err != nil {
+		ap.tokens.refreshFailed(key)
+		return
+	}
+	ap.tokens.set(key, resp)
+}
+
+func (ap *authProvider) fetchToken(ctx context.Context, req *auth.FetchTokenRequest, creds *auth.CredentialsResponse) (rr *auth.FetchTokenResponse, err error) {
 	to := authutil.TokenOptions{
 		Realm:    req.Realm,
 		Service:  req.Service,
@@ -116,13 +175,19 @@ func (ap *authProvider) FetchToken(ctx context.Context, req *auth.FetchTokenRequ
 	return toTokenResponse(resp.Token, resp.IssuedAt, resp.ExpiresIn), nil
 }
 
+// credentials returns the credentials of the host. They are cached for a few
+// minutes so that the credential helpers don't run for every request.
 func (ap *authProvider) credentials(host string) (*auth.CredentialsResponse, error) {
 	ap.mu.Lock()
 	defer ap.mu.Unlock()
 	if host == "registry-1.docker.io" {
 		host = "https://index.docker.io/v1/"
 	}
-	ac, err := ap.config.GetAuthConfig(host)
+	if c, ok := ap.creds[host]; ok && time.Now().Before(c.expires) {
+		res := *c.creds
+		return &res, nil
+	}
+	ac, err := ap.authConfig(host)
 	if err != nil {
 		return nil, err
 	}
@@ -133,7 +198,51 @@ func (ap *authProvider) credentials(host string) (*auth.CredentialsResponse, err
 		res.Username = ac.Username
 		res.Secret = ac.Password
 	}
-	return res, nil
+	ap.creds[host] = cachedCredentials{creds: res, expires: time.Now().Add(credentialsCacheDuration)}
+	r := *res
+	return &r, nil
+}
+
+// authConfig returns the credentials of the first credential helper of the
+// chain of the host that has credentials for it. The chain of a host is the
+// comma-separated helpers of its credHelpers entry, or the credsStore, of the
+// config file followed by the helpers of the provider. The credentials of the
+// config file itself are used if the chain is empty.
+func (ap *authProvider) authConfig(host string) (types.AuthConfig, error) {
+	var chain []string
+	if h, ok := ap.config.CredentialHelpers[host]; ok {
+		chain = splitHelpers(h)
+	} else if ap.config.CredentialsStore != "" {
+		chain = []string{ap.config.CredentialsStore}
+	}
+	for _, h := range ap.helpers {
+		if !contains(chain, h) {
+			chain = append(chain, h)
+		}
+	}
+	if len(chain) == 0 {
+		return credentials.NewFileStore(ap.config).Get(host)
+	}
+
+	var firstErr error
+	found := false
+	for _, h := range chain {
+		ac, err := credentials.NewNativeStore(ap.config, h).Get(host)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = errors.Wrapf(err, "credential helper %s", h)
+			}
+			continue
+		}
+		found = true
+		if ac.IdentityToken != "" || ac.Username != "" || ac.Password != "" {
+			return ac, nil
+		}
+	}
+	if !found && firstErr != nil {
+		return types.AuthConfig{}, firstErr
+	}
+	return types.AuthConfig{ServerAddress: host}, nil
 }
 
 func (ap *authProvider) Credentials(ctx context.Context, req *auth.CredentialsRequest) (*auth.CredentialsResponse, error) {
@@ -211,6 +320,25 @@ func toTokenResponse(token string, issuedAt time.Time, expires int) *auth.FetchT
 	return resp
 }
 
+func splitHelpers(s string) []string {
+	var out []string
+	for _, h := range strings.Split(s, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+func contains(l []string, s string) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 func trimScopePrefix(scopes []string) []string {
 	out := make([]string, len(scopes))
 	for i, s := range scopes {
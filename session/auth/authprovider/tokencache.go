@@ -0,0 +1,112 @@
+package authprovider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/moby/buildkit/session/auth"
+)
+
+const (
+	// tokenExpiryMargin is how long before their expiration the cached tokens
+	// stop being returned.
+	tokenExpiryMargin = 5 * time.Second
+	// tokenRefreshRatio is the part of the lifetime of a cached token after
+	// which it is refreshed in the background.
+	tokenRefreshRatio = 0.75
+	// credentialsCacheDuration is how long the credentials returned by the
+	// credential helpers are reused before the helpers are run again.
+	credentialsCacheDuration = 5 * time.Minute
+)
+
+// tokenCache keeps the registry tokens fetched for the builds of the session
+// so that the vertices pulling and pushing to the same repositories share
+// them.
+type tokenCache struct {
+	mu      sync.Mutex
+	entries map[string]*cachedToken
+	now     func() time.Time
+}
+
+type cachedToken struct {
+	resp       *auth.FetchTokenResponse
+	expires    time.Time
+	refreshAt  time.Time
+	refreshing bool
+}
+
+func newTokenCache() *tokenCache {
+	return &tokenCache{
+		entries: map[string]*cachedToken{},
+		now:     time.Now,
+	}
+}
+
+// tokenCacheKey returns the key of the tokens of the request fetched with the
+// credentials.
+func tokenCacheKey(req *auth.FetchTokenRequest, creds *auth.CredentialsResponse) string {
+	scopes := append([]string{}, req.Scopes...)
+	sort.Strings(scopes)
+	h := sha256.Sum256([]byte(creds.Username + "\x00" + creds.Secret))
+	return strings.Join([]string{req.Host, req.Realm, req.Service, hex.EncodeToString(h[:]), strings.Join(scopes, " ")}, "\x00")
+}
+
+// get returns the cached token of key, or nil if there is none or it is about
+// to expire. refresh is true for the first caller getting a token that is past
+// its refresh time, which should fetch a new token.
+func (c *tokenCache) get(key string) (resp *auth.FetchTokenResponse, refresh bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	now := c.now()
+	if !now.Before(e.expires.Add(-tokenExpiryMargin)) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	if !now.Before(e.refreshAt) && !e.refreshing {
+		e.refreshing = true
+		refresh = true
+	}
+	r := *e.resp
+	return &r, refresh
+}
+
+// set caches the token of key. The tokens without an issue time are
+// considered issued now.
+func (c *tokenCache) set(key string, resp *auth.FetchTokenResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r := *resp
+	if r.IssuedAt == 0 {
+		r.IssuedAt = c.now().Unix()
+	}
+	issuedAt := time.Unix(r.IssuedAt, 0)
+	lifetime := time.Duration(r.ExpiresIn) * time.Second
+	c.entries[key] = &cachedToken{
+		resp:      &r,
+		expires:   issuedAt.Add(lifetime),
+		refreshAt: issuedAt.Add(time.Duration(float64(lifetime) * tokenRefreshRatio)),
+	}
+	*resp = r
+}
+
+// refreshFailed lets the next caller retry the refresh of the token of key.
+func (c *tokenCache) refreshFailed(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		e.refreshing = false
+	}
+}
+
+type cachedCredentials struct {
+	creds   *auth.CredentialsResponse
+	expires time.Time
+}
@@ -0,0 +1,59 @@
+package authprovider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/session/auth"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenCache(t *testing.T) {
+	now := time.Unix(1000, 0)
+	c := newTokenCache()
+	c.now = func() time.Time { return now }
+
+	req := &auth.FetchTokenRequest{Host: "docker.io", Scopes: []string{"repository:a:pull", "repository:b:pull"}}
+	creds := &auth.CredentialsResponse{Username: "user", Secret: "secret"}
+	key := tokenCacheKey(req, creds)
+
+	reordered := &auth.FetchTokenRequest{Host: "docker.io", Scopes: []string{"repository:b:pull", "repository:a:pull"}}
+	require.Equal(t, key, tokenCacheKey(reordered, creds))
+	require.NotEqual(t, key, tokenCacheKey(req, &auth.CredentialsResponse{}))
+
+	resp, refresh := c.get(key)
+	require.Nil(t, resp)
+	require.False(t, refresh)
+
+	fetched := &auth.FetchTokenResponse{Token: "t1", ExpiresIn: 100}
+	c.set(key, fetched)
+	require.Equal(t, now.Unix(), fetched.IssuedAt)
+
+	now = now.Add(50 * time.Second)
+	resp, refresh = c.get(key)
+	require.Equal(t, "t1", resp.Token)
+	require.Equal(t, int64(1000), resp.IssuedAt)
+	require.False(t, refresh)
+
+	// past the refresh time a single caller refreshes the token
+	now = now.Add(30 * time.Second)
+	resp, refresh = c.get(key)
+	require.Equal(t, "t1", resp.Token)
+	require.True(t, refresh)
+	_, refresh = c.get(key)
+	require.False(t, refresh)
+
+	c.refreshFailed(key)
+	_, refresh = c.get(key)
+	require.True(t, refresh)
+
+	c.set(key, &auth.FetchTokenResponse{Token: "t2", ExpiresIn: 100})
+	resp, refresh = c.get(key)
+	require.Equal(t, "t2", resp.Token)
+	require.False(t, refresh)
+
+	// tokens about to expire are not returned
+	now = now.Add(96 * time.Second)
+	resp, _ = c.get(key)
+	require.Nil(t, resp)
+}
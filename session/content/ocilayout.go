@@ -0,0 +1,99 @@
+package content
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/content/local"
+	"github.com/moby/buildkit/client/ociindex"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	// OCIStoreIDPrefix prefixes the IDs of the OCI layout stores of a
+	// session, followed by the name of the store.
+	OCIStoreIDPrefix = "oci:"
+	// OCITagLabelPrefix prefixes the labels of the manifests of an OCI layout
+	// store that are tagged in its index, followed by the tag.
+	OCITagLabelPrefix = "buildkit.oci-layout.tag/"
+)
+
+// NewOCILayoutStore returns the content store of the OCI layout in dir. The
+// manifests referenced by the index.json file of the layout are labeled with
+// their tags so that they can be resolved by tag through the session.
+func NewOCILayoutStore(dir string) (content.Store, error) {
+	ls := &labelStore{labels: map[digest.Digest]map[string]string{}}
+	idx, err := ociindex.ReadIndexJSONFileLocked(filepath.Join(dir, "index.json"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if idx != nil {
+		for _, m := range idx.Manifests {
+			if tag, ok := m.Annotations[ocispecs.AnnotationRefName]; ok {
+				ls.add(m.Digest, OCITagLabelPrefix+tag, tag)
+			}
+		}
+	}
+	return local.NewLabeledStore(dir, ls)
+}
+
+// labelStore keeps the labels of an OCI layout store in memory.
+type labelStore struct {
+	mu     sync.Mutex
+	labels map[digest.Digest]map[string]string
+}
+
+func (ls *labelStore) add(dgst digest.Digest, key, value string) {
+	m, ok := ls.labels[dgst]
+	if !ok {
+		m = map[string]string{}
+		ls.labels[dgst] = m
+	}
+	m[key] = value
+}
+
+func (ls *labelStore) Get(dgst digest.Digest) (map[string]string, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return copyLabels(ls.labels[dgst]), nil
+}
+
+func (ls *labelStore) Set(dgst digest.Digest, labels map[string]string) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.labels[dgst] = copyLabels(labels)
+	return nil
+}
+
+func (ls *labelStore) Update(dgst digest.Digest, update map[string]string) (map[string]string, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	m, ok := ls.labels[dgst]
+	if !ok {
+		m = map[string]string{}
+		ls.labels[dgst] = m
+	}
+	for k, v := range update {
+		if v == "" {
+			delete(m, k)
+		} else {
+			m[k] = v
+		}
+	}
+	return copyLabels(m), nil
+}
+
+func copyLabels(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	m2 := make(map[string]string, len(m))
+	for k, v := range m {
+		m2[k] = v
+	}
+	return m2
+}
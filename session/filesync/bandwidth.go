@@ -0,0 +1,35 @@
+package filesync
+
+import (
+	"context"
+
+	"github.com/moby/buildkit/util/bandwidth"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+)
+
+// limitedRecvStream limits the messages received from the client with the
+// context transfer limit of the build.
+type limitedRecvStream struct {
+	grpc.ClientStream
+	ctx context.Context
+	l   *rate.Limiter
+}
+
+func newLimitedRecvStream(ctx context.Context, s grpc.ClientStream) grpc.ClientStream {
+	l := bandwidth.FromContext(ctx)
+	if l == nil || l.Context == nil {
+		return s
+	}
+	return &limitedRecvStream{ClientStream: s, ctx: ctx, l: l.Context}
+}
+
+func (s *limitedRecvStream) RecvMsg(m interface{}) error {
+	if err := s.ClientStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if sz, ok := m.(interface{ Size() int }); ok {
+		return bandwidth.Wait(s.ctx, s.l, sz.Size())
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package filesync
+
+// chunker splits a stream into content-defined chunks with the gear hash of
+// FastCDC. The boundaries of the chunks only depend on the bytes before them,
+// so a change in a file only changes the chunks around it.
+type chunker struct {
+	buf []byte
+	pos int
+	h   uint64
+}
+
+const (
+	chunkMinSize = 16 << 10
+	chunkMaxSize = 256 << 10
+	// chunkMask selects 16 bits of the hash for an average chunk size of
+	// 64KiB. The high bits depend on the last 64 bytes.
+	chunkMask = uint64(0xffff) << 48
+)
+
+var gearTable = func() (t [256]uint64) {
+	// splitmix64, the table must be the same for the sender and receiver
+	x := uint64(0)
+	for i := range t {
+		x += 0x9e3779b97f4a7c15
+		z := x
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		t[i] = z ^ (z >> 31)
+	}
+	return t
+}()
+
+func (c *chunker) write(dt []byte) {
+	c.buf = append(c.buf, dt...)
+}
+
+// next returns the next complete chunk, or nil if more data is needed. If
+// final is set, the remaining data is returned as the last chunk. The chunk
+// is valid until the next call to write.
+func (c *chunker) next(final bool) []byte {
+	if c.pos < chunkMinSize {
+		c.pos = chunkMinSize
+	}
+	end := len(c.buf)
+	if end > chunkMaxSize {
+		end = chunkMaxSize
+	}
+	for ; c.pos < end; c.pos++ {
+		c.h = (c.h << 1) + gearTable[c.buf[c.pos]]
+		if c.h&chunkMask == 0 {
+			return c.cut(c.pos + 1)
+		}
+	}
+	if len(c.buf) >= chunkMaxSize {
+		return c.cut(chunkMaxSize)
+	}
+	if final && len(c.buf) > 0 {
+		return c.cut(len(c.buf))
+	}
+	return nil
+}
+
+func (c *chunker) cut(n int) []byte {
+	chunk := c.buf[:n:n]
+	c.buf = c.buf[n:]
+	c.pos = 0
+	c.h = 0
+	return chunk
+}
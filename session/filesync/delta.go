@@ -0,0 +1,349 @@
+package filesync
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/moby/buildkit/util/bklog"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/fsutil/types"
+	"google.golang.org/grpc"
+)
+
+// Delta transfer avoids sending the unchanged parts of large files that are
+// already in the destination directory from a previous transfer. The receiver
+// sends the digests of the content-defined chunks of its version of a file
+// with the request for the file, and the sender replaces the chunks the
+// receiver already has with references to them.
+//
+// The delta transfer is negotiated with the keyDeltaTransfer metadata and
+// only changes the data packets of the fsutil protocol, so the rest of the
+// transfer is handled by fsutil.
+
+const (
+	keyDeltaTransfer = "delta-transfer"
+
+	// deltaMinSize is the minimum size of the files transferred as deltas
+	deltaMinSize = 1 << 20
+	// deltaMaxOpenFiles limits the previous versions of files the receiver
+	// keeps open while the transfer is running
+	deltaMaxOpenFiles = 1024
+)
+
+var deltaMagic = []byte("bkdelta1")
+
+const (
+	deltaOpLiteral byte = iota
+	deltaOpCopy
+)
+
+// chunkIndex contains the chunks of the receiver's version of a file.
+type chunkIndex struct {
+	offsets []int64
+	sizes   []int64
+}
+
+// fileSignature returns the signature of the file sent with the request and
+// the index of its chunks.
+func fileSignature(r io.Reader) ([]byte, *chunkIndex, error) {
+	sig := append([]byte{}, deltaMagic...)
+	idx := &chunkIndex{}
+	var c chunker
+	var offset int64
+	buf := make([]byte, 32*1024)
+	var tmp [binary.MaxVarintLen64]byte
+	add := func(chunk []byte) {
+		dgst := sha256.Sum256(chunk)
+		sig = append(sig, dgst[:]...)
+		sig = append(sig, tmp[:binary.PutUvarint(tmp[:], uint64(len(chunk)))]...)
+		idx.offsets = append(idx.offsets, offset)
+		idx.sizes = append(idx.sizes, int64(len(chunk)))
+		offset += int64(len(chunk))
+	}
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			c.write(buf[:n])
+			for chunk := c.next(false); chunk != nil; chunk = c.next(false) {
+				add(chunk)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if chunk := c.next(true); chunk != nil {
+		add(chunk)
+	}
+	return sig, idx, nil
+}
+
+// parseSignature returns the chunk numbers of the digests of a signature.
+func parseSignature(sig []byte) (map[[sha256.Size]byte]uint64, error) {
+	if !bytes.HasPrefix(sig, deltaMagic) {
+		return nil, errors.New("invalid delta signature")
+	}
+	r := bytes.NewReader(sig[len(deltaMagic):])
+	m := map[[sha256.Size]byte]uint64{}
+	for i := uint64(0); r.Len() > 0; i++ {
+		var dgst [sha256.Size]byte
+		if _, err := io.ReadFull(r, dgst[:]); err != nil {
+			return nil, errors.Wrap(err, "invalid delta signature")
+		}
+		if _, err := binary.ReadUvarint(r); err != nil {
+			return nil, errors.Wrap(err, "invalid delta signature")
+		}
+		if _, ok := m[dgst]; !ok {
+			m[dgst] = i
+		}
+	}
+	return m, nil
+}
+
+// deltaSendStream encodes the data of the files requested with a signature
+// as deltas.
+type deltaSendStream struct {
+	Stream
+	mu     sync.Mutex
+	states map[uint32]*deltaSendState
+}
+
+type deltaSendState struct {
+	index   map[[sha256.Size]byte]uint64
+	chunker chunker
+}
+
+func newDeltaSendStream(s Stream) *deltaSendStream {
+	return &deltaSendStream{Stream: s, states: map[uint32]*deltaSendState{}}
+}
+
+func (s *deltaSendStream) RecvMsg(m interface{}) error {
+	if err := s.Stream.RecvMsg(m); err != nil {
+		return err
+	}
+	p, ok := m.(*types.Packet)
+	if !ok || p.Type != types.PACKET_REQ || len(p.Data) == 0 {
+		return nil
+	}
+	index, err := parseSignature(p.Data)
+	if err != nil {
+		return err
+	}
+	p.Data = nil
+	s.mu.Lock()
+	s.states[p.ID] = &deltaSendState{index: index}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *deltaSendStream) SendMsg(m interface{}) error {
+	p, ok := m.(*types.Packet)
+	if !ok || p.Type != types.PACKET_DATA {
+		return s.Stream.SendMsg(m)
+	}
+	s.mu.Lock()
+	st, ok := s.states[p.ID]
+	if ok && len(p.Data) == 0 {
+		delete(s.states, p.ID)
+	}
+	s.mu.Unlock()
+	if !ok {
+		return s.Stream.SendMsg(m)
+	}
+
+	final := len(p.Data) == 0
+	st.chunker.write(p.Data)
+	var ops []byte
+	var tmp [binary.MaxVarintLen64]byte
+	for chunk := st.chunker.next(final); chunk != nil; chunk = st.chunker.next(final) {
+		if i, ok := st.index[sha256.Sum256(chunk)]; ok {
+			ops = append(ops, deltaOpCopy)
+			ops = append(ops, tmp[:binary.PutUvarint(tmp[:], i)]...)
+		} else {
+			ops = append(ops, deltaOpLiteral)
+			ops = append(ops, tmp[:binary.PutUvarint(tmp[:], uint64(len(chunk)))]...)
+			ops = append(ops, chunk...)
+		}
+	}
+	if len(ops) > 0 {
+		if err := s.Stream.SendMsg(&types.Packet{Type: types.PACKET_DATA, ID: p.ID, Data: ops}); err != nil {
+			return err
+		}
+	}
+	if final {
+		return s.Stream.SendMsg(p)
+	}
+	return nil
+}
+
+// deltaRecvStream requests large files that exist in the destination
+// directory with their signature and decodes the deltas sent for them.
+type deltaRecvStream struct {
+	grpc.ClientStream
+	dest string
+
+	mu     sync.Mutex
+	nextID uint32
+	old    map[uint32]*os.File
+	states map[uint32]*deltaRecvState
+}
+
+type deltaRecvState struct {
+	f     *os.File
+	index *chunkIndex
+}
+
+func newDeltaRecvStream(s grpc.ClientStream, dest string) *deltaRecvStream {
+	return &deltaRecvStream{
+		ClientStream: s,
+		dest:         dest,
+		old:          map[uint32]*os.File{},
+		states:       map[uint32]*deltaRecvState{},
+	}
+}
+
+func (s *deltaRecvStream) RecvMsg(m interface{}) error {
+	if err := s.ClientStream.RecvMsg(m); err != nil {
+		s.close()
+		return err
+	}
+	p, ok := m.(*types.Packet)
+	if !ok {
+		return nil
+	}
+	switch p.Type {
+	case types.PACKET_STAT:
+		if p.Stat == nil {
+			return nil
+		}
+		id := s.nextID
+		s.nextID++
+		if os.FileMode(p.Stat.Mode)&os.ModeType == 0 && p.Stat.Size_ >= deltaMinSize {
+			s.openOld(id, p.Stat.Path)
+		}
+	case types.PACKET_DATA:
+		s.mu.Lock()
+		st, ok := s.states[p.ID]
+		if ok && len(p.Data) == 0 {
+			delete(s.states, p.ID)
+		}
+		s.mu.Unlock()
+		if !ok {
+			return nil
+		}
+		if len(p.Data) == 0 {
+			return st.f.Close()
+		}
+		dt, err := st.decode(p.Data)
+		if err != nil {
+			return err
+		}
+		p.Data = dt
+	case types.PACKET_FIN:
+		s.close()
+	}
+	return nil
+}
+
+// openOld opens the version of the file in the destination directory. The
+// file is kept open as fsutil replaces it before requesting its data.
+func (s *deltaRecvStream) openOld(id uint32, p string) {
+	if runtime.GOOS == "windows" {
+		// open files can't be replaced
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.old)+len(s.states) >= deltaMaxOpenFiles {
+		return
+	}
+	fp := filepath.Join(s.dest, filepath.Clean(filepath.FromSlash("/"+p)))
+	fi, err := os.Lstat(fp)
+	if err != nil || !fi.Mode().IsRegular() || fi.Size() < deltaMinSize {
+		return
+	}
+	f, err := os.Open(fp)
+	if err != nil {
+		return
+	}
+	s.old[id] = f
+}
+
+func (s *deltaRecvStream) SendMsg(m interface{}) error {
+	p, ok := m.(*types.Packet)
+	if !ok || p.Type != types.PACKET_REQ {
+		return s.ClientStream.SendMsg(m)
+	}
+	s.mu.Lock()
+	f, ok := s.old[p.ID]
+	delete(s.old, p.ID)
+	s.mu.Unlock()
+	if !ok {
+		return s.ClientStream.SendMsg(m)
+	}
+	sig, index, err := fileSignature(f)
+	if err != nil {
+		f.Close()
+		bklog.G(s.Context()).Debugf("failed to compute delta signature: %v", err)
+		return s.ClientStream.SendMsg(m)
+	}
+	s.mu.Lock()
+	s.states[p.ID] = &deltaRecvState{f: f, index: index}
+	s.mu.Unlock()
+	return s.ClientStream.SendMsg(&types.Packet{Type: types.PACKET_REQ, ID: p.ID, Data: sig})
+}
+
+func (s *deltaRecvStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, f := range s.old {
+		f.Close()
+		delete(s.old, id)
+	}
+	for id, st := range s.states {
+		st.f.Close()
+		delete(s.states, id)
+	}
+}
+
+func (st *deltaRecvState) decode(ops []byte) ([]byte, error) {
+	var out []byte
+	r := bytes.NewReader(ops)
+	for r.Len() > 0 {
+		op, _ := r.ReadByte()
+		v, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid delta")
+		}
+		switch op {
+		case deltaOpLiteral:
+			if v > uint64(r.Len()) {
+				return nil, errors.New("invalid delta literal")
+			}
+			start := len(out)
+			out = append(out, make([]byte, v)...)
+			r.Read(out[start:])
+		case deltaOpCopy:
+			if v >= uint64(len(st.index.offsets)) {
+				return nil, errors.Errorf("invalid delta chunk %d", v)
+			}
+			start := len(out)
+			out = append(out, make([]byte, st.index.sizes[v])...)
+			if _, err := st.f.ReadAt(out[start:], st.index.offsets[v]); err != nil {
+				return nil, errors.Wrap(err, "failed to read chunk of previous file")
+			}
+		default:
+			return nil, errors.Errorf("invalid delta op %d", op)
+		}
+	}
+	return out, nil
+}
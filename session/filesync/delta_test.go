@@ -0,0 +1,194 @@
+package filesync
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/testutil"
+	"github.com/stretchr/testify/require"
+	"github.com/tonistiigi/fsutil/types"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestChunkerBoundaries(t *testing.T) {
+	dt := make([]byte, 4<<20)
+	rand.New(rand.NewSource(1)).Read(dt)
+
+	chunks := func(dt []byte, step int) (out [][]byte) {
+		var c chunker
+		for i := 0; i < len(dt); i += step {
+			end := i + step
+			if end > len(dt) {
+				end = len(dt)
+			}
+			c.write(dt[i:end])
+			for chunk := c.next(false); chunk != nil; chunk = c.next(false) {
+				out = append(out, append([]byte{}, chunk...))
+			}
+		}
+		if chunk := c.next(true); chunk != nil {
+			out = append(out, chunk)
+		}
+		return out
+	}
+
+	c1 := chunks(dt, 32*1024)
+	require.Equal(t, c1, chunks(dt, 1000))
+	require.Equal(t, dt, bytes.Join(c1, nil))
+	for _, c := range c1[:len(c1)-1] {
+		require.GreaterOrEqual(t, len(c), chunkMinSize)
+		require.LessOrEqual(t, len(c), chunkMaxSize)
+	}
+
+	// an insertion only changes the chunks around it
+	dt2 := append(append(append([]byte{}, dt[:1<<20]...), []byte("inserted")...), dt[1<<20:]...)
+	c2 := chunks(dt2, 32*1024)
+	m := map[string]struct{}{}
+	for _, c := range c1 {
+		m[string(c)] = struct{}{}
+	}
+	var changed int
+	for _, c := range c2 {
+		if _, ok := m[string(c)]; !ok {
+			changed++
+		}
+	}
+	require.LessOrEqual(t, changed, 2)
+}
+
+type packetStream struct {
+	Stream
+	recv []*types.Packet
+	sent []*types.Packet
+}
+
+func (s *packetStream) RecvMsg(m interface{}) error {
+	*m.(*types.Packet) = *s.recv[0]
+	s.recv = s.recv[1:]
+	return nil
+}
+
+func (s *packetStream) SendMsg(m interface{}) error {
+	p := *m.(*types.Packet)
+	p.Data = append([]byte{}, p.Data...)
+	s.sent = append(s.sent, &p)
+	return nil
+}
+
+func TestDeltaEncoding(t *testing.T) {
+	old := make([]byte, 3<<20)
+	rand.New(rand.NewSource(2)).Read(old)
+	dt := append(append(append([]byte{}, old[:2<<20]...), []byte("changed")...), old[2<<20+100:]...)
+
+	f, err := ioutil.TempFile("", "delta")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+	_, err = f.Write(old)
+	require.NoError(t, err)
+
+	_, err = f.Seek(0, 0)
+	require.NoError(t, err)
+	sig, index, err := fileSignature(f)
+	require.NoError(t, err)
+
+	ps := &packetStream{recv: []*types.Packet{{Type: types.PACKET_REQ, ID: 3, Data: sig}}}
+	s := newDeltaSendStream(ps)
+	var req types.Packet
+	require.NoError(t, s.RecvMsg(&req))
+	require.Equal(t, uint32(3), req.ID)
+	require.Empty(t, req.Data)
+
+	for i := 0; i < len(dt); i += 32 * 1024 {
+		end := i + 32*1024
+		if end > len(dt) {
+			end = len(dt)
+		}
+		require.NoError(t, s.SendMsg(&types.Packet{Type: types.PACKET_DATA, ID: 3, Data: dt[i:end]}))
+	}
+	require.NoError(t, s.SendMsg(&types.Packet{Type: types.PACKET_DATA, ID: 3}))
+
+	st := &deltaRecvState{f: f, index: index}
+	var out []byte
+	var size int
+	for i, p := range ps.sent {
+		if i == len(ps.sent)-1 {
+			require.Empty(t, p.Data)
+			break
+		}
+		size += len(p.Data)
+		decoded, err := st.decode(p.Data)
+		require.NoError(t, err)
+		out = append(out, decoded...)
+	}
+	require.Equal(t, dt, out)
+	require.Less(t, size, 1<<20)
+}
+
+func TestFileSyncDelta(t *testing.T) {
+	ctx := context.TODO()
+	t.Parallel()
+	tmpDir, err := ioutil.TempDir("", "fsynctest")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	destDir, err := ioutil.TempDir("", "fsynctest")
+	require.NoError(t, err)
+	defer os.RemoveAll(destDir)
+
+	dt := make([]byte, 2<<20)
+	rand.New(rand.NewSource(3)).Read(dt)
+	err = ioutil.WriteFile(filepath.Join(tmpDir, "foo"), dt, 0600)
+	require.NoError(t, err)
+
+	s, err := session.NewSession(ctx, "foo", "bar")
+	require.NoError(t, err)
+
+	m, err := session.NewManager()
+	require.NoError(t, err)
+
+	fs := NewFSSyncProvider([]SyncedDir{{Name: "test0", Dir: tmpDir}})
+	s.Allow(fs)
+
+	dialer := session.Dialer(testutil.TestStream(testutil.Handler(m.HandleConn)))
+
+	g, ctx := errgroup.WithContext(context.Background())
+
+	g.Go(func() error {
+		return s.Run(ctx, dialer)
+	})
+
+	g.Go(func() (reterr error) {
+		c, err := m.Get(ctx, s.ID(), false)
+		if err != nil {
+			return err
+		}
+		if err := FSSync(ctx, c, FSSendRequestOpt{Name: "test0", DestDir: destDir}); err != nil {
+			return err
+		}
+
+		dt2 := append(append(append([]byte{}, dt[:1<<20]...), []byte("changed")...), dt[1<<20:]...)
+		if err := ioutil.WriteFile(filepath.Join(tmpDir, "foo"), dt2, 0600); err != nil {
+			return err
+		}
+		if err := FSSync(ctx, c, FSSendRequestOpt{Name: "test0", DestDir: destDir}); err != nil {
+			return err
+		}
+
+		out, err := ioutil.ReadFile(filepath.Join(destDir, "foo"))
+		if err != nil {
+			return err
+		}
+		require.Equal(t, dt2, out)
+		return s.Close()
+	})
+
+	err = g.Wait()
+	require.NoError(t, err)
+}
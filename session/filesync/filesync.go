@@ -5,6 +5,7 @@ import (
 	"fmt"
 	io "io"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/moby/buildkit/session"
@@ -24,6 +25,7 @@ const (
 	keyFollowPaths        = "followpaths"
 	keyDirName            = "dir-name"
 	keyExporterMetaPrefix = "exporter-md-"
+	keyExporterID         = "buildkit-attachable-exporter-id"
 )
 
 type fsSyncProvider struct {
@@ -105,7 +107,13 @@ func (sp *fsSyncProvider) handle(method string, stream grpc.ServerStream) (retEr
 		doneCh = sp.doneCh
 		sp.doneCh = nil
 	}
-	err := pr.sendFn(stream, fsutil.NewFS(dir.Dir, &fsutil.WalkOpt{
+	var s Stream = stream
+	if pr.name == "diffcopy" && len(opts[keyDeltaTransfer]) > 0 {
+		if err := stream.SetHeader(metadata.Pairs(keyDeltaTransfer, "1")); err == nil {
+			s = newDeltaSendStream(stream)
+		}
+	}
+	err := pr.sendFn(s, fsutil.NewFS(dir.Dir, &fsutil.WalkOpt{
 		ExcludePatterns: excludes,
 		IncludePatterns: includes,
 		FollowPaths:     followPaths,
@@ -193,6 +201,7 @@ func FSSync(ctx context.Context, c session.Caller, opt FSSendRequestOpt) error {
 	}
 
 	opts[keyDirName] = []string{opt.Name}
+	opts[keyDeltaTransfer] = []string{"1"}
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -209,13 +218,16 @@ func FSSync(ctx context.Context, c session.Caller, opt FSSendRequestOpt) error {
 		if err != nil {
 			return err
 		}
-		stream = cc
+		stream = newLimitedRecvStream(ctx, cc)
 	case "diffcopy":
 		cc, err := client.DiffCopy(ctx)
 		if err != nil {
 			return err
 		}
-		stream = cc
+		stream = newLimitedRecvStream(ctx, cc)
+		if md, err := cc.Header(); err == nil && len(md.Get(keyDeltaTransfer)) > 0 {
+			stream = newDeltaRecvStream(stream, opt.DestDir)
+		}
 	default:
 		panic(fmt.Sprintf("invalid protocol: %q", pr.name))
 	}
@@ -223,39 +235,93 @@ func FSSync(ctx context.Context, c session.Caller, opt FSSendRequestOpt) error {
 	return pr.recvFn(stream, opt.DestDir, opt.CacheUpdater, opt.ProgressCb, opt.Differ, opt.Filter)
 }
 
-// NewFSSyncTargetDir allows writing into a directory
-func NewFSSyncTargetDir(outdir string) session.Attachable {
-	p := &fsSyncTarget{
-		outdir: outdir,
-	}
-	return p
+// FSSyncTarget is a single export destination handled by the target
+// attachable. Targets are selected by the exporter ID sent by the daemon.
+type FSSyncTarget interface {
+	target() *fsSyncTargetEntry
 }
 
-// NewFSSyncTarget allows writing into an io.WriteCloser
-func NewFSSyncTarget(f func(map[string]string) (io.WriteCloser, error)) session.Attachable {
+type fsSyncTargetEntry struct {
+	id     int
+	outdir string
+	f      func(map[string]string) (io.WriteCloser, error)
+}
+
+func (t *fsSyncTargetEntry) target() *fsSyncTargetEntry {
+	return t
+}
+
+// WithFSSyncDir returns a target that writes into a directory for the
+// exporter with the given ID
+func WithFSSyncDir(id int, outdir string) FSSyncTarget {
+	return &fsSyncTargetEntry{id: id, outdir: outdir}
+}
+
+// WithFSSync returns a target that writes into an io.WriteCloser for the
+// exporter with the given ID
+func WithFSSync(id int, f func(map[string]string) (io.WriteCloser, error)) FSSyncTarget {
+	return &fsSyncTargetEntry{id: id, f: f}
+}
+
+// NewFSSyncTarget allows writing into multiple directories or
+// io.WriteClosers, one for each exporter
+func NewFSSyncTarget(targets ...FSSyncTarget) session.Attachable {
 	p := &fsSyncTarget{
-		f: f,
+		outdirs: map[int]string{},
+		fs:      map[int]func(map[string]string) (io.WriteCloser, error){},
+	}
+	for _, t := range targets {
+		t := t.target()
+		if t.outdir != "" {
+			p.outdirs[t.id] = t.outdir
+		}
+		if t.f != nil {
+			p.fs[t.id] = t.f
+		}
 	}
 	return p
 }
 
+// NewFSSyncTargetDir allows writing into a directory
+func NewFSSyncTargetDir(outdir string) session.Attachable {
+	return NewFSSyncTarget(WithFSSyncDir(0, outdir))
+}
+
 type fsSyncTarget struct {
-	outdir string
-	f      func(map[string]string) (io.WriteCloser, error)
+	outdirs map[int]string
+	fs      map[int]func(map[string]string) (io.WriteCloser, error)
 }
 
 func (sp *fsSyncTarget) Register(server *grpc.Server) {
 	RegisterFileSendServer(server, sp)
 }
 
-func (sp *fsSyncTarget) DiffCopy(stream FileSend_DiffCopyServer) (err error) {
-	if sp.outdir != "" {
-		return syncTargetDiffCopy(stream, sp.outdir)
+func (sp *fsSyncTarget) chooser(ctx context.Context) int {
+	opts, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0
 	}
+	values := opts[keyExporterID]
+	if len(values) == 0 {
+		return 0
+	}
+	id, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return int(id)
+}
 
-	if sp.f == nil {
-		return errors.New("empty outfile and outdir")
+func (sp *fsSyncTarget) DiffCopy(stream FileSend_DiffCopyServer) (err error) {
+	id := sp.chooser(stream.Context())
+	if outdir, ok := sp.outdirs[id]; ok {
+		return syncTargetDiffCopy(stream, outdir)
 	}
+	f, ok := sp.fs[id]
+	if !ok {
+		return errors.Errorf("exporter %d not found", id)
+	}
+
 	opts, _ := metadata.FromIncomingContext(stream.Context()) // if no metadata continue with empty object
 	md := map[string]string{}
 	for k, v := range opts {
@@ -263,7 +329,7 @@ func (sp *fsSyncTarget) DiffCopy(stream FileSend_DiffCopyServer) (err error) {
 			md[strings.TrimPrefix(k, keyExporterMetaPrefix)] = strings.Join(v, ",")
 		}
 	}
-	wc, err := sp.f(md)
+	wc, err := f(md)
 	if err != nil {
 		return err
 	}
@@ -279,7 +345,7 @@ func (sp *fsSyncTarget) DiffCopy(stream FileSend_DiffCopyServer) (err error) {
 	return writeTargetFile(stream, wc)
 }
 
-func CopyToCaller(ctx context.Context, fs fsutil.FS, c session.Caller, progress func(int, bool)) error {
+func CopyToCaller(ctx context.Context, fs fsutil.FS, id int, c session.Caller, progress func(int, bool)) error {
 	method := session.MethodURL(_FileSend_serviceDesc.ServiceName, "diffcopy")
 	if !c.Supports(method) {
 		return errors.Errorf("method %s not supported by the client", method)
@@ -287,6 +353,8 @@ func CopyToCaller(ctx context.Context, fs fsutil.FS, c session.Caller, progress
 
 	client := NewFileSendClient(c.Conn())
 
+	ctx = metadata.AppendToOutgoingContext(ctx, keyExporterID, strconv.Itoa(id))
+
 	cc, err := client.DiffCopy(ctx)
 	if err != nil {
 		return errors.WithStack(err)
@@ -295,7 +363,7 @@ func CopyToCaller(ctx context.Context, fs fsutil.FS, c session.Caller, progress
 	return sendDiffCopy(cc, fs, progress)
 }
 
-func CopyFileWriter(ctx context.Context, md map[string]string, c session.Caller) (io.WriteCloser, error) {
+func CopyFileWriter(ctx context.Context, md map[string]string, id int, c session.Caller) (io.WriteCloser, error) {
 	method := session.MethodURL(_FileSend_serviceDesc.ServiceName, "diffcopy")
 	if !c.Supports(method) {
 		return nil, errors.Errorf("method %s not supported by the client", method)
@@ -303,10 +371,11 @@ func CopyFileWriter(ctx context.Context, md map[string]string, c session.Caller)
 
 	client := NewFileSendClient(c.Conn())
 
-	opts := make(map[string][]string, len(md))
+	opts := make(map[string][]string, len(md)+1)
 	for k, v := range md {
 		opts[keyExporterMetaPrefix+k] = []string{v}
 	}
+	opts[keyExporterID] = []string{strconv.Itoa(id)}
 
 	ctx = metadata.NewOutgoingContext(ctx, opts)
 
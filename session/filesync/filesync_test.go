@@ -10,6 +10,7 @@ import (
 	"github.com/moby/buildkit/session/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tonistiigi/fsutil"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -72,3 +73,61 @@ func TestFileSyncIncludePatterns(t *testing.T) {
 	err = g.Wait()
 	require.NoError(t, err)
 }
+
+func TestFileSyncMultipleTargets(t *testing.T) {
+	ctx := context.TODO()
+	t.Parallel()
+	srcDir, err := ioutil.TempDir("", "fsynctest")
+	require.NoError(t, err)
+
+	destDir0, err := ioutil.TempDir("", "fsynctest")
+	require.NoError(t, err)
+
+	destDir1, err := ioutil.TempDir("", "fsynctest")
+	require.NoError(t, err)
+
+	err = ioutil.WriteFile(filepath.Join(srcDir, "foo"), []byte("content1"), 0600)
+	require.NoError(t, err)
+
+	s, err := session.NewSession(ctx, "foo", "bar")
+	require.NoError(t, err)
+
+	m, err := session.NewManager()
+	require.NoError(t, err)
+
+	s.Allow(NewFSSyncTarget(WithFSSyncDir(0, destDir0), WithFSSyncDir(1, destDir1)))
+
+	dialer := session.Dialer(testutil.TestStream(testutil.Handler(m.HandleConn)))
+
+	g, ctx := errgroup.WithContext(context.Background())
+
+	g.Go(func() error {
+		return s.Run(ctx, dialer)
+	})
+
+	g.Go(func() (reterr error) {
+		c, err := m.Get(ctx, s.ID(), false)
+		if err != nil {
+			return err
+		}
+		if err := CopyToCaller(ctx, fsutil.NewFS(srcDir, nil), 1, c, func(int, bool) {}); err != nil {
+			return err
+		}
+
+		_, err = ioutil.ReadFile(filepath.Join(destDir0, "foo"))
+		assert.Error(t, err)
+
+		dt, err := ioutil.ReadFile(filepath.Join(destDir1, "foo"))
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, "content1", string(dt))
+
+		err = CopyToCaller(ctx, fsutil.NewFS(srcDir, nil), 2, c, func(int, bool) {})
+		assert.Error(t, err)
+		return s.Close()
+	})
+
+	err = g.Wait()
+	require.NoError(t, err)
+}
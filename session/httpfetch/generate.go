@@ -0,0 +1,3 @@
+package httpfetch
+
+//go:generate protoc --gogoslick_out=plugins=grpc:. httpfetch.proto
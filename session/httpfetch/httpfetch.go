@@ -0,0 +1,110 @@
+package httpfetch
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/util/grpcerrors"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+)
+
+// ErrNotDelegated is returned for the requests that the client doesn't fetch
+// for the daemon.
+var ErrNotDelegated = errors.Errorf("fetch not delegated to the client")
+
+// Supported returns true if the session fetches HTTP requests for the daemon.
+func Supported(c session.Caller) bool {
+	return c.Supports(session.MethodURL(_HTTPFetch_serviceDesc.ServiceName, "Fetch"))
+}
+
+// Fetch sends the request through the network of the client of the session.
+// The body of the response is streamed from the client until it is closed.
+func Fetch(ctx context.Context, c session.Caller, req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody {
+		return nil, errors.Errorf("requests with a body can't be delegated to the client")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	client := NewHTTPFetchClient(c.Conn())
+	stream, err := client.Fetch(ctx, &FetchRequest{
+		URL:    req.URL.String(),
+		Method: req.Method,
+		Header: toHeaders(req.Header),
+	})
+	if err != nil {
+		cancel()
+		return nil, errors.WithStack(err)
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		cancel()
+		if code := grpcerrors.Code(err); code == codes.Unimplemented || code == codes.PermissionDenied {
+			return nil, errors.Wrapf(ErrNotDelegated, "%s", req.URL.Host)
+		}
+		return nil, errors.WithStack(err)
+	}
+	return &http.Response{
+		Status:        first.Status,
+		StatusCode:    int(first.StatusCode),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        fromHeaders(first.Header),
+		ContentLength: first.ContentLength,
+		Body:          &body{stream: stream, buf: first.Data, cancel: cancel},
+		Request:       req,
+	}, nil
+}
+
+// body reads the chunks of the body of the response from the stream.
+type body struct {
+	stream HTTPFetch_FetchClient
+	buf    []byte
+	cancel func()
+	err    error
+}
+
+func (b *body) Read(p []byte) (int, error) {
+	for len(b.buf) == 0 {
+		if b.err != nil {
+			return 0, b.err
+		}
+		msg, err := b.stream.Recv()
+		if err != nil {
+			if err != io.EOF {
+				err = errors.WithStack(err)
+			}
+			b.err = err
+			continue
+		}
+		b.buf = msg.Data
+	}
+	n := copy(p, b.buf)
+	b.buf = b.buf[n:]
+	return n, nil
+}
+
+func (b *body) Close() error {
+	b.cancel()
+	return nil
+}
+
+func toHeaders(h http.Header) []*Header {
+	out := make([]*Header, 0, len(h))
+	for k, v := range h {
+		out = append(out, &Header{Name: k, Values: v})
+	}
+	return out
+}
+
+func fromHeaders(hs []*Header) http.Header {
+	h := http.Header{}
+	for _, v := range hs {
+		for _, vv := range v.Values {
+			h.Add(v.Name, vv)
+		}
+	}
+	return h
+}
@@ -0,0 +1,117 @@
+package httpfetchprovider
+
+import (
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/httpfetch"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	chunkSize = 32 * 1024
+	// maxRedirects matches the limit of the default policy of http.Client
+	maxRedirects = 10
+)
+
+// NewProvider returns a session attachable that fetches the HTTP sources of
+// the builds with the network of the client, for the hosts matching the
+// patterns, e.g. "intranet.example.com" or "*.corp.example.com". The patterns
+// are path.Match patterns matched against the host name of the URLs. The
+// requests for other hosts are fetched by the daemon. Redirects are only
+// followed to the hosts matching the patterns.
+func NewProvider(hosts []string) session.Attachable {
+	p := &provider{hosts: hosts}
+	p.client = &http.Client{Transport: http.DefaultTransport, CheckRedirect: p.checkRedirect}
+	return p
+}
+
+type provider struct {
+	hosts  []string
+	client *http.Client
+}
+
+func (p *provider) Register(server *grpc.Server) {
+	httpfetch.RegisterHTTPFetchServer(server, p)
+}
+
+func (p *provider) allowed(host string) bool {
+	host = strings.ToLower(host)
+	for _, pattern := range p.hosts {
+		if ok, _ := path.Match(strings.ToLower(pattern), host); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRedirect keeps the redirects of the requests within the delegated hosts.
+func (p *provider) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return errors.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if !p.allowed(req.URL.Hostname()) {
+		return status.Errorf(codes.PermissionDenied, "redirect to %s is not delegated to the client", req.URL.Host)
+	}
+	return nil
+}
+
+func (p *provider) Fetch(req *httpfetch.FetchRequest, stream httpfetch.HTTPFetch_FetchServer) error {
+	r, err := http.NewRequest(req.Method, req.URL, nil)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid request: %v", err)
+	}
+	if r.URL.Scheme != "http" && r.URL.Scheme != "https" {
+		return status.Errorf(codes.InvalidArgument, "unsupported scheme %s", r.URL.Scheme)
+	}
+	if !p.allowed(r.URL.Hostname()) {
+		return status.Errorf(codes.PermissionDenied, "fetching %s is not delegated to the client", r.URL.Host)
+	}
+	for _, h := range req.Header {
+		for _, v := range h.Values {
+			r.Header.Add(h.Name, v)
+		}
+	}
+	r = r.WithContext(stream.Context())
+
+	resp, err := p.client.Do(r)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+
+	headers := make([]*httpfetch.Header, 0, len(resp.Header))
+	for k, v := range resp.Header {
+		headers = append(headers, &httpfetch.Header{Name: k, Values: v})
+	}
+	if err := stream.Send(&httpfetch.FetchResponse{
+		StatusCode:    int32(resp.StatusCode),
+		Status:        resp.Status,
+		Header:        headers,
+		ContentLength: resp.ContentLength,
+	}); err != nil {
+		return err
+	}
+
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&httpfetch.FetchResponse{Data: buf[:n]}); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package httpfetchprovider
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/moby/buildkit/session/httpfetch"
+	"github.com/moby/buildkit/util/grpcerrors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+type stream struct {
+	grpc.ServerStream
+	msgs []*httpfetch.FetchResponse
+}
+
+func (s *stream) Context() context.Context {
+	return context.TODO()
+}
+
+func (s *stream) Send(m *httpfetch.FetchResponse) error {
+	m2 := *m
+	m2.Data = append([]byte{}, m.Data...)
+	s.msgs = append(s.msgs, &m2)
+	return nil
+}
+
+func TestFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "bar", r.Header.Get("X-Foo"))
+		w.Header().Set("ETag", `"abc"`)
+		w.Write([]byte("content"))
+	}))
+	defer srv.Close()
+
+	p := NewProvider([]string{"127.0.0.*"}).(*provider)
+	s := &stream{}
+	err := p.Fetch(&httpfetch.FetchRequest{
+		URL:    srv.URL + "/file",
+		Method: "GET",
+		Header: []*httpfetch.Header{{Name: "X-Foo", Values: []string{"bar"}}},
+	}, s)
+	require.NoError(t, err)
+	require.Len(t, s.msgs, 2)
+	require.Equal(t, int32(http.StatusOK), s.msgs[0].StatusCode)
+	var etag string
+	for _, h := range s.msgs[0].Header {
+		if h.Name == "Etag" {
+			etag = h.Values[0]
+		}
+	}
+	require.Equal(t, `"abc"`, etag)
+	require.Equal(t, "content", string(s.msgs[1].Data))
+
+	p = NewProvider([]string{"*.corp.example.com"}).(*provider)
+	err = p.Fetch(&httpfetch.FetchRequest{URL: srv.URL, Method: "GET"}, &stream{})
+	require.Error(t, err)
+	require.Equal(t, codes.PermissionDenied, grpcerrors.Code(err))
+	require.True(t, p.allowed("intranet.corp.example.com"))
+}
+
+func TestFetchRedirect(t *testing.T) {
+	external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("external"))
+	}))
+	defer external.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/local":
+			http.Redirect(w, r, "/file", http.StatusFound)
+		case "/external":
+			// localhost does not match the delegated pattern
+			http.Redirect(w, r, "http://localhost:"+strconv.Itoa(external.Listener.Addr().(*net.TCPAddr).Port)+"/file", http.StatusFound)
+		default:
+			w.Write([]byte("content"))
+		}
+	}))
+	defer srv.Close()
+
+	p := NewProvider([]string{"127.0.0.*"}).(*provider)
+	s := &stream{}
+	err := p.Fetch(&httpfetch.FetchRequest{URL: srv.URL + "/local", Method: "GET"}, s)
+	require.NoError(t, err)
+	require.Equal(t, "content", string(s.msgs[1].Data))
+
+	err = p.Fetch(&httpfetch.FetchRequest{URL: srv.URL + "/external", Method: "GET"}, &stream{})
+	require.Error(t, err)
+	require.Equal(t, codes.PermissionDenied, grpcerrors.Code(err))
+}
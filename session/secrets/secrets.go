@@ -4,8 +4,10 @@ import (
 	"context"
 
 	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/grpcerrors"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc/codes"
 )
 
@@ -15,7 +17,10 @@ type SecretStore interface {
 
 var ErrNotFound = errors.Errorf("not found")
 
-func GetSecret(ctx context.Context, c session.Caller, id string) ([]byte, error) {
+func GetSecret(ctx context.Context, c session.Caller, id string) (_ []byte, retErr error) {
+	defer func() {
+		auditSecret(ctx, c, id, retErr)
+	}()
 	client := NewSecretsClient(c.Conn())
 	resp, err := client.GetSecret(ctx, &GetSecretRequest{
 		ID: id,
@@ -28,3 +33,24 @@ func GetSecret(ctx context.Context, c session.Caller, id string) ([]byte, error)
 	}
 	return resp.Data, nil
 }
+
+// auditSecret logs the requests of the daemon for the secrets of the sessions,
+// without their values.
+func auditSecret(ctx context.Context, c session.Caller, id string, err error) {
+	fields := logrus.Fields{
+		"secret":       id,
+		"session.name": c.Name(),
+	}
+	if s, ok := c.(interface{ ID() string }); ok {
+		fields["session.id"] = s.ID()
+	}
+	l := bklog.G(ctx).WithFields(fields)
+	switch {
+	case err == nil:
+		l.Info("secret read from session")
+	case errors.Is(err, ErrNotFound):
+		l.Info("secret not found in session")
+	default:
+		l.WithError(err).Warn("failed to read secret from session")
+	}
+}
@@ -0,0 +1,225 @@
+package secretsprovider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/moby/buildkit/util/sigv4"
+	"github.com/pkg/errors"
+)
+
+// httpClient is the client the secrets of external stores are fetched with.
+var httpClient = http.DefaultClient
+
+// getVaultSecret reads the secret at path from HashiCorp Vault, with the
+// address and the token of the VAULT_ADDR and VAULT_TOKEN environment
+// variables or the ~/.vault-token file. A #field suffix of the path selects a
+// field of the secret, the only field is returned without it and all the
+// fields are returned as a JSON object if the secret has several.
+func getVaultSecret(ctx context.Context, path string) ([]byte, error) {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return nil, errors.New("VAULT_ADDR is not set")
+	}
+	path, field := splitField(path)
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, errors.New("VAULT_TOKEN is not set")
+		}
+		dt, err := ioutil.ReadFile(filepath.Join(home, ".vault-token"))
+		if err != nil {
+			return nil, errors.New("VAULT_TOKEN is not set and ~/.vault-token can't be read")
+		}
+		token = strings.TrimSpace(string(dt))
+	}
+
+	req, err := http.NewRequest("GET", strings.TrimSuffix(addr, "/")+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+	dt, err := doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(dt, &resp); err != nil {
+		return nil, errors.Wrap(err, "invalid Vault response")
+	}
+	data := resp.Data
+	// the fields of the secrets of KV version 2 stores are nested in data
+	if nested, ok := data["data"]; ok {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = nil
+			if err := json.Unmarshal(nested, &data); err != nil {
+				return nil, errors.Wrap(err, "invalid Vault response")
+			}
+		}
+	}
+	return selectField(data, field)
+}
+
+// getAWSSecret reads the secret with the ID or ARN id from AWS Secrets
+// Manager, with the credentials and the region of the AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN and AWS_REGION environment
+// variables. A #field suffix selects a field of a secret stored as a JSON
+// object.
+func getAWSSecret(ctx context.Context, id string) ([]byte, error) {
+	id, field := splitField(id)
+	creds := sigv4.Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are not set")
+	}
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		return nil, errors.New("AWS_REGION is not set")
+	}
+
+	body, err := json.Marshal(struct {
+		SecretID string `json:"SecretId"`
+	}{id})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", "https://secretsmanager."+region+".amazonaws.com/", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	h := sha256.Sum256(body)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(h[:]))
+	sigv4.Sign(req, creds, region, "secretsmanager", time.Now())
+	dt, err := doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		SecretString *string
+		SecretBinary []byte
+	}
+	if err := json.Unmarshal(dt, &resp); err != nil {
+		return nil, errors.Wrap(err, "invalid AWS Secrets Manager response")
+	}
+	if resp.SecretString == nil {
+		if field != "" {
+			return nil, errors.Errorf("field %s of binary secret", field)
+		}
+		return resp.SecretBinary, nil
+	}
+	if field == "" {
+		return []byte(*resp.SecretString), nil
+	}
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(*resp.SecretString), &data); err != nil {
+		return nil, errors.Wrapf(err, "field %s of secret that is not a JSON object", field)
+	}
+	return selectField(data, field)
+}
+
+// renderTemplate renders the text/template file at path. The env function
+// returns the value of an environment variable and the file function the
+// contents of a file, e.g. {"auth":"{{ env "REGISTRY_TOKEN" }}"}.
+func renderTemplate(path string) ([]byte, error) {
+	dt, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(filepath.Base(path)).Option("missingkey=error").Funcs(template.FuncMap{
+		"env": func(name string) (string, error) {
+			v, ok := os.LookupEnv(name)
+			if !ok {
+				return "", errors.Errorf("environment variable %s is not set", name)
+			}
+			return v, nil
+		},
+		"file": func(name string) (string, error) {
+			dt, err := ioutil.ReadFile(name)
+			if err != nil {
+				return "", err
+			}
+			return string(dt), nil
+		},
+	}).Parse(string(dt))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse template %s", path)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		return nil, errors.Wrapf(err, "failed to render template %s", path)
+	}
+	return buf.Bytes(), nil
+}
+
+func doRequest(req *http.Request) ([]byte, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	dt, err := ioutil.ReadAll(io.LimitReader(resp.Body, 4*MaxSecretSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status %s from %s", resp.Status, req.URL.Host)
+	}
+	return dt, nil
+}
+
+func splitField(s string) (string, string) {
+	if i := strings.LastIndex(s, "#"); i >= 0 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
+
+// selectField returns the field of the secret, the only field of the secret
+// or the secret as a JSON object. String values are returned unquoted.
+func selectField(data map[string]json.RawMessage, field string) ([]byte, error) {
+	if field == "" {
+		if len(data) != 1 {
+			return json.Marshal(data)
+		}
+		for field = range data {
+		}
+	}
+	v, ok := data[field]
+	if !ok {
+		return nil, errors.Errorf("field %s not found", field)
+	}
+	var s string
+	if err := json.Unmarshal(v, &s); err == nil {
+		return []byte(s), nil
+	}
+	return v, nil
+}
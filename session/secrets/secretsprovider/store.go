@@ -4,6 +4,8 @@ import (
 	"context"
 	"io/ioutil"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/moby/buildkit/session/secrets"
 	"github.com/pkg/errors"
@@ -14,6 +16,22 @@ type Source struct {
 	ID       string
 	FilePath string
 	Env      string
+	// Vault is the path of a secret of HashiCorp Vault, with an optional
+	// #field suffix.
+	Vault string
+	// AWSSecretID is the ID or ARN of a secret of AWS Secrets Manager, with
+	// an optional #field suffix.
+	AWSSecretID string
+	// Template is the path of a text/template file rendered when the secret
+	// is requested.
+	Template string
+	// TTL is how long the value of the secret is reused after it has been
+	// read. The value is read on every request of the build if it is zero.
+	TTL time.Duration
+}
+
+func (s Source) external() bool {
+	return s.Vault != "" || s.AWSSecretID != "" || s.Template != ""
 }
 
 func NewStore(files []Source) (secrets.SecretStore, error) {
@@ -22,7 +40,16 @@ func NewStore(files []Source) (secrets.SecretStore, error) {
 		if f.ID == "" {
 			return nil, errors.Errorf("secret missing ID")
 		}
-		if f.Env == "" && f.FilePath == "" {
+		n := 0
+		for _, v := range []string{f.FilePath, f.Env, f.Vault, f.AWSSecretID, f.Template} {
+			if v != "" {
+				n++
+			}
+		}
+		if n > 1 {
+			return nil, errors.Errorf("secret %s has multiple sources", f.ID)
+		}
+		if n == 0 {
 			if _, ok := os.LookupEnv(f.ID); ok {
 				f.Env = f.ID
 			} else {
@@ -38,15 +65,29 @@ func NewStore(files []Source) (secrets.SecretStore, error) {
 				return nil, errors.Errorf("secret %s too big. max size %#.f", f.ID, MaxSecretSize*units.B)
 			}
 		}
+		if f.Template != "" {
+			if _, err := os.Stat(f.Template); err != nil {
+				return nil, errors.Wrapf(err, "failed to stat %s", f.Template)
+			}
+		}
 		m[f.ID] = f
 	}
 	return &fileStore{
-		m: m,
+		m:      m,
+		cached: map[string]cachedSecret{},
 	}, nil
 }
 
 type fileStore struct {
 	m map[string]Source
+
+	mu     sync.Mutex
+	cached map[string]cachedSecret
+}
+
+type cachedSecret struct {
+	dt      []byte
+	expires time.Time
 }
 
 func (fs *fileStore) GetSecret(ctx context.Context, id string) ([]byte, error) {
@@ -54,8 +95,39 @@ func (fs *fileStore) GetSecret(ctx context.Context, id string) ([]byte, error) {
 	if !ok {
 		return nil, errors.WithStack(secrets.ErrNotFound)
 	}
-	if v.Env != "" {
+	if v.TTL > 0 {
+		fs.mu.Lock()
+		c, ok := fs.cached[id]
+		fs.mu.Unlock()
+		if ok && time.Now().Before(c.expires) {
+			return c.dt, nil
+		}
+	}
+	dt, err := fs.read(ctx, v)
+	if err != nil {
+		if v.external() {
+			return nil, errors.Wrapf(err, "failed to fetch secret %s", id)
+		}
+		return nil, err
+	}
+	if v.TTL > 0 {
+		fs.mu.Lock()
+		fs.cached[id] = cachedSecret{dt: dt, expires: time.Now().Add(v.TTL)}
+		fs.mu.Unlock()
+	}
+	return dt, nil
+}
+
+func (fs *fileStore) read(ctx context.Context, v Source) ([]byte, error) {
+	switch {
+	case v.Env != "":
 		return []byte(os.Getenv(v.Env)), nil
+	case v.Vault != "":
+		return getVaultSecret(ctx, v.Vault)
+	case v.AWSSecretID != "":
+		return getAWSSecret(ctx, v.AWSSecretID)
+	case v.Template != "":
+		return renderTemplate(v.Template)
 	}
 	dt, err := ioutil.ReadFile(v.FilePath)
 	if err != nil {
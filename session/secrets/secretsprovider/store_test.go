@@ -0,0 +1,86 @@
+package secretsprovider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultSecret(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/app":
+			w.Write([]byte(`{"data":{"data":{"password":"p4ss","user":"app"},"metadata":{"version":1}}}`))
+		case "/v1/kv/token":
+			w.Write([]byte(`{"data":{"value":"t0ken"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "token")
+
+	store, err := NewStore([]Source{
+		{ID: "password", Vault: "secret/data/app#password", TTL: time.Minute},
+		{ID: "app", Vault: "secret/data/app"},
+		{ID: "token", Vault: "kv/token"},
+		{ID: "missing", Vault: "kv/missing"},
+	})
+	require.NoError(t, err)
+
+	ctx := context.TODO()
+	for i := 0; i < 2; i++ {
+		dt, err := store.GetSecret(ctx, "password")
+		require.NoError(t, err)
+		require.Equal(t, "p4ss", string(dt))
+	}
+	require.Equal(t, 1, requests)
+
+	dt, err := store.GetSecret(ctx, "app")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"password":"p4ss","user":"app"}`, string(dt))
+
+	dt, err = store.GetSecret(ctx, "token")
+	require.NoError(t, err)
+	require.Equal(t, "t0ken", string(dt))
+
+	_, err = store.GetSecret(ctx, "missing")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to fetch secret missing")
+}
+
+func TestTemplateSecret(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "password"), []byte("p4ss"), 0600))
+	tmpl := filepath.Join(dir, "auth.tmpl")
+	require.NoError(t, os.WriteFile(tmpl, []byte(`{{ env "SECRET_USER" }}:{{ file "`+filepath.Join(dir, "password")+`" }}`), 0600))
+	t.Setenv("SECRET_USER", "app")
+
+	store, err := NewStore([]Source{{ID: "auth", Template: tmpl}})
+	require.NoError(t, err)
+	dt, err := store.GetSecret(context.TODO(), "auth")
+	require.NoError(t, err)
+	require.Equal(t, "app:p4ss", string(dt))
+
+	// the template is rendered again without a TTL
+	t.Setenv("SECRET_USER", "other")
+	dt, err = store.GetSecret(context.TODO(), "auth")
+	require.NoError(t, err)
+	require.Equal(t, "other:p4ss", string(dt))
+
+	_, err = NewStore([]Source{{ID: "auth", Template: tmpl, Env: "SECRET_USER"}})
+	require.Error(t, err)
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/sshforward"
+	"github.com/moby/buildkit/solver/pb"
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/agent"
@@ -26,8 +27,21 @@ type AgentConfig struct {
 	Paths []string
 }
 
+// SocketConfig is the config for a single exposed client socket
+type SocketConfig struct {
+	ID   string
+	Path string
+}
+
 // NewSSHAgentProvider creates a session provider that allows access to ssh agent
 func NewSSHAgentProvider(confs []AgentConfig) (session.Attachable, error) {
+	return NewProvider(confs, nil)
+}
+
+// NewProvider creates a session provider that allows access to ssh agents and
+// forwards the sockets to the socket mounts of the builds, e.g. a Docker or a
+// GPG agent socket.
+func NewProvider(confs []AgentConfig, sockets []SocketConfig) (session.Attachable, error) {
 	m := map[string]source{}
 	for _, conf := range confs {
 		if len(conf.Paths) == 0 || len(conf.Paths) == 1 && conf.Paths[0] == "" {
@@ -55,12 +69,30 @@ func NewSSHAgentProvider(confs []AgentConfig) (session.Attachable, error) {
 		m[conf.ID] = src
 	}
 
+	for _, conf := range sockets {
+		if conf.ID == "" || conf.Path == "" {
+			return nil, errors.Errorf("invalid socket %s=%s, id and path are required", conf.ID, conf.Path)
+		}
+		id := pb.SSHSocketIDPrefix + conf.ID
+		if _, ok := m[id]; ok {
+			return nil, errors.Errorf("invalid duplicate socket ID %s", conf.ID)
+		}
+		socket := getWindowsPipeDialer(conf.Path)
+		if socket == nil {
+			socket = &socketDialer{path: conf.Path, dialer: unixSocketDialer}
+		}
+		m[id] = source{socket: socket, raw: true}
+	}
+
 	return &socketProvider{m: m}, nil
 }
 
 type source struct {
 	agent  agent.Agent
 	socket *socketDialer
+	// raw sources forward the connections to the socket without the ssh
+	// agent protocol
+	raw bool
 }
 
 type socketDialer struct {
@@ -109,6 +141,14 @@ func (sp *socketProvider) ForwardAgent(stream sshforward.SSH_ForwardAgentServer)
 		return errors.Errorf("unset ssh forward key %s", id)
 	}
 
+	if src.raw {
+		conn, err := src.socket.Dial()
+		if err != nil {
+			return errors.Wrapf(err, "failed to connect to %s", src.socket)
+		}
+		return sshforward.Copy(context.TODO(), conn, stream, nil)
+	}
+
 	var a agent.Agent
 
 	if src.socket != nil {
@@ -19,3 +19,25 @@ func TestToAgentSource(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestSocketProvider(t *testing.T) {
+	configs, err := build.ParseSocket([]string{"docker=/var/run/docker.sock"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sshprovider.NewProvider(nil, configs); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := build.ParseSocket([]string{"docker"}); err == nil {
+		t.Fatal("expected error for socket without path")
+	}
+
+	_, err = sshprovider.NewProvider(nil, []sshprovider.SocketConfig{
+		{ID: "gpg", Path: "/tmp/a.sock"},
+		{ID: "gpg", Path: "/tmp/b.sock"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Fatalf("expected duplicate socket error, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package solver
+
+import (
+	"context"
+	"sync"
+)
+
+// CacheStats are the statistics of the cache of the vertexes run by a job.
+type CacheStats struct {
+	// Vertexes is the number of vertexes loaded from the cache or executed.
+	Vertexes int
+	// LocalHits is the number of vertexes loaded from the local cache.
+	LocalHits int
+	// RemoteHits is the number of vertexes loaded from imported caches.
+	RemoteHits int
+	// RemoteBytes is the size of the blobs of the results loaded from
+	// imported caches.
+	RemoteBytes int64
+}
+
+// BlobSizer is implemented by the results that know the size of the blob of
+// their content. The sizes of the results loaded from imported caches are
+// counted in CacheStats.RemoteBytes.
+type BlobSizer interface {
+	BlobSize(context.Context) (int64, error)
+}
+
+type cacheStats struct {
+	mu    sync.Mutex
+	stats CacheStats
+}
+
+func (cs *cacheStats) add(hit bool, remote bool, size int64) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.stats.Vertexes++
+	switch {
+	case hit && remote:
+		cs.stats.RemoteHits++
+		cs.stats.RemoteBytes += size
+	case hit:
+		cs.stats.LocalHits++
+	}
+}
+
+// CacheStats returns the statistics of the cache of the vertexes run by the
+// job so far. Vertexes shared with other jobs are counted in all of them.
+func (j *Job) CacheStats() CacheStats {
+	j.cacheStats.mu.Lock()
+	defer j.cacheStats.mu.Unlock()
+	return j.cacheStats.stats
+}
+
+// recordCacheLoad counts the result of the vertex loaded from the cache
+// record in the statistics of the jobs of the vertex.
+func (s *state) recordCacheLoad(ctx context.Context, rec *CacheRecord, res Result) {
+	remote := rec.cacheManager != nil && rec.cacheManager.ID() != s.mainCache.ID()
+	var size int64
+	if remote {
+		if bs, ok := res.(BlobSizer); ok {
+			size, _ = bs.BlobSize(ctx)
+		}
+	}
+	s.recordCache(true, remote, size)
+}
+
+// recordCache counts the vertex in the statistics of its jobs.
+func (s *state) recordCache(hit bool, remote bool, size int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for j := range s.jobs {
+		j.cacheStats.add(hit, remote, size)
+	}
+}
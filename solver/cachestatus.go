@@ -0,0 +1,248 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// VertexCacheStatus describes whether the result of a vertex can be loaded
+// from the cache without executing it.
+type VertexCacheStatus struct {
+	Digest digest.Digest
+	Name   string
+	Cached bool
+	// Uncertain is set when the vertex was not found in the cache but its cache
+	// key depends on the contents of an input that is only known after the
+	// input has been built.
+	Uncertain bool
+	// Local and Remote report if the result is in the local cache or in one
+	// of the imported caches.
+	Local  bool
+	Remote bool
+	// Key is the cache key of the vertex chained from its cache map digests
+	// and the keys of its inputs. Keys are stable between builds of the same
+	// graph, so the first vertex whose key changed is the one that caused a
+	// rebuild.
+	Key digest.Digest
+	// CacheMapDigests are the checksums of the operation, e.g. the manifest
+	// digest of an image or the commit of a git source.
+	CacheMapDigests []digest.Digest
+	// Inputs are the keys of the inputs of the vertex. The keys of inputs
+	// matched by the checksum of their contents are not known before the input
+	// is built and are empty.
+	Inputs []digest.Digest
+}
+
+// CacheStatus computes the cache keys of the graph of the edge and reports
+// for every vertex if it would be a cache hit, without executing anything.
+// Vertexes are returned with their inputs before them.
+func (j *Job) CacheStatus(ctx context.Context, e Edge) ([]*VertexCacheStatus, error) {
+	v, err := j.list.load(e.Vertex, nil, j)
+	if err != nil {
+		return nil, err
+	}
+	e.Vertex = v
+
+	cs := &cacheStatusWalker{
+		j:        j,
+		edges:    map[cacheStatusKey]*edgeCacheStatus{},
+		vertexes: map[digest.Digest]*VertexCacheStatus{},
+	}
+	if _, err := cs.walk(ctx, e); err != nil {
+		return nil, err
+	}
+	return cs.out, nil
+}
+
+type cacheStatusKey struct {
+	dgst  digest.Digest
+	index Index
+}
+
+type edgeCacheStatus struct {
+	keys      []*CacheKey
+	cached    bool
+	uncertain bool
+	local     bool
+	remote    bool
+	key       digest.Digest
+}
+
+type cacheStatusWalker struct {
+	j        *Job
+	edges    map[cacheStatusKey]*edgeCacheStatus
+	vertexes map[digest.Digest]*VertexCacheStatus
+	out      []*VertexCacheStatus
+}
+
+func (cs *cacheStatusWalker) walk(ctx context.Context, e Edge) (*edgeCacheStatus, error) {
+	k := cacheStatusKey{dgst: e.Vertex.Digest(), index: e.Index}
+	if s, ok := cs.edges[k]; ok {
+		return s, nil
+	}
+
+	deps := make([]*edgeCacheStatus, len(e.Vertex.Inputs()))
+	for i, inp := range e.Vertex.Inputs() {
+		s, err := cs.walk(ctx, inp)
+		if err != nil {
+			return nil, err
+		}
+		deps[i] = s
+	}
+
+	edge := cs.j.list.getEdge(e)
+	if edge == nil {
+		return nil, errors.Errorf("vertex %s not loaded", e.Vertex.Digest())
+	}
+	op := edge.op
+
+	s := &edgeCacheStatus{}
+	cm := op.Cache()
+	var (
+		slowCache       bool
+		cacheMapDigests []digest.Digest
+		inputKeys       []digest.Digest
+	)
+	for i := 0; ; i++ {
+		resp, err := op.CacheMap(ctx, i)
+		if err != nil {
+			return nil, err
+		}
+		cacheMapDigests = append(cacheMapDigests, resp.CacheMap.Digest)
+		inputKeys = make([]digest.Digest, len(deps))
+		for i, dep := range resp.CacheMap.Deps {
+			if dep.ComputeDigestFunc != nil {
+				slowCache = true
+			} else if i < len(deps) {
+				inputKeys[i] = deps[i].key
+			}
+		}
+		if !op.IgnoreCache() {
+			keys, err := queryCacheKeys(cm, resp.CacheMap, deps, e.Index)
+			if err != nil {
+				return nil, err
+			}
+			s.keys = append(s.keys, keys...)
+		}
+		if resp.complete {
+			break
+		}
+	}
+
+	st := cs.j.list.getState(e)
+	if st == nil {
+		return nil, errors.Errorf("vertex %s not loaded", e.Vertex.Digest())
+	}
+	mainID := st.mainCache.ID()
+	for _, k := range s.keys {
+		records, err := cm.Records(k)
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range records {
+			s.cached = true
+			if rec.cacheManager != nil && rec.cacheManager.ID() != mainID {
+				s.remote = true
+			} else {
+				s.local = true
+			}
+		}
+	}
+	if !s.cached && !op.IgnoreCache() {
+		if slowCache {
+			s.uncertain = true
+		}
+		for _, dep := range deps {
+			if dep.uncertain {
+				s.uncertain = true
+			}
+		}
+	}
+	vertexKey := chainCacheKey(cacheMapDigests, inputKeys)
+	s.key = rootKey(vertexKey, e.Index)
+	cs.edges[k] = s
+
+	vs, ok := cs.vertexes[k.dgst]
+	if !ok {
+		vs = &VertexCacheStatus{
+			Digest:          k.dgst,
+			Name:            e.Vertex.Name(),
+			Cached:          true,
+			Local:           true,
+			Remote:          true,
+			Key:             vertexKey,
+			CacheMapDigests: cacheMapDigests,
+			Inputs:          inputKeys,
+		}
+		cs.vertexes[k.dgst] = vs
+		cs.out = append(cs.out, vs)
+	}
+	vs.Cached = vs.Cached && s.cached
+	vs.Local = vs.Local && s.local
+	vs.Remote = vs.Remote && s.remote
+	vs.Uncertain = vs.Uncertain || s.uncertain
+	return s, nil
+}
+
+// chainCacheKey returns the key of a vertex from the digests of its cache
+// maps and the keys of its inputs. Inputs with an unknown key are marked as
+// content based.
+func chainCacheKey(cacheMapDigests []digest.Digest, inputKeys []digest.Digest) digest.Digest {
+	parts := make([]string, 0, len(cacheMapDigests)+len(inputKeys))
+	for _, dgst := range cacheMapDigests {
+		parts = append(parts, dgst.String())
+	}
+	for i, k := range inputKeys {
+		if k == "" {
+			parts = append(parts, fmt.Sprintf("%d:content", i))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d:%s", i, k))
+		}
+	}
+	return digest.FromBytes([]byte(strings.Join(parts, "\n")))
+}
+
+// queryCacheKeys returns the keys of the cache map that match the keys of all
+// the inputs.
+func queryCacheKeys(cm CacheManager, cacheMap *CacheMap, deps []*edgeCacheStatus, output Index) ([]*CacheKey, error) {
+	if len(deps) == 0 {
+		return cm.Query(nil, 0, cacheMap.Digest, output)
+	}
+
+	var matches map[string]*CacheKey
+	for i, dep := range deps {
+		if len(dep.keys) == 0 {
+			return nil, nil
+		}
+		depKeys := make([]CacheKeyWithSelector, 0, len(dep.keys))
+		for _, k := range dep.keys {
+			depKeys = append(depKeys, CacheKeyWithSelector{
+				Selector: cacheMap.Deps[i].Selector,
+				CacheKey: ExportableCacheKey{CacheKey: k},
+			})
+		}
+		keys, err := cm.Query(depKeys, Index(i), cacheMap.Digest, output)
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]*CacheKey, len(keys))
+		for _, k := range keys {
+			if matches == nil {
+				m[k.ID] = k
+			} else if _, ok := matches[k.ID]; ok {
+				m[k.ID] = k
+			}
+		}
+		matches = m
+	}
+
+	keys := make([]*CacheKey, 0, len(matches))
+	for _, k := range matches {
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
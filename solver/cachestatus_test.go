@@ -0,0 +1,132 @@
+package solver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheStatus(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	l := NewSolver(SolverOpt{
+		ResolveOpFunc: testOpResolver,
+		DefaultCache:  NewInMemoryCacheManager(),
+	})
+	defer l.Close()
+
+	graph := func(seed2 string) Edge {
+		return Edge{
+			Vertex: vtx(vtxOpt{
+				name:         "v0-" + seed2,
+				cacheKeySeed: "seed0",
+				value:        "result0",
+				inputs: []Edge{
+					{Vertex: vtx(vtxOpt{
+						name:         "v1",
+						cacheKeySeed: "seed1",
+						value:        "result1",
+					})},
+					{Vertex: vtx(vtxOpt{
+						name:         "v2-" + seed2,
+						cacheKeySeed: seed2,
+						value:        "result2",
+					})},
+				},
+				slowCacheCompute: map[int]ResultBasedCacheFunc{
+					1: digestFromResult,
+				},
+			}),
+		}
+	}
+
+	j0, err := l.NewJob("j0")
+	require.NoError(t, err)
+
+	defer func() {
+		if j0 != nil {
+			j0.Discard()
+		}
+	}()
+
+	g0 := graph("seed2")
+	g0.Vertex.(*vertex).setupCallCounters()
+
+	status, err := j0.CacheStatus(ctx, g0)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(status))
+	for _, s := range status {
+		require.False(t, s.Cached, s.Name)
+	}
+	require.Equal(t, "v1", status[0].Name)
+	require.Equal(t, "v0-seed2", status[2].Name)
+	require.Equal(t, int64(0), *g0.Vertex.(*vertex).execCallCount)
+
+	keys := map[string]*VertexCacheStatus{}
+	for _, s := range status {
+		require.NotEmpty(t, s.Key, s.Name)
+		require.Equal(t, 1, len(s.CacheMapDigests), s.Name)
+		keys[s.Name] = s
+	}
+	// the second input is matched by its contents
+	require.Equal(t, 2, len(keys["v0-seed2"].Inputs))
+	require.NotEmpty(t, keys["v0-seed2"].Inputs[0])
+	require.Empty(t, keys["v0-seed2"].Inputs[1])
+
+	_, _, err = j0.Build(ctx, g0)
+	require.NoError(t, err)
+
+	require.NoError(t, j0.Discard())
+	j0 = nil
+
+	j1, err := l.NewJob("j1")
+	require.NoError(t, err)
+
+	defer func() {
+		if j1 != nil {
+			j1.Discard()
+		}
+	}()
+
+	g1 := graph("seed2")
+	g1.Vertex.(*vertex).setupCallCounters()
+
+	status, err = j1.CacheStatus(ctx, g1)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(status))
+	for _, s := range status {
+		require.True(t, s.Cached, s.Name)
+		require.True(t, s.Local, s.Name)
+		require.False(t, s.Remote, s.Name)
+		require.False(t, s.Uncertain, s.Name)
+		require.Equal(t, keys[s.Name].Key, s.Key, s.Name)
+	}
+	require.Equal(t, int64(0), *g1.Vertex.(*vertex).execCallCount)
+
+	// the changed input is not cached and the cache of the vertex depending
+	// on it can only be known after computing the checksum of its result
+	g2 := graph("seed2-changed")
+	g2.Vertex.(*vertex).setupCallCounters()
+
+	status, err = j1.CacheStatus(ctx, g2)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(status))
+
+	byName := map[string]*VertexCacheStatus{}
+	for _, s := range status {
+		byName[s.Name] = s
+	}
+	require.True(t, byName["v1"].Cached)
+	require.False(t, byName["v2-seed2-changed"].Cached)
+	require.False(t, byName["v2-seed2-changed"].Uncertain)
+	require.False(t, byName["v0-seed2-changed"].Cached)
+	require.True(t, byName["v0-seed2-changed"].Uncertain)
+	require.Equal(t, keys["v1"].Key, byName["v1"].Key)
+	require.NotEqual(t, keys["v2-seed2"].Key, byName["v2-seed2-changed"].Key)
+	require.Equal(t, int64(0), *g2.Vertex.(*vertex).execCallCount)
+
+	require.NoError(t, j1.Discard())
+	j1 = nil
+}
@@ -2,9 +2,11 @@ package solver
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
+	"github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/solver/internal/pipe"
 	"github.com/moby/buildkit/util/bklog"
 	digest "github.com/opencontainers/go-digest"
@@ -108,6 +110,9 @@ type edgeState struct {
 	result   *SharedCachedResult
 	cacheMap *CacheMap
 	keys     []ExportableCacheKey
+	// executed is set when the result was computed instead of loaded from
+	// the cache.
+	executed bool
 }
 
 type edgeRequest struct {
@@ -448,6 +453,7 @@ func (e *edge) processUpdate(upt pipe.Receiver) (depChanged bool) {
 		} else {
 			e.result = NewSharedCachedResult(upt.Status().Value.(CachedResult))
 			e.state = edgeStatusComplete
+			e.executed = !e.execCacheLoad
 		}
 		return true
 	}
@@ -606,7 +612,9 @@ func (e *edge) recalcCurrentState() {
 	if e.cacheMap != nil {
 		for _, dep := range e.deps {
 			isSlowCacheIncomplete := e.slowCacheFunc(dep) != nil && (dep.state == edgeStatusCacheSlow || (dep.state == edgeStatusComplete && !dep.slowCacheComplete))
-			isSlowIncomplete := (e.slowCacheFunc(dep) != nil || e.preprocessFunc(dep) != nil) && (dep.state == edgeStatusCacheSlow || (dep.state == edgeStatusComplete && !dep.slowCacheComplete))
+			// the inputs with only a preprocess function are preprocessed
+			// when the op runs, their results can stay lazy on cache hits
+			isSlowIncomplete := e.slowCacheFunc(dep) != nil && (dep.state == edgeStatusCacheSlow || (dep.state == edgeStatusComplete && !dep.slowCacheComplete))
 
 			if dep.state > stLow && len(dep.keyMap) == 0 && !isSlowIncomplete {
 				stLow = dep.state
@@ -827,7 +835,7 @@ func (e *edge) createInputRequests(desiredState edgeStatusType, f *pipeFactory,
 			}
 		}
 		// initialize function to compute cache key based on dependency result
-		if dep.state == edgeStatusComplete && dep.slowCacheReq == nil && (e.slowCacheFunc(dep) != nil || e.preprocessFunc(dep) != nil) && e.cacheMap != nil {
+		if dep.state == edgeStatusComplete && dep.slowCacheReq == nil && e.slowCacheFunc(dep) != nil && e.cacheMap != nil {
 			pfn := e.preprocessFunc(dep)
 			fn := e.slowCacheFunc(dep)
 			res := dep.result
@@ -862,7 +870,10 @@ func (e *edge) execIfPossible(f *pipeFactory) bool {
 			e.postpone(f)
 			return true
 		}
-		e.execReq = f.NewFuncRequest(e.execOp)
+		miss := e.cacheMiss()
+		e.execReq = f.NewFuncRequest(func(ctx context.Context) (interface{}, error) {
+			return e.execOp(ctx, miss)
+		})
 		e.execCacheLoad = false
 		return true
 	}
@@ -896,10 +907,85 @@ func (e *edge) loadCache(ctx context.Context) (interface{}, error) {
 	return NewCachedResult(res, []ExportableCacheKey{{CacheKey: rec.key, Exporter: &exporter{k: rec.key, record: rec, edge: e}}}), nil
 }
 
+// cacheMiss explains why the edge is executed instead of loaded from the
+// cache, from the first step of the cache lookup that found no match
+func (e *edge) cacheMiss() *client.CacheMiss {
+	if e.op.IgnoreCache() {
+		return &client.CacheMiss{
+			Reason:  client.CacheMissIgnoreCache,
+			Input:   -1,
+			Message: "cache disabled for the operation",
+		}
+	}
+	where := ""
+	if cm, ok := e.op.Cache().(*combinedCacheManager); ok && len(cm.cms) > 1 {
+		where = " in the local or the imported caches"
+	}
+	var miss *client.CacheMiss
+	if len(e.deps) == 0 {
+		if len(e.keys) == 0 && len(e.cacheMapDigests) > 0 {
+			dgst := e.cacheMapDigests[len(e.cacheMapDigests)-1]
+			miss = &client.CacheMiss{
+				Reason:  client.CacheMissDefinition,
+				Input:   -1,
+				Digest:  dgst,
+				Message: fmt.Sprintf("no cache record for the operation with checksum %s%s", dgst, where),
+			}
+		}
+	} else {
+		unmatched := false
+		for i, dep := range e.deps {
+			if len(dep.keyMap) > 0 {
+				continue
+			}
+			name := e.edge.Vertex.Inputs()[i].Vertex.Name()
+			if dep.slowCacheKey != nil {
+				dgst := dep.slowCacheKey.Digest()
+				return &client.CacheMiss{
+					Reason:  client.CacheMissInputContent,
+					Input:   i,
+					Digest:  dgst,
+					Message: fmt.Sprintf("no cache record for the content of input %d %q with checksum %s%s", i, name, dgst, where),
+				}
+			}
+			if dep.executed {
+				return &client.CacheMiss{
+					Reason:  client.CacheMissInput,
+					Input:   i,
+					Message: fmt.Sprintf("input %d %q was rebuilt", i, name),
+				}
+			}
+			unmatched = true
+		}
+		if unmatched && e.cacheMap != nil {
+			miss = &client.CacheMiss{
+				Reason:  client.CacheMissDefinition,
+				Input:   -1,
+				Digest:  e.cacheMap.Digest,
+				Message: fmt.Sprintf("no cache record for the operation with checksum %s and its inputs%s", e.cacheMap.Digest, where),
+			}
+		} else if !unmatched && len(e.keys) == 0 {
+			miss = &client.CacheMiss{
+				Reason:  client.CacheMissInputs,
+				Input:   -1,
+				Message: "no cache record for the operation with all its inputs together" + where,
+			}
+		}
+	}
+	if miss == nil {
+		miss = &client.CacheMiss{
+			Reason:  client.CacheMissNoResult,
+			Input:   -1,
+			Message: "the cache record has no result left" + where,
+		}
+	}
+	return miss
+}
+
 // execOp creates a request to execute the vertex operation
-func (e *edge) execOp(ctx context.Context) (interface{}, error) {
+func (e *edge) execOp(ctx context.Context, miss *client.CacheMiss) (interface{}, error) {
 	cacheKeys, inputs := e.commitOptions()
-	results, subExporters, err := e.op.Exec(ctx, toResultSlice(inputs))
+	results, subExporters, err := e.op.Exec(ctx, toResultSlice(inputs), miss)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
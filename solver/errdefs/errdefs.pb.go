@@ -105,6 +105,138 @@ func (m *Source) GetRanges() []*pb.Range {
 	return nil
 }
 
+type Timeout struct {
+	// duration is the timeout of the execution in nanoseconds.
+	Duration             int64    `protobuf:"varint,1,opt,name=duration,proto3" json:"duration,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Timeout) Reset()         { *m = Timeout{} }
+func (m *Timeout) String() string { return proto.CompactTextString(m) }
+func (*Timeout) ProtoMessage()    {}
+func (*Timeout) Descriptor() ([]byte, []int) {
+	return fileDescriptor_689dc58a5060aff5, []int{2}
+}
+func (m *Timeout) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Timeout.Unmarshal(m, b)
+}
+func (m *Timeout) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Timeout.Marshal(b, m, deterministic)
+}
+func (m *Timeout) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Timeout.Merge(m, src)
+}
+func (m *Timeout) XXX_Size() int {
+	return xxx_messageInfo_Timeout.Size(m)
+}
+func (m *Timeout) XXX_DiscardUnknown() {
+	xxx_messageInfo_Timeout.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Timeout proto.InternalMessageInfo
+
+func (m *Timeout) GetDuration() int64 {
+	if m != nil {
+		return m.Duration
+	}
+	return 0
+}
+
+type UnverifiedImages struct {
+	Images               []*UnverifiedImage `protobuf:"bytes,1,rep,name=images,proto3" json:"images,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *UnverifiedImages) Reset()         { *m = UnverifiedImages{} }
+func (m *UnverifiedImages) String() string { return proto.CompactTextString(m) }
+func (*UnverifiedImages) ProtoMessage()    {}
+func (*UnverifiedImages) Descriptor() ([]byte, []int) {
+	return fileDescriptor_689dc58a5060aff5, []int{3}
+}
+func (m *UnverifiedImages) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UnverifiedImages.Unmarshal(m, b)
+}
+func (m *UnverifiedImages) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UnverifiedImages.Marshal(b, m, deterministic)
+}
+func (m *UnverifiedImages) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UnverifiedImages.Merge(m, src)
+}
+func (m *UnverifiedImages) XXX_Size() int {
+	return xxx_messageInfo_UnverifiedImages.Size(m)
+}
+func (m *UnverifiedImages) XXX_DiscardUnknown() {
+	xxx_messageInfo_UnverifiedImages.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UnverifiedImages proto.InternalMessageInfo
+
+func (m *UnverifiedImages) GetImages() []*UnverifiedImage {
+	if m != nil {
+		return m.Images
+	}
+	return nil
+}
+
+type UnverifiedImage struct {
+	Ref    string `protobuf:"bytes,1,opt,name=ref,proto3" json:"ref,omitempty"`
+	Digest string `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	// reason describes why the image could not be verified.
+	Reason               string   `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UnverifiedImage) Reset()         { *m = UnverifiedImage{} }
+func (m *UnverifiedImage) String() string { return proto.CompactTextString(m) }
+func (*UnverifiedImage) ProtoMessage()    {}
+func (*UnverifiedImage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_689dc58a5060aff5, []int{4}
+}
+func (m *UnverifiedImage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UnverifiedImage.Unmarshal(m, b)
+}
+func (m *UnverifiedImage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UnverifiedImage.Marshal(b, m, deterministic)
+}
+func (m *UnverifiedImage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UnverifiedImage.Merge(m, src)
+}
+func (m *UnverifiedImage) XXX_Size() int {
+	return xxx_messageInfo_UnverifiedImage.Size(m)
+}
+func (m *UnverifiedImage) XXX_DiscardUnknown() {
+	xxx_messageInfo_UnverifiedImage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UnverifiedImage proto.InternalMessageInfo
+
+func (m *UnverifiedImage) GetRef() string {
+	if m != nil {
+		return m.Ref
+	}
+	return ""
+}
+
+func (m *UnverifiedImage) GetDigest() string {
+	if m != nil {
+		return m.Digest
+	}
+	return ""
+}
+
+func (m *UnverifiedImage) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
 type FrontendCap struct {
 	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -116,7 +248,7 @@ func (m *FrontendCap) Reset()         { *m = FrontendCap{} }
 func (m *FrontendCap) String() string { return proto.CompactTextString(m) }
 func (*FrontendCap) ProtoMessage()    {}
 func (*FrontendCap) Descriptor() ([]byte, []int) {
-	return fileDescriptor_689dc58a5060aff5, []int{2}
+	return fileDescriptor_689dc58a5060aff5, []int{5}
 }
 func (m *FrontendCap) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_FrontendCap.Unmarshal(m, b)
@@ -154,7 +286,7 @@ func (m *Subrequest) Reset()         { *m = Subrequest{} }
 func (m *Subrequest) String() string { return proto.CompactTextString(m) }
 func (*Subrequest) ProtoMessage()    {}
 func (*Subrequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_689dc58a5060aff5, []int{3}
+	return fileDescriptor_689dc58a5060aff5, []int{6}
 }
 func (m *Subrequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Subrequest.Unmarshal(m, b)
@@ -198,7 +330,7 @@ func (m *Solve) Reset()         { *m = Solve{} }
 func (m *Solve) String() string { return proto.CompactTextString(m) }
 func (*Solve) ProtoMessage()    {}
 func (*Solve) Descriptor() ([]byte, []int) {
-	return fileDescriptor_689dc58a5060aff5, []int{4}
+	return fileDescriptor_689dc58a5060aff5, []int{7}
 }
 func (m *Solve) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Solve.Unmarshal(m, b)
@@ -294,7 +426,7 @@ func (m *FileAction) Reset()         { *m = FileAction{} }
 func (m *FileAction) String() string { return proto.CompactTextString(m) }
 func (*FileAction) ProtoMessage()    {}
 func (*FileAction) Descriptor() ([]byte, []int) {
-	return fileDescriptor_689dc58a5060aff5, []int{5}
+	return fileDescriptor_689dc58a5060aff5, []int{8}
 }
 func (m *FileAction) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_FileAction.Unmarshal(m, b)
@@ -333,7 +465,7 @@ func (m *ContentCache) Reset()         { *m = ContentCache{} }
 func (m *ContentCache) String() string { return proto.CompactTextString(m) }
 func (*ContentCache) ProtoMessage()    {}
 func (*ContentCache) Descriptor() ([]byte, []int) {
-	return fileDescriptor_689dc58a5060aff5, []int{6}
+	return fileDescriptor_689dc58a5060aff5, []int{9}
 }
 func (m *ContentCache) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ContentCache.Unmarshal(m, b)
@@ -363,6 +495,9 @@ func (m *ContentCache) GetIndex() int64 {
 func init() {
 	proto.RegisterType((*Vertex)(nil), "errdefs.Vertex")
 	proto.RegisterType((*Source)(nil), "errdefs.Source")
+	proto.RegisterType((*Timeout)(nil), "errdefs.Timeout")
+	proto.RegisterType((*UnverifiedImages)(nil), "errdefs.UnverifiedImages")
+	proto.RegisterType((*UnverifiedImage)(nil), "errdefs.UnverifiedImage")
 	proto.RegisterType((*FrontendCap)(nil), "errdefs.FrontendCap")
 	proto.RegisterType((*Subrequest)(nil), "errdefs.Subrequest")
 	proto.RegisterType((*Solve)(nil), "errdefs.Solve")
@@ -373,27 +508,32 @@ func init() {
 func init() { proto.RegisterFile("errdefs.proto", fileDescriptor_689dc58a5060aff5) }
 
 var fileDescriptor_689dc58a5060aff5 = []byte{
-	// 348 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x91, 0xcd, 0x8e, 0xd3, 0x30,
-	0x14, 0x85, 0x27, 0xbf, 0x43, 0x6e, 0x81, 0x85, 0x81, 0x51, 0x34, 0xab, 0x8c, 0xc5, 0xa2, 0x48,
-	0x90, 0x48, 0xc3, 0x13, 0x40, 0xd1, 0x68, 0x66, 0x55, 0xc9, 0x95, 0xd8, 0xc7, 0xc9, 0x4d, 0x6b,
-	0x48, 0x6c, 0xe3, 0xd8, 0xa8, 0xbc, 0x1b, 0x0f, 0x87, 0xe2, 0xa4, 0x65, 0x16, 0xdd, 0xe5, 0xe4,
-	0xfb, 0x7c, 0xed, 0x63, 0xc3, 0x2b, 0x34, 0xa6, 0xc5, 0x6e, 0x2c, 0xb5, 0x51, 0x56, 0x91, 0xeb,
-	0x25, 0xde, 0x7e, 0xdc, 0x0b, 0x7b, 0x70, 0xbc, 0x6c, 0xd4, 0x50, 0x0d, 0x8a, 0xff, 0xa9, 0xb8,
-	0x13, 0x7d, 0xfb, 0x53, 0xd8, 0x6a, 0x54, 0xfd, 0x6f, 0x34, 0x95, 0xe6, 0x95, 0xd2, 0xcb, 0x32,
-	0x5a, 0x40, 0xfa, 0x1d, 0x8d, 0xc5, 0x23, 0xb9, 0x81, 0xb4, 0x15, 0x7b, 0x1c, 0x6d, 0x1e, 0x14,
-	0xc1, 0x3a, 0x63, 0x4b, 0xa2, 0x5b, 0x48, 0x77, 0xca, 0x99, 0x06, 0x09, 0x85, 0x58, 0xc8, 0x4e,
-	0x79, 0xbe, 0xba, 0x7f, 0x5d, 0x6a, 0x5e, 0xce, 0xe4, 0x49, 0x76, 0x8a, 0x79, 0x46, 0xee, 0x20,
-	0x35, 0xb5, 0xdc, 0xe3, 0x98, 0x87, 0x45, 0xb4, 0x5e, 0xdd, 0x67, 0x93, 0xc5, 0xa6, 0x3f, 0x6c,
-	0x01, 0xf4, 0x0e, 0x56, 0x0f, 0x46, 0x49, 0x8b, 0xb2, 0xdd, 0xd4, 0x9a, 0x10, 0x88, 0x65, 0x3d,
-	0xe0, 0xb2, 0xab, 0xff, 0xa6, 0x05, 0xc0, 0xce, 0x71, 0x83, 0xbf, 0x1c, 0x8e, 0xf6, 0xa2, 0xf1,
-	0x37, 0x80, 0x64, 0x37, 0xf5, 0x21, 0xb7, 0xf0, 0x42, 0x48, 0xed, 0xec, 0xd3, 0xb7, 0x31, 0x0f,
-	0x8a, 0x68, 0x9d, 0xb1, 0x73, 0x9e, 0xd8, 0xa0, 0x9c, 0xf4, 0x2c, 0x9c, 0xd9, 0x29, 0x93, 0x1b,
-	0x08, 0x95, 0xce, 0x23, 0xdf, 0x25, 0x9d, 0x4e, 0xb9, 0xd5, 0x2c, 0x54, 0x9a, 0x7c, 0x80, 0xb8,
-	0x13, 0x3d, 0xe6, 0xb1, 0x27, 0x6f, 0xca, 0xd3, 0x35, 0x3f, 0x88, 0x1e, 0xbf, 0x34, 0x56, 0x28,
-	0xf9, 0x78, 0xc5, 0xbc, 0x42, 0x3e, 0x41, 0xd2, 0xd4, 0xcd, 0x01, 0xf3, 0xc4, 0xbb, 0xef, 0xce,
-	0xee, 0xc6, 0xd7, 0xb3, 0x9b, 0x09, 0x3e, 0x5e, 0xb1, 0xd9, 0xfa, 0x9a, 0xc1, 0xf5, 0xe8, 0xf8,
-	0x0f, 0x6c, 0x2c, 0xa5, 0x00, 0xff, 0xe7, 0x91, 0xb7, 0x90, 0x08, 0xd9, 0xe2, 0xd1, 0x37, 0x8c,
-	0xd8, 0x1c, 0xe8, 0x7b, 0x78, 0xf9, 0x7c, 0xce, 0x65, 0x8b, 0xa7, 0xfe, 0x1d, 0x3f, 0xff, 0x0b,
-	0x00, 0x00, 0xff, 0xff, 0x1e, 0xfa, 0x9c, 0x6f, 0x0f, 0x02, 0x00, 0x00,
+	// 424 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x92, 0x5f, 0x6f, 0xd3, 0x30,
+	0x14, 0xc5, 0xd7, 0xb4, 0x4d, 0xe9, 0x2d, 0x7f, 0x26, 0x03, 0x53, 0xb4, 0xa7, 0xce, 0x02, 0x69,
+	0x48, 0x90, 0xa0, 0xf2, 0x09, 0xa0, 0xd3, 0xb4, 0x3e, 0x4d, 0x72, 0x81, 0xf7, 0xfc, 0xb9, 0xe9,
+	0x0c, 0x8d, 0xaf, 0x71, 0xec, 0x69, 0x7c, 0x37, 0x3e, 0x1c, 0xb2, 0x93, 0x65, 0xd5, 0xb4, 0xb7,
+	0x7b, 0xee, 0xef, 0xf4, 0xa8, 0xf7, 0x38, 0xf0, 0x02, 0x8d, 0xa9, 0xb0, 0x6e, 0x53, 0x6d, 0xc8,
+	0x12, 0x9b, 0xf5, 0xf2, 0xf4, 0xe3, 0x4e, 0xda, 0x1b, 0x57, 0xa4, 0x25, 0x35, 0x59, 0x43, 0xc5,
+	0xdf, 0xac, 0x70, 0x72, 0x5f, 0xfd, 0x96, 0x36, 0x6b, 0x69, 0x7f, 0x8b, 0x26, 0xd3, 0x45, 0x46,
+	0xba, 0xff, 0x19, 0x5f, 0x42, 0xfc, 0x13, 0x8d, 0xc5, 0x3b, 0x76, 0x02, 0x71, 0x25, 0x77, 0xd8,
+	0xda, 0x64, 0xb4, 0x1c, 0x9d, 0xcf, 0x45, 0xaf, 0xf8, 0x35, 0xc4, 0x5b, 0x72, 0xa6, 0x44, 0xc6,
+	0x61, 0x22, 0x55, 0x4d, 0x81, 0x2f, 0x56, 0x2f, 0x53, 0x5d, 0xa4, 0x1d, 0xd9, 0xa8, 0x9a, 0x44,
+	0x60, 0xec, 0x0c, 0x62, 0x93, 0xab, 0x1d, 0xb6, 0x49, 0xb4, 0x1c, 0x9f, 0x2f, 0x56, 0x73, 0xef,
+	0x12, 0x7e, 0x23, 0x7a, 0xc0, 0xdf, 0xc3, 0xec, 0xbb, 0x6c, 0x90, 0x9c, 0x65, 0xa7, 0xf0, 0xac,
+	0x72, 0x26, 0xb7, 0x92, 0x54, 0x48, 0x1d, 0x8b, 0x41, 0xf3, 0x0b, 0x38, 0xfe, 0xa1, 0x6e, 0xd1,
+	0xc8, 0x5a, 0x62, 0xb5, 0x69, 0xf2, 0x1d, 0xb6, 0xec, 0x33, 0xc4, 0x32, 0x4c, 0xc9, 0x28, 0xa4,
+	0x27, 0xe9, 0x7d, 0x09, 0x8f, 0xac, 0xa2, 0xf7, 0xf1, 0x2d, 0xbc, 0x7a, 0x84, 0xd8, 0x31, 0x8c,
+	0x0d, 0xd6, 0xfd, 0x95, 0x7e, 0x3c, 0x38, 0x3d, 0x3a, 0x3c, 0xdd, 0xef, 0x0d, 0xe6, 0x2d, 0xa9,
+	0x64, 0xdc, 0xed, 0x3b, 0xc5, 0xcf, 0x60, 0x71, 0x69, 0x48, 0x59, 0x54, 0xd5, 0x3a, 0xd7, 0x8c,
+	0xc1, 0x44, 0xe5, 0x0d, 0xf6, 0x89, 0x61, 0xe6, 0x4b, 0x80, 0xad, 0x2b, 0x0c, 0xfe, 0x71, 0x3e,
+	0xe8, 0x29, 0xc7, 0xbf, 0x11, 0x4c, 0xb7, 0xfe, 0x45, 0x7c, 0x0b, 0x52, 0x69, 0x67, 0x37, 0x17,
+	0xdd, 0x5d, 0x73, 0x31, 0x68, 0xcf, 0x1a, 0x72, 0x2a, 0xb0, 0xa8, 0x63, 0xf7, 0x9a, 0x9d, 0x40,
+	0x44, 0x3a, 0xfc, 0xb5, 0xc5, 0x2a, 0xf6, 0x3d, 0x5f, 0x6b, 0x11, 0x91, 0x66, 0x1f, 0x60, 0x52,
+	0xcb, 0x3d, 0x26, 0x93, 0x40, 0x5e, 0x0f, 0x1d, 0x5d, 0xca, 0x3d, 0x7e, 0x2d, 0x7d, 0xb9, 0x57,
+	0x47, 0x22, 0x58, 0xd8, 0x27, 0x98, 0x96, 0x79, 0x79, 0x83, 0xc9, 0x34, 0x78, 0xdf, 0x0e, 0xde,
+	0x75, 0x38, 0xcf, 0xae, 0x3d, 0xbc, 0x3a, 0x12, 0x9d, 0xeb, 0xdb, 0x1c, 0x66, 0xad, 0x2b, 0x7e,
+	0x61, 0x69, 0x39, 0x07, 0x78, 0xc8, 0x63, 0x6f, 0x60, 0x2a, 0x55, 0x85, 0x77, 0xfd, 0x2b, 0x76,
+	0x82, 0xbf, 0x83, 0xe7, 0x87, 0x39, 0x4f, 0xbb, 0x8a, 0x38, 0x7c, 0x89, 0x5f, 0xfe, 0x0f, 0x00,
+	0x5e, 0x5d, 0x43, 0x1c, 0xd1, 0x02, 0x00, 0x00,
 }
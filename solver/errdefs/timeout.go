@@ -0,0 +1,44 @@
+package errdefs
+
+import (
+	"time"
+
+	"github.com/containerd/typeurl"
+	"github.com/moby/buildkit/util/grpcerrors"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	typeurl.Register((*Timeout)(nil), "github.com/moby/buildkit", "errdefs.Timeout+json")
+}
+
+// TimeoutError is returned when the execution of a vertex exceeds its
+// timeout.
+type TimeoutError struct {
+	Timeout
+	error
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.error
+}
+
+func (e *TimeoutError) ToProto() grpcerrors.TypedErrorProto {
+	return &e.Timeout
+}
+
+// WithTimeout wraps the error of an execution that exceeded the timeout.
+func WithTimeout(err error, d time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	var te *TimeoutError
+	if errors.As(err, &te) {
+		return err
+	}
+	return &TimeoutError{Timeout: Timeout{Duration: int64(d)}, error: errors.Wrapf(err, "timed out after %v", d)}
+}
+
+func (v *Timeout) WrapError(err error) error {
+	return &TimeoutError{error: err, Timeout: *v}
+}
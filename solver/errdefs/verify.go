@@ -0,0 +1,46 @@
+package errdefs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/containerd/typeurl"
+	"github.com/moby/buildkit/util/grpcerrors"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	typeurl.Register((*UnverifiedImages)(nil), "github.com/moby/buildkit", "errdefs.UnverifiedImages+json")
+}
+
+// UnverifiedImagesError is returned when images don't satisfy their
+// verification policy.
+type UnverifiedImagesError struct {
+	UnverifiedImages
+	error
+}
+
+func (e *UnverifiedImagesError) Unwrap() error {
+	return e.error
+}
+
+func (e *UnverifiedImagesError) ToProto() grpcerrors.TypedErrorProto {
+	return &e.UnverifiedImages
+}
+
+// NewUnverifiedImagesError returns the error listing the images that could not
+// be verified.
+func NewUnverifiedImagesError(images ...*UnverifiedImage) error {
+	msgs := make([]string, 0, len(images))
+	for _, img := range images {
+		msgs = append(msgs, fmt.Sprintf("%s@%s: %s", img.Ref, img.Digest, img.Reason))
+	}
+	return &UnverifiedImagesError{
+		UnverifiedImages: UnverifiedImages{Images: images},
+		error:            errors.Errorf("failed to verify images: %s", strings.Join(msgs, "; ")),
+	}
+}
+
+func (v *UnverifiedImages) WrapError(err error) error {
+	return &UnverifiedImagesError{error: err, UnverifiedImages: *v}
+}
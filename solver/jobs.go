@@ -12,11 +12,13 @@ import (
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/solver/errdefs"
 	"github.com/moby/buildkit/util/flightcontrol"
+	"github.com/moby/buildkit/util/metrics"
 	"github.com/moby/buildkit/util/progress"
 	"github.com/moby/buildkit/util/tracing"
 	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
 	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
 )
 
 // ResolveOpFunc finds an Op implementation for a Vertex
@@ -65,6 +67,9 @@ type state struct {
 	cache     map[string]CacheManager
 	mainCache CacheManager
 	solver    *Solver
+
+	// stopCh is closed when all the jobs are stopped
+	stopCh chan struct{}
 }
 
 func (s *state) SessionIterator() session.Iterator {
@@ -239,6 +244,9 @@ type Job struct {
 
 	progressCloser func()
 	SessionID      string
+
+	cacheStats cacheStats
+	stop       jobStop
 }
 
 type SolverOpt struct {
@@ -333,6 +341,9 @@ func (jl *Solver) loadUnlocked(v, parent Vertex, j *Job, cache map[Vertex]Vertex
 	}
 
 	dgst := v.Digest()
+	if ns := v.Options().Namespace; ns != "" {
+		dgst = namespaceDigest(dgst, ns)
+	}
 
 	dgstWithoutCache := digest.FromBytes([]byte(fmt.Sprintf("%s-ignorecache", dgst)))
 
@@ -389,6 +400,9 @@ func (jl *Solver) loadUnlocked(v, parent Vertex, j *Job, cache map[Vertex]Vertex
 	if j != nil {
 		if _, ok := st.jobs[j]; !ok {
 			st.jobs[j] = struct{}{}
+			if !j.Stopped() {
+				st.resumeStopping()
+			}
 		}
 	}
 	st.mu.Unlock()
@@ -590,7 +604,7 @@ type cacheMapResp struct {
 type activeOp interface {
 	CacheMap(context.Context, int) (*cacheMapResp, error)
 	LoadCache(ctx context.Context, rec *CacheRecord) (Result, error)
-	Exec(ctx context.Context, inputs []Result) (outputs []Result, exporters []ExportableCacheKey, err error)
+	Exec(ctx context.Context, inputs []Result, miss *client.CacheMiss) (outputs []Result, exporters []ExportableCacheKey, err error)
 	IgnoreCache() bool
 	Cache() CacheManager
 	CalcSlowCache(context.Context, Index, PreprocessFunc, ResultBasedCacheFunc, Result) (digest.Digest, error)
@@ -652,6 +666,10 @@ func (s *sharedOp) LoadCache(ctx context.Context, rec *CacheRecord) (Result, err
 	res, err := s.Cache().Load(withAncestorCacheOpts(ctx, s.st), rec)
 	tracing.FinishWithError(span, err)
 	notifyCompleted(err, true)
+	if err == nil {
+		metrics.Vertexes.WithLabelValues("true").Inc()
+		s.st.recordCacheLoad(ctx, rec, res)
+	}
 	return res, err
 }
 
@@ -767,6 +785,11 @@ func (s *sharedOp) CacheMap(ctx context.Context, index int) (resp *cacheMapResp,
 			}()
 		}
 		res, done, err := op.CacheMap(ctx, s.st, len(s.cacheRes))
+		if ns := s.st.vtx.Options().Namespace; ns != "" && err == nil {
+			cm := *res
+			cm.Digest = namespaceDigest(cm.Digest, ns)
+			res = &cm
+		}
 		complete := true
 		if err != nil {
 			select {
@@ -799,7 +822,7 @@ func (s *sharedOp) CacheMap(ctx context.Context, index int) (resp *cacheMapResp,
 	return &cacheMapResp{CacheMap: res.([]*CacheMap)[index], complete: s.cacheDone}, nil
 }
 
-func (s *sharedOp) Exec(ctx context.Context, inputs []Result) (outputs []Result, exporters []ExportableCacheKey, err error) {
+func (s *sharedOp) Exec(ctx context.Context, inputs []Result, miss *client.CacheMiss) (outputs []Result, exporters []ExportableCacheKey, err error) {
 	defer func() {
 		err = errdefs.WithOp(err, s.st.vtx.Sys())
 		err = errdefs.WrapVertex(err, s.st.origDigest)
@@ -808,11 +831,23 @@ func (s *sharedOp) Exec(ctx context.Context, inputs []Result) (outputs []Result,
 	if err != nil {
 		return nil, nil, err
 	}
+	// a stopped build doesn't start new operations, checked before the
+	// flight control so that the error isn't kept as the result of the op
+	stopping := s.st.stopping()
+	select {
+	case <-stopping:
+		return nil, nil, errors.Wrap(context.Canceled, "build is stopping")
+	default:
+	}
+	ctx = context.WithValue(ctx, stoppingKey{}, stopping)
 	flightControlKey := "exec"
 	res, err := s.g.Do(ctx, flightControlKey, func(ctx context.Context) (ret interface{}, retErr error) {
 		if s.execRes != nil || s.execErr != nil {
 			return s.execRes, s.execErr
 		}
+		if err := s.preprocessInputs(ctx, inputs); err != nil {
+			return nil, err
+		}
 		release, err := op.Acquire(ctx)
 		if err != nil {
 			return nil, errors.Wrap(err, "acquire op resources")
@@ -827,13 +862,18 @@ func (s *sharedOp) Exec(ctx context.Context, inputs []Result) (outputs []Result,
 
 		// no cache hit. start evaluating the node
 		span, ctx := tracing.StartSpan(ctx, s.st.vtx.Name())
+		s.st.clientVertex.CacheMiss = miss
 		notifyCompleted := notifyStarted(ctx, &s.st.clientVertex, false)
 		defer func() {
 			tracing.FinishWithError(span, retErr)
 			notifyCompleted(retErr, false)
+			if retErr == nil {
+				metrics.Vertexes.WithLabelValues("false").Inc()
+				s.st.recordCache(false, false, 0)
+			}
 		}()
 
-		res, err := op.Exec(ctx, s.st, inputs)
+		res, err := s.execRetry(ctx, op, inputs)
 		complete := true
 		if err != nil {
 			select {
@@ -868,6 +908,108 @@ func (s *sharedOp) Exec(ctx context.Context, inputs []Result) (outputs []Result,
 	return unwrapShared(r.execRes), r.execExporters, nil
 }
 
+// preprocessInputs runs the preprocess functions of the inputs that have no
+// result based cache key. They aren't run while the cache keys are computed,
+// so that the results loaded from the cache, e.g. the lazy layers of imported
+// cache records, are only unpacked when the op runs.
+func (s *sharedOp) preprocessInputs(ctx context.Context, inputs []Result) error {
+	if len(s.cacheRes) == 0 {
+		return nil
+	}
+	cm := s.cacheRes[len(s.cacheRes)-1]
+	eg, ctx := errgroup.WithContext(ctx)
+	for i, inp := range inputs {
+		if i >= len(cm.Deps) || cm.Deps[i].PreprocessFunc == nil || cm.Deps[i].ComputeDigestFunc != nil {
+			continue
+		}
+		st := s.st.solver.getState(s.st.vtx.Inputs()[i])
+		if st == nil {
+			return errors.Errorf("failed to get state for index %d on %v", i, s.st.vtx.Name())
+		}
+		pfn, inp := cm.Deps[i].PreprocessFunc, inp
+		eg.Go(func() error {
+			ctx := progress.WithProgress(ctx, st.mpw)
+			if st.mspan.Span != nil {
+				ctx = trace.ContextWithSpan(ctx, st.mspan)
+			}
+			return pfn(ctx, inp, st)
+		})
+	}
+	return eg.Wait()
+}
+
+const (
+	// MaxRetries is the maximum number of retries of a failed execution of
+	// a vertex.
+	MaxRetries = 10
+	// MaxRetryDelay is the maximum delay before retrying a failed execution
+	// of a vertex.
+	MaxRetryDelay = 5 * time.Minute
+)
+
+// execRetry runs the operation with the timeout and the retry policy of the
+// vertex.
+func (s *sharedOp) execRetry(ctx context.Context, op Op, inputs []Result) ([]Result, error) {
+	opts := s.st.vtx.Options()
+	retries := opts.Retries
+	if retries > MaxRetries {
+		retries = MaxRetries
+	}
+	delay := opts.RetryDelay
+	if delay > MaxRetryDelay {
+		delay = MaxRetryDelay
+	}
+	for attempt := 1; ; attempt++ {
+		res, err := execTimeout(ctx, op, s.st, inputs, opts.Timeout)
+		if err == nil || attempt > retries || ctx.Err() != nil {
+			return res, err
+		}
+		releaseError(err)
+
+		pw, _, _ := progress.NewFromContext(ctx)
+		pw.Write(identity.NewID(), client.VertexLog{
+			Stream: 2,
+			Data:   []byte(fmt.Sprintf("retrying (%d/%d) after error: %v\n", attempt, retries, err)),
+		})
+		pw.Close()
+
+		if delay > 0 {
+			t := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return nil, ctx.Err()
+			case <-t.C:
+			}
+			delay = nextRetryDelay(delay)
+		}
+	}
+}
+
+// nextRetryDelay returns the delay before the next retry, twice the previous
+// delay up to MaxRetryDelay.
+func nextRetryDelay(d time.Duration) time.Duration {
+	if d > MaxRetryDelay/2 {
+		return MaxRetryDelay
+	}
+	return d * 2
+}
+
+// execTimeout runs the operation and cancels it after the timeout. Zero is
+// unlimited.
+func execTimeout(ctx context.Context, op Op, g session.Group, inputs []Result, timeout time.Duration) ([]Result, error) {
+	if timeout <= 0 {
+		return op.Exec(ctx, g, inputs)
+	}
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	res, err := op.Exec(tctx, g, inputs)
+	if err != nil && errors.Is(tctx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		err = errdefs.WithTimeout(err, timeout)
+	}
+	return res, err
+}
+
 func (s *sharedOp) getOp() (Op, error) {
 	s.opOnce.Do(func() {
 		s.subBuilder = s.st.builder()
@@ -887,6 +1029,12 @@ func (s *sharedOp) release() {
 	}
 }
 
+// namespaceDigest returns the digest of the vertex or cache key in the
+// namespace.
+func namespaceDigest(dgst digest.Digest, ns string) digest.Digest {
+	return digest.FromBytes([]byte(fmt.Sprintf("%s-ns-%s", dgst, ns)))
+}
+
 func initClientVertex(v Vertex) client.Vertex {
 	inputDigests := make([]digest.Digest, 0, len(v.Inputs()))
 	for _, inp := range v.Inputs() {
@@ -936,6 +1084,9 @@ func notifyStarted(ctx context.Context, v *client.Vertex, cached bool) func(err
 	v.Started = &start
 	v.Completed = nil
 	v.Cached = cached
+	if cached {
+		v.CacheMiss = nil
+	}
 	id := identity.NewID()
 	pw.Write(id, *v)
 	return func(err error, cached bool) {
@@ -12,6 +12,7 @@ import (
 	"github.com/moby/buildkit/cache/remotecache"
 	"github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/executor"
 	"github.com/moby/buildkit/exporter/containerimage/exptypes"
 	"github.com/moby/buildkit/frontend"
 	gw "github.com/moby/buildkit/frontend/gateway/client"
@@ -21,8 +22,11 @@ import (
 	"github.com/moby/buildkit/solver/errdefs"
 	llberrdefs "github.com/moby/buildkit/solver/llbsolver/errdefs"
 	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/audit"
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/buildinfo"
+	binfotypes "github.com/moby/buildkit/util/buildinfo/types"
+	"github.com/moby/buildkit/util/fairsched"
 	"github.com/moby/buildkit/util/flightcontrol"
 	"github.com/moby/buildkit/util/progress"
 	"github.com/moby/buildkit/worker"
@@ -38,7 +42,14 @@ type llbBridge struct {
 	resolveCacheImporterFuncs map[string]remotecache.ResolveCacheImporterFunc
 	cms                       map[string]solver.CacheManager
 	cmsMu                     sync.Mutex
+	cacheImports              []binfotypes.CacheRef
 	sm                        *session.Manager
+	networkSched              *fairsched.Scheduler
+	speculation               *speculation
+	allowedSockets            []string
+	buildInfoAttrs            *buildinfo.AttrsPolicy
+	// dryRun loads the definitions without side effects on the workers
+	dryRun bool
 }
 
 func (b *llbBridge) Warn(ctx context.Context, dgst digest.Digest, msg string, opts frontend.WarnOpts) error {
@@ -65,19 +76,33 @@ func (b *llbBridge) Warn(ctx context.Context, dgst digest.Digest, msg string, op
 }
 
 func (b *llbBridge) loadResult(ctx context.Context, def *pb.Definition, cacheImports []gw.CacheOptionsEntry) (solver.CachedResult, solver.BuildSources, error) {
-	w, err := b.resolveWorker()
+	edge, err := b.loadEdge(ctx, def, cacheImports)
 	if err != nil {
 		return nil, nil, err
 	}
-	ent, err := loadEntitlements(b.builder)
+	b.speculate(ctx, edge)
+	res, bi, err := b.builder.Build(ctx, edge)
 	if err != nil {
 		return nil, nil, err
 	}
+	return res, bi, nil
+}
+
+// loadEdge loads the definition with the cache managers of the cache imports.
+func (b *llbBridge) loadEdge(ctx context.Context, def *pb.Definition, cacheImports []gw.CacheOptionsEntry) (solver.Edge, error) {
+	w, err := b.resolveWorker()
+	if err != nil {
+		return solver.Edge{}, err
+	}
+	ent, err := loadEntitlements(b.builder)
+	if err != nil {
+		return solver.Edge{}, err
+	}
 	var cms []solver.CacheManager
 	for _, im := range cacheImports {
 		cmID, err := cmKey(im)
 		if err != nil {
-			return nil, nil, err
+			return solver.Edge{}, err
 		}
 		b.cmsMu.Lock()
 		var cm solver.CacheManager
@@ -90,6 +115,11 @@ func (b *llbBridge) loadResult(ctx context.Context, def *pb.Definition, cacheImp
 						if !ok {
 							return errors.Errorf("unknown cache importer: %s", im.Type)
 						}
+						release, err := acquireSlot(ctx, b.networkSched, b.builder)
+						if err != nil {
+							return err
+						}
+						defer release()
 						ci, desc, err := resolveCI(ctx, g, im.Attrs)
 						if err != nil {
 							return err
@@ -104,36 +134,55 @@ func (b *llbBridge) loadResult(ctx context.Context, def *pb.Definition, cacheImp
 				})
 			}(cmID, im)
 			b.cms[cmID] = cm
+			b.cacheImports = append(b.cacheImports, buildinfo.CacheRef(im.Type, im.Attrs))
 		} else {
 			cm = prevCm
 		}
 		cms = append(cms, cm)
 		b.cmsMu.Unlock()
 	}
+	def, err = applySourcePolicies(b.builder, def)
+	if err != nil {
+		return solver.Edge{}, err
+	}
+
 	dpc := &detectPrunedCacheID{}
 
-	edge, err := Load(def, dpc.Load, ValidateEntitlements(ent), WithCacheSources(cms), NormalizeRuntimePlatforms(), WithValidateCaps())
+	res, err := loadResources(b.builder)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "failed to load LLB")
+		return solver.Edge{}, err
 	}
-
-	if len(dpc.ids) > 0 {
-		ids := make([]string, 0, len(dpc.ids))
-		for id := range dpc.ids {
-			ids = append(ids, id)
-		}
-		if err := b.eachWorker(func(w worker.Worker) error {
-			return w.PruneCacheMounts(ctx, ids)
-		}); err != nil {
-			return nil, nil, err
-		}
+	var tenantOpt LoadOpt = WithTenant("", false)
+	var usage *audit.Usage
+	if res != nil {
+		tenantOpt = WithTenant(res.Tenant, res.ShareTenantImages)
+		usage = res.Usage
 	}
 
-	res, bi, err := b.builder.Build(ctx, edge)
+	edge, err := Load(def, dpc.Load, ValidateEntitlements(ent), ValidateSockets(b.allowedSockets), WithCacheSources(cms), tenantOpt, RecordUsage(usage), NormalizeRuntimePlatforms(), WithValidateCaps())
 	if err != nil {
-		return nil, nil, err
+		return solver.Edge{}, errors.Wrap(err, "failed to load LLB")
 	}
-	return res, bi, nil
+
+	if err := b.pruneCacheMounts(ctx, dpc.ids); err != nil {
+		return solver.Edge{}, err
+	}
+	return edge, nil
+}
+
+// pruneCacheMounts prunes the cache mounts of the IDs on every worker. The
+// cache mounts are kept in dry runs.
+func (b *llbBridge) pruneCacheMounts(ctx context.Context, cacheIDs map[string]struct{}) error {
+	if len(cacheIDs) == 0 || b.dryRun {
+		return nil
+	}
+	ids := make([]string, 0, len(cacheIDs))
+	for id := range cacheIDs {
+		ids = append(ids, id)
+	}
+	return b.eachWorker(func(w worker.Worker) error {
+		return w.PruneCacheMounts(ctx, ids)
+	})
 }
 
 func (b *llbBridge) Solve(ctx context.Context, req frontend.SolveRequest, sid string) (res *frontend.Result, err error) {
@@ -151,10 +200,16 @@ func (b *llbBridge) Solve(ctx context.Context, req frontend.SolveRequest, sid st
 		if !ok {
 			return nil, errors.Errorf("invalid frontend: %s", req.Frontend)
 		}
-		res, err = f.Solve(ctx, b, req.FrontendOpt, req.FrontendInputs, sid, b.sm)
+		fctx, cancel := withFrontendBudget(ctx, req.Budget)
+		res, err = f.Solve(fctx, b, req.FrontendOpt, req.FrontendInputs, sid, b.sm)
 		if err != nil {
+			if req.Budget != nil && errors.Is(fctx.Err(), context.DeadlineExceeded) {
+				err = errdefs.WithTimeout(err, req.Budget.Timeout)
+			}
+			cancel()
 			return nil, err
 		}
+		cancel()
 	} else {
 		return &frontend.Result{}, nil
 	}
@@ -165,14 +220,14 @@ func (b *llbBridge) Solve(ctx context.Context, req frontend.SolveRequest, sid st
 
 	if len(res.Refs) > 0 {
 		for p := range res.Refs {
-			dtbi, err := buildinfo.GetMetadata(res.Metadata, fmt.Sprintf("%s/%s", exptypes.ExporterBuildInfo, p), req.Frontend, req.FrontendOpt)
+			dtbi, err := buildinfo.GetMetadata(res.Metadata, fmt.Sprintf("%s/%s", exptypes.ExporterBuildInfo, p), req.Frontend, req.FrontendOpt, b.buildInfoAttrs)
 			if err != nil {
 				return nil, err
 			}
 			res.Metadata[fmt.Sprintf("%s/%s", exptypes.ExporterBuildInfo, p)] = dtbi
 		}
 	} else {
-		dtbi, err := buildinfo.GetMetadata(res.Metadata, exptypes.ExporterBuildInfo, req.Frontend, req.FrontendOpt)
+		dtbi, err := buildinfo.GetMetadata(res.Metadata, exptypes.ExporterBuildInfo, req.Frontend, req.FrontendOpt, b.buildInfoAttrs)
 		if err != nil {
 			return nil, err
 		}
@@ -182,6 +237,39 @@ func (b *llbBridge) Solve(ctx context.Context, req frontend.SolveRequest, sid st
 	return
 }
 
+// withFrontendBudget returns the context running a frontend within the
+// budget. The resource limits of the budget are capped by the limits of ctx,
+// so that a frontend can't give more resources to the frontends it calls than
+// it has.
+func withFrontendBudget(ctx context.Context, budget *frontend.FrontendBudget) (context.Context, context.CancelFunc) {
+	if budget == nil {
+		return ctx, func() {}
+	}
+	limits := &executor.ResourceLimits{
+		CPU:    budget.CPU,
+		Memory: budget.Memory,
+		Pids:   budget.Pids,
+	}
+	if parent := executor.ResourceLimitsFromContext(ctx); parent != nil {
+		if parent.CPU > 0 && (limits.CPU == 0 || limits.CPU > parent.CPU) {
+			limits.CPU = parent.CPU
+		}
+		if parent.Memory > 0 && (limits.Memory == 0 || limits.Memory > parent.Memory) {
+			limits.Memory = parent.Memory
+		}
+		if parent.Pids > 0 && (limits.Pids == 0 || limits.Pids > parent.Pids) {
+			limits.Pids = parent.Pids
+		}
+	}
+	if *limits != (executor.ResourceLimits{}) {
+		ctx = executor.WithResourceLimits(ctx, limits)
+	}
+	if budget.Timeout > 0 {
+		return context.WithTimeout(ctx, budget.Timeout)
+	}
+	return ctx, func() {}
+}
+
 type resultProxy struct {
 	cb         func(context.Context) (solver.CachedResult, solver.BuildSources, error)
 	def        *pb.Definition
@@ -315,6 +403,10 @@ func (b *llbBridge) ResolveImageConfig(ctx context.Context, ref string, opt llb.
 	if err != nil {
 		return "", nil, err
 	}
+	ref, err = convertImageRef(b.builder, ref)
+	if err != nil {
+		return "", nil, err
+	}
 	if opt.LogName == "" {
 		opt.LogName = fmt.Sprintf("resolve image config for %s", ref)
 	}
@@ -388,6 +480,13 @@ func newLazyCacheManager(id string, fn func() (solver.CacheManager, error)) solv
 	return lcm
 }
 
+// importedCaches returns the caches imported by the builds of the bridge.
+func (b *llbBridge) importedCaches() []binfotypes.CacheRef {
+	b.cmsMu.Lock()
+	defer b.cmsMu.Unlock()
+	return append([]binfotypes.CacheRef(nil), b.cacheImports...)
+}
+
 func cmKey(im gw.CacheOptionsEntry) (string, error) {
 	if im.Type == "registry" && im.Attrs["ref"] != "" {
 		return im.Attrs["ref"], nil
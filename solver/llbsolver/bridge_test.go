@@ -0,0 +1,54 @@
+package llbsolver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/executor"
+	"github.com/moby/buildkit/frontend"
+	"github.com/moby/buildkit/worker"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithFrontendBudget(t *testing.T) {
+	ctx := context.TODO()
+
+	bctx, cancel := withFrontendBudget(ctx, nil)
+	cancel()
+	require.Equal(t, ctx, bctx)
+
+	bctx, cancel = withFrontendBudget(ctx, &frontend.FrontendBudget{Timeout: time.Minute, Memory: 1 << 30})
+	defer cancel()
+	_, ok := bctx.Deadline()
+	require.True(t, ok)
+	require.Equal(t, &executor.ResourceLimits{Memory: 1 << 30}, executor.ResourceLimitsFromContext(bctx))
+
+	// the limits of the calling frontend cap the budget
+	pctx := executor.WithResourceLimits(ctx, &executor.ResourceLimits{CPU: 2, Memory: 1 << 29})
+	bctx, cancel = withFrontendBudget(pctx, &frontend.FrontendBudget{CPU: 1, Memory: 1 << 30, Pids: 100})
+	defer cancel()
+	_, ok = bctx.Deadline()
+	require.False(t, ok)
+	require.Equal(t, &executor.ResourceLimits{CPU: 1, Memory: 1 << 29, Pids: 100}, executor.ResourceLimitsFromContext(bctx))
+}
+
+func TestPruneCacheMountsDryRun(t *testing.T) {
+	var pruned int
+	b := &llbBridge{
+		eachWorker: func(func(worker.Worker) error) error {
+			pruned++
+			return nil
+		},
+	}
+	ids := map[string]struct{}{"cache": {}}
+
+	require.NoError(t, b.pruneCacheMounts(context.TODO(), nil))
+	require.Equal(t, 0, pruned)
+	require.NoError(t, b.pruneCacheMounts(context.TODO(), ids))
+	require.Equal(t, 1, pruned)
+
+	b.dryRun = true
+	require.NoError(t, b.pruneCacheMounts(context.TODO(), ids))
+	require.Equal(t, 1, pruned)
+}
@@ -10,7 +10,9 @@ import (
 	"time"
 
 	"github.com/containerd/continuity/fs"
+	"github.com/containerd/continuity/pathdriver"
 	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/docker/pkg/system"
 	"github.com/moby/buildkit/snapshot"
 	"github.com/moby/buildkit/solver/llbsolver/ops/fileoptypes"
 	"github.com/moby/buildkit/solver/pb"
@@ -26,6 +28,10 @@ func timestampToTime(ts int64) *time.Time {
 	return &tm
 }
 
+// mapUserToChowner returns the chowner of the files created by the file
+// actions. The files are created with their owner rather than chowned after
+// the copy. Idmapped mounts can't replace it as the owners of the files are
+// recorded in the layers.
 func mapUserToChowner(user *copy.User, idmap *idtools.IdentityMapping) (copy.Chowner, error) {
 	if user == nil {
 		return func(old *copy.User) (*copy.User, error) {
@@ -66,8 +72,22 @@ func mapUserToChowner(user *copy.User, idmap *idtools.IdentityMapping) (copy.Cho
 	}, nil
 }
 
+// normalizePath removes the drive letter of the paths of Windows containers,
+// which must be on the system drive. It is a no-op on other platforms.
+func normalizePath(p string) (string, error) {
+	np, err := system.CheckSystemDriveAndRemoveDriveLetter(p, pathdriver.LocalPathDriver)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid path %s", p)
+	}
+	return np, nil
+}
+
 func mkdir(ctx context.Context, d string, action pb.FileActionMkDir, user *copy.User, idmap *idtools.IdentityMapping) error {
-	p, err := fs.RootPath(d, filepath.Join("/", action.Path))
+	actionPath, err := normalizePath(action.Path)
+	if err != nil {
+		return err
+	}
+	p, err := fs.RootPath(d, filepath.Join("/", actionPath))
 	if err != nil {
 		return err
 	}
@@ -100,7 +120,11 @@ func mkdir(ctx context.Context, d string, action pb.FileActionMkDir, user *copy.
 }
 
 func mkfile(ctx context.Context, d string, action pb.FileActionMkFile, user *copy.User, idmap *idtools.IdentityMapping) error {
-	p, err := fs.RootPath(d, filepath.Join("/", action.Path))
+	actionPath, err := normalizePath(action.Path)
+	if err != nil {
+		return err
+	}
+	p, err := fs.RootPath(d, filepath.Join("/", actionPath))
 	if err != nil {
 		return err
 	}
@@ -126,8 +150,12 @@ func mkfile(ctx context.Context, d string, action pb.FileActionMkFile, user *cop
 }
 
 func rm(ctx context.Context, d string, action pb.FileActionRm) error {
+	actionPath, err := normalizePath(action.Path)
+	if err != nil {
+		return err
+	}
 	if action.AllowWildcard {
-		src := cleanPath(action.Path)
+		src := cleanPath(actionPath)
 		m, err := copy.ResolveWildcards(d, src, false)
 		if err != nil {
 			return err
@@ -142,7 +170,7 @@ func rm(ctx context.Context, d string, action pb.FileActionRm) error {
 		return nil
 	}
 
-	return rmPath(d, action.Path, action.AllowNotFound)
+	return rmPath(d, actionPath, action.AllowNotFound)
 }
 
 func rmPath(root, src string, allowNotFound bool) error {
@@ -167,12 +195,153 @@ func rmPath(root, src string, allowNotFound bool) error {
 	return nil
 }
 
+func symlink(ctx context.Context, d string, action pb.FileActionSymlink, user *copy.User, idmap *idtools.IdentityMapping) error {
+	newpath, err := normalizePath(action.Newpath)
+	if err != nil {
+		return err
+	}
+	p, err := rootPathNoFollow(d, newpath)
+	if err != nil {
+		return err
+	}
+
+	ch, err := mapUserToChowner(user, idmap)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Symlink(action.Oldpath, p); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := copy.Chown(p, nil, ch); err != nil {
+		return err
+	}
+
+	return copy.Utimes(p, timestampToTime(action.Timestamp))
+}
+
+func link(ctx context.Context, d string, action pb.FileActionLink) error {
+	oldpath, err := normalizePath(action.Oldpath)
+	if err != nil {
+		return err
+	}
+	newpath, err := normalizePath(action.Newpath)
+	if err != nil {
+		return err
+	}
+	oldp, err := rootPathNoFollow(d, oldpath)
+	if err != nil {
+		return err
+	}
+	newp, err := rootPathNoFollow(d, newpath)
+	if err != nil {
+		return err
+	}
+	fi, err := os.Lstat(oldp)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if fi.IsDir() {
+		return errors.Errorf("can't hardlink directory %s", action.Oldpath)
+	}
+	return errors.WithStack(os.Link(oldp, newp))
+}
+
+func setattr(ctx context.Context, d string, action pb.FileActionSetAttr, user *copy.User, idmap *idtools.IdentityMapping) error {
+	actionPath, err := normalizePath(action.Path)
+	if err != nil {
+		return err
+	}
+
+	var ch copy.Chowner
+	if user != nil {
+		if ch, err = mapUserToChowner(user, idmap); err != nil {
+			return err
+		}
+	}
+
+	paths := []string{actionPath}
+	if action.AllowWildcard {
+		if paths, err = copy.ResolveWildcards(d, cleanPath(actionPath), false); err != nil {
+			return err
+		}
+		if len(paths) == 0 && !action.AllowNotFound {
+			return errors.Errorf("%s not found", action.Path)
+		}
+	}
+
+	for _, src := range paths {
+		p, err := rootPathNoFollow(d, src)
+		if err != nil {
+			return err
+		}
+		fi, err := os.Lstat(p)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) && action.AllowNotFound {
+				continue
+			}
+			return errors.WithStack(err)
+		}
+		if !action.Recursive || !fi.IsDir() {
+			if err := setattrPath(p, fi, action, ch); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := filepath.Walk(p, func(p string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			return setattrPath(p, fi, action, ch)
+		}); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func setattrPath(p string, fi os.FileInfo, action pb.FileActionSetAttr, ch copy.Chowner) error {
+	if err := copy.Chown(p, nil, ch); err != nil {
+		return err
+	}
+	// the permissions of symlinks can't be changed
+	if action.Mode != -1 && fi.Mode()&os.ModeSymlink == 0 {
+		if err := os.Chmod(p, os.FileMode(action.Mode)&0777); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return copy.Utimes(p, timestampToTime(action.Timestamp))
+}
+
+// rootPathNoFollow returns the path of p in root, following the symlinks of
+// its parent directories but not p itself.
+func rootPathNoFollow(root, p string) (string, error) {
+	dir, base := filepath.Split(filepath.Clean(filepath.Join("/", p)))
+	if base == "" {
+		return "", errors.Errorf("invalid path %s", p)
+	}
+	dir, err := fs.RootPath(root, dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, base), nil
+}
+
 func docopy(ctx context.Context, src, dest string, action pb.FileActionCopy, u *copy.User, idmap *idtools.IdentityMapping) error {
-	srcPath := cleanPath(action.Src)
-	destPath := cleanPath(action.Dest)
+	actionSrc, err := normalizePath(action.Src)
+	if err != nil {
+		return err
+	}
+	actionDest, err := normalizePath(action.Dest)
+	if err != nil {
+		return err
+	}
+	srcPath := cleanPath(actionSrc)
+	destPath := cleanPath(actionDest)
 
 	if !action.CreateDestPath {
-		p, err := fs.RootPath(dest, filepath.Join("/", action.Dest))
+		p, err := fs.RootPath(dest, filepath.Join("/", actionDest))
 		if err != nil {
 			return err
 		}
@@ -206,6 +375,19 @@ func docopy(ctx context.Context, src, dest string, action pb.FileActionCopy, u *
 		copy.WithXAttrErrorHandler(xattrErrorHandler),
 	}
 
+	copyFn := func(s string) error {
+		return copy.Copy(ctx, src, s, dest, destPath, opt...)
+	}
+	if hasCopyPolicies(action) {
+		c, err := newPolicyCopier(src, dest, action, opt)
+		if err != nil {
+			return err
+		}
+		copyFn = func(s string) error {
+			return c.Copy(ctx, s, destPath)
+		}
+	}
+
 	if !action.AllowWildcard {
 		if action.AttemptUnpackDockerCompatibility {
 			if ok, err := unpack(ctx, src, srcPath, dest, destPath, ch, timestampToTime(action.Timestamp)); err != nil {
@@ -214,7 +396,7 @@ func docopy(ctx context.Context, src, dest string, action pb.FileActionCopy, u *
 				return nil
 			}
 		}
-		return copy.Copy(ctx, src, srcPath, dest, destPath, opt...)
+		return copyFn(srcPath)
 	}
 
 	m, err := copy.ResolveWildcards(src, srcPath, action.FollowSymlink)
@@ -237,7 +419,7 @@ func docopy(ctx context.Context, src, dest string, action pb.FileActionCopy, u *
 				continue
 			}
 		}
-		if err := copy.Copy(ctx, src, s, dest, destPath, opt...); err != nil {
+		if err := copyFn(s); err != nil {
 			return err
 		}
 	}
@@ -319,6 +501,64 @@ func (fb *Backend) Rm(ctx context.Context, m fileoptypes.Mount, action pb.FileAc
 	return rm(ctx, dir, action)
 }
 
+func (fb *Backend) Symlink(ctx context.Context, m, user, group fileoptypes.Mount, action pb.FileActionSymlink) error {
+	mnt, ok := m.(*Mount)
+	if !ok {
+		return errors.Errorf("invalid mount type %T", m)
+	}
+
+	lm := snapshot.LocalMounter(mnt.m)
+	dir, err := lm.Mount()
+	if err != nil {
+		return err
+	}
+	defer lm.Unmount()
+
+	u, err := readUser(action.Owner, user, group)
+	if err != nil {
+		return err
+	}
+
+	return symlink(ctx, dir, action, u, mnt.m.IdentityMapping())
+}
+
+func (fb *Backend) Link(ctx context.Context, m fileoptypes.Mount, action pb.FileActionLink) error {
+	mnt, ok := m.(*Mount)
+	if !ok {
+		return errors.Errorf("invalid mount type %T", m)
+	}
+
+	lm := snapshot.LocalMounter(mnt.m)
+	dir, err := lm.Mount()
+	if err != nil {
+		return err
+	}
+	defer lm.Unmount()
+
+	return link(ctx, dir, action)
+}
+
+func (fb *Backend) SetAttr(ctx context.Context, m, user, group fileoptypes.Mount, action pb.FileActionSetAttr) error {
+	mnt, ok := m.(*Mount)
+	if !ok {
+		return errors.Errorf("invalid mount type %T", m)
+	}
+
+	lm := snapshot.LocalMounter(mnt.m)
+	dir, err := lm.Mount()
+	if err != nil {
+		return err
+	}
+	defer lm.Unmount()
+
+	u, err := readUser(action.Owner, user, group)
+	if err != nil {
+		return err
+	}
+
+	return setattr(ctx, dir, action, u, mnt.m.IdentityMapping())
+}
+
 func (fb *Backend) Copy(ctx context.Context, m1, m2, user, group fileoptypes.Mount, action pb.FileActionCopy) error {
 	mnt1, ok := m1.(*Mount)
 	if !ok {
@@ -0,0 +1,157 @@
+package file
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSymlinkLink(t *testing.T) {
+	d := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(d, "dir"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(d, "dir", "foo"), []byte("foo"), 0644))
+
+	err := symlink(context.TODO(), d, pb.FileActionSymlink{Oldpath: "dir/foo", Newpath: "/bar", Timestamp: -1}, nil, nil)
+	require.NoError(t, err)
+	target, err := os.Readlink(filepath.Join(d, "bar"))
+	require.NoError(t, err)
+	require.Equal(t, "dir/foo", target)
+
+	// the symlink is linked, not its target
+	err = link(context.TODO(), d, pb.FileActionLink{Oldpath: "/bar", Newpath: "/baz"})
+	require.NoError(t, err)
+	fi, err := os.Lstat(filepath.Join(d, "baz"))
+	require.NoError(t, err)
+	require.Equal(t, os.ModeSymlink, fi.Mode()&os.ModeSymlink)
+
+	err = link(context.TODO(), d, pb.FileActionLink{Oldpath: "/dir/foo", Newpath: "/dir/foo2"})
+	require.NoError(t, err)
+	fi1, err := os.Stat(filepath.Join(d, "dir", "foo"))
+	require.NoError(t, err)
+	fi2, err := os.Stat(filepath.Join(d, "dir", "foo2"))
+	require.NoError(t, err)
+	require.True(t, os.SameFile(fi1, fi2))
+
+	err = link(context.TODO(), d, pb.FileActionLink{Oldpath: "/dir", Newpath: "/dir2"})
+	require.Error(t, err)
+}
+
+func TestSetAttr(t *testing.T) {
+	d := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(d, "a", "b"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(d, "a", "b", "foo"), nil, 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(d, "x.txt"), nil, 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(d, "y.txt"), nil, 0644))
+
+	err := setattr(context.TODO(), d, pb.FileActionSetAttr{Path: "/a", Mode: 0700, Timestamp: -1, Recursive: true}, nil, nil)
+	require.NoError(t, err)
+	for _, p := range []string{"a", "a/b", "a/b/foo"} {
+		fi, err := os.Stat(filepath.Join(d, p))
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0700), fi.Mode().Perm(), p)
+	}
+
+	err = setattr(context.TODO(), d, pb.FileActionSetAttr{Path: "/*.txt", Mode: 0600, Timestamp: 1e9, AllowWildcard: true}, nil, nil)
+	require.NoError(t, err)
+	for _, p := range []string{"x.txt", "y.txt"} {
+		fi, err := os.Stat(filepath.Join(d, p))
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0600), fi.Mode().Perm(), p)
+		require.Equal(t, int64(1), fi.ModTime().Unix(), p)
+	}
+
+	err = setattr(context.TODO(), d, pb.FileActionSetAttr{Path: "/missing", Mode: -1, Timestamp: -1}, nil, nil)
+	require.Error(t, err)
+	err = setattr(context.TODO(), d, pb.FileActionSetAttr{Path: "/missing", Mode: -1, Timestamp: -1, AllowNotFound: true}, nil, nil)
+	require.NoError(t, err)
+}
+
+func TestCopyExisting(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "dir", "sub"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "dir", "foo"), []byte("new"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "dir", "bar"), []byte("new"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "dir", "sub", "baz"), []byte("new"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "dir", " [we*ird] "), []byte("new"), 0644))
+
+	newDest := func() string {
+		dest := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dest, "out", "sub"), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dest, "out", "foo"), []byte("old"), 0644))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dest, "out", "sub", "qux"), []byte("old"), 0644))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dest, "out", " [we*ird] "), []byte("old"), 0644))
+		return dest
+	}
+	action := pb.FileActionCopy{Src: "/dir", Dest: "/out", DirCopyContents: true, Mode: -1, Timestamp: -1}
+
+	dest := newDest()
+	action.Existing = pb.CopyExistingPolicy_SKIP
+	require.NoError(t, docopy(context.TODO(), src, dest, action, nil, nil))
+	for p, content := range map[string]string{"foo": "old", "bar": "new", "sub/baz": "new", "sub/qux": "old", " [we*ird] ": "old"} {
+		dt, err := ioutil.ReadFile(filepath.Join(dest, "out", p))
+		require.NoError(t, err)
+		require.Equal(t, content, string(dt), p)
+	}
+
+	dest = newDest()
+	action.Existing = pb.CopyExistingPolicy_FAIL
+	err := docopy(context.TODO(), src, dest, action, nil, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "already exists")
+	_, err = os.Stat(filepath.Join(dest, "out", "bar"))
+	require.True(t, os.IsNotExist(err))
+
+	// the excluded files don't conflict
+	action.ExcludePatterns = []string{"foo", "*ird*"}
+	require.NoError(t, docopy(context.TODO(), src, dest, action, nil, nil))
+	dt, err := ioutil.ReadFile(filepath.Join(dest, "out", "foo"))
+	require.NoError(t, err)
+	require.Equal(t, "old", string(dt))
+}
+
+func TestCopySymlinks(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "dir", "sub"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "other"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "dir", "foo"), []byte("foo"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "other", "bar"), []byte("bar"), 0644))
+	require.NoError(t, os.Symlink("../foo", filepath.Join(src, "dir", "sub", "inside")))
+	require.NoError(t, os.Symlink("../other", filepath.Join(src, "dir", "outside")))
+	action := pb.FileActionCopy{Src: "/dir", Dest: "/out", Mode: -1, Timestamp: -1}
+
+	dest := t.TempDir()
+	action.Symlinks = pb.CopySymlinkPolicy_FOLLOW
+	require.NoError(t, docopy(context.TODO(), src, dest, action, nil, nil))
+	for p, content := range map[string]string{"sub/inside": "foo", "outside/bar": "bar"} {
+		fi, err := os.Lstat(filepath.Join(dest, "out", p))
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0), fi.Mode()&os.ModeSymlink, p)
+		dt, err := ioutil.ReadFile(filepath.Join(dest, "out", p))
+		require.NoError(t, err)
+		require.Equal(t, content, string(dt), p)
+	}
+
+	dest = t.TempDir()
+	action.Symlinks = pb.CopySymlinkPolicy_FORBID_ESCAPE
+	err := docopy(context.TODO(), src, dest, action, nil, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "/dir/outside points outside of the copied files")
+
+	require.NoError(t, os.Remove(filepath.Join(src, "dir", "outside")))
+	require.NoError(t, docopy(context.TODO(), src, dest, action, nil, nil))
+	target, err := os.Readlink(filepath.Join(dest, "out", "sub", "inside"))
+	require.NoError(t, err)
+	require.Equal(t, "../foo", target)
+
+	// symlinks to their parent directories can't be followed
+	require.NoError(t, os.Symlink("..", filepath.Join(src, "dir", "sub", "loop")))
+	action.Symlinks = pb.CopySymlinkPolicy_FOLLOW
+	err = docopy(context.TODO(), src, t.TempDir(), action, nil, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "contains the symlink")
+}
@@ -0,0 +1,317 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/containerd/continuity/fs"
+	"github.com/docker/docker/pkg/fileutils"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/pkg/errors"
+	copy "github.com/tonistiigi/fsutil/copy"
+)
+
+// maxFollowDepth is the maximum number of nested symlinks followed by a
+// copy, like the limit of the kernel on path resolution.
+const maxFollowDepth = 40
+
+// hasCopyPolicies returns whether the copy action handles the existing files
+// or the symlinks differently from copy.Copy.
+func hasCopyPolicies(action pb.FileActionCopy) bool {
+	return action.Existing != pb.CopyExistingPolicy_OVERWRITE || action.Symlinks != pb.CopySymlinkPolicy_PRESERVE
+}
+
+// policyCopier copies the files like copy.Copy, applying the policies of
+// the action on the files already present at the destination and on the
+// symlinks of the copied files. The source is walked before the copy: the
+// files to skip and the symlinks to follow are excluded from copy.Copy, and
+// the symlinks are followed by copies of their targets.
+type policyCopier struct {
+	srcRoot  string
+	destRoot string
+	action   pb.FileActionCopy
+	opt      []copy.Opt
+	include  *fileutils.PatternMatcher
+	exclude  *fileutils.PatternMatcher
+}
+
+func newPolicyCopier(srcRoot, destRoot string, action pb.FileActionCopy, opt []copy.Opt) (*policyCopier, error) {
+	c := &policyCopier{
+		srcRoot:  srcRoot,
+		destRoot: destRoot,
+		action:   action,
+		opt:      opt,
+	}
+	var err error
+	if len(action.IncludePatterns) > 0 {
+		if c.include, err = fileutils.NewPatternMatcher(action.IncludePatterns); err != nil {
+			return nil, errors.Wrap(err, "invalid includepatterns")
+		}
+	}
+	if len(action.ExcludePatterns) > 0 {
+		if c.exclude, err = fileutils.NewPatternMatcher(action.ExcludePatterns); err != nil {
+			return nil, errors.Wrap(err, "invalid excludepatterns")
+		}
+	}
+	return c, nil
+}
+
+// Copy copies src of the source root to dest of the destination root.
+func (c *policyCopier) Copy(ctx context.Context, src, dest string) error {
+	srcFollowed := c.srcRoot
+	var err error
+	if p := filepath.Join("/", src); p != "/" {
+		if c.action.FollowSymlink {
+			srcFollowed, err = fs.RootPath(c.srcRoot, p)
+		} else {
+			srcFollowed, err = rootPathNoFollow(c.srcRoot, p)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	fiSrc, err := os.Lstat(srcFollowed)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	target, err := fs.RootPath(c.destRoot, filepath.Clean(dest))
+	if err != nil {
+		return err
+	}
+	fiDest, err := os.Stat(target)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to stat destination path")
+	}
+	// the files are copied to the same target as copy.Copy
+	if fiDest != nil && (fiSrc.IsDir() && !c.action.DirCopyContents || !fiSrc.IsDir() && fiDest.IsDir()) {
+		target = filepath.Join(target, filepath.Base(src))
+	}
+
+	skip, follow, err := c.plan(srcFollowed, target, true)
+	if err != nil {
+		return err
+	}
+	if len(skip) > 0 && skip[0] == "." {
+		return nil
+	}
+	if len(follow) == 0 || follow[0] != "." {
+		excludes := append(append([]string{}, c.action.ExcludePatterns...), exactPatterns(skip, follow)...)
+		opt := append(append([]copy.Opt{}, c.opt...), func(ci *copy.CopyInfo) {
+			ci.ExcludePatterns = excludes
+		})
+		if err := copy.Copy(ctx, c.srcRoot, src, c.destRoot, dest, opt...); err != nil {
+			return err
+		}
+	}
+	return c.follow(ctx, srcFollowed, target, follow, []string{srcFollowed}, 1)
+}
+
+// plan walks the files of src copied to target. It returns the paths,
+// relative to src, of the files to skip and of the symlinks to follow, and
+// fails if a file can't be copied with the policies of the action. "." is
+// src itself.
+func (c *policyCopier) plan(src, target string, patterns bool) (skip, follow []string, err error) {
+	boundary := src
+	if fi, err := os.Lstat(src); err == nil && !fi.IsDir() {
+		boundary = filepath.Dir(src)
+	}
+	err = filepath.Walk(src, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if rel != "." && patterns {
+			// the children of the directories that aren't included can
+			// still be included
+			if ok, err := c.included(rel); err != nil || !ok {
+				return err
+			}
+		}
+
+		if fi.Mode()&os.ModeSymlink != 0 {
+			switch c.action.Symlinks {
+			case pb.CopySymlinkPolicy_FOLLOW:
+				// the existing files are handled when the target of the
+				// symlink is copied
+				follow = append(follow, rel)
+				return nil
+			case pb.CopySymlinkPolicy_FORBID_ESCAPE:
+				if err := c.checkEscape(p, boundary); err != nil {
+					return err
+				}
+			}
+		}
+
+		dest := filepath.Join(target, rel)
+		fiDest, err := os.Lstat(dest)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to stat %s", c.destPath(dest))
+		}
+		// directories are merged, the other files are replaced
+		if fiDest != nil && !(fi.IsDir() && fiDest.IsDir()) {
+			switch c.action.Existing {
+			case pb.CopyExistingPolicy_SKIP:
+				skip = append(skip, rel)
+				if fi.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			case pb.CopyExistingPolicy_FAIL:
+				return errors.Errorf("failed to copy %s: %s already exists", c.srcPath(p), c.destPath(dest))
+			}
+			if fiDest.IsDir() {
+				return errors.Errorf("cannot replace directory %s with file", c.destPath(dest))
+			}
+		}
+		return nil
+	})
+	return skip, follow, err
+}
+
+// follow copies the targets of the symlinks of src, at their paths relative
+// to target. chain are the directories being copied by the copies following
+// the symlinks to src.
+func (c *policyCopier) follow(ctx context.Context, src, target string, links, chain []string, depth int) error {
+	for _, rel := range links {
+		link := filepath.Join(src, rel)
+		if depth > maxFollowDepth {
+			return errors.Errorf("failed to follow symlink %s: too many levels of symbolic links", c.srcPath(link))
+		}
+		resolved, err := fs.RootPath(c.srcRoot, c.srcPath(link))
+		if err != nil {
+			return err
+		}
+		fi, err := os.Lstat(resolved)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return errors.Errorf("failed to follow symlink %s: %s not found", c.srcPath(link), c.srcPath(resolved))
+			}
+			return errors.WithStack(err)
+		}
+		if fi.IsDir() {
+			for _, p := range append([]string{link}, chain...) {
+				if isParent(resolved, p) {
+					return errors.Errorf("failed to follow symlink %s: %s contains the symlink", c.srcPath(link), c.srcPath(resolved))
+				}
+			}
+		}
+
+		dest := filepath.Join(target, rel)
+		skip, follow, err := c.plan(resolved, dest, false)
+		if err != nil {
+			return err
+		}
+		if len(skip) > 0 && skip[0] == "." {
+			continue
+		}
+		if fiDest, err := os.Lstat(dest); err == nil && fiDest.Mode()&os.ModeSymlink != 0 {
+			// replaced like the existing files
+			if err := os.Remove(dest); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		opt := append(append([]copy.Opt{}, c.opt...), func(ci *copy.CopyInfo) {
+			ci.CopyDirContents = true
+			ci.FollowLinks = false
+			ci.IncludePatterns = nil
+			ci.ExcludePatterns = exactPatterns(skip, follow)
+		})
+		if err := copy.Copy(ctx, c.srcRoot, c.srcPath(resolved), c.destRoot, c.destPath(dest), opt...); err != nil {
+			return err
+		}
+		if err := c.follow(ctx, resolved, dest, follow, append(chain, resolved), depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *policyCopier) included(rel string) (bool, error) {
+	if c.exclude != nil {
+		if ok, err := c.exclude.MatchesOrParentMatches(rel); err != nil || ok {
+			return false, err
+		}
+	}
+	if c.include != nil {
+		return c.include.MatchesOrParentMatches(rel)
+	}
+	return true, nil
+}
+
+// checkEscape fails if the symlink p points outside of the boundary
+// directory. The absolute targets are relative to the source root.
+func (c *policyCopier) checkEscape(p, boundary string) error {
+	link, err := os.Readlink(p)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read link %s", c.srcPath(p))
+	}
+	var resolved string
+	if filepath.IsAbs(link) {
+		resolved = filepath.Join(c.srcRoot, link)
+	} else {
+		resolved = filepath.Join(filepath.Dir(p), link)
+	}
+	if !isParent(boundary, resolved) {
+		return errors.Errorf("symlink %s points outside of the copied files: %s", c.srcPath(p), link)
+	}
+	return nil
+}
+
+// srcPath returns the path of p in the source root.
+func (c *policyCopier) srcPath(p string) string {
+	return rootRel(c.srcRoot, p)
+}
+
+// destPath returns the path of p in the destination root.
+func (c *policyCopier) destPath(p string) string {
+	return rootRel(c.destRoot, p)
+}
+
+func rootRel(root, p string) string {
+	rel, err := filepath.Rel(root, p)
+	if err != nil || rel == "." {
+		return "/"
+	}
+	return filepath.Join("/", rel)
+}
+
+// isParent returns whether p is dir or one of its descendants.
+func isParent(dir, p string) bool {
+	return p == dir || strings.HasPrefix(p, dir+string(filepath.Separator)) || dir == string(filepath.Separator)
+}
+
+// exactPatterns returns the exclude patterns matching exactly the paths.
+func exactPatterns(paths ...[]string) []string {
+	var out []string
+	for _, pp := range paths {
+		for _, p := range pp {
+			out = append(out, exactPattern(p))
+		}
+	}
+	return out
+}
+
+func exactPattern(p string) string {
+	var b strings.Builder
+	for i, r := range p {
+		switch {
+		case r == '*' || r == '?' || r == '[' || r == ']' || r == '\\' || r == '!' && i == 0:
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			// the patterns are trimmed
+			b.WriteRune('[')
+			b.WriteRune(r)
+			b.WriteRune(']')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
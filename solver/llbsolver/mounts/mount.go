@@ -23,6 +23,8 @@ import (
 	"github.com/moby/buildkit/snapshot"
 	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/grpcerrors"
+	"github.com/moby/buildkit/util/nestedbuild"
+	"github.com/moby/buildkit/util/tenant"
 	"github.com/moby/locker"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
@@ -46,6 +48,10 @@ type MountManager struct {
 }
 
 func (mm *MountManager) getRefCacheDir(ctx context.Context, ref cache.ImmutableRef, id string, m *pb.Mount, sharing pb.CacheSharingOpt, s session.Group) (mref cache.MutableRef, err error) {
+	// the cache mounts of tenants are separate from the ones of other tenants
+	if t := tenant.FromContext(ctx); t != "" {
+		id = "tenant/" + t + "/" + id
+	}
 	g := &cacheRefGetter{
 		locker:          &mm.cacheMountsMu,
 		cacheMounts:     mm.cacheMounts,
@@ -378,9 +384,75 @@ func (mm *MountManager) MountableSecret(ctx context.Context, m *pb.Mount, g sess
 }
 
 func (mm *MountManager) MountableSSH(ctx context.Context, m *pb.Mount, g session.Group) (cache.Mountable, error) {
+	if m.SSHOpt != nil && m.SSHOpt.ID == pb.SSHNestedBuildID {
+		return mm.getNestedBuildMountable(ctx, m)
+	}
 	return mm.getSSHMountable(ctx, m, g)
 }
 
+func (mm *MountManager) getNestedBuildMountable(ctx context.Context, m *pb.Mount) (cache.Mountable, error) {
+	srv, parent := nestedbuild.ServerFromContext(ctx)
+	if srv == nil {
+		if m.SSHOpt.Optional {
+			return nil, nil
+		}
+		return nil, errors.New("nested builds are not supported by the worker")
+	}
+	return &nestedBuildMount{mount: m, server: srv, parent: parent, idmap: mm.cm.IdentityMapping()}, nil
+}
+
+type nestedBuildMount struct {
+	mount  *pb.Mount
+	server nestedbuild.Server
+	parent nestedbuild.Parent
+	idmap  *idtools.IdentityMapping
+}
+
+func (nm *nestedBuildMount) Mount(ctx context.Context, readonly bool, g session.Group) (snapshot.Mountable, error) {
+	return &nestedBuildMountInstance{nm: nm, idmap: nm.idmap}, nil
+}
+
+type nestedBuildMountInstance struct {
+	nm    *nestedBuildMount
+	idmap *idtools.IdentityMapping
+}
+
+func (nm *nestedBuildMountInstance) Mount() ([]mount.Mount, func() error, error) {
+	uid := int(nm.nm.mount.SSHOpt.Uid)
+	gid := int(nm.nm.mount.SSHOpt.Gid)
+
+	if nm.idmap != nil {
+		identity, err := nm.idmap.ToHost(idtools.Identity{
+			UID: uid,
+			GID: gid,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		uid = identity.UID
+		gid = identity.GID
+	}
+
+	sock, cleanup, err := nestedbuild.MountSocket(context.TODO(), nm.nm.server, nm.nm.parent, nestedbuild.SocketOpt{
+		UID:  uid,
+		GID:  gid,
+		Mode: int(nm.nm.mount.SSHOpt.Mode & 0777),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return []mount.Mount{{
+		Type:    "bind",
+		Source:  sock,
+		Options: []string{"rbind"},
+	}}, cleanup, nil
+}
+
+func (nm *nestedBuildMountInstance) IdentityMapping() *idtools.IdentityMapping {
+	return nm.idmap
+}
+
 func newTmpfs(idmap *idtools.IdentityMapping, opt *pb.TmpfsOpt) cache.Mountable {
 	return &tmpfs{idmap: idmap, opt: opt}
 }
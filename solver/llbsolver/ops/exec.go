@@ -9,6 +9,7 @@ import (
 	"path"
 	"sort"
 	"strings"
+	"syscall"
 
 	"github.com/containerd/containerd/platforms"
 	"github.com/moby/buildkit/cache"
@@ -33,6 +34,26 @@ import (
 
 const execCacheType = "buildkit.exec.v0"
 
+// stopSignal returns the channel sending SIGTERM to the process when the
+// build is stopped gracefully, before its context is canceled and the
+// process is killed. release must be called once the process has exited.
+func stopSignal(ctx context.Context) (_ <-chan syscall.Signal, release func()) {
+	stopping := solver.Stopping(ctx)
+	if stopping == nil {
+		return nil, func() {}
+	}
+	ch := make(chan syscall.Signal, 1)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-stopping:
+			ch <- syscall.SIGTERM
+		case <-done:
+		}
+	}()
+	return ch, func() { close(done) }
+}
+
 type execOp struct {
 	op          *pb.ExecOp
 	cm          cache.Manager
@@ -43,6 +64,9 @@ type execOp struct {
 	platform    *pb.Platform
 	numInputs   int
 	parallelism *semaphore.Weighted
+	// contentInputs are the inputs matched in the cache by their content
+	// even when they are mounted read-write.
+	contentInputs []bool
 }
 
 func NewExecOp(v solver.Vertex, op *pb.Op_Exec, platform *pb.Platform, cm cache.Manager, parallelism *semaphore.Weighted, sm *session.Manager, exec executor.Executor, w worker.Worker) (solver.Op, error) {
@@ -51,18 +75,34 @@ func NewExecOp(v solver.Vertex, op *pb.Op_Exec, platform *pb.Platform, cm cache.
 	}
 	name := fmt.Sprintf("exec %s", strings.Join(op.Exec.Meta.Args, " "))
 	return &execOp{
-		op:          op.Exec,
-		mm:          mounts.NewMountManager(name, cm, sm),
-		cm:          cm,
-		sm:          sm,
-		exec:        exec,
-		numInputs:   len(v.Inputs()),
-		w:           w,
-		platform:    platform,
-		parallelism: parallelism,
+		op:            op.Exec,
+		mm:            mounts.NewMountManager(name, cm, sm),
+		cm:            cm,
+		sm:            sm,
+		exec:          exec,
+		numInputs:     len(v.Inputs()),
+		w:             w,
+		platform:      platform,
+		parallelism:   parallelism,
+		contentInputs: contentCachedInputs(v),
 	}, nil
 }
 
+// contentCachedInputs returns which inputs of the vertex are results of
+// ExecOps whose cache mode matches their results by content.
+func contentCachedInputs(v solver.Vertex) []bool {
+	inputs := v.Inputs()
+	content := make([]bool, len(inputs))
+	for i, inp := range inputs {
+		if op, ok := inp.Vertex.Sys().(*pb.Op); ok {
+			if exec := op.GetExec(); exec != nil && exec.CacheMode != pb.ExecCacheMode_CACHE_DEFINITION {
+				content[i] = true
+			}
+		}
+	}
+	return content
+}
+
 func cloneExecOp(old *pb.ExecOp) pb.ExecOp {
 	n := *old
 	meta := *n.Meta
@@ -91,6 +131,21 @@ func (e *execOp) CacheMap(ctx context.Context, g session.Group, index int) (*sol
 		op.Mounts[i].Selector = ""
 	}
 	op.Meta.ProxyEnv = nil
+	// the trace context is different for every build and is not expected to
+	// change the result of the process
+	op.Meta.TraceContext = false
+	if op.CacheMode == pb.ExecCacheMode_CACHE_HERMETIC {
+		// hermetic commands don't depend on the environment they run in
+		op.Meta.Hostname = ""
+		op.Meta.ExtraHosts = nil
+		op.Meta.Dns = nil
+		op.Meta.Ulimit = nil
+		op.Meta.CgroupParent = ""
+		op.NetworkName = ""
+	}
+	// the cache mode changes how the results are matched, not the results,
+	// so the results are shared with the execs in the other modes
+	op.CacheMode = pb.ExecCacheMode_CACHE_DEFINITION
 
 	p := platforms.DefaultSpec()
 	if e.platform != nil {
@@ -156,7 +211,7 @@ func (e *execOp) CacheMap(ctx context.Context, g session.Group, index int) (*sol
 			}
 			cm.Deps[i].Selector = digest.FromBytes(bytes.Join(dgsts, []byte{0}))
 		}
-		if !dep.NoContentBasedHash {
+		if !dep.NoContentBasedHash || e.contentInputs[i] {
 			cm.Deps[i].ComputeDigestFunc = llbsolver.NewContentHashFunc(toSelectors(dedupePaths(dep.Selectors)))
 		}
 		cm.Deps[i].PreprocessFunc = llbsolver.UnlazyResultFunc
@@ -313,17 +368,35 @@ func (e *execOp) Exec(ctx context.Context, g session.Group, inputs []solver.Resu
 	}
 
 	meta := executor.Meta{
-		Args:           e.op.Meta.Args,
-		Env:            e.op.Meta.Env,
-		Cwd:            e.op.Meta.Cwd,
-		User:           e.op.Meta.User,
-		Hostname:       e.op.Meta.Hostname,
-		ReadonlyRootFS: p.ReadonlyRootFS,
-		ExtraHosts:     extraHosts,
-		Ulimit:         e.op.Meta.Ulimit,
-		CgroupParent:   e.op.Meta.CgroupParent,
-		NetMode:        e.op.Network,
-		SecurityMode:   e.op.Security,
+		Args:            e.op.Meta.Args,
+		Env:             e.op.Meta.Env,
+		Cwd:             e.op.Meta.Cwd,
+		User:            e.op.Meta.User,
+		Hostname:        e.op.Meta.Hostname,
+		ReadonlyRootFS:  p.ReadonlyRootFS,
+		ExtraHosts:      extraHosts,
+		Ulimit:          e.op.Meta.Ulimit,
+		CgroupParent:    e.op.Meta.CgroupParent,
+		NetMode:         e.op.Network,
+		NetworkName:     e.op.NetworkName,
+		SecurityMode:    e.op.Security,
+		SeccompProfile:  e.op.Meta.SeccompProfile,
+		ApparmorProfile: e.op.Meta.ApparmorProfile,
+		TraceContext:    e.op.Meta.TraceContext,
+		ResourceLimits:  executor.ResourceLimitsFromContext(ctx),
+		DNS:             executor.DNSFromContext(ctx),
+		Userns:          executor.UsernsFromContext(ctx),
+		Runtime:         executor.RuntimeFromContext(ctx),
+	}
+	if e.op.Meta.Runtime != "" {
+		meta.Runtime = e.op.Meta.Runtime
+	}
+	if dns := e.op.Meta.Dns; dns != nil {
+		meta.DNS = &executor.DNSConfig{
+			Nameservers:   dns.Nameservers,
+			Options:       dns.Options,
+			SearchDomains: dns.SearchDomains,
+		}
 	}
 
 	if e.op.Meta.ProxyEnv != nil {
@@ -350,12 +423,15 @@ func (e *execOp) Exec(ctx context.Context, g session.Group, inputs []solver.Resu
 		}
 	}()
 
+	signal, releaseSignal := stopSignal(ctx)
 	execErr := e.exec.Run(ctx, "", p.Root, p.Mounts, executor.ProcessInfo{
 		Meta:   meta,
 		Stdin:  nil,
 		Stdout: stdout,
 		Stderr: stderr,
+		Signal: signal,
 	}, nil)
+	releaseSignal()
 
 	for i, out := range p.OutputRefs {
 		if mutable, ok := out.Ref.(cache.MutableRef); ok {
@@ -22,16 +22,6 @@ import (
 
 const qemuMountName = "/dev/.buildkit_qemu_emulator"
 
-var qemuArchMap = map[string]string{
-	"arm64":   "aarch64",
-	"amd64":   "x86_64",
-	"riscv64": "riscv64",
-	"arm":     "arm",
-	"s390x":   "s390x",
-	"ppc64le": "ppc64le",
-	"386":     "i386",
-}
-
 type emulator struct {
 	path  string
 	idmap *idtools.IdentityMapping
@@ -111,12 +101,7 @@ func getEmulator(ctx context.Context, p *pb.Platform, idmap *idtools.IdentityMap
 		}
 	}
 
-	a, ok := qemuArchMap[pp.Architecture]
-	if !ok {
-		a = pp.Architecture
-	}
-
-	fn, err := exec.LookPath("buildkit-qemu-" + a)
+	fn, err := exec.LookPath("buildkit-qemu-" + archutil.QemuArch(pp.Architecture))
 	if err != nil {
 		bklog.G(ctx).Warn(err.Error()) // TODO: remove this with pull support
 		return nil, nil                // no emulator available
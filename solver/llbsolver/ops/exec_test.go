@@ -1,8 +1,12 @@
 package ops
 
 import (
+	"context"
 	"testing"
 
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/solver/pb"
+	digest "github.com/opencontainers/go-digest"
 	"github.com/stretchr/testify/require"
 )
 
@@ -25,3 +29,56 @@ func TestDedupPaths(t *testing.T) {
 	res = dedupePaths([]string{"foo/bar/baz", "foo/bara", "foo/bar/bax", "foo/bar"})
 	require.Equal(t, []string{"foo/bar", "foo/bara"}, res)
 }
+
+type testVertex struct {
+	op     *pb.Op
+	inputs []solver.Edge
+}
+
+func (v *testVertex) Digest() digest.Digest         { return "" }
+func (v *testVertex) Sys() interface{}              { return v.op }
+func (v *testVertex) Options() solver.VertexOptions { return solver.VertexOptions{} }
+func (v *testVertex) Inputs() []solver.Edge         { return v.inputs }
+func (v *testVertex) Name() string                  { return "" }
+
+func TestExecCacheMode(t *testing.T) {
+	newExec := func(mode pb.ExecCacheMode, hostname string) *pb.ExecOp {
+		return &pb.ExecOp{
+			Meta:      &pb.Meta{Args: []string{"make"}, Cwd: "/", Hostname: hostname},
+			Mounts:    []*pb.Mount{{Input: 0, Dest: "/", Output: 0}},
+			CacheMode: mode,
+		}
+	}
+	cacheMap := func(exec *pb.ExecOp, inputMode pb.ExecCacheMode) *solver.CacheMap {
+		v := &testVertex{
+			op: &pb.Op{Op: &pb.Op_Exec{Exec: exec}},
+			inputs: []solver.Edge{{Vertex: &testVertex{
+				op: &pb.Op{Op: &pb.Op_Exec{Exec: newExec(inputMode, "")}},
+			}}},
+		}
+		op, err := NewExecOp(v, v.op.Op.(*pb.Op_Exec), nil, nil, nil, nil, nil, nil)
+		require.NoError(t, err)
+		cm, _, err := op.CacheMap(context.TODO(), nil, 0)
+		require.NoError(t, err)
+		return cm
+	}
+
+	def := cacheMap(newExec(pb.ExecCacheMode_CACHE_DEFINITION, "foo"), pb.ExecCacheMode_CACHE_DEFINITION)
+	// the read-write root is matched by its definition
+	require.Nil(t, def.Deps[0].ComputeDigestFunc)
+
+	// the cache mode alone doesn't change the cache key of the exec
+	content := cacheMap(newExec(pb.ExecCacheMode_CACHE_CONTENT, "foo"), pb.ExecCacheMode_CACHE_DEFINITION)
+	require.Equal(t, def.Digest, content.Digest)
+
+	// the hostname is left out of the cache key of hermetic execs
+	hermetic1 := cacheMap(newExec(pb.ExecCacheMode_CACHE_HERMETIC, "foo"), pb.ExecCacheMode_CACHE_DEFINITION)
+	hermetic2 := cacheMap(newExec(pb.ExecCacheMode_CACHE_HERMETIC, "bar"), pb.ExecCacheMode_CACHE_DEFINITION)
+	require.Equal(t, hermetic1.Digest, hermetic2.Digest)
+	require.NotEqual(t, def.Digest, hermetic1.Digest)
+
+	// the results of content cached execs are matched by content
+	cm := cacheMap(newExec(pb.ExecCacheMode_CACHE_DEFINITION, "foo"), pb.ExecCacheMode_CACHE_CONTENT)
+	require.NotNil(t, cm.Deps[0].ComputeDigestFunc)
+	require.Equal(t, def.Digest, cm.Digest)
+}
@@ -35,6 +35,8 @@ type fileOp struct {
 	solver      *FileOpSolver
 	numInputs   int
 	parallelism *semaphore.Weighted
+	// contentInputs are the inputs matched in the cache by their content.
+	contentInputs []bool
 }
 
 func NewFileOp(v solver.Vertex, op *pb.Op_File, cm cache.Manager, parallelism *semaphore.Weighted, w worker.Worker) (solver.Op, error) {
@@ -42,12 +44,13 @@ func NewFileOp(v solver.Vertex, op *pb.Op_File, cm cache.Manager, parallelism *s
 		return nil, err
 	}
 	return &fileOp{
-		op:          op.File,
-		md:          cm,
-		numInputs:   len(v.Inputs()),
-		w:           w,
-		solver:      NewFileOpSolver(w, &file.Backend{}, file.NewRefManager(cm)),
-		parallelism: parallelism,
+		op:            op.File,
+		md:            cm,
+		numInputs:     len(v.Inputs()),
+		w:             w,
+		solver:        NewFileOpSolver(w, &file.Backend{}, file.NewRefManager(cm)),
+		parallelism:   parallelism,
+		contentInputs: contentCachedInputs(v),
 	}, nil
 }
 
@@ -92,12 +95,40 @@ func (f *fileOp) CacheMap(ctx context.Context, g session.Group, index int) (*sol
 			if err != nil {
 				return nil, false, err
 			}
+		case *pb.FileAction_Symlink:
+			p := *a.Symlink
+			markInvalid(action.Input)
+			processOwner(p.Owner, selectors)
+			dt, err = json.Marshal(p)
+			if err != nil {
+				return nil, false, err
+			}
+		case *pb.FileAction_Link:
+			p := *a.Link
+			markInvalid(action.Input)
+			dt, err = json.Marshal(p)
+			if err != nil {
+				return nil, false, err
+			}
+		case *pb.FileAction_Setattr:
+			p := *a.Setattr
+			markInvalid(action.Input)
+			processOwner(p.Owner, selectors)
+			dt, err = json.Marshal(p)
+			if err != nil {
+				return nil, false, err
+			}
 		case *pb.FileAction_Copy:
 			p := *a.Copy
 			markInvalid(action.Input)
 			processOwner(p.Owner, selectors)
 			if action.SecondaryInput != -1 && int(action.SecondaryInput) < f.numInputs {
-				addSelector(selectors, int(action.SecondaryInput), p.Src, p.AllowWildcard, p.FollowSymlink, p.IncludePatterns, p.ExcludePatterns)
+				if p.Symlinks == pb.CopySymlinkPolicy_FOLLOW {
+					// the followed symlinks can point anywhere in the input
+					addSelector(selectors, int(action.SecondaryInput), "/", false, false, nil, nil)
+				} else {
+					addSelector(selectors, int(action.SecondaryInput), p.Src, p.AllowWildcard, p.FollowSymlink, p.IncludePatterns, p.ExcludePatterns)
+				}
 				p.Src = path.Base(p.Src)
 			}
 			dt, err = json.Marshal(p)
@@ -152,6 +183,9 @@ func (f *fileOp) CacheMap(ctx context.Context, g session.Group, index int) (*sol
 		cm.Deps[idx].ComputeDigestFunc = llbsolver.NewContentHashFunc(dedupeSelectors(m))
 	}
 	for idx := range cm.Deps {
+		if cm.Deps[idx].ComputeDigestFunc == nil && f.contentInputs[idx] {
+			cm.Deps[idx].ComputeDigestFunc = llbsolver.NewContentHashFunc(nil)
+		}
 		cm.Deps[idx].PreprocessFunc = llbsolver.UnlazyResultFunc
 	}
 
@@ -591,6 +625,26 @@ func (s *FileOpSolver) getInput(ctx context.Context, idx int, inputs []fileoptyp
 			if err := s.b.Rm(ctx, inpMount, *a.Rm); err != nil {
 				return nil, err
 			}
+		case *pb.FileAction_Symlink:
+			user, group, err := loadOwner(ctx, a.Symlink.Owner)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.b.Symlink(ctx, inpMount, user, group, *a.Symlink); err != nil {
+				return nil, err
+			}
+		case *pb.FileAction_Link:
+			if err := s.b.Link(ctx, inpMount, *a.Link); err != nil {
+				return nil, err
+			}
+		case *pb.FileAction_Setattr:
+			user, group, err := loadOwner(ctx, a.Setattr.Owner)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.b.SetAttr(ctx, inpMount, user, group, *a.Setattr); err != nil {
+				return nil, err
+			}
 		case *pb.FileAction_Copy:
 			if inpMountSecondary == nil {
 				m, err := s.r.Prepare(ctx, nil, true, g)
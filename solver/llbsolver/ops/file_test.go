@@ -57,6 +57,66 @@ func TestMkdirMkfile(t *testing.T) {
 	require.Equal(t, fo.Actions[1].Action.(*pb.FileAction_Mkfile).Mkfile, o.mount.chain[1].mkfile)
 }
 
+func TestSymlinkLinkSetAttr(t *testing.T) {
+	t.Parallel()
+	fo := &pb.FileOp{
+		Actions: []*pb.FileAction{
+			{
+				Input:          0,
+				SecondaryInput: -1,
+				Output:         -1,
+				Action: &pb.FileAction_Symlink{
+					Symlink: &pb.FileActionSymlink{
+						Oldpath:   "bar",
+						Newpath:   "/foo",
+						Timestamp: -1,
+					},
+				},
+			},
+			{
+				Input:          1,
+				SecondaryInput: -1,
+				Output:         -1,
+				Action: &pb.FileAction_Link{
+					Link: &pb.FileActionLink{
+						Oldpath: "/bar",
+						Newpath: "/baz",
+					},
+				},
+			},
+			{
+				Input:          2,
+				SecondaryInput: -1,
+				Output:         0,
+				Action: &pb.FileAction_Setattr{
+					Setattr: &pb.FileActionSetAttr{
+						Path:      "/bar",
+						Mode:      0600,
+						Timestamp: -1,
+						Owner: &pb.ChownOpt{
+							User: &pb.UserOpt{User: &pb.UserOpt_ByID{ByID: 100}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	s, rb := newTestFileSolver()
+	inp := rb.NewRef("ref1")
+	outs, err := s.Solve(context.TODO(), []fileoptypes.Ref{inp}, fo.Actions, nil)
+	require.NoError(t, err)
+	require.Equal(t, len(outs), 1)
+	rb.checkReleased(t, append(outs, inp))
+
+	o := outs[0].(*testFileRef)
+	require.Equal(t, "mount-ref1-symlink-link-setattr-commit", o.id)
+	require.Equal(t, 3, len(o.mount.chain))
+	require.Equal(t, fo.Actions[0].Action.(*pb.FileAction_Symlink).Symlink, o.mount.chain[0].symlink)
+	require.Equal(t, fo.Actions[1].Action.(*pb.FileAction_Link).Link, o.mount.chain[1].link)
+	require.Equal(t, fo.Actions[2].Action.(*pb.FileAction_Setattr).Setattr, o.mount.chain[2].setattr)
+}
+
 func TestChownOpt(t *testing.T) {
 	t.Parallel()
 	fo := &pb.FileOp{
@@ -599,6 +659,9 @@ type mod struct {
 	mkdir   *pb.FileActionMkDir
 	rm      *pb.FileActionRm
 	mkfile  *pb.FileActionMkFile
+	symlink *pb.FileActionSymlink
+	link    *pb.FileActionLink
+	setattr *pb.FileActionSetAttr
 	copy    *pb.FileActionCopy
 	copySrc []mod
 }
@@ -649,6 +712,26 @@ func (b *testFileBackend) Rm(_ context.Context, m fileoptypes.Mount, a pb.FileAc
 	mm.chain = append(mm.chain, mod{rm: &a})
 	return nil
 }
+func (b *testFileBackend) Symlink(_ context.Context, m, user, group fileoptypes.Mount, a pb.FileActionSymlink) error {
+	mm := m.(*testMount)
+	mm.id += "-symlink"
+	mm.addUser(user, group)
+	mm.chain = append(mm.chain, mod{symlink: &a})
+	return nil
+}
+func (b *testFileBackend) Link(_ context.Context, m fileoptypes.Mount, a pb.FileActionLink) error {
+	mm := m.(*testMount)
+	mm.id += "-link"
+	mm.chain = append(mm.chain, mod{link: &a})
+	return nil
+}
+func (b *testFileBackend) SetAttr(_ context.Context, m, user, group fileoptypes.Mount, a pb.FileActionSetAttr) error {
+	mm := m.(*testMount)
+	mm.id += "-setattr"
+	mm.addUser(user, group)
+	mm.chain = append(mm.chain, mod{setattr: &a})
+	return nil
+}
 func (b *testFileBackend) Copy(_ context.Context, m1, m, user, group fileoptypes.Mount, a pb.FileActionCopy) error {
 	mm := m.(*testMount)
 	mm1 := m1.(*testMount)
@@ -21,6 +21,9 @@ type Backend interface {
 	Mkdir(context.Context, Mount, Mount, Mount, pb.FileActionMkDir) error
 	Mkfile(context.Context, Mount, Mount, Mount, pb.FileActionMkFile) error
 	Rm(context.Context, Mount, pb.FileActionRm) error
+	Symlink(context.Context, Mount, Mount, Mount, pb.FileActionSymlink) error
+	Link(context.Context, Mount, pb.FileActionLink) error
+	SetAttr(context.Context, Mount, Mount, Mount, pb.FileActionSetAttr) error
 	Copy(context.Context, Mount, Mount, Mount, Mount, pb.FileActionCopy) error
 }
 
@@ -10,6 +10,7 @@ import (
 	"github.com/moby/buildkit/solver/llbsolver"
 	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/source"
+	"github.com/moby/buildkit/util/buildinfo"
 	"github.com/moby/buildkit/worker"
 	digest "github.com/opencontainers/go-digest"
 	"golang.org/x/sync/semaphore"
@@ -80,7 +81,7 @@ func (s *sourceOp) CacheMap(ctx context.Context, g session.Group, index int) (*s
 
 	var buildSources map[string]string
 	if !strings.HasPrefix(s.op.Source.GetIdentifier(), "local://") {
-		buildSources = map[string]string{s.op.Source.GetIdentifier(): pin}
+		buildSources = map[string]string{buildinfo.SourceKey(s.op.Source): pin}
 	}
 
 	return &solver.CacheMap{
@@ -0,0 +1,90 @@
+package llbsolver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/moby/buildkit/frontend"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/sourcepolicy"
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
+	"github.com/pkg/errors"
+)
+
+const keySourcePolicy = "llb.sourcepolicy"
+
+// sourcePolicy returns the engine applying the policies of the request and
+// then the policies of the daemon, so the daemon decides on the sources the
+// request policies converted.
+func (s *Solver) sourcePolicy(req frontend.SolveRequest) (*sourcepolicy.Engine, error) {
+	pols := append([]*spb.Policy{}, req.SourcePolicies...)
+	pols = append(pols, s.policies...)
+	e, err := sourcepolicy.NewEngine(pols)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid source policy")
+	}
+	return e, nil
+}
+
+func loadSourcePolicies(b solver.Builder) ([]*sourcepolicy.Engine, error) {
+	var engines []*sourcepolicy.Engine
+	err := b.EachValue(context.TODO(), keySourcePolicy, func(v interface{}) error {
+		e, ok := v.(*sourcepolicy.Engine)
+		if !ok {
+			return errors.Errorf("invalid source policy %T", v)
+		}
+		engines = append(engines, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return engines, nil
+}
+
+// applySourcePolicies rewrites the sources of the definition with the source
+// policies of the builder. Sources are converted and denied before any vertex
+// of the definition is loaded.
+func applySourcePolicies(b solver.Builder, def *pb.Definition) (*pb.Definition, error) {
+	engines, err := loadSourcePolicies(b)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range engines {
+		def, err = e.Rewrite(def)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return def, nil
+}
+
+// convertImageRef applies the conversions of the source policies of the
+// builder to an image reference. Denied images are returned unchanged, they
+// are only denied when a definition uses them.
+func convertImageRef(b solver.Builder, ref string) (string, error) {
+	engines, err := loadSourcePolicies(b)
+	if err != nil || len(engines) == 0 {
+		return ref, err
+	}
+	if named, err := reference.ParseNormalizedNamed(ref); err == nil {
+		ref = reference.TagNameOnly(named).String()
+	}
+	id := "docker-image://" + ref
+	for _, e := range engines {
+		converted, err := e.EvaluateIdentifier(id)
+		if err != nil {
+			if errors.Is(err, sourcepolicy.ErrSourceDenied) {
+				return ref, nil
+			}
+			return "", err
+		}
+		id = converted
+	}
+	if !strings.HasPrefix(id, "docker-image://") {
+		return "", errors.Errorf("source policy converted image %s to unsupported source %s", ref, id)
+	}
+	return strings.TrimPrefix(id, "docker-image://"), nil
+}
@@ -0,0 +1,216 @@
+package llbsolver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/moby/buildkit/executor"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/solver/pb"
+	srctypes "github.com/moby/buildkit/source/types"
+	"github.com/moby/buildkit/util/audit"
+	"github.com/moby/buildkit/util/bandwidth"
+	"github.com/moby/buildkit/util/entitlements"
+	"github.com/moby/buildkit/util/fairsched"
+	"github.com/moby/buildkit/util/nestedbuild"
+	"github.com/moby/buildkit/util/tenant"
+	"github.com/pkg/errors"
+)
+
+const keyResources = "llb.resources"
+
+// Resources are the resources of a build.
+type Resources struct {
+	// Limits constrain the containers of the exec operations of the build.
+	Limits *executor.ResourceLimits
+	// Weight is the share of the parallel operations the build gets when
+	// builds compete for them. Weights lower than 1 are treated as 1.
+	Weight int
+	// DNS overrides the DNS config of the worker for the exec operations of
+	// the build that don't set their own.
+	DNS *executor.DNSConfig
+	// Userns is the user namespace mode of the exec operations of the build.
+	Userns string
+	// Runtime is the OCI runtime of the exec operations of the build that
+	// don't set their own.
+	Runtime string
+	// Bandwidth limits the context transfers and the registry pushes and
+	// pulls of the build.
+	Bandwidth bandwidth.Limits
+	// Tenant isolates the cache, the running operations and the cache
+	// mounts of the build from the builds of other tenants.
+	Tenant string
+	// ShareTenantImages shares the cache of the image sources of the build
+	// with the builds of other tenants.
+	ShareTenantImages bool
+	// Usage collects the sources, the secrets and the SSH IDs of the
+	// definitions of the build for the audit log.
+	Usage *audit.Usage
+}
+
+type buildResources struct {
+	Resources
+	id       string
+	limiters *bandwidth.Limiters
+}
+
+// loadResources returns the resources of the first build of the builder.
+func loadResources(b solver.Builder) (*buildResources, error) {
+	var res *buildResources
+	err := b.EachValue(context.TODO(), keyResources, func(v interface{}) error {
+		r, ok := v.(*buildResources)
+		if !ok {
+			return errors.Errorf("invalid resources %T", v)
+		}
+		if res == nil {
+			res = r
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Parallelism limits the number of operations run at a time across builds.
+// Zero values are unlimited.
+type Parallelism struct {
+	// Max limits all operations.
+	Max int
+	// Network limits the operations bound by the network: image pulls, git
+	// fetches, http downloads and cache imports.
+	Network int
+	// Exec limits the exec operations.
+	Exec int
+	// Speculative limits the network sources fetched speculatively while
+	// builds compute their cache keys. Zero disables speculation.
+	Speculative int
+}
+
+// Parallelism returns the current parallelism limits of the solver.
+func (s *Solver) Parallelism() Parallelism {
+	return Parallelism{
+		Max:         s.sched.Capacity(),
+		Network:     s.networkSched.Capacity(),
+		Exec:        s.execSched.Capacity(),
+		Speculative: s.speculation.getLimit(),
+	}
+}
+
+// SetParallelism changes the parallelism limits of the solver. Running
+// operations are not affected.
+func (s *Solver) SetParallelism(p Parallelism) {
+	s.sched.SetCapacity(p.Max)
+	s.networkSched.SetCapacity(p.Network)
+	s.execSched.SetCapacity(p.Exec)
+	s.speculation.setLimit(p.Speculative)
+}
+
+// schedulers returns the schedulers of the vertex, in the order their slots
+// are acquired.
+func (s *Solver) schedulers(v solver.Vertex) []*fairsched.Scheduler {
+	scheds := []*fairsched.Scheduler{s.sched}
+	if op, ok := v.Sys().(*pb.Op); ok {
+		switch op := op.Op.(type) {
+		case *pb.Op_Exec:
+			scheds = []*fairsched.Scheduler{s.execSched, s.sched}
+		case *pb.Op_Source:
+			if isNetworkSource(op.Source.Identifier) {
+				scheds = []*fairsched.Scheduler{s.networkSched, s.sched}
+			}
+		}
+	}
+	return scheds
+}
+
+func isNetworkSource(id string) bool {
+	for _, scheme := range []string{srctypes.DockerImageScheme, srctypes.GitScheme, srctypes.HTTPScheme, srctypes.HTTPSScheme, srctypes.S3Scheme, srctypes.GCSScheme, srctypes.AzureBlobScheme} {
+		if strings.HasPrefix(id, scheme+"://") {
+			return true
+		}
+	}
+	return false
+}
+
+// acquireSlot waits for a slot of the scheduler for the build of the builder.
+func acquireSlot(ctx context.Context, sched *fairsched.Scheduler, b solver.Builder) (func(), error) {
+	res, err := loadResources(b)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return sched.Acquire(ctx, "", 1)
+	}
+	return sched.Acquire(ctx, res.id, res.Weight)
+}
+
+// resourcesOp runs the operation with the resource limits, the DNS config, the
+// user namespace mode and the OCI runtime of the build and schedules it fairly with the operations of other builds.
+type resourcesOp struct {
+	solver.Op
+	res    *buildResources
+	scheds []*fairsched.Scheduler
+	// nested serves the nested builds of the exec operations, with the
+	// entitlements of the build
+	nested nestedbuild.Server
+	ents   entitlements.Set
+}
+
+func (op *resourcesOp) Acquire(ctx context.Context) (_ solver.ReleaseFunc, err error) {
+	var releases []func()
+	releaseAll := func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+	}
+	defer func() {
+		if err != nil {
+			releaseAll()
+		}
+	}()
+	for _, sched := range op.scheds {
+		r, err := sched.Acquire(ctx, op.res.id, op.res.Weight)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, r)
+	}
+	release, err := op.Op.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	releases = append(releases, release)
+	return releaseAll, nil
+}
+
+func (op *resourcesOp) Exec(ctx context.Context, g session.Group, inputs []solver.Result) ([]solver.Result, error) {
+	if op.res.Limits != nil {
+		ctx = executor.WithResourceLimits(ctx, op.res.Limits)
+	}
+	if op.res.DNS != nil {
+		ctx = executor.WithDNS(ctx, op.res.DNS)
+	}
+	if op.res.Userns != "" {
+		ctx = executor.WithUserns(ctx, op.res.Userns)
+	}
+	if op.res.Runtime != "" {
+		ctx = executor.WithRuntime(ctx, op.res.Runtime)
+	}
+	ctx = bandwidth.WithLimiters(ctx, op.res.limiters)
+	ctx = tenant.WithName(ctx, op.res.Tenant)
+	if op.nested != nil {
+		p := nestedbuild.Parent{
+			ID:     op.res.id,
+			Limits: op.res.Limits,
+			Weight: op.res.Weight,
+			Tenant: op.res.Tenant,
+		}
+		for e := range op.ents {
+			p.Entitlements = append(p.Entitlements, e)
+		}
+		ctx = nestedbuild.WithServer(ctx, op.nested, p)
+	}
+	return op.Op.Exec(ctx, g, inputs)
+}
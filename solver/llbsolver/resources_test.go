@@ -0,0 +1,20 @@
+package llbsolver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsNetworkSource(t *testing.T) {
+	for id, network := range map[string]bool{
+		"docker-image://docker.io/library/alpine:latest": true,
+		"git://github.com/moby/buildkit.git":             true,
+		"https://example.com/file.tar":                   true,
+		"http://example.com/file.tar":                    true,
+		"local://context":                                false,
+		"oci-layout://content":                           false,
+	} {
+		require.Equal(t, network, isNetworkSource(id), id)
+	}
+}
@@ -3,6 +3,7 @@ package llbsolver
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -19,12 +20,20 @@ import (
 	"github.com/moby/buildkit/identity"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/sourcepolicy"
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
+	"github.com/moby/buildkit/util/bandwidth"
 	"github.com/moby/buildkit/util/buildinfo"
+	binfotypes "github.com/moby/buildkit/util/buildinfo/types"
 	"github.com/moby/buildkit/util/compression"
 	"github.com/moby/buildkit/util/entitlements"
+	"github.com/moby/buildkit/util/fairsched"
+	"github.com/moby/buildkit/util/nestedbuild"
 	"github.com/moby/buildkit/util/progress"
 	"github.com/moby/buildkit/worker"
 	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
 )
@@ -32,9 +41,15 @@ import (
 const keyEntitlements = "llb.entitlements"
 
 type ExporterRequest struct {
-	Exporter        exporter.ExporterInstance
+	Exporters []exporter.ExporterInstance
+	// Outputs contains the names of the build outputs selected by the
+	// exporters with the same index. Empty name selects the build target.
+	Outputs         []string
 	CacheExporter   remotecache.Exporter
 	CacheExportMode solver.CacheExportMode
+	// CacheExportEntry are the options CacheExporter was created with,
+	// recorded in the build info.
+	CacheExportEntry *frontend.CacheOptionsEntry
 }
 
 // ResolveWorkerFunc returns default worker for the temporary default non-distributed use cases
@@ -44,24 +59,44 @@ type Solver struct {
 	workerController          *worker.Controller
 	solver                    *solver.Solver
 	resolveWorker             ResolveWorkerFunc
+	platformWorker            func(ocispecs.Platform) (worker.Worker, error)
 	eachWorker                func(func(worker.Worker) error) error
 	frontends                 map[string]frontend.Frontend
 	resolveCacheImporterFuncs map[string]remotecache.ResolveCacheImporterFunc
 	gatewayForwarder          *controlgateway.GatewayForwarder
 	sm                        *session.Manager
 	entitlements              []string
+	sched                     *fairsched.Scheduler
+	networkSched              *fairsched.Scheduler
+	execSched                 *fairsched.Scheduler
+	speculation               *speculationBudget
+	policies                  []*spb.Policy
+	allowedSockets            []string
+	buildInfoAttrs            *buildinfo.AttrsPolicy
+	nested                    nestedbuild.Server
 }
 
-func New(wc *worker.Controller, f map[string]frontend.Frontend, cache solver.CacheManager, resolveCI map[string]remotecache.ResolveCacheImporterFunc, gatewayForwarder *controlgateway.GatewayForwarder, sm *session.Manager, ents []string) (*Solver, error) {
+func New(wc *worker.Controller, f map[string]frontend.Frontend, cache solver.CacheManager, resolveCI map[string]remotecache.ResolveCacheImporterFunc, gatewayForwarder *controlgateway.GatewayForwarder, sm *session.Manager, ents []string, parallelism Parallelism, policies []*spb.Policy, allowedSockets []string, buildInfoAttrs *buildinfo.AttrsPolicy) (*Solver, error) {
 	s := &Solver{
 		workerController:          wc,
 		resolveWorker:             defaultResolver(wc),
+		platformWorker:            wc.ForPlatform,
 		eachWorker:                allWorkers(wc),
 		frontends:                 f,
 		resolveCacheImporterFuncs: resolveCI,
 		gatewayForwarder:          gatewayForwarder,
 		sm:                        sm,
 		entitlements:              ents,
+		sched:                     fairsched.New(parallelism.Max),
+		networkSched:              fairsched.New(parallelism.Network),
+		execSched:                 fairsched.New(parallelism.Exec),
+		speculation:               &speculationBudget{limit: int64(parallelism.Speculative)},
+		policies:                  policies,
+		allowedSockets:            allowedSockets,
+		buildInfoAttrs:            buildInfoAttrs,
+	}
+	if _, err := sourcepolicy.NewEngine(policies); err != nil {
+		return nil, errors.Wrap(err, "invalid source policy")
 	}
 
 	s.solver = solver.NewSolver(solver.SolverOpt{
@@ -73,15 +108,60 @@ func New(wc *worker.Controller, f map[string]frontend.Frontend, cache solver.Cac
 
 func (s *Solver) resolver() solver.ResolveOpFunc {
 	return func(v solver.Vertex, b solver.Builder) (solver.Op, error) {
-		w, err := s.resolveWorker()
+		w, err := s.vertexWorker(v)
+		if err != nil {
+			return nil, err
+		}
+		op, err := w.ResolveOp(v, s.Bridge(b), s.sm)
 		if err != nil {
 			return nil, err
 		}
-		return w.ResolveOp(v, s.Bridge(b), s.sm)
+		res, err := loadResources(b)
+		if err != nil || res == nil {
+			return op, err
+		}
+		rop := &resourcesOp{Op: op, res: res, scheds: s.schedulers(v)}
+		if s.nested != nil && isExec(v) {
+			ents, err := loadEntitlements(b)
+			if err != nil {
+				return nil, err
+			}
+			rop.nested, rop.ents = s.nested, ents
+		}
+		return rop, nil
 	}
 }
 
+func isExec(v solver.Vertex) bool {
+	op, ok := v.Sys().(*pb.Op)
+	if !ok {
+		return false
+	}
+	_, ok = op.Op.(*pb.Op_Exec)
+	return ok
+}
+
+// SetNestedBuildServer sets the server of the nested builds requested by the
+// exec operations mounting the BuildKit API.
+func (s *Solver) SetNestedBuildServer(srv nestedbuild.Server) {
+	s.nested = srv
+}
+
+// vertexWorker returns the worker running the operation of the vertex. The
+// operations are dispatched by their platform, so that each platform of a
+// multi-platform build runs on a worker running it natively when there is one.
+func (s *Solver) vertexWorker(v solver.Vertex) (worker.Worker, error) {
+	if op, ok := v.Sys().(*pb.Op); ok && op.Platform != nil {
+		return s.platformWorker(op.Platform.Spec())
+	}
+	return s.resolveWorker()
+}
+
 func (s *Solver) Bridge(b solver.Builder) frontend.FrontendLLBBridge {
+	return s.bridge(b)
+}
+
+func (s *Solver) bridge(b solver.Builder) *llbBridge {
 	return &llbBridge{
 		builder:                   b,
 		frontends:                 s.frontends,
@@ -90,10 +170,24 @@ func (s *Solver) Bridge(b solver.Builder) frontend.FrontendLLBBridge {
 		resolveCacheImporterFuncs: s.resolveCacheImporterFuncs,
 		cms:                       map[string]solver.CacheManager{},
 		sm:                        s.sm,
+		networkSched:              s.networkSched,
+		allowedSockets:            s.allowedSockets,
+		buildInfoAttrs:            s.buildInfoAttrs,
+		speculation: &speculation{
+			budget: s.speculation,
+			seen:   map[digest.Digest]struct{}{},
+		},
 	}
 }
 
-func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req frontend.SolveRequest, exp ExporterRequest, ent []entitlements.Entitlement) (*client.SolveResponse, error) {
+// DryRun loads the definition of the request and reports which of its
+// vertexes would be loaded from the cache, without executing any of them.
+// Only requests with an LLB definition are supported.
+func (s *Solver) DryRun(ctx context.Context, id string, sessionID string, req frontend.SolveRequest, ent []entitlements.Entitlement) ([]*solver.VertexCacheStatus, error) {
+	if req.Definition == nil || req.Definition.Def == nil {
+		return nil, errors.New("dry run requires an LLB definition")
+	}
+
 	j, err := s.solver.NewJob(id)
 	if err != nil {
 		return nil, err
@@ -107,11 +201,53 @@ func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req fro
 	}
 	j.SetValue(keyEntitlements, set)
 
+	pol, err := s.sourcePolicy(req)
+	if err != nil {
+		return nil, err
+	}
+	j.SetValue(keySourcePolicy, pol)
+
 	j.SessionID = sessionID
 
+	b := s.bridge(j)
+	b.dryRun = true
+	edge, err := b.loadEdge(ctx, req.Definition, req.CacheImports)
+	if err != nil {
+		return nil, err
+	}
+	return j.CacheStatus(ctx, edge)
+}
+
+func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req frontend.SolveRequest, exp ExporterRequest, ent []entitlements.Entitlement, resources Resources) (*client.SolveResponse, error) {
+	j, err := s.solver.NewJob(id)
+	if err != nil {
+		return nil, err
+	}
+
+	defer j.Discard()
+
+	set, err := entitlements.WhiteList(ent, supportedEntitlements(s.entitlements))
+	if err != nil {
+		return nil, err
+	}
+	j.SetValue(keyEntitlements, set)
+	limiters := bandwidth.NewLimiters(resources.Bandwidth)
+	j.SetValue(keyResources, &buildResources{Resources: resources, id: id, limiters: limiters})
+	// the frontend and the exporters run with the solve context
+	ctx = bandwidth.WithLimiters(ctx, limiters)
+
+	pol, err := s.sourcePolicy(req)
+	if err != nil {
+		return nil, err
+	}
+	j.SetValue(keySourcePolicy, pol)
+
+	j.SessionID = sessionID
+
+	br := s.bridge(j)
 	var res *frontend.Result
 	if s.gatewayForwarder != nil && req.Definition == nil && req.Frontend == "" {
-		fwd := gateway.NewBridgeForwarder(ctx, s.Bridge(j), s.workerController, req.FrontendInputs, sessionID, s.sm)
+		fwd := gateway.NewBridgeForwarder(ctx, br, s.workerController, req.FrontendInputs, sessionID, s.sm)
 		defer fwd.Discard()
 		if err := s.gatewayForwarder.RegisterBuild(ctx, id, fwd); err != nil {
 			return nil, err
@@ -129,7 +265,7 @@ func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req fro
 			return nil, err
 		}
 	} else {
-		res, err = s.Bridge(j).Solve(ctx, req, sessionID)
+		res, err = br.Solve(ctx, req, sessionID)
 		if err != nil {
 			return nil, err
 		}
@@ -161,8 +297,14 @@ func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req fro
 	if res.Metadata == nil {
 		res.Metadata = make(map[string][]byte)
 	}
+	cacheInfo := buildCacheInfo(j, br, exp)
 	if r := res.Ref; r != nil {
-		dtbi, err := buildinfo.Encode(ctx, res.Metadata, exptypes.ExporterBuildInfo, r.BuildSources())
+		dtbi, err := buildinfo.Encode(ctx, res.Metadata, exptypes.ExporterBuildInfo, buildinfo.EncodeOpt{
+			BuildSources: r.BuildSources(),
+			Definition:   r.Definition(),
+			CacheInfo:    cacheInfo,
+			AttrsPolicy:  s.buildInfoAttrs,
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -175,7 +317,12 @@ func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req fro
 			if r == nil {
 				continue
 			}
-			dtbi, err := buildinfo.Encode(ctx, res.Metadata, fmt.Sprintf("%s/%s", exptypes.ExporterBuildInfo, k), r.BuildSources())
+			dtbi, err := buildinfo.Encode(ctx, res.Metadata, fmt.Sprintf("%s/%s", exptypes.ExporterBuildInfo, k), buildinfo.EncodeOpt{
+				BuildSources: r.BuildSources(),
+				Definition:   r.Definition(),
+				CacheInfo:    cacheInfo,
+				AttrsPolicy:  s.buildInfoAttrs,
+			})
 			if err != nil {
 				return nil, err
 			}
@@ -185,8 +332,12 @@ func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req fro
 		}
 	}
 
+	if j.Stopped() {
+		return nil, errors.Wrap(context.Canceled, "build stopped before exporting")
+	}
+
 	var exporterResponse map[string]string
-	if e := exp.Exporter; e != nil {
+	if len(exp.Exporters) > 0 {
 		inp := exporter.Source{
 			Metadata: res.Metadata,
 		}
@@ -227,24 +378,38 @@ func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req fro
 			}
 			inp.Refs = m
 		}
+
+		// every exporter gets its own copy of the metadata as inline cache
+		// depends on the compression configured for the exporter
+		inps := make([]exporter.Source, len(exp.Exporters))
+		for i := range exp.Exporters {
+			inps[i] = inp
+			inps[i].Metadata = make(map[string][]byte, len(inp.Metadata))
+			for k, v := range inp.Metadata {
+				inps[i].Metadata[k] = v
+			}
+		}
+
 		if _, ok := asInlineCache(exp.CacheExporter); ok {
 			if err := inBuilderContext(ctx, j, "preparing layers for inline cache", "", func(ctx context.Context, _ session.Group) error {
-				if cr != nil {
-					dtic, err := inlineCache(ctx, exp.CacheExporter, cr, e.Config().Compression, session.NewGroup(sessionID))
-					if err != nil {
-						return err
-					}
-					if dtic != nil {
-						inp.Metadata[exptypes.ExporterInlineCache] = dtic
-					}
-				}
-				for k, res := range crMap {
-					dtic, err := inlineCache(ctx, exp.CacheExporter, res, e.Config().Compression, session.NewGroup(sessionID))
-					if err != nil {
-						return err
+				for i, e := range exp.Exporters {
+					if cr != nil {
+						dtic, err := inlineCache(ctx, exp.CacheExporter, cr, e.Config().Compression, session.NewGroup(sessionID))
+						if err != nil {
+							return err
+						}
+						if dtic != nil {
+							inps[i].Metadata[exptypes.ExporterInlineCache] = dtic
+						}
 					}
-					if dtic != nil {
-						inp.Metadata[fmt.Sprintf("%s/%s", exptypes.ExporterInlineCache, k)] = dtic
+					for k, res := range crMap {
+						dtic, err := inlineCache(ctx, exp.CacheExporter, res, e.Config().Compression, session.NewGroup(sessionID))
+						if err != nil {
+							return err
+						}
+						if dtic != nil {
+							inps[i].Metadata[fmt.Sprintf("%s/%s", exptypes.ExporterInlineCache, k)] = dtic
+						}
 					}
 				}
 				exp.CacheExporter = nil
@@ -253,12 +418,68 @@ func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req fro
 				return nil, err
 			}
 		}
-		if err := inBuilderContext(ctx, j, e.Name(), "", func(ctx context.Context, _ session.Group) error {
-			exporterResponse, err = e.Export(ctx, inp, j.SessionID)
-			return err
-		}); err != nil {
+
+		// an exporter exports every named build output of the result for
+		// exptypes.OutputAll, one after another
+		srcs := make([][]exporter.Source, len(exp.Exporters))
+		srcNames := make([][]string, len(exp.Exporters))
+		for i := range exp.Exporters {
+			var name string
+			if i < len(exp.Outputs) {
+				name = exp.Outputs[i]
+			}
+			names := []string{name}
+			if name == exptypes.OutputAll {
+				var err error
+				names, err = outputNames(inps[i])
+				if err != nil {
+					return nil, err
+				}
+				srcNames[i] = names
+			}
+			for _, name := range names {
+				inp, err := selectOutput(inps[i], name)
+				if err != nil {
+					return nil, err
+				}
+				srcs[i] = append(srcs[i], inp)
+			}
+		}
+
+		resps := make([]map[string]string, len(exp.Exporters))
+		eg, ctx := errgroup.WithContext(ctx)
+		for i, e := range exp.Exporters {
+			i, e := i, e
+			eg.Go(func() error {
+				return inBuilderContext(ctx, j, e.Name(), fmt.Sprintf("%s-%d", e.Name(), e.ID()), func(ctx context.Context, _ session.Group) error {
+					resps[i] = map[string]string{}
+					for si, src := range srcs[i] {
+						resp, err := e.Export(ctx, src, j.SessionID)
+						if err != nil {
+							return err
+						}
+						for k, v := range resp {
+							// responses of all the outputs are keyed by output
+							if srcNames[i] != nil {
+								k += "/" + srcNames[i][si]
+							}
+							resps[i][k] = v
+						}
+					}
+					return nil
+				})
+			})
+		}
+		if err := eg.Wait(); err != nil {
 			return nil, err
 		}
+
+		exporterResponse = make(map[string]string)
+		for _, resp := range resps {
+			for k, v := range resp {
+				exporterResponse[k] = v
+			}
+		}
 	}
 
 	g := session.NewGroup(j.SessionID)
@@ -323,6 +544,23 @@ func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req fro
 	}, nil
 }
 
+// buildCacheInfo returns the cache backends used by the build and the cache
+// statistics of the job.
+func buildCacheInfo(j *solver.Job, br *llbBridge, exp ExporterRequest) *binfotypes.CacheInfo {
+	stats := j.CacheStats()
+	ci := &binfotypes.CacheInfo{
+		Imports:     br.importedCaches(),
+		Vertexes:    stats.Vertexes,
+		LocalHits:   stats.LocalHits,
+		RemoteHits:  stats.RemoteHits,
+		RemoteBytes: stats.RemoteBytes,
+	}
+	if e := exp.CacheExportEntry; e != nil && exp.CacheExporter != nil {
+		ci.Exports = append(ci.Exports, buildinfo.CacheRef(e.Type, e.Attrs))
+	}
+	return ci
+}
+
 type inlineCacheExporter interface {
 	ExportForLayers(context.Context, []digest.Digest) ([]byte, error)
 }
@@ -332,6 +570,65 @@ func asInlineCache(e remotecache.Exporter) (inlineCacheExporter, bool) {
 	return ie, ok
 }
 
+// outputNames returns the names of the named build outputs of a result.
+func outputNames(inp exporter.Source) ([]string, error) {
+	dt, ok := inp.Metadata[exptypes.ExporterOutputsKey]
+	if !ok {
+		return nil, errors.New("build result has no named outputs")
+	}
+	var outputs []string
+	if err := json.Unmarshal(dt, &outputs); err != nil {
+		return nil, errors.Wrap(err, "failed to parse build outputs")
+	}
+	if len(outputs) == 0 {
+		return nil, errors.New("build result has no named outputs")
+	}
+	return outputs, nil
+}
+
+// selectOutput returns the source for exporting the named build output of a
+// result with named outputs. Empty name selects the build target.
+func selectOutput(inp exporter.Source, name string) (exporter.Source, error) {
+	dt, ok := inp.Metadata[exptypes.ExporterOutputsKey]
+	if !ok {
+		if name != "" {
+			return inp, errors.Errorf("build result has no output %s", name)
+		}
+		return inp, nil
+	}
+	var outputs []string
+	if err := json.Unmarshal(dt, &outputs); err != nil {
+		return inp, errors.Wrap(err, "failed to parse build outputs")
+	}
+
+	key := exptypes.OutputTargetKey
+	if name != "" {
+		key = exptypes.OutputKey(name)
+	}
+	ref, ok := inp.Refs[key]
+	if !ok {
+		return inp, errors.Errorf("build output %s not found, available outputs: %s", name, strings.Join(outputs, ", "))
+	}
+
+	out := exporter.Source{
+		Ref:      ref,
+		Metadata: make(map[string][]byte, len(inp.Metadata)),
+	}
+	for k, v := range inp.Metadata {
+		if k != exptypes.ExporterOutputsKey {
+			out.Metadata[k] = v
+		}
+	}
+	// metadata of the selected ref replaces the metadata of the result
+	suffix := "/" + key
+	for k, v := range inp.Metadata {
+		if strings.HasSuffix(k, suffix) {
+			out.Metadata[strings.TrimSuffix(k, suffix)] = v
+		}
+	}
+	return out, nil
+}
+
 func inlineCache(ctx context.Context, e remotecache.Exporter, res solver.CachedResult, compressionopt compression.Config, g session.Group) ([]byte, error) {
 	ie, ok := asInlineCache(e)
 	if !ok {
@@ -380,6 +677,18 @@ func withDescHandlerCacheOpts(ctx context.Context, ref cache.ImmutableRef) conte
 	})
 }
 
+// Stop stops the build gracefully: no new operations are started and the
+// running processes receive SIGTERM. Exporters already running finish unless
+// the build is canceled, but a build stopped before exporting isn't exported.
+func (s *Solver) Stop(id, reason string) error {
+	j, err := s.solver.Get(id)
+	if err != nil {
+		return err
+	}
+	j.Stop(reason)
+	return nil
+}
+
 func (s *Solver) Status(ctx context.Context, id string, statusChan chan *client.SolveStatus) error {
 	j, err := s.solver.Get(id)
 	if err != nil {
@@ -473,6 +782,9 @@ func supportedEntitlements(ents []string) []entitlements.Entitlement {
 		if e == string(entitlements.EntitlementSecurityInsecure) {
 			out = append(out, entitlements.EntitlementSecurityInsecure)
 		}
+		if e == string(entitlements.EntitlementNestedBuild) {
+			out = append(out, entitlements.EntitlementNestedBuild)
+		}
 	}
 	return out
 }
@@ -0,0 +1,70 @@
+package llbsolver
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/cache"
+	"github.com/moby/buildkit/exporter"
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectOutput(t *testing.T) {
+	t.Parallel()
+
+	inp := exporter.Source{
+		Metadata: map[string][]byte{
+			"frontend.foo": []byte("foo"),
+		},
+	}
+	out, err := selectOutput(inp, "")
+	require.NoError(t, err)
+	require.Equal(t, inp, out)
+
+	_, err = selectOutput(inp, "bin")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "build result has no output bin")
+
+	inp = exporter.Source{
+		Refs: map[string]cache.ImmutableRef{
+			exptypes.OutputTargetKey:  nil,
+			exptypes.OutputKey("bin"): nil,
+		},
+		Metadata: map[string][]byte{
+			"frontend.foo":              []byte("foo"),
+			exptypes.ExporterOutputsKey: []byte(`["bin"]`),
+			exptypes.ExporterImageConfigKey + "/" + exptypes.OutputTargetKey:  []byte("target"),
+			exptypes.ExporterImageConfigKey + "/" + exptypes.OutputKey("bin"): []byte("bin"),
+		},
+	}
+
+	out, err = selectOutput(inp, "")
+	require.NoError(t, err)
+	require.Nil(t, out.Refs)
+	require.Equal(t, "target", string(out.Metadata[exptypes.ExporterImageConfigKey]))
+	require.Equal(t, "foo", string(out.Metadata["frontend.foo"]))
+	require.NotContains(t, out.Metadata, exptypes.ExporterOutputsKey)
+
+	out, err = selectOutput(inp, "bin")
+	require.NoError(t, err)
+	require.Equal(t, "bin", string(out.Metadata[exptypes.ExporterImageConfigKey]))
+
+	_, err = selectOutput(inp, "docs")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "available outputs: bin")
+}
+
+func TestOutputNames(t *testing.T) {
+	t.Parallel()
+
+	_, err := outputNames(exporter.Source{})
+	require.Error(t, err)
+
+	names, err := outputNames(exporter.Source{
+		Metadata: map[string][]byte{
+			exptypes.ExporterOutputsKey: []byte(`["web","db"]`),
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"web", "db"}, names)
+}
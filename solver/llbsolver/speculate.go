@@ -0,0 +1,120 @@
+package llbsolver
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/worker"
+	digest "github.com/opencontainers/go-digest"
+)
+
+// speculationBudget limits the number of speculative fetches running at a
+// time across builds. A limit of zero disables speculation.
+type speculationBudget struct {
+	running int64
+	limit   int64
+}
+
+func (s *speculationBudget) setLimit(n int) {
+	atomic.StoreInt64(&s.limit, int64(n))
+}
+
+func (s *speculationBudget) getLimit() int {
+	return int(atomic.LoadInt64(&s.limit))
+}
+
+// tryAcquire takes a slot of the budget if one is free. Fetches are not
+// queued, a fetch that does not fit the budget is left to the build.
+func (s *speculationBudget) tryAcquire() bool {
+	if atomic.AddInt64(&s.running, 1) > atomic.LoadInt64(&s.limit) {
+		atomic.AddInt64(&s.running, -1)
+		return false
+	}
+	return true
+}
+
+func (s *speculationBudget) release() {
+	atomic.AddInt64(&s.running, -1)
+}
+
+// speculation tracks the vertexes a bridge has started fetching.
+type speculation struct {
+	budget *speculationBudget
+	mu     sync.Mutex
+	seen   map[digest.Digest]struct{}
+}
+
+// speculate starts fetching the network sources of the edge in the
+// background while the build computes the cache keys of the vertexes using
+// them. Image layers are pulled and git repositories cloned before a cache
+// miss needs them, shortening the critical path of partially cached builds.
+// Sources that turn out not to be needed are fetched in vain, the budget
+// bounds the cost of that.
+func (b *llbBridge) speculate(ctx context.Context, edge solver.Edge) {
+	if b.speculation == nil || b.speculation.budget.getLimit() <= 0 {
+		return
+	}
+	for _, v := range networkSources(edge.Vertex) {
+		b.speculation.mu.Lock()
+		_, seen := b.speculation.seen[v.Digest()]
+		b.speculation.seen[v.Digest()] = struct{}{}
+		b.speculation.mu.Unlock()
+		if seen {
+			continue
+		}
+		if !b.speculation.budget.tryAcquire() {
+			return
+		}
+		go func(v solver.Vertex) {
+			defer b.speculation.budget.release()
+			if err := b.fetch(ctx, v); err != nil {
+				bklog.G(ctx).Debugf("speculative fetch of %s failed: %v", v.Name(), err)
+			}
+		}(v)
+	}
+}
+
+// fetch builds the source vertex and pulls the content of its result.
+func (b *llbBridge) fetch(ctx context.Context, v solver.Vertex) error {
+	res, _, err := b.builder.Build(ctx, solver.Edge{Vertex: v})
+	if err != nil {
+		return err
+	}
+	defer res.Release(context.TODO())
+	workerRef, ok := res.Sys().(*worker.WorkerRef)
+	if !ok || workerRef.ImmutableRef == nil {
+		return nil
+	}
+	return b.builder.InContext(ctx, func(ctx context.Context, g session.Group) error {
+		return workerRef.ImmutableRef.Extract(ctx, g)
+	})
+}
+
+// networkSources returns the vertexes of the graph that fetch sources over
+// the network.
+func networkSources(v solver.Vertex) []solver.Vertex {
+	var out []solver.Vertex
+	visited := map[digest.Digest]struct{}{}
+	var walk func(v solver.Vertex)
+	walk = func(v solver.Vertex) {
+		if _, ok := visited[v.Digest()]; ok {
+			return
+		}
+		visited[v.Digest()] = struct{}{}
+		if op, ok := v.Sys().(*pb.Op); ok {
+			if src, ok := op.Op.(*pb.Op_Source); ok && isNetworkSource(src.Source.Identifier) {
+				out = append(out, v)
+			}
+		}
+		for _, inp := range v.Inputs() {
+			walk(inp.Vertex)
+		}
+	}
+	walk(v)
+	return out
+}
@@ -0,0 +1,25 @@
+package llbsolver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpeculationBudget(t *testing.T) {
+	b := &speculationBudget{}
+	require.False(t, b.tryAcquire())
+
+	b.setLimit(2)
+	require.True(t, b.tryAcquire())
+	require.True(t, b.tryAcquire())
+	require.False(t, b.tryAcquire())
+
+	b.release()
+	require.True(t, b.tryAcquire())
+
+	b.setLimit(0)
+	b.release()
+	b.release()
+	require.False(t, b.tryAcquire())
+}
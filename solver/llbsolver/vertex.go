@@ -2,12 +2,16 @@ package llbsolver
 
 import (
 	"fmt"
+	"path"
 	"strings"
+	"time"
 
 	"github.com/containerd/containerd/platforms"
 	"github.com/moby/buildkit/solver"
 	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/source"
+	srctypes "github.com/moby/buildkit/source/types"
+	"github.com/moby/buildkit/util/audit"
 	"github.com/moby/buildkit/util/entitlements"
 	digest "github.com/opencontainers/go-digest"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
@@ -68,6 +72,56 @@ func WithCacheSources(cms []solver.CacheManager) LoadOpt {
 	}
 }
 
+// WithTenant isolates the vertexes of the tenant from the vertexes of other
+// tenants. With shareImages, the image sources are still shared.
+func WithTenant(name string, shareImages bool) LoadOpt {
+	return func(op *pb.Op, _ *pb.OpMetadata, opt *solver.VertexOptions) error {
+		if name == "" {
+			return nil
+		}
+		if src := op.GetSource(); src != nil && shareImages && strings.HasPrefix(src.Identifier, srctypes.DockerImageScheme+"://") {
+			return nil
+		}
+		opt.Namespace = "tenant/" + name
+		return nil
+	}
+}
+
+// RecordUsage records the sources, the secrets and the SSH IDs of the
+// operations in the audit usage of the build.
+func RecordUsage(u *audit.Usage) LoadOpt {
+	return func(op *pb.Op, _ *pb.OpMetadata, opt *solver.VertexOptions) error {
+		if u == nil {
+			return nil
+		}
+		switch op := op.Op.(type) {
+		case *pb.Op_Source:
+			u.AddSource(op.Source.Identifier)
+			for _, k := range []string{pb.AttrAuthHeaderSecret, pb.AttrAuthTokenSecret, pb.AttrHTTPAuthHeaderSecret, pb.AttrHTTPAuthTokenSecret, pb.AttrObjectStoreSecret} {
+				if id := op.Source.Attrs[k]; id != "" {
+					u.AddSecret(id)
+				}
+			}
+			if id := op.Source.Attrs[pb.AttrMountSSHSock]; id != "" {
+				u.AddSSH(id)
+			}
+		case *pb.Op_Exec:
+			for _, m := range op.Exec.Mounts {
+				switch {
+				case m.MountType == pb.MountType_SECRET && m.SecretOpt != nil:
+					u.AddSecret(m.SecretOpt.ID)
+				case m.MountType == pb.MountType_SSH && m.SSHOpt != nil:
+					u.AddSSH(m.SSHOpt.ID)
+				}
+			}
+			for _, se := range op.Exec.Secretenv {
+				u.AddSecret(se.ID)
+			}
+		}
+		return nil
+	}
+}
+
 func NormalizeRuntimePlatforms() LoadOpt {
 	var defaultPlatform *pb.Platform
 	return func(op *pb.Op, _ *pb.OpMetadata, opt *solver.VertexOptions) error {
@@ -110,11 +164,57 @@ func ValidateEntitlements(ent entitlements.Set) LoadOpt {
 					return errors.Errorf("%s is not allowed", entitlements.EntitlementSecurityInsecure)
 				}
 			}
+
+			// the AppArmor profiles loaded on the host are not all safe,
+			// only the profile configured for the worker is allowed
+			if meta := op.Exec.Meta; meta != nil && (meta.SeccompProfile == pb.SecurityProfileUnconfined || (meta.ApparmorProfile != "" && meta.ApparmorProfile != pb.ApparmorProfileDefault)) {
+				if !ent.Allowed(entitlements.EntitlementSecurityInsecure) {
+					return errors.Errorf("%s is not allowed", entitlements.EntitlementSecurityInsecure)
+				}
+			}
+
+			for _, m := range op.Exec.Mounts {
+				if m.MountType == pb.MountType_SSH && m.SSHOpt != nil && m.SSHOpt.ID == pb.SSHNestedBuildID {
+					if !ent.Allowed(entitlements.EntitlementNestedBuild) {
+						return errors.Errorf("%s is not allowed", entitlements.EntitlementNestedBuild)
+					}
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// ValidateSockets rejects the exec operations mounting client sockets that are
+// not allowed. The allowed socket IDs are path.Match patterns.
+func ValidateSockets(allowed []string) LoadOpt {
+	return func(op *pb.Op, _ *pb.OpMetadata, opt *solver.VertexOptions) error {
+		exec, ok := op.Op.(*pb.Op_Exec)
+		if !ok {
+			return nil
+		}
+		for _, m := range exec.Exec.Mounts {
+			if m.MountType != pb.MountType_SSH || m.SSHOpt == nil || !strings.HasPrefix(m.SSHOpt.ID, pb.SSHSocketIDPrefix) {
+				continue
+			}
+			id := strings.TrimPrefix(m.SSHOpt.ID, pb.SSHSocketIDPrefix)
+			if !socketAllowed(allowed, id) {
+				return errors.Errorf("forwarding socket %s is not allowed by the daemon configuration", id)
+			}
 		}
 		return nil
 	}
 }
 
+func socketAllowed(allowed []string, id string) bool {
+	for _, p := range allowed {
+		if ok, _ := path.Match(p, id); ok {
+			return true
+		}
+	}
+	return false
+}
+
 type detectPrunedCacheID struct {
 	ids map[string]struct{}
 }
@@ -163,6 +263,22 @@ func newVertex(dgst digest.Digest, op *pb.Op, opMeta *pb.OpMetadata, load func(d
 			opt.ExportCache = &opMeta.ExportCache.Value
 		}
 		opt.ProgressGroup = opMeta.ProgressGroup
+		opt.Timeout = time.Duration(opMeta.Timeout)
+		if opMeta.Retry != nil {
+			if opMeta.Retry.Attempts < 0 {
+				return nil, errors.Errorf("invalid retry attempts %d", opMeta.Retry.Attempts)
+			}
+			if opMeta.Retry.Delay < 0 {
+				return nil, errors.Errorf("invalid retry delay %v", time.Duration(opMeta.Retry.Delay))
+			}
+			// larger attempt counts are reduced to the maximum, the delay is
+			// capped by the solver
+			opt.Retries = int(opMeta.Retry.Attempts)
+			if opMeta.Retry.Attempts > solver.MaxRetries {
+				opt.Retries = solver.MaxRetries
+			}
+			opt.RetryDelay = time.Duration(opMeta.Retry.Delay)
+		}
 	}
 	for _, fn := range opts {
 		if err := fn(op, opMeta, &opt); err != nil {
@@ -368,6 +484,12 @@ func fileOpName(actions []*pb.FileAction) string {
 			names = append(names, fmt.Sprintf("mkfile %s", a.Mkfile.Path))
 		case *pb.FileAction_Rm:
 			names = append(names, fmt.Sprintf("rm %s", a.Rm.Path))
+		case *pb.FileAction_Symlink:
+			names = append(names, fmt.Sprintf("symlink %s -> %s", a.Symlink.Newpath, a.Symlink.Oldpath))
+		case *pb.FileAction_Link:
+			names = append(names, fmt.Sprintf("link %s %s", a.Link.Oldpath, a.Link.Newpath))
+		case *pb.FileAction_Setattr:
+			names = append(names, fmt.Sprintf("setattr %s", a.Setattr.Path))
 		case *pb.FileAction_Copy:
 			names = append(names, fmt.Sprintf("copy %s %s", a.Copy.Src, a.Copy.Dest))
 		}
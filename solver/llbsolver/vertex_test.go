@@ -0,0 +1,125 @@
+package llbsolver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/audit"
+	"github.com/moby/buildkit/util/entitlements"
+	"github.com/stretchr/testify/require"
+)
+
+func sshMountsOp(ids ...string) *pb.Op {
+	exec := &pb.ExecOp{}
+	for _, id := range ids {
+		exec.Mounts = append(exec.Mounts, &pb.Mount{
+			MountType: pb.MountType_SSH,
+			SSHOpt:    &pb.SSHOpt{ID: id},
+		})
+	}
+	return &pb.Op{Op: &pb.Op_Exec{Exec: exec}}
+}
+
+func TestValidateSockets(t *testing.T) {
+	op := sshMountsOp
+
+	validate := ValidateSockets([]string{"docker", "gpg-*"})
+	require.NoError(t, validate(op("default", pb.SSHSocketIDPrefix+"docker"), nil, nil))
+	require.NoError(t, validate(op(pb.SSHSocketIDPrefix+"gpg-agent"), nil, nil))
+	require.Error(t, validate(op(pb.SSHSocketIDPrefix+"pcscd"), nil, nil))
+
+	require.Error(t, ValidateSockets(nil)(op(pb.SSHSocketIDPrefix+"docker"), nil, nil))
+	require.NoError(t, ValidateSockets(nil)(op("default"), nil, nil))
+}
+
+func TestValidateNestedBuildEntitlement(t *testing.T) {
+	op := sshMountsOp("default", pb.SSHNestedBuildID)
+
+	err := ValidateEntitlements(entitlements.Set{})(op, nil, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "nested.build is not allowed")
+
+	require.NoError(t, ValidateEntitlements(entitlements.Set{entitlements.EntitlementNestedBuild: {}})(op, nil, nil))
+	require.NoError(t, ValidateEntitlements(entitlements.Set{})(sshMountsOp("default"), nil, nil))
+}
+
+func TestValidateSecurityProfileEntitlement(t *testing.T) {
+	op := func(seccomp, apparmor string) *pb.Op {
+		return &pb.Op{Op: &pb.Op_Exec{Exec: &pb.ExecOp{Meta: &pb.Meta{
+			SeccompProfile:  seccomp,
+			ApparmorProfile: apparmor,
+		}}}}
+	}
+	insecure := entitlements.Set{entitlements.EntitlementSecurityInsecure: {}}
+
+	for _, tc := range []struct {
+		seccomp  string
+		apparmor string
+		allowed  bool
+	}{
+		{allowed: true},
+		{seccomp: pb.SeccompProfileDefault, apparmor: pb.ApparmorProfileDefault, allowed: true},
+		{seccomp: pb.SecurityProfileUnconfined},
+		{apparmor: pb.SecurityProfileUnconfined},
+		{apparmor: "buildkit-custom"},
+	} {
+		err := ValidateEntitlements(entitlements.Set{})(op(tc.seccomp, tc.apparmor), nil, nil)
+		if tc.allowed {
+			require.NoError(t, err)
+		} else {
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "security.insecure is not allowed")
+		}
+		require.NoError(t, ValidateEntitlements(insecure)(op(tc.seccomp, tc.apparmor), nil, nil))
+	}
+}
+
+func TestRecordUsage(t *testing.T) {
+	u := &audit.Usage{}
+	record := RecordUsage(u)
+
+	op := sshMountsOp("default")
+	exec := op.GetExec()
+	exec.Mounts = append(exec.Mounts, &pb.Mount{MountType: pb.MountType_SECRET, SecretOpt: &pb.SecretOpt{ID: "npmrc"}})
+	exec.Secretenv = []*pb.SecretEnv{{ID: "token", Name: "TOKEN"}}
+	require.NoError(t, record(op, nil, nil))
+	require.NoError(t, record(&pb.Op{Op: &pb.Op_Source{Source: &pb.SourceOp{
+		Identifier: "git://github.com/moby/buildkit",
+		Attrs:      map[string]string{pb.AttrAuthTokenSecret: "GIT_AUTH_TOKEN"},
+	}}}, nil, nil))
+
+	var rec audit.Record
+	u.Fill(&rec)
+	require.Equal(t, []string{"git://github.com/moby/buildkit"}, rec.Sources)
+	require.Equal(t, []string{"GIT_AUTH_TOKEN", "npmrc", "token"}, rec.Secrets)
+	require.Equal(t, []string{"default"}, rec.SSH)
+
+	require.NoError(t, RecordUsage(nil)(op, nil, nil))
+}
+
+func TestNewVertexRetryPolicy(t *testing.T) {
+	op := &pb.Op{Op: &pb.Op_Source{Source: &pb.SourceOp{Identifier: "local://context"}}}
+	md := func(attempts int64, delay time.Duration) *pb.OpMetadata {
+		return &pb.OpMetadata{Retry: &pb.RetryPolicy{Attempts: attempts, Delay: int64(delay)}}
+	}
+
+	v, err := newVertex("", op, md(3, time.Second), nil)
+	require.NoError(t, err)
+	require.Equal(t, 3, v.Options().Retries)
+	require.Equal(t, time.Second, v.Options().RetryDelay)
+
+	// the attempts are capped
+	v, err = newVertex("", op, md(solver.MaxRetries+1, time.Second), nil)
+	require.NoError(t, err)
+	require.Equal(t, solver.MaxRetries, v.Options().Retries)
+
+	_, err = newVertex("", op, md(-1, time.Second), nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid retry attempts")
+
+	_, err = newVertex("", op, md(1, -time.Second), nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid retry delay")
+}
@@ -6,6 +6,11 @@ const AttrAuthHeaderSecret = "git.authheadersecret"
 const AttrAuthTokenSecret = "git.authtokensecret"
 const AttrKnownSSHHosts = "git.knownsshhosts"
 const AttrMountSSHSock = "git.mountsshsock"
+const AttrGitSkipSubmodules = "git.skipsubmodules"
+const AttrGitLFS = "git.lfs"
+const AttrGitDepth = "git.depth"
+const AttrGitFilter = "git.filter"
+const AttrGitSparsePaths = "git.sparsepaths"
 const AttrLocalSessionID = "local.session"
 const AttrLocalUniqueID = "local.unique"
 const AttrIncludePatterns = "local.includepattern"
@@ -20,12 +25,47 @@ const AttrHTTPFilename = "http.filename"
 const AttrHTTPPerm = "http.perm"
 const AttrHTTPUID = "http.uid"
 const AttrHTTPGID = "http.gid"
+const AttrHTTPHeaderPrefix = "http.header."
+const AttrHTTPAuthHeaderSecret = "http.authheadersecret"
+const AttrHTTPAuthTokenSecret = "http.authtokensecret"
+const AttrHTTPProxy = "http.proxy.http"
+const AttrHTTPSProxy = "http.proxy.https"
+const AttrHTTPNoProxy = "http.proxy.no"
+
+// AttrHTTPUnpack extracts the downloaded archive instead of saving the file.
+// AttrHTTPUnpackStripComponents and AttrHTTPUnpackSubpath select the files of
+// the archive that are extracted.
+const AttrHTTPUnpack = "http.unpack"
+const AttrHTTPUnpackStripComponents = "http.unpack.stripcomponents"
+const AttrHTTPUnpackSubpath = "http.unpack.subpath"
+
+// AttrHTTPUnpackAuto detects the format of the archive from its contents and
+// saves the files that are not archives as they are.
+const AttrHTTPUnpackAuto = "auto"
+const AttrHTTPUnpackTar = "tar"
+const AttrHTTPUnpackZip = "zip"
+
+// AttrHTTPUnpackDecompress decompresses a compressed file that is not an
+// archive.
+const AttrHTTPUnpackDecompress = "decompress"
 
 const AttrImageResolveMode = "image.resolvemode"
 const AttrImageResolveModeDefault = "default"
 const AttrImageResolveModeForcePull = "pull"
 const AttrImageResolveModePreferLocal = "local"
+const AttrImageResolveModeLocalStore = "local-store"
 const AttrImageRecordType = "image.recordtype"
+const AttrImageVerifyKeys = "image.verify.keys"
+const AttrImageVerifyAttestations = "image.verify.attestations"
+
+const AttrOCILayoutSessionID = "oci.session"
+const AttrOCILayoutStoreID = "oci.store"
+
+const AttrObjectStoreChecksum = "objectstore.checksum"
+const AttrObjectStoreFilename = "objectstore.filename"
+const AttrObjectStoreSecret = "objectstore.secret"
+const AttrObjectStoreRegion = "objectstore.region"
+const AttrObjectStoreEndpoint = "objectstore.endpoint"
 
 const AttrLocalDiffer = "local.differ"
 const AttrLocalDifferNone = "none"
@@ -23,3 +23,24 @@ const LLBDefinitionInput = "buildkit.llb.definition"
 
 // LLBDefaultDefinitionFile is a filename containing the definition in LLBBuilder
 const LLBDefaultDefinitionFile = LLBDefinitionInput
+
+// SecurityProfileUnconfined disables the seccomp or AppArmor confinement of
+// the process. It requires the security.insecure entitlement.
+const SecurityProfileUnconfined = "unconfined"
+
+// SeccompProfileDefault is the default seccomp profile of the sandbox
+const SeccompProfileDefault = "default"
+
+// ApparmorProfileDefault is the AppArmor profile configured for the worker.
+// Other AppArmor profiles require the security.insecure entitlement.
+const ApparmorProfileDefault = "default"
+
+// SSHSocketIDPrefix prefixes the IDs of the SSH mounts that forward a socket of
+// the client instead of an SSH agent. The connections are copied to the socket
+// as they are.
+const SSHSocketIDPrefix = "socket:"
+
+// SSHNestedBuildID is the ID of the SSH mounts that serve the BuildKit API of
+// the daemon to the container, so that it can run nested builds. It requires
+// the nested.build entitlement.
+const SSHNestedBuildID = "buildkit:nested"
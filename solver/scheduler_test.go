@@ -11,8 +11,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/identity"
 	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/solver/errdefs"
 	digest "github.com/opencontainers/go-digest"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
@@ -2026,6 +2028,94 @@ func TestCacheWithSelector(t *testing.T) {
 	j2 = nil
 }
 
+func TestPreprocessOnlyOnExec(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	s := NewSolver(SolverOpt{
+		ResolveOpFunc: testOpResolver,
+	})
+	defer s.Close()
+
+	var preprocessCount int64
+	newEdge := func(value string) Edge {
+		return Edge{
+			Vertex: vtx(vtxOpt{
+				name:         "v0",
+				cacheKeySeed: "seed0",
+				value:        value,
+				inputs: []Edge{
+					{Vertex: vtx(vtxOpt{
+						name:         "v1",
+						cacheKeySeed: "seed1",
+						value:        "result1",
+					})},
+					{Vertex: vtx(vtxOpt{
+						name:         "v2",
+						cacheKeySeed: "seed2",
+						value:        "result2",
+					})},
+				},
+				preprocess: map[int]PreprocessFunc{
+					0: func(ctx context.Context, res Result, g session.Group) error {
+						require.Equal(t, "result1", unwrap(res))
+						atomic.AddInt64(&preprocessCount, 1)
+						return nil
+					},
+				},
+				slowCacheCompute: map[int]ResultBasedCacheFunc{
+					1: digestFromResult,
+				},
+			}),
+		}
+	}
+
+	j0, err := s.NewJob("job0")
+	require.NoError(t, err)
+
+	defer func() {
+		if j0 != nil {
+			j0.Discard()
+		}
+	}()
+
+	g0 := newEdge("result0")
+	g0.Vertex.(*vertex).setupCallCounters()
+
+	res, _, err := j0.Build(ctx, g0)
+	require.NoError(t, err)
+	require.Equal(t, "result0", unwrap(res))
+	require.Equal(t, int64(3), *g0.Vertex.(*vertex).execCallCount)
+	require.Equal(t, int64(1), atomic.LoadInt64(&preprocessCount))
+
+	require.NoError(t, j0.Discard())
+	j0 = nil
+
+	// the second input is loaded from the cache to compute its content
+	// based key, the first input is not preprocessed as the op is loaded
+	// from the cache
+	j1, err := s.NewJob("job1")
+	require.NoError(t, err)
+
+	defer func() {
+		if j1 != nil {
+			j1.Discard()
+		}
+	}()
+
+	g1 := newEdge("result0-cached")
+	g1.Vertex.(*vertex).setupCallCounters()
+
+	res, _, err = j1.Build(ctx, g1)
+	require.NoError(t, err)
+	require.Equal(t, "result0", unwrap(res))
+	require.Equal(t, int64(0), *g1.Vertex.(*vertex).execCallCount)
+	require.Equal(t, int64(1), atomic.LoadInt64(&preprocessCount))
+
+	require.NoError(t, j1.Discard())
+	j1 = nil
+}
+
 func TestCacheSlowWithSelector(t *testing.T) {
 	t.Parallel()
 	ctx := context.TODO()
@@ -3419,6 +3509,124 @@ func generateSubGraph(nodes int) (Edge, int) {
 	return Edge{Vertex: vtxSum(extra, vtxOpt{inputs: inputs})}, value
 }
 
+func TestExecRetry(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	s := NewSolver(SolverOpt{
+		ResolveOpFunc: testOpResolver,
+	})
+	defer s.Close()
+
+	j0, err := s.NewJob("job0")
+	require.NoError(t, err)
+	defer j0.Discard()
+
+	var attempts int64
+	g0 := Edge{
+		Vertex: vtx(vtxOpt{
+			name:    "v0",
+			value:   "result0",
+			retries: 2,
+			execPreFunc: func(context.Context) error {
+				if atomic.AddInt64(&attempts, 1) < 3 {
+					return errors.New("flaky")
+				}
+				return nil
+			},
+		}),
+	}
+
+	res, _, err := j0.Build(ctx, g0)
+	require.NoError(t, err)
+	require.Equal(t, "result0", unwrap(res))
+	require.Equal(t, int64(3), atomic.LoadInt64(&attempts))
+
+	j1, err := s.NewJob("job1")
+	require.NoError(t, err)
+	defer j1.Discard()
+
+	attempts = 0
+	g1 := Edge{
+		Vertex: vtx(vtxOpt{
+			name:    "v1",
+			retries: 1,
+			execPreFunc: func(context.Context) error {
+				atomic.AddInt64(&attempts, 1)
+				return errors.New("broken")
+			},
+		}),
+	}
+
+	_, _, err = j1.Build(ctx, g1)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "broken")
+	require.Equal(t, int64(2), atomic.LoadInt64(&attempts))
+
+	j2, err := s.NewJob("job2")
+	require.NoError(t, err)
+	defer j2.Discard()
+
+	// the retries are capped
+	attempts = 0
+	g2 := Edge{
+		Vertex: vtx(vtxOpt{
+			name:    "v2",
+			retries: 1000,
+			execPreFunc: func(context.Context) error {
+				atomic.AddInt64(&attempts, 1)
+				return errors.New("broken")
+			},
+		}),
+	}
+
+	_, _, err = j2.Build(ctx, g2)
+	require.Error(t, err)
+	require.Equal(t, int64(MaxRetries+1), atomic.LoadInt64(&attempts))
+}
+
+func TestNextRetryDelay(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, 2*time.Second, nextRetryDelay(time.Second))
+	require.Equal(t, MaxRetryDelay, nextRetryDelay(MaxRetryDelay/2+time.Second))
+	require.Equal(t, MaxRetryDelay, nextRetryDelay(MaxRetryDelay))
+
+	d := time.Second
+	for i := 0; i < 100; i++ {
+		d = nextRetryDelay(d)
+	}
+	require.Equal(t, MaxRetryDelay, d)
+}
+
+func TestExecTimeout(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	s := NewSolver(SolverOpt{
+		ResolveOpFunc: testOpResolver,
+	})
+	defer s.Close()
+
+	j0, err := s.NewJob("job0")
+	require.NoError(t, err)
+	defer j0.Discard()
+
+	g0 := Edge{
+		Vertex: vtx(vtxOpt{
+			name:      "v0",
+			execDelay: time.Minute,
+			timeout:   50 * time.Millisecond,
+		}),
+	}
+
+	_, _, err = j0.Build(ctx, g0)
+	require.Error(t, err)
+	var te *errdefs.TimeoutError
+	require.True(t, errors.As(err, &te))
+	require.Equal(t, int64(50*time.Millisecond), te.Duration)
+	require.Contains(t, err.Error(), "timed out after 50ms")
+}
+
 type vtxOpt struct {
 	name             string
 	cacheKeySeed     string
@@ -3430,9 +3638,13 @@ type vtxOpt struct {
 	inputs           []Edge
 	value            string
 	slowCacheCompute map[int]ResultBasedCacheFunc
+	preprocess       map[int]PreprocessFunc
 	selectors        map[int]digest.Digest
 	cacheSource      CacheManager
 	ignoreCache      bool
+	timeout          time.Duration
+	retries          int
+	namespace        string
 }
 
 func vtx(opt vtxOpt) *vertex {
@@ -3472,6 +3684,9 @@ func (v *vertex) Options() VertexOptions {
 	return VertexOptions{
 		CacheSources: cache,
 		IgnoreCache:  v.opt.ignoreCache,
+		Timeout:      v.opt.timeout,
+		Retries:      v.opt.retries,
+		Namespace:    v.opt.namespace,
 	}
 }
 
@@ -3584,6 +3799,9 @@ func (v *vertex) makeCacheMap() *CacheMap {
 	for i, f := range v.opt.slowCacheCompute {
 		m.Deps[i].ComputeDigestFunc = f
 	}
+	for i, f := range v.opt.preprocess {
+		m.Deps[i].PreprocessFunc = f
+	}
 	for i, dgst := range v.opt.selectors {
 		m.Deps[i].Selector = dgst
 	}
@@ -3859,3 +4077,227 @@ func (r *testExporterRecord) LinkFrom(src CacheExporterRecord, index int, select
 		}
 	}
 }
+
+func TestCacheStats(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	cacheManager := NewInMemoryCacheManager()
+
+	l := NewSolver(SolverOpt{
+		ResolveOpFunc: testOpResolver,
+		DefaultCache:  cacheManager,
+	})
+	defer l.Close()
+
+	graph := func(cacheSource CacheManager) Edge {
+		return Edge{
+			Vertex: vtx(vtxOpt{
+				name:         "v0",
+				cacheKeySeed: "seed0",
+				value:        "result0",
+				cacheSource:  cacheSource,
+				inputs: []Edge{
+					{Vertex: vtx(vtxOpt{
+						name:         "v1",
+						cacheKeySeed: "seed1",
+						value:        "result1",
+						cacheSource:  cacheSource,
+					})},
+				},
+			}),
+		}
+	}
+
+	j0, err := l.NewJob("j0")
+	require.NoError(t, err)
+	_, _, err = j0.Build(ctx, graph(nil))
+	require.NoError(t, err)
+	require.Equal(t, CacheStats{Vertexes: 2}, j0.CacheStats())
+	require.NoError(t, j0.Discard())
+
+	// only the result of the last vertex is loaded
+	j1, err := l.NewJob("j1")
+	require.NoError(t, err)
+	_, _, err = j1.Build(ctx, graph(nil))
+	require.NoError(t, err)
+	require.Equal(t, CacheStats{Vertexes: 1, LocalHits: 1}, j1.CacheStats())
+	require.NoError(t, j1.Discard())
+
+	l2 := NewSolver(SolverOpt{
+		ResolveOpFunc: testOpResolver,
+		DefaultCache:  NewInMemoryCacheManager(),
+	})
+	defer l2.Close()
+
+	j2, err := l2.NewJob("j2")
+	require.NoError(t, err)
+	_, _, err = j2.Build(ctx, graph(cacheManager))
+	require.NoError(t, err)
+	require.Equal(t, CacheStats{Vertexes: 1, RemoteHits: 1}, j2.CacheStats())
+	require.NoError(t, j2.Discard())
+}
+
+func TestCacheMiss(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	l := NewSolver(SolverOpt{
+		ResolveOpFunc: testOpResolver,
+		DefaultCache:  NewInMemoryCacheManager(),
+	})
+	defer l.Close()
+
+	graph := func(seed string, ignoreCache bool) Edge {
+		return Edge{
+			Vertex: vtx(vtxOpt{
+				name:         "v0",
+				cacheKeySeed: seed,
+				value:        "result0",
+				ignoreCache:  ignoreCache,
+				inputs: []Edge{
+					{Vertex: vtx(vtxOpt{
+						name:         "v1",
+						cacheKeySeed: "seed1",
+						value:        "result1",
+					})},
+				},
+			}),
+		}
+	}
+
+	// build returns the cache misses of the vertexes by name
+	build := func(e Edge) map[string]*client.CacheMiss {
+		j, err := l.NewJob(identity.NewID())
+		require.NoError(t, err)
+		ch := make(chan *client.SolveStatus)
+		done := make(chan map[string]*client.CacheMiss)
+		go func() {
+			misses := map[string]*client.CacheMiss{}
+			for ss := range ch {
+				for _, v := range ss.Vertexes {
+					misses[v.Name] = v.CacheMiss
+				}
+			}
+			done <- misses
+		}()
+		go j.Status(ctx, ch)
+		_, _, err = j.Build(ctx, e)
+		require.NoError(t, err)
+		require.NoError(t, j.Discard())
+		return <-done
+	}
+
+	misses := build(graph("seed0", false))
+	require.Equal(t, client.CacheMissDefinition, misses["v1"].Reason)
+	require.Equal(t, -1, misses["v1"].Input)
+	require.NotEmpty(t, misses["v1"].Digest)
+	require.Equal(t, client.CacheMissInput, misses["v0"].Reason)
+	require.Equal(t, 0, misses["v0"].Input)
+	require.Contains(t, misses["v0"].Message, `"v1" was rebuilt`)
+
+	misses = build(graph("seed0-changed", false))
+	require.Nil(t, misses["v1"])
+	require.Equal(t, client.CacheMissDefinition, misses["v0"].Reason)
+	require.Equal(t, -1, misses["v0"].Input)
+
+	misses = build(graph("seed0", true))
+	require.Equal(t, client.CacheMissIgnoreCache, misses["v0"].Reason)
+}
+
+func TestJobStop(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	l := NewSolver(SolverOpt{
+		ResolveOpFunc: testOpResolver,
+	})
+	defer l.Close()
+
+	j0, err := l.NewJob("job0")
+	require.NoError(t, err)
+	defer j0.Discard()
+
+	started := make(chan struct{})
+	g0 := Edge{
+		Vertex: vtx(vtxOpt{
+			name:  "v0",
+			value: "result0",
+			inputs: []Edge{
+				{Vertex: vtx(vtxOpt{
+					name:  "v1",
+					value: "result1",
+					execPreFunc: func(ctx context.Context) error {
+						close(started)
+						select {
+						case <-Stopping(ctx):
+							return nil
+						case <-time.After(10 * time.Second):
+							return errors.New("operation wasn't stopped")
+						}
+					},
+				})},
+			},
+		}),
+	}
+
+	eg, egctx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		_, _, err := j0.Build(egctx, g0)
+		return err
+	})
+	<-started
+	require.False(t, j0.Stopped())
+	j0.Stop("test")
+	require.True(t, j0.Stopped())
+
+	err = eg.Wait()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "build is stopping")
+	require.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestNamespace(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	l := NewSolver(SolverOpt{
+		ResolveOpFunc: testOpResolver,
+		DefaultCache:  NewInMemoryCacheManager(),
+	})
+	defer l.Close()
+
+	graph := func(ns string) Edge {
+		return Edge{
+			Vertex: vtx(vtxOpt{
+				name:         "v0",
+				cacheKeySeed: "seed0",
+				value:        "result0",
+				namespace:    ns,
+				inputs: []Edge{
+					{Vertex: vtx(vtxOpt{
+						name:         "v1",
+						cacheKeySeed: "seed1",
+						value:        "result1",
+						namespace:    ns,
+					})},
+				},
+			}),
+		}
+	}
+
+	build := func(ns string) CacheStats {
+		j, err := l.NewJob(identity.NewID())
+		require.NoError(t, err)
+		defer j.Discard()
+		_, _, err = j.Build(ctx, graph(ns))
+		require.NoError(t, err)
+		return j.CacheStats()
+	}
+
+	require.Equal(t, CacheStats{Vertexes: 2}, build("a"))
+	require.Equal(t, CacheStats{Vertexes: 1, LocalHits: 1}, build("a"))
+	// the cache of other namespaces isn't used
+	require.Equal(t, CacheStats{Vertexes: 2}, build("b"))
+	require.Equal(t, CacheStats{Vertexes: 2}, build(""))
+}
@@ -0,0 +1,122 @@
+package solver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/identity"
+	digest "github.com/opencontainers/go-digest"
+)
+
+type stoppingKey struct{}
+
+// Stopping returns a channel that is closed when all the jobs of the
+// operation run with the context are stopped with Job.Stop. Operations
+// should then wind down, e.g. by sending SIGTERM to their processes, before
+// their context is canceled. It is nil for the contexts of no operation.
+func Stopping(ctx context.Context) <-chan struct{} {
+	ch, _ := ctx.Value(stoppingKey{}).(<-chan struct{})
+	return ch
+}
+
+type jobStop struct {
+	mu      sync.Mutex
+	stopped bool
+}
+
+// Stop stops the job gracefully: the operations that only run for stopped
+// jobs are not started anymore and the running ones are notified through
+// Stopping. The job is still only canceled with its context. The reason is
+// reported in the progress of the job.
+func (j *Job) Stop(reason string) {
+	j.stop.mu.Lock()
+	if j.stop.stopped {
+		j.stop.mu.Unlock()
+		return
+	}
+	j.stop.stopped = true
+	j.stop.mu.Unlock()
+
+	j.list.mu.RLock()
+	for _, st := range j.list.actives {
+		st.mu.Lock()
+		if _, ok := st.jobs[j]; ok && st.allJobsStopped() {
+			st.closeStopping()
+		}
+		st.mu.Unlock()
+	}
+	j.list.mu.RUnlock()
+
+	name := "stopping build"
+	if reason != "" {
+		name += ": " + reason
+	}
+	now := time.Now()
+	j.pw.Write(identity.NewID(), client.Vertex{
+		Digest:    digest.FromString("stop:" + j.id),
+		Name:      name,
+		Started:   &now,
+		Completed: &now,
+	})
+}
+
+// Stopped returns true if the job was stopped with Stop.
+func (j *Job) Stopped() bool {
+	j.stop.mu.Lock()
+	defer j.stop.mu.Unlock()
+	return j.stop.stopped
+}
+
+// allJobsStopped returns true if every job of the state is stopped. The
+// state must be locked.
+func (s *state) allJobsStopped() bool {
+	if len(s.jobs) == 0 {
+		return false
+	}
+	for j := range s.jobs {
+		if !j.Stopped() {
+			return false
+		}
+	}
+	return true
+}
+
+// stopping returns the channel closed when all the jobs of the state are
+// stopped.
+func (s *state) stopping() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopCh == nil {
+		s.stopCh = make(chan struct{})
+	}
+	return s.stopCh
+}
+
+// closeStopping closes the channel returned by stopping. The state must be
+// locked.
+func (s *state) closeStopping() {
+	if s.stopCh == nil {
+		s.stopCh = make(chan struct{})
+	}
+	select {
+	case <-s.stopCh:
+	default:
+		close(s.stopCh)
+	}
+}
+
+// resumeStopping replaces the closed channel returned by stopping when a job
+// that isn't stopped joins the state, so that it can still run the
+// operation. The state must be locked.
+func (s *state) resumeStopping() {
+	if s.stopCh == nil {
+		return
+	}
+	select {
+	case <-s.stopCh:
+		s.stopCh = nil
+	default:
+	}
+}
@@ -56,6 +56,17 @@ type VertexOptions struct {
 	ExportCache  *bool
 	// WorkerConstraint
 	ProgressGroup *pb.ProgressGroup
+	// Timeout cancels executions of the vertex running longer than it.
+	Timeout time.Duration
+	// Retries is the number of times a failed execution of the vertex is
+	// retried, up to MaxRetries. The delay before a retry starts at
+	// RetryDelay and is doubled for every further retry, up to
+	// MaxRetryDelay.
+	Retries    int
+	RetryDelay time.Duration
+	// Namespace isolates the vertex from the vertexes of other namespaces:
+	// they neither share their cache keys nor run together.
+	Namespace string
 }
 
 // Result is an abstract return value for a solve
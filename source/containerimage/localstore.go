@@ -0,0 +1,73 @@
+package containerimage
+
+import (
+	"context"
+	"io"
+
+	"github.com/containerd/containerd/content"
+	containerderrdefs "github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/remotes"
+	distreference "github.com/docker/distribution/reference"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/util/pull"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// localStoreResolver resolves images with the image store of the worker and
+// fetches them from its content store, without contacting the registry. It is
+// used by the local-store resolve mode for images built or loaded on the
+// worker, e.g. in the containerd image store of the containerd worker.
+type localStoreResolver struct {
+	is images.Store
+	cs content.Store
+}
+
+var _ pull.SessionResolver = &localStoreResolver{}
+
+func newLocalStoreResolver(is images.Store, cs content.Store) (*localStoreResolver, error) {
+	if is == nil {
+		return nil, errors.New("local-store resolve mode requires a worker with an image store")
+	}
+	return &localStoreResolver{is: is, cs: cs}, nil
+}
+
+func (r *localStoreResolver) WithSession(session.Group) remotes.Resolver {
+	return r
+}
+
+// Resolve looks up the image by its normalized reference, then by its familiar
+// one as images exported with a short name are stored with it.
+func (r *localStoreResolver) Resolve(ctx context.Context, ref string) (string, ocispecs.Descriptor, error) {
+	names := []string{ref}
+	if named, err := distreference.ParseNormalizedNamed(ref); err == nil {
+		if familiar := distreference.FamiliarString(named); familiar != ref {
+			names = append(names, familiar)
+		}
+	}
+	for _, name := range names {
+		img, err := r.is.Get(ctx, name)
+		if err == nil {
+			return ref, img.Target, nil
+		}
+		if !errors.Is(err, containerderrdefs.ErrNotFound) {
+			return "", ocispecs.Descriptor{}, err
+		}
+	}
+	return "", ocispecs.Descriptor{}, errors.Wrapf(containerderrdefs.ErrNotFound, "image %s not found in the local image store", ref)
+}
+
+func (r *localStoreResolver) Fetcher(ctx context.Context, ref string) (remotes.Fetcher, error) {
+	return remotes.FetcherFunc(func(ctx context.Context, desc ocispecs.Descriptor) (io.ReadCloser, error) {
+		ra, err := r.cs.ReaderAt(ctx, desc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s of %s from the local content store", desc.Digest, ref)
+		}
+		return &readerAtCloser{Reader: content.NewReader(ra), ra: ra}, nil
+	}), nil
+}
+
+func (r *localStoreResolver) Pusher(ctx context.Context, ref string) (remotes.Pusher, error) {
+	return nil, errors.New("pushing to the local image store is not supported")
+}
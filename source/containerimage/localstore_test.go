@@ -0,0 +1,81 @@
+package containerimage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/content/local"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/images"
+	"github.com/moby/buildkit/util/imageutil"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStoreResolver(t *testing.T) {
+	ctx := context.TODO()
+
+	cs, err := local.NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	config, err := json.Marshal(ocispecs.Image{Author: "local"})
+	require.NoError(t, err)
+	configDesc := ocispecs.Descriptor{
+		MediaType: ocispecs.MediaTypeImageConfig,
+		Digest:    digest.FromBytes(config),
+		Size:      int64(len(config)),
+	}
+	require.NoError(t, content.WriteBlob(ctx, cs, configDesc.Digest.String(), bytes.NewReader(config), configDesc))
+	dt, err := json.Marshal(ocispecs.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispecs.MediaTypeImageManifest,
+		Config:    configDesc,
+	})
+	require.NoError(t, err)
+	desc := ocispecs.Descriptor{
+		MediaType: ocispecs.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(dt),
+		Size:      int64(len(dt)),
+	}
+	require.NoError(t, content.WriteBlob(ctx, cs, desc.Digest.String(), bytes.NewReader(dt), desc))
+
+	_, err = newLocalStoreResolver(nil, cs)
+	require.Error(t, err)
+
+	r, err := newLocalStoreResolver(&testImageStore{images: map[string]images.Image{
+		"docker.io/library/app:latest": {Name: "docker.io/library/app:latest", Target: desc},
+		"dev:latest":                   {Name: "dev:latest", Target: desc},
+	}}, cs)
+	require.NoError(t, err)
+
+	dgst, dtConfig, err := imageutil.Config(ctx, "docker.io/library/app:latest", r, cs, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, desc.Digest, dgst)
+	require.Equal(t, config, dtConfig)
+
+	_, resolved, err := r.Resolve(ctx, "docker.io/library/dev:latest")
+	require.NoError(t, err)
+	require.Equal(t, desc.Digest, resolved.Digest)
+
+	_, _, err = r.Resolve(ctx, "docker.io/library/other:latest")
+	require.Error(t, err)
+	require.True(t, errdefs.IsNotFound(err))
+}
+
+type testImageStore struct {
+	images.Store
+	images map[string]images.Image
+}
+
+func (s *testImageStore) Get(ctx context.Context, name string) (images.Image, error) {
+	img, ok := s.images[name]
+	if !ok {
+		return images.Image{}, errdefs.ErrNotFound
+	}
+	return img, nil
+}
@@ -0,0 +1,201 @@
+package containerimage
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/containerd/containerd/content"
+	containerderrdefs "github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/platforms"
+	"github.com/containerd/containerd/reference"
+	"github.com/containerd/containerd/remotes"
+	"github.com/moby/buildkit/session"
+	sessioncontent "github.com/moby/buildkit/session/content"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/source"
+	srctypes "github.com/moby/buildkit/source/types"
+	"github.com/moby/buildkit/util/imageutil"
+	"github.com/moby/buildkit/util/pull"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// OCILayoutSource loads images from the OCI layout stores that the clients
+// share in their sessions.
+type OCILayoutSource struct {
+	SourceOpt
+}
+
+var _ source.Source = &OCILayoutSource{}
+
+func NewOCILayoutSource(opt SourceOpt) (*OCILayoutSource, error) {
+	return &OCILayoutSource{SourceOpt: opt}, nil
+}
+
+func (is *OCILayoutSource) ID() string {
+	return srctypes.OCIScheme
+}
+
+func (is *OCILayoutSource) Resolve(ctx context.Context, id source.Identifier, sm *session.Manager, vtx solver.Vertex) (source.SourceInstance, error) {
+	ociIdentifier, ok := id.(*source.OCIIdentifier)
+	if !ok {
+		return nil, errors.Errorf("invalid OCI layout identifier %v", id)
+	}
+
+	platform := platforms.DefaultSpec()
+	if ociIdentifier.Platform != nil {
+		platform = *ociIdentifier.Platform
+	}
+
+	pullerUtil := &pull.Puller{
+		ContentStore: is.ContentStore,
+		Platform:     platform,
+		Src:          ociIdentifier.Reference,
+	}
+	p := &puller{
+		CacheAccessor: is.CacheAccessor,
+		LeaseManager:  is.LeaseManager,
+		Puller:        pullerUtil,
+		vtx:           vtx,
+		newResolver: func(g session.Group) remotes.Resolver {
+			return &ociLayoutResolver{
+				sm:        sm,
+				g:         g,
+				ref:       ociIdentifier.Reference,
+				sessionID: ociIdentifier.SessionID,
+				storeID:   ociIdentifier.StoreID,
+			}
+		},
+	}
+	return p, nil
+}
+
+// ociLayoutResolver resolves and fetches images from an OCI layout store of a
+// session.
+type ociLayoutResolver struct {
+	sm        *session.Manager
+	g         session.Group
+	ref       reference.Spec
+	sessionID string
+	storeID   string
+}
+
+var _ pull.SessionResolver = &ociLayoutResolver{}
+
+func (r *ociLayoutResolver) WithSession(g session.Group) remotes.Resolver {
+	r2 := *r
+	r2.g = g
+	return &r2
+}
+
+// withStore calls f with the store from the session of the source, or from
+// any session of the build if the source has no session.
+func (r *ociLayoutResolver) withStore(ctx context.Context, f func(context.Context, content.Store) error) error {
+	g := r.g
+	if r.sessionID != "" {
+		g = session.NewGroup(r.sessionID)
+	}
+	return r.sm.Any(ctx, g, func(ctx context.Context, _ string, c session.Caller) error {
+		return f(ctx, sessioncontent.NewCallerStore(c, sessioncontent.OCIStoreIDPrefix+r.storeID))
+	})
+}
+
+// Resolve resolves the reference of the source, ref is the same reference
+// formatted by the puller.
+func (r *ociLayoutResolver) Resolve(ctx context.Context, ref string) (string, ocispecs.Descriptor, error) {
+	var desc ocispecs.Descriptor
+	err := r.withStore(ctx, func(ctx context.Context, store content.Store) (err error) {
+		dgst := r.ref.Digest()
+		if dgst == "" {
+			dgst, err = resolveOCITag(ctx, store, r.ref)
+			if err != nil {
+				return err
+			}
+		}
+		desc, err = manifestDescriptor(ctx, store, dgst)
+		return err
+	})
+	if err != nil {
+		return "", ocispecs.Descriptor{}, errors.Wrapf(err, "failed to resolve %s in OCI layout store %q", ref, r.storeID)
+	}
+	return ref, desc, nil
+}
+
+// resolveOCITag returns the digest of the manifest tagged in the index of the
+// store with the tag of the reference or with the full reference.
+func resolveOCITag(ctx context.Context, store content.Store, spec reference.Spec) (digest.Digest, error) {
+	for _, tag := range []string{spec.Object, spec.String()} {
+		label := sessioncontent.OCITagLabelPrefix + tag
+		var dgsts []digest.Digest
+		if err := store.Walk(ctx, func(info content.Info) error {
+			if _, ok := info.Labels[label]; ok {
+				dgsts = append(dgsts, info.Digest)
+			}
+			return nil
+		}, fmt.Sprintf("labels.%q", label)); err != nil {
+			return "", err
+		}
+		switch len(dgsts) {
+		case 0:
+		case 1:
+			return dgsts[0], nil
+		default:
+			return "", errors.Errorf("tag %s refers to multiple manifests", tag)
+		}
+	}
+	return "", errors.Wrapf(containerderrdefs.ErrNotFound, "tag %s", spec.Object)
+}
+
+func manifestDescriptor(ctx context.Context, store content.Store, dgst digest.Digest) (ocispecs.Descriptor, error) {
+	info, err := store.Info(ctx, dgst)
+	if err != nil {
+		return ocispecs.Descriptor{}, err
+	}
+	desc := ocispecs.Descriptor{
+		Digest: dgst,
+		Size:   info.Size,
+	}
+	ra, err := store.ReaderAt(ctx, desc)
+	if err != nil {
+		return ocispecs.Descriptor{}, err
+	}
+	defer ra.Close()
+	desc.MediaType, err = imageutil.DetectManifestMediaType(ra)
+	if err != nil {
+		return ocispecs.Descriptor{}, err
+	}
+	return desc, nil
+}
+
+func (r *ociLayoutResolver) Fetcher(ctx context.Context, ref string) (remotes.Fetcher, error) {
+	return remotes.FetcherFunc(func(ctx context.Context, desc ocispecs.Descriptor) (io.ReadCloser, error) {
+		var rc io.ReadCloser
+		err := r.withStore(ctx, func(ctx context.Context, store content.Store) error {
+			ra, err := store.ReaderAt(ctx, desc)
+			if err != nil {
+				return err
+			}
+			rc = &readerAtCloser{Reader: content.NewReader(ra), ra: ra}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return rc, nil
+	}), nil
+}
+
+func (r *ociLayoutResolver) Pusher(ctx context.Context, ref string) (remotes.Pusher, error) {
+	return nil, errors.New("pushing to OCI layout stores is not supported")
+}
+
+type readerAtCloser struct {
+	io.Reader
+	ra content.ReaderAt
+}
+
+func (r *readerAtCloser) Close() error {
+	return r.ra.Close()
+}
@@ -0,0 +1,83 @@
+package containerimage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/content/local"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/reference"
+	sessioncontent "github.com/moby/buildkit/session/content"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveOCITag(t *testing.T) {
+	ctx := context.TODO()
+	dir := t.TempDir()
+
+	cs, err := local.NewStore(dir)
+	require.NoError(t, err)
+
+	writeManifest := func(created string) ocispecs.Descriptor {
+		config, err := json.Marshal(ocispecs.Image{Author: created})
+		require.NoError(t, err)
+		configDesc := ocispecs.Descriptor{
+			MediaType: ocispecs.MediaTypeImageConfig,
+			Digest:    digest.FromBytes(config),
+			Size:      int64(len(config)),
+		}
+		require.NoError(t, content.WriteBlob(ctx, cs, configDesc.Digest.String(), bytes.NewReader(config), configDesc))
+		dt, err := json.Marshal(ocispecs.Manifest{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			MediaType: ocispecs.MediaTypeImageManifest,
+			Config:    configDesc,
+		})
+		require.NoError(t, err)
+		desc := ocispecs.Descriptor{
+			MediaType: ocispecs.MediaTypeImageManifest,
+			Digest:    digest.FromBytes(dt),
+			Size:      int64(len(dt)),
+		}
+		require.NoError(t, content.WriteBlob(ctx, cs, desc.Digest.String(), bytes.NewReader(dt), desc))
+		return desc
+	}
+	v1 := writeManifest("v1")
+	v2 := writeManifest("v2")
+	v1.Annotations = map[string]string{ocispecs.AnnotationRefName: "v1"}
+	v2.Annotations = map[string]string{ocispecs.AnnotationRefName: "app:v2"}
+
+	dt, err := json.Marshal(ocispecs.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		Manifests: []ocispecs.Descriptor{v1, v2},
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.json"), dt, 0600))
+
+	store, err := sessioncontent.NewOCILayoutStore(dir)
+	require.NoError(t, err)
+
+	dgst, err := resolveOCITag(ctx, store, reference.Spec{Locator: "app", Object: "v1"})
+	require.NoError(t, err)
+	require.Equal(t, v1.Digest, dgst)
+
+	dgst, err = resolveOCITag(ctx, store, reference.Spec{Locator: "app", Object: "v2"})
+	require.NoError(t, err)
+	require.Equal(t, v2.Digest, dgst)
+
+	desc, err := manifestDescriptor(ctx, store, dgst)
+	require.NoError(t, err)
+	require.Equal(t, ocispecs.MediaTypeImageManifest, desc.MediaType)
+	require.Equal(t, v2.Size, desc.Size)
+
+	_, err = resolveOCITag(ctx, store, reference.Spec{Locator: "app", Object: "v3"})
+	require.Error(t, err)
+	require.True(t, errdefs.IsNotFound(err))
+}
@@ -12,9 +12,11 @@ import (
 	"github.com/containerd/containerd/images"
 	"github.com/containerd/containerd/leases"
 	"github.com/containerd/containerd/platforms"
+	"github.com/containerd/containerd/remotes"
 	"github.com/containerd/containerd/remotes/docker"
 	"github.com/containerd/containerd/snapshots"
 	"github.com/moby/buildkit/cache"
+	"github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/client/llb"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/snapshot"
@@ -25,7 +27,9 @@ import (
 	"github.com/moby/buildkit/util/estargz"
 	"github.com/moby/buildkit/util/flightcontrol"
 	"github.com/moby/buildkit/util/imageutil"
+	"github.com/moby/buildkit/util/imageverify"
 	"github.com/moby/buildkit/util/leaseutil"
+	"github.com/moby/buildkit/util/overlaybd"
 	"github.com/moby/buildkit/util/progress"
 	"github.com/moby/buildkit/util/progress/controller"
 	"github.com/moby/buildkit/util/pull"
@@ -85,7 +89,16 @@ func (is *Source) ResolveImageConfig(ctx context.Context, ref string, opt llb.Re
 	key += rm.String()
 
 	res, err := is.g.Do(ctx, key, func(ctx context.Context) (interface{}, error) {
-		res := resolver.DefaultPool.GetResolver(is.RegistryHosts, ref, "pull", sm, g).WithImageStore(is.ImageStore, rm)
+		var res remotes.Resolver
+		if rm == source.ResolveModeLocalStore {
+			lsr, err := newLocalStoreResolver(is.ImageStore, is.ContentStore)
+			if err != nil {
+				return nil, err
+			}
+			res = lsr
+		} else {
+			res = resolver.DefaultPool.GetResolver(is.RegistryHosts, ref, "pull", sm, g).WithImageStore(is.ImageStore, rm)
+		}
 		dgst, dt, err := imageutil.Config(ctx, ref, res, is.ContentStore, is.LeaseManager, opt.Platform)
 		if err != nil {
 			return nil, err
@@ -115,31 +128,53 @@ func (is *Source) Resolve(ctx context.Context, id source.Identifier, sm *session
 		Platform:     platform,
 		Src:          imageIdentifier.Reference,
 	}
+	ref := imageIdentifier.Reference.String()
+	newResolver := func(g session.Group) remotes.Resolver {
+		return resolver.DefaultPool.GetResolver(is.RegistryHosts, ref, "pull", sm, g).WithImageStore(is.ImageStore, imageIdentifier.ResolveMode)
+	}
+	if imageIdentifier.ResolveMode == source.ResolveModeLocalStore {
+		lsr, err := newLocalStoreResolver(is.ImageStore, is.ContentStore)
+		if err != nil {
+			return nil, err
+		}
+		newResolver = func(session.Group) remotes.Resolver {
+			return lsr
+		}
+	}
+	var verifyPolicy *imageverify.Policy
+	if imageIdentifier.VerifyKeys != "" {
+		if imageIdentifier.ResolveMode == source.ResolveModeLocalStore {
+			return nil, errors.Errorf("image verification is not supported with %s resolve mode", imageIdentifier.ResolveMode)
+		}
+		vp, err := imageverify.ParsePolicy(imageIdentifier.VerifyKeys, imageIdentifier.VerifyAttestations)
+		if err != nil {
+			return nil, err
+		}
+		verifyPolicy = vp
+	}
 	p := &puller{
-		CacheAccessor:  is.CacheAccessor,
-		LeaseManager:   is.LeaseManager,
-		Puller:         pullerUtil,
-		id:             imageIdentifier,
-		RegistryHosts:  is.RegistryHosts,
-		ImageStore:     is.ImageStore,
-		Mode:           imageIdentifier.ResolveMode,
-		Ref:            imageIdentifier.Reference.String(),
-		SessionManager: sm,
-		vtx:            vtx,
+		CacheAccessor: is.CacheAccessor,
+		LeaseManager:  is.LeaseManager,
+		Puller:        pullerUtil,
+		recordType:    imageIdentifier.RecordType,
+		vtx:           vtx,
+		newResolver:   newResolver,
+		verifyPolicy:  verifyPolicy,
 	}
 	return p, nil
 }
 
 type puller struct {
-	CacheAccessor  cache.Accessor
-	LeaseManager   leases.Manager
-	RegistryHosts  docker.RegistryHosts
-	ImageStore     images.Store
-	Mode           source.ResolveMode
-	Ref            string
-	SessionManager *session.Manager
-	id             *source.ImageIdentifier
-	vtx            solver.Vertex
+	CacheAccessor cache.Accessor
+	LeaseManager  leases.Manager
+	recordType    client.UsageRecordType
+	vtx           solver.Vertex
+	// newResolver returns the resolver the image is pulled with for the
+	// session group.
+	newResolver func(session.Group) remotes.Resolver
+	// verifyPolicy is the policy the signatures and attestations of the image
+	// are verified with before it is pulled, if any.
+	verifyPolicy *imageverify.Policy
 
 	g                flightcontrol.Group
 	cacheKeyErr      error
@@ -152,6 +187,28 @@ type puller struct {
 	*pull.Puller
 }
 
+// verify verifies the main manifest of the image with the verification policy
+// before any of its layers are pulled.
+func (p *puller) verify(ctx context.Context) (err error) {
+	desc := p.manifest.MainManifestDesc
+	progressDone := oneOffProgress(ctx, "verify "+p.Src.String())
+	defer func() {
+		progressDone(err)
+	}()
+	reg, err := imageverify.NewRegistry(p.Resolver, p.manifest.Ref)
+	if err != nil {
+		return err
+	}
+	if err := imageverify.Verify(ctx, reg, p.manifest.Ref, desc.Digest, p.verifyPolicy); err != nil {
+		return errdefs.NewUnverifiedImagesError(&errdefs.UnverifiedImage{
+			Ref:    p.manifest.Ref,
+			Digest: desc.Digest.String(),
+			Reason: err.Error(),
+		})
+	}
+	return nil
+}
+
 func mainManifestKey(ctx context.Context, desc ocispecs.Descriptor, platform ocispecs.Platform) (digest.Digest, error) {
 	dt, err := json.Marshal(struct {
 		Digest  digest.Digest
@@ -171,7 +228,7 @@ func mainManifestKey(ctx context.Context, desc ocispecs.Descriptor, platform oci
 }
 
 func (p *puller) CacheKey(ctx context.Context, g session.Group, index int) (cacheKey string, imgDigest string, cacheOpts solver.CacheOpts, cacheDone bool, err error) {
-	p.Puller.Resolver = resolver.DefaultPool.GetResolver(p.RegistryHosts, p.Ref, "pull", p.SessionManager, g).WithImageStore(p.ImageStore, p.id.ResolveMode)
+	p.Puller.Resolver = p.newResolver(g)
 
 	// progressFactory needs the outer context, the context in `p.g.Do` will
 	// be canceled before the progress output is complete
@@ -203,6 +260,12 @@ func (p *puller) CacheKey(ctx context.Context, g session.Group, index int) (cach
 			return nil, err
 		}
 
+		if p.verifyPolicy != nil {
+			if err := p.verify(ctx); err != nil {
+				return nil, err
+			}
+		}
+
 		if len(p.manifest.Descriptors) > 0 {
 			progressController := &controller.Controller{
 				WriterFactory: progressFactory,
@@ -222,6 +285,9 @@ func (p *puller) CacheKey(ctx context.Context, g session.Group, index int) (cach
 				for k, v := range estargz.SnapshotLabels(p.manifest.Ref, p.manifest.Descriptors, i) {
 					labels[k] = v
 				}
+				for k, v := range overlaybd.SnapshotLabels(p.manifest.Ref, p.manifest.Descriptors, i) {
+					labels[k] = v
+				}
 				p.descHandlers[desc.Digest] = &cache.DescHandler{
 					Provider:       p.manifest.Provider,
 					Progress:       progressController,
@@ -264,7 +330,7 @@ func (p *puller) CacheKey(ctx context.Context, g session.Group, index int) (cach
 }
 
 func (p *puller) Snapshot(ctx context.Context, g session.Group) (ir cache.ImmutableRef, err error) {
-	p.Puller.Resolver = resolver.DefaultPool.GetResolver(p.RegistryHosts, p.Ref, "pull", p.SessionManager, g).WithImageStore(p.ImageStore, p.id.ResolveMode)
+	p.Puller.Resolver = p.newResolver(g)
 
 	if len(p.manifest.Descriptors) == 0 {
 		return nil, nil
@@ -325,8 +391,8 @@ func (p *puller) Snapshot(ctx context.Context, g session.Group) (ir cache.Immuta
 		}
 	}
 
-	if p.id.RecordType != "" && current.GetRecordType() == "" {
-		if err := current.SetRecordType(p.id.RecordType); err != nil {
+	if p.recordType != "" && current.GetRecordType() == "" {
+		if err := current.SetRecordType(p.recordType); err != nil {
 			return nil, err
 		}
 	}
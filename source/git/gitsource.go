@@ -69,8 +69,14 @@ func (gs *gitSource) ID() string {
 }
 
 // needs to be called with repo lock
-func (gs *gitSource) mountRemote(ctx context.Context, remote string, auth []string, g session.Group) (target string, release func(), retErr error) {
-	sis, err := searchGitRemote(ctx, gs.cache, remote)
+func (gs *gitSource) mountRemote(ctx context.Context, remote, filter string, auth []string, g session.Group) (target string, release func(), retErr error) {
+	// partial clones are kept separately so objects of the other repositories
+	// are never fetched on demand
+	key := remote
+	if filter != "" {
+		key += "#filter=" + filter
+	}
+	sis, err := searchGitRemote(ctx, gs.cache, key)
 	if err != nil {
 		return "", nil, errors.Wrapf(err, "failed to search metadata for %s", urlutil.RedactCredentials(remote))
 	}
@@ -134,9 +140,22 @@ func (gs *gitSource) mountRemote(ctx context.Context, remote string, auth []stri
 			return "", nil, errors.Wrapf(err, "failed add origin repo at %s", dir)
 		}
 
+		if filter != "" {
+			for _, kv := range [][2]string{
+				{"core.repositoryformatversion", "1"},
+				{"extensions.partialClone", "origin"},
+				{"remote.origin.promisor", "true"},
+				{"remote.origin.partialclonefilter", filter},
+			} {
+				if _, err := gitWithinDir(ctx, dir, "", "", "", nil, "config", kv[0], kv[1]); err != nil {
+					return "", nil, errors.Wrapf(err, "failed to configure partial clone at %s", dir)
+				}
+			}
+		}
+
 		// save new remote metadata
 		md := cacheRefMetadata{remoteRef}
-		if err := md.setGitRemote(remote); err != nil {
+		if err := md.setGitRemote(key); err != nil {
 			return "", nil, err
 		}
 	}
@@ -152,6 +171,8 @@ type gitSourceHandler struct {
 	cacheKey string
 	sm       *session.Manager
 	auth     []string
+	// authHeader is the Authorization header of the main remote
+	authHeader string
 }
 
 func (gs *gitSourceHandler) shaToCacheKey(sha string) string {
@@ -159,6 +180,18 @@ func (gs *gitSourceHandler) shaToCacheKey(sha string) string {
 	if gs.src.KeepGitDir {
 		key += ".git"
 	}
+	if gs.src.SkipSubmodules {
+		key += ".nosubmodules"
+	}
+	if gs.src.LFS {
+		key += ".lfs"
+	}
+	if gs.src.KeepGitDir && gs.src.Depth > 0 {
+		key += ".depth=" + strconv.Itoa(gs.src.Depth)
+	}
+	if len(gs.src.SparsePaths) > 0 {
+		key += ".sparse=" + strings.Join(gs.src.SparsePaths, ",")
+	}
 	if gs.src.Subdir != "" {
 		key += ":" + gs.src.Subdir
 	}
@@ -183,16 +216,17 @@ type authSecret struct {
 	name  string
 }
 
-func (gs *gitSourceHandler) authSecretNames() (sec []authSecret, _ error) {
-	u, err := url.Parse(gs.src.Remote)
-	if err != nil {
-		return nil, err
-	}
+// authSecretNames returns the secrets for the host, the ones scoped to the
+// host first. Unscoped secrets are only returned for the main remote.
+func (gs *gitSourceHandler) authSecretNames(host string, unscoped bool) (sec []authSecret) {
 	if gs.src.AuthHeaderSecret != "" {
-		sec = append(sec, authSecret{name: gs.src.AuthHeaderSecret + "." + u.Host})
+		sec = append(sec, authSecret{name: gs.src.AuthHeaderSecret + "." + host})
 	}
 	if gs.src.AuthTokenSecret != "" {
-		sec = append(sec, authSecret{name: gs.src.AuthTokenSecret + "." + u.Host, token: true})
+		sec = append(sec, authSecret{name: gs.src.AuthTokenSecret + "." + host, token: true})
+	}
+	if !unscoped {
+		return sec
 	}
 	if gs.src.AuthHeaderSecret != "" {
 		sec = append(sec, authSecret{name: gs.src.AuthHeaderSecret})
@@ -200,18 +234,33 @@ func (gs *gitSourceHandler) authSecretNames() (sec []authSecret, _ error) {
 	if gs.src.AuthTokenSecret != "" {
 		sec = append(sec, authSecret{name: gs.src.AuthTokenSecret, token: true})
 	}
-	return sec, nil
+	return sec
 }
 
 func (gs *gitSourceHandler) getAuthToken(ctx context.Context, g session.Group) error {
 	if gs.auth != nil {
 		return nil
 	}
-	sec, err := gs.authSecretNames()
+	u, err := url.Parse(gs.src.Remote)
 	if err != nil {
 		return err
 	}
-	return gs.sm.Any(ctx, g, func(ctx context.Context, _ string, caller session.Caller) error {
+	h, err := gs.getAuthHeader(ctx, g, gs.authSecretNames(u.Host, true))
+	if err != nil || h == "" {
+		return err
+	}
+	gs.authHeader = h
+	gs.auth = []string{"-c", "http." + tokenScope(gs.src.Remote) + ".extraheader=Authorization: " + h}
+	return nil
+}
+
+// getAuthHeader returns the value of the Authorization header from the first
+// secret that exists, or an empty string if none of the secrets exist.
+func (gs *gitSourceHandler) getAuthHeader(ctx context.Context, g session.Group, sec []authSecret) (h string, _ error) {
+	if len(sec) == 0 {
+		return "", nil
+	}
+	err := gs.sm.Any(ctx, g, func(ctx context.Context, _ string, caller session.Caller) error {
 		for _, s := range sec {
 			dt, err := secrets.GetSecret(ctx, caller, s.name)
 			if err != nil {
@@ -223,11 +272,12 @@ func (gs *gitSourceHandler) getAuthToken(ctx context.Context, g session.Group) e
 			if s.token {
 				dt = []byte("basic " + base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("x-access-token:%s", dt))))
 			}
-			gs.auth = []string{"-c", "http." + tokenScope(gs.src.Remote) + ".extraheader=Authorization: " + string(dt)}
+			h = string(dt)
 			break
 		}
 		return nil
 	})
+	return h, err
 }
 
 func (gs *gitSourceHandler) mountSSHAuthSock(ctx context.Context, sshID string, g session.Group) (string, func() error, error) {
@@ -306,7 +356,7 @@ func (gs *gitSourceHandler) CacheKey(ctx context.Context, g session.Group, index
 
 	gs.getAuthToken(ctx, g)
 
-	gitDir, unmountGitDir, err := gs.mountRemote(ctx, remote, gs.auth, g)
+	gitDir, unmountGitDir, err := gs.mountRemote(ctx, remote, gs.src.Filter, gs.auth, g)
 	if err != nil {
 		return "", "", nil, false, err
 	}
@@ -373,6 +423,14 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 
 	gs.getAuthToken(ctx, g)
 
+	if gs.src.Filter != "" && gs.src.KeepGitDir {
+		return nil, errors.New("git filter can't be used when keeping the git directory")
+	}
+	sparsePaths, err := cleanSparsePaths(gs.src.SparsePaths)
+	if err != nil {
+		return nil, err
+	}
+
 	snapshotKey := cacheKey + ":" + gs.src.Subdir
 	gs.locker.Lock(snapshotKey)
 	defer gs.locker.Unlock(snapshotKey)
@@ -387,7 +445,7 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 
 	gs.locker.Lock(gs.src.Remote)
 	defer gs.locker.Unlock(gs.src.Remote)
-	gitDir, unmountGitDir, err := gs.mountRemote(ctx, gs.src.Remote, gs.auth, g)
+	gitDir, unmountGitDir, err := gs.mountRemote(ctx, gs.src.Remote, gs.src.Filter, gs.auth, g)
 	if err != nil {
 		return nil, err
 	}
@@ -435,18 +493,25 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 
 		args := []string{"fetch"}
 		if !isCommitSHA(ref) { // TODO: find a branch from ls-remote?
-			args = append(args, "--depth=1", "--no-tags")
+			args = append(args, "--depth="+strconv.Itoa(gs.depth()), "--no-tags")
+		} else if gs.src.Depth > 0 {
+			args = append(args, "--depth="+strconv.Itoa(gs.src.Depth))
 		} else {
 			if _, err := os.Lstat(filepath.Join(gitDir, "shallow")); err == nil {
 				args = append(args, "--unshallow")
 			}
 		}
+		if gs.src.Filter != "" {
+			args = append(args, "--filter="+gs.src.Filter)
+		}
 		args = append(args, "origin")
 		if !isCommitSHA(ref) {
 			args = append(args, "--force", ref+":tags/"+ref)
 			// local refs are needed so they would be advertised on next fetches. Force is used
 			// in case the ref is a branch and it now points to a different commit sha
 			// TODO: is there a better way to do this?
+		} else if gs.src.Depth > 0 {
+			args = append(args, ref)
 		}
 		if _, err := gitWithinDir(ctx, gitDir, "", sock, knownHosts, gs.auth, args...); err != nil {
 			return nil, errors.Wrapf(err, "failed to fetch remote %s", urlutil.RedactCredentials(gs.src.Remote))
@@ -520,10 +585,15 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 		} else {
 			pullref += ":" + pullref
 		}
-		_, err = gitWithinDir(ctx, checkoutDirGit, "", sock, knownHosts, gs.auth, "fetch", "-u", "--depth=1", "origin", pullref)
+		_, err = gitWithinDir(ctx, checkoutDirGit, "", sock, knownHosts, gs.auth, "fetch", "-u", "--depth="+strconv.Itoa(gs.depth()), "origin", pullref)
 		if err != nil {
 			return nil, err
 		}
+		if len(sparsePaths) > 0 {
+			if err := setSparseCheckout(ctx, checkoutDirGit, sparsePaths); err != nil {
+				return nil, err
+			}
+		}
 		_, err = gitWithinDir(ctx, checkoutDirGit, checkoutDir, sock, knownHosts, nil, "checkout", "FETCH_HEAD")
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to checkout remote %s", urlutil.RedactCredentials(gs.src.Remote))
@@ -539,6 +609,12 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 		if err := os.Remove(filepath.Join(checkoutDirGit, "FETCH_HEAD")); err != nil && !errors.Is(err, os.ErrNotExist) {
 			return nil, errors.Wrapf(err, "failed to remove FETCH_HEAD for remote %s", urlutil.RedactCredentials(gs.src.Remote))
 		}
+		if gs.src.LFS {
+			_, err = gitWithinDir(ctx, checkoutDirGit, checkoutDir, sock, knownHosts, gs.auth, "lfs", "pull")
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to fetch lfs objects for %s", urlutil.RedactCredentials(gs.src.Remote))
+			}
+		}
 		gitDir = checkoutDirGit
 	} else {
 		cd := checkoutDir
@@ -548,7 +624,24 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 				return nil, errors.Wrapf(err, "failed to create temporary checkout dir")
 			}
 		}
-		_, err = gitWithinDir(ctx, gitDir, cd, sock, knownHosts, nil, "checkout", ref, "--", ".")
+		var auth []string
+		var args []string
+		if gs.src.LFS {
+			// LFS objects are downloaded from the remote by the smudge filter
+			auth = gs.auth
+			args = append(args, lfsFilterArgs...)
+		}
+		if gs.src.Filter != "" {
+			// filtered objects are fetched from the remote on checkout
+			auth = gs.auth
+		}
+		args = append(args, "checkout", ref, "--")
+		if len(sparsePaths) > 0 {
+			args = append(args, sparsePaths...)
+		} else {
+			args = append(args, ".")
+		}
+		_, err = gitWithinDir(ctx, gitDir, cd, sock, knownHosts, auth, args...)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to checkout remote %s", urlutil.RedactCredentials(gs.src.Remote))
 		}
@@ -581,9 +674,20 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 		}
 	}
 
-	_, err = gitWithinDir(ctx, gitDir, checkoutDir, sock, knownHosts, gs.auth, "submodule", "update", "--init", "--recursive", "--depth=1")
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to update submodules for %s", urlutil.RedactCredentials(gs.src.Remote))
+	if !gs.src.SkipSubmodules {
+		var args []string
+		if gs.src.LFS {
+			args = append(args, lfsFilterArgs...)
+		}
+		args = append(args, "submodule", "update", "--init", "--recursive", "--depth=1")
+		if len(sparsePaths) > 0 {
+			args = append(append(args, "--"), sparsePaths...)
+		}
+		auth := gs.submoduleAuth(ctx, g, gitDir, checkoutDir)
+		_, err = gitWithinDir(ctx, gitDir, checkoutDir, sock, knownHosts, auth, args...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to update submodules for %s", urlutil.RedactCredentials(gs.src.Remote))
+		}
 	}
 
 	if idmap := mount.IdentityMapping(); idmap != nil {
@@ -618,10 +722,60 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 	return snap, nil
 }
 
+// depth returns the number of commits fetched for branches and tags.
+func (gs *gitSourceHandler) depth() int {
+	if gs.src.Depth > 0 {
+		return gs.src.Depth
+	}
+	return 1
+}
+
+// cleanSparsePaths validates the sparse checkout paths, which must be
+// relative paths inside the repository.
+func cleanSparsePaths(paths []string) ([]string, error) {
+	out := make([]string, 0, len(paths))
+	for _, p := range paths {
+		cp := path.Clean(strings.TrimPrefix(p, "/"))
+		if cp == "." {
+			// the whole repository is checked out
+			return nil, nil
+		}
+		if cp == ".." || strings.HasPrefix(cp, "../") {
+			return nil, errors.Errorf("invalid sparse checkout path %q", p)
+		}
+		out = append(out, cp)
+	}
+	return out, nil
+}
+
+// setSparseCheckout limits the files checked out in the repository to the
+// paths.
+func setSparseCheckout(ctx context.Context, gitDir string, paths []string) error {
+	if _, err := gitWithinDir(ctx, gitDir, "", "", "", nil, "config", "core.sparseCheckout", "true"); err != nil {
+		return errors.Wrap(err, "failed to enable sparse checkout")
+	}
+	var patterns strings.Builder
+	for _, p := range paths {
+		patterns.WriteString("/" + p + "\n")
+	}
+	if err := os.MkdirAll(filepath.Join(gitDir, "info"), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(gitDir, "info", "sparse-checkout"), []byte(patterns.String()), 0644)
+}
+
 func isCommitSHA(str string) bool {
 	return validHex.MatchString(str)
 }
 
+// lfsFilterArgs configure the Git LFS filters for a single command as the
+// git commands don't load any global configuration.
+var lfsFilterArgs = []string{
+	"-c", "filter.lfs.smudge=git-lfs smudge -- %f",
+	"-c", "filter.lfs.process=git-lfs filter-process",
+	"-c", "filter.lfs.required=true",
+}
+
 func gitWithinDir(ctx context.Context, gitDir, workDir, sshAuthSock, knownHosts string, auth []string, args ...string) (*bytes.Buffer, error) {
 	a := append([]string{"--git-dir", gitDir}, auth...)
 	if workDir != "" {
@@ -684,7 +838,7 @@ func git(ctx context.Context, dir, sshAuthSock, knownHosts string, args ...strin
 func argsNoDepth(args []string) []string {
 	out := make([]string, 0, len(args))
 	for _, a := range args {
-		if a != "--depth=1" {
+		if !strings.HasPrefix(a, "--depth=") {
 			out = append(out, a)
 		}
 	}
@@ -542,6 +542,105 @@ func testSubdir(t *testing.T, keepGitDir bool) {
 	require.Equal(t, "abc\n", string(dt))
 }
 
+func TestSparseCheckout(t *testing.T) {
+	testSparseCheckout(t, false)
+}
+func TestSparseCheckoutKeepGitDir(t *testing.T) {
+	testSparseCheckout(t, true)
+}
+
+func testSparseCheckout(t *testing.T, keepGitDir bool) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Depends on unimplemented containerd bind-mount support on Windows")
+	}
+
+	t.Parallel()
+	ctx := context.TODO()
+
+	tmpdir, err := ioutil.TempDir("", "buildkit-state")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	gs := setupGitSource(t, tmpdir)
+
+	repodir, err := ioutil.TempDir("", "buildkit-gitsource")
+	require.NoError(t, err)
+	defer os.RemoveAll(repodir)
+
+	err = runShell(repodir,
+		"git init",
+		"git config --local user.email test",
+		"git config --local user.name test",
+		"git config --local uploadpack.allowFilter true",
+		"echo foo > abc",
+		"mkdir sub other",
+		"echo abc > sub/bar",
+		"echo def > other/baz",
+		"git add abc sub other",
+		"git commit -m initial",
+		"echo ghi > sub/bar",
+		"git commit -am second",
+		"echo jkl > sub/bar",
+		"git commit -am third",
+	)
+	require.NoError(t, err)
+
+	id := &source.GitIdentifier{Remote: "file://" + repodir, KeepGitDir: keepGitDir, Depth: 2, SparsePaths: []string{"sub/"}}
+	if !keepGitDir {
+		id.Filter = "blob:none"
+	}
+
+	g, err := gs.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+
+	key1, _, _, done, err := g.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	require.True(t, done)
+	require.Contains(t, key1, ".sparse=sub/")
+
+	ref1, err := g.Snapshot(ctx, nil)
+	require.NoError(t, err)
+	defer ref1.Release(context.TODO())
+
+	mount, err := ref1.Mount(ctx, true, nil)
+	require.NoError(t, err)
+
+	lm := snapshot.LocalMounter(mount)
+	dir, err := lm.Mount()
+	require.NoError(t, err)
+	defer lm.Unmount()
+
+	dt, err := ioutil.ReadFile(filepath.Join(dir, "sub/bar"))
+	require.NoError(t, err)
+	require.Equal(t, "jkl\n", string(dt))
+
+	_, err = os.Lstat(filepath.Join(dir, "abc"))
+	require.True(t, errors.Is(err, os.ErrNotExist))
+	_, err = os.Lstat(filepath.Join(dir, "other"))
+	require.True(t, errors.Is(err, os.ErrNotExist))
+
+	if keepGitDir {
+		git := exec.Command("git", "rev-list", "--count", "HEAD")
+		git.Dir = dir
+		out, err := git.Output()
+		require.NoError(t, err)
+		require.Equal(t, "2", strings.TrimSpace(string(out)))
+	}
+}
+
+func TestSparsePaths(t *testing.T) {
+	paths, err := cleanSparsePaths([]string{"/sub/", "foo/../bar"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"sub", "bar"}, paths)
+
+	paths, err = cleanSparsePaths([]string{"sub", "."})
+	require.NoError(t, err)
+	require.Nil(t, paths)
+
+	_, err = cleanSparsePaths([]string{"../foo"})
+	require.Error(t, err)
+}
+
 func setupGitSource(t *testing.T, tmpdir string) source.Source {
 	snapshotter, err := native.NewSnapshotter(filepath.Join(tmpdir, "snapshots"))
 	assert.NoError(t, err)
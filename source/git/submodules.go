@@ -0,0 +1,88 @@
+package git
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/util/bklog"
+)
+
+// submoduleAuth returns the credentials for updating the submodules of the
+// checkout. The credentials of the main remote are reused for submodules on
+// the same host, submodules on other hosts use the secrets scoped to their
+// host.
+func (gs *gitSourceHandler) submoduleAuth(ctx context.Context, g session.Group, gitDir, checkoutDir string) []string {
+	auth := append([]string{}, gs.auth...)
+	if gs.src.AuthHeaderSecret == "" && gs.src.AuthTokenSecret == "" {
+		return auth
+	}
+	gitmodules := filepath.Join(checkoutDir, ".gitmodules")
+	if _, err := os.Stat(gitmodules); err != nil {
+		return auth
+	}
+	buf, err := gitWithinDir(ctx, gitDir, "", "", "", nil, "config", "-f", gitmodules, "--get-regexp", `^submodule\..*\.url$`)
+	if err != nil {
+		bklog.G(ctx).Debugf("failed to read submodules of %s: %v", gs.src.Remote, err)
+		return auth
+	}
+	var urls []string
+	for _, l := range strings.Split(buf.String(), "\n") {
+		if parts := strings.Fields(l); len(parts) == 2 {
+			urls = append(urls, parts[1])
+		}
+	}
+
+	sameHost, otherHosts := submoduleScopes(gs.src.Remote, urls)
+	if gs.authHeader != "" {
+		for _, scope := range sameHost {
+			auth = append(auth, "-c", "http."+scope+".extraheader=Authorization: "+gs.authHeader)
+		}
+	}
+	for host, scope := range otherHosts {
+		h, err := gs.getAuthHeader(ctx, g, gs.authSecretNames(host, false))
+		if err != nil {
+			bklog.G(ctx).Debugf("failed to get credentials for %s: %v", host, err)
+			continue
+		}
+		if h != "" {
+			auth = append(auth, "-c", "http."+scope+".extraheader=Authorization: "+h)
+		}
+	}
+	return auth
+}
+
+// submoduleScopes resolves the submodule URLs against the remote and returns
+// the URLs of the submodules on the host of the remote that are not covered
+// by the credentials of the remote, and the URL prefixes of the other hosts.
+func submoduleScopes(remote string, urls []string) (sameHost []string, otherHosts map[string]string) {
+	otherHosts = map[string]string{}
+	ru, err := url.Parse(remote)
+	if err != nil {
+		return nil, otherHosts
+	}
+	scope := tokenScope(remote)
+	for _, s := range urls {
+		if strings.HasPrefix(s, "./") || strings.HasPrefix(s, "../") {
+			u := *ru
+			u.Path = path.Join(u.Path, s)
+			s = u.String()
+		}
+		u, err := url.Parse(s)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+			continue
+		}
+		if u.Host != ru.Host {
+			otherHosts[u.Host] = u.Scheme + "://" + u.Host + "/"
+			continue
+		}
+		if !strings.HasPrefix(s, scope) {
+			sameHost = append(sameHost, s)
+		}
+	}
+	return sameHost, otherHosts
+}
@@ -0,0 +1,35 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmoduleScopes(t *testing.T) {
+	sameHost, otherHosts := submoduleScopes("https://git.example.com/group/main.git", []string{
+		"../sub.git",
+		"./nested/sub2.git",
+		"https://git.example.com/other/sub3.git",
+		"https://github.com/moby/buildkit.git",
+		"git@github.com:moby/moby.git",
+		"http://mirror.example.com:8080/sub4.git",
+	})
+	// nested/sub2.git is covered by the credentials of the remote
+	require.Equal(t, []string{
+		"https://git.example.com/group/sub.git",
+		"https://git.example.com/other/sub3.git",
+	}, sameHost)
+	require.Equal(t, map[string]string{
+		"github.com":              "https://github.com/",
+		"mirror.example.com:8080": "http://mirror.example.com:8080/",
+	}, otherHosts)
+
+	// submodules on github.com are covered by the credentials of the remote
+	sameHost, otherHosts = submoduleScopes("https://github.com/moby/buildkit.git", []string{
+		"https://github.com/moby/moby.git",
+		"../moby.git",
+	})
+	require.Empty(t, sameHost)
+	require.Empty(t, otherHosts)
+}
@@ -18,6 +18,17 @@ type GitIdentifier struct {
 	AuthHeaderSecret string
 	MountSSHSock     string
 	KnownSSHHosts    string
+	SkipSubmodules   bool
+	LFS              bool
+	// Depth is the number of commits fetched from the history of the ref.
+	// The default is 1 for branches and tags and the full history for
+	// commits.
+	Depth int
+	// Filter is the object filter of a partial clone, e.g. "blob:none".
+	// Filtered objects are fetched on demand when checking out the files.
+	Filter string
+	// SparsePaths limits the checkout to the paths.
+	SparsePaths []string
 }
 
 func NewGitIdentifier(remoteURL string) (*GitIdentifier, error) {
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/secrets"
+	"github.com/pkg/errors"
+)
+
+type authSecret struct {
+	name  string
+	token bool
+}
+
+// authSecretNames returns the secrets that are looked up for the
+// Authorization header, the ones scoped to the host of the URL first.
+func (hs *httpSourceHandler) authSecretNames() (sec []authSecret, _ error) {
+	if hs.src.AuthHeaderSecret == "" && hs.src.AuthTokenSecret == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(hs.src.URL)
+	if err != nil {
+		return nil, err
+	}
+	if hs.src.AuthHeaderSecret != "" {
+		sec = append(sec, authSecret{name: hs.src.AuthHeaderSecret + "." + u.Host})
+	}
+	if hs.src.AuthTokenSecret != "" {
+		sec = append(sec, authSecret{name: hs.src.AuthTokenSecret + "." + u.Host, token: true})
+	}
+	if hs.src.AuthHeaderSecret != "" {
+		sec = append(sec, authSecret{name: hs.src.AuthHeaderSecret})
+	}
+	if hs.src.AuthTokenSecret != "" {
+		sec = append(sec, authSecret{name: hs.src.AuthTokenSecret, token: true})
+	}
+	return sec, nil
+}
+
+// getAuthHeader loads the Authorization header from the session secrets. The
+// header is not set if none of the secrets are found.
+func (hs *httpSourceHandler) getAuthHeader(ctx context.Context, g session.Group) error {
+	if hs.auth != nil {
+		return nil
+	}
+	sec, err := hs.authSecretNames()
+	if err != nil || len(sec) == 0 {
+		return err
+	}
+	return hs.sm.Any(ctx, g, func(ctx context.Context, _ string, caller session.Caller) error {
+		for _, s := range sec {
+			dt, err := secrets.GetSecret(ctx, caller, s.name)
+			if err != nil {
+				if errors.Is(err, secrets.ErrNotFound) {
+					continue
+				}
+				return err
+			}
+			v := string(dt)
+			if s.token {
+				v = "Bearer " + v
+			}
+			hs.auth = &v
+			break
+		}
+		return nil
+	})
+}
+
+// newRequest returns a GET request for the URL with the headers of the
+// source.
+func (hs *httpSourceHandler) newRequest(ctx context.Context, g session.Group) (*http.Request, error) {
+	req, err := http.NewRequest("GET", hs.src.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range hs.src.Header {
+		req.Header.Set(k, v)
+	}
+	if err := hs.getAuthHeader(ctx, g); err != nil {
+		return nil, err
+	}
+	if hs.auth != nil {
+		req.Header.Set("Authorization", *hs.auth)
+	}
+	return req, nil
+}
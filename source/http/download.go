@@ -0,0 +1,244 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	defaultChunkSize   = 32 << 20
+	defaultParallelism = 4
+	defaultRetries     = 5
+	defaultBackoff     = 500 * time.Millisecond
+)
+
+// downloader writes the body of a response to a file. Interrupted transfers
+// are resumed with range requests and large files are split into ranges that
+// are fetched in parallel, if the server supports range requests for the
+// resource. The digest of the contents is computed while the file is written.
+type downloader struct {
+	client      *http.Client
+	req         *http.Request
+	chunkSize   int64
+	parallelism int
+	retries     int
+	backoff     time.Duration
+}
+
+func newDownloader(client *http.Client, req *http.Request) *downloader {
+	return &downloader{
+		client:      client,
+		req:         req,
+		chunkSize:   defaultChunkSize,
+		parallelism: defaultParallelism,
+		retries:     defaultRetries,
+		backoff:     defaultBackoff,
+	}
+}
+
+// download writes the body of resp, the response to the request of the
+// downloader, to f and returns the digest of the contents. The body of resp is
+// closed.
+func (d *downloader) download(ctx context.Context, f *os.File, resp *http.Response) (digest.Digest, error) {
+	validator := rangeValidator(resp)
+	if validator == "" {
+		defer resp.Body.Close()
+		h := sha256.New()
+		if _, err := io.Copy(io.MultiWriter(f, h), resp.Body); err != nil {
+			return "", err
+		}
+		return digest.NewDigest(digest.SHA256, h), nil
+	}
+
+	size := resp.ContentLength
+	if size < 2*d.chunkSize || d.parallelism < 2 {
+		size = -1
+	}
+	if size > 0 {
+		if err := f.Truncate(size); err != nil {
+			resp.Body.Close()
+			return "", err
+		}
+	}
+
+	sh := &streamHash{h: sha256.New(), r: f, done: map[int]int64{}}
+	var chunks [][2]int64
+	if size < 0 {
+		chunks = append(chunks, [2]int64{0, -1})
+	} else {
+		for start := int64(0); start < size; start += d.chunkSize {
+			end := start + d.chunkSize
+			if end > size {
+				end = size
+			}
+			chunks = append(chunks, [2]int64{start, end})
+		}
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, d.parallelism)
+	for i, c := range chunks {
+		i, c := i, c
+		var body io.ReadCloser
+		if i == 0 {
+			// the first range is read from the body of the response
+			body = resp.Body
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			if body != nil {
+				body.Close()
+			}
+			return "", eg.Wait()
+		}
+		eg.Go(func() error {
+			defer func() { <-sem }()
+			n, err := d.fetchRange(ctx, f, c[0], c[1], validator, body)
+			if err != nil {
+				return err
+			}
+			return sh.complete(i, n)
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return "", err
+	}
+	return digest.NewDigest(digest.SHA256, sh.h), nil
+}
+
+// fetchRange writes the range of the resource from start to end, or to the
+// end of the resource if end is negative, to f. The body of a response that
+// is already open for the range can be passed. Failed transfers are resumed
+// from the last written offset.
+func (d *downloader) fetchRange(ctx context.Context, f *os.File, start, end int64, validator string, body io.ReadCloser) (int64, error) {
+	pos := start
+	var err error
+	for attempt := 0; ; attempt++ {
+		if body == nil {
+			body, err = d.openRange(ctx, pos, end, validator)
+		}
+		if err == nil {
+			var n int64
+			w := &offsetWriter{w: f, off: pos}
+			if end < 0 {
+				n, err = io.Copy(w, body)
+			} else {
+				n, err = io.CopyN(w, body, end-pos)
+			}
+			body.Close()
+			body = nil
+			pos += n
+			if err == nil {
+				return pos - start, nil
+			}
+		}
+		if errors.Is(err, errRangeUnsupported) || ctx.Err() != nil || attempt >= d.retries {
+			return pos - start, errors.Wrapf(err, "failed to download %s", d.req.URL.Redacted())
+		}
+		select {
+		case <-time.After(d.backoff << attempt):
+		case <-ctx.Done():
+			return pos - start, ctx.Err()
+		}
+	}
+}
+
+var errRangeUnsupported = errors.New("remote file changed or range requests are not supported")
+
+// openRange requests the range of the resource. The request fails if the
+// resource does not match the validator of the first response anymore.
+func (d *downloader) openRange(ctx context.Context, start, end int64, validator string) (io.ReadCloser, error) {
+	req := d.req.Clone(ctx)
+	req.Method = "GET"
+	req.Header.Del("If-None-Match")
+	if end < 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", start))
+	} else {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end-1))
+	}
+	req.Header.Set("If-Range", validator)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			return nil, errors.Errorf("invalid response status %d", resp.StatusCode)
+		}
+		return nil, errors.WithStack(errRangeUnsupported)
+	}
+	if cr := resp.Header.Get("Content-Range"); !strings.HasPrefix(cr, fmt.Sprintf("bytes %d-", start)) {
+		resp.Body.Close()
+		return nil, errors.Wrapf(errRangeUnsupported, "invalid content range %q", cr)
+	}
+	return resp.Body, nil
+}
+
+// rangeValidator returns the value of the If-Range header that makes range
+// requests fail if the resource changed, or an empty string if the response
+// does not allow range requests.
+func rangeValidator(resp *http.Response) string {
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Accept-Ranges") != "bytes" {
+		return ""
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header.Get("Last-Modified")
+}
+
+// streamHash computes the digest of the file while its ranges are completed
+// out of order, hashing the contents as soon as all the previous ranges are
+// written.
+type streamHash struct {
+	mu     sync.Mutex
+	h      hash.Hash
+	r      io.ReaderAt
+	next   int
+	offset int64
+	done   map[int]int64
+}
+
+func (sh *streamHash) complete(i int, n int64) error {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.done[i] = n
+	for {
+		n, ok := sh.done[sh.next]
+		if !ok {
+			return nil
+		}
+		if _, err := io.Copy(sh.h, io.NewSectionReader(sh.r, sh.offset, n)); err != nil {
+			return err
+		}
+		delete(sh.done, sh.next)
+		sh.offset += n
+		sh.next++
+	}
+}
+
+type offsetWriter struct {
+	w   io.WriterAt
+	off int64
+}
+
+func (ow *offsetWriter) Write(p []byte) (int, error) {
+	n, err := ow.w.WriteAt(p, ow.off)
+	ow.off += int64(n)
+	return n, err
+}
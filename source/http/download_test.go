@@ -0,0 +1,152 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyServer serves the content with range support and aborts the first
+// responses after half of the requested bytes are written.
+type flakyServer struct {
+	content  []byte
+	etag     string
+	mu       sync.Mutex
+	failures int
+	ranges   int
+	requests int
+}
+
+func (s *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	if r.Header.Get("Range") != "" {
+		s.ranges++
+	}
+	fail := s.failures > 0
+	if fail {
+		s.failures--
+	}
+	s.mu.Unlock()
+
+	if s.etag != "" {
+		w.Header().Set("ETag", s.etag)
+	}
+	if fail {
+		w = &abortWriter{ResponseWriter: w}
+	}
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(s.content))
+}
+
+type abortWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *abortWriter) Write(p []byte) (int, error) {
+	if w.written {
+		panic(http.ErrAbortHandler)
+	}
+	w.written = true
+	n, err := w.ResponseWriter.Write(p[:len(p)/2])
+	w.ResponseWriter.(http.Flusher).Flush()
+	return n, err
+}
+
+func testDownload(t *testing.T, s *flakyServer, d func(*downloader)) []byte {
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	tmpdir, err := ioutil.TempDir("", "buildkit-download")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	req, err := http.NewRequest("GET", server.URL+"/foo", nil)
+	require.NoError(t, err)
+	client := server.Client()
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+
+	dl := newDownloader(client, req)
+	dl.backoff = time.Millisecond
+	d(dl)
+
+	fp := filepath.Join(tmpdir, "foo")
+	f, err := os.Create(fp)
+	require.NoError(t, err)
+	defer f.Close()
+
+	dgst, err := dl.download(context.TODO(), f, resp)
+	require.NoError(t, err)
+	require.Equal(t, digest.FromBytes(s.content), dgst)
+
+	dt, err := ioutil.ReadFile(fp)
+	require.NoError(t, err)
+	return dt
+}
+
+func TestDownloadResume(t *testing.T) {
+	t.Parallel()
+	content := make([]byte, 1<<20)
+	rand.Read(content)
+
+	s := &flakyServer{content: content, etag: `"v1"`, failures: 2}
+	dt := testDownload(t, s, func(*downloader) {})
+	require.Equal(t, content, dt)
+	require.Equal(t, 3, s.requests)
+	require.Equal(t, 2, s.ranges)
+}
+
+func TestDownloadParallel(t *testing.T) {
+	t.Parallel()
+	content := make([]byte, 1<<20)
+	rand.Read(content)
+
+	s := &flakyServer{content: content, etag: `"v1"`}
+	dt := testDownload(t, s, func(dl *downloader) {
+		dl.chunkSize = 100 << 10
+	})
+	require.Equal(t, content, dt)
+	require.Equal(t, 11, s.requests)
+	require.Equal(t, 10, s.ranges)
+
+	s = &flakyServer{content: content, etag: `"v1"`, failures: 4}
+	dt = testDownload(t, s, func(dl *downloader) {
+		dl.chunkSize = 100 << 10
+	})
+	require.Equal(t, content, dt)
+}
+
+func TestDownloadNoRanges(t *testing.T) {
+	t.Parallel()
+
+	// without a validator the resource can't be resumed
+	s := &flakyServer{content: []byte(strings.Repeat("foo", 1000)), failures: 1}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	req, err := http.NewRequest("GET", server.URL+"/foo", nil)
+	require.NoError(t, err)
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+
+	f, err := ioutil.TempFile("", "buildkit-download")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	_, err = newDownloader(server.Client(), req).download(context.TODO(), f, resp)
+	require.Error(t, err)
+}
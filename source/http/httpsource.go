@@ -2,10 +2,9 @@ package http
 
 import (
 	"context"
-	"crypto/sha256"
 	"encoding/json"
 	"fmt"
-	"io"
+	"io/ioutil"
 	"mime"
 	"net/http"
 	"net/url"
@@ -37,6 +36,7 @@ type httpSource struct {
 	cache     cache.Accessor
 	locker    *locker.Locker
 	transport http.RoundTripper
+	proxies   *proxyTransports
 }
 
 func NewSource(opt Opt) (source.Source, error) {
@@ -48,6 +48,7 @@ func NewSource(opt Opt) (source.Source, error) {
 		cache:     opt.CacheAccessor,
 		locker:    locker.New(),
 		transport: transport,
+		proxies:   newProxyTransports(opt.Transport),
 	}
 	return hs, nil
 }
@@ -62,6 +63,7 @@ type httpSourceHandler struct {
 	refID    string
 	cacheKey digest.Digest
 	sm       *session.Manager
+	auth     *string
 }
 
 func (hs *httpSource) Resolve(ctx context.Context, id source.Identifier, sm *session.Manager, _ solver.Vertex) (source.SourceInstance, error) {
@@ -77,8 +79,19 @@ func (hs *httpSource) Resolve(ctx context.Context, id source.Identifier, sm *ses
 	}, nil
 }
 
-func (hs *httpSourceHandler) client(g session.Group) *http.Client {
-	return &http.Client{Transport: newTransport(hs.transport, hs.sm, g)}
+// client returns the client of the source. The release function must be called
+// once the client is not used anymore.
+func (hs *httpSourceHandler) client(g session.Group) (*http.Client, func(), error) {
+	rt := hs.transport
+	release := func() {}
+	pc, err := newProxyConfig(hs.src)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pc != nil {
+		rt, release = hs.proxies.get(pc)
+	}
+	return &http.Client{Transport: newTransport(rt, hs.sm, g)}, release, nil
 }
 
 // urlHash is internal hash the etag is stored by that doesn't leak outside
@@ -87,11 +100,15 @@ func (hs *httpSourceHandler) urlHash() (digest.Digest, error) {
 	dt, err := json.Marshal(struct {
 		Filename       string
 		Perm, UID, GID int
+		Header         map[string]string `json:",omitempty"`
+		Unpack         *unpackKey        `json:",omitempty"`
 	}{
 		Filename: getFileName(hs.src.URL, hs.src.Filename, nil),
 		Perm:     hs.src.Perm,
 		UID:      hs.src.UID,
 		GID:      hs.src.GID,
+		Header:   hs.src.Header,
+		Unpack:   hs.unpackKey(),
 	})
 	if err != nil {
 		return "", err
@@ -104,7 +121,8 @@ func (hs *httpSourceHandler) formatCacheKey(filename string, dgst digest.Digest,
 		Filename       string
 		Perm, UID, GID int
 		Checksum       digest.Digest
-		LastModTime    string `json:",omitempty"`
+		LastModTime    string     `json:",omitempty"`
+		Unpack         *unpackKey `json:",omitempty"`
 	}{
 		Filename:    filename,
 		Perm:        hs.src.Perm,
@@ -112,6 +130,7 @@ func (hs *httpSourceHandler) formatCacheKey(filename string, dgst digest.Digest,
 		GID:         hs.src.GID,
 		Checksum:    dgst,
 		LastModTime: lastModTime,
+		Unpack:      hs.unpackKey(),
 	})
 	if err != nil {
 		return dgst
@@ -119,6 +138,25 @@ func (hs *httpSourceHandler) formatCacheKey(filename string, dgst digest.Digest,
 	return digest.FromBytes(dt)
 }
 
+// unpackKey is the part of the cache keys for the extraction of the
+// archives.
+type unpackKey struct {
+	Format          string
+	StripComponents int    `json:",omitempty"`
+	Subpath         string `json:",omitempty"`
+}
+
+func (hs *httpSourceHandler) unpackKey() *unpackKey {
+	if hs.src.Unpack == "" {
+		return nil
+	}
+	return &unpackKey{
+		Format:          hs.src.Unpack,
+		StripComponents: hs.src.StripComponents,
+		Subpath:         hs.src.UnpackSubpath,
+	}
+}
+
 func (hs *httpSourceHandler) CacheKey(ctx context.Context, g session.Group, index int) (string, string, solver.CacheOpts, bool, error) {
 	if hs.src.Checksum != "" {
 		hs.cacheKey = hs.src.Checksum
@@ -136,11 +174,10 @@ func (hs *httpSourceHandler) CacheKey(ctx context.Context, g session.Group, inde
 		return "", "", nil, false, errors.Wrapf(err, "failed to search metadata for %s", uh)
 	}
 
-	req, err := http.NewRequest("GET", hs.src.URL, nil)
+	req, err := hs.newRequest(ctx, g)
 	if err != nil {
 		return "", "", nil, false, err
 	}
-	req = req.WithContext(ctx)
 	m := map[string]cacheRefMetadata{}
 
 	// If we request a single ETag in 'If-None-Match', some servers omit the
@@ -171,7 +208,11 @@ func (hs *httpSourceHandler) CacheKey(ctx context.Context, g session.Group, inde
 		}
 	}
 
-	client := hs.client(g)
+	client, release, err := hs.client(g)
+	if err != nil {
+		return "", "", nil, false, err
+	}
+	defer release()
 
 	// Some servers seem to have trouble supporting If-None-Match properly even
 	// though they return ETag-s. So first, optionally try a HEAD request with
@@ -234,7 +275,7 @@ func (hs *httpSourceHandler) CacheKey(ctx context.Context, g session.Group, inde
 		return hs.formatCacheKey(getFileName(hs.src.URL, hs.src.Filename, resp), dgst, modTime).String(), dgst.String(), nil, true, nil
 	}
 
-	ref, dgst, err := hs.save(ctx, resp, g)
+	ref, dgst, err := hs.save(ctx, newDownloader(client, req), resp, g)
 	if err != nil {
 		return "", "", nil, false, err
 	}
@@ -245,7 +286,7 @@ func (hs *httpSourceHandler) CacheKey(ctx context.Context, g session.Group, inde
 	return hs.formatCacheKey(getFileName(hs.src.URL, hs.src.Filename, resp), dgst, resp.Header.Get("Last-Modified")).String(), dgst.String(), nil, true, nil
 }
 
-func (hs *httpSourceHandler) save(ctx context.Context, resp *http.Response, s session.Group) (ref cache.ImmutableRef, dgst digest.Digest, retErr error) {
+func (hs *httpSourceHandler) save(ctx context.Context, dl *downloader, resp *http.Response, s session.Group) (ref cache.ImmutableRef, dgst digest.Digest, retErr error) {
 	newRef, err := hs.cache.New(ctx, nil, s, cache.CachePolicyRetain, cache.WithDescription(fmt.Sprintf("http url %s", hs.src.URL)))
 	if err != nil {
 		return nil, "", err
@@ -281,7 +322,17 @@ func (hs *httpSourceHandler) save(ctx context.Context, resp *http.Response, s se
 	if hs.src.Perm != 0 {
 		perm = hs.src.Perm
 	}
-	fp := filepath.Join(dir, getFileName(hs.src.URL, hs.src.Filename, resp))
+	filename := getFileName(hs.src.URL, hs.src.Filename, resp)
+	fp := filepath.Join(dir, filename)
+	if hs.src.Unpack != "" {
+		// the archive is downloaded next to the extracted files
+		f, err := ioutil.TempFile(dir, ".download-")
+		if err != nil {
+			return nil, "", err
+		}
+		f.Close()
+		fp = f.Name()
+	}
 
 	f, err := os.OpenFile(fp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(perm))
 	if err != nil {
@@ -293,9 +344,8 @@ func (hs *httpSourceHandler) save(ctx context.Context, resp *http.Response, s se
 		}
 	}()
 
-	h := sha256.New()
-
-	if _, err := io.Copy(io.MultiWriter(f, h), resp.Body); err != nil {
+	dgst, err = dl.download(ctx, f, resp)
+	if err != nil {
 		return nil, "", err
 	}
 
@@ -318,22 +368,38 @@ func (hs *httpSourceHandler) save(ctx context.Context, resp *http.Response, s se
 		gid = identity.GID
 	}
 
-	if gid != 0 || uid != 0 {
-		if err := os.Chown(fp, uid, gid); err != nil {
+	if hs.src.Unpack != "" {
+		fp, err = unpack(fp, dir, filename, unpackOpt{
+			format:          hs.src.Unpack,
+			stripComponents: hs.src.StripComponents,
+			subpath:         hs.src.UnpackSubpath,
+			owner:           idtools.Identity{UID: uid, GID: gid},
+			perm:            perm,
+		})
+		if err != nil {
 			return nil, "", err
 		}
 	}
 
-	mTime := time.Unix(0, 0)
-	lastMod := resp.Header.Get("Last-Modified")
-	if lastMod != "" {
-		if parsedMTime, err := http.ParseTime(lastMod); err == nil {
-			mTime = parsedMTime
+	// fp is empty if the files of an archive were extracted
+	if fp != "" {
+		if gid != 0 || uid != 0 {
+			if err := os.Chown(fp, uid, gid); err != nil {
+				return nil, "", err
+			}
 		}
-	}
 
-	if err := os.Chtimes(fp, mTime, mTime); err != nil {
-		return nil, "", err
+		mTime := time.Unix(0, 0)
+		lastMod := resp.Header.Get("Last-Modified")
+		if lastMod != "" {
+			if parsedMTime, err := http.ParseTime(lastMod); err == nil {
+				mTime = parsedMTime
+			}
+		}
+
+		if err := os.Chtimes(fp, mTime, mTime); err != nil {
+			return nil, "", err
+		}
 	}
 
 	lm.Unmount()
@@ -347,7 +413,6 @@ func (hs *httpSourceHandler) save(ctx context.Context, resp *http.Response, s se
 	md := cacheRefMetadata{ref}
 
 	hs.refID = ref.ID()
-	dgst = digest.NewDigest(digest.SHA256, h)
 
 	if respETag := resp.Header.Get("ETag"); respETag != "" {
 		respETag = etagValue(respETag)
@@ -380,20 +445,23 @@ func (hs *httpSourceHandler) Snapshot(ctx context.Context, g session.Group) (cac
 		}
 	}
 
-	req, err := http.NewRequest("GET", hs.src.URL, nil)
+	req, err := hs.newRequest(ctx, g)
 	if err != nil {
 		return nil, err
 	}
-	req = req.WithContext(ctx)
 
-	client := hs.client(g)
+	client, release, err := hs.client(g)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
 
-	ref, dgst, err := hs.save(ctx, resp, g)
+	ref, dgst, err := hs.save(ctx, newDownloader(client, req), resp, g)
 	if err != nil {
 		return nil, err
 	}
@@ -383,3 +383,22 @@ func newHTTPSource(tmpdir string) (source.Source, error) {
 		CacheAccessor: cm,
 	})
 }
+
+func TestHTTPRequestHeader(t *testing.T) {
+	hs := &httpSourceHandler{
+		src: source.HTTPIdentifier{
+			URL:    "https://example.com/foo",
+			Header: map[string]string{"Accept": "application/octet-stream"},
+		},
+	}
+	req, err := hs.newRequest(context.TODO(), nil)
+	require.NoError(t, err)
+	require.Equal(t, "application/octet-stream", req.Header.Get("Accept"))
+	require.Empty(t, req.Header.Get("Authorization"))
+
+	auth := "Bearer token"
+	hs.auth = &auth
+	req, err = hs.newRequest(context.TODO(), nil)
+	require.NoError(t, err)
+	require.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+}
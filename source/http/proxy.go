@@ -0,0 +1,199 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/moby/buildkit/source"
+	"github.com/moby/buildkit/util/tracing"
+	"github.com/pkg/errors"
+)
+
+// proxyConfig is the proxy configuration of a source, with the semantics of
+// the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+type proxyConfig struct {
+	httpProxy  *url.URL
+	httpsProxy *url.URL
+	noProxy    []string
+}
+
+// newProxyConfig returns the proxy configuration of the source, or nil if the
+// source does not override the configuration of the daemon.
+func newProxyConfig(src source.HTTPIdentifier) (*proxyConfig, error) {
+	if src.HTTPProxy == "" && src.HTTPSProxy == "" {
+		return nil, nil
+	}
+	pc := &proxyConfig{}
+	var err error
+	if pc.httpProxy, err = parseProxy(src.HTTPProxy); err != nil {
+		return nil, err
+	}
+	if pc.httpsProxy, err = parseProxy(src.HTTPSProxy); err != nil {
+		return nil, err
+	}
+	for _, p := range strings.Split(src.NoProxy, ",") {
+		if p = strings.ToLower(strings.TrimSpace(p)); p != "" {
+			pc.noProxy = append(pc.noProxy, p)
+		}
+	}
+	return pc, nil
+}
+
+func parseProxy(v string) (*url.URL, error) {
+	if v == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(v)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		// allow proxies without a scheme, like the environment variables
+		if u2, err2 := url.Parse("http://" + v); err2 == nil && u2.Host != "" {
+			return u2, nil
+		}
+		if err == nil {
+			err = errors.Errorf("no host in proxy %q", v)
+		}
+		return nil, errors.Wrapf(err, "invalid proxy %q", v)
+	}
+	return u, nil
+}
+
+// proxy returns the proxy for the request, for use as http.Transport.Proxy.
+func (pc *proxyConfig) proxy(req *http.Request) (*url.URL, error) {
+	var p *url.URL
+	switch req.URL.Scheme {
+	case "https":
+		p = pc.httpsProxy
+	case "http":
+		p = pc.httpProxy
+	}
+	if p == nil || !pc.useProxy(req.URL.Host, req.URL.Scheme) {
+		return nil, nil
+	}
+	return p, nil
+}
+
+// useProxy reports whether the proxy is used for the host, which is excluded
+// by NO_PROXY entries matching it by name, domain suffix, IP or CIDR.
+func (pc *proxyConfig) useProxy(hostport, scheme string) bool {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+		port = ""
+	}
+	if port == "" {
+		if scheme == "https" {
+			port = "443"
+		} else {
+			port = "80"
+		}
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	ip := net.ParseIP(host)
+
+	for _, np := range pc.noProxy {
+		if np == "*" {
+			return false
+		}
+		if _, ipnet, err := net.ParseCIDR(np); err == nil {
+			if ip != nil && ipnet.Contains(ip) {
+				return false
+			}
+			continue
+		}
+		npHost, npPort, err := net.SplitHostPort(np)
+		if err != nil {
+			npHost = np
+			npPort = ""
+		}
+		if npPort != "" && npPort != port {
+			continue
+		}
+		if npIP := net.ParseIP(npHost); npIP != nil {
+			if ip != nil && npIP.Equal(ip) {
+				return false
+			}
+			continue
+		}
+		npHost = strings.TrimPrefix(strings.TrimSuffix(npHost, "."), "*")
+		if strings.HasPrefix(npHost, ".") {
+			if strings.HasSuffix(host, npHost) || host == npHost[1:] {
+				return false
+			}
+			continue
+		}
+		if host == npHost || strings.HasSuffix(host, "."+npHost) {
+			return false
+		}
+	}
+	return true
+}
+
+// key identifies the configuration in the transport cache.
+func (pc *proxyConfig) key() string {
+	var parts []string
+	for _, u := range []*url.URL{pc.httpProxy, pc.httpsProxy} {
+		if u == nil {
+			parts = append(parts, "")
+		} else {
+			parts = append(parts, u.String())
+		}
+	}
+	return strings.Join(append(parts, strings.Join(pc.noProxy, ",")), " ")
+}
+
+// proxyTransports shares the transports of the proxy configurations between
+// concurrent fetches, so that they reuse the connections to the proxies.
+type proxyTransports struct {
+	base *http.Transport
+
+	mu sync.Mutex
+	m  map[string]*proxyTransport
+}
+
+type proxyTransport struct {
+	tr   *http.Transport
+	rt   http.RoundTripper
+	refs int
+}
+
+func newProxyTransports(rt http.RoundTripper) *proxyTransports {
+	base, ok := rt.(*http.Transport)
+	if !ok {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	return &proxyTransports{base: base, m: map[string]*proxyTransport{}}
+}
+
+// get returns the transport of the configuration, derived from the base
+// transport. The release function closes the idle connections of the transport
+// once no fetch uses it anymore.
+func (pts *proxyTransports) get(pc *proxyConfig) (http.RoundTripper, func()) {
+	k := pc.key()
+
+	pts.mu.Lock()
+	defer pts.mu.Unlock()
+	pt, ok := pts.m[k]
+	if !ok {
+		tr := pts.base.Clone()
+		tr.Proxy = pc.proxy
+		pt = &proxyTransport{tr: tr, rt: tracing.NewTransport(tr)}
+		pts.m[k] = pt
+	}
+	pt.refs++
+
+	var once sync.Once
+	return pt.rt, func() {
+		once.Do(func() {
+			pts.mu.Lock()
+			defer pts.mu.Unlock()
+			pt.refs--
+			if pt.refs == 0 {
+				delete(pts.m, k)
+				pt.tr.CloseIdleConnections()
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/moby/buildkit/source"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxyConfig(t *testing.T) {
+	pc, err := newProxyConfig(source.HTTPIdentifier{})
+	require.NoError(t, err)
+	require.Nil(t, pc)
+
+	pc, err = newProxyConfig(source.HTTPIdentifier{
+		HTTPProxy:  "proxy.example.com:3128",
+		HTTPSProxy: "https://secure.example.com",
+		NoProxy:    "internal.example.com, .corp, 10.0.0.0/8,192.168.1.1,localhost:8080",
+	})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		url   string
+		proxy string
+	}{
+		{"http://example.com/foo", "http://proxy.example.com:3128"},
+		{"https://example.com/foo", "https://secure.example.com"},
+		{"http://internal.example.com/foo", ""},
+		{"http://sub.internal.example.com/foo", ""},
+		{"http://notinternal.example.com/foo", "http://proxy.example.com:3128"},
+		{"https://artifacts.corp/foo", ""},
+		{"https://corp/foo", ""},
+		{"http://10.1.2.3/foo", ""},
+		{"http://11.1.2.3/foo", "http://proxy.example.com:3128"},
+		{"http://192.168.1.1:8000/foo", ""},
+		{"http://localhost:8080/foo", ""},
+		{"http://localhost:9090/foo", "http://proxy.example.com:3128"},
+	} {
+		req, err := http.NewRequest("GET", tc.url, nil)
+		require.NoError(t, err)
+		u, err := pc.proxy(req)
+		require.NoError(t, err)
+		if tc.proxy == "" {
+			require.Nil(t, u, tc.url)
+		} else {
+			require.NotNil(t, u, tc.url)
+			require.Equal(t, tc.proxy, u.String(), tc.url)
+		}
+	}
+
+	pc, err = newProxyConfig(source.HTTPIdentifier{HTTPSProxy: "https://secure.example.com", NoProxy: "*"})
+	require.NoError(t, err)
+	req, err := http.NewRequest("GET", "https://example.com/foo", nil)
+	require.NoError(t, err)
+	u, err := pc.proxy(req)
+	require.NoError(t, err)
+	require.Nil(t, u)
+
+	_, err = newProxyConfig(source.HTTPIdentifier{HTTPProxy: "http://%zz"})
+	require.Error(t, err)
+}
+
+func TestProxyTransports(t *testing.T) {
+	base := &http.Transport{MaxIdleConns: 7}
+	pts := newProxyTransports(base)
+
+	pc1, err := newProxyConfig(source.HTTPIdentifier{HTTPProxy: "proxy.example.com:3128"})
+	require.NoError(t, err)
+	pc2, err := newProxyConfig(source.HTTPIdentifier{HTTPProxy: "proxy.example.com:3128"})
+	require.NoError(t, err)
+	pc3, err := newProxyConfig(source.HTTPIdentifier{HTTPProxy: "proxy.example.com:3128", NoProxy: "localhost"})
+	require.NoError(t, err)
+
+	rt1, release1 := pts.get(pc1)
+	rt2, release2 := pts.get(pc2)
+	rt3, release3 := pts.get(pc3)
+	require.Same(t, rt1, rt2)
+	require.NotSame(t, rt1, rt3)
+	require.Len(t, pts.m, 2)
+	require.Equal(t, 7, pts.m[pc1.key()].tr.MaxIdleConns)
+
+	release1()
+	release1()
+	require.Len(t, pts.m, 2)
+	release2()
+	release3()
+	require.Len(t, pts.m, 0)
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/httpfetch"
 	"github.com/moby/buildkit/session/upload"
 	"github.com/pkg/errors"
 )
@@ -22,6 +23,10 @@ type sessionHandler struct {
 
 func (h *sessionHandler) RoundTrip(req *http.Request) (*http.Response, error) {
 	if req.URL.Host != "buildkit-session" {
+		resp, err := h.delegate(req)
+		if err != nil || resp != nil {
+			return resp, err
+		}
 		return h.rt.RoundTrip(req)
 	}
 
@@ -56,3 +61,33 @@ func (h *sessionHandler) RoundTrip(req *http.Request) (*http.Response, error) {
 
 	return resp, nil
 }
+
+// delegate fetches the request through the network of the client if the
+// session fetches the requests of the host for the daemon. It returns a nil
+// response if the request is not delegated.
+func (h *sessionHandler) delegate(req *http.Request) (*http.Response, error) {
+	if h.sm == nil || h.g == nil {
+		return nil, nil
+	}
+	var resp *http.Response
+	var fetchErr error
+	h.sm.Any(req.Context(), h.g, func(ctx context.Context, _ string, caller session.Caller) error {
+		if !httpfetch.Supported(caller) {
+			return httpfetch.ErrNotDelegated
+		}
+		r, err := httpfetch.Fetch(ctx, caller, req)
+		if err != nil {
+			if errors.Is(err, httpfetch.ErrNotDelegated) {
+				return err
+			}
+			fetchErr = err
+			return nil
+		}
+		resp = r
+		return nil
+	})
+	if fetchErr != nil {
+		return nil, errors.Wrapf(fetchErr, "failed to fetch %s through the client", req.URL.Redacted())
+	}
+	return resp, nil
+}
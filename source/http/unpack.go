@@ -0,0 +1,293 @@
+package http
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/containerd/containerd/pkg/userns"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/chrootarchive"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/pkg/errors"
+)
+
+var zipMagic = []byte{'P', 'K', 0x03, 0x04}
+
+// unpackOpt are the options of the extraction of a downloaded archive.
+type unpackOpt struct {
+	format          string
+	stripComponents int
+	subpath         string
+	// owner owns the extracted files, on the host
+	owner idtools.Identity
+	// perm is the permissions of a decompressed file
+	perm int
+}
+
+// unpack extracts the archive p, downloaded for filename, to dir and
+// removes it. The path of the file to finish like a download is returned for
+// the decompressed files and, when the format is detected, for the files
+// that are not archives. It is empty if the files of an archive were
+// extracted.
+func unpack(p, dir, filename string, opt unpackOpt) (string, error) {
+	format := opt.format
+	if format == pb.AttrHTTPUnpackAuto {
+		var err error
+		if format, err = detectFormat(p); err != nil {
+			return "", err
+		}
+		if format == "" {
+			if opt.stripComponents != 0 || opt.subpath != "" {
+				return "", errors.Errorf("%s is not an archive", filename)
+			}
+			fp := filepath.Join(dir, filename)
+			if err := os.Rename(p, fp); err != nil {
+				return "", errors.WithStack(err)
+			}
+			return fp, nil
+		}
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer os.Remove(p)
+	defer f.Close()
+
+	var r io.Reader
+	switch format {
+	case pb.AttrHTTPUnpackTar:
+		rc, err := archive.DecompressStream(f)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to decompress %s", filename)
+		}
+		defer rc.Close()
+		r = rc
+	case pb.AttrHTTPUnpackZip:
+		fi, err := f.Stat()
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		zr, err := zip.NewReader(f, fi.Size())
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to read zip archive %s", filename)
+		}
+		pr := zipToTar(zr)
+		defer pr.Close()
+		r = pr
+	case pb.AttrHTTPUnpackDecompress:
+		if opt.stripComponents != 0 || opt.subpath != "" {
+			return "", errors.Errorf("%s is not an archive", filename)
+		}
+		return decompress(f, dir, filename, opt.perm)
+	default:
+		return "", errors.Errorf("invalid unpack format %q", format)
+	}
+
+	tr := filterTar(r, opt.stripComponents, opt.subpath)
+	defer tr.Close()
+	if err := chrootarchive.UntarUncompressed(tr, dir, &archive.TarOptions{
+		ChownOpts: &opt.owner,
+		InUserNS:  userns.RunningInUserNS(),
+	}); err != nil {
+		return "", errors.Wrapf(err, "failed to extract %s", filename)
+	}
+	return "", nil
+}
+
+// detectFormat returns the format of the archive p, or an empty string if p
+// is not an archive nor a compressed file.
+func detectFormat(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	head, err := br.Peek(10)
+	if err != nil && err != io.EOF {
+		return "", errors.WithStack(err)
+	}
+	if bytes.HasPrefix(head, zipMagic) {
+		return pb.AttrHTTPUnpackZip, nil
+	}
+	rc, err := archive.DecompressStream(br)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer rc.Close()
+	if _, err := tar.NewReader(rc).Next(); err == nil {
+		return pb.AttrHTTPUnpackTar, nil
+	}
+	if archive.DetectCompression(head) != archive.Uncompressed {
+		return pb.AttrHTTPUnpackDecompress, nil
+	}
+	return "", nil
+}
+
+// decompress writes the decompressed file r, downloaded for filename, to
+// dir, without the extension of the compression.
+func decompress(r io.Reader, dir, filename string, perm int) (string, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(10)
+	if err != nil && err != io.EOF {
+		return "", errors.WithStack(err)
+	}
+	if archive.DetectCompression(head) == archive.Uncompressed {
+		return "", errors.Errorf("%s is not compressed", filename)
+	}
+	rc, err := archive.DecompressStream(br)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to decompress %s", filename)
+	}
+	defer rc.Close()
+
+	fp := filepath.Join(dir, decompressedName(filename))
+	f, err := os.OpenFile(fp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(perm))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if _, err := io.Copy(f, rc); err != nil {
+		f.Close()
+		return "", errors.Wrapf(err, "failed to decompress %s", filename)
+	}
+	return fp, errors.WithStack(f.Close())
+}
+
+// decompressedName returns the name of the decompressed file filename.
+func decompressedName(filename string) string {
+	for _, ext := range []string{".gz", ".bz2", ".xz", ".zst"} {
+		if name := strings.TrimSuffix(filename, ext); name != filename && name != "" {
+			return name
+		}
+	}
+	return filename
+}
+
+// zipToTar returns the files of the zip archive as a tar stream.
+func zipToTar(zr *zip.Reader) *io.PipeReader {
+	pr, pw := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(pw)
+		pw.CloseWithError(func() error {
+			for _, zf := range zr.File {
+				if err := writeZipFile(tw, zf); err != nil {
+					return err
+				}
+			}
+			return tw.Close()
+		}())
+	}()
+	return pr
+}
+
+func writeZipFile(tw *tar.Writer, zf *zip.File) error {
+	rc, err := zf.Open()
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", zf.Name)
+	}
+	defer rc.Close()
+	var link string
+	if zf.Mode()&os.ModeSymlink != 0 {
+		dt, err := io.ReadAll(rc)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", zf.Name)
+		}
+		link = string(dt)
+	}
+	hdr, err := tar.FileInfoHeader(zf.FileInfo(), link)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	hdr.Name = zf.Name
+	if err := tw.WriteHeader(hdr); err != nil {
+		return errors.WithStack(err)
+	}
+	if hdr.Typeflag == tar.TypeReg {
+		if _, err := io.Copy(tw, rc); err != nil {
+			return errors.Wrapf(err, "failed to read %s", zf.Name)
+		}
+	}
+	return nil
+}
+
+// filterTar returns the files of the tar stream r without their first
+// stripComponents path components. If subpath is set, only the files in it
+// are kept, relative to it.
+func filterTar(r io.Reader, stripComponents int, subpath string) *io.PipeReader {
+	pr, pw := io.Pipe()
+	subpath = strings.TrimPrefix(path.Clean("/"+subpath), "/")
+	rename := func(name string) (string, bool) {
+		name = strings.TrimPrefix(path.Clean("/"+name), "/")
+		if stripComponents > 0 {
+			parts := strings.Split(name, "/")
+			if len(parts) <= stripComponents {
+				return "", false
+			}
+			name = strings.Join(parts[stripComponents:], "/")
+		}
+		switch {
+		case subpath == "" && name == "":
+			return ".", true
+		case subpath == "":
+			return name, true
+		case name == subpath:
+			return path.Base(name), true
+		case strings.HasPrefix(name, subpath+"/"):
+			return strings.TrimPrefix(name, subpath+"/"), true
+		}
+		return "", false
+	}
+	go func() {
+		tr := tar.NewReader(r)
+		tw := tar.NewWriter(pw)
+		pw.CloseWithError(func() error {
+			for {
+				hdr, err := tr.Next()
+				if err == io.EOF {
+					return tw.Close()
+				}
+				if err != nil {
+					return errors.Wrap(err, "failed to read tar archive")
+				}
+				if hdr.Typeflag != tar.TypeXGlobalHeader {
+					name, ok := rename(hdr.Name)
+					if !ok {
+						continue
+					}
+					if hdr.Typeflag == tar.TypeDir && subpath != "" && name == path.Base(subpath) {
+						name = "."
+					}
+					if hdr.Typeflag == tar.TypeLink {
+						link, ok := rename(hdr.Linkname)
+						if !ok {
+							return errors.Errorf("hard link %s points to %s, which is not extracted", hdr.Name, hdr.Linkname)
+						}
+						hdr.Linkname = link
+					}
+					hdr.Name = name
+					// the names are set by Name and Linkname
+					delete(hdr.PAXRecords, "path")
+					delete(hdr.PAXRecords, "linkpath")
+					hdr.Format = tar.FormatUnknown
+				}
+				if err := tw.WriteHeader(hdr); err != nil {
+					return errors.WithStack(err)
+				}
+				if _, err := io.Copy(tw, tr); err != nil {
+					return errors.Wrap(err, "failed to read tar archive")
+				}
+			}
+		}())
+	}()
+	return pr
+}
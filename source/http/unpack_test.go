@@ -0,0 +1,218 @@
+package http
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"sort"
+	"testing"
+
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/moby/buildkit/identity"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/source"
+	"github.com/moby/buildkit/util/testutil/httpserver"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	if reexec.Init() {
+		os.Exit(0)
+	}
+}
+
+func TestFilterTar(t *testing.T) {
+	t.Parallel()
+
+	dt := tarArchive(t, map[string]string{
+		"pkg/":           "",
+		"pkg/README":     "readme",
+		"pkg/bin/":       "",
+		"pkg/bin/tool":   "tool",
+		"pkg/bin/tool2":  "->pkg/bin/tool",
+		"pkg/lib/libx.a": "lib",
+	})
+
+	names := func(r io.Reader) map[string]string {
+		out := map[string]string{}
+		tr := tar.NewReader(r)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				return out
+			}
+			require.NoError(t, err)
+			out[hdr.Name] = hdr.Linkname
+		}
+	}
+
+	require.Equal(t, map[string]string{
+		"README":     "",
+		"bin":        "",
+		"bin/tool":   "",
+		"bin/tool2":  "bin/tool",
+		"lib/libx.a": "",
+	}, names(filterTar(bytes.NewReader(dt), 1, "")))
+
+	require.Equal(t, map[string]string{
+		".":     "",
+		"tool":  "",
+		"tool2": "tool",
+	}, names(filterTar(bytes.NewReader(dt), 0, "/pkg/bin")))
+
+	require.Equal(t, map[string]string{
+		"libx.a": "",
+	}, names(filterTar(bytes.NewReader(dt), 1, "lib/libx.a")))
+
+	// the hard links need their target
+	_, err := io.Copy(ioutil.Discard, filterTar(bytes.NewReader(dt), 0, "pkg/bin/tool2"))
+	require.Error(t, err)
+}
+
+func TestHTTPUnpack(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Depends on chroot")
+	}
+
+	t.Parallel()
+	ctx := context.TODO()
+
+	tmpdir, err := ioutil.TempDir("", "buildkit-state")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	hs, err := newHTTPSource(tmpdir)
+	require.NoError(t, err)
+
+	var tgz bytes.Buffer
+	gw := gzip.NewWriter(&tgz)
+	_, err = gw.Write(tarArchive(t, map[string]string{
+		"pkg-1.0/":         "",
+		"pkg-1.0/README":   "readme",
+		"pkg-1.0/bin/tool": "tool",
+	}))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	w, err := zw.Create("dir/foo")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("foo"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	var gz bytes.Buffer
+	gw = gzip.NewWriter(&gz)
+	_, err = gw.Write([]byte("bar"))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	server := httpserver.NewTestServer(map[string]httpserver.Response{
+		"/pkg.tar.gz": {Etag: identity.NewID(), Content: tgz.Bytes()},
+		"/dir.zip":    {Etag: identity.NewID(), Content: zipped.Bytes()},
+		"/bar.gz":     {Etag: identity.NewID(), Content: gz.Bytes()},
+		"/plain":      {Etag: identity.NewID(), Content: []byte("plain")},
+	})
+	defer server.Close()
+
+	for _, tc := range []struct {
+		name  string
+		id    *source.HTTPIdentifier
+		files map[string]string
+	}{
+		{
+			name:  "strip",
+			id:    &source.HTTPIdentifier{URL: server.URL + "/pkg.tar.gz", Unpack: pb.AttrHTTPUnpackAuto, StripComponents: 1},
+			files: map[string]string{"README": "readme", "bin/tool": "tool"},
+		},
+		{
+			name:  "subpath",
+			id:    &source.HTTPIdentifier{URL: server.URL + "/pkg.tar.gz", Unpack: pb.AttrHTTPUnpackTar, UnpackSubpath: "pkg-1.0/bin"},
+			files: map[string]string{"tool": "tool"},
+		},
+		{
+			name:  "zip",
+			id:    &source.HTTPIdentifier{URL: server.URL + "/dir.zip", Unpack: pb.AttrHTTPUnpackAuto},
+			files: map[string]string{"dir/foo": "foo"},
+		},
+		{
+			name:  "decompress",
+			id:    &source.HTTPIdentifier{URL: server.URL + "/bar.gz", Unpack: pb.AttrHTTPUnpackAuto},
+			files: map[string]string{"bar": "bar"},
+		},
+		{
+			name:  "plain",
+			id:    &source.HTTPIdentifier{URL: server.URL + "/plain", Unpack: pb.AttrHTTPUnpackAuto},
+			files: map[string]string{"plain": "plain"},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := hs.Resolve(ctx, tc.id, nil, nil)
+			require.NoError(t, err)
+			_, _, _, _, err = h.CacheKey(ctx, nil, 0)
+			require.NoError(t, err)
+			ref, err := h.Snapshot(ctx, nil)
+			require.NoError(t, err)
+			defer ref.Release(context.TODO())
+
+			for p, content := range tc.files {
+				dt, err := readFile(ctx, ref, p)
+				require.NoError(t, err, p)
+				require.Equal(t, content, string(dt), p)
+			}
+		})
+	}
+
+	// the extracted files and the archive don't share the cache
+	h, err := hs.Resolve(ctx, &source.HTTPIdentifier{URL: server.URL + "/pkg.tar.gz"}, nil, nil)
+	require.NoError(t, err)
+	k1, _, _, _, err := h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	h, err = hs.Resolve(ctx, &source.HTTPIdentifier{URL: server.URL + "/pkg.tar.gz", Unpack: pb.AttrHTTPUnpackTar}, nil, nil)
+	require.NoError(t, err)
+	k2, _, _, _, err := h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	require.NotEqual(t, k1, k2)
+}
+
+// tarArchive returns a tar archive of the files. The names ending with a
+// slash are directories and the contents starting with an arrow are hard
+// links.
+func tarArchive(t *testing.T, files map[string]string) []byte {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	// the directories and the link targets are written first
+	sort.Strings(names)
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range names {
+		content := files[name]
+		hdr := &tar.Header{Name: name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(content))}
+		switch {
+		case name[len(name)-1] == '/':
+			hdr.Typeflag = tar.TypeDir
+			hdr.Mode = 0755
+			hdr.Size = 0
+		case len(content) > 2 && content[:2] == "->":
+			hdr.Typeflag = tar.TypeLink
+			hdr.Linkname = content[2:]
+			hdr.Size = 0
+			content = ""
+		}
+		require.NoError(t, tw.WriteHeader(hdr))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/containerd/containerd/reference"
+	distreference "github.com/docker/distribution/reference"
 	"github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/solver/pb"
 	srctypes "github.com/moby/buildkit/source/types"
@@ -26,6 +27,7 @@ const (
 	ResolveModeDefault ResolveMode = iota
 	ResolveModeForcePull
 	ResolveModePreferLocal
+	ResolveModeLocalStore
 )
 
 type Identifier interface {
@@ -50,6 +52,10 @@ func FromString(s string) (Identifier, error) {
 		return NewHTTPIdentifier(parts[1], true)
 	case srctypes.HTTPScheme:
 		return NewHTTPIdentifier(parts[1], false)
+	case srctypes.OCIScheme:
+		return NewOCIIdentifier(parts[1])
+	case srctypes.S3Scheme, srctypes.GCSScheme, srctypes.AzureBlobScheme:
+		return NewObjectStoreIdentifier(parts[0], parts[1])
 	default:
 		return nil, errors.Wrapf(errNotFound, "unknown schema %s", parts[0])
 	}
@@ -85,6 +91,49 @@ func FromLLB(op *pb.Op_Source, platform *pb.Platform) (Identifier, error) {
 					return nil, err
 				}
 				id.RecordType = rt
+			case pb.AttrImageVerifyKeys:
+				id.VerifyKeys = v
+			case pb.AttrImageVerifyAttestations:
+				id.VerifyAttestations = v
+			}
+		}
+	}
+	if id, ok := id.(*OCIIdentifier); ok {
+		if platform != nil {
+			id.Platform = &ocispecs.Platform{
+				OS:           platform.OS,
+				Architecture: platform.Architecture,
+				Variant:      platform.Variant,
+				OSVersion:    platform.OSVersion,
+				OSFeatures:   platform.OSFeatures,
+			}
+		}
+		for k, v := range op.Source.Attrs {
+			switch k {
+			case pb.AttrOCILayoutSessionID:
+				id.SessionID = v
+			case pb.AttrOCILayoutStoreID:
+				id.StoreID = v
+			}
+		}
+	}
+	if id, ok := id.(*ObjectStoreIdentifier); ok {
+		for k, v := range op.Source.Attrs {
+			switch k {
+			case pb.AttrObjectStoreChecksum:
+				dgst, err := digest.Parse(v)
+				if err != nil {
+					return nil, err
+				}
+				id.Checksum = dgst
+			case pb.AttrObjectStoreFilename:
+				id.Filename = v
+			case pb.AttrObjectStoreSecret:
+				id.Secret = v
+			case pb.AttrObjectStoreRegion:
+				id.Region = v
+			case pb.AttrObjectStoreEndpoint:
+				id.Endpoint = v
 			}
 		}
 	}
@@ -108,6 +157,28 @@ func FromLLB(op *pb.Op_Source, platform *pb.Platform) (Identifier, error) {
 				id.KnownSSHHosts = v
 			case pb.AttrMountSSHSock:
 				id.MountSSHSock = v
+			case pb.AttrGitSkipSubmodules:
+				if v == "true" {
+					id.SkipSubmodules = true
+				}
+			case pb.AttrGitLFS:
+				if v == "true" {
+					id.LFS = true
+				}
+			case pb.AttrGitDepth:
+				i, err := strconv.Atoi(v)
+				if err != nil || i < 0 {
+					return nil, errors.Errorf("invalid git depth %q", v)
+				}
+				id.Depth = i
+			case pb.AttrGitFilter:
+				id.Filter = v
+			case pb.AttrGitSparsePaths:
+				var paths []string
+				if err := json.Unmarshal([]byte(v), &paths); err != nil {
+					return nil, err
+				}
+				id.SparsePaths = paths
 			}
 		}
 	}
@@ -179,8 +250,43 @@ func FromLLB(op *pb.Op_Source, platform *pb.Platform) (Identifier, error) {
 					return nil, err
 				}
 				id.GID = int(i)
+			case pb.AttrHTTPAuthHeaderSecret:
+				id.AuthHeaderSecret = v
+			case pb.AttrHTTPAuthTokenSecret:
+				id.AuthTokenSecret = v
+			case pb.AttrHTTPProxy:
+				id.HTTPProxy = v
+			case pb.AttrHTTPSProxy:
+				id.HTTPSProxy = v
+			case pb.AttrHTTPNoProxy:
+				id.NoProxy = v
+			case pb.AttrHTTPUnpack:
+				switch v {
+				case pb.AttrHTTPUnpackAuto, pb.AttrHTTPUnpackTar, pb.AttrHTTPUnpackZip, pb.AttrHTTPUnpackDecompress:
+					id.Unpack = v
+				default:
+					return nil, errors.Errorf("invalid unpack format %q", v)
+				}
+			case pb.AttrHTTPUnpackStripComponents:
+				i, err := strconv.Atoi(v)
+				if err != nil || i < 0 {
+					return nil, errors.Errorf("invalid strip components %q", v)
+				}
+				id.StripComponents = i
+			case pb.AttrHTTPUnpackSubpath:
+				id.UnpackSubpath = v
+			default:
+				if strings.HasPrefix(k, pb.AttrHTTPHeaderPrefix) {
+					if id.Header == nil {
+						id.Header = map[string]string{}
+					}
+					id.Header[strings.TrimPrefix(k, pb.AttrHTTPHeaderPrefix)] = v
+				}
 			}
 		}
+		if id.Unpack == "" && (id.StripComponents != 0 || id.UnpackSubpath != "") {
+			return nil, errors.Errorf("%s and %s require %s", pb.AttrHTTPUnpackStripComponents, pb.AttrHTTPUnpackSubpath, pb.AttrHTTPUnpack)
+		}
 	}
 	return id, nil
 }
@@ -190,6 +296,12 @@ type ImageIdentifier struct {
 	Platform    *ocispecs.Platform
 	ResolveMode ResolveMode
 	RecordType  client.UsageRecordType
+	// VerifyKeys are the PEM encoded public keys that need to verify the
+	// signature of the image before it is pulled.
+	VerifyKeys string
+	// VerifyAttestations are the comma separated predicate types of the
+	// signed attestations the image requires.
+	VerifyAttestations string
 }
 
 func NewImageIdentifier(str string) (*ImageIdentifier, error) {
@@ -208,6 +320,46 @@ func (*ImageIdentifier) ID() string {
 	return srctypes.DockerImageScheme
 }
 
+// OCIIdentifier identifies an image in an OCI layout store shared by the
+// client in a session. The reference is resolved by tag if it has no digest.
+type OCIIdentifier struct {
+	Reference reference.Spec
+	Platform  *ocispecs.Platform
+	// SessionID is the session of the store, the sessions of the build are
+	// used if it is empty.
+	SessionID string
+	// StoreID is the name of the store among the OCI layout stores of the
+	// session.
+	StoreID string
+}
+
+func NewOCIIdentifier(str string) (*OCIIdentifier, error) {
+	// the names in OCI layout stores have no registry host
+	named, err := distreference.Parse(str)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ref := reference.Spec{}
+	if n, ok := named.(distreference.Named); ok {
+		ref.Locator = n.Name()
+	}
+	if t, ok := named.(distreference.Tagged); ok {
+		ref.Object = t.Tag()
+	}
+	if d, ok := named.(distreference.Digested); ok {
+		ref.Object += "@" + d.Digest().String()
+	}
+
+	if ref.Locator == "" || ref.Object == "" {
+		return nil, errors.WithStack(reference.ErrObjectRequired)
+	}
+	return &OCIIdentifier{Reference: ref}, nil
+}
+
+func (*OCIIdentifier) ID() string {
+	return srctypes.OCIScheme
+}
+
 type LocalIdentifier struct {
 	Name            string
 	SessionID       string
@@ -242,12 +394,86 @@ type HTTPIdentifier struct {
 	Perm     int
 	UID      int
 	GID      int
+	// Header contains extra headers sent with the requests for the URL.
+	Header map[string]string
+	// AuthHeaderSecret and AuthTokenSecret are the IDs of session secrets
+	// used for the Authorization header of the requests. A token is sent as
+	// a bearer token.
+	AuthHeaderSecret string
+	AuthTokenSecret  string
+	// HTTPProxy, HTTPSProxy and NoProxy override the proxy configuration of
+	// the daemon for the requests, with the semantics of the environment
+	// variables of the same names.
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+	// Unpack is the format of the archive extracted instead of saving the
+	// downloaded file, one of the pb.AttrHTTPUnpack values. StripComponents
+	// leading path components of the files of the archive are removed, and
+	// only the files in UnpackSubpath are extracted, relative to it.
+	Unpack          string
+	StripComponents int
+	UnpackSubpath   string
 }
 
 func (*HTTPIdentifier) ID() string {
 	return srctypes.HTTPSScheme
 }
 
+// ObjectStoreIdentifier identifies an object in an S3, Google Cloud Storage or
+// Azure Blob Storage bucket, with the s3://bucket/key, gs://bucket/key or
+// azblob://account/container/key URL.
+type ObjectStoreIdentifier struct {
+	URL      string
+	Provider string
+	// Account is the storage account of Azure objects.
+	Account  string
+	Bucket   string
+	Key      string
+	Checksum digest.Digest
+	Filename string
+	// Secret is the ID of the session secret containing the credentials.
+	// Objects are fetched anonymously without it.
+	Secret string
+	// Region is the region of S3 buckets.
+	Region string
+	// Endpoint replaces the default endpoint of the provider. The bucket
+	// is the first element of the path of the requests.
+	Endpoint string
+}
+
+func NewObjectStoreIdentifier(scheme, str string) (*ObjectStoreIdentifier, error) {
+	id := &ObjectStoreIdentifier{
+		URL:      scheme + "://" + str,
+		Provider: scheme,
+	}
+	n := 2
+	if scheme == srctypes.AzureBlobScheme {
+		n = 3
+	}
+	parts := strings.SplitN(str, "/", n)
+	if len(parts) != n || parts[n-1] == "" {
+		return nil, errors.Wrapf(errInvalid, "invalid object URL %s", id.URL)
+	}
+	if scheme == srctypes.AzureBlobScheme {
+		id.Account, parts = parts[0], parts[1:]
+	}
+	id.Bucket, id.Key = parts[0], parts[1]
+	for _, p := range append(parts, id.Account) {
+		if strings.ContainsAny(p, "?#") {
+			return nil, errors.Wrapf(errInvalid, "invalid object URL %s", id.URL)
+		}
+	}
+	if id.Bucket == "" || (scheme == srctypes.AzureBlobScheme && id.Account == "") {
+		return nil, errors.Wrapf(errInvalid, "invalid object URL %s", id.URL)
+	}
+	return id, nil
+}
+
+func (*ObjectStoreIdentifier) ID() string {
+	return srctypes.ObjectStoreScheme
+}
+
 func (r ResolveMode) String() string {
 	switch r {
 	case ResolveModeDefault:
@@ -256,6 +482,8 @@ func (r ResolveMode) String() string {
 		return pb.AttrImageResolveModeForcePull
 	case ResolveModePreferLocal:
 		return pb.AttrImageResolveModePreferLocal
+	case ResolveModeLocalStore:
+		return pb.AttrImageResolveModeLocalStore
 	default:
 		return ""
 	}
@@ -269,6 +497,8 @@ func ParseImageResolveMode(v string) (ResolveMode, error) {
 		return ResolveModeForcePull, nil
 	case pb.AttrImageResolveModePreferLocal:
 		return ResolveModePreferLocal, nil
+	case pb.AttrImageResolveModeLocalStore:
+		return ResolveModeLocalStore, nil
 	default:
 		return 0, errors.Errorf("invalid resolvemode: %s", v)
 	}
@@ -0,0 +1,471 @@
+package objectstore
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/moby/buildkit/cache"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/secrets"
+	"github.com/moby/buildkit/snapshot"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/source"
+	srctypes "github.com/moby/buildkit/source/types"
+	"github.com/moby/buildkit/util/sigv4"
+	"github.com/moby/buildkit/util/tracing"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+const defaultS3Region = "us-east-1"
+
+type Opt struct {
+	CacheAccessor cache.Accessor
+	Transport     http.RoundTripper
+}
+
+// objectStoreSource fetches single objects from S3, Google Cloud Storage and
+// Azure Blob Storage. The credentials are loaded from the session secrets.
+type objectStoreSource struct {
+	cache     cache.Accessor
+	transport http.RoundTripper
+	now       func() time.Time
+}
+
+func NewSource(opt Opt) (source.Source, error) {
+	transport := opt.Transport
+	if transport == nil {
+		transport = tracing.DefaultTransport
+	}
+	return &objectStoreSource{
+		cache:     opt.CacheAccessor,
+		transport: transport,
+		now:       time.Now,
+	}, nil
+}
+
+func (s *objectStoreSource) ID() string {
+	return srctypes.ObjectStoreScheme
+}
+
+type objectStoreSourceHandler struct {
+	*objectStoreSource
+	src      source.ObjectStoreIdentifier
+	refID    string
+	cacheKey digest.Digest
+	sm       *session.Manager
+	secret   []byte
+}
+
+func (s *objectStoreSource) Resolve(ctx context.Context, id source.Identifier, sm *session.Manager, _ solver.Vertex) (source.SourceInstance, error) {
+	osIdentifier, ok := id.(*source.ObjectStoreIdentifier)
+	if !ok {
+		return nil, errors.Errorf("invalid object store identifier %v", id)
+	}
+
+	return &objectStoreSourceHandler{
+		src:               *osIdentifier,
+		objectStoreSource: s,
+		sm:                sm,
+	}, nil
+}
+
+func (h *objectStoreSourceHandler) filename() string {
+	if h.src.Filename != "" {
+		return h.src.Filename
+	}
+	if base := path.Base(h.src.Key); base != "." && base != "/" {
+		return base
+	}
+	return "download"
+}
+
+// urlHash is internal hash the etag is stored by that doesn't leak outside
+// this package.
+func (h *objectStoreSourceHandler) urlHash() (digest.Digest, error) {
+	dt, err := json.Marshal(struct {
+		URL      string
+		Filename string
+		Region   string `json:",omitempty"`
+		Endpoint string `json:",omitempty"`
+	}{
+		URL:      h.src.URL,
+		Filename: h.filename(),
+		Region:   h.src.Region,
+		Endpoint: h.src.Endpoint,
+	})
+	if err != nil {
+		return "", err
+	}
+	return digest.FromBytes(dt), nil
+}
+
+func (h *objectStoreSourceHandler) formatCacheKey(dgst digest.Digest) digest.Digest {
+	dt, err := json.Marshal(struct {
+		Filename string
+		Checksum digest.Digest
+	}{
+		Filename: h.filename(),
+		Checksum: dgst,
+	})
+	if err != nil {
+		return dgst
+	}
+	return digest.FromBytes(dt)
+}
+
+func (h *objectStoreSourceHandler) CacheKey(ctx context.Context, g session.Group, index int) (string, string, solver.CacheOpts, bool, error) {
+	if h.src.Checksum != "" {
+		h.cacheKey = h.src.Checksum
+		return h.formatCacheKey(h.src.Checksum).String(), h.src.Checksum.String(), nil, true, nil
+	}
+
+	uh, err := h.urlHash()
+	if err != nil {
+		return "", "", nil, false, err
+	}
+
+	mds, err := searchObjectDigest(ctx, h.cache, uh)
+	if err != nil {
+		return "", "", nil, false, errors.Wrapf(err, "failed to search metadata for %s", uh)
+	}
+
+	// the object has not changed if its etag is the one of a previous
+	// download
+	if len(mds) > 0 {
+		resp, err := h.do(ctx, g, "HEAD")
+		if err != nil {
+			return "", "", nil, false, err
+		}
+		resp.Body.Close()
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			for _, md := range mds {
+				if md.getETag() != etag {
+					continue
+				}
+				if dgst := md.getChecksum(); dgst != "" {
+					h.refID = md.ID()
+					h.cacheKey = dgst
+					return h.formatCacheKey(dgst).String(), dgst.String(), nil, true, nil
+				}
+			}
+		}
+	}
+
+	ref, dgst, err := h.save(ctx, g)
+	if err != nil {
+		return "", "", nil, false, err
+	}
+	ref.Release(context.TODO())
+
+	h.cacheKey = dgst
+
+	return h.formatCacheKey(dgst).String(), dgst.String(), nil, true, nil
+}
+
+func (h *objectStoreSourceHandler) save(ctx context.Context, g session.Group) (ref cache.ImmutableRef, dgst digest.Digest, retErr error) {
+	resp, err := h.do(ctx, g, "GET")
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	newRef, err := h.cache.New(ctx, nil, g, cache.CachePolicyRetain, cache.WithDescription(fmt.Sprintf("object %s", h.src.URL)))
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer func() {
+		if retErr != nil && newRef != nil {
+			newRef.Release(context.TODO())
+		}
+	}()
+
+	mount, err := newRef.Mount(ctx, false, g)
+	if err != nil {
+		return nil, "", err
+	}
+
+	lm := snapshot.LocalMounter(mount)
+	dir, err := lm.Mount()
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer func() {
+		if retErr != nil && lm != nil {
+			lm.Unmount()
+		}
+	}()
+
+	fp := filepath.Join(dir, h.filename())
+	f, err := os.OpenFile(fp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+	}()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, hash), resp.Body); err != nil {
+		return nil, "", errors.Wrapf(err, "failed to download %s", h.src.URL)
+	}
+	dgst = digest.NewDigest(digest.SHA256, hash)
+
+	if err := f.Close(); err != nil {
+		return nil, "", err
+	}
+	f = nil
+
+	mTime := time.Unix(0, 0)
+	if lastMod := resp.Header.Get("Last-Modified"); lastMod != "" {
+		if parsedMTime, err := http.ParseTime(lastMod); err == nil {
+			mTime = parsedMTime
+		}
+	}
+	if err := os.Chtimes(fp, mTime, mTime); err != nil {
+		return nil, "", err
+	}
+
+	lm.Unmount()
+	lm = nil
+
+	ref, err = newRef.Commit(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	newRef = nil
+	h.refID = ref.ID()
+
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		md := cacheRefMetadata{ref}
+		if err := md.setETag(etag); err != nil {
+			return nil, "", err
+		}
+		uh, err := h.urlHash()
+		if err != nil {
+			return nil, "", err
+		}
+		if err := md.setChecksum(uh, dgst); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return ref, dgst, nil
+}
+
+func (h *objectStoreSourceHandler) Snapshot(ctx context.Context, g session.Group) (cache.ImmutableRef, error) {
+	if h.refID != "" {
+		ref, err := h.cache.Get(ctx, h.refID, nil)
+		if err == nil {
+			return ref, nil
+		}
+	}
+
+	ref, dgst, err := h.save(ctx, g)
+	if err != nil {
+		return nil, err
+	}
+	if dgst != h.cacheKey {
+		ref.Release(context.TODO())
+		return nil, errors.Errorf("digest mismatch %s: %s", dgst, h.cacheKey)
+	}
+	return ref, nil
+}
+
+// do sends an authorized request for the object and returns the response if
+// the request succeeded.
+func (h *objectStoreSourceHandler) do(ctx context.Context, g session.Group, method string) (*http.Response, error) {
+	req, err := h.newRequest(ctx, g, method)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := (&http.Client{Transport: h.transport}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errors.Errorf("failed to fetch %s: invalid response status %d", h.src.URL, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+func (h *objectStoreSourceHandler) newRequest(ctx context.Context, g session.Group, method string) (*http.Request, error) {
+	u, err := h.objectURL()
+	if err != nil {
+		return nil, err
+	}
+	if err := h.loadSecret(ctx, g); err != nil {
+		return nil, err
+	}
+	if h.src.Provider == srctypes.AzureBlobScheme && len(h.secret) > 0 {
+		// the secret is a shared access signature
+		u.RawQuery = strings.TrimPrefix(strings.TrimSpace(string(h.secret)), "?")
+	}
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if len(h.secret) == 0 {
+		return req, nil
+	}
+	switch h.src.Provider {
+	case srctypes.S3Scheme:
+		creds, err := parseAWSCredentials(h.secret)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid secret %s", h.src.Secret)
+		}
+		req.Header.Set("X-Amz-Content-Sha256", sigv4.EmptyPayloadHash)
+		sigv4.Sign(req, creds, h.region(), "s3", h.now())
+	case srctypes.GCSScheme:
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(h.secret)))
+	}
+	return req, nil
+}
+
+func (h *objectStoreSourceHandler) region() string {
+	if h.src.Region != "" {
+		return h.src.Region
+	}
+	return defaultS3Region
+}
+
+// objectURL returns the HTTPS URL of the object. The bucket is the first
+// element of the path with a custom endpoint.
+func (h *objectStoreSourceHandler) objectURL() (*url.URL, error) {
+	var base string
+	switch {
+	case h.src.Endpoint != "":
+		base = strings.TrimSuffix(h.src.Endpoint, "/") + "/" + h.src.Bucket
+	case h.src.Provider == srctypes.S3Scheme:
+		base = "https://" + h.src.Bucket + ".s3." + h.region() + ".amazonaws.com"
+	case h.src.Provider == srctypes.GCSScheme:
+		base = "https://storage.googleapis.com/" + h.src.Bucket
+	case h.src.Provider == srctypes.AzureBlobScheme:
+		base = "https://" + h.src.Account + ".blob.core.windows.net/" + h.src.Bucket
+	default:
+		return nil, errors.Errorf("unsupported object store %s", h.src.Provider)
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid endpoint %s", h.src.Endpoint)
+	}
+	segments := strings.Split(h.src.Key, "/")
+	escaped := make([]string, len(segments))
+	for i, s := range segments {
+		escaped[i] = sigv4.URIEncode(s)
+	}
+	basePath := strings.TrimSuffix(u.EscapedPath(), "/")
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + h.src.Key
+	u.RawPath = basePath + "/" + strings.Join(escaped, "/")
+	return u, nil
+}
+
+// loadSecret loads the credentials of the source from the session secrets.
+// Objects are fetched anonymously if the source has no secret.
+func (h *objectStoreSourceHandler) loadSecret(ctx context.Context, g session.Group) error {
+	if h.src.Secret == "" || h.secret != nil {
+		return nil
+	}
+	return h.sm.Any(ctx, g, func(ctx context.Context, _ string, caller session.Caller) error {
+		dt, err := secrets.GetSecret(ctx, caller, h.src.Secret)
+		if err != nil {
+			return errors.Wrapf(err, "failed to load secret %s", h.src.Secret)
+		}
+		h.secret = dt
+		return nil
+	})
+}
+
+// parseAWSCredentials parses the credentials from the environment variables
+// format, one AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+// assignment per line.
+func parseAWSCredentials(dt []byte) (sigv4.Credentials, error) {
+	var creds sigv4.Credentials
+	s := bufio.NewScanner(bytes.NewReader(dt))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		k, v, ok := cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			return sigv4.Credentials{}, errors.Errorf("invalid line %q", line)
+		}
+		v = strings.Trim(strings.TrimSpace(v), `"'`)
+		switch strings.ToUpper(strings.TrimSpace(k)) {
+		case "AWS_ACCESS_KEY_ID":
+			creds.AccessKeyID = v
+		case "AWS_SECRET_ACCESS_KEY":
+			creds.SecretAccessKey = v
+		case "AWS_SESSION_TOKEN":
+			creds.SessionToken = v
+		}
+	}
+	if err := s.Err(); err != nil {
+		return sigv4.Credentials{}, err
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return sigv4.Credentials{}, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required")
+	}
+	return creds, nil
+}
+
+func cut(s, sep string) (string, string, bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+func searchObjectDigest(ctx context.Context, store cache.MetadataStore, dgst digest.Digest) ([]cacheRefMetadata, error) {
+	var results []cacheRefMetadata
+	mds, err := store.Search(ctx, string(dgst))
+	if err != nil {
+		return nil, err
+	}
+	for _, md := range mds {
+		results = append(results, cacheRefMetadata{md})
+	}
+	return results, nil
+}
+
+type cacheRefMetadata struct {
+	cache.RefMetadata
+}
+
+const keyChecksum = "objectstore.checksum"
+const keyETag = "objectstore.etag"
+
+func (md cacheRefMetadata) getChecksum() digest.Digest {
+	return digest.Digest(md.GetString(keyChecksum))
+}
+
+func (md cacheRefMetadata) setChecksum(urlDgst digest.Digest, d digest.Digest) error {
+	return md.SetString(keyChecksum, d.String(), urlDgst.String())
+}
+
+func (md cacheRefMetadata) getETag() string {
+	return md.GetString(keyETag)
+}
+
+func (md cacheRefMetadata) setETag(s string) error {
+	return md.SetString(keyETag, s, "")
+}
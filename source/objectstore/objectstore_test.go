@@ -0,0 +1,93 @@
+package objectstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/source"
+	"github.com/moby/buildkit/util/sigv4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectURL(t *testing.T) {
+	cases := []struct {
+		url      string
+		region   string
+		endpoint string
+		expected string
+	}{
+		{
+			url:      "s3://bucket/dir/file.tar.gz",
+			expected: "https://bucket.s3.us-east-1.amazonaws.com/dir/file.tar.gz",
+		},
+		{
+			url:      "s3://bucket/a b+c",
+			region:   "eu-west-1",
+			expected: "https://bucket.s3.eu-west-1.amazonaws.com/a%20b%2Bc",
+		},
+		{
+			url:      "s3://bucket/file",
+			endpoint: "http://127.0.0.1:9000/",
+			expected: "http://127.0.0.1:9000/bucket/file",
+		},
+		{
+			url:      "gs://bucket/dir/file",
+			expected: "https://storage.googleapis.com/bucket/dir/file",
+		},
+		{
+			url:      "azblob://account/container/dir/file",
+			expected: "https://account.blob.core.windows.net/container/dir/file",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.url, func(t *testing.T) {
+			id, err := source.FromString(tc.url)
+			require.NoError(t, err)
+			osid := id.(*source.ObjectStoreIdentifier)
+			osid.Region = tc.region
+			osid.Endpoint = tc.endpoint
+			h := &objectStoreSourceHandler{src: *osid}
+			u, err := h.objectURL()
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, u.String())
+		})
+	}
+}
+
+func TestObjectStoreRequest(t *testing.T) {
+	now := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	newHandler := func(url string, secret string) *objectStoreSourceHandler {
+		id, err := source.FromString(url)
+		require.NoError(t, err)
+		return &objectStoreSourceHandler{
+			objectStoreSource: &objectStoreSource{now: func() time.Time { return now }},
+			src:               *id.(*source.ObjectStoreIdentifier),
+			secret:            []byte(secret),
+		}
+	}
+
+	req, err := newHandler("s3://bucket/file", "").newRequest(context.TODO(), nil, "GET")
+	require.NoError(t, err)
+	require.Empty(t, req.Header.Get("Authorization"))
+
+	req, err = newHandler("s3://bucket/file", "AWS_ACCESS_KEY_ID=AKID\nexport AWS_SECRET_ACCESS_KEY=\"secret\"\nAWS_SESSION_TOKEN=token\n").newRequest(context.TODO(), nil, "GET")
+	require.NoError(t, err)
+	require.Equal(t, "20220301T100000Z", req.Header.Get("X-Amz-Date"))
+	require.Equal(t, "token", req.Header.Get("X-Amz-Security-Token"))
+	require.Equal(t, sigv4.EmptyPayloadHash, req.Header.Get("X-Amz-Content-Sha256"))
+	require.Contains(t, req.Header.Get("Authorization"), "Credential=AKID/20220301/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token, Signature=")
+
+	_, err = newHandler("s3://bucket/file", "AWS_ACCESS_KEY_ID=AKID\n").newRequest(context.TODO(), nil, "GET")
+	require.Error(t, err)
+
+	req, err = newHandler("gs://bucket/file", "token\n").newRequest(context.TODO(), nil, "GET")
+	require.NoError(t, err)
+	require.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+
+	req, err = newHandler("azblob://account/container/file", "?sv=2020-10-02&sig=abc").newRequest(context.TODO(), nil, "HEAD")
+	require.NoError(t, err)
+	require.Equal(t, "https://account.blob.core.windows.net/container/file?sv=2020-10-02&sig=abc", req.URL.String())
+	require.Empty(t, req.Header.Get("Authorization"))
+}
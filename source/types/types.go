@@ -6,4 +6,11 @@ const (
 	LocalScheme       = "local"
 	HTTPScheme        = "http"
 	HTTPSScheme       = "https"
+	OCIScheme         = "oci-layout"
+	S3Scheme          = "s3"
+	GCSScheme         = "gs"
+	AzureBlobScheme   = "azblob"
+	// ObjectStoreScheme is the ID of the source of the s3, gs and azblob
+	// schemes.
+	ObjectStoreScheme = "objectstore"
 )
@@ -0,0 +1,85 @@
+package sourcepolicy
+
+import (
+	"github.com/moby/buildkit/solver/pb"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// Rewrite applies the policies to the source operations of the definition.
+// Operations are identified by the digest of their content, so the digests
+// of the converted sources and of the operations depending on them change
+// and are updated in the returned definition. The definition is returned
+// unchanged if no source was converted.
+func (e *Engine) Rewrite(def *pb.Definition) (*pb.Definition, error) {
+	if e == nil || def == nil || len(e.policies) == 0 {
+		return def, nil
+	}
+
+	var (
+		out      = make([][]byte, 0, len(def.Def))
+		digests  = map[digest.Digest]digest.Digest{}
+		modified bool
+	)
+	for _, dt := range def.Def {
+		var op pb.Op
+		if err := (&op).Unmarshal(dt); err != nil {
+			return nil, errors.Wrap(err, "failed to parse llb proto op")
+		}
+		dgst := digest.FromBytes(dt)
+
+		var mutated bool
+		for _, inp := range op.Inputs {
+			if d, ok := digests[inp.Digest]; ok {
+				inp.Digest = d
+				mutated = true
+			}
+		}
+		if src, ok := op.Op.(*pb.Op_Source); ok {
+			converted, err := e.Evaluate(src.Source)
+			if err != nil {
+				return nil, err
+			}
+			mutated = mutated || converted
+		}
+
+		if mutated {
+			var err error
+			dt, err = op.Marshal()
+			if err != nil {
+				return nil, err
+			}
+			digests[dgst] = digest.FromBytes(dt)
+			modified = true
+		}
+		out = append(out, dt)
+	}
+	if !modified {
+		return def, nil
+	}
+
+	ndef := &pb.Definition{
+		Def:      out,
+		Metadata: make(map[digest.Digest]pb.OpMetadata, len(def.Metadata)),
+		Source:   def.Source,
+	}
+	for dgst, md := range def.Metadata {
+		if d, ok := digests[dgst]; ok {
+			dgst = d
+		}
+		ndef.Metadata[dgst] = md
+	}
+	if def.Source != nil && len(def.Source.Locations) > 0 {
+		ndef.Source = &pb.Source{
+			Infos:     def.Source.Infos,
+			Locations: make(map[string]*pb.Locations, len(def.Source.Locations)),
+		}
+		for k, l := range def.Source.Locations {
+			if d, ok := digests[digest.Digest(k)]; ok {
+				k = d.String()
+			}
+			ndef.Source.Locations[k] = l
+		}
+	}
+	return ndef, nil
+}
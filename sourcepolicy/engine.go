@@ -0,0 +1,94 @@
+package sourcepolicy
+
+import (
+	"regexp"
+
+	"github.com/moby/buildkit/solver/pb"
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
+	"github.com/pkg/errors"
+)
+
+// ErrSourceDenied is returned for sources denied by a policy.
+var ErrSourceDenied = errors.New("source denied by policy")
+
+// Engine evaluates sources against a list of policies. The policies are
+// applied in order, every policy sees the source as converted by the
+// policies before it.
+type Engine struct {
+	policies [][]*rule
+}
+
+type rule struct {
+	*spb.Rule
+	re *regexp.Regexp
+}
+
+// NewEngine validates the policies and returns an engine evaluating them.
+func NewEngine(pols []*spb.Policy) (*Engine, error) {
+	e := &Engine{}
+	for _, pol := range pols {
+		if pol == nil {
+			continue
+		}
+		rules := make([]*rule, 0, len(pol.Rules))
+		for _, r := range pol.Rules {
+			re, err := compileSelector(r.Selector)
+			if err != nil {
+				return nil, err
+			}
+			if r.Action == spb.PolicyAction_CONVERT && (r.Updates == nil || r.Updates.Identifier == "" && len(r.Updates.Attrs) == 0) {
+				return nil, errors.Errorf("convert rule for %q has no updates", r.Selector.Identifier)
+			}
+			rules = append(rules, &rule{Rule: r, re: re})
+		}
+		e.policies = append(e.policies, rules)
+	}
+	return e, nil
+}
+
+// Evaluate applies the policies to the source operation, updating it in
+// place. It returns true if the source was converted and an error wrapping
+// ErrSourceDenied if a policy denies it.
+func (e *Engine) Evaluate(op *pb.SourceOp) (bool, error) {
+	if e == nil || op == nil {
+		return false, nil
+	}
+	var mutated bool
+	for _, rules := range e.policies {
+		for _, r := range rules {
+			m := r.re.FindStringSubmatchIndex(op.Identifier)
+			if m == nil {
+				continue
+			}
+			switch r.Action {
+			case spb.PolicyAction_DENY:
+				return mutated, errors.Wrapf(ErrSourceDenied, "%s", op.Identifier)
+			case spb.PolicyAction_CONVERT:
+				if id := r.Updates.Identifier; id != "" {
+					op.Identifier = string(r.re.ExpandString(nil, id, op.Identifier, m))
+				}
+				if len(r.Updates.Attrs) > 0 {
+					if op.Attrs == nil {
+						op.Attrs = map[string]string{}
+					}
+					for k, v := range r.Updates.Attrs {
+						op.Attrs[k] = v
+					}
+				}
+				mutated = true
+			}
+			break
+		}
+	}
+	return mutated, nil
+}
+
+// EvaluateIdentifier applies the policies to a source identifier without
+// attributes and returns the converted identifier.
+func (e *Engine) EvaluateIdentifier(id string) (string, error) {
+	op := &pb.SourceOp{Identifier: id}
+	if _, err := e.Evaluate(op); err != nil {
+		return "", err
+	}
+	return op.Identifier, nil
+}
@@ -0,0 +1,140 @@
+package sourcepolicy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/solver/pb"
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	e, err := NewEngine([]*spb.Policy{{
+		Rules: []*spb.Rule{
+			{
+				Action:   spb.PolicyAction_ALLOW,
+				Selector: &spb.Selector{Identifier: "docker-image://*@sha256:*"},
+			},
+			{
+				Action:   spb.PolicyAction_CONVERT,
+				Selector: &spb.Selector{Identifier: "docker-image://docker.io/*"},
+				Updates:  &spb.Update{Identifier: "docker-image://mirror.example.com/${1}"},
+			},
+			{
+				Action:   spb.PolicyAction_DENY,
+				Selector: &spb.Selector{Identifier: "docker-image://*"},
+			},
+			{
+				Action:   spb.PolicyAction_CONVERT,
+				Selector: &spb.Selector{Identifier: `^https://example\.com/(.+)\.tar$`, MatchType: spb.MatchType_REGEX},
+				Updates:  &spb.Update{Attrs: map[string]string{pb.AttrHTTPChecksum: "sha256:abc"}},
+			},
+		},
+	}})
+	require.NoError(t, err)
+
+	op := &pb.SourceOp{Identifier: "docker-image://docker.io/library/alpine:latest"}
+	mutated, err := e.Evaluate(op)
+	require.NoError(t, err)
+	require.True(t, mutated)
+	require.Equal(t, "docker-image://mirror.example.com/library/alpine:latest", op.Identifier)
+
+	// the first matching rule applies
+	id := "docker-image://docker.io/library/alpine:latest@sha256:" + digest.FromString("foo").Hex()
+	converted, err := e.EvaluateIdentifier(id)
+	require.NoError(t, err)
+	require.Equal(t, id, converted)
+
+	_, err = e.EvaluateIdentifier("docker-image://ghcr.io/foo/bar:latest")
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrSourceDenied))
+
+	op = &pb.SourceOp{Identifier: "https://example.com/foo.tar"}
+	mutated, err = e.Evaluate(op)
+	require.NoError(t, err)
+	require.True(t, mutated)
+	require.Equal(t, "https://example.com/foo.tar", op.Identifier)
+	require.Equal(t, "sha256:abc", op.Attrs[pb.AttrHTTPChecksum])
+
+	mutated, err = e.Evaluate(&pb.SourceOp{Identifier: "git://github.com/moby/buildkit.git"})
+	require.NoError(t, err)
+	require.False(t, mutated)
+}
+
+func TestNewEngineInvalid(t *testing.T) {
+	_, err := NewEngine([]*spb.Policy{{Rules: []*spb.Rule{{Action: spb.PolicyAction_DENY}}}})
+	require.Error(t, err)
+
+	_, err = NewEngine([]*spb.Policy{{Rules: []*spb.Rule{{
+		Action:   spb.PolicyAction_CONVERT,
+		Selector: &spb.Selector{Identifier: "docker-image://*"},
+	}}}})
+	require.Error(t, err)
+
+	_, err = NewEngine([]*spb.Policy{{Rules: []*spb.Rule{{
+		Action:   spb.PolicyAction_DENY,
+		Selector: &spb.Selector{Identifier: "(", MatchType: spb.MatchType_REGEX},
+	}}}})
+	require.Error(t, err)
+}
+
+func TestRewrite(t *testing.T) {
+	ctx := context.TODO()
+	st := llb.Image("docker.io/library/alpine:latest").
+		Run(llb.Shlex("true"), llb.WithCustomName("run")).Root()
+	def, err := st.Marshal(ctx)
+	require.NoError(t, err)
+	pbDef := def.ToPB()
+
+	e, err := NewEngine([]*spb.Policy{{
+		Rules: []*spb.Rule{{
+			Action:   spb.PolicyAction_CONVERT,
+			Selector: &spb.Selector{Identifier: "docker-image://docker.io/*"},
+			Updates:  &spb.Update{Identifier: "docker-image://mirror.example.com/${1}"},
+		}},
+	}})
+	require.NoError(t, err)
+
+	ndef, err := e.Rewrite(pbDef)
+	require.NoError(t, err)
+	require.Equal(t, len(pbDef.Def), len(ndef.Def))
+
+	ops := map[digest.Digest]*pb.Op{}
+	var last *pb.Op
+	for _, dt := range ndef.Def {
+		var op pb.Op
+		require.NoError(t, op.Unmarshal(dt))
+		ops[digest.FromBytes(dt)] = &op
+		last = &op
+	}
+	// the inputs refer to the rewritten operations
+	for _, op := range ops {
+		for _, inp := range op.Inputs {
+			require.Contains(t, ops, inp.Digest)
+		}
+	}
+	for dgst := range ndef.Metadata {
+		require.Contains(t, ops, dgst)
+	}
+
+	exec := ops[last.Inputs[0].Digest]
+	require.Equal(t, "run", ndef.Metadata[last.Inputs[0].Digest].Description["llb.customname"])
+	src := ops[exec.Inputs[0].Digest].Op.(*pb.Op_Source)
+	require.Equal(t, "docker-image://mirror.example.com/library/alpine:latest", src.Source.Identifier)
+
+	// definitions without matching sources are returned unchanged
+	e, err = NewEngine([]*spb.Policy{{
+		Rules: []*spb.Rule{{
+			Action:   spb.PolicyAction_DENY,
+			Selector: &spb.Selector{Identifier: "git://*"},
+		}},
+	}})
+	require.NoError(t, err)
+	ndef, err = e.Rewrite(pbDef)
+	require.NoError(t, err)
+	require.Equal(t, pbDef, ndef)
+}
@@ -0,0 +1,38 @@
+package sourcepolicy
+
+import (
+	"regexp"
+	"strings"
+
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
+	"github.com/pkg/errors"
+)
+
+// compileSelector returns the regular expression matching the identifiers
+// selected by the selector. Every `*` of a wildcard selector is a capture
+// group that can be referenced by the updates of the rule.
+func compileSelector(sel *spb.Selector) (*regexp.Regexp, error) {
+	if sel == nil {
+		return nil, errors.New("rule has no selector")
+	}
+	var expr string
+	switch sel.MatchType {
+	case spb.MatchType_WILDCARD:
+		parts := strings.Split(sel.Identifier, "*")
+		for i, p := range parts {
+			parts[i] = regexp.QuoteMeta(p)
+		}
+		expr = "^" + strings.Join(parts, "(.*)") + "$"
+	case spb.MatchType_EXACT:
+		expr = "^" + regexp.QuoteMeta(sel.Identifier) + "$"
+	case spb.MatchType_REGEX:
+		expr = sel.Identifier
+	default:
+		return nil, errors.Errorf("unknown match type %v", sel.MatchType)
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid selector %q", sel.Identifier)
+	}
+	return re, nil
+}
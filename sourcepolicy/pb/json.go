@@ -0,0 +1,37 @@
+package moby_buildkit_v1_sourcepolicy //nolint:revive
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MarshalText encodes the action by its name.
+func (a PolicyAction) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText decodes the action from its case insensitive name.
+func (a *PolicyAction) UnmarshalText(dt []byte) error {
+	v, ok := PolicyAction_value[strings.ToUpper(string(dt))]
+	if !ok {
+		return errors.Errorf("invalid source policy action %q", dt)
+	}
+	*a = PolicyAction(v)
+	return nil
+}
+
+// MarshalText encodes the match type by its name.
+func (t MatchType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText decodes the match type from its case insensitive name.
+func (t *MatchType) UnmarshalText(dt []byte) error {
+	v, ok := MatchType_value[strings.ToUpper(string(dt))]
+	if !ok {
+		return errors.Errorf("invalid source policy match type %q", dt)
+	}
+	*t = MatchType(v)
+	return nil
+}
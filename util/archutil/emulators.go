@@ -0,0 +1,128 @@
+package archutil
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// EmulatorBinfmt is an emulator registered with binfmt_misc in the
+	// kernel. It also runs the foreign binaries started by the processes of
+	// the containers.
+	EmulatorBinfmt = "binfmt"
+	// EmulatorStatic is a buildkit-qemu-* binary in the $PATH of the daemon
+	// that runs the processes of the containers when no emulator is
+	// registered in the kernel.
+	EmulatorStatic = "static"
+)
+
+var qemuArchMap = map[string]string{
+	"arm64":    "aarch64",
+	"amd64":    "x86_64",
+	"riscv64":  "riscv64",
+	"arm":      "arm",
+	"s390x":    "s390x",
+	"ppc64le":  "ppc64le",
+	"386":      "i386",
+	"mips64le": "mips64el",
+	"mips64":   "mips64",
+}
+
+// Emulator is an emulator available to run the processes of a platform that
+// the host can't run natively.
+type Emulator struct {
+	Platform ocispecs.Platform
+	// Type is EmulatorBinfmt or EmulatorStatic.
+	Type string
+	// Path is the path of the binary of the emulator.
+	Path string
+}
+
+var emuMu sync.Mutex
+var emulators []Emulator
+
+// QemuArch returns the name QEMU uses for the architecture of a platform.
+func QemuArch(arch string) string {
+	if a, ok := qemuArchMap[arch]; ok {
+		return a
+	}
+	return arch
+}
+
+// Emulators returns the emulators available on the host. The emulators
+// registered in the kernel are checked by running a test binary of their
+// platform, like SupportedPlatforms.
+func Emulators(noCache bool) []Emulator {
+	emuMu.Lock()
+	defer emuMu.Unlock()
+	if !noCache && emulators != nil {
+		return emulators
+	}
+	def := nativePlatform()
+	out := []Emulator{}
+	if def.OS != "linux" {
+		emulators = out
+		return out
+	}
+	registered := map[string]struct{}{}
+	for _, p := range SupportedPlatforms(noCache) {
+		if p.Architecture == def.Architecture {
+			continue
+		}
+		if _, ok := registered[p.Architecture]; ok {
+			continue
+		}
+		// the platforms the host runs natively, e.g. 386 on amd64, have no
+		// interpreter registered
+		path, ok := binfmtInterpreter(QemuArch(p.Architecture))
+		if !ok {
+			continue
+		}
+		registered[p.Architecture] = struct{}{}
+		out = append(out, Emulator{Platform: linux(p.Architecture), Type: EmulatorBinfmt, Path: path})
+	}
+	for _, arch := range []string{"amd64", "arm64", "riscv64", "ppc64le", "s390x", "386", "mips64le", "mips64", "arm"} {
+		if arch == def.Architecture {
+			continue
+		}
+		if _, ok := registered[arch]; ok {
+			continue
+		}
+		path, err := exec.LookPath("buildkit-qemu-" + QemuArch(arch))
+		if err != nil {
+			continue
+		}
+		out = append(out, Emulator{Platform: linux(arch), Type: EmulatorStatic, Path: path})
+	}
+	emulators = out
+	return out
+}
+
+// binfmtInterpreter returns the interpreter of the qemu handler of arch
+// registered with binfmt_misc.
+func binfmtInterpreter(arch string) (string, bool) {
+	f, err := os.Open(filepath.Join("/proc/sys/fs/binfmt_misc", "qemu-"+arch))
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	enabled := false
+	var path string
+	for s.Scan() {
+		line := s.Text()
+		switch {
+		case line == "enabled":
+			enabled = true
+		case strings.HasPrefix(line, "interpreter "):
+			path = strings.TrimPrefix(line, "interpreter ")
+		}
+	}
+	return path, enabled
+}
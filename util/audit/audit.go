@@ -0,0 +1,192 @@
+// Package audit writes the records of the builds and the prunes run by the
+// daemon, for the review of shared builders.
+package audit
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/moby/buildkit/util/tenant"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	ActionSolve = "solve"
+	ActionPrune = "prune"
+)
+
+// Record is the audit record of a request to the daemon.
+type Record struct {
+	// Time is the time the request completed.
+	Time    time.Time `json:"time"`
+	Started time.Time `json:"started"`
+	Action  string    `json:"action"`
+	Client  Client    `json:"client"`
+	// Ref is the ID of the build.
+	Ref      string `json:"ref,omitempty"`
+	Frontend string `json:"frontend,omitempty"`
+	// Sources are the identifiers of the sources of the build, after the
+	// source policies.
+	Sources []string `json:"sources,omitempty"`
+	// Secrets are the IDs of the secrets mounted by the build.
+	Secrets []string `json:"secrets,omitempty"`
+	// SSH are the IDs of the SSH agents and sockets forwarded to the build.
+	SSH       []string   `json:"ssh,omitempty"`
+	Exporters []Exporter `json:"exporters,omitempty"`
+	Prune     *Prune     `json:"prune,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// Client identifies the client of a request.
+type Client struct {
+	Address string `json:"address,omitempty"`
+	// CommonName is the common name of the verified client certificate.
+	CommonName string `json:"commonName,omitempty"`
+	Tenant     string `json:"tenant,omitempty"`
+}
+
+// Exporter is an exporter of a build and the destination of its result.
+type Exporter struct {
+	Type string `json:"type"`
+	// Name is the name of the exported images.
+	Name string `json:"name,omitempty"`
+	Push bool   `json:"push,omitempty"`
+}
+
+// Prune are the options and the result of a prune.
+type Prune struct {
+	Filters      []string      `json:"filters,omitempty"`
+	All          bool          `json:"all,omitempty"`
+	KeepDuration time.Duration `json:"keepDuration,omitempty"`
+	KeepBytes    int64         `json:"keepBytes,omitempty"`
+	// Records is the number of pruned records.
+	Records int `json:"records"`
+	// Size is the size of the pruned records.
+	Size int64 `json:"size"`
+}
+
+// Logger writes audit records.
+type Logger interface {
+	Log(Record) error
+	Close() error
+}
+
+type multiLogger []Logger
+
+// MultiLogger returns a logger writing the records to all the loggers.
+func MultiLogger(loggers ...Logger) Logger {
+	return multiLogger(loggers)
+}
+
+func (l multiLogger) Log(rec Record) error {
+	var firstErr error
+	for _, logger := range l {
+		if err := logger.Log(rec); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (l multiLogger) Close() error {
+	var firstErr error
+	for _, logger := range l {
+		if err := logger.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// ClientFromContext returns the client of the request of the context.
+func ClientFromContext(ctx context.Context) Client {
+	c := Client{Tenant: tenant.FromContext(ctx)}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return c
+	}
+	if p.Addr != nil {
+		c.Address = p.Addr.String()
+	}
+	if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		for _, chain := range info.State.VerifiedChains {
+			if len(chain) > 0 {
+				c.CommonName = chain[0].Subject.CommonName
+				break
+			}
+		}
+	}
+	return c
+}
+
+// Usage collects the sources, the secrets and the SSH IDs used by the
+// definitions of a build.
+type Usage struct {
+	mu      sync.Mutex
+	sources map[string]struct{}
+	secrets map[string]struct{}
+	ssh     map[string]struct{}
+}
+
+// AddSource records a source. The credentials of URLs are not recorded.
+func (u *Usage) AddSource(id string) {
+	u.add(&u.sources, redactURL(id))
+}
+
+// AddSecret records the ID of a secret.
+func (u *Usage) AddSecret(id string) {
+	u.add(&u.secrets, id)
+}
+
+// AddSSH records the ID of an SSH agent or socket.
+func (u *Usage) AddSSH(id string) {
+	u.add(&u.ssh, id)
+}
+
+func (u *Usage) add(m *map[string]struct{}, v string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if *m == nil {
+		*m = map[string]struct{}{}
+	}
+	(*m)[v] = struct{}{}
+}
+
+// Fill sets the sources, the secrets and the SSH IDs of the record.
+func (u *Usage) Fill(rec *Record) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	rec.Sources = sorted(u.sources)
+	rec.Secrets = sorted(u.secrets)
+	rec.SSH = sorted(u.ssh)
+}
+
+func sorted(m map[string]struct{}) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(m))
+	for v := range m {
+		out = append(out, v)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// redactURL removes the user info of the URLs of the source identifiers.
+func redactURL(id string) string {
+	if !strings.Contains(id, "://") {
+		return id
+	}
+	u, err := url.Parse(id)
+	if err != nil || u.User == nil {
+		return id
+	}
+	u.User = nil
+	return u.String()
+}
@@ -0,0 +1,41 @@
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+type fileLogger struct {
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+// NewFileLogger returns a logger appending the records to the file as JSON
+// lines.
+func NewFileLogger(p string) (Logger, error) {
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return nil, errors.Wrapf(err, "failed to create directory of audit log %s", p)
+	}
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open audit log %s", p)
+	}
+	return &fileLogger{f: f, enc: json.NewEncoder(f)}, nil
+}
+
+func (l *fileLogger) Log(rec Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return errors.Wrap(l.enc.Encode(rec), "failed to write audit record")
+}
+
+func (l *fileLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Close()
+}
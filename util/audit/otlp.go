@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type otlpLogger struct {
+	tp     *sdktrace.TracerProvider
+	tracer trace.Tracer
+}
+
+// NewOTLPLogger returns a logger sending the records as spans to the OTLP
+// gRPC endpoint, with the attributes of the fields of the records.
+func NewOTLPLogger(ctx context.Context, endpoint string, insecure bool) (Logger, error) {
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint)}
+	if insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	exp, err := otlptrace.New(ctx, otlptracegrpc.NewClient(opts...))
+	if err != nil {
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String("buildkitd"))),
+	)
+	return &otlpLogger{tp: tp, tracer: tp.Tracer("github.com/moby/buildkit/util/audit")}, nil
+}
+
+func (l *otlpLogger) Log(rec Record) error {
+	attrs := []attribute.KeyValue{
+		attribute.String("audit.action", rec.Action),
+		attribute.String("audit.client.address", rec.Client.Address),
+		attribute.String("audit.client.common_name", rec.Client.CommonName),
+		attribute.String("audit.client.tenant", rec.Client.Tenant),
+		attribute.String("audit.ref", rec.Ref),
+		attribute.String("audit.frontend", rec.Frontend),
+		attribute.StringSlice("audit.sources", rec.Sources),
+		attribute.StringSlice("audit.secrets", rec.Secrets),
+		attribute.StringSlice("audit.ssh", rec.SSH),
+		attribute.String("audit.error", rec.Error),
+	}
+	if len(rec.Exporters) > 0 {
+		dt, err := json.Marshal(rec.Exporters)
+		if err != nil {
+			return err
+		}
+		attrs = append(attrs, attribute.String("audit.exporters", string(dt)))
+	}
+	if rec.Prune != nil {
+		dt, err := json.Marshal(rec.Prune)
+		if err != nil {
+			return err
+		}
+		attrs = append(attrs, attribute.String("audit.prune", string(dt)))
+	}
+	_, span := l.tracer.Start(context.Background(), "audit."+rec.Action, trace.WithTimestamp(rec.Started), trace.WithAttributes(attrs...))
+	span.End(trace.WithTimestamp(rec.Time))
+	return nil
+}
+
+func (l *otlpLogger) Close() error {
+	return l.tp.Shutdown(context.Background())
+}
@@ -0,0 +1,158 @@
+// Package bandwidth limits the transfer rates of a build: the upload of its
+// local contexts from the client and the pushes and pulls of registry
+// content.
+package bandwidth
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+// maxChunk is the most bytes waited for at once, so that the transfers of
+// slow limits proceed smoothly.
+const maxChunk = 32 * 1024
+
+// Limits are bandwidth limits in bytes per second. Zero values are
+// unlimited.
+type Limits struct {
+	// Context limits the transfer of the local contexts from the client.
+	Context int64
+	// Push limits the blobs pushed to registries.
+	Push int64
+	// Pull limits the content pulled from registries.
+	Pull int64
+}
+
+// Limiters are the rate limiters of the limits of a build, shared by all its
+// transfers. Nil limiters are unlimited.
+type Limiters struct {
+	Context *rate.Limiter
+	Push    *rate.Limiter
+	Pull    *rate.Limiter
+}
+
+// NewLimiters returns the limiters of the limits, nil if no limit is set.
+func NewLimiters(l Limits) *Limiters {
+	if l == (Limits{}) {
+		return nil
+	}
+	return &Limiters{
+		Context: newLimiter(l.Context),
+		Push:    newLimiter(l.Push),
+		Pull:    newLimiter(l.Pull),
+	}
+}
+
+func newLimiter(limit int64) *rate.Limiter {
+	if limit <= 0 {
+		return nil
+	}
+	burst := maxChunk
+	if limit < maxChunk {
+		burst = int(limit)
+	}
+	return rate.NewLimiter(rate.Limit(limit), burst)
+}
+
+type limitersKey struct{}
+
+// WithLimiters returns a context the transfers of which are limited by the
+// limiters.
+func WithLimiters(ctx context.Context, l *Limiters) context.Context {
+	if l == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, limitersKey{}, l)
+}
+
+// FromContext returns the limiters of the context, nil if it has none.
+func FromContext(ctx context.Context) *Limiters {
+	l, _ := ctx.Value(limitersKey{}).(*Limiters)
+	return l
+}
+
+// Wait waits until the limiter allows n bytes to be transferred. A nil
+// limiter doesn't wait.
+func Wait(ctx context.Context, l *rate.Limiter, n int) error {
+	if l == nil {
+		return nil
+	}
+	for n > 0 {
+		c := n
+		if c > l.Burst() {
+			c = l.Burst()
+		}
+		if err := l.WaitN(ctx, c); err != nil {
+			return err
+		}
+		n -= c
+	}
+	return nil
+}
+
+// NewReader returns a reader limited by the limiter. The reader is returned
+// as is for a nil limiter.
+func NewReader(ctx context.Context, r io.Reader, l *rate.Limiter) io.Reader {
+	if l == nil {
+		return r
+	}
+	return &reader{ctx: ctx, r: r, l: l}
+}
+
+type reader struct {
+	ctx context.Context
+	r   io.Reader
+	l   *rate.Limiter
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if len(p) > r.l.Burst() {
+		p = p[:r.l.Burst()]
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if werr := Wait(r.ctx, r.l, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// NewTransport returns a transport limiting the bodies of the requests with
+// the push limiter and the bodies of the responses with the pull limiter of
+// the limiters of the request context.
+func NewTransport(rt http.RoundTripper) http.RoundTripper {
+	return &transport{rt: rt}
+}
+
+type transport struct {
+	rt http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	l := FromContext(ctx)
+	if l == nil {
+		return t.rt.RoundTrip(req)
+	}
+	if l.Push != nil && req.Body != nil && req.Body != http.NoBody {
+		req = req.Clone(ctx)
+		req.Body = &readCloser{Reader: NewReader(ctx, req.Body, l.Push), Closer: req.Body}
+	}
+	resp, err := t.rt.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if l.Pull != nil && resp.Body != nil {
+		resp.Body = &readCloser{Reader: NewReader(ctx, resp.Body, l.Pull), Closer: resp.Body}
+	}
+	return resp, nil
+}
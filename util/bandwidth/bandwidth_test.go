@@ -0,0 +1,87 @@
+package bandwidth
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLimiters(t *testing.T) {
+	t.Parallel()
+
+	require.Nil(t, NewLimiters(Limits{}))
+
+	l := NewLimiters(Limits{Push: 1024, Pull: 1 << 20})
+	require.Nil(t, l.Context)
+	require.Equal(t, 1024, l.Push.Burst())
+	require.Equal(t, maxChunk, l.Pull.Burst())
+
+	ctx := context.TODO()
+	require.Nil(t, FromContext(ctx))
+	require.Equal(t, ctx, WithLimiters(ctx, nil))
+	require.Equal(t, l, FromContext(WithLimiters(ctx, l)))
+}
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	l := NewLimiters(Limits{Pull: 10 * 1024})
+	data := bytes.Repeat([]byte("a"), 15*1024)
+
+	// the first burst of 10KiB is immediate, the next 5KiB take 500ms
+	start := time.Now()
+	dt, err := ioutil.ReadAll(NewReader(ctx, bytes.NewReader(data), l.Pull))
+	require.NoError(t, err)
+	require.Equal(t, data, dt)
+	require.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+
+	r := bytes.NewReader(data)
+	require.Equal(t, io.Reader(r), NewReader(ctx, r, nil))
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = ioutil.ReadAll(NewReader(ctx, bytes.NewReader(data), NewLimiters(Limits{Pull: 1024}).Pull))
+	require.Error(t, err)
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTransport(t *testing.T) {
+	t.Parallel()
+
+	var reqBody io.Reader
+	tr := NewTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		reqBody = req.Body
+		return &http.Response{Body: ioutil.NopCloser(bytes.NewReader([]byte("response")))}, nil
+	}))
+
+	req, err := http.NewRequest(http.MethodPut, "https://example.com/v2/", bytes.NewReader([]byte("request")))
+	require.NoError(t, err)
+	resp, err := tr.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, req.Body, reqBody)
+	_, ok := resp.Body.(*readCloser)
+	require.False(t, ok)
+
+	ctx := WithLimiters(context.TODO(), NewLimiters(Limits{Push: 1024, Pull: 1024}))
+	resp, err = tr.RoundTrip(req.WithContext(ctx))
+	require.NoError(t, err)
+	_, ok = reqBody.(*readCloser)
+	require.True(t, ok)
+	dt, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "response", string(dt))
+	_, ok = resp.Body.(*readCloser)
+	require.True(t, ok)
+}
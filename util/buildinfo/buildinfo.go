@@ -4,14 +4,18 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"net/url"
 	"sort"
 	"strings"
 
 	"github.com/docker/distribution/reference"
 	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/source"
+	srctypes "github.com/moby/buildkit/source/types"
 	binfotypes "github.com/moby/buildkit/util/buildinfo/types"
 	"github.com/moby/buildkit/util/urlutil"
+	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
 )
 
@@ -25,8 +29,20 @@ func Decode(enc string) (bi binfotypes.BuildInfo, _ error) {
 	return bi, err
 }
 
+// EncodeOpt holds the values of the result recorded in the build info.
+type EncodeOpt struct {
+	// BuildSources are the sources of the result with their pins.
+	BuildSources map[string]string
+	// Definition is the LLB definition of the result.
+	Definition *pb.Definition
+	// CacheInfo is the cache used by the build.
+	CacheInfo *binfotypes.CacheInfo
+	// AttrsPolicy filters the recorded attributes, DefaultAttrsPolicy if nil.
+	AttrsPolicy *AttrsPolicy
+}
+
 // Encode encodes build info.
-func Encode(ctx context.Context, metadata map[string][]byte, key string, buildSources map[string]string) ([]byte, error) {
+func Encode(ctx context.Context, metadata map[string][]byte, key string, opt EncodeOpt) ([]byte, error) {
 	var bi binfotypes.BuildInfo
 	if metadata == nil {
 		metadata = make(map[string][]byte)
@@ -41,20 +57,100 @@ func Encode(ctx context.Context, metadata map[string][]byte, key string, buildSo
 	} else {
 		return nil, err
 	}
-	if sources, err := mergeSources(ctx, buildSources, bi.Sources); err == nil {
+	if deps, err := decodePlatformDeps(bi.Attrs); err == nil {
+		bi.PlatformDeps = reducePlatformDeps(deps, bi.PlatformDeps)
+	} else {
+		return nil, err
+	}
+	if sources, err := mergeSources(ctx, opt.BuildSources, bi.Sources); err == nil {
 		bi.Sources = sources
 	} else {
 		return nil, err
 	}
-	bi.Attrs = filterAttrs(key, bi.Attrs)
+	bi.Attrs = filterAttrs(key, bi.Attrs, opt.AttrsPolicy)
+	if opt.Definition != nil {
+		dgst, err := DefinitionDigest(opt.Definition)
+		if err != nil {
+			return nil, err
+		}
+		bi.Definition = dgst.String()
+	}
+	if opt.CacheInfo != nil {
+		bi.Cache = opt.CacheInfo
+	}
 	return json.Marshal(bi)
 }
 
+// DefinitionDigest returns the digest of the marshaled LLB definition.
+func DefinitionDigest(def *pb.Definition) (digest.Digest, error) {
+	dt, err := def.Marshal()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal definition")
+	}
+	return digest.FromBytes(dt), nil
+}
+
+// CacheRef returns the cache backend of the cache import or export options.
+// Local paths are not recorded.
+func CacheRef(typ string, attrs map[string]string) binfotypes.CacheRef {
+	ref := binfotypes.CacheRef{Type: typ}
+	for _, k := range []string{"ref", "scope"} {
+		if v := attrs[k]; v != "" {
+			ref.Ref = v
+			break
+		}
+	}
+	return ref
+}
+
+// recordedAttrs are the source attributes recorded as options of the sources,
+// with their option names.
+var recordedAttrs = map[string]string{
+	pb.AttrGitDepth:         "depth",
+	pb.AttrGitFilter:        "filter",
+	pb.AttrGitSparsePaths:   "sparse",
+	pb.AttrOCILayoutStoreID: "store",
+}
+
+// SourceKey returns the key of the source in the build sources, the
+// identifier followed by the attributes recorded as options.
+func SourceKey(op *pb.SourceOp) string {
+	v := url.Values{}
+	for k := range recordedAttrs {
+		if a, ok := op.Attrs[k]; ok {
+			v.Set(k, a)
+		}
+	}
+	if len(v) == 0 {
+		return op.Identifier
+	}
+	return op.Identifier + " " + v.Encode()
+}
+
+func parseSourceKey(key string) (string, map[string]string) {
+	parts := strings.SplitN(key, " ", 2)
+	if len(parts) != 2 {
+		return key, nil
+	}
+	v, err := url.ParseQuery(parts[1])
+	if err != nil {
+		return parts[0], nil
+	}
+	opts := map[string]string{}
+	for k, name := range recordedAttrs {
+		if a := v.Get(k); a != "" {
+			opts[name] = a
+		}
+	}
+	return parts[0], opts
+}
+
 // mergeSources combines and fixes build sources from frontend sources.
 func mergeSources(ctx context.Context, buildSources map[string]string, frontendSources []binfotypes.Source) ([]binfotypes.Source, error) {
 	// Iterate and combine build sources
 	mbs := map[string]binfotypes.Source{}
 	for buildSource, pin := range buildSources {
+		buildSource, opts := parseSourceKey(buildSource)
 		src, err := source.FromString(buildSource)
 		if err != nil {
 			return nil, err
@@ -96,8 +192,28 @@ func mergeSources(ctx context.Context, buildSources map[string]string, frontendS
 			}
 			if _, ok := mbs[sref]; !ok {
 				mbs[sref] = binfotypes.Source{
-					Type: binfotypes.SourceTypeGit,
-					Ref:  urlutil.RedactCredentials(sref),
+					Type:    binfotypes.SourceTypeGit,
+					Ref:     urlutil.RedactCredentials(sref),
+					Pin:     pin,
+					Options: opts,
+				}
+			}
+		case *source.OCIIdentifier:
+			// images of different stores can have the same reference
+			sref := srctypes.OCIScheme + "://" + sourceID.Reference.String() + " " + opts["store"]
+			if _, ok := mbs[sref]; !ok {
+				mbs[sref] = binfotypes.Source{
+					Type:    binfotypes.SourceTypeOCILayout,
+					Ref:     sourceID.Reference.String(),
+					Pin:     pin,
+					Options: opts,
+				}
+			}
+		case *source.ObjectStoreIdentifier:
+			if _, ok := mbs[sourceID.URL]; !ok {
+				mbs[sourceID.URL] = binfotypes.Source{
+					Type: binfotypes.SourceTypeObjectStore,
+					Ref:  sourceID.URL,
 					Pin:  pin,
 				}
 			}
@@ -197,9 +313,84 @@ func decodeDeps(key string, attrs map[string]*string) (map[string]binfotypes.Bui
 	return res, nil
 }
 
+// decodePlatformDeps decodes the dependencies added via the input contexts of
+// all the platforms, keyed by context name and platform. Unlike decodeDeps,
+// the dependencies of the other platforms than the one of the metadata key
+// are kept.
+func decodePlatformDeps(attrs map[string]*string) (map[string]map[string]binfotypes.BuildInfo, error) {
+	res := make(map[string]map[string]binfotypes.BuildInfo)
+	for k, v := range attrs {
+		if v == nil || !strings.HasPrefix(k, "input-metadata:") {
+			continue
+		}
+		name := strings.TrimPrefix(k, "input-metadata:")
+		i := strings.LastIndex(name, "::")
+		if i < 0 {
+			continue
+		}
+		name, platform := name[:i], name[i+2:]
+
+		var inputresp map[string]string
+		if err := json.Unmarshal([]byte(*v), &inputresp); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal input-metadata")
+		}
+		if _, ok := inputresp[exptypes.ExporterBuildInfo]; !ok {
+			continue
+		}
+		bi, err := Decode(inputresp[exptypes.ExporterBuildInfo])
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode buildinfo from input-metadata")
+		}
+		if res[name] == nil {
+			res[name] = make(map[string]binfotypes.BuildInfo)
+		}
+		res[name][platform] = bi
+	}
+	if len(res) == 0 {
+		return nil, nil
+	}
+	return res, nil
+}
+
+// DepsForPlatform returns the dependencies of the input contexts of the build
+// for the platform, e.g. linux/arm64, keyed by context name. The dependencies
+// recorded without platform are returned for the contexts that don't have
+// per-platform dependencies.
+func DepsForPlatform(bi *binfotypes.BuildInfo, platform string) map[string]binfotypes.BuildInfo {
+	res := make(map[string]binfotypes.BuildInfo)
+	for name, dep := range bi.Deps {
+		if _, ok := bi.PlatformDeps[name]; ok || strings.Contains(name, "::") {
+			continue
+		}
+		res[name] = dep
+	}
+	for name, deps := range bi.PlatformDeps {
+		if dep, ok := deps[platform]; ok {
+			res[name] = dep
+		}
+	}
+	return res
+}
+
+// DepPlatforms returns the sorted platforms the dependency of the input
+// context was recorded for.
+func DepPlatforms(bi *binfotypes.BuildInfo, name string) []string {
+	var platforms []string
+	for p := range bi.PlatformDeps[name] {
+		platforms = append(platforms, p)
+	}
+	sort.Strings(platforms)
+	return platforms
+}
+
 // FormatOpts holds build info format options.
 type FormatOpts struct {
 	RemoveAttrs bool
+	// Attrs further filters the recorded attributes if they aren't removed.
+	Attrs *AttrsPolicy
+	// RemoveCache removes the cache info, which differs between builds of
+	// the same result.
+	RemoveCache bool
 }
 
 // Format formats build info.
@@ -213,6 +404,11 @@ func Format(dt []byte, format FormatOpts) (_ []byte, err error) {
 	}
 	if format.RemoveAttrs {
 		bi.Attrs = nil
+	} else if format.Attrs != nil {
+		bi.Attrs = format.Attrs.Filter(bi.Attrs)
+	}
+	if format.RemoveCache {
+		bi.Cache = nil
 	}
 	if dt, err = json.Marshal(bi); err != nil {
 		return nil, err
@@ -220,6 +416,24 @@ func Format(dt []byte, format FormatOpts) (_ []byte, err error) {
 	return dt, nil
 }
 
+// SBOM returns the packages recorded in the build info as a SBOM
+// attestation, or nil if the result wasn't scanned.
+func SBOM(dt []byte) ([]byte, error) {
+	if len(dt) == 0 {
+		return nil, nil
+	}
+	var bi binfotypes.BuildInfo
+	if err := json.Unmarshal(dt, &bi); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal buildinfo for sbom")
+	}
+	if len(bi.Packages) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(struct {
+		Packages []binfotypes.Package `json:"packages"`
+	}{bi.Packages})
+}
+
 var knownAttrs = []string{
 	//"cmdline",
 	"context",
@@ -237,9 +451,169 @@ var knownAttrs = []string{
 	"ulimit",
 }
 
-// filterAttrs filters frontent opt by picking only those that
-// could effectively change the build result.
-func filterAttrs(key string, attrs map[string]*string) map[string]*string {
+// DefaultAttrsPolicy records the frontend attributes that could effectively
+// change the build result.
+var DefaultAttrsPolicy = AttrsPolicy{
+	Allow: append([]string{"build-arg:*", "label:*", "context:*"}, knownAttrs...),
+}
+
+// defaultRedactAttrs match the build args likely to hold credentials. They
+// are redacted unless the policy sets NoDefaultRedact.
+var defaultRedactAttrs = []string{
+	"build-arg:*TOKEN*",
+	"build-arg:*PASSWORD*",
+	"build-arg:*PASSWD*",
+	"build-arg:*SECRET*",
+	"build-arg:*CREDENTIAL*",
+	"build-arg:*APIKEY*",
+	"build-arg:*API_KEY*",
+	"build-arg:*_KEY",
+	"build-arg:*PRIVATE_KEY*",
+	"build-arg:*ACCESS_KEY*",
+}
+
+// redactedPrefix marks the redacted attribute values, followed by the digest
+// of the value so that builds with different values can still be told apart.
+const redactedPrefix = "redacted:"
+
+// AttrsPolicy selects the frontend attributes recorded in the build info.
+// Patterns match attribute keys, * matching any sequence of characters, e.g.
+// build-arg:SECRET_*. The attributes matching a Deny pattern are dropped, then
+// the ones matching an Allow pattern are kept. All the attributes are allowed
+// if Allow is empty. The values of the kept attributes matching a Redact
+// pattern, compared case-insensitively, are replaced with their digest.
+type AttrsPolicy struct {
+	Allow  []string
+	Deny   []string
+	Redact []string
+	// NoDefaultRedact disables the redaction of the build args whose names
+	// look like they hold credentials, e.g. GITHUB_TOKEN or DB_PASSWORD,
+	// when recording the attributes.
+	NoDefaultRedact bool
+}
+
+// ParseAttrsPolicy parses a policy from its
+// allow:<patterns>;deny:<patterns>;redact:<patterns> form, patterns being
+// comma separated, e.g. allow:target,build-arg:*;deny:build-arg:SECRET_*.
+func ParseAttrsPolicy(s string) (*AttrsPolicy, error) {
+	var p AttrsPolicy
+	for _, field := range strings.Split(s, ";") {
+		if strings.TrimSpace(field) == "" {
+			continue
+		}
+		parts := strings.SplitN(field, ":", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("invalid buildinfo attrs policy %q, expected allow:, deny: or redact:<patterns>", field)
+		}
+		var patterns []string
+		for _, pattern := range strings.Split(parts[1], ",") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				patterns = append(patterns, pattern)
+			}
+		}
+		switch strings.TrimSpace(parts[0]) {
+		case "allow":
+			p.Allow = append(p.Allow, patterns...)
+		case "deny":
+			p.Deny = append(p.Deny, patterns...)
+		case "redact":
+			p.Redact = append(p.Redact, patterns...)
+		default:
+			return nil, errors.Errorf("invalid buildinfo attrs policy %q, expected allow:, deny: or redact:<patterns>", field)
+		}
+	}
+	return &p, nil
+}
+
+// Allowed returns whether the attribute is recorded by the policy.
+func (p *AttrsPolicy) Allowed(k string) bool {
+	for _, pattern := range p.Deny {
+		if matchAttr(pattern, k) {
+			return false
+		}
+	}
+	if len(p.Allow) == 0 {
+		return true
+	}
+	for _, pattern := range p.Allow {
+		if matchAttr(pattern, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the attributes allowed by the policy, with the values of the
+// ones matching a Redact pattern redacted.
+func (p *AttrsPolicy) Filter(attrs map[string]*string) map[string]*string {
+	if attrs == nil {
+		return nil
+	}
+	filtered := make(map[string]*string)
+	for k, v := range attrs {
+		if p.Allowed(k) {
+			filtered[k] = p.redact(k, v)
+		}
+	}
+	return filtered
+}
+
+// redact returns the value of the attribute, replaced with the redaction
+// marker and the digest of the value if it matches a Redact pattern.
+func (p *AttrsPolicy) redact(k string, v *string) *string {
+	if v == nil || strings.HasPrefix(*v, redactedPrefix) {
+		return v
+	}
+	for _, pattern := range p.Redact {
+		if matchAttr(strings.ToUpper(pattern), strings.ToUpper(k)) {
+			redacted := redactedPrefix + digest.FromString(*v).String()
+			return &redacted
+		}
+	}
+	return v
+}
+
+// matchAttr returns whether the attribute key matches the pattern, in which *
+// matches any sequence of characters, including slashes of image references.
+func matchAttr(pattern, k string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == k
+	}
+	if !strings.HasPrefix(k, parts[0]) {
+		return false
+	}
+	k = k[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(k, part)
+		if i < 0 {
+			return false
+		}
+		k = k[i+len(part):]
+	}
+	return strings.HasSuffix(k, parts[len(parts)-1])
+}
+
+// filterAttrs filters frontent opt by picking only those allowed by the
+// policy, DefaultAttrsPolicy if nil. The default allowlist is used if the
+// policy only denies attributes, and the build args looking like credentials
+// are redacted unless the policy sets NoDefaultRedact.
+func filterAttrs(key string, attrs map[string]*string, policy *AttrsPolicy) map[string]*string {
+	if policy == nil {
+		policy = &DefaultAttrsPolicy
+	}
+	policy = &AttrsPolicy{
+		Allow:           policy.Allow,
+		Deny:            policy.Deny,
+		Redact:          policy.Redact,
+		NoDefaultRedact: policy.NoDefaultRedact,
+	}
+	if len(policy.Allow) == 0 {
+		policy.Allow = DefaultAttrsPolicy.Allow
+	}
+	if !policy.NoDefaultRedact {
+		policy.Redact = append(append([]string{}, policy.Redact...), defaultRedactAttrs...)
+	}
 	var platform string
 	// extract platform from metadata key
 	skey := strings.SplitN(key, "/", 2)
@@ -255,32 +629,18 @@ func filterAttrs(key string, attrs map[string]*string) map[string]*string {
 		if isControlArg(k) {
 			continue
 		}
-		// always include
-		if strings.HasPrefix(k, "build-arg:") || strings.HasPrefix(k, "label:") {
-			filtered[k] = v
-			continue
-		}
 		// input context key and value has to be cleaned up
 		// before being included
-		if strings.HasPrefix(k, "context:") {
-			if platform != "" {
-				// if platform is defined, only include the relevant platform
-				if !strings.HasSuffix(k, "::"+platform) {
-					continue
-				}
-				ctxival := strings.TrimSuffix(*v, "::"+platform)
-				filtered[strings.TrimSuffix(k, "::"+platform)] = &ctxival
+		if strings.HasPrefix(k, "context:") && platform != "" {
+			// if platform is defined, only include the relevant platform
+			if !strings.HasSuffix(k, "::"+platform) {
 				continue
 			}
-			filtered[k] = v
-			continue
+			ctxival := strings.TrimSuffix(*v, "::"+platform)
+			k, v = strings.TrimSuffix(k, "::"+platform), &ctxival
 		}
-		// filter only for known attributes
-		for _, knownAttr := range knownAttrs {
-			if knownAttr == k {
-				filtered[k] = v
-				break
-			}
+		if policy.Allowed(k) {
+			filtered[k] = policy.redact(k, v)
 		}
 	}
 	return filtered
@@ -307,11 +667,19 @@ func isControlArg(attrKey string) bool {
 }
 
 // GetMetadata returns buildinfo metadata for the specified key. If the key
-// is already there, result will be merged.
-func GetMetadata(metadata map[string][]byte, key string, reqFrontend string, reqAttrs map[string]string) ([]byte, error) {
+// is already there, result will be merged. The frontend image set in the
+// metadata by the gateway frontend is recorded. The attributes are filtered
+// with the policy, DefaultAttrsPolicy if nil.
+func GetMetadata(metadata map[string][]byte, key string, reqFrontend string, reqAttrs map[string]string, policy *AttrsPolicy) ([]byte, error) {
 	if metadata == nil {
 		metadata = make(map[string][]byte)
 	}
+	var frontendImage *binfotypes.Source
+	if dt, ok := metadata[exptypes.ExporterFrontendImage]; ok {
+		if err := json.Unmarshal(dt, &frontendImage); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal frontend image")
+		}
+	}
 	var dtbi []byte
 	if v, ok := metadata[key]; ok && v != nil {
 		var mbi binfotypes.BuildInfo
@@ -321,12 +689,20 @@ func GetMetadata(metadata map[string][]byte, key string, reqFrontend string, req
 		if reqFrontend != "" {
 			mbi.Frontend = reqFrontend
 		}
+		if frontendImage != nil {
+			mbi.FrontendImage = frontendImage
+		}
 		if deps, err := decodeDeps(key, convertMap(reduceMapString(reqAttrs, mbi.Attrs))); err == nil {
 			mbi.Deps = reduceMapBuildInfo(deps, mbi.Deps)
 		} else {
 			return nil, err
 		}
-		mbi.Attrs = filterAttrs(key, convertMap(reduceMapString(reqAttrs, mbi.Attrs)))
+		if deps, err := decodePlatformDeps(convertMap(reduceMapString(reqAttrs, mbi.Attrs))); err == nil {
+			mbi.PlatformDeps = reducePlatformDeps(deps, mbi.PlatformDeps)
+		} else {
+			return nil, err
+		}
+		mbi.Attrs = filterAttrs(key, convertMap(reduceMapString(reqAttrs, mbi.Attrs)), policy)
 		var err error
 		dtbi, err = json.Marshal(mbi)
 		if err != nil {
@@ -337,10 +713,16 @@ func GetMetadata(metadata map[string][]byte, key string, reqFrontend string, req
 		if err != nil {
 			return nil, err
 		}
+		platformDeps, err := decodePlatformDeps(convertMap(reqAttrs))
+		if err != nil {
+			return nil, err
+		}
 		dtbi, err = json.Marshal(binfotypes.BuildInfo{
-			Frontend: reqFrontend,
-			Attrs:    filterAttrs(key, convertMap(reqAttrs)),
-			Deps:     deps,
+			Frontend:      reqFrontend,
+			FrontendImage: frontendImage,
+			Attrs:         filterAttrs(key, convertMap(reqAttrs), policy),
+			Deps:          deps,
+			PlatformDeps:  platformDeps,
 		})
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to marshal build info for %q", key)
@@ -396,6 +778,24 @@ func reduceMapBuildInfo(m1 map[string]binfotypes.BuildInfo, m2 map[string]binfot
 	return m1
 }
 
+func reducePlatformDeps(m1 map[string]map[string]binfotypes.BuildInfo, m2 map[string]map[string]binfotypes.BuildInfo) map[string]map[string]binfotypes.BuildInfo {
+	if m1 == nil && m2 == nil {
+		return nil
+	}
+	if m1 == nil {
+		m1 = map[string]map[string]binfotypes.BuildInfo{}
+	}
+	for name, deps := range m2 {
+		if m1[name] == nil {
+			m1[name] = map[string]binfotypes.BuildInfo{}
+		}
+		for p, bi := range deps {
+			m1[name][p] = bi
+		}
+	}
+	return m1
+}
+
 func convertMap(m map[string]string) map[string]*string {
 	res := make(map[string]*string)
 	for k, v := range m {
@@ -2,11 +2,16 @@ package buildinfo
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	"github.com/moby/buildkit/solver/pb"
 	binfotypes "github.com/moby/buildkit/util/buildinfo/types"
+	digest "github.com/opencontainers/go-digest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -165,6 +170,65 @@ func TestDecodeDeps(t *testing.T) {
 	}
 }
 
+func TestPlatformDeps(t *testing.T) {
+	inputMetadata := func(ref string) *string {
+		dt, err := json.Marshal(binfotypes.BuildInfo{
+			Frontend: "dockerfile.v0",
+			Sources:  []binfotypes.Source{{Type: binfotypes.SourceTypeDockerImage, Ref: ref}},
+		})
+		require.NoError(t, err)
+		dt, err = json.Marshal(map[string]string{
+			exptypes.ExporterBuildInfo: base64.StdEncoding.EncodeToString(dt),
+		})
+		require.NoError(t, err)
+		return stringPtr(string(dt))
+	}
+	dep := func(ref string) binfotypes.BuildInfo {
+		return binfotypes.BuildInfo{
+			Frontend: "dockerfile.v0",
+			Sources:  []binfotypes.Source{{Type: binfotypes.SourceTypeDockerImage, Ref: ref}},
+		}
+	}
+
+	attrs := map[string]*string{
+		"context:base::linux/amd64":        stringPtr("input:base::linux/amd64"),
+		"input-metadata:base::linux/amd64": inputMetadata("alpine-amd64"),
+		"input-metadata:base::linux/arm64": inputMetadata("alpine-arm64"),
+		"input-metadata:tools":             inputMetadata("busybox"),
+	}
+	deps, err := decodePlatformDeps(attrs)
+	require.NoError(t, err)
+	require.Equal(t, map[string]map[string]binfotypes.BuildInfo{
+		"base": {
+			"linux/amd64": dep("alpine-amd64"),
+			"linux/arm64": dep("alpine-arm64"),
+		},
+	}, deps)
+
+	dt, err := GetMetadata(nil, exptypes.ExporterBuildInfo+"/linux/amd64", "dockerfile.v0", map[string]string{
+		"input-metadata:base::linux/amd64": *attrs["input-metadata:base::linux/amd64"],
+		"input-metadata:base::linux/arm64": *attrs["input-metadata:base::linux/arm64"],
+		"input-metadata:tools":             *attrs["input-metadata:tools"],
+	}, nil)
+	require.NoError(t, err)
+	var bi binfotypes.BuildInfo
+	require.NoError(t, json.Unmarshal(dt, &bi))
+
+	// the dependencies of the other platforms are kept
+	require.Equal(t, deps, bi.PlatformDeps)
+	require.Equal(t, []string{"linux/amd64", "linux/arm64"}, DepPlatforms(&bi, "base"))
+	require.Empty(t, DepPlatforms(&bi, "tools"))
+
+	bi.Deps = map[string]binfotypes.BuildInfo{"base": dep("alpine-amd64"), "tools": dep("busybox")}
+	require.Equal(t, map[string]binfotypes.BuildInfo{
+		"base":  dep("alpine-arm64"),
+		"tools": dep("busybox"),
+	}, DepsForPlatform(&bi, "linux/arm64"))
+	require.Equal(t, map[string]binfotypes.BuildInfo{
+		"tools": dep("busybox"),
+	}, DepsForPlatform(&bi, "linux/s390x"))
+}
+
 func TestFilterAttrs(t *testing.T) {
 	cases := []struct {
 		name  string
@@ -212,11 +276,101 @@ func TestFilterAttrs(t *testing.T) {
 	for _, tt := range cases {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equal(t, tt.want, filterAttrs(tt.key, tt.attrs))
+			assert.Equal(t, tt.want, filterAttrs(tt.key, tt.attrs, nil))
 		})
 	}
 }
 
+func TestFilterAttrsPolicy(t *testing.T) {
+	attrs := map[string]*string{
+		"build-arg:foo":                    stringPtr("bar"),
+		"build-arg:SECRET_TOKEN":           stringPtr("hunter2"),
+		"context:docker.io/library/alpine": stringPtr("docker-image://alpine:3.18"),
+		"filename":                         stringPtr("Dockerfile"),
+		"hostname":                         stringPtr("builder"),
+		"target":                           stringPtr("release"),
+	}
+
+	// deny only policies keep the default allowlist
+	assert.Equal(t, map[string]*string{
+		"build-arg:foo":                    stringPtr("bar"),
+		"context:docker.io/library/alpine": stringPtr("docker-image://alpine:3.18"),
+		"filename":                         stringPtr("Dockerfile"),
+		"target":                           stringPtr("release"),
+	}, filterAttrs(exptypes.ExporterBuildInfo, attrs, &AttrsPolicy{Deny: []string{"build-arg:SECRET_*"}}))
+
+	assert.Equal(t, map[string]*string{
+		"build-arg:foo": stringPtr("bar"),
+		"hostname":      stringPtr("builder"),
+	}, filterAttrs(exptypes.ExporterBuildInfo, attrs, &AttrsPolicy{
+		Allow: []string{"build-arg:*", "hostname"},
+		Deny:  []string{"build-arg:*_TOKEN"},
+	}))
+}
+
+func TestFilterAttrsRedact(t *testing.T) {
+	attrs := map[string]*string{
+		"build-arg:foo":          stringPtr("bar"),
+		"build-arg:github_token": stringPtr("ghp_xxx"),
+		"build-arg:DB_PASSWORD":  stringPtr("hunter2"),
+		"build-arg:DEPLOY_ENV":   stringPtr("prod"),
+		"build-arg:SSH_KEY":      stringPtr("ssh-ed25519 AAAA"),
+		"build-arg:KEYBOARD":     stringPtr("us"),
+	}
+	redacted := func(v string) *string {
+		return stringPtr(redactedPrefix + digest.FromString(v).String())
+	}
+
+	filtered := filterAttrs(exptypes.ExporterBuildInfo, attrs, nil)
+	assert.Equal(t, map[string]*string{
+		"build-arg:foo":          stringPtr("bar"),
+		"build-arg:github_token": redacted("ghp_xxx"),
+		"build-arg:DB_PASSWORD":  redacted("hunter2"),
+		"build-arg:DEPLOY_ENV":   stringPtr("prod"),
+		"build-arg:SSH_KEY":      redacted("ssh-ed25519 AAAA"),
+		"build-arg:KEYBOARD":     stringPtr("us"),
+	}, filtered)
+	// redacted values are kept when filtered again
+	assert.Equal(t, filtered, filterAttrs(exptypes.ExporterBuildInfo, filtered, nil))
+
+	assert.Equal(t, map[string]*string{
+		"build-arg:foo":          stringPtr("bar"),
+		"build-arg:github_token": stringPtr("ghp_xxx"),
+		"build-arg:DB_PASSWORD":  stringPtr("hunter2"),
+		"build-arg:DEPLOY_ENV":   redacted("prod"),
+		"build-arg:SSH_KEY":      stringPtr("ssh-ed25519 AAAA"),
+		"build-arg:KEYBOARD":     stringPtr("us"),
+	}, filterAttrs(exptypes.ExporterBuildInfo, attrs, &AttrsPolicy{
+		Redact:          []string{"build-arg:deploy_*"},
+		NoDefaultRedact: true,
+	}))
+}
+
+func TestParseAttrsPolicy(t *testing.T) {
+	p, err := ParseAttrsPolicy("allow:target, filename;deny:build-arg:SECRET_*;redact:build-arg:DSN")
+	require.NoError(t, err)
+	require.Equal(t, &AttrsPolicy{
+		Allow:  []string{"target", "filename"},
+		Deny:   []string{"build-arg:SECRET_*"},
+		Redact: []string{"build-arg:DSN"},
+	}, p)
+
+	_, err = ParseAttrsPolicy("mask:target")
+	require.Error(t, err)
+	_, err = ParseAttrsPolicy("target")
+	require.Error(t, err)
+}
+
+func TestMatchAttr(t *testing.T) {
+	assert.True(t, matchAttr("target", "target"))
+	assert.False(t, matchAttr("target", "target2"))
+	assert.True(t, matchAttr("build-arg:*", "build-arg:foo/bar"))
+	assert.True(t, matchAttr("build-arg:*_TOKEN", "build-arg:GH_TOKEN"))
+	assert.False(t, matchAttr("build-arg:*_TOKEN", "build-arg:GH_TOKEN2"))
+	assert.True(t, matchAttr("*:*", "label:foo"))
+	assert.False(t, matchAttr("ab*ba", "aba"))
+}
+
 func TestFormat(t *testing.T) {
 	bi := binfotypes.BuildInfo{
 		Frontend: "dockerfile.v0",
@@ -261,6 +415,24 @@ func TestFormat(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:       "filter attrs",
+			formatopts: FormatOpts{Attrs: &AttrsPolicy{Allow: []string{"build-arg:*", "filename"}, Deny: []string{"build-arg:foo"}}},
+			want: binfotypes.BuildInfo{
+				Frontend: "dockerfile.v0",
+				Attrs: map[string]*string{
+					"filename": stringPtr("Dockerfile"),
+				},
+				Sources: []binfotypes.Source{
+					{
+						Type:  binfotypes.SourceTypeDockerImage,
+						Ref:   "docker.io/docker/buildx-bin:0.6.1@sha256:a652ced4a4141977c7daaed0a074dcd9844a78d7d2615465b12f433ae6dd29f0",
+						Alias: "docker.io/docker/buildx-bin:0.6.1@sha256:a652ced4a4141977c7daaed0a074dcd9844a78d7d2615465b12f433ae6dd29f0",
+						Pin:   "sha256:a652ced4a4141977c7daaed0a074dcd9844a78d7d2615465b12f433ae6dd29f0",
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range cases {
@@ -278,6 +450,90 @@ func TestFormat(t *testing.T) {
 	}
 }
 
+func TestEncodeCacheInfo(t *testing.T) {
+	ci := &binfotypes.CacheInfo{
+		Imports:    []binfotypes.CacheRef{CacheRef("registry", map[string]string{"ref": "example.com/cache:latest"})},
+		Exports:    []binfotypes.CacheRef{CacheRef("local", map[string]string{"dest": "/tmp/cache"})},
+		Vertexes:   5,
+		LocalHits:  2,
+		RemoteHits: 1,
+	}
+	dt, err := Encode(context.TODO(), map[string][]byte{
+		exptypes.ExporterBuildInfo: []byte(`{"frontend":"dockerfile.v0"}`),
+	}, exptypes.ExporterBuildInfo, EncodeOpt{CacheInfo: ci})
+	require.NoError(t, err)
+
+	var bi binfotypes.BuildInfo
+	require.NoError(t, json.Unmarshal(dt, &bi))
+	require.Equal(t, "dockerfile.v0", bi.Frontend)
+	require.Equal(t, ci, bi.Cache)
+	require.Equal(t, binfotypes.CacheRef{Type: "local"}, bi.Cache.Exports[0])
+}
+
+func TestEncodeDefinition(t *testing.T) {
+	def := &pb.Definition{Def: [][]byte{[]byte("op0"), []byte("op1")}}
+	dgst, err := DefinitionDigest(def)
+	require.NoError(t, err)
+	dt, err := def.Marshal()
+	require.NoError(t, err)
+	require.Equal(t, digest.FromBytes(dt), dgst)
+
+	dt, err = Encode(context.TODO(), nil, exptypes.ExporterBuildInfo, EncodeOpt{Definition: def})
+	require.NoError(t, err)
+	var bi binfotypes.BuildInfo
+	require.NoError(t, json.Unmarshal(dt, &bi))
+	require.Equal(t, dgst.String(), bi.Definition)
+}
+
+func TestGetMetadataFrontendImage(t *testing.T) {
+	img := &binfotypes.Source{
+		Type: binfotypes.SourceTypeDockerImage,
+		Ref:  "docker.io/docker/dockerfile:1",
+		Pin:  "sha256:a652ced4a4141977c7daaed0a074dcd9844a78d7d2615465b12f433ae6dd29f0",
+	}
+	dtimg, err := json.Marshal(img)
+	require.NoError(t, err)
+
+	// the gateway frontend records its image
+	md := map[string][]byte{exptypes.ExporterFrontendImage: dtimg}
+	dt, err := GetMetadata(md, exptypes.ExporterBuildInfo, "gateway.v0", map[string]string{"source": "docker/dockerfile:1"}, nil)
+	require.NoError(t, err)
+	var bi binfotypes.BuildInfo
+	require.NoError(t, json.Unmarshal(dt, &bi))
+	require.Equal(t, img, bi.FrontendImage)
+
+	// the image is kept when the result is returned by the frontend
+	// forwarding to the gateway frontend
+	md[exptypes.ExporterBuildInfo] = dt
+	dt, err = GetMetadata(md, exptypes.ExporterBuildInfo, "dockerfile.v0", nil, nil)
+	require.NoError(t, err)
+	bi = binfotypes.BuildInfo{}
+	require.NoError(t, json.Unmarshal(dt, &bi))
+	require.Equal(t, "dockerfile.v0", bi.Frontend)
+	require.Equal(t, img, bi.FrontendImage)
+}
+
+func TestSBOM(t *testing.T) {
+	dt, err := SBOM(nil)
+	require.NoError(t, err)
+	require.Nil(t, dt)
+
+	dt, err = SBOM([]byte(`{"frontend":"dockerfile.v0"}`))
+	require.NoError(t, err)
+	require.Nil(t, dt)
+
+	bi, err := json.Marshal(binfotypes.BuildInfo{
+		Frontend: "dockerfile.v0",
+		Packages: []binfotypes.Package{
+			{Type: binfotypes.PackageTypeApk, Name: "musl", Version: "1.2.4-r2"},
+		},
+	})
+	require.NoError(t, err)
+	dt, err = SBOM(bi)
+	require.NoError(t, err)
+	require.Equal(t, `{"packages":[{"type":"apk","name":"musl","version":"1.2.4-r2"}]}`, string(dt))
+}
+
 func TestReduceMapString(t *testing.T) {
 	cases := []struct {
 		name     string
@@ -328,3 +584,62 @@ func TestReduceMapString(t *testing.T) {
 func stringPtr(v string) *string {
 	return &v
 }
+
+func TestMergeSourcesOptions(t *testing.T) {
+	op := &pb.SourceOp{
+		Identifier: "git://github.com/moby/buildkit.git#master",
+		Attrs: map[string]string{
+			pb.AttrGitDepth:       "10",
+			pb.AttrGitFilter:      "blob:none",
+			pb.AttrGitSparsePaths: `["docs"]`,
+			pb.AttrKeepGitDir:     "true",
+		},
+	}
+	srcs, err := mergeSources(context.TODO(), map[string]string{
+		SourceKey(op): "259a5aa5aa5bb3562d12cc631fe399f4788642c1",
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []binfotypes.Source{{
+		Type: binfotypes.SourceTypeGit,
+		Ref:  "https://github.com/moby/buildkit.git#master",
+		Pin:  "259a5aa5aa5bb3562d12cc631fe399f4788642c1",
+		Options: map[string]string{
+			"depth":  "10",
+			"filter": "blob:none",
+			"sparse": `["docs"]`,
+		},
+	}}, srcs)
+
+	op.Attrs = nil
+	require.Equal(t, op.Identifier, SourceKey(op))
+}
+
+func TestMergeSourcesOCILayout(t *testing.T) {
+	buildSources := map[string]string{}
+	for _, store := range []string{"base", "tools"} {
+		op := &pb.SourceOp{
+			Identifier: "oci-layout://app:v1",
+			Attrs: map[string]string{
+				pb.AttrOCILayoutSessionID: "sid",
+				pb.AttrOCILayoutStoreID:   store,
+			},
+		}
+		buildSources[SourceKey(op)] = "sha256:" + strings.Repeat(store[:1], 64)
+	}
+	srcs, err := mergeSources(context.TODO(), buildSources, nil)
+	require.NoError(t, err)
+	sort.Slice(srcs, func(i, j int) bool {
+		return srcs[i].Options["store"] < srcs[j].Options["store"]
+	})
+	require.Equal(t, []binfotypes.Source{{
+		Type:    binfotypes.SourceTypeOCILayout,
+		Ref:     "app:v1",
+		Pin:     "sha256:" + strings.Repeat("b", 64),
+		Options: map[string]string{"store": "base"},
+	}, {
+		Type:    binfotypes.SourceTypeOCILayout,
+		Ref:     "app:v1",
+		Pin:     "sha256:" + strings.Repeat("t", 64),
+		Options: map[string]string{"store": "tools"},
+	}}, srcs)
+}
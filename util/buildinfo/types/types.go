@@ -19,17 +19,32 @@ type ImageConfig struct {
 type BuildInfo struct {
 	// Frontend defines the frontend used to build.
 	Frontend string `json:"frontend,omitempty"`
+	// FrontendImage defines the image of the gateway frontend used to
+	// build, pinned to its resolved digest.
+	FrontendImage *Source `json:"frontendImage,omitempty"`
 	// Attrs defines build request attributes.
 	Attrs map[string]*string `json:"attrs,omitempty"`
 	// Sources defines build dependencies.
 	Sources []Source `json:"sources,omitempty"`
+	// Definition defines the digest of the marshaled LLB definition of the
+	// result.
+	Definition string `json:"definition,omitempty"`
 	// Deps defines context dependencies.
 	Deps map[string]BuildInfo `json:"deps,omitempty"`
+	// PlatformDeps defines the context dependencies of all the platforms of
+	// the input contexts, keyed by context name and platform.
+	PlatformDeps map[string]map[string]BuildInfo `json:"platformDeps,omitempty"`
+	// Packages defines the OS and language packages found in the result
+	// when the frontend scanned it.
+	Packages []Package `json:"packages,omitempty"`
+	// Cache defines the cache used by the build.
+	Cache *CacheInfo `json:"cache,omitempty"`
 }
 
 // Source defines a build dependency.
 type Source struct {
-	// Type defines the SourceType source type (docker-image, git, http).
+	// Type defines the SourceType source type (docker-image, git, http,
+	// oci-layout, objectstore).
 	Type SourceType `json:"type,omitempty"`
 	// Ref is the reference of the source.
 	Ref string `json:"ref,omitempty"`
@@ -39,6 +54,9 @@ type Source struct {
 	Alias string `json:"alias,omitempty"`
 	// Pin is the source digest.
 	Pin string `json:"pin,omitempty"`
+	// Options defines the options the source was fetched with, like the
+	// depth, filter and sparse paths of git sources.
+	Options map[string]string `json:"options,omitempty"`
 }
 
 // SourceType contains source type.
@@ -49,4 +67,52 @@ const (
 	SourceTypeDockerImage SourceType = srctypes.DockerImageScheme
 	SourceTypeGit         SourceType = srctypes.GitScheme
 	SourceTypeHTTP        SourceType = srctypes.HTTPScheme
+	SourceTypeOCILayout   SourceType = srctypes.OCIScheme
+	SourceTypeObjectStore SourceType = srctypes.ObjectStoreScheme
+)
+
+// CacheInfo defines the cache backends used by the build and the cache hit
+// statistics of its vertexes.
+type CacheInfo struct {
+	// Imports defines the caches imported by the build.
+	Imports []CacheRef `json:"imports,omitempty"`
+	// Exports defines the caches exported by the build.
+	Exports []CacheRef `json:"exports,omitempty"`
+	// Vertexes is the number of vertexes loaded from the cache or executed.
+	Vertexes int `json:"vertexes"`
+	// LocalHits is the number of vertexes loaded from the local cache.
+	LocalHits int `json:"localHits"`
+	// RemoteHits is the number of vertexes loaded from imported caches.
+	RemoteHits int `json:"remoteHits"`
+	// RemoteBytes is the size of the blobs of the results loaded from
+	// imported caches.
+	RemoteBytes int64 `json:"remoteBytes"`
+}
+
+// CacheRef defines a cache backend.
+type CacheRef struct {
+	// Type is the type of the cache backend (registry, local, gha, inline).
+	Type string `json:"type"`
+	// Ref is the reference or the scope of the cache, empty for local caches.
+	Ref string `json:"ref,omitempty"`
+}
+
+// Package defines a package installed in the build result.
+type Package struct {
+	// Type defines the PackageType package type (deb, apk, pypi).
+	Type PackageType `json:"type"`
+	// Name is the name of the package.
+	Name string `json:"name"`
+	// Version is the version of the package.
+	Version string `json:"version,omitempty"`
+}
+
+// PackageType contains package type, named as in package URLs.
+type PackageType string
+
+// List of package types.
+const (
+	PackageTypeDeb  PackageType = "deb"
+	PackageTypeApk  PackageType = "apk"
+	PackageTypePyPI PackageType = "pypi"
 )
@@ -0,0 +1,181 @@
+// Package cron parses cron-like schedules.
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Schedule returns the times a job runs at.
+type Schedule interface {
+	// Next returns the first time of the schedule after t.
+	Next(t time.Time) time.Time
+}
+
+// Parse parses a schedule. The standard five cron fields (minute, hour, day
+// of month, month and day of week) support *, lists, ranges and steps, e.g.
+// "0 */6 * * 1-5". The descriptors @hourly, @daily, @weekly, @monthly and
+// @every <duration> are supported as well.
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case "@hourly":
+		spec = "0 * * * *"
+	case "@daily", "@midnight":
+		spec = "0 0 * * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	case "@monthly":
+		spec = "0 0 1 * *"
+	case "@yearly", "@annually":
+		spec = "0 0 1 1 *"
+	}
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid schedule %q", spec)
+		}
+		if d < time.Minute {
+			return nil, errors.Errorf("invalid schedule %q: interval must be at least one minute", spec)
+		}
+		return every(d), nil
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("invalid schedule %q: expected 5 fields", spec)
+	}
+	var s fieldSchedule
+	for i, b := range bounds {
+		set, err := parseField(fields[i], b)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid schedule %q", spec)
+		}
+		s[i] = set
+	}
+	// 7 is an alias of sunday
+	if s[4]&(1<<7) != 0 {
+		s[4] |= 1
+	}
+	return &s, nil
+}
+
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e)).Truncate(time.Second)
+}
+
+type bound struct {
+	min, max int
+}
+
+var bounds = [5]bound{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of month
+	{1, 12}, // month
+	{0, 7},  // day of week
+}
+
+// fieldSchedule has a bit set for every allowed value of each field.
+type fieldSchedule [5]uint64
+
+func parseField(field string, b bound) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, errors.Errorf("invalid step in %q", part)
+			}
+			rng = part[:i]
+		}
+		lo, hi := b.min, b.max
+		if rng != "*" {
+			var err error
+			if i := strings.Index(rng, "-"); i >= 0 {
+				if lo, err = strconv.Atoi(rng[:i]); err != nil {
+					return 0, errors.Errorf("invalid range %q", part)
+				}
+				if hi, err = strconv.Atoi(rng[i+1:]); err != nil {
+					return 0, errors.Errorf("invalid range %q", part)
+				}
+			} else {
+				if lo, err = strconv.Atoi(rng); err != nil {
+					return 0, errors.Errorf("invalid value %q", part)
+				}
+				hi = lo
+				if step > 1 {
+					hi = b.max
+				}
+			}
+		}
+		if lo < b.min || hi > b.max || lo > hi {
+			return 0, errors.Errorf("%q out of range %d-%d", part, b.min, b.max)
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+func (s *fieldSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// a valid time is found within a few years unless the schedule can
+	// never match, e.g. february 30
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s[3]&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s[1]&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if s[0]&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchDay follows cron in matching either the day of month or the day of
+// week if both are restricted.
+func (s *fieldSchedule) matchDay(t time.Time) bool {
+	dom := s[2]&(1<<uint(t.Day())) != 0
+	dow := s[4]&(1<<uint(t.Weekday())) != 0
+	allDom := s[2] == fullSet(bounds[2])
+	allDow := s[4]&0x7f == 0x7f
+	switch {
+	case allDom && allDow:
+		return true
+	case allDom:
+		return dow
+	case allDow:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+func fullSet(b bound) uint64 {
+	var set uint64
+	for v := b.min; v <= b.max; v++ {
+		set |= 1 << uint(v)
+	}
+	return set
+}
@@ -0,0 +1,54 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNext(t *testing.T) {
+	// a thursday
+	now := time.Date(2022, 3, 3, 10, 17, 30, 0, time.UTC)
+
+	for spec, next := range map[string]time.Time{
+		"* * * * *":     time.Date(2022, 3, 3, 10, 18, 0, 0, time.UTC),
+		"30 * * * *":    time.Date(2022, 3, 3, 10, 30, 0, 0, time.UTC),
+		"0 3 * * *":     time.Date(2022, 3, 4, 3, 0, 0, 0, time.UTC),
+		"*/15 * * * *":  time.Date(2022, 3, 3, 10, 30, 0, 0, time.UTC),
+		"0 */6 * * *":   time.Date(2022, 3, 3, 12, 0, 0, 0, time.UTC),
+		"0 0 * * 0":     time.Date(2022, 3, 6, 0, 0, 0, 0, time.UTC),
+		"0 0 * * 7":     time.Date(2022, 3, 6, 0, 0, 0, 0, time.UTC),
+		"0 9 * * 1-5":   time.Date(2022, 3, 4, 9, 0, 0, 0, time.UTC),
+		"0 0 1 * *":     time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":    time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		"0 0 15 * 1":    time.Date(2022, 3, 7, 0, 0, 0, 0, time.UTC),
+		"5,10 10 * * *": time.Date(2022, 3, 4, 10, 5, 0, 0, time.UTC),
+		"@daily":        time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC),
+		"@hourly":       time.Date(2022, 3, 3, 11, 0, 0, 0, time.UTC),
+		"@every 90m":    time.Date(2022, 3, 3, 11, 47, 30, 0, time.UTC),
+		"0 0 30 2 *":    {},
+	} {
+		s, err := Parse(spec)
+		require.NoError(t, err, spec)
+		require.Equal(t, next, s.Next(now), spec)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every 10s",
+		"@every foo",
+	} {
+		_, err := Parse(spec)
+		require.Error(t, err, spec)
+	}
+}
@@ -9,11 +9,13 @@ type Entitlement string
 const (
 	EntitlementSecurityInsecure Entitlement = "security.insecure"
 	EntitlementNetworkHost      Entitlement = "network.host"
+	EntitlementNestedBuild      Entitlement = "nested.build"
 )
 
 var all = map[Entitlement]struct{}{
 	EntitlementSecurityInsecure: {},
 	EntitlementNetworkHost:      {},
+	EntitlementNestedBuild:      {},
 }
 
 func Parse(s string) (Entitlement, error) {
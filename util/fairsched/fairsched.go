@@ -0,0 +1,171 @@
+// Package fairsched shares a fixed number of slots between keys in
+// proportion to their weights.
+package fairsched
+
+import (
+	"context"
+	"sync"
+)
+
+// Scheduler hands out slots to callers. When callers are waiting, a freed
+// slot goes to the key with the fewest slots in use relative to its weight,
+// so a key with many waiting callers can not starve the others.
+type Scheduler struct {
+	mu       sync.Mutex
+	capacity int
+	running  int
+	waiting  int
+	seq      uint64
+	keys     map[string]*key
+}
+
+type key struct {
+	weight  int
+	running int
+	waiters []*waiter
+}
+
+type waiter struct {
+	seq   uint64
+	ready chan struct{}
+}
+
+// New returns a scheduler with capacity slots. A capacity lower than 1 is
+// unlimited.
+func New(capacity int) *Scheduler {
+	return &Scheduler{
+		capacity: capacity,
+		keys:     map[string]*key{},
+	}
+}
+
+// Capacity returns the number of slots of the scheduler.
+func (s *Scheduler) Capacity() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.capacity
+}
+
+// SetCapacity changes the number of slots of the scheduler. Callers holding
+// slots above a reduced capacity keep them until they release them.
+func (s *Scheduler) SetCapacity(capacity int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.capacity = capacity
+	s.dispatch()
+}
+
+func (s *Scheduler) free() bool {
+	return s.capacity < 1 || s.running < s.capacity
+}
+
+// Acquire waits for a slot for the key and returns the function releasing
+// it. Weights lower than 1 are treated as 1. The last weight passed for a key
+// is used.
+func (s *Scheduler) Acquire(ctx context.Context, id string, weight int) (func(), error) {
+	if weight < 1 {
+		weight = 1
+	}
+
+	s.mu.Lock()
+	k, ok := s.keys[id]
+	if !ok {
+		k = &key{}
+		s.keys[id] = k
+	}
+	k.weight = weight
+
+	if s.free() && s.waiting == 0 {
+		s.running++
+		k.running++
+		s.mu.Unlock()
+		return s.releaser(id), nil
+	}
+
+	w := &waiter{seq: s.seq, ready: make(chan struct{})}
+	s.seq++
+	k.waiters = append(k.waiters, w)
+	s.waiting++
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return s.releaser(id), nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	select {
+	case <-w.ready:
+		// the slot was handed out while the context was canceled
+		s.mu.Unlock()
+		s.release(id)
+		return nil, ctx.Err()
+	default:
+	}
+	for i, w2 := range k.waiters {
+		if w2 == w {
+			k.waiters = append(k.waiters[:i], k.waiters[i+1:]...)
+			s.waiting--
+			break
+		}
+	}
+	s.cleanup(id, k)
+	s.mu.Unlock()
+	return nil, ctx.Err()
+}
+
+func (s *Scheduler) releaser(id string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.release(id)
+		})
+	}
+}
+
+func (s *Scheduler) release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := s.keys[id]
+	k.running--
+	s.running--
+	s.cleanup(id, k)
+	s.dispatch()
+}
+
+func (s *Scheduler) cleanup(id string, k *key) {
+	if k.running == 0 && len(k.waiters) == 0 {
+		delete(s.keys, id)
+	}
+}
+
+// dispatch hands out the free slots to the waiting callers.
+func (s *Scheduler) dispatch() {
+	for s.free() && s.waiting > 0 {
+		var next *key
+		for _, k := range s.keys {
+			if len(k.waiters) == 0 {
+				continue
+			}
+			if next == nil || k.less(next) {
+				next = k
+			}
+		}
+		w := next.waiters[0]
+		next.waiters = next.waiters[1:]
+		s.waiting--
+		next.running++
+		s.running++
+		close(w.ready)
+	}
+}
+
+// less reports whether k has priority over k2 for the next slot.
+func (k *key) less(k2 *key) bool {
+	a, b := k.running*k2.weight, k2.running*k.weight
+	if a != b {
+		return a < b
+	}
+	return k.waiters[0].seq < k2.waiters[0].seq
+}
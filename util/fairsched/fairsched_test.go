@@ -0,0 +1,154 @@
+package fairsched
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.TODO()
+	s := New(2)
+
+	// a fills the slots and queues more work
+	releaseA1, err := s.Acquire(ctx, "a", 1)
+	require.NoError(t, err)
+	releaseA2, err := s.Acquire(ctx, "a", 1)
+	require.NoError(t, err)
+
+	acquired := make(chan string, 4)
+	acquire := func(id string, weight int) {
+		go func() {
+			release, err := s.Acquire(ctx, id, weight)
+			if err != nil {
+				return
+			}
+			acquired <- id
+			_ = release
+		}()
+	}
+	acquire("a", 1)
+	waitWaiting(t, s, 1)
+	acquire("b", 1)
+	waitWaiting(t, s, 2)
+
+	// b gets the freed slot before the queued work of a
+	releaseA1()
+	require.Equal(t, "b", <-acquired)
+	releaseA2()
+	require.Equal(t, "a", <-acquired)
+}
+
+func TestSchedulerWeight(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.TODO()
+	s := New(3)
+
+	var releases []func()
+	for i := 0; i < 3; i++ {
+		r, err := s.Acquire(ctx, "x", 1)
+		require.NoError(t, err)
+		releases = append(releases, r)
+	}
+
+	acquired := make(chan string, 4)
+	for i, id := range []string{"a", "a", "b", "b"} {
+		weight := 1
+		if id == "a" {
+			weight = 3
+		}
+		go func(id string, weight int) {
+			if _, err := s.Acquire(ctx, id, weight); err == nil {
+				acquired <- id
+			}
+		}(id, weight)
+		waitWaiting(t, s, i+1)
+	}
+
+	// a has three times the weight of b and gets two slots of three
+	for _, r := range releases {
+		r()
+	}
+	counts := map[string]int{}
+	for i := 0; i < 3; i++ {
+		counts[<-acquired]++
+	}
+	require.Equal(t, map[string]int{"a": 2, "b": 1}, counts)
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	t.Parallel()
+
+	s := New(1)
+	release, err := s.Acquire(context.TODO(), "a", 1)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := s.Acquire(ctx, "b", 1)
+		errCh <- err
+	}()
+	waitWaiting(t, s, 1)
+	cancel()
+	require.ErrorIs(t, <-errCh, context.Canceled)
+
+	release()
+	release2, err := s.Acquire(context.TODO(), "c", 1)
+	require.NoError(t, err)
+	release2()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	require.Equal(t, 0, s.running)
+	require.Equal(t, 0, s.waiting)
+	require.Len(t, s.keys, 0)
+}
+
+func waitWaiting(t *testing.T, s *Scheduler, n int) {
+	for i := 0; i < 1000; i++ {
+		s.mu.Lock()
+		waiting := s.waiting
+		s.mu.Unlock()
+		if waiting == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d waiters", n)
+}
+
+func TestSchedulerSetCapacity(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.TODO()
+	s := New(0)
+
+	// unlimited
+	for i := 0; i < 10; i++ {
+		_, err := s.Acquire(ctx, "a", 1)
+		require.NoError(t, err)
+	}
+
+	s = New(1)
+	release, err := s.Acquire(ctx, "a", 1)
+	require.NoError(t, err)
+
+	acquired := make(chan struct{})
+	go func() {
+		if _, err := s.Acquire(ctx, "b", 1); err == nil {
+			close(acquired)
+		}
+	}()
+	waitWaiting(t, s, 1)
+
+	s.SetCapacity(2)
+	require.Equal(t, 2, s.Capacity())
+	<-acquired
+	release()
+}
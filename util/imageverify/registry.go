@@ -0,0 +1,155 @@
+package imageverify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/remotes"
+	"github.com/containerd/containerd/remotes/docker"
+	"github.com/docker/distribution/reference"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// maxManifestSize limits the size of the manifests, payloads and indexes
+// read from the registries.
+const maxManifestSize = 4 << 20
+
+// hostsResolver is implemented by the resolvers of util/resolver, which
+// configure the hosts of the registries with the credentials of the session.
+type hostsResolver interface {
+	HostsFunc(host string) ([]docker.RegistryHost, error)
+}
+
+type registry struct {
+	resolver remotes.Resolver
+	named    reference.Named
+}
+
+// NewRegistry returns the registry of the repository of ref, accessed with
+// the resolver.
+func NewRegistry(resolver remotes.Resolver, ref string) (Registry, error) {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &registry{resolver: resolver, named: named}, nil
+}
+
+func (r *registry) Resolve(ctx context.Context, tag string) (ocispecs.Descriptor, error) {
+	_, desc, err := r.resolver.Resolve(ctx, r.named.Name()+":"+tag)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return ocispecs.Descriptor{}, errors.Wrapf(ErrNotFound, "%s", tag)
+		}
+		return ocispecs.Descriptor{}, err
+	}
+	return desc, nil
+}
+
+func (r *registry) Fetch(ctx context.Context, desc ocispecs.Descriptor) ([]byte, error) {
+	f, err := r.resolver.Fetcher(ctx, r.named.Name())
+	if err != nil {
+		return nil, err
+	}
+	rc, err := f.Fetch(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	dt, err := ioutil.ReadAll(io.LimitReader(rc, maxManifestSize))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if dgst := digest.FromBytes(dt); dgst != desc.Digest {
+		return nil, errors.Errorf("digest mismatch for %s: %s", desc.Digest, dgst)
+	}
+	return dt, nil
+}
+
+func (r *registry) Referrers(ctx context.Context, dgst digest.Digest) ([]Referrer, error) {
+	hr, ok := r.resolver.(hostsResolver)
+	if !ok {
+		return nil, nil
+	}
+	hosts, err := hr.HostsFunc(reference.Domain(r.named))
+	if err != nil {
+		return nil, err
+	}
+	repo := reference.Path(r.named)
+	ctx = docker.WithScope(ctx, "repository:"+repo+":pull")
+	for _, h := range hosts {
+		if !h.Capabilities.Has(docker.HostCapabilityPull) {
+			continue
+		}
+		u := url.URL{Scheme: h.Scheme, Host: h.Host, Path: path.Join(h.Path, repo, "referrers", dgst.String())}
+		resp, err := r.get(ctx, h, u.String())
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusNotFound:
+			// referrers API not supported
+			return nil, nil
+		default:
+			continue
+		}
+		dt, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		var idx struct {
+			Manifests []Referrer `json:"manifests"`
+		}
+		if err := json.Unmarshal(dt, &idx); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse referrers of %s", dgst)
+		}
+		return idx.Manifests, nil
+	}
+	return nil, nil
+}
+
+// get requests u from the host, authorizing the request with the challenge
+// of the registry if needed.
+func (r *registry) get(ctx context.Context, h docker.RegistryHost, u string) (*http.Response, error) {
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	for i := 0; ; i++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		req.Header.Set("Accept", ocispecs.MediaTypeImageIndex)
+		for k, v := range h.Header {
+			req.Header[k] = v
+		}
+		if h.Authorizer != nil {
+			if err := h.Authorizer.Authorize(ctx, req); err != nil {
+				return nil, err
+			}
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if resp.StatusCode != http.StatusUnauthorized || h.Authorizer == nil || i > 0 {
+			return resp, nil
+		}
+		err = h.Authorizer.AddResponses(ctx, []*http.Response{resp})
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,313 @@
+// Package imageverify verifies the cosign signatures and the signed in-toto
+// attestations of images before they are pulled.
+package imageverify
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SignatureArtifactType is the artifact type of the manifests of the
+	// cosign signatures referring to the images.
+	SignatureArtifactType = "application/vnd.dev.cosign.artifact.sig.v1+json"
+	// AttestationArtifactType is the artifact type of the manifests of the
+	// cosign attestations referring to the images.
+	AttestationArtifactType = "application/vnd.dev.cosign.artifact.att.v1+json"
+
+	mediaTypeSimpleSigning = "application/vnd.dev.cosign.simplesigning.v1+json"
+	mediaTypeDSSE          = "application/vnd.dsse.envelope.v1+json"
+	annotationSignature    = "dev.cosignproject.cosign/signature"
+	payloadTypeInToto      = "application/vnd.in-toto+json"
+)
+
+// Policy is the verification policy of an image.
+type Policy struct {
+	// Keys are the public keys verifying the signatures and the attestations.
+	Keys []crypto.PublicKey
+	// Attestations are the predicate types of the in-toto attestations the
+	// image requires, signed with one of the keys.
+	Attestations []string
+}
+
+// ParsePolicy returns the policy of the PEM encoded public keys and the comma
+// separated predicate types of the required attestations.
+func ParsePolicy(keys string, attestations string) (*Policy, error) {
+	p := &Policy{}
+	rest := []byte(keys)
+	for {
+		var b *pem.Block
+		b, rest = pem.Decode(rest)
+		if b == nil {
+			break
+		}
+		if b.Type != "PUBLIC KEY" {
+			return nil, errors.Errorf("unsupported PEM block %q for image verification key", b.Type)
+		}
+		k, err := x509.ParsePKIXPublicKey(b.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse image verification key")
+		}
+		p.Keys = append(p.Keys, k)
+	}
+	for _, a := range strings.Split(attestations, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			p.Attestations = append(p.Attestations, a)
+		}
+	}
+	if len(p.Keys) == 0 {
+		return nil, errors.New("no public keys for image verification")
+	}
+	return p, nil
+}
+
+// Registry fetches the signatures and the attestations of an image from its
+// repository.
+type Registry interface {
+	// Referrers returns the descriptors of the manifests referring to the
+	// manifest dgst, or nil if the registry doesn't support the referrers
+	// API.
+	Referrers(ctx context.Context, dgst digest.Digest) ([]Referrer, error)
+	// Resolve returns the descriptor of a tag of the repository, or
+	// ErrNotFound.
+	Resolve(ctx context.Context, tag string) (ocispecs.Descriptor, error)
+	// Fetch returns the content of desc.
+	Fetch(ctx context.Context, desc ocispecs.Descriptor) ([]byte, error)
+}
+
+// Referrer is the descriptor of a manifest referring to an image.
+type Referrer struct {
+	ocispecs.Descriptor
+	ArtifactType string `json:"artifactType,omitempty"`
+}
+
+// ErrNotFound is returned by Registry.Resolve for missing tags.
+var ErrNotFound = errors.New("not found")
+
+// Verify verifies the manifest dgst of the image ref with the policy. The
+// signatures and attestations are looked up with the referrers API and with
+// the tags of cosign, e.g. sha256-<hex>.sig. The returned error describes
+// what could not be verified.
+func Verify(ctx context.Context, reg Registry, ref string, dgst digest.Digest, p *Policy) error {
+	var sigs, atts []ocispecs.Descriptor
+	referrers, err := reg.Referrers(ctx, dgst)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list referrers of %s", ref)
+	}
+	for _, r := range referrers {
+		switch r.ArtifactType {
+		case SignatureArtifactType:
+			sigs = append(sigs, r.Descriptor)
+		case AttestationArtifactType:
+			atts = append(atts, r.Descriptor)
+		}
+	}
+	tag := strings.Replace(dgst.String(), ":", "-", 1)
+	for _, t := range []struct {
+		suffix string
+		descs  *[]ocispecs.Descriptor
+	}{{".sig", &sigs}, {".att", &atts}} {
+		desc, err := reg.Resolve(ctx, tag+t.suffix)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return errors.Wrapf(err, "failed to resolve %s of %s", tag+t.suffix, ref)
+		}
+		*t.descs = append(*t.descs, desc)
+	}
+
+	verified := false
+	for _, desc := range sigs {
+		ok, err := verifySignatures(ctx, reg, desc, dgst, p.Keys)
+		if err != nil {
+			return err
+		}
+		if ok {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return errors.New("no signature verified with the keys of the policy")
+	}
+
+	if len(p.Attestations) == 0 {
+		return nil
+	}
+	found := map[string]struct{}{}
+	for _, desc := range atts {
+		if err := verifyAttestations(ctx, reg, desc, dgst, p.Keys, found); err != nil {
+			return err
+		}
+	}
+	var missing []string
+	for _, a := range p.Attestations {
+		if _, ok := found[a]; !ok {
+			missing = append(missing, a)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return errors.Errorf("no signed attestation of type %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// simpleSigningPayload is the payload of the cosign signatures.
+type simpleSigningPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest digest.Digest `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+func fetchManifest(ctx context.Context, reg Registry, desc ocispecs.Descriptor) (*ocispecs.Manifest, error) {
+	dt, err := reg.Fetch(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	var mfst ocispecs.Manifest
+	if err := json.Unmarshal(dt, &mfst); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse manifest %s", desc.Digest)
+	}
+	return &mfst, nil
+}
+
+// verifySignatures returns true if a signature of the signature manifest of
+// desc signs dgst with one of the keys.
+func verifySignatures(ctx context.Context, reg Registry, desc ocispecs.Descriptor, dgst digest.Digest, keys []crypto.PublicKey) (bool, error) {
+	mfst, err := fetchManifest(ctx, reg, desc)
+	if err != nil {
+		return false, err
+	}
+	for _, l := range mfst.Layers {
+		b64sig, ok := l.Annotations[annotationSignature]
+		if l.MediaType != mediaTypeSimpleSigning || !ok {
+			continue
+		}
+		sig, err := base64.StdEncoding.DecodeString(b64sig)
+		if err != nil {
+			continue
+		}
+		payload, err := reg.Fetch(ctx, l)
+		if err != nil {
+			return false, err
+		}
+		var p simpleSigningPayload
+		if err := json.Unmarshal(payload, &p); err != nil || p.Critical.Image.DockerManifestDigest != dgst {
+			continue
+		}
+		if verifySignature(keys, payload, sig) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type dsseEnvelope struct {
+	PayloadType string `json:"payloadType"`
+	Payload     string `json:"payload"`
+	Signatures  []struct {
+		Sig string `json:"sig"`
+	} `json:"signatures"`
+}
+
+type inTotoStatement struct {
+	PredicateType string `json:"predicateType"`
+	Subject       []struct {
+		Digest map[string]string `json:"digest"`
+	} `json:"subject"`
+}
+
+// verifyAttestations adds the predicate types of the attestations of the
+// attestation manifest of desc about dgst signed with one of the keys to
+// found.
+func verifyAttestations(ctx context.Context, reg Registry, desc ocispecs.Descriptor, dgst digest.Digest, keys []crypto.PublicKey, found map[string]struct{}) error {
+	mfst, err := fetchManifest(ctx, reg, desc)
+	if err != nil {
+		return err
+	}
+	for _, l := range mfst.Layers {
+		if l.MediaType != mediaTypeDSSE {
+			continue
+		}
+		dt, err := reg.Fetch(ctx, l)
+		if err != nil {
+			return err
+		}
+		var env dsseEnvelope
+		if err := json.Unmarshal(dt, &env); err != nil || env.PayloadType != payloadTypeInToto {
+			continue
+		}
+		payload, err := base64.StdEncoding.DecodeString(env.Payload)
+		if err != nil {
+			continue
+		}
+		signed := false
+		for _, s := range env.Signatures {
+			sig, err := base64.StdEncoding.DecodeString(s.Sig)
+			if err == nil && verifySignature(keys, pae(env.PayloadType, payload), sig) {
+				signed = true
+				break
+			}
+		}
+		if !signed {
+			continue
+		}
+		var st inTotoStatement
+		if err := json.Unmarshal(payload, &st); err != nil {
+			continue
+		}
+		for _, s := range st.Subject {
+			if s.Digest[dgst.Algorithm().String()] == dgst.Hex() {
+				found[st.PredicateType] = struct{}{}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// pae returns the pre-authentication encoding of DSSE signing the payload.
+func pae(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+func verifySignature(keys []crypto.PublicKey, payload, sig []byte) bool {
+	h := sha256.Sum256(payload)
+	for _, k := range keys {
+		switch k := k.(type) {
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(k, h[:], sig) {
+				return true
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(k, crypto.SHA256, h[:], sig) == nil || rsa.VerifyPSS(k, crypto.SHA256, h[:], sig, nil) == nil {
+				return true
+			}
+		case ed25519.PublicKey:
+			if ed25519.Verify(k, payload, sig) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package imageverify
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRegistry struct {
+	blobs     map[digest.Digest][]byte
+	tags      map[string]ocispecs.Descriptor
+	referrers []Referrer
+}
+
+func (r *fakeRegistry) Referrers(ctx context.Context, dgst digest.Digest) ([]Referrer, error) {
+	return r.referrers, nil
+}
+
+func (r *fakeRegistry) Resolve(ctx context.Context, tag string) (ocispecs.Descriptor, error) {
+	desc, ok := r.tags[tag]
+	if !ok {
+		return ocispecs.Descriptor{}, errors.Wrap(ErrNotFound, tag)
+	}
+	return desc, nil
+}
+
+func (r *fakeRegistry) Fetch(ctx context.Context, desc ocispecs.Descriptor) ([]byte, error) {
+	dt, ok := r.blobs[desc.Digest]
+	if !ok {
+		return nil, errors.Errorf("%s not found", desc.Digest)
+	}
+	return dt, nil
+}
+
+func (r *fakeRegistry) add(t *testing.T, mediaType string, v interface{}) ocispecs.Descriptor {
+	dt, ok := v.([]byte)
+	if !ok {
+		var err error
+		dt, err = json.Marshal(v)
+		require.NoError(t, err)
+	}
+	desc := ocispecs.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(dt), Size: int64(len(dt))}
+	r.blobs[desc.Digest] = dt
+	return desc
+}
+
+func sign(t *testing.T, key *ecdsa.PrivateKey, payload []byte) string {
+	h := sha256.Sum256(payload)
+	sig, err := key.Sign(rand.Reader, h[:], crypto.SHA256)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+func newPolicy(t *testing.T, key *ecdsa.PrivateKey, attestations string) *Policy {
+	dt, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	p, err := ParsePolicy(string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: dt})), attestations)
+	require.NoError(t, err)
+	return p
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.TODO()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	reg := &fakeRegistry{blobs: map[digest.Digest][]byte{}, tags: map[string]ocispecs.Descriptor{}}
+	dgst := digest.FromString("image manifest")
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"critical": map[string]interface{}{
+			"image": map[string]string{"docker-manifest-digest": dgst.String()},
+			"type":  "cosign container image signature",
+		},
+	})
+	require.NoError(t, err)
+	sigLayer := reg.add(t, mediaTypeSimpleSigning, payload)
+	sigLayer.Annotations = map[string]string{annotationSignature: sign(t, key, payload)}
+	sigManifest := reg.add(t, ocispecs.MediaTypeImageManifest, ocispecs.Manifest{Layers: []ocispecs.Descriptor{sigLayer}})
+	reg.tags["sha256-"+dgst.Hex()+".sig"] = sigManifest
+
+	err = Verify(ctx, reg, "docker.io/library/alpine:latest", dgst, newPolicy(t, key, ""))
+	require.NoError(t, err)
+
+	err = Verify(ctx, reg, "docker.io/library/alpine:latest", dgst, newPolicy(t, otherKey, ""))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no signature verified")
+
+	err = Verify(ctx, reg, "docker.io/library/alpine:latest", digest.FromString("other manifest"), newPolicy(t, key, ""))
+	require.Error(t, err)
+
+	err = Verify(ctx, reg, "docker.io/library/alpine:latest", dgst, newPolicy(t, key, "https://slsa.dev/provenance/v0.2"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no signed attestation of type https://slsa.dev/provenance/v0.2")
+
+	statement, err := json.Marshal(map[string]interface{}{
+		"_type":         "https://in-toto.io/Statement/v0.1",
+		"predicateType": "https://slsa.dev/provenance/v0.2",
+		"subject": []interface{}{
+			map[string]interface{}{"digest": map[string]string{"sha256": dgst.Hex()}},
+		},
+	})
+	require.NoError(t, err)
+	env, err := json.Marshal(map[string]interface{}{
+		"payloadType": payloadTypeInToto,
+		"payload":     base64.StdEncoding.EncodeToString(statement),
+		"signatures":  []interface{}{map[string]string{"sig": sign(t, key, pae(payloadTypeInToto, statement))}},
+	})
+	require.NoError(t, err)
+	attLayer := reg.add(t, mediaTypeDSSE, env)
+	attManifest := reg.add(t, ocispecs.MediaTypeImageManifest, ocispecs.Manifest{Layers: []ocispecs.Descriptor{attLayer}})
+	reg.referrers = []Referrer{{Descriptor: attManifest, ArtifactType: AttestationArtifactType}}
+
+	err = Verify(ctx, reg, "docker.io/library/alpine:latest", dgst, newPolicy(t, key, "https://slsa.dev/provenance/v0.2"))
+	require.NoError(t, err)
+
+	err = Verify(ctx, reg, "docker.io/library/alpine:latest", dgst, newPolicy(t, key, "https://slsa.dev/provenance/v0.2,https://spdx.dev/Document"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no signed attestation of type https://spdx.dev/Document")
+}
+
+func TestParsePolicy(t *testing.T) {
+	_, err := ParsePolicy("", "")
+	require.Error(t, err)
+
+	_, err = ParsePolicy(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("foo")})), "")
+	require.Error(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p := newPolicy(t, key, " a, ,b")
+	require.Len(t, p.Keys, 1)
+	require.Equal(t, []string{"a", "b"}, p.Attestations)
+}
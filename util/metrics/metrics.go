@@ -0,0 +1,137 @@
+// Package metrics defines the Prometheus metrics of the daemon.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "buildkit"
+
+var (
+	ActiveBuilds = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "solver",
+		Name:      "active_builds",
+		Help:      "Number of builds being solved.",
+	})
+	Vertexes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "solver",
+		Name:      "vertexes_total",
+		Help:      "Number of vertexes that were executed or loaded from the cache.",
+	}, []string{"cached"})
+	GCRuns = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "gc",
+		Name:      "runs_total",
+		Help:      "Number of garbage collection runs.",
+	})
+	GCDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "gc",
+		Name:      "duration_seconds",
+		Help:      "Duration of garbage collection runs.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10),
+	})
+	GCReclaimedBytes = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "gc",
+		Name:      "reclaimed_bytes_total",
+		Help:      "Size of the records removed by garbage collection.",
+	})
+	PushedBytes = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "push",
+		Name:      "bytes_total",
+		Help:      "Size of the blobs and manifests pushed to registries.",
+	})
+	PushDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "push",
+		Name:      "duration_seconds",
+		Help:      "Duration of image pushes.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 3, 10),
+	})
+)
+
+var registry = prometheus.NewRegistry()
+
+func init() {
+	registry.MustRegister(
+		ActiveBuilds,
+		Vertexes,
+		GCRuns,
+		GCDuration,
+		GCReclaimedBytes,
+		PushedBytes,
+		PushDuration,
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+	)
+}
+
+// Register adds a collector to the metrics served by Handler.
+func Register(c prometheus.Collector) error {
+	return registry.Register(c)
+}
+
+// Handler serves the metrics in the Prometheus format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// NewSessionsCollector returns a collector for the number of active
+// sessions.
+func NewSessionsCollector(count func() int) prometheus.Collector {
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "session",
+		Name:      "active",
+		Help:      "Number of active client sessions.",
+	}, func() float64 {
+		return float64(count())
+	})
+}
+
+// diskUsageTimeout limits how long a scrape waits for the disk usage of the
+// workers.
+const diskUsageTimeout = 10 * time.Second
+
+// NewDiskUsageCollector returns a collector for the size of the build cache
+// of each worker. The usage is computed when the metrics are collected.
+func NewDiskUsageCollector(usage func(context.Context) (map[string]int64, error)) prometheus.Collector {
+	return &diskUsageCollector{
+		usage: usage,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "worker", "disk_usage_bytes"),
+			"Size of the build cache records of the worker.",
+			[]string{"worker"}, nil,
+		),
+	}
+}
+
+type diskUsageCollector struct {
+	usage func(context.Context) (map[string]int64, error)
+	desc  *prometheus.Desc
+}
+
+func (c *diskUsageCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *diskUsageCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), diskUsageTimeout)
+	defer cancel()
+	usage, err := c.usage(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	for id, size := range usage {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(size), id)
+	}
+}
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	ActiveBuilds.Inc()
+	defer ActiveBuilds.Dec()
+	Vertexes.WithLabelValues("true").Inc()
+
+	require.NoError(t, Register(NewSessionsCollector(func() int { return 3 })))
+	require.NoError(t, Register(NewDiskUsageCollector(func(context.Context) (map[string]int64, error) {
+		return map[string]int64{"worker0": 1024}, nil
+	})))
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	dt, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+
+	out := string(dt)
+	require.Contains(t, out, "buildkit_solver_active_builds 1")
+	require.Contains(t, out, `buildkit_solver_vertexes_total{cached="true"} 1`)
+	require.Contains(t, out, "buildkit_session_active 3")
+	require.Contains(t, out, `buildkit_worker_disk_usage_bytes{worker="worker0"} 1024`)
+}
+
+func TestDiskUsageCollectorError(t *testing.T) {
+	c := NewDiskUsageCollector(func(context.Context) (map[string]int64, error) {
+		return nil, errors.New("failed")
+	})
+	ch := make(chan prometheus.Metric, 1)
+	c.Collect(ch)
+	m := <-ch
+	require.Error(t, m.Write(&dto.Metric{}))
+}
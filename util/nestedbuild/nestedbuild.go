@@ -0,0 +1,124 @@
+// Package nestedbuild serves the BuildKit API of the daemon to the containers
+// of builds, so that they can run nested builds without access to the socket
+// of the daemon.
+package nestedbuild
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/moby/buildkit/executor"
+	"github.com/moby/buildkit/util/entitlements"
+	"github.com/pkg/errors"
+)
+
+// Parent is the build running the container that requests nested builds.
+type Parent struct {
+	// ID is the ID of the build.
+	ID string
+	// Entitlements are the entitlements of the build. Nested builds can only
+	// request these.
+	Entitlements []entitlements.Entitlement
+	// Limits are the resource limits of the build, nil if unlimited. Nested
+	// builds can't exceed them.
+	Limits *executor.ResourceLimits
+	// Weight is the scheduling weight of the build.
+	Weight int
+	// Tenant is the tenant of the build, empty if it has none.
+	Tenant string
+}
+
+// Server serves the BuildKit API for the nested builds of a parent build.
+type Server interface {
+	// Serve serves the connections of the listener until the listener is
+	// closed or the context is done. The nested builds are canceled when the
+	// context is done.
+	Serve(ctx context.Context, l net.Listener, p Parent) error
+}
+
+type serverKey struct{}
+
+type serverValue struct {
+	server Server
+	parent Parent
+}
+
+// WithServer returns a context with the server for the nested builds of the
+// operations run with it.
+func WithServer(ctx context.Context, s Server, p Parent) context.Context {
+	return context.WithValue(ctx, serverKey{}, &serverValue{server: s, parent: p})
+}
+
+// ServerFromContext returns the server set with WithServer and the parent
+// build, or nil if nested builds are not supported.
+func ServerFromContext(ctx context.Context) (Server, Parent) {
+	v, ok := ctx.Value(serverKey{}).(*serverValue)
+	if !ok {
+		return nil, Parent{}
+	}
+	return v.server, v.parent
+}
+
+// SocketOpt are the options of the socket served to the container.
+type SocketOpt struct {
+	UID  int
+	GID  int
+	Mode int
+}
+
+// MountSocket creates a socket serving the BuildKit API for the nested builds
+// of the parent build. The returned function stops serving, cancels the
+// nested builds and removes the socket.
+func MountSocket(ctx context.Context, s Server, p Parent, opt SocketOpt) (sockPath string, closer func() error, err error) {
+	dir, err := ioutil.TempDir("", ".buildkit-nested-sock")
+	if err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+
+	defer func() {
+		if err != nil {
+			os.RemoveAll(dir)
+		}
+	}()
+
+	if err := os.Chmod(dir, 0711); err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+
+	sockPath = filepath.Join(dir, "buildkitd.sock")
+
+	l, err := net.Listen("unix", sockPath)
+	if err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+
+	if err := os.Chown(sockPath, opt.UID, opt.GID); err != nil {
+		l.Close()
+		return "", nil, errors.WithStack(err)
+	}
+	if err := os.Chmod(sockPath, os.FileMode(opt.Mode)); err != nil {
+		l.Close()
+		return "", nil, errors.WithStack(err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Serve(ctx, l, p) // errors of the connections are reported to the nested builds
+	}()
+
+	return sockPath, func() error {
+		cancel()
+		err := l.Close()
+		<-done
+		os.RemoveAll(dir)
+		if errors.Is(err, net.ErrClosed) {
+			err = nil
+		}
+		return errors.WithStack(err)
+	}, nil
+}
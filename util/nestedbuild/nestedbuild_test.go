@@ -0,0 +1,66 @@
+//go:build !windows
+// +build !windows
+
+package nestedbuild
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type echoServer struct {
+	parents chan Parent
+}
+
+func (s *echoServer) Serve(ctx context.Context, l net.Listener, p Parent) error {
+	s.parents <- p
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			buf := make([]byte, 4)
+			n, _ := conn.Read(buf)
+			conn.Write(buf[:n])
+		}()
+	}
+}
+
+func TestMountSocket(t *testing.T) {
+	srv := &echoServer{parents: make(chan Parent, 1)}
+	ctx := WithServer(context.TODO(), srv, Parent{ID: "build"})
+
+	s, p := ServerFromContext(ctx)
+	require.Equal(t, srv, s)
+
+	sock, closer, err := MountSocket(ctx, s, p, SocketOpt{UID: os.Getuid(), GID: os.Getgid(), Mode: 0600})
+	require.NoError(t, err)
+	require.Equal(t, "build", (<-srv.parents).ID)
+
+	fi, err := os.Stat(sock)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	conn, err := net.Dial("unix", sock)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	_, err = conn.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "ping", string(buf))
+	conn.Close()
+
+	require.NoError(t, closer())
+	_, err = os.Stat(sock)
+	require.True(t, os.IsNotExist(err))
+
+	s, _ = ServerFromContext(context.TODO())
+	require.Nil(t, s)
+}
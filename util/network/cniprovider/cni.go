@@ -2,12 +2,15 @@ package cniprovider
 
 import (
 	"context"
+	"net"
 	"os"
 	"runtime"
+	"sync/atomic"
 
 	cni "github.com/containerd/go-cni"
 	"github.com/gofrs/flock"
 	"github.com/moby/buildkit/identity"
+	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/network"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/pkg/errors"
@@ -17,6 +20,18 @@ type Opt struct {
 	Root       string
 	ConfigPath string
 	BinaryDir  string
+	// PoolSize is the number of namespaces that are set up in advance so
+	// that the build steps don't wait for the CNI plugins.
+	PoolSize int
+	// Egress restricts the outgoing connections of the namespaces to the
+	// destinations of the rules if set.
+	Egress []EgressRule
+	// PublishPorts are the host ports the namespaces can publish, none if
+	// unset.
+	PublishPorts []int
+	// PublishHostIP is the address of the host the ports are published on,
+	// all the addresses of the host if unset.
+	PublishHostIP string
 }
 
 func New(opt Opt) (network.Provider, error) {
@@ -42,16 +57,39 @@ func New(opt Opt) (network.Provider, error) {
 		return nil, err
 	}
 
-	cp := &cniProvider{CNI: cniHandle, root: opt.Root}
+	if _, err := egressCommands(opt.Egress); err != nil {
+		return nil, err
+	}
+
+	if opt.PublishHostIP != "" && net.ParseIP(opt.PublishHostIP) == nil {
+		return nil, errors.Errorf("invalid publish host IP %q", opt.PublishHostIP)
+	}
+
+	cp := &cniProvider{
+		CNI:           cniHandle,
+		root:          opt.Root,
+		egress:        opt.Egress,
+		publishPorts:  opt.PublishPorts,
+		publishHostIP: opt.PublishHostIP,
+	}
+	if opt.PoolSize > 0 {
+		cp.pool = make(chan *cniNS, opt.PoolSize)
+	}
 	if err := cp.initNetwork(); err != nil {
 		return nil, err
 	}
+	go cp.fillPool()
 	return cp, nil
 }
 
 type cniProvider struct {
 	cni.CNI
-	root string
+	root          string
+	egress        []EgressRule
+	publishPorts  []int
+	publishHostIP string
+	pool          chan *cniNS
+	filling       int32
 }
 
 func (c *cniProvider) initNetwork() error {
@@ -69,25 +107,75 @@ func (c *cniProvider) initNetwork() error {
 	return ns.Close()
 }
 
+// New returns a namespace of the pool, or sets up a new one if the pool is
+// empty. The namespaces are never reused so that the build steps don't share
+// their network.
 func (c *cniProvider) New() (network.Namespace, error) {
+	select {
+	case ns := <-c.pool:
+		go c.fillPool()
+		return ns, nil
+	default:
+	}
+	return c.newNS()
+}
+
+// NewPublished sets up a namespace with the ports published on the host. The
+// CNI config needs the portmap plugin.
+func (c *cniProvider) NewPublished(ports []network.PortMapping) (network.Namespace, error) {
+	mappings, err := portMappings(ports, c.publishPorts, c.publishHostIP)
+	if err != nil {
+		return nil, err
+	}
+	return c.newNS(cni.WithCapabilityPortMap(mappings))
+}
+
+func (c *cniProvider) newNS(opts ...cni.NamespaceOpts) (*cniNS, error) {
 	id := identity.NewID()
 	nativeID, err := createNetNS(c, id)
 	if err != nil {
 		return nil, err
 	}
 
-	if _, err := c.CNI.Setup(context.TODO(), id, nativeID); err != nil {
+	if _, err := c.CNI.Setup(context.TODO(), id, nativeID, opts...); err != nil {
 		deleteNetNS(nativeID)
 		return nil, errors.Wrap(err, "CNI setup error")
 	}
 
-	return &cniNS{nativeID: nativeID, id: id, handle: c.CNI}, nil
+	ns := &cniNS{nativeID: nativeID, id: id, handle: c.CNI, opts: opts}
+	if err := applyEgress(nativeID, c.egress); err != nil {
+		ns.Close()
+		return nil, errors.Wrap(err, "failed to apply egress rules")
+	}
+	return ns, nil
+}
+
+// fillPool sets up namespaces until the pool is full.
+func (c *cniProvider) fillPool() {
+	if c.pool == nil || !atomic.CompareAndSwapInt32(&c.filling, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&c.filling, 0)
+	for len(c.pool) < cap(c.pool) {
+		ns, err := c.newNS()
+		if err != nil {
+			bklog.L.WithError(err).Warn("failed to set up network namespace for the pool")
+			return
+		}
+		select {
+		case c.pool <- ns:
+		default:
+			ns.Close()
+			return
+		}
+	}
 }
 
 type cniNS struct {
 	handle   cni.CNI
 	id       string
 	nativeID string
+	opts     []cni.NamespaceOpts
 }
 
 func (ns *cniNS) Set(s *specs.Spec) error {
@@ -95,7 +183,7 @@ func (ns *cniNS) Set(s *specs.Spec) error {
 }
 
 func (ns *cniNS) Close() error {
-	err := ns.handle.Remove(context.TODO(), ns.id, ns.nativeID)
+	err := ns.handle.Remove(context.TODO(), ns.id, ns.nativeID, ns.opts...)
 	if err1 := unmountNetNS(ns.nativeID); err1 != nil && err == nil {
 		err = err1
 	}
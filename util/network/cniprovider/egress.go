@@ -0,0 +1,79 @@
+package cniprovider
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// EgressRule allows the outgoing connections to a destination of a network.
+type EgressRule struct {
+	// CIDR is the destination network or address, e.g. 10.0.0.0/8.
+	CIDR string
+	// Ports restricts the rule to destination ports if set.
+	Ports []int
+	// Protocol of the ports, tcp if unset.
+	Protocol string
+}
+
+// egressCommands returns the iptables and ip6tables commands that reject
+// the outgoing connections of a namespace that are not allowed by the rules.
+// The replies to incoming connections and the loopback traffic are always
+// allowed.
+func egressCommands(rules []EgressRule) ([][]string, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	var out [][]string
+	for _, bin := range []string{"iptables", "ip6tables"} {
+		out = append(out,
+			[]string{bin, "-A", "OUTPUT", "-o", "lo", "-j", "ACCEPT"},
+			[]string{bin, "-A", "OUTPUT", "-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "-j", "ACCEPT"},
+		)
+		for _, r := range rules {
+			ipnet, err := parseCIDR(r.CIDR)
+			if err != nil {
+				return nil, err
+			}
+			if (ipnet.IP.To4() != nil) != (bin == "iptables") {
+				continue
+			}
+			protocol := r.Protocol
+			if protocol == "" {
+				protocol = "tcp"
+			}
+			if protocol != "tcp" && protocol != "udp" {
+				return nil, errors.Errorf("invalid egress protocol %q", r.Protocol)
+			}
+			if len(r.Ports) == 0 {
+				out = append(out, []string{bin, "-A", "OUTPUT", "-d", ipnet.String(), "-j", "ACCEPT"})
+				continue
+			}
+			for _, p := range r.Ports {
+				if p <= 0 || p > 65535 {
+					return nil, errors.Errorf("invalid egress port %d", p)
+				}
+				out = append(out, []string{bin, "-A", "OUTPUT", "-d", ipnet.String(), "-p", protocol, "--dport", strconv.Itoa(p), "-j", "ACCEPT"})
+			}
+		}
+		out = append(out, []string{bin, "-A", "OUTPUT", "-j", "REJECT"})
+	}
+	return out, nil
+}
+
+func parseCIDR(s string) (*net.IPNet, error) {
+	if ip := net.ParseIP(s); ip != nil {
+		bits := 128
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = 32
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, ipnet, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, errors.Errorf("invalid egress destination %q", s)
+	}
+	return ipnet, nil
+}
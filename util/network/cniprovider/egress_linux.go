@@ -0,0 +1,56 @@
+//go:build linux
+// +build linux
+
+package cniprovider
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// applyEgress runs the egress commands of the rules in the namespace. The
+// ip6tables commands are skipped if ip6tables is not installed.
+func applyEgress(nsPath string, rules []EgressRule) error {
+	cmds, err := egressCommands(rules)
+	if err != nil || len(cmds) == 0 {
+		return err
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		// the thread is never unlocked so that it exits with the goroutine
+		// instead of being reused while in the namespace
+		runtime.LockOSThread()
+		errCh <- inNetNS(nsPath, func() error {
+			_, ip6Err := exec.LookPath("ip6tables")
+			for _, args := range cmds {
+				if args[0] == "ip6tables" && ip6Err != nil {
+					continue
+				}
+				if out, err := exec.Command(args[0], args[1:]...).CombinedOutput(); err != nil {
+					return errors.Wrapf(err, "%s: %s", strings.Join(args, " "), strings.TrimSpace(string(out)))
+				}
+			}
+			return nil
+		})
+	}()
+	return <-errCh
+}
+
+// inNetNS calls f with the thread in the network namespace. The thread must
+// be locked.
+func inNetNS(nsPath string, f func() error) error {
+	ns, err := os.Open(nsPath)
+	if err != nil {
+		return err
+	}
+	defer ns.Close()
+	if err := unix.Setns(int(ns.Fd()), unix.CLONE_NEWNET); err != nil {
+		return errors.Wrapf(err, "failed to enter network namespace %s", nsPath)
+	}
+	return f()
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+// +build !linux
+
+package cniprovider
+
+import (
+	"github.com/pkg/errors"
+)
+
+func applyEgress(nsPath string, rules []EgressRule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	return errors.New("egress rules are only supported on linux")
+}
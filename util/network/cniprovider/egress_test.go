@@ -0,0 +1,39 @@
+package cniprovider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEgressCommands(t *testing.T) {
+	cmds, err := egressCommands(nil)
+	require.NoError(t, err)
+	require.Nil(t, cmds)
+
+	cmds, err = egressCommands([]EgressRule{
+		{CIDR: "10.0.0.0/8"},
+		{CIDR: "192.168.1.10", Ports: []int{443, 8443}},
+		{CIDR: "fd00::/8", Ports: []int{53}, Protocol: "udp"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"iptables", "-A", "OUTPUT", "-o", "lo", "-j", "ACCEPT"},
+		{"iptables", "-A", "OUTPUT", "-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "-j", "ACCEPT"},
+		{"iptables", "-A", "OUTPUT", "-d", "10.0.0.0/8", "-j", "ACCEPT"},
+		{"iptables", "-A", "OUTPUT", "-d", "192.168.1.10/32", "-p", "tcp", "--dport", "443", "-j", "ACCEPT"},
+		{"iptables", "-A", "OUTPUT", "-d", "192.168.1.10/32", "-p", "tcp", "--dport", "8443", "-j", "ACCEPT"},
+		{"iptables", "-A", "OUTPUT", "-j", "REJECT"},
+		{"ip6tables", "-A", "OUTPUT", "-o", "lo", "-j", "ACCEPT"},
+		{"ip6tables", "-A", "OUTPUT", "-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "-j", "ACCEPT"},
+		{"ip6tables", "-A", "OUTPUT", "-d", "fd00::/8", "-p", "udp", "--dport", "53", "-j", "ACCEPT"},
+		{"ip6tables", "-A", "OUTPUT", "-j", "REJECT"},
+	}, cmds)
+
+	_, err = egressCommands([]EgressRule{{CIDR: "example.com"}})
+	require.Error(t, err)
+	_, err = egressCommands([]EgressRule{{CIDR: "10.0.0.1", Protocol: "icmp"}})
+	require.Error(t, err)
+	_, err = egressCommands([]EgressRule{{CIDR: "10.0.0.1", Ports: []int{70000}}})
+	require.Error(t, err)
+}
@@ -0,0 +1,45 @@
+package cniprovider
+
+import (
+	cni "github.com/containerd/go-cni"
+	"github.com/moby/buildkit/util/network"
+	"github.com/pkg/errors"
+)
+
+// portMappings converts the published ports after checking that the host
+// ports are allowed. The ports are published on hostIP if it is set.
+func portMappings(ports []network.PortMapping, allowed []int, hostIP string) ([]cni.PortMapping, error) {
+	mappings := make([]cni.PortMapping, len(ports))
+	for i, p := range ports {
+		if !portAllowed(int(p.HostPort), allowed) {
+			return nil, errors.Errorf("publishing host port %d is not allowed by the network", p.HostPort)
+		}
+		ip := p.HostIP
+		if hostIP != "" {
+			if ip != "" && ip != hostIP {
+				return nil, errors.Errorf("publishing ports on host address %s is not allowed by the network", ip)
+			}
+			ip = hostIP
+		}
+		protocol := p.Protocol
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		mappings[i] = cni.PortMapping{
+			HostPort:      p.HostPort,
+			ContainerPort: p.ContainerPort,
+			Protocol:      protocol,
+			HostIP:        ip,
+		}
+	}
+	return mappings, nil
+}
+
+func portAllowed(port int, allowed []int) bool {
+	for _, p := range allowed {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
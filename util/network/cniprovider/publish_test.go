@@ -0,0 +1,40 @@
+package cniprovider
+
+import (
+	"testing"
+
+	cni "github.com/containerd/go-cni"
+	"github.com/moby/buildkit/util/network"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortMappings(t *testing.T) {
+	ports := []network.PortMapping{
+		{HostPort: 8080, ContainerPort: 80},
+		{HostPort: 5353, ContainerPort: 53, Protocol: "udp", HostIP: "127.0.0.1"},
+	}
+
+	mappings, err := portMappings(ports, []int{5353, 8080}, "")
+	require.NoError(t, err)
+	require.Equal(t, []cni.PortMapping{
+		{HostPort: 8080, ContainerPort: 80, Protocol: "tcp"},
+		{HostPort: 5353, ContainerPort: 53, Protocol: "udp", HostIP: "127.0.0.1"},
+	}, mappings)
+
+	_, err = portMappings(ports, nil, "")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "host port 8080")
+
+	_, err = portMappings(ports, []int{8080}, "")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "host port 5353")
+
+	// the ports are published on the configured address only
+	mappings, err = portMappings(ports[:1], []int{8080}, "10.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.1", mappings[0].HostIP)
+
+	_, err = portMappings(ports, []int{5353, 8080}, "10.0.0.1")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "host address 127.0.0.1")
+}
@@ -0,0 +1,89 @@
+package network
+
+import (
+	"github.com/pkg/errors"
+)
+
+// NamedProvider is a Provider that can also create namespaces attached to
+// one of its named networks.
+type NamedProvider interface {
+	Provider
+	NewNamed(name string) (Namespace, error)
+	// Named returns the provider of the named network.
+	Named(name string) (Provider, bool)
+}
+
+// WithNamed returns a provider that uses p for the default network and the
+// providers in named for the named networks.
+func WithNamed(p Provider, named map[string]Provider) NamedProvider {
+	return &namedProvider{Provider: p, named: named}
+}
+
+// PortMapping publishes a port of a namespace on the host.
+type PortMapping struct {
+	HostPort      int32
+	ContainerPort int32
+	// Protocol is tcp or udp, tcp if unset.
+	Protocol string
+	// HostIP is the address of the host the port is published on, all the
+	// addresses of the host if unset.
+	HostIP string
+}
+
+// PortPublisher is a Provider that can create namespaces with ports
+// published on the host.
+type PortPublisher interface {
+	NewPublished(ports []PortMapping) (Namespace, error)
+}
+
+// NewNamespace creates a new namespace from p. If name is set the namespace
+// is attached to the named network. The ports are published on the host.
+func NewNamespace(p Provider, name string, ports ...PortMapping) (Namespace, error) {
+	if name != "" {
+		np, ok := p.(NamedProvider)
+		if !ok {
+			return nil, errors.Errorf("network %q is not available", name)
+		}
+		if len(ports) == 0 {
+			return np.NewNamed(name)
+		}
+		p, ok = np.Named(name)
+		if !ok {
+			return nil, errors.Errorf("network %q is not available", name)
+		}
+	}
+	if len(ports) == 0 {
+		return p.New()
+	}
+	pp, ok := p.(PortPublisher)
+	if !ok {
+		return nil, errors.Errorf("publishing ports is not supported by the network")
+	}
+	return pp.NewPublished(ports)
+}
+
+type namedProvider struct {
+	Provider
+	named map[string]Provider
+}
+
+func (p *namedProvider) Named(name string) (Provider, bool) {
+	np, ok := p.named[name]
+	return np, ok
+}
+
+func (p *namedProvider) NewPublished(ports []PortMapping) (Namespace, error) {
+	pp, ok := p.Provider.(PortPublisher)
+	if !ok {
+		return nil, errors.Errorf("publishing ports is not supported by the network")
+	}
+	return pp.NewPublished(ports)
+}
+
+func (p *namedProvider) NewNamed(name string) (Namespace, error) {
+	np, ok := p.named[name]
+	if !ok {
+		return nil, errors.Errorf("network %q is not available", name)
+	}
+	return np.New()
+}
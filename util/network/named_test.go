@@ -0,0 +1,56 @@
+package network
+
+import (
+	"testing"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/require"
+)
+
+type testProvider struct {
+	name      string
+	published []PortMapping
+}
+
+func (p *testProvider) New() (Namespace, error) {
+	return &testNS{name: p.name}, nil
+}
+
+func (p *testProvider) NewPublished(ports []PortMapping) (Namespace, error) {
+	p.published = ports
+	return &testNS{name: p.name}, nil
+}
+
+type testNS struct {
+	name string
+}
+
+func (ns *testNS) Set(*specs.Spec) error { return nil }
+func (ns *testNS) Close() error          { return nil }
+
+func TestNewNamespace(t *testing.T) {
+	def := &testProvider{name: "default"}
+	pool := &testProvider{name: "pool"}
+	p := WithNamed(def, map[string]Provider{"pool": pool})
+	ports := []PortMapping{{HostPort: 8080, ContainerPort: 80}}
+
+	ns, err := NewNamespace(p, "")
+	require.NoError(t, err)
+	require.Equal(t, "default", ns.(*testNS).name)
+
+	ns, err = NewNamespace(p, "pool", ports...)
+	require.NoError(t, err)
+	require.Equal(t, "pool", ns.(*testNS).name)
+	require.Equal(t, ports, pool.published)
+
+	ns, err = NewNamespace(p, "", ports...)
+	require.NoError(t, err)
+	require.Equal(t, "default", ns.(*testNS).name)
+	require.Equal(t, ports, def.published)
+
+	_, err = NewNamespace(p, "missing")
+	require.Error(t, err)
+
+	_, err = NewNamespace(NewNoneProvider(), "", ports...)
+	require.Error(t, err)
+}
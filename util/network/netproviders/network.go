@@ -6,12 +6,34 @@ import (
 	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/network"
 	"github.com/moby/buildkit/util/network/cniprovider"
+	"github.com/moby/buildkit/util/network/slirpprovider"
 	"github.com/pkg/errors"
 )
 
 type Opt struct {
 	CNI  cniprovider.Opt
 	Mode string
+	// CNINetworks maps network names to CNI config files. Build steps can
+	// select these networks by name instead of the default network.
+	CNINetworks map[string]string
+	// Pools are named CNI networks with namespaces set up in advance. Build
+	// steps select them by name like the CNINetworks.
+	Pools []NetworkPool
+	// Slirp4netns configures the slirp4netns mode.
+	Slirp4netns slirpprovider.Opt
+}
+
+// NetworkPool is a named CNI network that keeps Size namespaces ready. The
+// outgoing connections of its namespaces are restricted to the destinations
+// of the Egress rules if set. Gateway containers can publish the
+// PublishPorts on the PublishHostIP address of the host.
+type NetworkPool struct {
+	Name          string
+	ConfigPath    string
+	Size          int
+	Egress        []cniprovider.EgressRule
+	PublishPorts  []int
+	PublishHostIP string
 }
 
 // Providers returns the network provider set.
@@ -26,6 +48,13 @@ func Providers(opt Opt) (providers map[pb.NetMode]network.Provider, resolvedMode
 		}
 		defaultProvider = cniProvider
 		resolvedMode = opt.Mode
+	case "slirp4netns":
+		slirpProvider, err := slirpprovider.New(opt.Slirp4netns)
+		if err != nil {
+			return nil, resolvedMode, err
+		}
+		defaultProvider = slirpProvider
+		resolvedMode = opt.Mode
 	case "host":
 		hostProvider, ok := getHostProvider()
 		if !ok {
@@ -48,6 +77,41 @@ func Providers(opt Opt) (providers map[pb.NetMode]network.Provider, resolvedMode
 		return nil, resolvedMode, errors.Errorf("invalid network mode: %q", opt.Mode)
 	}
 
+	if len(opt.CNINetworks) > 0 || len(opt.Pools) > 0 {
+		named := make(map[string]network.Provider, len(opt.CNINetworks)+len(opt.Pools))
+		for name, configPath := range opt.CNINetworks {
+			cniOpt := opt.CNI
+			cniOpt.ConfigPath = configPath
+			p, err := cniprovider.New(cniOpt)
+			if err != nil {
+				return nil, resolvedMode, errors.Wrapf(err, "failed to create network %q", name)
+			}
+			named[name] = p
+		}
+		for _, pool := range opt.Pools {
+			if pool.Name == "" {
+				return nil, resolvedMode, errors.New("network pool without name")
+			}
+			if _, ok := named[pool.Name]; ok {
+				return nil, resolvedMode, errors.Errorf("duplicate network %q", pool.Name)
+			}
+			cniOpt := opt.CNI
+			if pool.ConfigPath != "" {
+				cniOpt.ConfigPath = pool.ConfigPath
+			}
+			cniOpt.PoolSize = pool.Size
+			cniOpt.Egress = pool.Egress
+			cniOpt.PublishPorts = pool.PublishPorts
+			cniOpt.PublishHostIP = pool.PublishHostIP
+			p, err := cniprovider.New(cniOpt)
+			if err != nil {
+				return nil, resolvedMode, errors.Wrapf(err, "failed to create network pool %q", pool.Name)
+			}
+			named[pool.Name] = p
+		}
+		defaultProvider = network.WithNamed(defaultProvider, named)
+	}
+
 	providers = map[pb.NetMode]network.Provider{
 		pb.NetMode_UNSET: defaultProvider,
 		pb.NetMode_NONE:  network.NewNoneProvider(),
@@ -0,0 +1,20 @@
+// Package slirpprovider provides network namespaces connected to the network
+// of the daemon by slirp4netns, for the build steps of rootless daemons that
+// can't create the veth pairs of CNI networks.
+package slirpprovider
+
+// defaultMTU is the MTU recommended by slirp4netns.
+const defaultMTU = 65520
+
+// Opt are the options of the slirp4netns provider.
+type Opt struct {
+	// Binary is the slirp4netns binary, "slirp4netns" if empty.
+	Binary string
+	// MTU is the MTU of the network namespaces, 65520 if zero.
+	MTU int
+	// Bypass4netns is the bypass4netns binary accelerating the sockets of
+	// the build steps. bypass4netns is disabled if it is empty.
+	Bypass4netns string
+	// Root is the state directory of the daemon.
+	Root string
+}
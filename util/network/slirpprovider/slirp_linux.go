@@ -0,0 +1,291 @@
+package slirpprovider
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/containerd/containerd/oci"
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/network"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+const netnsHolderCmd = "buildkit-netns-holder"
+
+// readyTimeout is how long slirp4netns has to configure the network
+// namespace.
+const readyTimeout = 30 * time.Second
+
+// bypass4netnsSyscalls are the syscalls of the build steps handled by
+// bypass4netns.
+var bypass4netnsSyscalls = []string{"bind", "close", "connect", "sendmsg", "sendto", "setsockopt", "fcntl"}
+
+func init() {
+	reexec.Register(netnsHolderCmd, func() {
+		// keep the network namespace alive until the parent closes stdin,
+		// or exits
+		io.Copy(ioutil.Discard, os.Stdin)
+	})
+}
+
+// New returns a provider of network namespaces connected to the network of
+// the daemon by slirp4netns. It fails if slirp4netns, or bypass4netns when
+// it is enabled, is not installed.
+func New(opt Opt) (network.Provider, error) {
+	bin := opt.Binary
+	if bin == "" {
+		bin = "slirp4netns"
+	}
+	p, err := exec.LookPath(bin)
+	if err != nil {
+		return nil, errors.Wrapf(err, "the slirp4netns network mode requires %s: install slirp4netns or use the host network mode", bin)
+	}
+	mtu := opt.MTU
+	if mtu == 0 {
+		mtu = defaultMTU
+	}
+	prov := &provider{binary: p, mtu: mtu}
+	if opt.Bypass4netns != "" {
+		bp, err := exec.LookPath(opt.Bypass4netns)
+		if err != nil {
+			return nil, errors.Wrapf(err, "bypass4netns is enabled but %s is not installed", opt.Bypass4netns)
+		}
+		prov.bypass = &bypass4netns{
+			binary: bp,
+			socket: filepath.Join(opt.Root, "net", "bypass4netns.sock"),
+		}
+	}
+	return prov, nil
+}
+
+type provider struct {
+	binary string
+	mtu    int
+	bypass *bypass4netns
+}
+
+func (p *provider) New() (network.Namespace, error) {
+	if p.bypass != nil {
+		if err := p.bypass.start(); err != nil {
+			return nil, err
+		}
+	}
+
+	holder := reexec.Command(netnsHolderCmd)
+	holder.SysProcAttr.Cloneflags = unix.CLONE_NEWNET
+	// the holder exits when stdin is closed, it must not be killed with the
+	// thread that started it
+	holder.SysProcAttr.Pdeathsig = 0
+	holderStdin, err := holder.StdinPipe()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := holder.Start(); err != nil {
+		return nil, errors.Wrap(err, "failed to create network namespace")
+	}
+	ns := &slirpNS{holder: holder, holderStdin: holderStdin, bypassSocket: p.bypass.listenerPath()}
+
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		ns.Close()
+		return nil, errors.WithStack(err)
+	}
+	defer readyR.Close()
+	exitR, exitW, err := os.Pipe()
+	if err != nil {
+		readyW.Close()
+		ns.Close()
+		return nil, errors.WithStack(err)
+	}
+	ns.exitW = exitW
+
+	stderr := &limitedBuffer{max: 4096}
+	slirp := exec.Command(p.binary,
+		"--configure",
+		"--mtu="+strconv.Itoa(p.mtu),
+		"--disable-host-loopback",
+		"--ready-fd=3",
+		"--exit-fd=4",
+		strconv.Itoa(holder.Process.Pid),
+		"tap0",
+	)
+	slirp.ExtraFiles = []*os.File{readyW, exitR}
+	slirp.Stderr = stderr
+	err = slirp.Start()
+	readyW.Close()
+	exitR.Close()
+	if err != nil {
+		ns.Close()
+		return nil, errors.Wrap(err, "failed to start slirp4netns")
+	}
+	ns.slirp = slirp
+
+	ready := make(chan error, 1)
+	go func() {
+		b := make([]byte, 1)
+		_, err := readyR.Read(b)
+		ready <- err
+	}()
+	select {
+	case err = <-ready:
+	case <-time.After(readyTimeout):
+		err = errors.Errorf("timed out after %s", readyTimeout)
+	}
+	if err != nil {
+		ns.Close()
+		return nil, errors.Errorf("slirp4netns failed to set up the network of the build step: %v: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return ns, nil
+}
+
+type slirpNS struct {
+	holder       *exec.Cmd
+	holderStdin  io.Closer
+	slirp        *exec.Cmd
+	exitW        *os.File
+	bypassSocket string
+}
+
+func (ns *slirpNS) Set(s *specs.Spec) error {
+	if err := oci.WithLinuxNamespace(specs.LinuxNamespace{
+		Type: specs.NetworkNamespace,
+		Path: fmt.Sprintf("/proc/%d/ns/net", ns.holder.Process.Pid),
+	})(nil, nil, nil, s); err != nil {
+		return err
+	}
+	if ns.bypassSocket != "" {
+		withBypass4netns(s, ns.bypassSocket)
+	}
+	return nil
+}
+
+func (ns *slirpNS) Close() error {
+	if ns.exitW != nil {
+		// slirp4netns exits when the exit fd is closed
+		ns.exitW.Close()
+	}
+	if ns.slirp != nil {
+		ns.slirp.Wait()
+	}
+	ns.holderStdin.Close()
+	if err := ns.holder.Wait(); err != nil {
+		return errors.Wrap(err, "failed to release network namespace")
+	}
+	return nil
+}
+
+// withBypass4netns makes the socket syscalls of the build step notify
+// bypass4netns, which replaces the sockets connecting outside of the
+// network namespace with sockets of the network of the daemon.
+func withBypass4netns(s *specs.Spec, socket string) {
+	if s.Linux == nil {
+		s.Linux = &specs.Linux{}
+	}
+	if s.Linux.Seccomp == nil {
+		s.Linux.Seccomp = &specs.LinuxSeccomp{DefaultAction: specs.ActAllow}
+	}
+	s.Linux.Seccomp.ListenerPath = socket
+	s.Linux.Seccomp.Syscalls = append(s.Linux.Seccomp.Syscalls, specs.LinuxSyscall{
+		Names:  bypass4netnsSyscalls,
+		Action: specs.ActNotify,
+	})
+}
+
+// bypass4netns is the bypass4netns daemon shared by the build steps. It is
+// started with the first network namespace and restarted if it exits.
+type bypass4netns struct {
+	binary string
+	socket string
+
+	mu  sync.Mutex
+	cmd *exec.Cmd
+	// done is closed when cmd exits
+	done chan struct{}
+}
+
+func (b *bypass4netns) listenerPath() string {
+	if b == nil {
+		return ""
+	}
+	return b.socket
+}
+
+func (b *bypass4netns) start() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.done != nil {
+		select {
+		case <-b.done:
+		default:
+			return nil
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(b.socket), 0700); err != nil {
+		return errors.WithStack(err)
+	}
+	os.Remove(b.socket)
+	stderr := &limitedBuffer{max: 4096}
+	cmd := exec.Command(b.binary, "--socket="+b.socket, "--ignore=127.0.0.0/8,10.0.2.0/24")
+	cmd.Stderr = stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Pdeathsig: unix.SIGTERM}
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "failed to start bypass4netns")
+	}
+	done := make(chan struct{})
+	go func() {
+		err := cmd.Wait()
+		bklog.L.Warnf("bypass4netns exited: %v: %s", err, bytes.TrimSpace(stderr.Bytes()))
+		close(done)
+	}()
+	// the build steps can't start before the socket is listening
+	for i := 0; i < 50; i++ {
+		if _, err := os.Stat(b.socket); err == nil {
+			b.cmd, b.done = cmd, done
+			return nil
+		}
+		select {
+		case <-done:
+			return errors.Errorf("bypass4netns exited: %s", bytes.TrimSpace(stderr.Bytes()))
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	cmd.Process.Kill()
+	return errors.Errorf("bypass4netns didn't create %s", b.socket)
+}
+
+// limitedBuffer keeps the first max bytes written to it.
+type limitedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if n := b.max - b.buf.Len(); n > 0 {
+		if len(p) < n {
+			n = len(p)
+		}
+		b.buf.Write(p[:n])
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Bytes()
+}
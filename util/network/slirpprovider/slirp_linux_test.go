@@ -0,0 +1,41 @@
+package slirpprovider
+
+import (
+	"testing"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithBypass4netns(t *testing.T) {
+	s := &specs.Spec{Linux: &specs.Linux{Seccomp: &specs.LinuxSeccomp{
+		DefaultAction: specs.ActErrno,
+		Syscalls:      []specs.LinuxSyscall{{Names: []string{"read"}, Action: specs.ActAllow}},
+	}}}
+	withBypass4netns(s, "/run/bypass4netns.sock")
+	require.Equal(t, specs.ActErrno, s.Linux.Seccomp.DefaultAction)
+	require.Equal(t, "/run/bypass4netns.sock", s.Linux.Seccomp.ListenerPath)
+	require.Len(t, s.Linux.Seccomp.Syscalls, 2)
+	require.Equal(t, specs.ActNotify, s.Linux.Seccomp.Syscalls[1].Action)
+
+	s = &specs.Spec{}
+	withBypass4netns(s, "/run/bypass4netns.sock")
+	require.Equal(t, specs.ActAllow, s.Linux.Seccomp.DefaultAction)
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	b := &limitedBuffer{max: 4}
+	n, err := b.Write([]byte("abc"))
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	n, err = b.Write([]byte("def"))
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Equal(t, "abcd", string(b.Bytes()))
+}
+
+func TestNewMissingBinary(t *testing.T) {
+	_, err := New(Opt{Binary: "slirp4netns-missing"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "install slirp4netns")
+}
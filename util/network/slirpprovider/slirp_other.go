@@ -0,0 +1,15 @@
+//go:build !linux
+// +build !linux
+
+package slirpprovider
+
+import (
+	"github.com/moby/buildkit/util/network"
+	"github.com/pkg/errors"
+)
+
+// New returns a provider of network namespaces connected to the network of
+// the daemon by slirp4netns. It is only supported on Linux.
+func New(opt Opt) (network.Provider, error) {
+	return nil, errors.New("the slirp4netns network mode is only supported on linux")
+}
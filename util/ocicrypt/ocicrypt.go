@@ -0,0 +1,254 @@
+// Package ocicrypt encrypts the layers of images in the format of ocicrypt,
+// so that the images can be decrypted by containerd imgcrypt. The layers are
+// encrypted with AES-256-CTR and authenticated with HMAC-SHA256, and their
+// keys are wrapped for each recipient in a JWE with RSA-OAEP.
+package ocicrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"strings"
+
+	"github.com/containerd/containerd/content"
+	"github.com/moby/buildkit/identity"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	// KeysAnnotation is the annotation of the encrypted layers holding the
+	// JWEs of their keys.
+	KeysAnnotation = "org.opencontainers.image.enc.keys.jwe"
+	// PubOptsAnnotation is the annotation of the encrypted layers holding the
+	// public options of their cipher.
+	PubOptsAnnotation = "org.opencontainers.image.enc.pubopts"
+
+	encryptedSuffix = "+encrypted"
+	cipherType      = "AES_256_CTR_HMAC_SHA256"
+	keySize         = 32
+)
+
+// Config holds the public keys of the recipients of the layers.
+type Config struct {
+	recipients []*rsa.PublicKey
+}
+
+// ParseRecipients returns the config encrypting for the recipients of the PEM
+// encoded public keys or certificates in keys.
+func ParseRecipients(keys ...[]byte) (*Config, error) {
+	c := &Config{}
+	for _, dt := range keys {
+		n := len(c.recipients)
+		for {
+			var b *pem.Block
+			b, dt = pem.Decode(dt)
+			if b == nil {
+				break
+			}
+			pub, err := parsePublicKey(b)
+			if err != nil {
+				return nil, err
+			}
+			c.recipients = append(c.recipients, pub)
+		}
+		if len(c.recipients) == n {
+			return nil, errors.New("no PEM encoded public key or certificate for recipient")
+		}
+	}
+	if len(c.recipients) == 0 {
+		return nil, errors.New("no recipients for layer encryption")
+	}
+	return c, nil
+}
+
+func parsePublicKey(b *pem.Block) (*rsa.PublicKey, error) {
+	var pub interface{}
+	switch b.Type {
+	case "PUBLIC KEY":
+		k, err := x509.ParsePKIXPublicKey(b.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse public key")
+		}
+		pub = k
+	case "RSA PUBLIC KEY":
+		k, err := x509.ParsePKCS1PublicKey(b.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse public key")
+		}
+		pub = k
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(b.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse certificate")
+		}
+		pub = cert.PublicKey
+	default:
+		return nil, errors.Errorf("unsupported PEM block %q for recipient", b.Type)
+	}
+	rsaPub, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.Errorf("unsupported recipient key type %T, only RSA keys are supported", pub)
+	}
+	return rsaPub, nil
+}
+
+// IsEncrypted returns true if mediaType is the media type of an encrypted
+// layer.
+func IsEncrypted(mediaType string) bool {
+	return strings.HasSuffix(mediaType, encryptedSuffix)
+}
+
+type privateOpts struct {
+	SymmetricKey  []byte            `json:"symkey"`
+	Digest        digest.Digest     `json:"digest"`
+	CipherOptions map[string][]byte `json:"cipheroptions"`
+}
+
+type publicOpts struct {
+	CipherType    string            `json:"cipher"`
+	Hmac          []byte            `json:"hmac"`
+	CipherOptions map[string][]byte `json:"cipheroptions"`
+}
+
+// EncryptLayer writes the layer of desc read from p encrypted to cs and
+// returns the descriptor of the encrypted layer.
+func (c *Config) EncryptLayer(ctx context.Context, cs content.Store, p content.Provider, desc ocispecs.Descriptor) (ocispecs.Descriptor, error) {
+	key := make([]byte, keySize)
+	nonce := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(key); err != nil {
+		return ocispecs.Descriptor{}, errors.WithStack(err)
+	}
+	if _, err := rand.Read(nonce); err != nil {
+		return ocispecs.Descriptor{}, errors.WithStack(err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return ocispecs.Descriptor{}, errors.WithStack(err)
+	}
+	stream := cipher.NewCTR(block, nonce)
+	mac := hmac.New(sha256.New, key)
+
+	ra, err := p.ReaderAt(ctx, desc)
+	if err != nil {
+		return ocispecs.Descriptor{}, err
+	}
+	defer ra.Close()
+	w, err := content.OpenWriter(ctx, cs, content.WithRef("encrypt-"+desc.Digest.String()+"-"+identity.NewID()))
+	if err != nil {
+		return ocispecs.Descriptor{}, err
+	}
+	defer w.Close()
+	size, err := io.Copy(io.MultiWriter(w, mac), &cipher.StreamReader{S: stream, R: content.NewReader(ra)})
+	if err != nil {
+		return ocispecs.Descriptor{}, errors.Wrapf(err, "failed to encrypt layer %s", desc.Digest)
+	}
+	dgst := w.Digest()
+	if err := w.Commit(ctx, size, dgst); err != nil {
+		return ocispecs.Descriptor{}, err
+	}
+
+	priv, err := json.Marshal(privateOpts{
+		SymmetricKey:  key,
+		Digest:        desc.Digest,
+		CipherOptions: map[string][]byte{"nonce": nonce},
+	})
+	if err != nil {
+		return ocispecs.Descriptor{}, errors.WithStack(err)
+	}
+	pub, err := json.Marshal(publicOpts{
+		CipherType:    cipherType,
+		Hmac:          mac.Sum(nil),
+		CipherOptions: map[string][]byte{},
+	})
+	if err != nil {
+		return ocispecs.Descriptor{}, errors.WithStack(err)
+	}
+	jwe, err := c.wrapKey(priv)
+	if err != nil {
+		return ocispecs.Descriptor{}, err
+	}
+
+	annotations := make(map[string]string, len(desc.Annotations)+2)
+	for k, v := range desc.Annotations {
+		annotations[k] = v
+	}
+	annotations[KeysAnnotation] = base64.StdEncoding.EncodeToString(jwe)
+	annotations[PubOptsAnnotation] = base64.StdEncoding.EncodeToString(pub)
+	return ocispecs.Descriptor{
+		MediaType:   desc.MediaType + encryptedSuffix,
+		Digest:      dgst,
+		Size:        size,
+		Annotations: annotations,
+	}, nil
+}
+
+type jweRecipient struct {
+	Header       map[string]string `json:"header"`
+	EncryptedKey string            `json:"encrypted_key"`
+}
+
+type jweJSON struct {
+	Protected  string         `json:"protected"`
+	Recipients []jweRecipient `json:"recipients"`
+	IV         string         `json:"iv"`
+	Ciphertext string         `json:"ciphertext"`
+	Tag        string         `json:"tag"`
+}
+
+// wrapKey returns the JWE in JSON serialization of the private options of a
+// layer, with its content key encrypted for each recipient with RSA-OAEP and
+// the options encrypted with A256GCM.
+func (c *Config) wrapKey(plaintext []byte) ([]byte, error) {
+	cek := make([]byte, keySize)
+	if _, err := rand.Read(cek); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	b64 := base64.RawURLEncoding
+	protected := b64.EncodeToString([]byte(`{"enc":"A256GCM"}`))
+	sealed := gcm.Seal(nil, iv, plaintext, []byte(protected))
+	tagStart := len(sealed) - gcm.Overhead()
+
+	jwe := jweJSON{
+		Protected:  protected,
+		IV:         b64.EncodeToString(iv),
+		Ciphertext: b64.EncodeToString(sealed[:tagStart]),
+		Tag:        b64.EncodeToString(sealed[tagStart:]),
+	}
+	for _, pub := range c.recipients {
+		ek, err := rsa.EncryptOAEP(sha1.New(), rand.Reader, pub, cek, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to wrap layer key")
+		}
+		jwe.Recipients = append(jwe.Recipients, jweRecipient{
+			Header:       map[string]string{"alg": "RSA-OAEP"},
+			EncryptedKey: b64.EncodeToString(ek),
+		})
+	}
+	dt, err := json.Marshal(jwe)
+	return dt, errors.WithStack(err)
+}
@@ -0,0 +1,141 @@
+package ocicrypt
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/content/local"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptLayer(t *testing.T) {
+	ctx := context.TODO()
+	cs, err := local.NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	layer := bytes.Repeat([]byte("layer data "), 10000)
+	desc := ocispecs.Descriptor{
+		MediaType:   ocispecs.MediaTypeImageLayerGzip,
+		Digest:      digest.FromBytes(layer),
+		Size:        int64(len(layer)),
+		Annotations: map[string]string{"containerd.io/uncompressed": "sha256:0000"},
+	}
+	require.NoError(t, content.WriteBlob(ctx, cs, "layer", bytes.NewReader(layer), desc))
+
+	keys := make([]*rsa.PrivateKey, 2)
+	var pems [][]byte
+	for i := range keys {
+		keys[i], err = rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		dt, err := x509.MarshalPKIXPublicKey(&keys[i].PublicKey)
+		require.NoError(t, err)
+		pems = append(pems, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: dt}))
+	}
+	c, err := ParseRecipients(pems...)
+	require.NoError(t, err)
+
+	enc, err := c.EncryptLayer(ctx, cs, cs, desc)
+	require.NoError(t, err)
+	require.Equal(t, "application/vnd.oci.image.layer.v1.tar+gzip+encrypted", enc.MediaType)
+	require.True(t, IsEncrypted(enc.MediaType))
+	require.Equal(t, "sha256:0000", enc.Annotations["containerd.io/uncompressed"])
+	require.Equal(t, int64(len(layer)), enc.Size)
+
+	ciphertext, err := content.ReadBlob(ctx, cs, enc)
+	require.NoError(t, err)
+	require.Equal(t, enc.Digest, digest.FromBytes(ciphertext))
+	require.NotEqual(t, layer, ciphertext)
+
+	dt, err := base64.StdEncoding.DecodeString(enc.Annotations[PubOptsAnnotation])
+	require.NoError(t, err)
+	var pub publicOpts
+	require.NoError(t, json.Unmarshal(dt, &pub))
+	require.Equal(t, cipherType, pub.CipherType)
+
+	for _, key := range keys {
+		priv := unwrapKey(t, enc.Annotations[KeysAnnotation], key)
+		require.Equal(t, desc.Digest, priv.Digest)
+
+		mac := hmac.New(sha256.New, priv.SymmetricKey)
+		mac.Write(ciphertext)
+		require.Equal(t, pub.Hmac, mac.Sum(nil))
+
+		block, err := aes.NewCipher(priv.SymmetricKey)
+		require.NoError(t, err)
+		plaintext := make([]byte, len(ciphertext))
+		cipher.NewCTR(block, priv.CipherOptions["nonce"]).XORKeyStream(plaintext, ciphertext)
+		require.Equal(t, layer, plaintext)
+	}
+}
+
+func TestParseRecipients(t *testing.T) {
+	_, err := ParseRecipients([]byte("not a key"))
+	require.Error(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	dt, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	require.NoError(t, err)
+	_, err = ParseRecipients(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: dt}))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "only RSA keys")
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	c, err := ParseRecipients(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)}))
+	require.NoError(t, err)
+	require.Len(t, c.recipients, 1)
+}
+
+func unwrapKey(t *testing.T, annotation string, key *rsa.PrivateKey) privateOpts {
+	dt, err := base64.StdEncoding.DecodeString(annotation)
+	require.NoError(t, err)
+	var jwe jweJSON
+	require.NoError(t, json.Unmarshal(dt, &jwe))
+
+	b64 := base64.RawURLEncoding
+	var cek []byte
+	for _, r := range jwe.Recipients {
+		require.Equal(t, "RSA-OAEP", r.Header["alg"])
+		ek, err := b64.DecodeString(r.EncryptedKey)
+		require.NoError(t, err)
+		if cek, err = rsa.DecryptOAEP(sha1.New(), nil, key, ek, nil); err == nil {
+			break
+		}
+	}
+	require.NotNil(t, cek)
+
+	block, err := aes.NewCipher(cek)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	iv, err := b64.DecodeString(jwe.IV)
+	require.NoError(t, err)
+	ciphertext, err := b64.DecodeString(jwe.Ciphertext)
+	require.NoError(t, err)
+	tag, err := b64.DecodeString(jwe.Tag)
+	require.NoError(t, err)
+	plaintext, err := gcm.Open(nil, iv, append(ciphertext, tag...), []byte(jwe.Protected))
+	require.NoError(t, err)
+
+	var priv privateOpts
+	require.NoError(t, json.Unmarshal(plaintext, &priv))
+	return priv
+}
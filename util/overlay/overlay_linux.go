@@ -156,17 +156,21 @@ func (w *cancellableWriter) Write(p []byte) (int, error) {
 // "upperdir" for computing the diff. "upperdirView" is overlayfs mounted view of
 // the upperdir that doesn't contain whiteouts. This is used for computing
 // changes under opaque directories.
+//
+// The directories of the upperdir are read in parallel and the entries under
+// a directory added in the upperdir are all reported as added without
+// looking them up in the base, as none of them can exist there.
 func Changes(ctx context.Context, changeFn fs.ChangeFunc, upperdir, upperdirView, base string) error {
-	return filepath.Walk(upperdir, func(path string, f os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+	// addedDir is the last directory added in the upperdir. As the walk is
+	// depth-first, the entries under it are walked right after it.
+	var addedDir string
+	return walk(ctx, upperdir, func(path string, f os.FileInfo) error {
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
 
 		// Rebase path
-		path, err = filepath.Rel(upperdir, path)
+		path, err := filepath.Rel(upperdir, path)
 		if err != nil {
 			return err
 		}
@@ -186,6 +190,16 @@ func Changes(ctx context.Context, changeFn fs.ChangeFunc, upperdir, upperdirView
 			return fmt.Errorf("redirect_dir is used but it's not supported in overlayfs differ")
 		}
 
+		if addedDir != "" && strings.HasPrefix(path, addedDir+string(os.PathSeparator)) {
+			// The parent directory doesn't exist in the base, so whiteouts have
+			// nothing to delete and directories can't be opaque.
+			if isWhiteout, err := checkWhiteout(f); err != nil || isWhiteout {
+				return err
+			}
+			return changeFn(fs.ChangeKindAdd, path, f, nil)
+		}
+		addedDir = ""
+
 		// Check if this is a deleted entry
 		isDelete, skip, err := checkDelete(upperdir, path, base, f)
 		if err != nil {
@@ -223,6 +237,9 @@ func Changes(ctx context.Context, changeFn fs.ChangeFunc, upperdir, upperdirView
 				return err
 			}
 		}
+		if kind == fs.ChangeKindAdd && f.IsDir() {
+			addedDir = path
+		}
 
 		if f != nil {
 			if isOpaque, err := checkOpaque(upperdir, path, base, f); err != nil {
@@ -246,27 +263,33 @@ func Changes(ctx context.Context, changeFn fs.ChangeFunc, upperdir, upperdirView
 
 // checkDelete checks if the specified file is a whiteout
 func checkDelete(upperdir string, path string, base string, f os.FileInfo) (delete, skip bool, _ error) {
+	if isWhiteout, err := checkWhiteout(f); err != nil || !isWhiteout {
+		return false, false, err
+	}
+	// This file is a whiteout (char 0/0) that indicates this is deleted from the base
+	if _, err := os.Lstat(filepath.Join(base, path)); err != nil {
+		if !os.IsNotExist(err) {
+			return false, false, errors.Wrapf(err, "failed to lstat")
+		}
+		// This file doesn't exist even in the base dir.
+		// We don't need whiteout. Just skip this file.
+		return false, true, nil
+	}
+	return true, false, nil
+}
+
+// checkWhiteout checks if the specified file is a whiteout device (char 0/0)
+func checkWhiteout(f os.FileInfo) (bool, error) {
 	if f.Mode()&os.ModeCharDevice != 0 {
 		if _, ok := f.Sys().(*syscall.Stat_t); ok {
 			maj, min, err := devices.DeviceInfo(f)
 			if err != nil {
-				return false, false, errors.Wrapf(err, "failed to get device info")
-			}
-			if maj == 0 && min == 0 {
-				// This file is a whiteout (char 0/0) that indicates this is deleted from the base
-				if _, err := os.Lstat(filepath.Join(base, path)); err != nil {
-					if !os.IsNotExist(err) {
-						return false, false, errors.Wrapf(err, "failed to lstat")
-					}
-					// This file doesn't exist even in the base dir.
-					// We don't need whiteout. Just skip this file.
-					return false, true, nil
-				}
-				return true, false, nil
+				return false, errors.Wrapf(err, "failed to get device info")
 			}
+			return maj == 0 && min == 0, nil
 		}
 	}
-	return false, false, nil
+	return false, nil
 }
 
 // checkDelete checks if the specified file is an opaque directory
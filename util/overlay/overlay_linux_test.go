@@ -208,6 +208,77 @@ func TestRemoveDirectoryTreeWithDash(t *testing.T) {
 	}
 }
 
+func TestAddedDirectoryTree(t *testing.T) {
+	l1 := fstest.Apply(
+		fstest.CreateDir("/dir1-after", 0755),
+		fstest.CreateFile("/dir1-after/f1", []byte("f1"), 0644),
+	)
+	l2 := fstest.Apply(
+		fstest.CreateDir("/dir1/dir2/dir3", 0755),
+		fstest.CreateFile("/dir1/f1", []byte("f1"), 0644),
+		fstest.CreateFile("/dir1/dir2/dir3/f3", []byte("f3"), 0644),
+		fstest.CreateFile("/dir1-after/f1", []byte("f1-modified"), 0644),
+	)
+	diff := []TestChange{
+		Add("/dir1"),
+		Add("/dir1/dir2"),
+		Add("/dir1/dir2/dir3"),
+		Add("/dir1/dir2/dir3/f3"),
+		Add("/dir1/f1"),
+		Modify("/dir1-after/f1"),
+	}
+
+	if err := testDiffWithBase(l1, l2, diff); err != nil {
+		t.Fatalf("Failed diff with base: %+v", err)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	root, err := ioutil.TempDir("", "walk-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	if err := fstest.Apply(
+		fstest.CreateDir("/a/b/c", 0755),
+		fstest.CreateFile("/a/b/c/f", []byte("f"), 0644),
+		fstest.CreateFile("/a/f", []byte("f"), 0644),
+		fstest.CreateDir("/a-b", 0755),
+		fstest.CreateFile("/a-b/f", []byte("f"), 0644),
+		fstest.CreateDir("/skip/d", 0755),
+		fstest.CreateFile("/skip/f", []byte("f"), 0644),
+		fstest.Symlink("a", "/z"),
+	).Apply(root); err != nil {
+		t.Fatal(err)
+	}
+
+	var expected, walked []string
+	if err := filepath.Walk(root, func(path string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		expected = append(expected, path)
+		if f.Name() == "skip" {
+			return filepath.SkipDir
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := walk(context.TODO(), root, func(path string, f os.FileInfo) error {
+		walked = append(walked, path)
+		if f.Name() == "skip" {
+			return filepath.SkipDir
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(walked, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("walked %v, expected %v", walked, expected)
+	}
+}
+
 // TestFileReplace is a test ported from
 // https://github.com/containerd/continuity/blob/v0.1.0/fs/diff_test.go#L174-L192
 // Copyright The containerd Authors.
@@ -0,0 +1,126 @@
+//go:build linux
+// +build linux
+
+package overlay
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// walkConcurrency is the number of directories read in parallel while
+// walking the upperdir.
+const walkConcurrency = 32
+
+type walkFunc func(path string, f os.FileInfo) error
+
+// dirListing is the entries of a directory, sorted by name and read in the
+// background.
+type dirListing struct {
+	done    chan struct{}
+	entries []os.FileInfo
+	err     error
+}
+
+type walker struct {
+	ctx context.Context
+	sem chan struct{}
+}
+
+// walk walks the tree of root like filepath.Walk, calling fn in lexical
+// order, but reads the subdirectories of each directory it enters in
+// parallel so that the walk doesn't wait on reading the directories one by
+// one.
+func walk(ctx context.Context, root string, fn walkFunc) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // stop the readers of the directories not walked
+
+	info, err := os.Lstat(root)
+	if err != nil {
+		return err
+	}
+	if err := fn(root, info); err != nil {
+		if err == filepath.SkipDir {
+			return nil
+		}
+		return err
+	}
+	if !info.IsDir() {
+		return nil
+	}
+	w := &walker{ctx: ctx, sem: make(chan struct{}, walkConcurrency)}
+	return w.walkDir(root, w.list(root), fn)
+}
+
+func (w *walker) walkDir(dir string, l *dirListing, fn walkFunc) error {
+	<-l.done
+	if l.err != nil {
+		return l.err
+	}
+	subdirs := make([]*dirListing, len(l.entries))
+	for i, f := range l.entries {
+		if f.IsDir() {
+			subdirs[i] = w.list(filepath.Join(dir, f.Name()))
+		}
+	}
+	for i, f := range l.entries {
+		path := filepath.Join(dir, f.Name())
+		if err := fn(path, f); err != nil {
+			if err != filepath.SkipDir {
+				return err
+			}
+			if f.IsDir() {
+				continue
+			}
+			// like filepath.Walk, skipping a file skips the rest of its directory
+			return nil
+		}
+		if subdirs[i] != nil {
+			if err := w.walkDir(path, subdirs[i], fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// list reads the directory in the background.
+func (w *walker) list(dir string) *dirListing {
+	l := &dirListing{done: make(chan struct{})}
+	go func() {
+		defer close(l.done)
+		select {
+		case w.sem <- struct{}{}:
+		case <-w.ctx.Done():
+			l.err = w.ctx.Err()
+			return
+		}
+		defer func() { <-w.sem }()
+		l.entries, l.err = readDir(dir)
+	}()
+	return l
+}
+
+func readDir(dir string) ([]os.FileInfo, error) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	names, err := d.Readdirnames(-1)
+	d.Close()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	entries := make([]os.FileInfo, 0, len(names))
+	for _, name := range names {
+		f, err := os.Lstat(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, f)
+	}
+	return entries, nil
+}
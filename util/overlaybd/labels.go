@@ -0,0 +1,51 @@
+package overlaybd
+
+import (
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// BlobDigestAnnotation is the annotation of the layers of overlaybd images
+	// holding the digest of their overlaybd blob.
+	BlobDigestAnnotation = "containerd.io/snapshot/overlaybd/blob-digest"
+	// BlobSizeAnnotation is the annotation of the layers of overlaybd images
+	// holding the size of their overlaybd blob.
+	BlobSizeAnnotation = "containerd.io/snapshot/overlaybd/blob-size"
+	// BlobFsTypeAnnotation is the annotation of the layers of overlaybd images
+	// holding the filesystem of their block device.
+	BlobFsTypeAnnotation = "containerd.io/snapshot/overlaybd/blob-fs-type"
+
+	imageRefLabel = "containerd.io/snapshot/image-ref"
+)
+
+// Annotations are the annotations of the layers of overlaybd images.
+var Annotations = []string{BlobDigestAnnotation, BlobSizeAnnotation, BlobFsTypeAnnotation}
+
+// IsLayer returns true if desc is a layer of an overlaybd image, which the
+// overlaybd snapshotter mounts as a remote block device.
+func IsLayer(desc ocispecs.Descriptor) bool {
+	_, ok := desc.Annotations[BlobDigestAnnotation]
+	return ok
+}
+
+// SnapshotLabels returns the labels the overlaybd snapshotter needs to
+// prepare the layer of descs at targetIndex of the image ref as a remote
+// snapshot, or nil if the layer isn't an overlaybd layer.
+func SnapshotLabels(ref string, descs []ocispecs.Descriptor, targetIndex int) map[string]string {
+	if len(descs) <= targetIndex {
+		return nil
+	}
+	desc := descs[targetIndex]
+	if !IsLayer(desc) {
+		return nil
+	}
+	labels := map[string]string{
+		imageRefLabel: ref,
+	}
+	for _, k := range Annotations {
+		if v, ok := desc.Annotations[k]; ok {
+			labels[k] = v
+		}
+	}
+	return labels
+}
@@ -72,6 +72,7 @@ func DisplaySolveStatus(ctx context.Context, phase string, c console.Console, w
 			if done {
 				disp.print(t.displayInfo(), width, height, true)
 				t.printErrorLogs(c)
+				t.printSummary(c)
 				return t.warnings(), nil
 			} else if displayLimiter.Allow() {
 				ticker.Stop()
@@ -83,6 +84,7 @@ func DisplaySolveStatus(ctx context.Context, phase string, c console.Console, w
 				printer.print(t)
 				if done {
 					t.printErrorLogs(w)
+					t.printSummary(w)
 					return t.warnings(), nil
 				}
 				ticker.Stop()
@@ -597,7 +599,6 @@ func (t *trace) displayInfo() (d displayInfo) {
 
 	for _, v := range t.vertexes {
 		if v.jobCached {
-			d.jobs = append(d.jobs, v.jobs...)
 			continue
 		}
 		var jobs []*job
@@ -663,10 +664,10 @@ func (t *trace) displayInfo() (d displayInfo) {
 			}
 			jobs = append(jobs, j)
 		}
-		d.jobs = append(d.jobs, jobs...)
 		v.jobs = jobs
 		v.jobCached = true
 	}
+	d.jobs = stageJobs(t, t.vertexes)
 
 	return d
 }
@@ -0,0 +1,139 @@
+package progressui
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/moby/buildkit/client"
+	digest "github.com/opencontainers/go-digest"
+)
+
+const (
+	JSONEventVertex  = "vertex"
+	JSONEventStatus  = "status"
+	JSONEventLog     = "log"
+	JSONEventWarning = "warning"
+)
+
+// JSONEvent is a line of the JSON progress output. Type is one of the
+// JSONEvent* constants and defines which of the fields are set.
+type JSONEvent struct {
+	Type   string        `json:"type"`
+	Vertex digest.Digest `json:"vertex"`
+
+	// vertex
+	Name      string          `json:"name,omitempty"`
+	Inputs    []digest.Digest `json:"inputs,omitempty"`
+	Started   *time.Time      `json:"started,omitempty"`
+	Completed *time.Time      `json:"completed,omitempty"`
+	Duration  float64         `json:"duration,omitempty"`
+	Cached    bool            `json:"cached,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	// CacheMiss explains why the vertex was executed.
+	CacheMiss *client.CacheMiss `json:"cacheMiss,omitempty"`
+
+	// status
+	ID      string `json:"id,omitempty"`
+	Current int64  `json:"current,omitempty"`
+	Total   int64  `json:"total,omitempty"`
+
+	// log
+	Stream int    `json:"stream,omitempty"`
+	Data   string `json:"data,omitempty"`
+
+	// warning
+	Level  int      `json:"level,omitempty"`
+	Short  string   `json:"short,omitempty"`
+	Detail []string `json:"detail,omitempty"`
+	URL    string   `json:"url,omitempty"`
+
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
+
+// DisplaySolveStatusJSON writes the progress of the build to w as newline
+// delimited JSON events, one event per vertex, status, log chunk and warning
+// update.
+func DisplaySolveStatusJSON(ctx context.Context, w io.Writer, ch chan *client.SolveStatus) ([]client.VertexWarning, error) {
+	enc := json.NewEncoder(w)
+	var warnings []client.VertexWarning
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case ss, ok := <-ch:
+			if !ok {
+				return warnings, nil
+			}
+			for _, ev := range jsonEvents(ss) {
+				if err := enc.Encode(ev); err != nil {
+					return nil, err
+				}
+			}
+			for _, w := range ss.Warnings {
+				warnings = append(warnings, *w)
+			}
+		}
+	}
+}
+
+func jsonEvents(ss *client.SolveStatus) []*JSONEvent {
+	events := make([]*JSONEvent, 0, len(ss.Vertexes)+len(ss.Statuses)+len(ss.Logs)+len(ss.Warnings))
+	for _, v := range ss.Vertexes {
+		ev := &JSONEvent{
+			Type:      JSONEventVertex,
+			Vertex:    v.Digest,
+			Name:      v.Name,
+			Inputs:    v.Inputs,
+			Started:   v.Started,
+			Completed: v.Completed,
+			Cached:    v.Cached,
+			Error:     v.Error,
+			CacheMiss: v.CacheMiss,
+		}
+		if v.Started != nil && v.Completed != nil {
+			ev.Duration = v.Completed.Sub(*v.Started).Seconds()
+		}
+		events = append(events, ev)
+	}
+	for _, s := range ss.Statuses {
+		ts := s.Timestamp
+		events = append(events, &JSONEvent{
+			Type:      JSONEventStatus,
+			Vertex:    s.Vertex,
+			ID:        s.ID,
+			Name:      s.Name,
+			Current:   s.Current,
+			Total:     s.Total,
+			Started:   s.Started,
+			Completed: s.Completed,
+			Timestamp: &ts,
+		})
+	}
+	for _, l := range ss.Logs {
+		ts := l.Timestamp
+		events = append(events, &JSONEvent{
+			Type:      JSONEventLog,
+			Vertex:    l.Vertex,
+			Stream:    l.Stream,
+			Data:      string(l.Data),
+			Timestamp: &ts,
+		})
+	}
+	for _, w := range ss.Warnings {
+		detail := make([]string, 0, len(w.Detail))
+		for _, d := range w.Detail {
+			detail = append(detail, string(d))
+		}
+		events = append(events, &JSONEvent{
+			Type:   JSONEventWarning,
+			Vertex: w.Vertex,
+			Level:  w.Level,
+			Short:  string(w.Short),
+			Detail: detail,
+			URL:    w.URL,
+		})
+	}
+	return events
+}
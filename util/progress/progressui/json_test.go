@@ -0,0 +1,63 @@
+package progressui
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/client"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisplaySolveStatusJSON(t *testing.T) {
+	dgst := digest.FromString("vtx")
+	started := time.Unix(100, 0).UTC()
+	completed := started.Add(1500 * time.Millisecond)
+
+	ch := make(chan *client.SolveStatus, 2)
+	ch <- &client.SolveStatus{
+		Vertexes: []*client.Vertex{{Digest: dgst, Name: "RUN make", Started: &started, CacheMiss: &client.CacheMiss{Reason: client.CacheMissInput, Input: 0, Message: "input 0 was rebuilt"}}},
+		Logs:     []*client.VertexLog{{Vertex: dgst, Stream: 1, Data: []byte("hello\n"), Timestamp: started}},
+	}
+	ch <- &client.SolveStatus{
+		Vertexes: []*client.Vertex{{Digest: dgst, Name: "RUN make", Started: &started, Completed: &completed, Cached: true}},
+		Warnings: []*client.VertexWarning{{Vertex: dgst, Level: 1, Short: []byte("deprecated")}},
+	}
+	close(ch)
+
+	buf := &bytes.Buffer{}
+	warnings, err := DisplaySolveStatusJSON(context.TODO(), buf, ch)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+
+	var events []JSONEvent
+	s := bufio.NewScanner(buf)
+	for s.Scan() {
+		var ev JSONEvent
+		require.NoError(t, json.Unmarshal(s.Bytes(), &ev))
+		events = append(events, ev)
+	}
+	require.Len(t, events, 4)
+
+	require.Equal(t, JSONEventVertex, events[0].Type)
+	require.Equal(t, dgst, events[0].Vertex)
+	require.Equal(t, "RUN make", events[0].Name)
+	require.Nil(t, events[0].Completed)
+	require.Equal(t, &client.CacheMiss{Reason: client.CacheMissInput, Input: 0, Message: "input 0 was rebuilt"}, events[0].CacheMiss)
+
+	require.Equal(t, JSONEventLog, events[1].Type)
+	require.Equal(t, "hello\n", events[1].Data)
+	require.Equal(t, 1, events[1].Stream)
+
+	require.Equal(t, JSONEventVertex, events[2].Type)
+	require.True(t, events[2].Cached)
+	require.Nil(t, events[2].CacheMiss)
+	require.Equal(t, 1.5, events[2].Duration)
+
+	require.Equal(t, JSONEventWarning, events[3].Type)
+	require.Equal(t, "deprecated", events[3].Short)
+}
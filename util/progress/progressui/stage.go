@@ -0,0 +1,190 @@
+package progressui
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tonistiigi/units"
+)
+
+// stagePrefix matches the prefix the Dockerfile frontend adds to the names
+// of the vertexes of a stage, e.g. "[linux/amd64 builder 2/5] RUN make".
+var stagePrefix = regexp.MustCompile(`^\[(.*?)\s*\d+/\d+\] `)
+
+const (
+	defaultStage = "default"
+	otherStage   = "other"
+)
+
+// stageName returns the stage of a vertex, including the platform prefix of
+// multi-platform builds. An empty string is returned if the vertex is not
+// part of a stage.
+func stageName(name string) string {
+	m := stagePrefix.FindStringSubmatch(name)
+	if m == nil {
+		return ""
+	}
+	if m[1] == "" {
+		return defaultStage
+	}
+	return m[1]
+}
+
+type stage struct {
+	name     string
+	vertexes []*vertex
+	jobs     []*job
+}
+
+// stageJobs groups the jobs of the vertexes of a stage under a header job.
+// The jobs of a stage that completed successfully are collapsed into the
+// header. Jobs of vertexes that are not part of a stage are kept in place.
+func stageJobs(t *trace, vertexes []*vertex) []*job {
+	var sections []*stage
+	byName := map[string]*stage{}
+	for _, v := range vertexes {
+		name := stageName(v.Name)
+		if name == "" {
+			sections = append(sections, &stage{jobs: v.jobs})
+			continue
+		}
+		st, ok := byName[name]
+		if !ok {
+			st = &stage{name: name}
+			byName[name] = st
+			sections = append(sections, st)
+		}
+		st.vertexes = append(st.vertexes, v)
+		st.jobs = append(st.jobs, v.jobs...)
+	}
+
+	var jobs []*job
+	for _, st := range sections {
+		if st.name == "" {
+			jobs = append(jobs, st.jobs...)
+			continue
+		}
+		header := &job{
+			name:        "STAGE " + st.name,
+			isCompleted: true,
+		}
+		var cached int
+		for _, v := range st.vertexes {
+			if v.Cached {
+				cached++
+			}
+			for _, ival := range v.intervals {
+				header.intervals = append(header.intervals, interval{
+					start: addTime(ival.start, t.localTimeDiff),
+					stop:  addTime(ival.stop, t.localTimeDiff),
+				})
+			}
+		}
+		header.intervals = mergeIntervals(header.intervals)
+		for _, j := range st.jobs {
+			if !j.isCompleted {
+				header.isCompleted = false
+			}
+			if j.hasError {
+				header.hasError = true
+			}
+		}
+		header.status = fmt.Sprintf("%d/%d cached", cached, len(st.vertexes))
+		jobs = append(jobs, header)
+		if header.isCompleted && !header.hasError {
+			continue
+		}
+		for _, j := range st.jobs {
+			cj := *j
+			cj.name = "  " + j.name
+			jobs = append(jobs, &cj)
+		}
+	}
+	return jobs
+}
+
+type stageSummary struct {
+	name      string
+	intervals []interval
+	vertexes  int
+	cached    int
+	pulled    int64
+	pushed    int64
+}
+
+func (s *stageSummary) duration() time.Duration {
+	var d time.Duration
+	for _, ival := range mergeIntervals(s.intervals) {
+		d += ival.duration()
+	}
+	return d
+}
+
+// stageSummaries returns the summaries of the stages of the build in the
+// order they were started. Vertexes that are not part of a stage, like
+// exporters, are summarized as a separate "other" stage. Nil is returned if
+// the build has no stages.
+func (t *trace) stageSummaries() []*stageSummary {
+	var summaries []*stageSummary
+	byName := map[string]*stageSummary{}
+	var hasStages bool
+	for _, v := range t.vertexes {
+		name := stageName(v.Name)
+		if name == "" {
+			name = otherStage
+		} else {
+			hasStages = true
+		}
+		s, ok := byName[name]
+		if !ok {
+			s = &stageSummary{name: name}
+			byName[name] = s
+			summaries = append(summaries, s)
+		}
+		s.vertexes++
+		if v.Cached {
+			s.cached++
+		}
+		for _, ival := range v.intervals {
+			s.intervals = append(s.intervals, ival)
+		}
+		for _, st := range v.statuses {
+			switch {
+			case strings.HasPrefix(st.ID, "pushing"):
+				s.pushed += st.Current
+			case st.Total != 0:
+				s.pulled += st.Current
+			}
+		}
+	}
+	if !hasStages {
+		return nil
+	}
+	return summaries
+}
+
+// printSummary prints a table with the duration, ratio of cached steps and
+// transferred bytes of every stage of the build.
+func (t *trace) printSummary(w io.Writer) {
+	summaries := t.stageSummaries()
+	if len(summaries) == 0 {
+		return
+	}
+	tw := tabwriter.NewWriter(w, 1, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "STAGE\tDURATION\tSTEPS\tCACHED\tPULLED\tPUSHED")
+	for _, s := range summaries {
+		fmt.Fprintf(tw, "%s\t%.1fs\t%d\t%d%%\t%.2f\t%.2f\n",
+			s.name,
+			s.duration().Seconds(),
+			s.vertexes,
+			s.cached*100/s.vertexes,
+			units.Bytes(s.pulled),
+			units.Bytes(s.pushed),
+		)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,86 @@
+package progressui
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/client"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStageName(t *testing.T) {
+	for name, stage := range map[string]string{
+		"[builder 2/5] RUN make":                     "builder",
+		"[builder  2/10] RUN make":                   "builder",
+		"[2/3] COPY . .":                             defaultStage,
+		"[linux/arm64 builder 1/2] FROM alpine":      "linux/arm64 builder",
+		"[linux/amd64->arm64 stage-1 3/4] RUN make":  "linux/amd64->arm64 stage-1",
+		"[internal] load build definition":           "",
+		"exporting to image":                         "",
+		"[builder 2/5] RUN echo [foo 1/2] something": "builder",
+	} {
+		require.Equal(t, stage, stageName(name), name)
+	}
+}
+
+func TestStageJobs(t *testing.T) {
+	tr := newTrace(&bytes.Buffer{}, false)
+
+	now := time.Now()
+	later := now.Add(time.Second)
+	vtx := func(name string, completed *time.Time, cached bool) *client.Vertex {
+		return &client.Vertex{
+			Digest:    digest.FromString(name),
+			Name:      name,
+			Started:   &now,
+			Completed: completed,
+			Cached:    cached,
+		}
+	}
+	tr.update(&client.SolveStatus{
+		Vertexes: []*client.Vertex{
+			vtx("[internal] load build definition", &later, false),
+			vtx("[base 1/2] FROM alpine", &later, true),
+			vtx("[base 2/2] RUN apk add git", &later, false),
+			vtx("[build 1/2] COPY . .", &later, true),
+			vtx("[build 2/2] RUN make", nil, false),
+		},
+		Statuses: []*client.VertexStatus{{
+			ID:        "sha256:layer",
+			Vertex:    digest.FromString("[base 1/2] FROM alpine"),
+			Current:   100,
+			Total:     100,
+			Started:   &now,
+			Completed: &later,
+		}},
+	}, 80)
+
+	var names []string
+	for _, j := range tr.displayInfo().jobs {
+		names = append(names, j.name)
+	}
+	require.Equal(t, []string{
+		"[internal] load build definition",
+		"STAGE base",
+		"STAGE build",
+		"  CACHED [build 1/2] COPY . .",
+		"  [build 2/2] RUN make",
+	}, names)
+
+	summaries := tr.stageSummaries()
+	require.Len(t, summaries, 3)
+	require.Equal(t, otherStage, summaries[0].name)
+	require.Equal(t, "base", summaries[1].name)
+	require.Equal(t, 2, summaries[1].vertexes)
+	require.Equal(t, 1, summaries[1].cached)
+	require.Equal(t, int64(100), summaries[1].pulled)
+	require.Equal(t, time.Second, summaries[1].duration())
+	require.Equal(t, "build", summaries[2].name)
+
+	buf := &bytes.Buffer{}
+	tr.printSummary(buf)
+	require.Contains(t, buf.String(), "STAGE")
+	require.Contains(t, buf.String(), "base")
+}
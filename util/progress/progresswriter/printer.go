@@ -80,14 +80,18 @@ func NewPrinter(ctx context.Context, out console.File, mode string) (Writer, err
 				return nil, errors.Wrap(err, "failed to get console")
 			}
 		}
-	case "plain":
+	case "plain", "json":
 	default:
 		return nil, errors.Errorf("invalid progress mode %s", mode)
 	}
 
 	go func() {
 		// not using shared context to not disrupt display but let is finish reporting errors
-		_, pw.err = progressui.DisplaySolveStatus(ctx, "", c, out, statusCh)
+		if mode == "json" {
+			_, pw.err = progressui.DisplaySolveStatusJSON(ctx, out, statusCh)
+		} else {
+			_, pw.err = progressui.DisplaySolveStatus(ctx, "", c, out, statusCh)
+		}
 		close(doneCh)
 	}()
 	return pw, nil
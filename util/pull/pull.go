@@ -27,9 +27,11 @@ import (
 
 type Puller struct {
 	ContentStore content.Store
-	Resolver     *resolver.Resolver
-	Src          reference.Spec
-	Platform     ocispecs.Platform
+	// Resolver is a registry resolver or a SessionResolver for images that
+	// are not pulled from a registry.
+	Resolver remotes.Resolver
+	Src      reference.Spec
+	Platform ocispecs.Platform
 
 	g           flightcontrol.Group
 	resolveErr  error
@@ -43,6 +45,25 @@ type Puller struct {
 
 var _ content.Provider = &provider{}
 
+// SessionResolver is a resolver that is not backed by a registry. Its images
+// get no distribution source labels.
+type SessionResolver interface {
+	remotes.Resolver
+	// WithSession returns the resolver for fetching lazy blobs with the
+	// session group.
+	WithSession(session.Group) remotes.Resolver
+}
+
+func withSession(r remotes.Resolver, g session.Group) remotes.Resolver {
+	switch r := r.(type) {
+	case *resolver.Resolver:
+		return r.WithSession(g)
+	case SessionResolver:
+		return r.WithSession(g)
+	}
+	return r
+}
+
 type PulledManifests struct {
 	Ref              string
 	MainManifestDesc ocispecs.Descriptor
@@ -143,16 +164,18 @@ func (p *Puller) PullManifests(ctx context.Context) (*PulledManifests, error) {
 		// Limit manifests pulled to the best match in an index
 		childrenHandler = images.LimitManifests(childrenHandler, platform, 1)
 
-		dslHandler, err := docker.AppendDistributionSourceLabel(p.ContentStore, p.ref)
-		if err != nil {
-			return nil, err
-		}
 		handlers = append(handlers,
 			filterLayerBlobs(metadata, &mu),
 			retryhandler.New(limited.FetchHandler(p.ContentStore, fetcher, p.ref), logs.LoggerFromContext(ctx)),
 			childrenHandler,
-			dslHandler,
 		)
+		if _, ok := p.Resolver.(SessionResolver); !ok {
+			dslHandler, err := docker.AppendDistributionSourceLabel(p.ContentStore, p.ref)
+			if err != nil {
+				return nil, err
+			}
+			handlers = append(handlers, dslHandler)
+		}
 	}
 
 	if err := images.Dispatch(ctx, images.Handlers(handlers...), nil, p.desc); err != nil {
@@ -196,7 +219,7 @@ func (p *Puller) PullManifests(ctx context.Context) (*PulledManifests, error) {
 		Nonlayers:        p.nonlayers,
 		Descriptors:      p.layers,
 		Provider: func(g session.Group) content.Provider {
-			return &provider{puller: p, resolver: p.Resolver.WithSession(g)}
+			return &provider{puller: p, resolver: withSession(p.Resolver, g)}
 		},
 	}, nil
 }
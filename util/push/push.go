@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/containerd/containerd/content"
@@ -18,6 +19,7 @@ import (
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/util/flightcontrol"
 	"github.com/moby/buildkit/util/imageutil"
+	"github.com/moby/buildkit/util/metrics"
 	"github.com/moby/buildkit/util/progress"
 	"github.com/moby/buildkit/util/progress/logs"
 	"github.com/moby/buildkit/util/resolver"
@@ -46,6 +48,7 @@ func Pusher(ctx context.Context, resolver remotes.Resolver, ref string) (remotes
 }
 
 func Push(ctx context.Context, sm *session.Manager, sid string, provider content.Provider, manager content.Manager, dgst digest.Digest, ref string, insecure bool, hosts docker.RegistryHosts, byDigest bool, annotations map[digest.Digest]map[string]string) error {
+	start := time.Now()
 	desc := ocispecs.Descriptor{
 		Digest: dgst,
 	}
@@ -104,7 +107,8 @@ func Push(ctx context.Context, sm *session.Manager, sid string, provider content
 		}
 	})
 
-	pushHandler := retryhandler.New(limited.PushHandler(pusher, provider, ref), logs.LoggerFromContext(ctx))
+	var pushedBytes int64
+	pushHandler := countPushedBytes(retryhandler.New(limited.PushHandler(pusher, provider, ref), logs.LoggerFromContext(ctx)), &pushedBytes)
 	pushUpdateSourceHandler, err := updateDistributionSourceHandler(manager, pushHandler, ref)
 	if err != nil {
 		return err
@@ -126,7 +130,7 @@ func Push(ctx context.Context, sm *session.Manager, sid string, provider content
 		return err
 	}
 
-	layersDone := oneOffProgress(ctx, "pushing layers")
+	layersDone := bytesProgress(ctx, "pushing layers", &pushedBytes)
 	err = images.Dispatch(ctx, skipNonDistributableBlobs(images.Handlers(handlers...)), nil, ocispecs.Descriptor{
 		Digest:    dgst,
 		Size:      ra.Size(),
@@ -142,7 +146,22 @@ func Push(ctx context.Context, sm *session.Manager, sid string, provider content
 			return mfstDone(err)
 		}
 	}
-	return mfstDone(nil)
+	if err := mfstDone(nil); err != nil {
+		return err
+	}
+	metrics.PushDuration.Observe(time.Since(start).Seconds())
+	return nil
+}
+
+func countPushedBytes(f images.HandlerFunc, pushed *int64) images.HandlerFunc {
+	return func(ctx context.Context, desc ocispecs.Descriptor) ([]ocispecs.Descriptor, error) {
+		children, err := f(ctx, desc)
+		if err == nil {
+			metrics.PushedBytes.Add(float64(desc.Size))
+			atomic.AddInt64(pushed, desc.Size)
+		}
+		return children, err
+	}
 }
 
 // TODO: the containerd function for this is filtering too much, that needs to be fixed.
@@ -229,6 +248,25 @@ func oneOffProgress(ctx context.Context, id string) func(err error) error {
 	}
 }
 
+// bytesProgress is like oneOffProgress but reports the number of bytes
+// counted in n when the progress is completed.
+func bytesProgress(ctx context.Context, id string, n *int64) func(err error) error {
+	pw, _, _ := progress.NewFromContext(ctx)
+	now := time.Now()
+	st := progress.Status{
+		Started: &now,
+	}
+	pw.Write(id, st)
+	return func(err error) error {
+		now := time.Now()
+		st.Completed = &now
+		st.Current = int(atomic.LoadInt64(n))
+		pw.Write(id, st)
+		pw.Close()
+		return err
+	}
+}
+
 func childrenHandler(provider content.Provider) images.HandlerFunc {
 	return func(ctx context.Context, desc ocispecs.Descriptor) ([]ocispecs.Descriptor, error) {
 		var descs []ocispecs.Descriptor
@@ -0,0 +1,187 @@
+// Package rbac authorizes the requests to the daemon with the roles of the
+// client certificates of their connections.
+package rbac
+
+import (
+	"context"
+	"strings"
+
+	"github.com/moby/buildkit/util/tenant"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Role is a set of methods of the daemon API.
+type Role string
+
+const (
+	// RoleBuild allows running builds, following their progress and
+	// canceling them.
+	RoleBuild Role = "build"
+	// RolePrune allows pruning the build cache and the build history.
+	RolePrune Role = "prune"
+	// RoleAdmin allows all the methods, including the ones changing the
+	// configuration of the daemon.
+	RoleAdmin Role = "admin"
+
+	// roleAny is the role of the methods allowed to all authorized clients.
+	roleAny Role = ""
+)
+
+const (
+	controlService = "/moby.buildkit.v1.Control/"
+	gatewayService = "/moby.buildkit.v1.frontend.LLBBridge/"
+	traceService   = "/opentelemetry.proto.collector.trace.v1.TraceService/"
+	healthService  = "/grpc.health.v1.Health/"
+)
+
+var controlRoles = map[string]Role{
+	"DiskUsage":          roleAny,
+	"ListWorkers":        roleAny,
+	"ListEmulators":      roleAny,
+	"ListBuildHistory":   roleAny,
+	"ListGCRuns":         roleAny,
+	"GetParallelism":     roleAny,
+	"Solve":              RoleBuild,
+	"Attach":             RoleBuild,
+	"Cancel":             RoleBuild,
+	"Status":             RoleBuild,
+	"Session":            RoleBuild,
+	"BuildHistoryLogs":   RoleBuild,
+	"Prune":              RolePrune,
+	"RunGC":              RolePrune,
+	"DeleteBuildHistory": RolePrune,
+	"SetParallelism":     RoleAdmin,
+	"ReloadConfig":       RoleAdmin,
+	"RootlessInfo":       roleAny,
+}
+
+// MethodRole returns the role required to call the method. Unknown methods
+// require the admin role.
+func MethodRole(fullMethod string) Role {
+	switch {
+	case strings.HasPrefix(fullMethod, controlService):
+		if r, ok := controlRoles[strings.TrimPrefix(fullMethod, controlService)]; ok {
+			return r
+		}
+	case strings.HasPrefix(fullMethod, gatewayService), strings.HasPrefix(fullMethod, traceService):
+		return RoleBuild
+	case strings.HasPrefix(fullMethod, healthService):
+		return roleAny
+	}
+	return RoleAdmin
+}
+
+// ParseRole returns the role of its name.
+func ParseRole(s string) (Role, error) {
+	switch r := Role(s); r {
+	case RoleBuild, RolePrune, RoleAdmin:
+		return r, nil
+	}
+	return "", errors.Errorf("invalid role %q, expected build, prune or admin", s)
+}
+
+// Client is a client of the daemon and its roles.
+type Client struct {
+	// CommonNames are the common names of the verified client certificates
+	// of the client.
+	CommonNames []string
+	Roles       []Role
+}
+
+// Authorizer authorizes the methods called by the clients of the daemon.
+// The connections of local sockets are authorized to call all the methods,
+// the connections of TCP sockets need a verified client certificate with a
+// role allowing the method.
+type Authorizer struct {
+	roles map[string]map[Role]struct{}
+}
+
+// NewAuthorizer returns an authorizer of the clients.
+func NewAuthorizer(clients []Client) (*Authorizer, error) {
+	a := &Authorizer{roles: map[string]map[Role]struct{}{}}
+	for _, c := range clients {
+		if len(c.CommonNames) == 0 {
+			return nil, errors.New("client roles without common names")
+		}
+		for _, cn := range c.CommonNames {
+			if cn == "" {
+				return nil, errors.New("empty client common name")
+			}
+			roles, ok := a.roles[cn]
+			if !ok {
+				roles = map[Role]struct{}{}
+				a.roles[cn] = roles
+			}
+			for _, r := range c.Roles {
+				if _, err := ParseRole(string(r)); err != nil {
+					return nil, errors.Wrapf(err, "client %s", cn)
+				}
+				roles[r] = struct{}{}
+			}
+		}
+	}
+	return a, nil
+}
+
+// Authorize returns an error if the client of the context is not allowed to
+// call the method.
+func (a *Authorizer) Authorize(ctx context.Context, fullMethod string) error {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "unknown peer")
+	}
+	var cn string
+	switch info := p.AuthInfo.(type) {
+	case tenant.LocalInfo:
+		return nil
+	case credentials.TLSInfo:
+		for _, chain := range info.State.VerifiedChains {
+			if len(chain) > 0 {
+				cn = chain[0].Subject.CommonName
+				break
+			}
+		}
+	}
+	if cn == "" {
+		return status.Error(codes.Unauthenticated, "no verified client certificate")
+	}
+	roles := a.roles[cn]
+	if len(roles) == 0 {
+		return status.Errorf(codes.PermissionDenied, "client %s has no role", cn)
+	}
+	role := MethodRole(fullMethod)
+	if _, ok := roles[RoleAdmin]; ok || role == roleAny {
+		return nil
+	}
+	if _, ok := roles[role]; ok {
+		return nil
+	}
+	return status.Errorf(codes.PermissionDenied, "client %s is not allowed to call %s, it requires the %s role", cn, fullMethod, role)
+}
+
+// UnaryServerInterceptor rejects the requests the client is not allowed to
+// make.
+func (a *Authorizer) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := a.Authorize(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor rejects the streams the client is not allowed to
+// open.
+func (a *Authorizer) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := a.Authorize(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
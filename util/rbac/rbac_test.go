@@ -0,0 +1,78 @@
+package rbac
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+
+	"github.com/moby/buildkit/util/tenant"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func withCommonName(cn string) context.Context {
+	info := credentials.TLSInfo{}
+	if cn != "" {
+		info.State = tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: cn}}}},
+		}
+	}
+	return peer.NewContext(context.TODO(), &peer.Peer{Addr: &net.TCPAddr{}, AuthInfo: info})
+}
+
+func TestMethodRole(t *testing.T) {
+	require.Equal(t, RoleBuild, MethodRole("/moby.buildkit.v1.Control/Solve"))
+	require.Equal(t, RoleBuild, MethodRole("/moby.buildkit.v1.frontend.LLBBridge/Return"))
+	require.Equal(t, RolePrune, MethodRole("/moby.buildkit.v1.Control/Prune"))
+	require.Equal(t, RoleAdmin, MethodRole("/moby.buildkit.v1.Control/ReloadConfig"))
+	require.Equal(t, RoleAdmin, MethodRole("/moby.buildkit.v1.Control/Unknown"))
+	require.Equal(t, roleAny, MethodRole("/moby.buildkit.v1.Control/ListWorkers"))
+}
+
+func TestAuthorize(t *testing.T) {
+	a, err := NewAuthorizer([]Client{
+		{CommonNames: []string{"ci"}, Roles: []Role{RoleBuild}},
+		{CommonNames: []string{"ops", "ci"}, Roles: []Role{RolePrune}},
+		{CommonNames: []string{"root"}, Roles: []Role{RoleAdmin}},
+		{CommonNames: []string{"nobody"}},
+	})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		cn     string
+		method string
+		code   codes.Code
+	}{
+		{cn: "ci", method: "/moby.buildkit.v1.Control/Solve"},
+		{cn: "ci", method: "/moby.buildkit.v1.Control/Prune"},
+		{cn: "ci", method: "/moby.buildkit.v1.Control/SetParallelism", code: codes.PermissionDenied},
+		{cn: "ops", method: "/moby.buildkit.v1.Control/Solve", code: codes.PermissionDenied},
+		{cn: "ops", method: "/moby.buildkit.v1.Control/DiskUsage"},
+		{cn: "root", method: "/moby.buildkit.v1.Control/ReloadConfig"},
+		{cn: "nobody", method: "/moby.buildkit.v1.Control/DiskUsage", code: codes.PermissionDenied},
+		{cn: "unknown", method: "/moby.buildkit.v1.Control/DiskUsage", code: codes.PermissionDenied},
+		{cn: "", method: "/moby.buildkit.v1.Control/DiskUsage", code: codes.Unauthenticated},
+	} {
+		err := a.Authorize(withCommonName(tc.cn), tc.method)
+		if tc.code == codes.OK {
+			require.NoError(t, err, "%s %s", tc.cn, tc.method)
+			continue
+		}
+		require.Equal(t, tc.code, status.Code(err), "%s %s", tc.cn, tc.method)
+	}
+
+	local := peer.NewContext(context.TODO(), &peer.Peer{Addr: &net.UnixAddr{}, AuthInfo: tenant.LocalInfo{}})
+	require.NoError(t, a.Authorize(local, "/moby.buildkit.v1.Control/ReloadConfig"))
+
+	insecure := peer.NewContext(context.TODO(), &peer.Peer{Addr: &net.TCPAddr{}, AuthInfo: tenant.InsecureInfo{}})
+	require.Equal(t, codes.Unauthenticated, status.Code(a.Authorize(insecure, "/moby.buildkit.v1.Control/Solve")))
+
+	_, err = NewAuthorizer([]Client{{CommonNames: []string{"ci"}, Roles: []Role{"root"}}})
+	require.Error(t, err)
+}
@@ -0,0 +1,157 @@
+package resolver
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containerd/containerd/remotes/docker"
+	"github.com/moby/buildkit/util/progress/logs"
+	"github.com/pkg/errors"
+)
+
+const (
+	// mirrorFailureThreshold is the number of consecutive failures after which
+	// a mirror is skipped.
+	mirrorFailureThreshold = 3
+	// mirrorCooldown is how long a failing mirror is skipped before it is
+	// tried again.
+	mirrorCooldown = 30 * time.Second
+)
+
+var mirrorHealth = newHealthTracker(mirrorFailureThreshold, mirrorCooldown)
+
+// healthTracker is a circuit breaker for the registry mirrors. A mirror that
+// failed threshold times in a row is skipped for the cooldown, then a single
+// request is let through. The mirror recovers when a request succeeds.
+type healthTracker struct {
+	mu        sync.Mutex
+	hosts     map[string]*hostHealth
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+}
+
+type hostHealth struct {
+	failures  int
+	openUntil time.Time
+}
+
+func newHealthTracker(threshold int, cooldown time.Duration) *healthTracker {
+	return &healthTracker{
+		hosts:     map[string]*hostHealth{},
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// available returns false while the circuit of the host is open.
+func (t *healthTracker) available(host string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h, ok := t.hosts[host]
+	if !ok || h.failures < t.threshold {
+		return true
+	}
+	if t.now().Before(h.openUntil) {
+		return false
+	}
+	// half-open, let one request through until it completes
+	h.openUntil = t.now().Add(t.cooldown)
+	return true
+}
+
+func (t *healthTracker) success(host string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.hosts, host)
+}
+
+func (t *healthTracker) failure(host string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h, ok := t.hosts[host]
+	if !ok {
+		h = &hostHealth{}
+		t.hosts[host] = h
+	}
+	h.failures++
+	if h.failures >= t.threshold {
+		h.openUntil = t.now().Add(t.cooldown)
+	}
+}
+
+// healthy returns false if the host failed since its last success.
+func (t *healthTracker) healthy(host string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.hosts[host]
+	return !ok
+}
+
+// order sorts the hosts so that the ones that are healthy are tried first,
+// keeping the configured order otherwise.
+func (t *healthTracker) order(hosts []docker.RegistryHost) {
+	sort.SliceStable(hosts, func(i, j int) bool {
+		return t.healthy(hosts[i].Host) && !t.healthy(hosts[j].Host)
+	})
+}
+
+// mirrorTransport tracks the health of a mirror and fails its requests
+// immediately while its circuit is open, so that the next host is tried. The
+// blobs served by the mirror are reported in the logs of the pull.
+type mirrorTransport struct {
+	rt      http.RoundTripper
+	host    string
+	tracker *healthTracker
+}
+
+func newMirrorClient(c *http.Client, host string, tracker *healthTracker) *http.Client {
+	c2 := *c
+	rt := c.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	c2.Transport = &mirrorTransport{rt: rt, host: host, tracker: tracker}
+	return &c2
+}
+
+func (t *mirrorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.tracker.available(t.host) {
+		return nil, errors.Errorf("skipping registry mirror %s after repeated failures", t.host)
+	}
+	resp, err := t.rt.RoundTrip(req)
+	if err != nil || resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		t.tracker.failure(t.host)
+		if dgst := blobDigest(req); dgst != "" {
+			reason := err
+			if reason == nil {
+				reason = errors.Errorf("status %s", resp.Status)
+			}
+			logs.LoggerFromContext(req.Context())([]byte(fmt.Sprintf("registry mirror %s failed to serve %s: %v\n", t.host, dgst, reason)))
+		}
+		return resp, err
+	}
+	t.tracker.success(t.host)
+	if dgst := blobDigest(req); dgst != "" && resp.StatusCode < 400 && req.Header.Get("Range") == "" {
+		logs.LoggerFromContext(req.Context())([]byte(fmt.Sprintf("%s served by registry mirror %s\n", dgst, t.host)))
+	}
+	return resp, nil
+}
+
+// blobDigest returns the digest of the blob fetched by the request, or an
+// empty string for other requests.
+func blobDigest(req *http.Request) string {
+	if req.Method != http.MethodGet {
+		return ""
+	}
+	parts := strings.Split(req.URL.Path, "/")
+	if len(parts) < 2 || parts[len(parts)-2] != "blobs" {
+		return ""
+	}
+	return parts[len(parts)-1]
+}
@@ -0,0 +1,80 @@
+package resolver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd/remotes/docker"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthTracker(t *testing.T) {
+	now := time.Now()
+	tracker := newHealthTracker(2, time.Minute)
+	tracker.now = func() time.Time { return now }
+
+	require.True(t, tracker.available("mirror"))
+	tracker.failure("mirror")
+	require.True(t, tracker.available("mirror"))
+	require.False(t, tracker.healthy("mirror"))
+	tracker.failure("mirror")
+	require.False(t, tracker.available("mirror"))
+
+	// a single request is let through after the cooldown
+	now = now.Add(time.Minute)
+	require.True(t, tracker.available("mirror"))
+	require.False(t, tracker.available("mirror"))
+
+	tracker.success("mirror")
+	require.True(t, tracker.available("mirror"))
+	require.True(t, tracker.healthy("mirror"))
+
+	tracker.failure("b")
+	hosts := []docker.RegistryHost{{Host: "a"}, {Host: "b"}, {Host: "c"}, {Host: "registry"}}
+	tracker.order(hosts)
+	require.Equal(t, []docker.RegistryHost{{Host: "a"}, {Host: "c"}, {Host: "registry"}, {Host: "b"}}, hosts)
+}
+
+func TestMirrorTransport(t *testing.T) {
+	var requests int
+	status := http.StatusServiceUnavailable
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	tracker := newHealthTracker(2, time.Minute)
+	c := newMirrorClient(srv.Client(), "mirror", tracker)
+	url := srv.URL + "/v2/library/alpine/blobs/sha256:abc"
+
+	for i := 0; i < 2; i++ {
+		resp, err := c.Get(url)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	}
+	_, err := c.Get(url)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "skipping registry mirror mirror")
+	require.Equal(t, 2, requests)
+
+	tracker.success("mirror")
+	status = http.StatusOK
+	resp, err := c.Get(url)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, 3, requests)
+	require.True(t, tracker.healthy("mirror"))
+}
+
+func TestBlobDigest(t *testing.T) {
+	req := httptest.NewRequest("GET", "https://mirror/v2/library/alpine/blobs/sha256:abc?ns=docker.io", nil)
+	require.Equal(t, "sha256:abc", blobDigest(req))
+	req = httptest.NewRequest("GET", "https://mirror/v2/library/alpine/manifests/latest", nil)
+	require.Equal(t, "", blobDigest(req))
+	req = httptest.NewRequest("HEAD", "https://mirror/v2/library/alpine/blobs/sha256:abc", nil)
+	require.Equal(t, "", blobDigest(req))
+}
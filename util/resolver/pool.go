@@ -162,6 +162,8 @@ func (r *Resolver) HostsFunc(host string) ([]docker.RegistryHost, error) {
 		for i := range res {
 			res[i].Authorizer = auth
 		}
+		// try the mirrors that failed last
+		mirrorHealth.order(res)
 		return res, nil
 	}(host)
 }
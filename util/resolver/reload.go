@@ -0,0 +1,58 @@
+package resolver
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/containerd/containerd/remotes/docker"
+	"github.com/moby/buildkit/util/resolver/config"
+	"github.com/pkg/errors"
+)
+
+// ReloadableRegistryHosts is a registry configuration that can be replaced
+// while the daemon runs. Resolvers created before a reload keep using the
+// previous configuration, so pulls and pushes in progress are not
+// interrupted.
+type ReloadableRegistryHosts struct {
+	mu    sync.RWMutex
+	hosts docker.RegistryHosts
+}
+
+// NewReloadableRegistryHosts returns the registry hosts of the configuration.
+func NewReloadableRegistryHosts(m map[string]config.RegistryConfig) *ReloadableRegistryHosts {
+	return &ReloadableRegistryHosts{
+		hosts: NewRegistryConfig(m),
+	}
+}
+
+// RegistryHosts returns the hosts of the registry with the current
+// configuration. It implements docker.RegistryHosts.
+func (r *ReloadableRegistryHosts) RegistryHosts(host string) ([]docker.RegistryHost, error) {
+	r.mu.RLock()
+	hosts := r.hosts
+	r.mu.RUnlock()
+	return hosts(host)
+}
+
+// Reload replaces the configuration. The configuration of every registry is
+// loaded first, including its certificates, and the current configuration
+// is kept if any of them fails. The cached resolvers of the default pool are
+// cleared so that new pulls and pushes use the new configuration. The
+// configured registries are returned.
+func (r *ReloadableRegistryHosts) Reload(m map[string]config.RegistryConfig) ([]string, error) {
+	hosts := NewRegistryConfig(m)
+	names := make([]string, 0, len(m))
+	for name := range m {
+		if _, err := hosts(name); err != nil {
+			return nil, errors.Wrapf(err, "invalid configuration for registry %s", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	r.mu.Lock()
+	r.hosts = hosts
+	r.mu.Unlock()
+	DefaultPool.Clear()
+	return names, nil
+}
@@ -0,0 +1,39 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/util/resolver/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadableRegistryHosts(t *testing.T) {
+	r := NewReloadableRegistryHosts(map[string]config.RegistryConfig{
+		"docker.io": {Mirrors: []string{"mirror1.example.com"}},
+	})
+
+	hosts, err := r.RegistryHosts("docker.io")
+	require.NoError(t, err)
+	require.Equal(t, "mirror1.example.com", hosts[0].Host)
+
+	names, err := r.Reload(map[string]config.RegistryConfig{
+		"docker.io":            {Mirrors: []string{"mirror2.example.com"}},
+		"registry.example.com": {},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"docker.io", "registry.example.com"}, names)
+
+	hosts, err = r.RegistryHosts("docker.io")
+	require.NoError(t, err)
+	require.Equal(t, "mirror2.example.com", hosts[0].Host)
+
+	// an invalid configuration keeps the current one
+	_, err = r.Reload(map[string]config.RegistryConfig{
+		"docker.io": {RootCAs: []string{"/nonexistent/ca.pem"}},
+	})
+	require.Error(t, err)
+
+	hosts, err = r.RegistryHosts("docker.io")
+	require.NoError(t, err)
+	require.Equal(t, "mirror2.example.com", hosts[0].Host)
+}
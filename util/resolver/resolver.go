@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/containerd/containerd/remotes/docker"
+	"github.com/moby/buildkit/util/bandwidth"
 	"github.com/moby/buildkit/util/resolver/config"
 	"github.com/moby/buildkit/util/tracing"
 	"github.com/pkg/errors"
@@ -46,7 +47,7 @@ func fillInsecureOpts(host string, c config.RegistryConfig, h docker.RegistryHos
 		transport := newDefaultTransport()
 		transport.TLSClientConfig = tc
 		h2.Client = &http.Client{
-			Transport: tracing.NewTransport(transport),
+			Transport: tracing.NewTransport(bandwidth.NewTransport(transport)),
 		}
 		tc.InsecureSkipVerify = true
 		hosts = append(hosts, h2)
@@ -57,7 +58,7 @@ func fillInsecureOpts(host string, c config.RegistryConfig, h docker.RegistryHos
 		transport.TLSClientConfig = tc
 
 		h.Client = &http.Client{
-			Transport: tracing.NewTransport(transport),
+			Transport: tracing.NewTransport(bandwidth.NewTransport(transport)),
 		}
 		hosts = append(hosts, h)
 	}
@@ -139,6 +140,9 @@ func NewRegistryConfig(m map[string]config.RegistryConfig) docker.RegistryHosts
 				if err != nil {
 					return nil, err
 				}
+				for i := range hosts {
+					hosts[i].Client = newMirrorClient(hosts[i].Client, mirror, mirrorHealth)
+				}
 
 				out = append(out, hosts...)
 			}
@@ -172,7 +176,7 @@ func NewRegistryConfig(m map[string]config.RegistryConfig) docker.RegistryHosts
 
 func newDefaultClient() *http.Client {
 	return &http.Client{
-		Transport: tracing.NewTransport(newDefaultTransport()),
+		Transport: tracing.NewTransport(bandwidth.NewTransport(newDefaultTransport())),
 	}
 }
 
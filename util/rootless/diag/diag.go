@@ -0,0 +1,37 @@
+// Package diag detects the features of the host used by the daemon in
+// rootless mode and reports the limitations of the missing ones.
+package diag
+
+// Check is the result of the detection of a feature.
+type Check struct {
+	// Name is the name of the feature, e.g. "overlayfs".
+	Name string
+	// OK is set if the feature is available.
+	OK bool
+	// Message describes what was detected and, for missing features, the
+	// limitation and how to lift it.
+	Message string
+}
+
+// Opt are the options of the detection.
+type Opt struct {
+	// Root is the state directory of the daemon, where overlayfs is tried.
+	Root string
+	// Slirp4netns is the slirp4netns binary, looked up in PATH if it is not
+	// a path.
+	Slirp4netns string
+	// Bypass4netns is the bypass4netns binary, looked up in PATH if it is
+	// not a path.
+	Bypass4netns string
+}
+
+// Limitations returns the checks of the missing features.
+func Limitations(checks []Check) []Check {
+	var out []Check
+	for _, c := range checks {
+		if !c.OK {
+			out = append(out, c)
+		}
+	}
+	return out
+}
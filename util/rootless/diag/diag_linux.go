@@ -0,0 +1,284 @@
+package diag
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containerd/containerd/pkg/userns"
+	"github.com/containerd/containerd/snapshots/overlay/overlayutils"
+	fuseoverlayfs "github.com/containerd/fuse-overlayfs-snapshotter"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// minMappedIDs is the number of IDs a user namespace needs to map to
+// extract most images.
+const minMappedIDs = 65536
+
+// Detect runs the checks of the features used in rootless mode.
+func Detect(ctx context.Context, opt Opt) []Check {
+	var checks []Check
+	if userns.RunningInUserNS() {
+		checks = append(checks, checkIDMap(), checkRootlessKit(ctx))
+	}
+	checks = append(checks,
+		checkOverlay(opt.Root),
+		checkCgroup(),
+		checkKernelIDMap(),
+		checkBinary(ctx, "slirp4netns", opt.Slirp4netns, "the build steps use the network namespace of the daemon, install slirp4netns to isolate their network"),
+		checkBinary(ctx, "bypass4netns", opt.Bypass4netns, "the network traffic of the build steps using slirp4netns is slower than the host network, install bypass4netns to accelerate it"),
+	)
+	return checks
+}
+
+func checkIDMap() Check {
+	c := Check{Name: "subuid"}
+	n, err := mappedIDs("/proc/self/uid_map")
+	if err != nil {
+		c.Message = fmt.Sprintf("failed to read the UID mapping: %v", err)
+		return c
+	}
+	if n < minMappedIDs {
+		c.Message = fmt.Sprintf("only %d UIDs are mapped in the user namespace: images with files owned by higher UIDs can't be extracted, add a range of %d IDs for the user to /etc/subuid and /etc/subgid", n, minMappedIDs)
+		return c
+	}
+	c.OK = true
+	c.Message = fmt.Sprintf("%d UIDs are mapped in the user namespace", n)
+	return c
+}
+
+func mappedIDs(p string) (int64, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	var total int64
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		n, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, s.Err()
+}
+
+// rootlessKitInfo is the response of the info endpoint of the RootlessKit
+// API.
+type rootlessKitInfo struct {
+	Version       string `json:"version"`
+	NetworkDriver *struct {
+		Driver string `json:"driver"`
+	} `json:"networkDriver"`
+	PortDriver *struct {
+		Driver string `json:"driver"`
+	} `json:"portDriver"`
+}
+
+func checkRootlessKit(ctx context.Context) Check {
+	c := Check{Name: "rootlesskit"}
+	dir := os.Getenv("ROOTLESSKIT_STATE_DIR")
+	if dir == "" {
+		c.Message = "not started by RootlessKit: the daemon shares the network namespace of the host, start it with rootlesskit to isolate it"
+		return c
+	}
+	info, err := queryRootlessKit(ctx, filepath.Join(dir, "api.sock"))
+	if err != nil {
+		c.OK = true
+		c.Message = fmt.Sprintf("started by RootlessKit, failed to query its API: %v", err)
+		return c
+	}
+	netDriver, portDriver := "none", "none"
+	if info.NetworkDriver != nil {
+		netDriver = info.NetworkDriver.Driver
+	}
+	if info.PortDriver != nil {
+		portDriver = info.PortDriver.Driver
+	}
+	c.OK = true
+	c.Message = fmt.Sprintf("RootlessKit %s, network driver %s, port driver %s", info.Version, netDriver, portDriver)
+	if netDriver == "none" || netDriver == "host" {
+		c.OK = false
+		c.Message += ": the daemon shares the network namespace of the host, start rootlesskit with --net=slirp4netns to isolate it"
+	}
+	return c
+}
+
+func queryRootlessKit(ctx context.Context, sock string) (*rootlessKitInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", sock)
+			},
+		},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://rootlesskit/v1/info", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status %s", resp.Status)
+	}
+	var info rootlessKitInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func checkOverlay(root string) Check {
+	c := Check{Name: "overlayfs"}
+	err := overlayutils.Supported(root)
+	if err == nil {
+		c.OK = true
+		c.Message = "overlayfs is available"
+		return c
+	}
+	if err2 := fuseoverlayfs.Supported(root); err2 == nil {
+		c.Message = fmt.Sprintf("overlayfs is not available (%v): the fuse-overlayfs snapshotter is used by default, use kernel >= 5.11 for the faster overlayfs snapshotter", err)
+		return c
+	}
+	c.Message = fmt.Sprintf("overlayfs is not available (%v) and neither is fuse-overlayfs: the native snapshotter is used by default, it copies the files of every layer, use kernel >= 5.11 or install fuse-overlayfs", err)
+	return c
+}
+
+func checkCgroup() Check {
+	c := Check{Name: "cgroup"}
+	var st unix.Statfs_t
+	if err := unix.Statfs("/sys/fs/cgroup", &st); err != nil || st.Type != unix.CGROUP2_SUPER_MAGIC {
+		c.Message = "cgroup v1: the resource limits of the build steps can't be delegated, use cgroup v2"
+		return c
+	}
+	controllers, err := delegatedControllers()
+	if err != nil {
+		c.Message = fmt.Sprintf("cgroup v2, failed to read the delegated controllers: %v", err)
+		return c
+	}
+	var missing []string
+	for _, name := range []string{"cpu", "memory", "pids"} {
+		if _, ok := controllers[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		c.Message = fmt.Sprintf("cgroup v2, the %s controllers are not delegated: configure Delegate=cpu memory pids for the systemd user service", strings.Join(missing, ", "))
+		return c
+	}
+	c.OK = true
+	c.Message = "cgroup v2 with the cpu, memory and pids controllers delegated"
+	return c
+}
+
+func delegatedControllers() (map[string]struct{}, error) {
+	dt, err := ioutil.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return nil, err
+	}
+	var group string
+	for _, line := range strings.Split(string(dt), "\n") {
+		if strings.HasPrefix(line, "0::") {
+			group = strings.TrimPrefix(line, "0::")
+			break
+		}
+	}
+	dt, err = ioutil.ReadFile(filepath.Join("/sys/fs/cgroup", group, "cgroup.controllers"))
+	if err != nil {
+		return nil, err
+	}
+	controllers := map[string]struct{}{}
+	for _, name := range strings.Fields(string(dt)) {
+		controllers[name] = struct{}{}
+	}
+	return controllers, nil
+}
+
+func checkKernelIDMap() Check {
+	c := Check{Name: "idmapped-mounts"}
+	var uts unix.Utsname
+	if err := unix.Uname(&uts); err != nil {
+		c.Message = fmt.Sprintf("failed to read the kernel version: %v", err)
+		return c
+	}
+	release := unix.ByteSliceToString(uts.Release[:])
+	if !kernelAtLeast(release, 5, 19) {
+		c.Message = fmt.Sprintf("kernel %s doesn't support idmapped overlayfs mounts: the user namespace remapping of the build steps needs kernel >= 5.19", release)
+		return c
+	}
+	c.OK = true
+	c.Message = fmt.Sprintf("kernel %s supports idmapped overlayfs mounts", release)
+	return c
+}
+
+// kernelAtLeast returns whether the kernel release is at least
+// major.minor.
+func kernelAtLeast(release string, major, minor int) bool {
+	parts := strings.SplitN(release, ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	maj, ok := leadingInt(parts[0])
+	if !ok {
+		return false
+	}
+	min, ok := leadingInt(parts[1])
+	if !ok {
+		return false
+	}
+	return maj > major || (maj == major && min >= minor)
+}
+
+func leadingInt(s string) (int, bool) {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	n, err := strconv.Atoi(s[:i])
+	return n, err == nil
+}
+
+func checkBinary(ctx context.Context, name, bin, limitation string) Check {
+	c := Check{Name: name}
+	if bin == "" {
+		bin = name
+	}
+	p, err := exec.LookPath(bin)
+	if err != nil {
+		c.Message = fmt.Sprintf("%s not found: %s", bin, limitation)
+		return c
+	}
+	c.OK = true
+	c.Message = p
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if out, err := exec.CommandContext(ctx, p, "--version").Output(); err == nil {
+		if line := strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0]); line != "" {
+			c.Message = fmt.Sprintf("%s (%s)", p, line)
+		}
+	}
+	return c
+}
@@ -0,0 +1,26 @@
+package diag
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKernelAtLeast(t *testing.T) {
+	require.True(t, kernelAtLeast("5.19.0-41-generic", 5, 19))
+	require.True(t, kernelAtLeast("6.1.0", 5, 19))
+	require.True(t, kernelAtLeast("5.19-rc1", 5, 19))
+	require.False(t, kernelAtLeast("5.15.0-1030-aws", 5, 19))
+	require.False(t, kernelAtLeast("4.19.0", 5, 11))
+	require.False(t, kernelAtLeast("invalid", 5, 11))
+}
+
+func TestMappedIDs(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "uid_map")
+	require.NoError(t, os.WriteFile(p, []byte("         0       1000          1\n         1     100000      65536\n"), 0600))
+	n, err := mappedIDs(p)
+	require.NoError(t, err)
+	require.Equal(t, int64(65537), n)
+}
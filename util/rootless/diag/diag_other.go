@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package diag
+
+import "context"
+
+// Detect runs the checks of the features used in rootless mode. Rootless
+// mode is only supported on Linux.
+func Detect(ctx context.Context, opt Opt) []Check {
+	return nil
+}
@@ -0,0 +1,73 @@
+package tenant
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/credentials"
+)
+
+// LocalInfo is the auth info of the connections of local sockets.
+type LocalInfo struct {
+	credentials.CommonAuthInfo
+}
+
+func (LocalInfo) AuthType() string {
+	return "local"
+}
+
+// InsecureInfo is the auth info of the TCP connections without TLS.
+type InsecureInfo struct {
+	credentials.CommonAuthInfo
+}
+
+func (InsecureInfo) AuthType() string {
+	return "insecure"
+}
+
+type listenerCredentials struct{}
+
+// ListenerCredentials returns the server credentials of the connections of
+// listeners terminating TLS themselves, like the TCP listeners of the
+// daemon. The connections are kept as they are and their auth info reports
+// the TLS state of the TLS connections and whether the others are local, so
+// that the client certificates can be checked by the interceptors.
+func ListenerCredentials() credentials.TransportCredentials {
+	return listenerCredentials{}
+}
+
+func (listenerCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	switch c := conn.(type) {
+	case *tls.Conn:
+		if err := c.Handshake(); err != nil {
+			return nil, nil, err
+		}
+		return conn, credentials.TLSInfo{
+			State:          c.ConnectionState(),
+			CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity},
+		}, nil
+	}
+	switch conn.LocalAddr().Network() {
+	case "unix", "pipe":
+		return conn, LocalInfo{credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity}}, nil
+	}
+	return conn, InsecureInfo{credentials.CommonAuthInfo{SecurityLevel: credentials.NoSecurity}}, nil
+}
+
+func (listenerCredentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, errors.New("listener credentials are server credentials")
+}
+
+func (listenerCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: "listener"}
+}
+
+func (c listenerCredentials) Clone() credentials.TransportCredentials {
+	return c
+}
+
+func (listenerCredentials) OverrideServerName(string) error {
+	return nil
+}
@@ -0,0 +1,160 @@
+// Package tenant identifies the tenants sharing a daemon by the client
+// certificates or the tokens of their requests.
+package tenant
+
+import (
+	"context"
+	"crypto/subtle"
+	"regexp"
+	"strings"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var nameRe = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// Tenant is a tenant of the daemon and the credentials identifying it.
+type Tenant struct {
+	Name string
+	// CommonNames are the common names of the verified client certificates
+	// of the tenant.
+	CommonNames []string
+	// Tokens are the bearer tokens of the tenant, sent in the authorization
+	// metadata of the requests.
+	Tokens []string
+}
+
+type nameKey struct{}
+
+// WithName returns a context of the requests of the tenant.
+func WithName(ctx context.Context, name string) context.Context {
+	if name == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, nameKey{}, name)
+}
+
+// FromContext returns the name of the tenant of the context, empty if it
+// has none.
+func FromContext(ctx context.Context) string {
+	name, _ := ctx.Value(nameKey{}).(string)
+	return name
+}
+
+// Authenticator identifies the tenants of the requests to the daemon.
+type Authenticator struct {
+	commonNames map[string]string
+	tokens      []token
+}
+
+type token struct {
+	value  []byte
+	tenant string
+}
+
+// NewAuthenticator returns an authenticator of the tenants. Names, common
+// names and tokens must be unique.
+func NewAuthenticator(tenants []Tenant) (*Authenticator, error) {
+	a := &Authenticator{commonNames: map[string]string{}}
+	names := map[string]struct{}{}
+	seenTokens := map[string]struct{}{}
+	for _, t := range tenants {
+		if !nameRe.MatchString(t.Name) {
+			return nil, errors.Errorf("invalid tenant name %q", t.Name)
+		}
+		if _, ok := names[t.Name]; ok {
+			return nil, errors.Errorf("duplicate tenant %s", t.Name)
+		}
+		names[t.Name] = struct{}{}
+		for _, cn := range t.CommonNames {
+			if other, ok := a.commonNames[cn]; ok {
+				return nil, errors.Errorf("common name %q of tenant %s is used by tenant %s", cn, t.Name, other)
+			}
+			a.commonNames[cn] = t.Name
+		}
+		for _, tok := range t.Tokens {
+			if tok == "" {
+				return nil, errors.Errorf("empty token of tenant %s", t.Name)
+			}
+			if _, ok := seenTokens[tok]; ok {
+				return nil, errors.Errorf("duplicate token of tenant %s", t.Name)
+			}
+			seenTokens[tok] = struct{}{}
+			a.tokens = append(a.tokens, token{value: []byte(tok), tenant: t.Name})
+		}
+	}
+	return a, nil
+}
+
+// Authenticate returns the tenant of the request of the context, identified
+// by the bearer token of the request or else by the common name of the
+// verified client certificate.
+func (a *Authenticator) Authenticate(ctx context.Context) (string, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, v := range md.Get("authorization") {
+			if !strings.HasPrefix(v, "Bearer ") {
+				continue
+			}
+			if name, ok := a.tenantOfToken([]byte(strings.TrimPrefix(v, "Bearer "))); ok {
+				return name, nil
+			}
+			return "", status.Error(codes.Unauthenticated, "invalid token")
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			for _, chain := range info.State.VerifiedChains {
+				if len(chain) == 0 {
+					continue
+				}
+				if name, ok := a.commonNames[chain[0].Subject.CommonName]; ok {
+					return name, nil
+				}
+			}
+		}
+	}
+	return "", status.Error(codes.Unauthenticated, "no tenant credentials")
+}
+
+func (a *Authenticator) tenantOfToken(v []byte) (string, bool) {
+	name := ""
+	for _, t := range a.tokens {
+		if subtle.ConstantTimeCompare(t.value, v) == 1 {
+			name = t.tenant
+		}
+	}
+	return name, name != ""
+}
+
+// UnaryServerInterceptor rejects the requests of no tenant and sets the
+// tenant in the context of the others.
+func (a *Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		name, err := a.Authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(WithName(ctx, name), req)
+	}
+}
+
+// StreamServerInterceptor rejects the streams of no tenant and sets the
+// tenant in the context of the others.
+func (a *Authenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		name, err := a.Authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+		wrapped := grpc_middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = WithName(ss.Context(), name)
+		return handler(srv, wrapped)
+	}
+}
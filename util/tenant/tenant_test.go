@@ -0,0 +1,64 @@
+package tenant
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthenticate(t *testing.T) {
+	a, err := NewAuthenticator([]Tenant{
+		{Name: "team-a", CommonNames: []string{"a.example.com"}, Tokens: []string{"secret-a"}},
+		{Name: "team-b", Tokens: []string{"secret-b"}},
+	})
+	require.NoError(t, err)
+
+	ctx := context.TODO()
+	_, err = a.Authenticate(ctx)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	name, err := a.Authenticate(metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer secret-b")))
+	require.NoError(t, err)
+	require.Equal(t, "team-b", name)
+
+	_, err = a.Authenticate(metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer secret-c")))
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	certCtx := func(cn string) context.Context {
+		return peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: cn}}}},
+		}}})
+	}
+	name, err = a.Authenticate(certCtx("a.example.com"))
+	require.NoError(t, err)
+	require.Equal(t, "team-a", name)
+
+	_, err = a.Authenticate(certCtx("c.example.com"))
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	require.Equal(t, "", FromContext(ctx))
+	require.Equal(t, "team-a", FromContext(WithName(ctx, "team-a")))
+}
+
+func TestNewAuthenticator(t *testing.T) {
+	_, err := NewAuthenticator([]Tenant{{Name: "a/b"}})
+	require.Error(t, err)
+
+	_, err = NewAuthenticator([]Tenant{{Name: "a"}, {Name: "a"}})
+	require.Error(t, err)
+
+	_, err = NewAuthenticator([]Tenant{{Name: "a", Tokens: []string{"t"}}, {Name: "b", Tokens: []string{"t"}}})
+	require.Error(t, err)
+
+	_, err = NewAuthenticator([]Tenant{{Name: "a", CommonNames: []string{"cn"}}, {Name: "b", CommonNames: []string{"cn"}}})
+	require.Error(t, err)
+}
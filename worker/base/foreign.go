@@ -0,0 +1,82 @@
+package base
+
+import (
+	"context"
+
+	cacheconfig "github.com/moby/buildkit/cache/config"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/util/compression"
+	"github.com/moby/buildkit/worker"
+	"github.com/pkg/errors"
+)
+
+// foreignInputsOp imports the inputs of an op that are results of other
+// workers in the cache of the worker. The layers of the inputs are imported as
+// lazy blob-only records, so they are only unpacked by the worker if the op
+// mounts them.
+type foreignInputsOp struct {
+	solver.Op
+	w worker.Worker
+}
+
+func (op *foreignInputsOp) CacheMap(ctx context.Context, g session.Group, index int) (*solver.CacheMap, bool, error) {
+	cm, done, err := op.Op.CacheMap(ctx, g, index)
+	if err != nil || cm == nil {
+		return cm, done, err
+	}
+	for i, dep := range cm.Deps {
+		if dep.PreprocessFunc == nil {
+			continue
+		}
+		pfn := dep.PreprocessFunc
+		// the foreign inputs are unpacked after they are imported
+		cm.Deps[i].PreprocessFunc = func(ctx context.Context, res solver.Result, g session.Group) error {
+			if isForeign(res, op.w) {
+				return nil
+			}
+			return pfn(ctx, res, g)
+		}
+	}
+	return cm, done, nil
+}
+
+func (op *foreignInputsOp) Exec(ctx context.Context, g session.Group, inputs []solver.Result) ([]solver.Result, error) {
+	var imported []solver.Result
+	defer func() {
+		for _, res := range imported {
+			res.Release(context.TODO())
+		}
+	}()
+	converted := make([]solver.Result, len(inputs))
+	for i, inp := range inputs {
+		if !isForeign(inp, op.w) {
+			converted[i] = inp
+			continue
+		}
+		wref := inp.Sys().(*worker.WorkerRef)
+		remotes, err := wref.GetRemotes(ctx, true, cacheconfig.RefConfig{Compression: compression.New(compression.Default)}, false, g)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get layers of input %d from worker %s", i, wref.Worker.ID())
+		}
+		if len(remotes) == 0 {
+			return nil, errors.Errorf("no layers for input %d from worker %s", i, wref.Worker.ID())
+		}
+		ref, err := op.w.FromRemote(ctx, remotes[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to import input %d from worker %s", i, wref.Worker.ID())
+		}
+		converted[i] = worker.NewWorkerRefResult(ref, op.w)
+		imported = append(imported, converted[i])
+	}
+	return op.Op.Exec(ctx, g, converted)
+}
+
+// isForeign returns true if res is a non-empty result of another worker than w.
+func isForeign(res solver.Result, w worker.Worker) bool {
+	wref, ok := res.Sys().(*worker.WorkerRef)
+	if !ok || wref.ImmutableRef == nil || wref.Worker == nil {
+		return false
+	}
+	return wref.Worker.ID() != w.ID()
+}
@@ -0,0 +1,69 @@
+package base
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/buildkit/cache"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/worker"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+)
+
+type idWorker struct {
+	worker.Worker
+	id string
+}
+
+func (w *idWorker) ID() string {
+	return w.id
+}
+
+type testRef struct {
+	cache.ImmutableRef
+}
+
+type cacheMapOp struct {
+	solver.Op
+	cm *solver.CacheMap
+}
+
+func (op *cacheMapOp) CacheMap(context.Context, session.Group, int) (*solver.CacheMap, bool, error) {
+	return op.cm, true, nil
+}
+
+func TestForeignInputsPreprocess(t *testing.T) {
+	w0 := &idWorker{id: "w0"}
+	w1 := &idWorker{id: "w1"}
+
+	local := worker.NewWorkerRefResult(&testRef{}, w0)
+	foreign := worker.NewWorkerRefResult(&testRef{}, w1)
+	scratch := worker.NewWorkerRefResult(nil, w1)
+
+	require.False(t, isForeign(local, w0))
+	require.True(t, isForeign(foreign, w0))
+	require.False(t, isForeign(scratch, w0))
+
+	cm := &solver.CacheMap{
+		Deps: make([]struct {
+			Selector          digest.Digest
+			ComputeDigestFunc solver.ResultBasedCacheFunc
+			PreprocessFunc    solver.PreprocessFunc
+		}, 1),
+	}
+	var preprocessed []solver.Result
+	cm.Deps[0].PreprocessFunc = func(ctx context.Context, res solver.Result, g session.Group) error {
+		preprocessed = append(preprocessed, res)
+		return nil
+	}
+
+	op := &foreignInputsOp{Op: &cacheMapOp{cm: cm}, w: w0}
+	res, _, err := op.CacheMap(context.TODO(), nil, 0)
+	require.NoError(t, err)
+	pfn := res.Deps[0].PreprocessFunc
+	require.NoError(t, pfn(context.TODO(), local, nil))
+	require.NoError(t, pfn(context.TODO(), foreign, nil))
+	require.Equal(t, []solver.Result{local}, preprocessed)
+}
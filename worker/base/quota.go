@@ -0,0 +1,147 @@
+package base
+
+import (
+	"context"
+	"sync"
+
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/tenant"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/units"
+)
+
+// ErrDiskQuotaExceeded is returned by operations when the build cache of the
+// worker exceeds its disk quota and garbage collection could not free enough
+// space.
+var ErrDiskQuotaExceeded = errors.New("disk quota exceeded")
+
+// quotaManager enforces the disk quota of a worker and of its tenants. The
+// build cache is measured around every operation and garbage collected
+// synchronously when it exceeds the quota, holding back other operations of
+// the worker.
+type quotaManager struct {
+	limit int64
+	// tenants are the quotas of the records created for the tenants
+	tenants map[string]int64
+	// size returns the size of the build cache, or of the records of the
+	// tenant if it isn't empty
+	size func(ctx context.Context, tenant string) (int64, error)
+	// prune removes records from the build cache
+	prune func(context.Context, ...client.PruneInfo) error
+	// policy is run before the build cache is pruned down to the quota
+	policy []client.PruneInfo
+
+	mu sync.Mutex
+}
+
+func newQuotaManager(w *Worker) *quotaManager {
+	if w.DiskQuota <= 0 && len(w.TenantDiskQuota) == 0 {
+		return nil
+	}
+	return &quotaManager{
+		limit:   w.DiskQuota,
+		tenants: w.TenantDiskQuota,
+		size: func(ctx context.Context, tenant string) (int64, error) {
+			du, err := w.CacheMgr.DiskUsage(ctx, client.DiskUsageInfo{})
+			if err != nil {
+				return 0, err
+			}
+			var size int64
+			for _, ui := range du {
+				if !ui.Shared && (tenant == "" || ui.Tenant == tenant) {
+					size += ui.Size
+				}
+			}
+			return size, nil
+		},
+		prune: func(ctx context.Context, opt ...client.PruneInfo) error {
+			return w.CacheMgr.Prune(ctx, nil, opt...)
+		},
+		policy: w.WorkerOpt.GCPolicy,
+	}
+}
+
+// check garbage collects the build cache if it exceeds the quota, and the
+// records of the tenant of the operation if they exceed its quota. It fails
+// if the build cache still exceeds the quota after the garbage collection.
+func (q *quotaManager) check(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.limit > 0 {
+		if err := q.checkLimit(ctx, "", q.limit); err != nil {
+			return err
+		}
+	}
+	if t := tenant.FromContext(ctx); t != "" {
+		if limit, ok := q.tenants[t]; ok && limit > 0 {
+			return q.checkLimit(ctx, t, limit)
+		}
+	}
+	return nil
+}
+
+func (q *quotaManager) checkLimit(ctx context.Context, tenant string, limit int64) error {
+	what := "build cache"
+	if tenant != "" {
+		what = "build cache of tenant " + tenant
+	}
+	size, err := q.size(ctx, tenant)
+	if err != nil {
+		bklog.G(ctx).Warnf("failed to measure %s for disk quota: %v", what, err)
+		return nil
+	}
+	if size <= limit {
+		return nil
+	}
+
+	bklog.G(ctx).Infof("%s uses %.2f of disk quota %.2f, running garbage collection", what, units.Bytes(size), units.Bytes(limit))
+	var policy []client.PruneInfo
+	if tenant == "" {
+		policy = append(policy, q.policy...)
+	}
+	policy = append(policy, client.PruneInfo{KeepBytes: limit, Tenant: tenant})
+	if err := q.prune(ctx, policy...); err != nil {
+		return errors.Wrapf(err, "failed to garbage collect %s for disk quota", what)
+	}
+
+	size, err = q.size(ctx, tenant)
+	if err != nil {
+		bklog.G(ctx).Warnf("failed to measure %s for disk quota: %v", what, err)
+		return nil
+	}
+	if size > limit {
+		return errors.Wrapf(ErrDiskQuotaExceeded, "%s uses %.2f of %.2f after garbage collection", what, units.Bytes(size), units.Bytes(limit))
+	}
+	return nil
+}
+
+// quotaOp checks the disk quota of the worker before and after running the
+// operation. The results of the operation are released if they make the
+// build cache exceed the quota.
+type quotaOp struct {
+	solver.Op
+	quota *quotaManager
+}
+
+func (op *quotaOp) Exec(ctx context.Context, g session.Group, inputs []solver.Result) ([]solver.Result, error) {
+	if err := op.quota.check(ctx); err != nil {
+		return nil, err
+	}
+	outputs, err := op.Op.Exec(ctx, g, inputs)
+	if err != nil {
+		return nil, err
+	}
+	if err := op.quota.check(ctx); err != nil {
+		for _, r := range outputs {
+			if r != nil {
+				r.Release(context.TODO())
+			}
+		}
+		return nil, err
+	}
+	return outputs, nil
+}
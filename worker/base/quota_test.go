@@ -0,0 +1,78 @@
+package base
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/util/tenant"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaManager(t *testing.T) {
+	t.Parallel()
+
+	var size int64
+	var pruned [][]client.PruneInfo
+	q := &quotaManager{
+		limit: 100,
+		size: func(context.Context, string) (int64, error) {
+			return size, nil
+		},
+		prune: func(ctx context.Context, opt ...client.PruneInfo) error {
+			pruned = append(pruned, opt)
+			if size > 150 {
+				size -= 50
+			} else {
+				size = 80
+			}
+			return nil
+		},
+		policy: []client.PruneInfo{{KeepDuration: 3600}},
+	}
+	ctx := context.TODO()
+
+	size = 90
+	require.NoError(t, q.check(ctx))
+	require.Len(t, pruned, 0)
+
+	size = 120
+	require.NoError(t, q.check(ctx))
+	require.Len(t, pruned, 1)
+	require.Equal(t, []client.PruneInfo{{KeepDuration: 3600}, {KeepBytes: 100}}, pruned[0])
+	require.Equal(t, int64(80), size)
+
+	size = 300
+	err := q.check(ctx)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrDiskQuotaExceeded))
+	require.Len(t, pruned, 2)
+}
+
+func TestQuotaManagerTenant(t *testing.T) {
+	t.Parallel()
+
+	sizes := map[string]int64{"": 500, "a": 120, "b": 500}
+	var pruned [][]client.PruneInfo
+	q := &quotaManager{
+		tenants: map[string]int64{"a": 100},
+		size: func(ctx context.Context, tenant string) (int64, error) {
+			return sizes[tenant], nil
+		},
+		prune: func(ctx context.Context, opt ...client.PruneInfo) error {
+			pruned = append(pruned, opt)
+			sizes["a"] = 80
+			return nil
+		},
+	}
+	ctx := context.TODO()
+
+	// no daemon wide quota and no quota of tenant b
+	require.NoError(t, q.check(ctx))
+	require.NoError(t, q.check(tenant.WithName(ctx, "b")))
+	require.Len(t, pruned, 0)
+
+	require.NoError(t, q.check(tenant.WithName(ctx, "a")))
+	require.Equal(t, [][]client.PruneInfo{{{KeepBytes: 100, Tenant: "a"}}}, pruned)
+}
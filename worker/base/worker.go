@@ -40,6 +40,7 @@ import (
 	"github.com/moby/buildkit/source/git"
 	"github.com/moby/buildkit/source/http"
 	"github.com/moby/buildkit/source/local"
+	"github.com/moby/buildkit/source/objectstore"
 	"github.com/moby/buildkit/util/archutil"
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/progress"
@@ -75,6 +76,14 @@ type WorkerOpt struct {
 	ParallelismSem  *semaphore.Weighted
 	MetadataStore   *metadata.Store
 	MountPoolRoot   string
+	// DiskQuota is the maximum size of the build cache. Operations run
+	// garbage collection when it is exceeded and fail if that does not free
+	// enough space. Zero disables the quota.
+	DiskQuota int64
+	// TenantDiskQuota are the maximum sizes of the records of the build
+	// cache created for each tenant, enforced like DiskQuota on the
+	// operations of the tenant.
+	TenantDiskQuota map[string]int64
 }
 
 // Worker is a local worker instance with dedicated snapshotter, cache, and so on.
@@ -85,6 +94,7 @@ type Worker struct {
 	SourceManager *source.Manager
 	imageWriter   *imageexporter.ImageWriter
 	ImageSource   *containerimage.Source
+	quota         *quotaManager
 }
 
 // NewWorker instantiates a local worker
@@ -129,6 +139,17 @@ func NewWorker(ctx context.Context, opt WorkerOpt) (*Worker, error) {
 
 	sm.Register(is)
 
+	ocis, err := containerimage.NewOCILayoutSource(containerimage.SourceOpt{
+		ContentStore:  opt.ContentStore,
+		CacheAccessor: cm,
+		LeaseManager:  opt.LeaseManager,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sm.Register(ocis)
+
 	if err := git.Supported(); err == nil {
 		gs, err := git.NewSource(git.Opt{
 			CacheAccessor: cm,
@@ -150,6 +171,15 @@ func NewWorker(ctx context.Context, opt WorkerOpt) (*Worker, error) {
 
 	sm.Register(hs)
 
+	oss, err := objectstore.NewSource(objectstore.Opt{
+		CacheAccessor: cm,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sm.Register(oss)
+
 	ss, err := local.NewSource(local.Opt{
 		CacheAccessor: cm,
 	})
@@ -176,13 +206,15 @@ func NewWorker(ctx context.Context, opt WorkerOpt) (*Worker, error) {
 		opt.LeaseManager.Delete(ctx, l)
 	}
 
-	return &Worker{
+	w := &Worker{
 		WorkerOpt:     opt,
 		CacheMgr:      cm,
 		SourceManager: sm,
 		imageWriter:   iw,
 		ImageSource:   is,
-	}, nil
+	}
+	w.quota = newQuotaManager(w)
+	return w, nil
 }
 
 func (w *Worker) ContentStore() content.Store {
@@ -275,6 +307,18 @@ func (w *Worker) CacheManager() cache.Manager {
 }
 
 func (w *Worker) ResolveOp(v solver.Vertex, s frontend.FrontendLLBBridge, sm *session.Manager) (solver.Op, error) {
+	op, err := w.resolveOp(v, s, sm)
+	if err != nil {
+		return nil, err
+	}
+	op = &foreignInputsOp{Op: op, w: w}
+	if w.quota == nil {
+		return op, nil
+	}
+	return &quotaOp{Op: op, quota: w.quota}, nil
+}
+
+func (w *Worker) resolveOp(v solver.Vertex, s frontend.FrontendLLBBridge, sm *session.Manager) (solver.Op, error) {
 	if baseOp, ok := v.Sys().(*pb.Op); ok {
 		switch op := baseOp.Op.(type) {
 		case *pb.Op_Source:
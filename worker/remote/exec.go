@@ -0,0 +1,302 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/content/local"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/images/archive"
+	"github.com/moby/buildkit/cache"
+	cacheconfig "github.com/moby/buildkit/cache/config"
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/compression"
+	"github.com/moby/buildkit/util/contentutil"
+	"github.com/moby/buildkit/util/entitlements"
+	"github.com/moby/buildkit/worker"
+	digest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	// inputStore is the name of the OCI layout store of the session of the
+	// solves sent to the agents, holding the inputs of the operations.
+	inputStore = "remote-inputs"
+	// inputRepo is the name of the images of the inputs in the store.
+	inputRepo = "buildkit/remote-input"
+)
+
+// execOp runs an exec operation on the agent of a remote worker.
+type execOp struct {
+	solver.Op
+	op *pb.Op
+	w  *Worker
+}
+
+func (e *execOp) Exec(ctx context.Context, g session.Group, inputs []solver.Result) (outputs []solver.Result, err error) {
+	exec := e.op.GetExec()
+	for _, m := range exec.Mounts {
+		if m.MountType == pb.MountType_SECRET || m.MountType == pb.MountType_SSH {
+			return nil, errors.Errorf("%s mounts are not supported by remote worker %s", m.MountType, e.w.opt.Address)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "buildkit-remote-")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := local.NewStore(filepath.Join(dir, "inputs"))
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := make([]digest.Digest, len(inputs))
+	for i, inp := range inputs {
+		wref, ok := inp.Sys().(*worker.WorkerRef)
+		if !ok {
+			return nil, errors.Errorf("invalid reference for remote exec %T", inp.Sys())
+		}
+		manifests[i], err = pushInput(ctx, g, store, wref.ImmutableRef, e.op.Platform)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to send input %d to remote worker %s", i, e.w.opt.Address)
+		}
+	}
+
+	c, err := e.w.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			for _, r := range outputs {
+				if r != nil {
+					r.Release(context.TODO())
+				}
+			}
+			outputs = nil
+		}
+	}()
+	for i := 0; i < numOutputs(exec); i++ {
+		def, err := remoteDefinition(e.op, manifests, i)
+		if err != nil {
+			return outputs, err
+		}
+		ref, err := e.solve(ctx, g, c, def, store, filepath.Join(dir, "output"+strconv.Itoa(i)))
+		if err != nil {
+			return outputs, errors.Wrapf(err, "failed to run on remote worker %s", e.w.opt.Address)
+		}
+		outputs = append(outputs, worker.NewWorkerRefResult(ref, e.w.Worker))
+	}
+	return outputs, nil
+}
+
+// solve runs the definition on the agent and imports its output in the
+// cache of the local worker.
+func (e *execOp) solve(ctx context.Context, g session.Group, c *client.Client, def *llb.Definition, store content.Store, dir string) (cache.ImmutableRef, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	tarPath := filepath.Join(dir, "image.tar")
+	var ents []entitlements.Entitlement
+	if e.op.GetExec().Network == pb.NetMode_HOST {
+		ents = append(ents, entitlements.EntitlementNetworkHost)
+	}
+	if e.op.GetExec().Security == pb.SecurityMode_INSECURE {
+		ents = append(ents, entitlements.EntitlementSecurityInsecure)
+	}
+	if _, err := c.Solve(ctx, def, client.SolveOpt{
+		Exports: []client.ExportEntry{{
+			Type: client.ExporterOCI,
+			Output: func(map[string]string) (io.WriteCloser, error) {
+				return os.Create(tarPath)
+			},
+		}},
+		OCIStores:           map[string]content.Store{inputStore: store},
+		AllowedEntitlements: ents,
+	}, nil); err != nil {
+		return nil, err
+	}
+
+	outStore, err := local.NewStore(filepath.Join(dir, "content"))
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(tarPath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer f.Close()
+	idx, err := archive.ImportIndex(ctx, outStore, f)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := images.Manifest(ctx, outStore, idx, nil)
+	if err != nil {
+		return nil, err
+	}
+	dt, err := content.ReadBlob(ctx, outStore, manifest.Config)
+	if err != nil {
+		return nil, err
+	}
+	var img ocispecs.Image
+	if err := json.Unmarshal(dt, &img); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(img.RootFS.DiffIDs) != len(manifest.Layers) {
+		return nil, errors.Errorf("invalid image of remote output: %d layers, %d diff IDs", len(manifest.Layers), len(img.RootFS.DiffIDs))
+	}
+	layers := make([]ocispecs.Descriptor, len(manifest.Layers))
+	for i, desc := range manifest.Layers {
+		desc.Annotations = map[string]string{"containerd.io/uncompressed": img.RootFS.DiffIDs[i].String()}
+		layers[i] = desc
+	}
+	ref, err := e.w.Worker.FromRemote(ctx, &solver.Remote{
+		Descriptors: layers,
+		Provider:    outStore,
+	})
+	if err != nil || ref == nil {
+		return ref, err
+	}
+	// the layers are unpacked before the store of the output is removed
+	if err := ref.Extract(ctx, g); err != nil {
+		ref.Release(context.TODO())
+		return nil, err
+	}
+	return ref, nil
+}
+
+// pushInput writes an image with the layers of ref to the store and returns
+// the digest of its manifest.
+func pushInput(ctx context.Context, g session.Group, store content.Store, ref cache.ImmutableRef, platform *pb.Platform) (digest.Digest, error) {
+	layers := []ocispecs.Descriptor{}
+	diffIDs := []digest.Digest{}
+	if ref != nil {
+		remotes, err := ref.GetRemotes(ctx, true, cacheconfig.RefConfig{Compression: compression.New(compression.Default)}, false, g)
+		if err != nil {
+			return "", err
+		}
+		if len(remotes) == 0 {
+			return "", errors.Errorf("no layers for input %s", ref.ID())
+		}
+		for _, desc := range remotes[0].Descriptors {
+			if err := contentutil.Copy(ctx, store, remotes[0].Provider, desc, "", nil); err != nil {
+				return "", err
+			}
+			diffID, err := digest.Parse(desc.Annotations["containerd.io/uncompressed"])
+			if err != nil {
+				return "", errors.Wrapf(err, "invalid uncompressed digest of layer %s", desc.Digest)
+			}
+			layers = append(layers, ocispecs.Descriptor{
+				MediaType: desc.MediaType,
+				Digest:    desc.Digest,
+				Size:      desc.Size,
+			})
+			diffIDs = append(diffIDs, diffID)
+		}
+	}
+
+	img := ocispecs.Image{
+		RootFS: ocispecs.RootFS{Type: "layers", DiffIDs: diffIDs},
+	}
+	if platform != nil {
+		img.Architecture = platform.Architecture
+		img.OS = platform.OS
+		img.Variant = platform.Variant
+	}
+	config, err := writeJSON(ctx, store, ocispecs.MediaTypeImageConfig, img)
+	if err != nil {
+		return "", err
+	}
+	manifest, err := writeJSON(ctx, store, ocispecs.MediaTypeImageManifest, ocispecs.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispecs.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    layers,
+	})
+	if err != nil {
+		return "", err
+	}
+	return manifest.Digest, nil
+}
+
+func writeJSON(ctx context.Context, store content.Store, mediaType string, v interface{}) (ocispecs.Descriptor, error) {
+	dt, err := json.Marshal(v)
+	if err != nil {
+		return ocispecs.Descriptor{}, errors.WithStack(err)
+	}
+	desc := ocispecs.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(dt),
+		Size:      int64(len(dt)),
+	}
+	if err := content.WriteBlob(ctx, store, desc.Digest.String(), bytes.NewReader(dt), desc); err != nil {
+		return ocispecs.Descriptor{}, err
+	}
+	return desc, nil
+}
+
+// remoteDefinition returns the definition of the output of the exec op with
+// its inputs loaded from the images of the manifests in the input store.
+func remoteDefinition(op *pb.Op, manifests []digest.Digest, output int) (*llb.Definition, error) {
+	if len(manifests) != len(op.Inputs) {
+		return nil, errors.Errorf("invalid number of inputs %d, expected %d", len(manifests), len(op.Inputs))
+	}
+	def := &llb.Definition{}
+	inputs := make([]*pb.Input, len(manifests))
+	for i, dgst := range manifests {
+		src := &pb.Op{
+			Op: &pb.Op_Source{Source: &pb.SourceOp{
+				Identifier: "oci-layout://" + inputRepo + "@" + dgst.String(),
+				Attrs:      map[string]string{pb.AttrOCILayoutStoreID: inputStore},
+			}},
+			Platform: op.Platform,
+		}
+		dt, err := src.Marshal()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		def.Def = append(def.Def, dt)
+		inputs[i] = &pb.Input{Digest: digest.FromBytes(dt), Index: 0}
+	}
+
+	exec := *op
+	exec.Inputs = inputs
+	dt, err := exec.Marshal()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	def.Def = append(def.Def, dt)
+
+	dt, err = (&pb.Op{Inputs: []*pb.Input{{Digest: digest.FromBytes(dt), Index: pb.OutputIndex(output)}}}).Marshal()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	def.Def = append(def.Def, dt)
+	return def, nil
+}
+
+// numOutputs returns the number of outputs of the exec op.
+func numOutputs(exec *pb.ExecOp) int {
+	n := 0
+	for _, m := range exec.Mounts {
+		if int(m.Output)+1 > n {
+			n = int(m.Output) + 1
+		}
+	}
+	return n
+}
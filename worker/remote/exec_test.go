@@ -0,0 +1,89 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/content/local"
+	"github.com/moby/buildkit/solver/pb"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteDefinition(t *testing.T) {
+	op := &pb.Op{
+		Inputs: []*pb.Input{
+			{Digest: digest.FromString("rootfs"), Index: 0},
+			{Digest: digest.FromString("src"), Index: 1},
+		},
+		Op: &pb.Op_Exec{Exec: &pb.ExecOp{
+			Meta: &pb.Meta{Args: []string{"make"}, Cwd: "/src"},
+			Mounts: []*pb.Mount{
+				{Input: 0, Dest: "/", Output: 0},
+				{Input: 1, Dest: "/src", Output: 1},
+				{Input: pb.Empty, Dest: "/cache", Output: pb.SkipOutput, MountType: pb.MountType_CACHE},
+			},
+		}},
+		Platform: &pb.Platform{OS: "linux", Architecture: "arm64"},
+	}
+	require.Equal(t, 2, numOutputs(op.GetExec()))
+
+	manifests := []digest.Digest{digest.FromString("m0"), digest.FromString("m1")}
+	def, err := remoteDefinition(op, manifests, 1)
+	require.NoError(t, err)
+	require.Len(t, def.Def, 4)
+
+	ops := make([]pb.Op, len(def.Def))
+	for i, dt := range def.Def {
+		require.NoError(t, ops[i].Unmarshal(dt))
+	}
+	for i, m := range manifests {
+		src := ops[i].GetSource()
+		require.NotNil(t, src)
+		require.Equal(t, "oci-layout://"+inputRepo+"@"+m.String(), src.Identifier)
+		require.Equal(t, inputStore, src.Attrs[pb.AttrOCILayoutStoreID])
+		require.Equal(t, "arm64", ops[i].Platform.Architecture)
+	}
+	exec := ops[2]
+	require.Equal(t, []string{"make"}, exec.GetExec().Meta.Args)
+	require.Len(t, exec.Inputs, 2)
+	require.Equal(t, digest.FromBytes(def.Def[0]), exec.Inputs[0].Digest)
+	require.Equal(t, digest.FromBytes(def.Def[1]), exec.Inputs[1].Digest)
+	require.Equal(t, pb.InputIndex(1), exec.GetExec().Mounts[1].Input)
+
+	require.Equal(t, digest.FromBytes(def.Def[2]), ops[3].Inputs[0].Digest)
+	require.Equal(t, pb.OutputIndex(1), ops[3].Inputs[0].Index)
+
+	// the original op is not modified
+	require.Equal(t, digest.FromString("rootfs"), op.Inputs[0].Digest)
+
+	_, err = remoteDefinition(op, manifests[:1], 0)
+	require.Error(t, err)
+}
+
+func TestPushScratchInput(t *testing.T) {
+	ctx := context.TODO()
+	store, err := local.NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	dgst, err := pushInput(ctx, nil, store, nil, &pb.Platform{OS: "linux", Architecture: "riscv64"})
+	require.NoError(t, err)
+
+	dt, err := content.ReadBlob(ctx, store, ocispecs.Descriptor{Digest: dgst})
+	require.NoError(t, err)
+	var mfst ocispecs.Manifest
+	require.NoError(t, json.Unmarshal(dt, &mfst))
+	require.Equal(t, ocispecs.MediaTypeImageManifest, mfst.MediaType)
+	require.Len(t, mfst.Layers, 0)
+
+	dt, err = content.ReadBlob(ctx, store, mfst.Config)
+	require.NoError(t, err)
+	var img ocispecs.Image
+	require.NoError(t, json.Unmarshal(dt, &img))
+	require.Equal(t, "riscv64", img.Architecture)
+	require.Equal(t, "linux", img.OS)
+	require.Equal(t, "layers", img.RootFS.Type)
+}
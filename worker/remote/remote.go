@@ -0,0 +1,120 @@
+package remote
+
+import (
+	"context"
+	"sync"
+
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/frontend"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/worker"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// WorkerOpt configures a remote worker.
+type WorkerOpt struct {
+	// Address is the address of the buildkitd agent, e.g.
+	// tcp://arm64-builder:1234.
+	Address string
+	// Platforms are the platforms the agent runs, the first one is the
+	// platform of its host.
+	Platforms []ocispecs.Platform
+	Labels    map[string]string
+	// ClientOpts are the options of the client connecting to the agent.
+	ClientOpts []client.ClientOpt
+}
+
+// Worker runs the exec operations of its platforms on a remote buildkitd
+// agent. The inputs of the operations are sent to the agent through the
+// content store of the session of the solve, and the outputs are exported by
+// the agent as OCI images and imported in the cache of the local worker. The
+// other operations run on the local worker.
+type Worker struct {
+	worker.Worker
+	opt WorkerOpt
+
+	mu sync.Mutex
+	c  *client.Client
+}
+
+// NewWorker returns a remote worker keeping its results in the cache of the
+// local worker.
+func NewWorker(local worker.Worker, opt WorkerOpt) (*Worker, error) {
+	if opt.Address == "" {
+		return nil, errors.New("remote worker requires an address")
+	}
+	if len(opt.Platforms) == 0 {
+		return nil, errors.Errorf("remote worker %s requires platforms", opt.Address)
+	}
+	return &Worker{Worker: local, opt: opt}, nil
+}
+
+func (w *Worker) ID() string {
+	return "remote:" + w.opt.Address
+}
+
+func (w *Worker) Labels() map[string]string {
+	labels := map[string]string{
+		worker.LabelExecutor:      "remote",
+		worker.LabelRemoteAddress: w.opt.Address,
+	}
+	for k, v := range w.opt.Labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+func (w *Worker) Platforms(noCache bool) []ocispecs.Platform {
+	return w.opt.Platforms
+}
+
+// GCPolicy, DiskUsage, Prune and PruneCacheMounts don't apply to the cache
+// of the local worker, which is listed and pruned with the local worker.
+
+func (w *Worker) GCPolicy() []client.PruneInfo {
+	return nil
+}
+
+func (w *Worker) DiskUsage(ctx context.Context, opt client.DiskUsageInfo) ([]*client.UsageInfo, error) {
+	return nil, nil
+}
+
+func (w *Worker) Prune(ctx context.Context, ch chan client.UsageInfo, opt ...client.PruneInfo) error {
+	return nil
+}
+
+func (w *Worker) PruneCacheMounts(ctx context.Context, ids []string) error {
+	return nil
+}
+
+func (w *Worker) ResolveOp(v solver.Vertex, s frontend.FrontendLLBBridge, sm *session.Manager) (solver.Op, error) {
+	op, err := w.Worker.ResolveOp(v, s, sm)
+	if err != nil {
+		return nil, err
+	}
+	if baseOp, ok := v.Sys().(*pb.Op); ok {
+		if _, ok := baseOp.Op.(*pb.Op_Exec); ok {
+			// the local op computes the cache keys from the local inputs
+			return &execOp{Op: op, op: baseOp, w: w}, nil
+		}
+	}
+	return op, nil
+}
+
+// client returns the client of the agent, connecting on first use.
+func (w *Worker) client(ctx context.Context) (*client.Client, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.c != nil {
+		return w.c, nil
+	}
+	c, err := client.New(ctx, w.opt.Address, w.opt.ClientOpts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to remote worker %s", w.opt.Address)
+	}
+	w.c = c
+	return c, nil
+}
@@ -7,6 +7,7 @@ import (
 	cacheconfig "github.com/moby/buildkit/cache/config"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/util/compression"
 )
 
 func NewWorkerRefResult(ref cache.ImmutableRef, worker Worker) solver.Result {
@@ -52,6 +53,17 @@ func (r *workerRefResult) Release(ctx context.Context) error {
 	return r.ImmutableRef.Release(ctx)
 }
 
+// BlobSize returns the size of the blob of the topmost layer of the ref,
+// without creating the blob if it doesn't exist.
+func (r *workerRefResult) BlobSize(ctx context.Context) (int64, error) {
+	remotes, err := r.GetRemotes(ctx, false, cacheconfig.RefConfig{Compression: compression.New(compression.Default)}, false, nil)
+	if err != nil || len(remotes) == 0 || len(remotes[0].Descriptors) == 0 {
+		return 0, err
+	}
+	descs := remotes[0].Descriptors
+	return descs[len(descs)-1].Size, nil
+}
+
 func (r *workerRefResult) Sys() interface{} {
 	return r.WorkerRef
 }
@@ -53,4 +53,5 @@ const (
 	LabelOCIProcessMode      = labelPrefix + "oci.process-mode"     // OCI worker: process mode ("sandbox", "no-sandbox")
 	LabelContainerdUUID      = labelPrefix + "containerd.uuid"      // containerd worker: containerd UUID
 	LabelContainerdNamespace = labelPrefix + "containerd.namespace" // containerd worker: containerd namespace
+	LabelRemoteAddress       = labelPrefix + "remote.address"       // remote worker: address of the agent
 )
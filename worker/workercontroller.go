@@ -2,7 +2,9 @@ package worker
 
 import (
 	"github.com/containerd/containerd/filters"
+	"github.com/containerd/containerd/platforms"
 	"github.com/moby/buildkit/client"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
 )
 
@@ -54,7 +56,38 @@ func (c *Controller) Get(id string) (Worker, error) {
 	return nil, errors.Errorf("worker %s not found", id)
 }
 
-// TODO: add Get(Constraint) (*Worker, error)
+// ForPlatform returns the worker running the processes of the platform. The
+// workers running the platform natively are preferred over the workers
+// running it with an emulator. The default worker is returned if no worker
+// lists the platform, so that it runs the processes with the emulators
+// bundled with BuildKit.
+func (c *Controller) ForPlatform(p ocispecs.Platform) (Worker, error) {
+	p = platforms.Normalize(p)
+	var emulated Worker
+	for _, w := range c.workers {
+		ps := w.Platforms(false)
+		if len(ps) == 0 {
+			continue
+		}
+		// the first platform of a worker is the platform of its host
+		if platforms.Only(ps[0]).Match(p) {
+			return w, nil
+		}
+		if emulated != nil {
+			continue
+		}
+		for _, wp := range ps[1:] {
+			if platforms.Only(wp).Match(p) {
+				emulated = w
+				break
+			}
+		}
+	}
+	if emulated != nil {
+		return emulated, nil
+	}
+	return c.GetDefault()
+}
 
 // WorkerInfos returns slice of WorkerInfo.
 // The first item is the default worker.
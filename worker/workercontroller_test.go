@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/containerd/containerd/platforms"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+type platformWorker struct {
+	Worker
+	id        string
+	platforms []ocispecs.Platform
+}
+
+func (w *platformWorker) ID() string {
+	return w.id
+}
+
+func (w *platformWorker) Platforms(bool) []ocispecs.Platform {
+	return w.platforms
+}
+
+func TestControllerForPlatform(t *testing.T) {
+	p := func(s string) ocispecs.Platform {
+		return platforms.MustParse(s)
+	}
+	c := &Controller{}
+	require.NoError(t, c.Add(&platformWorker{id: "amd64", platforms: []ocispecs.Platform{p("linux/amd64"), p("linux/arm64"), p("linux/riscv64")}}))
+	require.NoError(t, c.Add(&platformWorker{id: "arm64", platforms: []ocispecs.Platform{p("linux/arm64"), p("linux/amd64")}}))
+
+	for _, tc := range []struct {
+		platform string
+		worker   string
+	}{
+		{"linux/amd64", "amd64"},
+		// native worker preferred over the default worker emulating it
+		{"linux/arm64", "arm64"},
+		{"linux/arm/v7", "arm64"},
+		// only emulated by the default worker
+		{"linux/riscv64", "amd64"},
+		// not listed by any worker
+		{"linux/s390x", "amd64"},
+	} {
+		w, err := c.ForPlatform(p(tc.platform))
+		require.NoError(t, err)
+		require.Equal(t, tc.worker, w.ID(), tc.platform)
+	}
+
+	_, err := (&Controller{}).ForPlatform(p("linux/amd64"))
+	require.Error(t, err)
+}